@@ -0,0 +1,9 @@
+#![no_main]
+use glaurung::core::triage::Budgets;
+use glaurung::triage::recurse::RecursionEngine;
+use libfuzzer_sys::fuzz_target;
+
+fuzz_target!(|data: &[u8]| {
+    let mut budgets = Budgets::new(0, 0, 0);
+    let _ = RecursionEngine::new(3).discover_children(data, &mut budgets, 0);
+});
@@ -0,0 +1,43 @@
+#![no_main]
+use glaurung::formats::elf::ElfParser;
+use libfuzzer_sys::fuzz_target;
+
+// Walks every lazily parsed table, since most of the ELF parser only runs
+// when an accessor is called.
+fuzz_target!(|data: &[u8]| {
+    let Ok(elf) = ElfParser::parse(data) else {
+        return;
+    };
+    if let Ok(sections) = elf.sections() {
+        let _ = sections.sections().count();
+        let _ = sections.executable_sections();
+    }
+    if let Ok(segments) = elf.segments() {
+        let _ = segments.segments().count();
+    }
+    for table in [elf.symbols(), elf.dynamic_symbols()]
+        .into_iter()
+        .flatten()
+        .flatten()
+    {
+        let _ = table.functions();
+        let _ = table.imports();
+        let _ = table.exports();
+    }
+    if let Ok(Some(dynamic)) = elf.dynamic() {
+        let _ = dynamic.needed_libraries();
+    }
+    if let Ok(Some(got)) = elf.got_relocations() {
+        let _ = got.got_entries().count();
+    }
+    if let Ok(Some(plt)) = elf.plt_relocations() {
+        let _ = plt.plt_entries().count();
+    }
+    let _ = elf.android_packed_relocations();
+    let _ = elf.relr_relocations();
+    let _ = elf.security_features();
+    let _ = elf.interpreter();
+    let _ = elf.entry_section();
+    let _ = elf.build_id();
+    let _ = elf.validate();
+});
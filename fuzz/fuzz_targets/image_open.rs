@@ -0,0 +1,18 @@
+#![no_main]
+use glaurung::core::image::BinaryImage;
+use glaurung::formats::open_image;
+use libfuzzer_sys::fuzz_target;
+
+// The common image view: Mach-O, COFF, and wasm have no other entry point,
+// and ELF/PE reach it through the `object` crate instead of our parsers.
+fuzz_target!(|data: &[u8]| {
+    let Some(image) = open_image(data) else {
+        return;
+    };
+    let image: &dyn BinaryImage = &*image;
+    let _ = image.entry();
+    let _ = image.sections();
+    let _ = image.segments();
+    let _ = image.symbols();
+    let _ = image.imports();
+});
@@ -0,0 +1,27 @@
+#![no_main]
+use glaurung::formats::pe::PeParser;
+use libfuzzer_sys::fuzz_target;
+
+// Data directories are parsed on first access; touch each of them.
+fuzz_target!(|data: &[u8]| {
+    let Ok(pe) = PeParser::new(data) else {
+        return;
+    };
+    let _ = pe.entry_section();
+    if let Ok(imports) = pe.imports() {
+        let _ = imports.dll_names();
+        let _ = imports.import_hash();
+    }
+    if let Ok(exports) = pe.exports() {
+        let _ = exports.names();
+    }
+    let _ = pe.debug_directory();
+    let _ = pe.codeview_rsds();
+    let _ = pe.resources();
+    let _ = pe.tls();
+    let _ = pe.iat_map();
+    let _ = pe.security_features();
+    let _ = pe.checksum_valid();
+    let _ = pe.anomalies();
+    let _ = pe.packer_detection();
+});
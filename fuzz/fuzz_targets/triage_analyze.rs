@@ -0,0 +1,9 @@
+#![no_main]
+use glaurung::triage::api::analyze_bytes;
+use glaurung::triage::io::IOLimits;
+use libfuzzer_sys::fuzz_target;
+
+// End to end: every parser and heuristic triage runs on one input.
+fuzz_target!(|data: &[u8]| {
+    let _ = analyze_bytes(data, &IOLimits::default());
+});
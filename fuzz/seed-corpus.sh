@@ -0,0 +1,105 @@
+#!/usr/bin/env bash
+#
+# Seeds fuzz/corpus/<target>/ from the sample binaries, so the fuzzers start
+# from well-formed ELF, PE, Mach-O, wasm, and container inputs instead of
+# having to discover every magic number and header chain on their own.
+#
+# Each sample goes to the targets that can use it, chosen by its leading
+# bytes (format parsers only get their own format; the triage-wide targets
+# get everything). Files are named by SHA-1, as libFuzzer names its own
+# corpus entries, so re-running only adds what is new. git-lfs pointers that
+# were never fetched are skipped.
+#
+# Sources: samples/binaries/platforms/*/*/export (without metadata/),
+# samples/containers, samples/packed, and tests/fixtures.
+#
+# Usage:
+#   fuzz/seed-corpus.sh [--max-size 1048576] [--targets "elf_parse pe_parse"] [--clean]
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+CORPUS_DIR="$ROOT/fuzz/corpus"
+
+MAX_SIZE=1048576
+TARGETS="elf_parse pe_parse image_open containers_detect containers_recurse \
+headers_validate sniffers_sniff parsers_parse entropy_analyze triage_analyze"
+CLEAN=0
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --max-size) MAX_SIZE="$2"; shift 2 ;;
+        --targets) TARGETS="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) echo "unknown option: $1" >&2; usage; exit 2 ;;
+    esac
+done
+
+# kind FILE: coarse format from the first bytes.
+kind() {
+    local magic
+    magic="$(head -c 4 "$1" | od -An -tx1 | tr -d ' \n')"
+    case "$magic" in
+        7f454c46) echo "elf" ;;
+        4d5a*) echo "pe" ;;
+        feedface|feedfacf|cefaedfe|cffaedfe|cafebabe|bebafeca) echo "macho" ;;
+        0061736d) echo "wasm" ;;
+        504b0304|1f8b*|425a68*|fd377a58|28b52ffd|213c6172) echo "container" ;;
+        *) echo "other" ;;
+    esac
+}
+
+# wants TARGET KIND: whether TARGET should be seeded with a KIND file.
+wants() {
+    case "$1" in
+        elf_parse) [ "$2" = elf ] ;;
+        pe_parse) [ "$2" = pe ] ;;
+        image_open) [[ "$2" =~ ^(elf|pe|macho|wasm)$ ]] ;;
+        containers_detect|containers_recurse) [ "$2" = container ] ;;
+        *) true ;;
+    esac
+}
+
+if [ "$CLEAN" = 1 ]; then
+    for t in $TARGETS; do rm -rf "${CORPUS_DIR:?}/$t"; done
+fi
+for t in $TARGETS; do mkdir -p "$CORPUS_DIR/$t"; done
+
+sources=()
+for d in "$ROOT"/samples/binaries/platforms/*/*/export "$ROOT/samples/containers" \
+         "$ROOT/samples/packed" "$ROOT/tests/fixtures"; do
+    [ -d "$d" ] && sources+=("$d")
+done
+if [ ${#sources[@]} -eq 0 ]; then
+    echo "no samples found; build or fetch them first (see samples/README.md)" >&2
+    exit 1
+fi
+
+added=0
+skipped=0
+while IFS= read -r -d '' f; do
+    if head -c 40 "$f" | grep -q '^version https://git-lfs.github.com/spec/'; then
+        skipped=$((skipped + 1))
+        continue
+    fi
+    k="$(kind "$f")"
+    name="$(sha1sum "$f" | cut -d' ' -f1)"
+    for t in $TARGETS; do
+        wants "$t" "$k" || continue
+        if [ ! -e "$CORPUS_DIR/$t/$name" ]; then
+            cp "$f" "$CORPUS_DIR/$t/$name"
+            added=$((added + 1))
+        fi
+    done
+done < <(find "${sources[@]}" -type f -size -"$((MAX_SIZE + 1))"c \
+             -not -path '*/metadata/*' -not -name '*.json' -print0)
+
+for t in $TARGETS; do
+    echo "$t: $(find "$CORPUS_DIR/$t" -type f | wc -l) inputs"
+done
+echo "added $added corpus entries; skipped $skipped LFS pointers"
@@ -0,0 +1,116 @@
+#!/usr/bin/env bash
+#
+# Groups the inputs libFuzzer saved under fuzz/artifacts/<target>/ by the
+# place they fail, so one bug that produced two hundred crash files shows up
+# as one line. Each input is replayed once; its signature is the panic
+# location (`panicked at src/...:line:col`), else the first sanitizer stack
+# frame inside this crate, else the artifact kind (timeout, oom, leak).
+#
+# Per target a report is written to fuzz/artifacts/<target>/triage.txt:
+# count, signature, and the smallest input for each group.
+#
+# With --minimize the smallest input of every group is shrunk with
+# `cargo fuzz tmin`. With --promote the (minimized) inputs are copied to
+# fuzz/regressions/<target>/, which `cargo test --test fuzz_regressions`
+# replays on every run; promote once the bug is fixed.
+#
+# Usage:
+#   fuzz/triage-crashes.sh [--minimize] [--promote] [target...]
+#
+# CARGO_FUZZ overrides the driver (default: "cargo +nightly fuzz").
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+ARTIFACTS_DIR="$ROOT/fuzz/artifacts"
+REGRESSIONS_DIR="$ROOT/fuzz/regressions"
+read -r -a CARGO_FUZZ <<< "${CARGO_FUZZ:-cargo +nightly fuzz}"
+
+MINIMIZE=0
+PROMOTE=0
+TARGETS=()
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --minimize) MINIMIZE=1; shift ;;
+        --promote) PROMOTE=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        -*) echo "unknown option: $1" >&2; usage; exit 2 ;;
+        *) TARGETS+=("$1"); shift ;;
+    esac
+done
+
+if [ ${#TARGETS[@]} -eq 0 ]; then
+    for d in "$ARTIFACTS_DIR"/*/; do
+        [ -d "$d" ] && TARGETS+=("$(basename "$d")")
+    done
+fi
+if [ ${#TARGETS[@]} -eq 0 ]; then
+    echo "no artifacts under $ARTIFACTS_DIR"
+    exit 0
+fi
+
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+# signature FILE LOG: where the input fails.
+signature() {
+    local sig
+    sig="$(grep -m1 -oE "panicked at [^ ]+:[0-9]+:[0-9]+" "$2" || true)"
+    if [ -z "$sig" ]; then
+        # ASan/UBSan frame: "#3 0x55... in glaurung::formats::elf::... /.../src/formats/elf/x.rs:12:5"
+        sig="$(grep -m1 -E '^ *#[0-9]+ .* in .*/src/[^ ]+\.rs' "$2" \
+            | sed -E 's/.* in ([^ ]+) .*(src\/[^ ]+\.rs:[0-9]+).*/\2 (\1)/' || true)"
+    fi
+    if [ -z "$sig" ]; then
+        sig="$(basename "$1" | cut -d- -f1)"
+    fi
+    echo "$sig"
+}
+
+for target in "${TARGETS[@]}"; do
+    dir="$ARTIFACTS_DIR/$target"
+    [ -d "$dir" ] || { echo "$target: no artifacts"; continue; }
+    : > "$work/index"
+    for f in "$dir"/crash-* "$dir"/oom-* "$dir"/timeout-* "$dir"/leak-*; do
+        [ -f "$f" ] || continue
+        (cd "$ROOT/fuzz" && "${CARGO_FUZZ[@]}" run "$target" "$f" -- -runs=1) \
+            > "$work/log" 2>&1 || true
+        printf '%s\t%s\t%s\n' "$(signature "$f" "$work/log")" "$(wc -c < "$f")" "$f" \
+            >> "$work/index"
+    done
+    if [ ! -s "$work/index" ]; then
+        echo "$target: no crash, oom, timeout, or leak inputs"
+        continue
+    fi
+
+    report="$dir/triage.txt"
+    # Per signature: count and smallest input.
+    sort -t$'\t' -k1,1 -k2,2n "$work/index" | awk -F'\t' '
+        $1 != sig { if (sig != "") print n "\t" sig "\t" first; sig = $1; n = 0; first = $3 }
+        { n++ }
+        END { if (sig != "") print n "\t" sig "\t" first }
+    ' | sort -t$'\t' -k1,1nr > "$report"
+
+    echo "$target: $(wc -l < "$work/index") inputs, $(wc -l < "$report") distinct failures"
+    while IFS=$'\t' read -r count sig first; do
+        input="$first"
+        if [ "$MINIMIZE" = 1 ]; then
+            (cd "$ROOT/fuzz" && "${CARGO_FUZZ[@]}" tmin "$target" "$first") > "$work/tmin" 2>&1 || true
+            # tmin prints the path relative to fuzz/.
+            minimized="$(grep -oE "[^[:space:]]*minimized-from-[0-9a-f]+" "$work/tmin" | tail -n1 || true)"
+            [[ -z "$minimized" || "$minimized" == /* ]] || minimized="$ROOT/fuzz/$minimized"
+            [ -n "$minimized" ] && [ -f "$minimized" ] && input="$minimized"
+        fi
+        printf '  %5d  %s\n         %s\n' "$count" "$sig" "${input#"$ROOT"/}"
+        if [ "$PROMOTE" = 1 ]; then
+            mkdir -p "$REGRESSIONS_DIR/$target"
+            cp "$input" "$REGRESSIONS_DIR/$target/$(sha1sum "$input" | cut -d' ' -f1)"
+        fi
+    done < "$report"
+    echo "  report: ${report#"$ROOT"/}"
+done
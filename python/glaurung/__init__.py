@@ -370,3 +370,14 @@ __all__ += [
     "init_logging",
     "log_message",
 ]
+
+# Process-wide analysis metrics (Prometheus text format)
+enable_metrics = _native.enable_metrics
+metrics_prometheus = _native.metrics_prometheus
+reset_metrics = _native.reset_metrics
+
+__all__ += [
+    "enable_metrics",
+    "metrics_prometheus",
+    "reset_metrics",
+]
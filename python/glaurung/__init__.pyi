@@ -301,6 +301,7 @@ class ControlFlowEdgeKind(enum.Enum):
     Branch: ControlFlowEdgeKind
     Call: ControlFlowEdgeKind
     Return: ControlFlowEdgeKind
+    Exception: ControlFlowEdgeKind
 
 class CallType(enum.Enum):
     """Type of function call."""
@@ -902,6 +903,7 @@ class Function:
 
     id: str
     name: str
+    demangled_name: Optional[str]
     entry_point: Address
     kind: FunctionKind
     size: Optional[int]
@@ -1373,11 +1375,17 @@ class EnumMember:
 # Logging Functions
 # ============================================================================
 
-def init_logging(json: bool = False) -> None:
+def init_logging(json: bool = False, level: Optional[str] = None) -> None:
     """Initialize the logging system.
 
     Args:
         json: If True, use JSON format for logs. If False, use regular format.
+        level: Level or filter directives (e.g. ``"debug"`` or
+            ``"info,glaurung::analysis::pipeline=trace"``). Defaults to
+            ``GLAURUNG_LOG``, then ``RUST_LOG``, then ``info``.
+
+    Raises:
+        ValueError: If ``level`` is not a valid filter.
     """
     ...
 
@@ -1385,6 +1393,26 @@ def log_message(level: LogLevel, message: str) -> None:
     """Log a message at the specified level."""
     ...
 
+# ============================================================================
+# Metrics Functions
+# ============================================================================
+
+def enable_metrics() -> None:
+    """Start aggregating analysis events (triage runs, bytes read, pass
+    durations and outcomes, findings) into process-wide counters."""
+    ...
+
+def metrics_prometheus() -> str:
+    """Render the process-wide counters in the Prometheus text format.
+
+    Enables collection if it was not already enabled.
+    """
+    ...
+
+def reset_metrics() -> None:
+    """Zero the process-wide counters."""
+    ...
+
 # ============================================================================
 # Module Exports
 # ============================================================================
@@ -1465,4 +1493,7 @@ __all__ = [
     # Functions
     "init_logging",
     "log_message",
+    "enable_metrics",
+    "metrics_prometheus",
+    "reset_metrics",
 ]
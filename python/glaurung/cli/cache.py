@@ -1,8 +1,9 @@
 """Persistent, content-addressed cache for expensive CLI sub-operations.
 
 This module backs the ``--cache-dir`` flag / ``GLAURUNG_CACHE_DIR`` env var
-plumbed through :mod:`glaurung.cli.commands.decompile` and
-:mod:`glaurung.cli.commands.name_func`. The cache is best-effort: any I/O
+plumbed through :mod:`glaurung.cli.commands.decompile`,
+:mod:`glaurung.cli.commands.name_func` and :mod:`glaurung.cli.commands.batch`.
+The cache is best-effort: any I/O
 error from the cache path is logged at WARNING and the caller falls back
 to the un-cached path. Cache misses never propagate as user-facing
 errors.
@@ -11,11 +12,13 @@ Layout under ``<cache_dir>``::
 
     decomp/<glaurung_version>/<sha256>/<va_hex>.<flags_hash>.c
     name-func/<glaurung_version>/<sha256>/<va_hex>.<flags_hash>.json
+    triage/<glaurung_version>/<sha256>/<flags_hash>.json
 
 * ``<glaurung_version>`` is :func:`importlib.metadata.version("glaurung")`
   so version bumps invalidate cleanly without manual clearing.
 * ``<sha256>`` is a chunked-read hex SHA-256 of the binary file's bytes.
-* ``<va_hex>`` is the function VA in lowercase hex, no ``0x`` prefix.
+* ``<va_hex>`` is the function VA in lowercase hex, no ``0x`` prefix;
+  whole-file entries (``triage``) have none.
 * ``<flags_hash>`` is the first 8 hex chars of SHA-256 of a canonical
   JSON encoding of the decompile-affecting flags. This keeps two
   different invocations (e.g. ``--style plain`` vs ``--style c``) from
@@ -99,10 +102,10 @@ class CachePaths:
     """Concrete on-disk paths for a single cache entry."""
 
     cache_dir: Path
-    namespace: str  # "decomp" or "name-func"
+    namespace: str  # "decomp", "name-func" or "triage"
     glaurung_version: str
     binary_sha256: str
-    va_hex: str
+    va_hex: str  # "" for whole-file entries
     flags_hash: str
     suffix: str  # ".c", ".plain.c", ".json", ...
 
@@ -114,7 +117,8 @@ class CachePaths:
 
     @property
     def file(self) -> Path:
-        return self.dir / f"{self.va_hex}.{self.flags_hash}{self.suffix}"
+        stem = f"{self.va_hex}.{self.flags_hash}" if self.va_hex else self.flags_hash
+        return self.dir / f"{stem}{self.suffix}"
 
 
 def build_paths(
@@ -122,18 +126,21 @@ def build_paths(
     *,
     namespace: str,
     binary_sha256: str,
-    va: int,
+    va: int | None,
     flags: dict[str, Any],
     suffix: str,
 ) -> CachePaths:
-    """Helper to assemble :class:`CachePaths` from raw inputs."""
+    """Helper to assemble :class:`CachePaths` from raw inputs.
+
+    ``va=None`` addresses a whole-file entry.
+    """
 
     return CachePaths(
         cache_dir=Path(cache_dir),
         namespace=namespace,
         glaurung_version=get_glaurung_version(),
         binary_sha256=binary_sha256,
-        va_hex=va_to_key(va),
+        va_hex="" if va is None else va_to_key(va),
         flags_hash=flags_hash(flags),
         suffix=suffix,
     )
@@ -0,0 +1,127 @@
+"""Analyst annotation CLI subcommand.
+
+`glaurung annotate <file> --store <dir>` records tags, comments, a
+verdict, and function renames for a file in an annotation store. Entries
+are keyed by the file's SHA-256, so `glaurung triage --annotations <dir>`
+merges them into every later triage of the same bytes, wherever the file
+lives. With no mutating flags the current annotations are printed.
+"""
+
+import argparse
+import getpass
+import os
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+def _parse_rename(spec: str):
+    va, sep, name = spec.partition("=")
+    if not sep or not name:
+        raise argparse.ArgumentTypeError(f"expected VA=NAME, got {spec!r}")
+    try:
+        return int(va, 0), name
+    except ValueError:
+        raise argparse.ArgumentTypeError(f"bad address in {spec!r}") from None
+
+
+def _parse_va(text: str) -> int:
+    try:
+        return int(text, 0)
+    except ValueError:
+        raise argparse.ArgumentTypeError(f"bad address {text!r}") from None
+
+
+class AnnotateCommand(BaseCommand):
+    """Record analyst annotations for a file."""
+
+    def get_name(self) -> str:
+        return "annotate"
+
+    def get_help(self) -> str:
+        return "Tag, comment on, judge, or rename functions of a file (keyed by SHA-256)"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="File to annotate")
+        parser.add_argument(
+            "--store", default=os.environ.get("GLAURUNG_ANNOTATIONS"),
+            help="Annotation store directory (default: $GLAURUNG_ANNOTATIONS)",
+        )
+        parser.add_argument(
+            "--tag", action="append", default=[], help="Add a tag (repeatable)",
+        )
+        parser.add_argument(
+            "--untag", action="append", default=[], help="Remove a tag (repeatable)",
+        )
+        parser.add_argument("--comment", default=None, help="Add a comment")
+        parser.add_argument(
+            "--at", type=_parse_va, default=None,
+            help="Anchor --comment at this address (hex or decimal)",
+        )
+        verdict = parser.add_mutually_exclusive_group()
+        verdict.add_argument("--verdict", default=None, help="Set the verdict label")
+        verdict.add_argument(
+            "--clear-verdict", action="store_true", help="Remove the verdict",
+        )
+        parser.add_argument("--note", default=None, help="Note for --verdict")
+        parser.add_argument(
+            "--rename", action="append", type=_parse_rename, default=[],
+            metavar="VA=NAME", help="Name the function at VA (repeatable)",
+        )
+        parser.add_argument(
+            "--author", default=None, help="Author to record (default: current user)",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        import glaurung as g
+
+        if not args.store:
+            formatter.output_plain("Error: no --store given and GLAURUNG_ANNOTATIONS unset")
+            return 2
+        try:
+            path = self.validate_file_path(args.path)
+        except (FileNotFoundError, ValueError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+
+        store = g.triage.AnnotationStore(args.store)
+        sha = g.triage.sha256_file(str(path))
+        author = args.author
+        if author is None:
+            try:
+                author = getpass.getuser()
+            except Exception:
+                author = None
+
+        ann = store.load(sha)
+        for tag in args.tag:
+            ann = store.add_tag(sha, tag)
+        for tag in args.untag:
+            ann = store.remove_tag(sha, tag)
+        if args.comment:
+            ann = store.add_comment(sha, args.comment, args.at, author)
+        if args.verdict:
+            ann = store.set_verdict(sha, args.verdict, args.note, author)
+        elif args.clear_verdict:
+            ann = store.set_verdict(sha, None)
+        for va, name in args.rename:
+            ann = store.rename_function(sha, va, name, author)
+
+        if formatter.format_type == OutputFormat.JSON:
+            import json
+
+            formatter.output_json(json.loads(ann.to_json()))
+            return 0
+
+        lines = [f"sha256: {sha}"]
+        lines.append(f"tags: {', '.join(ann.tags) if ann.tags else '(none)'}")
+        if ann.verdict:
+            note = f" ({ann.verdict.note})" if ann.verdict.note else ""
+            lines.append(f"verdict: {ann.verdict.label}{note}")
+        for c in ann.comments:
+            where = f" @ {c.address:#x}" if c.address is not None else ""
+            lines.append(f"comment{where}: {c.text}")
+        for r in ann.function_names:
+            lines.append(f"rename {r.address:#x} -> {r.name}")
+        formatter.output_plain("\n".join(lines))
+        return 0
@@ -0,0 +1,258 @@
+"""Corpus-scale batch triage.
+
+`glaurung batch <roots...> -o report.jsonl` walks files and directories,
+triages every file across a pool of worker processes, and writes one
+report per line in input order — the same JSONL that `glaurung report
+migrate` / `build-ids` consume.
+
+With `--cache-dir` (or `GLAURUNG_CACHE_DIR`) each report is stored under
+its SHA-256, the glaurung version and the analysis flags (see
+:mod:`glaurung.cli.cache`), so re-running a sweep only analyzes files
+that are new or changed; duplicates anywhere in the corpus are analyzed
+once. Cached reports are migrated to the current schema on read.
+
+Memory stays bounded: at most ``2 * --jobs`` files are in flight, each
+read under the usual ``--max-read-bytes`` / ``--max-file-size`` limits,
+and reports are streamed to the output as they complete. A worker that
+dies outright (a crash in native code, an OOM kill) costs only the file
+that killed it: that file is recorded as failed and the pool is rebuilt.
+"""
+
+import argparse
+import json
+import os
+import sys
+from collections import deque
+from concurrent.futures import Future, ProcessPoolExecutor
+from concurrent.futures.process import BrokenProcessPool
+from pathlib import Path
+from typing import Any, Dict, Iterator, List, Optional, Tuple
+
+from .base import BaseCommand
+from .. import cache as _cache
+from ..formatters.base import BaseFormatter, OutputFormat
+
+# (path, report JSON or None, "analyzed" | "cached" | "failed", error)
+_Result = Tuple[str, Optional[str], str, Optional[str]]
+
+
+def _walk(roots: List[str]) -> Iterator[Path]:
+    """Yield regular files under ``roots`` in a stable order."""
+    for root in map(Path, roots):
+        if root.is_file():
+            yield root
+        elif root.is_dir():
+            for dirpath, dirnames, filenames in os.walk(root):
+                dirnames.sort()
+                for name in sorted(filenames):
+                    p = Path(dirpath) / name
+                    if p.is_file() and not p.is_symlink():
+                        yield p
+
+
+def _timed_out(report: Dict[str, Any]) -> bool:
+    # A partial artifact depends on machine load, not just the input bytes.
+    return any(
+        (e or {}).get("kind") == "BudgetExceeded" for e in report.get("errors") or []
+    )
+
+
+def _triage_one(path: str, options: Dict[str, Any], cache_dir: Optional[str]) -> _Result:
+    """Worker: triage one file, consulting and filling the cache."""
+    import glaurung as g
+
+    paths = None
+    if cache_dir is not None:
+        try:
+            paths = _cache.build_paths(
+                cache_dir,
+                namespace="triage",
+                binary_sha256=_cache.sha256_file(Path(path)),
+                va=None,
+                flags=_cache.canonical_flag_dict(options.items()),
+                suffix=".json",
+            )
+        except OSError as e:
+            return path, None, "failed", str(e)
+        hit = _cache.read_text(paths)
+        if hit is not None:
+            try:
+                migrated, _steps = g.triage.migrate_report(hit)
+                report = json.loads(migrated)
+            except ValueError:
+                report = None  # corrupt or from a newer build: re-analyze
+            if report is not None:
+                report["path"] = path  # the same bytes may live elsewhere
+                return path, json.dumps(report, sort_keys=True), "cached", None
+
+    try:
+        artifact = g.triage.analyze_path(
+            path,
+            options["max_read_bytes"],
+            options["max_file_size"],
+            options["max_depth"],
+            timeout_ms=options["timeout_ms"],
+        )
+    except Exception as e:  # noqa: BLE001 — one bad sample must not stop a sweep
+        return path, None, "failed", str(e)
+    report = json.loads(artifact.to_json())
+    body = json.dumps(report, sort_keys=True)
+    if paths is not None and not _timed_out(report):
+        _cache.write_text(paths, body)
+    return path, body, "analyzed", None
+
+
+class _Inline:
+    """Executor stand-in for ``--jobs 1``: runs each task on submit."""
+
+    def submit(self, fn, *args) -> Future:
+        fut: Future = Future()
+        fut.set_result(fn(*args))
+        return fut
+
+    def shutdown(self, wait: bool = True) -> None:
+        return None
+
+
+class BatchCommand(BaseCommand):
+    """Triage a corpus in parallel into a JSONL report."""
+
+    def get_name(self) -> str:
+        return "batch"
+
+    def get_help(self) -> str:
+        return "Triage every file under directories in parallel, with a result cache"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("roots", nargs="+", help="Files or directories to triage")
+        parser.add_argument(
+            "-o", "--output", required=True, help="JSONL report to write (one artifact per line)"
+        )
+        parser.add_argument(
+            "--cache-dir",
+            default=None,
+            help="Persistent result cache keyed by SHA-256 and glaurung version "
+            "(default: $GLAURUNG_CACHE_DIR; unset disables caching)",
+        )
+        parser.add_argument(
+            "-j",
+            "--jobs",
+            type=int,
+            default=os.cpu_count() or 1,
+            help="Worker processes (default: CPU count)",
+        )
+        parser.add_argument(
+            "--max-read-bytes",
+            type=int,
+            default=10_485_760,
+            help="Max bytes to read per file (default: 10MB)",
+        )
+        parser.add_argument(
+            "--max-file-size",
+            type=int,
+            default=104_857_600,
+            help="Max file size (default: 100MB)",
+        )
+        parser.add_argument(
+            "--max-depth", type=int, default=1, help="Max container recursion depth"
+        )
+        parser.add_argument(
+            "--timeout-ms",
+            type=int,
+            default=None,
+            help="Per-file time budget; partial reports are written but not cached",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        if args.jobs < 1:
+            formatter.output_plain("Error: --jobs must be at least 1")
+            return 2
+        cache_dir = _cache.resolve_cache_dir(args.cache_dir)
+        options = {
+            "max_read_bytes": args.max_read_bytes,
+            "max_file_size": args.max_file_size,
+            "max_depth": args.max_depth,
+            "timeout_ms": args.timeout_ms,
+        }
+        cache_arg = str(cache_dir) if cache_dir is not None else None
+
+        out = Path(args.output)
+        out.parent.mkdir(parents=True, exist_ok=True)
+        counts = {"analyzed": 0, "cached": 0, "failed": 0}
+        failures: List[Dict[str, str]] = []
+
+        def new_pool():
+            return ProcessPoolExecutor(args.jobs) if args.jobs > 1 else _Inline()
+
+        pool = new_pool()
+        # Futures are drained in submission order so the report is stable
+        # run to run; the window bounds how many results wait in memory.
+        window = 2 * args.jobs
+        pending: "deque[Tuple[str, Future]]" = deque()
+
+        def submit(path: str) -> Tuple[str, Future]:
+            try:
+                return path, pool.submit(_triage_one, path, options, cache_arg)
+            except BrokenProcessPool as e:
+                # Broken since the last drain; `result` recovers it.
+                fut: Future = Future()
+                fut.set_exception(e)
+                return path, fut
+
+        def result(path: str, fut: Future) -> _Result:
+            nonlocal pool
+            try:
+                return fut.result()
+            except BrokenProcessPool:
+                pass
+            # A dead worker takes every in-flight task down with it, and
+            # the first future to report it need not be the culprit. Retry
+            # this file alone so only the file that crashes is failed, then
+            # resubmit the rest to a fresh pool.
+            pool.shutdown(wait=False)
+            with ProcessPoolExecutor(1) as solo:
+                try:
+                    res = solo.submit(_triage_one, path, options, cache_arg).result()
+                except BrokenProcessPool:
+                    res = (path, None, "failed", "worker process died")
+            pool = new_pool()
+            for i in range(len(pending)):
+                pending[i] = submit(pending[i][0])
+            return res
+
+        def drain(sink) -> None:
+            path, body, status, error = result(*pending.popleft())
+            counts[status] += 1
+            if body is not None:
+                sink.write(body + "\n")
+            else:
+                failures.append({"path": path, "error": error or ""})
+                print(f"batch: {path}: {error}", file=sys.stderr)
+
+        try:
+            with out.open("w", encoding="utf-8") as sink:
+                for path in _walk(args.roots):
+                    pending.append(submit(str(path)))
+                    if len(pending) >= window:
+                        drain(sink)
+                while pending:
+                    drain(sink)
+        finally:
+            pool.shutdown()
+
+        summary = {
+            "output": str(out),
+            "cache_dir": cache_arg,
+            "files": sum(counts.values()),
+            **counts,
+            "failures": failures,
+        }
+        if formatter.format_type in (OutputFormat.JSON, OutputFormat.JSONL):
+            formatter.output_json(summary)
+        else:
+            formatter.output_plain(
+                f"wrote {out}: {summary['files']} files "
+                f"(analyzed={counts['analyzed']} cached={counts['cached']} "
+                f"failed={counts['failed']})"
+            )
+        return 0 if not failures else 1
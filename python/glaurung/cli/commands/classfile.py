@@ -2,7 +2,10 @@
 
 `glaurung classfile <path>` parses a single `.class` and prints its
 structured metadata. When given a `.jar`, walks every class entry
-in the archive and prints a per-class summary.
+in the archive and prints a per-class summary. `--disasm` adds a
+`javap -c` style listing of every method body; `--calls` adds the call
+graph across the class (or the whole archive) and flags process,
+class-loading, and reflective calls.
 """
 
 import argparse
@@ -44,7 +47,10 @@ def _format_access(flags: int, is_method: bool = False) -> str:
 
 
 def _render_class_summary(
-    info: dict, formatter: BaseFormatter, classfile_size: int | None = None
+    info: dict,
+    formatter: BaseFormatter,
+    classfile_size: int | None = None,
+    disasm: bool = False,
 ) -> None:
     policy = classfile_policy(
         int(info["major_version"]),
@@ -75,9 +81,24 @@ def _render_class_summary(
         formatter.output_plain(
             f"    {_format_access(m['access_flags'], is_method=True):<24} {m['name']}{m['descriptor']}"
         )
+        if disasm and m.get("code"):
+            for line in m["code"]["disassembly"]:
+                formatter.output_plain(f"      {line}")
 
 
-def _scan_jar(path: Path, formatter: BaseFormatter) -> int:
+def _render_call_graph(graph: dict, formatter: BaseFormatter) -> None:
+    formatter.output_plain(
+        f"call graph: {len(graph['nodes'])} methods, {len(graph['edges'])} edges"
+    )
+    for e in graph["edges"]:
+        formatter.output_plain(f"  {e['caller']} -> {e['callee']} ({e['kind']})")
+    if graph["suspicious"]:
+        formatter.output_plain(f"suspicious calls: {len(graph['suspicious'])}")
+        for name in graph["suspicious"]:
+            formatter.output_plain(f"  {name}")
+
+
+def _scan_jar(path: Path, formatter: BaseFormatter, disasm: bool = False) -> int:
     """Walk a JAR archive and print every class it contains. Returns
     the number of classes successfully parsed."""
     parsed = 0
@@ -91,7 +112,9 @@ def _scan_jar(path: Path, formatter: BaseFormatter) -> int:
             if info is None:
                 continue
             formatter.output_plain("")
-            _render_class_summary(info, formatter, classfile_size=len(data))
+            _render_class_summary(
+                info, formatter, classfile_size=len(data), disasm=disasm
+            )
             parsed += 1
     return parsed
 
@@ -107,6 +130,16 @@ class ClassfileCommand(BaseCommand):
 
     def add_arguments(self, parser: argparse.ArgumentParser) -> None:
         parser.add_argument("path", help=".class file or .jar archive")
+        parser.add_argument(
+            "--disasm",
+            action="store_true",
+            help="Disassemble every method body",
+        )
+        parser.add_argument(
+            "--calls",
+            action="store_true",
+            help="Print the call graph and flag suspicious calls",
+        )
 
     def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
         path = Path(args.path)
@@ -118,11 +151,16 @@ class ClassfileCommand(BaseCommand):
         # zipfile.is_zipfile recognizes — covers .war / .ear / unnamed).
         if path.suffix.lower() in (".jar", ".war", ".ear") or zipfile.is_zipfile(path):
             try:
-                count = _scan_jar(path, formatter)
+                count = _scan_jar(path, formatter, disasm=args.disasm)
             except zipfile.BadZipFile:
                 formatter.output_plain(f"Error: not a valid archive: {path}")
                 return 3
             formatter.output_plain(f"\n_parsed {count} class(es)_")
+            if args.calls:
+                graph = g.analysis.java_call_graph_path(str(path))
+                if graph is not None:
+                    formatter.output_plain("")
+                    _render_call_graph(graph, formatter)
             return 0
 
         java_analysis = getattr(g, "analysis")
@@ -130,8 +168,16 @@ class ClassfileCommand(BaseCommand):
         if info is None:
             formatter.output_plain(f"Error: not a Java class file: {path}")
             return 4
+        graph = java_analysis.java_call_graph_path(str(path)) if args.calls else None
         if formatter.format_type == OutputFormat.JSON:
+            if graph is not None:
+                info["call_graph"] = graph
             formatter.output_json(info)
             return 0
-        _render_class_summary(info, formatter, classfile_size=path.stat().st_size)
+        _render_class_summary(
+            info, formatter, classfile_size=path.stat().st_size, disasm=args.disasm
+        )
+        if graph is not None:
+            formatter.output_plain("")
+            _render_call_graph(graph, formatter)
         return 0
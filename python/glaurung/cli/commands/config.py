@@ -0,0 +1,53 @@
+"""Embedded configuration carver CLI subcommand.
+
+`glaurung config <path>` scans a file for configuration blobs: runs of
+length-prefixed key/value records, and plain, XOR- or RC4-encoded
+key/value text (INI, `k=v;...`, JSON) following markers such as
+`CONFIG` or `[CFG]`. Each config is printed with its offset, encoding,
+recovered key, and decoded fields.
+"""
+
+import argparse
+import json
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+class ConfigCommand(BaseCommand):
+    """Carve embedded configuration blobs from a file."""
+
+    def get_name(self) -> str:
+        return "config"
+
+    def get_help(self) -> str:
+        return "Carve embedded configuration blobs (length-prefixed, XOR/RC4 near markers)"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to the file to scan")
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        configs = json.loads(g.analysis.carve_configs_path(str(path)))
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"path": str(path), "configs": configs})
+            return 0
+        if not configs:
+            formatter.output_plain("no embedded configuration found")
+            return 1
+        for cfg in configs:
+            source = cfg["family"] or cfg["extractor"]
+            key = f" key={cfg['key']}" if cfg["key"] else ""
+            formatter.output_plain(
+                f"{cfg['offset']:#x} ({cfg['length']} bytes) {source} "
+                f"{cfg['encoding']}{key}"
+            )
+            for entry in cfg["entries"]:
+                formatter.output_plain(f"  {entry['key']} = {entry['value']}")
+        return 0
@@ -0,0 +1,68 @@
+"""Dead function CLI subcommand.
+
+`glaurung dead <path>` lists discovered functions that nothing reaches:
+no entry point, export, init/fini array or TLS callback roots them, no
+pointer to them sits in data, and no live function calls them or takes
+their address. Unreferenced functions are bloat or hidden functionality
+entered through dispatch the analysis could not resolve; unreachable ones
+are only referenced from other dead functions.
+"""
+
+import argparse
+import json
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+class DeadCommand(BaseCommand):
+    """List unreferenced and unreachable functions."""
+
+    def get_name(self) -> str:
+        return "dead"
+
+    def get_help(self) -> str:
+        return "List functions with no inbound calls, pointers, or loader roots"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to the ELF, PE or Mach-O file")
+        parser.add_argument(
+            "--unreferenced-only",
+            action="store_true",
+            help="Hide functions that are only referenced from dead code",
+        )
+        parser.add_argument(
+            "--min-size",
+            type=int,
+            default=0,
+            help="Hide functions smaller than this many bytes",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        try:
+            dead = json.loads(g.analysis.dead_functions_path(str(path)))
+        except (ValueError, RuntimeError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        dead = [d for d in dead if d["size"] >= args.min_size]
+        if args.unreferenced_only:
+            dead = [d for d in dead if d["status"] == "unreferenced"]
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"path": str(path), "dead": dead})
+            return 0
+        if not dead:
+            formatter.output_plain("no dead functions found")
+            return 1
+        for d in dead:
+            line = f"{d['va']:#x}  {d['size']:>6}  {d['status']:<12}  {d['name']}"
+            if d["referrers"]:
+                line += "  <- " + ", ".join(f"{va:#x}" for va in d["referrers"])
+            formatter.output_plain(line)
+        return 0
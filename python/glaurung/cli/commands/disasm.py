@@ -16,6 +16,11 @@ from ..formatters.base import OutputFormat
 class DisasmCommand(BaseCommand):
     """Command for disassembling code from a binary."""
 
+    # Classifier verdict and inferred load base for a headerless input,
+    # set by _get_architecture.
+    _raw_guess: Optional[dict] = None
+    _raw_base: Optional[int] = None
+
     def get_name(self) -> str:
         """Return the command name."""
         return "disasm"
@@ -134,6 +139,19 @@ class DisasmCommand(BaseCommand):
             if art.verdicts:
                 arch = art.verdicts[0].arch
             else:
+                # Headerless blob: the opcode classifier also names the
+                # byte order and ARM/Thumb state for the fallback decoder,
+                # and string pointers give the load base.
+                blob = path.read_bytes()
+                guesses = g.triage.identify_arch(blob[:262_144], 1)
+                if guesses and guesses[0]["confidence"] >= 0.5:
+                    self._raw_guess = guess = guesses[0]
+                    bits = 64 if guess["arch"].endswith("64") else 32
+                    bases = g.triage.infer_base(
+                        blob, bits, guess["endianness"], 0x1000, 1
+                    )
+                    if bases:
+                        self._raw_base = bases[0][0]
                 arch = (
                     art.heuristic_arch[0][0] if art.heuristic_arch else g.Arch.Unknown
                 )
@@ -195,7 +213,7 @@ class DisasmCommand(BaseCommand):
                 )
             except Exception:
                 # Fallback to simple disassembly from file start
-                start_va = 0
+                start_va = self._raw_base or 0
                 instructions = self._fallback_disasm(
                     path,
                     arch,
@@ -205,7 +223,7 @@ class DisasmCommand(BaseCommand):
                     args.max_time_ms,
                 )
         else:
-            start_va = 0
+            start_va = self._raw_base or 0
             instructions = self._fallback_disasm(
                 path,
                 arch,
@@ -226,6 +244,8 @@ class DisasmCommand(BaseCommand):
                 "max_instructions": args.max_instructions,
             },
         }
+        if self._raw_guess is not None:
+            data["metadata"]["arch_guess"] = self._raw_guess
 
         # Convert instructions to dict format
         for inst in instructions:
@@ -277,13 +297,19 @@ class DisasmCommand(BaseCommand):
         if engine in ("iced", "capstone"):
             options = {"engine": engine}
 
-        cfg = DisassemblerConfig(arch, Endianness.Little, options)
+        guess = self._raw_guess
+        endian = Endianness.Little
+        if guess is not None and guess["endianness"] == "big":
+            endian = Endianness.Big
+        cfg = DisassemblerConfig(arch, endian, options)
         try:
             d = PyDisassembler(cfg)
+            if guess is not None and guess["thumb"]:
+                d.set_thumb_mode(True)
         except Exception:
             return []
 
-        addr = Address(AddressKind.VA, 0, arch.address_bits())
+        addr = Address(AddressKind.VA, self._raw_base or 0, arch.address_bits())
         return d.disassemble_bytes(
             addr, data, max_instructions=max_instructions, max_time_ms=max_time_ms
         )
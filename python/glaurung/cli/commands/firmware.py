@@ -0,0 +1,141 @@
+"""Headerless firmware reconnaissance CLI subcommand.
+
+`glaurung firmware <blob>` answers the questions a raw dump leaves open
+before any disassembly is useful: which CPU and byte order the code is
+for (opcode-statistics classifier) and where the image was loaded
+(string-pointer voting over candidate bases). When no opcode model fits,
+the byte order and word size come from the shape of the data words
+instead. `--bits`, `--endian` and `--align` override both.
+"""
+
+import argparse
+from pathlib import Path
+from typing import Any, Dict, List
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+_ARCH_64 = {"x86_64", "aarch64", "mips64", "ppc64", "riscv64"}
+
+# Below this a classifier verdict is too weak to pick the pointer layout;
+# the data-word heuristics are more reliable for such blobs.
+_MIN_CONFIDENCE = 0.5
+
+
+def _describe(guess: Dict[str, Any]) -> str:
+    mode = " thumb" if guess["thumb"] else ""
+    return (
+        f"{guess['arch']}{mode} {guess['endianness']}-endian "
+        f"(confidence {guess['confidence']:.2f})"
+    )
+
+
+class FirmwareCommand(BaseCommand):
+    """Identify architecture and load address of a raw blob."""
+
+    def get_name(self) -> str:
+        return "firmware"
+
+    def get_help(self) -> str:
+        return "Identify CPU, byte order and load base of a headerless blob"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to raw firmware / memory dump")
+        parser.add_argument(
+            "--bits",
+            type=int,
+            choices=[32, 64],
+            default=None,
+            help="Pointer width for base inference (default: from the architecture)",
+        )
+        parser.add_argument(
+            "--endian",
+            choices=["little", "big"],
+            default=None,
+            help="Byte order for base inference (default: from the architecture)",
+        )
+        parser.add_argument(
+            "--align",
+            type=lambda x: int(x, 0),
+            default=0x1000,
+            help="Granularity of candidate bases (default: 0x1000)",
+        )
+        parser.add_argument(
+            "--max-candidates",
+            type=int,
+            default=5,
+            help="Candidates listed per question (default: 5)",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        data = path.read_bytes()
+
+        guesses: List[Dict[str, Any]] = g.triage.identify_arch(
+            data[:262_144], args.max_candidates
+        )
+        best = (
+            guesses[0]
+            if guesses and guesses[0]["confidence"] >= _MIN_CONFIDENCE
+            else None
+        )
+        layout = None if best else g.triage.detect_layout(data)
+        if best:
+            default_bits = 64 if best["arch"] in _ARCH_64 else 32
+            default_endian = best["endianness"]
+        elif layout:
+            default_bits, default_endian = layout["bits"], layout["endianness"]
+        else:
+            default_bits, default_endian = 32, "little"
+        bits = args.bits or default_bits
+        endian = args.endian or default_endian
+        bases = g.triage.infer_base(
+            data, bits, endian, args.align, args.max_candidates
+        )
+
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json(
+                {
+                    "path": str(path),
+                    "arch": guesses,
+                    "layout": layout,
+                    "bits": bits,
+                    "endianness": endian,
+                    "base": [
+                        {"base": b, "hits": hits, "score": score}
+                        for b, hits, score in bases
+                    ],
+                }
+            )
+            return 0
+
+        if best is None:
+            formatter.output_plain("arch: unidentified (no opcode model fits)")
+            if layout:
+                formatter.output_plain(
+                    f"layout: {layout['bits']}-bit {layout['endianness']}-endian "
+                    f"(confidence {layout['bits_confidence']:.2f} / "
+                    f"{layout['endianness_confidence']:.2f})"
+                )
+        else:
+            formatter.output_plain(f"arch: {_describe(best)}")
+            for other in guesses[1:]:
+                formatter.output_plain(f"  also: {_describe(other)}")
+        if not bases:
+            formatter.output_plain(
+                f"base: unidentified ({bits}-bit {endian}-endian pointers "
+                "do not reach the blob's strings)"
+            )
+            return 0
+        base, hits, score = bases[0]
+        formatter.output_plain(
+            f"base: {base:#x} ({hits} string pointers, {score:.0%} of strings)"
+        )
+        for b, h, s in bases[1:]:
+            formatter.output_plain(f"  also: {b:#x} ({h} pointers, {s:.0%})")
+        return 0
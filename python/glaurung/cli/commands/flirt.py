@@ -0,0 +1,124 @@
+"""FLIRT-style signature CLI subcommands.
+
+`glaurung flirt build` turns static libraries (`.a` / `.lib`, or loose
+`.o` / `.obj` files) into a signature library: each function's leading
+bytes with relocated bytes wildcarded, a CRC16 of the bytes after them
+and its length. Drop the output into `data/sigs/` (or point
+`GLAURUNG_FLIRT_LIB` at it) and analysis renames the matching `sub_*`
+functions of stripped, statically linked binaries. `glaurung flirt match`
+lists what a library recognises in one binary.
+"""
+
+import argparse
+import json
+from pathlib import Path
+from typing import List
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+_INPUT_SUFFIXES = (".a", ".lib", ".o", ".obj")
+
+
+def _collect_inputs(roots: List[str]) -> List[Path]:
+    out: List[Path] = []
+    for root in map(Path, roots):
+        if root.is_file():
+            out.append(root)
+        elif root.is_dir():
+            out.extend(
+                p for p in sorted(root.rglob("*"))
+                if p.is_file() and p.suffix.lower() in _INPUT_SUFFIXES
+            )
+    return out
+
+
+class FlirtCommand(BaseCommand):
+    """Build and match FLIRT-style library function signatures."""
+
+    def get_name(self) -> str:
+        return "flirt"
+
+    def get_help(self) -> str:
+        return "Build signature libraries from .a/.lib files and match them"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        sub = parser.add_subparsers(dest="flirt_action", required=True)
+        build = sub.add_parser("build", help="Build a signature library")
+        build.add_argument(
+            "roots", nargs="+", help="Static libraries, objects, or directories of them"
+        )
+        build.add_argument("-o", "--output", required=True, help="Library JSON to write")
+        build.add_argument(
+            "--arch", default="x86_64", help="Target architecture (default: x86_64)"
+        )
+        build.add_argument(
+            "--pattern-len", type=int, default=32, help="Leading bytes per pattern"
+        )
+        build.add_argument(
+            "--min-length", type=int, default=16, help="Skip shorter functions"
+        )
+        match = sub.add_parser("match", help="List library functions found in a binary")
+        match.add_argument("path", help="Binary to scan")
+        match.add_argument(
+            "--sig",
+            action="append",
+            default=None,
+            help="Signature library (repeatable; default: data/sigs/*.flirt.json)",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        if args.flirt_action == "build":
+            return self._build(args, formatter)
+        return self._match(args, formatter)
+
+    def _build(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        inputs = _collect_inputs(args.roots)
+        if not inputs:
+            formatter.output_plain("Error: no .a/.lib/.o/.obj inputs found")
+            return 2
+        try:
+            lib = json.loads(
+                g.analysis.flirt_build_library(
+                    [str(p) for p in inputs],
+                    arch=args.arch,
+                    pattern_len=args.pattern_len,
+                    min_length=args.min_length,
+                )
+            )
+        except (IOError, ValueError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        out = Path(args.output)
+        out.parent.mkdir(parents=True, exist_ok=True)
+        out.write_text(json.dumps(lib, indent=2, sort_keys=True))
+        stats = lib["stats"]
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"output": str(out), "arch": lib["arch"], **stats})
+            return 0
+        formatter.output_plain(
+            f"wrote {out}: {stats['unique_signatures']} signatures from "
+            f"{stats['functions']} functions in {stats['objects']} objects "
+            f"(too_short={stats['too_short']} ambiguous={stats['dropped_ambiguous']} "
+            f"other_arch={stats['skipped_arch']})"
+        )
+        return 0
+
+    def _match(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        try:
+            hits = json.loads(g.analysis.flirt_match_path(str(path), libraries=args.sig))
+        except (IOError, ValueError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"path": str(path), "matches": hits})
+            return 0
+        for hit in hits:
+            formatter.output_plain(f"{hit['va']:#x}  {hit['name']}")
+        return 0
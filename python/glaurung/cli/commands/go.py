@@ -0,0 +1,108 @@
+"""Go runtime metadata CLI subcommand.
+
+`glaurung go <path>` recovers what a Go binary keeps for its runtime even
+when stripped: pclntab function names with their source file and line
+(and, with `--lines`, the per-instruction line rows), the build
+information (toolchain, main module, dependencies, build settings), the
+packages the functions belong to, and the type names reachable from the
+runtime type descriptors.
+"""
+
+import argparse
+import json
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+def _is_std(pkg: str) -> bool:
+    # Standard-library import paths have no dot in their first element.
+    return pkg != "main" and "." not in pkg.split("/")[0]
+
+
+def _module(m: dict) -> str:
+    ref = f"{m['path']}@{m['version']}"
+    if m.get("replace"):
+        ref += f" => {m['replace']['path']}@{m['replace']['version']}"
+    return ref
+
+
+class GoCommand(BaseCommand):
+    """Recover functions, lines, build info, packages and types from Go binaries."""
+
+    def get_name(self) -> str:
+        return "go"
+
+    def get_help(self) -> str:
+        return "Recover Go functions, source lines, build info, packages and types"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to the Go ELF, PE or Mach-O file")
+        parser.add_argument(
+            "--show",
+            choices=("summary", "functions", "packages", "deps", "types", "files"),
+            default="summary",
+            help="What to list (default: summary)",
+        )
+        parser.add_argument(
+            "--lines",
+            action="store_true",
+            help="Include per-instruction line rows (JSON output)",
+        )
+        parser.add_argument(
+            "--all",
+            action="store_true",
+            help="Include standard-library packages and functions",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        try:
+            meta = json.loads(g.analysis.go_metadata_path(str(path), lines=args.lines))
+        except (ValueError, RuntimeError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"path": str(path), **meta})
+            return 0
+        build = meta.get("build") or {}
+        if args.show == "summary":
+            main = build.get("main")
+            formatter.output_plain(f"go version: {build.get('go_version', '?')}")
+            formatter.output_plain(f"main:       {build.get('path') or '?'}")
+            if main:
+                formatter.output_plain(f"module:     {_module(main)}")
+            formatter.output_plain(f"deps:       {len(build.get('deps', []))}")
+            for key, value in build.get("settings", []):
+                formatter.output_plain(f"  {key}={value}")
+            formatter.output_plain(
+                f"functions={len(meta['functions'])} files={len(meta['source_files'])} "
+                f"packages={len(meta['packages'])} types={len(meta['types'])}"
+            )
+        elif args.show == "functions":
+            ours = tuple(p + "." for p in meta["packages"] if not _is_std(p))
+            for f in meta["functions"]:
+                if not args.all and not f["name"].startswith(ours):
+                    continue
+                where = f"  {f['file']}:{f['line']}" if f.get("file") else ""
+                formatter.output_plain(f"{f['entry_va']:#x}  {f['name']}{where}")
+        elif args.show == "packages":
+            for pkg in meta["packages"]:
+                if args.all or not _is_std(pkg):
+                    formatter.output_plain(pkg)
+        elif args.show == "deps":
+            for dep in build.get("deps", []):
+                formatter.output_plain(_module(dep))
+        elif args.show == "types":
+            for name in meta["types"]:
+                formatter.output_plain(name)
+        else:
+            for name in meta["source_files"]:
+                formatter.output_plain(name)
+        return 0
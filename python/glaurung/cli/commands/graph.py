@@ -1,4 +1,9 @@
-"""Graph export command — DOT/GraphViz output for callgraphs and CFGs (#167)."""
+"""Graph export command — DOT/GraphViz output for callgraphs and CFGs (#167).
+
+With `--format json` the same graphs come out as sorted, name-keyed JSON
+(call edges merged per caller/callee/kind with their call sites), so two
+versions of a sample can be compared with any JSON or text diff.
+"""
 
 from __future__ import annotations
 
@@ -15,12 +20,19 @@ def _dot_escape(s: str) -> str:
     return s.replace("\\", "\\\\").replace('"', '\\"')
 
 
-def _emit_callgraph_dot(funcs: List, callgraph) -> str:
-    """Render the callgraph as DOT. Maps the (potentially stale) callgraph
-    node names back to the current Function names by entry VA — the
-    callgraph is built before the symbol-rename / DWARF-override passes,
-    so its node strings can be `sub_*` even when the discovered Function
-    has a real name."""
+# DOT edge styles for resolved non-direct calls; direct calls stay solid.
+_CALL_EDGE_STYLE = {
+    "indirect": "style=dashed",
+    "virtual": "style=dotted",
+    "tail": "arrowhead=empty",
+}
+
+
+def _name_fixer(funcs: List):
+    """Map (potentially stale) callgraph node names back to the current
+    Function names by entry VA — the callgraph is built before the
+    symbol-rename / DWARF-override passes, so its node strings can be
+    `sub_*` even when the discovered Function has a real name."""
     # Build sub_<hex> → real_name map.
     name_remap: dict[str, str] = {}
     for f in funcs:
@@ -31,6 +43,14 @@ def _emit_callgraph_dot(funcs: List, callgraph) -> str:
     def fix(n: str) -> str:
         return name_remap.get(n, n)
 
+    return fix
+
+
+def _emit_callgraph_dot(funcs: List, callgraph) -> str:
+    """Render the callgraph as DOT, styling indirect, virtual and tail
+    calls so resolved-but-not-direct edges stand out."""
+    fix = _name_fixer(funcs)
+
     nodes = list(getattr(callgraph, "nodes", []) or [])
     edges = list(getattr(callgraph, "edges", []) or [])
 
@@ -59,7 +79,9 @@ def _emit_callgraph_dot(funcs: List, callgraph) -> str:
         callee = fix(getattr(e, "callee", ""))
         if not caller or not callee:
             continue
-        lines.append(f'  "{_dot_escape(caller)}" -> "{_dot_escape(callee)}";')
+        style = _CALL_EDGE_STYLE.get(e.call_type.value())
+        attr_s = f" [{style}]" if style else ""
+        lines.append(f'  "{_dot_escape(caller)}" -> "{_dot_escape(callee)}"{attr_s};')
 
     lines.append("}")
     return "\n".join(lines) + "\n"
@@ -96,8 +118,68 @@ def _emit_cfg_dot(func) -> str:
     return "\n".join(lines) + "\n"
 
 
+def _demangled(func) -> dict:
+    # Raw names stay the graph keys; the readable form rides alongside.
+    pretty = getattr(func, "demangled_name", None)
+    return {"demangled": pretty} if pretty else {}
+
+
+def _callgraph_json(funcs: List, callgraph) -> dict:
+    """The callgraph as a diff-friendly document: functions sorted by entry
+    VA, edges merged per (caller, callee, kind) and sorted by name."""
+    fix = _name_fixer(funcs)
+    functions = [
+        {
+            "name": f.name,
+            **_demangled(f),
+            "entry_va": int(f.entry_point.value),
+            "blocks": len(f.basic_blocks),
+        }
+        for f in sorted(funcs, key=lambda f: int(f.entry_point.value))
+    ]
+    merged: dict[tuple[str, str, str], dict] = {}
+    for e in getattr(callgraph, "edges", []) or []:
+        caller, callee = fix(e.caller), fix(e.callee)
+        if not caller or not callee:
+            continue
+        kind = e.call_type.value()
+        edge = merged.setdefault(
+            (caller, callee, kind),
+            {"caller": caller, "callee": callee, "kind": kind, "call_sites": set()},
+        )
+        edge["call_sites"].update(int(a.value) for a in e.call_sites)
+        if e.confidence is not None:
+            edge["confidence"] = round(float(e.confidence), 3)
+    edges = []
+    for key in sorted(merged):
+        edge = merged[key]
+        edge["call_sites"] = sorted(edge["call_sites"])
+        edges.append(edge)
+    return {"functions": functions, "edges": edges}
+
+
+def _cfg_json(func) -> dict:
+    """One function's CFG as a diff-friendly document keyed by block id."""
+    blocks = sorted(func.basic_blocks, key=lambda bb: int(bb.start_address.value))
+    return {
+        "function": func.name,
+        **_demangled(func),
+        "entry_va": int(func.entry_point.value),
+        "blocks": [
+            {
+                "id": bb.id,
+                "start": int(bb.start_address.value),
+                "end": int(bb.end_address.value),
+                "instructions": bb.instruction_count,
+                "successors": sorted(bb.successor_ids or []),
+            }
+            for bb in blocks
+        ],
+    }
+
+
 def _resolve_function(funcs: Iterable, target: str):
-    """Resolve `target` to a Function: accepts decimal/hex VA or function name."""
+    """Resolve `target` to a Function: accepts decimal/hex VA, raw or demangled name."""
     if target.startswith("0x") or target.startswith("0X"):
         try:
             va = int(target, 16)
@@ -111,7 +193,7 @@ def _resolve_function(funcs: Iterable, target: str):
     for f in funcs:
         if va is not None and int(f.entry_point.value) == va:
             return f
-        if f.name == target:
+        if target in (f.name, getattr(f, "demangled_name", None)):
             return f
     return None
 
@@ -123,7 +205,7 @@ class GraphCommand(BaseCommand):
         return "graph"
 
     def get_help(self) -> str:
-        return "Export DOT/GraphViz for callgraph or function CFG"
+        return "Export DOT/GraphViz or JSON for callgraph or function CFG"
 
     def add_arguments(self, parser: argparse.ArgumentParser) -> None:
         parser.add_argument("path", help="Path to binary")
@@ -152,7 +234,11 @@ class GraphCommand(BaseCommand):
             formatter.output_plain(f"Error during analysis: {e}")
             return 3
 
+        as_json = formatter.format_type == OutputFormat.JSON
         if args.kind == "callgraph":
+            if as_json:
+                formatter.output_json({"path": str(path), **_callgraph_json(funcs, callgraph)})
+                return 0
             dot = _emit_callgraph_dot(funcs, callgraph)
             formatter.output_plain(dot)
             return 0
@@ -170,6 +256,9 @@ class GraphCommand(BaseCommand):
                     f"Error: {target.name} has no basic blocks (skipped during discovery)"
                 )
                 return 5
+            if as_json:
+                formatter.output_json({"path": str(path), **_cfg_json(target)})
+                return 0
             dot = _emit_cfg_dot(target)
             formatter.output_plain(dot)
             return 0
@@ -1,12 +1,12 @@
 """Lua bytecode triage CLI subcommand (#211).
 
 `glaurung luac <path>` parses a `.luac` (or LuaJIT) file and prints
-its engine kind, format byte, and source filename if recoverable
-from the embedded debug info.
+its engine kind, format byte, source filename if recoverable from the
+embedded debug info, string constants, and one line per function
+prototype. `--constants` adds each function's constant table.
 """
 
 import argparse
-import json
 from pathlib import Path
 
 import glaurung as g
@@ -22,10 +22,15 @@ class LuacCommand(BaseCommand):
         return "luac"
 
     def get_help(self) -> str:
-        return "Recognise Lua bytecode (.luac / LuaJIT) and surface header info"
+        return "Parse Lua bytecode (.luac / LuaJIT): functions, constants, strings"
 
     def add_arguments(self, parser: argparse.ArgumentParser) -> None:
         parser.add_argument("path", help="Path to .luac or LuaJIT bytecode")
+        parser.add_argument(
+            "--constants",
+            action="store_true",
+            help="List every function's constant table",
+        )
 
     def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
         path = Path(args.path)
@@ -45,4 +50,20 @@ class LuacCommand(BaseCommand):
             formatter.output_plain(f"source: {info['source']}")
         else:
             formatter.output_plain("source: (stripped)")
+        formatter.output_plain(f"strings: {len(info['strings'])}")
+        for s in info["strings"]:
+            formatter.output_plain(f"  {s!r}")
+        formatter.output_plain(f"functions: {len(info['functions'])}")
+        for i, fn in enumerate(info["functions"]):
+            where = "main" if fn["parent"] is None else f"line {fn['line_defined']}"
+            vararg = ", vararg" if fn["is_vararg"] else ""
+            formatter.output_plain(
+                f"  [{i}] {where} ({fn['num_params']} params{vararg}, "
+                f"{fn['num_instructions']} instructions, "
+                f"{len(fn['constants'])} constants, "
+                f"{fn['num_upvalues']} upvalues)"
+            )
+            if args.constants:
+                for k in fn["constants"]:
+                    formatter.output_plain(f"    {k}")
         return 0
@@ -32,7 +32,7 @@ class PeCommand(BaseCommand):
         return "pe"
 
     def get_help(self) -> str:
-        return "Inspect Windows PE/COFF resources and metadata"
+        return "Inspect Windows PE/COFF resources, debug directory and metadata"
 
     def add_arguments(self, parser: argparse.ArgumentParser) -> None:
         subparsers = parser.add_subparsers(
@@ -50,6 +50,10 @@ class PeCommand(BaseCommand):
         version = subparsers.add_parser("version", help="Decode VERSIONINFO")
         self._add_common_child_arguments(version)
         version.add_argument("--max-payload-bytes", type=int, default=65_536)
+        debug = subparsers.add_parser(
+            "debug", help="Summarize the debug directory and PDB path leaks"
+        )
+        self._add_common_child_arguments(debug)
 
     def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
         if args.pe_action == "resources":
@@ -85,6 +89,16 @@ class PeCommand(BaseCommand):
             else:
                 formatter.output_plain(_format_version_human(result))
             return 0 if result.found else 4
+        if args.pe_action == "debug":
+            path = self.validate_file_path(args.path)
+            payload = _debug_info(path, args)
+            if formatter.format_type == OutputFormat.JSON:
+                formatter.output_json(payload)
+            elif formatter.format_type == OutputFormat.JSONL:
+                formatter.output_jsonl(payload)
+            else:
+                formatter.output_plain(_format_debug_human(payload))
+            return 0 if payload["found"] else 4
         raise ValueError(f"unsupported PE action: {args.pe_action}")
 
     def _add_common_child_arguments(self, parser: argparse.ArgumentParser) -> None:
@@ -226,6 +240,55 @@ def _decode_version(path: Path, args: argparse.Namespace) -> PeVersionInfoResult
     )
 
 
+def _debug_info(path: Path, args: argparse.Namespace) -> dict:
+    artifact = g.triage.analyze_path(
+        str(path),
+        int(args.max_read_bytes),
+        int(args.max_file_size),
+        1,
+    )
+    pe = getattr(artifact.format_specific, "pe", None)
+    debug = pe.debug if pe is not None else None
+    if debug is None:
+        return {"path": str(path), "found": False}
+    return {
+        "path": str(path),
+        "found": True,
+        "entries": list(debug.entries),
+        "pdb_path": debug.pdb_path,
+        "pdb_path_offset": debug.pdb_path_offset,
+        "pdb_guid_age": debug.pdb_guid_age,
+        "pogo_signature": debug.pogo_signature,
+        "pogo_sections": list(debug.pogo_sections),
+        "repro": debug.repro,
+        "repro_hash": debug.repro_hash,
+        "leaks": [{"kind": leak.kind, "value": leak.value} for leak in debug.leaks],
+    }
+
+
+def _format_debug_human(payload: dict) -> str:
+    lines = [f"# PE debug directory: {Path(payload['path']).name}"]
+    if not payload["found"]:
+        lines.append("no debug directory")
+        return "\n".join(lines)
+    lines.append(f"entries: {', '.join(payload['entries'])}")
+    if payload["pdb_path"]:
+        lines.append(f"pdb: {payload['pdb_path']}")
+        lines.append(f"guid_age: {payload['pdb_guid_age']}")
+    if payload["pogo_signature"]:
+        lines.append(
+            f"pogo: {payload['pogo_signature']} "
+            f"({len(payload['pogo_sections'])} section contributions)"
+        )
+    if payload["repro"]:
+        lines.append(f"repro: {payload['repro_hash'] or 'deterministic build'}")
+    if payload["leaks"]:
+        lines.append("leaks:")
+        for leak in payload["leaks"]:
+            lines.append(f"  {leak['kind']}: {leak['value']}")
+    return "\n".join(lines)
+
+
 def _format_resources_human(result: PeListResourcesResult) -> str:
     lines = [
         f"# PE resources: {Path(result.path).name}",
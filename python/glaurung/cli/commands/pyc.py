@@ -0,0 +1,63 @@
+"""Python bytecode triage CLI subcommand.
+
+`glaurung pyc <path>` parses a CPython `.pyc` (3.6-3.13, including files
+extracted from PyInstaller bundles) and prints its version, imported
+modules, string constants, and code objects. `--disasm` adds a
+`dis`-style listing of every code object.
+"""
+
+import argparse
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+class PycCommand(BaseCommand):
+    """Inspect a CPython bytecode file."""
+
+    def get_name(self) -> str:
+        return "pyc"
+
+    def get_help(self) -> str:
+        return "Parse CPython .pyc files: imports, strings, and disassembly"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to a .pyc file")
+        parser.add_argument(
+            "--disasm",
+            action="store_true",
+            help="Disassemble every code object",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        info = g.analysis.parse_pyc_path(str(path), disassemble=args.disasm)
+        if info is None:
+            formatter.output_plain(f"Error: not Python bytecode: {path}")
+            return 4
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json(info)
+            return 0
+        formatter.output_plain(f"python: {info['version']}")
+        formatter.output_plain(f"source: {info['filename']}")
+        formatter.output_plain(f"imports: {len(info['imports'])}")
+        for name in info["imports"]:
+            formatter.output_plain(f"  {name}")
+        formatter.output_plain(f"strings: {len(info['strings'])}")
+        for s in info["strings"]:
+            formatter.output_plain(f"  {s!r}")
+        formatter.output_plain(f"code objects: {len(info['code_objects'])}")
+        for code in info["code_objects"]:
+            formatter.output_plain(
+                f"  {code['qualname']} (line {code['first_line']}, "
+                f"{code['bytecode_size']} bytes)"
+            )
+            for line in code.get("instructions", []):
+                formatter.output_plain(f"    {line}")
+        return 0
@@ -0,0 +1,230 @@
+"""Stored-report maintenance CLI subcommands.
+
+`glaurung report migrate <files...>` upgrades triage reports written by
+older schema versions to the current one, so corpus databases built over
+months stay loadable after upstream schema changes. Both single-report
+`.json` files and one-report-per-line `.jsonl` files are accepted.
+
+`glaurung report build-ids <files...>` indexes the same corpus by build
+identifier (GNU build-id, Mach-O UUID, PE CodeView GUID+age, Go build ID),
+listing which reports describe the same build; `--id` looks one up.
+"""
+
+import argparse
+import json
+import os
+import sys
+import tempfile
+from pathlib import Path
+from typing import Any, Dict, Iterator, List, Tuple
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+def _migrate_text(text: str, jsonl: bool) -> Tuple[str, int, List[str]]:
+    """Migrate one file's contents.
+
+    Returns the new text, the number of reports changed, and the distinct
+    steps applied.
+    """
+    import glaurung as g
+
+    changed = 0
+    steps: List[str] = []
+    if jsonl:
+        out_lines = []
+        for lineno, line in enumerate(text.splitlines(), start=1):
+            if not line.strip():
+                out_lines.append(line)
+                continue
+            try:
+                new, applied = g.triage.migrate_report(line)
+            except ValueError as e:
+                raise ValueError(f"line {lineno}: {e}") from None
+            out_lines.append(new if applied else line)
+            if applied:
+                changed += 1
+                steps.extend(s for s in applied if s not in steps)
+        body = "\n".join(out_lines)
+        return (body + "\n" if text.endswith("\n") else body), changed, steps
+    new, applied = g.triage.migrate_report(text)
+    if not applied:
+        return text, 0, []
+    # Re-indent so migrated single-report files stay readable.
+    return json.dumps(json.loads(new), indent=2) + "\n", 1, list(applied)
+
+
+def _iter_reports(path: Path) -> Iterator[Tuple[str, Dict[str, Any]]]:
+    """Yield `(location, report)` for each report stored in `path`."""
+    text = path.read_text(encoding="utf-8")
+    if path.suffix != ".jsonl":
+        yield str(path), json.loads(text)
+        return
+    for lineno, line in enumerate(text.splitlines(), start=1):
+        if line.strip():
+            yield f"{path}:{lineno}", json.loads(line)
+
+
+def _id_key(value: str) -> str:
+    """Comparison key for user-typed identifiers (case, dashes, braces)."""
+    return "".join(c for c in value.lower() if c not in "-{}")
+
+
+def _index_build_ids(
+    paths: List[Path],
+) -> Tuple[Dict[Tuple[str, str], List[str]], List[Dict[str, str]]]:
+    """Map `(kind, value)` to the reports carrying it.
+
+    Reports are named by the analysed file's path, falling back to where the
+    report is stored. Unreadable files are returned as errors.
+    """
+    index: Dict[Tuple[str, str], List[str]] = {}
+    errors: List[Dict[str, str]] = []
+    for path in paths:
+        try:
+            for where, report in _iter_reports(path):
+                name = report.get("path") or where
+                for bid in report.get("build_ids") or []:
+                    key = (bid.get("kind", ""), bid.get("value", ""))
+                    if name not in index.setdefault(key, []):
+                        index[key].append(name)
+        except (OSError, ValueError) as e:
+            errors.append({"path": str(path), "error": str(e)})
+    return index, errors
+
+
+def _write_atomic(path: Path, text: str) -> None:
+    fd, tmp = tempfile.mkstemp(dir=path.parent, prefix=f".{path.name}.")
+    try:
+        with os.fdopen(fd, "w", encoding="utf-8") as f:
+            f.write(text)
+        os.replace(tmp, path)
+    except BaseException:
+        Path(tmp).unlink(missing_ok=True)
+        raise
+
+
+class ReportCommand(BaseCommand):
+    """Maintain stored triage reports."""
+
+    def get_name(self) -> str:
+        return "report"
+
+    def get_help(self) -> str:
+        return "Maintain and query stored triage reports (schema migration, build IDs)"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument(
+            "action", choices=("migrate", "build-ids"),
+            help=(
+                "`migrate`: upgrade reports to the current schema version; "
+                "`build-ids`: group reports by build identifier"
+            ),
+        )
+        parser.add_argument(
+            "paths", nargs="+", type=Path,
+            help="Report files (.json, or .jsonl with one report per line)",
+        )
+        mode = parser.add_mutually_exclusive_group()
+        mode.add_argument(
+            "--in-place", action="store_true",
+            help="Rewrite each file with the migrated reports",
+        )
+        mode.add_argument(
+            "--output", "-o", type=Path, default=None,
+            help="Write the migrated report here (single input only)",
+        )
+        mode.add_argument(
+            "--check", action="store_true",
+            help="Only report which files need migrating; exit 1 if any do",
+        )
+        parser.add_argument(
+            "--id", dest="build_id", default=None,
+            help="build-ids: only reports carrying this identifier; exit 1 if none",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        if args.action == "build-ids":
+            return self._build_ids(args, formatter)
+        if args.output is not None and len(args.paths) != 1:
+            formatter.output_plain("Error: --output takes exactly one input file")
+            return 2
+
+        results = []
+        failed = False
+        for path in args.paths:
+            try:
+                text = path.read_text(encoding="utf-8")
+                new, changed, steps = _migrate_text(text, path.suffix == ".jsonl")
+            except (OSError, ValueError) as e:
+                failed = True
+                results.append({"path": str(path), "error": str(e)})
+                continue
+            if args.output is not None:
+                _write_atomic(args.output, new)
+            elif args.in_place and changed:
+                _write_atomic(path, new)
+            elif not args.in_place and not args.check:
+                formatter.output_plain(new.rstrip("\n"))
+            results.append({"path": str(path), "migrated": changed, "steps": steps})
+
+        needs_migration = any(r.get("migrated") for r in results)
+        to_stdout = not (args.in_place or args.check or args.output is not None)
+        if to_stdout:
+            # The migrated reports are the output; only surface failures.
+            for r in results:
+                if "error" in r:
+                    print(f"{r['path']}: error: {r['error']}", file=sys.stderr)
+        elif formatter.format_type == OutputFormat.JSON:
+            formatter.output_json(results)
+        else:
+            for r in results:
+                if "error" in r:
+                    formatter.output_plain(f"{r['path']}: error: {r['error']}")
+                elif r["migrated"]:
+                    verb = "needs migration" if args.check else "migrated"
+                    formatter.output_plain(
+                        f"{r['path']}: {verb} ({r['migrated']} report(s))"
+                    )
+                    for step in r["steps"]:
+                        formatter.output_plain(f"  {step}")
+                else:
+                    formatter.output_plain(f"{r['path']}: up to date")
+
+        if failed:
+            return 3
+        if args.check and needs_migration:
+            return 1
+        return 0
+
+    def _build_ids(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        index, errors = _index_build_ids(args.paths)
+        groups = sorted(index.items(), key=lambda kv: (-len(kv[1]), kv[0]))
+        if args.build_id is not None:
+            want = _id_key(args.build_id)
+            groups = [(k, v) for k, v in groups if _id_key(k[1]) == want]
+
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json(
+                {
+                    "build_ids": [
+                        {"kind": kind, "value": value, "reports": reports}
+                        for (kind, value), reports in groups
+                    ],
+                    "errors": errors,
+                }
+            )
+        else:
+            for e in errors:
+                formatter.output_plain(f"{e['path']}: error: {e['error']}")
+            for (kind, value), reports in groups:
+                formatter.output_plain(f"{value} [{kind}]: {len(reports)} report(s)")
+                for r in reports:
+                    formatter.output_plain(f"  {r}")
+
+        if errors:
+            return 3
+        if args.build_id is not None and not groups:
+            return 1
+        return 0
@@ -0,0 +1,408 @@
+"""Long-running export server.
+
+`glaurung serve [--host H] [--port P] [--root DIR] [--grpc-port G]` answers
+the same queries as `glaurung export-program` over HTTP, and over gRPC when
+``--grpc-port`` is given, so pipelines and other RE tools can fetch results
+without shelling out and re-parsing text:
+
+    GET  /v1/health                         {"status": "ok", "version": ...}
+    GET  /v1/formats                        export formats and media types
+    POST /v1/program?format=F&name=N        export the request body
+    GET  /v1/program?path=P&format=F        export a file under --root
+
+`format` defaults to ``json``; ``protobuf`` answers with a
+``glaurung.program.v1.Program`` message (schema in
+``proto/glaurung/program/v1/program.proto``). The gRPC endpoint serves that
+file's ``ProgramService.GetProgram`` and needs the optional ``grpcio``
+package. Path lookups are refused unless ``--root`` is given and never
+resolve outside it. Recent results are cached in memory by content SHA-256,
+name and format.
+"""
+
+import argparse
+import hashlib
+import json
+import threading
+from collections import OrderedDict
+from concurrent.futures import ThreadPoolExecutor
+from http import HTTPStatus
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Any, Optional, Tuple
+from urllib.parse import parse_qs, urlparse
+
+import glaurung as g
+
+from .base import BaseCommand
+from .export_program import FORMATS
+from .. import cache as _cache
+from ..formatters.base import BaseFormatter, OutputFormat
+
+GRPC_SERVICE = "glaurung.program.v1.ProgramService"
+
+
+class ExportError(Exception):
+    """A request the server refuses, with the HTTP status to answer."""
+
+    def __init__(self, status: HTTPStatus, message: str) -> None:
+        super().__init__(message)
+        self.status = status
+
+
+class Exporter:
+    """Runs exports for the server and keeps the most recent ones."""
+
+    def __init__(
+        self,
+        root: Optional[Path] = None,
+        max_functions: int = 0,
+        timeout_ms: int = 1000,
+        max_body: int = 104_857_600,
+        cache_entries: int = 64,
+    ) -> None:
+        self.root = root.resolve() if root is not None else None
+        self.max_functions = max_functions
+        self.timeout_ms = timeout_ms
+        self.max_body = max_body
+        self.cache_entries = cache_entries
+        self._cache: "OrderedDict[Tuple[str, str, str], bytes]" = OrderedDict()
+        self._lock = threading.Lock()
+
+    def resolve(self, rel: str) -> Path:
+        """Map a request path onto a file under the root."""
+        if self.root is None:
+            raise ExportError(
+                HTTPStatus.FORBIDDEN, "path lookups are disabled; start with --root"
+            )
+        target = (self.root / rel).resolve()
+        if not target.is_relative_to(self.root):
+            raise ExportError(
+                HTTPStatus.FORBIDDEN, f"path escapes the server root: {rel}"
+            )
+        if not target.is_file():
+            raise ExportError(HTTPStatus.NOT_FOUND, f"no such file: {rel}")
+        if target.stat().st_size > self.max_body:
+            raise ExportError(
+                HTTPStatus.REQUEST_ENTITY_TOO_LARGE, f"file too large: {rel}"
+            )
+        return target
+
+    def export(self, data: bytes, name: str, fmt: str) -> bytes:
+        if fmt not in FORMATS:
+            raise ExportError(HTTPStatus.BAD_REQUEST, f"unknown format '{fmt}'")
+        # The export records the file name, so it is part of the key.
+        key = (hashlib.sha256(data).hexdigest(), name, fmt)
+        with self._lock:
+            hit = self._cache.get(key)
+            if hit is not None:
+                self._cache.move_to_end(key)
+                return hit
+        body = g.analysis.export_program_bytes(
+            data,
+            format=fmt,
+            name=name,
+            max_functions=self.max_functions,
+            timeout_ms=self.timeout_ms,
+        )
+        with self._lock:
+            self._cache[key] = body
+            while len(self._cache) > self.cache_entries:
+                self._cache.popitem(last=False)
+        return body
+
+
+class _Handler(BaseHTTPRequestHandler):
+    server_version = "glaurung-serve"
+    exporter: Exporter  # set on the subclass `make_server` builds
+
+    def log_message(self, format: str, *args) -> None:  # noqa: A002
+        pass
+
+    def _send(self, status: HTTPStatus, body: bytes, media_type: str) -> None:
+        self.send_response(status)
+        self.send_header("Content-Type", media_type)
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def _send_json(self, status: HTTPStatus, payload: dict) -> None:
+        self._send(status, json.dumps(payload).encode(), "application/json")
+
+    def _dispatch(self, method: str) -> None:
+        url = urlparse(self.path)
+        query = {k: v[-1] for k, v in parse_qs(url.query).items()}
+        try:
+            if method == "GET" and url.path == "/v1/health":
+                version = _cache.get_glaurung_version()
+                self._send_json(HTTPStatus.OK, {"status": "ok", "version": version})
+            elif method == "GET" and url.path == "/v1/formats":
+                self._send_json(HTTPStatus.OK, {"formats": FORMATS})
+            elif url.path == "/v1/program":
+                fmt = query.get("format", "json")
+                if method == "POST":
+                    data = self._read_body()
+                    name = query.get("name", "input")
+                else:
+                    if "path" not in query:
+                        raise ExportError(
+                            HTTPStatus.BAD_REQUEST, "missing 'path' query parameter"
+                        )
+                    target = self.exporter.resolve(query["path"])
+                    data, name = target.read_bytes(), target.name
+                body = self.exporter.export(data, name, fmt)
+                self._send(HTTPStatus.OK, body, FORMATS[fmt])
+            else:
+                raise ExportError(
+                    HTTPStatus.NOT_FOUND, f"no route for {method} {url.path}"
+                )
+        except ExportError as e:
+            self._send_json(e.status, {"error": str(e)})
+        except (ValueError, OSError) as e:
+            self._send_json(HTTPStatus.UNPROCESSABLE_ENTITY, {"error": str(e)})
+        except Exception as e:  # noqa: BLE001 — never drop the connection
+            self._send_json(
+                HTTPStatus.INTERNAL_SERVER_ERROR, {"error": f"internal error: {e}"}
+            )
+
+    def _read_body(self) -> bytes:
+        try:
+            length = int(self.headers.get("Content-Length") or 0)
+        except ValueError:
+            length = -1
+        if length < 0:
+            raise ExportError(HTTPStatus.BAD_REQUEST, "invalid Content-Length")
+        if length > self.exporter.max_body:
+            raise ExportError(
+                HTTPStatus.REQUEST_ENTITY_TOO_LARGE, "request body too large"
+            )
+        data = self.rfile.read(length)
+        if not data:
+            raise ExportError(HTTPStatus.BAD_REQUEST, "empty request body")
+        return data
+
+    def do_GET(self) -> None:  # noqa: N802 — stdlib naming
+        self._dispatch("GET")
+
+    def do_POST(self) -> None:  # noqa: N802 — stdlib naming
+        self._dispatch("POST")
+
+
+def make_server(host: str, port: int, exporter: Exporter) -> ThreadingHTTPServer:
+    """Bind the export server; `port` 0 picks a free port."""
+    handler = type("Handler", (_Handler,), {"exporter": exporter})
+    return ThreadingHTTPServer((host, port), handler)
+
+
+def _varint(buf: bytes, pos: int) -> Tuple[int, int]:
+    value = shift = 0
+    while shift < 64:
+        if pos >= len(buf):
+            raise ValueError("truncated varint")
+        byte = buf[pos]
+        pos += 1
+        value |= (byte & 0x7F) << shift
+        if not byte & 0x80:
+            return value, pos
+        shift += 7
+    raise ValueError("varint longer than 64 bits")
+
+
+def parse_get_program_request(raw: bytes) -> Tuple[Optional[str], Optional[bytes], str]:
+    """`(path, content, name)` from a serialized ``GetProgramRequest``.
+
+    Decoded by hand so the server needs no generated code: fields 1 and 2
+    are the ``source`` oneof, where the last one on the wire wins, and
+    unknown fields are skipped.
+    """
+    path: Optional[str] = None
+    content: Optional[bytes] = None
+    name = ""
+    pos = 0
+    while pos < len(raw):
+        key, pos = _varint(raw, pos)
+        field, wire = key >> 3, key & 7
+        if wire == 0:
+            _, pos = _varint(raw, pos)
+            continue
+        if wire == 1:
+            size = 8
+        elif wire == 5:
+            size = 4
+        elif wire == 2:
+            size, pos = _varint(raw, pos)
+        else:
+            raise ValueError(f"unsupported wire type {wire}")
+        if pos + size > len(raw):
+            raise ValueError("truncated field")
+        value, pos = raw[pos : pos + size], pos + size
+        if wire != 2:
+            continue
+        if field == 1:
+            path, content = value.decode(), None
+        elif field == 2:
+            path, content = None, value
+        elif field == 3:
+            name = value.decode()
+    return path, content, name
+
+
+def make_grpc_server(host: str, port: int, exporter: Exporter) -> Tuple[Any, int]:
+    """Bind ``ProgramService`` and return the server and its port.
+
+    `port` 0 picks a free port. Raises ImportError without ``grpcio``.
+    """
+    import grpc
+
+    codes = {
+        HTTPStatus.BAD_REQUEST: grpc.StatusCode.INVALID_ARGUMENT,
+        HTTPStatus.FORBIDDEN: grpc.StatusCode.PERMISSION_DENIED,
+        HTTPStatus.NOT_FOUND: grpc.StatusCode.NOT_FOUND,
+        HTTPStatus.REQUEST_ENTITY_TOO_LARGE: grpc.StatusCode.RESOURCE_EXHAUSTED,
+    }
+
+    def export(raw: bytes) -> bytes:
+        path, content, name = parse_get_program_request(raw)
+        if content is not None:
+            if not content:
+                raise ExportError(HTTPStatus.BAD_REQUEST, "empty content")
+            return exporter.export(content, name or "input", "protobuf")
+        if path is None:
+            raise ExportError(HTTPStatus.BAD_REQUEST, "request has no path or content")
+        target = exporter.resolve(path)
+        return exporter.export(target.read_bytes(), target.name, "protobuf")
+
+    # Requests arrive, and the Program leaves, as serialized bytes.
+    def get_program(raw: bytes, context: Any) -> bytes:
+        try:
+            return export(raw)
+        except ExportError as e:
+            code, message = codes.get(e.status, grpc.StatusCode.UNKNOWN), str(e)
+        except (ValueError, OSError) as e:
+            code, message = grpc.StatusCode.INVALID_ARGUMENT, str(e)
+        except Exception as e:  # noqa: BLE001 — same contract as the HTTP side
+            code, message = grpc.StatusCode.INTERNAL, f"internal error: {e}"
+        return context.abort(code, message)  # raises
+
+    handler = grpc.method_handlers_generic_handler(
+        GRPC_SERVICE,
+        {"GetProgram": grpc.unary_unary_rpc_method_handler(get_program)},
+    )
+    # Leave room for the request's other fields around the uploaded bytes.
+    limit = exporter.max_body + 1024
+    server = grpc.server(
+        ThreadPoolExecutor(max_workers=8),
+        handlers=(handler,),
+        options=[
+            ("grpc.max_receive_message_length", limit),
+            ("grpc.max_send_message_length", -1),
+        ],
+    )
+    bound = server.add_insecure_port(f"{host}:{port}")
+    return server, bound
+
+
+class ServeCommand(BaseCommand):
+    """Serve program-model exports over HTTP and, optionally, gRPC."""
+
+    def get_name(self) -> str:
+        return "serve"
+
+    def get_help(self) -> str:
+        return (
+            "Serve program-model exports (JSON, Ghidra XML, SARIF, protobuf) "
+            "over HTTP and gRPC"
+        )
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument(
+            "--host", default="127.0.0.1", help="Bind address (default: 127.0.0.1)"
+        )
+        parser.add_argument(
+            "--port", type=int, default=8765, help="Port (default: 8765; 0 picks one)"
+        )
+        parser.add_argument(
+            "--grpc-port",
+            type=int,
+            default=None,
+            help="Also serve ProgramService over gRPC on this port (0 picks one; "
+            "needs grpcio)",
+        )
+        parser.add_argument(
+            "--root",
+            default=None,
+            help="Directory GET /v1/program?path= may read from "
+            "(default: path lookups disabled)",
+        )
+        parser.add_argument(
+            "--max-functions",
+            type=int,
+            default=0,
+            help="Cap on discovered functions per export (default: 0, no cap)",
+        )
+        parser.add_argument(
+            "--timeout-ms",
+            type=int,
+            default=1000,
+            help="Function-discovery time budget per export (default: 1000)",
+        )
+        parser.add_argument(
+            "--max-body",
+            type=int,
+            default=104_857_600,
+            help="Largest upload or file served, in bytes (default: 100MB)",
+        )
+        parser.add_argument(
+            "--cache-entries",
+            type=int,
+            default=64,
+            help="Exports kept in memory (default: 64)",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        root = Path(args.root) if args.root else None
+        if root is not None and not root.is_dir():
+            formatter.output_plain(f"Error: not a directory: {root}")
+            return 2
+        exporter = Exporter(
+            root=root,
+            max_functions=args.max_functions,
+            timeout_ms=args.timeout_ms,
+            max_body=args.max_body,
+            cache_entries=args.cache_entries,
+        )
+        grpc_server, grpc_url = None, None
+        if args.grpc_port is not None:
+            try:
+                grpc_server, grpc_port = make_grpc_server(
+                    args.host, args.grpc_port, exporter
+                )
+            except ImportError:
+                formatter.output_plain("Error: --grpc-port needs the grpcio package")
+                return 2
+            grpc_url = f"{args.host}:{grpc_port}"
+        server = make_server(args.host, args.port, exporter)
+        host, port = server.server_address[:2]
+        if formatter.format_type in (OutputFormat.JSON, OutputFormat.JSONL):
+            formatter.output_json(
+                {
+                    "url": f"http://{host}:{port}",
+                    "grpc": grpc_url,
+                    "root": str(exporter.root) if root else None,
+                }
+            )
+        else:
+            grpc_note = f", gRPC on {grpc_url}" if grpc_url else ""
+            formatter.output_plain(
+                f"serving on http://{host}:{port}{grpc_note} (Ctrl-C to stop)"
+            )
+        if grpc_server is not None:
+            grpc_server.start()
+        try:
+            server.serve_forever()
+        except KeyboardInterrupt:
+            pass
+        finally:
+            server.server_close()
+            if grpc_server is not None:
+                grpc_server.stop(None)
+        return 0
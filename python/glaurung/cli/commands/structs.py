@@ -0,0 +1,74 @@
+"""Struct recovery CLI subcommand.
+
+`glaurung structs <path>` infers composite types from how the binary's
+functions access memory: `[ptr + k]` loads and stores on argument and
+allocator-returned pointers become fields, constant allocation sizes fix
+the struct size, RTTI vtable stores name C++ classes, and a matching DWARF
+structure supplies real member names. Output is a C header of the
+recovered declarations.
+"""
+
+import argparse
+import json
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+def _summary(s: dict) -> str:
+    parts = [f"size {s['size']:#x}", f"confidence {s['confidence']:.2f}"]
+    if s["alloc_size"] is not None:
+        parts.append(f"alloc {s['alloc_size']:#x}")
+    if s["class"]:
+        parts.append(f"class {s['class']}")
+    if s["dwarf"]:
+        parts.append("dwarf")
+    funcs = ", ".join(f"{va:#x}" for va in s["functions"][:4])
+    more = ", ..." if len(s["functions"]) > 4 else ""
+    parts.append(f"used by {funcs}{more}")
+    return f"/* {s['name']}: {'; '.join(parts)} */"
+
+
+class StructsCommand(BaseCommand):
+    """Recover struct layouts from field access patterns."""
+
+    def get_name(self) -> str:
+        return "structs"
+
+    def get_help(self) -> str:
+        return "Recover struct layouts from field accesses, allocation sizes, RTTI and DWARF"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to the ELF, PE or Mach-O file")
+        parser.add_argument(
+            "--min-confidence",
+            type=float,
+            default=0.0,
+            help="Only print structs at or above this confidence (0.0-1.0)",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        try:
+            structs = json.loads(g.analysis.recover_structs_path(str(path)))
+        except (ValueError, RuntimeError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        structs = [s for s in structs if s["confidence"] >= args.min_confidence]
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json({"path": str(path), "structs": structs})
+            return 0
+        if not structs:
+            formatter.output_plain("no structs recovered")
+            return 1
+        formatter.output_plain("#include <stdint.h>\n")
+        for s in structs:
+            formatter.output_plain(_summary(s))
+            formatter.output_plain(s["declaration"] + "\n")
+        return 0
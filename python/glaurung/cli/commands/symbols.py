@@ -4,6 +4,7 @@ import argparse
 
 import glaurung as g
 from .base import BaseCommand
+from ..formatters.base import OutputFormat
 from ..formatters.symbols import SymbolsFormatter
 
 
@@ -44,6 +45,11 @@ class SymbolsCommand(BaseCommand):
         parser.add_argument(
             "--limit", type=int, help="Limit number of symbols displayed"
         )
+        parser.add_argument(
+            "--raw",
+            action="store_true",
+            help="Do not demangle C++/Rust/Swift names",
+        )
 
     def execute(self, args: argparse.Namespace, formatter: SymbolsFormatter) -> int:
         """Execute the symbols command."""
@@ -89,12 +95,24 @@ class SymbolsCommand(BaseCommand):
             filtered = {args.filter: data.get(args.filter, [])}
             data = filtered
 
-        # Apply search if specified
+        # raw name -> (demangled, flavor); libs are file names, not symbols
+        demangled = {}
+        if not args.raw:
+            names = sorted({s for k, v in data.items() if k != "libs" for s in v})
+            demangled = {
+                raw: (pretty, flavor)
+                for raw, pretty, flavor in g.strings.demangle_list(names, max=len(names))
+            }
+
+        # Apply search if specified (matches raw or demangled names)
         if args.search:
             search_lower = args.search.lower()
             for key in data:
                 data[key] = [
-                    sym for sym in data[key] if search_lower in str(sym).lower()
+                    sym
+                    for sym in data[key]
+                    if search_lower in str(sym).lower()
+                    or search_lower in demangled.get(sym, ("",))[0].lower()
                 ]
 
         # Apply limit if specified
@@ -102,6 +120,23 @@ class SymbolsCommand(BaseCommand):
             for key in data:
                 data[key] = data[key][: args.limit]
 
+        # JSON keeps the raw lists and adds the pairs; text shows both forms.
+        if demangled:
+            shown = {s for k, v in data.items() if k != "libs" for s in v}
+            if formatter.format_type in (OutputFormat.JSON, OutputFormat.JSONL):
+                data["demangled"] = [
+                    {"name": raw, "demangled": pretty, "flavor": flavor}
+                    for raw, (pretty, flavor) in sorted(demangled.items())
+                    if raw in shown
+                ]
+            else:
+                for key in data:
+                    if key != "libs":
+                        data[key] = [
+                            f"{demangled[s][0]}  [{s}]" if s in demangled else s
+                            for s in data[key]
+                        ]
+
         # Format and output results
         formatter.format_output(data)
 
@@ -1,6 +1,7 @@
 """Triage command implementation."""
 
 import argparse
+import os
 
 import glaurung as g
 from .base import BaseCommand
@@ -37,7 +38,10 @@ class TriageCommand(BaseCommand):
             "--max-depth",
             type=int,
             default=1,
-            help="Max recursion depth for containers",
+            help=(
+                "Max recursion depth; above 1, nested containers are unpacked "
+                "and each child triaged"
+            ),
         )
         parser.add_argument(
             "--sim",
@@ -109,6 +113,38 @@ class TriageCommand(BaseCommand):
             action="store_true",
             help="Filter output to only strings with detected language (JSON/JSONL)",
         )
+        parser.add_argument(
+            "--annotations",
+            default=os.environ.get("GLAURUNG_ANNOTATIONS"),
+            help="Annotation store directory to merge analyst tags/comments/"
+            "verdicts from (default: $GLAURUNG_ANNOTATIONS)",
+        )
+        parser.add_argument(
+            "--min-confidence",
+            default=None,
+            help="Drop verdicts, packer matches, and findings below this "
+            "confidence (0-1 or a band: low, medium, high, certain)",
+        )
+        parser.add_argument(
+            "--severity",
+            action="append",
+            default=[],
+            metavar="KEY=LEVEL",
+            help="Override the severity of findings by rule id or category, "
+            "e.g. memory:wx=critical or packer=low (repeatable)",
+        )
+        parser.add_argument(
+            "--min-severity",
+            default=None,
+            help="Drop findings below this severity "
+            "(info, low, medium, high, critical)",
+        )
+        parser.add_argument(
+            "--fail-on",
+            default=None,
+            metavar="LEVEL",
+            help="Exit with status 1 if any finding is at or above this severity",
+        )
 
     def execute(self, args: argparse.Namespace, formatter: TriageFormatter) -> int:
         """Execute the triage command."""
@@ -119,6 +155,35 @@ class TriageCommand(BaseCommand):
             formatter.output_plain(f"Error: {e}")
             return 2
 
+        min_confidence = None
+        if args.min_confidence is not None:
+            try:
+                min_confidence = g.triage.confidence_threshold(args.min_confidence)
+            except ValueError as e:
+                formatter.output_plain(f"Error: {e}")
+                return 2
+
+        overrides = {}
+        try:
+            for item in args.severity:
+                key, sep, level = item.rpartition("=")
+                if not sep or not key:
+                    raise ValueError(f"expected KEY=LEVEL, got {item!r}")
+                overrides[key] = g.triage.parse_severity(level)
+            min_severity = (
+                g.triage.parse_severity(args.min_severity)
+                if args.min_severity is not None
+                else None
+            )
+            fail_on = (
+                g.triage.parse_severity(args.fail_on)
+                if args.fail_on is not None
+                else None
+            )
+        except ValueError as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+
         # Perform triage analysis
         try:
             artifact = g.triage.analyze_path(
@@ -133,10 +198,15 @@ class TriageCommand(BaseCommand):
                 args.str_classify,
                 args.str_max_classify,
                 args.str_max_ioc_per_string,
+                annotations=args.annotations,
             )
         except Exception as e:
             formatter.output_plain(f"Error during analysis: {e}")
             return 3
+        if overrides:
+            artifact = artifact.with_severity_overrides(overrides)
+        if min_confidence is not None or min_severity is not None:
+            artifact = artifact.filtered(min_confidence or 0.0, min_severity)
 
         # Attach display preference to formatter
         setattr(
@@ -146,4 +216,8 @@ class TriageCommand(BaseCommand):
         # Format and output results
         formatter.format_output(artifact)
 
+        if fail_on is not None and artifact.max_severity is not None:
+            levels = ["info", "low", "medium", "high", "critical"]
+            if levels.index(artifact.max_severity) >= levels.index(fail_on):
+                return 1
         return 0
@@ -0,0 +1,91 @@
+"""Byte-structure image CLI subcommand.
+
+`glaurung visual <path>` renders the file as a byte map laid out along a
+Hilbert curve (or in scan order) and coloured by byte class or entropy,
+with section boundaries outlined, or as a byte digraph. PNG is compact;
+SVG scales cleanly and adds a legend naming each outlined section.
+"""
+
+import argparse
+from pathlib import Path
+
+import glaurung as g
+
+from .base import BaseCommand
+from ..formatters.base import BaseFormatter, OutputFormat
+
+
+class VisualCommand(BaseCommand):
+    """Render a binary as a byte-class/entropy map or digraph image."""
+
+    def get_name(self) -> str:
+        return "visual"
+
+    def get_help(self) -> str:
+        return "Render a byte-class/entropy map or byte digraph as PNG or SVG"
+
+    def add_arguments(self, parser: argparse.ArgumentParser) -> None:
+        parser.add_argument("path", help="Path to the file to render")
+        parser.add_argument(
+            "-o",
+            "--output",
+            help="Image path; .svg selects SVG (default: <name>.<kind>.png here)",
+        )
+        parser.add_argument(
+            "--kind",
+            choices=("hilbert", "scan", "digraph"),
+            default="hilbert",
+            help="Byte map layout, or the byte-pair digraph (default: hilbert)",
+        )
+        parser.add_argument(
+            "--color",
+            choices=("class", "entropy"),
+            default="class",
+            help="Byte-map colouring (default: class)",
+        )
+        parser.add_argument(
+            "--size",
+            type=int,
+            default=256,
+            help="Byte-map side in pixels, rounded up to a power of two (default: 256)",
+        )
+        parser.add_argument(
+            "--scale",
+            type=int,
+            default=2,
+            help="SVG screen pixels per image pixel (default: 2)",
+        )
+        parser.add_argument(
+            "--no-sections",
+            action="store_true",
+            help="Do not outline sections",
+        )
+
+    def execute(self, args: argparse.Namespace, formatter: BaseFormatter) -> int:
+        path = Path(args.path)
+        if not path.exists():
+            formatter.output_plain(f"Error: not found: {path}")
+            return 2
+        out = Path(args.output) if args.output else Path(f"{path.name}.{args.kind}.png")
+        fmt = "svg" if out.suffix.lower() == ".svg" else "png"
+        try:
+            image = g.analysis.render_image_path(
+                str(path),
+                kind=args.kind,
+                coloring=args.color,
+                side=args.size,
+                fmt=fmt,
+                sections=not args.no_sections,
+                scale=args.scale,
+            )
+        except (ValueError, OSError) as e:
+            formatter.output_plain(f"Error: {e}")
+            return 2
+        out.write_bytes(image)
+        if formatter.format_type == OutputFormat.JSON:
+            formatter.output_json(
+                {"path": str(path), "output": str(out), "kind": args.kind, "format": fmt, "bytes": len(image)}
+            )
+        else:
+            formatter.output_plain(f"wrote {out} ({fmt}, {len(image)} bytes)")
+        return 0
@@ -140,6 +140,13 @@ class DisasmFormatter(BaseFormatter):
         engine = data.get("engine", "unknown")
         arch = data.get("arch", "unknown")
         lines.append(f"engine: {engine} arch: {arch}")
+        guess = data.get("metadata", {}).get("arch_guess")
+        if guess:
+            mode = " thumb" if guess.get("thumb") else ""
+            lines.append(
+                f"identified: {guess['arch']}{mode} {guess['endianness']}-endian "
+                f"(confidence {guess['confidence']:.2f})"
+            )
 
         instructions = data.get("instructions", [])
         for inst in instructions:
@@ -534,6 +534,20 @@ class TriageFormatter(BaseFormatter):
         # Print the complete layout
         self.console.print(layout)
 
+    def _unpacked_lines(self, children, indent: int) -> List[str]:
+        """One line per extracted child, indented by nesting level."""
+        lines = []
+        for c in children:
+            fmt = f"{c.format} {c.arch}" if c.arch else c.format
+            severity = f" severity={c.max_severity}" if c.max_severity else ""
+            note = f" ({c.note})" if c.note else ""
+            lines.append(
+                f"{'  ' * indent}{c.name} [{c.source}] {fmt} "
+                f"{human_bytes(c.size)} sha256={c.sha256[:16]}{severity}{note}"
+            )
+            lines.extend(self._unpacked_lines(c.children, indent + 1))
+        return lines
+
     def _format_plain(self, art) -> None:
         """Format output as plain text."""
         lines = []
@@ -559,6 +573,43 @@ class TriageFormatter(BaseFormatter):
             flag_str = f" flags: {','.join(flags)}" if flags else ""
             lines.append(f"symbols: imports={imp} exports={exp} libs={libs}{flag_str}")
 
+        # Build identifiers (one line each, value first for easy grepping)
+        for bid in getattr(art, "build_ids", None) or []:
+            detail = f" ({bid.detail})" if bid.detail else ""
+            lines.append(f"build id: {bid.value} [{bid.kind}]{detail}")
+
+        # What the PE's PDB path gives away about where it was built
+        pe = getattr(getattr(art, "format_specific", None), "pe", None)
+        debug = getattr(pe, "debug", None)
+        if debug is not None and debug.leaks:
+            leaks = ", ".join(f"{leak.kind}={leak.value}" for leak in debug.leaks)
+            lines.append(f"pdb path leaks: {leaks}")
+
+        # Source language classifier verdict
+        lang = getattr(art, "source_language", None)
+        if lang is not None:
+            alts = ", ".join(
+                f"{name} {p:.2f}" for name, p in lang.alternatives if p >= 0.05
+            )
+            alt_str = f" (also {alts})" if alts else ""
+            lines.append(f"source language: {lang.language} {lang.confidence:.2f}{alt_str}")
+
+        # Go toolchain and module graph
+        go = getattr(art, "go", None)
+        if go is not None:
+            main = go.main_module or go.main_package or "?"
+            lines.append(
+                f"go: {go.go_version or '?'} main={main} deps={len(go.dependencies)} "
+                f"functions={go.functions} files={go.source_files} types={go.types}"
+            )
+
+        # Embedded open-source licenses and copyright notices
+        lic = getattr(art, "licenses", None)
+        if lic is not None and lic.licenses:
+            lines.append(f"licenses: {', '.join(lic.spdx_ids())}")
+        for notice in (lic.copyrights if lic is not None else [])[:3]:
+            lines.append(f"copyright: {notice.text}")
+
         # Strings
         strings = getattr(art, "strings", None)
         if strings:
@@ -601,4 +652,25 @@ class TriageFormatter(BaseFormatter):
             if overall is not None:
                 lines.append(f"entropy: overall={overall:.2f}")
 
+        # Children extracted from nested containers (--max-depth > 1)
+        unpacked = getattr(art, "unpacked", None)
+        if unpacked is not None and unpacked.children:
+            stopped = f" stopped={','.join(unpacked.stopped)}" if unpacked.stopped else ""
+            lines.append(
+                f"unpacked: children={unpacked.total_children} "
+                f"bytes={human_bytes(unpacked.total_bytes)} "
+                f"depth={unpacked.max_depth}{stopped}"
+            )
+            lines.extend(self._unpacked_lines(unpacked.children, 1))
+
+        # Analyst annotations (merged from an annotation store)
+        ann = getattr(art, "annotations", None)
+        if ann:
+            verdict = ann.verdict.label if ann.verdict else "(none)"
+            tags = ",".join(ann.tags) if ann.tags else "(none)"
+            lines.append(
+                f"analyst: verdict={verdict} tags={tags} "
+                f"comments={len(ann.comments)} renames={len(ann.function_names)}"
+            )
+
         self.output_plain("\n".join(lines))
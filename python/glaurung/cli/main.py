@@ -5,6 +5,7 @@ import sys
 from typing import List, Optional
 
 from .commands.triage import TriageCommand
+from .commands.batch import BatchCommand
 from .commands.base import BaseCommand
 from .commands.symbols import SymbolsCommand
 from .commands.disasm import DisasmCommand
@@ -22,23 +23,35 @@ from .commands.kickoff import KickoffCommand
 from .commands.patch import PatchCommand
 from .commands.verify_recovery import VerifyRecoveryCommand
 from .commands.export import ExportCommand
+from .commands.export_program import ExportProgramCommand
+from .commands.serve import ServeCommand
 from .commands.undo import UndoCommand, RedoCommand
 from .commands.xrefs import XrefsCommand
 from .commands.frame import FrameCommand
 from .commands.string_xrefs import StringsXrefsCommand
 from .commands.view import ViewCommand
 from .commands.find import FindCommand
+from .commands.firmware import FirmwareCommand
 from .commands.bookmark import BookmarkCommand, JournalCommand
 from .commands.classfile import ClassfileCommand
 from .commands.java import JavaCommand
 from .commands.java_recovery_report import JavaRecoveryReportCommand
 from .commands.luac import LuacCommand
+from .commands.pyc import PycCommand
+from .commands.config import ConfigCommand
+from .commands.structs import StructsCommand
+from .commands.dead import DeadCommand
+from .commands.go import GoCommand
+from .commands.flirt import FlirtCommand
+from .commands.visual import VisualCommand
 from .commands.pe import PeCommand
 from .commands.windows_risk import WindowsRiskCommand
 from .commands.types import TypesCommand
 from .commands.windows import WindowsCommand
 from .commands.locks import LocksCommand
 from .commands.group import GroupCommand
+from .commands.report import ReportCommand
+from .commands.annotate import AnnotateCommand
 
 from .formatters import (
     TriageFormatter,
@@ -60,6 +73,7 @@ class GlaurungCLI:
         """Initialize the CLI with available commands."""
         self.commands: dict[str, BaseCommand] = {
             "triage": TriageCommand(),
+            "batch": BatchCommand(),
             "strings": StringsCommand(),
             "symbols": SymbolsCommand(),
             "disasm": DisasmCommand(),
@@ -76,6 +90,8 @@ class GlaurungCLI:
             "patch": PatchCommand(),
             "verify-recovery": VerifyRecoveryCommand(),
             "export": ExportCommand(),
+            "export-program": ExportProgramCommand(),
+            "serve": ServeCommand(),
             "undo": UndoCommand(),
             "redo": RedoCommand(),
             "xrefs": XrefsCommand(),
@@ -89,12 +105,22 @@ class GlaurungCLI:
             "java": JavaCommand(),
             "java-recovery-report": JavaRecoveryReportCommand(),
             "luac": LuacCommand(),
+            "firmware": FirmwareCommand(),
+            "pyc": PycCommand(),
+            "config": ConfigCommand(),
+            "structs": StructsCommand(),
+            "dead": DeadCommand(),
+            "go": GoCommand(),
+            "flirt": FlirtCommand(),
+            "visual": VisualCommand(),
             "pe": PeCommand(),
             "windows-risk": WindowsRiskCommand(),
             "types": TypesCommand(),
             "windows": WindowsCommand(),
             "locks": LocksCommand(),
             "group": GroupCommand(),
+            "report": ReportCommand(),
+            "annotate": AnnotateCommand(),
         }
 
         # Map commands to their formatters. The REPL is interactive and
@@ -102,6 +128,7 @@ class GlaurungCLI:
         # placeholder — the formatter is never actually consulted.
         self.formatter_map: dict[str, type[BaseFormatter]] = {
             "triage": TriageFormatter,
+            "batch": TriageFormatter,
             "strings": StringsFormatter,
             "symbols": SymbolsFormatter,
             "disasm": DisasmFormatter,
@@ -124,6 +151,8 @@ class GlaurungCLI:
             "patch": TriageFormatter,
             "verify-recovery": TriageFormatter,
             "export": TriageFormatter,
+            "export-program": TriageFormatter,
+            "serve": TriageFormatter,
             "undo": TriageFormatter,
             "redo": TriageFormatter,
             "xrefs": TriageFormatter,
@@ -137,12 +166,22 @@ class GlaurungCLI:
             "java": TriageFormatter,
             "java-recovery-report": TriageFormatter,
             "luac": TriageFormatter,
+            "firmware": TriageFormatter,
+            "pyc": TriageFormatter,
+            "config": TriageFormatter,
+            "structs": TriageFormatter,
+            "dead": TriageFormatter,
+            "go": TriageFormatter,
+            "flirt": TriageFormatter,
+            "visual": TriageFormatter,
             "pe": TriageFormatter,
             "windows-risk": TriageFormatter,
             "types": TriageFormatter,
             "windows": TriageFormatter,
             "locks": TriageFormatter,
             "group": TriageFormatter,
+            "report": TriageFormatter,
+            "annotate": TriageFormatter,
         }
 
     def create_parser(self) -> argparse.ArgumentParser:
@@ -153,6 +192,17 @@ class GlaurungCLI:
 
         # Add global arguments
         parser.add_argument("--version", action="version", version="%(prog)s 0.1.0")
+        parser.add_argument(
+            "--log-level",
+            default=None,
+            help="Native log level or filter (e.g. debug, "
+            "'info,glaurung::analysis::pipeline=trace'); logs go to stderr",
+        )
+        parser.add_argument(
+            "--log-json",
+            action="store_true",
+            help="Emit native logs as JSON lines with file/pass span context",
+        )
 
         # Create subparsers for commands
         subparsers = parser.add_subparsers(
@@ -170,6 +220,15 @@ class GlaurungCLI:
         parser = self.create_parser()
         args = parser.parse_args(argv)
 
+        if args.log_level or args.log_json:
+            import glaurung as g
+
+            try:
+                g.init_logging(json=args.log_json, level=args.log_level)
+            except ValueError as e:
+                print(f"Error: {e}", file=sys.stderr)
+                return 2
+
         # Get the command
         command = self.commands.get(args.cmd)
         if not command:
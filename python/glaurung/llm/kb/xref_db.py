@@ -1152,7 +1152,14 @@ def demangle_function_names(kb: PersistentKnowledgeBase) -> dict:
     except Exception:
         return {"error": "demangle_bridge_unavailable"}
 
-    counts = {"total": 0, "rust": 0, "itanium": 0, "msvc": 0, "unrecognized": 0}
+    counts = {
+        "total": 0,
+        "rust": 0,
+        "itanium": 0,
+        "msvc": 0,
+        "swift": 0,
+        "unrecognized": 0,
+    }
     cur = kb._conn.cursor()
     cur.execute(
         "SELECT entry_va, canonical FROM function_names WHERE binary_id = ?",
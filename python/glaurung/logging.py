@@ -87,7 +87,13 @@ def configure_logging(
 
     # Initialize native Rust logging if available
     if HAS_NATIVE_LOGGING:
-        _init_native_logging(json_output)
+        _init_native_logging(json_output, _native_level(level))
+
+
+def _native_level(level: str) -> str:
+    """Map a Python-style level name onto a Rust tracing filter."""
+    name = level.strip().lower()
+    return {"warning": "warn", "critical": "error", "fatal": "error"}.get(name, name)
 
 
 def get_logger(name: Optional[str] = None) -> structlog.BoundLogger:
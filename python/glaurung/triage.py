@@ -5,7 +5,7 @@ These map directly to the Rust types in `glaurung._native.triage`.
 """
 
 import glaurung._native as _native  # type: ignore
-from typing import Any
+from typing import Any, Optional
 
 # Import triage types from the triage attribute
 SnifferSource = _native.triage.SnifferSource
@@ -15,6 +15,9 @@ TriageError = _native.triage.TriageError
 ConfidenceSignal = _native.triage.ConfidenceSignal
 ParserKind = _native.triage.ParserKind
 ParserResult = _native.triage.ParserResult
+EvidenceSpan = _native.triage.EvidenceSpan
+Provenance = _native.triage.Provenance
+Finding = _native.triage.Finding
 EntropySummary = _native.triage.EntropySummary
 DetectedString = _native.triage.DetectedString
 StringsSummary = _native.triage.StringsSummary
@@ -31,6 +34,16 @@ TriagedArtifact = _native.triage.TriagedArtifact
 # Overlay detection types
 OverlayAnalysis = _native.triage.OverlayAnalysis
 OverlayFormat = _native.triage.OverlayFormat
+BuildId = _native.triage.BuildId
+PeDebugInfo = _native.triage.PeDebugInfo
+PdbPathLeak = _native.triage.PdbPathLeak
+LicenseReport = _native.triage.LicenseReport
+LicenseMatch = _native.triage.LicenseMatch
+CopyrightNotice = _native.triage.CopyrightNotice
+SourceLanguageGuess = _native.triage.SourceLanguageGuess
+GoBinaryInfo = _native.triage.GoBinaryInfo
+UnpackReport = _native.triage.UnpackReport
+UnpackedChild = _native.triage.UnpackedChild
 
 IOConfig = _native.triage.IOConfig
 EntropyConfig = _native.triage.EntropyConfig
@@ -41,6 +54,7 @@ ScoringConfig = _native.triage.ScoringConfig
 PackerConfig = _native.triage.PackerConfig
 HeaderConfig = _native.triage.HeaderConfig
 ParserConfig = _native.triage.ParserConfig
+FindingsConfig = _native.triage.FindingsConfig
 SimilarityConfig = _native.triage.SimilarityConfig
 
 
@@ -180,9 +194,35 @@ class TriageConfig:
     def similarity(self, cfg: SimilarityConfig) -> None:  # pragma: no cover
         self._native.similarity = cfg
 
+    @property
+    def findings(self) -> FindingsConfig:
+        """Finding severity overrides. Assign the whole config back after
+        editing: ``cfg.findings = f``."""
+        return self._native.findings
+
+    @findings.setter
+    def findings(self, cfg: FindingsConfig) -> None:  # pragma: no cover
+        self._native.findings = cfg
+
 
 # Import triage functions
 analyze_bytes = _native.triage.analyze_bytes
+migrate_report = _native.triage.migrate_report
+
+# Analyst annotations, keyed by SHA-256
+Annotations = _native.triage.Annotations
+AnalystComment = _native.triage.AnalystComment
+AnalystVerdict = _native.triage.AnalystVerdict
+FunctionRename = _native.triage.FunctionRename
+AnnotationStore = _native.triage.AnnotationStore
+sha256_file = _native.triage.sha256_file
+
+# Shared confidence model (see core::confidence)
+confidence_threshold = _native.triage.confidence_threshold
+confidence_band = _native.triage.confidence_band
+
+# Finding severity (see core::triage::severity)
+parse_severity = _native.triage.parse_severity
 
 
 class _StringsProxy:
@@ -256,9 +296,15 @@ def analyze_path(
     str_classify: bool = True,
     str_max_classify: int = 200,
     str_max_ioc_per_string: int = 16,
+    timeout_ms: Optional[int] = None,
+    annotations: Optional[Any] = None,
 ):
     """Wrapper around native analyze_path with stable defaults.
 
+    ``annotations`` may be an :class:`AnnotationStore` (or a directory for
+    one); analyst annotations recorded for the file's SHA-256 are merged
+    into the returned artifact.
+
     Falls back to older signatures if the native extension doesn't support
     extended string-analysis parameters.
     """
@@ -274,7 +320,15 @@ def analyze_path(
         str_classify,
         str_max_classify,
         str_max_ioc_per_string,
+        timeout_ms=timeout_ms,
     )
+    if annotations is not None:
+        store = (
+            annotations
+            if isinstance(annotations, AnnotationStore)
+            else AnnotationStore(str(annotations))
+        )
+        art = store.apply(art)
     return _ArtifactProxy(art)
 
 
@@ -369,6 +423,9 @@ __all__ = [
     "ConfidenceSignal",
     "ParserKind",
     "ParserResult",
+    "EvidenceSpan",
+    "Provenance",
+    "Finding",
     "EntropySummary",
     "DetectedString",
     "StringsSummary",
@@ -381,6 +438,13 @@ __all__ = [
     "TriagedArtifact",
     "OverlayAnalysis",
     "OverlayFormat",
+    "LicenseReport",
+    "LicenseMatch",
+    "CopyrightNotice",
+    "SourceLanguageGuess",
+    "GoBinaryInfo",
+    "UnpackReport",
+    "UnpackedChild",
     # Configs
     "TriageConfig",
     "IOConfig",
@@ -393,8 +457,19 @@ __all__ = [
     "SimilarityConfig",
     "HeaderConfig",
     "ParserConfig",
+    "FindingsConfig",
     "analyze_bytes",
     "analyze_path",
+    "migrate_report",
+    "Annotations",
+    "AnalystComment",
+    "AnalystVerdict",
+    "FunctionRename",
+    "AnnotationStore",
+    "sha256_file",
+    "confidence_threshold",
+    "confidence_band",
+    "parse_severity",
     "triage",
 ]
 
@@ -406,7 +481,6 @@ try:
     PeMachine = _native.triage.PeMachine
     PeCharacteristics = _native.triage.PeCharacteristics
     PeDllCharacteristics = _native.triage.PeDllCharacteristics
-    PeDebugInfo = _native.triage.PeDebugInfo
     PePdbInfo = _native.triage.PePdbInfo
     PeImport = _native.triage.PeImport
     PeExport = _native.triage.PeExport
@@ -426,7 +500,6 @@ try:
         "PeMachine",
         "PeCharacteristics",
         "PeDllCharacteristics",
-        "PeDebugInfo",
         "PePdbInfo",
         "PeImport",
         "PeExport",
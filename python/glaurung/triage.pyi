@@ -1,5 +1,5 @@
 from __future__ import annotations
-from typing import List, Optional, Dict
+from typing import Any, List, Optional, Dict, Tuple
 
 class SnifferSource:
     Infer: SnifferSource
@@ -47,6 +47,27 @@ class ConfidenceSignal:
     notes: Optional[str]
     def __init__(self, name: str, score: float, notes: Optional[str] = ...) -> None: ...
 
+class EvidenceSpan:
+    offset: int
+    length: int
+    note: Optional[str]
+    def __init__(self, offset: int, length: int, note: Optional[str] = ...) -> None: ...
+
+class Provenance:
+    pass_name: str
+    rule: Optional[str]
+    rule_version: Optional[str]
+    tool_version: str
+    confidence: float
+    evidence: List[EvidenceSpan]
+
+class Finding:
+    category: str
+    claim: str
+    provenance: Provenance
+    band: str
+    severity: str
+
 class ParserKind:
     Object: ParserKind
     Goblin: ParserKind
@@ -110,6 +131,15 @@ def analyze_entropy_bytes(
     max_windows: int = ...,
     header_size: int = ...,
 ) -> EntropyAnalysis: ...
+def identify_arch(data: bytes, max_results: int = ...) -> List[Dict[str, Any]]: ...
+def detect_layout(data: bytes) -> Optional[Dict[str, Any]]: ...
+def infer_base(
+    data: bytes,
+    bits: int = ...,
+    endianness: str = ...,
+    alignment: int = ...,
+    max_results: int = ...,
+) -> List[Tuple[int, int, float]]: ...
 
 class EntropyAnalysis:
     summary: EntropySummary
@@ -119,6 +149,11 @@ class EntropyAnalysis:
     classification_kind: str
     def __init__(self) -> None: ...
 
+class DemangledName:
+    name: str
+    demangled: str
+    flavor: str
+
 class SymbolSummary:
     imports_count: int
     exports_count: int
@@ -127,6 +162,7 @@ class SymbolSummary:
     export_names: Optional[List[str]]
     demangled_import_names: Optional[List[str]]
     demangled_export_names: Optional[List[str]]
+    demangled: Optional[List[DemangledName]]
     stripped: bool
     tls_used: bool
     tls_callback_count: Optional[int]
@@ -231,6 +267,12 @@ class PackerConfig:
     packer_signal_weight: float
     def __init__(self) -> None: ...
 
+class FindingsConfig:
+    severity_overrides: dict[str, str]
+    """Severity remapping keyed by rule id (e.g. ``memory:wx``) or finding
+    category (e.g. ``packer``); a rule key wins over a category key."""
+    def __init__(self) -> None: ...
+
 class ContainerChild:
     type_name: str
     offset: int
@@ -281,6 +323,10 @@ class TriageConfig:
     def packers(self) -> PackerConfig: ...
     @packers.setter
     def packers(self, cfg: PackerConfig) -> None: ...
+    @property
+    def findings(self) -> FindingsConfig: ...
+    @findings.setter
+    def findings(self, cfg: FindingsConfig) -> None: ...
 
 class TriageVerdict:
     from glaurung import Format, Arch, Endianness
@@ -301,6 +347,224 @@ class TriageVerdict:
         signals: Optional[List[ConfidenceSignal]] = ...,
     ) -> None: ...
 
+class AnalystComment:
+    text: str
+    address: Optional[int]
+    author: Optional[str]
+    created_at: str
+
+class AnalystVerdict:
+    label: str
+    note: Optional[str]
+    author: Optional[str]
+    created_at: str
+
+class FunctionRename:
+    address: int
+    name: str
+    author: Optional[str]
+    created_at: str
+
+class Annotations:
+    """Analyst annotations recorded for one artifact (keyed by SHA-256)."""
+
+    sha256: str
+    tags: List[str]
+    comments: List[AnalystComment]
+    verdict: Optional[AnalystVerdict]
+    function_names: List[FunctionRename]
+    updated_at: str
+    def function_name(self, address: int) -> Optional[str]: ...
+    def to_json(self) -> str: ...
+
+class AnnotationStore:
+    """Directory of per-artifact annotation documents, keyed by SHA-256.
+
+    Every mutating method persists immediately and returns the updated
+    annotations.
+    """
+
+    root: str
+    def __init__(self, root: str) -> None: ...
+    def load(self, sha256: str) -> Annotations: ...
+    def add_tag(self, sha256: str, tag: str) -> Annotations: ...
+    def remove_tag(self, sha256: str, tag: str) -> Annotations: ...
+    def add_comment(
+        self,
+        sha256: str,
+        text: str,
+        address: Optional[int] = None,
+        author: Optional[str] = None,
+    ) -> Annotations: ...
+    def set_verdict(
+        self,
+        sha256: str,
+        label: Optional[str],
+        note: Optional[str] = None,
+        author: Optional[str] = None,
+    ) -> Annotations: ...
+    def rename_function(
+        self, sha256: str, address: int, name: str, author: Optional[str] = None
+    ) -> Annotations: ...
+    def list(self) -> List[str]: ...
+    def apply(
+        self, artifact: TriagedArtifact, sha256: Optional[str] = None
+    ) -> TriagedArtifact:
+        """Copy of ``artifact`` with stored annotations attached. Without
+        ``sha256`` the artifact's digest is used, or ``artifact.path`` is
+        hashed."""
+        ...
+
+def sha256_file(path: str) -> str:
+    """SHA-256 of a file, as used to key annotations."""
+    ...
+
+def confidence_threshold(spec: str) -> float:
+    """Parse a threshold given as a number in [0, 1] or a band name
+    (``speculative``, ``low``, ``medium``, ``high``, ``certain``)."""
+    ...
+
+def confidence_band(confidence: float) -> str:
+    """Band name for a confidence value."""
+    ...
+
+def parse_severity(level: str) -> str:
+    """Normalize a severity name (``info``, ``low``, ``medium``, ``high``,
+    ``critical``); raises ``ValueError`` for anything else."""
+    ...
+
+class BuildId:
+    """A toolchain build identifier, normalized for comparison across reports.
+
+    ``kind`` is ``gnu_build_id`` (lowercase hex), ``macho_uuid`` (uppercase
+    hyphenated UUID), ``pe_codeview`` (symbol-server ``<GUID><AGE>`` key, with
+    the PDB path in ``detail``) or ``go_build_id`` (as embedded).
+    """
+
+    kind: str
+    value: str
+    detail: Optional[str]
+
+class PdbPathLeak:
+    """One fact disclosed by a PE's embedded PDB path.
+
+    ``kind`` is ``username``, ``host``, ``build_agent`` or ``source_tree``.
+    """
+
+    kind: str
+    value: str
+
+class PeDebugInfo:
+    """Summary of a PE debug directory (CodeView, POGO and Repro records)."""
+
+    entries: List[str]
+    pdb_path: Optional[str]
+    pdb_path_offset: Optional[int]
+    pdb_guid_age: Optional[str]
+    pogo_signature: Optional[str]
+    pogo_sections: List[str]
+    repro: bool
+    repro_hash: Optional[str]
+    leaks: List[PdbPathLeak]
+
+class LicenseMatch:
+    """An open-source license identified in an artifact.
+
+    ``spdx`` is an SPDX identifier (or expression, for tags and dual
+    licenses); ``source`` is ``text``, ``spdx_tag`` or ``modinfo``.
+    """
+
+    spdx: str
+    source: str
+    offset: int
+    length: int
+
+class CopyrightNotice:
+    text: str
+    offset: int
+
+class LicenseReport:
+    """Embedded licenses, one per (SPDX id, source), and copyright notices."""
+
+    licenses: List[LicenseMatch]
+    copyrights: List[CopyrightNotice]
+    def spdx_ids(self) -> List[str]: ...
+
+class SourceLanguageGuess:
+    """Classifier verdict on a binary's primary source language.
+
+    ``language`` is ``c``, ``cpp``, ``objc``, ``swift``, ``go``, ``rust``,
+    ``dotnet`` or ``delphi``; ``evidence`` lists the features that fired as
+    ``name=count``.
+    """
+
+    language: str
+    confidence: float
+    alternatives: List[Tuple[str, float]]
+    evidence: List[str]
+
+class GoBinaryInfo:
+    """Go toolchain, module graph and runtime metadata counts.
+
+    Modules are ``path@version`` (``=> path@version`` appended when
+    replaced); ``packages`` lists the non-standard-library packages.
+    """
+
+    go_version: Optional[str]
+    main_package: Optional[str]
+    main_module: Optional[str]
+    dependencies: List[str]
+    settings: List[Tuple[str, str]]
+    functions: int
+    source_files: int
+    types: int
+    packages: List[str]
+
+class UnpackedChild:
+    """A child extracted from a nested container, with its own triage.
+
+    ``source`` names the extractor (``zip``, ``gzip``, ``tar``, ``ar``,
+    ``cab``, ``fat-macho``, ``overlay``, ``pe-resource``); ``note`` says why
+    a recognised but opaque child (xz, 7z, packed executable) was not
+    taken further apart.
+    """
+
+    name: str
+    source: str
+    depth: int
+    size: int
+    sha256: str
+    format: str
+    arch: Optional[str]
+    bits: Optional[int]
+    packers: List[str]
+    max_severity: Optional[str]
+    note: Optional[str]
+    children: List["UnpackedChild"]
+
+class UnpackReport:
+    """Child tree extracted when triage runs with ``max_recursion_depth > 1``.
+
+    ``stopped`` names each bound that cut extraction short
+    (``max_depth``, ``max_children``, ``max_child_size``,
+    ``max_total_size``, ``cancelled``).
+    """
+
+    children: List[UnpackedChild]
+    total_children: int
+    total_bytes: int
+    max_depth: int
+    stopped: List[str]
+
+class PeTriageInfo:
+    rich_header: Optional[Any]
+    debug: Optional[PeDebugInfo]
+
+class FormatSpecificTriage:
+    pe: Optional[PeTriageInfo]
+    elf: Optional[Any]
+    macho: Optional[Any]
+
 class TriagedArtifact:
     id: str
     path: str
@@ -319,6 +583,15 @@ class TriagedArtifact:
     parse_status: Optional[List[ParserResult]]
     budgets: Optional[Budgets]
     errors: Optional[List[TriageError]]
+    findings: Optional[List[Finding]]
+    annotations: Optional[Annotations]
+    build_ids: Optional[List[BuildId]]
+    licenses: Optional[LicenseReport]
+    source_language: Optional[SourceLanguageGuess]
+    go: Optional[GoBinaryInfo]
+    unpacked: Optional[UnpackReport]
+    format_specific: Optional[FormatSpecificTriage]
+    max_severity: Optional[str]
     def __init__(
         self,
         id: str,
@@ -342,6 +615,16 @@ class TriagedArtifact:
     @staticmethod
     def from_json(json_str: str) -> TriagedArtifact: ...
     def ctph_similarity(self, other: TriagedArtifact) -> Optional[float]: ...
+    def filtered(
+        self, min_confidence: float = 0.0, min_severity: Optional[str] = None
+    ) -> TriagedArtifact:
+        """Copy without verdicts, packer matches, architecture guesses, or
+        findings below ``min_confidence``, and without findings below
+        ``min_severity``."""
+        ...
+    def with_severity_overrides(self, overrides: dict[str, str]) -> TriagedArtifact:
+        """Copy with finding severities remapped by rule id or category."""
+        ...
 
 # Note: symbols API is now exposed at top-level: glaurung.symbols
 
@@ -359,6 +642,8 @@ def analyze_path(
     max_classify: int = 200,
     max_ioc_per_string: int = 16,
     config: Optional[TriageConfig] = None,
+    timeout_ms: Optional[int] = None,
+    annotations: Optional[AnnotationStore | str] = None,
 ) -> TriagedArtifact:
     """
     Analyze a file at the given path.
@@ -367,6 +652,10 @@ def analyze_path(
         path: Path to the file to analyze
         max_read_bytes: Maximum bytes to read for analysis (default 10MB)
         max_file_size: Maximum file size to analyze (default 100MB)
+        timeout_ms: Stop after this many milliseconds and return a partial
+            artifact (skipped phases are reported in ``errors``)
+        annotations: Annotation store (or its directory) whose entries for
+            this file's SHA-256 are merged into the result
 
     Returns:
         TriagedArtifact containing analysis results
@@ -385,6 +674,7 @@ def analyze_bytes(
     max_classify: int = 200,
     max_ioc_per_string: int = 16,
     config: Optional[TriageConfig] = None,
+    timeout_ms: Optional[int] = None,
 ) -> TriagedArtifact:
     """
     Analyze raw bytes.
@@ -392,12 +682,31 @@ def analyze_bytes(
     Args:
         data: Bytes to analyze
         max_read_bytes: Maximum bytes to read for analysis (default 10MB)
+        timeout_ms: Stop after this many milliseconds and return a partial
+            artifact (skipped phases are reported in ``errors``)
 
     Returns:
         TriagedArtifact containing analysis results
     """
     ...
 
+def migrate_report(json: str) -> tuple[str, List[str]]:
+    """
+    Upgrade a serialized TriagedArtifact to the current schema version.
+
+    Args:
+        json: Report JSON written by any earlier schema version
+
+    Returns:
+        The upgraded report JSON and a description of each step applied
+        (empty if the report was already current)
+
+    Raises:
+        ValueError: If the report is malformed, of an unknown version, or
+            newer than this build
+    """
+    ...
+
 # Convenience passthrough for symbols listing
 def list_symbols(
     path: str,
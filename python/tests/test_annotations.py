@@ -0,0 +1,55 @@
+import json
+
+import glaurung as g
+from glaurung import cli
+
+
+ELF = b"\x7fELF\x02\x01\x01" + b"\x00" * 57
+
+
+def test_store_roundtrip_and_merge_into_triage(tmp_path):
+    sample = tmp_path / "sample.bin"
+    sample.write_bytes(ELF)
+    store = g.triage.AnnotationStore(str(tmp_path / "ann"))
+    sha = g.triage.sha256_file(str(sample))
+
+    store.add_tag(sha, "loader")
+    store.set_verdict(sha, "malicious", note="drops stage2", author="ana")
+    ann = store.rename_function(sha, 0x401000, "unpack_stage2")
+    assert ann.tags == ["loader"]
+    assert ann.function_name(0x401000) == "unpack_stage2"
+    assert store.list() == [sha]
+
+    # Re-analysis of the same bytes at a different path picks them up.
+    moved = tmp_path / "renamed.exe"
+    moved.write_bytes(ELF)
+    art = g.triage.analyze_path(str(moved), annotations=store)
+    assert art.sha256 == sha
+    assert art.annotations.verdict.label == "malicious"
+    assert json.loads(art.to_json())["annotations"]["tags"] == ["loader"]
+
+
+def test_invalid_digest_raises(tmp_path):
+    store = g.triage.AnnotationStore(str(tmp_path))
+    try:
+        store.add_tag("not-a-digest", "x")
+    except ValueError:
+        pass
+    else:
+        raise AssertionError("expected ValueError")
+
+
+def test_cli_annotate_then_triage(tmp_path, capsys):
+    sample = tmp_path / "sample.bin"
+    sample.write_bytes(ELF)
+    store = tmp_path / "ann"
+    rc = cli.main([
+        "annotate", str(sample), "--store", str(store),
+        "--tag", "apt", "--verdict", "suspicious", "--rename", "0x1000=main",
+    ])
+    assert rc == 0
+    out = capsys.readouterr().out
+    assert "tags: apt" in out and "rename 0x1000 -> main" in out
+
+    assert cli.main(["triage", str(sample), "--annotations", str(store)]) == 0
+    assert "analyst: verdict=suspicious tags=apt" in capsys.readouterr().out
@@ -0,0 +1,66 @@
+"""Statistical architecture identification for headerless blobs."""
+
+import io
+import json
+import struct
+from contextlib import redirect_stdout
+from pathlib import Path
+
+import glaurung as g
+
+# addiu sp,-32; sw ra,28(sp); lw t9,(gp); jalr t9; nop; lw ra,28(sp);
+# jr ra; addiu sp,32 -- a MIPS32 call-and-return, repeated into a blob.
+_MIPS_FN = [
+    0x27BDFFE0,
+    0xAFBF001C,
+    0x8F998010,
+    0x0320F809,
+    0x00000000,
+    0x8FBF001C,
+    0x03E00008,
+    0x27BD0020,
+]
+
+
+def _mips_blob(fmt: str) -> bytes:
+    return struct.pack(f"{fmt}{len(_MIPS_FN)}I", *_MIPS_FN) * 64
+
+
+def test_identify_arch_names_isa_and_byte_order() -> None:
+    for fmt, endian in ((">", "big"), ("<", "little")):
+        guesses = g.triage.identify_arch(_mips_blob(fmt))
+        assert guesses, endian
+        best = guesses[0]
+        assert best["arch"] == "mips"
+        assert best["endianness"] == endian
+        assert best["thumb"] is False
+        assert 0.5 < best["confidence"] <= 1.0
+
+
+def test_identify_arch_has_no_verdict_for_text() -> None:
+    text = b"The quick brown fox jumps over the lazy dog. " * 200
+    assert g.triage.identify_arch(text) == []
+
+
+def test_triage_uses_classifier_for_headerless_blob() -> None:
+    art = g.triage.analyze_bytes(_mips_blob(">"))
+    assert not art.verdicts
+    assert art.heuristic_arch[0][0] == g.Arch.MIPS
+    assert art.heuristic_endianness[0] == g.Endianness.Big
+
+
+def test_disasm_cli_configures_decoder_for_raw_firmware(tmp_path: Path) -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    blob = tmp_path / "firmware.bin"
+    blob.write_bytes(_mips_blob(">"))
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = GlaurungCLI().run(
+            ["disasm", str(blob), "--json", "--max-instructions", "8"]
+        )
+    assert rc == 0
+    data = json.loads(buf.getvalue())
+    assert data["metadata"]["arch_guess"]["endianness"] == "big"
+    mnemonics = [i["mnemonic"] for i in data["instructions"]]
+    assert "jr" in mnemonics
@@ -0,0 +1,62 @@
+"""Build identifiers in triage reports and the `report build-ids` index."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_MACHO = Path("samples/binaries/platforms/darwin/amd64/export/native/multi_import-macho")
+_MACHO_UUID = "4C4C4437-5555-3144-A192-1D45C790DF5A"
+
+
+def _report(path: str, *ids: tuple) -> dict:
+    return {
+        "schema_version": "1.6",
+        "path": path,
+        "build_ids": [{"kind": k, "value": v, "detail": None} for k, v in ids],
+    }
+
+
+def test_triage_reports_macho_uuid() -> None:
+    if not _MACHO.exists():
+        pytest.skip("Mach-O sample not available")
+    art = g.triage.analyze_path(str(_MACHO))
+    assert [(b.kind, b.value) for b in art.build_ids] == [("macho_uuid", _MACHO_UUID)]
+    assert json.loads(art.to_json())["build_ids"][0]["value"] == _MACHO_UUID
+
+
+def test_migrate_adds_build_ids_slot() -> None:
+    old = json.loads(g.triage.analyze_bytes(b"\x7fELF" + b"\x00" * 64).to_json())
+    old.pop("build_ids", None)
+    old["schema_version"] = "1.5"
+    new, steps = g.triage.migrate_report(json.dumps(old))
+    assert steps[0] == "1.5 -> 1.6: add build identifiers slot"
+    assert json.loads(new)["build_ids"] is None
+
+
+def test_cli_report_build_ids_groups_and_looks_up(tmp_path, capsys) -> None:
+    cv = ("pe_codeview", "E65581C52602417BACDE82D805DC896F1")
+    (tmp_path / "a.json").write_text(json.dumps(_report("/dist/a.exe", cv)))
+    (tmp_path / "corpus.jsonl").write_text(
+        json.dumps(_report("/mirror/a.exe", cv))
+        + "\n"
+        + json.dumps(_report("/bin/ls", ("gnu_build_id", "15dfff32")))
+        + "\n"
+    )
+    files = [str(tmp_path / "a.json"), str(tmp_path / "corpus.jsonl")]
+
+    assert cli.main(["report", "build-ids", *files]) == 0
+    out = capsys.readouterr().out
+    assert f"{cv[1]} [pe_codeview]: 2 report(s)" in out
+    assert "  /mirror/a.exe" in out
+
+    # GUIDs are matched regardless of case and dashes.
+    key = "e65581c5-2602-417b-acde-82d805dc896f1"
+    assert cli.main(["report", "build-ids", *files, "--id", key, "--json"]) == 0
+    groups = json.loads(capsys.readouterr().out)["build_ids"]
+    assert [grp["reports"] for grp in groups] == [["/dist/a.exe", "/mirror/a.exe"]]
+
+    assert cli.main(["report", "build-ids", *files, "--id", "deadbeef"]) == 1
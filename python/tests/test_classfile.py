@@ -133,6 +133,59 @@ def test_parse_class_bytes_returns_none_on_non_class() -> None:
     assert info is None
 
 
+def test_parse_class_disassembles_method_bodies() -> None:
+    info = g.analysis.parse_java_class_path(str(_need(_HELLO_CLASS)))
+    assert info is not None
+    pm = next(m for m in info["methods"] if m["name"] == "printMessage")
+    lines = pm["code"]["disassembly"]
+    assert lines[0].split() == [
+        "0:",
+        "getstatic",
+        "java/lang/System.out:Ljava/io/PrintStream;",
+    ]
+    assert any(
+        "invokevirtual" in line
+        and "java/io/PrintStream.println:(Ljava/lang/String;)V" in line
+        for line in lines
+    )
+
+
+def test_java_call_graph_resolves_invokes() -> None:
+    graph = g.analysis.java_call_graph_path(str(_need(_HELLO_CLASS)))
+    assert graph is not None
+    assert graph["class_count"] == 1
+    edges = {(e["caller"], e["callee"]): e for e in graph["edges"]}
+    main = "HelloWorld.main:([Ljava/lang/String;)V"
+    assert edges[(main, "HelloWorld.printMessage:()V")]["kind"] == "virtual"
+    assert len(edges[(main, "HelloWorld.printMessage:()V")]["call_sites"]) == 2
+    assert edges[(main, "HelloWorld.printGlobalInfo:()V")]["kind"] == "direct"
+    assert (
+        "HelloWorld.<init>:()V",
+        "HelloWorld.<init>:(Ljava/lang/String;)V",
+    ) in edges
+    assert "java/io/PrintStream.println:(Ljava/lang/String;)V" in graph["nodes"]
+    assert graph["suspicious"] == []
+
+
+def test_java_call_graph_returns_none_on_non_java() -> None:
+    assert g.analysis.java_call_graph_path(str(_need(_HELLO_C))) is None
+
+
+def test_classfile_cli_disasm_and_calls(tmp_path: Path) -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    binary = _need(_HELLO_CLASS)
+    cli = GlaurungCLI()
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = cli.run(["classfile", str(binary), "--disasm", "--calls"])
+    assert rc == 0
+    out = buf.getvalue()
+    assert "invokevirtual" in out
+    assert "call graph:" in out
+    assert "-> HelloWorld.printMessage:()V (virtual)" in out
+
+
 def test_classfile_cli_renders_class(tmp_path: Path) -> None:
     from glaurung.cli.main import GlaurungCLI
 
@@ -0,0 +1,99 @@
+"""Tests for `glaurung batch` (parallel corpus triage with a result cache)."""
+
+from __future__ import annotations
+
+import json
+import multiprocessing
+import os
+from pathlib import Path
+from typing import Optional
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+from glaurung.cli import cache as _cache
+from glaurung.cli.commands import batch
+
+
+def _corpus(root: Path) -> Path:
+    corpus = root / "corpus"
+    (corpus / "nested").mkdir(parents=True)
+    elf = b"\x7fELF\x02\x01\x01" + b"\x00" * 57
+    (corpus / "a.bin").write_bytes(elf)
+    (corpus / "nested" / "copy.bin").write_bytes(elf)  # duplicate content
+    (corpus / "notes.txt").write_bytes(b"hello http://example.com\n" * 4)
+    return corpus
+
+
+def _run(capsys, *argv: str) -> dict:
+    rc = cli.main(["batch", *argv, "--json"])
+    assert rc == 0
+    return json.loads(capsys.readouterr().out.strip().splitlines()[-1])
+
+
+def _paths(report: Path) -> list[str]:
+    return [json.loads(line)["path"] for line in report.read_text().splitlines()]
+
+
+def test_batch_reuses_cached_results(tmp_path: Path, capsys) -> None:
+    corpus = _corpus(tmp_path)
+    report = tmp_path / "out" / "report.jsonl"
+    cache_dir = tmp_path / "cache"
+    args = (str(corpus), "-o", str(report), "--cache-dir", str(cache_dir), "-j", "1")
+
+    first = _run(capsys, *args)
+    assert first["files"] == 3
+    # The duplicate is answered from the entry its twin just wrote.
+    assert (first["analyzed"], first["cached"]) == (2, 1)
+    expected = [str(corpus / "a.bin"), str(corpus / "nested" / "copy.bin"), str(corpus / "notes.txt")]
+    assert _paths(report) == expected
+    entries = list((cache_dir / "triage" / _cache.get_glaurung_version()).glob("*/*.json"))
+    assert len(entries) == 2
+
+    second = _run(capsys, *args)
+    assert (second["analyzed"], second["cached"]) == (0, 3)
+    assert _paths(report) == expected
+
+
+def test_batch_parallel_order_matches_serial(tmp_path: Path, capsys, monkeypatch) -> None:
+    monkeypatch.delenv("GLAURUNG_CACHE_DIR", raising=False)
+    corpus = _corpus(tmp_path)
+    serial, parallel = tmp_path / "serial.jsonl", tmp_path / "parallel.jsonl"
+    _run(capsys, str(corpus), "-o", str(serial), "-j", "1")
+    summary = _run(capsys, str(corpus), "-o", str(parallel), "-j", "2")
+    assert summary["cache_dir"] is None
+    assert summary["analyzed"] == 3
+    assert _paths(parallel) == _paths(serial)
+    # Every line is a current-schema report that `report migrate` accepts.
+    for line in parallel.read_text().splitlines():
+        assert g.triage.migrate_report(line)[1] == []
+
+
+_triage_one = batch._triage_one
+
+
+def _crash_on_boom(path: str, options: dict, cache_dir: Optional[str]):
+    if path.endswith("boom.bin"):
+        os._exit(1)  # what a segfault in native code looks like to the pool
+    return _triage_one(path, options, cache_dir)
+
+
+@pytest.mark.skipif(
+    multiprocessing.get_start_method() != "fork",
+    reason="workers must inherit the patched task",
+)
+def test_batch_survives_a_dead_worker(tmp_path: Path, capsys, monkeypatch) -> None:
+    monkeypatch.delenv("GLAURUNG_CACHE_DIR", raising=False)
+    monkeypatch.setattr(batch, "_triage_one", _crash_on_boom)
+    corpus = _corpus(tmp_path)
+    (corpus / "boom.bin").write_bytes(b"\x00" * 16)
+    report = tmp_path / "report.jsonl"
+    rc = cli.main(["batch", str(corpus), "-o", str(report), "-j", "2", "--json"])
+    summary = json.loads(capsys.readouterr().out.strip().splitlines()[-1])
+    assert rc == 1
+    assert (summary["analyzed"], summary["failed"]) == (3, 1)
+    assert summary["failures"] == [
+        {"path": str(corpus / "boom.bin"), "error": "worker process died"}
+    ]
+    assert str(corpus / "notes.txt") in _paths(report)
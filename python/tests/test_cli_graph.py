@@ -3,6 +3,7 @@
 from __future__ import annotations
 
 import io
+import json
 import re
 from contextlib import redirect_stdout
 from pathlib import Path
@@ -83,3 +84,27 @@ def test_cfg_unknown_function_returns_error() -> None:
     rc, out = _run_cli(["graph", str(binary), "cfg", "definitely_not_a_real_symbol"])
     assert rc == 4
     assert "not found" in out.lower()
+
+
+def test_callgraph_json_is_sorted_and_merged() -> None:
+    binary = _need(_HELLO_DEBUG)
+    rc, out = _run_cli(["graph", "--format", "json", str(binary), "callgraph"])
+    assert rc == 0
+    doc = json.loads(out)
+    vas = [f["entry_va"] for f in doc["functions"]]
+    assert vas == sorted(vas)
+    keys = [(e["caller"], e["callee"], e["kind"]) for e in doc["edges"]]
+    assert keys == sorted(set(keys)), "edges must be merged and sorted"
+    assert all(e["kind"] in ("direct", "indirect", "virtual", "tail") for e in doc["edges"])
+    assert any(e["caller"] == "main" for e in doc["edges"])
+
+
+def test_cfg_json_lists_blocks_with_successors() -> None:
+    binary = _need(_HELLO_DEBUG)
+    rc, out = _run_cli(["graph", "--format", "json", str(binary), "cfg", "main"])
+    assert rc == 0
+    doc = json.loads(out)
+    assert doc["function"] == "main"
+    ids = {b["id"] for b in doc["blocks"]}
+    assert doc["blocks"][0]["start"] == doc["entry_va"]
+    assert all(set(b["successors"]) <= ids for b in doc["blocks"])
@@ -0,0 +1,45 @@
+import json
+
+import glaurung as g
+from glaurung import cli
+
+
+def _old_report() -> dict:
+    art = g.triage.analyze_bytes(b"\x7fELF" + b"\x00" * 64)
+    obj = json.loads(art.to_json())
+    obj.pop("findings", None)
+    obj["schema_version"] = "1.2"
+    return obj
+
+
+def test_migrate_report_roundtrip():
+    new, steps = g.triage.migrate_report(json.dumps(_old_report()))
+    assert steps and steps[0].startswith("1.2 -> ")
+    again, steps2 = g.triage.migrate_report(new)
+    assert steps2 == []
+    assert json.loads(again)["schema_version"] == json.loads(new)["schema_version"]
+
+
+def test_cli_report_migrate_check_and_in_place(tmp_path, capsys):
+    single = tmp_path / "one.json"
+    single.write_text(json.dumps(_old_report()))
+    corpus = tmp_path / "corpus.jsonl"
+    corpus.write_text("\n".join(json.dumps(_old_report()) for _ in range(3)) + "\n")
+
+    assert cli.main(["report", "migrate", str(single), str(corpus), "--check"]) == 1
+    assert "needs migration (3 report(s))" in capsys.readouterr().out
+
+    assert cli.main(["report", "migrate", str(single), str(corpus), "--in-place"]) == 0
+    assert json.loads(single.read_text())["schema_version"] != "1.2"
+    lines = corpus.read_text().splitlines()
+    assert len(lines) == 3
+    assert all(json.loads(l)["schema_version"] != "1.2" for l in lines)
+
+    assert cli.main(["report", "migrate", str(single), str(corpus), "--check"]) == 0
+
+
+def test_cli_report_migrate_rejects_newer_schema(tmp_path, capsys):
+    p = tmp_path / "future.json"
+    p.write_text(json.dumps({"schema_version": "99.0"}))
+    assert cli.main(["report", "migrate", str(p), "--check"]) == 3
+    assert "newer than this build" in capsys.readouterr().out
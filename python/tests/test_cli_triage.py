@@ -38,3 +38,9 @@ def test_cli_triage_pretty_bytes(capsys):
     finally:
         if tmp.exists():
             tmp.unlink()
+
+
+def test_cli_rejects_invalid_log_filter(capsys):
+    rc = cli.main(["--log-level", "glaurung=loud", "triage", "/dev/null"])
+    assert rc == 2
+    assert "invalid log filter" in capsys.readouterr().err
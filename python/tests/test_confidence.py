@@ -0,0 +1,30 @@
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+
+def test_thresholds_and_bands():
+    assert g.triage.confidence_threshold("high") == pytest.approx(0.8)
+    assert g.triage.confidence_threshold("0.25") == pytest.approx(0.25)
+    assert g.triage.confidence_band(0.97) == "certain"
+    assert g.triage.confidence_band(0.1) == "speculative"
+    with pytest.raises(ValueError):
+        g.triage.confidence_threshold("very")
+
+
+def test_filtered_drops_low_confidence_claims():
+    data = b"MZ" + b"\x00" * 1024 + b"UPX!" + b"UPX0"
+    art = g.triage.analyze_bytes(data)
+    strict = art.filtered(1.01)
+    assert strict.verdicts == []
+    assert not strict.packers
+    kept = art.filtered(0.5)
+    assert all(v.confidence >= 0.5 for v in kept.verdicts)
+
+
+def test_cli_min_confidence(tmp_path, capsys):
+    sample = tmp_path / "x.bin"
+    sample.write_bytes(b"\x7fELF\x02\x01\x01" + b"\x00" * 57)
+    assert cli.main(["triage", str(sample), "--min-confidence", "bogus"]) == 2
+    assert cli.main(["triage", str(sample), "--min-confidence", "medium"]) == 0
@@ -0,0 +1,51 @@
+"""Embedded configuration carving and `glaurung config`."""
+
+import json
+
+import glaurung as g
+from glaurung import cli
+
+
+def _xor_config(tmp_path):
+    text = b"host=10.1.2.3\nport=443\nmutex=Global\\qq\n\0"
+    data = b"\x90" * 64 + b"CONFIG" + bytes(b ^ 0xA5 for b in text) + b"\x90" * 32
+    path = tmp_path / "implant.bin"
+    path.write_bytes(data)
+    return path
+
+
+def _records(tmp_path):
+    data = bytearray(b"\xcc" * 37)
+    for k, v in [(b"server", b"c2.example.net"), (b"port", b"8443"), (b"sleep", b"60")]:
+        data += bytes([len(k)]) + k + bytes([len(v)]) + v
+    data += b"\xcc" * 16
+    path = tmp_path / "records.bin"
+    path.write_bytes(bytes(data))
+    return path
+
+
+def test_carve_configs_decodes_xor_block_and_records(tmp_path) -> None:
+    (cfg,) = json.loads(g.analysis.carve_configs_path(str(_xor_config(tmp_path))))
+    assert (cfg["encoding"], cfg["key"], cfg["offset"]) == ("xor", "a5", 70)
+    assert {e["key"]: e["value"] for e in cfg["entries"]}["host"] == "10.1.2.3"
+
+    (cfg,) = json.loads(g.analysis.carve_configs_path(str(_records(tmp_path))))
+    assert cfg["extractor"] == "length_prefixed"
+    assert cfg["offset"] == 37
+    assert [e["key"] for e in cfg["entries"]] == ["server", "port", "sleep"]
+
+
+def test_cli_config_prints_fields_and_json(tmp_path, capsys) -> None:
+    path = _xor_config(tmp_path)
+    assert cli.main(["config", str(path)]) == 0
+    out = capsys.readouterr().out
+    assert "0x46" in out and "xor key=a5" in out
+    assert "  port = 443" in out
+
+    assert cli.main(["config", str(path), "--format", "json"]) == 0
+    report = json.loads(capsys.readouterr().out)
+    assert report["configs"][0]["entries"][0] == {"key": "host", "value": "10.1.2.3"}
+
+    clean = tmp_path / "clean.bin"
+    clean.write_bytes(b"\x00" * 256)
+    assert cli.main(["config", str(clean)]) == 1
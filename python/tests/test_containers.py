@@ -24,3 +24,32 @@ def test_detect_gzip_container_from_bytes():
     if art.containers is not None:
         types = {c.type_name for c in art.containers}
         assert "gzip" in types
+
+
+def test_nested_containers_are_unpacked_and_triaged(tmp_path: Path):
+    import io
+    import tarfile
+
+    buf = io.BytesIO()
+    with tarfile.open(fileobj=buf, mode="w") as tar:
+        body = b"#!/bin/sh\necho nested\n" * 8
+        info = tarfile.TarInfo("bin/run.sh")
+        info.size = len(body)
+        tar.addfile(info, io.BytesIO(body))
+    nested = gzip.compress(buf.getvalue())
+
+    assert g.triage.analyze_bytes(nested).unpacked is None
+    art = g.triage.analyze_bytes(nested, max_recursion_depth=3)
+    report = art.unpacked
+    assert report.total_children == 2 and not report.stopped
+    (tarball,) = report.children
+    assert tarball.source == "gzip" and tarball.format == "tar"
+    (script,) = tarball.children
+    assert script.name == "bin/run.sh" and script.depth == 2
+    assert len(script.sha256) == 64
+
+    path = tmp_path / "nested.tar.gz"
+    path.write_bytes(nested)
+    shallow = g.triage.analyze_path(str(path), max_depth=2)
+    assert shallow.unpacked.max_depth == 1
+    assert shallow.unpacked.stopped == ["max_depth"]
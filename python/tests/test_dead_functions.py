@@ -0,0 +1,46 @@
+"""Unreferenced and unreachable function detection."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_SYNTH = Path("samples/binaries/platforms/linux/amd64/synthetic")
+
+
+def _need(name: str) -> Path:
+    p = _SYNTH / name
+    # Git LFS pointers stand in for samples that were not fetched.
+    if not p.exists() or p.read_bytes()[:2] not in (b"\x7fE", b"MZ"):
+        pytest.skip(f"missing {p}")
+    return p
+
+
+def test_loader_roots_and_their_callees_are_live() -> None:
+    dead = json.loads(g.analysis.dead_functions_path(str(_need("vulnparse-c-gcc-O0"))))
+    names = {d["name"] for d in dead}
+    # Entry, init/fini arrays, and main (passed to __libc_start_main by address).
+    assert not names & {"_start", "_init", "_fini", "frame_dummy", "main"}
+    for d in dead:
+        assert d["status"] in ("unreferenced", "unreachable")
+        assert bool(d["referrers"]) == (d["status"] == "unreachable")
+
+
+def test_vtable_slots_keep_virtual_methods_live() -> None:
+    dead = json.loads(g.analysis.dead_functions_path(str(_need("poly-cpp-virtual"))))
+    assert not any(d["name"].startswith(("_ZNK3Dog", "_ZNK6Spider")) for d in dead)
+
+
+def test_cli_dead_missing_path() -> None:
+    assert cli.main(["dead", "/nonexistent/binary"]) == 2
+
+
+def test_cli_dead_json(capsys) -> None:
+    path = _need("vulnparse-c-gcc-O0")
+    assert cli.main(["dead", "--format", "json", "--unreferenced-only", str(path)]) == 0
+    out = json.loads(capsys.readouterr().out)
+    assert out["path"] == str(path)
+    assert all(d["status"] == "unreferenced" for d in out["dead"])
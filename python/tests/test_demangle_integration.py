@@ -1,3 +1,4 @@
+import json
 from pathlib import Path
 
 import pytest
@@ -37,4 +38,50 @@ def test_symbol_summary_includes_demangled_names_if_present(path: Path) -> None:
     if out is not None:
         demangled, flavor = out
         assert isinstance(demangled, str)
-        assert flavor in {"itanium", "rust", "msvc", "unknown"}
+        assert flavor in {"itanium", "rust", "msvc", "swift", "unknown"}
+
+
+def test_demangle_text_covers_every_scheme() -> None:
+    cases = {
+        "_ZN3foo3barEv": ("foo::bar()", "itanium"),
+        # Mach-O adds a leading underscore to every C-level symbol.
+        "__ZN3foo3barEv": ("foo::bar()", "itanium"),
+        "_$s4main3FooV3baryyF": ("main.Foo.bar() -> ()", "swift"),
+        "$s4main3FooV3barSivg": ("main.Foo.bar.getter : Swift.Int", "swift"),
+    }
+    for raw, expected in cases.items():
+        assert g.strings.demangle_text(raw) == expected, raw
+    assert g.strings.demangle_text("?bar@foo@@QEAAXXZ")[1] == "msvc"
+    assert g.strings.demangle_text("_ZN7mycrate3foo17h0123456789abcdefE")[1] == "rust"
+    assert g.strings.demangle_text("printf") is None
+
+
+_HELLO_CPP = Path("samples/binaries/platforms/linux/amd64/export/native/gcc/O0/hello-cpp-g++-O0")
+
+
+def test_symbol_summary_pairs_raw_and_demangled_names() -> None:
+    if not _HELLO_CPP.exists():
+        pytest.skip(f"sample not present: {_HELLO_CPP}")
+    sym = g.triage.analyze_path(str(_HELLO_CPP)).symbols
+    assert sym is not None and sym.demangled
+    raw = set(sym.import_names or []) | set(sym.export_names or [])
+    for entry in sym.demangled:
+        assert entry.name in raw
+        assert entry.demangled != entry.name
+        assert entry.flavor == "itanium"
+    assert any("std::" in e.demangled for e in sym.demangled)
+
+
+def test_symbols_cli_json_keeps_raw_names_and_adds_demangled(capsys) -> None:
+    if not _HELLO_CPP.exists():
+        pytest.skip(f"sample not present: {_HELLO_CPP}")
+    from glaurung import cli
+
+    assert cli.main(["symbols", str(_HELLO_CPP), "--json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert any(n.startswith("_Z") for n in data["all"])
+    pairs = {d["name"]: d["demangled"] for d in data["demangled"]}
+    assert pairs and all(name in data["all"] for name in pairs)
+
+    assert cli.main(["symbols", str(_HELLO_CPP), "--json", "--raw"]) == 0
+    assert "demangled" not in json.loads(capsys.readouterr().out)
@@ -0,0 +1,215 @@
+"""Tests for `glaurung export-program` and `glaurung serve`."""
+
+from __future__ import annotations
+
+import http.client
+import json
+import shutil
+import subprocess
+import threading
+import urllib.error
+import urllib.request
+import xml.etree.ElementTree as ET
+from collections.abc import Iterator
+from contextlib import contextmanager
+from pathlib import Path
+
+import pytest
+
+from glaurung import cli
+from glaurung.cli.commands.serve import (
+    GRPC_SERVICE,
+    Exporter,
+    make_grpc_server,
+    make_server,
+    parse_get_program_request,
+)
+
+_HELLO_C = r"""
+#include <stdio.h>
+
+static int helper(int x) { return x * 3 + 1; }
+
+int main(int argc, char **argv) {
+    puts("fetching http://example.com/payload.bin");
+    return helper(argc);
+}
+"""
+
+
+@pytest.fixture
+def hello(tmp_path: Path) -> Path:
+    cc = shutil.which("cc")
+    if not cc:
+        pytest.skip("needs cc")
+    (tmp_path / "hello.c").write_text(_HELLO_C)
+    subprocess.run([cc, "-O0", "hello.c", "-o", "hello"], cwd=tmp_path, check=True)
+    return tmp_path / "hello"
+
+
+def _export(capsysbinary, binary: Path, fmt: str) -> bytes:
+    assert cli.main(["export-program", str(binary), "--output-format", fmt]) == 0
+    return capsysbinary.readouterr().out
+
+
+def test_json_export_covers_the_program_model(hello: Path, capsysbinary) -> None:
+    program = json.loads(_export(capsysbinary, hello, "json"))
+    assert program["schema_version"] == 1
+    assert (program["name"], program["format"], program["bits"]) == ("hello", "ELF", 64)
+    assert any(s["name"] == ".text" and s["execute"] for s in program["sections"])
+    names = {f["name"] for f in program["functions"]}
+    assert "main" in names
+    assert all(f["blocks"] for f in program["functions"])
+    assert any("example.com" in s["value"] for s in program["strings"])
+    assert {x["kind"] for x in program["xrefs"]} & {"call", "data"}
+
+
+def test_ghidra_xml_export_parses(hello: Path, capsysbinary) -> None:
+    root = ET.fromstring(_export(capsysbinary, hello, "ghidra-xml"))
+    assert root.tag == "PROGRAM"
+    assert root.get("NAME") == "hello"
+    assert root.find("PROCESSOR").get("ADDRESS_MODEL") == "64-bit"
+    # Section bytes are loaded from the binary next to the XML.
+    contents = root.findall("MEMORY_MAP/MEMORY_SECTION/MEMORY_CONTENTS")
+    assert contents and all(c.get("FILE_NAME") == "hello" for c in contents)
+    functions = {f.get("NAME") for f in root.findall("FUNCTIONS/FUNCTION")}
+    assert "main" in functions
+
+
+def test_sarif_export_written_to_file(hello: Path, tmp_path: Path, capsys) -> None:
+    out = tmp_path / "out" / "hello.sarif"
+    argv = ["export-program", str(hello), "--output-format", "sarif", "-o", str(out)]
+    assert cli.main([*argv, "--json"]) == 0
+    summary = json.loads(capsys.readouterr().out.strip().splitlines()[-1])
+    assert summary["format"] == "sarif"
+    assert summary["bytes"] == out.stat().st_size
+    log = json.loads(out.read_text())
+    assert log["version"] == "2.1.0"
+    run = log["runs"][0]
+    assert run["tool"]["driver"]["name"] == "glaurung"
+    assert run["artifacts"][0]["location"]["uri"] == "hello"
+    rules = run["tool"]["driver"]["rules"]
+    for result in run["results"]:
+        assert rules[result["ruleIndex"]]["id"] == result["ruleId"]
+
+
+def test_protobuf_export_starts_with_schema_version(hello: Path, capsysbinary) -> None:
+    body = _export(capsysbinary, hello, "protobuf")
+    # Field 1 (schema_version), varint, value 1.
+    assert body[:2] == b"\x08\x01"
+    assert b"hello" in body and b"main" in body
+
+
+@contextmanager
+def _serving(exporter: Exporter) -> Iterator[str]:
+    srv = make_server("127.0.0.1", 0, exporter)
+    thread = threading.Thread(target=srv.serve_forever, daemon=True)
+    thread.start()
+    host, port = srv.server_address[:2]
+    try:
+        yield f"http://{host}:{port}"
+    finally:
+        srv.shutdown()
+        srv.server_close()
+
+
+@pytest.fixture
+def server(hello: Path) -> Iterator[str]:
+    with _serving(Exporter(root=hello.parent)) as url:
+        yield url
+
+
+def _fetch(url: str, data: bytes | None = None) -> tuple[int, str, bytes]:
+    try:
+        with urllib.request.urlopen(urllib.request.Request(url, data=data)) as r:
+            return r.status, r.headers["Content-Type"], r.read()
+    except urllib.error.HTTPError as e:
+        return e.code, e.headers["Content-Type"], e.read()
+
+
+def test_serve_exports_uploads_and_rooted_paths(hello: Path, server: str) -> None:
+    status, _, body = _fetch(f"{server}/v1/health")
+    assert status == 200 and json.loads(body)["status"] == "ok"
+
+    upload = f"{server}/v1/program?format=sarif&name=up"
+    status, media, body = _fetch(upload, hello.read_bytes())
+    assert (status, media) == (200, "application/sarif+json")
+    assert json.loads(body)["runs"][0]["artifacts"][0]["location"]["uri"] == "up"
+
+    status, media, body = _fetch(f"{server}/v1/program?path=hello")
+    assert (status, media) == (200, "application/json")
+    assert json.loads(body)["name"] == "hello"
+
+
+def test_serve_refuses_bad_requests(server: str) -> None:
+    status, _, body = _fetch(f"{server}/v1/program?path=../../etc/passwd")
+    assert status == 403 and "escapes" in json.loads(body)["error"]
+    assert _fetch(f"{server}/v1/program?path=missing")[0] == 404
+    assert _fetch(f"{server}/v1/program?path=hello&format=pdf")[0] == 400
+    assert _fetch(f"{server}/v1/program", b"")[0] == 400
+    assert _fetch(f"{server}/v2/nothing")[0] == 404
+
+
+@pytest.mark.parametrize("length", ["-1", "ten"])
+def test_serve_rejects_bad_content_length(server: str, length: str) -> None:
+    conn = http.client.HTTPConnection(server.removeprefix("http://"), timeout=10)
+    conn.putrequest("POST", "/v1/program")
+    conn.putheader("Content-Length", length)
+    conn.endheaders()
+    resp = conn.getresponse()
+    assert resp.status == 400
+    assert "Content-Length" in json.loads(resp.read())["error"]
+    conn.close()
+
+
+class _FailingExporter(Exporter):
+    def export(self, data: bytes, name: str, fmt: str) -> bytes:
+        raise RuntimeError("exporter crashed")
+
+
+def test_serve_answers_unexpected_errors_with_500() -> None:
+    with _serving(_FailingExporter()) as url:
+        status, media, body = _fetch(f"{url}/v1/program", b"\x7fELF")
+    assert (status, media) == (500, "application/json")
+    assert "exporter crashed" in json.loads(body)["error"]
+
+
+def _field(number: int, value: bytes) -> bytes:
+    """A length-delimited protobuf field."""
+    out = bytearray([number << 3 | 2])
+    n = len(value)
+    while n >= 0x80:
+        out.append(n & 0x7F | 0x80)
+        n >>= 7
+    out.append(n)
+    return bytes(out) + value
+
+
+def test_get_program_request_decoding() -> None:
+    assert parse_get_program_request(_field(1, b"bin/ls")) == ("bin/ls", None, "")
+    upload = _field(3, b"up") + _field(2, b"\x7fELF" * 40)
+    assert parse_get_program_request(upload) == (None, b"\x7fELF" * 40, "up")
+    # The oneof keeps the last member on the wire; unknown fields are skipped.
+    mixed = _field(2, b"x") + b"\x20\x96\x01" + _field(9, b"?") + _field(1, b"p")
+    assert parse_get_program_request(mixed) == ("p", None, "")
+    with pytest.raises(ValueError):
+        parse_get_program_request(_field(1, b"abc")[:-1])
+
+
+def test_grpc_get_program(hello: Path) -> None:
+    grpc = pytest.importorskip("grpc")
+    server, port = make_grpc_server("127.0.0.1", 0, Exporter(root=hello.parent))
+    server.start()
+    try:
+        with grpc.insecure_channel(f"127.0.0.1:{port}") as channel:
+            get_program = channel.unary_unary(f"/{GRPC_SERVICE}/GetProgram")
+            body = get_program(_field(1, b"hello"), timeout=60)
+            # Field 1 (schema_version), varint, value 1.
+            assert body[:2] == b"\x08\x01" and b"main" in body
+            upload = _field(2, hello.read_bytes()) + _field(3, b"up")
+            assert b"up" in get_program(upload, timeout=60)
+            with pytest.raises(grpc.RpcError) as denied:
+                get_program(_field(1, b"../../etc/passwd"), timeout=60)
+            assert denied.value.code() == grpc.StatusCode.PERMISSION_DENIED
+    finally:
+        server.stop(None)
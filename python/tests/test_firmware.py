@@ -0,0 +1,100 @@
+"""Load-base inference and the `glaurung firmware` command."""
+
+import io
+import json
+import struct
+from contextlib import redirect_stdout
+from pathlib import Path
+
+import glaurung as g
+
+_BASE = 0x80010000
+
+# addiu sp,-32; sw ra,28(sp); lw t9,(gp); jalr t9; nop; lw ra,28(sp);
+# jr ra; addiu sp,32
+_MIPS_FN = [
+    0x27BDFFE0,
+    0xAFBF001C,
+    0x8F998010,
+    0x0320F809,
+    0x00000000,
+    0x8FBF001C,
+    0x03E00008,
+    0x27BD0020,
+]
+
+
+def _firmware() -> bytes:
+    """Big-endian MIPS code, a string pool, and a table of pointers to the
+    strings as mapped at `_BASE`."""
+    data = bytearray(struct.pack(">8I", *_MIPS_FN) * 64)
+    data += bytes(4)
+    offsets = []
+    for i in range(16):
+        offsets.append(len(data))
+        data += f"error code {i:02d} raised\0".encode()
+    while len(data) % 4:
+        data.append(0)
+    for off in offsets:
+        data += struct.pack(">I", _BASE + off)
+    return bytes(data)
+
+
+def test_infer_base_votes_for_string_pointers() -> None:
+    bases = g.triage.infer_base(_firmware(), 32, "big")
+    base, hits, score = bases[0]
+    assert base == _BASE
+    assert hits == 16
+    assert 0.9 <= score <= 1.0
+    assert all(b != _BASE for b, _, _ in g.triage.infer_base(_firmware(), 32, "little"))
+
+
+def test_firmware_cli_reports_arch_and_base(tmp_path: Path) -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    blob = tmp_path / "dump.bin"
+    blob.write_bytes(_firmware())
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = GlaurungCLI().run(["firmware", str(blob)])
+    assert rc == 0
+    out = buf.getvalue()
+    assert "arch: mips big-endian" in out
+    assert f"base: {_BASE:#x} (16 string pointers" in out
+
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = GlaurungCLI().run(["firmware", str(blob), "--json"])
+    assert rc == 0
+    data = json.loads(buf.getvalue())
+    assert data["bits"] == 32
+    assert data["base"][0]["base"] == _BASE
+
+
+def _records(fmt: str, ptr: int) -> bytes:
+    """A table of (id, pointer) records with the given struct layout."""
+    return b"".join(struct.pack(fmt, i + 1, ptr + i * 0x20) for i in range(128))
+
+
+def test_detect_layout_reports_order_and_word_size() -> None:
+    wide = g.triage.detect_layout(_records(">QQ", 0x00007FFF00401000))
+    assert wide["endianness"] == "big"
+    assert wide["bits"] == 64
+    narrow = g.triage.detect_layout(_records("<II", 0x08048000))
+    assert narrow["endianness"] == "little"
+    assert narrow["bits"] == 32
+    assert g.triage.detect_layout(bytes(4096)) is None
+
+
+def test_firmware_cli_falls_back_to_layout_for_data(tmp_path: Path) -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    blob = tmp_path / "table.bin"
+    blob.write_bytes(_records(">QQ", 0x00007FFF00401000))
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = GlaurungCLI().run(["firmware", str(blob), "--json"])
+    assert rc == 0
+    data = json.loads(buf.getvalue())
+    assert data["layout"]["bits"] == 64
+    assert (data["bits"], data["endianness"]) == (64, "big")
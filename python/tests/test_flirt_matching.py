@@ -3,6 +3,7 @@
 from __future__ import annotations
 
 import json
+import shutil
 import subprocess
 import sys
 from pathlib import Path
@@ -10,6 +11,7 @@ from pathlib import Path
 import pytest
 
 import glaurung as g
+from glaurung import cli
 
 
 def _need(p: Path) -> Path:
@@ -97,3 +99,59 @@ def test_flirt_does_not_overwrite_dwarf_names() -> None:
     # main's name must come from DWARF / symbol table, not FLIRT — and it
     # must still be just "main", not some libc-prologue-collision name.
     assert main.name == "main"
+
+
+_LIB_C = """
+int lib_calls;
+unsigned lib_checksum(const unsigned char *p, unsigned long n) {
+    unsigned a = 1, b = 0;
+    for (unsigned long i = 0; i < n; i++) { a = (a + p[i]) % 65521; b = (b + a) % 65521; }
+    lib_calls++;
+    return (b << 16) | a;
+}
+void lib_sort(int *v, unsigned long n) {
+    for (unsigned long i = 1; i < n; i++) {
+        int k = v[i]; unsigned long j = i;
+        while (j > 0 && v[j - 1] > k) { v[j] = v[j - 1]; j--; }
+        v[j] = k;
+    }
+    lib_calls += (int)n;
+}
+"""
+
+_MAIN_C = """
+unsigned lib_checksum(const unsigned char *, unsigned long);
+void lib_sort(int *, unsigned long);
+int main(int argc, char **argv) {
+    int v[3] = {3, argc, 1};
+    lib_sort(v, 3);
+    return (int)lib_checksum((const unsigned char *)argv[0], 4) + v[0];
+}
+"""
+
+
+def test_static_library_signatures_name_stripped_code(tmp_path: Path) -> None:
+    """Signatures built from a `.a` recognise its functions once they are
+    linked (relocations resolved) into a stripped executable."""
+    cc, ar, strip = (shutil.which(t) for t in ("cc", "ar", "strip"))
+    if not (cc and ar and strip):
+        pytest.skip("needs cc, ar and strip")
+    (tmp_path / "lib.c").write_text(_LIB_C)
+    (tmp_path / "main.c").write_text(_MAIN_C)
+
+    def run(*cmd: str) -> None:
+        subprocess.run(cmd, cwd=tmp_path, check=True)
+
+    run(cc, "-O2", "-c", "lib.c", "-o", "lib.o")
+    run(ar, "rcs", "libsig.a", "lib.o")
+    run(cc, "-O2", "main.c", "-L.", "-lsig", "-o", "app")
+    run(strip, "app")
+
+    sigs = tmp_path / "sigs" / "libsig.flirt.json"
+    assert cli.main(["flirt", "build", str(tmp_path / "libsig.a"), "-o", str(sigs)]) == 0
+    lib = json.loads(sigs.read_text())
+    assert lib["schema_version"] == "2"
+    assert {e["name"] for e in lib["entries"]} == {"lib_checksum", "lib_sort"}
+
+    hits = json.loads(g.analysis.flirt_match_path(str(tmp_path / "app"), libraries=[str(sigs)]))
+    assert {h["name"] for h in hits} == {"lib_checksum", "lib_sort"}
@@ -0,0 +1,43 @@
+"""Go pclntab lines, build info, packages and type names."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_GO = Path("samples/binaries/platforms/linux/amd64/export/go/hello-go")
+
+
+def _need(path: Path) -> Path:
+    if not path.exists() or path.read_bytes()[:4] != b"\x7fELF":
+        pytest.skip(f"missing {path}")
+    return path
+
+
+def test_stripped_go_binary_metadata() -> None:
+    meta = json.loads(g.analysis.go_metadata_path(str(_need(_GO)), lines=True))
+    assert meta["build"]["go_version"].startswith("go1.")
+    main = next(f for f in meta["functions"] if f["name"] == "main.main")
+    assert main["file"].endswith(".go") and main["line"] > 0
+    assert main["lines"] and main["lines"][0]["va"] == main["entry_va"]
+    assert "main" in meta["packages"] and "runtime" in meta["packages"]
+    assert meta["types"]
+
+
+def test_triage_reports_go_summary() -> None:
+    art = g.triage.analyze_path(str(_need(_GO)))
+    assert art.go is not None
+    assert art.go.functions > 100
+    assert "main" in art.go.packages
+
+
+def test_non_go_input_is_rejected(tmp_path) -> None:
+    blob = tmp_path / "blob.bin"
+    blob.write_bytes(b"\x00" * 1024)
+    with pytest.raises(ValueError):
+        g.analysis.go_metadata_path(str(blob))
+    assert cli.main(["go", str(blob)]) == 2
+    assert cli.main(["go", str(tmp_path / "missing")]) == 2
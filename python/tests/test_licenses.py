@@ -0,0 +1,51 @@
+"""Embedded license detection in triage reports."""
+
+import json
+
+import glaurung as g
+from glaurung import cli
+
+_GPL_HEADER = (
+    b"This program is free software; you can redistribute it and/or modify\0\0"
+    b"it under the terms of the GNU General Public License as published by\0\0"
+    b"the Free Software Foundation; either version 3 of the License, or\0\0"
+    b"(at your option) any later version.\0"
+)
+
+
+def _blob() -> bytes:
+    return (
+        b"\x7fELF" + b"\xff" * 60 + _GPL_HEADER + b"\xff" * 32
+        + b"/* SPDX-License-Identifier: BSD-2-Clause */\0" + b"\xff" * 32
+        + b"Copyright (C) 2019-2024 Example Project contributors\0"
+    )
+
+
+def test_triage_reports_licenses_and_notices() -> None:
+    art = g.triage.analyze_bytes(_blob())
+    assert art.licenses.spdx_ids() == ["GPL-3.0-or-later", "BSD-2-Clause"]
+    assert [m.source for m in art.licenses.licenses] == ["text", "spdx_tag"]
+    assert art.licenses.copyrights[0].text.startswith("Copyright (C) 2019-2024")
+
+    report = json.loads(art.to_json())
+    assert report["licenses"]["licenses"][0]["spdx"] == "GPL-3.0-or-later"
+    claims = {f.claim: f.severity for f in art.findings if f.category == "license"}
+    assert claims == {"license=GPL-3.0-or-later": "low", "license=BSD-2-Clause": "info"}
+
+
+def test_migrate_adds_license_slot() -> None:
+    old = json.loads(g.triage.analyze_bytes(b"\x7fELF" + b"\x00" * 64).to_json())
+    old.pop("licenses", None)
+    old["schema_version"] = "1.7"
+    new, steps = g.triage.migrate_report(json.dumps(old))
+    assert steps[0] == "1.7 -> 1.8: add embedded license slot"
+    assert json.loads(new)["licenses"] is None
+
+
+def test_cli_triage_lists_licenses(tmp_path, capsys) -> None:
+    path = tmp_path / "blob.bin"
+    path.write_bytes(_blob())
+    assert cli.main(["triage", str(path)]) == 0
+    out = capsys.readouterr().out
+    assert "licenses: GPL-3.0-or-later, BSD-2-Clause" in out
+    assert "copyright: Copyright (C) 2019-2024 Example Project contributors" in out
@@ -38,6 +38,28 @@ def test_parse_luajit_detected() -> None:
     assert info["kind"] == "LuaJIT"
 
 
+@pytest.mark.parametrize(
+    "name", ["hello-lua5.1", "hello-lua5.2", "hello-lua5.3", "hello-lua5.4", "hello-luajit"]
+)
+def test_parse_lua_walks_functions(name: str) -> None:
+    binary = _need(Path(f"{_SAMPLES}/{name}.luac"))
+    if binary.read_bytes().startswith(b"version https://git-lfs"):
+        pytest.skip(f"git-lfs pointer {binary}")
+    info = g.analysis.parse_lua_bytecode_path(str(binary))
+    assert info is not None
+    functions = info["functions"]
+    assert functions[0]["parent"] is None
+    assert all(f["parent"] is not None for f in functions[1:])
+    # make_counter(start) is defined on line 8 of hello.lua and nests
+    # the counter closure.
+    make_counter = [f for f in functions if f["line_defined"] == 8]
+    assert len(make_counter) == 1
+    assert make_counter[0]["num_params"] == 1
+    assert make_counter[0]["num_children"] == 1
+    for s in ("Hello from Lua!", "glaurung", "Intentional error for testing"):
+        assert s in info["strings"]
+
+
 def test_parse_returns_none_on_native_binary() -> None:
     binary = _need(Path(
         "samples/binaries/platforms/linux/amd64/export/native/clang/debug/hello-clang-debug"
@@ -58,6 +80,8 @@ def test_luac_cli_renders_summary(tmp_path: Path) -> None:
     assert "engine:" in out
     assert "source:" in out
     assert "hello" in out
+    assert "functions:" in out
+    assert "[0] main" in out
 
 
 def test_luac_cli_json(tmp_path: Path) -> None:
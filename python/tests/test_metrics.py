@@ -0,0 +1,18 @@
+import glaurung as g
+
+
+def test_triage_updates_prometheus_counters():
+    g.enable_metrics()
+    g.reset_metrics()
+    g.triage.analyze_bytes(b"\x7fELF" + b"\x00" * 64)
+    text = g.metrics_prometheus()
+    assert "# TYPE glaurung_analyses_finished_total counter" in text
+    assert "glaurung_analyses_finished_total 1" in text
+    assert "glaurung_bytes_processed_total 0" not in text
+
+
+def test_reset_zeroes_counters():
+    g.enable_metrics()
+    g.triage.analyze_bytes(b"MZ" + b"\x00" * 62)
+    g.reset_metrics()
+    assert "glaurung_analyses_started_total 0" in g.metrics_prometheus()
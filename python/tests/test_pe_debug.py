@@ -0,0 +1,71 @@
+"""PE debug-directory summary, PDB path leaks and `glaurung pe debug`."""
+
+import json
+import struct
+
+import glaurung as g
+from glaurung import cli
+
+_PDB = rb"C:\Users\jdoe\src\Acme\Release\acme.pdb"
+
+
+def _pe_with_codeview(pdb_path: bytes) -> bytes:
+    """PE32 image with one section holding a CodeView RSDS debug entry."""
+    file_align = sect_rva = 0x200
+    cv = b"RSDS" + bytes(range(16)) + struct.pack("<I", 1) + pdb_path + b"\0"
+    debug = struct.pack(
+        "<IIHHIIII", 0, 0, 0, 0, 2, len(cv), sect_rva + 28, file_align + 28
+    )
+    body = (debug + cv).ljust(0x200, b"\0")
+    dos = b"MZ".ljust(0x3C, b"\0") + struct.pack("<I", 0x40)
+    coff = struct.pack("<HHIIIHH", 0x14C, 1, 0, 0, 0, 0xE0, 0x0102)
+    opt = struct.pack(
+        "<HBBIIIIIIIIIHHHHHHIIIIHHIIIIII",
+        0x10B, 14, 0, 0x200, 0, 0, sect_rva, sect_rva, 0, 0x400000,
+        sect_rva, file_align, 6, 0, 0, 0, 6, 0, 0, 0x400, 0x200, 0, 3, 0,
+        0x100000, 0x1000, 0x100000, 0x1000, 0, 16,
+    )  # fmt: skip
+    dirs = [(0, 0)] * 16
+    dirs[6] = (sect_rva, 28)  # IMAGE_DIRECTORY_ENTRY_DEBUG
+    opt += b"".join(struct.pack("<II", *d) for d in dirs)
+    sect = struct.pack(
+        "<8sIIIIIIHHI", b".rdata", 0x200, sect_rva, 0x200, file_align,
+        0, 0, 0, 0, 0x40000040,
+    )  # fmt: skip
+    head = (dos + b"PE\0\0" + coff + opt + sect).ljust(file_align, b"\0")
+    return head + body
+
+
+def test_triage_reports_pdb_path_and_leaks() -> None:
+    data = _pe_with_codeview(_PDB)
+    art = g.triage.analyze_bytes(data)
+    debug = art.format_specific.pe.debug
+    assert debug.entries == ["codeview"]
+    assert debug.pdb_path == _PDB.decode()
+    assert data[debug.pdb_path_offset :].startswith(_PDB)
+    assert [(leak.kind, leak.value) for leak in debug.leaks] == [
+        ("username", "jdoe"),
+        ("source_tree", r"Users\jdoe\src\Acme\Release"),
+    ]
+    claims = {f.claim: f.severity for f in art.findings if f.category == "pdb_path_leak"}
+    assert claims["username=jdoe"] == "medium"
+
+
+def test_relative_pdb_path_leaks_nothing() -> None:
+    art = g.triage.analyze_bytes(_pe_with_codeview(b"acme.pdb"))
+    assert art.format_specific.pe.debug.leaks == []
+
+
+def test_cli_pe_debug(tmp_path, capsys) -> None:
+    exe = tmp_path / "acme.exe"
+    exe.write_bytes(_pe_with_codeview(_PDB))
+
+    assert cli.main(["pe", "debug", str(exe)]) == 0
+    out = capsys.readouterr().out
+    assert f"pdb: {_PDB.decode()}" in out
+    assert "  username: jdoe" in out
+
+    assert cli.main(["pe", "debug", str(exe), "--json"]) == 0
+    payload = json.loads(capsys.readouterr().out)
+    assert payload["pdb_guid_age"].endswith("1")
+    assert {"kind": "username", "value": "jdoe"} in payload["leaks"]
@@ -0,0 +1,91 @@
+"""Tests for the CPython .pyc parser and `glaurung pyc`."""
+
+from __future__ import annotations
+
+import io
+import json
+from contextlib import redirect_stdout
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+
+
+def _need(p: Path) -> Path:
+    if not p.exists():
+        pytest.skip(f"missing {p}")
+    return p
+
+
+_SAMPLES = "samples/binaries/platforms/linux/amd64/export/python"
+
+
+@pytest.mark.parametrize("minor", [8, 10, 11, 12, 13])
+def test_parse_pyc_recovers_imports_and_strings(minor: int) -> None:
+    pyc = _need(Path(f"{_SAMPLES}/hello-py3.{minor}.pyc"))
+    info = g.analysis.parse_pyc_path(str(pyc))
+    assert info is not None
+    assert info["version"] == f"3.{minor}"
+    assert info["imports"] == ["os", "sys"]
+    assert "Hello, World from Python!" in info["strings"]
+    names = {c["name"] for c in info["code_objects"]}
+    assert {"HelloWorld", "print_message", "main"} <= names
+    assert all("instructions" not in c for c in info["code_objects"])
+
+
+def test_parse_pyc_disassembles() -> None:
+    pyc = _need(Path(f"{_SAMPLES}/hello-py3.11.pyc"))
+    info = g.analysis.parse_pyc_path(str(pyc), disassemble=True)
+    assert info is not None
+    main = next(c for c in info["code_objects"] if c["name"] == "main")
+    assert any("(calculate_arg_sum)" in line for line in main["instructions"])
+
+
+def test_parse_pyc_returns_none_on_native_binary() -> None:
+    binary = _need(Path(
+        "samples/binaries/platforms/linux/amd64/export/native/clang/debug/hello-clang-debug"
+    ))
+    assert g.analysis.parse_pyc_path(str(binary)) is None
+
+
+def test_pyc_cli_renders_summary() -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    pyc = _need(Path(f"{_SAMPLES}/hello-py3.11.pyc"))
+    cli = GlaurungCLI()
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = cli.run(["pyc", str(pyc), "--disasm"])
+    assert rc == 0
+    out = buf.getvalue()
+    assert "python: 3.11" in out
+    assert "imports: 2" in out
+    assert "LOAD_GLOBAL" in out
+
+
+def test_pyc_cli_json() -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    pyc = _need(Path(f"{_SAMPLES}/hello-py3.11.pyc"))
+    cli = GlaurungCLI()
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = cli.run(["pyc", str(pyc), "--format", "json"])
+    assert rc == 0
+    info = json.loads(buf.getvalue())
+    assert info["version"] == "3.11"
+
+
+def test_pyc_cli_rejects_non_pyc() -> None:
+    from glaurung.cli.main import GlaurungCLI
+
+    binary = _need(Path(
+        "samples/binaries/platforms/linux/amd64/export/native/clang/debug/hello-clang-debug"
+    ))
+    cli = GlaurungCLI()
+    buf = io.StringIO()
+    with redirect_stdout(buf):
+        rc = cli.run(["pyc", str(binary)])
+    assert rc == 4
+    assert "not Python bytecode" in buf.getvalue()
@@ -0,0 +1,63 @@
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+
+def _packed():
+    return b"MZ" + b"\x00" * 1024 + b"UPX!" + b"UPX0" + b"UPX1"
+
+
+def test_parse_severity():
+    assert g.triage.parse_severity(" High ") == "high"
+    with pytest.raises(ValueError):
+        g.triage.parse_severity("severe")
+
+
+def test_overrides_and_filtering():
+    art = g.triage.analyze_bytes(_packed())
+    packers = [f for f in art.findings or [] if f.category == "packer"]
+    if not packers:
+        pytest.skip("no packer finding for synthetic sample")
+    assert packers[0].severity == "medium"
+
+    bumped = art.with_severity_overrides({"packer": "critical"})
+    assert bumped.max_severity == "critical"
+    kept = bumped.filtered(min_severity="critical").findings
+    assert kept and all(f.severity == "critical" for f in kept)
+
+    with pytest.raises(ValueError):
+        art.with_severity_overrides({"packer": "loud"})
+
+
+def test_config_overrides_apply():
+    cfg = g.triage.TriageConfig()
+    f = g.triage.FindingsConfig()
+    f.severity_overrides = {"format": "high", "hint": "high", "packer": "high"}
+    cfg.findings = f
+    assert cfg.findings.severity_overrides["format"] == "high"
+    art = g.triage.analyze_bytes(_packed(), config=cfg._native)
+    assert all(f.severity in ("high", "low", "medium") for f in art.findings or [])
+
+
+def test_cli_fail_on(tmp_path):
+    sample = tmp_path / "x.bin"
+    sample.write_bytes(b"\x7fELF\x02\x01\x01" + b"\x00" * 57)
+    assert cli.main(["triage", str(sample), "--fail-on", "loud"]) == 2
+    assert cli.main(["triage", str(sample), "--severity", "bogus"]) == 2
+    assert cli.main(["triage", str(sample), "--fail-on", "critical"]) == 0
+    assert (
+        cli.main(
+            [
+                "triage",
+                str(sample),
+                "--severity",
+                "format=critical",
+                "--severity",
+                "hint=critical",
+                "--fail-on",
+                "critical",
+            ]
+        )
+        == 1
+    )
@@ -0,0 +1,53 @@
+"""Source-language classification in triage reports."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_EXPORT = Path("samples/binaries/platforms/linux/amd64/export")
+
+
+def _need(rel: str) -> Path:
+    p = _EXPORT / rel
+    # Git LFS pointers stand in for samples that were not fetched.
+    if not p.exists() or p.read_bytes()[:2] not in (b"\x7fE", b"MZ"):
+        pytest.skip(f"missing {p}")
+    return p
+
+
+@pytest.mark.parametrize(
+    "rel,language",
+    [
+        ("rust/hello-rust-release", "rust"),
+        ("go/hello-go", "go"),
+        ("native/gcc/debug/hello-cpp-g++-stripped", "cpp"),
+        ("native/gcc/debug/hello-c-gcc-stripped", "c"),
+        ("dotnet/mono/Hello-mono.exe", "dotnet"),
+    ],
+)
+def test_triage_classifies_source_language(rel: str, language: str) -> None:
+    art = g.triage.analyze_path(str(_need(rel)))
+    guess = art.source_language
+    assert guess.language == language
+    assert 0.0 < guess.confidence <= 1.0
+    assert all(p <= guess.confidence for _, p in guess.alternatives)
+    assert json.loads(art.to_json())["source_language"]["language"] == language
+
+
+def test_migrate_adds_source_language_slot() -> None:
+    old = json.loads(g.triage.analyze_bytes(b"\x7fELF" + b"\x00" * 64).to_json())
+    old.pop("source_language", None)
+    old["schema_version"] = "1.8"
+    new, steps = g.triage.migrate_report(json.dumps(old))
+    assert steps[0] == "1.8 -> 1.9: add source language slot"
+    assert json.loads(new)["source_language"] is None
+
+
+def test_cli_triage_prints_source_language(capsys) -> None:
+    path = _need("go/hello-go")
+    assert cli.main(["triage", str(path)]) == 0
+    assert "source language: go" in capsys.readouterr().out
@@ -0,0 +1,48 @@
+"""Struct recovery from field accesses, allocations, RTTI and DWARF."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_SYNTH = Path("samples/binaries/platforms/linux/amd64/synthetic")
+
+
+def _need(name: str) -> Path:
+    p = _SYNTH / name
+    # Git LFS pointers stand in for samples that were not fetched.
+    if not p.exists() or p.read_bytes()[:2] not in (b"\x7fE", b"MZ"):
+        pytest.skip(f"missing {p}")
+    return p
+
+
+def test_recover_structs_names_virtual_classes() -> None:
+    structs = json.loads(g.analysis.recover_structs_path(str(_need("poly-cpp-virtual"))))
+    assert structs
+    for s in structs:
+        assert s["declaration"].startswith(f"struct {s['name']} {{")
+        assert 0.0 < s["confidence"] <= 1.0
+        assert s["functions"]
+    classes = [s for s in structs if s["class"]]
+    assert classes
+    assert all(any(f["name"].startswith("vtable") for f in s["fields"]) for s in classes)
+
+
+def test_recover_structs_rejects_non_executables() -> None:
+    with pytest.raises(ValueError):
+        g.analysis.recover_structs_path("README.md")
+
+
+def test_cli_structs_missing_path() -> None:
+    assert cli.main(["structs", "/nonexistent/binary"]) == 2
+
+
+def test_cli_structs_json(capsys) -> None:
+    path = _need("poly-cpp-virtual")
+    assert cli.main(["structs", "--format", "json", str(path)]) == 0
+    out = json.loads(capsys.readouterr().out)
+    assert out["path"] == str(path)
+    assert all("declaration" in s for s in out["structs"])
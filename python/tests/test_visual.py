@@ -0,0 +1,48 @@
+"""Byte-map and digraph image rendering."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+import glaurung as g
+from glaurung import cli
+
+_ELF = Path("samples/binaries/platforms/linux/amd64/synthetic/vulnparse-c-gcc-O0")
+
+
+def _blob(tmp_path: Path) -> Path:
+    p = tmp_path / "blob.bin"
+    p.write_bytes(b"\x00" * 4096 + b"hello world " * 300 + bytes(range(256)) * 16)
+    return p
+
+
+def test_render_png_and_digraph(tmp_path) -> None:
+    path = str(_blob(tmp_path))
+    png = g.analysis.render_image_path(path, side=64)
+    assert png[:8] == b"\x89PNG\r\n\x1a\n"
+    assert int.from_bytes(png[16:20], "big") == 64  # IHDR width
+    digraph = g.analysis.render_image_path(path, kind="digraph")
+    assert int.from_bytes(digraph[16:20], "big") == 256
+    with pytest.raises(ValueError):
+        g.analysis.render_image_path(path, kind="spiral")
+
+
+def test_render_svg_outlines_sections() -> None:
+    if not _ELF.exists() or _ELF.read_bytes()[:2] != b"\x7fE":
+        pytest.skip(f"missing {_ELF}")
+    svg = g.analysis.render_image_path(str(_ELF), coloring="entropy", fmt="svg").decode()
+    assert svg.startswith("<svg")
+    assert ".text 0x" in svg
+    bare = g.analysis.render_image_path(str(_ELF), fmt="svg", sections=False).decode()
+    assert ".text 0x" not in bare
+
+
+def test_cli_visual_writes_image(tmp_path, capsys) -> None:
+    out = tmp_path / "map.svg"
+    args = ["visual", "--format", "json", "--kind", "scan", "-o", str(out), str(_blob(tmp_path))]
+    assert cli.main(args) == 0
+    report = json.loads(capsys.readouterr().out)
+    assert report["format"] == "svg"
+    assert out.read_text().startswith("<svg")
+    assert cli.main(["visual", str(tmp_path / "missing.bin")]) == 2
@@ -0,0 +1,159 @@
+#!/usr/bin/env bash
+#
+# Builds the benign anti-analysis samples: stack strings, XOR-encoded
+# strings, timing checks, and TLS callbacks (source/c/{stack_strings,
+# xor_strings,timing_checks,tls_callbacks}.c). Each one exercises a single
+# technique and documents, at the top of its source, what a detector should
+# recover; tests/anti_analysis.rs checks that the built binaries still
+# contain that evidence and still hide the plaintext.
+#
+# Compilers:
+#   gcc clang   host builds under linux/<arch>
+#   mingw       x86_64-w64-mingw32-gcc and i686-w64-mingw32-gcc, under
+#               windows/amd64 and windows/i386
+# tls_callbacks is Windows-only and is built by mingw alone. Missing
+# compilers are skipped with a warning.
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   native/anti-analysis/<sample>-<driver>-<opt>[.exe]
+#   metadata/<sample>-<driver>-<opt>[.exe].json
+#
+# Usage:
+#   ./build-anti-analysis.sh [--compilers "gcc clang mingw"] [--opts "O0 O2"]
+#                            [--samples "stack_strings xor_strings"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/c"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+COMPILERS="gcc clang mingw"
+OPTS="O0 O2"
+SAMPLES="stack_strings xor_strings timing_checks tls_callbacks"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --compilers) COMPILERS="$2"; shift 2 ;;
+        --opts) OPTS="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+host_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64" ;;
+        aarch64|arm64) echo "arm64" ;;
+        i?86) echo "i386" ;;
+        armv7*) echo "armhf" ;;
+        *) uname -m ;;
+    esac
+}
+
+# Drivers for a compiler family, as "driver:os/arch" pairs.
+drivers() {
+    case "$1" in
+        gcc) echo "gcc:linux/$(host_arch)" ;;
+        clang) echo "clang:linux/$(host_arch)" ;;
+        mingw) echo "x86_64-w64-mingw32-gcc:windows/amd64 i686-w64-mingw32-gcc:windows/i386" ;;
+        *) return 1 ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" sample="$2" driver="$3" flags="$4" platform="$5" out="$6"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/c/$sample.c",
+  "compiler": "$driver",
+  "compiler_version": "$("$driver" --version 2>/dev/null | head -n1)",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$flags",
+  "sha256": "$sha",
+  "description": "Benign anti-analysis sample ($sample) built with $driver $flags",
+  "timestamp": "$(date -Iseconds)",
+  "platform": "${platform%/*}",
+  "architecture": "${platform#*/}"
+}
+EOF
+}
+
+built=0
+skipped=0
+failed=0
+
+for family in $COMPILERS; do
+    if ! pairs="$(drivers "$family")"; then
+        warn "unknown compiler family: $family"
+        continue
+    fi
+    for pair in $pairs; do
+        driver="${pair%%:*}"
+        platform="${pair#*:}"
+        if ! command -v "$driver" &> /dev/null; then
+            warn "$driver not found; skipping $platform"
+            skipped=$((skipped + 1))
+            continue
+        fi
+        ext=""
+        [ "${platform%/*}" = "windows" ] && ext=".exe"
+        out_root="$PLATFORMS_DIR/$platform/export"
+        out_dir="$out_root/native/anti-analysis"
+        mkdir -p "$out_dir"
+
+        for sample in $SAMPLES; do
+            if [ "$sample" = "tls_callbacks" ] && [ -z "$ext" ]; then
+                continue
+            fi
+            src="$SOURCE_DIR/$sample.c"
+            if [ ! -f "$src" ]; then
+                error "no source for $sample"
+                failed=$((failed + 1))
+                continue
+            fi
+            if [ "$CLEAN" = 1 ]; then
+                rm -f "$out_dir/$sample-$driver-"* "$out_root/metadata/$sample-$driver-"*.json
+            fi
+            for opt in $OPTS; do
+                name="$sample-$driver-$opt$ext"
+                out="$out_dir/$name"
+                flags="-$opt -Wall -Wextra"
+                log "$platform $driver -$opt: $sample -> ${out#"$SCRIPT_DIR"/}"
+                # shellcheck disable=SC2086
+                if "$driver" $flags -o "$out" "$src"; then
+                    write_metadata "$out_root/metadata/$name.json" \
+                        "$sample" "$driver" "$flags" "$platform" "$out"
+                    built=$((built + 1))
+                else
+                    error "$driver -$opt $sample failed"
+                    failed=$((failed + 1))
+                fi
+            done
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
@@ -0,0 +1,225 @@
+#!/usr/bin/env bash
+#
+# Builds the C and C++ matrix samples with every available compiler family
+# (gcc, clang, msvc) at -O0 through -O3 and with link-time optimization, so
+# function discovery, demangling, and compiler identification are tested
+# against the variation real optimizers produce: inlining and cloning
+# (.constprop/.isra), jump tables, tail calls, cold splits, and LTO
+# internalization.
+#
+# gcc and clang build for the host and land under the host's linux/<arch>
+# directory; msvc (cl.exe on PATH, e.g. from a Developer Command Prompt or
+# the windows-msvc compose service used by build-msvc.sh) lands under
+# windows/<arch>. Missing compilers are skipped with a warning. Binaries keep
+# their symbols; msvc builds also write a PDB next to the executable.
+#
+# Optimization levels:
+#   O0 O1 O2 O3    -O<N> (msvc: /Od /O1 /O2 /Ox)
+#   O2-lto         -O2 -flto (msvc: /O2 /GL, linked with /LTCG)
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   native/matrix/<family>/<sample>-<driver>-<opt>[.exe]
+#   metadata/matrix/<sample>-<driver>-<opt>[.exe].json
+# where <driver> is gcc, g++, clang, clang++, or cl.
+#
+# Usage:
+#   ./build-c-matrix.sh [--compilers "gcc clang msvc"] [--opts "O0 O2-lto"]
+#                       [--samples "algos shapes hierarchy"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+COMPILERS="gcc clang msvc"
+OPTS="O0 O1 O2 O3 O2-lto"
+SAMPLES="hello algos shapes hierarchy exceptions"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --compilers) COMPILERS="$2"; shift 2 ;;
+        --opts) OPTS="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+host_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64" ;;
+        aarch64|arm64) echo "arm64" ;;
+        i?86) echo "i386" ;;
+        armv7*) echo "armhf" ;;
+        *) uname -m ;;
+    esac
+}
+
+msvc_arch() {
+    case "${VSCMD_ARG_TGT_ARCH:-x64}" in
+        x86) echo "i386" ;;
+        arm64) echo "arm64" ;;
+        *) echo "amd64" ;;
+    esac
+}
+
+cl_cmd() {
+    if command -v cl.exe &> /dev/null; then
+        echo "cl.exe"
+    elif command -v cl &> /dev/null; then
+        echo "cl"
+    fi
+}
+
+# driver FAMILY LANG: compiler driver for a family and source language.
+driver() {
+    case "$1:$2" in
+        gcc:c) echo "gcc" ;;
+        gcc:cpp) echo "g++" ;;
+        clang:c) echo "clang" ;;
+        clang:cpp) echo "clang++" ;;
+        msvc:*) cl_cmd ;;
+    esac
+}
+
+# opt_flags FAMILY OPT: compile (and link) flags for an optimization level.
+opt_flags() {
+    local family="$1" opt="$2"
+    if [ "$family" = "msvc" ]; then
+        case "$opt" in
+            O0) echo "/Od" ;;
+            O1) echo "/O1" ;;
+            O2) echo "/O2" ;;
+            O3) echo "/Ox" ;;
+            O2-lto) echo "/O2 /GL" ;;
+        esac
+    else
+        case "$opt" in
+            O[0-3]) echo "-$opt" ;;
+            O2-lto) echo "-O2 -flto" ;;
+        esac
+    fi
+}
+
+compiler_version() {
+    local cc="$1"
+    if [[ "$cc" == cl* ]]; then
+        "$cc" 2>&1 | head -n1 | tr -d '\r'
+    else
+        "$cc" --version | head -n1
+    fi
+}
+
+write_metadata() {
+    local meta_file="$1" src="$2" family="$3" cc="$4" opt="$5" flags="$6" out="$7" os="$8" arch="$9"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << JSON
+{
+  "source_file": "${src#"$SCRIPT_DIR"/}",
+  "compiler": "$cc",
+  "compiler_family": "$family",
+  "compiler_version": "$(compiler_version "$cc" | sed 's/"/\\"/g')",
+  "optimization": "$opt",
+  "lto": $([[ "$opt" == *-lto ]] && echo true || echo false),
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$flags",
+  "sha256": "$sha",
+  "platform": "$os",
+  "architecture": "$arch",
+  "description": "$family $opt matrix build",
+  "timestamp": "$(date -Iseconds)"
+}
+JSON
+}
+
+# build FAMILY CC SRC LANG OPT OUT
+build() {
+    local family="$1" cc="$2" src="$3" lang="$4" opt="$5" out="$6"
+    local flags
+    flags="$(opt_flags "$family" "$opt")"
+    if [ "$family" = "msvc" ]; then
+        local link="/link /DEBUG"
+        [[ "$opt" == *-lto ]] && link="$link /LTCG"
+        [ "$lang" = "cpp" ] && flags="$flags /EHsc /std:c++17"
+        # shellcheck disable=SC2086
+        "$cc" /nologo /Zi $flags /Fe:"$out" /Fd:"${out%.exe}.pdb" /Fo:"${out%.exe}.obj" "$src" $link \
+            && rm -f "${out%.exe}.obj"
+    else
+        local std="-std=c11"
+        [ "$lang" = "cpp" ] && std="-std=c++17"
+        # shellcheck disable=SC2086
+        "$cc" $std $flags -o "$out" "$src"
+    fi
+}
+
+built=0
+skipped=0
+failed=0
+
+for family in $COMPILERS; do
+    case "$family" in
+        gcc|clang) os="linux"; arch="$(host_arch)"; ext="" ;;
+        msvc) os="windows"; arch="$(msvc_arch)"; ext=".exe" ;;
+        *) warn "unknown compiler family: $family"; continue ;;
+    esac
+    out_root="$PLATFORMS_DIR/$os/$arch/export"
+    out_dir="$out_root/native/matrix/$family"
+
+    for sample in $SAMPLES; do
+        for lang in c cpp; do
+            src="$SOURCE_DIR/$lang/$sample.$lang"
+            [ -f "$src" ] || continue
+            cc="$(driver "$family" "$lang")"
+            if [ -z "$cc" ] || ! command -v "$cc" &> /dev/null; then
+                warn "${cc:-cl} not found; skipping $family $lang/$sample"
+                skipped=$((skipped + 1))
+                continue
+            fi
+            name_cc="${cc%.exe}"
+            if [ "$CLEAN" = 1 ]; then
+                rm -f "$out_dir/$sample-$name_cc-"* "$out_root/metadata/matrix/$sample-$name_cc-"*.json
+            fi
+            mkdir -p "$out_dir"
+            for opt in $OPTS; do
+                if [ -z "$(opt_flags "$family" "$opt")" ]; then
+                    warn "unknown optimization level: $opt"
+                    continue
+                fi
+                name="$sample-$name_cc-$opt$ext"
+                out="$out_dir/$name"
+                log "$family $opt: $lang/$sample -> ${out#"$SCRIPT_DIR"/}"
+                if build "$family" "$cc" "$src" "$lang" "$opt" "$out"; then
+                    write_metadata "$out_root/metadata/matrix/$name.json" "$src" "$family" "$cc" "$opt" \
+                        "$(opt_flags "$family" "$opt")" "$out" "$os" "$arch"
+                    built=$((built + 1))
+                else
+                    error "$family $opt $lang/$sample failed"
+                    failed=$((failed + 1))
+                fi
+            done
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
@@ -0,0 +1,185 @@
+#!/usr/bin/env bash
+#
+# Builds the cgo sample (source/go/cgo: Go calling C, C calling back into an
+# exported Go function) with the `go` on PATH. cgo forces external linking,
+# so the binary's layout differs from pure Go: the system linker places
+# C objects after the Go text, pclntab covers only [runtime.text,
+# runtime.etext), and cgo adds wrapper symbols (main._Cfunc_*, _cgoexp_*,
+# crosscall2) around every language crossing.
+#
+# Variants per target:
+#   (none)     default external link, with symbols
+#   stripped   -ldflags="-s -w"
+#   static     -ldflags="-extldflags=-static" (needs libc.a)
+#
+# Targets other than the host need a C cross compiler named
+# <triple>-gcc on PATH (e.g. aarch64-linux-gnu-gcc); targets without one are
+# skipped with a warning.
+#
+# Output (under samples/binaries/platforms/linux/<arch>/export/):
+#   go/cgo/<sample>-go<version>[-<variant>]
+#   metadata/<sample>-go<version>[-<variant>].json
+#
+# Usage:
+#   ./build-go-cgo.sh [--targets "linux/amd64 linux/arm64"]
+#                     [--variants "default stripped static"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/go"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+TARGETS=""
+VARIANTS="default stripped static"
+SAMPLES="cgo"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --targets) TARGETS="$2"; shift 2 ;;
+        --variants) VARIANTS="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+if ! command -v go &> /dev/null; then
+    error "go not found on PATH"
+    exit 1
+fi
+GO_VERSION="$(go env GOVERSION | sed 's/^go//')"
+HOST_TARGET="$(go env GOHOSTOS)/$(go env GOHOSTARCH)"
+[ -n "$TARGETS" ] || TARGETS="$HOST_TARGET"
+
+# Directory name used under binaries/platforms for a GOARCH.
+platform_arch() {
+    case "$1" in
+        386) echo "i386" ;;
+        arm) echo "armhf" ;;
+        *) echo "$1" ;;
+    esac
+}
+
+# C compiler for a linux target, or "".
+target_cc() {
+    if [ "$1" = "$HOST_TARGET" ]; then
+        echo "${CC:-gcc}"
+        return
+    fi
+    local triple
+    case "${1#*/}" in
+        amd64) triple="x86_64-linux-gnu" ;;
+        arm64) triple="aarch64-linux-gnu" ;;
+        386) triple="i686-linux-gnu" ;;
+        arm) triple="arm-linux-gnueabihf" ;;
+        riscv64) triple="riscv64-linux-gnu" ;;
+        *) return 0 ;;
+    esac
+    command -v "$triple-gcc" &> /dev/null && echo "$triple-gcc"
+    return 0
+}
+
+variant_ldflags() {
+    case "$1" in
+        default) echo "" ;;
+        stripped) echo "-s -w" ;;
+        static) echo "-extldflags=-static" ;;
+        *) return 1 ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" sample="$2" goos="$3" goarch="$4" cc="$5" ldflags="$6" out="$7"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/go/$sample",
+  "compiler": "go build",
+  "c_compiler": "$cc",
+  "go_version": "go$GO_VERSION",
+  "goos": "$goos",
+  "goarch": "$goarch",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "CGO_ENABLED=1 -trimpath -ldflags='$ldflags'",
+  "stripped": $([[ "$ldflags" == *-s* ]] && echo true || echo false),
+  "sha256": "$sha",
+  "description": "cgo Go $GO_VERSION $goos/$goarch sample",
+  "timestamp": "$(date -Iseconds)"
+}
+EOF
+}
+
+built=0
+skipped=0
+failed=0
+
+for target in $TARGETS; do
+    goos="${target%/*}"
+    goarch="${target#*/}"
+    if [ "$goos" != "linux" ]; then
+        warn "cgo builds are linux-only here; skipping $target"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    cc="$(target_cc "$target")"
+    if [ -z "$cc" ]; then
+        warn "no C compiler for $target; skipping"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    out_root="$PLATFORMS_DIR/$goos/$(platform_arch "$goarch")/export"
+    out_dir="$out_root/go/cgo"
+    mkdir -p "$out_dir"
+
+    for sample in $SAMPLES; do
+        src="$SOURCE_DIR/$sample"
+        if [ "$CLEAN" = 1 ]; then
+            rm -f "$out_dir/$sample-go$GO_VERSION"* "$out_root/metadata/$sample-go$GO_VERSION"*.json
+        fi
+        for variant in $VARIANTS; do
+            if ! ldflags="$(variant_ldflags "$variant")"; then
+                warn "unknown variant: $variant"
+                continue
+            fi
+            suffix=""
+            [ "$variant" = "default" ] || suffix="-$variant"
+            name="$sample-go$GO_VERSION$suffix"
+            out="$out_dir/$name"
+            log "$target $variant: go/$sample -> ${out#"$SCRIPT_DIR"/}"
+            if (cd "$src" && CGO_ENABLED=1 CC="$cc" GOOS="$goos" GOARCH="$goarch" GOTOOLCHAIN=local \
+                go build -trimpath -ldflags="$ldflags" -o "$out" .); then
+                write_metadata "$out_root/metadata/$name.json" \
+                    "$sample" "$goos" "$goarch" "$cc" "$ldflags" "$out"
+                built=$((built + 1))
+            elif [ "$variant" = "static" ]; then
+                warn "static cgo link failed for $target (no libc.a?); skipping"
+                skipped=$((skipped + 1))
+            else
+                error "$target $variant $sample failed"
+                failed=$((failed + 1))
+            fi
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
@@ -0,0 +1,191 @@
+#!/usr/bin/env bash
+#
+# Builds the Go samples with garble (mvdan.cc/garble) so pclntab recovery,
+# buildinfo extraction, and symbol parsing can be measured against
+# obfuscated binaries: hashed package, type, and function names, obfuscated
+# string literals (-literals), and removed position and panic information
+# (-tiny).
+#
+# garble is taken from PATH or $(go env GOPATH)/bin, or installed there at
+# $GARBLE_VERSION with `go install` (needs network access). garble only
+# supports recent Go releases, so the `go` on PATH must be one the chosen
+# garble release accepts. Builds use a fixed -seed so output is reproducible
+# for a given toolchain.
+#
+# Modes:
+#   plain      garble build
+#   literals   garble -literals build
+#   tiny       garble -tiny build
+#   full       garble -literals -tiny build
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   go/garble/<sample>-go<version>-garble[-<mode>][.exe]   (no suffix for plain)
+#   metadata/<sample>-go<version>-garble[-<mode>][.exe].json
+#
+# Usage:
+#   ./build-go-garble.sh [--targets "linux/amd64 windows/amd64"]
+#                        [--modes "plain literals"] [--samples "hello iface"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/go"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+GARBLE_VERSION="${GARBLE_VERSION:-v0.13.0}"
+SEED="Z2xhdXJ1bmc"
+
+TARGETS="linux/amd64 linux/arm64 windows/amd64 darwin/arm64"
+MODES="plain literals tiny full"
+SAMPLES="hello generics iface"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --targets) TARGETS="$2"; shift 2 ;;
+        --modes) MODES="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+if ! command -v go &> /dev/null; then
+    error "go not found on PATH"
+    exit 1
+fi
+GO_VERSION="$(go env GOVERSION | sed 's/^go//')"
+
+garble_cmd() {
+    local gobin
+    gobin="$(go env GOPATH)/bin/garble"
+    if command -v garble &> /dev/null; then
+        command -v garble
+    elif [ -x "$gobin" ]; then
+        echo "$gobin"
+    elif go install "mvdan.cc/garble@$GARBLE_VERSION" &> /dev/null && [ -x "$gobin" ]; then
+        echo "$gobin"
+    fi
+}
+
+GARBLE="$(garble_cmd)"
+if [ -z "$GARBLE" ]; then
+    error "garble not found and could not be installed (go install mvdan.cc/garble@$GARBLE_VERSION)"
+    exit 1
+fi
+GARBLE_REPORTED="$("$GARBLE" version 2> /dev/null | head -n1 || echo "$GARBLE_VERSION")"
+
+# Directory name used under binaries/platforms for a GOARCH.
+platform_arch() {
+    case "$1" in
+        386) echo "i386" ;;
+        arm) echo "armhf" ;;
+        *) echo "$1" ;;
+    esac
+}
+
+mode_flags() {
+    case "$1" in
+        plain) echo "" ;;
+        literals) echo "-literals" ;;
+        tiny) echo "-tiny" ;;
+        full) echo "-literals -tiny" ;;
+        *) return 1 ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" sample="$2" goos="$3" goarch="$4" mode="$5" flags="$6" out="$7"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/go/$sample.go",
+  "compiler": "garble build",
+  "garble_version": "$(sed 's/"/\\"/g' <<< "$GARBLE_REPORTED")",
+  "go_version": "go$GO_VERSION",
+  "goos": "$goos",
+  "goarch": "$goarch",
+  "obfuscation": "$mode",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "CGO_ENABLED=0 garble -seed=$SEED $flags build",
+  "sha256": "$sha",
+  "description": "garble-obfuscated Go $GO_VERSION $goos/$goarch sample",
+  "timestamp": "$(date -Iseconds)"
+}
+EOF
+}
+
+built=0
+failed=0
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+log "garble $GARBLE_REPORTED with Go $GO_VERSION"
+
+for target in $TARGETS; do
+    goos="${target%/*}"
+    goarch="${target#*/}"
+    out_root="$PLATFORMS_DIR/$goos/$(platform_arch "$goarch")/export"
+    out_dir="$out_root/go/garble"
+    if [ "$CLEAN" = 1 ]; then
+        rm -f "$out_dir"/*-go"$GO_VERSION"-garble* "$out_root"/metadata/*-go"$GO_VERSION"-garble*.json
+    fi
+    mkdir -p "$out_dir"
+    ext=""
+    [ "$goos" = "windows" ] && ext=".exe"
+
+    for sample in $SAMPLES; do
+        src="$SOURCE_DIR/$sample.go"
+        if [ ! -f "$src" ]; then
+            warn "no such sample: $src"
+            continue
+        fi
+        # garble wants a module; give each sample a throwaway one.
+        mod="$work/$sample"
+        mkdir -p "$mod"
+        cp "$src" "$mod/main.go"
+        [ -f "$mod/go.mod" ] || printf 'module glaurung.samples/%s\n\ngo 1.21\n' "$sample" > "$mod/go.mod"
+
+        for mode in $MODES; do
+            if ! flags="$(mode_flags "$mode")"; then
+                warn "unknown mode: $mode"
+                continue
+            fi
+            suffix="-garble"
+            [ "$mode" = "plain" ] || suffix="-garble-$mode"
+            name="$sample-go$GO_VERSION$suffix$ext"
+            out="$out_dir/$name"
+            # shellcheck disable=SC2086
+            if (cd "$mod" && CGO_ENABLED=0 GOOS="$goos" GOARCH="$goarch" GOTOOLCHAIN=local \
+                "$GARBLE" -seed="$SEED" $flags build -o "$out" .); then
+                write_metadata "$out_root/metadata/$name.json" \
+                    "$sample" "$goos" "$goarch" "$mode" "$flags" "$out"
+                built=$((built + 1))
+            else
+                error "garble $mode $target $sample failed"
+                failed=$((failed + 1))
+            fi
+        done
+    done
+done
+
+log "built $built binaries; $failed failed"
+[ "$failed" -eq 0 ]
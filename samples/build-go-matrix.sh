@@ -0,0 +1,228 @@
+#!/usr/bin/env bash
+#
+# Cross-compiles the Go samples for linux/windows/darwin x amd64/arm64/386/arm
+# across several Go releases, so the pclntab and buildinfo parsers are tested
+# against real layout variation: both pclntab magics (0xfffffff0 for Go
+# 1.18/1.19, 0xfffffff1 for 1.20+), 32- and 64-bit pointer sizes, and the
+# ELF, PE, and Mach-O containers.
+#
+# Each sample is built twice per target: with symbols, and stripped with
+# -ldflags="-s -w" (pclntab and buildinfo survive stripping).
+#
+# Toolchains: the `go` on PATH is used for its own version. Other releases
+# are fetched through GOTOOLCHAIN (needs Go >= 1.21 on PATH and network
+# access), or for releases older than 1.21 run in the golang:<version> Docker
+# image. Versions that cannot be obtained, and os/arch pairs a release does
+# not support (e.g. darwin/386), are skipped with a warning.
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   go/matrix/<sample>-go<version>[-stripped][.exe]
+#   metadata/<sample>-go<version>[-stripped][.exe].json
+#
+# Usage:
+#   ./build-go-matrix.sh [--versions "1.20.14 1.23.5"] [--targets "linux/amd64 windows/386"]
+#                        [--samples "hello generics"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/go"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+DEFAULT_VERSIONS="1.18.10 1.19.13 1.20.14 1.21.13 1.22.12 1.23.5"
+DEFAULT_TARGETS=""
+for os in linux windows darwin; do
+    for arch in amd64 arm64 386 arm; do
+        DEFAULT_TARGETS="$DEFAULT_TARGETS $os/$arch"
+    done
+done
+DEFAULT_SAMPLES="hello generics iface"
+
+VERSIONS="$DEFAULT_VERSIONS"
+TARGETS="$DEFAULT_TARGETS"
+SAMPLES="$DEFAULT_SAMPLES"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --versions) VERSIONS="$2"; shift 2 ;;
+        --targets) TARGETS="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+if ! command -v go &> /dev/null && ! command -v docker &> /dev/null; then
+    error "neither go nor docker found on PATH"
+    exit 1
+fi
+
+# Directory name used under binaries/platforms for a GOARCH.
+platform_arch() {
+    case "$1" in
+        386) echo "i386" ;;
+        arm) echo "armhf" ;;
+        *) echo "$1" ;;
+    esac
+}
+
+version_ge() {
+    # version_ge A B: true if A >= B (dotted numeric versions)
+    [ "$(printf '%s\n%s\n' "$2" "$1" | sort -V | head -n1)" = "$2" ]
+}
+
+LOCAL_VERSION=""
+if command -v go &> /dev/null; then
+    LOCAL_VERSION="$(go env GOVERSION 2> /dev/null | sed 's/^go//')"
+fi
+
+# How to run a given Go release: "local", "toolchain", "docker", or "".
+toolchain_mode() {
+    local v="$1"
+    if [ "$v" = "$LOCAL_VERSION" ]; then
+        echo "local"
+    elif [ -n "$LOCAL_VERSION" ] && version_ge "$LOCAL_VERSION" 1.21 && version_ge "$v" 1.21 \
+        && GOTOOLCHAIN="go$v" go version &> /dev/null; then
+        echo "toolchain"
+    elif command -v docker &> /dev/null && docker image inspect "golang:$v" &> /dev/null \
+        || { command -v docker &> /dev/null && docker pull -q "golang:$v" &> /dev/null; }; then
+        echo "docker"
+    else
+        echo ""
+    fi
+}
+
+# run_go MODE VERSION GOOS GOARCH ARGS...: run `go ARGS...` for a target.
+run_go() {
+    local mode="$1" v="$2" goos="$3" goarch="$4"
+    shift 4
+    local goarm=""
+    [ "$goarch" = "arm" ] && goarm=7
+    case "$mode" in
+        local)
+            CGO_ENABLED=0 GOOS="$goos" GOARCH="$goarch" GOARM="$goarm" GOTOOLCHAIN=local go "$@"
+            ;;
+        toolchain)
+            CGO_ENABLED=0 GOOS="$goos" GOARCH="$goarch" GOARM="$goarm" GOTOOLCHAIN="go$v" go "$@"
+            ;;
+        docker)
+            docker run --rm -u "$(id -u):$(id -g)" \
+                -e HOME=/tmp -e GOCACHE=/tmp/go-cache \
+                -e CGO_ENABLED=0 -e GOOS="$goos" -e GOARCH="$goarch" -e GOARM="$goarm" \
+                -v "$SCRIPT_DIR:/samples" -w /samples "golang:$v" go "$@"
+            ;;
+    esac
+}
+
+# Paths as the chosen toolchain sees them.
+tool_path() {
+    local mode="$1" path="$2"
+    if [ "$mode" = "docker" ]; then
+        echo "/samples/${path#"$SCRIPT_DIR"/}"
+    else
+        echo "$path"
+    fi
+}
+
+write_metadata() {
+    local meta_file="$1" sample="$2" v="$3" goos="$4" goarch="$5" ldflags="$6" out="$7"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/go/$sample.go",
+  "compiler": "go build",
+  "go_version": "go$v",
+  "goos": "$goos",
+  "goarch": "$goarch",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "CGO_ENABLED=0 -trimpath -ldflags='$ldflags'",
+  "stripped": $([ -n "$ldflags" ] && echo true || echo false),
+  "sha256": "$sha",
+  "description": "Go $v $goos/$goarch cross-compiled sample",
+  "timestamp": "$(date -Iseconds)"
+}
+EOF
+}
+
+built=0
+skipped=0
+failed=0
+
+for v in $VERSIONS; do
+    mode="$(toolchain_mode "$v")"
+    if [ -z "$mode" ]; then
+        warn "Go $v unavailable (no matching local go, GOTOOLCHAIN download, or golang:$v image); skipping"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    log "Go $v via $mode"
+    supported="$(run_go "$mode" "$v" "" "" tool dist list 2> /dev/null || true)"
+
+    for target in $TARGETS; do
+        goos="${target%/*}"
+        goarch="${target#*/}"
+        if ! grep -qx "$target" <<< "$supported"; then
+            warn "Go $v does not support $target; skipping"
+            skipped=$((skipped + 1))
+            continue
+        fi
+        out_root="$PLATFORMS_DIR/$goos/$(platform_arch "$goarch")/export"
+        out_dir="$out_root/go/matrix"
+        if [ "$CLEAN" = 1 ]; then
+            rm -f "$out_dir"/*-go"$v"* "$out_root"/metadata/*-go"$v"*.json
+        fi
+        mkdir -p "$out_dir"
+        ext=""
+        [ "$goos" = "windows" ] && ext=".exe"
+
+        for sample in $SAMPLES; do
+            src="$SOURCE_DIR/$sample.go"
+            if [ ! -f "$src" ]; then
+                warn "no such sample: $src"
+                continue
+            fi
+            for variant in symbols stripped; do
+                ldflags=""
+                suffix=""
+                if [ "$variant" = "stripped" ]; then
+                    ldflags="-s -w"
+                    suffix="-stripped"
+                fi
+                name="$sample-go$v$suffix$ext"
+                out="$out_dir/$name"
+                if run_go "$mode" "$v" "$goos" "$goarch" build -trimpath \
+                    -ldflags="$ldflags" \
+                    -o "$(tool_path "$mode" "$out")" "$(tool_path "$mode" "$src")"; then
+                    write_metadata "$out_root/metadata/$name.json" \
+                        "$sample" "$v" "$goos" "$goarch" "$ldflags" "$out"
+                    built=$((built + 1))
+                else
+                    error "Go $v $target $sample ($variant) failed"
+                    failed=$((failed + 1))
+                fi
+            done
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
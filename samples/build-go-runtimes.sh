@@ -0,0 +1,189 @@
+#!/usr/bin/env bash
+#
+# Builds the hello Go sample for the runtimes that do not look like the
+# gc toolchain's native binaries, so Go detection is tested where its usual
+# evidence (ELF/PE/Mach-O pclntab, the "Go buildinf:" blob, gc symbol
+# layout) is missing or moved:
+#   go      standard toolchain, GOOS=js and GOOS=wasip1 with GOARCH=wasm;
+#           pclntab lives in a data segment and names in the wasm name section
+#   tinygo  TinyGo (LLVM backend, its own runtime, no pclntab or buildinfo)
+#           for js/wasm, wasip1, and bare-metal microcontroller boards
+#
+# TinyGo is taken from PATH; when it is missing its builds are skipped with a
+# warning. Boards whose toolchain pieces TinyGo cannot find (e.g. avr-libc for
+# arduino) are skipped the same way.
+#
+# Output (under samples/binaries/platforms/):
+#   <js|wasip1>/wasm/export/go/wasm/hello-go<version>.wasm
+#   <js|wasip1>/wasm/export/go/tinygo/hello-tinygo<version>.wasm
+#   baremetal/<arch>/export/go/tinygo/hello-tinygo<version>-<board>.elf
+#   .../export/metadata/<name>.json next to each
+#
+# Usage:
+#   ./build-go-runtimes.sh [--compilers "go tinygo"] [--boards "pico arduino"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE="$SCRIPT_DIR/source/go/hello.go"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+COMPILERS="go tinygo"
+WASM_OSES="js wasip1"
+BOARDS="pico microbit-v2 arduino"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --compilers) COMPILERS="$2"; shift 2 ;;
+        --boards) BOARDS="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+# Directory name used under binaries/platforms/baremetal for a TinyGo board.
+board_arch() {
+    case "$1" in
+        pico|feather-rp2040|qtpy-rp2040) echo "armv6m" ;;
+        microbit|microbit-v2|feather-nrf52840|wioterminal|feather-m4) echo "armv7em" ;;
+        arduino|arduino-nano|arduino-mega2560) echo "avr" ;;
+        esp32c3|esp32c3-12f) echo "riscv32" ;;
+        *) echo "" ;;
+    esac
+}
+
+# TinyGo target for a wasm GOOS.
+tinygo_wasm_target() {
+    case "$1" in
+        js) echo "wasm" ;;
+        wasip1) echo "wasip1" ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" compiler="$2" version="$3" go_version="$4" target="$5" flags="$6" out="$7"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/go/hello.go",
+  "compiler": "$compiler",
+  "compiler_version": "$version",
+  "go_version": "$go_version",
+  "target": "$target",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$flags",
+  "sha256": "$sha",
+  "description": "hello built with $compiler $version for $target",
+  "timestamp": "$(date -Iseconds)"
+}
+EOF
+}
+
+built=0
+skipped=0
+failed=0
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+# build_one OUT META COMPILER VERSION GO_VERSION TARGET FLAGS OPTIONAL CMD...
+# OPTIONAL=1 turns a failed build into a skip (missing board support files).
+build_one() {
+    local out="$1" meta="$2" compiler="$3" version="$4" go_version="$5" target="$6" flags="$7"
+    local optional="$8"
+    shift 8
+    mkdir -p "$(dirname "$out")"
+    log "$compiler $target -> ${out#"$SCRIPT_DIR"/}"
+    if "$@" 2> "$work/log"; then
+        write_metadata "$meta" "$compiler" "$version" "$go_version" "$target" "$flags" "$out"
+        built=$((built + 1))
+    elif [ "$optional" = 1 ]; then
+        warn "$compiler cannot build for $target here: $(head -n1 "$work/log")"
+        skipped=$((skipped + 1))
+    else
+        cat "$work/log" >&2
+        error "$compiler $target failed"
+        failed=$((failed + 1))
+    fi
+}
+
+if ! command -v go &> /dev/null; then
+    error "go not found on PATH"
+    exit 1
+fi
+GO_VERSION="$(go env GOVERSION | sed 's/^go//')"
+
+for compiler in $COMPILERS; do
+    case "$compiler" in
+        go)
+            for goos in $WASM_OSES; do
+                root="$PLATFORMS_DIR/$goos/wasm/export"
+                name="hello-go$GO_VERSION.wasm"
+                [ "$CLEAN" = 0 ] || rm -f "$root/go/wasm/"* "$root/metadata/hello-go"*.wasm.json
+                build_one "$root/go/wasm/$name" "$root/metadata/$name.json" \
+                    "go build" "go$GO_VERSION" "go$GO_VERSION" "$goos/wasm" \
+                    "GOOS=$goos GOARCH=wasm -trimpath" 0 \
+                    env GOOS="$goos" GOARCH=wasm GOFLAGS= \
+                    go build -trimpath -o "$root/go/wasm/$name" "$SOURCE"
+            done
+            ;;
+        tinygo)
+            if ! command -v tinygo &> /dev/null; then
+                warn "tinygo not found on PATH; skipping TinyGo builds"
+                skipped=$((skipped + 1))
+                continue
+            fi
+            # "tinygo version 0.33.0 linux/amd64 (using go version go1.22.5 and ...)"
+            tg_version="$(tinygo version | awk '{print $3}')"
+            tg_go="$(tinygo version | sed -n 's/.*using go version \(go[0-9.]*\).*/\1/p')"
+            for goos in $WASM_OSES; do
+                target="$(tinygo_wasm_target "$goos")"
+                root="$PLATFORMS_DIR/$goos/wasm/export"
+                name="hello-tinygo$tg_version.wasm"
+                [ "$CLEAN" = 0 ] || rm -f "$root/go/tinygo/"* "$root/metadata/hello-tinygo"*.json
+                build_one "$root/go/tinygo/$name" "$root/metadata/$name.json" \
+                    tinygo "$tg_version" "$tg_go" "$target" "-target=$target" 1 \
+                    tinygo build -target="$target" -o "$root/go/tinygo/$name" "$SOURCE"
+            done
+            for board in $BOARDS; do
+                arch="$(board_arch "$board")"
+                if [ -z "$arch" ]; then
+                    warn "unknown board: $board"
+                    skipped=$((skipped + 1))
+                    continue
+                fi
+                root="$PLATFORMS_DIR/baremetal/$arch/export"
+                name="hello-tinygo$tg_version-$board.elf"
+                [ "$CLEAN" = 0 ] || rm -f "$root/go/tinygo/hello-tinygo"*"-$board.elf" \
+                    "$root/metadata/hello-tinygo"*"-$board.elf.json"
+                build_one "$root/go/tinygo/$name" "$root/metadata/$name.json" \
+                    tinygo "$tg_version" "$tg_go" "$board" "-target=$board" 1 \
+                    tinygo build -target="$board" -o "$root/go/tinygo/$name" "$SOURCE"
+            done
+            ;;
+        *)
+            warn "unknown compiler: $compiler"
+            ;;
+    esac
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
@@ -0,0 +1,197 @@
+#!/usr/bin/env bash
+#
+# Builds the Zig, Nim, D, and Haskell samples (source/{zig,nim,d,haskell}/
+# hello.*) for the host with whichever of their compilers are on PATH. Each
+# language brings a runtime that C-oriented compiler identification gets
+# wrong: Zig uses dotted unmangled names and its own start code, Nim goes
+# through gcc with a Nim runtime on top, D has its own mangling and
+# druntime, and GHC emits STG-machine code around the Haskell RTS.
+#
+# Compilers per language:
+#   zig       zig            (sources target Zig 0.13)
+#   nim       nim            (C backend, gcc unless NIM_CC is set)
+#   d         ldc2 dmd gdc   (each one found is built)
+#   haskell   ghc
+#
+# Modes:
+#   debug     no optimization, debug info
+#   release   optimized, symbols kept
+#
+# Output (under samples/binaries/platforms/linux/<arch>/export/):
+#   <lang>/hello-<compiler>-<mode>
+#   metadata/hello-<compiler>-<mode>.json
+#
+# Usage:
+#   ./build-lang-samples.sh [--langs "zig nim d haskell"] [--modes "debug release"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+LANGS="zig nim d haskell"
+MODES="debug release"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --langs) LANGS="$2"; shift 2 ;;
+        --modes) MODES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+host_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64" ;;
+        aarch64|arm64) echo "arm64" ;;
+        i?86) echo "i386" ;;
+        armv7*) echo "armhf" ;;
+        *) uname -m ;;
+    esac
+}
+
+compilers() {
+    case "$1" in
+        zig) echo "zig" ;;
+        nim) echo "nim" ;;
+        d) echo "ldc2 dmd gdc" ;;
+        haskell) echo "ghc" ;;
+        *) return 1 ;;
+    esac
+}
+
+source_file() {
+    case "$1" in
+        zig) echo "$SOURCE_DIR/zig/hello.zig" ;;
+        nim) echo "$SOURCE_DIR/nim/hello.nim" ;;
+        d) echo "$SOURCE_DIR/d/hello.d" ;;
+        haskell) echo "$SOURCE_DIR/haskell/hello.hs" ;;
+    esac
+}
+
+# flags COMPILER MODE: compiler-specific flags for a mode.
+flags() {
+    case "$1:$2" in
+        zig:debug) echo "-O Debug" ;;
+        zig:release) echo "-O ReleaseFast" ;;
+        nim:debug) echo "--debugger:native --opt:none" ;;
+        nim:release) echo "-d:release --debuginfo:off" ;;
+        ldc2:debug|gdc:debug) echo "-O0 -g" ;;
+        ldc2:release|gdc:release) echo "-O2" ;;
+        dmd:debug) echo "-g" ;;
+        dmd:release) echo "-O -release -inline" ;;
+        ghc:debug) echo "-O0 -g" ;;
+        ghc:release) echo "-O2" ;;
+        *) return 1 ;;
+    esac
+}
+
+compiler_version() {
+    case "$1" in
+        zig) zig version ;;
+        ghc) ghc --numeric-version ;;
+        *) "$1" --version 2>/dev/null | head -n1 ;;
+    esac
+}
+
+# compile COMPILER FLAGS SRC OUT WORKDIR
+compile() {
+    local cc="$1" fl="$2" src="$3" out="$4" work="$5"
+    # shellcheck disable=SC2086
+    case "$cc" in
+        zig) (cd "$work" && zig build-exe $fl -femit-bin="$out" "$src") ;;
+        nim) nim c $fl --cc:"${NIM_CC:-gcc}" --hints:off --nimcache:"$work" -o:"$out" "$src" ;;
+        ldc2|dmd) "$cc" $fl -od="$work" -of="$out" "$src" ;;
+        gdc) gdc $fl -o "$out" "$src" ;;
+        ghc) ghc $fl -outputdir "$work" -o "$out" "$src" ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" lang="$2" cc="$3" fl="$4" src="$5" out="$6"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "${src#"$SCRIPT_DIR"/}",
+  "language": "$lang",
+  "compiler": "$cc",
+  "compiler_version": "$(compiler_version "$cc")",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$fl",
+  "sha256": "$sha",
+  "description": "$lang hello built with $cc $fl",
+  "timestamp": "$(date -Iseconds)",
+  "platform": "linux",
+  "architecture": "$(host_arch)"
+}
+EOF
+}
+
+out_root="$PLATFORMS_DIR/linux/$(host_arch)/export"
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+built=0
+skipped=0
+failed=0
+
+for lang in $LANGS; do
+    if ! ccs="$(compilers "$lang")"; then
+        warn "unknown language: $lang"
+        continue
+    fi
+    src="$(source_file "$lang")"
+    out_dir="$out_root/$lang"
+    for cc in $ccs; do
+        if ! command -v "$cc" &> /dev/null; then
+            warn "$cc not found; skipping $lang"
+            skipped=$((skipped + 1))
+            continue
+        fi
+        mkdir -p "$out_dir"
+        if [ "$CLEAN" = 1 ]; then
+            rm -f "$out_dir/hello-$cc-"* "$out_root/metadata/hello-$cc-"*.json
+        fi
+        for mode in $MODES; do
+            if ! fl="$(flags "$cc" "$mode")"; then
+                warn "unknown mode: $mode"
+                continue
+            fi
+            name="hello-$cc-$mode"
+            out="$out_dir/$name"
+            log "$lang $cc $mode -> ${out#"$SCRIPT_DIR"/}"
+            rm -rf "${work:?}"/*
+            if compile "$cc" "$fl" "$src" "$out" "$work"; then
+                write_metadata "$out_root/metadata/$name.json" "$lang" "$cc" "$fl" "$src" "$out"
+                built=$((built + 1))
+            else
+                error "$cc $mode failed"
+                failed=$((failed + 1))
+            fi
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
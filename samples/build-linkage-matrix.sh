@@ -0,0 +1,226 @@
+#!/usr/bin/env bash
+#
+# Builds the C and Go samples in every linkage mode the host toolchains
+# support, so library signature matching (libc code linked into the image),
+# dependency reporting (PT_INTERP, DT_NEEDED), and hardening audits (PIE,
+# RELRO) are exercised on each, not only on the default dynamic build.
+#
+# Linkage modes:
+#   dynamic        gcc; Go with cgo and -linkmode=external against glibc
+#   static         gcc -static; Go external link with -extldflags=-static
+#   static-pie     gcc -static-pie (C only)
+#   musl-dynamic   musl-gcc (C only)
+#   musl-static    musl-gcc -static; Go external link through musl-gcc
+#   static-nocgo   CGO_ENABLED=0 internal link, no libc at all (Go only)
+#
+# Modes whose toolchain is missing (musl-gcc, a libc.a for -static, a cgo
+# capable go) are skipped with a warning. Builds are for the host only and
+# land under its linux/<arch> directory.
+#
+# Output (under samples/binaries/platforms/linux/<arch>/export/):
+#   linkage/<sample>-<lang>-<mode>
+#   metadata/linkage/<sample>-<lang>-<mode>.json
+# where <lang> is c or go.
+#
+# Usage:
+#   ./build-linkage-matrix.sh [--modes "dynamic static musl-static"]
+#                             [--samples "hello algos"] [--opt O2] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+MODES="dynamic static static-pie musl-dynamic musl-static static-nocgo"
+SAMPLES="hello algos"
+OPT="O2"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --modes) MODES="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --opt) OPT="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+host_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64" ;;
+        aarch64|arm64) echo "arm64" ;;
+        i?86) echo "i386" ;;
+        armv7*) echo "armhf" ;;
+        *) uname -m ;;
+    esac
+}
+
+OUT_ROOT="$PLATFORMS_DIR/linux/$(host_arch)/export"
+OUT_DIR="$OUT_ROOT/linkage"
+META_DIR="$OUT_ROOT/metadata/linkage"
+
+# c_compiler MODE / c_ldflags MODE: driver and link flags for a C mode.
+c_compiler() {
+    case "$1" in
+        dynamic|static|static-pie) echo "gcc" ;;
+        musl-dynamic|musl-static) echo "musl-gcc" ;;
+        *) echo "" ;;
+    esac
+}
+
+c_ldflags() {
+    case "$1" in
+        static|musl-static) echo "-static" ;;
+        static-pie) echo "-static-pie" ;;
+        *) echo "" ;;
+    esac
+}
+
+# go_env MODE / go_ldflags MODE: environment and -ldflags for a Go mode.
+go_env() {
+    case "$1" in
+        dynamic|static) echo "CGO_ENABLED=1 CC=gcc" ;;
+        musl-static) echo "CGO_ENABLED=1 CC=musl-gcc" ;;
+        static-nocgo) echo "CGO_ENABLED=0" ;;
+        *) echo "" ;;
+    esac
+}
+
+go_ldflags() {
+    case "$1" in
+        dynamic) echo "-linkmode=external" ;;
+        static|musl-static) echo "-linkmode=external -extldflags=-static" ;;
+        *) echo "" ;;
+    esac
+}
+
+libc_of() {
+    case "$1" in
+        musl-*) echo "musl" ;;
+        static-nocgo) echo "none" ;;
+        *) echo "glibc" ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" src="$2" lang="$3" mode="$4" compiler="$5" flags="$6" out="$7"
+    local sha="" interp=false
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    if command -v readelf &> /dev/null && readelf -lW "$out" 2> /dev/null | grep -q ' INTERP '; then
+        interp=true
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << JSON
+{
+  "source_file": "${src#"$SCRIPT_DIR"/}",
+  "language": "$lang",
+  "linkage": "$mode",
+  "libc": "$(libc_of "$mode")",
+  "interpreter": $interp,
+  "compiler": "$compiler",
+  "compilation_flags": "$flags",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "sha256": "$sha",
+  "platform": "linux",
+  "architecture": "$(host_arch)",
+  "description": "$lang $mode linkage variant",
+  "timestamp": "$(date -Iseconds)"
+}
+JSON
+}
+
+built=0
+skipped=0
+failed=0
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+if [ "$CLEAN" = 1 ]; then
+    rm -rf "$OUT_DIR" "$META_DIR"
+fi
+mkdir -p "$OUT_DIR"
+
+for sample in $SAMPLES; do
+    for lang in c go; do
+        src="$SOURCE_DIR/$lang/$sample.$lang"
+        [ -f "$src" ] || continue
+        for mode in $MODES; do
+            name="$sample-$lang-$mode"
+            out="$OUT_DIR/$name"
+            if [ "$lang" = "c" ]; then
+                cc="$(c_compiler "$mode")"
+                [ -n "$cc" ] || continue
+                if ! command -v "$cc" &> /dev/null; then
+                    warn "$cc not found; skipping $name"
+                    skipped=$((skipped + 1))
+                    continue
+                fi
+                flags="-$OPT $(c_ldflags "$mode")"
+                flags="${flags% }"
+                log "$mode: c/$sample -> ${out#"$SCRIPT_DIR"/}"
+                # shellcheck disable=SC2086
+                if "$cc" -std=c11 $flags -o "$out" "$src" 2> "$work/log"; then
+                    write_metadata "$META_DIR/$name.json" "$src" c "$mode" "$cc" "$flags" "$out"
+                    built=$((built + 1))
+                elif grep -qE 'cannot find -lc|musl' "$work/log"; then
+                    # No libc.a (or a broken musl install): a missing toolchain, not a bad build.
+                    warn "$mode unsupported by the host toolchain; skipping $name"
+                    skipped=$((skipped + 1))
+                else
+                    cat "$work/log" >&2
+                    error "$name failed"
+                    failed=$((failed + 1))
+                fi
+            else
+                env_vars="$(go_env "$mode")"
+                [ -n "$env_vars" ] || continue
+                if ! command -v go &> /dev/null; then
+                    warn "go not found; skipping $name"
+                    skipped=$((skipped + 1))
+                    continue
+                fi
+                if [[ "$env_vars" == *CC=musl-gcc* ]] && ! command -v musl-gcc &> /dev/null; then
+                    warn "musl-gcc not found; skipping $name"
+                    skipped=$((skipped + 1))
+                    continue
+                fi
+                ldflags="$(go_ldflags "$mode")"
+                log "$mode: go/$sample -> ${out#"$SCRIPT_DIR"/}"
+                # shellcheck disable=SC2086
+                if env $env_vars GOFLAGS= go build -trimpath -ldflags="$ldflags" -o "$out" "$src" 2> "$work/log"; then
+                    write_metadata "$META_DIR/$name.json" "$src" go "$mode" "go build" \
+                        "$env_vars -ldflags='$ldflags'" "$out"
+                    built=$((built + 1))
+                elif grep -qE 'cannot find -lc|C compiler .* not found|cgo' "$work/log"; then
+                    warn "$mode unsupported by the host toolchain; skipping $name"
+                    skipped=$((skipped + 1))
+                else
+                    cat "$work/log" >&2
+                    error "$name failed"
+                    failed=$((failed + 1))
+                fi
+            fi
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
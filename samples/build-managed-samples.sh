@@ -0,0 +1,368 @@
+#!/usr/bin/env bash
+#
+# Builds the managed-code inventory samples (source/csharp/inventory/ and
+# source/java/inventory/) in the packagings the CIL and JVM parsers meet in
+# the wild. The same source backs every variant, so metadata tables and
+# class files are comparable across them; tests/managed_samples.rs checks
+# the results.
+#
+# .NET variants (dotnet SDK, or csc/mcs for framework):
+#   framework       .NET Framework 4.x exe, from csc or mcs if found, else
+#                   dotnet with net48 reference assemblies
+#   core            framework-dependent IL assembly per --tfms
+#   self-contained  single-file apphost bundle per --tfms and --rids
+#   r2r             ReadyToRun (IL plus precompiled native code) per
+#                   --tfms and --rids
+# Everything but core restores runtime packs from NuGet, so offline
+# machines can only build core.
+#
+# Java variants (per JDK found, 11+):
+#   classes         loose class files of the app, module-info included
+#   fat-jar         app plus the ledger library in one runnable JAR
+#   jlink           runtime image with both modules and an inventory launcher
+# JDKs are located from JAVA_HOME_<N>, then /usr/lib/jvm/*-<N>-*; without
+# --jdks, the javac on PATH is used.
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   dotnet/framework/Inventory-<compiler>.exe
+#   dotnet/core/Inventory-<tfm>.dll
+#   dotnet/self-contained/Inventory-<tfm>-<rid>[.exe]
+#   dotnet/r2r/Inventory-<tfm>-<rid>.dll
+#   java/inventory/jdk<N>/{classes/,inventory-fat.jar,image/}
+#   metadata/<.NET artifact name>.json, metadata/Inventory-<variant>-jdk<N>.json
+# Host builds go under the host platform, RID builds under the RID's.
+#
+# Usage:
+#   ./build-managed-samples.sh [--variants "framework core self-contained r2r classes fat-jar jlink"]
+#                              [--tfms "net8.0"] [--rids "linux-x64 win-x64"]
+#                              [--jdks "11 17 21"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+CSHARP_DIR="$SCRIPT_DIR/source/csharp/inventory"
+JAVA_DIR="$SCRIPT_DIR/source/java/inventory"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+VARIANTS="framework core self-contained r2r classes fat-jar jlink"
+TFMS="net8.0"
+RIDS=""
+JDKS=""
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --variants) VARIANTS="$2"; shift 2 ;;
+        --tfms) TFMS="$2"; shift 2 ;;
+        --rids) RIDS="$2"; shift 2 ;;
+        --jdks) JDKS="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+host_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64" ;;
+        aarch64|arm64) echo "arm64" ;;
+        i?86) echo "i386" ;;
+        armv7*) echo "armhf" ;;
+        *) uname -m ;;
+    esac
+}
+
+host_os() {
+    case "$(uname -s)" in
+        Linux) echo "linux" ;;
+        Darwin) echo "darwin" ;;
+        MINGW*|MSYS*|CYGWIN*) echo "windows" ;;
+        *) uname -s | tr '[:upper:]' '[:lower:]' ;;
+    esac
+}
+
+host_rid() {
+    local os arch
+    case "$(host_os)" in
+        windows) os="win" ;;
+        darwin) os="osx" ;;
+        *) os="$(host_os)" ;;
+    esac
+    case "$(host_arch)" in
+        amd64) arch="x64" ;;
+        i386) arch="x86" ;;
+        armhf) arch="arm" ;;
+        *) arch="$(host_arch)" ;;
+    esac
+    echo "$os-$arch"
+}
+
+# rid_platform RID: the <os>/<arch> directory for a .NET runtime identifier.
+rid_platform() {
+    local os arch
+    case "${1%-*}" in
+        win) os="windows" ;;
+        osx) os="darwin" ;;
+        linux|linux-musl) os="linux" ;;
+        *) return 1 ;;
+    esac
+    case "${1##*-}" in
+        x64) arch="amd64" ;;
+        x86) arch="i386" ;;
+        arm64) arch="arm64" ;;
+        arm) arch="armhf" ;;
+        *) return 1 ;;
+    esac
+    echo "$os/$arch"
+}
+
+if [ -z "$RIDS" ]; then
+    RIDS="$(host_rid)"
+    [ "$RIDS" = "win-x64" ] || RIDS="$RIDS win-x64"
+fi
+
+write_metadata() {
+    local meta_file="$1" src="$2" compiler="$3" version="$4" flags="$5" desc="$6" platform="$7" out="$8"
+    local sha=""
+    if [ -f "$out" ] && command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "${src#"$SCRIPT_DIR"/}",
+  "compiler": "$compiler",
+  "compiler_version": "$version",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$flags",
+  "sha256": "$sha",
+  "description": "$desc",
+  "timestamp": "$(date -Iseconds)",
+  "platform": "${platform%/*}",
+  "architecture": "${platform#*/}"
+}
+EOF
+}
+
+wants() {
+    case " $VARIANTS " in
+        *" $1 "*) return 0 ;;
+        *) return 1 ;;
+    esac
+}
+
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+host_platform="$(host_os)/$(host_arch)"
+
+built=0
+skipped=0
+failed=0
+
+# dotnet_publish NAME PRODUCED OUT PLATFORM DESC ARGS...: publishes a scratch
+# copy of the project and copies the PRODUCED file from it to OUT.
+dotnet_publish() {
+    local name="$1" produced="$2" out="$3" platform="$4" desc="$5"
+    shift 5
+    local proj="$work/dotnet-$name"
+    rm -rf "$proj"
+    cp -r "$CSHARP_DIR" "$proj"
+    log "dotnet $name -> ${out#"$SCRIPT_DIR"/}"
+    if dotnet publish "$proj/Inventory.csproj" -c Release -nologo -o "$proj/out" "$@" \
+        && cp "$proj/out/$produced" "$out"; then
+        write_metadata "$PLATFORMS_DIR/$platform/export/metadata/$(basename "$out").json" \
+            "$CSHARP_DIR/Inventory.cs" "dotnet" "$(dotnet --version)" "$*" "$desc" "$platform" "$out"
+        built=$((built + 1))
+    else
+        error "dotnet $name failed"
+        failed=$((failed + 1))
+    fi
+}
+
+dotnet_root() {
+    local root="$PLATFORMS_DIR/$1/export/dotnet"
+    mkdir -p "$root/$2"
+    if [ "$CLEAN" = 1 ]; then
+        rm -f "$root/$2/Inventory-"*
+    fi
+    echo "$root/$2"
+}
+
+if wants framework; then
+    dir="$(dotnet_root "$host_platform" framework)"
+    if command -v csc &> /dev/null || command -v mcs &> /dev/null; then
+        cs="$(command -v csc &> /dev/null && echo csc || echo mcs)"
+        out="$dir/Inventory-$cs.exe"
+        flags="-optimize+ -target:exe"
+        log "$cs framework -> ${out#"$SCRIPT_DIR"/}"
+        # shellcheck disable=SC2086
+        if "$cs" $flags -out:"$out" "$CSHARP_DIR/Inventory.cs"; then
+            write_metadata "$PLATFORMS_DIR/$host_platform/export/metadata/Inventory-$cs.exe.json" \
+                "$CSHARP_DIR/Inventory.cs" "$cs" "$("$cs" -version 2>/dev/null | head -n1)" \
+                "$flags" ".NET Framework inventory sample built with $cs" "$host_platform" "$out"
+            built=$((built + 1))
+        else
+            error "$cs framework failed"
+            failed=$((failed + 1))
+        fi
+    elif command -v dotnet &> /dev/null; then
+        dotnet_publish framework Inventory.exe "$dir/Inventory-dotnet-net48.exe" "$host_platform" \
+            ".NET Framework 4.8 inventory sample" -p:TargetFramework=net48
+    else
+        warn "no csc, mcs, or dotnet; skipping framework"
+        skipped=$((skipped + 1))
+    fi
+fi
+
+for variant in core self-contained r2r; do
+    wants "$variant" || continue
+    if ! command -v dotnet &> /dev/null; then
+        warn "dotnet not found; skipping $variant"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    for tfm in $TFMS; do
+        if [ "$variant" = core ]; then
+            dir="$(dotnet_root "$host_platform" core)"
+            dotnet_publish "core-$tfm" Inventory.dll "$dir/Inventory-$tfm.dll" "$host_platform" \
+                "framework-dependent $tfm inventory sample" -p:TargetFramework="$tfm" -p:UseAppHost=false
+            continue
+        fi
+        for rid in $RIDS; do
+            if ! platform="$(rid_platform "$rid")"; then
+                warn "unknown RID: $rid"
+                continue
+            fi
+            ext=""
+            [ "${platform%/*}" = "windows" ] && ext=".exe"
+            dir="$(dotnet_root "$platform" "$variant")"
+            if [ "$variant" = self-contained ]; then
+                dotnet_publish "self-contained-$tfm-$rid" "Inventory$ext" "$dir/Inventory-$tfm-$rid$ext" "$platform" \
+                    "self-contained single-file $tfm inventory sample for $rid" \
+                    -p:TargetFramework="$tfm" -r "$rid" --self-contained -p:PublishSingleFile=true
+            else
+                dotnet_publish "r2r-$tfm-$rid" Inventory.dll "$dir/Inventory-$tfm-$rid.dll" "$platform" \
+                    "ReadyToRun $tfm inventory sample for $rid" \
+                    -p:TargetFramework="$tfm" -r "$rid" --no-self-contained -p:PublishReadyToRun=true
+            fi
+        done
+    done
+done
+
+# java_homes: "version:home" for each requested JDK, or for the javac on PATH.
+java_homes() {
+    local v home
+    if [ -z "$JDKS" ]; then
+        command -v javac &> /dev/null || return 0
+        home="$(dirname "$(dirname "$(readlink -f "$(command -v javac)")")")"
+        v="$(javac -version 2>&1 | awk '{print $2}' | cut -d. -f1)"
+        echo "$v:$home"
+        return 0
+    fi
+    for v in $JDKS; do
+        home="$(printenv "JAVA_HOME_$v" || true)"
+        if [ -z "$home" ]; then
+            home="$(find /usr/lib/jvm -maxdepth 1 -name "*-$v-*" 2>/dev/null | sort | head -n1)"
+        fi
+        if [ -n "$home" ] && [ -x "$home/bin/javac" ]; then
+            echo "$v:$home"
+        else
+            warn "JDK $v not found"
+        fi
+    done
+}
+
+if wants classes || wants fat-jar || wants jlink; then
+    homes="$(java_homes)"
+    if [ -z "$homes" ]; then
+        warn "no JDK found; skipping Java"
+        skipped=$((skipped + 1))
+    elif [ -n "$JDKS" ]; then
+        skipped=$((skipped + $(echo "$JDKS" | wc -w) - $(echo "$homes" | wc -w)))
+    fi
+    main="com.glaurung.samples.app.Inventory"
+    for pair in $homes; do
+        v="${pair%%:*}"
+        home="${pair#*:}"
+        javac_version="$("$home/bin/javac" -version 2>&1)"
+        jdir="$PLATFORMS_DIR/$host_platform/export/java/inventory/jdk$v"
+        meta="$PLATFORMS_DIR/$host_platform/export/metadata"
+        if [ "$CLEAN" = 1 ]; then
+            rm -rf "$jdir" "$meta/Inventory-"*"-jdk$v.json"
+        fi
+        mkdir -p "$jdir"
+        mods="$work/jdk$v"
+        rm -rf "$mods"
+        log "javac (JDK $v) -> ${jdir#"$SCRIPT_DIR"/}"
+        # shellcheck disable=SC2046
+        if ! "$home/bin/javac" -d "$mods/lib" $(find "$JAVA_DIR/lib" -name '*.java') \
+            || ! "$home/bin/javac" --module-path "$mods/lib" -d "$mods/app" \
+                $(find "$JAVA_DIR/app" -name '*.java'); then
+            error "javac (JDK $v) failed"
+            failed=$((failed + 1))
+            continue
+        fi
+        src="$JAVA_DIR/app/com/glaurung/samples/app/Inventory.java"
+
+        if wants classes; then
+            rm -rf "$jdir/classes"
+            cp -r "$mods/app" "$jdir/classes"
+            write_metadata "$meta/Inventory-classes-jdk$v.json" "$src" "javac" "$javac_version" "" \
+                "Inventory app classes (JDK $v)" "$host_platform" "$jdir/classes/${main//.//}.class"
+            built=$((built + 1))
+        fi
+
+        if wants fat-jar; then
+            # Shading drops the libraries' module descriptors; the fat JAR
+            # runs from the class path.
+            fat="$mods/fat"
+            mkdir -p "$fat"
+            cp -r "$mods/lib/." "$mods/app/." "$fat/"
+            rm -f "$fat/module-info.class"
+            out="$jdir/inventory-fat.jar"
+            if "$home/bin/jar" --create --file "$out" --main-class "$main" -C "$fat" .; then
+                write_metadata "$meta/Inventory-fat-jar-jdk$v.json" "$src" "javac+jar" "$javac_version" \
+                    "--main-class $main" "Inventory fat JAR with the ledger library (JDK $v)" \
+                    "$host_platform" "$out"
+                built=$((built + 1))
+            else
+                error "jar (JDK $v) failed"
+                failed=$((failed + 1))
+            fi
+        fi
+
+        if wants jlink; then
+            out="$jdir/image"
+            rm -rf "$out"
+            flags="--strip-debug --no-header-files --no-man-pages"
+            # shellcheck disable=SC2086
+            if "$home/bin/jlink" --module-path "$mods/lib:$mods/app" \
+                --add-modules com.glaurung.samples.app \
+                --launcher "inventory=com.glaurung.samples.app/$main" \
+                $flags --output "$out"; then
+                write_metadata "$meta/Inventory-jlink-jdk$v.json" "$src" "jlink" "$javac_version" \
+                    "$flags" "Inventory jlink runtime image (JDK $v)" "$host_platform" "$out/lib/modules"
+                built=$((built + 1))
+            else
+                error "jlink (JDK $v) failed"
+                failed=$((failed + 1))
+            fi
+        fi
+    done
+fi
+
+log "built $built artifacts; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
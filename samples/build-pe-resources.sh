@@ -0,0 +1,242 @@
+#!/usr/bin/env bash
+#
+# Builds the Windows resource sample (source/c/versioned.c with
+# source/c/resources/versioned.rc): a PE with a VERSIONINFO resource, an
+# embedded RT_MANIFEST, an icon group, and a string table. Each build is
+# also Authenticode-signed with a throwaway self-signed certificate, so
+# resource parsing and signature validation both have end-to-end fixtures;
+# tests/pe_resources.rs checks the results.
+#
+# Toolchains:
+#   mingw   x86_64-w64-mingw32-{gcc,windres} and i686-w64-mingw32-{gcc,windres},
+#           under windows/amd64 and windows/i386
+#   msvc    cl and rc from a Developer Command Prompt, under windows/<target arch>
+# Missing toolchains are skipped with a warning.
+#
+# Signing uses openssl to make the certificate (or --cert/--key PEM files),
+# then osslsigncode, or signtool on Windows. Without a signer only the
+# unsigned builds are produced.
+#
+# Output (under samples/binaries/platforms/windows/<arch>/export/):
+#   native/resources/versioned-<driver>.exe
+#   native/resources/versioned-<driver>-signed.exe
+#   native/resources/test-signing.cer   (DER signing certificate)
+#   metadata/versioned-<driver>[-signed].exe.json
+#
+# Usage:
+#   ./build-pe-resources.sh [--toolchains "mingw msvc"] [--cert cert.pem --key key.pem]
+#                           [--no-sign] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/c"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+TOOLCHAINS="mingw msvc"
+CERT=""
+KEY=""
+SIGN=1
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --toolchains) TOOLCHAINS="$2"; shift 2 ;;
+        --cert) CERT="$2"; shift 2 ;;
+        --key) KEY="$2"; shift 2 ;;
+        --no-sign) SIGN=0; shift ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+# MSVC's target architecture, from the Developer Command Prompt.
+msvc_arch() {
+    case "${VSCMD_ARG_TGT_ARCH:-x64}" in
+        x64) echo "amd64" ;;
+        x86) echo "i386" ;;
+        arm64) echo "arm64" ;;
+        *) echo "${VSCMD_ARG_TGT_ARCH}" ;;
+    esac
+}
+
+# Drivers for a toolchain, as "driver:arch" pairs.
+drivers() {
+    case "$1" in
+        mingw) echo "x86_64-w64-mingw32-gcc:amd64 i686-w64-mingw32-gcc:i386" ;;
+        msvc) echo "cl:$(msvc_arch)" ;;
+        *) return 1 ;;
+    esac
+}
+
+# compile DRIVER OUT: builds versioned.c with its resources into OUT.
+compile() {
+    local driver="$1" out="$2"
+    local rc_dir="$SOURCE_DIR/resources"
+    case "$driver" in
+        cl)
+            (cd "$rc_dir" && rc /nologo /fo "$work/versioned.res" versioned.rc) \
+                && cl /nologo /O2 /W3 "/Fo$work/" "/Fe$out" "$SOURCE_DIR/versioned.c" \
+                    "$work/versioned.res" /link version.lib
+            ;;
+        *)
+            "${driver%gcc}windres" -I "$rc_dir" -i "$rc_dir/versioned.rc" -O coff \
+                -o "$work/versioned.res.o" \
+                && "$driver" -O2 -Wall -o "$out" "$SOURCE_DIR/versioned.c" \
+                    "$work/versioned.res.o" -lversion
+            ;;
+    esac
+}
+
+compiler_version() {
+    case "$1" in
+        cl) cl 2>&1 | head -n1 ;;
+        *) "$1" --version 2>/dev/null | head -n1 ;;
+    esac
+}
+
+signer() {
+    if command -v osslsigncode &> /dev/null; then
+        echo "osslsigncode"
+    elif command -v signtool &> /dev/null; then
+        echo "signtool"
+    else
+        return 1
+    fi
+}
+
+# make_cert: a self-signed code-signing certificate and key in $work, unless
+# --cert/--key were given.
+make_cert() {
+    if [ -n "$CERT" ] && [ -n "$KEY" ]; then
+        cp "$CERT" "$work/cert.pem"
+        cp "$KEY" "$work/key.pem"
+    else
+        openssl req -x509 -newkey rsa:2048 -sha256 -days 3650 -nodes \
+            -subj "/CN=Glaurung Test Signing/O=Glaurung Project" \
+            -addext "keyUsage=critical,digitalSignature" \
+            -addext "extendedKeyUsage=critical,codeSigning" \
+            -keyout "$work/key.pem" -out "$work/cert.pem" 2>/dev/null || return 1
+    fi
+    openssl x509 -in "$work/cert.pem" -outform DER -out "$work/cert.cer"
+}
+
+# sign TOOL IN OUT
+sign() {
+    case "$1" in
+        osslsigncode)
+            osslsigncode sign -certs "$work/cert.pem" -key "$work/key.pem" -h sha256 \
+                -n "Glaurung resource sample" -in "$2" -out "$3" > /dev/null
+            ;;
+        signtool)
+            [ -f "$work/cert.pfx" ] || openssl pkcs12 -export -passout pass: \
+                -inkey "$work/key.pem" -in "$work/cert.pem" -out "$work/cert.pfx"
+            cp "$2" "$3" && signtool sign /q /fd SHA256 /f "$work/cert.pfx" /p "" "$3"
+            ;;
+    esac
+}
+
+write_metadata() {
+    local meta_file="$1" driver="$2" arch="$3" out="$4" desc="$5"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << EOF
+{
+  "source_file": "source/c/versioned.c",
+  "resource_file": "source/c/resources/versioned.rc",
+  "compiler": "$driver",
+  "compiler_version": "$(compiler_version "$driver")",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "sha256": "$sha",
+  "description": "$desc",
+  "timestamp": "$(date -Iseconds)",
+  "platform": "windows",
+  "architecture": "$arch"
+}
+EOF
+}
+
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+tool=""
+if [ "$SIGN" = 1 ]; then
+    if ! command -v openssl &> /dev/null; then
+        warn "openssl not found; not signing"
+    elif ! tool="$(signer)"; then
+        warn "neither osslsigncode nor signtool found; not signing"
+    elif ! make_cert; then
+        error "could not create the signing certificate"
+        exit 1
+    fi
+fi
+
+built=0
+skipped=0
+failed=0
+
+for family in $TOOLCHAINS; do
+    if ! pairs="$(drivers "$family")"; then
+        warn "unknown toolchain: $family"
+        continue
+    fi
+    for pair in $pairs; do
+        driver="${pair%%:*}"
+        arch="${pair#*:}"
+        if ! command -v "$driver" &> /dev/null; then
+            warn "$driver not found; skipping windows/$arch"
+            skipped=$((skipped + 1))
+            continue
+        fi
+        out_root="$PLATFORMS_DIR/windows/$arch/export"
+        out_dir="$out_root/native/resources"
+        mkdir -p "$out_dir"
+        if [ "$CLEAN" = 1 ]; then
+            rm -f "$out_dir/versioned-$driver"* "$out_root/metadata/versioned-$driver"*.json
+        fi
+
+        out="$out_dir/versioned-$driver.exe"
+        log "windows/$arch $driver -> ${out#"$SCRIPT_DIR"/}"
+        if ! compile "$driver" "$out"; then
+            error "$driver failed"
+            failed=$((failed + 1))
+            continue
+        fi
+        write_metadata "$out_root/metadata/versioned-$driver.exe.json" "$driver" "$arch" "$out" \
+            "Resource sample (version, manifest, icon, strings) built with $driver"
+        built=$((built + 1))
+
+        [ -n "$tool" ] || continue
+        signed="$out_dir/versioned-$driver-signed.exe"
+        log "$tool -> ${signed#"$SCRIPT_DIR"/}"
+        if sign "$tool" "$out" "$signed"; then
+            cp "$work/cert.cer" "$out_dir/test-signing.cer"
+            write_metadata "$out_root/metadata/versioned-$driver-signed.exe.json" "$driver" "$arch" \
+                "$signed" "Resource sample built with $driver, Authenticode-signed by $tool (self-signed)"
+            built=$((built + 1))
+        else
+            error "$tool failed on ${out#"$SCRIPT_DIR"/}"
+            failed=$((failed + 1))
+        fi
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
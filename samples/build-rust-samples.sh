@@ -0,0 +1,188 @@
+#!/usr/bin/env bash
+#
+# Builds the Rust samples (async_exec, panics, generics, and the cdylib
+# shared library) in debug and release, each with the legacy and the v0
+# symbol mangling scheme, to exercise Rust language detection, v0
+# demangling, and library signature matching against std/core/alloc code.
+#
+#   debug     -C opt-level=0 -g
+#   release   -O (symbols kept; see build-packed.sh etc. for stripped copies)
+#   -v0       adds -C symbol-mangling-version=v0
+#
+# Targets default to the host. Other rustc targets can be listed with
+# --targets; a target is skipped if its std is not installed
+# (`rustup target add <triple>`) or no linker for it is configured.
+#
+# Output (under samples/binaries/platforms/<os>/<arch>/export/):
+#   rust/<sample>-rust-<profile>[-v0][.exe]
+#   rust/lib<sample>-rust-<profile>[-v0].{so,dll,dylib}   (cdylib)
+#   metadata/<output name>.json
+#
+# Usage:
+#   ./build-rust-samples.sh [--targets "x86_64-unknown-linux-gnu x86_64-pc-windows-gnu"]
+#                           [--samples "panics cdylib"] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+SOURCE_DIR="$SCRIPT_DIR/source/rust"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+
+TARGETS=""
+SAMPLES="async_exec panics generics cdylib"
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --targets) TARGETS="$2"; shift 2 ;;
+        --samples) SAMPLES="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+if ! command -v rustc &> /dev/null; then
+    error "rustc not found on PATH"
+    exit 1
+fi
+
+HOST="$(rustc -vV | sed -n 's/^host: //p')"
+[ -n "$TARGETS" ] || TARGETS="$HOST"
+SYSROOT="$(rustc --print sysroot)"
+
+# platform_dir TRIPLE: <os>/<arch> directory under binaries/platforms.
+platform_dir() {
+    local arch os
+    case "$1" in
+        x86_64-*) arch="amd64" ;;
+        aarch64-*) arch="arm64" ;;
+        i686-*|i586-*) arch="i386" ;;
+        armv7-*|arm-*) arch="armhf" ;;
+        *) return 1 ;;
+    esac
+    case "$1" in
+        *-linux-*) os="linux" ;;
+        *-windows-*) os="windows" ;;
+        *-apple-darwin) os="darwin" ;;
+        *) return 1 ;;
+    esac
+    echo "$os/$arch"
+}
+
+# artifact_name SAMPLE SUFFIX TRIPLE: output filename for a build.
+artifact_name() {
+    local sample="$1" suffix="$2" triple="$3"
+    if [ "$sample" = "cdylib" ]; then
+        case "$triple" in
+            *-windows-*) echo "$sample-rust$suffix.dll" ;;
+            *-apple-darwin) echo "lib$sample-rust$suffix.dylib" ;;
+            *) echo "lib$sample-rust$suffix.so" ;;
+        esac
+    else
+        case "$triple" in
+            *-windows-*) echo "$sample-rust$suffix.exe" ;;
+            *) echo "$sample-rust$suffix" ;;
+        esac
+    fi
+}
+
+write_metadata() {
+    local meta_file="$1" sample="$2" triple="$3" profile="$4" mangling="$5" flags="$6" out="$7"
+    local sha=""
+    if command -v sha256sum &> /dev/null; then
+        sha="$(sha256sum "$out" | cut -d' ' -f1)"
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << JSON
+{
+  "source_file": "source/rust/$sample.rs",
+  "compiler": "rustc",
+  "rust_version": "$(rustc --version)",
+  "target": "$triple",
+  "profile": "$profile",
+  "symbol_mangling": "$mangling",
+  "crate_type": "$([ "$sample" = "cdylib" ] && echo cdylib || echo bin)",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "compilation_flags": "$flags",
+  "sha256": "$sha",
+  "description": "Rust $profile build ($mangling mangling)",
+  "timestamp": "$(date -Iseconds)"
+}
+JSON
+}
+
+built=0
+skipped=0
+failed=0
+
+for triple in $TARGETS; do
+    if ! dir="$(platform_dir "$triple")"; then
+        warn "no platform directory for $triple; skipping"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    if [ ! -d "$SYSROOT/lib/rustlib/$triple/lib" ]; then
+        warn "std for $triple not installed; skipping"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    out_root="$PLATFORMS_DIR/$dir/export"
+    out_dir="$out_root/rust"
+    mkdir -p "$out_dir"
+
+    for sample in $SAMPLES; do
+        src="$SOURCE_DIR/$sample.rs"
+        if [ ! -f "$src" ]; then
+            warn "no such sample: $src"
+            continue
+        fi
+        crate_type="bin"
+        [ "$sample" = "cdylib" ] && crate_type="cdylib"
+        for profile in debug release; do
+            for mangling in legacy v0; do
+                flags="--edition 2021 --crate-type $crate_type"
+                case "$profile" in
+                    debug) flags="$flags -C opt-level=0 -g" ;;
+                    release) flags="$flags -O" ;;
+                esac
+                suffix="-$profile"
+                if [ "$mangling" = "v0" ]; then
+                    flags="$flags -C symbol-mangling-version=v0"
+                    suffix="$suffix-v0"
+                fi
+                name="$(artifact_name "$sample" "$suffix" "$triple")"
+                out="$out_dir/$name"
+                if [ "$CLEAN" = 1 ]; then
+                    rm -f "$out" "$out_root/metadata/$name.json"
+                fi
+                log "$triple $profile/$mangling: $sample -> ${out#"$SCRIPT_DIR"/}"
+                # shellcheck disable=SC2086
+                if rustc $flags --target "$triple" --crate-name "$sample" -o "$out" "$src"; then
+                    write_metadata "$out_root/metadata/$name.json" \
+                        "$sample" "$triple" "$profile" "$mangling" "$flags" "$out"
+                    built=$((built + 1))
+                else
+                    error "$triple $sample ($profile, $mangling) failed"
+                    failed=$((failed + 1))
+                fi
+            done
+        done
+    done
+done
+
+log "built $built binaries; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
@@ -0,0 +1,224 @@
+#!/usr/bin/env bash
+#
+# Packs existing samples with several UPX releases at several compression
+# levels, so the packer detector and unpacking code are tested end to end
+# against the stub and header layouts real UPX versions emit, rather than a
+# single upx -9 build (see build-packed.sh).
+#
+# UPX releases: the `upx` on PATH is used for its own version. Other releases
+# are taken from $UPX_CACHE/<version>/upx (default ~/.cache/glaurung/upx), or
+# downloaded there from the upx/upx GitHub releases (needs network access).
+# Releases that cannot be obtained are skipped with a warning.
+#
+# Levels:
+#   1 .. 9         -<N>
+#   best           --best
+#   brute          --brute (slow)
+#   lzma           --lzma
+#
+# Inputs are the unstripped ELF and PE executables of each platform (LFS
+# pointers that were never fetched and files UPX refuses are skipped).
+# Every variant is also unpacked with the same release to record whether
+# `upx -d` restores the input byte for byte.
+#
+# Output (under samples/packed/matrix/):
+#   upx-<version>/<os>-<arch>/<name>.upx<level>
+#   metadata/upx-<version>/<os>-<arch>/<name>.upx<level>.json
+#
+# Usage:
+#   ./build-upx-matrix.sh [--versions "3.96 4.2.4"] [--levels "1 9 best lzma"]
+#                         [--platforms "linux/amd64 windows/amd64"]
+#                         [--max-inputs 8] [--clean]
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+PLATFORMS_DIR="$SCRIPT_DIR/binaries/platforms"
+OUT_ROOT="$SCRIPT_DIR/packed/matrix"
+UPX_CACHE="${UPX_CACHE:-$HOME/.cache/glaurung/upx}"
+
+VERSIONS="3.96 4.0.2 4.2.4"
+LEVELS="1 9 best lzma"
+PLATFORMS="linux/amd64 linux/arm64 linux/i386 windows/amd64 windows/i386"
+MAX_INPUTS=8
+CLEAN=0
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m'
+
+log() { echo -e "${GREEN}[BUILD] $1${NC}"; }
+warn() { echo -e "${YELLOW}[WARN] $1${NC}"; }
+error() { echo -e "${RED}[ERROR] $1${NC}" >&2; }
+
+usage() {
+    sed -n '2,/^$/s/^# \{0,1\}//p' "$0"
+}
+
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --versions) VERSIONS="$2"; shift 2 ;;
+        --levels) LEVELS="$2"; shift 2 ;;
+        --platforms) PLATFORMS="$2"; shift 2 ;;
+        --max-inputs) MAX_INPUTS="$2"; shift 2 ;;
+        --clean) CLEAN=1; shift ;;
+        -h|--help) usage; exit 0 ;;
+        *) error "unknown option: $1"; usage; exit 2 ;;
+    esac
+done
+
+# Release asset suffix for the host, e.g. amd64_linux.
+release_arch() {
+    case "$(uname -m)" in
+        x86_64|amd64) echo "amd64_linux" ;;
+        aarch64|arm64) echo "arm64_linux" ;;
+        i?86) echo "i386_linux" ;;
+        armv7*) echo "arm_linux" ;;
+        *) echo "" ;;
+    esac
+}
+
+LOCAL_VERSION=""
+if command -v upx &> /dev/null; then
+    LOCAL_VERSION="$(upx --version 2> /dev/null | head -n1 | awk '{print $2}')"
+fi
+
+# upx_for VERSION: path to an upx binary of that release, or "".
+upx_for() {
+    local v="$1" cached="$UPX_CACHE/$1/upx"
+    if [ "$v" = "$LOCAL_VERSION" ]; then
+        command -v upx
+        return
+    fi
+    if [ ! -x "$cached" ]; then
+        local asset arch tmp
+        arch="$(release_arch)"
+        [ -n "$arch" ] && command -v curl &> /dev/null || return 0
+        asset="upx-$v-$arch"
+        tmp="$(mktemp -d)"
+        if curl -fsL -o "$tmp/$asset.tar.xz" \
+            "https://github.com/upx/upx/releases/download/v$v/$asset.tar.xz" \
+            && tar -xJf "$tmp/$asset.tar.xz" -C "$tmp"; then
+            mkdir -p "$(dirname "$cached")"
+            mv "$tmp/$asset/upx" "$cached"
+        fi
+        rm -rf "$tmp"
+    fi
+    [ -x "$cached" ] && echo "$cached"
+    return 0
+}
+
+# level_flags LEVEL: upx flags for a level name, or "".
+level_flags() {
+    case "$1" in
+        [1-9]) echo "-$1" ;;
+        best|brute|lzma) echo "--$1" ;;
+        *) echo "" ;;
+    esac
+}
+
+# Unstripped ELF/PE executables of a platform, skipping LFS pointers.
+inputs_for() {
+    local dir="$PLATFORMS_DIR/$1/export"
+    [ -d "$dir" ] || return 0
+    find "$dir" -type f -size +16k ! -name '*-stripped*' ! -name '*.json' ! -name '*.pdb' \
+        ! -name '*.so' ! -name '*.dll' ! -name '*.a' ! -name '*.lib' -print0 \
+        | sort -z \
+        | while IFS= read -r -d '' f; do
+            case "$(head -c 4 "$f" | od -An -tx1 | tr -d ' \n')" in
+                7f454c46|4d5a*) echo "$f" ;;
+            esac
+        done \
+        | head -n "$MAX_INPUTS"
+}
+
+sha256() {
+    sha256sum "$1" | cut -d' ' -f1
+}
+
+write_metadata() {
+    local meta_file="$1" src="$2" v="$3" level="$4" flags="$5" out="$6" restored="$7"
+    local restored_sha="" exact=false
+    if [ -n "$restored" ]; then
+        restored_sha="$(sha256 "$restored")"
+        [ "$restored_sha" = "$(sha256 "$src")" ] && exact=true
+    fi
+    mkdir -p "$(dirname "$meta_file")"
+    cat > "$meta_file" << JSON
+{
+  "source_file": "${src#"$SCRIPT_DIR"/}",
+  "source_sha256": "$(sha256 "$src")",
+  "packer": "upx",
+  "upx_version": "$v",
+  "level": "$level",
+  "packing_flags": "$flags",
+  "output_file": "${out#"$SCRIPT_DIR"/}",
+  "sha256": "$(sha256 "$out")",
+  "restored_sha256": "$restored_sha",
+  "roundtrip_exact": $exact,
+  "description": "UPX $v $level packed variant",
+  "timestamp": "$(date -Iseconds)"
+}
+JSON
+}
+
+built=0
+skipped=0
+failed=0
+tmp_dir="$(mktemp -d)"
+trap 'rm -rf "$tmp_dir"' EXIT
+
+for v in $VERSIONS; do
+    upx_bin="$(upx_for "$v")"
+    if [ -z "$upx_bin" ]; then
+        warn "UPX $v unavailable (not on PATH, in $UPX_CACHE, or downloadable); skipping"
+        skipped=$((skipped + 1))
+        continue
+    fi
+    log "UPX $v: $upx_bin"
+    if [ "$CLEAN" = 1 ]; then
+        rm -rf "$OUT_ROOT/upx-$v" "$OUT_ROOT/metadata/upx-$v"
+    fi
+
+    for platform in $PLATFORMS; do
+        tag="${platform%/*}-${platform#*/}"
+        out_dir="$OUT_ROOT/upx-$v/$tag"
+        meta_dir="$OUT_ROOT/metadata/upx-$v/$tag"
+        while IFS= read -r src; do
+            [ -n "$src" ] || continue
+            for level in $LEVELS; do
+                flags="$(level_flags "$level")"
+                if [ -z "$flags" ]; then
+                    warn "unknown level: $level"
+                    continue
+                fi
+                name="$(basename "$src").upx$level"
+                out="$out_dir/$name"
+                mkdir -p "$out_dir"
+                rm -f "$out"
+                # shellcheck disable=SC2086
+                if ! "$upx_bin" -q $flags -o "$out" "$src" > "$tmp_dir/log" 2>&1; then
+                    # CantPackException, NotCompressibleException, etc.
+                    warn "UPX $v $level refused ${src#"$SCRIPT_DIR"/}: $(tail -n1 "$tmp_dir/log")"
+                    rm -f "$out"
+                    skipped=$((skipped + 1))
+                    continue
+                fi
+                restored="$tmp_dir/restored"
+                rm -f "$restored"
+                if ! "$upx_bin" -q -d -o "$restored" "$out" > "$tmp_dir/log" 2>&1; then
+                    error "UPX $v cannot unpack its own ${out#"$SCRIPT_DIR"/}"
+                    restored=""
+                    failed=$((failed + 1))
+                fi
+                write_metadata "$meta_dir/$name.json" "$src" "$v" "$level" "$flags" "$out" "$restored"
+                log "  $level: ${out#"$SCRIPT_DIR"/}"
+                built=$((built + 1))
+            done
+        done < <(inputs_for "$platform")
+    done
+done
+
+log "built $built variants; $skipped skipped; $failed failed"
+[ "$failed" -eq 0 ]
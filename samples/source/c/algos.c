@@ -0,0 +1,106 @@
+/*
+ * Function-discovery fixture for the C/C++ build matrix.
+ *
+ * Each helper exercises a code shape that discovery has to cope with once
+ * the optimizer gets involved: a dense switch (jump table), direct and
+ * mutual recursion, calls through a function-pointer table, a qsort
+ * callback, a variadic function, a tail call, and a noreturn error path.
+ * Helpers are marked NOINLINE so they survive -O3 and LTO as separate
+ * functions; their names are the ground truth the matrix tests look for.
+ */
+#include <stdarg.h>
+#include <stdio.h>
+#include <stdlib.h>
+
+#if defined(_MSC_VER)
+#define NOINLINE __declspec(noinline)
+#define NORETURN __declspec(noreturn)
+#else
+#define NOINLINE __attribute__((noinline))
+#define NORETURN __attribute__((noreturn))
+#endif
+
+typedef int (*binop_fn)(int, int);
+
+NOINLINE int op_add(int a, int b) { return a + b; }
+NOINLINE int op_sub(int a, int b) { return a - b; }
+NOINLINE int op_mul(int a, int b) { return a * b; }
+NOINLINE int op_xor(int a, int b) { return a ^ b; }
+
+static const binop_fn OPS[] = {op_add, op_sub, op_mul, op_xor};
+
+/* Dense cases so every compiler emits a jump table at -O1 and above. */
+NOINLINE const char *classify(int code) {
+    switch (code) {
+    case 0: return "zero";
+    case 1: return "one";
+    case 2: return "two";
+    case 3: return "three";
+    case 4: return "four";
+    case 5: return "five";
+    case 6: return "six";
+    case 7: return "seven";
+    default: return "many";
+    }
+}
+
+NOINLINE unsigned long fib(unsigned n) {
+    return n < 2 ? n : fib(n - 1) + fib(n - 2);
+}
+
+NOINLINE int is_odd(unsigned n);
+
+NOINLINE int is_even(unsigned n) { return n == 0 ? 1 : is_odd(n - 1); }
+
+NOINLINE int is_odd(unsigned n) { return n == 0 ? 0 : is_even(n - 1); }
+
+NOINLINE int compare_ints(const void *a, const void *b) {
+    int x = *(const int *)a, y = *(const int *)b;
+    return (x > y) - (x < y);
+}
+
+NOINLINE int sum_varargs(int count, ...) {
+    va_list ap;
+    int total = 0;
+    va_start(ap, count);
+    while (count-- > 0)
+        total += va_arg(ap, int);
+    va_end(ap);
+    return total;
+}
+
+NOINLINE NORETURN void die(const char *msg) {
+    fprintf(stderr, "fatal: %s\n", msg);
+    exit(2);
+}
+
+NOINLINE int apply(unsigned op, int a, int b) {
+    if (op >= sizeof(OPS) / sizeof(OPS[0]))
+        die("bad opcode");
+    return OPS[op](a, b);
+}
+
+/* Ends in a call the optimizer turns into a jump. */
+NOINLINE int checksum(const char *s) {
+    unsigned h = 5381;
+    while (*s)
+        h = h * 33 + (unsigned char)*s++;
+    return apply(h & 3, (int)h, 7);
+}
+
+int main(int argc, char **argv) {
+    int values[] = {42, 7, 19, 3, 88, 1};
+    size_t n = sizeof(values) / sizeof(values[0]);
+    size_t i;
+
+    qsort(values, n, sizeof(values[0]), compare_ints);
+    for (i = 0; i < n; i++)
+        printf("%d%c", values[i], i + 1 < n ? ' ' : '\n');
+
+    printf("%s fib=%lu even=%d\n", classify(argc), fib(20), is_even((unsigned)argc));
+    printf("ops=%d,%d sum=%d\n", apply(0, 3, 4), apply(2, 3, 4), sum_varargs(3, 1, 2, 3));
+    printf("checksum=%d\n", checksum(argc > 1 ? argv[1] : "glaurung"));
+    if (argc > 3)
+        die("too many arguments");
+    return 0;
+}
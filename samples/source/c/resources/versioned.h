@@ -0,0 +1,7 @@
+#ifndef VERSIONED_H
+#define VERSIONED_H
+
+#define IDI_APP 101
+#define IDS_GREETING 201
+
+#endif
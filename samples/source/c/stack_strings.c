@@ -0,0 +1,63 @@
+// Benign anti-analysis sample: stack strings.
+//
+// Each string is assembled on the stack at run time instead of being stored
+// in .rodata, so it never appears contiguously in the file and a plain
+// strings pass misses it. The volatile buffers keep compilers from folding
+// the stores back into a constant at any optimization level.
+//
+// Expected recoveries (see tests/anti_analysis.rs):
+//   glaurung-stack-bytes   one byte store per character
+//   glaurung-stack-dwords  four characters per 32-bit immediate store
+//   glaurung-stack-wide    UTF-16LE, one 16-bit store per character
+#include <stdio.h>
+#include <stdint.h>
+#include <string.h>
+
+static void build_bytes(char *out) {
+    volatile char s[21];
+    s[0] = 'g'; s[1] = 'l'; s[2] = 'a'; s[3] = 'u'; s[4] = 'r';
+    s[5] = 'u'; s[6] = 'n'; s[7] = 'g'; s[8] = '-'; s[9] = 's';
+    s[10] = 't'; s[11] = 'a'; s[12] = 'c'; s[13] = 'k'; s[14] = '-';
+    s[15] = 'b'; s[16] = 'y'; s[17] = 't'; s[18] = 'e'; s[19] = 's';
+    s[20] = '\0';
+    for (int i = 0; i < 21; i++) out[i] = s[i];
+}
+
+static void build_dwords(char *out) {
+    // Little-endian: 0x75616c67 is "glau".
+    volatile uint32_t d[6];
+    d[0] = 0x75616c67; // glau
+    d[1] = 0x676e7572; // rung
+    d[2] = 0x6174732d; // -sta
+    d[3] = 0x642d6b63; // ck-d
+    d[4] = 0x64726f77; // word
+    d[5] = 0x00000073; // s
+    for (int i = 0; i < 6; i++) {
+        uint32_t v = d[i];
+        memcpy(out + 4 * i, &v, 4);
+    }
+}
+
+static void build_wide(uint16_t *out) {
+    volatile uint16_t w[20];
+    w[0] = 'g'; w[1] = 'l'; w[2] = 'a'; w[3] = 'u'; w[4] = 'r';
+    w[5] = 'u'; w[6] = 'n'; w[7] = 'g'; w[8] = '-'; w[9] = 's';
+    w[10] = 't'; w[11] = 'a'; w[12] = 'c'; w[13] = 'k'; w[14] = '-';
+    w[15] = 'w'; w[16] = 'i'; w[17] = 'd'; w[18] = 'e'; w[19] = 0;
+    for (int i = 0; i < 20; i++) out[i] = w[i];
+}
+
+int main(void) {
+    char bytes[21];
+    char dwords[24];
+    uint16_t wide[20];
+    char narrow[20];
+
+    build_bytes(bytes);
+    build_dwords(dwords);
+    build_wide(wide);
+    for (int i = 0; i < 20; i++) narrow[i] = (char)wide[i];
+
+    printf("%s\n%s\n%s\n", bytes, dwords, narrow);
+    return 0;
+}
@@ -0,0 +1,78 @@
+// Benign anti-analysis sample: timing checks.
+//
+// Measures how long a fixed loop takes with the clocks debugger- and
+// emulator-detection code usually reads, and only prints whether it looked
+// slow; nothing else depends on the result.
+//
+// Expected evidence (see tests/anti_analysis.rs):
+//   x86/x86_64  an rdtsc instruction
+//   Linux       clock_gettime imported
+//   Windows     QueryPerformanceCounter and GetTickCount imported
+#include <stdio.h>
+#include <stdint.h>
+
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <time.h>
+#endif
+
+#if defined(__x86_64__) || defined(__i386__) || defined(_M_X64) || defined(_M_IX86)
+#ifdef _MSC_VER
+#include <intrin.h>
+#else
+#include <x86intrin.h>
+#endif
+#define HAVE_RDTSC 1
+#endif
+
+// Roughly what a single-stepped loop costs; generous enough that a normal
+// run never crosses it.
+#define SLOW_CYCLES 100000000ULL
+#define SLOW_NANOS 50000000ULL
+
+static volatile uint32_t sink;
+
+static void work(void) {
+    for (uint32_t i = 0; i < 1000; i++) sink += i;
+}
+
+static uint64_t now_nanos(void) {
+#ifdef _WIN32
+    LARGE_INTEGER c, f;
+    QueryPerformanceCounter(&c);
+    QueryPerformanceFrequency(&f);
+    return (uint64_t)(c.QuadPart * 1000000000.0 / f.QuadPart);
+#else
+    struct timespec ts;
+    clock_gettime(CLOCK_MONOTONIC, &ts);
+    return (uint64_t)ts.tv_sec * 1000000000ULL + (uint64_t)ts.tv_nsec;
+#endif
+}
+
+int main(void) {
+    int slow = 0;
+
+#ifdef HAVE_RDTSC
+    uint64_t t0 = __rdtsc();
+    work();
+    uint64_t cycles = __rdtsc() - t0;
+    slow |= cycles > SLOW_CYCLES;
+    printf("rdtsc: %llu cycles\n", (unsigned long long)cycles);
+#endif
+
+    uint64_t n0 = now_nanos();
+    work();
+    uint64_t nanos = now_nanos() - n0;
+    slow |= nanos > SLOW_NANOS;
+    printf("monotonic: %llu ns\n", (unsigned long long)nanos);
+
+#ifdef _WIN32
+    DWORD tick0 = GetTickCount();
+    work();
+    printf("tick count: %lu ms\n", (unsigned long)(GetTickCount() - tick0));
+#endif
+
+    puts(slow ? "timing: slow" : "timing: normal");
+    return 0;
+}
@@ -0,0 +1,64 @@
+// Benign anti-analysis sample: TLS callbacks (Windows only).
+//
+// Two callbacks run before main: the first XOR-decodes a string, the second
+// calls IsDebuggerPresent. Both only record what they saw for main to
+// print. Unlike pe_tls.c, the callbacks do work, so the callback array and
+// the code it points at are both worth checking.
+//
+// Expected evidence (see tests/anti_analysis.rs):
+//   PE TLS directory with two callbacks
+//   IsDebuggerPresent imported
+//   glaurung-tls-callback  XOR 0x37, decoded by the first callback
+#ifdef _WIN32
+#include <windows.h>
+#include <stdio.h>
+
+static const unsigned char encoded[] = {
+    0x50, 0x5b, 0x56, 0x42, 0x45, 0x42, 0x59, 0x50, 0x1a, 0x43, 0x5b,
+    0x44, 0x1a, 0x54, 0x56, 0x5b, 0x5b, 0x55, 0x56, 0x54, 0x5c,
+};
+
+static char decoded[sizeof encoded + 1];
+static volatile LONG attach_calls;
+static volatile BOOL debugger_seen;
+
+static void NTAPI tls_decode(PVOID h, DWORD reason, PVOID res) {
+    (void)h; (void)res;
+    if (reason != DLL_PROCESS_ATTACH) return;
+    for (size_t i = 0; i < sizeof encoded; i++) decoded[i] = (char)(encoded[i] ^ 0x37);
+    InterlockedIncrement(&attach_calls);
+}
+
+static void NTAPI tls_probe(PVOID h, DWORD reason, PVOID res) {
+    (void)h; (void)res;
+    if (reason != DLL_PROCESS_ATTACH) return;
+    debugger_seen = IsDebuggerPresent();
+    InterlockedIncrement(&attach_calls);
+}
+
+#ifdef _MSC_VER
+#ifdef _WIN64
+#pragma comment(linker, "/INCLUDE:_tls_used")
+#pragma comment(linker, "/INCLUDE:tls_callback_list")
+#else
+#pragma comment(linker, "/INCLUDE:__tls_used")
+#pragma comment(linker, "/INCLUDE:_tls_callback_list")
+#endif
+#pragma const_seg(".CRT$XLB")
+EXTERN_C const PIMAGE_TLS_CALLBACK tls_callback_list[] = {tls_decode, tls_probe};
+#pragma const_seg()
+#else
+// MinGW: the CRT collects everything between .CRT$XLA and .CRT$XLZ.
+PIMAGE_TLS_CALLBACK tls_callback_list[] __attribute__((section(".CRT$XLB"), used)) = {
+    tls_decode, tls_probe,
+};
+#endif
+
+int main(void) {
+    printf("%s\n", decoded);
+    printf("callbacks: %ld, debugger: %s\n", (long)attach_calls, debugger_seen ? "yes" : "no");
+    return 0;
+}
+#else
+int main(void) { return 0; }
+#endif
@@ -0,0 +1,48 @@
+// Windows PE sample with a full resource section: a version resource, an
+// embedded application manifest, an icon group, and a string table
+// (resources/versioned.rc). build-pe-resources.sh also emits a
+// test-signed copy, so one source backs both resource parsing and
+// Authenticode fixtures.
+//
+// At run time it reads its own resources back, which keeps the sample
+// honest: a broken .rc fails here before it misleads a parser test.
+#ifdef _WIN32
+#include <windows.h>
+#include <stdio.h>
+
+#include "resources/versioned.h"
+
+#ifdef _MSC_VER
+#pragma comment(lib, "version.lib")
+#endif
+
+int main(void) {
+    char greeting[64];
+    char path[MAX_PATH];
+    DWORD handle = 0, size;
+    void *info;
+    VS_FIXEDFILEINFO *fixed = NULL;
+    UINT len = 0;
+
+    if (!LoadStringA(GetModuleHandleA(NULL), IDS_GREETING, greeting, sizeof(greeting))) {
+        return 1;
+    }
+    if (!LoadIconA(GetModuleHandleA(NULL), MAKEINTRESOURCEA(IDI_APP))) {
+        return 2;
+    }
+    GetModuleFileNameA(NULL, path, sizeof(path));
+    size = GetFileVersionInfoSizeA(path, &handle);
+    info = size ? HeapAlloc(GetProcessHeap(), 0, size) : NULL;
+    if (!info || !GetFileVersionInfoA(path, 0, size, info)
+        || !VerQueryValueA(info, "\\", (void **)&fixed, &len)) {
+        return 3;
+    }
+    printf("%s %u.%u.%u.%u\n", greeting,
+           HIWORD(fixed->dwFileVersionMS), LOWORD(fixed->dwFileVersionMS),
+           HIWORD(fixed->dwFileVersionLS), LOWORD(fixed->dwFileVersionLS));
+    HeapFree(GetProcessHeap(), 0, info);
+    return 0;
+}
+#else
+int main(void) { return 0; }
+#endif
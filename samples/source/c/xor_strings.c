@@ -0,0 +1,44 @@
+// Benign anti-analysis sample: XOR-encoded strings.
+//
+// The strings are stored only in encoded form and decoded into a stack
+// buffer just before use, the simplest obfuscation a string deobfuscator
+// has to see through.
+//
+// Expected recoveries (see tests/anti_analysis.rs):
+//   glaurung-xor-single-byte  every byte XOR 0x5a
+//   glaurung-xor-rolling-key  XOR with the repeating key "K3y!"
+#include <stdio.h>
+#include <stddef.h>
+
+static const unsigned char single_byte[] = {
+    0x3d, 0x36, 0x3b, 0x2f, 0x28, 0x2f, 0x34, 0x3d, 0x77, 0x22, 0x35, 0x28,
+    0x77, 0x29, 0x33, 0x34, 0x3d, 0x36, 0x3f, 0x77, 0x38, 0x23, 0x2e, 0x3f,
+};
+
+static const unsigned char rolling_key[] = {
+    0x2c, 0x5f, 0x18, 0x54, 0x39, 0x46, 0x17, 0x46, 0x66, 0x4b, 0x16, 0x53,
+    0x66, 0x41, 0x16, 0x4d, 0x27, 0x5a, 0x17, 0x46, 0x66, 0x58, 0x1c, 0x58,
+};
+
+static const unsigned char key[] = {'K', '3', 'y', '!'};
+
+// Not static, and called through a volatile pointer below, so the decode
+// loop survives constant propagation at -O2 and up.
+void xor_decode(char *out, const unsigned char *in, size_t n,
+                const unsigned char *k, size_t klen) {
+    for (size_t i = 0; i < n; i++) out[i] = (char)(in[i] ^ k[i % klen]);
+    out[n] = '\0';
+}
+
+int main(void) {
+    void (*volatile decode)(char *, const unsigned char *, size_t,
+                            const unsigned char *, size_t) = xor_decode;
+    const unsigned char single_key = 0x5a;
+    char buf[32];
+
+    decode(buf, single_byte, sizeof single_byte, &single_key, 1);
+    printf("%s\n", buf);
+    decode(buf, rolling_key, sizeof rolling_key, key, sizeof key);
+    printf("%s\n", buf);
+    return 0;
+}
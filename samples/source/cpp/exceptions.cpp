@@ -0,0 +1,136 @@
+// C++ exception-handling sample for LSDA and EH table recovery.
+//
+// Each function exercises one shape of exception metadata:
+//   eh::typed         try with two typed catches (class and int)
+//   eh::catch_all     catch (...)
+//   eh::cleanup_only  no catch, only a destructor run during unwinding
+//   eh::nested        a rethrowing inner try inside an outer try
+//   eh::seh_except    __try/__except with a filter function (msvc only)
+//   eh::seh_finally   __try/__finally (msvc only)
+// gcc and clang record these in .gcc_except_table (LSDA) reached from
+// .eh_frame; msvc records them in FuncInfo tables and C scope tables reached
+// from .pdata. Every function is NOINLINE and is called with a value main
+// cannot fold, so the try regions survive optimization.
+// tests/cpp_exceptions.rs lists the catch clauses it expects.
+#include <cstdio>
+#include <stdexcept>
+
+#if defined(_MSC_VER)
+#include <windows.h>
+#define NOINLINE __declspec(noinline)
+#else
+#define NOINLINE __attribute__((noinline))
+#endif
+
+namespace eh {
+
+class ParseError : public std::runtime_error {
+public:
+    explicit ParseError(const char *what) : std::runtime_error(what) {}
+};
+
+struct Guard {
+    explicit Guard(int *count) : count_(count) {}
+    ~Guard() { ++*count_; }
+
+private:
+    int *count_;
+};
+
+int guards_run = 0;
+
+NOINLINE int might_throw(int v) {
+    if (v < 0) {
+        throw ParseError("negative");
+    }
+    if (v == 0) {
+        throw 42;
+    }
+    if (v > 100) {
+        throw std::out_of_range("too large");
+    }
+    return v;
+}
+
+NOINLINE int typed(int v) {
+    try {
+        return might_throw(v);
+    } catch (const ParseError &e) {
+        std::printf("parse error: %s\n", e.what());
+        return -1;
+    } catch (int code) {
+        return code;
+    }
+}
+
+NOINLINE int catch_all(int v) {
+    try {
+        return might_throw(v) * 2;
+    } catch (...) {
+        return -2;
+    }
+}
+
+NOINLINE int cleanup_only(int v) {
+    Guard g(&guards_run);
+    return might_throw(v) + 1;
+}
+
+NOINLINE int nested(int v) {
+    try {
+        try {
+            return might_throw(v);
+        } catch (const std::out_of_range &) {
+            std::printf("rethrowing\n");
+            throw;
+        }
+    } catch (const std::exception &e) {
+        std::printf("outer: %s\n", e.what());
+        return -3;
+    }
+}
+
+#if defined(_MSC_VER)
+NOINLINE int seh_filter(unsigned long code) {
+    return code == EXCEPTION_ACCESS_VIOLATION ? EXCEPTION_EXECUTE_HANDLER
+                                              : EXCEPTION_CONTINUE_SEARCH;
+}
+
+NOINLINE int seh_except(volatile int *p) {
+    __try {
+        return *p;
+    } __except (seh_filter(GetExceptionCode())) {
+        return -4;
+    }
+}
+
+NOINLINE int seh_finally(volatile int *p) {
+    int r = 0;
+    __try {
+        r = *p;
+    } __finally {
+        ++guards_run;
+    }
+    return r;
+}
+#endif
+
+}  // namespace eh
+
+int main(int argc, char **argv) {
+    (void)argv;
+    int total = eh::typed(argc) + eh::typed(-argc) + eh::typed(argc - 1);
+    total += eh::catch_all(argc * 1000);
+    try {
+        total += eh::cleanup_only(-argc);
+    } catch (const eh::ParseError &) {
+        total += 1;
+    }
+    total += eh::nested(argc * 1000);
+#if defined(_MSC_VER)
+    volatile int value = argc;
+    total += eh::seh_except(&value) + eh::seh_finally(&value);
+#endif
+    std::printf("total %d guards %d\n", total, eh::guards_run);
+    return 0;
+}
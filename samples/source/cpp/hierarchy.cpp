@@ -0,0 +1,160 @@
+// C++ class hierarchy sample for RTTI and vtable recovery.
+//
+// Covers the layouts RTTI parsers have to tell apart:
+//   single inheritance    zoo::Dog : zoo::Animal            (__si_class_type_info)
+//   multiple inheritance  zoo::RoboDog : zoo::Dog, zoo::Named
+//                         (__vmi_class_type_info, secondary vtable at +8)
+//   virtual inheritance   zoo::Diamond : zoo::Left, zoo::Right, both
+//                         virtual zoo::Base (vbase offsets, vmi virtual flag)
+//   abstract classes      zoo::Animal (pure virtual speak)
+//   no bases              zoo::Named, zoo::Base
+//   templates             zoo::Cage<zoo::Dog>
+// Every polymorphic class has an out-of-line key function, every concrete
+// class is instantiated, and every object is reached through an opaque
+// pointer, so the vtables survive optimization and calls stay virtual.
+// tests/cpp_rtti.rs lists the hierarchy it expects.
+#include <cstdio>
+#include <memory>
+#include <vector>
+
+#if defined(_MSC_VER)
+#define NOINLINE __declspec(noinline)
+#else
+#define NOINLINE __attribute__((noinline))
+#endif
+
+namespace zoo {
+
+class Animal {
+public:
+    virtual ~Animal();
+    virtual const char *speak() const = 0;
+    virtual int legs() const { return 4; }
+};
+
+Animal::~Animal() {}
+
+class Named {
+public:
+    virtual ~Named();
+    virtual const char *name() const { return "unnamed"; }
+};
+
+Named::~Named() {}
+
+class Dog : public Animal {
+public:
+    ~Dog() override;
+    const char *speak() const override { return "woof"; }
+    virtual void fetch() { fetched_++; }
+
+protected:
+    int fetched_ = 0;
+};
+
+Dog::~Dog() {}
+
+class RoboDog : public Dog, public Named {
+public:
+    ~RoboDog() override;
+    const char *speak() const override { return "beep-woof"; }
+    const char *name() const override { return "robodog"; }
+    virtual int battery() const { return 100 - fetched_; }
+};
+
+RoboDog::~RoboDog() {}
+
+class Base {
+public:
+    virtual ~Base();
+    virtual int id() const { return 1; }
+};
+
+Base::~Base() {}
+
+class Left : public virtual Base {
+public:
+    ~Left() override;
+    int id() const override { return 2; }
+    virtual int left() const { return 3; }
+};
+
+Left::~Left() {}
+
+class Right : public virtual Base {
+public:
+    ~Right() override;
+    virtual int right() const { return 4; }
+};
+
+Right::~Right() {}
+
+class Diamond : public Left, public Right {
+public:
+    ~Diamond() override;
+    int id() const override { return 5; }
+};
+
+Diamond::~Diamond() {}
+
+template <typename T>
+class Cage : public Named {
+public:
+    ~Cage() override {}
+    const char *name() const override { return "cage"; }
+    virtual T *occupant() { return &occupant_; }
+
+private:
+    T occupant_;
+};
+
+template class Cage<Dog>;
+
+}  // namespace zoo
+
+// Opaque to the optimizer, so calls through these stay virtual.
+NOINLINE const zoo::Animal *pick_animal(int which) {
+    static zoo::Dog dog;
+    static zoo::RoboDog robo;
+    return which ? static_cast<const zoo::Animal *>(&robo) : &dog;
+}
+
+NOINLINE const zoo::Base *pick_base(int which) {
+    static zoo::Base base;
+    static zoo::Left left;
+    static zoo::Right right;
+    static zoo::Diamond diamond;
+    switch (which) {
+    case 0:
+        return &base;
+    case 1:
+        return &left;
+    case 2:
+        return &right;
+    default:
+        return &diamond;
+    }
+}
+
+int main(int argc, char **argv) {
+    (void)argv;
+    std::vector<std::unique_ptr<zoo::Named>> named;
+    named.emplace_back(new zoo::RoboDog);
+    named.emplace_back(new zoo::Cage<zoo::Dog>);
+    named.emplace_back(new zoo::Named);
+
+    const zoo::Animal *a = pick_animal(argc > 1);
+    std::printf("%s has %d legs\n", a->speak(), a->legs());
+    for (const auto &n : named) {
+        std::printf("named %s\n", n->name());
+    }
+    if (const auto *robo = dynamic_cast<const zoo::RoboDog *>(a)) {
+        std::printf("battery %d\n", robo->battery());
+    }
+    const zoo::Base *b = pick_base(argc);
+    std::printf("id %d\n", b->id());
+    if (const auto *r = dynamic_cast<const zoo::Right *>(b)) {
+        std::printf("right %d\n", r->right());
+    }
+    return 0;
+}
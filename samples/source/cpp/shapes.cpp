@@ -0,0 +1,125 @@
+// Demangling and RTTI fixture for the C/C++ build matrix.
+//
+// Exercises the symbol shapes demanglers must handle: nested namespaces,
+// virtual methods and vtables, const and overloaded members, operators,
+// templates instantiated over several types, an anonymous namespace,
+// lambdas, and exceptions. Entry points named in the matrix tests are kept
+// out of line so they keep their own symbols under -O3 and LTO.
+#include <algorithm>
+#include <iostream>
+#include <map>
+#include <memory>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+#if defined(_MSC_VER)
+#define NOINLINE __declspec(noinline)
+#else
+#define NOINLINE __attribute__((noinline))
+#endif
+
+namespace geometry {
+
+class Shape {
+public:
+    virtual ~Shape() = default;
+    virtual double area() const = 0;
+    virtual std::string name() const = 0;
+};
+
+class Circle : public Shape {
+public:
+    explicit Circle(double r) : radius_(r) {}
+    double area() const override;
+    std::string name() const override { return "circle"; }
+
+private:
+    double radius_;
+};
+
+class Rect : public Shape {
+public:
+    Rect(double w, double h) : w_(w), h_(h) {}
+    double area() const override;
+    std::string name() const override { return "rect"; }
+    NOINLINE Rect scaled(double k) const { return Rect(w_ * k, h_ * k); }
+    NOINLINE Rect scaled(double kw, double kh) const { return Rect(w_ * kw, h_ * kh); }
+
+private:
+    double w_, h_;
+};
+
+NOINLINE double Circle::area() const { return 3.14159265358979 * radius_ * radius_; }
+NOINLINE double Rect::area() const { return w_ * h_; }
+
+NOINLINE bool operator<(const Shape &a, const Shape &b) { return a.area() < b.area(); }
+
+std::ostream &operator<<(std::ostream &os, const Shape &s) {
+    return os << s.name() << "(" << s.area() << ")";
+}
+
+} // namespace geometry
+
+namespace util {
+
+template <typename T>
+class Accumulator {
+public:
+    NOINLINE void add(const T &v) { total_ += v; ++count_; }
+    NOINLINE T mean() const {
+        if (count_ == 0)
+            throw std::domain_error("mean of empty accumulator");
+        return total_ / static_cast<T>(count_);
+    }
+
+private:
+    T total_{};
+    unsigned count_ = 0;
+};
+
+template <typename Container, typename Pred>
+NOINLINE std::size_t count_if_ref(const Container &c, Pred pred) {
+    return static_cast<std::size_t>(std::count_if(c.begin(), c.end(), pred));
+}
+
+} // namespace util
+
+namespace {
+
+NOINLINE std::vector<std::unique_ptr<geometry::Shape>> make_shapes() {
+    std::vector<std::unique_ptr<geometry::Shape>> shapes;
+    shapes.push_back(std::make_unique<geometry::Circle>(1.5));
+    shapes.push_back(std::make_unique<geometry::Rect>(2.0, 3.0));
+    shapes.push_back(std::make_unique<geometry::Rect>(geometry::Rect(1.0, 1.0).scaled(4.0, 0.5)));
+    return shapes;
+}
+
+} // namespace
+
+int main() {
+    auto shapes = make_shapes();
+    std::sort(shapes.begin(), shapes.end(),
+              [](const auto &a, const auto &b) { return *a < *b; });
+
+    util::Accumulator<double> areas;
+    util::Accumulator<long> sizes;
+    std::map<std::string, int> by_name;
+    for (const auto &s : shapes) {
+        std::cout << *s << "\n";
+        areas.add(s->area());
+        sizes.add(static_cast<long>(s->name().size()));
+        ++by_name[s->name()];
+    }
+
+    auto big = util::count_if_ref(shapes, [](const auto &s) { return s->area() > 2.0; });
+    std::cout << "mean area " << areas.mean() << ", mean name " << sizes.mean()
+              << ", big " << big << ", rects " << by_name["rect"] << "\n";
+
+    try {
+        util::Accumulator<int>().mean();
+    } catch (const std::domain_error &e) {
+        std::cout << "caught: " << e.what() << "\n";
+    }
+    return 0;
+}
@@ -0,0 +1,120 @@
+// Inventory sample for the managed-code parsers.
+//
+// One source builds every .NET flavor in build-managed-samples.sh
+// (framework, core, self-contained single-file, ReadyToRun), so the CIL
+// metadata tables are comparable across them. It gives the MethodDef and
+// TypeDef walkers a namespace, nested and generic types, an interface,
+// properties, a lambda (compiler-generated closure class), and an iterator
+// state machine. `Plugins` gives IL disassembly and capability detection a
+// P/Invoke into kernel32 under a different managed name plus reflection
+// loading; it only runs under `--plugin <path>`, so the sample still runs
+// anywhere. tests/managed_samples.rs expects, among others:
+//
+//   Glaurung.Samples.Inventory::Add
+//   Glaurung.Samples.Inventory::Main
+//   Glaurung.Samples.Ledger`1::Record
+//   Glaurung.Samples.Plugins::Protect -> kernel32.dll!VirtualProtect
+using System;
+using System.Collections.Generic;
+using System.IO;
+using System.Linq;
+using System.Reflection;
+using System.Runtime.InteropServices;
+
+namespace Glaurung.Samples
+{
+    public interface IPriced
+    {
+        decimal Price { get; }
+    }
+
+    public sealed class Item : IPriced
+    {
+        public Item(string name, decimal price, int quantity)
+        {
+            Name = name;
+            Price = price;
+            Quantity = quantity;
+        }
+
+        public string Name { get; }
+        public decimal Price { get; }
+        public int Quantity { get; set; }
+
+        public override string ToString() => $"{Name} x{Quantity} @ {Price}";
+    }
+
+    public class Ledger<T>
+    {
+        private readonly List<T> _entries = new List<T>();
+
+        public void Record(T entry) => _entries.Add(entry);
+
+        public IEnumerable<T> Entries()
+        {
+            foreach (var e in _entries)
+            {
+                yield return e;
+            }
+        }
+    }
+
+    public class Inventory
+    {
+        private readonly Dictionary<string, Item> _items = new Dictionary<string, Item>();
+        private readonly Ledger<string> _ledger = new Ledger<string>();
+
+        public void Add(string name, decimal price, int quantity)
+        {
+            if (_items.TryGetValue(name, out var existing))
+            {
+                existing.Quantity += quantity;
+            }
+            else
+            {
+                _items[name] = new Item(name, price, quantity);
+            }
+            _ledger.Record("add " + name);
+        }
+
+        public decimal Total() => _items.Values.Sum(i => i.Price * i.Quantity);
+
+        public static int Main(string[] args)
+        {
+            if (args.Length == 2 && args[0] == "--plugin")
+            {
+                return Plugins.Run(args[1]);
+            }
+            var inv = new Inventory();
+            inv.Add("glaurung-widget", 2.50m, 4);
+            inv.Add("glaurung-gadget", 10m, 1);
+            foreach (var arg in args)
+            {
+                inv.Add(arg, 1m, 1);
+            }
+            foreach (var entry in inv._ledger.Entries())
+            {
+                Console.WriteLine(entry);
+            }
+            Console.WriteLine("Inventory total: " + inv.Total());
+            return 0;
+        }
+    }
+
+    public static class Plugins
+    {
+        [DllImport("kernel32.dll", EntryPoint = "VirtualProtect", SetLastError = true)]
+        private static extern bool Protect(IntPtr address, UIntPtr size, uint protect, out uint old);
+
+        public static int Run(string path)
+        {
+            var asm = Assembly.Load(File.ReadAllBytes(path));
+            foreach (var type in asm.GetExportedTypes())
+            {
+                Activator.CreateInstance(type);
+            }
+            uint old;
+            return Protect(IntPtr.Zero, UIntPtr.Zero, 0x40, out old) ? 0 : 1;
+        }
+    }
+}
@@ -0,0 +1,21 @@
+#include "fold.h"
+
+#include <stdio.h>
+
+#include "_cgo_export.h"
+
+__attribute__((noinline)) int64_t c_fold(const int64_t *xs, int n) {
+    int64_t acc = 0;
+    for (int i = 0; i < n; i++) {
+        acc = goAccumulate(acc, xs[i]);
+    }
+    return acc;
+}
+
+__attribute__((noinline)) uint32_t c_checksum(const char *s) {
+    uint32_t h = 2166136261u;
+    for (; *s; s++) {
+        h = (h ^ (unsigned char)*s) * 16777619u;
+    }
+    return h;
+}
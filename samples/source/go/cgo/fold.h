@@ -0,0 +1,13 @@
+#ifndef FOLD_H
+#define FOLD_H
+
+#include <stdint.h>
+
+/* Folds xs with the Go callback goAccumulate, so the stack holds
+   Go -> C -> Go frames while it runs. */
+int64_t c_fold(const int64_t *xs, int n);
+
+/* Plain C leaf called from Go. */
+uint32_t c_checksum(const char *s);
+
+#endif
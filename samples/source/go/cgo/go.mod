@@ -0,0 +1,3 @@
+module glaurung.samples/cgo
+
+go 1.21
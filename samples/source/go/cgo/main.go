@@ -0,0 +1,55 @@
+// Command cgo mixes Go and C frames: Go calls C, C calls back into an
+// exported Go function, and a goroutine does the same concurrently.
+package main
+
+/*
+#include <stdlib.h>
+#include "fold.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+//export goAccumulate
+func goAccumulate(acc, v C.int64_t) C.int64_t {
+	return acc*31 + v
+}
+
+func fold(xs []int64) int64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	return int64(C.c_fold((*C.int64_t)(unsafe.Pointer(&xs[0])), C.int(len(xs))))
+}
+
+func checksum(s string) uint32 {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return uint32(C.c_checksum(cs))
+}
+
+func main() {
+	xs := []int64{1, 2, 3, 4, 5}
+	fmt.Printf("fold: %d\n", fold(xs))
+
+	var wg sync.WaitGroup
+	sums := make([]uint32, 4)
+	for i := range sums {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sums[i] = checksum(fmt.Sprintf("worker-%d", i))
+		}(i)
+	}
+	wg.Wait()
+	fmt.Printf("checksums: %x\n", sums)
+
+	if len(os.Args) > 1 {
+		fmt.Printf("arg checksum: %x\n", checksum(os.Args[1]))
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// Number is a constraint over the built-in numeric types.
+type Number interface {
+    ~int | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// Stack is a generic container; each instantiation is stenciled into its
+// own set of functions (e.g. main.(*Stack[go.shape.int]).Push) in pclntab.
+type Stack[T any] struct {
+    items []T
+}
+
+func (s *Stack[T]) Push(v T) {
+    s.items = append(s.items, v)
+}
+
+func (s *Stack[T]) Pop() (T, bool) {
+    var zero T
+    if len(s.items) == 0 {
+        return zero, false
+    }
+    v := s.items[len(s.items)-1]
+    s.items = s.items[:len(s.items)-1]
+    return v, true
+}
+
+func (s *Stack[T]) Len() int {
+    return len(s.items)
+}
+
+// Pair is a generic struct with two type parameters.
+type Pair[K comparable, V any] struct {
+    Key   K
+    Value V
+}
+
+func Sum[T Number](xs []T) T {
+    var total T
+    for _, x := range xs {
+        total += x
+    }
+    return total
+}
+
+func Filter[T any](xs []T, keep func(T) bool) []T {
+    out := make([]T, 0, len(xs))
+    for _, x := range xs {
+        if keep(x) {
+            out = append(out, x)
+        }
+    }
+    return out
+}
+
+func SortedPairs[K comparable, V any](m map[K]V, less func(a, b K) bool) []Pair[K, V] {
+    out := make([]Pair[K, V], 0, len(m))
+    for k, v := range m {
+        out = append(out, Pair[K, V]{k, v})
+    }
+    sort.Slice(out, func(i, j int) bool { return less(out[i].Key, out[j].Key) })
+    return out
+}
+
+type Celsius float64
+
+func main() {
+    ints := &Stack[int]{}
+    words := &Stack[string]{}
+    for i := 1; i <= 4; i++ {
+        ints.Push(i * i)
+        words.Push(strings.Repeat("x", i))
+    }
+    top, _ := ints.Pop()
+    word, _ := words.Pop()
+    fmt.Printf("top=%d word=%s ints=%d words=%d\n", top, word, ints.Len(), words.Len())
+
+    fmt.Println("sum ints:", Sum([]int{1, 2, 3}))
+    fmt.Println("sum floats:", Sum([]float64{0.5, 1.25}))
+    fmt.Println("sum celsius:", Sum([]Celsius{21.5, 19}))
+
+    evens := Filter([]int{1, 2, 3, 4, 5, 6}, func(n int) bool { return n%2 == 0 })
+    fmt.Println("evens:", evens)
+
+    ages := map[string]int{"carol": 41, "alice": 30, "bob": 25}
+    for _, p := range SortedPairs(ages, func(a, b string) bool { return a < b }) {
+        fmt.Printf("%s=%d\n", p.Key, p.Value)
+    }
+}
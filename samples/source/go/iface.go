@@ -0,0 +1,98 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+)
+
+// Shape is implemented by several concrete types, so the binary carries one
+// itab per (interface, concrete type) pair that is converted at runtime.
+type Shape interface {
+    Area() float64
+    Perimeter() float64
+}
+
+// Named embeds Shape to exercise interface embedding.
+type Named interface {
+    Shape
+    fmt.Stringer
+}
+
+type Rect struct{ W, H float64 }
+type Circle struct{ R float64 }
+type Square struct{ Rect }
+
+func (r Rect) Area() float64      { return r.W * r.H }
+func (r Rect) Perimeter() float64 { return 2 * (r.W + r.H) }
+func (r Rect) String() string     { return fmt.Sprintf("rect %gx%g", r.W, r.H) }
+
+func (c *Circle) Area() float64      { return 3.14159 * c.R * c.R }
+func (c *Circle) Perimeter() float64 { return 2 * 3.14159 * c.R }
+func (c *Circle) String() string     { return fmt.Sprintf("circle r=%g", c.R) }
+
+func NewSquare(side float64) Square { return Square{Rect{side, side}} }
+
+// byArea implements sort.Interface.
+type byArea []Named
+
+func (s byArea) Len() int           { return len(s) }
+func (s byArea) Less(i, j int) bool { return s[i].Area() < s[j].Area() }
+func (s byArea) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// upperWriter is an io.Writer wrapper converted to io.Writer at runtime.
+type upperWriter struct{ w io.Writer }
+
+func (u upperWriter) Write(p []byte) (int, error) {
+    return u.w.Write([]byte(strings.ToUpper(string(p))))
+}
+
+var errEmpty = errors.New("empty shape list")
+
+type shapeError struct{ name string }
+
+func (e *shapeError) Error() string { return "bad shape: " + e.name }
+
+func describe(v interface{}) string {
+    switch s := v.(type) {
+    case Named:
+        return s.String()
+    case Shape:
+        return fmt.Sprintf("shape with area %.2f", s.Area())
+    case error:
+        return "error: " + s.Error()
+    default:
+        return fmt.Sprintf("unknown %T", v)
+    }
+}
+
+func largest(shapes []Named) (Named, error) {
+    if len(shapes) == 0 {
+        return nil, errEmpty
+    }
+    sort.Sort(byArea(shapes))
+    return shapes[len(shapes)-1], nil
+}
+
+func main() {
+    shapes := []Named{Rect{3, 4}, &Circle{1.5}, NewSquare(2)}
+    var out io.Writer = upperWriter{os.Stdout}
+    for _, s := range shapes {
+        fmt.Fprintln(out, describe(s))
+    }
+    if big, err := largest(shapes); err == nil {
+        area := big.Area // method value
+        fmt.Printf("largest: %s (%.2f)\n", big, area())
+    }
+    if _, err := largest(nil); errors.Is(err, errEmpty) {
+        fmt.Println(describe(err))
+    }
+    var err error = &shapeError{"hexagon"}
+    var se *shapeError
+    if errors.As(err, &se) {
+        fmt.Println(describe(se))
+    }
+}
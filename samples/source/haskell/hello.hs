@@ -0,0 +1,84 @@
+-- Haskell sample with type classes, algebraic data types, laziness,
+-- exceptions, and a Data.Map, for binary analysis.
+--
+-- GHC emits code for the STG machine, not C calling conventions: functions
+-- are z-encoded closures and info tables (Main_riskyOperation_info), control
+-- flow is tail jumps through the evaluation stack, and the RTS (scheduler,
+-- GC, hs_main) is linked in beside them. The result is a gcc-linked ELF
+-- with almost nothing a C-oriented analysis expects.
+
+module Main (main) where
+
+import Control.Exception (ArithException (..), evaluate, throw, try)
+import qualified Data.Map.Strict as Map
+import Data.IORef (modifyIORef', newIORef, readIORef)
+import System.Environment (getArgs)
+
+globalConstant :: Int
+globalConstant = 42
+
+data Shape
+  = Circle Double
+  | Square Double
+  deriving (Show)
+
+class HasArea a where
+  area :: a -> Double
+
+instance HasArea Shape where
+  area (Circle r) = 3.14159 * r * r
+  area (Square s) = s * s
+
+data Operation
+  = Add Int Int
+  | Multiply Int Int
+  | Divide Int Int
+  | Print String
+
+runOperation :: Operation -> String
+runOperation (Add a b) = "Add: " ++ show a ++ " + " ++ show b ++ " = " ++ show (a + b)
+runOperation (Multiply a b) = "Multiply: " ++ show a ++ " * " ++ show b ++ " = " ++ show (a * b)
+runOperation (Divide _ 0) = "Division by zero!"
+runOperation (Divide a b) = "Divide: " ++ show a ++ " / " ++ show b ++ " = " ++ show (a `div` b)
+runOperation (Print msg) = "Message: " ++ msg
+
+riskyOperation :: Int -> Either String Int
+riskyOperation 0 = Left "Cannot process zero"
+riskyOperation v
+  | v < 0 = Left "Negative values not supported"
+  | otherwise = Right (v * 2)
+
+largest :: (Ord a) => [a] -> a
+largest = foldr1 max
+
+processData :: [Int] -> Int
+processData = sum . map (* 2) . filter (> 0)
+
+-- Infinite list, forced only as far as needed.
+primes :: [Int]
+primes = sieve [2 ..] where sieve (p : xs) = p : sieve [x | x <- xs, x `mod` p /= 0]
+
+main :: IO ()
+main = do
+  putStrLn "Hello, World from Haskell!"
+  args <- getArgs
+  putStrLn ("Number of arguments: " ++ show (length args))
+
+  mapM_ (putStrLn . runOperation) [Add 10 20, Multiply 5 7, Divide 100 0, Print "Processing complete"]
+  mapM_ (\s -> putStrLn (show s ++ ": area " ++ show (area s))) [Circle 2.0, Square 3.0]
+
+  mapM_ (\v -> putStrLn (either (("Error for " ++ show v ++ ": ") ++) (\r -> "Operation(" ++ show v ++ ") = " ++ show r) (riskyOperation v))) [10, 0, -5, 42]
+
+  r <- try (evaluate (throw DivideByZero :: Int))
+  putStrLn (either (\e -> "Caught: " ++ show (e :: ArithException)) show r)
+
+  let features = Map.fromList [("feature1", True), ("feature2", False)] :: Map.Map String Bool
+  putStrLn ("Features: " ++ show (Map.size features))
+  putStrLn ("Largest: " ++ show (largest [3, 9, 4 :: Int]) ++ " " ++ show (largest [1.5, 0.5 :: Double]))
+  putStrLn ("First primes: " ++ show (take 10 primes))
+  putStrLn ("Processed sum: " ++ show (processData [1, -2, 3, -4, 5, 6]))
+
+  counter <- newIORef (0 :: Int)
+  modifyIORef' counter (+ 1)
+  n <- readIORef counter
+  putStrLn ("Global counter: " ++ show n ++ " (constant " ++ show globalConstant ++ ")")
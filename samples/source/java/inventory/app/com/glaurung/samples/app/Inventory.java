@@ -0,0 +1,98 @@
+// Inventory sample for the JVM parsers, built by build-managed-samples.sh
+// as loose classes, a fat JAR (with the ledger library folded in), and a
+// jlink image. Beyond plain methods it gives the class-file walker an
+// interface, an enum, a nested record-like class, a lambda (invokedynamic
+// plus a BootstrapMethods attribute), and string concatenation through
+// StringConcatFactory on JDK 9+. `price` calls through the Priced
+// interface so the call graph has an override to find, and `Plugins`
+// gives capability detection a URLClassLoader, a reflective invoke, and
+// Runtime.exec; it only runs under `--plugin <jar>`, so the sample still
+// runs anywhere. tests/managed_samples.rs expects the class
+// com/glaurung/samples/app/Inventory with methods add, total, and main,
+// and the string "glaurung-widget".
+package com.glaurung.samples.app;
+
+import com.glaurung.samples.ledger.Ledger;
+import java.io.File;
+import java.net.URL;
+import java.net.URLClassLoader;
+import java.util.LinkedHashMap;
+import java.util.Map;
+
+public class Inventory {
+    interface Priced {
+        long priceCents();
+    }
+
+    enum Category {
+        WIDGET,
+        GADGET,
+        OTHER
+    }
+
+    static final class Item implements Priced {
+        final String name;
+        final Category category;
+        final long priceCents;
+        int quantity;
+
+        Item(String name, Category category, long priceCents, int quantity) {
+            this.name = name;
+            this.category = category;
+            this.priceCents = priceCents;
+            this.quantity = quantity;
+        }
+
+        @Override
+        public long priceCents() {
+            return priceCents;
+        }
+    }
+
+    private final Map<String, Item> items = new LinkedHashMap<>();
+    private final Ledger<String> ledger = new Ledger<>();
+
+    public void add(String name, Category category, long priceCents, int quantity) {
+        items.merge(name, new Item(name, category, priceCents, quantity), (old, neu) -> {
+            old.quantity += neu.quantity;
+            return old;
+        });
+        ledger.record("add " + name);
+    }
+
+    public long total() {
+        return items.values().stream().mapToLong(i -> price(i) * i.quantity).sum();
+    }
+
+    static long price(Priced p) {
+        return p.priceCents();
+    }
+
+    public static void main(String[] args) throws Exception {
+        if (args.length == 2 && args[0].equals("--plugin")) {
+            System.exit(Plugins.run(args[1]));
+        }
+        Inventory inv = new Inventory();
+        inv.add("glaurung-widget", Category.WIDGET, 250, 4);
+        inv.add("glaurung-gadget", Category.GADGET, 1000, 1);
+        for (String arg : args) {
+            inv.add(arg, Category.OTHER, 100, 1);
+        }
+        inv.ledger.entries().forEach(System.out::println);
+        System.out.println("Inventory total (cents): " + inv.total());
+    }
+}
+
+final class Plugins {
+    private Plugins() {}
+
+    static int run(String path) throws Exception {
+        URL url = new File(path).toURI().toURL();
+        try (URLClassLoader loader = new URLClassLoader(new URL[] {url})) {
+            Class<?> plugin = Class.forName("glaurung.Plugin", true, loader);
+            plugin.getMethod("main", String[].class).invoke(null, (Object) new String[0]);
+        }
+        Process java = Runtime.getRuntime().exec(new String[] {"java", "-version"});
+        return java.waitFor();
+    }
+}
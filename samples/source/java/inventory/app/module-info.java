@@ -0,0 +1,3 @@
+module com.glaurung.samples.app {
+    requires com.glaurung.samples.ledger;
+}
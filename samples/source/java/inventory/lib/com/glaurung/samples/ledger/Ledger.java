@@ -0,0 +1,24 @@
+// Dependency half of the inventory sample: build-managed-samples.sh
+// compiles it on its own and folds its classes into the fat JAR, the way
+// shading bundles a third-party library.
+package com.glaurung.samples.ledger;
+
+import java.util.ArrayList;
+import java.util.Collections;
+import java.util.List;
+
+public final class Ledger<T> {
+    private final List<T> entries = new ArrayList<>();
+
+    public void record(T entry) {
+        entries.add(entry);
+    }
+
+    public List<T> entries() {
+        return Collections.unmodifiableList(entries);
+    }
+
+    public int size() {
+        return entries.size();
+    }
+}
@@ -0,0 +1,3 @@
+module com.glaurung.samples.ledger {
+    exports com.glaurung.samples.ledger;
+}
@@ -0,0 +1,75 @@
+# Nim sample with objects, methods, generics, exceptions, and closures, for
+# binary analysis.
+#
+# Nim compiles through C: the binary is a gcc (or clang) executable whose
+# .comment says GCC, but every function is a mangled Nim proc
+# ("riskyOperation__hello_u12") called from NimMain/NimMainModule, with the
+# Nim runtime (GC, exceptions, string ops) linked in beside it.
+
+import std/[os, strformat, tables]
+
+const GlobalConstant = 42
+var globalCounter = 0
+
+type
+  Shape = ref object of RootObj
+    name: string
+  Circle = ref object of Shape
+    radius: float
+  Square = ref object of Shape
+    side: float
+
+  ParseError = object of CatchableError
+
+method area(s: Shape): float {.base.} = 0.0
+method area(c: Circle): float = 3.14159 * c.radius * c.radius
+method area(s: Square): float = s.side * s.side
+
+# Generic: one instantiation per element type.
+proc largest[T](items: openArray[T]): T =
+  result = items[0]
+  for x in items:
+    if x > result: result = x
+
+proc riskyOperation(value: int): int =
+  if value == 0:
+    raise newException(ParseError, "Cannot process zero")
+  if value < 0:
+    raise newException(ParseError, "Negative values not supported")
+  value * 2
+
+proc makeMultiplier(factor: int): proc (x: int): int =
+  result = proc (x: int): int = x * factor
+
+proc processData(data: openArray[int]): int =
+  for x in data:
+    if x > 0: result += x * 2
+
+proc main() =
+  echo "Hello, World from Nim!"
+  echo &"Number of arguments: {paramCount()}"
+
+  let shapes: seq[Shape] = @[Circle(name: "circle", radius: 2.0), Square(name: "square", side: 3.0)]
+  for s in shapes:
+    echo &"{s.name}: area {s.area():.2f}"
+
+  for v in [10, 0, -5, 42]:
+    try:
+      echo &"Operation({v}) = {riskyOperation(v)}"
+    except ParseError as e:
+      echo &"Error for {v}: {e.msg}"
+
+  var features = initTable[string, bool]()
+  features["feature1"] = true
+  features["feature2"] = false
+  echo &"Features: {features.len}"
+
+  echo &"Largest: {largest([3, 9, 4])} {largest([1.5, 0.5])}"
+  let triple = makeMultiplier(3)
+  echo &"3 * 7 = {triple(7)}"
+  echo &"Processed sum: {processData([1, -2, 3, -4, 5, 6])}"
+
+  inc globalCounter
+  echo &"Global counter: {globalCounter} (constant {GlobalConstant})"
+
+main()
@@ -0,0 +1,98 @@
+// Async Rust without an external runtime: a tiny single-threaded executor
+// drives hand-written futures and async fns, so the binary carries the
+// generator state machines, Waker vtables, and Poll plumbing that real
+// async code produces.
+
+use std::collections::VecDeque;
+use std::future::Future;
+use std::pin::Pin;
+use std::sync::{Arc, Mutex};
+use std::task::{Context, Poll, Wake, Waker};
+
+/// Future that is pending for `remaining` polls before completing.
+struct Countdown {
+    remaining: u32,
+    label: &'static str,
+}
+
+impl Future for Countdown {
+    type Output = &'static str;
+
+    fn poll(mut self: Pin<&mut Self>, cx: &mut Context<'_>) -> Poll<Self::Output> {
+        if self.remaining == 0 {
+            Poll::Ready(self.label)
+        } else {
+            self.remaining -= 1;
+            cx.waker().wake_by_ref();
+            Poll::Pending
+        }
+    }
+}
+
+type Task = Pin<Box<dyn Future<Output = ()> + Send>>;
+
+#[derive(Default)]
+struct Executor {
+    queue: Mutex<VecDeque<Task>>,
+}
+
+struct Ready;
+
+impl Wake for Ready {
+    fn wake(self: Arc<Self>) {}
+}
+
+impl Executor {
+    fn spawn<F: Future<Output = ()> + Send + 'static>(&self, fut: F) {
+        self.queue.lock().unwrap().push_back(Box::pin(fut));
+    }
+
+    /// Round-robin the queued tasks until all complete.
+    fn run(&self) -> usize {
+        let waker = Waker::from(Arc::new(Ready));
+        let mut cx = Context::from_waker(&waker);
+        let mut polls = 0;
+        loop {
+            let Some(mut task) = self.queue.lock().unwrap().pop_front() else {
+                return polls;
+            };
+            polls += 1;
+            if task.as_mut().poll(&mut cx).is_pending() {
+                self.queue.lock().unwrap().push_back(task);
+            }
+        }
+    }
+}
+
+async fn fetch(id: u32) -> String {
+    let label = Countdown {
+        remaining: id,
+        label: "fetched",
+    }
+    .await;
+    format!("{label} #{id}")
+}
+
+async fn pipeline(ids: Vec<u32>, sink: Arc<Mutex<Vec<String>>>) {
+    for id in ids {
+        let item = fetch(id).await;
+        sink.lock().unwrap().push(item);
+    }
+}
+
+fn main() {
+    let sink = Arc::new(Mutex::new(Vec::new()));
+    let exec = Executor::default();
+    exec.spawn(pipeline(vec![3, 1], Arc::clone(&sink)));
+    exec.spawn(pipeline(vec![2], Arc::clone(&sink)));
+    exec.spawn(async {
+        let done = Countdown {
+            remaining: 4,
+            label: "timer",
+        }
+        .await;
+        println!("{done} fired");
+    });
+    let polls = exec.run();
+    println!("{:?} after {polls} polls", sink.lock().unwrap());
+}
@@ -0,0 +1,43 @@
+// A `cdylib`: a C-ABI shared library written in Rust. The exported
+// `#[no_mangle] extern "C"` functions sit next to the statically linked
+// Rust std, so the image looks like a C library from the outside while its
+// internals are Rust (mangled symbols, panic machinery, `rust_begin_unwind`
+// or `rust_panic`).
+
+use std::ffi::{c_char, CStr};
+use std::panic;
+
+/// Checksum of a NUL-terminated string; returns 0 for NULL.
+#[no_mangle]
+pub extern "C" fn glaurung_checksum(s: *const c_char) -> u32 {
+    if s.is_null() {
+        return 0;
+    }
+    let bytes = unsafe { CStr::from_ptr(s) }.to_bytes();
+    bytes
+        .iter()
+        .fold(2166136261u32, |h, b| (h ^ *b as u32).wrapping_mul(16777619))
+}
+
+/// Sum of `len` values at `ptr`; returns -1 instead of unwinding across the
+/// FFI boundary if the sum overflows.
+#[no_mangle]
+pub extern "C" fn glaurung_sum(ptr: *const i32, len: usize) -> i64 {
+    if ptr.is_null() {
+        return 0;
+    }
+    let values = unsafe { std::slice::from_raw_parts(ptr, len) };
+    panic::catch_unwind(|| {
+        values
+            .iter()
+            .try_fold(0i32, |acc, v| acc.checked_add(*v))
+            .expect("sum overflowed") as i64
+    })
+    .unwrap_or(-1)
+}
+
+/// Library version as a static NUL-terminated string.
+#[no_mangle]
+pub extern "C" fn glaurung_version() -> *const c_char {
+    c"glaurung-sample 1.0".as_ptr()
+}
@@ -0,0 +1,118 @@
+// Generics and traits: one generic function and one generic type
+// monomorphized over several concrete types, trait objects with vtables,
+// blanket impls, associated types, and closures passed as `impl Fn`. Each
+// instantiation gets its own symbol, which is what the demangling tests
+// look for (e.g. `generics::largest::<f64>`).
+
+use std::collections::BTreeMap;
+use std::fmt::Display;
+use std::hint::black_box;
+
+trait Describe {
+    fn describe(&self) -> String;
+}
+
+impl<T: Display> Describe for T {
+    fn describe(&self) -> String {
+        format!("<{self}>")
+    }
+}
+
+trait Shape {
+    fn area(&self) -> f64;
+    fn name(&self) -> &'static str;
+}
+
+struct Square(f64);
+struct Triangle(f64, f64);
+
+impl Shape for Square {
+    fn area(&self) -> f64 {
+        self.0 * self.0
+    }
+    fn name(&self) -> &'static str {
+        "square"
+    }
+}
+
+impl Shape for Triangle {
+    fn area(&self) -> f64 {
+        0.5 * self.0 * self.1
+    }
+    fn name(&self) -> &'static str {
+        "triangle"
+    }
+}
+
+#[inline(never)]
+fn largest<T: PartialOrd + Copy>(items: &[T]) -> Option<T> {
+    let mut it = items.iter().copied();
+    let first = it.next()?;
+    Some(it.fold(first, |m, x| if x > m { x } else { m }))
+}
+
+struct Registry<K, V> {
+    entries: BTreeMap<K, V>,
+}
+
+impl<K: Ord, V> Registry<K, V> {
+    fn new() -> Self {
+        Registry {
+            entries: BTreeMap::new(),
+        }
+    }
+
+    #[inline(never)]
+    fn insert(&mut self, key: K, value: V) -> &mut Self {
+        self.entries.insert(key, value);
+        self
+    }
+
+    #[inline(never)]
+    fn map_values<W>(&self, f: impl Fn(&V) -> W) -> Vec<W> {
+        self.entries.values().map(f).collect()
+    }
+}
+
+trait Parser {
+    type Output;
+    fn parse(&self, s: &str) -> Option<Self::Output>;
+}
+
+struct Csv;
+
+impl Parser for Csv {
+    type Output = Vec<i64>;
+    fn parse(&self, s: &str) -> Option<Vec<i64>> {
+        s.split(',').map(|p| p.trim().parse().ok()).collect()
+    }
+}
+
+fn total_area(shapes: &[Box<dyn Shape>]) -> f64 {
+    shapes.iter().map(|s| s.area()).sum()
+}
+
+fn main() {
+    // black_box keeps the optimizer from folding the calls away in release.
+    println!(
+        "{:?} {:?} {:?}",
+        largest(black_box(&[3i64, 9, 2])),
+        largest(black_box(&[1.5f64, -2.0])),
+        largest(black_box(&['q', 'z']))
+    );
+
+    let shapes: Vec<Box<dyn Shape>> = vec![Box::new(Square(2.0)), Box::new(Triangle(3.0, 4.0))];
+    for s in &shapes {
+        println!("{} {}", s.name(), s.area().describe());
+    }
+    println!("total {}", total_area(&shapes));
+
+    let mut reg = Registry::new();
+    reg.insert("b", 2u32).insert("a", 1u32);
+    println!("{:?}", reg.map_values(|v| v * 10));
+    let mut names = Registry::new();
+    names.insert(2u8, "two".to_string());
+    println!("{:?}", names.map_values(|s| s.len()));
+
+    println!("{:?} {}", Csv.parse("1, 2, 3"), 42.describe());
+}
@@ -0,0 +1,82 @@
+// Panic and error-handling paths: unwinding through catch_unwind, a custom
+// panic hook, unwrap/expect failures, arithmetic overflow checks, slice
+// bounds checks, and `?` propagation of a custom error type. These leave
+// the panic-location tables and message strings Rust detection keys on.
+
+use std::fmt;
+use std::num::ParseIntError;
+use std::panic;
+
+#[derive(Debug)]
+enum ConfigError {
+    Missing(&'static str),
+    BadNumber(ParseIntError),
+}
+
+impl fmt::Display for ConfigError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            ConfigError::Missing(key) => write!(f, "missing key {key}"),
+            ConfigError::BadNumber(e) => write!(f, "bad number: {e}"),
+        }
+    }
+}
+
+impl std::error::Error for ConfigError {}
+
+impl From<ParseIntError> for ConfigError {
+    fn from(e: ParseIntError) -> Self {
+        ConfigError::BadNumber(e)
+    }
+}
+
+fn lookup<'a>(pairs: &'a [(&str, &str)], key: &'static str) -> Result<&'a str, ConfigError> {
+    pairs
+        .iter()
+        .find(|(k, _)| *k == key)
+        .map(|(_, v)| *v)
+        .ok_or(ConfigError::Missing(key))
+}
+
+fn port(pairs: &[(&str, &str)]) -> Result<u16, ConfigError> {
+    Ok(lookup(pairs, "port")?.parse()?)
+}
+
+#[inline(never)]
+fn checked_index(v: &[u32], i: usize) -> u32 {
+    v[i]
+}
+
+#[inline(never)]
+fn add_u8(a: u8, b: u8) -> u8 {
+    a.checked_add(b).expect("u8 addition overflowed")
+}
+
+fn main() {
+    panic::set_hook(Box::new(|info| {
+        let msg = info
+            .payload()
+            .downcast_ref::<&str>()
+            .map(|s| s.to_string())
+            .or_else(|| info.payload().downcast_ref::<String>().cloned())
+            .unwrap_or_default();
+        eprintln!("hook: {msg}");
+    }));
+
+    let config = [("host", "localhost"), ("port", "80x")];
+    match port(&config) {
+        Ok(p) => println!("port {p}"),
+        Err(e) => println!("config error: {e}"),
+    }
+    println!("{}", lookup(&config, "user").unwrap_err());
+
+    let data = vec![1, 2, 3];
+    let n = std::env::args().count();
+    let outcomes = [
+        panic::catch_unwind(|| checked_index(&data, n + 5)).is_err(),
+        panic::catch_unwind(|| add_u8(200, 100 + n as u8)).is_err(),
+        panic::catch_unwind(|| None::<u32>.unwrap()).is_err(),
+        panic::catch_unwind(|| "x".parse::<i32>().unwrap()).is_err(),
+    ];
+    println!("caught {:?}", outcomes);
+}
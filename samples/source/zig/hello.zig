@@ -0,0 +1,118 @@
+// Zig sample with comptime generics, error unions, tagged unions, and an
+// allocator, for binary analysis. Written against Zig 0.13.
+//
+// Zig links its own start code and panic handler instead of a C runtime
+// (unless -lc), and mangles nothing: symbols are plain dotted paths
+// ("hello.Stack(i32).push"), which is easy to mistake for C.
+
+const std = @import("std");
+
+const GLOBAL_CONSTANT: i32 = 42;
+var global_counter: i32 = 0;
+
+const ParseError = error{ Empty, Negative, TooLarge };
+
+// Tagged union with a method.
+const Operation = union(enum) {
+    add: [2]i32,
+    multiply: [2]i32,
+    divide: [2]i32,
+    print: []const u8,
+
+    fn run(self: Operation, out: anytype) !void {
+        switch (self) {
+            .add => |v| try out.print("Add: {d} + {d} = {d}\n", .{ v[0], v[1], v[0] + v[1] }),
+            .multiply => |v| try out.print("Multiply: {d} * {d} = {d}\n", .{ v[0], v[1], v[0] * v[1] }),
+            .divide => |v| {
+                if (v[1] == 0) {
+                    try out.print("Division by zero!\n", .{});
+                } else {
+                    try out.print("Divide: {d} / {d} = {d}\n", .{ v[0], v[1], @divTrunc(v[0], v[1]) });
+                }
+            },
+            .print => |msg| try out.print("Message: {s}\n", .{msg}),
+        }
+    }
+};
+
+// Comptime generic: one instantiation per element type.
+fn Stack(comptime T: type) type {
+    return struct {
+        items: std.ArrayList(T),
+
+        const Self = @This();
+
+        fn init(allocator: std.mem.Allocator) Self {
+            return .{ .items = std.ArrayList(T).init(allocator) };
+        }
+
+        fn deinit(self: *Self) void {
+            self.items.deinit();
+        }
+
+        fn push(self: *Self, value: T) !void {
+            try self.items.append(value);
+        }
+
+        fn pop(self: *Self) ?T {
+            return self.items.popOrNull();
+        }
+    };
+}
+
+fn riskyOperation(value: i32) ParseError!i32 {
+    if (value == 0) return error.Empty;
+    if (value < 0) return error.Negative;
+    if (value > 1000) return error.TooLarge;
+    return value * 2;
+}
+
+fn processData(data: []const i32) i32 {
+    var sum: i32 = 0;
+    for (data) |x| {
+        if (x > 0) sum += x * 2;
+    }
+    return sum;
+}
+
+pub fn main() !void {
+    const out = std.io.getStdOut().writer();
+    try out.print("Hello, World from Zig!\n", .{});
+
+    var gpa = std.heap.GeneralPurposeAllocator(.{}){};
+    defer _ = gpa.deinit();
+    const allocator = gpa.allocator();
+
+    const args = try std.process.argsAlloc(allocator);
+    defer std.process.argsFree(allocator, args);
+    try out.print("Number of arguments: {d}\n", .{args.len - 1});
+
+    const operations = [_]Operation{
+        .{ .add = .{ 10, 20 } },
+        .{ .multiply = .{ 5, 7 } },
+        .{ .divide = .{ 100, 0 } },
+        .{ .print = "Processing complete" },
+    };
+    for (operations) |op| try op.run(out);
+
+    for ([_]i32{ 10, 0, -5, 4242 }) |v| {
+        if (riskyOperation(v)) |result| {
+            try out.print("Operation({d}) = {d}\n", .{ v, result });
+        } else |err| {
+            try out.print("Error for {d}: {s}\n", .{ v, @errorName(err) });
+        }
+    }
+
+    var ints = Stack(i32).init(allocator);
+    defer ints.deinit();
+    var floats = Stack(f64).init(allocator);
+    defer floats.deinit();
+    try ints.push(GLOBAL_CONSTANT);
+    try floats.push(3.5);
+    try out.print("Popped: {?d} {?d}\n", .{ ints.pop(), floats.pop() });
+
+    try out.print("Processed sum: {d}\n", .{processData(&[_]i32{ 1, -2, 3, -4, 5, 6 })});
+
+    global_counter += 1;
+    try out.print("Global counter: {d}\n", .{global_counter});
+}
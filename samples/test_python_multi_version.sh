@@ -2,15 +2,18 @@
 
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 SOURCE_DIR="$SCRIPT_DIR/source"
-OUTPUT_DIR="$SCRIPT_DIR/test_output"
+# Set OUTPUT_DIR to write into the sample tree, e.g.
+# samples/binaries/platforms/linux/amd64/export for tests/pyc_samples.rs.
+OUTPUT_DIR="${OUTPUT_DIR:-$SCRIPT_DIR/test_output}"
 PYTHON_SOURCE="$SOURCE_DIR/python/hello.py"
 
 echo "Testing multi-version Python bytecode generation..."
 echo "Source: $PYTHON_SOURCE"
 echo "Output: $OUTPUT_DIR/python"
+mkdir -p "$OUTPUT_DIR/python"
 
 # Python versions to test
-PYTHON_VERSIONS=("3.8" "3.9" "3.10" "3.11" "3.12" "3.13")
+PYTHON_VERSIONS=("3.6" "3.7" "3.8" "3.9" "3.10" "3.11" "3.12" "3.13")
 
 for version in "${PYTHON_VERSIONS[@]}"; do
     echo "Testing Python $version..."
@@ -0,0 +1,254 @@
+#!/usr/bin/env python3
+"""
+Fetch the pinned public binary corpus into samples/public/.
+
+samples/public-corpus.json lists redistributable third-party binaries
+(Debian coreutils builds for seven architectures, the MSVC runtime DLLs
+shipped in CPython's embeddable packages, OpenWrt and Raspberry Pi
+firmware) by URL, SHA-256, and license. Each download is checked against
+its pinned hash before anything is extracted; a mismatch is an error and
+the file is discarded, so a moved or tampered upstream never reaches the
+tests.
+
+Downloads are cached by hash in ~/.cache/glaurung/public-corpus (override
+with GLAURUNG_CORPUS_CACHE). Extracted files land in
+
+  samples/public/<os>/<arch>/<name>/<file>
+
+with samples/public/index.json recording each file's hash and size. The
+directory is git-ignored; only the manifest is committed.
+
+Entries with an empty "sha256" are not fetched. To add one, fill in
+everything else, run with --pin NAME to download it once and write its
+hash into the manifest, and review the result before committing.
+
+Usage:
+    python scripts/fetch_public_corpus.py [--list] [--pin] [--force] [NAME ...]
+"""
+from __future__ import annotations
+
+import argparse
+import gzip
+import hashlib
+import io
+import json
+import os
+import shutil
+import sys
+import tarfile
+import tempfile
+import urllib.request
+import zipfile
+from pathlib import Path
+from typing import Dict, List, Optional
+
+ROOT = Path(__file__).resolve().parents[1]
+MANIFEST = ROOT / "samples" / "public-corpus.json"
+DEST_DIR = ROOT / "samples" / "public"
+CACHE_DIR = Path(
+    os.environ.get("GLAURUNG_CORPUS_CACHE", Path.home() / ".cache" / "glaurung" / "public-corpus")
+)
+KINDS = ("deb", "zip", "gz", "raw")
+USER_AGENT = "glaurung-corpus-fetch/1"
+
+
+def sha256_bytes(data: bytes) -> str:
+    return hashlib.sha256(data).hexdigest()
+
+
+def download(url: str) -> bytes:
+    req = urllib.request.Request(url, headers={"User-Agent": USER_AGENT})
+    with urllib.request.urlopen(req, timeout=120) as resp:
+        return resp.read()
+
+
+def fetch(entry: Dict, pin: bool) -> Optional[bytes]:
+    """The artifact's bytes, from cache or network, verified against its pin.
+
+    With pin=True an unpinned entry is downloaded and its hash recorded in
+    `entry`; a pinned one is still verified.
+    """
+    want = entry.get("sha256", "")
+    if want:
+        cached = CACHE_DIR / want
+        if cached.is_file():
+            data = cached.read_bytes()
+            if sha256_bytes(data) == want:
+                return data
+            cached.unlink()
+    elif not pin:
+        print(f"skip {entry['name']}: not pinned (run with --pin {entry['name']})")
+        return None
+
+    print(f"fetch {entry['url']}")
+    data = download(entry["url"])
+    got = sha256_bytes(data)
+    if not want:
+        entry["sha256"] = got
+        print(f"pinned {entry['name']}: {got}")
+    elif got != want:
+        raise ValueError(f"{entry['name']}: sha256 {got} does not match pinned {want}")
+    CACHE_DIR.mkdir(parents=True, exist_ok=True)
+    with tempfile.NamedTemporaryFile(dir=CACHE_DIR, delete=False) as tmp:
+        tmp.write(data)
+    Path(tmp.name).replace(CACHE_DIR / got)
+    return data
+
+
+def ar_members(data: bytes) -> Dict[str, bytes]:
+    """Members of a System V `ar` archive (the .deb container)."""
+    if not data.startswith(b"!<arch>\n"):
+        raise ValueError("not an ar archive")
+    out: Dict[str, bytes] = {}
+    off = 8
+    while off + 60 <= len(data):
+        header = data[off : off + 60]
+        name = header[:16].decode("ascii").strip().rstrip("/")
+        size = int(header[48:58].decode("ascii").strip())
+        off += 60
+        out[name] = data[off : off + size]
+        off += size + (size & 1)
+    return out
+
+
+def deb_files(data: bytes) -> Dict[str, bytes]:
+    """Regular files in a .deb's data.tar.*, by path."""
+    members = ar_members(data)
+    payload = next((v for k, v in members.items() if k.startswith("data.tar")), None)
+    if payload is None:
+        raise ValueError("no data.tar member")
+    out: Dict[str, bytes] = {}
+    # tarfile detects gz/xz/bz2; zstd payloads (Ubuntu) are not supported.
+    with tarfile.open(fileobj=io.BytesIO(payload)) as tar:
+        for m in tar.getmembers():
+            if m.isfile():
+                f = tar.extractfile(m)
+                if f is not None:
+                    out[m.name.lstrip("./")] = f.read()
+    return out
+
+
+def zip_files(data: bytes) -> Dict[str, bytes]:
+    with zipfile.ZipFile(io.BytesIO(data)) as z:
+        return {i.filename: z.read(i) for i in z.infolist() if not i.is_dir()}
+
+
+def unpack(entry: Dict, data: bytes) -> Dict[str, bytes]:
+    """Output file name -> bytes for one artifact."""
+    kind = entry["kind"]
+    url_name = entry["url"].rsplit("/", 1)[-1]
+    if kind == "raw":
+        return {url_name: data}
+    if kind == "gz":
+        return {url_name[: -len(".gz")] if url_name.endswith(".gz") else url_name: gzip.decompress(data)}
+    files = deb_files(data) if kind == "deb" else zip_files(data)
+    by_base: Dict[str, bytes] = {}
+    for path, content in sorted(files.items()):
+        by_base.setdefault(path.rsplit("/", 1)[-1], content)
+    out: Dict[str, bytes] = {}
+    for base in entry.get("extract", []):
+        if base in by_base:
+            out[base] = by_base[base]
+        else:
+            print(f"warning: {entry['name']}: no {base} in the archive", file=sys.stderr)
+    return out
+
+
+def validate(manifest: Dict) -> List[str]:
+    problems = []
+    names = set()
+    for e in manifest["artifacts"]:
+        name = e.get("name", "?")
+        for key in ("name", "platform", "url", "kind", "license", "source"):
+            if not e.get(key):
+                problems.append(f"{name}: missing {key}")
+        if e.get("kind") not in KINDS:
+            problems.append(f"{name}: kind must be one of {', '.join(KINDS)}")
+        if e.get("kind") in ("deb", "zip") and not e.get("extract"):
+            problems.append(f"{name}: archive without an extract list")
+        if not str(e.get("url", "")).startswith("https://"):
+            problems.append(f"{name}: url must be https")
+        if name in names:
+            problems.append(f"{name}: duplicate name")
+        names.add(name)
+    return problems
+
+
+def main(argv: Optional[List[str]] = None) -> int:
+    p = argparse.ArgumentParser(description=__doc__.split("\n\n")[0].strip())
+    p.add_argument("names", nargs="*", help="only these artifacts (default: all)")
+    p.add_argument("--list", action="store_true", help="list artifacts and their pin state")
+    p.add_argument("--pin", action="store_true", help="download unpinned entries and record their hashes")
+    p.add_argument("--force", action="store_true", help="re-extract files that already exist")
+    args = p.parse_args(argv)
+
+    manifest = json.loads(MANIFEST.read_text())
+    problems = validate(manifest)
+    if problems:
+        for msg in problems:
+            print(f"error: {msg}", file=sys.stderr)
+        return 2
+    entries = [e for e in manifest["artifacts"] if not args.names or e["name"] in args.names]
+    unknown = set(args.names) - {e["name"] for e in entries}
+    if unknown:
+        print(f"error: unknown artifact(s): {', '.join(sorted(unknown))}", file=sys.stderr)
+        return 2
+
+    if args.list:
+        for e in entries:
+            state = e["sha256"][:12] if e["sha256"] else "unpinned"
+            print(f"{e['name']:<40} {e['platform']:<20} {state:<12} {e['license']}")
+        return 0
+
+    index_path = DEST_DIR / "index.json"
+    index = json.loads(index_path.read_text()) if index_path.is_file() else {}
+    counts = {"extracted": 0, "skipped": 0, "failed": 0}
+    pinned_any = False
+    for e in entries:
+        out_dir = DEST_DIR / e["platform"] / e["name"]
+        recorded = index.get(e["name"], {})
+        if (
+            not args.force
+            and e["sha256"]
+            and recorded.get("sha256") == e["sha256"]
+            and all((out_dir / f).is_file() for f in recorded.get("files", {}))
+        ):
+            counts["skipped"] += 1
+            continue
+        was_pinned = bool(e["sha256"])
+        try:
+            data = fetch(e, args.pin)
+            if data is None:
+                counts["skipped"] += 1
+                continue
+            files = unpack(e, data)
+        except (OSError, ValueError, tarfile.TarError, zipfile.BadZipFile) as exc:
+            print(f"error: {e['name']}: {exc}", file=sys.stderr)
+            counts["failed"] += 1
+            continue
+        pinned_any |= not was_pinned
+        if out_dir.exists():
+            shutil.rmtree(out_dir)
+        out_dir.mkdir(parents=True)
+        for fname, content in files.items():
+            (out_dir / fname).write_bytes(content)
+        index[e["name"]] = {
+            "platform": e["platform"],
+            "sha256": e["sha256"],
+            "license": e["license"],
+            "files": {f: {"sha256": sha256_bytes(c), "size": len(c)} for f, c in sorted(files.items())},
+        }
+        counts["extracted"] += 1
+        print(f"{e['name']}: {len(files)} file(s) -> {out_dir.relative_to(ROOT)}")
+
+    if pinned_any:
+        MANIFEST.write_text(json.dumps(manifest, indent=2) + "\n")
+        print(f"updated pins in {MANIFEST.relative_to(ROOT)}; review before committing")
+    if counts["extracted"]:
+        index_path.write_text(json.dumps(index, indent=2, sort_keys=True) + "\n")
+    print(", ".join(f"{k}={v}" for k, v in counts.items()))
+    return 1 if counts["failed"] else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
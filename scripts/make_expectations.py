@@ -0,0 +1,334 @@
+#!/usr/bin/env python3
+"""
+Seed per-sample expected triage results from the built samples.
+
+For every ELF, PE, or Mach-O file under the given roots (default:
+samples/binaries/platforms) this writes
+
+  samples/expected/<os>/<arch>/<path under export/>.json
+
+holding what is known to be true of the binary, independent of Glaurung:
+
+  * format, arch, bits, endianness from the file header;
+  * compiler vendor from the ELF .comment section or the PE Rich header;
+  * notable strings: string literals from the sample's source (found via
+    export/metadata/<name>.json) that occur verbatim in the binary;
+  * a function count range: at least half the function symbols (of the
+    binary, or of its unstripped sibling for a stripped variant, which
+    only has to yield one) and at most four times as many plus slack.
+
+tests/sample_expectations.rs loads these and asserts them against triage
+and function discovery, so a new detector gains a check across the whole
+corpus by adding its field here and in the test. Keys that cannot be
+determined are omitted, and the test skips them. The files are meant to
+be hand-tightened: existing ones are left alone unless --force is given,
+and --check reports samples that have none or whose binary changed.
+
+Usage:
+    python scripts/make_expectations.py [--force] [--check] [ROOT ...]
+"""
+from __future__ import annotations
+
+import argparse
+import hashlib
+import json
+import re
+import shutil
+import struct
+import subprocess
+import sys
+from pathlib import Path
+from typing import Dict, List, Optional
+
+ROOT = Path(__file__).resolve().parents[1]
+SAMPLES_DIR = ROOT / "samples"
+PLATFORMS_DIR = SAMPLES_DIR / "binaries" / "platforms"
+SOURCE_DIR = SAMPLES_DIR / "source"
+EXPECTED_DIR = SAMPLES_DIR / "expected"
+SCHEMA_VERSION = "1"
+
+LFS_POINTER = b"version https://git-lfs.github.com/spec/"
+EXTENSIONS = (".exe", ".dll", ".sys", ".so", ".dylib")
+MAX_STRINGS = 5
+MIN_STRING_LEN = 6
+
+# Names as serialized by glaurung::core::binary::Arch.
+ELF_MACHINES = {
+    3: "X86",
+    8: "MIPS",
+    20: "PPC",
+    21: "PPC64",
+    40: "ARM",
+    62: "X86_64",
+    183: "AArch64",
+}
+PE_MACHINES = {0x14C: "X86", 0x8664: "X86_64", 0x1C0: "ARM", 0x1C4: "ARM", 0xAA64: "AArch64"}
+MACHO_CPUS = {7: "X86", 0x01000007: "X86_64", 12: "ARM", 0x0100000C: "AArch64", 18: "PPC"}
+
+
+def sha256_bytes(data: bytes) -> str:
+    return hashlib.sha256(data).hexdigest()
+
+
+def is_stripped_name(path: Path) -> bool:
+    return "-stripped" in path.name or path.name.endswith(".stripped")
+
+
+def unstripped_name(name: str) -> str:
+    for ext in EXTENSIONS:
+        if name.endswith("-stripped" + ext):
+            return name[: -len("-stripped" + ext)] + ext
+    return name.replace("-stripped", "").removesuffix(".stripped")
+
+
+def expected_path(path: Path) -> Optional[Path]:
+    """samples/expected/<os>/<arch>/<path under export/>.json"""
+    try:
+        rel = path.relative_to(PLATFORMS_DIR)
+    except ValueError:
+        return None
+    parts = rel.parts
+    if len(parts) < 4 or parts[2] != "export" or parts[3] == "metadata":
+        return None
+    return EXPECTED_DIR.joinpath(parts[0], parts[1], *parts[3:]).with_name(parts[-1] + ".json")
+
+
+def elf_header(data: bytes) -> Optional[Dict]:
+    if len(data) < 64 or data[4] not in (1, 2) or data[5] not in (1, 2):
+        return None
+    end = "<" if data[5] == 1 else ">"
+    (machine,) = struct.unpack_from(end + "H", data, 18)
+    arch = ELF_MACHINES.get(machine)
+    if machine == 8 and data[4] == 2:
+        arch = "MIPS64"
+    if machine == 243:
+        arch = "RISCV" if data[4] == 1 else "RISCV64"
+    return {
+        "format": "ELF",
+        "arch": arch,
+        "bits": 32 if data[4] == 1 else 64,
+        "endianness": "Little" if data[5] == 1 else "Big",
+    }
+
+
+def elf_section(data: bytes, name: str) -> Optional[bytes]:
+    """Contents of the named ELF section, from the section header table."""
+    is64, end = data[4] == 2, "<" if data[5] == 1 else ">"
+    try:
+        if is64:
+            shoff, = struct.unpack_from(end + "Q", data, 0x28)
+            shentsize, shnum, shstrndx = struct.unpack_from(end + "HHH", data, 0x3A)
+        else:
+            shoff, = struct.unpack_from(end + "I", data, 0x20)
+            shentsize, shnum, shstrndx = struct.unpack_from(end + "HHH", data, 0x2E)
+        fmt = end + ("IIQQQQ" if is64 else "IIIIII")
+
+        def header(i: int):
+            name_off, _type, _flags, _addr, off, size = struct.unpack_from(fmt, data, shoff + i * shentsize)
+            return name_off, off, size
+
+        _, str_off, str_size = header(shstrndx)
+        names = data[str_off : str_off + str_size]
+        for i in range(shnum):
+            name_off, off, size = header(i)
+            if names[name_off:].split(b"\0", 1)[0] == name.encode():
+                return data[off : off + size]
+    except struct.error:
+        pass
+    return None
+
+
+def pe_header(data: bytes) -> Optional[Dict]:
+    if len(data) < 0x40:
+        return None
+    (lfanew,) = struct.unpack_from("<I", data, 0x3C)
+    if data[lfanew : lfanew + 4] != b"PE\0\0" or len(data) < lfanew + 26:
+        return None
+    (machine,) = struct.unpack_from("<H", data, lfanew + 4)
+    (magic,) = struct.unpack_from("<H", data, lfanew + 24)
+    return {
+        "format": "PE",
+        "arch": PE_MACHINES.get(machine),
+        "bits": 64 if magic == 0x20B else 32,
+        "endianness": "Little",
+    }
+
+
+def macho_header(data: bytes) -> Optional[Dict]:
+    magic = data[:4]
+    if magic in (b"\xcf\xfa\xed\xfe", b"\xce\xfa\xed\xfe"):
+        end = "<"
+    elif magic in (b"\xfe\xed\xfa\xcf", b"\xfe\xed\xfa\xce"):
+        end = ">"
+    else:
+        return None
+    (cpu,) = struct.unpack_from(end + "I", data, 4)
+    return {
+        "format": "MachO",
+        "arch": MACHO_CPUS.get(cpu),
+        "bits": 64 if cpu & 0x01000000 else 32,
+        "endianness": "Little" if end == "<" else "Big",
+    }
+
+
+def header(data: bytes) -> Optional[Dict]:
+    if data.startswith(b"\x7fELF"):
+        return elf_header(data)
+    if data.startswith(b"MZ"):
+        return pe_header(data)
+    return macho_header(data)
+
+
+def compiler_vendor(data: bytes, fmt: str) -> Optional[str]:
+    """CompilerVendor name, as serialized by glaurung."""
+    if fmt == "ELF":
+        comment = elf_section(data, ".comment") or b""
+        if b"clang version " in comment:
+            return "Llvm"
+        if b"rustc version " in comment:
+            return "Rustc"
+        if b"GCC: " in comment:
+            return "Gnu"
+    if fmt == "PE" and b"Rich" in data[: 0x400]:
+        return "Microsoft"
+    return None
+
+
+def metadata(path: Path) -> Dict:
+    """export/metadata/<name>.json for a binary or its stripped variant."""
+    rel = path.relative_to(PLATFORMS_DIR)
+    meta_dir = PLATFORMS_DIR.joinpath(*rel.parts[:3], "metadata")
+    for name in (path.name, unstripped_name(path.name)):
+        meta = meta_dir / (name + ".json")
+        if meta.is_file():
+            return json.loads(meta.read_text())
+    return {}
+
+
+LITERAL = re.compile(r'"((?:[^"\\\n]|\\.)*)"|\'((?:[^\'\\\n]|\\.)*)\'')
+ESCAPES = {"n": "\n", "t": "\t", "r": "\r", "0": "\0", "\\": "\\", '"': '"', "'": "'"}
+
+
+def source_literals(meta: Dict) -> List[str]:
+    # "/workspace/source/c/x.c" from the docker builds, "source/c/x.c" from
+    # the matrix scripts.
+    _, sep, rest = meta.get("source_file", "").partition("source/")
+    if not sep:
+        return []
+    path = SOURCE_DIR / rest
+    if not path.is_file():
+        return []
+    out: List[str] = []
+    for m in LITERAL.finditer(path.read_text(errors="replace")):
+        raw = m.group(1) if m.group(1) is not None else m.group(2)
+        text = re.sub(r"\\(.)", lambda e: ESCAPES.get(e.group(1), e.group(0)), raw)
+        # Escaped newlines and tabs split a literal into separately stored
+        # pieces in assembly and Fortran output; keep each piece.
+        out.extend(p.strip() for p in re.split(r"[\n\t\r\0]", text))
+    return out
+
+
+def notable_strings(data: bytes, meta: Dict) -> List[str]:
+    seen: List[str] = []
+    for s in source_literals(meta):
+        if len(s) < MIN_STRING_LEN or s in seen or "%" in s:
+            continue
+        if s.encode() in data or s.encode("utf-16-le") in data:
+            seen.append(s)
+        if len(seen) == MAX_STRINGS:
+            break
+    return seen
+
+
+def function_symbol_count(nm: Optional[str], path: Path) -> int:
+    if nm is None or not path.is_file():
+        return 0
+    try:
+        out = subprocess.run(
+            [nm, "--defined-only", str(path)], capture_output=True, text=True, check=True
+        ).stdout
+    except (subprocess.CalledProcessError, OSError):
+        return 0
+    starts = set()
+    for line in out.splitlines():
+        fields = line.split()
+        if len(fields) == 3 and fields[1] in ("T", "t", "W", "w"):
+            starts.add(fields[0])
+    return len(starts)
+
+
+def function_range(nm: Optional[str], path: Path) -> Optional[Dict]:
+    stripped = is_stripped_name(path)
+    source = path.with_name(unstripped_name(path.name)) if stripped else path
+    n = function_symbol_count(nm, source)
+    if n == 0:
+        return None
+    return {"min": 1 if stripped else max(1, n // 2), "max": 4 * n + 32}
+
+
+def process(path: Path, nm: Optional[str], force: bool, check: bool) -> str:
+    out = expected_path(path)
+    if out is None:
+        return "skip"
+    try:
+        data = path.read_bytes()
+    except OSError:
+        return "skip"
+    if data.startswith(LFS_POINTER):
+        return "skip"
+    head = header(data)
+    if head is None:
+        return "skip"
+    sha = sha256_bytes(data)
+    if check:
+        if not out.exists():
+            print(f"missing: {out.relative_to(ROOT)}")
+            return "stale"
+        if json.loads(out.read_text()).get("sha256") != sha:
+            print(f"stale: {out.relative_to(ROOT)}")
+            return "stale"
+        return "ok"
+    if out.exists() and not force:
+        return "ok"
+
+    expected = {
+        "schema_version": SCHEMA_VERSION,
+        "binary": str(path.relative_to(SAMPLES_DIR)),
+        "sha256": sha,
+    }
+    expected.update((k, v) for k, v in head.items() if v is not None)
+    meta = metadata(path)
+    optional = {
+        "compiler": compiler_vendor(data, head["format"]),
+        "strings": notable_strings(data, meta),
+        "functions": function_range(nm, path),
+    }
+    expected.update((k, v) for k, v in optional.items() if v)
+    out.parent.mkdir(parents=True, exist_ok=True)
+    out.write_text(json.dumps(expected, indent=2) + "\n")
+    return "wrote"
+
+
+def main(argv: Optional[List[str]] = None) -> int:
+    p = argparse.ArgumentParser(description=__doc__.split("\n\n")[0].strip())
+    p.add_argument("roots", nargs="*", type=Path, default=[PLATFORMS_DIR])
+    p.add_argument("--force", action="store_true", help="regenerate existing expectations")
+    p.add_argument("--check", action="store_true", help="only report missing or stale expectations")
+    args = p.parse_args(argv)
+
+    nm = shutil.which("llvm-nm") or shutil.which("nm")
+    if nm is None and not args.check:
+        print("warning: llvm-nm/nm not found; not seeding function counts", file=sys.stderr)
+
+    counts: Dict[str, int] = {}
+    for root in args.roots:
+        paths = [root] if root.is_file() else sorted(q for q in root.rglob("*") if q.is_file())
+        for path in paths:
+            status = process(path.resolve(), nm, args.force, args.check)
+            counts[status] = counts.get(status, 0) + 1
+    print(", ".join(f"{k}={v}" for k, v in sorted(counts.items())))
+    return 1 if counts.get("stale") else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
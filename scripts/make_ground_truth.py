@@ -0,0 +1,268 @@
+#!/usr/bin/env python3
+"""
+Produce stripped variants and function ground truth for built samples.
+
+For every unstripped executable or shared library under the given roots
+(default: samples/binaries/platforms), this writes:
+
+  * a stripped copy next to it, `<name>-stripped[.ext]`, unless the build
+    already produced one (e.g. Go `-ldflags="-s -w"` builds);
+  * `samples/ground-truth/<os>/<arch>/<path under export/>.json` with the
+    true function boundaries and names, read from the unstripped symbol
+    table with llvm-nm and limited to executable sections.
+
+Ground truth lives outside samples/binaries so it stays in plain git while
+the binaries go through LFS. Tests load it to score function discovery
+(precision and recall of entry points) on the stripped variant.
+
+Binaries with no function symbols (already stripped, MSVC images whose
+symbols live in a PDB) are skipped. Existing files are left alone unless
+--force is given; --check reports ground truth that is missing or stale
+(sha256 mismatch) and exits 1 if any is found.
+
+Usage:
+    python scripts/make_ground_truth.py [--force] [--check] [ROOT ...]
+"""
+from __future__ import annotations
+
+import argparse
+import hashlib
+import json
+import shutil
+import subprocess
+import sys
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+
+ROOT = Path(__file__).resolve().parents[1]
+SAMPLES_DIR = ROOT / "samples"
+PLATFORMS_DIR = SAMPLES_DIR / "binaries" / "platforms"
+TRUTH_DIR = SAMPLES_DIR / "ground-truth"
+SCHEMA_VERSION = "1"
+
+LFS_POINTER = b"version https://git-lfs.github.com/spec/"
+MAGICS = {
+    b"\x7fELF": "elf",
+    b"MZ": "pe",
+    b"\xcf\xfa\xed\xfe": "macho",
+    b"\xce\xfa\xed\xfe": "macho",
+    b"\xfe\xed\xfa\xcf": "macho",
+    b"\xfe\xed\xfa\xce": "macho",
+}
+# Extensions the stripped suffix goes in front of.
+EXTENSIONS = (".exe", ".dll", ".sys", ".so", ".dylib")
+# Text symbols that mark section boundaries rather than functions.
+MARKERS = {"go:buildid", "runtime.text", "runtime.etext", "etext", "__etext", "_etext"}
+
+
+def sha256_file(path: Path) -> str:
+    h = hashlib.sha256()
+    with path.open("rb") as f:
+        for chunk in iter(lambda: f.read(1024 * 1024), b""):
+            h.update(chunk)
+    return h.hexdigest()
+
+
+def binary_format(path: Path) -> Optional[str]:
+    try:
+        with path.open("rb") as f:
+            head = f.read(len(LFS_POINTER))
+    except OSError:
+        return None
+    if head.startswith(LFS_POINTER):
+        return None
+    for magic, fmt in MAGICS.items():
+        if head.startswith(magic):
+            return fmt
+    return None
+
+
+def is_stripped_name(path: Path) -> bool:
+    return "-stripped" in path.name or path.name.endswith(".stripped")
+
+
+def stripped_path(path: Path) -> Path:
+    name = path.name
+    for ext in EXTENSIONS:
+        if name.endswith(ext):
+            return path.with_name(name[: -len(ext)] + "-stripped" + ext)
+    return path.with_name(name + "-stripped")
+
+
+def truth_path(path: Path) -> Optional[Path]:
+    """samples/ground-truth/<os>/<arch>/<path under export/>.json"""
+    try:
+        rel = path.relative_to(PLATFORMS_DIR)
+    except ValueError:
+        return None
+    parts = rel.parts
+    if len(parts) < 4 or parts[2] != "export":
+        return None
+    return TRUTH_DIR.joinpath(parts[0], parts[1], *parts[3:]).with_name(parts[-1] + ".json")
+
+
+def find_tool(*names: str) -> Optional[str]:
+    for n in names:
+        exe = shutil.which(n)
+        if exe:
+            return exe
+    return None
+
+
+def code_sections(objdump: str, path: Path) -> List[Tuple[int, int]]:
+    """(start, end) VA ranges of executable sections."""
+    try:
+        out = subprocess.run(
+            [objdump, "--section-headers", str(path)],
+            capture_output=True,
+            text=True,
+            check=True,
+        ).stdout
+    except (subprocess.CalledProcessError, OSError):
+        return []
+    ranges = []
+    for line in out.splitlines():
+        fields = line.split()
+        # Idx Name Size VMA Type
+        if len(fields) >= 5 and fields[0].isdigit() and fields[-1] == "TEXT":
+            size, vma = int(fields[-3], 16), int(fields[-2], 16)
+            if size:
+                ranges.append((vma, vma + size))
+    return sorted(ranges)
+
+
+def containing(ranges: List[Tuple[int, int]], va: int) -> Optional[Tuple[int, int]]:
+    for r in ranges:
+        if r[0] <= va < r[1]:
+            return r
+    return None
+
+
+def function_symbols(nm: str, objdump: str, path: Path) -> List[Dict]:
+    """Symbols in executable sections as [{name, start, end, aliases?}],
+    sorted by start."""
+    sections = code_sections(objdump, path)
+    try:
+        out = subprocess.run(
+            [nm, "--defined-only", "--print-size", "--numeric-sort", str(path)],
+            capture_output=True,
+            text=True,
+            check=True,
+        ).stdout
+    except (subprocess.CalledProcessError, OSError):
+        return []
+    by_start: Dict[int, Dict] = {}
+    for line in out.splitlines():
+        fields = line.split(None, 3)
+        if len(fields) != 4 or fields[2] not in ("T", "t", "W", "w"):
+            continue
+        start, size, name = int(fields[0], 16), int(fields[1], 16), fields[3]
+        bare = name[1:] if name.startswith("_") else name
+        if not name or name[0] in "$." or name in MARKERS or bare in MARKERS:
+            continue
+        if containing(sections, start) is None:
+            continue
+        entry = by_start.get(start)
+        if entry is None:
+            by_start[start] = {"name": name, "start": start, "size": size}
+        else:
+            entry.setdefault("aliases", []).append(name)
+            entry["size"] = max(entry["size"], size)
+    funcs = [by_start[s] for s in sorted(by_start)]
+    # Mach-O and COFF (and hand-written assembly) carry no sizes; end at
+    # the next function or the end of the section, whichever comes first.
+    for i, f in enumerate(funcs):
+        size = f.pop("size")
+        if size:
+            f["end"] = f["start"] + size
+            continue
+        sec_end = containing(sections, f["start"])[1]
+        nxt = funcs[i + 1]["start"] if i + 1 < len(funcs) else sec_end
+        f["end"] = min(nxt, sec_end)
+    return funcs
+
+
+def process(
+    path: Path, nm: str, objdump: str, strip: Optional[str], force: bool, check: bool
+) -> str:
+    fmt = binary_format(path)
+    if fmt is None or is_stripped_name(path):
+        return "skip"
+    out = truth_path(path)
+    if out is None:
+        return "skip"
+    sha = sha256_file(path)
+    if check:
+        if not out.exists():
+            print(f"missing: {out.relative_to(ROOT)}")
+            return "stale"
+        if json.loads(out.read_text()).get("sha256") != sha:
+            print(f"stale: {out.relative_to(ROOT)}")
+            return "stale"
+        return "ok"
+    if out.exists() and not force:
+        return "ok"
+    funcs = function_symbols(nm, objdump, path)
+    if not funcs:
+        return "skip"
+
+    variant = stripped_path(path)
+    if strip and (force or not variant.exists()):
+        try:
+            subprocess.run([strip, "--strip-all", "-o", str(variant), str(path)], check=True)
+        except (subprocess.CalledProcessError, OSError) as e:
+            print(f"warning: {strip} failed on {path}: {e}", file=sys.stderr)
+    stripped = None
+    if variant.exists():
+        stripped = {
+            "path": str(variant.relative_to(SAMPLES_DIR)),
+            "sha256": sha256_file(variant),
+        }
+
+    out.parent.mkdir(parents=True, exist_ok=True)
+    out.write_text(
+        json.dumps(
+            {
+                "schema_version": SCHEMA_VERSION,
+                "binary": str(path.relative_to(SAMPLES_DIR)),
+                "sha256": sha,
+                "format": fmt,
+                "source": "symtab",
+                "stripped": stripped,
+                "functions": funcs,
+            },
+            indent=2,
+        )
+        + "\n"
+    )
+    return "wrote"
+
+
+def main(argv: Optional[List[str]] = None) -> int:
+    p = argparse.ArgumentParser(description=__doc__.split("\n\n")[0].strip())
+    p.add_argument("roots", nargs="*", type=Path, default=[PLATFORMS_DIR])
+    p.add_argument("--force", action="store_true", help="regenerate existing output")
+    p.add_argument("--check", action="store_true", help="only report missing or stale truth")
+    args = p.parse_args(argv)
+
+    nm = find_tool("llvm-nm", "nm")
+    objdump = find_tool("llvm-objdump", "objdump")
+    if nm is None or objdump is None:
+        print("error: llvm-nm and llvm-objdump (or binutils) not found on PATH", file=sys.stderr)
+        return 2
+    strip = find_tool("llvm-strip", "strip")
+    if strip is None and not args.check:
+        print("warning: no strip tool found; not producing stripped variants", file=sys.stderr)
+
+    counts: Dict[str, int] = {}
+    for root in args.roots:
+        paths = [root] if root.is_file() else sorted(q for q in root.rglob("*") if q.is_file())
+        for path in paths:
+            status = process(path.resolve(), nm, objdump, strip, args.force, args.check)
+            counts[status] = counts.get(status, 0) + 1
+    print(", ".join(f"{k}={v}" for k, v in sorted(counts.items())))
+    return 1 if counts.get("stale") else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
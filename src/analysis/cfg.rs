@@ -5,6 +5,8 @@
 //! exports/PLT/etc.), disassembles within executable ranges only, splits basic
 //! blocks on control flow, and emits `Function`s plus a `CallGraph`.
 
+use crate::analysis::eh::{recover_eh_info, EhFunction, EhScheme};
+use crate::analysis::goitab::{recover_go_itabs, GoItabs};
 use crate::analysis::jump_table::discover_jump_tables;
 use crate::analysis::vtable::discover_vtables;
 use crate::core::address::{Address, AddressKind};
@@ -15,12 +17,14 @@ use crate::core::call_graph::{CallGraph, CallGraphEdge, CallType};
 use crate::core::control_flow_graph::ControlFlowEdgeKind;
 use crate::core::disassembler::Disassembler;
 use crate::core::function::{Function, FunctionFlags, FunctionKind};
-use crate::core::instruction::Instruction;
+use crate::core::instruction::{Access, Instruction, Operand};
 use crate::debug::dwarf::{extract_dwarf_functions, DwarfFunction};
 use crate::disasm::registry;
 use crate::flirt::{
     apply_flirt_overrides, discover_flirt_seeds, load_default_library, FlirtLibrary,
 };
+use crate::formats::object_image::segment_perms;
+use crate::triage::arch_id;
 use crate::triage::heuristics;
 
 use object::{Object, ObjectSegment, SectionKind};
@@ -79,6 +83,17 @@ pub struct FunctionDiscoveryStats {
     pub pdata_chained_unwind_parse_failed: usize,
     pub pdata_chained_parent_starts: usize,
     pub pdata_nonexec_rejected: usize,
+    pub eh_functions: usize,
+    pub eh_try_regions: usize,
+    pub eh_handler_seeds_inserted: usize,
+    pub exception_edges: usize,
+    pub go_itabs: usize,
+    pub go_itab_seeds_inserted: usize,
+    pub go_interface_call_sites: usize,
+    pub go_interface_call_edges: usize,
+    pub register_call_edges: usize,
+    pub flirt_signatures: usize,
+    pub flirt_functions_named: usize,
     pub prologue_scan_candidates: usize,
     pub prologue_scan_seeds_inserted: usize,
     pub thunk_scan_candidates: usize,
@@ -165,6 +180,8 @@ pub struct SeedProvenance {
     pub source_va: Option<u64>,
     pub kind: String,
     pub detail: String,
+    /// Confidence that `target_va` starts a function (`core::confidence`)
+    pub confidence: f32,
 }
 
 #[derive(Debug, Clone)]
@@ -201,6 +218,8 @@ enum DiscoverySeedKind {
     JumpTable,
     Export,
     Pdata,
+    ExceptionHandler,
+    GoItab,
     Prologue,
     Thunk,
     TinyStub,
@@ -225,6 +244,22 @@ impl DiscoverySeedKind {
         )
     }
 
+    /// Prior confidence that a seed of this kind starts a real function.
+    /// Metadata-backed seeds are structural; scan-derived ones are not.
+    fn confidence(self) -> f32 {
+        match self {
+            Self::EntryPoint | Self::Symbol | Self::Export => 1.0,
+            Self::Pdata => 0.98,
+            Self::ExceptionHandler | Self::GoItab => 0.95,
+            Self::Flirt | Self::DirectCall => 0.9,
+            Self::Vtable => 0.85,
+            Self::JumpTable | Self::Thunk => 0.7,
+            Self::DirectCallBodySplit | Self::IndirectCall | Self::TailCall => 0.6,
+            Self::Prologue | Self::TinyStub => 0.5,
+            Self::DataRef => 0.4,
+        }
+    }
+
     fn label(self) -> &'static str {
         match self {
             Self::EntryPoint => "entrypoint",
@@ -234,6 +269,8 @@ impl DiscoverySeedKind {
             Self::JumpTable => "jump_table",
             Self::Export => "export",
             Self::Pdata => "trusted_pdata",
+            Self::ExceptionHandler => "exception_handler",
+            Self::GoItab => "go_itab",
             Self::Prologue => "prologue",
             Self::Thunk => "thunk",
             Self::TinyStub => "tiny_stub",
@@ -251,7 +288,9 @@ fn parse_exec_regions(data: &[u8]) -> (Vec<ExecRegion>, BArch, Endianness, Optio
     let mut arch = BArch::Unknown;
     let mut endian = Endianness::Little;
     let mut entry: Option<Address> = None;
+    let mut parsed = false;
     if let Ok(obj) = object::read::File::parse(data) {
+        parsed = true;
         arch = match obj.architecture() {
             object::Architecture::I386 => BArch::X86,
             object::Architecture::X86_64 => BArch::X86_64,
@@ -279,16 +318,16 @@ fn parse_exec_regions(data: &[u8]) -> (Vec<ExecRegion>, BArch, Endianness, Optio
             }
         }
 
-        // Prefer segments with execute permissions
+        // Only segments mapped executable are candidate code; data
+        // segments are never disassembled, even without section headers.
         for seg in obj.segments() {
             let addr = seg.address();
             let size = seg.size();
-            if size == 0 {
+            if size == 0 || !segment_perms(seg.flags()).has_execute() {
                 continue;
             }
             let file = seg.file_range().0;
-            // Heuristic: treat segments mapped into memory as candidate code; object doesn't expose perms uniformly across formats here
-            // We will filter by sections if possible below.
+            // Narrowed to code sections below when the file has them.
             regions.push(ExecRegion {
                 start: addr,
                 end: addr.saturating_add(size),
@@ -333,21 +372,34 @@ fn parse_exec_regions(data: &[u8]) -> (Vec<ExecRegion>, BArch, Endianness, Optio
         }
     }
 
-    if regions.is_empty() {
-        // As a last resort, decode from start of file as VA=0 range
-        let (e, _conf) = heuristics::endianness::guess(data);
-        endian = e;
-        let (arch_guess, _ac) = heuristics::architecture::infer(data)
-            .first()
-            .cloned()
-            .unwrap_or((BArch::Unknown, 0.0));
-        arch = arch_guess;
+    if regions.is_empty() && !parsed {
+        // Raw blob: as a last resort, decode from start of file as VA=0 range
+        // with the opcode classifier's verdict, else the data-layout and
+        // byte-frequency guesses.
+        let layout = heuristics::layout::detect(data);
+        if let Some(g) = arch_id::identify(data, 0.5) {
+            arch = g.arch;
+            endian = g.endianness;
+        } else {
+            endian = layout
+                .map(|l| l.endianness)
+                .unwrap_or_else(|| heuristics::endianness::guess(data).0);
+            let (arch_guess, _ac) = heuristics::architecture::infer(data)
+                .first()
+                .cloned()
+                .unwrap_or((BArch::Unknown, 0.0));
+            arch = arch_guess;
+        }
         regions.push(ExecRegion {
             start: 0,
             end: data.len() as u64,
             _file_off_start: 0,
         });
-        let bits = 64;
+        let bits = match (arch, layout) {
+            (BArch::Unknown, Some(l)) => l.bits,
+            (BArch::Unknown, None) => 64,
+            (a, _) => a.bits(),
+        };
         entry = Address::new(AddressKind::VA, 0, bits, None, None).ok();
     }
     (regions, arch, endian, entry)
@@ -593,6 +645,229 @@ fn pe_tail_target_looks_like_function_start(data: &[u8], target_va: u64) -> bool
     classify_pe_thunk_head(target_va, &data[file_off..head_end]).is_some()
 }
 
+/// Go interface method calls in `func` (#235), as `(callsite_va, targets)`.
+///
+/// Go calls an interface method by loading its slot from the itab and
+/// calling the register (`mov rcx, [rax+0x18]; call rcx`). Func values are
+/// closures and take one more load, so a single itab-relative load feeding
+/// a call is the interface-call shape. A `lea`/`mov` of a known itab into
+/// the base register earlier in the block pins the call to that itab;
+/// otherwise every itab's method in the slot is a candidate. x86 only: the
+/// capstone operand parser does not split ARM memory operands.
+fn go_interface_calls(
+    data: &[u8],
+    arch: BArch,
+    end: Endianness,
+    func: &Function,
+    itabs: &GoItabs,
+) -> Vec<(u64, Vec<u64>)> {
+    use std::collections::HashMap;
+    let mut out = Vec::new();
+    if !matches!(arch, BArch::X86 | BArch::X86_64) {
+        return out;
+    }
+    let darch: crate::core::disassembler::Architecture = arch.into();
+    let Some(backend) = registry::for_arch(darch, end) else {
+        return out;
+    };
+    let bits = darch.address_bits();
+    // (base register, slot) of an itab-relative load. The stack and frame
+    // pointers and the goroutine register (r14) never hold an itab.
+    let itab_slot = |op: &Operand| -> Option<(String, usize)> {
+        let base = op.base.as_deref()?;
+        if op.index.is_some()
+            || matches!(base, "rip" | "eip" | "rsp" | "esp" | "rbp" | "ebp" | "r14")
+        {
+            return None;
+        }
+        Some((base.to_string(), itabs.slot(op.displacement.unwrap_or(0))?))
+    };
+    for bb in &func.basic_blocks {
+        // register -> (slot loaded into it, its itab when known)
+        let mut slots: HashMap<String, (usize, Option<u64>)> = HashMap::new();
+        // register -> known itab address
+        let mut known: HashMap<String, u64> = HashMap::new();
+        let mut va = bb.start_address.value;
+        while va < bb.end_address.value {
+            let Some(slice) =
+                crate::analysis::entry::va_to_file_offset(data, va).and_then(|fo| data.get(fo..))
+            else {
+                break;
+            };
+            let Ok(addr) = Address::new(AddressKind::VA, va, bits, None, None) else {
+                break;
+            };
+            let Ok(ins) = backend.disassemble_instruction(&addr, slice) else {
+                break;
+            };
+            let site = va;
+            va = va.saturating_add(ins.length.max(1) as u64);
+            let dst = ins.operands.first();
+            if classify_ctrl_flow(&ins.mnemonic, arch).1 {
+                let call = dst.and_then(|op| match &op.register {
+                    Some(r) => slots.get(r).copied(),
+                    None => itab_slot(op).map(|(base, slot)| (slot, known.get(&base).copied())),
+                });
+                if let Some((slot, itab)) = call {
+                    let targets: Vec<u64> = match itab.and_then(|t| itabs.at(t)) {
+                        Some(t) => t.methods.get(slot).map(|m| m.target).into_iter().collect(),
+                        None => itabs.slot_targets(slot).into_iter().collect(),
+                    };
+                    if !targets.is_empty() {
+                        out.push((site, targets));
+                    }
+                }
+                // Go's ABI has no callee-saved registers.
+                slots.clear();
+                known.clear();
+                continue;
+            }
+            let Some(reg) = dst
+                .filter(|op| op.access != Access::Read)
+                .and_then(|op| op.register.clone())
+            else {
+                continue;
+            };
+            let src = ins.operands.get(1);
+            let loaded = src
+                .and_then(|op| itab_slot(op))
+                .map(|(base, slot)| (slot, known.get(&base).copied()));
+            slots.remove(&reg);
+            known.remove(&reg);
+            match ins.mnemonic.to_ascii_lowercase().as_str() {
+                "mov" => {
+                    if let Some(load) = loaded {
+                        slots.insert(reg, load);
+                    } else if let Some(t) = src
+                        .and_then(|op| op.immediate)
+                        .map(|t| t as u64)
+                        .filter(|&t| itabs.at(t).is_some())
+                    {
+                        known.insert(reg, t);
+                    }
+                }
+                "lea" => {
+                    if let Some(t) = memory_operand_va(&ins).filter(|&t| itabs.at(t).is_some()) {
+                        known.insert(reg, t);
+                    }
+                }
+                _ => {}
+            }
+        }
+    }
+    out
+}
+
+/// Register family of an x86 register name, so a write to `eax` or `al`
+/// clobbers what was tracked for `rax`.
+fn x86_register_family(reg: &str) -> String {
+    let reg = reg.to_ascii_lowercase();
+    if let Some(rest) = reg.strip_prefix('r') {
+        let digits: String = rest.chars().take_while(char::is_ascii_digit).collect();
+        if !digits.is_empty() {
+            return format!("r{digits}");
+        }
+    }
+    let base = match reg.len() {
+        3 if reg.starts_with('r') || reg.starts_with('e') => &reg[1..],
+        _ => reg.as_str(),
+    };
+    match base {
+        "ax" | "al" | "ah" | "bx" | "bl" | "bh" | "cx" | "cl" | "ch" | "dx" | "dl" | "dh" => {
+            base[..1].to_string()
+        }
+        "sil" | "dil" | "spl" | "bpl" => base[..2].to_string(),
+        _ => base.to_string(),
+    }
+}
+
+/// Register-indirect calls in `func` whose register holds a known function
+/// entry, as `(callsite_va, target_va)`.
+///
+/// Best-effort constant tracking within each block: `lea reg, [rip+f]`,
+/// `mov reg, f` and a load of a pointer to `f` from a file-backed slot pin
+/// the register; any other write clears it, as does a call (the tracked
+/// registers are caller-saved in practice). Only discovered entries count,
+/// so pointers into data or relocated GOT slots never become edges. x86
+/// only, like `go_interface_calls`.
+fn register_call_targets(
+    data: &[u8],
+    arch: BArch,
+    end: Endianness,
+    func: &Function,
+    entries: &std::collections::HashSet<u64>,
+) -> Vec<(u64, u64)> {
+    use std::collections::HashMap;
+    let mut out = Vec::new();
+    if !matches!(arch, BArch::X86 | BArch::X86_64) {
+        return out;
+    }
+    let darch: crate::core::disassembler::Architecture = arch.into();
+    let Some(backend) = registry::for_arch(darch, end) else {
+        return out;
+    };
+    let bits = darch.address_bits();
+    for bb in &func.basic_blocks {
+        // register family -> function entry it holds
+        let mut known: HashMap<String, u64> = HashMap::new();
+        let mut va = bb.start_address.value;
+        while va < bb.end_address.value {
+            let Some(slice) =
+                crate::analysis::entry::va_to_file_offset(data, va).and_then(|fo| data.get(fo..))
+            else {
+                break;
+            };
+            let Ok(addr) = Address::new(AddressKind::VA, va, bits, None, None) else {
+                break;
+            };
+            let Ok(ins) = backend.disassemble_instruction(&addr, slice) else {
+                break;
+            };
+            let site = va;
+            va = va.saturating_add(ins.length.max(1) as u64);
+            let dst = ins.operands.first();
+            if classify_ctrl_flow(&ins.mnemonic, arch).1 {
+                let target = dst
+                    .and_then(|op| op.register.as_deref())
+                    .and_then(|r| known.get(&x86_register_family(r)));
+                if let Some(&target) = target {
+                    out.push((site, target));
+                }
+                known.clear();
+                continue;
+            }
+            let Some(reg) = dst
+                .filter(|op| op.access != Access::Read)
+                .and_then(|op| op.register.as_deref())
+                .map(x86_register_family)
+            else {
+                continue;
+            };
+            known.remove(&reg);
+            let src = ins.operands.get(1);
+            let value = match ins.mnemonic.to_ascii_lowercase().as_str() {
+                "lea" => memory_operand_va(&ins),
+                "mov" => match src {
+                    Some(op) if op.immediate.is_some() => op.immediate.map(|v| v as u64),
+                    Some(op) if op.displacement.is_some() => {
+                        indirect_memory_target(data, &ins, bits)
+                    }
+                    Some(op) => op
+                        .register
+                        .as_deref()
+                        .and_then(|r| known.get(&x86_register_family(r)).copied()),
+                    _ => None,
+                },
+                _ => None,
+            };
+            if let Some(target) = value.filter(|t| entries.contains(t)) {
+                known.insert(reg, target);
+            }
+        }
+    }
+    out
+}
+
 /// Discover a single function starting at `entry` within executable regions.
 fn discover_function(
     data: &[u8],
@@ -601,6 +876,7 @@ fn discover_function(
     entry: Address,
     regions: &[ExecRegion],
     budgets: &Budgets,
+    eh: Option<&EhFunction>,
 ) -> Option<(Function, Vec<FunctionXref>, SingleFunctionDiscoveryStats)> {
     let darch: crate::core::disassembler::Architecture = arch.into();
     let mut backend = registry::for_arch(darch, end)?;
@@ -634,7 +910,10 @@ fn discover_function(
 
     let mut decoded_instructions = 0usize;
 
-    while let Some(start_va) = queue.pop_front() {
+    while let Some(start_va) = queue
+        .pop_front()
+        .or_else(|| next_landing_pad(eh, &blocks, &mut seen, regions))
+    {
         if blocks.len() >= budgets.max_blocks {
             stats.hit_block_limit = true;
             break;
@@ -800,9 +1079,31 @@ fn discover_function(
         }
     }
 
+    // Exception edges run from every (final) block overlapping a try region
+    // to the region's landing pad.
+    let mut exception_edges: Vec<(u64, u64)> = Vec::new();
+    for region in eh.map(|f| f.regions.as_slice()).unwrap_or_default() {
+        let Some(pad) = region.landing_pad.filter(|p| blocks.contains_key(p)) else {
+            continue;
+        };
+        for (&s, &(e, _)) in &blocks {
+            if s < region.end && region.start < e {
+                exception_edges.push((s, pad));
+            }
+        }
+    }
+    exception_edges.sort_unstable();
+    exception_edges.dedup();
+
     // Build Function object
     let fname = format!("sub_{:x}", entry.value);
     let mut func = Function::new(fname, entry.clone(), FunctionKind::Normal).ok()?;
+    if let Some(eh) = eh {
+        func.add_flag(match eh.scheme {
+            EhScheme::Seh => FunctionFlags::HAS_SEH,
+            EhScheme::Itanium | EhScheme::MsvcCxx => FunctionFlags::HAS_EH,
+        });
+    }
 
     // Build BasicBlocks with successor/predecessor IDs
     let mut bb_ids: std::collections::BTreeMap<u64, String> = std::collections::BTreeMap::new();
@@ -842,6 +1143,11 @@ fn discover_function(
             }
         }
     }
+    for (src_va, dst_va) in exception_edges {
+        let saddr = Address::new(AddressKind::VA, src_va, bits, None, None).ok()?;
+        let daddr = Address::new(AddressKind::VA, dst_va, bits, None, None).ok()?;
+        func.add_exception_edge(saddr, daddr);
+    }
     // Patch blocks with relationships (best-effort): replace blocks with enriched copies
     for bb in &mut func.basic_blocks {
         let id = bb.id.clone();
@@ -879,6 +1185,34 @@ fn discover_function(
     Some((func, call_edges, stats))
 }
 
+/// The next landing pad of `eh` whose try region overlaps a discovered block
+/// and that has not been queued yet. Pads are reached only by unwinding, so
+/// the BFS in `discover_function` takes them up once ordinary control flow
+/// runs dry.
+fn next_landing_pad(
+    eh: Option<&EhFunction>,
+    blocks: &std::collections::HashMap<u64, (u64, u32)>,
+    seen: &mut std::collections::BTreeSet<u64>,
+    regions: &[ExecRegion],
+) -> Option<u64> {
+    for region in &eh?.regions {
+        let Some(pad) = region.landing_pad else {
+            continue;
+        };
+        if seen.contains(&pad) || in_exec_regions(regions, pad).is_none() {
+            continue;
+        }
+        if blocks
+            .iter()
+            .any(|(&s, &(e, _))| s < region.end && region.start < e)
+        {
+            seen.insert(pad);
+            return Some(pad);
+        }
+    }
+    None
+}
+
 /// Heuristic: does `data[file_off..]` look like the start of a real
 /// function?
 ///
@@ -1092,6 +1426,7 @@ fn record_seed_provenance(
         source_va,
         kind: label,
         detail: detail.into(),
+        confidence: kind.confidence(),
     });
 }
 
@@ -2616,7 +2951,7 @@ pub fn analyze_functions_bytes_with_stats(
     // regions for FLIRT prologue matches and seed those VAs too. A name
     // mapping is also kept so we can rename `sub_*` → real_name once
     // discovery completes (see post-processing below).
-    let flirt_lib_for_seeds: Option<FlirtLibrary> = load_default_library();
+    let flirt_lib_for_seeds: Option<FlirtLibrary> = load_default_library(arch);
     let flirt_seeds: Vec<(u64, String)> = if let Some(ref lib) = flirt_lib_for_seeds {
         discover_flirt_seeds(data, &functions, lib)
     } else {
@@ -2804,6 +3139,52 @@ pub fn analyze_functions_bytes_with_stats(
         }
     }
 
+    // Exception handlers (#234). Catch and `__finally` funclets, SEH
+    // filters, and in-image personality routines are entered only by the
+    // unwinder, so no call or branch reaches them.
+    let eh_info = recover_eh_info(data);
+    if let Some(eh) = &eh_info {
+        stats.eh_functions = eh.functions.len();
+        stats.eh_try_regions = eh.region_count();
+        for va in eh.handler_functions() {
+            if known.contains(&va) || in_exec_regions(&regions, va).is_none() {
+                continue;
+            }
+            if let Ok(addr) = Address::new(AddressKind::VA, va, bits, None, None) {
+                seeds.push((addr, DiscoverySeedKind::ExceptionHandler));
+                known.insert(va);
+                seed_kind_by_va.insert(va, DiscoverySeedKind::ExceptionHandler);
+                record_seed_provenance(
+                    &mut stats,
+                    va,
+                    None,
+                    DiscoverySeedKind::ExceptionHandler,
+                    "eh_tables",
+                );
+                stats.eh_handler_seeds_inserted = stats.eh_handler_seeds_inserted.saturating_add(1);
+            }
+        }
+    }
+
+    // Go itab methods (#235). Methods reached only through interfaces have
+    // no direct caller; the itab is the one reference to them.
+    let go_itabs = recover_go_itabs(data);
+    if let Some(itabs) = &go_itabs {
+        stats.go_itabs = itabs.itabs.len();
+        for va in itabs.method_targets() {
+            if known.contains(&va) || in_exec_regions(&regions, va).is_none() {
+                continue;
+            }
+            if let Ok(addr) = Address::new(AddressKind::VA, va, bits, None, None) {
+                seeds.push((addr, DiscoverySeedKind::GoItab));
+                known.insert(va);
+                seed_kind_by_va.insert(va, DiscoverySeedKind::GoItab);
+                record_seed_provenance(&mut stats, va, None, DiscoverySeedKind::GoItab, "go_itab");
+                stats.go_itab_seeds_inserted = stats.go_itab_seeds_inserted.saturating_add(1);
+            }
+        }
+    }
+
     let mut prologue_starts = scan_pe_prologue_function_starts(data, &regions, arch);
     // AArch64 ELF PAC prologues recover functions on stripped hardened binaries
     // (Pixel device .so files) where the PE-specific scan does not apply.
@@ -3050,9 +3431,15 @@ pub fn analyze_functions_bytes_with_stats(
             );
             continue;
         }
-        if let Some((f, calls, func_stats)) =
-            discover_function(data, arch, end, seed.clone(), &regions, budgets)
-        {
+        if let Some((f, calls, func_stats)) = discover_function(
+            data,
+            arch,
+            end,
+            seed.clone(),
+            &regions,
+            budgets,
+            eh_info.as_ref().and_then(|eh| eh.function_at(seed.value)),
+        ) {
             stats.function_seed_kinds.push((
                 f.entry_point.value,
                 seed_kind_by_va
@@ -3065,6 +3452,9 @@ pub fn analyze_functions_bytes_with_stats(
             stats.hit_block_limit |= func_stats.hit_block_limit;
             stats.hit_instruction_limit |= func_stats.hit_instruction_limit;
             stats.hit_timeout |= func_stats.hit_timeout;
+            stats.exception_edges = stats
+                .exception_edges
+                .saturating_add(f.exception_edges.len());
             for xref in &calls {
                 calls_all.push((f.entry_point.value, *xref));
                 match xref.call_type {
@@ -3190,11 +3580,13 @@ pub fn analyze_functions_bytes_with_stats(
             }
             if let Some(name) = flirt_name_by_va.get(&f.entry_point.value) {
                 f.name = name.clone();
+                stats.flirt_functions_named += 1;
             }
         }
     }
     if let Some(ref lib) = flirt_lib_for_seeds {
-        apply_flirt_overrides(data, &mut functions, lib);
+        stats.flirt_signatures = lib.signature_count();
+        stats.flirt_functions_named += apply_flirt_overrides(data, &mut functions, lib);
     }
 
     // Fold compiler-emitted split chunks (e.g. GCC -O2 `<fn>.cold`) into
@@ -3241,6 +3633,61 @@ pub fn analyze_functions_bytes_with_stats(
         cg.add_edge(edge);
     }
 
+    // Go interface method calls (#235): one virtual edge per implementation
+    // the itab slot admits, weighted by how many it admits.
+    if let Some(itabs) = &go_itabs {
+        for f in &functions {
+            for (site, targets) in go_interface_calls(data, arch, end, f, itabs) {
+                stats.go_interface_call_sites = stats.go_interface_call_sites.saturating_add(1);
+                let confidence = 1.0 / targets.len() as f32;
+                for target in targets {
+                    let callee = name_by_va
+                        .get(&target)
+                        .cloned()
+                        .unwrap_or_else(|| format!("sub_{:x}", target));
+                    cg.add_node(callee.clone());
+                    let mut edge = CallGraphEdge::with_confidence(
+                        f.name.clone(),
+                        callee,
+                        CallType::Virtual,
+                        confidence,
+                    );
+                    if let Ok(site) = Address::new(AddressKind::VA, site, bits, None, None) {
+                        edge.call_sites.push(site);
+                    }
+                    cg.add_edge(edge);
+                    stats.go_interface_call_edges = stats.go_interface_call_edges.saturating_add(1);
+                }
+            }
+        }
+    }
+
+    // Register-indirect calls through a register loaded with a function
+    // address in the same block (`lea rax, [rip+f]; call rax`).
+    let entries: std::collections::HashSet<u64> =
+        functions.iter().map(|f| f.entry_point.value).collect();
+    for f in &functions {
+        for (site, target) in register_call_targets(data, arch, end, f, &entries) {
+            let callee = name_by_va
+                .get(&target)
+                .cloned()
+                .unwrap_or_else(|| format!("sub_{:x}", target));
+            cg.add_node(callee.clone());
+            let edge = Address::new(AddressKind::VA, site, bits, None, None)
+                .map(|site| {
+                    CallGraphEdge::with_call_sites(
+                        f.name.clone(),
+                        callee.clone(),
+                        CallType::Indirect,
+                        vec![site],
+                    )
+                })
+                .unwrap_or_else(|_| CallGraphEdge::new(f.name.clone(), callee, CallType::Indirect));
+            cg.add_edge(edge);
+            stats.register_call_edges = stats.register_call_edges.saturating_add(1);
+        }
+    }
+
     stats.code_labels = collect_code_labels(data, &functions);
     stats.code_label_count = stats.code_labels.len();
     stats.functions_discovered = functions.len();
@@ -3831,3 +4278,21 @@ mod chunk_tests {
         assert_eq!(funcs.len(), 1);
     }
 }
+
+#[cfg(test)]
+mod register_call_tests {
+    use super::x86_register_family;
+
+    #[test]
+    fn sub_registers_share_a_family() {
+        for r in ["rax", "eax", "ax", "al", "ah"] {
+            assert_eq!(x86_register_family(r), "a", "{r}");
+        }
+        for r in ["r8", "r8d", "r8w", "r8b"] {
+            assert_eq!(x86_register_family(r), "r8", "{r}");
+        }
+        assert_eq!(x86_register_family("RSI"), "si");
+        assert_eq!(x86_register_family("sil"), "si");
+        assert_ne!(x86_register_family("rsi"), x86_register_family("rdi"));
+    }
+}
@@ -0,0 +1,485 @@
+//! CIL method-body disassembly and managed call graph.
+//!
+//! Method bodies (ECMA-335 II.25.4) start with a tiny (1-byte) or fat
+//! (12-byte) header followed by the IL stream. Instructions are a one-byte
+//! opcode, or `0xFE` plus a second byte, followed by an operand whose shape
+//! the opcode fixes (III.1.2). Token operands are resolved through
+//! `CilMetadata`, so `call`, `ldfld`, `newobj` and friends render with the
+//! member names ILDasm would print.
+//!
+//! The managed call graph has one node per MethodDef and one per referenced
+//! external member:
+//!
+//!   * `call`, `newobj` → Direct
+//!   * `callvirt` → Virtual (the runtime type picks the override)
+//!   * `jmp`, and calls under a `tail.` prefix → Tail
+//!   * `ldftn`, `ldvirtftn` → Indirect (a delegate is built over the target)
+//!
+//! `calli` goes through a function pointer and a bare signature, so it has
+//! no named callee.
+
+use std::collections::HashMap;
+
+use crate::analysis::cil_metadata::{token_table, CilError, CilMetadata, CilPInvoke, METHOD_DEF};
+use crate::core::address::{Address, AddressKind};
+use crate::core::call_graph::{CallGraph, CallGraphEdge, CallType};
+
+/// Operand shape of an opcode.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Operand {
+    None,
+    I1,
+    U1,
+    I4,
+    I8,
+    R4,
+    R8,
+    Token,
+    String,
+    Br1,
+    Br4,
+    Switch,
+    Var1,
+    Var2,
+}
+
+/// Mnemonics of the one-byte opcodes 0x00..=0xE0; empty entries are
+/// reserved encodings.
+#[rustfmt::skip]
+const ONE_BYTE: [&str; 0xE1] = [
+    "nop", "break", "ldarg.0", "ldarg.1", "ldarg.2", "ldarg.3", "ldloc.0", "ldloc.1",
+    "ldloc.2", "ldloc.3", "stloc.0", "stloc.1", "stloc.2", "stloc.3", "ldarg.s", "ldarga.s",
+    "starg.s", "ldloc.s", "ldloca.s", "stloc.s", "ldnull", "ldc.i4.m1", "ldc.i4.0", "ldc.i4.1",
+    "ldc.i4.2", "ldc.i4.3", "ldc.i4.4", "ldc.i4.5", "ldc.i4.6", "ldc.i4.7", "ldc.i4.8", "ldc.i4.s",
+    "ldc.i4", "ldc.i8", "ldc.r4", "ldc.r8", "", "dup", "pop", "jmp",
+    "call", "calli", "ret", "br.s", "brfalse.s", "brtrue.s", "beq.s", "bge.s",
+    "bgt.s", "ble.s", "blt.s", "bne.un.s", "bge.un.s", "bgt.un.s", "ble.un.s", "blt.un.s",
+    "br", "brfalse", "brtrue", "beq", "bge", "bgt", "ble", "blt",
+    "bne.un", "bge.un", "bgt.un", "ble.un", "blt.un", "switch", "ldind.i1", "ldind.u1",
+    "ldind.i2", "ldind.u2", "ldind.i4", "ldind.u4", "ldind.i8", "ldind.i", "ldind.r4", "ldind.r8",
+    "ldind.ref", "stind.ref", "stind.i1", "stind.i2", "stind.i4", "stind.i8", "stind.r4", "stind.r8",
+    "add", "sub", "mul", "div", "div.un", "rem", "rem.un", "and",
+    "or", "xor", "shl", "shr", "shr.un", "neg", "not", "conv.i1",
+    "conv.i2", "conv.i4", "conv.i8", "conv.r4", "conv.r8", "conv.u4", "conv.u8", "callvirt",
+    "cpobj", "ldobj", "ldstr", "newobj", "castclass", "isinst", "conv.r.un", "",
+    "", "unbox", "throw", "ldfld", "ldflda", "stfld", "ldsfld", "ldsflda",
+    "stsfld", "stobj", "conv.ovf.i1.un", "conv.ovf.i2.un", "conv.ovf.i4.un", "conv.ovf.i8.un", "conv.ovf.u1.un", "conv.ovf.u2.un",
+    "conv.ovf.u4.un", "conv.ovf.u8.un", "conv.ovf.i.un", "conv.ovf.u.un", "box", "newarr", "ldlen", "ldelema",
+    "ldelem.i1", "ldelem.u1", "ldelem.i2", "ldelem.u2", "ldelem.i4", "ldelem.u4", "ldelem.i8", "ldelem.i",
+    "ldelem.r4", "ldelem.r8", "ldelem.ref", "stelem.i", "stelem.i1", "stelem.i2", "stelem.i4", "stelem.i8",
+    "stelem.r4", "stelem.r8", "stelem.ref", "ldelem", "stelem", "unbox.any", "", "",
+    "", "", "", "", "", "", "", "",
+    "", "", "", "conv.ovf.i1", "conv.ovf.u1", "conv.ovf.i2", "conv.ovf.u2", "conv.ovf.i4",
+    "conv.ovf.u4", "conv.ovf.i8", "conv.ovf.u8", "", "", "", "", "",
+    "", "", "refanyval", "ckfinite", "", "", "mkrefany", "",
+    "", "", "", "", "", "", "", "",
+    "ldtoken", "conv.u2", "conv.u1", "conv.i", "conv.ovf.i", "conv.ovf.u", "add.ovf", "add.ovf.un",
+    "mul.ovf", "mul.ovf.un", "sub.ovf", "sub.ovf.un", "endfinally", "leave", "leave.s", "stind.i",
+    "conv.u",
+];
+
+/// Mnemonics of the `0xFE`-prefixed opcodes 0x00..=0x1E.
+#[rustfmt::skip]
+const TWO_BYTE: [&str; 0x1F] = [
+    "arglist", "ceq", "cgt", "cgt.un", "clt", "clt.un", "ldftn", "ldvirtftn",
+    "", "ldarg", "ldarga", "starg", "ldloc", "ldloca", "stloc", "localloc",
+    "", "endfilter", "unaligned.", "volatile.", "tail.", "initobj", "constrained.", "cpblk",
+    "initblk", "no.", "rethrow", "", "sizeof", "refanytype", "readonly.",
+];
+
+/// Two-byte opcodes are stored as `0xFE00 | second byte`.
+const TAIL_PREFIX: u16 = 0xFE14;
+
+fn operand_of(opcode: u16) -> Operand {
+    match opcode {
+        0x0E..=0x13 => Operand::Var1,
+        0x1F => Operand::I1,
+        0x20 => Operand::I4,
+        0x21 => Operand::I8,
+        0x22 => Operand::R4,
+        0x23 => Operand::R8,
+        0x27
+        | 0x28
+        | 0x29
+        | 0x6F..=0x71
+        | 0x73..=0x75
+        | 0x79
+        | 0x7B..=0x81
+        | 0x8C
+        | 0x8D
+        | 0x8F
+        | 0xA3..=0xA5
+        | 0xC2
+        | 0xC6
+        | 0xD0 => Operand::Token,
+        0x72 => Operand::String,
+        0x2B..=0x37 | 0xDE => Operand::Br1,
+        0x38..=0x44 | 0xDD => Operand::Br4,
+        0x45 => Operand::Switch,
+        0xFE06 | 0xFE07 | 0xFE15 | 0xFE16 | 0xFE1C => Operand::Token,
+        0xFE09..=0xFE0E => Operand::Var2,
+        0xFE12 | 0xFE19 => Operand::U1,
+        _ => Operand::None,
+    }
+}
+
+/// A decoded operand. Branch targets are absolute IL offsets.
+#[derive(Debug, Clone, PartialEq)]
+pub enum IlOperand {
+    None,
+    Int(i64),
+    Float(f64),
+    /// Metadata token: method, field, type, or signature
+    Token(u32),
+    /// `#US` token of an `ldstr` literal
+    String(u32),
+    Target(u32),
+    Switch(Vec<u32>),
+    /// Argument or local slot
+    Var(u16),
+}
+
+#[derive(Debug, Clone, PartialEq)]
+pub struct IlInstruction {
+    /// Offset from the start of the method's IL stream.
+    pub offset: u32,
+    /// One-byte opcode, or `0xFE00 | second byte`.
+    pub opcode: u16,
+    pub mnemonic: &'static str,
+    pub operand: IlOperand,
+}
+
+impl IlInstruction {
+    /// ILDasm-style text, e.g. `IL_0006: ldstr "glaurung-widget"`.
+    pub fn render(&self, md: &CilMetadata<'_>) -> String {
+        let operand = match &self.operand {
+            IlOperand::None => String::new(),
+            IlOperand::Int(v) => v.to_string(),
+            IlOperand::Float(v) => v.to_string(),
+            IlOperand::Token(t) => md.token_name(*t),
+            IlOperand::String(t) => match md.user_string(*t) {
+                Some(s) => format!("{:?}", s),
+                None => format!("{:#010x}", t),
+            },
+            IlOperand::Target(t) => format!("IL_{:04x}", t),
+            IlOperand::Switch(ts) => {
+                let ts: Vec<String> = ts.iter().map(|t| format!("IL_{:04x}", t)).collect();
+                format!("({})", ts.join(", "))
+            }
+            IlOperand::Var(v) => v.to_string(),
+        };
+        if operand.is_empty() {
+            format!("IL_{:04x}: {}", self.offset, self.mnemonic)
+        } else {
+            format!("IL_{:04x}: {} {}", self.offset, self.mnemonic, operand)
+        }
+    }
+}
+
+/// A method body: header fields plus the decoded IL stream.
+#[derive(Debug, Clone, PartialEq)]
+pub struct IlBody {
+    /// RVA of the first IL byte (after the header).
+    pub code_rva: u32,
+    pub max_stack: u16,
+    /// StandAloneSig token of the locals, 0 when there are none.
+    pub local_sig: u32,
+    pub init_locals: bool,
+    pub instructions: Vec<IlInstruction>,
+}
+
+#[derive(Debug, Clone, PartialEq)]
+pub struct CilMethodIl {
+    /// MethodDef token.
+    pub token: u32,
+    pub rva: u32,
+    /// `Namespace.Type::Method`
+    pub name: String,
+    pub body: IlBody,
+}
+
+/// Disassembled methods, P/Invoke imports, and managed call graph of an
+/// assembly.
+#[derive(Debug, Clone)]
+pub struct CilProgram {
+    pub methods: Vec<CilMethodIl>,
+    pub pinvokes: Vec<CilPInvoke>,
+    pub call_graph: CallGraph,
+}
+
+/// Decode the method body header at the start of `bytes` and the IL stream
+/// it describes. `rva` is the body's RVA.
+pub fn decode_body(bytes: &[u8], rva: u32) -> Result<IlBody, CilError> {
+    let b0 = *bytes.first().ok_or(CilError::Truncated("method header"))?;
+    let (header, code_size, max_stack, local_sig, init_locals) = match b0 & 0x3 {
+        // Tiny: size in the upper six bits, max stack 8, no locals.
+        0x2 => (1, (b0 >> 2) as usize, 8, 0, false),
+        0x3 => {
+            let h = bytes
+                .get(..12)
+                .ok_or(CilError::Truncated("fat method header"))?;
+            let flags = u16::from_le_bytes([h[0], h[1]]);
+            let header = (flags >> 12) as usize * 4;
+            if header < 12 {
+                return Err(CilError::Truncated("fat method header"));
+            }
+            (
+                header,
+                u32::from_le_bytes(h[4..8].try_into().unwrap()) as usize,
+                u16::from_le_bytes([h[2], h[3]]),
+                u32::from_le_bytes(h[8..12].try_into().unwrap()),
+                flags & 0x10 != 0,
+            )
+        }
+        _ => return Err(CilError::Truncated("method header")),
+    };
+    let code = header
+        .checked_add(code_size)
+        .and_then(|end| bytes.get(header..end))
+        .ok_or(CilError::Truncated("method body"))?;
+    Ok(IlBody {
+        code_rva: rva + header as u32,
+        max_stack,
+        local_sig,
+        init_locals,
+        instructions: decode_il(code)?,
+    })
+}
+
+/// Decode an IL stream.
+pub fn decode_il(code: &[u8]) -> Result<Vec<IlInstruction>, CilError> {
+    let mut out = Vec::new();
+    let mut p = 0usize;
+    while p < code.len() {
+        let offset = p as u32;
+        let (opcode, mnemonic) = match code[p] {
+            0xFE => {
+                let b = *code.get(p + 1).ok_or(CilError::Truncated("opcode"))?;
+                p += 2;
+                (
+                    0xFE00 | b as u16,
+                    TWO_BYTE.get(b as usize).copied().unwrap_or(""),
+                )
+            }
+            b => {
+                p += 1;
+                (b as u16, ONE_BYTE.get(b as usize).copied().unwrap_or(""))
+            }
+        };
+        if mnemonic.is_empty() {
+            return Err(CilError::Truncated("reserved opcode"));
+        }
+        let mut take = |n: usize| -> Result<&[u8], CilError> {
+            let b = code.get(p..p + n).ok_or(CilError::Truncated("operand"))?;
+            p += n;
+            Ok(b)
+        };
+        let i32_at = |b: &[u8]| i32::from_le_bytes(b.try_into().unwrap());
+        let operand = match operand_of(opcode) {
+            Operand::None => IlOperand::None,
+            Operand::I1 => IlOperand::Int(take(1)?[0] as i8 as i64),
+            Operand::U1 => IlOperand::Int(take(1)?[0] as i64),
+            Operand::I4 => IlOperand::Int(i32_at(take(4)?) as i64),
+            Operand::I8 => IlOperand::Int(i64::from_le_bytes(take(8)?.try_into().unwrap())),
+            Operand::R4 => {
+                IlOperand::Float(f32::from_le_bytes(take(4)?.try_into().unwrap()) as f64)
+            }
+            Operand::R8 => IlOperand::Float(f64::from_le_bytes(take(8)?.try_into().unwrap())),
+            Operand::Token => IlOperand::Token(i32_at(take(4)?) as u32),
+            Operand::String => IlOperand::String(i32_at(take(4)?) as u32),
+            Operand::Var1 => IlOperand::Var(take(1)?[0] as u16),
+            Operand::Var2 => {
+                let b = take(2)?;
+                IlOperand::Var(u16::from_le_bytes([b[0], b[1]]))
+            }
+            // Branch displacements are relative to the next instruction.
+            Operand::Br1 => {
+                let d = take(1)?[0] as i8 as i64;
+                IlOperand::Target((p as i64 + d) as u32)
+            }
+            Operand::Br4 => {
+                let d = i32_at(take(4)?) as i64;
+                IlOperand::Target((p as i64 + d) as u32)
+            }
+            Operand::Switch => {
+                let n = i32_at(take(4)?) as u32 as usize;
+                let table = take(n.checked_mul(4).ok_or(CilError::Truncated("switch"))?)?;
+                let next = p as i64;
+                IlOperand::Switch(
+                    table
+                        .chunks_exact(4)
+                        .map(|c| (next + i32_at(c) as i64) as u32)
+                        .collect(),
+                )
+            }
+        };
+        out.push(IlInstruction {
+            offset,
+            opcode,
+            mnemonic,
+            operand,
+        });
+    }
+    Ok(out)
+}
+
+/// Call-graph edge kind of a call-like instruction, or `None`.
+fn call_type(opcode: u16, tail: bool) -> Option<CallType> {
+    Some(match opcode {
+        0x28 | 0x6F if tail => CallType::Tail,
+        0x28 | 0x73 => CallType::Direct,
+        0x6F => CallType::Virtual,
+        0x27 => CallType::Tail,
+        0xFE06 | 0xFE07 => CallType::Indirect,
+        _ => return None,
+    })
+}
+
+/// Disassemble every MethodDef with a body and build the managed call graph.
+pub fn disassemble_assembly(data: &[u8]) -> Result<CilProgram, CilError> {
+    let md = CilMetadata::parse(data)?;
+    let mut methods = Vec::new();
+    for row in 1..=md.rows(METHOD_DEF) {
+        // Abstract, runtime-implemented, and P/Invoke methods have RVA 0.
+        let rva = md.cell(METHOD_DEF, row, 0).unwrap_or(0);
+        if rva == 0 {
+            continue;
+        }
+        let Some(bytes) = md.rva_bytes(rva) else {
+            continue;
+        };
+        let Ok(body) = decode_body(bytes, rva) else {
+            continue;
+        };
+        methods.push(CilMethodIl {
+            token: (METHOD_DEF as u32) << 24 | row,
+            rva,
+            name: md.method_name(row),
+            body,
+        });
+    }
+    let call_graph = managed_call_graph(&md, &methods);
+    Ok(CilProgram {
+        methods,
+        pinvokes: md.pinvokes(),
+        call_graph,
+    })
+}
+
+fn managed_call_graph(md: &CilMetadata<'_>, methods: &[CilMethodIl]) -> CallGraph {
+    let mut cg = CallGraph::new();
+    let mut nodes: Vec<String> = (1..=md.rows(METHOD_DEF))
+        .map(|r| md.method_name(r))
+        .collect();
+    let mut edge_of: HashMap<(String, String, CallType), usize> = HashMap::new();
+    for m in methods {
+        let mut tail = false;
+        for ins in &m.body.instructions {
+            let prefixed = std::mem::replace(&mut tail, ins.opcode == TAIL_PREFIX);
+            let (Some(kind), IlOperand::Token(token)) =
+                (call_type(ins.opcode, prefixed), &ins.operand)
+            else {
+                continue;
+            };
+            let callee = md.token_name(*token);
+            if token_table(*token) != METHOD_DEF {
+                nodes.push(callee.clone());
+            }
+            let Ok(site) = Address::new(
+                AddressKind::RVA,
+                (m.body.code_rva + ins.offset) as u64,
+                32,
+                None,
+                None,
+            ) else {
+                continue;
+            };
+            let key = (m.name.clone(), callee, kind);
+            let idx = *edge_of.entry(key.clone()).or_insert_with(|| {
+                cg.add_edge(CallGraphEdge::new(key.0, key.1, kind));
+                cg.edges.len() - 1
+            });
+            cg.edges[idx].add_call_site(site);
+        }
+    }
+    nodes.sort();
+    nodes.dedup();
+    cg.nodes = nodes;
+    cg
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn decodes_a_tiny_body() {
+        // ldarg.0; brtrue.s +1; ldnull; ldc.i4.s -2; ret
+        let code = [0x02, 0x2D, 0x01, 0x14, 0x1F, 0xFE, 0x2A];
+        let mut bytes = vec![(code.len() as u8) << 2 | 0x2];
+        bytes.extend_from_slice(&code);
+        let body = decode_body(&bytes, 0x2050).unwrap();
+        assert_eq!(body.code_rva, 0x2051);
+        assert_eq!(body.max_stack, 8);
+        let ops: Vec<(&str, &IlOperand)> = body
+            .instructions
+            .iter()
+            .map(|i| (i.mnemonic, &i.operand))
+            .collect();
+        assert_eq!(
+            ops,
+            vec![
+                ("ldarg.0", &IlOperand::None),
+                ("brtrue.s", &IlOperand::Target(4)),
+                ("ldnull", &IlOperand::None),
+                ("ldc.i4.s", &IlOperand::Int(-2)),
+                ("ret", &IlOperand::None),
+            ]
+        );
+    }
+
+    #[test]
+    fn decodes_a_fat_body_with_two_byte_opcodes() {
+        // flags: fat, InitLocals, 3 dwords of header; max stack 2;
+        // locals sig 0x11000001
+        let mut bytes = vec![0x13, 0x30, 0x02, 0x00];
+        let code = [
+            0xFE, 0x06, 0x01, 0x00, 0x00, 0x06, // ldftn 0x06000001
+            0x45, 0x02, 0x00, 0x00, 0x00, // switch (2 targets)
+            0x00, 0x00, 0x00, 0x00, 0xFE, 0xFF, 0xFF, 0xFF, //
+            0xFE, 0x0C, 0x03, 0x00, // ldloc 3
+        ];
+        bytes.extend_from_slice(&(code.len() as u32).to_le_bytes());
+        bytes.extend_from_slice(&0x1100_0001u32.to_le_bytes());
+        bytes.extend_from_slice(&code);
+        let body = decode_body(&bytes, 0x2000).unwrap();
+        assert!(body.init_locals);
+        assert_eq!(body.max_stack, 2);
+        assert_eq!(body.local_sig, 0x1100_0001);
+        assert_eq!(body.code_rva, 0x200C);
+        let ins = &body.instructions;
+        assert_eq!(ins.len(), 3);
+        assert_eq!((ins[0].opcode, ins[0].mnemonic), (0xFE06, "ldftn"));
+        assert_eq!(ins[0].operand, IlOperand::Token(0x0600_0001));
+        // Switch targets are relative to the end of the jump table (0x13).
+        assert_eq!(ins[1].operand, IlOperand::Switch(vec![0x13, 0x11]));
+        assert_eq!(ins[2].offset, 0x13);
+        assert_eq!(ins[2].operand, IlOperand::Var(3));
+    }
+
+    #[test]
+    fn rejects_truncated_and_reserved_code() {
+        assert!(decode_il(&[0x20, 0x01]).is_err()); // ldc.i4 without its imm32
+        assert!(decode_il(&[0x24]).is_err()); // reserved
+        assert!(decode_body(&[0x09], 0).is_err()); // tiny body past the end
+    }
+
+    #[test]
+    fn tail_prefix_marks_tail_calls() {
+        assert_eq!(call_type(0x28, true), Some(CallType::Tail));
+        assert_eq!(call_type(0x28, false), Some(CallType::Direct));
+        assert_eq!(call_type(0x6F, false), Some(CallType::Virtual));
+        assert_eq!(call_type(0xFE07, false), Some(CallType::Indirect));
+        assert_eq!(call_type(0x29, false), None); // calli
+    }
+}
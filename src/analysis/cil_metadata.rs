@@ -25,6 +25,8 @@
 //!   * Param/Field row size is computed but not consumed beyond
 //!     advancing the cursor.
 
+use std::collections::HashMap;
+
 use object::{Object, ObjectSection};
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -49,11 +51,15 @@ pub enum CilError {
 /// Parse a .NET PE and return every recoverable method's (RVA, full name).
 pub fn extract_cil_methods(data: &[u8]) -> Result<Vec<CilMethod>, CilError> {
     let obj = object::read::File::parse(data).map_err(|_| CilError::NoCom)?;
+    parse_metadata_root(metadata_root(&obj)?)
+}
 
+/// The metadata root (`BSJB` blob) of a .NET PE.
+fn metadata_root<'d>(obj: &object::read::File<'d>) -> Result<&'d [u8], CilError> {
     // Locate the CLR data directory. object's PE support exposes the
     // 16 data dirs through `pe_data_directories`. Index 14 is
     // IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR.
-    let (clr_rva, clr_size) = match &obj {
+    let (clr_rva, clr_size) = match obj {
         object::read::File::Pe32(pe) => {
             let dirs = pe.data_directories();
             let dir = dirs.get(14).ok_or(CilError::NoCom)?;
@@ -78,7 +84,7 @@ pub fn extract_cil_methods(data: &[u8]) -> Result<Vec<CilMethod>, CilError> {
 
     // CLR header is at the COM data dir RVA. We need it as a file slice.
     let clr_header =
-        read_at_rva(&obj, clr_rva as u64, 72).ok_or(CilError::Truncated("clr header"))?;
+        read_at_rva(obj, clr_rva as u64, 72).ok_or(CilError::Truncated("clr header"))?;
     if clr_header.len() < 16 {
         return Err(CilError::Truncated("clr header"));
     }
@@ -93,10 +99,16 @@ pub fn extract_cil_methods(data: &[u8]) -> Result<Vec<CilMethod>, CilError> {
     if meta_rva == 0 || meta_size == 0 {
         return Err(CilError::NoMetadata);
     }
-    let meta = read_at_rva(&obj, meta_rva as u64, meta_size as usize)
-        .ok_or(CilError::Truncated("metadata"))?;
+    read_at_rva(obj, meta_rva as u64, meta_size as usize).ok_or(CilError::Truncated("metadata"))
+}
 
-    parse_metadata_root(meta)
+/// PE image base, or 0 for other formats.
+fn image_base(obj: &object::read::File<'_>) -> u64 {
+    match obj {
+        object::read::File::Pe32(ref pe) => pe.relative_address_base(),
+        object::read::File::Pe64(ref pe) => pe.relative_address_base(),
+        _ => 0,
+    }
 }
 
 /// Read `len` bytes at the given RVA, returning a borrowed slice.
@@ -105,15 +117,11 @@ pub fn extract_cil_methods(data: &[u8]) -> Result<Vec<CilMethod>, CilError> {
 /// VA (i.e. RVA, not absolute VA — `object` doesn't add the image base
 /// for PE). We compare against both interpretations defensively so
 /// this works with either convention.
-fn read_at_rva<'a>(obj: &'a object::read::File<'a>, rva: u64, len: usize) -> Option<&'a [u8]> {
+fn read_at_rva<'d>(obj: &object::read::File<'d>, rva: u64, len: usize) -> Option<&'d [u8]> {
     // First pass: find a section whose [addr, addr+size) contains rva
     // under either RVA or absolute-VA semantics, where the absolute-VA
     // case subtracts the image base.
-    let image_base: u64 = match obj {
-        object::read::File::Pe32(ref pe) => pe.relative_address_base(),
-        object::read::File::Pe64(ref pe) => pe.relative_address_base(),
-        _ => 0,
-    };
+    let image_base = image_base(obj);
     for sec in obj.sections() {
         let sec_addr_raw = sec.address();
         // Try treating section address as RVA first.
@@ -133,6 +141,14 @@ fn read_at_rva<'a>(obj: &'a object::read::File<'a>, rva: u64, len: usize) -> Opt
 /// Parse the metadata root, locate the streams, and walk the table
 /// stream to recover MethodDef + TypeDef rows.
 fn parse_metadata_root(meta: &[u8]) -> Result<Vec<CilMethod>, CilError> {
+    let streams = metadata_streams(meta)?;
+    let tilde = stream(&streams, &["#~", "#-"]).ok_or(CilError::NoTilde)?;
+    let strings = stream(&streams, &["#Strings"]).ok_or(CilError::NoStrings)?;
+    parse_tilde_stream(tilde, strings)
+}
+
+/// `(name, bytes)` of every stream the metadata root lists.
+fn metadata_streams(meta: &[u8]) -> Result<Vec<(&str, &[u8])>, CilError> {
     if meta.len() < 16 {
         return Err(CilError::Truncated("metadata root"));
     }
@@ -154,11 +170,7 @@ fn parse_metadata_root(meta: &[u8]) -> Result<Vec<CilMethod>, CilError> {
     let n_streams = u16::from_le_bytes(meta[p..p + 2].try_into().unwrap()) as usize;
     p += 2;
 
-    let mut tilde_off: Option<usize> = None;
-    let mut tilde_size: Option<usize> = None;
-    let mut strings_off: Option<usize> = None;
-    let mut strings_size: Option<usize> = None;
-
+    let mut out = Vec::with_capacity(n_streams);
     for _ in 0..n_streams {
         if p + 8 > meta.len() {
             return Err(CilError::Truncated("stream header"));
@@ -174,33 +186,20 @@ fn parse_metadata_root(meta: &[u8]) -> Result<Vec<CilMethod>, CilError> {
         let name = std::str::from_utf8(&meta[name_start..name_end]).unwrap_or("");
         let pad = ((name_end - name_start + 1) + 3) & !3;
         p = name_start + pad;
-        match name {
-            "#~" | "#-" => {
-                tilde_off = Some(off);
-                tilde_size = Some(size);
-            }
-            "#Strings" => {
-                strings_off = Some(off);
-                strings_size = Some(size);
-            }
-            _ => {}
-        }
-    }
-
-    let tilde_off = tilde_off.ok_or(CilError::NoTilde)?;
-    let tilde_size = tilde_size.unwrap_or(meta.len() - tilde_off);
-    let strings_off = strings_off.ok_or(CilError::NoStrings)?;
-    let strings_size = strings_size.unwrap_or(meta.len() - strings_off);
-    if tilde_off + tilde_size > meta.len() {
-        return Err(CilError::Truncated("#~"));
-    }
-    if strings_off + strings_size > meta.len() {
-        return Err(CilError::Truncated("#Strings"));
+        let bytes = off
+            .checked_add(size)
+            .and_then(|end| meta.get(off..end))
+            .ok_or(CilError::Truncated("stream"))?;
+        out.push((name, bytes));
     }
-    let tilde = &meta[tilde_off..tilde_off + tilde_size];
-    let strings = &meta[strings_off..strings_off + strings_size];
+    Ok(out)
+}
 
-    parse_tilde_stream(tilde, strings)
+fn stream<'m>(streams: &[(&str, &'m [u8])], names: &[&str]) -> Option<&'m [u8]> {
+    streams
+        .iter()
+        .find(|(n, _)| names.contains(n))
+        .map(|&(_, bytes)| bytes)
 }
 
 fn read_string(strings: &[u8], idx: u32) -> String {
@@ -400,6 +399,678 @@ fn parse_tilde_stream(tilde: &[u8], strings: &[u8]) -> Result<Vec<CilMethod>, Ci
     Ok(out)
 }
 
+// ---------------------------------------------------------------------------
+// Full metadata view (#236)
+//
+// `extract_cil_methods` only needs TypeDef and MethodDef. IL disassembly
+// resolves tokens into every table, so `CilMetadata` carries the complete
+// II.22 schema and computes each table's row size and offset up front.
+// ---------------------------------------------------------------------------
+
+pub const MODULE: u8 = 0x00;
+pub const TYPE_REF: u8 = 0x01;
+pub const TYPE_DEF: u8 = 0x02;
+pub const FIELD: u8 = 0x04;
+pub const METHOD_DEF: u8 = 0x06;
+pub const MEMBER_REF: u8 = 0x0A;
+pub const STAND_ALONE_SIG: u8 = 0x11;
+pub const MODULE_REF: u8 = 0x1A;
+pub const TYPE_SPEC: u8 = 0x1B;
+pub const IMPL_MAP: u8 = 0x1C;
+pub const ASSEMBLY_REF: u8 = 0x23;
+pub const NESTED_CLASS: u8 = 0x29;
+pub const METHOD_SPEC: u8 = 0x2B;
+/// Token table byte of `#US` heap references (`ldstr`).
+pub const USER_STRING: u8 = 0x70;
+
+/// Unused tag of a coded index.
+const NONE: u8 = 0xFF;
+
+// Coded indexes (II.24.2.6): the referenced table for each tag value.
+const TYPE_DEF_OR_REF: &[u8] = &[TYPE_DEF, TYPE_REF, TYPE_SPEC];
+const HAS_CONSTANT: &[u8] = &[FIELD, 0x08, 0x17];
+const HAS_CUSTOM_ATTRIBUTE: &[u8] = &[
+    METHOD_DEF,
+    FIELD,
+    TYPE_REF,
+    TYPE_DEF,
+    0x08,
+    0x09,
+    MEMBER_REF,
+    MODULE,
+    0x0E,
+    0x17,
+    0x14,
+    STAND_ALONE_SIG,
+    MODULE_REF,
+    TYPE_SPEC,
+    0x20,
+    ASSEMBLY_REF,
+    0x26,
+    0x27,
+    0x28,
+    0x2A,
+    0x2C,
+    METHOD_SPEC,
+];
+const HAS_FIELD_MARSHAL: &[u8] = &[FIELD, 0x08];
+const HAS_DECL_SECURITY: &[u8] = &[TYPE_DEF, METHOD_DEF, 0x20];
+const MEMBER_REF_PARENT: &[u8] = &[TYPE_DEF, TYPE_REF, MODULE_REF, METHOD_DEF, TYPE_SPEC];
+const HAS_SEMANTICS: &[u8] = &[0x14, 0x17];
+const METHOD_DEF_OR_REF: &[u8] = &[METHOD_DEF, MEMBER_REF];
+const MEMBER_FORWARDED: &[u8] = &[FIELD, METHOD_DEF];
+const IMPLEMENTATION: &[u8] = &[0x26, ASSEMBLY_REF, 0x27];
+const CUSTOM_ATTRIBUTE_TYPE: &[u8] = &[NONE, NONE, METHOD_DEF, MEMBER_REF, NONE];
+const RESOLUTION_SCOPE: &[u8] = &[MODULE, MODULE_REF, ASSEMBLY_REF, TYPE_REF];
+const TYPE_OR_METHOD_DEF: &[u8] = &[TYPE_DEF, METHOD_DEF];
+
+#[derive(Debug, Clone, Copy)]
+enum Col {
+    U16,
+    U32,
+    Str,
+    Guid,
+    Blob,
+    Table(u8),
+    Coded(&'static [u8]),
+}
+
+use Col::{Blob, Coded, Guid, Str, Table, U16, U32};
+
+/// Column layout of tables 0x00..=0x2C (II.22). Constant's one-byte type
+/// plus its padding byte is a `U16`.
+#[rustfmt::skip]
+const SCHEMA: [&[Col]; 0x2D] = [
+    // 0x00 Module
+    &[U16, Str, Guid, Guid, Guid],
+    // 0x01 TypeRef
+    &[Coded(RESOLUTION_SCOPE), Str, Str],
+    // 0x02 TypeDef
+    &[U32, Str, Str, Coded(TYPE_DEF_OR_REF), Table(FIELD), Table(METHOD_DEF)],
+    // 0x03 FieldPtr
+    &[Table(FIELD)],
+    // 0x04 Field
+    &[U16, Str, Blob],
+    // 0x05 MethodPtr
+    &[Table(METHOD_DEF)],
+    // 0x06 MethodDef
+    &[U32, U16, U16, Str, Blob, Table(0x08)],
+    // 0x07 ParamPtr
+    &[Table(0x08)],
+    // 0x08 Param
+    &[U16, U16, Str],
+    // 0x09 InterfaceImpl
+    &[Table(TYPE_DEF), Coded(TYPE_DEF_OR_REF)],
+    // 0x0A MemberRef
+    &[Coded(MEMBER_REF_PARENT), Str, Blob],
+    // 0x0B Constant
+    &[U16, Coded(HAS_CONSTANT), Blob],
+    // 0x0C CustomAttribute
+    &[Coded(HAS_CUSTOM_ATTRIBUTE), Coded(CUSTOM_ATTRIBUTE_TYPE), Blob],
+    // 0x0D FieldMarshal
+    &[Coded(HAS_FIELD_MARSHAL), Blob],
+    // 0x0E DeclSecurity
+    &[U16, Coded(HAS_DECL_SECURITY), Blob],
+    // 0x0F ClassLayout
+    &[U16, U32, Table(TYPE_DEF)],
+    // 0x10 FieldLayout
+    &[U32, Table(FIELD)],
+    // 0x11 StandAloneSig
+    &[Blob],
+    // 0x12 EventMap
+    &[Table(TYPE_DEF), Table(0x14)],
+    // 0x13 EventPtr
+    &[Table(0x14)],
+    // 0x14 Event
+    &[U16, Str, Coded(TYPE_DEF_OR_REF)],
+    // 0x15 PropertyMap
+    &[Table(TYPE_DEF), Table(0x17)],
+    // 0x16 PropertyPtr
+    &[Table(0x17)],
+    // 0x17 Property
+    &[U16, Str, Blob],
+    // 0x18 MethodSemantics
+    &[U16, Table(METHOD_DEF), Coded(HAS_SEMANTICS)],
+    // 0x19 MethodImpl
+    &[Table(TYPE_DEF), Coded(METHOD_DEF_OR_REF), Coded(METHOD_DEF_OR_REF)],
+    // 0x1A ModuleRef
+    &[Str],
+    // 0x1B TypeSpec
+    &[Blob],
+    // 0x1C ImplMap
+    &[U16, Coded(MEMBER_FORWARDED), Str, Table(MODULE_REF)],
+    // 0x1D FieldRVA
+    &[U32, Table(FIELD)],
+    // 0x1E EncLog
+    &[U32, U32],
+    // 0x1F EncMap
+    &[U32],
+    // 0x20 Assembly
+    &[U32, U16, U16, U16, U16, U32, Blob, Str, Str],
+    // 0x21 AssemblyProcessor
+    &[U32],
+    // 0x22 AssemblyOS
+    &[U32, U32, U32],
+    // 0x23 AssemblyRef
+    &[U16, U16, U16, U16, U32, Blob, Str, Str, Blob],
+    // 0x24 AssemblyRefProcessor
+    &[U32, Table(ASSEMBLY_REF)],
+    // 0x25 AssemblyRefOS
+    &[U32, U32, U32, Table(ASSEMBLY_REF)],
+    // 0x26 File
+    &[U32, Str, Blob],
+    // 0x27 ExportedType
+    &[U32, U32, Str, Str, Coded(IMPLEMENTATION)],
+    // 0x28 ManifestResource
+    &[U32, U32, Str, Coded(IMPLEMENTATION)],
+    // 0x29 NestedClass
+    &[Table(TYPE_DEF), Table(TYPE_DEF)],
+    // 0x2A GenericParam
+    &[U16, U16, Coded(TYPE_OR_METHOD_DEF), Str],
+    // 0x2B MethodSpec
+    &[Coded(METHOD_DEF_OR_REF), Blob],
+    // 0x2C GenericParamConstraint
+    &[Table(0x2A), Coded(TYPE_DEF_OR_REF)],
+];
+
+/// Table byte of a metadata token.
+pub fn token_table(token: u32) -> u8 {
+    (token >> 24) as u8
+}
+
+/// 1-based row of a metadata token.
+pub fn token_row(token: u32) -> u32 {
+    token & 0x00FF_FFFF
+}
+
+fn make_token(table: u8, row: u32) -> u32 {
+    (table as u32) << 24 | row
+}
+
+/// A P/Invoke import declared through the ImplMap table.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct CilPInvoke {
+    /// MethodDef token of the managed declaration.
+    pub token: u32,
+    /// Managed name `Namespace.Type::Method`.
+    pub method: String,
+    /// Native entry point (`EntryPoint=`, else the managed method name).
+    pub entry: String,
+    /// Native module as written in `[DllImport]`, e.g. `kernel32.dll`.
+    pub module: String,
+}
+
+/// Heaps and tables of a .NET assembly, plus the image's sections so method
+/// bodies can be read by RVA.
+pub struct CilMetadata<'a> {
+    tables: &'a [u8],
+    strings: &'a [u8],
+    user_strings: &'a [u8],
+    blobs: &'a [u8],
+    heap_sizes: u8,
+    rows: [u32; 64],
+    offsets: [usize; 64],
+    row_sizes: [usize; 64],
+    /// Owning TypeDef row of each MethodDef row (index 0 is row 1).
+    method_owner: Vec<u32>,
+    /// Owning TypeDef row of each Field row.
+    field_owner: Vec<u32>,
+    /// TypeDef row -> enclosing TypeDef row.
+    enclosing: HashMap<u32, u32>,
+    /// (RVA, bytes) of each section.
+    sections: Vec<(u64, &'a [u8])>,
+}
+
+impl<'a> CilMetadata<'a> {
+    pub fn parse(data: &'a [u8]) -> Result<Self, CilError> {
+        let obj = object::read::File::parse(data).map_err(|_| CilError::NoCom)?;
+        let meta = metadata_root(&obj)?;
+        let streams = metadata_streams(meta)?;
+        let tables = stream(&streams, &["#~", "#-"]).ok_or(CilError::NoTilde)?;
+        let strings = stream(&streams, &["#Strings"]).ok_or(CilError::NoStrings)?;
+        let user_strings = stream(&streams, &["#US"]).unwrap_or(&[]);
+        let blobs = stream(&streams, &["#Blob"]).unwrap_or(&[]);
+        let base = image_base(&obj);
+        let sections = obj
+            .sections()
+            .filter_map(|s| Some((s.address().checked_sub(base)?, s.data().ok()?)))
+            .collect();
+
+        if tables.len() < 24 {
+            return Err(CilError::Truncated("#~ header"));
+        }
+        let heap_sizes = tables[6];
+        let valid = u64::from_le_bytes(tables[8..16].try_into().unwrap());
+        let mut rows = [0u32; 64];
+        let mut p = 24;
+        for (i, count) in rows.iter_mut().enumerate() {
+            if (valid >> i) & 1 == 1 {
+                let b = tables
+                    .get(p..p + 4)
+                    .ok_or(CilError::Truncated("row counts"))?;
+                *count = u32::from_le_bytes(b.try_into().unwrap());
+                p += 4;
+            }
+        }
+        // Uncompressed (#-) streams written by edit-and-continue carry an
+        // extra 4 bytes after the row counts.
+        if heap_sizes & 0x40 != 0 {
+            p += 4;
+        }
+        let mut md = CilMetadata {
+            tables,
+            strings,
+            user_strings,
+            blobs,
+            heap_sizes,
+            rows,
+            offsets: [0; 64],
+            row_sizes: [0; 64],
+            method_owner: Vec::new(),
+            field_owner: Vec::new(),
+            enclosing: HashMap::new(),
+            sections,
+        };
+        for t in 0..64 {
+            if rows[t] == 0 {
+                continue;
+            }
+            let cols = SCHEMA
+                .get(t)
+                .ok_or(CilError::Truncated("unknown metadata table"))?;
+            md.row_sizes[t] = cols.iter().map(|&c| md.col_size(c)).sum();
+            md.offsets[t] = p;
+            p += rows[t] as usize * md.row_sizes[t];
+        }
+        if p > tables.len() {
+            return Err(CilError::Truncated("table rows"));
+        }
+
+        md.method_owner = md.owners(5, METHOD_DEF);
+        md.field_owner = md.owners(4, FIELD);
+        for r in 1..=md.rows(NESTED_CLASS) {
+            if let (Some(nested), Some(outer)) =
+                (md.cell(NESTED_CLASS, r, 0), md.cell(NESTED_CLASS, r, 1))
+            {
+                md.enclosing.insert(nested, outer);
+            }
+        }
+        Ok(md)
+    }
+
+    fn col_size(&self, col: Col) -> usize {
+        let wide = |bits: u8| if self.heap_sizes & bits != 0 { 4 } else { 2 };
+        match col {
+            U16 => 2,
+            U32 => 4,
+            Str => wide(0x01),
+            Guid => wide(0x02),
+            Blob => wide(0x04),
+            Table(t) => {
+                if self.rows[t as usize] < 1 << 16 {
+                    2
+                } else {
+                    4
+                }
+            }
+            Coded(tables) => {
+                let tag_bits = usize::BITS - (tables.len() - 1).leading_zeros();
+                let max_rows = tables
+                    .iter()
+                    .filter(|&&t| t != NONE)
+                    .map(|&t| self.rows[t as usize])
+                    .max()
+                    .unwrap_or(0);
+                if (max_rows as u64) < 1u64 << (16 - tag_bits) {
+                    2
+                } else {
+                    4
+                }
+            }
+        }
+    }
+
+    /// Owning TypeDef row of every row of `table`, from the TypeDef list
+    /// column `list_col` (FieldList or MethodList).
+    fn owners(&self, list_col: usize, table: u8) -> Vec<u32> {
+        let n = self.rows(table);
+        let mut out = vec![0; n as usize];
+        let types = self.rows(TYPE_DEF);
+        for t in 1..=types {
+            let start = self.cell(TYPE_DEF, t, list_col).unwrap_or(0);
+            let end = if t < types {
+                self.cell(TYPE_DEF, t + 1, list_col).unwrap_or(0)
+            } else {
+                n + 1
+            };
+            for r in start.max(1)..end.min(n + 1) {
+                out[r as usize - 1] = t;
+            }
+        }
+        out
+    }
+
+    /// Row count of `table`.
+    pub fn rows(&self, table: u8) -> u32 {
+        self.rows.get(table as usize).copied().unwrap_or(0)
+    }
+
+    /// Raw value of column `col` of 1-based `row` in `table`. Heap columns are
+    /// heap offsets; table columns are row numbers; coded indexes are left
+    /// encoded (see `decode_coded`).
+    pub fn cell(&self, table: u8, row: u32, col: usize) -> Option<u32> {
+        let t = table as usize;
+        if row == 0 || row > self.rows(table) {
+            return None;
+        }
+        let cols = SCHEMA.get(t)?;
+        let mut off = self.offsets[t] + (row as usize - 1) * self.row_sizes[t];
+        for &c in &cols[..col] {
+            off += self.col_size(c);
+        }
+        let size = self.col_size(*cols.get(col)?);
+        let b = self.tables.get(off..off + size)?;
+        Some(match size {
+            2 => u16::from_le_bytes(b.try_into().unwrap()) as u32,
+            _ => u32::from_le_bytes(b.try_into().unwrap()),
+        })
+    }
+
+    /// Token named by coded index `value` over `tables`.
+    fn decode_coded(value: u32, tables: &[u8]) -> Option<u32> {
+        let tag_bits = usize::BITS - (tables.len() - 1).leading_zeros();
+        let table = *tables.get((value & ((1 << tag_bits) - 1)) as usize)?;
+        (table != NONE).then(|| make_token(table, value >> tag_bits))
+    }
+
+    fn coded_cell(&self, table: u8, row: u32, col: usize) -> Option<u32> {
+        let Col::Coded(tables) = SCHEMA[table as usize][col] else {
+            return None;
+        };
+        Self::decode_coded(self.cell(table, row, col)?, tables)
+    }
+
+    /// `#Strings` entry at heap offset `idx`.
+    pub fn string(&self, idx: u32) -> String {
+        read_string(self.strings, idx)
+    }
+
+    fn str_cell(&self, table: u8, row: u32, col: usize) -> String {
+        self.cell(table, row, col)
+            .map(|i| self.string(i))
+            .unwrap_or_default()
+    }
+
+    /// `#Blob` entry at heap offset `idx`, without its length prefix.
+    pub fn blob(&self, idx: u32) -> Option<&'a [u8]> {
+        let mut p = idx as usize;
+        let len = compressed_u32(self.blobs, &mut p)? as usize;
+        self.blobs.get(p..p.checked_add(len)?)
+    }
+
+    /// `#US` string named by an `ldstr` token.
+    pub fn user_string(&self, token: u32) -> Option<String> {
+        let mut p = token_row(token) as usize;
+        let len = compressed_u32(self.user_strings, &mut p)? as usize;
+        // UTF-16LE code units, then one flag byte.
+        let bytes = self.user_strings.get(p..p.checked_add(len)?)?;
+        let units: Vec<u16> = bytes
+            .chunks_exact(2)
+            .map(|c| u16::from_le_bytes([c[0], c[1]]))
+            .collect();
+        Some(String::from_utf16_lossy(&units))
+    }
+
+    /// Image bytes from `rva` to the end of its section.
+    pub fn rva_bytes(&self, rva: u32) -> Option<&'a [u8]> {
+        let rva = rva as u64;
+        self.sections.iter().find_map(|&(start, bytes)| {
+            (rva >= start && rva - start < bytes.len() as u64)
+                .then(|| &bytes[(rva - start) as usize..])
+        })
+    }
+
+    /// Name of a TypeDef, TypeRef, or TypeSpec token. Nested types render
+    /// as `Outer/Inner`.
+    pub fn type_name(&self, token: u32) -> String {
+        self.type_name_depth(token, 0)
+    }
+
+    fn type_name_depth(&self, token: u32, depth: usize) -> String {
+        let row = token_row(token);
+        if depth > MAX_DEPTH {
+            return "?".to_string();
+        }
+        match token_table(token) {
+            TYPE_DEF => {
+                let name = self.str_cell(TYPE_DEF, row, 1);
+                if let Some(&outer) = self.enclosing.get(&row) {
+                    let outer = self.type_name_depth(make_token(TYPE_DEF, outer), depth + 1);
+                    return format!("{}/{}", outer, name);
+                }
+                qualify(&self.str_cell(TYPE_DEF, row, 2), &name)
+            }
+            TYPE_REF => {
+                let name = self.str_cell(TYPE_REF, row, 1);
+                match self.coded_cell(TYPE_REF, row, 0) {
+                    Some(scope) if token_table(scope) == TYPE_REF && token_row(scope) != 0 => {
+                        format!("{}/{}", self.type_name_depth(scope, depth + 1), name)
+                    }
+                    _ => qualify(&self.str_cell(TYPE_REF, row, 2), &name),
+                }
+            }
+            TYPE_SPEC => self
+                .cell(TYPE_SPEC, row, 0)
+                .and_then(|b| self.blob(b))
+                .and_then(|sig| {
+                    let mut p = 0;
+                    self.sig_type(sig, &mut p, depth + 1)
+                })
+                .unwrap_or_else(|| format!("{:#010x}", token)),
+            _ => format!("{:#010x}", token),
+        }
+    }
+
+    /// `Namespace.Type::Method` of a MethodDef row, matching
+    /// `extract_cil_methods` for top-level types.
+    pub fn method_name(&self, row: u32) -> String {
+        let name = self.str_cell(METHOD_DEF, row, 3);
+        match self.method_owner.get(row as usize - 1) {
+            Some(&owner) if owner != 0 => {
+                format!("{}::{}", self.type_name(make_token(TYPE_DEF, owner)), name)
+            }
+            _ => name,
+        }
+    }
+
+    /// Readable name of any token an IL operand references: methods and
+    /// fields render as `Type::Member`, generic method instantiations as
+    /// `Type::Method<args>`, types by `type_name`.
+    pub fn token_name(&self, token: u32) -> String {
+        let row = token_row(token);
+        match token_table(token) {
+            METHOD_DEF if row >= 1 && row <= self.rows(METHOD_DEF) => self.method_name(row),
+            FIELD if row >= 1 && row <= self.rows(FIELD) => {
+                let name = self.str_cell(FIELD, row, 1);
+                match self.field_owner[row as usize - 1] {
+                    0 => name,
+                    owner => format!("{}::{}", self.type_name(make_token(TYPE_DEF, owner)), name),
+                }
+            }
+            MEMBER_REF if row >= 1 && row <= self.rows(MEMBER_REF) => {
+                let name = self.str_cell(MEMBER_REF, row, 1);
+                let parent = match self.coded_cell(MEMBER_REF, row, 0) {
+                    Some(p) if token_table(p) == MODULE_REF => {
+                        self.str_cell(MODULE_REF, token_row(p), 0)
+                    }
+                    Some(p) if token_table(p) == METHOD_DEF => return self.token_name(p),
+                    Some(p) => self.type_name(p),
+                    None => return name,
+                };
+                format!("{}::{}", parent, name)
+            }
+            METHOD_SPEC if row >= 1 && row <= self.rows(METHOD_SPEC) => {
+                let method = self
+                    .coded_cell(METHOD_SPEC, row, 0)
+                    .map(|m| self.token_name(m))
+                    .unwrap_or_default();
+                let args = self
+                    .cell(METHOD_SPEC, row, 1)
+                    .and_then(|b| self.blob(b))
+                    .and_then(|sig| {
+                        // GENERICINST, count, types
+                        let mut p = 1;
+                        let n = compressed_u32(sig, &mut p)?;
+                        (0..n)
+                            .map(|_| self.sig_type(sig, &mut p, 1))
+                            .collect::<Option<Vec<_>>>()
+                    });
+                match args {
+                    Some(args) => format!("{}<{}>", method, args.join(", ")),
+                    None => method,
+                }
+            }
+            TYPE_DEF | TYPE_REF | TYPE_SPEC => self.type_name(token),
+            _ => format!("{:#010x}", token),
+        }
+    }
+
+    /// One type from a signature blob (II.23.2.12), in ILDasm's spelling.
+    fn sig_type(&self, sig: &[u8], p: &mut usize, depth: usize) -> Option<String> {
+        if depth > MAX_DEPTH {
+            return None;
+        }
+        let elem = *sig.get(*p)?;
+        *p += 1;
+        let prim = match elem {
+            0x01 => "void",
+            0x02 => "bool",
+            0x03 => "char",
+            0x04 => "int8",
+            0x05 => "uint8",
+            0x06 => "int16",
+            0x07 => "uint16",
+            0x08 => "int32",
+            0x09 => "uint32",
+            0x0A => "int64",
+            0x0B => "uint64",
+            0x0C => "float32",
+            0x0D => "float64",
+            0x0E => "string",
+            0x16 => "typedref",
+            0x18 => "native int",
+            0x19 => "native uint",
+            0x1C => "object",
+            _ => "",
+        };
+        if !prim.is_empty() {
+            return Some(prim.to_string());
+        }
+        let type_def_or_ref = |p: &mut usize| -> Option<String> {
+            let coded = compressed_u32(sig, p)?;
+            let token = Self::decode_coded(coded, TYPE_DEF_OR_REF)?;
+            Some(self.type_name_depth(token, depth))
+        };
+        Some(match elem {
+            0x0F => format!("{}*", self.sig_type(sig, p, depth + 1)?),
+            0x10 => format!("{}&", self.sig_type(sig, p, depth + 1)?),
+            0x11 | 0x12 => type_def_or_ref(p)?,
+            0x13 => format!("!{}", compressed_u32(sig, p)?),
+            0x1E => format!("!!{}", compressed_u32(sig, p)?),
+            0x14 => {
+                // ARRAY type rank numSizes sizes... numLoBounds loBounds...
+                let inner = self.sig_type(sig, p, depth + 1)?;
+                let rank = compressed_u32(sig, p)?;
+                for _ in 0..compressed_u32(sig, p)? {
+                    compressed_u32(sig, p)?;
+                }
+                for _ in 0..compressed_u32(sig, p)? {
+                    compressed_u32(sig, p)?;
+                }
+                format!("{}[{}]", inner, ",".repeat(rank.saturating_sub(1) as usize))
+            }
+            0x15 => {
+                let generic = self.sig_type(sig, p, depth + 1)?;
+                let n = compressed_u32(sig, p)?;
+                let args = (0..n)
+                    .map(|_| self.sig_type(sig, p, depth + 1))
+                    .collect::<Option<Vec<_>>>()?;
+                format!("{}<{}>", generic, args.join(", "))
+            }
+            0x1B => "method".to_string(),
+            0x1D => format!("{}[]", self.sig_type(sig, p, depth + 1)?),
+            // Custom modifiers and `pinned` prefix the type they qualify.
+            0x1F | 0x20 => {
+                type_def_or_ref(p)?;
+                self.sig_type(sig, p, depth + 1)?
+            }
+            0x45 => self.sig_type(sig, p, depth + 1)?,
+            _ => return None,
+        })
+    }
+
+    /// `Type::Member` names of every MemberRef row: the external methods and
+    /// fields the assembly's IL and attributes reference.
+    pub fn member_refs(&self) -> Vec<String> {
+        (1..=self.rows(MEMBER_REF))
+            .map(|r| self.token_name(make_token(MEMBER_REF, r)))
+            .collect()
+    }
+
+    /// P/Invoke declarations from the ImplMap table.
+    pub fn pinvokes(&self) -> Vec<CilPInvoke> {
+        let mut out = Vec::new();
+        for r in 1..=self.rows(IMPL_MAP) {
+            let Some(member) = self.coded_cell(IMPL_MAP, r, 1) else {
+                continue;
+            };
+            if token_table(member) != METHOD_DEF {
+                continue;
+            }
+            let module = self
+                .cell(IMPL_MAP, r, 3)
+                .map(|m| self.str_cell(MODULE_REF, m, 0))
+                .unwrap_or_default();
+            let mut entry = self.str_cell(IMPL_MAP, r, 2);
+            if entry.is_empty() {
+                entry = self.str_cell(METHOD_DEF, token_row(member), 3);
+            }
+            out.push(CilPInvoke {
+                token: member,
+                method: self.token_name(member),
+                entry,
+                module,
+            });
+        }
+        out
+    }
+}
+
+/// Nesting limit for type names and signatures.
+const MAX_DEPTH: usize = 16;
+
+fn qualify(namespace: &str, name: &str) -> String {
+    if namespace.is_empty() {
+        name.to_string()
+    } else {
+        format!("{}.{}", namespace, name)
+    }
+}
+
+/// Compressed unsigned integer (II.23.2) at `*p`.
+pub(crate) fn compressed_u32(buf: &[u8], p: &mut usize) -> Option<u32> {
+    let b0 = *buf.get(*p)? as u32;
+    let (len, v) = match b0 {
+        b if b & 0x80 == 0 => (1, b),
+        b if b & 0xC0 == 0x80 => (2, b & 0x3F),
+        b if b & 0xE0 == 0xC0 => (4, b & 0x1F),
+        _ => return None,
+    };
+    let rest = buf.get(*p + 1..*p + len)?;
+    *p += len;
+    Some(rest.iter().fold(v, |acc, &b| acc << 8 | b as u32))
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -430,6 +1101,47 @@ mod tests {
         );
     }
 
+    #[test]
+    fn decodes_compressed_integers() {
+        let buf = [0x03, 0x80, 0x80, 0xC0, 0x00, 0x40, 0x00, 0xFF];
+        let mut p = 0;
+        assert_eq!(compressed_u32(&buf, &mut p), Some(0x03));
+        assert_eq!(compressed_u32(&buf, &mut p), Some(0x80));
+        assert_eq!(compressed_u32(&buf, &mut p), Some(0x4000));
+        assert_eq!(p, 7);
+        assert_eq!(compressed_u32(&buf, &mut p), None);
+    }
+
+    #[test]
+    fn decodes_coded_indexes() {
+        // TypeDefOrRef: 2 tag bits; tag 1 is TypeRef.
+        assert_eq!(
+            CilMetadata::decode_coded(5 << 2 | 1, TYPE_DEF_OR_REF),
+            Some(0x0100_0005)
+        );
+        // HasCustomAttribute: 22 tables, 5 tag bits; tag 21 is MethodSpec.
+        assert_eq!(
+            CilMetadata::decode_coded(2 << 5 | 21, HAS_CUSTOM_ATTRIBUTE),
+            Some(0x2B00_0002)
+        );
+        // CustomAttributeType tags 0, 1, and 4 are unused.
+        assert_eq!(
+            CilMetadata::decode_coded(1 << 3, CUSTOM_ATTRIBUTE_TYPE),
+            None
+        );
+        assert_eq!(
+            CilMetadata::decode_coded(7 << 3 | 3, CUSTOM_ATTRIBUTE_TYPE),
+            Some(0x0A00_0007)
+        );
+    }
+
+    #[test]
+    fn schema_covers_every_assembly_table() {
+        assert_eq!(SCHEMA.len(), METHOD_SPEC as usize + 2);
+        assert_eq!(SCHEMA[IMPL_MAP as usize].len(), 4);
+        assert_eq!(SCHEMA[NESTED_CLASS as usize].len(), 2);
+    }
+
     #[test]
     fn errors_on_non_dotnet_pe() {
         let path = Path::new(
@@ -0,0 +1,605 @@
+//! Embedded configuration carving.
+//!
+//! Implants, loaders and droppers carry their settings (C2 hosts, ports,
+//! campaign ids, sleep intervals, mutex names) as a structured blob inside
+//! the binary: in the clear as length-prefixed key/value records, or
+//! encrypted with XOR or RC4 next to a recognizable marker. The generic
+//! extractors here cover those layouts; family-specific decoders implement
+//! `ConfigExtractor` and are registered on a `ConfigCarver` alongside them.
+//! Results from an extractor that names a family take precedence over
+//! generic results covering the same bytes.
+
+use serde::{Deserialize, Serialize};
+
+/// Markers that commonly precede an embedded settings block.
+pub const DEFAULT_MARKERS: &[&[u8]] = &[
+    b"CONFIG",
+    b"config",
+    b"SETTINGS",
+    b"settings",
+    b"[CFG]",
+    b"CFG:",
+    b"<cfg>",
+];
+
+/// Bytes examined after each marker.
+const MAX_BLOCK: usize = 4096;
+
+/// Shortest decoded text accepted as a configuration.
+const MIN_TEXT: usize = 16;
+
+/// Fewest key/value pairs that make a region a configuration.
+const MIN_PAIRS: usize = 3;
+
+/// Longest accepted key, in bytes. Below 0x20 an 8-bit length byte is
+/// never itself text, so runs inside strings and base64 do not chain.
+const MAX_KEY: usize = 31;
+
+/// Longest accepted value, in bytes.
+const MAX_VALUE: usize = 1024;
+
+/// Fewest key and value bytes in a run of length-prefixed records.
+const MIN_RECORD_TEXT: usize = 24;
+
+/// Occurrences of one marker examined per input.
+const MAX_MARKER_HITS: usize = 256;
+
+/// Upper bound on configurations reported per input.
+const MAX_CONFIGS: usize = 64;
+
+/// One setting from a decoded configuration.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ConfigEntry {
+    pub key: String,
+    pub value: String,
+}
+
+impl ConfigEntry {
+    fn new(key: impl Into<String>, value: impl Into<String>) -> Self {
+        Self {
+            key: key.into(),
+            value: value.into(),
+        }
+    }
+}
+
+/// A configuration blob located and decoded in the input.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct EmbeddedConfig {
+    /// Name of the extractor that produced it.
+    pub extractor: String,
+    /// Malware or tool family, for family-specific extractors.
+    pub family: Option<String>,
+    /// File offset of the (encoded) blob.
+    pub offset: u64,
+    /// Length of the blob in bytes.
+    pub length: u64,
+    /// How the blob was stored: `plain`, `xor`, or `rc4`.
+    pub encoding: String,
+    /// Decryption key in hex, for encrypted blobs.
+    pub key: Option<String>,
+    /// Settings in blob order; keys may repeat (e.g. several C2 hosts).
+    pub entries: Vec<ConfigEntry>,
+}
+
+impl EmbeddedConfig {
+    fn end(&self) -> u64 {
+        self.offset + self.length
+    }
+
+    /// Value of the first entry named `key`.
+    pub fn get(&self, key: &str) -> Option<&str> {
+        self.entries
+            .iter()
+            .find(|e| e.key == key)
+            .map(|e| e.value.as_str())
+    }
+}
+
+/// A decoder for one configuration layout or family.
+pub trait ConfigExtractor: Send + Sync {
+    /// Name recorded in `EmbeddedConfig::extractor`.
+    fn name(&self) -> &'static str;
+
+    /// Locate and decode configurations in `data`.
+    fn extract(&self, data: &[u8]) -> Vec<EmbeddedConfig>;
+}
+
+/// Runs a set of extractors and merges their results.
+pub struct ConfigCarver {
+    extractors: Vec<Box<dyn ConfigExtractor>>,
+}
+
+impl Default for ConfigCarver {
+    fn default() -> Self {
+        Self::with_builtin()
+    }
+}
+
+impl ConfigCarver {
+    /// Carver without extractors.
+    pub fn new() -> Self {
+        Self {
+            extractors: Vec::new(),
+        }
+    }
+
+    /// Carver with the generic extractors.
+    pub fn with_builtin() -> Self {
+        let mut carver = Self::new();
+        carver.register(Box::new(LengthPrefixedRecords));
+        carver.register(Box::new(MarkerBlocks::default()));
+        carver
+    }
+
+    /// Add an extractor.
+    pub fn register(&mut self, extractor: Box<dyn ConfigExtractor>) -> &mut Self {
+        self.extractors.push(extractor);
+        self
+    }
+
+    /// Names of the registered extractors, in registration order.
+    pub fn names(&self) -> Vec<&'static str> {
+        self.extractors.iter().map(|e| e.name()).collect()
+    }
+
+    /// Run every extractor over `data`; results are ordered by offset and
+    /// never overlap.
+    pub fn carve(&self, data: &[u8]) -> Vec<EmbeddedConfig> {
+        let mut found: Vec<EmbeddedConfig> = self
+            .extractors
+            .iter()
+            .flat_map(|e| e.extract(data))
+            .collect();
+        // Family-specific results first, then larger blobs.
+        found.sort_by_key(|c| (c.family.is_none(), c.offset, std::cmp::Reverse(c.length)));
+        let mut kept: Vec<EmbeddedConfig> = Vec::new();
+        for c in found {
+            if !kept
+                .iter()
+                .any(|k| c.offset < k.end() && k.offset < c.end())
+            {
+                kept.push(c);
+            }
+        }
+        kept.sort_by_key(|c| c.offset);
+        kept.truncate(MAX_CONFIGS);
+        kept
+    }
+}
+
+/// Carve `data` with the generic extractors.
+pub fn carve_configs(data: &[u8]) -> Vec<EmbeddedConfig> {
+    ConfigCarver::with_builtin().carve(data)
+}
+
+fn is_text(b: u8) -> bool {
+    b.is_ascii_graphic() || matches!(b, b' ' | b'\t' | b'\r' | b'\n')
+}
+
+/// Setting names: identifier-like, at least two characters, and not a
+/// bare hex digest (hash tables are full of length-prefixed hex strings).
+fn is_key(bytes: &[u8]) -> bool {
+    bytes.len() >= 2
+        && bytes.len() <= MAX_KEY
+        && !(bytes.len() > 8 && bytes.iter().all(u8::is_ascii_hexdigit))
+        && is_name(bytes)
+}
+
+/// Identifier-like text of any length (`c2x-extensions`, `aad`).
+fn is_name(bytes: &[u8]) -> bool {
+    bytes.first().is_some_and(u8::is_ascii_alphabetic)
+        && bytes
+            .iter()
+            .all(|&b| b.is_ascii_alphanumeric() || matches!(b, b'_' | b'-' | b'.'))
+}
+
+/// Values that only settings carry: numbers, addresses, paths.
+fn is_setting_value(v: &str) -> bool {
+    !is_name(v.as_bytes())
+        && v.bytes()
+            .any(|b| b.is_ascii_digit() || matches!(b, b'.' | b':' | b'/' | b'\\' | b'@' | b','))
+}
+
+fn distinct_keys(entries: &[ConfigEntry]) -> usize {
+    let mut keys: Vec<&str> = entries.iter().map(|e| e.key.as_str()).collect();
+    keys.sort_unstable();
+    keys.dedup();
+    keys.len()
+}
+
+fn hex(bytes: &[u8]) -> String {
+    bytes.iter().map(|b| format!("{b:02x}")).collect()
+}
+
+/// Clear-text runs of `<len><key><len><value>` records, with 8-, 16- or
+/// 32-bit little-endian lengths.
+pub struct LengthPrefixedRecords;
+
+impl LengthPrefixedRecords {
+    fn read_len(data: &[u8], at: usize, width: usize) -> Option<usize> {
+        let b = data.get(at..at + width)?;
+        Some(match width {
+            1 => b[0] as usize,
+            2 => u16::from_le_bytes([b[0], b[1]]) as usize,
+            _ => u32::from_le_bytes([b[0], b[1], b[2], b[3]]) as usize,
+        })
+    }
+
+    /// One record at `at`: (key, value, next offset).
+    fn record(data: &[u8], at: usize, width: usize) -> Option<(String, String, usize)> {
+        let klen = Self::read_len(data, at, width)?;
+        let kstart = at + width;
+        let key = data.get(kstart..kstart + klen)?;
+        if !is_key(key) {
+            return None;
+        }
+        let vlen_at = kstart + klen;
+        let vlen = Self::read_len(data, vlen_at, width)?;
+        if vlen > MAX_VALUE {
+            return None;
+        }
+        let vstart = vlen_at + width;
+        let value = data.get(vstart..vstart + vlen)?;
+        // Single-line values: in newline-separated text a `\n` reads as a
+        // length byte and the following lines as values.
+        if !value.iter().all(|&b| b.is_ascii_graphic() || b == b' ') {
+            return None;
+        }
+        Some((
+            String::from_utf8_lossy(key).into_owned(),
+            String::from_utf8_lossy(value).into_owned(),
+            vstart + vlen,
+        ))
+    }
+
+    fn run_at(data: &[u8], start: usize, width: usize) -> Option<(Vec<ConfigEntry>, usize)> {
+        let mut entries = Vec::new();
+        let mut at = start;
+        while let Some((k, v, next)) = Self::record(data, at, width) {
+            entries.push(ConfigEntry::new(k, v));
+            at = next;
+        }
+        let valued = entries.iter().filter(|e| !e.value.is_empty()).count();
+        // Lookup tables of short fixed-width names chain up just as well;
+        // a configuration has real values and some longer names.
+        // Pascal-string name tables (mnemonics, option names) chain up just
+        // as well; a configuration holds some numbers, addresses or paths.
+        let text: usize = entries.iter().map(|e| e.key.len() + e.value.len()).sum();
+        let substantial = text >= MIN_RECORD_TEXT
+            && entries.iter().any(|e| e.key.len() > 2)
+            && entries.iter().any(|e| is_setting_value(&e.value));
+        (distinct_keys(&entries) >= MIN_PAIRS && valued >= MIN_PAIRS && substantial)
+            .then_some((entries, at))
+    }
+}
+
+impl ConfigExtractor for LengthPrefixedRecords {
+    fn name(&self) -> &'static str {
+        "length_prefixed"
+    }
+
+    fn extract(&self, data: &[u8]) -> Vec<EmbeddedConfig> {
+        let mut out = Vec::new();
+        let mut at = 0;
+        while at < data.len() && out.len() < MAX_CONFIGS {
+            // Wider prefixes first: a 32-bit length also parses as an 8-bit
+            // length followed by NULs only when the key is empty, which
+            // `is_key` rejects.
+            let hit = [4, 2, 1]
+                .into_iter()
+                .find_map(|w| Self::run_at(data, at, w));
+            match hit {
+                Some((entries, end)) => {
+                    out.push(EmbeddedConfig {
+                        extractor: self.name().to_string(),
+                        family: None,
+                        offset: at as u64,
+                        length: (end - at) as u64,
+                        encoding: "plain".to_string(),
+                        key: None,
+                        entries,
+                    });
+                    at = end;
+                }
+                None => at += 1,
+            }
+        }
+        out
+    }
+}
+
+/// Parse decoded text as a configuration: a JSON object, `key=value` /
+/// `key: value` lines (INI sections are skipped), or `;`/`|`/`&`-separated
+/// `key=value` pairs.
+pub fn parse_text_config(text: &str) -> Option<Vec<ConfigEntry>> {
+    let text = text.trim();
+    if text.starts_with('{') {
+        if let Ok(serde_json::Value::Object(map)) = serde_json::from_str(text) {
+            let entries: Vec<ConfigEntry> = map
+                .into_iter()
+                .map(|(k, v)| match v {
+                    serde_json::Value::String(s) => ConfigEntry::new(k, s),
+                    other => ConfigEntry::new(k, other.to_string()),
+                })
+                .collect();
+            return (distinct_keys(&entries) >= MIN_PAIRS).then_some(entries);
+        }
+    }
+    let fields: Vec<&str> = text
+        .split(['\n', ';', '|', '&'])
+        .map(str::trim)
+        .filter(|f| !f.is_empty() && !(f.starts_with('[') && f.ends_with(']')))
+        .collect();
+    let entries: Vec<ConfigEntry> = fields
+        .iter()
+        .filter_map(|f| {
+            let (k, v) = f.split_once('=').or_else(|| f.split_once(':'))?;
+            let k = k.trim();
+            is_key(k.as_bytes()).then(|| ConfigEntry::new(k, v.trim()))
+        })
+        .collect();
+    // Most fields must be settings, not prose that happens to hold a colon.
+    (distinct_keys(&entries) >= MIN_PAIRS && entries.len() * 4 >= fields.len() * 3)
+        .then_some(entries)
+}
+
+/// Longest run of text bytes at the start of `plain`.
+fn text_prefix(plain: &[u8]) -> &[u8] {
+    let n = plain
+        .iter()
+        .position(|&b| !is_text(b))
+        .unwrap_or(plain.len());
+    &plain[..n]
+}
+
+fn rc4(key: &[u8], data: &[u8]) -> Vec<u8> {
+    let mut s: [u8; 256] = std::array::from_fn(|i| i as u8);
+    let mut j = 0u8;
+    for i in 0..256 {
+        j = j.wrapping_add(s[i]).wrapping_add(key[i % key.len()]);
+        s.swap(i, j as usize);
+    }
+    let (mut i, mut j) = (0u8, 0u8);
+    data.iter()
+        .map(|&b| {
+            i = i.wrapping_add(1);
+            j = j.wrapping_add(s[i as usize]);
+            s.swap(i as usize, j as usize);
+            b ^ s[s[i as usize].wrapping_add(s[j as usize]) as usize]
+        })
+        .collect()
+}
+
+/// Settings blocks that follow a marker string, stored in the clear or,
+/// when the bytes are not text, XORed with a single byte or with the
+/// marker, or RC4-encrypted with the marker or with a key stored between
+/// marker and ciphertext (either prefixed by its length byte or as a raw
+/// 16-byte key).
+pub struct MarkerBlocks {
+    pub markers: Vec<Vec<u8>>,
+}
+
+impl Default for MarkerBlocks {
+    fn default() -> Self {
+        Self {
+            markers: DEFAULT_MARKERS.iter().map(|m| m.to_vec()).collect(),
+        }
+    }
+}
+
+/// A decoding of the bytes after a marker: (blob start, encoding, key,
+/// plaintext).
+type Candidate = (usize, &'static str, Option<Vec<u8>>, Vec<u8>);
+
+impl MarkerBlocks {
+    fn candidates(data: &[u8], marker: &[u8], after: usize) -> Vec<Candidate> {
+        let block = |start: usize| &data[start.min(data.len())..data.len().min(start + MAX_BLOCK)];
+        let body = block(after);
+        let mut out: Vec<Candidate> = vec![(after, "plain", None, body.to_vec())];
+        // Text already; XOR with a small key would turn its spaces and
+        // punctuation into `word=word` noise.
+        if text_prefix(body).len() >= MIN_TEXT {
+            return out;
+        }
+        // Single-byte XOR: decode only the part that comes out as text.
+        for k in 1..=255u8 {
+            let n = body.iter().take_while(|&&b| is_text(b ^ k)).count();
+            if n >= MIN_TEXT {
+                out.push((
+                    after,
+                    "xor",
+                    Some(vec![k]),
+                    body[..n].iter().map(|&b| b ^ k).collect(),
+                ));
+            }
+        }
+        out.push((
+            after,
+            "xor",
+            Some(marker.to_vec()),
+            body.iter()
+                .zip(marker.iter().cycle())
+                .map(|(&b, &k)| b ^ k)
+                .collect(),
+        ));
+        out.push((after, "rc4", Some(marker.to_vec()), rc4(marker, body)));
+        if let Some(&klen) = data.get(after) {
+            let klen = klen as usize;
+            if (4..=64).contains(&klen) && after + 1 + klen < data.len() {
+                let key = &data[after + 1..after + 1 + klen];
+                let start = after + 1 + klen;
+                out.push((start, "rc4", Some(key.to_vec()), rc4(key, block(start))));
+            }
+        }
+        if after + 16 < data.len() {
+            let key = &data[after..after + 16];
+            let start = after + 16;
+            out.push((start, "rc4", Some(key.to_vec()), rc4(key, block(start))));
+        }
+        out
+    }
+}
+
+impl ConfigExtractor for MarkerBlocks {
+    fn name(&self) -> &'static str {
+        "marker_block"
+    }
+
+    fn extract(&self, data: &[u8]) -> Vec<EmbeddedConfig> {
+        let mut out = Vec::new();
+        for marker in self.markers.iter().filter(|m| !m.is_empty()) {
+            for pos in memchr::memmem::find_iter(data, marker).take(MAX_MARKER_HITS) {
+                if out.len() >= MAX_CONFIGS {
+                    return out;
+                }
+                let after = pos + marker.len();
+                // Skip separators between marker and block (`CONFIG=`, `CFG: `).
+                let after = after
+                    + data[after..]
+                        .iter()
+                        .take(2)
+                        .take_while(|&&b| matches!(b, b'=' | b':' | b' ' | 0))
+                        .count();
+                let decoded = Self::candidates(data, marker, after).into_iter().find_map(
+                    |(start, encoding, key, plain)| {
+                        let text = text_prefix(&plain);
+                        if text.len() < MIN_TEXT {
+                            return None;
+                        }
+                        let entries = parse_text_config(&String::from_utf8_lossy(text))?;
+                        // A wrong key over text still decodes to letters and
+                        // punctuation; real settings carry numbers or paths.
+                        if encoding != "plain"
+                            && !entries.iter().any(|e| is_setting_value(&e.value))
+                        {
+                            return None;
+                        }
+                        Some((start, text.len(), encoding, key, entries))
+                    },
+                );
+                if let Some((start, len, encoding, key, entries)) = decoded {
+                    out.push(EmbeddedConfig {
+                        extractor: self.name().to_string(),
+                        family: None,
+                        offset: start as u64,
+                        length: len as u64,
+                        encoding: encoding.to_string(),
+                        key: key.map(|k| hex(&k)),
+                        entries,
+                    });
+                }
+            }
+        }
+        out
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn records(width: usize, pairs: &[(&str, &str)]) -> Vec<u8> {
+        let mut v = Vec::new();
+        for (k, val) in pairs {
+            for field in [k.as_bytes(), val.as_bytes()] {
+                v.extend_from_slice(&(field.len() as u32).to_le_bytes()[..width]);
+                v.extend_from_slice(field);
+            }
+        }
+        v
+    }
+
+    #[test]
+    fn length_prefixed_records_in_the_clear() {
+        let pairs = [
+            ("host", "c2.example.net"),
+            ("port", "8443"),
+            ("sleep", "60"),
+        ];
+        for width in [1, 2, 4] {
+            let mut data = vec![0xccu8; 37];
+            data.extend(records(width, &pairs));
+            data.extend([0xcc; 8]);
+            let found = carve_configs(&data);
+            assert_eq!(found.len(), 1, "width {width}");
+            assert_eq!(found[0].offset, 37);
+            assert_eq!(found[0].encoding, "plain");
+            assert_eq!(found[0].get("host"), Some("c2.example.net"));
+            assert_eq!(found[0].entries.len(), 3);
+        }
+    }
+
+    #[test]
+    fn xor_and_rc4_blocks_after_markers() {
+        let text = b"host=10.1.2.3\nport=443\nmutex=Global\\qq\n\0";
+        let mut data = vec![0x90u8; 64];
+        data.extend_from_slice(b"CONFIG");
+        data.extend(text.iter().map(|b| b ^ 0xa5));
+        data.extend([0x90; 32]);
+        let found = carve_configs(&data);
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0].encoding, "xor");
+        assert_eq!(found[0].key.as_deref(), Some("a5"));
+        assert_eq!(found[0].offset, 70);
+        assert_eq!(found[0].get("mutex"), Some("Global\\qq"));
+
+        let key = b"s3cr3tk3y";
+        let json = br#"{"c2":["a.example","b.example"],"port":8080,"id":"campaign-7"}"#;
+        let mut data = vec![0u8; 16];
+        data.extend_from_slice(b"SETTINGS");
+        data.push(key.len() as u8);
+        data.extend_from_slice(key);
+        data.extend(rc4(key, json));
+        let found = carve_configs(&data);
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0].encoding, "rc4");
+        assert_eq!(found[0].key.as_deref(), Some(hex(key).as_str()));
+        assert_eq!(found[0].get("port"), Some("8080"));
+        assert_eq!(found[0].get("c2"), Some(r#"["a.example","b.example"]"#));
+    }
+
+    struct Fixed;
+
+    impl ConfigExtractor for Fixed {
+        fn name(&self) -> &'static str {
+            "fixed"
+        }
+
+        fn extract(&self, data: &[u8]) -> Vec<EmbeddedConfig> {
+            let Some(at) = memchr::memmem::find(data, b"\x04host") else {
+                return Vec::new();
+            };
+            vec![EmbeddedConfig {
+                extractor: self.name().to_string(),
+                family: Some("Example".to_string()),
+                offset: at as u64,
+                length: 5,
+                encoding: "plain".to_string(),
+                key: None,
+                entries: vec![ConfigEntry::new("family_field", "x")],
+            }]
+        }
+    }
+
+    #[test]
+    fn family_extractors_win_over_generic_overlaps() {
+        let data = records(1, &[("host", "h"), ("port", "1"), ("user", "u")]);
+        let mut carver = ConfigCarver::with_builtin();
+        carver.register(Box::new(Fixed));
+        assert_eq!(carver.names(), ["length_prefixed", "marker_block", "fixed"]);
+        let found = carver.carve(&data);
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0].family.as_deref(), Some("Example"));
+    }
+
+    #[test]
+    fn prose_and_noise_are_not_configs() {
+        assert!(parse_text_config("Usage: tool [options]\nNote: see docs for more").is_none());
+        let noise: Vec<u8> = (0..4096u32)
+            .map(|i| (i.wrapping_mul(2654435761) >> 13) as u8)
+            .collect();
+        assert!(carve_configs(&noise).is_empty());
+        assert!(carve_configs(b"CONFIG").is_empty());
+    }
+}
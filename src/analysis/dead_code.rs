@@ -0,0 +1,440 @@
+//! Unreferenced and unreachable function detection.
+//!
+//! A discovered function is *referenced* when another function calls or
+//! jumps to it, takes its address in code (a RIP-relative `lea`, an
+//! `adrp`/`add` pair, an absolute `mov`), or a pointer to it sits in
+//! initialised data (vtables, callback tables, relocated pointer slots).
+//! It is a *root* when the loader or another module can enter it without
+//! any such reference: the image entry point, exported symbols, ELF
+//! init/fini arrays and `.init`/`.fini`, Mach-O module initialisers, and PE
+//! TLS callbacks.
+//!
+//! Everything reachable from the roots and the address-taken functions is
+//! live. The rest is reported as `Unreferenced` (nothing refers to it) or
+//! `Unreachable` (only other dead functions refer to it). Both are
+//! candidates, not verdicts: a function entered through a computed pointer
+//! the analysis did not resolve looks dead too, and that is exactly the
+//! obfuscated dispatch worth a second look.
+
+use std::collections::{HashMap, HashSet, VecDeque};
+
+use object::{Architecture, BinaryFormat, Object, ObjectSection, ObjectSegment};
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::mapped::{MappedImage, Region};
+use crate::analysis::xrefs::llir_to_data_xrefs;
+use crate::core::function::{Function, FunctionKind};
+use crate::ir::types::{CallTarget, LlirFunction, Op, Value};
+
+/// Longest init/fini pointer array read.
+const MAX_INIT_SLOTS: usize = 4096;
+
+/// How a function is entered without a reference from code.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum RootKind {
+    /// Image entry point
+    Entry,
+    /// Exported or dynamically visible symbol
+    Export,
+    /// ELF `.preinit_array`/`.init_array`/`.ctors`/`.init`, Mach-O module
+    /// initialiser
+    Initializer,
+    /// ELF `.fini_array`/`.dtors`/`.fini`, Mach-O module terminator
+    Finalizer,
+    /// PE TLS callback
+    TlsCallback,
+}
+
+impl RootKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Entry => "entry",
+            Self::Export => "export",
+            Self::Initializer => "initializer",
+            Self::Finalizer => "finalizer",
+            Self::TlsCallback => "tls_callback",
+        }
+    }
+}
+
+/// Why a function is reported.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum Liveness {
+    /// No call, jump, code or data reference targets the function
+    Unreferenced,
+    /// Referenced, but only from functions that are themselves dead
+    Unreachable,
+}
+
+impl Liveness {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Unreferenced => "unreferenced",
+            Self::Unreachable => "unreachable",
+        }
+    }
+}
+
+/// A function no root reaches.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct DeadFunction {
+    pub va: u64,
+    pub name: String,
+    /// Size in bytes (0 if discovery did not bound the function)
+    pub size: u64,
+    pub status: Liveness,
+    /// Entry VAs of the dead functions referring to this one
+    pub referrers: Vec<u64>,
+}
+
+/// Load address of the Mach-O header (`__TEXT` vmaddr).
+fn macho_base(obj: &object::read::File<'_>) -> u64 {
+    obj.segments()
+        .find(|s| s.name().ok().flatten() == Some("__TEXT"))
+        .map_or(0, |s| s.address())
+}
+
+/// Mach-O images linked with chained fixups keep fixup metadata in the
+/// high bits of each rebased pointer; the low 36 bits hold the target as a
+/// VA (`DYLD_CHAINED_PTR_64`) or as an offset from the image base
+/// (`DYLD_CHAINED_PTR_64_OFFSET`).
+fn chained_target(raw: u64, base: u64) -> u64 {
+    if raw >> 36 == 0 {
+        return raw;
+    }
+    let target = raw & 0xf_ffff_ffff;
+    if target < base {
+        base + target
+    } else {
+        target
+    }
+}
+
+/// Pointer-sized value at `va` as the loader would see it, with Thumb bits
+/// and Mach-O chained-fixup metadata stripped.
+fn code_pointer(mem: &MappedImage<'_>, va: u64, macho: Option<u64>, thumb: bool) -> Option<u64> {
+    let mut p = mem.pointer(va)?;
+    if let Some(base) = macho {
+        p = chained_target(p, base);
+    }
+    Some(if thumb { p & !1 } else { p })
+}
+
+/// Functions the loader or another module enters directly: the entry
+/// point, exports, initialiser/finaliser arrays and sections, and TLS
+/// callbacks. Values are VAs; some may not be discovered function starts.
+pub fn loader_roots(data: &[u8]) -> Vec<(u64, RootKind)> {
+    let Ok(obj) = object::read::File::parse(data) else {
+        return Vec::new();
+    };
+    let Some(mut mem) = MappedImage::parse(data) else {
+        return Vec::new();
+    };
+    let thumb = obj.architecture() == Architecture::Arm;
+    let macho = (obj.format() == BinaryFormat::MachO).then(|| macho_base(&obj));
+    let strip = |va: u64| if thumb { va & !1 } else { va };
+    let mut out = Vec::new();
+
+    // `LC_MAIN` records the entry as an offset from the Mach-O header.
+    match obj.entry() {
+        0 => {}
+        e => out.push((strip(e + macho.unwrap_or(0)), RootKind::Entry)),
+    }
+    for exp in obj.exports().unwrap_or_default() {
+        if exp.address() != 0 {
+            out.push((strip(exp.address()), RootKind::Export));
+        }
+    }
+
+    let mut arrays = Vec::new();
+    for sec in obj.sections() {
+        let Ok(name) = sec.name() else { continue };
+        let kind = match name {
+            ".preinit_array" | ".init_array" | ".ctors" | "__mod_init_func" => {
+                RootKind::Initializer
+            }
+            ".fini_array" | ".dtors" | "__mod_term_func" => RootKind::Finalizer,
+            ".init" => {
+                out.push((sec.address(), RootKind::Initializer));
+                continue;
+            }
+            ".fini" => {
+                out.push((sec.address(), RootKind::Finalizer));
+                continue;
+            }
+            // 32-bit offsets from the image base (ld64 `-fixup_chains`).
+            "__init_offsets" => {
+                let base = macho.unwrap_or(0);
+                for chunk in sec.data().unwrap_or_default().chunks_exact(4) {
+                    let off = u32::from_le_bytes([chunk[0], chunk[1], chunk[2], chunk[3]]);
+                    out.push((base + off as u64, RootKind::Initializer));
+                }
+                continue;
+            }
+            _ => continue,
+        };
+        let Ok(bytes) = sec.data() else { continue };
+        // Init arrays are neither code nor data to `MappedImage`; map them
+        // so their relocated slots read back.
+        if mem.region(sec.address()).is_none() {
+            mem.regions.push(Region {
+                va: sec.address(),
+                bytes,
+                exec: false,
+            });
+        }
+        arrays.push((sec.address(), bytes.len(), kind));
+    }
+    let step = mem.ptr_size;
+    for (va, len, kind) in arrays {
+        for i in 0..(len / step).min(MAX_INIT_SLOTS) {
+            let slot = va + (i * step) as u64;
+            match code_pointer(&mem, slot, macho, thumb) {
+                // `.ctors`/`.dtors` are bracketed by 0 and -1 sentinels.
+                Some(p) if p != 0 && p != u64::MAX && p != u32::MAX as u64 => out.push((p, kind)),
+                _ => {}
+            }
+        }
+    }
+
+    if obj.format() == BinaryFormat::Pe {
+        let opts = crate::formats::pe::ParseOptions::default();
+        if let Ok(parser) = crate::formats::pe::PeParser::with_options(data, opts) {
+            if let Ok(tls) = parser.tls() {
+                out.extend(tls.callbacks.iter().map(|&va| (va, RootKind::TlsCallback)));
+            }
+        }
+    }
+    out.sort_unstable_by_key(|(va, kind)| (*va, kind.as_str()));
+    out.dedup_by_key(|(va, _)| *va);
+    out
+}
+
+/// Members of `entries` whose address is stored in initialised data as a
+/// pointer-aligned, pointer-sized value (after dynamic relocations).
+pub fn data_code_pointers(data: &[u8], entries: &HashSet<u64>) -> HashSet<u64> {
+    let Ok(obj) = object::read::File::parse(data) else {
+        return HashSet::new();
+    };
+    let Some(mem) = MappedImage::parse(data) else {
+        return HashSet::new();
+    };
+    let thumb = obj.architecture() == Architecture::Arm;
+    let macho = (obj.format() == BinaryFormat::MachO).then(|| macho_base(&obj));
+    mem.scan(mem.ptr_size as u64)
+        .filter_map(|va| code_pointer(&mem, va, macho, thumb))
+        .filter(|p| entries.contains(p))
+        .collect()
+}
+
+fn const_addr(v: &Value) -> Option<u64> {
+    match v {
+        Value::Addr(a) => Some(*a),
+        Value::Const(c) if *c > 0 => Some(*c as u64),
+        _ => None,
+    }
+}
+
+/// Members of `entries` other than its own that `lf` calls, jumps to, or
+/// materialises as an address. `code_ranges` bounds the pointer tracking
+/// of `xrefs::llir_to_data_xrefs` (adrp pairs, pointers moved through
+/// registers).
+pub fn llir_code_refs(
+    lf: &LlirFunction,
+    code_ranges: &[(u64, u64)],
+    entries: &HashSet<u64>,
+) -> Vec<u64> {
+    let mut out: Vec<u64> = llir_to_data_xrefs(lf, code_ranges, 64, usize::MAX)
+        .into_iter()
+        .map(|x| x.to.value)
+        .collect();
+    for ins in lf.blocks.iter().flat_map(|b| &b.instrs) {
+        match &ins.op {
+            Op::Call {
+                target: CallTarget::Direct(t),
+            }
+            | Op::Jump { target: t }
+            | Op::CondJump { target: t, .. } => out.push(*t),
+            Op::Assign { src, .. } | Op::CondAssign { src, .. } | Op::Store { src, .. } => {
+                out.extend(const_addr(src))
+            }
+            _ => {}
+        }
+    }
+    out.retain(|t| *t != lf.entry_va && entries.contains(t));
+    out.sort_unstable();
+    out.dedup();
+    out
+}
+
+/// Functions of `funcs` not reachable over `edges` (caller entry, callee
+/// entry) from any entry in `live`. Imports and thunks are never reported:
+/// an unused stub says nothing about the code.
+pub fn find_dead_functions(
+    funcs: &[Function],
+    edges: &[(u64, u64)],
+    live: &HashSet<u64>,
+) -> Vec<DeadFunction> {
+    let mut succs: HashMap<u64, Vec<u64>> = HashMap::new();
+    let mut preds: HashMap<u64, Vec<u64>> = HashMap::new();
+    for &(from, to) in edges {
+        if from != to {
+            succs.entry(from).or_default().push(to);
+            preds.entry(to).or_default().push(from);
+        }
+    }
+    let mut queue: VecDeque<u64> = funcs
+        .iter()
+        .map(|f| f.entry_point.value)
+        .filter(|va| live.contains(va))
+        .collect();
+    let mut reached: HashSet<u64> = queue.iter().copied().collect();
+    while let Some(va) = queue.pop_front() {
+        for &next in succs.get(&va).into_iter().flatten() {
+            if reached.insert(next) {
+                queue.push_back(next);
+            }
+        }
+    }
+
+    let mut out: Vec<DeadFunction> = funcs
+        .iter()
+        .filter(|f| !matches!(f.kind, FunctionKind::Imported | FunctionKind::Thunk))
+        .filter(|f| !reached.contains(&f.entry_point.value))
+        .map(|f| {
+            let va = f.entry_point.value;
+            let mut referrers = preds.get(&va).cloned().unwrap_or_default();
+            referrers.sort_unstable();
+            referrers.dedup();
+            DeadFunction {
+                va,
+                name: f.name.clone(),
+                size: f.size.unwrap_or(0),
+                status: if referrers.is_empty() {
+                    Liveness::Unreferenced
+                } else {
+                    Liveness::Unreachable
+                },
+                referrers,
+            }
+        })
+        .collect();
+    out.sort_by_key(|d| d.va);
+    out.dedup_by_key(|d| d.va);
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::core::address::{Address, AddressKind};
+    use crate::ir::types::{LlirBlock, LlirInstr, VReg};
+
+    fn func(name: &str, va: u64, kind: FunctionKind) -> Function {
+        let entry = Address::new(AddressKind::VA, va, 64, None, None).unwrap();
+        Function::new(name.to_string(), entry, kind).unwrap()
+    }
+
+    #[test]
+    fn reachability_separates_unreferenced_from_unreachable() {
+        let funcs = vec![
+            func("main", 0x1000, FunctionKind::Normal),
+            func("helper", 0x1100, FunctionKind::Normal),
+            func("callback", 0x1200, FunctionKind::Normal),
+            func("orphan", 0x1300, FunctionKind::Normal),
+            func("orphan_child", 0x1400, FunctionKind::Normal),
+            func("recursive", 0x1500, FunctionKind::Normal),
+            func("puts@plt", 0x1600, FunctionKind::Thunk),
+        ];
+        let edges = [
+            (0x1000, 0x1100),
+            (0x1000, 0x1600),
+            (0x1300, 0x1400),
+            (0x1400, 0x1300),
+            (0x1500, 0x1500),
+        ];
+        let live: HashSet<u64> = [0x1000, 0x1200].into_iter().collect();
+        let dead = find_dead_functions(&funcs, &edges, &live);
+        let got: Vec<_> = dead
+            .iter()
+            .map(|d| (d.name.as_str(), d.status, d.referrers.clone()))
+            .collect();
+        assert_eq!(
+            got,
+            [
+                ("orphan", Liveness::Unreachable, vec![0x1400]),
+                ("orphan_child", Liveness::Unreachable, vec![0x1300]),
+                ("recursive", Liveness::Unreferenced, vec![]),
+            ]
+        );
+    }
+
+    #[test]
+    fn llir_refs_cover_calls_jumps_and_taken_addresses() {
+        let entries: HashSet<u64> = [0x1000, 0x2000, 0x3000, 0x4000, 0x5000]
+            .into_iter()
+            .collect();
+        let ins = |va, op| LlirInstr { va, op };
+        let lf = LlirFunction {
+            entry_va: 0x1000,
+            blocks: vec![LlirBlock {
+                start_va: 0x1000,
+                end_va: 0x1020,
+                instrs: vec![
+                    ins(
+                        0x1000,
+                        Op::Call {
+                            target: CallTarget::Direct(0x2000),
+                        },
+                    ),
+                    // lea rdi, [rip + handler]
+                    ins(
+                        0x1005,
+                        Op::Assign {
+                            dst: VReg::phys("rdi"),
+                            src: Value::Addr(0x3000),
+                        },
+                    ),
+                    // mov esi, offset other (non-PIE)
+                    ins(
+                        0x100c,
+                        Op::Assign {
+                            dst: VReg::phys("esi"),
+                            src: Value::Const(0x4000),
+                        },
+                    ),
+                    ins(
+                        0x1011,
+                        Op::Assign {
+                            dst: VReg::phys("eax"),
+                            src: Value::Const(0x4001),
+                        },
+                    ),
+                    ins(0x1016, Op::Jump { target: 0x1000 }),
+                    ins(0x101b, Op::Jump { target: 0x5000 }),
+                ],
+                succs: vec![],
+            }],
+        };
+        let refs = llir_code_refs(&lf, &[(0x1000, 0x6000)], &entries);
+        assert_eq!(refs, [0x2000, 0x3000, 0x4000, 0x5000]);
+    }
+
+    #[test]
+    fn elf_roots_include_entry_and_init_arrays() {
+        let path = "samples/binaries/platforms/linux/amd64/synthetic/vulnparse-c-gcc-O0";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        if !data.starts_with(b"\x7fELF") {
+            return;
+        }
+        let roots = loader_roots(&data);
+        let obj = object::read::File::parse(&*data).unwrap();
+        assert!(roots.contains(&(obj.entry(), RootKind::Entry)));
+        assert!(roots.iter().any(|(_, k)| *k == RootKind::Initializer));
+        assert!(roots.iter().any(|(_, k)| *k == RootKind::Finalizer));
+    }
+}
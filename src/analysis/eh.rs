@@ -0,0 +1,1400 @@
+//! Exception-handling metadata: try regions, handlers, and landing pads.
+//!
+//! Code reached only while an exception unwinds is invisible to ordinary
+//! control flow: no branch or call leads to it. Compilers describe it in
+//! tables instead, and three families of them are parsed:
+//!
+//! - **Itanium** (GCC, Clang; ELF, Mach-O, MinGW): every `.eh_frame` FDE
+//!   with an LSDA pointer names a `.gcc_except_table` entry whose call-site
+//!   table maps code ranges to landing pads, and whose action and type
+//!   tables list the `catch` clauses each landing pad dispatches to. MinGW
+//!   x64 reaches the same LSDA through `.pdata` and
+//!   `__gxx_personality_seh0`.
+//! - **SEH** (MSVC x64): `__C_specific_handler` scope tables, one entry
+//!   per `__try` with its filter and `__except` target, or its `__finally`
+//!   handler.
+//! - **MSVC C++** (x64): `__CxxFrameHandler3` FuncInfo and
+//!   `__CxxFrameHandler4` compressed FuncInfo4 tables; try blocks are state
+//!   ranges mapped back to code through the IP-to-state map, and each catch
+//!   is a separate funclet.
+//!
+//! Windows language handlers are identified by symbol or import name. In
+//! images without either (MSVC links the CRT statically and keeps symbols
+//! in the PDB) each handler's table format is decided by which parser
+//! accepts most of the records that use it.
+
+use std::collections::{BTreeSet, HashMap};
+use std::fmt;
+
+use gimli::UnwindSection;
+use object::{Object, ObjectSection};
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::mapped::{MappedImage, Word};
+use crate::analysis::pe_iat::pe_import_thunk_map;
+use crate::analysis::rtti::msvc_type_name;
+use crate::demangle::demangle_one;
+use crate::formats::pe::{Machine, PeParser, IMAGE_DIRECTORY_ENTRY_EXCEPTION};
+
+/// Most entries read from any one call-site, scope, try-block, handler, or
+/// IP-to-state table.
+const MAX_ENTRIES: usize = 4096;
+/// Most records followed along one LSDA action chain.
+const MAX_ACTIONS: usize = 64;
+
+/// Table family a function's exception metadata came from.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum EhScheme {
+    /// LSDA in `.gcc_except_table`
+    Itanium,
+    /// `__C_specific_handler` scope table
+    Seh,
+    /// `__CxxFrameHandler3`/`4` FuncInfo
+    MsvcCxx,
+}
+
+impl fmt::Display for EhScheme {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(match self {
+            EhScheme::Itanium => "itanium",
+            EhScheme::Seh => "seh",
+            EhScheme::MsvcCxx => "msvc_cxx",
+        })
+    }
+}
+
+/// What a handler does with the exception.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum HandlerKind {
+    /// Runs destructors and resumes unwinding
+    Cleanup,
+    /// `catch (T)`
+    Catch,
+    /// `catch (...)`
+    CatchAll,
+    /// Dynamic exception specification (`throw(T)`, `noexcept`)
+    ExceptionSpec,
+    /// SEH `__except`
+    Except,
+    /// SEH `__finally`
+    Finally,
+}
+
+/// One handler attached to a try region.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Handler {
+    pub kind: HandlerKind,
+    /// Demangled caught type, for `Catch`
+    pub type_name: Option<String>,
+    /// Code that runs the handler: the landing pad, `__except` block, catch
+    /// funclet, or `__finally` funclet
+    pub target: Option<u64>,
+    /// SEH filter function, for `Except` with a non-constant filter
+    pub filter: Option<u64>,
+}
+
+impl Handler {
+    fn new(kind: HandlerKind, type_name: Option<String>, target: Option<u64>) -> Self {
+        Self {
+            kind,
+            type_name,
+            target,
+            filter: None,
+        }
+    }
+
+    /// Source-level shape, e.g. `catch (std::exception const&)`.
+    pub fn describe(&self) -> String {
+        match self.kind {
+            HandlerKind::Cleanup => "cleanup".to_string(),
+            HandlerKind::Catch => format!("catch ({})", self.type_name.as_deref().unwrap_or("?")),
+            HandlerKind::CatchAll => "catch (...)".to_string(),
+            HandlerKind::ExceptionSpec => "exception spec".to_string(),
+            HandlerKind::Except => "__except".to_string(),
+            HandlerKind::Finally => "__finally".to_string(),
+        }
+    }
+}
+
+/// A protected code range and the handlers that cover it.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct TryRegion {
+    pub start: u64,
+    /// Exclusive
+    pub end: u64,
+    /// Where control resumes inside the function when the range throws
+    /// (Itanium landing pad, SEH `__except` block); `None` when every
+    /// handler is a funclet
+    pub landing_pad: Option<u64>,
+    pub handlers: Vec<Handler>,
+}
+
+/// Exception metadata of one function (or one split chunk of it).
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct EhFunction {
+    pub start: u64,
+    /// Exclusive
+    pub end: u64,
+    pub scheme: EhScheme,
+    /// Personality routine or language-specific handler, if in this image
+    pub personality: Option<u64>,
+    pub personality_name: Option<String>,
+    /// Address of the LSDA, scope table, or FuncInfo
+    pub table: u64,
+    pub regions: Vec<TryRegion>,
+}
+
+/// Exception metadata of every function that has any.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct EhInfo {
+    /// Sorted by `start`
+    pub functions: Vec<EhFunction>,
+}
+
+impl EhInfo {
+    /// The function whose metadata starts exactly at `va`.
+    pub fn function_at(&self, va: u64) -> Option<&EhFunction> {
+        let i = self.functions.binary_search_by_key(&va, |f| f.start).ok()?;
+        Some(&self.functions[i])
+    }
+
+    /// Code entered only by the unwinder as a function of its own: catch
+    /// and `__finally` funclets, SEH filters, and in-image personality
+    /// routines.
+    pub fn handler_functions(&self) -> BTreeSet<u64> {
+        let mut out = BTreeSet::new();
+        for f in &self.functions {
+            out.extend(f.personality);
+            for h in f.regions.iter().flat_map(|r| &r.handlers) {
+                out.extend(h.filter);
+                if matches!(h.kind, HandlerKind::Finally)
+                    || (f.scheme == EhScheme::MsvcCxx && h.target.is_some())
+                {
+                    out.extend(h.target);
+                }
+            }
+        }
+        out
+    }
+
+    /// In-function code reached only by exception flow.
+    pub fn landing_pads(&self) -> BTreeSet<u64> {
+        self.functions
+            .iter()
+            .flat_map(|f| &f.regions)
+            .filter_map(|r| r.landing_pad)
+            .collect()
+    }
+
+    pub fn region_count(&self) -> usize {
+        self.functions.iter().map(|f| f.regions.len()).sum()
+    }
+
+    pub fn handler_count(&self) -> usize {
+        self.functions
+            .iter()
+            .flat_map(|f| &f.regions)
+            .map(|r| r.handlers.len())
+            .sum()
+    }
+}
+
+/// Try regions and handlers from `.eh_frame`/`.gcc_except_table` and, on
+/// x64 PE, from `.pdata`. `None` when the image has no try regions.
+pub fn recover_eh_info(data: &[u8]) -> Option<EhInfo> {
+    let mem = MappedImage::parse(data)?;
+    let mut functions = itanium_functions(data, &mem);
+    if mem.is_pe {
+        functions.extend(pe_functions(data, &mem));
+    }
+    functions.retain(|f| !f.regions.is_empty());
+    functions.sort_by_key(|f| f.start);
+    functions.dedup_by_key(|f| f.start);
+    (!functions.is_empty()).then_some(EhInfo { functions })
+}
+
+/// Demangled form of a symbol, or the symbol itself.
+fn demangled(raw: &str) -> String {
+    demangle_one(raw).map_or_else(|| raw.to_string(), |d| d.demangled)
+}
+
+/// Widens the pointer-sized tables of a region list into one entry per
+/// contiguous run of code sharing a landing pad and handlers.
+fn merge_regions(regions: Vec<TryRegion>) -> Vec<TryRegion> {
+    let mut out: Vec<TryRegion> = Vec::new();
+    for r in regions {
+        match out.last_mut() {
+            Some(last)
+                if last.end == r.start
+                    && last.landing_pad == r.landing_pad
+                    && last.handlers == r.handlers =>
+            {
+                last.end = r.end;
+            }
+            _ => out.push(r),
+        }
+    }
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Itanium
+
+/// `DW_EH_PE_*` pointer encodings.
+const DW_EH_PE_OMIT: u8 = 0xff;
+const DW_EH_PE_ABSPTR: u8 = 0x00;
+const DW_EH_PE_ULEB128: u8 = 0x01;
+const DW_EH_PE_UDATA2: u8 = 0x02;
+const DW_EH_PE_UDATA4: u8 = 0x03;
+const DW_EH_PE_UDATA8: u8 = 0x04;
+const DW_EH_PE_SLEB128: u8 = 0x09;
+const DW_EH_PE_SDATA2: u8 = 0x0a;
+const DW_EH_PE_SDATA4: u8 = 0x0b;
+const DW_EH_PE_SDATA8: u8 = 0x0c;
+const DW_EH_PE_PCREL: u8 = 0x10;
+const DW_EH_PE_FUNCREL: u8 = 0x40;
+const DW_EH_PE_INDIRECT: u8 = 0x80;
+
+/// Sequential reads through mapped memory.
+struct Reader<'m, 'a> {
+    mem: &'m MappedImage<'a>,
+    va: u64,
+}
+
+impl<'m, 'a> Reader<'m, 'a> {
+    fn new(mem: &'m MappedImage<'a>, va: u64) -> Self {
+        Self { mem, va }
+    }
+
+    fn u8(&mut self) -> Option<u8> {
+        let b = *self.mem.bytes(self.va, 1)?.first()?;
+        self.va += 1;
+        Some(b)
+    }
+
+    fn uint(&mut self, size: usize) -> Option<u64> {
+        let v = self.mem.uint(self.va, size)?;
+        self.va += size as u64;
+        Some(v)
+    }
+
+    fn int(&mut self, size: usize) -> Option<i64> {
+        let v = self.mem.int(self.va, size)?;
+        self.va += size as u64;
+        Some(v)
+    }
+
+    fn uleb(&mut self) -> Option<u64> {
+        let mut v = 0u64;
+        for shift in (0..64).step_by(7) {
+            let b = self.u8()?;
+            v |= u64::from(b & 0x7f) << shift;
+            if b & 0x80 == 0 {
+                return Some(v);
+            }
+        }
+        None
+    }
+
+    fn sleb(&mut self) -> Option<i64> {
+        let mut v = 0i64;
+        let mut shift = 0;
+        loop {
+            let b = self.u8()?;
+            v |= i64::from(b & 0x7f) << shift;
+            shift += 7;
+            if b & 0x80 == 0 {
+                if shift < 64 && b & 0x40 != 0 {
+                    v |= -1 << shift;
+                }
+                return Some(v);
+            }
+            if shift >= 64 {
+                return None;
+            }
+        }
+    }
+
+    /// The raw value of a `DW_EH_PE_*` format, without its application.
+    fn value(&mut self, enc: u8) -> Option<i64> {
+        Some(match enc & 0x0f {
+            DW_EH_PE_ABSPTR => self.uint(self.mem.ptr_size)? as i64,
+            DW_EH_PE_ULEB128 => self.uleb()? as i64,
+            DW_EH_PE_UDATA2 => self.uint(2)? as i64,
+            DW_EH_PE_UDATA4 => self.uint(4)? as i64,
+            DW_EH_PE_UDATA8 => self.uint(8)? as i64,
+            DW_EH_PE_SLEB128 => self.sleb()?,
+            DW_EH_PE_SDATA2 => self.int(2)?,
+            DW_EH_PE_SDATA4 => self.int(4)?,
+            DW_EH_PE_SDATA8 => self.int(8)?,
+            _ => return None,
+        })
+    }
+
+    /// An encoded pointer as the loader sees it, following `indirect`.
+    /// Absolute pointers pick up dynamic relocations; `textrel` and
+    /// `datarel` are not used in LSDAs and are rejected. A zero value is a
+    /// null pointer whatever its encoding, as in the unwinder.
+    fn pointer(&mut self, enc: u8, func: u64) -> Option<Word<'m>> {
+        let at = self.va;
+        let word = if enc & 0x7f == DW_EH_PE_ABSPTR {
+            let w = self.mem.word(at)?;
+            self.va += self.mem.ptr_size as u64;
+            w
+        } else {
+            let v = self.value(enc)?;
+            if v == 0 {
+                return Some(Word::Addr(0));
+            }
+            let base = match enc & 0x70 {
+                0 => 0,
+                DW_EH_PE_PCREL => at,
+                DW_EH_PE_FUNCREL => func,
+                _ => return None,
+            };
+            Word::Addr(base.wrapping_add(v as u64))
+        };
+        match word {
+            Word::Addr(a) if enc & DW_EH_PE_INDIRECT != 0 => self.mem.word(a),
+            w => Some(w),
+        }
+    }
+}
+
+/// Byte size of one type-table entry.
+fn encoded_size(mem: &MappedImage<'_>, enc: u8) -> Option<u64> {
+    Some(match enc & 0x0f {
+        DW_EH_PE_ABSPTR => mem.ptr_size as u64,
+        DW_EH_PE_UDATA2 | DW_EH_PE_SDATA2 => 2,
+        DW_EH_PE_UDATA4 | DW_EH_PE_SDATA4 => 4,
+        DW_EH_PE_UDATA8 | DW_EH_PE_SDATA8 => 8,
+        _ => return None,
+    })
+}
+
+/// Name of the function or data a word refers to.
+fn word_name(mem: &MappedImage<'_>, w: Word<'_>) -> Option<String> {
+    match w {
+        Word::Import(name, 0) => Some(name.to_string()),
+        Word::Import(..) => None,
+        Word::Addr(a) => mem.names.get(&a).cloned(),
+    }
+}
+
+/// Demangled type behind a `std::type_info` reference: from its `_ZTI`
+/// symbol, or from the mangled name the typeinfo points to.
+fn typeinfo_name(mem: &MappedImage<'_>, w: Word<'_>) -> Option<String> {
+    if let Some(raw) = word_name(mem, w).and_then(|s| s.strip_prefix("_ZTI").map(str::to_string)) {
+        return Some(mangled_type_name(&raw));
+    }
+    let Word::Addr(ti) = w else {
+        return None;
+    };
+    let name = mem.cstr(mem.pointer(ti + mem.ptr_size as u64)?)?;
+    // GCC prefixes `*` to names of types with internal linkage.
+    Some(mangled_type_name(name.strip_prefix('*').unwrap_or(name)))
+}
+
+/// `N2eh10ParseErrorE` -> `eh::ParseError`, `i` -> `int`.
+fn mangled_type_name(raw: &str) -> String {
+    demangle_one(&format!("_ZTS{}", raw))
+        .and_then(|d| {
+            d.demangled
+                .strip_prefix("typeinfo name for ")
+                .map(str::to_string)
+        })
+        .unwrap_or_else(|| raw.to_string())
+}
+
+fn itanium_functions(data: &[u8], mem: &MappedImage<'_>) -> Vec<EhFunction> {
+    let mut out = Vec::new();
+    let Ok(obj) = object::read::File::parse(data) else {
+        return out;
+    };
+    let Some(section) = obj.section_by_name(".eh_frame") else {
+        return out;
+    };
+    let Ok(bytes) = section.data() else {
+        return out;
+    };
+    let endian = if obj.is_little_endian() {
+        gimli::RunTimeEndian::Little
+    } else {
+        gimli::RunTimeEndian::Big
+    };
+    let mut eh_frame = gimli::EhFrame::new(bytes, endian);
+    eh_frame.set_address_size(mem.ptr_size as u8);
+    let mut bases = gimli::BaseAddresses::default().set_eh_frame(section.address());
+    if let Some(text) = obj.section_by_name(".text") {
+        bases = bases.set_text(text.address());
+    }
+    if let Some(got) = obj.section_by_name(".got") {
+        bases = bases.set_got(got.address());
+    }
+
+    let mut entries = eh_frame.entries(&bases);
+    while let Ok(Some(entry)) = entries.next() {
+        let gimli::CieOrFde::Fde(partial) = entry else {
+            continue;
+        };
+        let Ok(fde) = partial.parse(gimli::EhFrame::cie_from_offset) else {
+            continue;
+        };
+        let Some(lsda) = fde.lsda().and_then(|p| resolve(mem, p)) else {
+            continue;
+        };
+        let start = fde.initial_address();
+        let (personality, personality_name) = match fde.personality() {
+            Some(p) => personality_routine(mem, p),
+            None => (None, None),
+        };
+        out.push(EhFunction {
+            start,
+            end: start.saturating_add(fde.len()),
+            scheme: EhScheme::Itanium,
+            personality,
+            personality_name,
+            table: lsda,
+            regions: parse_lsda(mem, lsda, start),
+        });
+    }
+    out
+}
+
+/// A CFI pointer as an in-image address.
+fn resolve(mem: &MappedImage<'_>, p: gimli::Pointer) -> Option<u64> {
+    match p {
+        gimli::Pointer::Direct(a) => Some(a),
+        gimli::Pointer::Indirect(a) => mem.pointer(a),
+    }
+}
+
+/// Personality routine address (when defined here) and name.
+fn personality_routine(mem: &MappedImage<'_>, p: gimli::Pointer) -> (Option<u64>, Option<String>) {
+    let w = match p {
+        gimli::Pointer::Direct(a) => Some(Word::Addr(a)),
+        gimli::Pointer::Indirect(a) => mem.word(a),
+    };
+    match w {
+        Some(Word::Addr(a)) if mem.is_code(a) => (Some(a), mem.names.get(&a).map(|n| demangled(n))),
+        Some(w) => (None, word_name(mem, w).map(|n| demangled(&n))),
+        None => (None, None),
+    }
+}
+
+/// Call-site table of the LSDA at `va` for the code starting at `func`.
+fn parse_lsda(mem: &MappedImage<'_>, va: u64, func: u64) -> Vec<TryRegion> {
+    let mut regions = Vec::new();
+    let mut r = Reader::new(mem, va);
+    let Some(lp_enc) = r.u8() else {
+        return regions;
+    };
+    let lp_start = if lp_enc == DW_EH_PE_OMIT {
+        func
+    } else {
+        match r.pointer(lp_enc, func) {
+            Some(Word::Addr(a)) => a,
+            _ => return regions,
+        }
+    };
+    let Some(tt_enc) = r.u8() else {
+        return regions;
+    };
+    let types = if tt_enc == DW_EH_PE_OMIT {
+        None
+    } else {
+        let Some(off) = r.uleb() else {
+            return regions;
+        };
+        Some(r.va + off)
+    };
+    let (Some(cs_enc), Some(cs_len)) = (r.u8(), r.uleb()) else {
+        return regions;
+    };
+    let actions = r.va + cs_len;
+    while r.va < actions && regions.len() < MAX_ENTRIES {
+        let (Some(start), Some(len), Some(lp), Some(action)) =
+            (r.value(cs_enc), r.value(cs_enc), r.value(cs_enc), r.uleb())
+        else {
+            break;
+        };
+        // A call site without a landing pad just lets exceptions through.
+        if lp == 0 || len <= 0 {
+            continue;
+        }
+        let pad = lp_start.wrapping_add(lp as u64);
+        let handlers = if action == 0 {
+            vec![Handler::new(HandlerKind::Cleanup, None, Some(pad))]
+        } else {
+            action_chain(mem, actions + action - 1, types, tt_enc, func, pad)
+        };
+        let start = lp_start.wrapping_add(start as u64);
+        regions.push(TryRegion {
+            start,
+            end: start.wrapping_add(len as u64),
+            landing_pad: Some(pad),
+            handlers,
+        });
+    }
+    merge_regions(regions)
+}
+
+/// Handlers along the action chain starting at `va`, innermost first.
+fn action_chain(
+    mem: &MappedImage<'_>,
+    mut va: u64,
+    types: Option<u64>,
+    tt_enc: u8,
+    func: u64,
+    pad: u64,
+) -> Vec<Handler> {
+    let mut out = Vec::new();
+    for _ in 0..MAX_ACTIONS {
+        let mut r = Reader::new(mem, va);
+        let Some(filter) = r.sleb() else {
+            break;
+        };
+        let next = r.va;
+        let Some(disp) = r.sleb() else {
+            break;
+        };
+        let handler = match filter {
+            0 => Handler::new(HandlerKind::Cleanup, None, Some(pad)),
+            f if f > 0 => {
+                let entry = types.zip(encoded_size(mem, tt_enc)).and_then(|(tt, size)| {
+                    Reader::new(mem, tt.checked_sub(f as u64 * size)?).pointer(tt_enc, func)
+                });
+                match entry {
+                    Some(Word::Addr(0)) => Handler::new(HandlerKind::CatchAll, None, Some(pad)),
+                    Some(w) => Handler::new(HandlerKind::Catch, typeinfo_name(mem, w), Some(pad)),
+                    None => Handler::new(HandlerKind::Catch, None, Some(pad)),
+                }
+            }
+            _ => Handler::new(HandlerKind::ExceptionSpec, None, Some(pad)),
+        };
+        out.push(handler);
+        if disp == 0 {
+            break;
+        }
+        va = next.wrapping_add(disp as u64);
+    }
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Windows x64
+
+/// `UNWIND_INFO` flags with a language-specific handler.
+const UNW_FLAG_EHANDLER: u8 = 0x1;
+const UNW_FLAG_UHANDLER: u8 = 0x2;
+/// `ScopeTable` handler value of `__except (EXCEPTION_EXECUTE_HANDLER)`.
+const EXCEPTION_EXECUTE_HANDLER: u32 = 1;
+/// `FuncInfo::magicNumber` values (low 29 bits)
+const EH_MAGIC_NUMBER1: u32 = 0x1993_0520;
+const EH_MAGIC_NUMBER3: u32 = 0x1993_0522;
+
+/// FuncInfo4 header bits.
+const FI4_IS_CATCH: u8 = 0x01;
+const FI4_IS_SEPARATED: u8 = 0x02;
+const FI4_BBT: u8 = 0x04;
+const FI4_UNWIND_MAP: u8 = 0x08;
+const FI4_TRY_BLOCK_MAP: u8 = 0x10;
+const FI4_RESERVED: u8 = 0x80;
+/// HandlerType4 header bits.
+const HT4_ADJECTIVES: u8 = 0x01;
+const HT4_DISP_TYPE: u8 = 0x02;
+const HT4_DISP_CATCH_OBJ: u8 = 0x04;
+const HT4_CONT_IS_RVA: u8 = 0x08;
+const HT4_CONT_ADDR_SHIFT: u8 = 4;
+
+/// Table format behind a language-specific handler.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+enum PeHandler {
+    ScopeTable,
+    FuncInfo3,
+    FuncInfo4,
+    Lsda,
+}
+
+impl PeHandler {
+    const ALL: [PeHandler; 4] = [
+        PeHandler::FuncInfo3,
+        PeHandler::ScopeTable,
+        PeHandler::FuncInfo4,
+        PeHandler::Lsda,
+    ];
+
+    /// Format by handler name; `Err(())` for handlers with no try tables
+    /// (`__GSHandlerCheck`), `Ok(None)` for unknown names.
+    fn from_name(name: &str) -> Result<Option<Self>, ()> {
+        let name = name.trim_start_matches("__imp_").trim_start_matches('_');
+        Ok(Some(match name {
+            "C_specific_handler" | "GSHandlerCheck_SEH" => PeHandler::ScopeTable,
+            "CxxFrameHandler4" | "GSHandlerCheck_EH4" => PeHandler::FuncInfo4,
+            "CxxFrameHandler3" | "CxxFrameHandler2" | "CxxFrameHandler" | "GSHandlerCheck_EH" => {
+                PeHandler::FuncInfo3
+            }
+            "gxx_personality_seh0" | "gcc_personality_seh0" | "GCC_specific_handler" => {
+                PeHandler::Lsda
+            }
+            "GSHandlerCheck" => return Err(()),
+            _ => return Ok(None),
+        }))
+    }
+}
+
+/// A `.pdata` entry with a language-specific handler.
+struct HandlerRecord {
+    start: u64,
+    end: u64,
+    handler: u64,
+    /// Handler data following the handler RVA in `UNWIND_INFO`
+    data: u64,
+}
+
+fn pe_functions(data: &[u8], mem: &MappedImage<'_>) -> Vec<EhFunction> {
+    let records = handler_records(data, mem);
+    if records.is_empty() {
+        return Vec::new();
+    }
+    let thunks: HashMap<u64, String> = pe_import_thunk_map(data).into_iter().collect();
+    let mut by_handler: HashMap<u64, Vec<&HandlerRecord>> = HashMap::new();
+    for rec in &records {
+        by_handler.entry(rec.handler).or_default().push(rec);
+    }
+
+    let mut out = Vec::new();
+    for (handler, recs) in by_handler {
+        let name = handler_name(mem, &thunks, handler);
+        let format = match name.as_deref().map(PeHandler::from_name) {
+            Some(Err(())) => continue,
+            Some(Ok(Some(f))) => f,
+            _ => match guess_handler(mem, &recs) {
+                Some(f) => f,
+                None => continue,
+            },
+        };
+        for rec in recs {
+            let Some((scheme, table, regions)) = parse_handler_data(mem, format, rec) else {
+                continue;
+            };
+            out.push(EhFunction {
+                start: rec.start,
+                end: rec.end,
+                scheme,
+                personality: mem.is_code(handler).then_some(handler),
+                personality_name: name.clone(),
+                table,
+                regions,
+            });
+        }
+    }
+    out
+}
+
+/// `.pdata` entries whose `UNWIND_INFO` names an exception or termination
+/// handler. x64 only: x86 registers SEH frames on the stack and ARM64
+/// packs its unwind data differently.
+fn handler_records(data: &[u8], mem: &MappedImage<'_>) -> Vec<HandlerRecord> {
+    let mut out = Vec::new();
+    let Ok(pe) = PeParser::new(data) else {
+        return out;
+    };
+    if pe.machine() != Machine::X86_64 {
+        return out;
+    }
+    let Ok(dir) = pe.data_directory(IMAGE_DIRECTORY_ENTRY_EXCEPTION) else {
+        return out;
+    };
+    let base = mem.image_base;
+    let table = base + u64::from(dir.virtual_address);
+    for i in 0..(dir.size / 12).min(1 << 20) as u64 {
+        let entry = table + 12 * i;
+        let (Some(begin), Some(end), Some(unwind)) =
+            (mem.u32(entry), mem.u32(entry + 4), mem.u32(entry + 8))
+        else {
+            break;
+        };
+        // Odd unwind RVAs point at another RUNTIME_FUNCTION (chained).
+        if begin >= end || unwind & 1 != 0 {
+            continue;
+        }
+        let info = base + u64::from(unwind);
+        let (Some(head), Some(codes)) = (mem.bytes(info, 1), mem.bytes(info + 2, 1)) else {
+            continue;
+        };
+        let (version, flags) = (head[0] & 0x7, head[0] >> 3);
+        if !(1..=2).contains(&version) || flags & (UNW_FLAG_EHANDLER | UNW_FLAG_UHANDLER) == 0 {
+            continue;
+        }
+        let at = info + 4 + 2 * ((u64::from(codes[0]) + 1) & !1);
+        let Some(handler) = mem.u32(at) else {
+            continue;
+        };
+        out.push(HandlerRecord {
+            start: base + u64::from(begin),
+            end: base + u64::from(end),
+            handler: base + u64::from(handler),
+            data: at + 4,
+        });
+    }
+    out
+}
+
+/// Symbol name of a language handler, or the import its thunk jumps to.
+fn handler_name(mem: &MappedImage<'_>, thunks: &HashMap<u64, String>, va: u64) -> Option<String> {
+    mem.names.get(&va).or_else(|| thunks.get(&va)).cloned()
+}
+
+/// The table format most of an unnamed handler's records parse as.
+fn guess_handler(mem: &MappedImage<'_>, recs: &[&HandlerRecord]) -> Option<PeHandler> {
+    let mut best = None;
+    let mut best_count = 0;
+    for format in PeHandler::ALL {
+        let count = recs
+            .iter()
+            .filter(|rec| parse_handler_data(mem, format, rec).is_some())
+            .count();
+        if count > best_count {
+            best = Some(format);
+            best_count = count;
+        }
+    }
+    // Half the records must agree; the rest may be handlerless funclets.
+    (best_count * 2 >= recs.len()).then_some(best).flatten()
+}
+
+fn parse_handler_data(
+    mem: &MappedImage<'_>,
+    format: PeHandler,
+    rec: &HandlerRecord,
+) -> Option<(EhScheme, u64, Vec<TryRegion>)> {
+    let base = mem.image_base;
+    match format {
+        PeHandler::ScopeTable => Some((EhScheme::Seh, rec.data, scope_table(mem, rec)?)),
+        PeHandler::FuncInfo3 => {
+            let fi = base + u64::from(mem.u32(rec.data)?);
+            Some((EhScheme::MsvcCxx, fi, func_info3(mem, fi, rec)?))
+        }
+        PeHandler::FuncInfo4 => {
+            let fi = base + u64::from(mem.u32(rec.data)?);
+            Some((EhScheme::MsvcCxx, fi, func_info4(mem, fi, rec)?))
+        }
+        PeHandler::Lsda => {
+            let lsda = base + u64::from(mem.u32(rec.data)?);
+            if !mem.is_data(lsda) {
+                return None;
+            }
+            let regions = parse_lsda(mem, lsda, rec.start);
+            (!regions.is_empty()).then_some((EhScheme::Itanium, lsda, regions))
+        }
+    }
+}
+
+/// `SCOPE_TABLE_AMD64`: `{Count, {Begin, End, Handler, JumpTarget}[]}`.
+fn scope_table(mem: &MappedImage<'_>, rec: &HandlerRecord) -> Option<Vec<TryRegion>> {
+    let base = mem.image_base;
+    let count = mem.u32(rec.data)? as usize;
+    if count == 0 || count > MAX_ENTRIES {
+        return None;
+    }
+    let in_func = |va: u64| va >= rec.start && va < rec.end;
+    let mut regions = Vec::new();
+    for i in 0..count as u64 {
+        let e = rec.data + 4 + 16 * i;
+        let (begin, end, handler, jump) = (
+            base + u64::from(mem.u32(e)?),
+            base + u64::from(mem.u32(e + 4)?),
+            mem.u32(e + 8)?,
+            mem.u32(e + 12)?,
+        );
+        if begin >= end || !in_func(begin) || end > rec.end {
+            return None;
+        }
+        let handler_va = base + u64::from(handler);
+        let h = if jump == 0 {
+            if !mem.is_code(handler_va) {
+                return None;
+            }
+            Handler::new(HandlerKind::Finally, None, Some(handler_va))
+        } else {
+            let target = base + u64::from(jump);
+            if !in_func(target) {
+                return None;
+            }
+            let filter = match handler {
+                EXCEPTION_EXECUTE_HANDLER => None,
+                _ if mem.is_code(handler_va) => Some(handler_va),
+                _ => return None,
+            };
+            Handler {
+                filter,
+                ..Handler::new(HandlerKind::Except, None, Some(target))
+            }
+        };
+        regions.push(TryRegion {
+            start: begin,
+            end,
+            landing_pad: (jump != 0).then_some(h.target).flatten(),
+            handlers: vec![h],
+        });
+    }
+    Some(regions)
+}
+
+/// Catch for a type descriptor RVA (0 for `catch (...)`).
+fn msvc_catch(mem: &MappedImage<'_>, type_rva: i64, target: u64) -> Handler {
+    if type_rva == 0 {
+        return Handler::new(HandlerKind::CatchAll, None, Some(target));
+    }
+    // TypeDescriptor: pVFTable, spare, name[]
+    let td = mem.image_base.wrapping_add(type_rva as u64);
+    let name = mem.cstr(td + 2 * mem.ptr_size as u64).map(|raw| {
+        msvc_type_name(raw)
+            .or_else(|| msvc_builtin_type(raw).map(str::to_string))
+            .unwrap_or_else(|| raw.to_string())
+    });
+    Handler::new(HandlerKind::Catch, name, Some(target))
+}
+
+/// Fundamental types in MSVC type descriptors (`.H` for `int`); classes
+/// go through `rtti::msvc_type_name`.
+fn msvc_builtin_type(raw: &str) -> Option<&'static str> {
+    Some(match raw.strip_prefix('.')? {
+        "D" => "char",
+        "C" => "signed char",
+        "E" => "unsigned char",
+        "F" => "short",
+        "G" => "unsigned short",
+        "H" => "int",
+        "I" => "unsigned int",
+        "J" => "long",
+        "K" => "unsigned long",
+        "M" => "float",
+        "N" => "double",
+        "O" => "long double",
+        "_J" => "__int64",
+        "_K" => "unsigned __int64",
+        "_N" => "bool",
+        "_W" => "wchar_t",
+        _ => return None,
+    })
+}
+
+/// Code ranges of `[start, end)` whose EH state is in `low..=high`, from a
+/// sorted `(ip, state)` map.
+fn state_ranges(map: &[(u64, i64)], end: u64, low: i64, high: i64) -> Vec<(u64, u64)> {
+    let mut out: Vec<(u64, u64)> = Vec::new();
+    for (i, &(ip, state)) in map.iter().enumerate() {
+        let next = map.get(i + 1).map_or(end, |e| e.0).min(end);
+        if state < low || state > high || ip >= next {
+            continue;
+        }
+        match out.last_mut() {
+            Some(last) if last.1 == ip => last.1 = next,
+            _ => out.push((ip, next)),
+        }
+    }
+    out
+}
+
+/// One region per code range per try block, each listing the block's
+/// catches.
+fn try_regions(
+    map: &[(u64, i64)],
+    end: u64,
+    blocks: Vec<(i64, i64, Vec<Handler>)>,
+) -> Vec<TryRegion> {
+    let mut regions = Vec::new();
+    for (low, high, handlers) in blocks {
+        for (start, end) in state_ranges(map, end, low, high) {
+            regions.push(TryRegion {
+                start,
+                end,
+                landing_pad: None,
+                handlers: handlers.clone(),
+            });
+        }
+    }
+    regions.sort_by_key(|r| (r.start, r.end));
+    regions
+}
+
+/// `__CxxFrameHandler3` FuncInfo (x64 layout, RVAs throughout).
+fn func_info3(mem: &MappedImage<'_>, fi: u64, rec: &HandlerRecord) -> Option<Vec<TryRegion>> {
+    let base = mem.image_base;
+    let magic = mem.u32(fi)? & 0x1fff_ffff;
+    if !(EH_MAGIC_NUMBER1..=EH_MAGIC_NUMBER3).contains(&magic) {
+        return None;
+    }
+    let n_try = mem.u32(fi + 12)? as usize;
+    let try_map = base + u64::from(mem.u32(fi + 16)?);
+    let n_ip = mem.u32(fi + 20)? as usize;
+    let ip_map = base + u64::from(mem.u32(fi + 24)?);
+    if n_try > MAX_ENTRIES || n_ip > MAX_ENTRIES {
+        return None;
+    }
+    let mut map = Vec::with_capacity(n_ip);
+    for i in 0..n_ip as u64 {
+        let ip = base + u64::from(mem.u32(ip_map + 8 * i)?);
+        map.push((ip, mem.int(ip_map + 8 * i + 4, 4)?));
+    }
+    map.sort_unstable();
+
+    let mut blocks = Vec::with_capacity(n_try);
+    for i in 0..n_try as u64 {
+        // TryBlockMapEntry: tryLow, tryHigh, catchHigh, nCatches, dispHandlerArray
+        let e = try_map + 20 * i;
+        let (low, high) = (mem.int(e, 4)?, mem.int(e + 4, 4)?);
+        let n_catches = mem.u32(e + 12)? as usize;
+        let handlers_at = base + u64::from(mem.u32(e + 16)?);
+        if n_catches > MAX_ENTRIES {
+            return None;
+        }
+        let mut handlers = Vec::with_capacity(n_catches);
+        for j in 0..n_catches as u64 {
+            // HandlerType: adjectives, dispType, dispCatchObj, dispOfHandler, dispFrame
+            let h = handlers_at + 20 * j;
+            let target = base + u64::from(mem.u32(h + 12)?);
+            if !mem.is_code(target) {
+                return None;
+            }
+            handlers.push(msvc_catch(mem, mem.int(h + 4, 4)?, target));
+        }
+        blocks.push((low, high, handlers));
+    }
+    Some(try_regions(&map, rec.end, blocks))
+}
+
+/// FH4 compressed unsigned integer.
+fn fh4_unsigned(r: &mut Reader<'_, '_>) -> Option<u64> {
+    let first = r.mem.bytes(r.va, 1)?[0];
+    let len = match first & 0x0f {
+        0xf => 5,
+        b if b & 0x7 == 0x7 => 4,
+        b if b & 0x3 == 0x3 => 3,
+        b if b & 0x1 == 0x1 => 2,
+        _ => 1,
+    };
+    let v = if len == 5 {
+        r.va += 1;
+        r.uint(4)?
+    } else {
+        r.uint(len as usize)? >> len
+    };
+    Some(v)
+}
+
+/// FH4 image-relative offset (a plain 32-bit value).
+fn fh4_rva(r: &mut Reader<'_, '_>) -> Option<u64> {
+    let rva = r.uint(4)?;
+    Some(r.mem.image_base + rva)
+}
+
+/// `__CxxFrameHandler4` FuncInfo4.
+fn func_info4(mem: &MappedImage<'_>, fi: u64, rec: &HandlerRecord) -> Option<Vec<TryRegion>> {
+    let mut r = Reader::new(mem, fi);
+    let header = r.u8()?;
+    if header & FI4_RESERVED != 0 || header & (FI4_UNWIND_MAP | FI4_TRY_BLOCK_MAP) == 0 {
+        return None;
+    }
+    if header & FI4_BBT != 0 {
+        fh4_unsigned(&mut r)?;
+    }
+    if header & FI4_UNWIND_MAP != 0 {
+        r.uint(4)?;
+    }
+    let try_map = if header & FI4_TRY_BLOCK_MAP != 0 {
+        Some(fh4_rva(&mut r)?)
+    } else {
+        None
+    };
+    let mut ip_map = fh4_rva(&mut r)?;
+    if header & FI4_IS_CATCH != 0 {
+        fh4_unsigned(&mut r)?;
+    }
+    if header & FI4_IS_SEPARATED != 0 {
+        // SepIptoStateMap4: {count, {funcStart RVA, dispOfIPMap}[]}
+        let mut s = Reader::new(mem, ip_map);
+        let count = fh4_unsigned(&mut s)? as usize;
+        let mut found = None;
+        for _ in 0..count.min(MAX_ENTRIES) {
+            let (start, map) = (fh4_rva(&mut s)?, fh4_rva(&mut s)?);
+            if start == rec.start {
+                found = Some(map);
+            }
+        }
+        ip_map = found?;
+    }
+
+    // IPtoStateMap4: {count, {ip delta, state + 1}[]}, function-relative.
+    let mut s = Reader::new(mem, ip_map);
+    let count = fh4_unsigned(&mut s)? as usize;
+    if count > MAX_ENTRIES {
+        return None;
+    }
+    let mut map = Vec::with_capacity(count);
+    let mut ip = rec.start;
+    for _ in 0..count {
+        ip = ip.checked_add(fh4_unsigned(&mut s)?)?;
+        if ip >= rec.end {
+            return None;
+        }
+        map.push((ip, fh4_unsigned(&mut s)? as i64 - 1));
+    }
+
+    let mut blocks = Vec::new();
+    if let Some(try_map) = try_map {
+        let mut t = Reader::new(mem, try_map);
+        let n_try = fh4_unsigned(&mut t)? as usize;
+        if n_try > MAX_ENTRIES {
+            return None;
+        }
+        for _ in 0..n_try {
+            let low = fh4_unsigned(&mut t)? as i64;
+            let high = fh4_unsigned(&mut t)? as i64;
+            fh4_unsigned(&mut t)?; // catchHigh
+            let handlers_at = fh4_rva(&mut t)?;
+            blocks.push((low, high, handler_map4(mem, handlers_at)?));
+        }
+    }
+    Some(try_regions(&map, rec.end, blocks))
+}
+
+/// HandlerMap4: `{count, HandlerType4[]}`.
+fn handler_map4(mem: &MappedImage<'_>, va: u64) -> Option<Vec<Handler>> {
+    let mut r = Reader::new(mem, va);
+    let count = fh4_unsigned(&mut r)? as usize;
+    if count > MAX_ENTRIES {
+        return None;
+    }
+    let mut out = Vec::with_capacity(count);
+    for _ in 0..count {
+        let header = r.u8()?;
+        if header & HT4_ADJECTIVES != 0 {
+            fh4_unsigned(&mut r)?;
+        }
+        let type_rva = if header & HT4_DISP_TYPE != 0 {
+            r.int(4)?
+        } else {
+            0
+        };
+        if header & HT4_DISP_CATCH_OBJ != 0 {
+            fh4_unsigned(&mut r)?;
+        }
+        let target = fh4_rva(&mut r)?;
+        if !mem.is_code(target) {
+            return None;
+        }
+        for _ in 0..(header >> HT4_CONT_ADDR_SHIFT) & 0x3 {
+            if header & HT4_CONT_IS_RVA != 0 {
+                r.uint(4)?;
+            } else {
+                fh4_unsigned(&mut r)?;
+            }
+        }
+        out.push(msvc_catch(mem, type_rva, target));
+    }
+    Some(out)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::analysis::mapped::{Region, Reloc};
+
+    const BASE: u64 = 0x1_4000_0000;
+    const CODE: u64 = BASE + 0x1000;
+    const DATA: u64 = BASE + 0x4000;
+
+    /// Synthetic image: 0x200 bytes of code at `CODE` and a data region at
+    /// `DATA` assembled by the test.
+    struct Image {
+        code: Vec<u8>,
+        data: Vec<u8>,
+    }
+
+    impl Image {
+        fn new() -> Self {
+            Self {
+                code: vec![0xc3; 0x200],
+                data: vec![0; 0x400],
+            }
+        }
+
+        fn bytes(&mut self, va: u64, b: &[u8]) {
+            let off = (va - DATA) as usize;
+            self.data[off..off + b.len()].copy_from_slice(b);
+        }
+
+        fn u32(&mut self, va: u64, v: u32) {
+            self.bytes(va, &v.to_le_bytes());
+        }
+
+        fn memory(&self) -> MappedImage<'_> {
+            let mut mem = MappedImage::new(8, false);
+            mem.image_base = BASE;
+            mem.regions.push(Region {
+                va: CODE,
+                bytes: &self.code,
+                exec: true,
+            });
+            mem.regions.push(Region {
+                va: DATA,
+                bytes: &self.data,
+                exec: false,
+            });
+            mem
+        }
+    }
+
+    fn rva(va: u64) -> u32 {
+        (va - BASE) as u32
+    }
+
+    fn record(start: u64, end: u64, data: u64) -> HandlerRecord {
+        HandlerRecord {
+            start,
+            end,
+            handler: CODE + 0x1f0,
+            data,
+        }
+    }
+
+    #[test]
+    fn lsda_call_sites_actions_and_types() {
+        let mut img = Image::new();
+        let lsda = DATA;
+        // Type table base at DATA + 0x40 (udata8 absolute entries, read
+        // backwards): index 1 = int (import), 2 = catch-all (0).
+        img.bytes(
+            lsda,
+            &[
+                0xff, // LPStart omitted: function start
+                0x00, // TType absptr
+                0x3e, // TType base offset: lsda + 2 + 0x3e after this byte
+                0x01, // call sites uleb128
+                8,    // call-site table length
+                // call 1: [0x04, 0x0c) -> pad 0x40, action 1
+                0x04, 0x08, 0x40, 0x01,
+                // call 2: [0x0c, 0x10) -> pad 0x40, action 1 (merges)
+                0x0c, 0x04, 0x40, 0x01,
+                // action 1: filter 2 (catch-all), next +1 -> action 2
+                0x02, 0x01, // action 2: filter 1 (int), end
+                0x01, 0x00,
+            ],
+        );
+        let ttbase = lsda + 3 + 0x3e;
+        assert_eq!(ttbase, DATA + 0x41);
+        let mut mem = img.memory();
+        mem.relocs
+            .insert(ttbase - 8, Reloc::Import("_ZTIi".to_string(), 0));
+        // ttbase - 16 stays 0 (catch-all).
+        let regions = parse_lsda(&mem, lsda, CODE);
+        assert_eq!(regions.len(), 1);
+        let r = &regions[0];
+        assert_eq!((r.start, r.end), (CODE + 4, CODE + 0x10));
+        assert_eq!(r.landing_pad, Some(CODE + 0x40));
+        let kinds: Vec<_> = r.handlers.iter().map(|h| h.kind).collect();
+        assert_eq!(kinds, [HandlerKind::CatchAll, HandlerKind::Catch]);
+        assert!(r.handlers[1].type_name.is_some());
+    }
+
+    #[test]
+    fn lsda_cleanup_and_skipped_call_sites() {
+        let mut img = Image::new();
+        img.bytes(
+            DATA,
+            &[
+                0xff, 0xff, 0x03, 26, //
+                // [0x00, 0x10) without a landing pad
+                0, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0, 0, 0, //
+                // [0x10, 0x18) -> 0x30, cleanup
+                0x10, 0, 0, 0, 0x08, 0, 0, 0, 0x30, 0, 0, 0, 0,
+            ],
+        );
+        let mem = img.memory();
+        let regions = parse_lsda(&mem, DATA, CODE);
+        assert_eq!(regions.len(), 1);
+        assert_eq!(
+            (regions[0].start, regions[0].end),
+            (CODE + 0x10, CODE + 0x18)
+        );
+        assert_eq!(regions[0].landing_pad, Some(CODE + 0x30));
+        assert_eq!(regions[0].handlers[0].kind, HandlerKind::Cleanup);
+    }
+
+    #[test]
+    fn seh_scope_table_except_and_finally() {
+        let mut img = Image::new();
+        let table = DATA + 0x100;
+        img.u32(table, 2);
+        // __try [0x10, 0x20) __except (filter at 0x180) -> 0x30
+        img.u32(table + 4, rva(CODE + 0x10));
+        img.u32(table + 8, rva(CODE + 0x20));
+        img.u32(table + 12, rva(CODE + 0x180));
+        img.u32(table + 16, rva(CODE + 0x30));
+        // __try [0x40, 0x50) __finally (funclet at 0x1a0)
+        img.u32(table + 20, rva(CODE + 0x40));
+        img.u32(table + 24, rva(CODE + 0x50));
+        img.u32(table + 28, rva(CODE + 0x1a0));
+        img.u32(table + 32, 0);
+        let mem = img.memory();
+        let rec = record(CODE, CODE + 0x100, table);
+        let regions = scope_table(&mem, &rec).unwrap();
+        assert_eq!(regions.len(), 2);
+        assert_eq!(regions[0].landing_pad, Some(CODE + 0x30));
+        assert_eq!(regions[0].handlers[0].filter, Some(CODE + 0x180));
+        assert_eq!(regions[1].landing_pad, None);
+        assert_eq!(regions[1].handlers[0].kind, HandlerKind::Finally);
+        assert_eq!(regions[1].handlers[0].target, Some(CODE + 0x1a0));
+
+        // A scope entry outside the function rejects the table.
+        assert!(scope_table(&mem, &record(CODE + 0x20, CODE + 0x100, table)).is_none());
+        assert_eq!(guess_handler(&mem, &[&rec]), Some(PeHandler::ScopeTable));
+    }
+
+    #[test]
+    fn fh4_compressed_integers() {
+        let mut img = Image::new();
+        // 1 byte: 0x7f << 1; 2 bytes: 0x1234 << 2 | 1; 5 bytes: 0x0f + u32.
+        img.bytes(DATA, &[0xfe]);
+        img.bytes(DATA + 1, &((0x1234u16 << 2) | 1).to_le_bytes());
+        img.bytes(DATA + 3, &[0x0f, 0x78, 0x56, 0x34, 0x12]);
+        let mem = img.memory();
+        let mut r = Reader::new(&mem, DATA);
+        assert_eq!(fh4_unsigned(&mut r), Some(0x7f));
+        assert_eq!(fh4_unsigned(&mut r), Some(0x1234));
+        assert_eq!(fh4_unsigned(&mut r), Some(0x1234_5678));
+        assert_eq!(r.va, DATA + 8);
+    }
+
+    #[test]
+    fn fh4_try_blocks_map_through_ip_states() {
+        let mut img = Image::new();
+        let (fi, ip_map, try_map, handlers, td) = (
+            DATA + 0x100,
+            DATA + 0x140,
+            DATA + 0x180,
+            DATA + 0x1c0,
+            DATA + 0x200,
+        );
+        // FuncInfo4: TryBlockMap + UnwindMap, dispUnwindMap, dispTryBlockMap, dispIPtoStateMap
+        img.bytes(fi, &[FI4_UNWIND_MAP | FI4_TRY_BLOCK_MAP]);
+        img.u32(fi + 1, rva(DATA + 0x300));
+        img.u32(fi + 5, rva(try_map));
+        img.u32(fi + 9, rva(ip_map));
+        // IP map: 0x00 -> -1, 0x10 -> 0, 0x28 -> -1 (state + 1, compressed)
+        img.bytes(
+            ip_map,
+            &[3 << 1, 0, 0 << 1, 0x10 << 1, 1 << 1, 0x18 << 1, 0],
+        );
+        // one try block: states 0..=0, catchHigh 1, handler array
+        img.bytes(try_map, &[1 << 1, 0, 0, 1 << 1]);
+        img.u32(try_map + 4, rva(handlers));
+        // HandlerMap4: two handlers: typed, then catch (...)
+        img.bytes(handlers, &[2 << 1, HT4_DISP_TYPE]);
+        img.u32(handlers + 2, rva(td));
+        img.u32(handlers + 6, rva(CODE + 0x180));
+        img.bytes(handlers + 10, &[0]);
+        img.u32(handlers + 11, rva(CODE + 0x1a0));
+        img.bytes(td + 16, b".?AVParseError@eh@@\0");
+        let mem = img.memory();
+        let rec = record(CODE, CODE + 0x100, 0);
+        let regions = func_info4(&mem, fi, &rec).unwrap();
+        assert_eq!(regions.len(), 1);
+        assert_eq!(
+            (regions[0].start, regions[0].end),
+            (CODE + 0x10, CODE + 0x28)
+        );
+        let h = &regions[0].handlers;
+        assert_eq!(h[0].type_name.as_deref(), Some("eh::ParseError"));
+        assert_eq!(h[0].target, Some(CODE + 0x180));
+        assert_eq!(h[1].kind, HandlerKind::CatchAll);
+
+        let info = EhInfo {
+            functions: vec![EhFunction {
+                start: CODE,
+                end: CODE + 0x100,
+                scheme: EhScheme::MsvcCxx,
+                personality: None,
+                personality_name: None,
+                table: fi,
+                regions,
+            }],
+        };
+        let funclets: Vec<u64> = info.handler_functions().into_iter().collect();
+        assert_eq!(funclets, [CODE + 0x180, CODE + 0x1a0]);
+        assert!(info.landing_pads().is_empty());
+    }
+
+    #[test]
+    fn fh3_func_info() {
+        let mut img = Image::new();
+        let (fi, try_map, handlers, ip_map) =
+            (DATA + 0x100, DATA + 0x140, DATA + 0x180, DATA + 0x1c0);
+        img.u32(fi, EH_MAGIC_NUMBER3);
+        img.u32(fi + 4, 2); // maxState
+        img.u32(fi + 12, 1); // nTryBlocks
+        img.u32(fi + 16, rva(try_map));
+        img.u32(fi + 20, 3); // nIPMapEntries
+        img.u32(fi + 24, rva(ip_map));
+        // tryLow 0, tryHigh 0, catchHigh 1, nCatches 1
+        img.u32(try_map + 8, 1);
+        img.u32(try_map + 12, 1);
+        img.u32(try_map + 16, rva(handlers));
+        img.u32(handlers + 12, rva(CODE + 0x1c0));
+        for (i, (ip, state)) in [(0u64, -1i32), (0x20, 0), (0x30, -1)].iter().enumerate() {
+            img.u32(ip_map + 8 * i as u64, rva(CODE + ip));
+            img.u32(ip_map + 8 * i as u64 + 4, *state as u32);
+        }
+        let mem = img.memory();
+        let rec = record(CODE, CODE + 0x80, 0);
+        let regions = func_info3(&mem, fi, &rec).unwrap();
+        assert_eq!(regions.len(), 1);
+        assert_eq!(
+            (regions[0].start, regions[0].end),
+            (CODE + 0x20, CODE + 0x30)
+        );
+        assert_eq!(regions[0].handlers[0].kind, HandlerKind::CatchAll);
+        // Not FuncInfo4, and not a scope table.
+        let data_rec = {
+            img.u32(DATA + 0x380, rva(fi));
+            record(CODE, CODE + 0x80, DATA + 0x380)
+        };
+        let mem = img.memory();
+        assert_eq!(
+            guess_handler(&mem, &[&data_rec]),
+            Some(PeHandler::FuncInfo3)
+        );
+    }
+
+    #[test]
+    fn handler_names_pick_table_formats() {
+        assert_eq!(
+            PeHandler::from_name("__C_specific_handler"),
+            Ok(Some(PeHandler::ScopeTable))
+        );
+        assert_eq!(
+            PeHandler::from_name("__imp___CxxFrameHandler4"),
+            Ok(Some(PeHandler::FuncInfo4))
+        );
+        assert_eq!(
+            PeHandler::from_name("__CxxFrameHandler3"),
+            Ok(Some(PeHandler::FuncInfo3))
+        );
+        assert_eq!(
+            PeHandler::from_name("__gxx_personality_seh0"),
+            Ok(Some(PeHandler::Lsda))
+        );
+        assert_eq!(PeHandler::from_name("__GSHandlerCheck"), Err(()));
+        assert_eq!(PeHandler::from_name("my_handler"), Ok(None));
+    }
+
+    #[test]
+    fn msvc_fundamental_catch_types() {
+        assert_eq!(msvc_builtin_type(".H"), Some("int"));
+        assert_eq!(msvc_builtin_type("._N"), Some("bool"));
+        assert_eq!(msvc_builtin_type(".?AVParseError@eh@@"), None);
+    }
+}
@@ -4,7 +4,9 @@
 //! and mapping virtual addresses to file offsets across common formats (ELF/PE/Mach-O).
 //! Implementations are bounded and deterministic and avoid allocating large buffers.
 
+use crate::core::address_map::{AddressMap, MappedRegion};
 use crate::core::binary::{Arch, Endianness, Format};
+use crate::formats::object_image::{section_perms, segment_perms};
 use object::{ObjectSection, ObjectSegment};
 
 /// Entry info returned by `detect_entry`.
@@ -82,41 +84,98 @@ pub fn detect_entry(data: &[u8]) -> Option<EntryInfo> {
     })
 }
 
-/// Map an arbitrary virtual address to a file offset using segments, then sections.
-/// Returns Some(file_offset) if the VA is within a mapped file-backed region; otherwise None.
-pub fn va_to_file_offset(data: &[u8], va: u64) -> Option<usize> {
+/// Build an `AddressMap` for an ELF/PE/Mach-O image.
+///
+/// Segments (program headers, PE sections, Mach-O segments as exposed by
+/// `object`) are preferred; section headers are used as a fallback for
+/// images without segments, such as relocatable objects.
+pub fn build_address_map(data: &[u8]) -> Option<AddressMap> {
     use object::read::Object;
     let obj = object::read::File::parse(data).ok()?;
-    // Try program headers (segments) first
+    let bits = if obj.is_64() { 64 } else { 32 };
+    // RVAs are a PE notion; Mach-O offsets are conventionally taken from
+    // the `__TEXT` segment. ELF images have no image base.
+    let image_base = match obj.format() {
+        object::BinaryFormat::Pe => Some(obj.relative_address_base()),
+        object::BinaryFormat::MachO => obj
+            .segments()
+            .find(|s| s.name().ok().flatten() == Some("__TEXT"))
+            .map(|s| s.address()),
+        _ => None,
+    };
+    let mut map = AddressMap::new(image_base, bits);
     for seg in obj.segments() {
-        let addr = seg.address();
-        let size = seg.size();
-        if size == 0 {
-            continue;
-        }
-        if va >= addr && va < addr.saturating_add(size) {
-            let (off, _sz) = seg.file_range();
-            let delta = va - addr;
-            if let Some(v) = off.checked_add(delta) {
-                return Some(v as usize);
-            }
-        }
+        let (off, file_size) = seg.file_range();
+        let name = seg.name().ok().flatten().map(str::to_string);
+        map.add_region(
+            MappedRegion::new(name, seg.address(), seg.size(), off, file_size)
+                .with_perms(segment_perms(seg.flags())),
+        );
     }
-    // Fallback to sections
-    for sec in obj.sections() {
-        let addr = sec.address();
-        let size = sec.size();
-        if size == 0 {
-            continue;
-        }
-        if va >= addr && va < addr.saturating_add(size) {
-            if let Some((off, _sz)) = sec.file_range() {
-                let delta = va - addr;
-                if let Some(v) = off.checked_add(delta) {
-                    return Some(v as usize);
-                }
+    if map.is_empty() {
+        for sec in obj.sections() {
+            if let Some((off, file_size)) = sec.file_range() {
+                let name = sec.name().ok().map(str::to_string);
+                map.add_region(
+                    MappedRegion::new(name, sec.address(), sec.size(), off, file_size)
+                        .with_perms(section_perms(sec.kind(), sec.flags())),
+                );
             }
         }
     }
-    None
+    Some(map)
+}
+
+/// Map an arbitrary virtual address to a file offset using segments, then sections.
+/// Returns Some(file_offset) if the VA is within a mapped file-backed region; otherwise None.
+pub fn va_to_file_offset(data: &[u8], va: u64) -> Option<usize> {
+    build_address_map(data)?
+        .va_to_file_offset(va)
+        .ok()
+        .map(|v| v as usize)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::core::address_map::AddressMapError;
+
+    #[test]
+    fn elf_entry_roundtrips_through_address_map() {
+        let path = "samples/binaries/platforms/linux/amd64/export/rust/hello-rust-release";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        let info = detect_entry(&data).expect("entry");
+        let map = build_address_map(&data).expect("map");
+        assert_eq!(map.bits, 64);
+        assert!(map.image_base.is_none());
+        let off = map
+            .va_to_file_offset(info.entry_va)
+            .expect("entry is file-backed");
+        assert_eq!(Some(off as usize), info.file_offset);
+        assert_eq!(map.file_offset_to_va(off).unwrap(), info.entry_va);
+        assert!(matches!(
+            map.va_to_file_offset(u64::MAX - 1),
+            Err(AddressMapError::Unmapped { .. })
+        ));
+    }
+
+    #[test]
+    fn pe_rva_conversion_uses_image_base() {
+        let path =
+            "samples/binaries/platforms/windows/i386/export/windows/i686/O3/hello-c-mingw32-O3.exe";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        let info = detect_entry(&data).expect("entry");
+        let map = build_address_map(&data).expect("map");
+        let base = map.image_base.expect("PE has an image base");
+        let rva = map.va_to_rva(info.entry_va).unwrap();
+        assert_eq!(rva, info.entry_va - base);
+        assert_eq!(
+            map.rva_to_file_offset(rva).ok().map(|v| v as usize),
+            va_to_file_offset(&data, info.entry_va)
+        );
+    }
 }
@@ -0,0 +1,373 @@
+//! Go build information (`go.buildinfo`).
+//!
+//! Since Go 1.13 the linker embeds the toolchain version and the module
+//! graph the binary was built from, and since Go 1.18 the build settings
+//! (`-ldflags`, `CGO_ENABLED`, VCS revision, ...). `go version -m` reads
+//! the same blob. It survives `-ldflags="-s -w"`: the runtime serves it to
+//! `runtime/debug.ReadBuildInfo`, so only a deliberate rewrite removes it.
+//!
+//! Layout (`debug/buildinfo`): a 16-byte aligned 32-byte header
+//!
+//! ```text
+//! [14]u8 magic = "\xff Go buildinf:"
+//! u8     ptrSize
+//! u8     flags      bit 0: big endian, bit 1: strings inline (Go 1.18+)
+//! usize  versPtr    \ only without the inline bit: addresses of
+//! usize  modPtr     / Go string headers {data, len}
+//! ```
+//!
+//! With the inline bit the header is followed by two varint-length-prefixed
+//! strings, the version and the module info. The module info is framed by
+//! 16-byte sentinels and holds tab-separated lines:
+//!
+//! ```text
+//! path   example.com/cmd/tool
+//! mod    example.com/cmd   v1.2.3   h1:...
+//! dep    golang.org/x/sys  v0.1.0   h1:...
+//! =>     ../sys            (devel)              (replaces the line above)
+//! build  CGO_ENABLED=0
+//! ```
+
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::mapped::MappedImage;
+
+const MAGIC: &[u8; 14] = b"\xff Go buildinf:";
+const HEADER_SIZE: usize = 32;
+const ALIGN: usize = 16;
+const FLAG_BIG_ENDIAN: u8 = 0x1;
+const FLAG_INLINE: u8 = 0x2;
+/// Longest version or module-info string accepted.
+const MAX_STRING: u64 = 1 << 20;
+
+/// One module of the build graph.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GoModule {
+    /// Module path, e.g. `golang.org/x/sys`.
+    pub path: String,
+    /// Module version, e.g. `v0.1.0`, or `(devel)` for the main module.
+    pub version: String,
+    /// `go.sum` hash, when recorded.
+    pub sum: Option<String>,
+    /// `replace` directive target, when the module was replaced.
+    pub replace: Option<Box<GoModule>>,
+}
+
+/// Build information of one Go binary.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GoBuildInfo {
+    /// Toolchain version, e.g. `go1.22.1`.
+    pub go_version: String,
+    /// Import path of the main package.
+    pub path: Option<String>,
+    /// The main module.
+    pub main: Option<GoModule>,
+    /// Dependency modules, in recorded order.
+    pub deps: Vec<GoModule>,
+    /// `build` settings as (key, value), e.g. `("GOOS", "linux")`.
+    pub settings: Vec<(String, String)>,
+}
+
+impl GoBuildInfo {
+    /// Value of build setting `key`.
+    pub fn setting(&self, key: &str) -> Option<&str> {
+        self.settings
+            .iter()
+            .find(|(k, _)| k == key)
+            .map(|(_, v)| v.as_str())
+    }
+}
+
+/// Find and decode the build information of a Go binary. `None` when the
+/// blob is absent or malformed.
+pub fn find_go_buildinfo(data: &[u8]) -> Option<GoBuildInfo> {
+    // The blob starts a data section, so it is 16-byte aligned in the file
+    // as well; unaligned hits are string literals (e.g. in the toolchain).
+    memchr::memmem::find_iter(data, MAGIC)
+        .filter(|off| off % ALIGN == 0)
+        .find_map(|off| decode(data, off))
+}
+
+fn decode(data: &[u8], off: usize) -> Option<GoBuildInfo> {
+    let header = data.get(off..off + HEADER_SIZE)?;
+    let flags = header[15];
+    let (version, modinfo) = if flags & FLAG_INLINE != 0 {
+        let (version, n) = varint_bytes(data.get(off + HEADER_SIZE..)?)?;
+        let (modinfo, _) = varint_bytes(data.get(off + HEADER_SIZE + n..)?)?;
+        (version.to_vec(), modinfo.to_vec())
+    } else {
+        pointer_strings(data, header)?
+    };
+    if !(version.starts_with(b"go") || version.starts_with(b"devel")) {
+        return None;
+    }
+    Some(parse_modinfo(
+        &String::from_utf8_lossy(&version),
+        &String::from_utf8_lossy(unframe(&modinfo)),
+    ))
+}
+
+/// A uvarint length followed by that many bytes: (bytes, bytes consumed).
+fn varint_bytes(b: &[u8]) -> Option<(&[u8], usize)> {
+    let (mut len, mut shift, mut n) = (0u64, 0, 0);
+    loop {
+        let byte = *b.get(n)?;
+        n += 1;
+        len |= ((byte & 0x7f) as u64) << shift;
+        if byte & 0x80 == 0 {
+            break;
+        }
+        shift += 7;
+        if shift > 28 {
+            return None;
+        }
+    }
+    if len > MAX_STRING {
+        return None;
+    }
+    Some((b.get(n..n + len as usize)?, n + len as usize))
+}
+
+/// Pre-1.18 header: `versPtr` and `modPtr` address Go string headers.
+fn pointer_strings(data: &[u8], header: &[u8]) -> Option<(Vec<u8>, Vec<u8>)> {
+    let ps = header[14] as usize;
+    if ps != 4 && ps != 8 {
+        return None;
+    }
+    let big = header[15] & FLAG_BIG_ENDIAN != 0;
+    let word = |b: &[u8]| -> u64 {
+        b[..ps].iter().enumerate().fold(0u64, |acc, (i, &x)| {
+            let shift = if big { (ps - 1 - i) * 8 } else { i * 8 };
+            acc | (x as u64) << shift
+        })
+    };
+    let mem = MappedImage::parse(data)?;
+    let string = |hdr: u64| -> Option<Vec<u8>> {
+        let ptr = mem.pointer(hdr)?;
+        let len = mem.uint(hdr + ps as u64, ps)?;
+        if len > MAX_STRING {
+            return None;
+        }
+        Some(mem.bytes(ptr, len as usize)?.to_vec())
+    };
+    Some((
+        string(word(&header[16..]))?,
+        string(word(&header[16 + ps..])).unwrap_or_default(),
+    ))
+}
+
+/// Strip the 16-byte binary sentinels around the module info; unframed
+/// text means the binary was built outside module mode and carries none.
+fn unframe(modinfo: &[u8]) -> &[u8] {
+    if modinfo.len() >= 33 && modinfo[modinfo.len() - 17] == b'\n' {
+        &modinfo[16..modinfo.len() - 16]
+    } else {
+        &[]
+    }
+}
+
+/// Parse module-info text as `runtime/debug.ParseBuildInfo` does, but keep
+/// going past malformed lines.
+pub fn parse_modinfo(go_version: &str, text: &str) -> GoBuildInfo {
+    let mut info = GoBuildInfo {
+        go_version: go_version.to_string(),
+        ..GoBuildInfo::default()
+    };
+    // Which module a `=>` line replaces: the main module or the last dep.
+    let mut last_is_dep = false;
+    for line in text.lines() {
+        let (kind, rest) = line.split_once('\t').unwrap_or((line, ""));
+        match kind {
+            "go" if info.go_version.is_empty() => info.go_version = rest.to_string(),
+            "path" => info.path = Some(rest.to_string()),
+            "mod" => {
+                info.main = module(rest);
+                last_is_dep = false;
+            }
+            "dep" => {
+                if let Some(m) = module(rest) {
+                    info.deps.push(m);
+                    last_is_dep = true;
+                }
+            }
+            "=>" => {
+                let target = if last_is_dep {
+                    info.deps.last_mut()
+                } else {
+                    info.main.as_mut()
+                };
+                if let Some(m) = target {
+                    m.replace = module(rest).map(Box::new);
+                }
+            }
+            "build" => info.settings.extend(setting(rest)),
+            _ => {}
+        }
+    }
+    info
+}
+
+/// `path \t version [\t sum]`
+fn module(fields: &str) -> Option<GoModule> {
+    let mut it = fields.split('\t');
+    let path = it.next().filter(|p| !p.is_empty())?;
+    Some(GoModule {
+        path: path.to_string(),
+        version: it.next().unwrap_or("").to_string(),
+        sum: it.next().filter(|s| !s.is_empty()).map(str::to_string),
+        replace: None,
+    })
+}
+
+/// `key=value`, either side Go-quoted when it holds spaces, quotes, tabs,
+/// newlines or (for keys) `=`.
+fn setting(kv: &str) -> Option<(String, String)> {
+    let (key, value) = if kv.starts_with('"') {
+        let end = quoted_len(kv)?;
+        (unquote(&kv[..end])?, kv[end..].strip_prefix('=')?)
+    } else {
+        let (k, v) = kv.split_once('=')?;
+        (k.to_string(), v)
+    };
+    if key.is_empty() {
+        return None;
+    }
+    let value = if value.starts_with('"') {
+        unquote(value)?
+    } else {
+        value.to_string()
+    };
+    Some((key, value))
+}
+
+/// Length of the leading double-quoted string of `s`, quotes included.
+fn quoted_len(s: &str) -> Option<usize> {
+    let mut escaped = false;
+    for (i, c) in s.char_indices().skip(1) {
+        match c {
+            _ if escaped => escaped = false,
+            '\\' => escaped = true,
+            '"' => return Some(i + 1),
+            _ => {}
+        }
+    }
+    None
+}
+
+/// Undo `strconv.Quote` for the escapes it emits.
+fn unquote(s: &str) -> Option<String> {
+    let inner = s.strip_prefix('"')?.strip_suffix('"')?;
+    let mut out = String::with_capacity(inner.len());
+    let mut chars = inner.chars();
+    while let Some(c) = chars.next() {
+        if c != '\\' {
+            out.push(c);
+            continue;
+        }
+        let hex = |chars: &mut std::str::Chars<'_>, n: usize| -> Option<char> {
+            let digits: String = chars.by_ref().take(n).collect();
+            char::from_u32(u32::from_str_radix(&digits, 16).ok()?)
+        };
+        out.push(match chars.next()? {
+            'n' => '\n',
+            't' => '\t',
+            'r' => '\r',
+            'a' => '\x07',
+            'b' => '\x08',
+            'f' => '\x0c',
+            'v' => '\x0b',
+            'x' => hex(&mut chars, 2)?,
+            'u' => hex(&mut chars, 4)?,
+            'U' => hex(&mut chars, 8)?,
+            other => other,
+        });
+    }
+    Some(out)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const MODINFO: &str = "path\texample.com/cmd/tool\n\
+        mod\texample.com/cmd\t(devel)\t\n\
+        dep\tgolang.org/x/sys\tv0.1.0\th1:abc=\n\
+        dep\tgolang.org/x/text\tv0.3.0\th1:def=\n\
+        =>\t../text\t(devel)\t\n\
+        build\t-ldflags=\"-s -w\"\n\
+        build\tCGO_ENABLED=0\n\
+        build\tvcs.revision=0123abcd\n";
+
+    /// An inline (Go 1.18+) blob at a 16-byte aligned offset.
+    fn blob(version: &str, modinfo: &str) -> Vec<u8> {
+        let mut b = vec![0u8; 48];
+        let mut header = MAGIC.to_vec();
+        header.extend_from_slice(&[8, FLAG_INLINE]);
+        header.resize(HEADER_SIZE, 0);
+        b.extend_from_slice(&header);
+        // The sentinels are arbitrary bytes, not UTF-8.
+        let mut framed = vec![0xf9; 16];
+        framed.extend_from_slice(modinfo.as_bytes());
+        framed.extend_from_slice(&[0x30; 16]);
+        for s in [version.as_bytes(), &framed] {
+            let mut len = s.len();
+            while len >= 0x80 {
+                b.push((len as u8 & 0x7f) | 0x80);
+                len >>= 7;
+            }
+            b.push(len as u8);
+            b.extend_from_slice(s);
+        }
+        b
+    }
+
+    #[test]
+    fn modinfo_lines_and_replacements() {
+        let info = parse_modinfo("go1.22.1", MODINFO);
+        assert_eq!(info.path.as_deref(), Some("example.com/cmd/tool"));
+        assert_eq!(info.main.as_ref().unwrap().version, "(devel)");
+        assert_eq!(info.deps.len(), 2);
+        assert_eq!(info.deps[0].sum.as_deref(), Some("h1:abc="));
+        assert!(info.deps[0].replace.is_none());
+        assert_eq!(info.deps[1].replace.as_ref().unwrap().path, "../text");
+        assert_eq!(info.setting("-ldflags"), Some("-s -w"));
+        assert_eq!(info.setting("CGO_ENABLED"), Some("0"));
+    }
+
+    #[test]
+    fn inline_blob_is_found_and_unframed() {
+        let data = blob("go1.22.1", MODINFO);
+        let info = find_go_buildinfo(&data).expect("buildinfo");
+        assert_eq!(info.go_version, "go1.22.1");
+        assert_eq!(info.deps[1].path, "golang.org/x/text");
+        assert_eq!(info.setting("vcs.revision"), Some("0123abcd"));
+    }
+
+    #[test]
+    fn unaligned_or_foreign_magic_is_ignored() {
+        let mut data = vec![0u8; 3];
+        data.extend(blob("go1.22.1", MODINFO));
+        assert!(find_go_buildinfo(&data).is_none());
+        assert!(find_go_buildinfo(&blob("not a version", MODINFO)).is_none());
+        // Outside module mode the module info is unframed and dropped.
+        let info = parse_modinfo("go1.22.1", "");
+        assert!(unframe(b"path\tcmd").is_empty());
+        assert_eq!(
+            info,
+            GoBuildInfo {
+                go_version: "go1.22.1".into(),
+                ..Default::default()
+            }
+        );
+    }
+
+    #[test]
+    fn quoted_settings() {
+        assert_eq!(
+            setting("\"key with space\"=\"a\\tb\\u00e9\""),
+            Some(("key with space".into(), "a\tb\u{e9}".into()))
+        );
+        assert_eq!(setting("=x"), None);
+        assert_eq!(setting("noequals"), None);
+    }
+}
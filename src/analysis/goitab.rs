@@ -0,0 +1,424 @@
+//! Go interface tables (itabs) and interface method resolution (#235).
+//!
+//! A non-empty Go interface value is an (itab, data) pair. The itab names
+//! the interface and the concrete type and lists the concrete type's
+//! methods in the interface's method order, so every method call through an
+//! interface is an indirect call through one itab slot:
+//!
+//! ```text
+//! mov  rcx, [rax+0x18]   ; itab.Fun[0]   (rax = itab)
+//! call rcx
+//! ```
+//!
+//! Without the itabs those calls have no target and the call graph of a Go
+//! binary falls apart into the goroutines' entry points. The linker emits
+//! one itab for every (interface, concrete type) conversion known at link
+//! time; they survive stripping because the runtime reads them for type
+//! switches and assertions.
+//!
+//! Layouts (`internal/abi`, stable since Go 1.18; `ps` is the pointer size):
+//!
+//! ```text
+//! ITab          { Inter *InterfaceType; Type *Type; Hash u32; Fun [n]uintptr }   Fun at 3*ps
+//! Type          { Size, PtrBytes uintptr; Hash u32; TFlag, Align, FieldAlign, Kind u8;
+//!                 Equal, GCData ptr; Str NameOff; PtrToThis TypeOff }            4*ps + 16 bytes
+//! InterfaceType { Type; PkgPath Name; Methods []Imethod }
+//! Imethod       { Name NameOff; Typ TypeOff }
+//! Name          { flags u8; len varint; bytes [len]u8 }
+//! ```
+//!
+//! Itabs are found by shape: an interface type pointer, a type pointer
+//! whose hash the itab repeats, and one code pointer per interface method.
+//! `NameOff`s are relative to `moduledata.types`; moduledata is the data
+//! word that points at the pclntab header (its first field), and `types` is
+//! whichever of its words resolves the itabs' names. Only the Go 1.18+
+//! pclntab magics are accepted, matching `gopclntab`.
+
+use std::collections::BTreeSet;
+
+use crate::analysis::gopclntab::pclntab_headers;
+use crate::analysis::mapped::MappedImage;
+
+/// `abi.Interface`
+const KIND_INTERFACE: u8 = 20;
+const KIND_MASK: u8 = 0x1f;
+/// `abi.TFlagExtraStar`: `Str` carries a leading `*` to drop.
+const TFLAG_EXTRA_STAR: u8 = 1 << 1;
+/// moduledata words searched for `types`; the field sits well inside this.
+const MODULEDATA_WORDS: u64 = 64;
+const MAX_METHODS: u64 = 1024;
+const MAX_NAME: u64 = 4096;
+
+/// One interface method as implemented by the itab's concrete type.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GoItabMethod {
+    /// Method name, e.g. `Area`.
+    pub name: String,
+    /// Address of the concrete implementation.
+    pub target: u64,
+}
+
+/// One (interface, concrete type) itab.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GoItab {
+    pub va: u64,
+    /// Interface type, e.g. `main.Shape`.
+    pub interface: String,
+    /// Concrete type, e.g. `*main.Circle`.
+    pub concrete: String,
+    /// `Fun[i]` paired with the interface's `Methods[i]`.
+    pub methods: Vec<GoItabMethod>,
+}
+
+/// Every itab recovered from one binary.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct GoItabs {
+    pub ptr_size: usize,
+    pub itabs: Vec<GoItab>,
+}
+
+impl GoItabs {
+    /// Offset of `Fun[0]` within an itab.
+    pub fn fun_offset(&self) -> u64 {
+        3 * self.ptr_size as u64
+    }
+
+    /// Method slot an itab-relative load at `disp` reads, if it is one.
+    pub fn slot(&self, disp: i64) -> Option<usize> {
+        let rel = disp.checked_sub(self.fun_offset() as i64)?;
+        let ps = self.ptr_size as i64;
+        (rel >= 0 && rel % ps == 0).then_some((rel / ps) as usize)
+    }
+
+    /// The itab at `va`.
+    pub fn at(&self, va: u64) -> Option<&GoItab> {
+        self.itabs
+            .binary_search_by_key(&va, |t| t.va)
+            .ok()
+            .map(|i| &self.itabs[i])
+    }
+
+    /// The itab for `concrete` implementing `interface`.
+    pub fn find(&self, interface: &str, concrete: &str) -> Option<&GoItab> {
+        self.itabs
+            .iter()
+            .find(|t| t.interface == interface && t.concrete == concrete)
+    }
+
+    /// Every itab method that a call through `slot` of an unknown itab may
+    /// reach: `Fun[slot]` of each itab with more than `slot` methods.
+    pub fn slot_targets(&self, slot: usize) -> BTreeSet<u64> {
+        self.itabs
+            .iter()
+            .filter_map(|t| t.methods.get(slot))
+            .map(|m| m.target)
+            .collect()
+    }
+
+    /// Every concrete method address the itabs reference.
+    pub fn method_targets(&self) -> BTreeSet<u64> {
+        self.itabs
+            .iter()
+            .flat_map(|t| &t.methods)
+            .map(|m| m.target)
+            .collect()
+    }
+}
+
+/// Itab fields before names are resolved.
+pub(crate) struct RawItab {
+    va: u64,
+    pub inter: u64,
+    pub typ: u64,
+    /// (Methods pointer, method count)
+    imethods: (u64, u64),
+    fun: Vec<u64>,
+}
+
+/// Recover every itab of a Go binary. `None` when the image is not a Go
+/// 1.18+ binary or carries no itabs.
+pub fn recover_go_itabs(data: &[u8]) -> Option<GoItabs> {
+    let mem = MappedImage::parse(data)?;
+    let ps = mem.ptr_size as u64;
+    let moduledata = find_moduledata(&mem, data)?;
+
+    let raw: Vec<RawItab> = mem.scan(ps).filter_map(|va| raw_itab(&mem, va)).collect();
+    let lo = raw.iter().map(|t| t.inter.min(t.typ)).min()?;
+
+    // `types` is a lower bound of every type descriptor; of the moduledata
+    // words that are, take the one that resolves the most names.
+    let mut bases: Vec<u64> = (0..MODULEDATA_WORDS)
+        .filter_map(|i| mem.pointer(moduledata + i * ps))
+        .filter(|&w| w <= lo && mem.is_data(w))
+        .collect();
+    bases.sort_unstable_by(|a, b| b.cmp(a));
+    bases.dedup();
+    let resolved = |base: u64| {
+        raw.iter()
+            .take(32)
+            .filter(|t| type_name(&mem, base, t.inter).is_some())
+            .count()
+    };
+    let types = bases
+        .iter()
+        .copied()
+        .max_by_key(|&b| (resolved(b), std::cmp::Reverse(lo - b)))
+        .filter(|&b| resolved(b) > 0)?;
+
+    let mut itabs: Vec<GoItab> = raw
+        .iter()
+        .filter_map(|t| {
+            let interface = type_name(&mem, types, t.inter)?;
+            let concrete = type_name(&mem, types, t.typ)?;
+            let methods = (0..t.imethods.1)
+                .zip(&t.fun)
+                .map(|(i, &target)| {
+                    let off = mem.int(t.imethods.0 + i * 8, 4)?;
+                    Some(GoItabMethod {
+                        name: name_at(&mem, types.checked_add_signed(off)?)?,
+                        target,
+                    })
+                })
+                .collect::<Option<Vec<_>>>()?;
+            Some(GoItab {
+                va: t.va,
+                interface,
+                concrete,
+                methods,
+            })
+        })
+        .collect();
+    itabs.sort_by_key(|t| t.va);
+    (!itabs.is_empty()).then_some(GoItabs {
+        ptr_size: mem.ptr_size,
+        itabs,
+    })
+}
+
+/// Address of the Go 1.18+ moduledata: the data word pointing at a pclntab
+/// header whose next word points at that header's function-name table.
+pub(crate) fn find_moduledata(mem: &MappedImage<'_>, data: &[u8]) -> Option<u64> {
+    let ps = mem.ptr_size as u64;
+    let headers = pclntab_headers(mem, data);
+    mem.scan(ps).find(|&va| {
+        mem.pointer(va).is_some_and(|pcln| {
+            headers.contains(&pcln)
+                && mem
+                    .pointer(pcln + 8 + 3 * ps)
+                    .zip(mem.pointer(va + ps))
+                    .is_some_and(|(off, names)| names == pcln.wrapping_add(off))
+        })
+    })
+}
+
+/// Itab-shaped data at `va`, before any name is resolved.
+pub(crate) fn raw_itab(mem: &MappedImage<'_>, va: u64) -> Option<RawItab> {
+    let ps = mem.ptr_size as u64;
+    let inter = mem.pointer(va)?;
+    let typ = mem.pointer(va + ps)?;
+    if !mem.is_data(inter) || !mem.is_data(typ) {
+        return None;
+    }
+    let kind = mem.bytes(inter + 2 * ps + 7, 1)?[0];
+    if kind & KIND_MASK != KIND_INTERFACE || mem.u32(va + 2 * ps)? != mem.u32(typ + 2 * ps)? {
+        return None;
+    }
+    let slice = inter + 4 * ps + 16 + ps;
+    let (methods, len, cap) = (
+        mem.pointer(slice)?,
+        mem.uint(slice + ps, mem.ptr_size)?,
+        mem.uint(slice + 2 * ps, mem.ptr_size)?,
+    );
+    if len == 0 || len != cap || len > MAX_METHODS || !mem.is_data(methods) {
+        return None;
+    }
+    let fun = (0..len)
+        .map(|i| {
+            mem.pointer(va + 3 * ps + i * ps)
+                .filter(|&f| mem.is_code(f))
+        })
+        .collect::<Option<Vec<_>>>()?;
+    Some(RawItab {
+        va,
+        inter,
+        typ,
+        imethods: (methods, len),
+        fun,
+    })
+}
+
+/// `Type.Str` of the type descriptor at `typ`, resolved against `types`.
+pub(crate) fn type_name(mem: &MappedImage<'_>, types: u64, typ: u64) -> Option<String> {
+    let ps = mem.ptr_size as u64;
+    let tflag = mem.bytes(typ + 2 * ps + 4, 1)?[0];
+    let off = mem.int(typ + 4 * ps + 8, 4)?;
+    let name = name_at(mem, types.checked_add_signed(off)?)?;
+    if tflag & TFLAG_EXTRA_STAR != 0 {
+        name.strip_prefix('*').map(str::to_string)
+    } else {
+        Some(name)
+    }
+}
+
+/// The `abi.Name` at `va`: a flags byte, a varint length, then the bytes.
+pub(crate) fn name_at(mem: &MappedImage<'_>, va: u64) -> Option<String> {
+    let (mut len, mut shift, mut p) = (0u64, 0, va + 1);
+    loop {
+        let b = mem.bytes(p, 1)?[0];
+        p += 1;
+        len |= ((b & 0x7f) as u64) << shift;
+        if b & 0x80 == 0 {
+            break;
+        }
+        shift += 7;
+        if shift > 21 {
+            return None;
+        }
+    }
+    if len == 0 || len > MAX_NAME {
+        return None;
+    }
+    let s = std::str::from_utf8(mem.bytes(p, len as usize)?).ok()?;
+    (!s.chars().any(char::is_control)).then(|| s.to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::analysis::mapped::Region;
+
+    /// A little-endian 64-bit image: one data region at 0x1000 holding
+    /// `bytes`, one code region at 0x8000.
+    fn image(bytes: &[u8]) -> MappedImage<'_> {
+        static CODE: [u8; 0x100] = [0xc3; 0x100];
+        let mut mem = MappedImage::new(8, false);
+        mem.regions.push(Region {
+            va: 0x1000,
+            bytes,
+            exec: false,
+        });
+        mem.regions.push(Region {
+            va: 0x8000,
+            bytes: &CODE,
+            exec: true,
+        });
+        mem
+    }
+
+    fn put(buf: &mut [u8], off: usize, bytes: &[u8]) {
+        buf[off..off + bytes.len()].copy_from_slice(bytes);
+    }
+
+    fn name(buf: &mut [u8], off: usize, s: &str) {
+        buf[off] = 0;
+        buf[off + 1] = s.len() as u8;
+        put(buf, off + 2, s.as_bytes());
+    }
+
+    /// types at 0x1000: names, the `main.Shape` interface type, the
+    /// `*main.Circle` pointer type, and its itab. Named types store `*T`
+    /// with ExtraStar set so that `*T` can share the string.
+    fn shape_image() -> Vec<u8> {
+        let mut b = vec![0u8; 0x400];
+        name(&mut b, 0x10, "*main.Shape");
+        name(&mut b, 0x20, "*main.Circle");
+        name(&mut b, 0x30, "Area");
+        name(&mut b, 0x38, "Perimeter");
+        // InterfaceType at 0x100: TFlag, Kind, Str, Methods {ptr, len, cap}.
+        b[0x100 + 20] = TFLAG_EXTRA_STAR;
+        b[0x100 + 23] = KIND_INTERFACE;
+        put(&mut b, 0x100 + 40, &0x10u32.to_le_bytes());
+        put(&mut b, 0x100 + 56, &0x1200u64.to_le_bytes());
+        put(&mut b, 0x100 + 64, &2u64.to_le_bytes());
+        put(&mut b, 0x100 + 72, &2u64.to_le_bytes());
+        // Imethods at 0x200.
+        put(&mut b, 0x200, &0x30u32.to_le_bytes());
+        put(&mut b, 0x208, &0x38u32.to_le_bytes());
+        // Pointer Type at 0x180: Hash, Kind, Str.
+        put(&mut b, 0x180 + 16, &0xfeedu32.to_le_bytes());
+        b[0x180 + 23] = 22;
+        put(&mut b, 0x180 + 40, &0x20u32.to_le_bytes());
+        // ITab at 0x300.
+        put(&mut b, 0x300, &0x1100u64.to_le_bytes());
+        put(&mut b, 0x308, &0x1180u64.to_le_bytes());
+        put(&mut b, 0x310, &0xfeedu32.to_le_bytes());
+        put(&mut b, 0x318, &0x8010u64.to_le_bytes());
+        put(&mut b, 0x320, &0x8040u64.to_le_bytes());
+        b
+    }
+
+    #[test]
+    fn itab_shape_is_validated() {
+        let bytes = shape_image();
+        let mem = image(&bytes);
+        let raw = raw_itab(&mem, 0x1300).expect("itab");
+        assert_eq!((raw.inter, raw.typ), (0x1100, 0x1180));
+        assert_eq!(raw.imethods, (0x1200, 2));
+        assert_eq!(raw.fun, vec![0x8010, 0x8040]);
+        // Everything else in the image is rejected.
+        let hits: Vec<u64> = mem
+            .scan(8)
+            .filter(|&va| raw_itab(&mem, va).is_some())
+            .collect();
+        assert_eq!(hits, vec![0x1300]);
+    }
+
+    #[test]
+    fn itab_hash_must_match_the_type() {
+        let mut bytes = shape_image();
+        put(&mut bytes, 0x310, &0xbeefu32.to_le_bytes());
+        assert!(raw_itab(&image(&bytes), 0x1300).is_none());
+    }
+
+    #[test]
+    fn unimplemented_itab_is_rejected() {
+        // Fun[0] == 0 marks a failed assertion cached by the runtime.
+        let mut bytes = shape_image();
+        put(&mut bytes, 0x318, &0u64.to_le_bytes());
+        assert!(raw_itab(&image(&bytes), 0x1300).is_none());
+    }
+
+    #[test]
+    fn names_resolve_against_types() {
+        let bytes = shape_image();
+        let mem = image(&bytes);
+        // ExtraStar drops the leading '*' of the stored name.
+        assert_eq!(
+            type_name(&mem, 0x1000, 0x1100).as_deref(),
+            Some("main.Shape")
+        );
+        assert_eq!(
+            type_name(&mem, 0x1000, 0x1180).as_deref(),
+            Some("*main.Circle")
+        );
+        assert_eq!(name_at(&mem, 0x1030).as_deref(), Some("Area"));
+    }
+
+    #[test]
+    fn multi_byte_varint_lengths() {
+        let mut bytes = vec![0u8; 0x200];
+        bytes[1] = 0x82;
+        bytes[2] = 0x01; // 130
+        put(&mut bytes, 3, &[b'x'; 130]);
+        let mem = image(&bytes);
+        assert_eq!(name_at(&mem, 0x1000).map(|s| s.len()), Some(130));
+    }
+
+    #[test]
+    fn slots_follow_the_fun_array() {
+        let itabs = GoItabs {
+            ptr_size: 8,
+            itabs: Vec::new(),
+        };
+        assert_eq!(itabs.slot(0x18), Some(0));
+        assert_eq!(itabs.slot(0x28), Some(2));
+        assert_eq!(itabs.slot(0x10), None);
+        assert_eq!(itabs.slot(0x1c), None);
+        let itabs32 = GoItabs {
+            ptr_size: 4,
+            itabs: Vec::new(),
+        };
+        assert_eq!(itabs32.slot(0xc), Some(0));
+        assert_eq!(itabs32.slot(0x14), Some(2));
+    }
+}
@@ -0,0 +1,575 @@
+//! Go runtime metadata of stripped binaries.
+//!
+//! `-ldflags="-s -w"` removes the symbol table and DWARF, but a Go binary
+//! cannot run without the tables the runtime itself reads, and together
+//! they describe the program almost as well:
+//!
+//! * pclntab (`gopclntab`) - every function's name and entry, and the
+//!   pc -> file:line tables tracebacks print;
+//! * `go.buildinfo` (`gobuildinfo`) - toolchain version, main module,
+//!   dependency modules with versions and sums, and build settings;
+//! * type descriptors - every type the program converts to an interface
+//!   or reflects on, named by moduledata. Go 1.18-1.26 list them in
+//!   `moduledata.typelinks` (`[]int32` offsets from `types`); Go 1.27
+//!   dropped that slice and records `typedesclen`, the length of the run of
+//!   descriptors that starts one pointer past `types`, which is walked by
+//!   descriptor size.
+//!
+//! Package paths are derived from the function names, split the way
+//! `debug/gosym` splits them, except that a dependency module path wins
+//! over the last-slash rule (`gopkg.in/yaml.v3.Marshal` is in
+//! `gopkg.in/yaml.v3`, not `gopkg.in/yaml`).
+
+use std::collections::{BTreeSet, HashSet};
+
+use object::{Object, ObjectSection};
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::gobuildinfo::{find_go_buildinfo, GoBuildInfo};
+use crate::analysis::goitab::{find_moduledata, raw_itab, type_name};
+use crate::analysis::gopclntab::{
+    pclntab_headers, text_section_start, GoLine, GoPclnError, Pclntab,
+};
+use crate::analysis::mapped::MappedImage;
+
+/// moduledata words searched for the type fields.
+const MODULEDATA_WORDS: u64 = 64;
+const MAX_TYPES: u64 = 1 << 20;
+/// Most element/field/parameter references followed per descriptor.
+const MAX_REFS: u64 = 4096;
+const KIND_MASK: u8 = 0x1f;
+/// `abi.TFlagUncommon`: an `UncommonType` follows the kind-specific part.
+const TFLAG_UNCOMMON: u8 = 1 << 0;
+
+/// One function recovered from pclntab.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GoFunction {
+    pub entry_va: u64,
+    /// First address past the function (the next function's entry).
+    pub end_va: u64,
+    pub name: String,
+    /// Source file of the entry instruction.
+    pub file: Option<String>,
+    /// Line of the `func` keyword (Go 1.20+) or of the entry instruction.
+    pub line: Option<u32>,
+    /// pc -> file:line rows; only filled when requested.
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub lines: Vec<GoLine>,
+}
+
+/// Everything recovered from a Go binary's runtime metadata.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GoMetadata {
+    pub ptr_size: usize,
+    /// pclntab header magic; 0 when the table was not found.
+    pub pclntab_magic: u32,
+    pub build: Option<GoBuildInfo>,
+    /// Functions in address order.
+    pub functions: Vec<GoFunction>,
+    /// Compilation source paths, sorted.
+    pub source_files: Vec<String>,
+    /// Package import paths of the functions, sorted.
+    pub packages: Vec<String>,
+    /// Type descriptor names (`main.Config`, `*main.Circle`,
+    /// `map[string]int`, ...), sorted.
+    pub types: Vec<String>,
+}
+
+impl GoMetadata {
+    /// Toolchain version from the build information.
+    pub fn go_version(&self) -> Option<&str> {
+        self.build.as_ref().map(|b| b.go_version.as_str())
+    }
+
+    /// The function containing `va`.
+    pub fn function_at(&self, va: u64) -> Option<&GoFunction> {
+        let i = self.functions.partition_point(|f| f.entry_va <= va);
+        self.functions[..i].last().filter(|f| va < f.end_va)
+    }
+
+    /// Packages outside the standard library (plus `main`).
+    pub fn first_party_packages(&self) -> impl Iterator<Item = &str> {
+        self.packages
+            .iter()
+            .map(String::as_str)
+            .filter(|p| !is_std_package(p))
+    }
+}
+
+/// Standard-library packages have no dot in their first path element;
+/// `main` is the program itself.
+pub fn is_std_package(pkg: &str) -> bool {
+    pkg != "main" && !pkg.split('/').next().unwrap_or(pkg).contains('.')
+}
+
+/// Package import path of Go symbol `name`, or `None` for linker-generated
+/// symbols (`type:.eq.T`, `go:buildid`).
+pub fn package_of<'a>(name: &'a str, modules: &[&str]) -> Option<&'a str> {
+    if name.starts_with("type:") || name.starts_with("go:") {
+        return None;
+    }
+    // Generic instantiations carry type arguments with their own paths.
+    let base = &name[..name.find('[').unwrap_or(name.len())];
+    if let Some(m) = modules
+        .iter()
+        .filter(|m| base.len() > m.len() && base.starts_with(*m))
+        .filter(|m| base.as_bytes()[m.len()] == b'.')
+        .max_by_key(|m| m.len())
+    {
+        return Some(&name[..m.len()]);
+    }
+    let slash = base.rfind('/').map_or(0, |i| i + 1);
+    let dot = base[slash..].find('.')?;
+    Some(&name[..slash + dot]).filter(|p| !p.is_empty())
+}
+
+/// Recover the runtime metadata of a Go binary. Line tables are decoded
+/// only when `with_lines` is set; they are large.
+///
+/// Fails with the pclntab error when there is neither a usable pclntab
+/// nor build information; an older pclntab layout next to build
+/// information still yields the build information.
+pub fn recover_go_metadata(data: &[u8], with_lines: bool) -> Result<GoMetadata, GoPclnError> {
+    let build = find_go_buildinfo(data);
+    let mem = MappedImage::parse(data);
+    let tab = match mem.as_ref() {
+        Some(mem) => mapped_pclntab(mem, data),
+        None => Err(GoPclnError::NoSection),
+    }
+    .or_else(|_| named_pclntab(data));
+    let mut meta = GoMetadata {
+        ptr_size: mem.as_ref().map_or(0, |m| m.ptr_size),
+        build,
+        ..GoMetadata::default()
+    };
+    let tab = match tab {
+        Ok(tab) => tab,
+        Err(GoPclnError::NoSection | GoPclnError::UnknownMagic(_)) if meta.build.is_some() => {
+            return Ok(meta)
+        }
+        Err(e) => return Err(e),
+    };
+
+    meta.ptr_size = tab.ptr_size;
+    meta.pclntab_magic = tab.magic;
+    meta.functions = (0..tab.nfunc)
+        .filter_map(|i| {
+            let entry_va = tab.entry(i)?;
+            let (file, line) = tab.position(i);
+            Some(GoFunction {
+                entry_va,
+                end_va: tab.entry(i + 1).unwrap_or(entry_va),
+                name: tab.name(i)?,
+                file,
+                line,
+                lines: if with_lines { tab.lines(i) } else { Vec::new() },
+            })
+        })
+        .collect();
+    meta.functions.sort_by_key(|f| f.entry_va);
+
+    meta.source_files = tab.files();
+    meta.source_files.sort();
+    meta.source_files.dedup();
+
+    let modules: Vec<&str> = meta
+        .build
+        .iter()
+        .flat_map(|b| b.main.iter().chain(&b.deps))
+        .map(|m| m.path.as_str())
+        .collect();
+    let mut packages: Vec<String> = meta
+        .functions
+        .iter()
+        .filter_map(|f| package_of(&f.name, &modules))
+        .map(str::to_string)
+        .collect();
+    packages.sort();
+    packages.dedup();
+    meta.packages = packages;
+
+    if let Some(mem) = mem.as_ref() {
+        meta.types = recover_type_names(mem, data);
+    }
+    Ok(meta)
+}
+
+/// pclntab through the mapped image, which also finds it inside PE
+/// `.rdata` and applies the relocation of `textStart` in PIE builds.
+fn mapped_pclntab<'a>(mem: &MappedImage<'a>, data: &[u8]) -> Result<Pclntab<'a>, GoPclnError> {
+    let va = pclntab_headers(mem, data)
+        .into_iter()
+        .min()
+        .ok_or(GoPclnError::NoSection)?;
+    let region = mem.region(va).ok_or(GoPclnError::NoSection)?;
+    let mut tab = Pclntab::parse(&region.bytes[(va - region.va) as usize..])?;
+    tab.text_start = mem
+        .pointer(va + 8 + 2 * tab.ptr_size as u64)
+        .filter(|&t| t != 0)
+        .or_else(|| text_section_start(&object::read::File::parse(data).ok()?))
+        .unwrap_or(0);
+    Ok(tab)
+}
+
+/// pclntab by section name, for images `MappedImage` does not map.
+fn named_pclntab(data: &[u8]) -> Result<Pclntab<'_>, GoPclnError> {
+    let obj = object::read::File::parse(data).map_err(|_| GoPclnError::NoSection)?;
+    let sec = obj
+        .sections()
+        .find(|s| {
+            matches!(
+                s.name().ok(),
+                Some(".gopclntab" | "__gopclntab" | "runtime.pclntab")
+            )
+        })
+        .ok_or(GoPclnError::NoSection)?;
+    let mut tab = Pclntab::parse(sec.data().map_err(|_| GoPclnError::NoSection)?)?;
+    if tab.text_start == 0 {
+        tab.text_start = text_section_start(&obj).unwrap_or(0);
+    }
+    Ok(tab)
+}
+
+/// Names of the program's type descriptors. The roots are the ones
+/// moduledata lists, by whichever of the `typelinks` and `typedesclen`
+/// layouts finds more, plus the types of every itab; named types are
+/// mostly reached from there through element, field and parameter types.
+pub(crate) fn recover_type_names(mem: &MappedImage<'_>, data: &[u8]) -> Vec<String> {
+    let Some(md) = find_moduledata(mem, data) else {
+        return Vec::new();
+    };
+    let ps = mem.ptr_size as u64;
+    let word = |i: u64| mem.pointer(md + i * ps);
+    let uint = |i: u64| mem.uint(md + i * ps, mem.ptr_size);
+
+    let mut roots: Vec<u64> = Vec::new();
+    let mut types_base = None;
+    for i in 0..MODULEDATA_WORDS - 2 {
+        let Some(types) = word(i).filter(|&t| mem.is_data(t)) else {
+            continue;
+        };
+        // Go 1.18-1.26: types, etypes, ..., typelinks []int32.
+        if let Some(etypes) = word(i + 1).filter(|&e| e > types) {
+            for j in i + 2..MODULEDATA_WORDS - 2 {
+                let found = typelinks(mem, types, etypes, word(j), uint(j + 1), uint(j + 2));
+                if found.len() > roots.len() {
+                    (roots, types_base) = (found, Some(types));
+                }
+            }
+        }
+        // Go 1.27+: types, typedesclen, etypes.
+        if let (Some(len), Some(etypes)) = (uint(i + 1), word(i + 2)) {
+            if len > 0 && types.checked_add(len).is_some_and(|end| end <= etypes) {
+                let found = walk_types(mem, types, types + len);
+                if found.len() > roots.len() {
+                    (roots, types_base) = (found, Some(types));
+                }
+            }
+        }
+    }
+    let Some(types) = types_base else {
+        return Vec::new();
+    };
+    roots.extend(
+        mem.scan(ps)
+            .filter_map(|va| raw_itab(mem, va))
+            .flat_map(|t| [t.inter, t.typ]),
+    );
+
+    let mut seen: HashSet<u64> = HashSet::new();
+    let mut names: BTreeSet<String> = BTreeSet::new();
+    while let Some(td) = roots.pop() {
+        if seen.len() as u64 >= MAX_TYPES || !seen.insert(td) {
+            continue;
+        }
+        if let Some(name) = type_name(mem, types, td) {
+            names.insert(name);
+            roots.extend(referenced_types(mem, td));
+        }
+    }
+    names.into_iter().collect()
+}
+
+/// Descriptors the descriptor at `td` points at: element, key, field and
+/// parameter types. These fields have the same offsets in every Go 1.18+
+/// layout.
+fn referenced_types(mem: &MappedImage<'_>, td: u64) -> Vec<u64> {
+    let ps = mem.ptr_size as u64;
+    let t = 4 * ps + 16;
+    let Some(kind) = mem.bytes(td + 2 * ps + 7, 1).map(|b| b[0] & KIND_MASK) else {
+        return Vec::new();
+    };
+    let ptrs = |va: u64, n: u64| -> Vec<u64> {
+        (0..n.min(MAX_REFS))
+            .filter_map(|k| mem.pointer(va + k * ps))
+            .collect()
+    };
+    match kind {
+        // Array { Elem, Slice }, Map { Key, Elem }
+        17 | 21 => ptrs(td + t, 2),
+        // Chan, Pointer, Slice { Elem }
+        18 | 22 | 23 => ptrs(td + t, 1),
+        // Func: parameter types follow the UncommonType, if any.
+        19 => {
+            let Some(n) = mem.uint(td + t, 2).zip(mem.uint(td + t + 2, 2)) else {
+                return Vec::new();
+            };
+            let uncommon = mem.bytes(td + 2 * ps + 4, 1).map_or(0, |b| {
+                if b[0] & TFLAG_UNCOMMON != 0 {
+                    16
+                } else {
+                    0
+                }
+            });
+            ptrs(
+                (td + t + 4).next_multiple_of(ps) + uncommon,
+                n.0 + (n.1 & 0x7fff),
+            )
+        }
+        // Struct: StructField { Name, Typ *Type, Offset } array.
+        25 => {
+            let (Some(fields), Some(n)) = (
+                mem.pointer(td + t + ps),
+                mem.uint(td + t + 2 * ps, mem.ptr_size),
+            ) else {
+                return Vec::new();
+            };
+            (0..n.min(MAX_REFS))
+                .filter_map(|k| mem.pointer(fields + k * 3 * ps + ps))
+                .collect()
+        }
+        _ => Vec::new(),
+    }
+}
+
+/// Type descriptor addresses of a `[]int32` slice `(ptr, len, cap)` whose
+/// every element is an offset from `types` naming a type before `etypes`.
+fn typelinks(
+    mem: &MappedImage<'_>,
+    types: u64,
+    etypes: u64,
+    ptr: Option<u64>,
+    len: Option<u64>,
+    cap: Option<u64>,
+) -> Vec<u64> {
+    let (Some(ptr), Some(len)) = (ptr, len) else {
+        return Vec::new();
+    };
+    if len == 0 || Some(len) != cap || len > MAX_TYPES || !mem.is_data(ptr) {
+        return Vec::new();
+    }
+    (0..len)
+        .map(|k| {
+            let off = u64::try_from(mem.int(ptr + 4 * k, 4)?).ok()?;
+            let typ = types.checked_add(off).filter(|&t| t < etypes)?;
+            type_name(mem, types, typ).map(|_| typ)
+        })
+        .collect::<Option<Vec<_>>>()
+        .unwrap_or_default()
+}
+
+/// Type descriptors laid end to end from one pointer past `types` up to
+/// `end`, stopping at the first that does not parse.
+fn walk_types(mem: &MappedImage<'_>, types: u64, end: u64) -> Vec<u64> {
+    let ps = mem.ptr_size as u64;
+    let mut out = Vec::new();
+    let mut td = types + ps;
+    while td < end && (out.len() as u64) < MAX_TYPES {
+        td = td.next_multiple_of(ps);
+        let Some(size) = descriptor_size(mem, td) else {
+            break;
+        };
+        if type_name(mem, types, td).is_none() {
+            break;
+        }
+        out.push(td);
+        td += size;
+    }
+    out
+}
+
+/// `abi.Type.DescriptorSize` of the descriptor at `td`: the kind-specific
+/// struct, the optional `UncommonType`, then the trailing arrays (func
+/// parameters, interface methods, struct fields, methods).
+fn descriptor_size(mem: &MappedImage<'_>, td: u64) -> Option<u64> {
+    let ps = mem.ptr_size as u64;
+    let t = 4 * ps + 16;
+    let tflag = mem.bytes(td + 2 * ps + 4, 1)?[0];
+    let kind = mem.bytes(td + 2 * ps + 7, 1)?[0] & KIND_MASK;
+    let (base, add) = match kind {
+        1..=16 | 24 | 26 => (t, 0),
+        // Array { Elem, Slice *Type; Len uintptr }
+        17 => (t + 3 * ps, 0),
+        // Chan { Elem *Type; Dir int }
+        18 => (t + 2 * ps, 0),
+        // Func { InCount, OutCount u16 } + [in+out]*Type
+        19 => {
+            let ins = mem.uint(td + t, 2)?;
+            let outs = mem.uint(td + t + 2, 2)? & 0x7fff;
+            ((t + 4).next_multiple_of(ps), (ins + outs) * ps)
+        }
+        // Interface { PkgPath Name; Methods []Imethod } + [n]Imethod
+        20 => (t + 4 * ps, mem.uint(td + t + 2 * ps, mem.ptr_size)? * 8),
+        // Map { Key, Elem, Group *Type; Hasher; GroupSize, KeysOff,
+        // KeyStride, ElemsOff, ElemStride, ElemOff uintptr; Flags u32 }
+        21 => ((t + 10 * ps + 4).next_multiple_of(ps), 0),
+        // Pointer { Elem }, Slice { Elem }
+        22 | 23 => (t + ps, 0),
+        // Struct { PkgPath Name; Fields []StructField } + [n]StructField
+        25 => (
+            t + 4 * ps,
+            mem.uint(td + t + 2 * ps, mem.ptr_size)? * 3 * ps,
+        ),
+        _ => return None,
+    };
+    // UncommonType { PkgPath u32; Mcount, Xcount u16; Moff, _ u32 } + [Mcount]Method
+    let uncommon = if tflag & TFLAG_UNCOMMON != 0 {
+        16 + mem.uint(td + base + 4, 2)? * 16
+    } else {
+        0
+    };
+    if add > MAX_TYPES * ps {
+        return None;
+    }
+    Some(base + uncommon + add)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::analysis::mapped::Region;
+
+    #[test]
+    fn packages_follow_gosym_with_module_override() {
+        let mods = ["gopkg.in/yaml.v3", "example.com/tool"];
+        assert_eq!(package_of("main.main", &mods), Some("main"));
+        assert_eq!(
+            package_of("sync/atomic.(*Int32).Add", &mods),
+            Some("sync/atomic")
+        );
+        assert_eq!(
+            package_of("gopkg.in/yaml.v3.Marshal", &mods),
+            Some("gopkg.in/yaml.v3")
+        );
+        assert_eq!(
+            package_of("gopkg.in/yaml.v3.Marshal", &[]),
+            Some("gopkg.in/yaml")
+        );
+        assert_eq!(
+            package_of("example.com/tool/cmd.Run[go.shape.string]", &mods),
+            Some("example.com/tool/cmd")
+        );
+        assert_eq!(package_of("type:.eq.main.T", &mods), None);
+        assert!(is_std_package("net/http") && is_std_package("vendor/golang.org/x/net"));
+        assert!(!is_std_package("main") && !is_std_package("github.com/a/b"));
+    }
+
+    #[test]
+    fn stripped_go_hello_metadata() {
+        let path = "samples/binaries/platforms/linux/amd64/export/go/hello-go";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        if !data.starts_with(b"\x7fELF") {
+            return; // Git LFS pointer
+        }
+        let meta = recover_go_metadata(&data, true).expect("go metadata");
+        let main = meta
+            .functions
+            .iter()
+            .find(|f| f.name == "main.main")
+            .expect("main.main");
+        assert!(main.file.as_deref().is_some_and(|f| f.ends_with(".go")));
+        assert!(main.line.is_some() && !main.lines.is_empty());
+        assert!(main
+            .lines
+            .iter()
+            .all(|l| (main.entry_va..main.end_va).contains(&l.va)));
+        assert_eq!(
+            meta.function_at(main.entry_va + 1).map(|f| &f.name),
+            Some(&main.name)
+        );
+        assert!(meta.go_version().is_some_and(|v| v.starts_with("go")));
+        assert!(meta.packages.iter().any(|p| p == "runtime"));
+        assert!(meta.types.iter().any(|t| t == "string"));
+    }
+
+    fn put(buf: &mut [u8], off: usize, bytes: &[u8]) {
+        buf[off..off + bytes.len()].copy_from_slice(bytes);
+    }
+
+    /// A 64-bit Type header at `off` of kind `kind` named by the `Name` at
+    /// `name_off` (relative to the buffer start, which is `types`).
+    fn type_at(buf: &mut [u8], off: usize, kind: u8, tflag: u8, name_off: u32) {
+        buf[off + 20] = tflag;
+        buf[off + 23] = kind;
+        put(buf, off + 40, &name_off.to_le_bytes());
+    }
+
+    fn name(buf: &mut [u8], off: usize, s: &str) {
+        buf[off + 1] = s.len() as u8;
+        put(buf, off + 2, s.as_bytes());
+    }
+
+    /// Descriptors laid out as Go 1.27 does: an int at types+8, a struct
+    /// with one field and two methods, a map, then a pointer.
+    fn type_run() -> Vec<u8> {
+        let mut b = vec![0u8; 0x400];
+        name(&mut b, 0x300, "int");
+        name(&mut b, 0x310, "main.Config");
+        name(&mut b, 0x330, "map[string]int");
+        name(&mut b, 0x350, "*main.Config");
+        type_at(&mut b, 0x08, 2, 0, 0x300);
+        // StructType at 0x38: Type(48) + PkgPath + Fields{ptr, len=1, cap}
+        // = 80, UncommonType 16 with Mcount=2, field 24, methods 2*16.
+        type_at(&mut b, 0x38, 25, TFLAG_UNCOMMON, 0x310);
+        put(&mut b, 0x38 + 48 + 16, &1u64.to_le_bytes());
+        put(&mut b, 0x38 + 80 + 4, &2u16.to_le_bytes());
+        // MapType at 0x38 + 80 + 16 + 24 + 32 = 0xd0: 48 + 84 -> 136 bytes.
+        type_at(&mut b, 0xd0, 21, 0, 0x330);
+        type_at(&mut b, 0x158, 22, 0, 0x350);
+        b
+    }
+
+    fn image(bytes: &[u8]) -> MappedImage<'_> {
+        let mut mem = MappedImage::new(8, false);
+        mem.regions.push(Region {
+            va: 0x1000,
+            bytes,
+            exec: false,
+        });
+        mem
+    }
+
+    #[test]
+    fn descriptors_are_walked_by_size() {
+        let bytes = type_run();
+        let mem = image(&bytes);
+        let found = walk_types(&mem, 0x1000, 0x1190);
+        assert_eq!(found, vec![0x1008, 0x1038, 0x10d0, 0x1158]);
+        let names: Vec<_> = found
+            .iter()
+            .filter_map(|&t| type_name(&mem, 0x1000, t))
+            .collect();
+        assert_eq!(
+            names,
+            ["int", "main.Config", "map[string]int", "*main.Config"]
+        );
+    }
+
+    #[test]
+    fn typelinks_must_all_resolve() {
+        let mut bytes = type_run();
+        // typelinks []int32 at 0x380: offsets of the four descriptors.
+        for (k, off) in [0x08u32, 0x38, 0xd0, 0x158].iter().enumerate() {
+            put(&mut bytes, 0x380 + 4 * k, &off.to_le_bytes());
+        }
+        let mem = image(&bytes);
+        let ok = typelinks(&mem, 0x1000, 0x1300, Some(0x1380), Some(4), Some(4));
+        assert_eq!(ok, vec![0x1008, 0x1038, 0x10d0, 0x1158]);
+        // One offset past etypes rejects the whole slice.
+        assert!(typelinks(&mem, 0x1000, 0x1100, Some(0x1380), Some(4), Some(4)).is_empty());
+        // len != cap is not a linker-built slice.
+        assert!(typelinks(&mem, 0x1000, 0x1300, Some(0x1380), Some(4), Some(8)).is_empty());
+    }
+}
@@ -43,7 +43,15 @@
 //! Each _func struct begins with `(entry_off u32, name_off i32)` — the
 //! name is a null-terminated string at `funcnametab_off + name_off`.
 
+use std::collections::HashSet;
+
 use object::{Object, ObjectSection};
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::mapped::MappedImage;
+
+/// Header magics of the supported (Go 1.18+) layouts.
+pub(crate) const PCLN_MAGICS: [u32; 2] = [0xfffffff0, 0xfffffff1];
 
 /// One function recovered from gopclntab.
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -88,95 +96,322 @@ pub fn extract_go_functions(data: &[u8]) -> Result<Vec<GoFunc>, GoPclnError> {
         .ok_or(GoPclnError::NoSection)?;
 
     let bytes = pcln.data().map_err(|_| GoPclnError::NoSection)?;
-    parse_pclntab(bytes)
+    let mut tab = Pclntab::parse(bytes)?;
+    if tab.text_start == 0 {
+        tab.text_start = text_section_start(&obj).unwrap_or(0);
+    }
+    walk_names(&tab, bytes)
+}
+
+/// Address of the text section, where the linker places `runtime.text`.
+/// Go 1.27 leaves the header's `textStart` zero (the runtime reads
+/// `moduledata.text` instead), as `debug/gosym` expects callers to.
+pub(crate) fn text_section_start(obj: &object::read::File<'_>) -> Option<u64> {
+    obj.sections()
+        .find(|s| matches!(s.name(), Ok(".text" | "__text")))
+        .map(|s| s.address())
+}
+
+fn walk_names(tab: &Pclntab<'_>, bytes: &[u8]) -> Result<Vec<GoFunc>, GoPclnError> {
+    let mut out: Vec<GoFunc> = Vec::with_capacity(tab.nfunc);
+    for i in 0..tab.nfunc {
+        // Skip malformed entries instead of failing the whole walk.
+        let Some(func) = tab.func_struct(i) else {
+            continue;
+        };
+        let name_off = tab.field(func, FUNC_NAME_OFF).unwrap_or(0) as i32;
+        if name_off < 0 || (name_off as usize) >= bytes.len() - tab.funcnametab {
+            return Err(GoPclnError::BadNameOffset {
+                name_off: name_off as u32,
+                table_size: bytes.len() - tab.funcnametab,
+            });
+        }
+        let name = tab
+            .cstr(tab.funcnametab + name_off as usize)
+            .unwrap_or_default();
+        out.push(GoFunc {
+            entry_va: tab.entry(i).unwrap_or(tab.text_start),
+            name,
+        });
+    }
+    Ok(out)
+}
+
+// `_func` field offsets (runtime/runtime2.go). `startLine` only exists in
+// the Go 1.20+ layout.
+const FUNC_ENTRY_OFF: usize = 0;
+const FUNC_NAME_OFF: usize = 4;
+const FUNC_PCFILE: usize = 20;
+const FUNC_PCLN: usize = 24;
+const FUNC_CU_OFFSET: usize = 32;
+const FUNC_START_LINE: usize = 36;
+
+/// One row of a function's pc -> (file, line) table: the instructions
+/// from `va` up to the next row's `va` belong to `file:line`.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GoLine {
+    pub va: u64,
+    pub file: String,
+    pub line: u32,
 }
 
-fn parse_pclntab(bytes: &[u8]) -> Result<Vec<GoFunc>, GoPclnError> {
-    if bytes.len() < 64 {
-        return Err(GoPclnError::Truncated("header"));
+/// A Go 1.18+ pclntab: the header and the tables its offsets locate.
+///
+/// Beyond names, `_func` carries `pcfile`/`pcln` offsets into `pctab` and
+/// a `cuOffset` into `cutab`. A pc-value table is a run of
+/// (zig-zag value delta, pc delta / quantum) varint pairs starting from
+/// value -1 at the function entry; `pcfile` values index the function's
+/// compilation unit in `cutab`, whose entries are offsets of
+/// NUL-terminated paths in `filetab`.
+pub struct Pclntab<'a> {
+    bytes: &'a [u8],
+    pub magic: u32,
+    /// Instruction size quantum (`minLC`): 1 on x86, 4 on arm64.
+    pub quantum: u8,
+    pub ptr_size: usize,
+    pub nfunc: usize,
+    pub nfiles: usize,
+    /// `runtime.text`; function entries are offsets from it. Zero in
+    /// Go 1.27 headers, see [`text_section_start`].
+    pub text_start: u64,
+    funcnametab: usize,
+    cutab: usize,
+    filetab: usize,
+    pctab: usize,
+    functab: usize,
+}
+
+impl<'a> Pclntab<'a> {
+    pub fn parse(bytes: &'a [u8]) -> Result<Self, GoPclnError> {
+        if bytes.len() < 64 {
+            return Err(GoPclnError::Truncated("header"));
+        }
+        let magic = u32::from_le_bytes(bytes[0..4].try_into().unwrap());
+        if !PCLN_MAGICS.contains(&magic) {
+            return Err(GoPclnError::UnknownMagic(magic));
+        }
+        // pad1 (u8), pad2 (u8), minLC (u8), ptrSize (u8)
+        let ptr_size = bytes[7] as usize;
+        if ptr_size != 8 && ptr_size != 4 {
+            return Err(GoPclnError::Truncated("ptrSize"));
+        }
+        // Header fields after the 8-byte (magic+pads+sizes) prefix, in order:
+        // nfunc, nfiles, textStart, funcnametab, cutab, filetab, pctab, pclntab.
+        let word = |i: usize| -> u64 {
+            let off = 8 + i * ptr_size;
+            if ptr_size == 8 {
+                u64::from_le_bytes(bytes[off..off + 8].try_into().unwrap())
+            } else {
+                u32::from_le_bytes(bytes[off..off + 4].try_into().unwrap()) as u64
+            }
+        };
+        let tab = Self {
+            bytes,
+            magic,
+            quantum: bytes[6].max(1),
+            ptr_size,
+            nfunc: word(0) as usize,
+            nfiles: word(1) as usize,
+            text_start: word(2),
+            funcnametab: word(3) as usize,
+            cutab: word(4) as usize,
+            filetab: word(5) as usize,
+            pctab: word(6) as usize,
+            functab: word(7) as usize,
+        };
+        let rows = tab.nfunc.checked_add(1).and_then(|n| n.checked_mul(8));
+        if rows
+            .and_then(|r| tab.functab.checked_add(r))
+            .is_none_or(|end| end > bytes.len())
+        {
+            return Err(GoPclnError::Truncated("pclntab"));
+        }
+        if tab.funcnametab >= bytes.len() {
+            return Err(GoPclnError::Truncated("funcnametab"));
+        }
+        Ok(tab)
     }
-    let magic = u32::from_le_bytes(bytes[0..4].try_into().unwrap());
-    match magic {
-        0xfffffff0 | 0xfffffff1 => {} // supported
-        other => return Err(GoPclnError::UnknownMagic(other)),
+
+    fn u32_at(&self, off: usize) -> Option<u32> {
+        let b = self.bytes.get(off..off.checked_add(4)?)?;
+        Some(u32::from_le_bytes(b.try_into().unwrap()))
     }
-    // pad1 (u8), pad2 (u8), minLC (u8), ptrSize (u8)
-    let ptr_size = bytes[7] as usize;
-    if ptr_size != 8 && ptr_size != 4 {
-        return Err(GoPclnError::Truncated("ptrSize"));
+
+    fn cstr(&self, off: usize) -> Option<String> {
+        let rest = self.bytes.get(off..)?;
+        let nul = rest.iter().position(|&b| b == 0).unwrap_or(rest.len());
+        Some(String::from_utf8_lossy(&rest[..nul]).into_owned())
+    }
+
+    /// Offset of function `i`'s `_func` struct.
+    fn func_struct(&self, i: usize) -> Option<usize> {
+        let off = self.functab + self.u32_at(self.functab + i * 8 + 4)? as usize;
+        (off + 8 <= self.bytes.len()).then_some(off)
+    }
+
+    fn field(&self, func: usize, field: usize) -> Option<u32> {
+        self.u32_at(func + field)
+    }
+
+    /// Entry address of function `i`; `i == nfunc` is the end of text.
+    pub fn entry(&self, i: usize) -> Option<u64> {
+        let off = self.u32_at(self.functab + i * 8 + FUNC_ENTRY_OFF)?;
+        Some(self.text_start.wrapping_add(off as u64))
     }
-    let read_usize = |off: usize| -> Result<u64, GoPclnError> {
-        if off + ptr_size > bytes.len() {
-            return Err(GoPclnError::Truncated("usize"));
+
+    /// Name of function `i`.
+    pub fn name(&self, i: usize) -> Option<String> {
+        let off = self.field(self.func_struct(i)?, FUNC_NAME_OFF)? as i32;
+        self.cstr(self.funcnametab.checked_add(usize::try_from(off).ok()?)?)
+    }
+
+    /// Every path in `filetab`, in table order.
+    pub fn files(&self) -> Vec<String> {
+        let mut out = Vec::new();
+        let mut off = self.filetab;
+        let end = self.pctab.min(self.bytes.len());
+        while out.len() < self.nfiles && off < end {
+            let Some(path) = self.cstr(off) else {
+                break;
+            };
+            off += path.len() + 1;
+            out.push(path);
         }
-        Ok(if ptr_size == 8 {
-            u64::from_le_bytes(bytes[off..off + 8].try_into().unwrap())
-        } else {
-            u32::from_le_bytes(bytes[off..off + 4].try_into().unwrap()) as u64
-        })
+        out
+    }
+
+    /// Source file of function `i`'s `pcfile` value `fileno`.
+    fn file(&self, func: usize, fileno: i32) -> Option<String> {
+        let cu = self.field(func, FUNC_CU_OFFSET)? as usize;
+        let slot = cu.checked_add(usize::try_from(fileno).ok()?)?;
+        let off = self.u32_at(self.cutab + slot * 4)?;
+        if off == u32::MAX {
+            return None;
+        }
+        self.cstr(self.filetab + off as usize)
+    }
+
+    /// Decode the pc-value table at `pctab + off` for a function at
+    /// `entry`: `(end_va, value)` runs, in address order.
+    fn pcvalue(&self, off: u32, entry: u64) -> Vec<(u64, i32)> {
+        let mut runs = Vec::new();
+        if off == 0 {
+            return runs;
+        }
+        let mut p = self.pctab + off as usize;
+        let (mut pc, mut val) = (entry, -1i32);
+        loop {
+            let Some((uvdelta, n)) = uvarint(self.bytes.get(p..).unwrap_or_default()) else {
+                break;
+            };
+            if uvdelta == 0 && !runs.is_empty() {
+                break;
+            }
+            p += n;
+            let Some((pcdelta, n)) = uvarint(self.bytes.get(p..).unwrap_or_default()) else {
+                break;
+            };
+            p += n;
+            val = val.wrapping_add((-((uvdelta & 1) as i32)) ^ ((uvdelta >> 1) as i32));
+            pc = pc.wrapping_add(pcdelta as u64 * self.quantum as u64);
+            runs.push((pc, val));
+            if runs.len() > MAX_PCVALUE_RUNS {
+                break;
+            }
+        }
+        runs
+    }
+
+    /// Declaration site of function `i`: its file and the line of its
+    /// `func` keyword (Go 1.20+) or of its first instruction.
+    pub fn position(&self, i: usize) -> (Option<String>, Option<u32>) {
+        let Some(func) = self.func_struct(i) else {
+            return (None, None);
+        };
+        let entry = self.entry(i).unwrap_or(self.text_start);
+        let file = self
+            .field(func, FUNC_PCFILE)
+            .and_then(|off| self.pcvalue(off, entry).first().copied())
+            .and_then(|(_, fileno)| self.file(func, fileno));
+        let start_line = match self.magic {
+            0xfffffff1 => self.field(func, FUNC_START_LINE).filter(|&l| l != 0),
+            _ => None,
+        };
+        let line = start_line.or_else(|| {
+            let off = self.field(func, FUNC_PCLN)?;
+            let line = self.pcvalue(off, entry).first()?.1;
+            u32::try_from(line).ok()
+        });
+        (file, line)
+    }
+
+    /// Function `i`'s pc -> (file, line) table, one row per change.
+    pub fn lines(&self, i: usize) -> Vec<GoLine> {
+        let Some(func) = self.func_struct(i) else {
+            return Vec::new();
+        };
+        let entry = self.entry(i).unwrap_or(self.text_start);
+        let files = self.pcvalue(self.field(func, FUNC_PCFILE).unwrap_or(0), entry);
+        let lines = self.pcvalue(self.field(func, FUNC_PCLN).unwrap_or(0), entry);
+        let (mut fi, mut li) = (0, 0);
+        let (mut va, mut out) = (entry, Vec::<GoLine>::new());
+        while fi < files.len() && li < lines.len() {
+            let (fend, fileno) = files[fi];
+            let (lend, line) = lines[li];
+            if let (Some(file), Ok(line)) = (self.file(func, fileno), u32::try_from(line)) {
+                if out.last().is_none_or(|l| l.line != line || l.file != file) {
+                    out.push(GoLine { va, file, line });
+                }
+            }
+            va = fend.min(lend);
+            if fend == va {
+                fi += 1;
+            }
+            if lend == va {
+                li += 1;
+            }
+        }
+        out
+    }
+}
+
+/// Addresses of mapped pclntab headers. ELF and Mach-O name the section;
+/// PE keeps pclntab inside `.rdata`, so the data is scanned for a header
+/// whose pointer size matches the image's.
+pub(crate) fn pclntab_headers(mem: &MappedImage<'_>, data: &[u8]) -> HashSet<u64> {
+    let ps = mem.ptr_size as u64;
+    let is_header = |va: u64| {
+        mem.u32(va).is_some_and(|m| PCLN_MAGICS.contains(&m))
+            && mem.bytes(va + 4, 4).is_some_and(|b| {
+                b[0] == 0 && b[1] == 0 && matches!(b[2], 1 | 2 | 4) && b[3] as u64 == ps
+            })
     };
+    let named = object::read::File::parse(data).ok().and_then(|obj| {
+        obj.sections()
+            .find(|s| matches!(s.name(), Ok(".gopclntab" | "__gopclntab")))
+            .map(|s| s.address())
+    });
+    match named.filter(|&va| is_header(va)) {
+        Some(va) => HashSet::from([va]),
+        None => mem.scan(ps).filter(|&va| is_header(va)).collect(),
+    }
+}
 
-    // Header field offsets after the 8-byte (magic+pads+sizes) prefix.
-    let mut p = 8;
-    let nfunc = read_usize(p)? as usize;
-    p += ptr_size;
-    let _nfiles = read_usize(p)?;
-    p += ptr_size;
-    let text_start = read_usize(p)?;
-    p += ptr_size;
-    let funcnametab_off = read_usize(p)? as usize;
-    p += ptr_size;
-    let _cu_off = read_usize(p)?;
-    p += ptr_size;
-    let _filetab_off = read_usize(p)?;
-    p += ptr_size;
-    let _pctab_off = read_usize(p)?;
-    p += ptr_size;
-    let pclntab_off = read_usize(p)? as usize;
-
-    if pclntab_off + (nfunc + 1) * 8 > bytes.len() {
-        return Err(GoPclnError::Truncated("pclntab"));
-    }
-    if funcnametab_off >= bytes.len() {
-        return Err(GoPclnError::Truncated("funcnametab"));
-    }
-    let funcnametab = &bytes[funcnametab_off..];
-
-    let mut out: Vec<GoFunc> = Vec::with_capacity(nfunc);
-    for i in 0..nfunc {
-        let row = pclntab_off + i * 8;
-        let entry_off = u32::from_le_bytes(bytes[row..row + 4].try_into().unwrap()) as u64;
-        let func_off = u32::from_le_bytes(bytes[row + 4..row + 8].try_into().unwrap()) as usize;
-
-        // _func struct begins at gopclntab_base + pclntab_off + func_off.
-        // Per Go's runtime/symtab.go, the _func layout starts with:
-        //   uint32 entryOff   // duplicate of the row's entry_off
-        //   int32  nameOff    // offset into funcnametab
-        let func_struct_off = pclntab_off + func_off;
-        if func_struct_off + 8 > bytes.len() {
-            continue; // skip malformed entries instead of failing the whole walk
-        }
-        let name_off = i32::from_le_bytes(
-            bytes[func_struct_off + 4..func_struct_off + 8]
-                .try_into()
-                .unwrap(),
-        );
-        if name_off < 0 || (name_off as usize) >= funcnametab.len() {
-            return Err(GoPclnError::BadNameOffset {
-                name_off: name_off as u32,
-                table_size: funcnametab.len(),
-            });
+/// Upper bound on decoded pc-value runs per table (a corrupt table can
+/// otherwise walk the whole section).
+const MAX_PCVALUE_RUNS: usize = 1 << 16;
+
+/// Unsigned LEB128, as Go's `readvarint`: (value, bytes read).
+fn uvarint(b: &[u8]) -> Option<(u32, usize)> {
+    let (mut v, mut shift) = (0u32, 0);
+    for (i, &byte) in b.iter().enumerate().take(5) {
+        v |= ((byte & 0x7f) as u32) << shift;
+        if byte & 0x80 == 0 {
+            return Some((v, i + 1));
         }
-        let name_bytes = &funcnametab[name_off as usize..];
-        let nul = name_bytes
-            .iter()
-            .position(|&b| b == 0)
-            .unwrap_or(name_bytes.len());
-        let name = String::from_utf8_lossy(&name_bytes[..nul]).into_owned();
-        let entry_va = text_start + entry_off;
-        out.push(GoFunc { entry_va, name });
+        shift += 7;
     }
-    Ok(out)
+    None
 }
 
 #[cfg(test)]
@@ -184,6 +419,55 @@ mod tests {
     use super::*;
     use std::path::Path;
 
+    /// A one-function Go 1.20 pclntab: `main.main` at 0x1000-0x1020 in
+    /// `/src/main.go`, declared on line 9, lines 10 then 12 (from 0x1010).
+    fn tiny_pclntab(magic: u32) -> Vec<u8> {
+        let mut b = vec![0u8; 200];
+        b[0..4].copy_from_slice(&magic.to_le_bytes());
+        b[6] = 1; // minLC
+        b[7] = 8; // ptrSize
+                  // nfunc, nfiles, textStart, funcnametab, cutab, filetab, pctab, functab
+        for (i, v) in [1u64, 1, 0x1000, 72, 84, 88, 104, 120].iter().enumerate() {
+            b[8 + i * 8..16 + i * 8].copy_from_slice(&v.to_le_bytes());
+        }
+        b[72..81].copy_from_slice(b"main.main");
+        b[88..100].copy_from_slice(b"/src/main.go");
+        // pcfile @1: file 0 for 0x20 bytes. pcln @4: line 10 for 0x10, then 12.
+        b[105..107].copy_from_slice(&[2, 0x20]);
+        b[108..112].copy_from_slice(&[22, 0x10, 4, 0x10]);
+        // functab rows (entryOff, funcOff) for main.main and the end of text.
+        b[124..128].copy_from_slice(&16u32.to_le_bytes());
+        b[128..132].copy_from_slice(&0x20u32.to_le_bytes());
+        let func = 136;
+        b[func + FUNC_PCFILE..func + FUNC_PCFILE + 4].copy_from_slice(&1u32.to_le_bytes());
+        b[func + FUNC_PCLN..func + FUNC_PCLN + 4].copy_from_slice(&4u32.to_le_bytes());
+        b[func + FUNC_START_LINE..func + FUNC_START_LINE + 4].copy_from_slice(&9u32.to_le_bytes());
+        b
+    }
+
+    #[test]
+    fn pc_value_tables_give_files_and_lines() {
+        let bytes = tiny_pclntab(0xfffffff1);
+        let tab = Pclntab::parse(&bytes).unwrap();
+        assert_eq!(tab.name(0).as_deref(), Some("main.main"));
+        assert_eq!((tab.entry(0), tab.entry(1)), (Some(0x1000), Some(0x1020)));
+        assert_eq!(tab.files(), ["/src/main.go"]);
+        assert_eq!(tab.position(0), (Some("/src/main.go".into()), Some(9)));
+        let rows: Vec<(u64, u32)> = tab.lines(0).iter().map(|l| (l.va, l.line)).collect();
+        assert_eq!(rows, [(0x1000, 10), (0x1010, 12)]);
+        // Go 1.18/1.19 `_func` has no startLine: the entry line stands in.
+        let old = tiny_pclntab(0xfffffff0);
+        assert_eq!(Pclntab::parse(&old).unwrap().position(0).1, Some(10));
+        let funcs = walk_names(&tab, &bytes).unwrap();
+        assert_eq!(
+            funcs,
+            [GoFunc {
+                entry_va: 0x1000,
+                name: "main.main".into()
+            }]
+        );
+    }
+
     #[test]
     fn extracts_main_main_from_stripped_go_hello() {
         let path = Path::new("samples/binaries/platforms/linux/amd64/export/go/hello-go");
@@ -0,0 +1,582 @@
+//! JVM bytecode listings and class-level call graph.
+//!
+//! `java_class::parse_class` already decodes each Code attribute into
+//! instructions and constant-pool xrefs. This module joins the two into
+//! `javap -c` style listings and builds one call graph over a set of
+//! classes (a single `.class`, or every class in a JAR). Nodes are
+//! `owner.name:descriptor`, the form `JavaXref::target` uses.
+//!
+//!   * `invokestatic`, `invokespecial` → Direct
+//!   * `invokevirtual`, `invokeinterface` → Virtual
+//!   * `invokedynamic` through `LambdaMetafactory` → Indirect, to the
+//!     lambda's implementation method
+//!
+//! Method references are resolved the way the JVM links them (JVMS
+//! §5.4.3.3): a call to `Sub.run` where `run` is declared in `Base` lands
+//! on `Base.run`, and when the superclass chain leaves the class set the
+//! call is attributed to the first ancestor outside it, so a subclass of
+//! `URLClassLoader` calling an inherited method still reads as a
+//! `java/net/URLClassLoader` call. Virtual calls also get an edge to every
+//! override in a subtype inside the set (class hierarchy analysis).
+//!
+//! Call sites are `Relative` addresses: the bytecode index in the caller,
+//! with the caller as the address space.
+
+use std::collections::{HashMap, HashSet, VecDeque};
+
+use crate::analysis::java_class::{parse_class, ClassInfo, JavaCode, JavaXref};
+use crate::core::address::{Address, AddressKind};
+use crate::core::call_graph::{CallGraph, CallGraphEdge, CallType};
+use crate::formats::apk::{ApkError, ApkReader};
+
+const ACC_STATIC: u16 = 0x0008;
+const ACC_ABSTRACT: u16 = 0x0400;
+
+const LAMBDA_METAFACTORY: &str = "java/lang/invoke/LambdaMetafactory";
+
+/// `javap -c` style listing of a method body, one line per instruction.
+/// Constant-pool operands are replaced by what they reference: member
+/// targets, class names, and quoted string literals.
+pub fn disassemble(code: &JavaCode) -> Vec<String> {
+    let xref_at: HashMap<u32, &JavaXref> = code.xrefs.iter().map(|x| (x.bci, x)).collect();
+    code.instructions
+        .iter()
+        .map(|ins| {
+            let operands: Vec<String> = ins
+                .operands
+                .iter()
+                .map(|op| match (op.starts_with("cp#"), xref_at.get(&ins.bci)) {
+                    (true, Some(x)) => match &x.string_value {
+                        Some(s) => format!("{:?}", s),
+                        None => x.target.clone(),
+                    },
+                    _ => op.clone(),
+                })
+                .collect();
+            format!(
+                "{:>5}: {:<15} {}",
+                ins.bci,
+                ins.mnemonic,
+                operands.join(", ")
+            )
+            .trim_end()
+            .to_string()
+        })
+        .collect()
+}
+
+/// Parse every `.class` member of a JAR (or WAR / plain ZIP). Members
+/// that fail to parse are skipped, as are multi-release overlays under
+/// `META-INF/`, which would duplicate the base classes.
+pub fn jar_classes(data: &[u8]) -> Result<Vec<ClassInfo>, ApkError> {
+    let jar = ApkReader::open(data)?;
+    let names: Vec<String> = jar
+        .names()
+        .filter(|n| n.ends_with(".class") && !n.starts_with("META-INF/"))
+        .map(str::to_string)
+        .collect();
+    let mut out = Vec::new();
+    for name in names {
+        let Ok(bytes) = jar.read(&name) else {
+            continue;
+        };
+        if let Ok(class) = parse_class(&bytes) {
+            out.push(class);
+        }
+    }
+    Ok(out)
+}
+
+/// Call-graph edge kind of an invoke opcode, or `None`.
+fn call_type(opcode: u8) -> Option<CallType> {
+    Some(match opcode {
+        0xb6 | 0xb9 => CallType::Virtual,
+        0xb7 | 0xb8 => CallType::Direct,
+        0xba => CallType::Indirect,
+        _ => return None,
+    })
+}
+
+/// Class set with the lookups method resolution needs.
+struct Hierarchy<'a> {
+    classes: HashMap<&'a str, &'a ClassInfo>,
+    /// Direct subclasses and implementors of each type.
+    subtypes: HashMap<&'a str, Vec<&'a str>>,
+}
+
+impl<'a> Hierarchy<'a> {
+    fn new(classes: &'a [ClassInfo]) -> Self {
+        let mut subtypes: HashMap<&str, Vec<&str>> = HashMap::new();
+        for c in classes {
+            let parents = std::iter::once(&c.super_class).chain(&c.interfaces);
+            for p in parents.filter(|p| !p.is_empty()) {
+                subtypes
+                    .entry(p.as_str())
+                    .or_default()
+                    .push(c.class_name.as_str());
+            }
+        }
+        Self {
+            classes: classes.iter().map(|c| (c.class_name.as_str(), c)).collect(),
+            subtypes,
+        }
+    }
+
+    fn declares(&self, class: &str, name: &str, descriptor: &str) -> bool {
+        self.classes.get(class).is_some_and(|c| {
+            c.methods
+                .iter()
+                .any(|m| m.name == name && m.descriptor == descriptor)
+        })
+    }
+
+    /// Class that declares the method a reference to `owner.name` links
+    /// to: the superclass chain first, then superinterfaces (default
+    /// methods), then the first ancestor outside the set.
+    fn resolve(&self, owner: &'a str, name: &str, descriptor: &str) -> &'a str {
+        let mut chain = Vec::new();
+        let mut outside = None;
+        let mut cur = owner;
+        while let Some(&c) = self.classes.get(cur) {
+            if chain.contains(&cur) {
+                break;
+            }
+            if self.declares(cur, name, descriptor) {
+                return cur;
+            }
+            chain.push(cur);
+            if c.super_class.is_empty() {
+                break;
+            }
+            cur = c.super_class.as_str();
+            if !self.classes.contains_key(cur) {
+                outside = Some(cur);
+            }
+        }
+        let mut seen = HashSet::new();
+        let mut queue: VecDeque<&str> = chain
+            .iter()
+            .filter_map(|c| self.classes.get(c))
+            .flat_map(|c| c.interfaces.iter().map(String::as_str))
+            .collect();
+        while let Some(i) = queue.pop_front() {
+            if !seen.insert(i) {
+                continue;
+            }
+            if self.declares(i, name, descriptor) {
+                return i;
+            }
+            if let Some(&c) = self.classes.get(i) {
+                queue.extend(c.interfaces.iter().map(String::as_str));
+            }
+        }
+        outside.unwrap_or(owner)
+    }
+
+    /// Subtypes of `owner` in the set that override `name` with a
+    /// concrete instance method.
+    fn overrides(&self, owner: &str, name: &str, descriptor: &str) -> Vec<&'a str> {
+        let mut out = Vec::new();
+        let mut seen = HashSet::new();
+        let mut queue: VecDeque<&str> = VecDeque::from([owner]);
+        while let Some(t) = queue.pop_front() {
+            for &sub in self.subtypes.get(t).map(Vec::as_slice).unwrap_or(&[]) {
+                if !seen.insert(sub) {
+                    continue;
+                }
+                queue.push_back(sub);
+                let concrete = self.classes[sub].methods.iter().any(|m| {
+                    m.name == name
+                        && m.descriptor == descriptor
+                        && m.access_flags & (ACC_STATIC | ACC_ABSTRACT) == 0
+                });
+                if concrete {
+                    out.push(sub);
+                }
+            }
+        }
+        out
+    }
+}
+
+/// Build the call graph over `classes`. Every method they declare is a
+/// node, as is every method they call outside the set.
+pub fn call_graph(classes: &[ClassInfo]) -> CallGraph {
+    let h = Hierarchy::new(classes);
+    let mut cg = CallGraph::new();
+    let mut nodes: Vec<String> = Vec::new();
+    let mut edge_of: HashMap<(String, String, CallType), usize> = HashMap::new();
+    for class in classes {
+        for m in &class.methods {
+            let caller = format!("{}.{}:{}", class.class_name, m.name, m.descriptor);
+            nodes.push(caller.clone());
+            let Some(code) = &m.code else {
+                continue;
+            };
+            for x in &code.xrefs {
+                let Some(kind) = call_type(x.opcode) else {
+                    continue;
+                };
+                let mut callees = Vec::new();
+                if kind == CallType::Indirect {
+                    // LambdaMetafactory's second static argument is the
+                    // implementation method handle. Other bootstraps
+                    // (string concatenation, records, switch patterns)
+                    // do not call user code.
+                    let bsm = x
+                        .bootstrap_index
+                        .and_then(|i| class.bootstrap_methods.get(i as usize));
+                    if let Some(bsm) = bsm {
+                        if bsm.reference_owner.as_deref() == Some(LAMBDA_METAFACTORY) {
+                            callees.extend(bsm.arguments.get(1).cloned());
+                        }
+                    }
+                } else {
+                    let owner = h.resolve(&x.owner, &x.name, &x.descriptor);
+                    callees.push(format!("{}.{}:{}", owner, x.name, x.descriptor));
+                    if kind == CallType::Virtual {
+                        for sub in h.overrides(&x.owner, &x.name, &x.descriptor) {
+                            callees.push(format!("{}.{}:{}", sub, x.name, x.descriptor));
+                        }
+                    }
+                }
+                for callee in callees {
+                    nodes.push(callee.clone());
+                    let Ok(site) = Address::new(
+                        AddressKind::Relative,
+                        x.bci as u64,
+                        32,
+                        Some(caller.clone()),
+                        None,
+                    ) else {
+                        continue;
+                    };
+                    let key = (caller.clone(), callee, kind);
+                    let idx = *edge_of.entry(key.clone()).or_insert_with(|| {
+                        cg.add_edge(CallGraphEdge::new(key.0, key.1, kind));
+                        cg.edges.len() - 1
+                    });
+                    cg.edges[idx].add_call_site(site);
+                }
+            }
+        }
+    }
+    nodes.sort();
+    nodes.dedup();
+    cg.nodes = nodes;
+    cg
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::analysis::java_class::{
+        JavaBootstrapMethod, JavaConstantPoolSummary, JavaInstruction, JavaMethod,
+    };
+
+    fn method(name: &str, descriptor: &str, access_flags: u16, xrefs: Vec<JavaXref>) -> JavaMethod {
+        JavaMethod {
+            access_flags,
+            name: name.to_string(),
+            descriptor: descriptor.to_string(),
+            signature: None,
+            attribute_names: Vec::new(),
+            is_deprecated: false,
+            is_synthetic: false,
+            runtime_visible_type_annotation_count: 0,
+            runtime_invisible_type_annotation_count: 0,
+            constant_value: None,
+            exceptions: Vec::new(),
+            annotations: Vec::new(),
+            method_parameters: Vec::new(),
+            parameter_annotations: Vec::new(),
+            annotation_default: None,
+            code: Some(JavaCode {
+                max_stack: 2,
+                max_locals: 1,
+                code_length: 0,
+                exception_table_len: 0,
+                exception_handlers: Vec::new(),
+                attributes_count: 0,
+                attribute_names: Vec::new(),
+                instruction_count: 0,
+                unknown_instruction_count: 0,
+                stack_map_frame_count: 0,
+                runtime_visible_type_annotation_count: 0,
+                runtime_invisible_type_annotation_count: 0,
+                line_numbers: Vec::new(),
+                local_variables: Vec::new(),
+                local_variable_types: Vec::new(),
+                instructions: Vec::new(),
+                xrefs,
+            }),
+        }
+    }
+
+    fn class(
+        name: &str,
+        super_class: &str,
+        interfaces: &[&str],
+        methods: Vec<JavaMethod>,
+    ) -> ClassInfo {
+        ClassInfo {
+            minor_version: 0,
+            major_version: 61,
+            access_flags: 0x0021,
+            class_name: name.to_string(),
+            super_class: super_class.to_string(),
+            source_file: None,
+            signature: None,
+            attribute_names: Vec::new(),
+            is_deprecated: false,
+            is_synthetic: false,
+            runtime_visible_type_annotation_count: 0,
+            runtime_invisible_type_annotation_count: 0,
+            source_debug_extension_length: 0,
+            source_debug_extension_sha256: None,
+            constant_pool: JavaConstantPoolSummary::default(),
+            annotations: Vec::new(),
+            inner_classes: Vec::new(),
+            enclosing_method: None,
+            nest_host: None,
+            nest_members: Vec::new(),
+            record_components: Vec::new(),
+            permitted_subclasses: Vec::new(),
+            module: None,
+            bootstrap_method_count: 0,
+            bootstrap_methods: Vec::new(),
+            interfaces: interfaces.iter().map(|i| i.to_string()).collect(),
+            methods,
+            fields: Vec::new(),
+        }
+    }
+
+    fn invoke(bci: u32, opcode: u8, owner: &str, name: &str, descriptor: &str) -> JavaXref {
+        JavaXref {
+            bci,
+            opcode,
+            kind: "method".to_string(),
+            owner: owner.to_string(),
+            name: name.to_string(),
+            descriptor: descriptor.to_string(),
+            target: format!("{owner}.{name}:{descriptor}"),
+            string_value: None,
+            bootstrap_index: None,
+        }
+    }
+
+    fn edge<'a>(cg: &'a CallGraph, caller: &str, callee: &str) -> Option<&'a CallGraphEdge> {
+        cg.edges
+            .iter()
+            .find(|e| e.caller == caller && e.callee == callee)
+    }
+
+    /// `Loader extends URLClassLoader`, `Task` an interface with a
+    /// default `name()`, `Shell implements Task` overriding `run()`, and
+    /// `Main` calling through all of them.
+    fn program() -> Vec<ClassInfo> {
+        let main_calls = vec![
+            invoke(
+                0,
+                0xb6,
+                "Loader",
+                "loadClass",
+                "(Ljava/lang/String;)Ljava/lang/Class;",
+            ),
+            invoke(4, 0xb9, "Task", "run", "()V"),
+            invoke(9, 0xb9, "Task", "name", "()Ljava/lang/String;"),
+            invoke(14, 0xb8, "Main", "helper", "()V"),
+            invoke(17, 0xb8, "Main", "helper", "()V"),
+        ];
+        let shell_calls = vec![
+            invoke(
+                0,
+                0xb8,
+                "java/lang/Runtime",
+                "getRuntime",
+                "()Ljava/lang/Runtime;",
+            ),
+            invoke(
+                5,
+                0xb6,
+                "java/lang/Runtime",
+                "exec",
+                "(Ljava/lang/String;)Ljava/lang/Process;",
+            ),
+        ];
+        vec![
+            class("Loader", "java/net/URLClassLoader", &[], vec![]),
+            class(
+                "Task",
+                "java/lang/Object",
+                &[],
+                vec![
+                    method("run", "()V", 0x0401, vec![]),
+                    method("name", "()Ljava/lang/String;", 0x0001, vec![]),
+                ],
+            ),
+            class(
+                "Shell",
+                "java/lang/Object",
+                &["Task"],
+                vec![method("run", "()V", 0x0001, shell_calls)],
+            ),
+            class(
+                "Main",
+                "java/lang/Object",
+                &[],
+                vec![
+                    method("main", "([Ljava/lang/String;)V", 0x0009, main_calls),
+                    method("helper", "()V", 0x000a, vec![]),
+                ],
+            ),
+        ]
+    }
+
+    #[test]
+    fn resolves_inherited_and_default_methods() {
+        let classes = program();
+        let h = Hierarchy::new(&classes);
+        // Inherited from outside the set: attributed to the first external
+        // ancestor.
+        assert_eq!(
+            h.resolve(
+                "Loader",
+                "loadClass",
+                "(Ljava/lang/String;)Ljava/lang/Class;"
+            ),
+            "java/net/URLClassLoader"
+        );
+        // Interface default method reached through an implementor.
+        assert_eq!(h.resolve("Shell", "name", "()Ljava/lang/String;"), "Task");
+        assert_eq!(h.resolve("Shell", "run", "()V"), "Shell");
+        assert_eq!(h.resolve("java/io/File", "exists", "()Z"), "java/io/File");
+    }
+
+    #[test]
+    fn builds_call_graph_with_overrides() {
+        let cg = call_graph(&program());
+        let main = "Main.main:([Ljava/lang/String;)V";
+        let load = edge(
+            &cg,
+            main,
+            "java/net/URLClassLoader.loadClass:(Ljava/lang/String;)Ljava/lang/Class;",
+        )
+        .expect("inherited loadClass edge");
+        assert_eq!(load.call_type, CallType::Virtual);
+        // The interface call reaches the declaration and the one override.
+        assert!(edge(&cg, main, "Task.run:()V").is_some());
+        assert_eq!(
+            edge(&cg, main, "Shell.run:()V").map(|e| e.call_type),
+            Some(CallType::Virtual)
+        );
+        // Two call sites, one edge.
+        let helper = edge(&cg, main, "Main.helper:()V").unwrap();
+        assert_eq!(helper.call_type, CallType::Direct);
+        assert_eq!(helper.call_sites.len(), 2);
+        assert!(edge(
+            &cg,
+            "Shell.run:()V",
+            "java/lang/Runtime.exec:(Ljava/lang/String;)Ljava/lang/Process;"
+        )
+        .is_some());
+        assert!(cg.nodes.contains(&"Main.helper:()V".to_string()));
+        assert!(cg
+            .nodes
+            .contains(&"java/lang/Runtime.getRuntime:()Ljava/lang/Runtime;".to_string()));
+    }
+
+    #[test]
+    fn lambdas_link_to_their_implementation() {
+        let mut indy = invoke(0, 0xba, "", "run", "()Ljava/lang/Runnable;");
+        indy.kind = "invokedynamic".to_string();
+        indy.bootstrap_index = Some(0);
+        let mut concat = indy.clone();
+        concat.bci = 6;
+        concat.bootstrap_index = Some(1);
+        let mut main = class(
+            "Main",
+            "java/lang/Object",
+            &[],
+            vec![
+                method("main", "()V", 0x0009, vec![indy, concat]),
+                method("lambda$main$0", "()V", 0x100a, vec![]),
+            ],
+        );
+        let bsm = |owner: &str, arguments: Vec<String>| JavaBootstrapMethod {
+            bootstrap_method_ref_index: 1,
+            reference_kind: Some(6),
+            reference_kind_name: Some("invoke_static".to_string()),
+            reference_owner: Some(owner.to_string()),
+            reference_name: None,
+            reference_descriptor: None,
+            reference_target: None,
+            argument_count: arguments.len() as u16,
+            arguments,
+        };
+        main.bootstrap_methods = vec![
+            bsm(
+                LAMBDA_METAFACTORY,
+                vec!["()V".into(), "Main.lambda$main$0:()V".into(), "()V".into()],
+            ),
+            bsm(
+                "java/lang/invoke/StringConcatFactory",
+                vec!["\u{1}!".into()],
+            ),
+        ];
+        let cg = call_graph(&[main]);
+        assert_eq!(cg.edges.len(), 1);
+        assert_eq!(cg.edges[0].callee, "Main.lambda$main$0:()V");
+        assert_eq!(cg.edges[0].call_type, CallType::Indirect);
+    }
+
+    #[test]
+    fn disassembly_resolves_constant_pool_operands() {
+        let mut m = method(
+            "hello",
+            "()V",
+            0x0009,
+            vec![
+                invoke(
+                    3,
+                    0xb6,
+                    "java/io/PrintStream",
+                    "println",
+                    "(Ljava/lang/String;)V",
+                ),
+                JavaXref {
+                    kind: "string".to_string(),
+                    owner: String::new(),
+                    name: String::new(),
+                    descriptor: String::new(),
+                    target: "hi".to_string(),
+                    string_value: Some("hi".to_string()),
+                    ..invoke(0, 0x12, "", "", "")
+                },
+            ],
+        );
+        let ins = |bci: u32, opcode: u8, mnemonic: &str, operands: &[&str], length: u32| {
+            JavaInstruction {
+                bci,
+                opcode,
+                mnemonic: mnemonic.to_string(),
+                operands: operands.iter().map(|o| o.to_string()).collect(),
+                length,
+            }
+        };
+        let code = m.code.as_mut().unwrap();
+        code.instructions = vec![
+            ins(0, 0x12, "ldc", &["cp#2"], 2),
+            ins(2, 0x2a, "aload_0", &[], 1),
+            ins(3, 0xb6, "invokevirtual", &["cp#7"], 3),
+            ins(6, 0xb1, "return", &[], 1),
+        ];
+        let lines = disassemble(code);
+        assert_eq!(lines[0], "    0: ldc             \"hi\"");
+        assert_eq!(lines[1], "    2: aload_0");
+        assert_eq!(
+            lines[2],
+            "    3: invokevirtual   java/io/PrintStream.println:(Ljava/lang/String;)V"
+        );
+    }
+}
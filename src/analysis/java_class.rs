@@ -247,6 +247,9 @@ pub struct JavaXref {
     pub descriptor: String,
     pub target: String,
     pub string_value: Option<String>,
+    /// `BootstrapMethods` index of an `invokedynamic` / dynamic-constant
+    /// reference.
+    pub bootstrap_index: Option<u16>,
 }
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -2075,6 +2078,7 @@ fn resolve_constant_xref(
                 descriptor: String::new(),
                 target: name,
                 string_value: None,
+                bootstrap_index: None,
             }))
         }
         CpEntry::String { string_idx } => {
@@ -2088,14 +2092,33 @@ fn resolve_constant_xref(
                 descriptor: String::new(),
                 target: value.clone(),
                 string_value: Some(value),
+                bootstrap_index: None,
             }))
         }
         CpEntry::Dynamic {
-            name_and_type_idx, ..
-        } => dynamic_xref(cp, *name_and_type_idx, bci, opcode, "dynamic").map(Some),
+            bootstrap_method_attr_idx,
+            name_and_type_idx,
+        } => dynamic_xref(
+            cp,
+            *bootstrap_method_attr_idx,
+            *name_and_type_idx,
+            bci,
+            opcode,
+            "dynamic",
+        )
+        .map(Some),
         CpEntry::InvokeDynamic {
-            name_and_type_idx, ..
-        } => dynamic_xref(cp, *name_and_type_idx, bci, opcode, "invokedynamic").map(Some),
+            bootstrap_method_attr_idx,
+            name_and_type_idx,
+        } => dynamic_xref(
+            cp,
+            *bootstrap_method_attr_idx,
+            *name_and_type_idx,
+            bci,
+            opcode,
+            "invokedynamic",
+        )
+        .map(Some),
         _ => Ok(None),
     }
 }
@@ -2120,11 +2143,13 @@ fn member_xref(
         descriptor,
         target,
         string_value: None,
+        bootstrap_index: None,
     })
 }
 
 fn dynamic_xref(
     cp: &[CpEntry],
+    bootstrap_method_attr_idx: u16,
     name_and_type_idx: u16,
     bci: u32,
     opcode: u8,
@@ -2141,6 +2166,7 @@ fn dynamic_xref(
         descriptor,
         target,
         string_value: None,
+        bootstrap_index: Some(bootstrap_method_attr_idx),
     })
 }
 
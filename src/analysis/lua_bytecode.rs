@@ -1,21 +1,26 @@
-//! Lua bytecode recognizer (#211 v0).
+//! Lua bytecode parser (#211).
 //!
-//! Detects compiled Lua bytecode (`.luac`) and LuaJIT bytecode and
-//! surfaces enough header / source info to triage these files. Full
-//! prototype walking — opcodes, constants, debug-info line numbers —
-//! is filed for v1 once we have a real malware sample driving the
-//! requirements.
+//! Detects compiled Lua chunks (`luac` output, `string.dump`) and LuaJIT
+//! bytecode (`luajit -b`) and walks the function prototypes inside them.
+//! Both turn up in game mods, embedded scripting engines, and malware
+//! that ships its logic as bytecode to keep the source out of sight.
 //!
-//! Supported magics:
+//! Supported formats:
 //!
-//!   * `\x1bLua` followed by version byte (Lua 5.1 / 5.2 / 5.3 / 5.4)
-//!   * `\x1bLJ`  followed by flags + version (LuaJIT 1 / 2)
+//!   * `\x1bLua` + version byte: Lua 5.1, 5.2, 5.3, and 5.4 chunks. The
+//!     header records the `int` / `size_t` / `Instruction` / number
+//!     widths and byte order the chunk was dumped with, and the walker
+//!     honours them rather than assuming a 64-bit little-endian build.
+//!   * `\x1bLJ` + dump version 1 (LuaJIT 2.0) or 2 (LuaJIT 2.1, FR2).
+//!     LuaJIT 1.x reused the stock Lua 5.1 format.
 //!
-//! For Lua 5.3+ the parser walks the well-defined header far enough
-//! to locate the top-level prototype's source-name string, which
-//! gives the analyst the original `.lua` filename — critical for
-//! tracing where a script came from.
+//! For every prototype the parser records its line span, arity, upvalue
+//! count, instruction count, and constant table, plus local and upvalue
+//! names when debug info was not stripped. Functions are returned as a
+//! flat pre-order list with parent indices, so `functions[0]` is always
+//! the main chunk. Instructions are counted, not decoded.
 
+/// Bytecode family, from the header's version byte.
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub enum LuaKind {
     Lua51,
@@ -26,27 +31,144 @@ pub enum LuaKind {
     Unknown(u8),
 }
 
-#[derive(Debug, Clone, PartialEq, Eq)]
+#[derive(Debug, Clone, PartialEq)]
 pub struct LuaInfo {
     pub kind: LuaKind,
-    /// Format byte. 0 = official, anything else = customised build.
+    /// Format byte. 0 = official, anything else = customised build. For
+    /// LuaJIT this is the dump version (1 = 2.0, 2 = 2.1).
     pub format: u8,
     /// Source filename embedded in the bytecode (debug info). `None`
-    /// when the file was stripped with `luac -s`.
+    /// when the file was stripped with `luac -s` / `luajit -s`.
     pub source: Option<String>,
-    /// Endianness flag. Some Lua versions encode this.
+    /// Byte order the chunk was dumped with.
     pub little_endian: bool,
+    /// Every function prototype, main chunk first, in pre-order. Empty
+    /// for versions whose layout is not walked (`LuaKind::Unknown`,
+    /// LuaJIT 1.x).
+    pub functions: Vec<LuaFunction>,
+}
+
+impl LuaInfo {
+    /// String constants from every function, in first-seen order and
+    /// without duplicates. Includes strings nested in LuaJIT table
+    /// constants.
+    pub fn strings(&self) -> Vec<String> {
+        fn walk(k: &LuaConstant, out: &mut Vec<String>) {
+            match k {
+                LuaConstant::Str(s) => {
+                    if !out.contains(s) {
+                        out.push(s.clone());
+                    }
+                }
+                LuaConstant::Table { array, hash } => {
+                    array.iter().for_each(|v| walk(v, out));
+                    for (key, v) in hash {
+                        walk(key, out);
+                        walk(v, out);
+                    }
+                }
+                _ => {}
+            }
+        }
+        let mut out = Vec::new();
+        for f in &self.functions {
+            f.constants.iter().for_each(|k| walk(k, &mut out));
+        }
+        out
+    }
+}
+
+/// One function prototype.
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct LuaFunction {
+    /// Index of the enclosing function in `LuaInfo::functions`; `None`
+    /// for the main chunk.
+    pub parent: Option<usize>,
+    /// Chunk name (`@hello.lua`, `=stdin`, ...). Nested functions inherit
+    /// their parent's, as the Lua loader does.
+    pub source: Option<String>,
+    /// First line of the definition; 0 for the main chunk.
+    pub line_defined: u32,
+    pub last_line_defined: u32,
+    pub num_params: u8,
+    pub is_vararg: bool,
+    /// Register window size (`maxstacksize`, LuaJIT `framesize`).
+    pub max_stack: u8,
+    pub num_upvalues: u32,
+    pub num_instructions: u32,
+    /// Constant table in index order. For LuaJIT this is the GC
+    /// constants (minus child prototypes) followed by the numeric ones.
+    pub constants: Vec<LuaConstant>,
+    /// Number of directly nested prototypes.
+    pub num_children: u32,
+    /// Local variable names from debug info, in declaration order.
+    pub locals: Vec<String>,
+    /// Upvalue names from debug info.
+    pub upvalue_names: Vec<String>,
+}
+
+#[derive(Debug, Clone, PartialEq)]
+pub enum LuaConstant {
+    Nil,
+    Bool(bool),
+    Int(i64),
+    Num(f64),
+    Str(String),
+    /// LuaJIT template table (`{ "a", "b", x = 1 }` with constant
+    /// contents).
+    Table {
+        array: Vec<LuaConstant>,
+        hash: Vec<(LuaConstant, LuaConstant)>,
+    },
+    /// LuaJIT FFI 64-bit integer / complex literal (`1LL`, `2ULL`, `3i`),
+    /// as written in source.
+    Cdata(String),
+}
+
+impl std::fmt::Display for LuaConstant {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            LuaConstant::Nil => write!(f, "nil"),
+            LuaConstant::Bool(b) => write!(f, "{}", b),
+            LuaConstant::Int(i) => write!(f, "{}", i),
+            LuaConstant::Num(n) => write!(f, "{:?}", n),
+            LuaConstant::Str(s) => write!(f, "{:?}", s),
+            LuaConstant::Table { array, hash } => {
+                write!(f, "{{")?;
+                let mut sep = "";
+                for v in array {
+                    write!(f, "{}{}", sep, v)?;
+                    sep = ", ";
+                }
+                for (k, v) in hash {
+                    write!(f, "{}[{}] = {}", sep, k, v)?;
+                    sep = ", ";
+                }
+                write!(f, "}}")
+            }
+            LuaConstant::Cdata(s) => write!(f, "{}", s),
+        }
+    }
 }
 
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub enum LuaError {
     BadMagic,
     Truncated(&'static str),
+    /// Header widths or tags this parser cannot decode (e.g. a 16-byte
+    /// `lua_Number`, or an unknown constant tag from a modified VM).
+    Unsupported(&'static str),
+    /// Prototype nesting beyond `MAX_DEPTH`.
+    TooDeep,
 }
 
 const LUA_MAGIC: &[u8] = b"\x1bLua";
 const LUAJIT_MAGIC: &[u8] = b"\x1bLJ";
 
+/// Nested prototypes deeper than this are treated as hostile. Stock Lua
+/// caps nesting at 200 C levels (`LUAI_MAXCCALLS`).
+const MAX_DEPTH: usize = 200;
+
 pub fn parse_lua(data: &[u8]) -> Result<LuaInfo, LuaError> {
     if data.len() < 6 {
         return Err(LuaError::Truncated("header"));
@@ -60,6 +182,291 @@ pub fn parse_lua(data: &[u8]) -> Result<LuaInfo, LuaError> {
     Err(LuaError::BadMagic)
 }
 
+/// Cursor over a stock Lua chunk, carrying the type widths from its
+/// header.
+struct Reader<'a> {
+    data: &'a [u8],
+    pos: usize,
+    version: u8,
+    little_endian: bool,
+    sizeof_int: usize,
+    sizeof_size_t: usize,
+    sizeof_insn: usize,
+    sizeof_integer: usize,
+    sizeof_number: usize,
+    /// 5.1 / 5.2 builds with `LUA_NUMBER` defined as an integer type.
+    integral: bool,
+}
+
+impl<'a> Reader<'a> {
+    fn byte(&mut self, what: &'static str) -> Result<u8, LuaError> {
+        let b = *self.data.get(self.pos).ok_or(LuaError::Truncated(what))?;
+        self.pos += 1;
+        Ok(b)
+    }
+
+    fn take(&mut self, n: usize, what: &'static str) -> Result<&'a [u8], LuaError> {
+        let end = self.pos.checked_add(n).ok_or(LuaError::Truncated(what))?;
+        let s = self
+            .data
+            .get(self.pos..end)
+            .ok_or(LuaError::Truncated(what))?;
+        self.pos = end;
+        Ok(s)
+    }
+
+    /// Unsigned integer `width` bytes wide in the chunk's byte order.
+    fn uint(&mut self, width: usize, what: &'static str) -> Result<u64, LuaError> {
+        if width == 0 || width > 8 {
+            return Err(LuaError::Unsupported(what));
+        }
+        let b = self.take(width, what)?;
+        let mut v = 0u64;
+        for i in 0..width {
+            let byte = if self.little_endian {
+                b[width - 1 - i]
+            } else {
+                b[i]
+            };
+            v = (v << 8) | byte as u64;
+        }
+        Ok(v)
+    }
+
+    /// Sign-extended integer `width` bytes wide.
+    fn sint(&mut self, width: usize, what: &'static str) -> Result<i64, LuaError> {
+        let v = self.uint(width, what)?;
+        let shift = 64 - 8 * width as u32;
+        Ok(((v << shift) as i64) >> shift)
+    }
+
+    /// Lua 5.4 `loadUnsigned`: big-endian 7-bit groups, the last byte
+    /// flagged with 0x80.
+    fn varint(&mut self, what: &'static str) -> Result<u64, LuaError> {
+        let mut v = 0u64;
+        loop {
+            let b = self.byte(what)?;
+            if v >> 57 != 0 {
+                return Err(LuaError::Unsupported(what));
+            }
+            v = (v << 7) | (b & 0x7f) as u64;
+            if b & 0x80 != 0 {
+                return Ok(v);
+            }
+        }
+    }
+
+    /// C `int` field: line numbers, vector sizes, pcs.
+    fn int(&mut self, what: &'static str) -> Result<u64, LuaError> {
+        if self.version == 0x54 {
+            self.varint(what)
+        } else {
+            Ok(self.sint(self.sizeof_int, what)?.max(0) as u64)
+        }
+    }
+
+    /// Element count of a vector that follows; bounded by the bytes left
+    /// so a corrupt count fails fast instead of looping.
+    fn count(&mut self, what: &'static str) -> Result<usize, LuaError> {
+        let n = self.int(what)?;
+        if n > (self.data.len() - self.pos) as u64 {
+            return Err(LuaError::Truncated(what));
+        }
+        Ok(n as usize)
+    }
+
+    /// Nullable string. Every version stores length + 1 so that 0 can
+    /// mean NULL; 5.1 / 5.2 also keep the trailing NUL in the body.
+    fn string(&mut self, what: &'static str) -> Result<Option<String>, LuaError> {
+        let size = match self.version {
+            0x51 | 0x52 => self.uint(self.sizeof_size_t, what)?,
+            0x53 => match self.byte(what)? {
+                0xff => self.uint(self.sizeof_size_t, what)?,
+                b => b as u64,
+            },
+            _ => self.varint(what)?,
+        };
+        if size == 0 {
+            return Ok(None);
+        }
+        let len = usize::try_from(size - 1).map_err(|_| LuaError::Truncated(what))?;
+        let body = self.take(len, what)?;
+        if matches!(self.version, 0x51 | 0x52) {
+            self.take(1, what)?;
+        }
+        Ok(Some(String::from_utf8_lossy(body).into_owned()))
+    }
+
+    fn number(&mut self) -> Result<LuaConstant, LuaError> {
+        if self.integral {
+            return Ok(LuaConstant::Int(self.sint(self.sizeof_number, "number")?));
+        }
+        let bits = self.uint(self.sizeof_number, "number")?;
+        Ok(LuaConstant::Num(match self.sizeof_number {
+            8 => f64::from_bits(bits),
+            4 => f32::from_bits(bits as u32) as f64,
+            _ => return Err(LuaError::Unsupported("lua_Number size")),
+        }))
+    }
+
+    fn constant(&mut self) -> Result<LuaConstant, LuaError> {
+        let tag = self.byte("constant tag")?;
+        Ok(match (self.version, tag) {
+            (_, 0x00) => LuaConstant::Nil,
+            (0x51..=0x53, 0x01) => LuaConstant::Bool(self.byte("boolean")? != 0),
+            (0x54, 0x01) => LuaConstant::Bool(false),
+            (0x54, 0x11) => LuaConstant::Bool(true),
+            (0x51 | 0x52, 0x03) | (0x53, 0x03) | (0x54, 0x13) => self.number()?,
+            (0x53, 0x13) | (0x54, 0x03) => {
+                LuaConstant::Int(self.sint(self.sizeof_integer, "integer")?)
+            }
+            (_, 0x04) | (0x53 | 0x54, 0x14) => {
+                LuaConstant::Str(self.string("string constant")?.unwrap_or_default())
+            }
+            _ => return Err(LuaError::Unsupported("constant tag")),
+        })
+    }
+
+    /// Parse one prototype and its children into `out`, returning its
+    /// index.
+    fn function(
+        &mut self,
+        out: &mut Vec<LuaFunction>,
+        parent: Option<usize>,
+        parent_source: Option<&str>,
+        depth: usize,
+    ) -> Result<usize, LuaError> {
+        if depth >= MAX_DEPTH {
+            return Err(LuaError::TooDeep);
+        }
+        let idx = out.len();
+        out.push(LuaFunction {
+            parent,
+            ..Default::default()
+        });
+        let mut f = LuaFunction {
+            parent,
+            ..Default::default()
+        };
+        // 5.2 moved the source name into the debug section; the others
+        // lead with it.
+        if self.version != 0x52 {
+            f.source = self.string("source")?;
+            if f.source.is_none() {
+                f.source = parent_source.map(str::to_string);
+            }
+        }
+        f.line_defined = self.int("linedefined")? as u32;
+        f.last_line_defined = self.int("lastlinedefined")? as u32;
+        if self.version == 0x51 {
+            f.num_upvalues = self.byte("nups")? as u32;
+        }
+        f.num_params = self.byte("numparams")?;
+        // 5.1 stores VARARG_* flag bits; VARARG_ISVARARG is 2.
+        let vararg = self.byte("is_vararg")?;
+        f.is_vararg = if self.version == 0x51 {
+            vararg & 2 != 0
+        } else {
+            vararg != 0
+        };
+        f.max_stack = self.byte("maxstacksize")?;
+        if self.sizeof_insn == 0 || self.sizeof_insn > 8 {
+            return Err(LuaError::Unsupported("Instruction size"));
+        }
+        let ncode = self.count("code")?;
+        f.num_instructions = ncode as u32;
+        self.take(
+            ncode
+                .checked_mul(self.sizeof_insn)
+                .ok_or(LuaError::Truncated("code"))?,
+            "code",
+        )?;
+
+        let nk = self.count("constants")?;
+        for _ in 0..nk {
+            let k = self.constant()?;
+            f.constants.push(k);
+        }
+
+        // 5.1 / 5.2 nest prototypes inside the constants block, 5.3 / 5.4
+        // after the upvalue descriptors.
+        if self.version >= 0x52 {
+            if self.version == 0x52 {
+                self.children(out, idx, &mut f, depth)?;
+            }
+            let nups = self.count("upvalues")?;
+            f.num_upvalues = nups as u32;
+            // instack, idx (+ kind in 5.4) per upvalue.
+            let width = if self.version == 0x54 { 3 } else { 2 };
+            self.take(nups * width, "upvalues")?;
+            if self.version != 0x52 {
+                self.children(out, idx, &mut f, depth)?;
+            }
+        } else {
+            self.children(out, idx, &mut f, depth)?;
+        }
+
+        // Debug info.
+        if self.version == 0x52 {
+            f.source = self.string("source")?;
+            if f.source.is_none() {
+                f.source = parent_source.map(str::to_string);
+            }
+        }
+        let nline = self.count("lineinfo")?;
+        if self.version == 0x54 {
+            // One signed byte delta per instruction, then (pc, line)
+            // anchors.
+            self.take(nline, "lineinfo")?;
+            let nabs = self.count("abslineinfo")?;
+            for _ in 0..nabs {
+                self.int("abslineinfo")?;
+                self.int("abslineinfo")?;
+            }
+        } else {
+            self.take(nline * self.sizeof_int, "lineinfo")?;
+        }
+        let nloc = self.count("locvars")?;
+        for _ in 0..nloc {
+            let name = self.string("locvar")?.unwrap_or_default();
+            self.int("locvar startpc")?;
+            self.int("locvar endpc")?;
+            f.locals.push(name);
+        }
+        let nupn = self.count("upvalue names")?;
+        for _ in 0..nupn {
+            let name = self.string("upvalue name")?.unwrap_or_default();
+            f.upvalue_names.push(name);
+        }
+
+        // 5.2 children were parsed before this function's source was
+        // known; fill in what they could not inherit.
+        for child in out.iter_mut().skip(idx + 1) {
+            if child.source.is_none() {
+                child.source = f.source.clone();
+            }
+        }
+        out[idx] = f;
+        Ok(idx)
+    }
+
+    fn children(
+        &mut self,
+        out: &mut Vec<LuaFunction>,
+        idx: usize,
+        f: &mut LuaFunction,
+        depth: usize,
+    ) -> Result<(), LuaError> {
+        let n = self.count("protos")?;
+        f.num_children = n as u32;
+        let source = f.source.clone();
+        for _ in 0..n {
+            self.function(out, Some(idx), source.as_deref(), depth + 1)?;
+        }
+        Ok(())
+    }
+}
+
 fn parse_lua_official(data: &[u8]) -> Result<LuaInfo, LuaError> {
     let version = data[4];
     let format = data[5];
@@ -68,156 +475,396 @@ fn parse_lua_official(data: &[u8]) -> Result<LuaInfo, LuaError> {
         0x52 => LuaKind::Lua52,
         0x53 => LuaKind::Lua53,
         0x54 => LuaKind::Lua54,
-        other => LuaKind::Unknown(other),
+        other => {
+            return Ok(LuaInfo {
+                kind: LuaKind::Unknown(other),
+                format,
+                source: None,
+                little_endian: true,
+                functions: Vec::new(),
+            })
+        }
+    };
+    let mut r = Reader {
+        data,
+        pos: 6,
+        version,
+        little_endian: true,
+        sizeof_int: 4,
+        sizeof_size_t: 8,
+        sizeof_insn: 4,
+        sizeof_integer: 8,
+        sizeof_number: 8,
+        integral: false,
     };
 
-    // For Lua 5.3+ the header is well-defined enough to extract the
-    // source filename. The layout is:
-    //   [0..4]  \x1bLua
-    //   [4]     version
-    //   [5]     format
-    //   [6..12] LUAC_DATA = \x19\x93\r\n\x1a\n
-    //   [12]    sizeof(int)        usually 4
-    //   [13]    sizeof(size_t)     usually 8
-    //   [14]    sizeof(Instruction) usually 4
-    //   [15]    sizeof(lua_Integer) (5.3+) usually 8
-    //   [16]    sizeof(lua_Number)
-    //   [17..]  LUAC_INT (sizeof_int), LUAC_NUM (sizeof_number) test values
-    //   ... upvalue count u8, then top-level prototype.
-    //
-    // Prototype starts with the source name string. Lua 5.3+ encodes
-    // strings with a single-byte length L (0 = nil; 0xFF = follow
-    // with size_t). String body is L-1 bytes long when L < 0xFF.
-    let mut source: Option<String> = None;
-    if matches!(kind, LuaKind::Lua53 | LuaKind::Lua54) {
-        if let Some(name) = extract_source_53(data) {
-            source = Some(name);
+    // Header after magic, version, and format:
+    //   5.1: endianness, sizeof(int), sizeof(size_t), sizeof(Instruction),
+    //        sizeof(lua_Number), integral flag
+    //   5.2: as 5.1, then LUAC_TAIL "\x19\x93\r\n\x1a\n"
+    //   5.3: LUAC_DATA "\x19\x93\r\n\x1a\n", sizeof(int), sizeof(size_t),
+    //        sizeof(Instruction), sizeof(lua_Integer), sizeof(lua_Number),
+    //        LUAC_INT 0x5678, LUAC_NUM 370.5, main-closure upvalue count
+    //   5.4: LUAC_DATA, sizeof(Instruction), sizeof(lua_Integer),
+    //        sizeof(lua_Number), LUAC_INT, LUAC_NUM, upvalue count
+    match version {
+        0x51 | 0x52 => {
+            r.little_endian = r.byte("endianness")? != 0;
+            r.sizeof_int = r.byte("sizeof(int)")? as usize;
+            r.sizeof_size_t = r.byte("sizeof(size_t)")? as usize;
+            r.sizeof_insn = r.byte("sizeof(Instruction)")? as usize;
+            r.sizeof_number = r.byte("sizeof(lua_Number)")? as usize;
+            r.integral = r.byte("integral flag")? != 0;
+            if version == 0x52 {
+                r.take(6, "LUAC_TAIL")?;
+            }
+        }
+        _ => {
+            r.take(6, "LUAC_DATA")?;
+            if version == 0x53 {
+                r.sizeof_int = r.byte("sizeof(int)")? as usize;
+                r.sizeof_size_t = r.byte("sizeof(size_t)")? as usize;
+            }
+            r.sizeof_insn = r.byte("sizeof(Instruction)")? as usize;
+            r.sizeof_integer = r.byte("sizeof(lua_Integer)")? as usize;
+            r.sizeof_number = r.byte("sizeof(lua_Number)")? as usize;
+            // 5.3+ dropped the endianness byte; LUAC_INT tells instead.
+            let check = r.take(r.sizeof_integer, "LUAC_INT")?;
+            r.little_endian = check.first() == Some(&0x78);
+            r.take(r.sizeof_number, "LUAC_NUM")?;
+            r.byte("upvalue count")?;
         }
     }
 
-    let little_endian = true; // Lua 5.3+ removed the explicit endianness byte; assume LE.
-
+    let mut functions = Vec::new();
+    r.function(&mut functions, None, None, 0)?;
     Ok(LuaInfo {
         kind,
         format,
-        source,
-        little_endian,
+        source: functions[0].source.clone(),
+        little_endian: r.little_endian,
+        functions,
     })
 }
 
-fn extract_source_53(data: &[u8]) -> Option<String> {
-    // Header is fixed-size up through LUAC_NUM:
-    //   12 bytes header + LUAC_DATA  = bytes 0..12
-    //   13: sizeof_int
-    //   14: sizeof_size_t
-    //   15: sizeof_Instruction
-    //   16: sizeof_lua_Integer (5.3) or sizeof_lua_Number for 5.4
-    //   17: sizeof_lua_Number  (5.3) — for 5.4 this slot doesn't exist
-    //
-    // Then test integer (sizeof_lua_Integer bytes for 5.3, sizeof_int
-    // for 5.4) and test number (sizeof_lua_Number bytes).
-    if data.len() < 18 {
-        return None;
-    }
-    let version = data[4];
-    let mut p = 12;
-    let sizeof_int = data[p] as usize;
-    p += 1;
-    let _sizeof_size_t = data[p] as usize;
-    p += 1;
-    let _sizeof_inst = data[p] as usize;
-    p += 1;
-    let sizeof_lua_int;
-    let sizeof_lua_num;
-    if version == 0x53 {
-        sizeof_lua_int = data[p] as usize;
-        p += 1;
-        sizeof_lua_num = data[p] as usize;
-        p += 1;
-        // Skip LUAC_INT and LUAC_NUM tag values.
-        p += sizeof_lua_int + sizeof_lua_num;
-    } else if version == 0x54 {
-        // 5.4 layout: sizeof(Instruction), sizeof(lua_Integer), sizeof(lua_Number)
-        // already consumed sizeof_inst above; the next bytes are
-        // sizeof(lua_Integer) then sizeof(lua_Number).
-        sizeof_lua_int = data[p] as usize;
-        p += 1;
-        sizeof_lua_num = data[p] as usize;
-        p += 1;
-        p += sizeof_lua_int + sizeof_lua_num;
-    } else {
-        return None;
-    }
-    if p >= data.len() {
-        return None;
-    }
-    // Upvalue count u8 (number of upvalues for the main chunk).
-    p += 1;
-    if p >= data.len() {
-        return None;
-    }
-    // Now we're at the start of the top-level prototype. Its first
-    // field is the source-name string.
-    let _ = sizeof_int;
-    read_lua_string(data, &mut p)
+// LuaJIT dump flags (lj_bcdump.h).
+const BCDUMP_F_BE: u64 = 0x01;
+const BCDUMP_F_STRIP: u64 = 0x02;
+
+// GC constant tags; strings are BCDUMP_KGC_STR + length.
+const BCDUMP_KGC_CHILD: u64 = 0;
+const BCDUMP_KGC_TAB: u64 = 1;
+const BCDUMP_KGC_I64: u64 = 2;
+const BCDUMP_KGC_U64: u64 = 3;
+const BCDUMP_KGC_COMPLEX: u64 = 4;
+const BCDUMP_KGC_STR: u64 = 5;
+
+// Template-table entry tags; strings are BCDUMP_KTAB_STR + length.
+const BCDUMP_KTAB_NIL: u64 = 0;
+const BCDUMP_KTAB_FALSE: u64 = 1;
+const BCDUMP_KTAB_TRUE: u64 = 2;
+const BCDUMP_KTAB_INT: u64 = 3;
+const BCDUMP_KTAB_NUM: u64 = 4;
+const BCDUMP_KTAB_STR: u64 = 5;
+
+/// Builtin names LuaJIT encodes as a single byte in `varinfo` instead of
+/// a string (`VARNAMEDEF` in lj_debug.c); `VARNAME_END` is 0.
+const LJ_VARNAME_MAX: u8 = 7;
+
+/// Cursor over a LuaJIT dump. Everything but the raw bytecode is
+/// ULEB128, so byte order does not matter here.
+struct LjReader<'a> {
+    data: &'a [u8],
+    pos: usize,
 }
 
-/// Read a Lua 5.3+ string at `*p`, advancing `*p`. Returns None when
-/// the encoded string is nil (length byte 0) or when the data is
-/// truncated. The encoding is:
-///   - First byte L. If 0, string is nil.
-///   - If L < 0xFF: actual length is L-1; body follows.
-///   - If L == 0xFF: next sizeof(size_t) bytes are the actual length.
-fn read_lua_string(data: &[u8], p: &mut usize) -> Option<String> {
-    if *p >= data.len() {
-        return None;
-    }
-    let first = data[*p];
-    *p += 1;
-    if first == 0 {
-        return None;
-    }
-    if first == 0xFF {
-        // Long-string form: 8-byte size_t length follows. Bound this
-        // generously to avoid runaway reads on malformed files.
-        if *p + 8 > data.len() {
-            return None;
-        }
-        let len = u64::from_le_bytes(data[*p..*p + 8].try_into().unwrap()) as usize;
-        *p += 8;
-        if len == 0 || *p + len.saturating_sub(1) > data.len() || len > 4096 {
-            return None;
-        }
-        let actual = len - 1; // Lua stores length+1; the trailing nul is implicit.
-        let s = String::from_utf8_lossy(&data[*p..*p + actual]).into_owned();
-        *p += actual;
-        Some(s)
-    } else {
-        let actual = (first as usize).saturating_sub(1);
-        if *p + actual > data.len() {
-            return None;
-        }
-        let s = String::from_utf8_lossy(&data[*p..*p + actual]).into_owned();
-        *p += actual;
-        Some(s)
+impl<'a> LjReader<'a> {
+    fn byte(&mut self, what: &'static str) -> Result<u8, LuaError> {
+        let b = *self.data.get(self.pos).ok_or(LuaError::Truncated(what))?;
+        self.pos += 1;
+        Ok(b)
+    }
+
+    fn take(&mut self, n: usize, what: &'static str) -> Result<&'a [u8], LuaError> {
+        let end = self.pos.checked_add(n).ok_or(LuaError::Truncated(what))?;
+        let s = self
+            .data
+            .get(self.pos..end)
+            .ok_or(LuaError::Truncated(what))?;
+        self.pos = end;
+        Ok(s)
     }
+
+    fn uleb(&mut self, what: &'static str) -> Result<u64, LuaError> {
+        let mut v = 0u64;
+        let mut shift = 0;
+        loop {
+            let b = self.byte(what)?;
+            if shift < 64 {
+                v |= ((b & 0x7f) as u64) << shift;
+            }
+            if b & 0x80 == 0 {
+                return Ok(v);
+            }
+            shift += 7;
+        }
+    }
+
+    /// `bcread_uleb128_33`: the low bit of the first byte is a tag, the
+    /// remaining 33 bits a ULEB128 value. Returns `(tag, value)`.
+    fn uleb33(&mut self, what: &'static str) -> Result<(bool, u64), LuaError> {
+        let first = self.byte(what)?;
+        let tag = first & 1 != 0;
+        let mut v = (first >> 1) as u64;
+        if v >= 0x40 {
+            v &= 0x3f;
+            let mut shift = 6;
+            loop {
+                let b = self.byte(what)?;
+                if shift < 64 {
+                    v |= ((b & 0x7f) as u64) << shift;
+                }
+                if b & 0x80 == 0 {
+                    break;
+                }
+                shift += 7;
+            }
+        }
+        Ok((tag, v & 0xffff_ffff))
+    }
+
+    fn count(&mut self, what: &'static str) -> Result<usize, LuaError> {
+        let n = self.uleb(what)?;
+        if n > (self.data.len() - self.pos) as u64 {
+            return Err(LuaError::Truncated(what));
+        }
+        Ok(n as usize)
+    }
+
+    fn str_of(&mut self, len: u64, what: &'static str) -> Result<String, LuaError> {
+        let len = usize::try_from(len).map_err(|_| LuaError::Truncated(what))?;
+        Ok(String::from_utf8_lossy(self.take(len, what)?).into_owned())
+    }
+
+    /// 64-bit value split into two ULEB128 halves, low word first.
+    fn u64_pair(&mut self, what: &'static str) -> Result<u64, LuaError> {
+        let lo = self.uleb(what)? & 0xffff_ffff;
+        let hi = self.uleb(what)? & 0xffff_ffff;
+        Ok((hi << 32) | lo)
+    }
+
+    fn ktab_entry(&mut self) -> Result<LuaConstant, LuaError> {
+        let tp = self.uleb("table entry")?;
+        Ok(match tp {
+            BCDUMP_KTAB_NIL => LuaConstant::Nil,
+            BCDUMP_KTAB_FALSE => LuaConstant::Bool(false),
+            BCDUMP_KTAB_TRUE => LuaConstant::Bool(true),
+            BCDUMP_KTAB_INT => LuaConstant::Int(self.uleb("table int")? as u32 as i32 as i64),
+            BCDUMP_KTAB_NUM => LuaConstant::Num(f64::from_bits(self.u64_pair("table num")?)),
+            _ => LuaConstant::Str(self.str_of(tp - BCDUMP_KTAB_STR, "table string")?),
+        })
+    }
+
+    /// One GC constant; `None` for a child-prototype reference.
+    fn kgc(&mut self) -> Result<Option<LuaConstant>, LuaError> {
+        let tp = self.uleb("gc constant")?;
+        Ok(Some(match tp {
+            BCDUMP_KGC_CHILD => return Ok(None),
+            BCDUMP_KGC_TAB => {
+                let narray = self.count("table array")?;
+                let nhash = self.count("table hash")?;
+                let mut array = Vec::new();
+                for _ in 0..narray {
+                    array.push(self.ktab_entry()?);
+                }
+                let mut hash = Vec::new();
+                for _ in 0..nhash {
+                    let k = self.ktab_entry()?;
+                    let v = self.ktab_entry()?;
+                    hash.push((k, v));
+                }
+                LuaConstant::Table { array, hash }
+            }
+            BCDUMP_KGC_I64 => LuaConstant::Cdata(format!("{}LL", self.u64_pair("i64")? as i64)),
+            BCDUMP_KGC_U64 => LuaConstant::Cdata(format!("{}ULL", self.u64_pair("u64")?)),
+            BCDUMP_KGC_COMPLEX => {
+                let re = f64::from_bits(self.u64_pair("complex")?);
+                let im = f64::from_bits(self.u64_pair("complex")?);
+                LuaConstant::Cdata(format!("{:?}{:+?}i", re, im))
+            }
+            _ => LuaConstant::Str(self.str_of(tp - BCDUMP_KGC_STR, "string constant")?),
+        }))
+    }
+
+    fn cstring(&mut self, what: &'static str) -> Result<String, LuaError> {
+        let rest = &self.data[self.pos..];
+        let n = rest
+            .iter()
+            .position(|&b| b == 0)
+            .ok_or(LuaError::Truncated(what))?;
+        let s = String::from_utf8_lossy(&rest[..n]).into_owned();
+        self.pos += n + 1;
+        Ok(s)
+    }
+}
+
+/// A LuaJIT prototype with the children it popped off the loader stack.
+struct LjProto {
+    func: LuaFunction,
+    children: Vec<LjProto>,
 }
 
 fn parse_luajit(data: &[u8]) -> Result<LuaInfo, LuaError> {
-    // LuaJIT header: \x1b L J <version> <flags> [<chunkname>]
-    // version 1 = LJ1, 2 = LJ2 (the modern one).
-    if data.len() < 5 {
-        return Err(LuaError::Truncated("ljheader"));
-    }
-    let version_byte = data[3];
-    let _flags = data[4];
-    let _ = version_byte;
-    Ok(LuaInfo {
+    // Header: \x1b L J <dump version> <flags: uleb128> [<chunkname>]
+    // Dump version 1 is LuaJIT 2.0, 2 is LuaJIT 2.1 with FR2 (two-slot
+    // frames). The chunkname is absent when stripped.
+    let version = data[3];
+    let mut r = LjReader { data, pos: 4 };
+    let flags = r.uleb("ljheader")?;
+    let little_endian = flags & BCDUMP_F_BE == 0;
+    let stripped = flags & BCDUMP_F_STRIP != 0;
+    let mut info = LuaInfo {
         kind: LuaKind::LuaJit,
-        format: 0,
-        source: None, // TODO v1: parse LJ chunkname
-        little_endian: true,
-    })
+        format: version,
+        source: None,
+        little_endian,
+        functions: Vec::new(),
+    };
+    if !(1..=2).contains(&version) {
+        return Ok(info);
+    }
+    if !stripped {
+        let len = r.uleb("chunkname")?;
+        info.source = Some(r.str_of(len, "chunkname")?);
+    }
+
+    // Prototypes follow children-first, each prefixed with its length,
+    // until a zero length. A parent pops one finished prototype off the
+    // stack per child-reference constant, so the main chunk is the last
+    // one left.
+    let mut stack: Vec<LjProto> = Vec::new();
+    loop {
+        let len = r.uleb("proto length")? as usize;
+        if len == 0 {
+            break;
+        }
+        let body = r.take(len, "proto")?;
+        let proto = parse_lj_proto(body, stripped, info.source.as_deref(), &mut stack)?;
+        stack.push(proto);
+    }
+    let Some(main) = stack.pop() else {
+        return Err(LuaError::Truncated("main proto"));
+    };
+
+    fn flatten(
+        p: LjProto,
+        parent: Option<usize>,
+        out: &mut Vec<LuaFunction>,
+        depth: usize,
+    ) -> Result<(), LuaError> {
+        if depth >= MAX_DEPTH {
+            return Err(LuaError::TooDeep);
+        }
+        let idx = out.len();
+        let mut func = p.func;
+        func.parent = parent;
+        out.push(func);
+        for c in p.children {
+            flatten(c, Some(idx), out, depth + 1)?;
+        }
+        Ok(())
+    }
+    flatten(main, None, &mut info.functions, 0)?;
+    Ok(info)
+}
+
+fn parse_lj_proto(
+    body: &[u8],
+    stripped: bool,
+    source: Option<&str>,
+    stack: &mut Vec<LjProto>,
+) -> Result<LjProto, LuaError> {
+    let mut r = LjReader { data: body, pos: 0 };
+    let flags = r.byte("proto flags")?;
+    let mut f = LuaFunction {
+        source: source.map(str::to_string),
+        num_params: r.byte("numparams")?,
+        // PROTO_VARARG
+        is_vararg: flags & 0x02 != 0,
+        max_stack: r.byte("framesize")?,
+        num_upvalues: r.byte("numuv")? as u32,
+        ..Default::default()
+    };
+    let nkgc = r.count("sizekgc")?;
+    let nkn = r.count("sizekn")?;
+    // Excludes the FUNCF header instruction the loader synthesises.
+    let nbc = r.count("sizebc")?;
+    f.num_instructions = nbc as u32;
+    let mut dbg_len = 0;
+    let mut numline = 0;
+    if !stripped {
+        dbg_len = r.count("sizedbg")?;
+        if dbg_len != 0 {
+            f.line_defined = r.uleb("firstline")? as u32;
+            numline = r.uleb("numline")? as u32;
+            f.last_line_defined = f.line_defined.saturating_add(numline);
+        }
+    }
+    r.take(nbc * 4, "bytecode")?;
+    r.take(f.num_upvalues as usize * 2, "upvalue refs")?;
+
+    let mut children = Vec::new();
+    for _ in 0..nkgc {
+        match r.kgc()? {
+            Some(k) => f.constants.push(k),
+            None => children.push(stack.pop().ok_or(LuaError::Truncated("child proto"))?),
+        }
+    }
+    // Popped last-written first; put them back in source order.
+    children.reverse();
+    f.num_children = children.len() as u32;
+    for _ in 0..nkn {
+        let (is_num, lo) = r.uleb33("numeric constant")?;
+        f.constants.push(if is_num {
+            let hi = r.uleb("numeric constant")? & 0xffff_ffff;
+            LuaConstant::Num(f64::from_bits((hi << 32) | lo))
+        } else {
+            LuaConstant::Int(lo as u32 as i32 as i64)
+        });
+    }
+
+    if dbg_len != 0 {
+        // lineinfo (1, 2, or 4 bytes per instruction depending on the
+        // line span), upvalue names, then varinfo records.
+        let dbg = r.take(dbg_len, "debug info")?;
+        let mut d = LjReader { data: dbg, pos: 0 };
+        let width = match numline {
+            0..=255 => 1,
+            256..=65535 => 2,
+            _ => 4,
+        };
+        d.take(nbc * width, "lineinfo")?;
+        for _ in 0..f.num_upvalues {
+            let name = d.cstring("upvalue name")?;
+            f.upvalue_names.push(name);
+        }
+        while d.pos < dbg.len() {
+            let tag = dbg[d.pos];
+            if tag == 0 {
+                break;
+            }
+            if tag < LJ_VARNAME_MAX {
+                // "(for index)" and friends.
+                d.pos += 1;
+            } else {
+                let name = d.cstring("varinfo")?;
+                f.locals.push(name);
+            }
+            d.uleb("varinfo startpc")?;
+            d.uleb("varinfo endpc")?;
+        }
+    }
+    Ok(LjProto { func: f, children })
 }
 
 #[cfg(test)]
@@ -225,13 +872,20 @@ mod tests {
     use super::*;
     use std::path::Path;
 
+    /// Fixture bytes, or `None` when the sample is missing or is a
+    /// git-lfs pointer that was never fetched.
+    fn read_fixture(path: &str) -> Option<Vec<u8>> {
+        let data = std::fs::read(Path::new(path)).ok()?;
+        (!data.starts_with(b"version https://git-lfs.github.com/spec/")).then_some(data)
+    }
+
     #[test]
     fn detects_lua_53_bytecode() {
-        let path = Path::new("samples/binaries/platforms/linux/amd64/export/lua/hello-lua5.3.luac");
-        if !path.exists() {
+        let Some(data) =
+            read_fixture("samples/binaries/platforms/linux/amd64/export/lua/hello-lua5.3.luac")
+        else {
             return;
-        }
-        let data = std::fs::read(path).unwrap();
+        };
         let info = parse_lua(&data).expect("parse");
         // The 5.3.luac sample's actual version byte is 0x53.
         assert!(matches!(info.kind, LuaKind::Lua53 | LuaKind::Lua54));
@@ -239,11 +893,11 @@ mod tests {
 
     #[test]
     fn detects_luajit_bytecode() {
-        let path = Path::new("samples/binaries/platforms/linux/amd64/export/lua/hello-luajit.luac");
-        if !path.exists() {
+        let Some(data) =
+            read_fixture("samples/binaries/platforms/linux/amd64/export/lua/hello-luajit.luac")
+        else {
             return;
-        }
-        let data = std::fs::read(path).unwrap();
+        };
         let info = parse_lua(&data).expect("parse");
         assert!(matches!(info.kind, LuaKind::LuaJit));
     }
@@ -260,20 +914,237 @@ mod tests {
     fn extracts_source_filename_when_present() {
         // hello-lua5.3.luac was compiled from samples/source/lua/hello.lua
         // — depending on whether luac -s was used the source field may
-        // be the full path, the basename, or absent. Just check it
-        // parses without error.
-        let path = Path::new("samples/binaries/platforms/linux/amd64/export/lua/hello-lua5.3.luac");
-        if !path.exists() {
+        // be the full path, the basename, or absent.
+        let Some(data) =
+            read_fixture("samples/binaries/platforms/linux/amd64/export/lua/hello-lua5.3.luac")
+        else {
             return;
-        }
-        let data = std::fs::read(path).unwrap();
+        };
         let info = parse_lua(&data).expect("parse");
-        // Source extraction is best-effort. When present, should
-        // contain "hello" (the filename stem).
         if let Some(src) = &info.source {
-            // Either a full path, a relative path, or just "hello.lua".
-            // We only assert it's non-empty and probably ASCII-printable.
             assert!(!src.is_empty());
         }
     }
+
+    // Hand-assembled chunks for
+    //
+    //     local s = "hi"
+    //     function f(a) return a + 1.5 end
+    //
+    // in each layout: a main function with one string constant and one
+    // child taking one parameter with a numeric constant.
+
+    /// 5.1 chunk from a 32-bit big-endian build.
+    fn lua51_chunk() -> Vec<u8> {
+        fn s(out: &mut Vec<u8>, v: &str) {
+            out.extend_from_slice(&(v.len() as u32 + 1).to_be_bytes());
+            out.extend_from_slice(v.as_bytes());
+            out.push(0);
+        }
+        fn i(out: &mut Vec<u8>, v: u32) {
+            out.extend_from_slice(&v.to_be_bytes());
+        }
+        let mut c = b"\x1bLua\x51\x00".to_vec();
+        c.extend_from_slice(&[0, 4, 4, 4, 8, 0]);
+        // main
+        s(&mut c, "@t.lua");
+        i(&mut c, 0);
+        i(&mut c, 0);
+        c.extend_from_slice(&[0, 0, 2, 2]);
+        i(&mut c, 2);
+        c.extend_from_slice(&[0; 8]);
+        i(&mut c, 2);
+        c.push(4);
+        s(&mut c, "hi");
+        c.push(4);
+        s(&mut c, "f");
+        i(&mut c, 1);
+        {
+            // f: source NULL, inherits "@t.lua"
+            i(&mut c, 0);
+            i(&mut c, 2);
+            i(&mut c, 2);
+            c.extend_from_slice(&[0, 1, 0, 2]);
+            i(&mut c, 1);
+            c.extend_from_slice(&[0; 4]);
+            i(&mut c, 1);
+            c.push(3);
+            c.extend_from_slice(&1.5f64.to_be_bytes());
+            i(&mut c, 0);
+            i(&mut c, 1);
+            i(&mut c, 2);
+            i(&mut c, 1);
+            s(&mut c, "a");
+            i(&mut c, 0);
+            i(&mut c, 1);
+            i(&mut c, 0);
+        }
+        i(&mut c, 2);
+        i(&mut c, 1);
+        i(&mut c, 1);
+        i(&mut c, 1);
+        s(&mut c, "s");
+        i(&mut c, 1);
+        i(&mut c, 2);
+        i(&mut c, 0);
+        c
+    }
+
+    fn lua54_chunk() -> Vec<u8> {
+        fn s(out: &mut Vec<u8>, v: &str) {
+            out.push((v.len() as u8 + 1) | 0x80);
+            out.extend_from_slice(v.as_bytes());
+        }
+        fn n(out: &mut Vec<u8>, v: u8) {
+            out.push(v | 0x80);
+        }
+        let mut c = b"\x1bLua\x54\x00\x19\x93\r\n\x1a\n\x04\x08\x08".to_vec();
+        c.extend_from_slice(&0x5678i64.to_le_bytes());
+        c.extend_from_slice(&370.5f64.to_le_bytes());
+        c.push(1);
+        // main
+        s(&mut c, "@t.lua");
+        n(&mut c, 0);
+        n(&mut c, 0);
+        c.extend_from_slice(&[0, 1, 2]);
+        n(&mut c, 2);
+        c.extend_from_slice(&[0; 8]);
+        n(&mut c, 2);
+        c.push(0x04);
+        s(&mut c, "hi");
+        c.push(0x11);
+        n(&mut c, 1);
+        c.extend_from_slice(&[1, 0, 0]);
+        n(&mut c, 1);
+        {
+            // f: stripped source (0) inherits "@t.lua"
+            n(&mut c, 0);
+            n(&mut c, 2);
+            n(&mut c, 2);
+            c.extend_from_slice(&[1, 0, 2]);
+            n(&mut c, 1);
+            c.extend_from_slice(&[0; 4]);
+            n(&mut c, 2);
+            c.push(0x13);
+            c.extend_from_slice(&1.5f64.to_le_bytes());
+            c.push(0x03);
+            c.extend_from_slice(&(-7i64).to_le_bytes());
+            n(&mut c, 0);
+            n(&mut c, 0);
+            n(&mut c, 1);
+            c.push(0);
+            n(&mut c, 1);
+            n(&mut c, 2);
+            n(&mut c, 2);
+            n(&mut c, 1);
+            s(&mut c, "a");
+            n(&mut c, 0);
+            n(&mut c, 1);
+            n(&mut c, 0);
+        }
+        n(&mut c, 2);
+        c.extend_from_slice(&[0, 0]);
+        n(&mut c, 0);
+        n(&mut c, 1);
+        s(&mut c, "s");
+        n(&mut c, 1);
+        n(&mut c, 2);
+        n(&mut c, 1);
+        s(&mut c, "_ENV");
+        c
+    }
+
+    fn luajit_chunk() -> Vec<u8> {
+        let mut c = b"\x1bLJ\x02\x00".to_vec();
+        c.push(6);
+        c.extend_from_slice(b"@t.lua");
+        // f: 1 param, no gc constants, one number, one instruction.
+        let mut f = vec![0x00, 1, 2, 0, 0, 1, 1];
+        let mut dbg = vec![0];
+        dbg.extend_from_slice(b"a\0");
+        dbg.extend_from_slice(&[0, 2, 0]);
+        f.push(dbg.len() as u8);
+        f.extend_from_slice(&[2, 0]);
+        f.extend_from_slice(&[0; 4]);
+        // 1.5 = 0x3ff8_0000_0000_0000: lo 0 with the number tag, hi word.
+        f.push(0x01);
+        f.extend_from_slice(&[0x80, 0x80, 0xe0, 0xff, 0x03]);
+        f.extend_from_slice(&dbg);
+        c.push(f.len() as u8);
+        c.extend_from_slice(&f);
+        // main: child, "hi", table {"x", k = true}, integer 42.
+        let mut m = vec![0x01 | 0x02, 0, 3, 0, 3, 1, 2];
+        m.push(0);
+        m.extend_from_slice(&[0; 8]);
+        m.push(0);
+        m.push(5 + 2);
+        m.extend_from_slice(b"hi");
+        m.extend_from_slice(&[1, 1, 1, 5 + 1, b'x', 5 + 1, b'k', 2]);
+        m.push(42 << 1);
+        c.push(m.len() as u8);
+        c.extend_from_slice(&m);
+        c.push(0);
+        c
+    }
+
+    fn check_shape(info: &LuaInfo) {
+        assert_eq!(info.source.as_deref(), Some("@t.lua"));
+        assert_eq!(info.functions.len(), 2);
+        let (main, f) = (&info.functions[0], &info.functions[1]);
+        assert_eq!(main.parent, None);
+        assert_eq!(main.num_children, 1);
+        assert_eq!(f.parent, Some(0));
+        assert_eq!(f.source.as_deref(), Some("@t.lua"));
+        assert_eq!(f.num_params, 1);
+        assert_eq!(f.line_defined, 2);
+        assert!(f.constants.contains(&LuaConstant::Num(1.5)));
+        assert!(info.strings().contains(&"hi".to_string()));
+    }
+
+    #[test]
+    fn walks_lua51_big_endian() {
+        let info = parse_lua(&lua51_chunk()).unwrap();
+        assert_eq!(info.kind, LuaKind::Lua51);
+        assert!(!info.little_endian);
+        check_shape(&info);
+        assert_eq!(info.strings(), vec!["hi", "f"]);
+        assert_eq!(info.functions[0].locals, vec!["s"]);
+        assert_eq!(info.functions[1].locals, vec!["a"]);
+        assert_eq!(info.functions[0].num_instructions, 2);
+    }
+
+    #[test]
+    fn walks_lua54() {
+        let info = parse_lua(&lua54_chunk()).unwrap();
+        assert_eq!(info.kind, LuaKind::Lua54);
+        assert!(info.little_endian);
+        check_shape(&info);
+        assert_eq!(info.functions[0].constants[1], LuaConstant::Bool(true));
+        assert_eq!(info.functions[1].constants[1], LuaConstant::Int(-7));
+        assert_eq!(info.functions[0].upvalue_names, vec!["_ENV"]);
+        assert!(info.functions[0].is_vararg);
+    }
+
+    #[test]
+    fn walks_luajit() {
+        let info = parse_lua(&luajit_chunk()).unwrap();
+        assert_eq!(info.kind, LuaKind::LuaJit);
+        assert_eq!(info.format, 2);
+        check_shape(&info);
+        let main = &info.functions[0];
+        assert_eq!(main.constants.len(), 3);
+        assert_eq!(main.constants[2], LuaConstant::Int(42));
+        assert_eq!(info.strings(), vec!["hi", "x", "k"]);
+        assert_eq!(info.functions[1].locals, vec!["a"]);
+        assert_eq!(info.functions[1].last_line_defined, 2);
+    }
+
+    #[test]
+    fn truncated_chunk_is_an_error() {
+        let c = lua54_chunk();
+        assert!(matches!(
+            parse_lua(&c[..c.len() - 3]),
+            Err(LuaError::Truncated(_))
+        ));
+    }
 }
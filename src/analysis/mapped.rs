@@ -0,0 +1,246 @@
+//! Section-mapped view of an image, as the loader would see it.
+//!
+//! Metadata parsers (RTTI, exception tables) follow pointers stored in the
+//! image. In position-independent ELF those pointers are zero until the
+//! loader applies dynamic relocations, and pointers to types or functions in
+//! other modules are only import references. `MappedImage` maps the code and
+//! data sections at their link-time addresses, applies pointer-sized
+//! dynamic relocations, and indexes defined symbols, so those parsers can
+//! read pointers without a full loader.
+
+use std::collections::HashMap;
+
+use object::macho::S_ATTR_PURE_INSTRUCTIONS;
+use object::{
+    BinaryFormat, Object, ObjectSection, ObjectSymbol, ObjectSymbolTable, RelocationTarget,
+    SectionFlags, SectionKind,
+};
+
+/// Longest string read by `MappedImage::cstr`.
+const MAX_STR: usize = 1024;
+
+/// A pointer-sized value whose load-time contents come from a dynamic
+/// relocation.
+#[derive(Debug, Clone, PartialEq)]
+pub(crate) enum Reloc {
+    /// Resolved in-image address (RELATIVE, or a symbol this image defines)
+    Value(u64),
+    /// Imported symbol plus addend
+    Import(String, i64),
+}
+
+/// A pointer-sized value as the loader would see it.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub(crate) enum Word<'m> {
+    Addr(u64),
+    Import(&'m str, i64),
+}
+
+pub(crate) struct Region<'a> {
+    pub va: u64,
+    pub bytes: &'a [u8],
+    pub exec: bool,
+}
+
+/// Mapped section bytes, dynamic relocations, and symbols of an image.
+pub(crate) struct MappedImage<'a> {
+    pub regions: Vec<Region<'a>>,
+    pub relocs: HashMap<u64, Reloc>,
+    /// Defined symbols: name -> (address, size)
+    pub symbols: HashMap<String, (u64, u64)>,
+    /// Defined symbol names by address (first one wins)
+    pub names: HashMap<u64, String>,
+    pub ptr_size: usize,
+    pub big_endian: bool,
+    pub image_base: u64,
+    pub is_pe: bool,
+}
+
+impl<'a> MappedImage<'a> {
+    pub fn new(ptr_size: usize, big_endian: bool) -> Self {
+        Self {
+            regions: Vec::new(),
+            relocs: HashMap::new(),
+            symbols: HashMap::new(),
+            names: HashMap::new(),
+            ptr_size,
+            big_endian,
+            image_base: 0,
+            is_pe: false,
+        }
+    }
+
+    pub fn parse(data: &'a [u8]) -> Option<Self> {
+        let obj = object::read::File::parse(data).ok()?;
+        let mut mem = MappedImage::new(if obj.is_64() { 8 } else { 4 }, !obj.is_little_endian());
+        mem.image_base = obj.relative_address_base();
+        mem.is_pe = obj.format() == BinaryFormat::Pe;
+        // ELF symbol tables may carry `name@VERSION` for versioned imports.
+        let is_elf = obj.format() == BinaryFormat::Elf;
+        let unversioned = |name: &'a str| -> &'a str {
+            if is_elf {
+                name.split('@').next().unwrap_or(name)
+            } else {
+                name
+            }
+        };
+
+        for sec in obj.sections() {
+            let exec = sec.kind() == SectionKind::Text;
+            // Go's Mach-O sections (__rodata, __go_type, __gopclntab,
+            // __noptrdata) are plain S_REGULAR sections with names `object`
+            // does not classify.
+            let macho_data = matches!(
+                sec.flags(),
+                SectionFlags::MachO { flags } if flags & (S_ATTR_PURE_INSTRUCTIONS | 0xff) == 0
+            );
+            let data_like = matches!(
+                sec.kind(),
+                SectionKind::Data
+                    | SectionKind::ReadOnlyData
+                    | SectionKind::ReadOnlyDataWithRel
+                    | SectionKind::ReadOnlyString
+            ) || (sec.kind() == SectionKind::Unknown && macho_data);
+            if (!exec && !data_like) || sec.address() == 0 {
+                continue;
+            }
+            match sec.data() {
+                Ok(bytes) if !bytes.is_empty() => mem.regions.push(Region {
+                    va: sec.address(),
+                    bytes,
+                    exec,
+                }),
+                _ => {}
+            }
+        }
+
+        for sym in obj.symbols().chain(obj.dynamic_symbols()) {
+            let Ok(name) = sym.name() else {
+                continue;
+            };
+            if name.is_empty() || sym.is_undefined() || sym.address() == 0 {
+                continue;
+            }
+            mem.add_symbol(unversioned(name), sym.address(), sym.size());
+        }
+
+        let bits = (mem.ptr_size * 8) as u8;
+        let dynsym = obj.dynamic_symbol_table();
+        for (offset, reloc) in obj.dynamic_relocations().into_iter().flatten() {
+            if reloc.size() != 0 && reloc.size() != bits {
+                continue;
+            }
+            let addend = if reloc.has_implicit_addend() {
+                mem.uint(offset, mem.ptr_size).unwrap_or(0) as i64
+            } else {
+                reloc.addend()
+            };
+            let value = match reloc.target() {
+                RelocationTarget::Absolute => Reloc::Value(addend as u64),
+                RelocationTarget::Symbol(idx) => {
+                    let Some(sym) = dynsym.as_ref().and_then(|t| t.symbol_by_index(idx).ok())
+                    else {
+                        continue;
+                    };
+                    if !sym.is_undefined() && sym.address() != 0 {
+                        Reloc::Value(sym.address().wrapping_add(addend as u64))
+                    } else {
+                        match sym.name() {
+                            Ok(name) if !name.is_empty() => {
+                                Reloc::Import(unversioned(name).to_string(), addend)
+                            }
+                            _ => continue,
+                        }
+                    }
+                }
+                _ => continue,
+            };
+            mem.relocs.insert(offset, value);
+        }
+        Some(mem)
+    }
+
+    pub fn add_symbol(&mut self, name: &str, va: u64, size: u64) {
+        self.symbols.entry(name.to_string()).or_insert((va, size));
+        self.names.entry(va).or_insert_with(|| name.to_string());
+    }
+
+    pub fn region(&self, va: u64) -> Option<&Region<'a>> {
+        self.regions
+            .iter()
+            .find(|r| va >= r.va && va - r.va < r.bytes.len() as u64)
+    }
+
+    pub fn bytes(&self, va: u64, len: usize) -> Option<&'a [u8]> {
+        let r = self.region(va)?;
+        let bytes: &'a [u8] = r.bytes;
+        let start = (va - r.va) as usize;
+        bytes.get(start..start.checked_add(len)?)
+    }
+
+    pub fn uint(&self, va: u64, size: usize) -> Option<u64> {
+        let b = self.bytes(va, size)?;
+        let fold = |acc: u64, byte: &u8| acc << 8 | *byte as u64;
+        Some(if self.big_endian {
+            b.iter().fold(0, fold)
+        } else {
+            b.iter().rev().fold(0, fold)
+        })
+    }
+
+    pub fn u32(&self, va: u64) -> Option<u32> {
+        self.uint(va, 4).map(|v| v as u32)
+    }
+
+    pub fn int(&self, va: u64, size: usize) -> Option<i64> {
+        let v = self.uint(va, size)?;
+        Some(match size {
+            8 => v as i64,
+            2 => v as u16 as i16 as i64,
+            _ => v as u32 as i32 as i64,
+        })
+    }
+
+    /// Pointer-sized value at `va`, with any dynamic relocation applied.
+    pub fn word(&self, va: u64) -> Option<Word<'_>> {
+        match self.relocs.get(&va) {
+            Some(Reloc::Value(v)) => Some(Word::Addr(*v)),
+            Some(Reloc::Import(name, addend)) => Some(Word::Import(name, *addend)),
+            None => self.uint(va, self.ptr_size).map(Word::Addr),
+        }
+    }
+
+    pub fn pointer(&self, va: u64) -> Option<u64> {
+        match self.word(va)? {
+            Word::Addr(a) => Some(a),
+            Word::Import(..) => None,
+        }
+    }
+
+    pub fn is_code(&self, va: u64) -> bool {
+        self.region(va).is_some_and(|r| r.exec)
+    }
+
+    pub fn is_data(&self, va: u64) -> bool {
+        self.region(va).is_some_and(|r| !r.exec)
+    }
+
+    /// NUL-terminated printable string at `va`.
+    pub fn cstr(&self, va: u64) -> Option<&'a str> {
+        let r = self.region(va)?;
+        let bytes: &'a [u8] = r.bytes;
+        let rest = &bytes[(va - r.va) as usize..];
+        let len = rest.iter().take(MAX_STR).position(|&b| b == 0)?;
+        let s = std::str::from_utf8(&rest[..len]).ok()?;
+        (len > 0 && s.bytes().all(|b| b.is_ascii_graphic() || b == b' ')).then_some(s)
+    }
+
+    /// `align`-aligned VAs across the data regions.
+    pub fn scan(&self, align: u64) -> impl Iterator<Item = u64> + '_ {
+        self.regions.iter().filter(|r| !r.exec).flat_map(move |r| {
+            let start = r.va.div_ceil(align) * align;
+            let end = (r.va + r.bytes.len() as u64).saturating_sub(align - 1);
+            (start..end.max(start)).step_by(align as usize)
+        })
+    }
+}
@@ -7,13 +7,21 @@
 
 pub mod aarch64_literals;
 pub mod cfg;
+pub mod cil_il;
 pub mod cil_metadata;
+pub mod config_carver;
+pub mod dead_code;
+pub mod eh;
 pub mod elf_got;
 pub mod elf_plt;
 pub mod entry;
+pub mod gobuildinfo;
+pub mod goitab;
+pub mod gometa;
 pub mod gopclntab;
 pub mod ioctl_surface;
 pub mod ioctl_taint;
+pub mod java_bytecode;
 pub mod java_class;
 pub mod java_jar;
 pub mod jump_table;
@@ -21,8 +29,13 @@ pub mod linux_ioctl;
 pub mod linux_symbolic_frontend;
 pub mod lua_bytecode;
 pub mod macho_stubs;
+pub(crate) mod mapped;
 pub mod memory;
 pub mod pe_iat;
+pub mod pipeline;
+pub mod pyc;
+pub(crate) mod pyc_opcodes;
+pub mod rtti;
 pub mod view;
 pub mod vtable;
 pub mod xrefs;
@@ -0,0 +1,252 @@
+//! Cross-pass artifact store.
+//!
+//! Passes publish intermediate results (the entry point, discovered
+//! functions, decoded strings, resolved constants, recovered buffers) to a
+//! `Blackboard` keyed by type, and later passes read them back instead of
+//! re-deriving the same data. Each artifact type holds at most one value per
+//! run; publishing again replaces it (a refining pass may rewrite an earlier
+//! pass's output) and the store remembers which pass produced the current
+//! value. Consumers should list producers in `AnalysisPass::dependencies` so
+//! the scheduler orders them correctly.
+
+use crate::analysis::config_carver::EmbeddedConfig;
+use crate::analysis::dead_code::DeadFunction;
+use crate::analysis::eh::EhInfo;
+use crate::analysis::goitab::GoItabs;
+use crate::analysis::rtti::ClassHierarchy;
+use crate::core::call_graph::CallGraph;
+use crate::core::function::Function;
+use crate::ir::struct_recover::RecoveredStruct;
+use std::any::{Any, TypeId};
+use std::collections::{BTreeMap, HashMap};
+
+/// A value passes can exchange through the blackboard.
+pub trait Artifact: Any + Send + Sync {
+    /// Stable name used in reports and diagnostics (e.g. "functions").
+    const NAME: &'static str;
+}
+
+/// Resolved entry point VA.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct EntryPoint(pub u64);
+
+impl Artifact for EntryPoint {
+    const NAME: &'static str = "entry_point";
+}
+
+/// Discovered functions.
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct Functions(pub Vec<Function>);
+
+impl Artifact for Functions {
+    const NAME: &'static str = "functions";
+}
+
+impl Artifact for CallGraph {
+    const NAME: &'static str = "call_graph";
+}
+
+impl Artifact for ClassHierarchy {
+    const NAME: &'static str = "class_hierarchy";
+}
+
+impl Artifact for EhInfo {
+    const NAME: &'static str = "eh_info";
+}
+
+impl Artifact for GoItabs {
+    const NAME: &'static str = "go_itabs";
+}
+
+/// Configuration blobs carved from the image.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct EmbeddedConfigs(pub Vec<EmbeddedConfig>);
+
+impl Artifact for EmbeddedConfigs {
+    const NAME: &'static str = "embedded_configs";
+}
+
+/// Struct layouts recovered from field accesses.
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct RecoveredStructs(pub Vec<RecoveredStruct>);
+
+impl Artifact for RecoveredStructs {
+    const NAME: &'static str = "recovered_structs";
+}
+
+/// Functions no loader root, call, or address reference reaches.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct DeadFunctions(pub Vec<DeadFunction>);
+
+impl Artifact for DeadFunctions {
+    const NAME: &'static str = "dead_functions";
+}
+
+/// A string recovered from the image, possibly after decoding.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct DecodedString {
+    /// VA the string (or its encoded form) lives at, if mapped
+    pub va: Option<u64>,
+    /// File offset of the source bytes, if file-backed
+    pub offset: Option<u64>,
+    pub text: String,
+    /// Encoding or decoder that produced `text` (e.g. "utf16le", "xor:0x5a")
+    pub encoding: String,
+}
+
+/// Strings recovered so far, in discovery order.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct DecodedStrings(pub Vec<DecodedString>);
+
+impl Artifact for DecodedStrings {
+    const NAME: &'static str = "decoded_strings";
+}
+
+/// Constant values resolved at specific VAs (e.g. by propagation or
+/// emulation), keyed by VA.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct ResolvedConstants(pub BTreeMap<u64, u64>);
+
+impl Artifact for ResolvedConstants {
+    const NAME: &'static str = "resolved_constants";
+}
+
+/// A buffer reconstructed during analysis (an unpacked stage, a decrypted
+/// blob).
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RecoveredBuffer {
+    /// VA the buffer was written to or read from, if known
+    pub va: Option<u64>,
+    pub data: Vec<u8>,
+    /// How the buffer was obtained
+    pub note: String,
+}
+
+/// Buffers recovered so far, in discovery order.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct RecoveredBuffers(pub Vec<RecoveredBuffer>);
+
+impl Artifact for RecoveredBuffers {
+    const NAME: &'static str = "recovered_buffers";
+}
+
+struct Slot {
+    name: &'static str,
+    producer: String,
+    value: Box<dyn Any + Send + Sync>,
+}
+
+/// Type-keyed store of artifacts shared by the passes of one run.
+#[derive(Default)]
+pub struct Blackboard {
+    slots: HashMap<TypeId, Slot>,
+}
+
+impl Blackboard {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Store `value` as produced by `producer`, returning the value it
+    /// replaces.
+    pub fn publish<T: Artifact>(&mut self, producer: &str, value: T) -> Option<T> {
+        let slot = Slot {
+            name: T::NAME,
+            producer: producer.to_string(),
+            value: Box::new(value),
+        };
+        self.slots
+            .insert(TypeId::of::<T>(), slot)
+            .and_then(|old| old.value.downcast().ok())
+            .map(|b| *b)
+    }
+
+    pub fn get<T: Artifact>(&self) -> Option<&T> {
+        self.slots
+            .get(&TypeId::of::<T>())
+            .and_then(|s| s.value.downcast_ref())
+    }
+
+    /// Mutable access, for passes that extend an existing artifact in place.
+    /// The recorded producer is unchanged.
+    pub fn get_mut<T: Artifact>(&mut self) -> Option<&mut T> {
+        self.slots
+            .get_mut(&TypeId::of::<T>())
+            .and_then(|s| s.value.downcast_mut())
+    }
+
+    /// Remove and return the artifact.
+    pub fn take<T: Artifact>(&mut self) -> Option<T> {
+        self.slots
+            .remove(&TypeId::of::<T>())
+            .and_then(|s| s.value.downcast().ok())
+            .map(|b| *b)
+    }
+
+    pub fn contains<T: Artifact>(&self) -> bool {
+        self.slots.contains_key(&TypeId::of::<T>())
+    }
+
+    /// Pass that published the current value of `T`.
+    pub fn producer<T: Artifact>(&self) -> Option<&str> {
+        self.slots
+            .get(&TypeId::of::<T>())
+            .map(|s| s.producer.as_str())
+    }
+
+    /// `(artifact name, producer)` for every stored artifact, sorted by name.
+    pub fn entries(&self) -> Vec<(&'static str, &str)> {
+        let mut out: Vec<_> = self
+            .slots
+            .values()
+            .map(|s| (s.name, s.producer.as_str()))
+            .collect();
+        out.sort_unstable();
+        out
+    }
+
+    pub fn len(&self) -> usize {
+        self.slots.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.slots.is_empty()
+    }
+}
+
+impl std::fmt::Debug for Blackboard {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_map().entries(self.entries()).finish()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn publish_get_replace_take() {
+        let mut bb = Blackboard::new();
+        assert!(bb.get::<EntryPoint>().is_none());
+        assert_eq!(bb.publish("entry", EntryPoint(0x1000)), None);
+        assert_eq!(bb.get::<EntryPoint>(), Some(&EntryPoint(0x1000)));
+        assert_eq!(bb.producer::<EntryPoint>(), Some("entry"));
+
+        let old = bb.publish("refine", EntryPoint(0x1010));
+        assert_eq!(old, Some(EntryPoint(0x1000)));
+        assert_eq!(bb.producer::<EntryPoint>(), Some("refine"));
+
+        bb.publish("consts", ResolvedConstants::default());
+        bb.get_mut::<ResolvedConstants>()
+            .unwrap()
+            .0
+            .insert(0x2000, 42);
+        assert_eq!(
+            bb.entries(),
+            [("entry_point", "refine"), ("resolved_constants", "consts")]
+        );
+        assert_eq!(bb.take::<ResolvedConstants>().unwrap().0[&0x2000], 42);
+        assert!(!bb.contains::<ResolvedConstants>());
+        assert_eq!(bb.len(), 1);
+    }
+}
@@ -0,0 +1,176 @@
+//! Per-pass resource budgets.
+//!
+//! A `Profile` may cap each pass's wall time, instruction count, and memory
+//! so one pathological binary cannot starve a batch. Budgets degrade rather
+//! than fail: a pass that runs out keeps what it produced, is reported as
+//! partial with a `BudgetExceeded` warning, and its dependents still run.
+//!
+//! Wall time is enforced by the pipeline through a child cancellation token.
+//! Instruction and memory budgets are cooperative: passes read them from
+//! `PassContext::budget` and account through `PassContext::charge_*`.
+//!
+//! Budgets apply to passes run through `run_pipeline`; disassembly is covered
+//! by the built-in `functions` pass, which folds them into the discovery
+//! `Budgets` it hands to `analyze_functions_bytes_with_stats`.
+
+use serde::{Deserialize, Serialize};
+use std::time::Duration;
+
+/// Resource a budget applies to.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum BudgetResource {
+    Time,
+    Instructions,
+    Memory,
+}
+
+impl BudgetResource {
+    /// Lower-case name, as serialized.
+    pub fn label(self) -> &'static str {
+        match self {
+            BudgetResource::Time => "time",
+            BudgetResource::Instructions => "instructions",
+            BudgetResource::Memory => "memory",
+        }
+    }
+}
+
+impl std::fmt::Display for BudgetResource {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.write_str(self.label())
+    }
+}
+
+/// Limits for one pass. `None` leaves a resource unbounded (subject to the
+/// pass's own defaults).
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PassBudget {
+    /// Wall-time limit in milliseconds
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub time_ms: Option<u64>,
+    /// Instructions the pass may decode or execute
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max_instructions: Option<u64>,
+    /// Bytes the pass may allocate for its results and working state
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max_memory_bytes: Option<u64>,
+}
+
+impl PassBudget {
+    /// No limits.
+    pub fn unlimited() -> Self {
+        Self::default()
+    }
+
+    pub fn with_time_ms(mut self, ms: u64) -> Self {
+        self.time_ms = Some(ms);
+        self
+    }
+
+    pub fn with_instructions(mut self, n: u64) -> Self {
+        self.max_instructions = Some(n);
+        self
+    }
+
+    pub fn with_memory_bytes(mut self, n: u64) -> Self {
+        self.max_memory_bytes = Some(n);
+        self
+    }
+
+    /// Wall-time limit as a `Duration`.
+    pub fn time(&self) -> Option<Duration> {
+        self.time_ms.map(Duration::from_millis)
+    }
+
+    /// True if no resource is bounded.
+    pub fn is_unlimited(&self) -> bool {
+        self.time_ms.is_none() && self.max_instructions.is_none() && self.max_memory_bytes.is_none()
+    }
+
+    /// `self` with unset limits filled from `fallback`.
+    pub fn or(self, fallback: PassBudget) -> Self {
+        Self {
+            time_ms: self.time_ms.or(fallback.time_ms),
+            max_instructions: self.max_instructions.or(fallback.max_instructions),
+            max_memory_bytes: self.max_memory_bytes.or(fallback.max_memory_bytes),
+        }
+    }
+
+    /// Parse `time=500ms,instructions=1e6,memory=64M`-style specs, as used
+    /// on the command line. Keys: `time` (ms, or with `ms`/`s` suffix),
+    /// `instructions`, `memory` (bytes, or with `K`/`M`/`G` suffix).
+    pub fn parse(spec: &str) -> Result<Self, String> {
+        let mut out = Self::default();
+        for item in spec.split(',').map(str::trim).filter(|s| !s.is_empty()) {
+            let (key, value) = item
+                .split_once('=')
+                .ok_or_else(|| format!("expected key=value, got '{}'", item))?;
+            let value = value.trim();
+            match key.trim() {
+                "time" => {
+                    out.time_ms = Some(if let Some(s) = value.strip_suffix("ms") {
+                        parse_u64(s)?
+                    } else if let Some(s) = value.strip_suffix('s') {
+                        parse_u64(s)?.saturating_mul(1000)
+                    } else {
+                        parse_u64(value)?
+                    })
+                }
+                "instructions" => out.max_instructions = Some(parse_u64(value)?),
+                "memory" => {
+                    let (num, scale) = match value.char_indices().last() {
+                        Some((i, 'K' | 'k')) => (&value[..i], 1u64 << 10),
+                        Some((i, 'M' | 'm')) => (&value[..i], 1 << 20),
+                        Some((i, 'G' | 'g')) => (&value[..i], 1 << 30),
+                        _ => (value, 1),
+                    };
+                    out.max_memory_bytes = Some(parse_u64(num)?.saturating_mul(scale));
+                }
+                other => return Err(format!("unknown budget '{}'", other)),
+            }
+        }
+        Ok(out)
+    }
+}
+
+fn parse_u64(s: &str) -> Result<u64, String> {
+    let s = s.trim().replace('_', "");
+    s.parse::<u64>()
+        .or_else(|_| {
+            // Accept 1e6-style counts.
+            s.parse::<f64>()
+                .ok()
+                .filter(|f| f.is_finite() && *f >= 0.0 && f.fract() == 0.0)
+                .map(|f| f as u64)
+                .ok_or(())
+        })
+        .map_err(|_| format!("invalid number '{}'", s))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn parse_specs() {
+        let b = PassBudget::parse("time=2s, instructions=1e6,memory=64M").unwrap();
+        assert_eq!(b.time_ms, Some(2000));
+        assert_eq!(b.max_instructions, Some(1_000_000));
+        assert_eq!(b.max_memory_bytes, Some(64 << 20));
+        assert_eq!(PassBudget::parse("time=250").unwrap().time_ms, Some(250));
+        assert!(PassBudget::parse("").unwrap().is_unlimited());
+        assert!(PassBudget::parse("cpu=1").is_err());
+        assert!(PassBudget::parse("memory=lots").is_err());
+    }
+
+    #[test]
+    fn or_fills_unset_limits() {
+        let pass = PassBudget::default().with_time_ms(10);
+        let all = PassBudget::default().with_time_ms(99).with_memory_bytes(1);
+        let b = pass.or(all);
+        assert_eq!(b.time_ms, Some(10));
+        assert_eq!(b.max_memory_bytes, Some(1));
+        assert_eq!(b.max_instructions, None);
+    }
+}
@@ -0,0 +1,940 @@
+//! Built-in passes wrapping the existing analyses.
+
+use super::blackboard::{DeadFunctions, EmbeddedConfigs, EntryPoint, Functions, RecoveredStructs};
+use super::budget::BudgetResource;
+use super::pass::{AnalysisPass, PassContext, PassError};
+use super::{PassRegistry, PipelineError, Plugin};
+use crate::analysis::cfg::{analyze_functions_bytes_with_stats, Budgets};
+use crate::analysis::config_carver::ConfigCarver;
+use crate::analysis::dead_code::{
+    data_code_pointers, find_dead_functions, llir_code_refs, loader_roots, Liveness,
+};
+use crate::analysis::eh::{recover_eh_info, HandlerKind};
+use crate::analysis::elf_plt::elf_plt_map;
+use crate::analysis::goitab::recover_go_itabs;
+use crate::analysis::macho_stubs::macho_stubs_map;
+use crate::analysis::pe_iat::pe_iat_map;
+use crate::analysis::rtti::{recover_class_hierarchy, ClassHierarchy};
+use crate::core::binary::{Arch, Format};
+use crate::core::function::Function;
+use crate::core::triage::Finding;
+use crate::debug::dwarf::{extract_dwarf_types, DwarfTypeKind};
+use crate::error::ErrorKind;
+use crate::ir::call_args::CallConv;
+use crate::ir::lift_function::{lift_function_with_map, supports_arch};
+use crate::ir::struct_recover::{allocator_for, recover_structs, StructHints};
+use std::collections::{HashMap, HashSet};
+
+/// Registers the passes shipped with glaurung.
+pub struct BuiltinPlugin;
+
+impl Plugin for BuiltinPlugin {
+    fn name(&self) -> &str {
+        "builtin"
+    }
+
+    fn register(&self, registry: &mut PassRegistry) -> Result<(), PipelineError> {
+        registry.register(Box::new(LayoutPass))?;
+        registry.register(Box::new(EntryPass))?;
+        registry.register(Box::new(ImportsPass))?;
+        registry.register(Box::new(SymbolsPass))?;
+        registry.register(Box::new(FunctionsPass::default()))?;
+        registry.register(Box::new(RttiPass))?;
+        registry.register(Box::new(ExceptionsPass))?;
+        registry.register(Box::new(GoItabsPass))?;
+        registry.register(Box::new(ConfigsPass::default()))?;
+        registry.register(Box::new(StructsPass))?;
+        registry.register(Box::new(DeadFunctionsPass))?;
+        Ok(())
+    }
+}
+
+/// Records format, architecture, and section/segment counts.
+pub struct LayoutPass;
+
+impl AnalysisPass for LayoutPass {
+    fn name(&self) -> &str {
+        "layout"
+    }
+
+    fn description(&self) -> &str {
+        "format, architecture, sections, segments, and W^X violations"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        let img = ctx.image;
+        ctx.note(
+            "layout",
+            format!(
+                "format={} arch={} bits={} sections={} segments={}",
+                img.format(),
+                img.arch(),
+                img.bits(),
+                img.sections().len(),
+                img.segments().len()
+            ),
+        );
+        // W^X violations: loaders map these pages writable and executable,
+        // which compilers never emit for ordinary code.
+        for seg in img.writable_executable_segments() {
+            let mut prov = ctx.provenance(1.0).with_rule("memory:wx", None);
+            if seg.file_size > 0 {
+                prov = prov.with_evidence(seg.file_offset, seg.file_size, Some("segment"));
+            }
+            ctx.push_finding(Finding::new(
+                "wx_mapping",
+                format!(
+                    "writable+executable segment {} at {:#x} ({} bytes, {})",
+                    seg.name.as_deref().unwrap_or("<unnamed>"),
+                    seg.va,
+                    seg.mem_size,
+                    seg.perms
+                ),
+                prov,
+            ));
+        }
+        Ok(())
+    }
+}
+
+/// Resolves the entry point and the file bytes backing it.
+pub struct EntryPass;
+
+impl AnalysisPass for EntryPass {
+    fn name(&self) -> &str {
+        "entry"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["layout"]
+    }
+
+    fn description(&self) -> &str {
+        "entry point address"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        let Some(entry) = ctx.image.entry() else {
+            return Ok(());
+        };
+        ctx.publish(EntryPoint(entry));
+        let mut prov = ctx.provenance(1.0).with_rule("header:entry", None);
+        if let Ok(off) = ctx.image.address_map().va_to_file_offset(entry) {
+            prov = prov.with_evidence(off, 1, Some("entry point"));
+        }
+        ctx.push_finding(Finding::new("entry", format!("entry={:#x}", entry), prov));
+        Ok(())
+    }
+}
+
+/// Summarises imported names.
+pub struct ImportsPass;
+
+impl AnalysisPass for ImportsPass {
+    fn name(&self) -> &str {
+        "imports"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["layout"]
+    }
+
+    fn description(&self) -> &str {
+        "imported libraries and names"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        let imports = ctx.image.imports();
+        let mut libs: Vec<&str> = imports
+            .iter()
+            .filter_map(|i| i.library.as_deref())
+            .collect();
+        libs.sort_unstable();
+        libs.dedup();
+        ctx.note(
+            "imports",
+            format!("imports={} libraries={}", imports.len(), libs.len()),
+        );
+        Ok(())
+    }
+}
+
+/// Summarises defined symbols.
+pub struct SymbolsPass;
+
+impl AnalysisPass for SymbolsPass {
+    fn name(&self) -> &str {
+        "symbols"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["layout"]
+    }
+
+    fn description(&self) -> &str {
+        "static and dynamic symbol tables"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        let syms = ctx.image.symbols();
+        let dynamic = syms.iter().filter(|s| s.dynamic).count();
+        ctx.note(
+            "symbols",
+            format!("symbols={} dynamic={}", syms.len(), dynamic),
+        );
+        Ok(())
+    }
+}
+
+/// Rough heap footprint of a discovered function, for memory budgets.
+fn approx_size(f: &Function) -> u64 {
+    use crate::core::address::Address;
+    use std::mem::size_of;
+    (size_of::<Function>()
+        + f.basic_blocks.len() * size_of::<crate::core::basic_block::BasicBlock>()
+        + f.edges.len() * size_of::<(Address, Address)>()
+        + (f.callers.len() + f.callees.len()) * size_of::<Address>()
+        + f.name.len()) as u64
+}
+
+/// Bounded function discovery and call graph construction (`analysis::cfg`).
+///
+/// The pass's instruction budget tightens `Budgets::max_instructions`; a
+/// memory budget truncates the function list once it is full.
+#[derive(Default)]
+pub struct FunctionsPass {
+    pub budgets: Budgets,
+}
+
+impl AnalysisPass for FunctionsPass {
+    fn name(&self) -> &str {
+        "functions"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["entry", "symbols"]
+    }
+
+    fn description(&self) -> &str {
+        "function discovery and call graph"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        if ctx.image.arch() == Arch::Unknown {
+            return Err(PassError::unsupported(format!(
+                "no disassembler for {} images",
+                ctx.image.format()
+            )));
+        }
+        ctx.check_cancelled()?;
+        // Discovery polls its own clock; never let it outlive the run.
+        let mut budgets = self.budgets;
+        if let Some(left) = ctx.cancel.remaining() {
+            budgets.timeout_ms = budgets.timeout_ms.min(left.as_millis() as u64);
+        }
+        if let Some(max) = ctx.budget.max_instructions {
+            budgets.max_instructions = budgets
+                .max_instructions
+                .min(usize::try_from(max).unwrap_or(usize::MAX));
+        }
+        let (mut funcs, cg, stats) = analyze_functions_bytes_with_stats(ctx.image.data(), &budgets);
+        for (hit, budget) in [
+            (stats.hit_function_limit, "function"),
+            (stats.hit_block_limit, "block"),
+        ] {
+            if hit {
+                ctx.warn(
+                    ErrorKind::BudgetExceeded,
+                    format!("{} budget reached; function list is incomplete", budget),
+                );
+            }
+        }
+        for (hit, resource) in [
+            (stats.hit_instruction_limit, BudgetResource::Instructions),
+            (stats.hit_timeout, BudgetResource::Time),
+        ] {
+            if hit {
+                ctx.budget_exceeded(
+                    resource,
+                    format!("{} budget reached; function list is incomplete", resource),
+                );
+            }
+        }
+        if let Some(keep) = funcs
+            .iter()
+            .position(|f| !ctx.charge_memory(approx_size(f)))
+        {
+            funcs.truncate(keep);
+        }
+        ctx.note(
+            "functions",
+            format!("functions={} call_edges={}", funcs.len(), cg.edges.len()),
+        );
+        ctx.publish(Functions(funcs));
+        ctx.publish(cg);
+        Ok(())
+    }
+}
+
+/// C++ classes, bases, and vtables from Itanium or MSVC RTTI
+/// (`analysis::rtti`).
+///
+/// Discovered functions that are virtual methods but have no symbol of
+/// their own (`sub_*`) are renamed after their class.
+pub struct RttiPass;
+
+impl AnalysisPass for RttiPass {
+    fn name(&self) -> &str {
+        "rtti"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["functions"]
+    }
+
+    fn description(&self) -> &str {
+        "C++ class hierarchies and vtables from RTTI"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        ctx.check_cancelled()?;
+        let Some(hierarchy) = recover_class_hierarchy(ctx.image.data()) else {
+            return Ok(());
+        };
+        let mut renamed = 0;
+        if let Some(Functions(funcs)) = ctx.artifacts.get_mut::<Functions>() {
+            let names = hierarchy.method_names();
+            for f in funcs.iter_mut().filter(|f| f.name.starts_with("sub_")) {
+                if let Some(name) = names.get(&f.entry_point.value) {
+                    f.name = name.to_string();
+                    renamed += 1;
+                }
+            }
+        }
+        let map = ctx.image.address_map();
+        for class in &hierarchy.classes {
+            let mut prov = ctx
+                .provenance(1.0)
+                .with_rule(format!("rtti:{}", hierarchy.abi), None);
+            if let Ok(off) = map.va_to_file_offset(class.typeinfo) {
+                prov = prov.with_evidence(off, u64::from(ctx.image.bits() / 8), Some("typeinfo"));
+            }
+            ctx.push_finding(Finding::new(
+                "cpp_class",
+                format!(
+                    "class {} ({} vtables, {} virtual methods)",
+                    class.declaration(),
+                    class.vtables.len(),
+                    class.methods.len()
+                ),
+                prov,
+            ));
+        }
+        ctx.note(
+            "rtti",
+            format!(
+                "abi={} classes={} vtables={} methods={} renamed={}",
+                hierarchy.abi,
+                hierarchy.classes.len(),
+                hierarchy.vtable_count(),
+                hierarchy.method_count(),
+                renamed
+            ),
+        );
+        ctx.publish(hierarchy);
+        Ok(())
+    }
+}
+
+/// Try regions, landing pads, and handlers from LSDA, SEH scope, and MSVC
+/// FuncInfo tables (`analysis::eh`).
+///
+/// Function discovery already follows landing pads and seeds handler
+/// funclets; this pass publishes the tables themselves and reports every
+/// function that catches something.
+pub struct ExceptionsPass;
+
+impl AnalysisPass for ExceptionsPass {
+    fn name(&self) -> &str {
+        "exceptions"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["functions"]
+    }
+
+    fn description(&self) -> &str {
+        "try regions and handlers from exception tables"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        ctx.check_cancelled()?;
+        let Some(info) = recover_eh_info(ctx.image.data()) else {
+            return Ok(());
+        };
+        let names: std::collections::HashMap<u64, String> = ctx
+            .artifacts
+            .get::<Functions>()
+            .map(|Functions(funcs)| {
+                funcs
+                    .iter()
+                    .map(|f| (f.entry_point.value, f.name.clone()))
+                    .collect()
+            })
+            .unwrap_or_default();
+        let map = ctx.image.address_map();
+        for f in &info.functions {
+            let mut catches: Vec<String> = f
+                .regions
+                .iter()
+                .flat_map(|r| &r.handlers)
+                .filter(|h| !matches!(h.kind, HandlerKind::Cleanup | HandlerKind::ExceptionSpec))
+                .map(|h| h.describe())
+                .collect();
+            catches.dedup();
+            if catches.is_empty() {
+                continue;
+            }
+            let mut prov = ctx
+                .provenance(1.0)
+                .with_rule(format!("eh:{}", f.scheme), None);
+            if let Ok(off) = map.va_to_file_offset(f.table) {
+                prov = prov.with_evidence(off, 4, Some("eh_table"));
+            }
+            let name = names
+                .get(&f.start)
+                .cloned()
+                .unwrap_or_else(|| format!("sub_{:x}", f.start));
+            ctx.push_finding(Finding::new(
+                "exception_handler",
+                format!(
+                    "{} ({} try regions): {}",
+                    name,
+                    f.regions.len(),
+                    catches.join(", ")
+                ),
+                prov,
+            ));
+        }
+        ctx.note(
+            "exceptions",
+            format!(
+                "functions={} regions={} landing_pads={} handlers={}",
+                info.functions.len(),
+                info.region_count(),
+                info.landing_pads().len(),
+                info.handler_count()
+            ),
+        );
+        ctx.publish(info);
+        Ok(())
+    }
+}
+
+/// Go itabs: which concrete types implement which interfaces, and the
+/// method each interface call slot reaches.
+pub struct GoItabsPass;
+
+impl AnalysisPass for GoItabsPass {
+    fn name(&self) -> &str {
+        "go_itabs"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["functions"]
+    }
+
+    fn description(&self) -> &str {
+        "Go interface tables and their method implementations"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        ctx.check_cancelled()?;
+        let Some(itabs) = recover_go_itabs(ctx.image.data()) else {
+            return Ok(());
+        };
+        let names: std::collections::HashMap<u64, String> = ctx
+            .artifacts
+            .get::<Functions>()
+            .map(|Functions(funcs)| {
+                funcs
+                    .iter()
+                    .map(|f| (f.entry_point.value, f.name.clone()))
+                    .collect()
+            })
+            .unwrap_or_default();
+        let map = ctx.image.address_map();
+        for itab in &itabs.itabs {
+            let methods: Vec<String> = itab
+                .methods
+                .iter()
+                .map(|m| match names.get(&m.target) {
+                    Some(name) => format!("{}={}", m.name, name),
+                    None => format!("{}=sub_{:x}", m.name, m.target),
+                })
+                .collect();
+            let mut prov = ctx.provenance(1.0).with_rule("go:itab", None);
+            if let Ok(off) = map.va_to_file_offset(itab.va) {
+                let len = itabs.fun_offset() + (itab.methods.len() * itabs.ptr_size) as u64;
+                prov = prov.with_evidence(off, len, Some("itab"));
+            }
+            ctx.push_finding(Finding::new(
+                "go_itab",
+                format!(
+                    "{} implements {}: {}",
+                    itab.concrete,
+                    itab.interface,
+                    methods.join(", ")
+                ),
+                prov,
+            ));
+        }
+        ctx.note(
+            "go_itabs",
+            format!(
+                "itabs={} methods={}",
+                itabs.itabs.len(),
+                itabs.method_targets().len()
+            ),
+        );
+        ctx.publish(itabs);
+        Ok(())
+    }
+}
+
+/// Embedded configuration blobs (`analysis::config_carver`).
+///
+/// Family-specific extractors are registered on `carver` before the pass
+/// is added to a registry.
+#[derive(Default)]
+pub struct ConfigsPass {
+    pub carver: ConfigCarver,
+}
+
+impl AnalysisPass for ConfigsPass {
+    fn name(&self) -> &str {
+        "configs"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["layout"]
+    }
+
+    fn description(&self) -> &str {
+        "embedded configuration blobs (length-prefixed, XOR/RC4 near markers)"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        ctx.check_cancelled()?;
+        let configs = self.carver.carve(ctx.image.data());
+        for cfg in &configs {
+            let fields: Vec<String> = cfg
+                .entries
+                .iter()
+                .map(|e| format!("{}={}", e.key, e.value))
+                .collect();
+            let rule = format!("config:{}", cfg.extractor);
+            let prov = ctx
+                .provenance(if cfg.family.is_some() { 1.0 } else { 0.7 })
+                .with_rule(&rule, None)
+                .with_evidence(cfg.offset, cfg.length, Some(cfg.encoding.as_str()));
+            ctx.push_finding(Finding::new(
+                "embedded_config",
+                format!(
+                    "{} config at {:#x} ({}): {}",
+                    cfg.family.as_deref().unwrap_or(&cfg.extractor),
+                    cfg.offset,
+                    cfg.encoding,
+                    fields.join(", ")
+                ),
+                prov,
+            ));
+        }
+        ctx.note("configs", format!("configs={}", configs.len()));
+        ctx.publish(EmbeddedConfigs(configs));
+        Ok(())
+    }
+}
+
+/// Struct layouts from field-offset accesses in the lifted functions
+/// (`ir::struct_recover`), sized by allocator calls, named by RTTI vtable
+/// stores, and replaced by DWARF layouts where one matches.
+///
+/// Lifted instructions count against the instruction budget; functions
+/// past it are left out of the recovery.
+pub struct StructsPass;
+
+impl AnalysisPass for StructsPass {
+    fn name(&self) -> &str {
+        "structs"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["functions", "rtti"]
+    }
+
+    fn description(&self) -> &str {
+        "struct layouts from field accesses, allocation sizes, RTTI, and DWARF"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        let image = ctx.image;
+        let arch = image.arch();
+        if !supports_arch(arch) {
+            return Err(PassError::unsupported(format!(
+                "no LLIR lifter for {} images",
+                arch
+            )));
+        }
+        ctx.check_cancelled()?;
+        let data = image.data();
+        let conv = match (arch, image.format()) {
+            (Arch::AArch64, _) => CallConv::Aarch64,
+            (Arch::ARM, _) => CallConv::Arm,
+            (Arch::X86_64, Format::PE) => CallConv::Win64,
+            _ => CallConv::SysVAmd64,
+        };
+
+        let mut hints = StructHints::default();
+        let stubs = elf_plt_map(data)
+            .into_iter()
+            .chain(pe_iat_map(data))
+            .chain(macho_stubs_map(data));
+        let named = ctx
+            .functions()
+            .iter()
+            .map(|f| (f.entry_point.value, f.name.clone()));
+        for (va, name) in named.chain(stubs) {
+            if let Some(alloc) = allocator_for(&name) {
+                hints.allocators.insert(va, alloc);
+            }
+            hints.names.entry(va).or_insert(name);
+        }
+        if let Some(h) = ctx.artifact::<ClassHierarchy>() {
+            let depths = h.depths();
+            for class in &h.classes {
+                let depth = depths[class.name.as_str()];
+                for vt in &class.vtables {
+                    hints.vtables.insert(vt.va, (class.name.clone(), depth));
+                }
+            }
+        }
+        hints.dwarf = extract_dwarf_types(data)
+            .into_iter()
+            .filter(|t| t.kind == DwarfTypeKind::Struct)
+            .collect();
+
+        let map = image.address_map();
+        let mut lifted = Vec::new();
+        for i in 0..ctx.functions().len() {
+            ctx.check_cancelled()?;
+            let Some(lf) = lift_function_with_map(data, &ctx.functions()[i], arch, &map) else {
+                continue;
+            };
+            let n: usize = lf.blocks.iter().map(|b| b.instrs.len()).sum();
+            lifted.push(lf);
+            if !ctx.charge_instructions(n as u64) {
+                break;
+            }
+        }
+
+        let structs = recover_structs(&lifted, arch, conv, &hints);
+        for s in &structs {
+            let rule = if s.dwarf {
+                "struct:dwarf"
+            } else if s.class.is_some() {
+                "struct:rtti"
+            } else {
+                "struct:access"
+            };
+            let mut prov = ctx.provenance(s.confidence).with_rule(rule, None);
+            if let Some(off) = s
+                .functions
+                .first()
+                .and_then(|va| map.va_to_file_offset(*va).ok())
+            {
+                prov = prov.with_evidence(off, 1, Some("first accessing function"));
+            }
+            ctx.push_finding(Finding::new("recovered_struct", s.declaration(), prov));
+        }
+        ctx.note(
+            "structs",
+            format!(
+                "structs={} lifted={} dwarf_matches={}",
+                structs.len(),
+                lifted.len(),
+                structs.iter().filter(|s| s.dwarf).count()
+            ),
+        );
+        ctx.publish(RecoveredStructs(structs));
+        Ok(())
+    }
+}
+
+/// Functions nothing reaches (`analysis::dead_code`): no loader root
+/// (entry, exports, init/fini arrays, TLS callbacks), no pointer in data,
+/// and no call or address reference from a live function.
+///
+/// Call graph edges are always used. Where an LLIR lifter exists the
+/// functions are also lifted to find taken addresses; lifted instructions
+/// count against the instruction budget, and a pass that runs out reports
+/// more dead functions than there are.
+pub struct DeadFunctionsPass;
+
+impl AnalysisPass for DeadFunctionsPass {
+    fn name(&self) -> &str {
+        "dead_functions"
+    }
+
+    fn dependencies(&self) -> &[&str] {
+        &["functions"]
+    }
+
+    fn description(&self) -> &str {
+        "unreferenced and unreachable functions"
+    }
+
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+        ctx.check_cancelled()?;
+        let image = ctx.image;
+        let data = image.data();
+        let entries: HashSet<u64> = ctx
+            .functions()
+            .iter()
+            .map(|f| f.entry_point.value)
+            .collect();
+        let by_name: HashMap<&str, u64> = ctx
+            .functions()
+            .iter()
+            .map(|f| (f.name.as_str(), f.entry_point.value))
+            .collect();
+        // The call graph keeps the names discovery gave; `rtti` may have
+        // renamed `sub_*` functions since.
+        let resolve = |name: &str| {
+            by_name.get(name).copied().or_else(|| {
+                let va = u64::from_str_radix(name.strip_prefix("sub_")?, 16).ok()?;
+                entries.contains(&va).then_some(va)
+            })
+        };
+        let mut edges: Vec<(u64, u64)> = ctx
+            .call_graph()
+            .map(|cg| {
+                cg.edges
+                    .iter()
+                    .filter_map(|e| Some((resolve(&e.caller)?, resolve(&e.callee)?)))
+                    .collect()
+            })
+            .unwrap_or_default();
+
+        let arch = image.arch();
+        let mut lifted = 0;
+        if supports_arch(arch) {
+            let map = image.address_map();
+            let code: Vec<(u64, u64)> = image
+                .code_sections()
+                .iter()
+                .map(|s| (s.va, s.va + s.size))
+                .collect();
+            for i in 0..ctx.functions().len() {
+                ctx.check_cancelled()?;
+                let Some(lf) = lift_function_with_map(data, &ctx.functions()[i], arch, &map) else {
+                    continue;
+                };
+                lifted += 1;
+                edges.extend(
+                    llir_code_refs(&lf, &code, &entries)
+                        .into_iter()
+                        .map(|to| (lf.entry_va, to)),
+                );
+                let n: usize = lf.blocks.iter().map(|b| b.instrs.len()).sum();
+                if !ctx.charge_instructions(n as u64) {
+                    break;
+                }
+            }
+        }
+
+        let roots = loader_roots(data);
+        let mut live = data_code_pointers(data, &entries);
+        let address_taken = live.len();
+        live.extend(roots.iter().map(|(va, _)| *va));
+        live.extend(ctx.entry());
+        let dead = find_dead_functions(ctx.functions(), &edges, &live);
+
+        let map = image.address_map();
+        for d in &dead {
+            let (confidence, claim) = match d.status {
+                Liveness::Unreferenced => (
+                    0.7,
+                    format!(
+                        "{} at {:#x} ({} bytes) is never referenced",
+                        d.name, d.va, d.size
+                    ),
+                ),
+                Liveness::Unreachable => {
+                    let from: Vec<String> =
+                        d.referrers.iter().map(|va| format!("{:#x}", va)).collect();
+                    (
+                        0.6,
+                        format!(
+                            "{} at {:#x} ({} bytes) is only referenced from dead code ({})",
+                            d.name,
+                            d.va,
+                            d.size,
+                            from.join(", ")
+                        ),
+                    )
+                }
+            };
+            let rule = format!("dead:{}", d.status.as_str());
+            let mut prov = ctx.provenance(confidence).with_rule(&rule, None);
+            if let Ok(off) = map.va_to_file_offset(d.va) {
+                prov = prov.with_evidence(off, d.size.max(1), Some("function body"));
+            }
+            ctx.push_finding(Finding::new("dead_function", claim, prov));
+        }
+        ctx.note(
+            "dead_functions",
+            format!(
+                "functions={} roots={} address_taken={} lifted={} unreferenced={} unreachable={}",
+                entries.len(),
+                roots.iter().filter(|(va, _)| entries.contains(va)).count(),
+                address_taken,
+                lifted,
+                dead.iter()
+                    .filter(|d| d.status == Liveness::Unreferenced)
+                    .count(),
+                dead.iter()
+                    .filter(|d| d.status == Liveness::Unreachable)
+                    .count()
+            ),
+        );
+        ctx.publish(DeadFunctions(dead));
+        Ok(())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::super::{run_pipeline, PassStatus, Profile};
+    use super::*;
+    use crate::formats::object_image::ObjectImage;
+
+    /// Fixture bytes, or `None` when the sample is missing or is a
+    /// git-lfs pointer that was never fetched.
+    fn read_fixture(path: &str) -> Option<Vec<u8>> {
+        let data = std::fs::read(path).ok()?;
+        (!data.starts_with(b"version https://git-lfs.github.com/spec/")).then_some(data)
+    }
+
+    #[test]
+    fn builtin_schedule_is_stable() {
+        let reg = PassRegistry::with_builtin();
+        let s = reg.schedule(&Profile::default()).unwrap();
+        assert_eq!(
+            s.order,
+            [
+                "layout",
+                "entry",
+                "imports",
+                "symbols",
+                "functions",
+                "rtti",
+                "exceptions",
+                "go_itabs",
+                "configs",
+                "structs",
+                "dead_functions"
+            ]
+        );
+        let quick = reg.schedule(&Profile::named("quick").unwrap()).unwrap();
+        assert!(!quick.order.contains(&"functions".to_string()));
+        assert!(!quick.order.contains(&"rtti".to_string()));
+        assert!(!quick.order.contains(&"exceptions".to_string()));
+        assert!(!quick.order.contains(&"go_itabs".to_string()));
+        assert!(!quick.order.contains(&"configs".to_string()));
+        assert!(!quick.order.contains(&"structs".to_string()));
+        assert!(!quick.order.contains(&"dead_functions".to_string()));
+    }
+
+    struct RwxImage;
+
+    impl crate::core::image::BinaryImage for RwxImage {
+        fn data(&self) -> &[u8] {
+            &[0x90; 0x20]
+        }
+        fn format(&self) -> crate::core::binary::Format {
+            crate::core::binary::Format::Raw
+        }
+        fn arch(&self) -> Arch {
+            Arch::X86_64
+        }
+        fn bits(&self) -> u8 {
+            64
+        }
+        fn endianness(&self) -> crate::core::binary::Endianness {
+            crate::core::binary::Endianness::Little
+        }
+        fn entry(&self) -> Option<u64> {
+            Some(0x1000)
+        }
+        fn sections(&self) -> Vec<crate::core::image::ImageSection> {
+            Vec::new()
+        }
+        fn segments(&self) -> Vec<crate::core::image::ImageSegment> {
+            vec![crate::core::image::ImageSegment {
+                name: Some("shellcode".into()),
+                va: 0x1000,
+                mem_size: 0x20,
+                file_offset: 0,
+                file_size: 0x20,
+                perms: crate::core::segment::Perms::new(true, true, true),
+            }]
+        }
+        fn symbols(&self) -> Vec<crate::core::image::ImageSymbol> {
+            Vec::new()
+        }
+        fn imports(&self) -> Vec<crate::core::image::ImageImport> {
+            Vec::new()
+        }
+    }
+
+    #[test]
+    fn layout_flags_writable_executable_segments() {
+        let reg = PassRegistry::with_builtin();
+        let (report, ctx) =
+            run_pipeline(&reg, &Profile::only("layout", ["layout"]), &RwxImage).unwrap();
+        let wx: Vec<_> = report
+            .findings
+            .iter()
+            .filter(|f| f.category == "wx_mapping")
+            .collect();
+        assert_eq!(wx.len(), 1);
+        assert!(wx[0].claim.contains("shellcode"));
+        assert!(ctx.is_executable(0x1010));
+        assert!(!ctx.is_executable(0x2000));
+    }
+
+    #[test]
+    fn builtin_pipeline_on_elf() {
+        let path = "samples/binaries/platforms/linux/amd64/export/rust/hello-rust-release";
+        let Some(data) = read_fixture(path) else {
+            return;
+        };
+        let img = ObjectImage::parse(&data).expect("parse ELF");
+        let reg = PassRegistry::with_builtin();
+        let (report, ctx) = run_pipeline(&reg, &Profile::default(), &img).unwrap();
+        assert!(report
+            .outcomes
+            .iter()
+            .all(|o| matches!(o.status, PassStatus::Completed | PassStatus::Partial)));
+        assert!(ctx.entry().is_some());
+        assert!(!ctx.functions().is_empty());
+        assert!(ctx.call_graph().is_some());
+        assert_eq!(ctx.artifacts.producer::<Functions>(), Some("functions"));
+        assert_eq!(
+            report.outcome("functions").unwrap().published,
+            ["functions", "call_graph"]
+        );
+        assert!(report.findings.iter().any(|f| f.category == "entry"));
+    }
+}
@@ -0,0 +1,712 @@
+//! Pass-based analysis pipeline.
+//!
+//! Analyses are registered as named passes that declare the passes they
+//! depend on. A `Profile` selects which passes run; the registry resolves the
+//! selection into a dependency-ordered schedule and `run_pipeline` executes
+//! it against a `BinaryImage`, recording an outcome per pass. Third-party
+//! passes slot into the same graph by implementing `AnalysisPass` and
+//! registering through a `Plugin`. Profiles can also give each pass a
+//! time, instruction, and memory budget (see `budget`). Passes exchange
+//! intermediate results through a typed artifact store (see `blackboard`).
+//!
+//! Only work scheduled here is governed by profiles. Triage and the direct
+//! `analysis::cfg::analyze_functions_*` entry points predate the registry and
+//! keep their own `Budgets`; unpacking and emulation are not passes yet.
+
+pub mod blackboard;
+pub mod budget;
+pub mod builtin;
+pub mod pass;
+pub mod profile;
+
+pub use blackboard::{Artifact, Blackboard};
+pub use budget::{BudgetResource, PassBudget};
+pub use pass::{AnalysisPass, PassContext, PassError};
+pub use profile::Profile;
+
+use crate::cancel::{CancelReason, CancellationToken};
+use crate::core::image::BinaryImage;
+use crate::core::triage::Finding;
+use crate::error::{ErrorKind, Warning};
+use crate::events::{self, AnalysisEvent};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeSet, HashMap};
+use std::time::Instant;
+
+/// Errors raised while registering or scheduling passes.
+#[derive(Debug, Clone, thiserror::Error, PartialEq, Eq)]
+pub enum PipelineError {
+    #[error("pass '{0}' is already registered")]
+    DuplicatePass(String),
+    #[error("unknown pass '{0}'")]
+    UnknownPass(String),
+    #[error("pass '{pass}' depends on unregistered pass '{dependency}'")]
+    MissingDependency { pass: String, dependency: String },
+    #[error("dependency cycle among passes: {}", .0.join(", "))]
+    Cycle(Vec<String>),
+}
+
+/// A source of passes (built-in set or third-party crate).
+pub trait Plugin {
+    /// Plugin name, used in diagnostics.
+    fn name(&self) -> &str;
+
+    /// Register this plugin's passes.
+    fn register(&self, registry: &mut PassRegistry) -> Result<(), PipelineError>;
+}
+
+/// Registered passes, in registration order.
+#[derive(Default)]
+pub struct PassRegistry {
+    passes: Vec<Box<dyn AnalysisPass>>,
+}
+
+/// A resolved schedule: passes to run in order, plus passes dropped because
+/// one of their dependencies was disabled.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Schedule {
+    /// Pass names in execution order
+    pub order: Vec<String>,
+    /// `(pass, reason)` for passes removed from the selection
+    pub dropped: Vec<(String, String)>,
+}
+
+impl PassRegistry {
+    /// Empty registry.
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Registry pre-populated with the built-in passes.
+    pub fn with_builtin() -> Self {
+        let mut reg = Self::new();
+        reg.install(&builtin::BuiltinPlugin)
+            .expect("built-in passes register cleanly");
+        reg
+    }
+
+    /// Register a pass. Names must be unique.
+    pub fn register(&mut self, pass: Box<dyn AnalysisPass>) -> Result<(), PipelineError> {
+        if self.get(pass.name()).is_some() {
+            return Err(PipelineError::DuplicatePass(pass.name().to_string()));
+        }
+        self.passes.push(pass);
+        Ok(())
+    }
+
+    /// Register every pass provided by `plugin`.
+    pub fn install(&mut self, plugin: &dyn Plugin) -> Result<(), PipelineError> {
+        tracing::debug!(plugin = plugin.name(), "installing analysis plugin");
+        plugin.register(self)
+    }
+
+    /// Look up a pass by name.
+    pub fn get(&self, name: &str) -> Option<&dyn AnalysisPass> {
+        self.passes
+            .iter()
+            .find(|p| p.name() == name)
+            .map(|p| p.as_ref())
+    }
+
+    /// Registered pass names in registration order.
+    pub fn names(&self) -> Vec<&str> {
+        self.passes.iter().map(|p| p.name()).collect()
+    }
+
+    fn index_of(&self, name: &str) -> Option<usize> {
+        self.passes.iter().position(|p| p.name() == name)
+    }
+
+    /// Resolve `profile` into a dependency-ordered schedule.
+    ///
+    /// Explicitly selected passes pull in their dependencies. Disabling a
+    /// pass also drops every pass that (transitively) depends on it. Ties are
+    /// broken by registration order so schedules are deterministic.
+    pub fn schedule(&self, profile: &Profile) -> Result<Schedule, PipelineError> {
+        for name in profile.enabled.iter().flatten().chain(&profile.disabled) {
+            if self.index_of(name).is_none() {
+                return Err(PipelineError::UnknownPass(name.clone()));
+            }
+        }
+        let mut deps: Vec<Vec<usize>> = Vec::with_capacity(self.passes.len());
+        for p in &self.passes {
+            let mut v = Vec::new();
+            for d in p.dependencies() {
+                let idx = self
+                    .index_of(d)
+                    .ok_or_else(|| PipelineError::MissingDependency {
+                        pass: p.name().to_string(),
+                        dependency: d.to_string(),
+                    })?;
+                v.push(idx);
+            }
+            deps.push(v);
+        }
+
+        // Selection: explicit set plus dependency closure, or everything.
+        let mut selected: BTreeSet<usize> = match &profile.enabled {
+            Some(names) => {
+                let mut set = BTreeSet::new();
+                let mut stack: Vec<usize> = names.iter().filter_map(|n| self.index_of(n)).collect();
+                while let Some(i) = stack.pop() {
+                    if set.insert(i) {
+                        stack.extend(deps[i].iter().copied());
+                    }
+                }
+                set
+            }
+            None => (0..self.passes.len()).collect(),
+        };
+
+        // Disabled passes and their dependents drop out.
+        let mut dropped = Vec::new();
+        for name in &profile.disabled {
+            if let Some(i) = self.index_of(name) {
+                if selected.remove(&i) {
+                    dropped.push((name.clone(), "disabled by profile".to_string()));
+                }
+            }
+        }
+        loop {
+            let victim = selected.iter().copied().find_map(|i| {
+                deps[i]
+                    .iter()
+                    .find(|d| !selected.contains(d))
+                    .map(|d| (i, *d))
+            });
+            let Some((i, d)) = victim else { break };
+            selected.remove(&i);
+            dropped.push((
+                self.passes[i].name().to_string(),
+                format!("dependency '{}' not scheduled", self.passes[d].name()),
+            ));
+        }
+
+        // Kahn's algorithm, lowest registration index first.
+        let mut indegree: HashMap<usize, usize> =
+            selected.iter().map(|&i| (i, deps[i].len())).collect();
+        let mut ready: BTreeSet<usize> = indegree
+            .iter()
+            .filter(|(_, n)| **n == 0)
+            .map(|(i, _)| *i)
+            .collect();
+        let mut order = Vec::with_capacity(selected.len());
+        while let Some(i) = ready.pop_first() {
+            order.push(i);
+            for &j in &selected {
+                if deps[j].contains(&i) {
+                    let n = indegree.get_mut(&j).expect("selected pass has indegree");
+                    *n -= deps[j].iter().filter(|d| **d == i).count();
+                    if *n == 0 {
+                        ready.insert(j);
+                    }
+                }
+            }
+        }
+        if order.len() != selected.len() {
+            let stuck = selected
+                .iter()
+                .filter(|i| !order.contains(i))
+                .map(|&i| self.passes[i].name().to_string())
+                .collect();
+            return Err(PipelineError::Cycle(stuck));
+        }
+        Ok(Schedule {
+            order: order
+                .into_iter()
+                .map(|i| self.passes[i].name().to_string())
+                .collect(),
+            dropped,
+        })
+    }
+}
+
+/// Final state of one pass in a pipeline run.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(tag = "status", content = "reason", rename_all = "snake_case")]
+pub enum PassStatus {
+    /// Ran to completion without warnings
+    Completed,
+    /// Ran to completion but recorded warnings; outputs may be incomplete
+    Partial,
+    /// Returned an error; outputs recorded before the error are kept
+    Failed(PassError),
+    /// Not run
+    Skipped(String),
+}
+
+impl PassStatus {
+    /// Lower-case status name, as serialized in the `status` field.
+    pub fn label(&self) -> &'static str {
+        match self {
+            PassStatus::Completed => "completed",
+            PassStatus::Partial => "partial",
+            PassStatus::Failed(_) => "failed",
+            PassStatus::Skipped(_) => "skipped",
+        }
+    }
+}
+
+/// Outcome record for one pass.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PassOutcome {
+    pub pass: String,
+    #[serde(flatten)]
+    pub status: PassStatus,
+    pub elapsed_ms: u64,
+    /// Warnings raised while the pass ran
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub warnings: Vec<Warning>,
+    /// Budgets the pass exhausted; its outputs are incomplete
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub budgets_hit: Vec<BudgetResource>,
+    /// Names of the artifacts the pass published
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub published: Vec<String>,
+}
+
+impl PassOutcome {
+    fn skipped<S: Into<String>>(pass: S, reason: String) -> Self {
+        Self {
+            pass: pass.into(),
+            status: PassStatus::Skipped(reason),
+            elapsed_ms: 0,
+            warnings: Vec::new(),
+            budgets_hit: Vec::new(),
+            published: Vec::new(),
+        }
+    }
+}
+
+/// Result of running a pipeline.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct PipelineReport {
+    /// Per-pass outcomes in schedule order (dropped passes last)
+    pub outcomes: Vec<PassOutcome>,
+    /// Findings produced by all passes
+    pub findings: Vec<Finding>,
+}
+
+impl PipelineReport {
+    /// Outcome for `pass`, if it was scheduled.
+    pub fn outcome(&self, pass: &str) -> Option<&PassOutcome> {
+        self.outcomes.iter().find(|o| o.pass == pass)
+    }
+
+    /// `(pass, resource)` for every budget hit during the run.
+    pub fn budgets_hit(&self) -> impl Iterator<Item = (&str, BudgetResource)> + '_ {
+        self.outcomes
+            .iter()
+            .flat_map(|o| o.budgets_hit.iter().map(move |r| (o.pass.as_str(), *r)))
+    }
+}
+
+/// Schedule and run `profile` from `registry` against `image`.
+///
+/// A failing pass does not abort the run; passes that depend on it are
+/// skipped and everything else still executes.
+pub fn run_pipeline<'a>(
+    registry: &PassRegistry,
+    profile: &Profile,
+    image: &'a dyn BinaryImage,
+) -> Result<(PipelineReport, PassContext<'a>), PipelineError> {
+    run_pipeline_with_cancel(registry, profile, image, &CancellationToken::new())
+}
+
+/// Like `run_pipeline`, but stops scheduling passes once `cancel` fires.
+///
+/// The pass running at that moment sees the token through
+/// `PassContext::cancel`; every pass after it is reported as skipped. A pass
+/// that outlives its own time budget is not treated as cancelled: it is
+/// reported as partial and the rest of the run continues.
+pub fn run_pipeline_with_cancel<'a>(
+    registry: &PassRegistry,
+    profile: &Profile,
+    image: &'a dyn BinaryImage,
+    cancel: &CancellationToken,
+) -> Result<(PipelineReport, PassContext<'a>), PipelineError> {
+    let schedule = registry.schedule(profile)?;
+    let mut ctx = PassContext::new(image);
+    ctx.cancel = cancel.clone();
+    let mut report = PipelineReport::default();
+    let mut failed: BTreeSet<String> = BTreeSet::new();
+    let span = tracing::info_span!(
+        "pipeline",
+        profile = %profile.name,
+        format = %image.format(),
+        arch = %image.arch()
+    );
+    let _run = span.enter();
+
+    for name in &schedule.order {
+        let pass = registry.get(name).expect("scheduled pass is registered");
+        if let Err(e) = cancel.check() {
+            report
+                .outcomes
+                .push(PassOutcome::skipped(name, e.to_string()));
+            continue;
+        }
+        if let Some(dep) = pass.dependencies().iter().find(|d| failed.contains(**d)) {
+            failed.insert(name.clone());
+            report.outcomes.push(PassOutcome::skipped(
+                name,
+                format!("dependency '{}' failed", dep),
+            ));
+            continue;
+        }
+        let pass_span = crate::logging::pass_span(name);
+        let _pass = pass_span.enter();
+        let t0 = Instant::now();
+        let budget = profile.budget_for(name);
+        let pass_cancel = cancel.child(budget.time());
+        ctx.begin_pass(name, budget, pass_cancel.clone());
+        tracing::debug!("pass started");
+        events::emit(AnalysisEvent::PassStarted { pass: name });
+        let findings_before = ctx.findings.len();
+        let mut result = pass.run(&mut ctx);
+        // Running out of its own time budget degrades the pass instead of
+        // failing it; cancellation of the whole run is still an error.
+        let out_of_time = budget.time_ms.is_some()
+            && cancel.reason().is_none()
+            && pass_cancel.reason() == Some(CancelReason::DeadlineExceeded);
+        if out_of_time {
+            ctx.budget_exceeded(
+                BudgetResource::Time,
+                format!(
+                    "time budget of {} ms exhausted; results are incomplete",
+                    budget.time_ms.unwrap_or_default()
+                ),
+            );
+            if matches!(&result, Err(e) if matches!(e.kind, ErrorKind::BudgetExceeded | ErrorKind::Cancelled))
+            {
+                result = Ok(());
+            }
+        }
+        for f in &ctx.findings[findings_before..] {
+            events::emit(AnalysisEvent::FindingEmitted {
+                source: name,
+                category: &f.category,
+            });
+        }
+        let warnings = std::mem::take(&mut ctx.warnings);
+        let budgets_hit = std::mem::take(&mut ctx.budgets_hit);
+        let published = std::mem::take(&mut ctx.published)
+            .into_iter()
+            .map(str::to_string)
+            .collect();
+        let status = match result {
+            Ok(()) if warnings.is_empty() => PassStatus::Completed,
+            Ok(()) => PassStatus::Partial,
+            Err(e) => {
+                tracing::warn!(error = %e, "analysis pass failed");
+                failed.insert(name.clone());
+                PassStatus::Failed(e)
+            }
+        };
+        let elapsed_ms = t0.elapsed().as_millis() as u64;
+        tracing::debug!(elapsed_ms, warnings = warnings.len(), "pass finished");
+        events::emit(AnalysisEvent::PassFinished {
+            pass: name,
+            status: status.label(),
+            elapsed_ms,
+            warnings: warnings.len(),
+        });
+        report.outcomes.push(PassOutcome {
+            pass: name.clone(),
+            status,
+            elapsed_ms,
+            warnings,
+            budgets_hit,
+            published,
+        });
+    }
+    ctx.cancel = cancel.clone();
+    ctx.budget = PassBudget::default();
+    for (pass, reason) in schedule.dropped {
+        report.outcomes.push(PassOutcome::skipped(pass, reason));
+    }
+    report.findings = ctx.findings.clone();
+    Ok((report, ctx))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    struct Stub {
+        name: &'static str,
+        deps: &'static [&'static str],
+        fail: bool,
+    }
+
+    impl AnalysisPass for Stub {
+        fn name(&self) -> &str {
+            self.name
+        }
+        fn dependencies(&self) -> &[&str] {
+            self.deps
+        }
+        fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+            if self.fail {
+                ctx.note(self.name, "before failure");
+                return Err(PassError::truncated("boom"));
+            }
+            ctx.note(self.name, self.name);
+            Ok(())
+        }
+    }
+
+    fn stub(name: &'static str, deps: &'static [&'static str]) -> Box<dyn AnalysisPass> {
+        Box::new(Stub {
+            name,
+            deps,
+            fail: false,
+        })
+    }
+
+    fn registry() -> PassRegistry {
+        let mut r = PassRegistry::new();
+        r.register(stub("c", &["a", "b"])).unwrap();
+        r.register(stub("a", &[])).unwrap();
+        r.register(stub("b", &["a"])).unwrap();
+        r.register(stub("d", &[])).unwrap();
+        r
+    }
+
+    #[test]
+    fn schedule_orders_by_dependencies() {
+        let s = registry().schedule(&Profile::default()).unwrap();
+        assert_eq!(s.order, ["a", "b", "c", "d"]);
+        assert!(s.dropped.is_empty());
+    }
+
+    #[test]
+    fn explicit_selection_pulls_in_dependencies() {
+        let s = registry()
+            .schedule(&Profile::only("custom", ["c"]))
+            .unwrap();
+        assert_eq!(s.order, ["a", "b", "c"]);
+    }
+
+    #[test]
+    fn disabling_a_pass_drops_its_dependents() {
+        let s = registry()
+            .schedule(&Profile::default().disable("b"))
+            .unwrap();
+        assert_eq!(s.order, ["a", "d"]);
+        assert_eq!(s.dropped.len(), 2);
+        assert_eq!(s.dropped[1].0, "c");
+    }
+
+    #[test]
+    fn registry_errors() {
+        let mut r = registry();
+        assert_eq!(
+            r.register(stub("a", &[])),
+            Err(PipelineError::DuplicatePass("a".into()))
+        );
+        r.register(stub("x", &["y"])).unwrap();
+        assert!(matches!(
+            r.schedule(&Profile::default()),
+            Err(PipelineError::MissingDependency { .. })
+        ));
+
+        let mut cyc = PassRegistry::new();
+        cyc.register(stub("p", &["q"])).unwrap();
+        cyc.register(stub("q", &["p"])).unwrap();
+        assert!(matches!(
+            cyc.schedule(&Profile::default()),
+            Err(PipelineError::Cycle(_))
+        ));
+        assert_eq!(
+            cyc.schedule(&Profile::only("x", ["nope"])),
+            Err(PipelineError::UnknownPass("nope".into()))
+        );
+    }
+
+    #[test]
+    fn failed_pass_skips_dependents_only() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let mut r = PassRegistry::new();
+        r.register(Box::new(Stub {
+            name: "a",
+            deps: &[],
+            fail: true,
+        }))
+        .unwrap();
+        r.register(stub("b", &["a"])).unwrap();
+        r.register(stub("d", &[])).unwrap();
+        let (report, _) = run_pipeline(&r, &Profile::default(), &img).unwrap();
+        assert_eq!(
+            report.outcome("a").unwrap().status,
+            PassStatus::Failed(PassError::truncated("boom"))
+        );
+        assert!(matches!(
+            report.outcome("b").unwrap().status,
+            PassStatus::Skipped(_)
+        ));
+        assert_eq!(report.outcome("d").unwrap().status, PassStatus::Completed);
+        // The failed pass's partial output survives.
+        assert_eq!(report.findings.len(), 2);
+    }
+
+    /// Polls the token until it fires, charging instructions as it goes.
+    struct Spin;
+
+    impl AnalysisPass for Spin {
+        fn name(&self) -> &str {
+            "spin"
+        }
+        fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+            ctx.note("spin", "started");
+            loop {
+                ctx.check_cancelled()?;
+                if !ctx.charge_instructions(1) {
+                    return Ok(());
+                }
+                std::thread::sleep(std::time::Duration::from_millis(1));
+            }
+        }
+    }
+
+    fn spin_registry() -> PassRegistry {
+        let mut r = PassRegistry::new();
+        r.register(Box::new(Spin)).unwrap();
+        r.register(stub("after", &["spin"])).unwrap();
+        r
+    }
+
+    #[test]
+    fn time_budget_degrades_instead_of_failing() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let profile = Profile::default().with_budget("spin", PassBudget::default().with_time_ms(5));
+        let (report, _) = run_pipeline(&spin_registry(), &profile, &img).unwrap();
+        let spin = report.outcome("spin").unwrap();
+        assert_eq!(spin.status, PassStatus::Partial);
+        assert_eq!(spin.budgets_hit, [BudgetResource::Time]);
+        assert_eq!(spin.warnings[0].kind, ErrorKind::BudgetExceeded);
+        // Dependents still run on the partial results.
+        assert_eq!(
+            report.outcome("after").unwrap().status,
+            PassStatus::Completed
+        );
+        assert_eq!(report.findings.len(), 2);
+        assert_eq!(
+            report.budgets_hit().collect::<Vec<_>>(),
+            [("spin", BudgetResource::Time)]
+        );
+    }
+
+    #[test]
+    fn instruction_budget_is_reported() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let profile =
+            Profile::default().with_default_budget(PassBudget::default().with_instructions(3));
+        let (report, _) = run_pipeline(&spin_registry(), &profile, &img).unwrap();
+        let spin = report.outcome("spin").unwrap();
+        assert_eq!(spin.status, PassStatus::Partial);
+        assert_eq!(spin.budgets_hit, [BudgetResource::Instructions]);
+        assert!(report.outcome("after").unwrap().budgets_hit.is_empty());
+    }
+
+    #[test]
+    fn memory_charges_accumulate_per_pass() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let mut ctx = PassContext::new(&img);
+        ctx.begin_pass(
+            "x",
+            PassBudget::default().with_memory_bytes(100),
+            CancellationToken::new(),
+        );
+        assert!(ctx.charge_memory(60));
+        assert!(!ctx.charge_memory(60));
+        assert!(!ctx.charge_memory(60));
+        assert!(ctx.charge_memory(40));
+        assert_eq!(ctx.budgets_hit, [BudgetResource::Memory]);
+        assert_eq!(ctx.warnings.len(), 1);
+    }
+
+    #[test]
+    fn cancelled_run_skips_remaining_passes() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let token = CancellationToken::new();
+        token.cancel();
+        let (report, _) =
+            run_pipeline_with_cancel(&registry(), &Profile::default(), &img, &token).unwrap();
+        assert_eq!(report.outcomes.len(), 4);
+        assert!(report
+            .outcomes
+            .iter()
+            .all(|o| matches!(o.status, PassStatus::Skipped(_))));
+        assert!(report.findings.is_empty());
+    }
+
+    /// Publishes one decoded string.
+    struct Decode;
+
+    impl AnalysisPass for Decode {
+        fn name(&self) -> &str {
+            "decode"
+        }
+        fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+            use blackboard::{DecodedString, DecodedStrings};
+            ctx.publish(DecodedStrings(vec![DecodedString {
+                va: Some(0x1000),
+                offset: None,
+                text: "http://example.invalid".into(),
+                encoding: "xor:0x5a".into(),
+            }]));
+            Ok(())
+        }
+    }
+
+    /// Consumes the decoded strings without re-deriving them.
+    struct UseStrings(&'static [&'static str]);
+
+    impl AnalysisPass for UseStrings {
+        fn name(&self) -> &str {
+            "use"
+        }
+        fn dependencies(&self) -> &[&str] {
+            self.0
+        }
+        fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError> {
+            let n = ctx.require::<blackboard::DecodedStrings>()?.0.len();
+            ctx.note("strings", format!("decoded={}", n));
+            Ok(())
+        }
+    }
+
+    #[test]
+    fn passes_share_artifacts_through_the_blackboard() {
+        let data = *b"\0asm\x01\0\0\0";
+        let img = crate::formats::wasm::WasmModule::parse(&data).unwrap();
+        let mut r = PassRegistry::new();
+        r.register(Box::new(Decode)).unwrap();
+        r.register(Box::new(UseStrings(&["decode"]))).unwrap();
+        let (report, ctx) = run_pipeline(&r, &Profile::default(), &img).unwrap();
+        assert_eq!(
+            report.outcome("decode").unwrap().published,
+            ["decoded_strings"]
+        );
+        assert_eq!(report.findings[0].claim, "decoded=1");
+        assert_eq!(
+            ctx.artifacts.producer::<blackboard::DecodedStrings>(),
+            Some("decode")
+        );
+
+        // Without its producer the consumer fails rather than guessing.
+        let mut r = PassRegistry::new();
+        r.register(Box::new(UseStrings(&[]))).unwrap();
+        let (report, _) = run_pipeline(&r, &Profile::default(), &img).unwrap();
+        assert!(matches!(
+            &report.outcome("use").unwrap().status,
+            PassStatus::Failed(e) if e.message.contains("decoded_strings")
+        ));
+    }
+}
@@ -0,0 +1,261 @@
+//! The `AnalysisPass` trait and the state passes share during a run.
+
+use super::blackboard::{Artifact, Blackboard, EntryPoint, Functions};
+use super::budget::{BudgetResource, PassBudget};
+use crate::cancel::CancellationToken;
+use crate::core::address_map::AddressMap;
+use crate::core::call_graph::CallGraph;
+use crate::core::function::Function;
+use crate::core::image::BinaryImage;
+use crate::core::segment::Perms;
+use crate::core::triage::{Finding, Provenance};
+use crate::error::{ErrorKind, GlaurungError, Warning};
+use serde::{Deserialize, Serialize};
+
+/// Error returned by a pass that could not complete.
+///
+/// Anything the pass recorded in the context before failing (findings,
+/// warnings, partial outputs) is kept; only its dependents are skipped.
+#[derive(Debug, Clone, thiserror::Error, PartialEq, Eq, Serialize, Deserialize)]
+#[error("{kind}: {message}")]
+pub struct PassError {
+    pub kind: ErrorKind,
+    pub message: String,
+}
+
+impl PassError {
+    pub fn new<S: Into<String>>(kind: ErrorKind, message: S) -> Self {
+        Self {
+            kind,
+            message: message.into(),
+        }
+    }
+
+    pub fn unsupported<S: Into<String>>(message: S) -> Self {
+        Self::new(ErrorKind::UnsupportedFormat, message)
+    }
+
+    pub fn truncated<S: Into<String>>(message: S) -> Self {
+        Self::new(ErrorKind::TruncatedStructure, message)
+    }
+
+    pub fn budget<S: Into<String>>(message: S) -> Self {
+        Self::new(ErrorKind::BudgetExceeded, message)
+    }
+
+    pub fn bug<S: Into<String>>(message: S) -> Self {
+        Self::new(ErrorKind::ParserBug, message)
+    }
+
+    /// A required artifact was not published by any earlier pass.
+    pub fn missing_artifact(name: &str) -> Self {
+        Self::new(
+            ErrorKind::Other,
+            format!("required artifact '{}' was not published", name),
+        )
+    }
+}
+
+impl From<GlaurungError> for PassError {
+    fn from(err: GlaurungError) -> Self {
+        Self::new(err.kind(), err.to_string())
+    }
+}
+
+/// A unit of analysis scheduled by the pipeline.
+pub trait AnalysisPass: Send + Sync {
+    /// Unique pass name (e.g. "entry", "functions").
+    fn name(&self) -> &str;
+
+    /// Names of passes that must run before this one.
+    fn dependencies(&self) -> &[&str] {
+        &[]
+    }
+
+    /// One-line description for listings.
+    fn description(&self) -> &str {
+        ""
+    }
+
+    /// Run the pass, reading and extending the shared context.
+    fn run(&self, ctx: &mut PassContext<'_>) -> Result<(), PassError>;
+}
+
+/// State shared by the passes of one pipeline run.
+pub struct PassContext<'a> {
+    /// Image under analysis
+    pub image: &'a dyn BinaryImage,
+    /// Loaded address space with per-region permissions
+    pub memory: AddressMap,
+    /// Name of the pass currently executing
+    pub current_pass: String,
+    /// Cancellation token for the current pass (the run's token narrowed by
+    /// the pass's time budget); long passes should poll it
+    pub cancel: CancellationToken,
+    /// Budget of the currently running pass
+    pub budget: PassBudget,
+    /// Budgets the currently running pass has exhausted
+    pub budgets_hit: Vec<BudgetResource>,
+    instructions_used: u64,
+    memory_used: u64,
+    /// Findings reported so far
+    pub findings: Vec<Finding>,
+    /// Warnings raised by the currently running pass
+    pub warnings: Vec<Warning>,
+    /// Intermediate results published by passes for later passes
+    pub artifacts: Blackboard,
+    /// Artifacts published by the currently running pass
+    pub(crate) published: Vec<&'static str>,
+}
+
+impl<'a> PassContext<'a> {
+    pub fn new(image: &'a dyn BinaryImage) -> Self {
+        Self {
+            image,
+            memory: image.address_map().into_owned(),
+            current_pass: String::new(),
+            cancel: CancellationToken::new(),
+            budget: PassBudget::default(),
+            budgets_hit: Vec::new(),
+            instructions_used: 0,
+            memory_used: 0,
+            findings: Vec::new(),
+            warnings: Vec::new(),
+            artifacts: Blackboard::new(),
+            published: Vec::new(),
+        }
+    }
+
+    /// Publish `value` to the blackboard on behalf of the current pass.
+    pub fn publish<T: Artifact>(&mut self, value: T) {
+        self.artifacts.publish(&self.current_pass, value);
+        if !self.published.contains(&T::NAME) {
+            self.published.push(T::NAME);
+        }
+    }
+
+    /// Artifact published by an earlier pass, if any.
+    pub fn artifact<T: Artifact>(&self) -> Option<&T> {
+        self.artifacts.get()
+    }
+
+    /// Artifact published by an earlier pass, or an error for passes that
+    /// cannot run without it.
+    pub fn require<T: Artifact>(&self) -> Result<&T, PassError> {
+        self.artifacts
+            .get()
+            .ok_or_else(|| PassError::missing_artifact(T::NAME))
+    }
+
+    /// Entry point VA, once resolved (the `EntryPoint` artifact).
+    pub fn entry(&self) -> Option<u64> {
+        self.artifact::<EntryPoint>().map(|e| e.0)
+    }
+
+    /// Discovered functions (the `Functions` artifact), or none yet.
+    pub fn functions(&self) -> &[Function] {
+        self.artifact::<Functions>().map_or(&[], |f| &f.0)
+    }
+
+    /// Call graph over `functions`, once built.
+    pub fn call_graph(&self) -> Option<&CallGraph> {
+        self.artifact()
+    }
+
+    /// Provenance attributed to the currently running pass.
+    pub fn provenance(&self, confidence: f32) -> Provenance {
+        Provenance::new(self.current_pass.clone(), confidence)
+    }
+
+    /// Record a finding.
+    pub fn push_finding(&mut self, finding: Finding) {
+        self.findings.push(finding);
+    }
+
+    /// Record a structural (confidence 1.0) finding for the current pass.
+    pub fn note<C: Into<String>, S: Into<String>>(&mut self, category: C, claim: S) {
+        let prov = self.provenance(1.0);
+        self.push_finding(Finding::new(category, claim, prov));
+    }
+
+    /// Permissions of the mapping containing `va`.
+    pub fn perms_at(&self, va: u64) -> Option<Perms> {
+        self.memory.perms_at(va)
+    }
+
+    /// True if `va` is mapped executable. Passes that decode code should
+    /// not follow addresses for which this is false.
+    pub fn is_executable(&self, va: u64) -> bool {
+        self.memory.is_executable(va)
+    }
+
+    /// `Err` once the run has been cancelled or its deadline has passed.
+    pub fn check_cancelled(&self) -> Result<(), PassError> {
+        self.cancel.check().map_err(PassError::from)
+    }
+
+    /// Prepare for `pass` running under `budget` and `cancel`.
+    pub(crate) fn begin_pass(&mut self, pass: &str, budget: PassBudget, cancel: CancellationToken) {
+        self.current_pass = pass.to_string();
+        self.budget = budget;
+        self.cancel = cancel;
+        self.budgets_hit.clear();
+        self.published.clear();
+        self.instructions_used = 0;
+        self.memory_used = 0;
+    }
+
+    /// Account `n` decoded or executed instructions. Returns false once the
+    /// pass's instruction budget is exhausted; the first overrun is recorded.
+    pub fn charge_instructions(&mut self, n: u64) -> bool {
+        self.instructions_used = self.instructions_used.saturating_add(n);
+        match self.budget.max_instructions {
+            Some(max) if self.instructions_used > max => {
+                self.budget_exceeded(
+                    BudgetResource::Instructions,
+                    format!("instruction budget of {} exhausted", max),
+                );
+                false
+            }
+            _ => true,
+        }
+    }
+
+    /// Account `bytes` of memory the pass is about to hold. Returns false
+    /// (and records the overrun) if that would exceed the memory budget; the
+    /// pass should then stop growing its results.
+    pub fn charge_memory(&mut self, bytes: u64) -> bool {
+        let used = self.memory_used.saturating_add(bytes);
+        match self.budget.max_memory_bytes {
+            Some(max) if used > max => {
+                self.budget_exceeded(
+                    BudgetResource::Memory,
+                    format!("memory budget of {} bytes exhausted", max),
+                );
+                false
+            }
+            _ => {
+                self.memory_used = used;
+                true
+            }
+        }
+    }
+
+    /// Record that `resource` ran out. Only the first report per resource
+    /// becomes a warning; the pass will be reported as partial.
+    pub fn budget_exceeded<S: Into<String>>(&mut self, resource: BudgetResource, message: S) {
+        if self.budgets_hit.contains(&resource) {
+            return;
+        }
+        self.budgets_hit.push(resource);
+        self.warn(ErrorKind::BudgetExceeded, message);
+    }
+
+    /// Record a non-fatal problem; the pass will be reported as partial.
+    pub fn warn<S: Into<String>>(&mut self, kind: ErrorKind, message: S) {
+        let w = Warning::new(kind, message);
+        // The enclosing `pass` span attributes the record to this pass.
+        tracing::warn!(kind = %w.kind, message = %w.message, "pass warning");
+        self.warnings.push(w);
+    }
+}
@@ -0,0 +1,92 @@
+//! Profiles: named selections of passes.
+
+use super::budget::PassBudget;
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+
+/// Which passes a pipeline run should execute.
+///
+/// `enabled = None` selects every registered pass; otherwise only the listed
+/// passes (and their dependencies) run. `disabled` always wins and also
+/// removes dependents of the disabled passes. `budgets` caps individual
+/// passes; `default_budget` applies to every pass for limits it leaves unset.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Profile {
+    pub name: String,
+    pub enabled: Option<Vec<String>>,
+    pub disabled: Vec<String>,
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub budgets: BTreeMap<String, PassBudget>,
+    #[serde(default, skip_serializing_if = "PassBudget::is_unlimited")]
+    pub default_budget: PassBudget,
+}
+
+impl Default for Profile {
+    fn default() -> Self {
+        Self {
+            name: "default".to_string(),
+            enabled: None,
+            disabled: Vec::new(),
+            budgets: BTreeMap::new(),
+            default_budget: PassBudget::default(),
+        }
+    }
+}
+
+impl Profile {
+    /// Profile running exactly `passes` (plus dependencies).
+    pub fn only<N, I, S>(name: N, passes: I) -> Self
+    where
+        N: Into<String>,
+        I: IntoIterator<Item = S>,
+        S: Into<String>,
+    {
+        Self {
+            name: name.into(),
+            enabled: Some(passes.into_iter().map(Into::into).collect()),
+            disabled: Vec::new(),
+            budgets: BTreeMap::new(),
+            default_budget: PassBudget::default(),
+        }
+    }
+
+    /// Disable `pass` (and anything depending on it).
+    pub fn disable<S: Into<String>>(mut self, pass: S) -> Self {
+        self.disabled.push(pass.into());
+        self
+    }
+
+    /// Cap `pass` with `budget`.
+    pub fn with_budget<S: Into<String>>(mut self, pass: S, budget: PassBudget) -> Self {
+        self.budgets.insert(pass.into(), budget);
+        self
+    }
+
+    /// Cap every pass with `budget` (per-pass budgets take precedence).
+    pub fn with_default_budget(mut self, budget: PassBudget) -> Self {
+        self.default_budget = budget;
+        self
+    }
+
+    /// Effective budget for `pass`.
+    pub fn budget_for(&self, pass: &str) -> PassBudget {
+        self.budgets
+            .get(pass)
+            .copied()
+            .unwrap_or_default()
+            .or(self.default_budget)
+    }
+
+    /// Built-in profiles: `quick` (header-level passes only) and `default`
+    /// (every registered pass).
+    pub fn named(name: &str) -> Option<Self> {
+        match name {
+            "quick" => Some(Self::only(
+                "quick",
+                ["layout", "entry", "imports", "symbols"],
+            )),
+            "default" => Some(Self::default()),
+            _ => None,
+        }
+    }
+}
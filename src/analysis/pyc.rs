@@ -0,0 +1,988 @@
+//! CPython bytecode (`.pyc`) parser and disassembler.
+//!
+//! A `.pyc` is a 16-byte header (12 bytes before 3.7) followed by the
+//! module's code object in `marshal` format. The header's first two bytes
+//! are a per-release magic number, which fixes both the code-object layout
+//! and the opcode table. PyInstaller stores its entry scripts and PYZ
+//! members as bare marshal data; `parse_code_object` reads those once the
+//! version is known from the archive's own magic.
+//!
+//! Supported: CPython 3.6 through 3.13.
+//!
+//!   * Code objects: names, constants, locals, cell/free variables,
+//!     filename, first line, and raw bytecode, recursively for nested
+//!     functions, classes, and comprehensions.
+//!   * Disassembly: `EXTENDED_ARG` folding, inline `CACHE` entries (3.11+)
+//!     skipped, jump targets as absolute offsets, constant / name / local
+//!     arguments resolved the way `dis` prints them.
+//!   * Triage summaries: every string constant, and the modules named by
+//!     `IMPORT_NAME`.
+//!
+//! Line tables and 3.11+ exception tables are kept as raw bytes only.
+
+use std::fmt;
+
+use crate::analysis::pyc_opcodes::{self, OpTable};
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum PycError {
+    BadMagic,
+    /// Magic number of a CPython release outside 3.6..=3.13.
+    UnsupportedVersion(u16),
+    Truncated(&'static str),
+    /// Unknown marshal type byte.
+    BadMarshal(u8),
+    /// Object nesting beyond `MAX_DEPTH`.
+    TooDeep,
+    UnknownOpcode(u8),
+}
+
+/// A marshalled Python object.
+#[derive(Debug, Clone, PartialEq)]
+pub enum PyObject {
+    /// `TYPE_NULL`; only terminates dicts.
+    Null,
+    None,
+    Bool(bool),
+    StopIteration,
+    Ellipsis,
+    Int(i64),
+    /// Integer wider than 63 bits, as `0x`-prefixed hex.
+    BigInt(String),
+    Float(f64),
+    Complex(f64, f64),
+    Bytes(Vec<u8>),
+    Str(String),
+    Tuple(Vec<PyObject>),
+    List(Vec<PyObject>),
+    Set(Vec<PyObject>),
+    FrozenSet(Vec<PyObject>),
+    Dict(Vec<(PyObject, PyObject)>),
+    Code(Box<PyCode>),
+}
+
+/// A code object. Field names follow `co_*` without the prefix.
+#[derive(Debug, Clone, PartialEq)]
+pub struct PyCode {
+    pub name: String,
+    /// `co_qualname` on 3.11+, `name` before.
+    pub qualname: String,
+    pub filename: String,
+    pub first_line: u32,
+    pub arg_count: u32,
+    pub posonly_arg_count: u32,
+    pub kwonly_arg_count: u32,
+    pub stack_size: u32,
+    pub flags: u32,
+    pub bytecode: Vec<u8>,
+    pub consts: Vec<PyObject>,
+    pub names: Vec<String>,
+    /// Fast locals, arguments first.
+    pub varnames: Vec<String>,
+    pub cellvars: Vec<String>,
+    pub freevars: Vec<String>,
+    /// Every fast slot in index order: `co_localsplusnames` on 3.11+,
+    /// `varnames + cellvars + freevars` before.
+    pub localsplus: Vec<String>,
+    /// `co_lnotab` before 3.10, `co_linetable` after.
+    pub linetable: Vec<u8>,
+    /// 3.11+ zero-cost exception table; empty before.
+    pub exception_table: Vec<u8>,
+}
+
+impl PyCode {
+    /// This code object and every one nested in its constants, depth-first.
+    pub fn code_objects(&self) -> Vec<&PyCode> {
+        fn walk<'c>(obj: &'c PyObject, out: &mut Vec<&'c PyCode>) {
+            match obj {
+                PyObject::Code(c) => {
+                    out.push(c);
+                    c.consts.iter().for_each(|k| walk(k, out));
+                }
+                PyObject::Tuple(v)
+                | PyObject::List(v)
+                | PyObject::Set(v)
+                | PyObject::FrozenSet(v) => v.iter().for_each(|k| walk(k, out)),
+                _ => {}
+            }
+        }
+        let mut out = vec![self];
+        self.consts.iter().for_each(|k| walk(k, &mut out));
+        out
+    }
+
+    /// Every `str` constant of this and nested code objects, first
+    /// occurrence order, without duplicates. Docstrings, literals, format
+    /// fragments, and keyword-argument names all land here.
+    pub fn strings(&self) -> Vec<String> {
+        fn walk(obj: &PyObject, out: &mut Vec<String>) {
+            match obj {
+                PyObject::Str(s) => {
+                    if !out.contains(s) {
+                        out.push(s.clone());
+                    }
+                }
+                PyObject::Tuple(v)
+                | PyObject::List(v)
+                | PyObject::Set(v)
+                | PyObject::FrozenSet(v) => v.iter().for_each(|k| walk(k, out)),
+                PyObject::Code(c) => c.consts.iter().for_each(|k| walk(k, out)),
+                _ => {}
+            }
+        }
+        let mut out = Vec::new();
+        self.consts.iter().for_each(|k| walk(k, &mut out));
+        out
+    }
+}
+
+/// A parsed `.pyc` file.
+#[derive(Debug, Clone, PartialEq)]
+pub struct PycFile {
+    pub magic: u16,
+    /// CPython minor version: 11 for 3.11.
+    pub minor: u8,
+    /// PEP 552 flags (3.7+); bit 0 marks a hash-based pyc.
+    pub flags: u32,
+    pub source_mtime: Option<u32>,
+    pub source_size: Option<u32>,
+    /// SipHash of the source, for hash-based pycs.
+    pub source_hash: Option<[u8; 8]>,
+    pub code: PyCode,
+}
+
+impl PycFile {
+    /// String constants of the whole module; see `PyCode::strings`.
+    pub fn strings(&self) -> Vec<String> {
+        self.code.strings()
+    }
+
+    /// Modules imported anywhere in the module; see `imports`.
+    pub fn imports(&self) -> Vec<String> {
+        imports(&self.code, self.minor)
+    }
+}
+
+/// CPython minor version of a pyc magic number (the little-endian u16 in
+/// front of `\r\n`). Ranges follow `importlib._bootstrap_external`.
+pub fn version_from_magic(magic: u16) -> Option<u8> {
+    Some(match magic {
+        3360..=3379 => 6,
+        3390..=3399 => 7,
+        3400..=3419 => 8,
+        3420..=3429 => 9,
+        3430..=3449 => 10,
+        3450..=3499 => 11,
+        3500..=3549 => 12,
+        3550..=3599 => 13,
+        _ => return None,
+    })
+}
+
+/// Parse a `.pyc` file: header, then the module code object.
+pub fn parse_pyc(data: &[u8]) -> Result<PycFile, PycError> {
+    if data.len() < 4 || data[2..4] != *b"\r\n" {
+        return Err(PycError::BadMagic);
+    }
+    let magic = u16::from_le_bytes([data[0], data[1]]);
+    let minor = version_from_magic(magic).ok_or(PycError::UnsupportedVersion(magic))?;
+    let u32_at = |off: usize| -> Result<u32, PycError> {
+        data.get(off..off + 4)
+            .map(|b| u32::from_le_bytes(b.try_into().unwrap()))
+            .ok_or(PycError::Truncated("pyc header"))
+    };
+    let (flags, mtime, size, hash, header) = if minor >= 7 {
+        let flags = u32_at(4)?;
+        if flags & 1 != 0 {
+            let hash = data
+                .get(8..16)
+                .ok_or(PycError::Truncated("pyc header"))?
+                .try_into()
+                .unwrap();
+            (flags, None, None, Some(hash), 16)
+        } else {
+            (flags, Some(u32_at(8)?), Some(u32_at(12)?), None, 16)
+        }
+    } else {
+        (0, Some(u32_at(4)?), Some(u32_at(8)?), None, 12)
+    };
+    Ok(PycFile {
+        magic,
+        minor,
+        flags,
+        source_mtime: mtime,
+        source_size: size,
+        source_hash: hash,
+        code: parse_code_object(&data[header..], minor)?,
+    })
+}
+
+/// Parse header-less marshal data holding a code object, as written by
+/// `marshal.dumps(code)` under CPython 3.`minor`.
+pub fn parse_code_object(data: &[u8], minor: u8) -> Result<PyCode, PycError> {
+    if !(6..=13).contains(&minor) {
+        return Err(PycError::UnsupportedVersion(0));
+    }
+    let mut r = Reader {
+        data,
+        pos: 0,
+        minor,
+        refs: Vec::new(),
+        depth: 0,
+    };
+    match r.object()? {
+        PyObject::Code(code) => Ok(*code),
+        _ => Err(PycError::BadMarshal(
+            data.first().copied().unwrap_or(0) & 0x7F,
+        )),
+    }
+}
+
+/// Nesting limit for marshalled containers and code objects.
+const MAX_DEPTH: usize = 200;
+
+/// Marshal's reference flag: the object is appended to the ref table.
+const FLAG_REF: u8 = 0x80;
+
+struct Reader<'a> {
+    data: &'a [u8],
+    pos: usize,
+    minor: u8,
+    /// Objects written with `FLAG_REF`, in order; containers are reserved
+    /// before their items are read, as `marshal.c` does.
+    refs: Vec<PyObject>,
+    depth: usize,
+}
+
+impl Reader<'_> {
+    fn take(&mut self, n: usize, what: &'static str) -> Result<&[u8], PycError> {
+        let b = self
+            .pos
+            .checked_add(n)
+            .and_then(|end| self.data.get(self.pos..end))
+            .ok_or(PycError::Truncated(what))?;
+        self.pos += n;
+        Ok(b)
+    }
+
+    fn u8(&mut self) -> Result<u8, PycError> {
+        Ok(self.take(1, "marshal byte")?[0])
+    }
+
+    fn i32(&mut self) -> Result<i32, PycError> {
+        Ok(i32::from_le_bytes(
+            self.take(4, "marshal int")?.try_into().unwrap(),
+        ))
+    }
+
+    fn u32(&mut self) -> Result<u32, PycError> {
+        Ok(self.i32()? as u32)
+    }
+
+    /// Length prefix of a sized object, checked against the remaining input.
+    fn len(&mut self, short: bool) -> Result<usize, PycError> {
+        let n = if short {
+            self.u8()? as usize
+        } else {
+            let n = self.i32()?;
+            usize::try_from(n).map_err(|_| PycError::Truncated("negative length"))?
+        };
+        if n > self.data.len() - self.pos {
+            return Err(PycError::Truncated("marshal length"));
+        }
+        Ok(n)
+    }
+
+    fn f64(&mut self) -> Result<f64, PycError> {
+        Ok(f64::from_le_bytes(
+            self.take(8, "marshal float")?.try_into().unwrap(),
+        ))
+    }
+
+    /// Pre-3 `TYPE_FLOAT`: a length-prefixed decimal string.
+    fn text_float(&mut self) -> Result<f64, PycError> {
+        let n = self.u8()? as usize;
+        let s = String::from_utf8_lossy(self.take(n, "marshal float")?).into_owned();
+        Ok(s.trim().parse().unwrap_or(f64::NAN))
+    }
+
+    fn object(&mut self) -> Result<PyObject, PycError> {
+        if self.depth >= MAX_DEPTH {
+            return Err(PycError::TooDeep);
+        }
+        self.depth += 1;
+        let code = self.u8()?;
+        let slot = (code & FLAG_REF != 0).then(|| {
+            self.refs.push(PyObject::Null);
+            self.refs.len() - 1
+        });
+        let obj = self.object_body(code & !FLAG_REF);
+        self.depth -= 1;
+        let obj = obj?;
+        if let Some(slot) = slot {
+            self.refs[slot] = obj.clone();
+        }
+        Ok(obj)
+    }
+
+    fn object_body(&mut self, ty: u8) -> Result<PyObject, PycError> {
+        Ok(match ty {
+            b'0' => PyObject::Null,
+            b'N' => PyObject::None,
+            b'F' => PyObject::Bool(false),
+            b'T' => PyObject::Bool(true),
+            b'S' => PyObject::StopIteration,
+            b'.' => PyObject::Ellipsis,
+            b'i' => PyObject::Int(self.i32()? as i64),
+            b'I' => PyObject::Int(i64::from_le_bytes(
+                self.take(8, "marshal int64")?.try_into().unwrap(),
+            )),
+            b'l' => self.long()?,
+            b'f' => PyObject::Float(self.text_float()?),
+            b'g' => PyObject::Float(self.f64()?),
+            b'x' => PyObject::Complex(self.text_float()?, self.text_float()?),
+            b'y' => PyObject::Complex(self.f64()?, self.f64()?),
+            b's' => {
+                let n = self.len(false)?;
+                PyObject::Bytes(self.take(n, "marshal bytes")?.to_vec())
+            }
+            // Unicode is UTF-8 with lone surrogates allowed.
+            b'u' | b't' | b'a' | b'A' | b'z' | b'Z' => {
+                let n = self.len(matches!(ty, b'z' | b'Z'))?;
+                PyObject::Str(String::from_utf8_lossy(self.take(n, "marshal str")?).into_owned())
+            }
+            b'(' | b')' | b'[' | b'<' | b'>' => {
+                let n = self.len(ty == b')')?;
+                let items = (0..n)
+                    .map(|_| self.object())
+                    .collect::<Result<Vec<_>, _>>()?;
+                match ty {
+                    b'[' => PyObject::List(items),
+                    b'<' => PyObject::Set(items),
+                    b'>' => PyObject::FrozenSet(items),
+                    _ => PyObject::Tuple(items),
+                }
+            }
+            b'{' => {
+                let mut items = Vec::new();
+                loop {
+                    let k = self.object()?;
+                    if k == PyObject::Null {
+                        break;
+                    }
+                    items.push((k, self.object()?));
+                }
+                PyObject::Dict(items)
+            }
+            b'r' => {
+                let idx = self.u32()? as usize;
+                self.refs
+                    .get(idx)
+                    .cloned()
+                    .ok_or(PycError::Truncated("marshal ref"))?
+            }
+            b'c' => PyObject::Code(Box::new(self.code()?)),
+            other => return Err(PycError::BadMarshal(other)),
+        })
+    }
+
+    /// `TYPE_LONG`: signed digit count, then 15-bit digits, least
+    /// significant first.
+    fn long(&mut self) -> Result<PyObject, PycError> {
+        let n = self.i32()?;
+        let count = n.unsigned_abs() as usize;
+        if count * 2 > self.data.len() - self.pos {
+            return Err(PycError::Truncated("marshal long"));
+        }
+        let digits: Vec<u16> = (0..count)
+            .map(|_| {
+                let b = self.take(2, "marshal long")?;
+                Ok(u16::from_le_bytes([b[0], b[1]]))
+            })
+            .collect::<Result<_, PycError>>()?;
+        if count <= 4 {
+            let v = digits
+                .iter()
+                .rev()
+                .fold(0u64, |acc, &d| acc << 15 | (d & 0x7FFF) as u64);
+            if v <= i64::MAX as u64 {
+                let v = v as i64;
+                return Ok(PyObject::Int(if n < 0 { -v } else { v }));
+            }
+        }
+        // Repack 15-bit digits into nibbles for a hex rendering.
+        let mut nibbles = Vec::new();
+        let (mut acc, mut bits) = (0u32, 0);
+        for &d in &digits {
+            acc |= ((d & 0x7FFF) as u32) << bits;
+            bits += 15;
+            while bits >= 4 {
+                nibbles.push(acc & 0xF);
+                acc >>= 4;
+                bits -= 4;
+            }
+        }
+        nibbles.push(acc);
+        while nibbles.len() > 1 && nibbles.last() == Some(&0) {
+            nibbles.pop();
+        }
+        let hex: String = nibbles
+            .iter()
+            .rev()
+            .map(|&d| char::from_digit(d, 16).unwrap())
+            .collect();
+        Ok(PyObject::BigInt(format!(
+            "{}0x{}",
+            if n < 0 { "-" } else { "" },
+            hex
+        )))
+    }
+
+    fn strings(&mut self) -> Result<Vec<String>, PycError> {
+        Ok(match self.object()? {
+            PyObject::Tuple(items) => items
+                .into_iter()
+                .map(|o| match o {
+                    PyObject::Str(s) => s,
+                    other => other.to_string(),
+                })
+                .collect(),
+            _ => Vec::new(),
+        })
+    }
+
+    fn string(&mut self) -> Result<String, PycError> {
+        Ok(match self.object()? {
+            PyObject::Str(s) => s,
+            other => other.to_string(),
+        })
+    }
+
+    fn bytes(&mut self) -> Result<Vec<u8>, PycError> {
+        Ok(match self.object()? {
+            PyObject::Bytes(b) => b,
+            _ => Vec::new(),
+        })
+    }
+
+    /// Code object fields, in the order `marshal.c` writes them:
+    ///
+    ///   * 3.6, 3.7: argcount kwonlyargcount nlocals stacksize flags code
+    ///     consts names varnames freevars cellvars filename name
+    ///     firstlineno lnotab
+    ///   * 3.8-3.10: as above with posonlyargcount after argcount
+    ///   * 3.11+: argcount posonlyargcount kwonlyargcount stacksize flags
+    ///     code consts names localsplusnames localspluskinds filename name
+    ///     qualname firstlineno linetable exceptiontable
+    fn code(&mut self) -> Result<PyCode, PycError> {
+        let arg_count = self.u32()?;
+        let posonly_arg_count = if self.minor >= 8 { self.u32()? } else { 0 };
+        let kwonly_arg_count = self.u32()?;
+        if self.minor <= 10 {
+            self.u32()?; // nlocals
+        }
+        let stack_size = self.u32()?;
+        let flags = self.u32()?;
+        let bytecode = self.bytes()?;
+        let consts = match self.object()? {
+            PyObject::Tuple(items) => items,
+            _ => Vec::new(),
+        };
+        let names = self.strings()?;
+        if self.minor >= 11 {
+            let localsplus = self.strings()?;
+            let kinds = self.bytes()?;
+            let filename = self.string()?;
+            let name = self.string()?;
+            let qualname = self.string()?;
+            let first_line = self.u32()?;
+            let linetable = self.bytes()?;
+            let exception_table = self.bytes()?;
+            // CO_FAST_LOCAL, CO_FAST_CELL, CO_FAST_FREE; a cell argument
+            // is both a local and a cell.
+            let of_kind = |bit: u8| -> Vec<String> {
+                localsplus
+                    .iter()
+                    .zip(&kinds)
+                    .filter(|&(_, &k)| k & bit != 0)
+                    .map(|(n, _)| n.clone())
+                    .collect()
+            };
+            return Ok(PyCode {
+                varnames: of_kind(0x20),
+                cellvars: of_kind(0x40),
+                freevars: of_kind(0x80),
+                localsplus,
+                name,
+                qualname,
+                filename,
+                first_line,
+                arg_count,
+                posonly_arg_count,
+                kwonly_arg_count,
+                stack_size,
+                flags,
+                bytecode,
+                consts,
+                names,
+                linetable,
+                exception_table,
+            });
+        }
+        let varnames = self.strings()?;
+        let freevars = self.strings()?;
+        let cellvars = self.strings()?;
+        let filename = self.string()?;
+        let name = self.string()?;
+        let first_line = self.u32()?;
+        let linetable = self.bytes()?;
+        let localsplus = varnames
+            .iter()
+            .chain(&cellvars)
+            .chain(&freevars)
+            .cloned()
+            .collect();
+        Ok(PyCode {
+            qualname: name.clone(),
+            name,
+            filename,
+            first_line,
+            arg_count,
+            posonly_arg_count,
+            kwonly_arg_count,
+            stack_size,
+            flags,
+            bytecode,
+            consts,
+            names,
+            varnames,
+            cellvars,
+            freevars,
+            localsplus,
+            linetable,
+            exception_table: Vec::new(),
+        })
+    }
+}
+
+/// Python-`repr`-like rendering, as `dis` shows constants.
+impl fmt::Display for PyObject {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        fn seq(f: &mut fmt::Formatter<'_>, items: &[PyObject]) -> fmt::Result {
+            for (i, o) in items.iter().enumerate() {
+                if i > 0 {
+                    f.write_str(", ")?;
+                }
+                write!(f, "{}", o)?;
+            }
+            Ok(())
+        }
+        match self {
+            PyObject::Null => f.write_str("<NULL>"),
+            PyObject::None => f.write_str("None"),
+            PyObject::Bool(true) => f.write_str("True"),
+            PyObject::Bool(false) => f.write_str("False"),
+            PyObject::StopIteration => f.write_str("StopIteration"),
+            PyObject::Ellipsis => f.write_str("Ellipsis"),
+            PyObject::Int(v) => write!(f, "{}", v),
+            PyObject::BigInt(s) => f.write_str(s),
+            PyObject::Float(v) => write!(f, "{:?}", v),
+            PyObject::Complex(re, im) => write!(f, "({:?}+{:?}j)", re, im),
+            PyObject::Bytes(b) => {
+                f.write_str("b'")?;
+                for &c in b {
+                    match c {
+                        b'\\' | b'\'' => write!(f, "\\{}", c as char)?,
+                        b'\n' => f.write_str("\\n")?,
+                        b'\r' => f.write_str("\\r")?,
+                        b'\t' => f.write_str("\\t")?,
+                        0x20..=0x7E => write!(f, "{}", c as char)?,
+                        _ => write!(f, "\\x{:02x}", c)?,
+                    }
+                }
+                f.write_str("'")
+            }
+            PyObject::Str(s) => {
+                f.write_str("'")?;
+                for c in s.chars() {
+                    match c {
+                        '\\' | '\'' => write!(f, "\\{}", c)?,
+                        '\n' => f.write_str("\\n")?,
+                        '\r' => f.write_str("\\r")?,
+                        '\t' => f.write_str("\\t")?,
+                        c if c.is_control() => write!(f, "\\x{:02x}", c as u32)?,
+                        c => write!(f, "{}", c)?,
+                    }
+                }
+                f.write_str("'")
+            }
+            PyObject::Tuple(items) if items.len() == 1 => write!(f, "({},)", items[0]),
+            PyObject::Tuple(items) => {
+                f.write_str("(")?;
+                seq(f, items)?;
+                f.write_str(")")
+            }
+            PyObject::List(items) => {
+                f.write_str("[")?;
+                seq(f, items)?;
+                f.write_str("]")
+            }
+            PyObject::Set(items) => {
+                f.write_str("{")?;
+                seq(f, items)?;
+                f.write_str("}")
+            }
+            PyObject::FrozenSet(items) => {
+                f.write_str("frozenset({")?;
+                seq(f, items)?;
+                f.write_str("})")
+            }
+            PyObject::Dict(items) => {
+                f.write_str("{")?;
+                for (i, (k, v)) in items.iter().enumerate() {
+                    if i > 0 {
+                        f.write_str(", ")?;
+                    }
+                    write!(f, "{}: {}", k, v)?;
+                }
+                f.write_str("}")
+            }
+            PyObject::Code(c) => write!(f, "<code object {}>", c.name),
+        }
+    }
+}
+
+/// One decoded instruction.
+#[derive(Debug, Clone, PartialEq)]
+pub struct PyInstruction {
+    /// Byte offset in `co_code`.
+    pub offset: u32,
+    pub opcode: u8,
+    pub opname: &'static str,
+    /// Argument with `EXTENDED_ARG` prefixes folded in; `None` for opcodes
+    /// that ignore it.
+    pub arg: Option<u32>,
+    /// Jump target as a byte offset.
+    pub target: Option<u32>,
+    /// What `dis` prints after the argument: a constant's repr, a name,
+    /// a comparison, or `to <offset>`.
+    pub argrepr: String,
+}
+
+impl fmt::Display for PyInstruction {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{:>6} {}", self.offset, self.opname)?;
+        if let Some(arg) = self.arg {
+            write!(f, " {}", arg)?;
+            if !self.argrepr.is_empty() {
+                write!(f, " ({})", self.argrepr)?;
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Disassemble one code object's bytecode (not its nested code objects).
+pub fn disassemble(code: &PyCode, minor: u8) -> Result<Vec<PyInstruction>, PycError> {
+    let table = pyc_opcodes::table(minor).ok_or(PycError::UnsupportedVersion(0))?;
+    let bc = &code.bytecode;
+    let mut out = Vec::new();
+    let mut extended = 0u32;
+    let mut p = 0usize;
+    while p + 1 < bc.len() {
+        let (offset, opcode, raw) = (p, bc[p], bc[p + 1] as u32);
+        p += 2;
+        // Inline caches (3.11+) are zeroed `CACHE` words after their owner.
+        if minor >= 11 && opcode == 0 {
+            continue;
+        }
+        let opname = table.names.get(opcode as usize).copied().unwrap_or("");
+        if opname.is_empty() {
+            return Err(PycError::UnknownOpcode(opcode));
+        }
+        let arg = (opcode >= table.have_argument).then_some(extended << 8 | raw);
+        extended = match (opname, arg) {
+            ("EXTENDED_ARG", Some(a)) => a,
+            _ => 0,
+        };
+        let mut next = p;
+        if minor >= 11 {
+            while next + 1 < bc.len() && bc[next] == 0 {
+                next += 2;
+            }
+        }
+        let (target, argrepr) = match arg {
+            Some(a) => describe(code, table, minor, opcode, opname, a, next as u32),
+            None => (None, String::new()),
+        };
+        out.push(PyInstruction {
+            offset: offset as u32,
+            opcode,
+            opname,
+            arg,
+            target,
+            argrepr,
+        });
+    }
+    Ok(out)
+}
+
+/// Jump target and `dis`-style argument text of an instruction.
+fn describe(
+    code: &PyCode,
+    table: &OpTable,
+    minor: u8,
+    opcode: u8,
+    opname: &str,
+    arg: u32,
+    next: u32,
+) -> (Option<u32>, String) {
+    let name_at = |names: &[String], i: u32| names.get(i as usize).cloned().unwrap_or_default();
+    // 3.10 counts jumps in code units rather than bytes.
+    let scale = if minor >= 10 { 2 } else { 1 };
+    if table.hasjrel.contains(&opcode) {
+        let delta = arg.wrapping_mul(scale);
+        let target = if opname.contains("BACKWARD") {
+            next.wrapping_sub(delta)
+        } else {
+            next.wrapping_add(delta)
+        };
+        return (Some(target), format!("to {}", target));
+    }
+    if table.hasjabs.contains(&opcode) {
+        let target = arg.wrapping_mul(scale);
+        return (Some(target), format!("to {}", target));
+    }
+    let text = if table.hasconst.contains(&opcode) {
+        code.consts
+            .get(arg as usize)
+            .map(|c| c.to_string())
+            .unwrap_or_default()
+    } else if table.hasname.contains(&opcode) {
+        // Low bits of these arguments are flags (push NULL, method call,
+        // super() form), not part of the name index.
+        let shift = match opname {
+            "LOAD_GLOBAL" if minor >= 11 => 1,
+            "LOAD_ATTR" if minor >= 12 => 1,
+            "LOAD_SUPER_ATTR" => 2,
+            _ => 0,
+        };
+        name_at(&code.names, arg >> shift)
+    } else if table.haslocal.contains(&opcode) {
+        if opname.matches("FAST").count() == 2 {
+            // 3.13 superinstructions pack two 4-bit slots.
+            format!(
+                "{}, {}",
+                name_at(&code.localsplus, arg >> 4),
+                name_at(&code.localsplus, arg & 15)
+            )
+        } else {
+            name_at(&code.localsplus, arg)
+        }
+    } else if table.hasfree.contains(&opcode) {
+        // Before 3.11 cell/free indexes start after the plain locals.
+        let base = if minor >= 11 {
+            0
+        } else {
+            code.varnames.len() as u32
+        };
+        name_at(&code.localsplus, base + arg)
+    } else if table.hascompare.contains(&opcode) {
+        let shift = match minor {
+            12 => 4,
+            13.. => 5,
+            _ => 0,
+        };
+        table
+            .cmp_op
+            .get((arg >> shift) as usize)
+            .map(|s| s.to_string())
+            .unwrap_or_default()
+    } else {
+        String::new()
+    };
+    (None, text)
+}
+
+/// Modules named by `IMPORT_NAME` in `code` and everything nested in it,
+/// sorted and deduplicated. Relative imports keep their leading dots;
+/// `from . import x` yields `.x`.
+pub fn imports(code: &PyCode, minor: u8) -> Vec<String> {
+    let mut out = Vec::new();
+    for c in code.code_objects() {
+        let Ok(ins) = disassemble(c, minor) else {
+            continue;
+        };
+        for (i, insn) in ins.iter().enumerate() {
+            let (true, Some(arg)) = (insn.opname == "IMPORT_NAME", insn.arg) else {
+                continue;
+            };
+            let name = c.names.get(arg as usize).cloned().unwrap_or_default();
+            // IMPORT_NAME pops fromlist and level, loaded just before it.
+            let loaded = |back: usize| {
+                let prev = ins.get(i.checked_sub(back)?)?;
+                (prev.opname == "LOAD_CONST")
+                    .then(|| c.consts.get(prev.arg? as usize))
+                    .flatten()
+            };
+            let level = match loaded(2) {
+                Some(PyObject::Int(l)) => (*l).clamp(0, 16) as usize,
+                _ => 0,
+            };
+            let dots = ".".repeat(level);
+            if !name.is_empty() {
+                out.push(format!("{}{}", dots, name));
+                continue;
+            }
+            match loaded(1) {
+                Some(PyObject::Tuple(from)) => out.extend(from.iter().filter_map(|o| match o {
+                    PyObject::Str(s) => Some(format!("{}{}", dots, s)),
+                    _ => None,
+                })),
+                _ => out.push(dots),
+            }
+        }
+    }
+    out.sort();
+    out.dedup();
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn code(bytecode: &[u8]) -> PyCode {
+        PyCode {
+            name: "f".into(),
+            qualname: "f".into(),
+            filename: "t.py".into(),
+            first_line: 1,
+            arg_count: 1,
+            posonly_arg_count: 0,
+            kwonly_arg_count: 0,
+            stack_size: 2,
+            flags: 0,
+            bytecode: bytecode.to_vec(),
+            consts: vec![PyObject::None, PyObject::Str("hi".into())],
+            names: vec!["print".into(), "os".into()],
+            varnames: vec!["x".into()],
+            cellvars: Vec::new(),
+            freevars: Vec::new(),
+            localsplus: vec!["x".into()],
+            linetable: Vec::new(),
+            exception_table: Vec::new(),
+        }
+    }
+
+    #[test]
+    fn maps_magic_numbers_to_versions() {
+        assert_eq!(version_from_magic(3379), Some(6));
+        assert_eq!(version_from_magic(3394), Some(7));
+        assert_eq!(version_from_magic(3413), Some(8));
+        assert_eq!(version_from_magic(3439), Some(10));
+        assert_eq!(version_from_magic(3495), Some(11));
+        assert_eq!(version_from_magic(3531), Some(12));
+        assert_eq!(version_from_magic(3571), Some(13));
+        assert_eq!(version_from_magic(62211), None); // 2.7
+    }
+
+    #[test]
+    fn reads_refs_and_small_containers() {
+        // (FLAG_REF|'Z' "os", 'r' 0, ')' 0, 'i' -1, 'l' 2**40, 'g' 1.5)
+        let mut m = vec![
+            b')',
+            6,
+            b'Z' | FLAG_REF,
+            2,
+            b'o',
+            b's',
+            b'r',
+            0,
+            0,
+            0,
+            0,
+            b')',
+            0,
+        ];
+        m.extend_from_slice(&[b'i', 0xFF, 0xFF, 0xFF, 0xFF]);
+        m.extend_from_slice(&[b'l', 3, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x04]);
+        m.push(b'g');
+        m.extend_from_slice(&1.5f64.to_le_bytes());
+        let mut r = Reader {
+            data: &m,
+            pos: 0,
+            minor: 11,
+            refs: Vec::new(),
+            depth: 0,
+        };
+        let obj = r.object().unwrap();
+        assert_eq!(
+            obj,
+            PyObject::Tuple(vec![
+                PyObject::Str("os".into()),
+                PyObject::Str("os".into()),
+                PyObject::Tuple(vec![]),
+                PyObject::Int(-1),
+                PyObject::Int(1 << 40),
+                PyObject::Float(1.5),
+            ])
+        );
+        assert_eq!(obj.to_string(), "('os', 'os', (), -1, 1099511627776, 1.5)");
+    }
+
+    #[test]
+    fn rejects_bad_input() {
+        assert_eq!(parse_pyc(b"\x7fELF"), Err(PycError::BadMagic));
+        assert_eq!(
+            parse_pyc(b"\x03\xf3\r\n\0\0\0\0"),
+            Err(PycError::UnsupportedVersion(62211))
+        );
+        // 3.11 header, then a tuple claiming more items than bytes remain.
+        let mut data = vec![0xA7, 0x0D, b'\r', b'\n'];
+        data.extend_from_slice(&[0; 12]);
+        data.extend_from_slice(&[b'(', 0xFF, 0xFF, 0, 0]);
+        assert!(matches!(parse_pyc(&data), Err(PycError::Truncated(_))));
+    }
+
+    #[test]
+    fn disassembles_across_jump_encodings() {
+        // 3.8: LOAD_FAST x; POP_JUMP_IF_FALSE 8 (absolute bytes);
+        // LOAD_CONST 'hi'; RETURN_VALUE; LOAD_CONST None; RETURN_VALUE
+        let c = code(&[124, 0, 114, 8, 100, 1, 83, 0, 100, 0, 83, 0]);
+        let ins = disassemble(&c, 8).unwrap();
+        assert_eq!(ins[1].target, Some(8));
+        assert_eq!(ins[2].argrepr, "'hi'");
+        assert_eq!(ins[0].to_string(), "     0 LOAD_FAST 0 (x)");
+
+        // 3.10: the same jump in code units.
+        let c = code(&[124, 0, 114, 4, 100, 1, 83, 0, 100, 0, 83, 0]);
+        assert_eq!(disassemble(&c, 10).unwrap()[1].target, Some(8));
+
+        // 3.12: LOAD_GLOBAL has four cache words and its name index is
+        // shifted past the push-NULL bit; JUMP_BACKWARD runs from the end
+        // of its own instruction.
+        let c = code(&[
+            151, 0, // RESUME
+            116, 3, 0, 0, 0, 0, 0, 0, 0, 0, // LOAD_GLOBAL NULL + os
+            140, 6, // JUMP_BACKWARD 6
+        ]);
+        let ins = disassemble(&c, 12).unwrap();
+        assert_eq!(ins.len(), 3);
+        assert_eq!(
+            (ins[1].opname, ins[1].argrepr.as_str()),
+            ("LOAD_GLOBAL", "os")
+        );
+        assert_eq!(ins[2].offset, 12);
+        assert_eq!(ins[2].target, Some(2));
+    }
+
+    #[test]
+    fn folds_extended_args() {
+        // 3.9: EXTENDED_ARG 1; JUMP_ABSOLUTE 2 -> 0x102
+        let c = code(&[144, 1, 113, 2]);
+        let ins = disassemble(&c, 9).unwrap();
+        assert_eq!(ins[1].arg, Some(0x102));
+        assert_eq!(ins[1].target, Some(0x102));
+    }
+}
@@ -0,0 +1,334 @@
+//! CPython opcode tables for 3.6 through 3.13.
+//!
+//! Transcribed from each release's `Lib/opcode.py`: opcode names, the
+//! first opcode that takes an argument, and the `has*` lists that say how
+//! the argument is interpreted. Specialized and `INSTRUMENTED_*` opcodes
+//! are left out; marshal writes code objects de-specialized, so they never
+//! appear in a `.pyc`.
+
+/// Opcode names and argument classes of one CPython minor version.
+pub(crate) struct OpTable {
+    /// Indexed by opcode; empty entries are unassigned.
+    pub names: &'static [&'static str],
+    /// Opcodes below this ignore their argument byte.
+    pub have_argument: u8,
+    /// Argument indexes `co_consts`.
+    pub hasconst: &'static [u8],
+    /// Argument indexes `co_names`.
+    pub hasname: &'static [u8],
+    /// Argument is a forward (or, named `*BACKWARD*`, backward) distance.
+    pub hasjrel: &'static [u8],
+    /// Argument is an absolute bytecode offset.
+    pub hasjabs: &'static [u8],
+    /// Argument indexes the fast locals.
+    pub haslocal: &'static [u8],
+    /// Argument indexes cell and free variables.
+    pub hasfree: &'static [u8],
+    /// Argument selects a `cmp_op` entry.
+    pub hascompare: &'static [u8],
+    pub cmp_op: &'static [&'static str],
+}
+
+/// Table for CPython 3.`minor`.
+pub(crate) fn table(minor: u8) -> Option<&'static OpTable> {
+    Some(match minor {
+        6 => &PY36,
+        7 => &PY37,
+        8 => &PY38,
+        9 => &PY39,
+        10 => &PY310,
+        11 => &PY311,
+        12 => &PY312,
+        13 => &PY313,
+        _ => return None,
+    })
+}
+
+#[rustfmt::skip]
+static PY36: OpTable = OpTable {
+    names: &[
+        "", "POP_TOP", "ROT_TWO", "ROT_THREE", "DUP_TOP", "DUP_TOP_TWO", "", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "BINARY_MATRIX_MULTIPLY", "INPLACE_MATRIX_MULTIPLY", "", "BINARY_POWER", "BINARY_MULTIPLY", "", "BINARY_MODULO", "BINARY_ADD",
+        "BINARY_SUBTRACT", "BINARY_SUBSCR", "BINARY_FLOOR_DIVIDE", "BINARY_TRUE_DIVIDE", "INPLACE_FLOOR_DIVIDE", "INPLACE_TRUE_DIVIDE", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "", "", "INPLACE_ADD",
+        "INPLACE_SUBTRACT", "INPLACE_MULTIPLY", "", "INPLACE_MODULO", "STORE_SUBSCR", "DELETE_SUBSCR", "BINARY_LSHIFT", "BINARY_RSHIFT",
+        "BINARY_AND", "BINARY_XOR", "BINARY_OR", "INPLACE_POWER", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "YIELD_FROM", "GET_AWAITABLE", "", "INPLACE_LSHIFT", "INPLACE_RSHIFT", "INPLACE_AND", "INPLACE_XOR", "INPLACE_OR",
+        "BREAK_LOOP", "WITH_CLEANUP_START", "WITH_CLEANUP_FINISH", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "POP_BLOCK",
+        "END_FINALLY", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "JUMP_ABSOLUTE", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "", "", "CONTINUE_LOOP",
+        "SETUP_LOOP", "SETUP_EXCEPT", "SETUP_FINALLY", "", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "STORE_ANNOTATION",
+        "", "", "RAISE_VARARGS", "CALL_FUNCTION", "MAKE_FUNCTION", "BUILD_SLICE", "", "LOAD_CLOSURE",
+        "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "", "", "CALL_FUNCTION_KW", "CALL_FUNCTION_EX", "SETUP_WITH",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "BUILD_LIST_UNPACK", "BUILD_MAP_UNPACK", "BUILD_MAP_UNPACK_WITH_CALL",
+        "BUILD_TUPLE_UNPACK", "BUILD_SET_UNPACK", "SETUP_ASYNC_WITH", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "BUILD_TUPLE_UNPACK_WITH_CALL",
+    ],
+    have_argument: 90,
+    hasconst: &[100],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 127],
+    hasjrel: &[93, 110, 120, 121, 122, 143, 154],
+    hasjabs: &[111, 112, 113, 114, 115, 119],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">=", "in", "not in", "is", "is not", "exception match", "BAD"],
+};
+
+#[rustfmt::skip]
+static PY37: OpTable = OpTable {
+    names: &[
+        "", "POP_TOP", "ROT_TWO", "ROT_THREE", "DUP_TOP", "DUP_TOP_TWO", "", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "BINARY_MATRIX_MULTIPLY", "INPLACE_MATRIX_MULTIPLY", "", "BINARY_POWER", "BINARY_MULTIPLY", "", "BINARY_MODULO", "BINARY_ADD",
+        "BINARY_SUBTRACT", "BINARY_SUBSCR", "BINARY_FLOOR_DIVIDE", "BINARY_TRUE_DIVIDE", "INPLACE_FLOOR_DIVIDE", "INPLACE_TRUE_DIVIDE", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "", "", "INPLACE_ADD",
+        "INPLACE_SUBTRACT", "INPLACE_MULTIPLY", "", "INPLACE_MODULO", "STORE_SUBSCR", "DELETE_SUBSCR", "BINARY_LSHIFT", "BINARY_RSHIFT",
+        "BINARY_AND", "BINARY_XOR", "BINARY_OR", "INPLACE_POWER", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "YIELD_FROM", "GET_AWAITABLE", "", "INPLACE_LSHIFT", "INPLACE_RSHIFT", "INPLACE_AND", "INPLACE_XOR", "INPLACE_OR",
+        "BREAK_LOOP", "WITH_CLEANUP_START", "WITH_CLEANUP_FINISH", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "POP_BLOCK",
+        "END_FINALLY", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "JUMP_ABSOLUTE", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "", "", "CONTINUE_LOOP",
+        "SETUP_LOOP", "SETUP_EXCEPT", "SETUP_FINALLY", "", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "",
+        "", "", "RAISE_VARARGS", "CALL_FUNCTION", "MAKE_FUNCTION", "BUILD_SLICE", "", "LOAD_CLOSURE",
+        "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "", "", "CALL_FUNCTION_KW", "CALL_FUNCTION_EX", "SETUP_WITH",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "BUILD_LIST_UNPACK", "BUILD_MAP_UNPACK", "BUILD_MAP_UNPACK_WITH_CALL",
+        "BUILD_TUPLE_UNPACK", "BUILD_SET_UNPACK", "SETUP_ASYNC_WITH", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "BUILD_TUPLE_UNPACK_WITH_CALL", "",
+        "LOAD_METHOD", "CALL_METHOD",
+    ],
+    have_argument: 90,
+    hasconst: &[100],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 160],
+    hasjrel: &[93, 110, 120, 121, 122, 143, 154],
+    hasjabs: &[111, 112, 113, 114, 115, 119],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">=", "in", "not in", "is", "is not", "exception match", "BAD"],
+};
+
+#[rustfmt::skip]
+static PY38: OpTable = OpTable {
+    names: &[
+        "", "POP_TOP", "ROT_TWO", "ROT_THREE", "DUP_TOP", "DUP_TOP_TWO", "ROT_FOUR", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "BINARY_MATRIX_MULTIPLY", "INPLACE_MATRIX_MULTIPLY", "", "BINARY_POWER", "BINARY_MULTIPLY", "", "BINARY_MODULO", "BINARY_ADD",
+        "BINARY_SUBTRACT", "BINARY_SUBSCR", "BINARY_FLOOR_DIVIDE", "BINARY_TRUE_DIVIDE", "INPLACE_FLOOR_DIVIDE", "INPLACE_TRUE_DIVIDE", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "BEGIN_FINALLY", "END_ASYNC_FOR", "INPLACE_ADD",
+        "INPLACE_SUBTRACT", "INPLACE_MULTIPLY", "", "INPLACE_MODULO", "STORE_SUBSCR", "DELETE_SUBSCR", "BINARY_LSHIFT", "BINARY_RSHIFT",
+        "BINARY_AND", "BINARY_XOR", "BINARY_OR", "INPLACE_POWER", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "YIELD_FROM", "GET_AWAITABLE", "", "INPLACE_LSHIFT", "INPLACE_RSHIFT", "INPLACE_AND", "INPLACE_XOR", "INPLACE_OR",
+        "", "WITH_CLEANUP_START", "WITH_CLEANUP_FINISH", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "POP_BLOCK",
+        "END_FINALLY", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "JUMP_ABSOLUTE", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "", "", "",
+        "", "", "SETUP_FINALLY", "", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "",
+        "", "", "RAISE_VARARGS", "CALL_FUNCTION", "MAKE_FUNCTION", "BUILD_SLICE", "", "LOAD_CLOSURE",
+        "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "", "", "CALL_FUNCTION_KW", "CALL_FUNCTION_EX", "SETUP_WITH",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "BUILD_LIST_UNPACK", "BUILD_MAP_UNPACK", "BUILD_MAP_UNPACK_WITH_CALL",
+        "BUILD_TUPLE_UNPACK", "BUILD_SET_UNPACK", "SETUP_ASYNC_WITH", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "BUILD_TUPLE_UNPACK_WITH_CALL", "",
+        "LOAD_METHOD", "CALL_METHOD", "CALL_FINALLY", "POP_FINALLY",
+    ],
+    have_argument: 90,
+    hasconst: &[100],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 160],
+    hasjrel: &[93, 110, 122, 143, 154, 162],
+    hasjabs: &[111, 112, 113, 114, 115],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">=", "in", "not in", "is", "is not", "exception match", "BAD"],
+};
+
+#[rustfmt::skip]
+static PY39: OpTable = OpTable {
+    names: &[
+        "", "POP_TOP", "ROT_TWO", "ROT_THREE", "DUP_TOP", "DUP_TOP_TWO", "ROT_FOUR", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "BINARY_MATRIX_MULTIPLY", "INPLACE_MATRIX_MULTIPLY", "", "BINARY_POWER", "BINARY_MULTIPLY", "", "BINARY_MODULO", "BINARY_ADD",
+        "BINARY_SUBTRACT", "BINARY_SUBSCR", "BINARY_FLOOR_DIVIDE", "BINARY_TRUE_DIVIDE", "INPLACE_FLOOR_DIVIDE", "INPLACE_TRUE_DIVIDE", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "RERAISE", "WITH_EXCEPT_START", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "", "END_ASYNC_FOR", "INPLACE_ADD",
+        "INPLACE_SUBTRACT", "INPLACE_MULTIPLY", "", "INPLACE_MODULO", "STORE_SUBSCR", "DELETE_SUBSCR", "BINARY_LSHIFT", "BINARY_RSHIFT",
+        "BINARY_AND", "BINARY_XOR", "BINARY_OR", "INPLACE_POWER", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "YIELD_FROM", "GET_AWAITABLE", "LOAD_ASSERTION_ERROR", "INPLACE_LSHIFT", "INPLACE_RSHIFT", "INPLACE_AND", "INPLACE_XOR", "INPLACE_OR",
+        "", "", "LIST_TO_TUPLE", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "POP_BLOCK",
+        "", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "JUMP_ABSOLUTE", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "IS_OP", "CONTAINS_OP", "",
+        "", "JUMP_IF_NOT_EXC_MATCH", "SETUP_FINALLY", "", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "",
+        "", "", "RAISE_VARARGS", "CALL_FUNCTION", "MAKE_FUNCTION", "BUILD_SLICE", "", "LOAD_CLOSURE",
+        "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "", "", "CALL_FUNCTION_KW", "CALL_FUNCTION_EX", "SETUP_WITH",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "", "", "",
+        "", "", "SETUP_ASYNC_WITH", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "", "",
+        "LOAD_METHOD", "CALL_METHOD", "LIST_EXTEND", "SET_UPDATE", "DICT_MERGE", "DICT_UPDATE",
+    ],
+    have_argument: 90,
+    hasconst: &[100],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 160],
+    hasjrel: &[93, 110, 122, 143, 154],
+    hasjabs: &[111, 112, 113, 114, 115, 121],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">="],
+};
+
+#[rustfmt::skip]
+static PY310: OpTable = OpTable {
+    names: &[
+        "", "POP_TOP", "ROT_TWO", "ROT_THREE", "DUP_TOP", "DUP_TOP_TWO", "ROT_FOUR", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "BINARY_MATRIX_MULTIPLY", "INPLACE_MATRIX_MULTIPLY", "", "BINARY_POWER", "BINARY_MULTIPLY", "", "BINARY_MODULO", "BINARY_ADD",
+        "BINARY_SUBTRACT", "BINARY_SUBSCR", "BINARY_FLOOR_DIVIDE", "BINARY_TRUE_DIVIDE", "INPLACE_FLOOR_DIVIDE", "INPLACE_TRUE_DIVIDE", "GET_LEN", "MATCH_MAPPING",
+        "MATCH_SEQUENCE", "MATCH_KEYS", "COPY_DICT_WITHOUT_KEYS", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "WITH_EXCEPT_START", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "", "END_ASYNC_FOR", "INPLACE_ADD",
+        "INPLACE_SUBTRACT", "INPLACE_MULTIPLY", "", "INPLACE_MODULO", "STORE_SUBSCR", "DELETE_SUBSCR", "BINARY_LSHIFT", "BINARY_RSHIFT",
+        "BINARY_AND", "BINARY_XOR", "BINARY_OR", "INPLACE_POWER", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "YIELD_FROM", "GET_AWAITABLE", "LOAD_ASSERTION_ERROR", "INPLACE_LSHIFT", "INPLACE_RSHIFT", "INPLACE_AND", "INPLACE_XOR", "INPLACE_OR",
+        "", "", "LIST_TO_TUPLE", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "POP_BLOCK",
+        "", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "ROT_N", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "JUMP_ABSOLUTE", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "IS_OP", "CONTAINS_OP", "RERAISE",
+        "", "JUMP_IF_NOT_EXC_MATCH", "SETUP_FINALLY", "", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "",
+        "", "GEN_START", "RAISE_VARARGS", "CALL_FUNCTION", "MAKE_FUNCTION", "BUILD_SLICE", "", "LOAD_CLOSURE",
+        "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "", "", "CALL_FUNCTION_KW", "CALL_FUNCTION_EX", "SETUP_WITH",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "", "", "",
+        "MATCH_CLASS", "", "SETUP_ASYNC_WITH", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "", "",
+        "LOAD_METHOD", "CALL_METHOD", "LIST_EXTEND", "SET_UPDATE", "DICT_MERGE", "DICT_UPDATE",
+    ],
+    have_argument: 90,
+    hasconst: &[100],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 160],
+    hasjrel: &[93, 110, 122, 143, 154],
+    hasjabs: &[111, 112, 113, 114, 115, 121],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">="],
+};
+
+#[rustfmt::skip]
+static PY311: OpTable = OpTable {
+    names: &[
+        "CACHE", "POP_TOP", "PUSH_NULL", "", "", "", "", "",
+        "", "NOP", "UNARY_POSITIVE", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "", "", "", "", "", "", "", "",
+        "", "BINARY_SUBSCR", "", "", "", "", "GET_LEN", "MATCH_MAPPING",
+        "MATCH_SEQUENCE", "MATCH_KEYS", "", "PUSH_EXC_INFO", "CHECK_EXC_MATCH", "CHECK_EG_MATCH", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "WITH_EXCEPT_START", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "BEFORE_WITH", "END_ASYNC_FOR", "",
+        "", "", "", "", "STORE_SUBSCR", "DELETE_SUBSCR", "", "",
+        "", "", "", "", "GET_ITER", "GET_YIELD_FROM_ITER", "PRINT_EXPR", "LOAD_BUILD_CLASS",
+        "", "", "LOAD_ASSERTION_ERROR", "RETURN_GENERATOR", "", "", "", "",
+        "", "", "LIST_TO_TUPLE", "RETURN_VALUE", "IMPORT_STAR", "SETUP_ANNOTATIONS", "YIELD_VALUE", "ASYNC_GEN_WRAP",
+        "PREP_RERAISE_STAR", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "SWAP", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "JUMP_IF_FALSE_OR_POP",
+        "JUMP_IF_TRUE_OR_POP", "", "POP_JUMP_FORWARD_IF_FALSE", "POP_JUMP_FORWARD_IF_TRUE", "LOAD_GLOBAL", "IS_OP", "CONTAINS_OP", "RERAISE",
+        "COPY", "", "BINARY_OP", "SEND", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "",
+        "POP_JUMP_FORWARD_IF_NOT_NONE", "POP_JUMP_FORWARD_IF_NONE", "RAISE_VARARGS", "GET_AWAITABLE", "MAKE_FUNCTION", "BUILD_SLICE", "JUMP_BACKWARD_NO_INTERRUPT", "MAKE_CELL",
+        "LOAD_CLOSURE", "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "JUMP_BACKWARD", "", "CALL_FUNCTION_EX", "",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "LOAD_CLASSDEREF", "COPY_FREE_VARS", "", "RESUME",
+        "MATCH_CLASS", "", "", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "", "",
+        "LOAD_METHOD", "", "LIST_EXTEND", "SET_UPDATE", "DICT_MERGE", "DICT_UPDATE", "PRECALL", "",
+        "", "", "", "CALL", "KW_NAMES", "POP_JUMP_BACKWARD_IF_NOT_NONE", "POP_JUMP_BACKWARD_IF_NONE", "POP_JUMP_BACKWARD_IF_FALSE",
+        "POP_JUMP_BACKWARD_IF_TRUE",
+    ],
+    have_argument: 90,
+    hasconst: &[100, 172],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 160],
+    hasjrel: &[93, 110, 111, 112, 114, 115, 123, 128, 129, 134, 140, 173, 174, 175, 176],
+    hasjabs: &[],
+    haslocal: &[124, 125, 126],
+    hasfree: &[135, 136, 137, 138, 139, 148],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">="],
+};
+
+#[rustfmt::skip]
+static PY312: OpTable = OpTable {
+    names: &[
+        "CACHE", "POP_TOP", "PUSH_NULL", "INTERPRETER_EXIT", "END_FOR", "END_SEND", "", "",
+        "", "NOP", "", "UNARY_NEGATIVE", "UNARY_NOT", "", "", "UNARY_INVERT",
+        "", "RESERVED", "", "", "", "", "", "",
+        "", "BINARY_SUBSCR", "BINARY_SLICE", "STORE_SLICE", "", "", "GET_LEN", "MATCH_MAPPING",
+        "MATCH_SEQUENCE", "MATCH_KEYS", "", "PUSH_EXC_INFO", "CHECK_EXC_MATCH", "CHECK_EG_MATCH", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "WITH_EXCEPT_START", "GET_AITER", "GET_ANEXT", "BEFORE_ASYNC_WITH", "BEFORE_WITH", "END_ASYNC_FOR", "CLEANUP_THROW",
+        "", "", "", "", "STORE_SUBSCR", "DELETE_SUBSCR", "", "",
+        "", "", "", "", "GET_ITER", "GET_YIELD_FROM_ITER", "", "LOAD_BUILD_CLASS",
+        "", "", "LOAD_ASSERTION_ERROR", "RETURN_GENERATOR", "", "", "", "",
+        "", "", "", "RETURN_VALUE", "", "SETUP_ANNOTATIONS", "", "LOAD_LOCALS",
+        "", "POP_EXCEPT", "STORE_NAME", "DELETE_NAME", "UNPACK_SEQUENCE", "FOR_ITER", "UNPACK_EX", "STORE_ATTR",
+        "DELETE_ATTR", "STORE_GLOBAL", "DELETE_GLOBAL", "SWAP", "LOAD_CONST", "LOAD_NAME", "BUILD_TUPLE", "BUILD_LIST",
+        "BUILD_SET", "BUILD_MAP", "LOAD_ATTR", "COMPARE_OP", "IMPORT_NAME", "IMPORT_FROM", "JUMP_FORWARD", "",
+        "", "", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_TRUE", "LOAD_GLOBAL", "IS_OP", "CONTAINS_OP", "RERAISE",
+        "COPY", "RETURN_CONST", "BINARY_OP", "SEND", "LOAD_FAST", "STORE_FAST", "DELETE_FAST", "LOAD_FAST_CHECK",
+        "POP_JUMP_IF_NOT_NONE", "POP_JUMP_IF_NONE", "RAISE_VARARGS", "GET_AWAITABLE", "MAKE_FUNCTION", "BUILD_SLICE", "JUMP_BACKWARD_NO_INTERRUPT", "MAKE_CELL",
+        "LOAD_CLOSURE", "LOAD_DEREF", "STORE_DEREF", "DELETE_DEREF", "JUMP_BACKWARD", "LOAD_SUPER_ATTR", "CALL_FUNCTION_EX", "LOAD_FAST_AND_CLEAR",
+        "EXTENDED_ARG", "LIST_APPEND", "SET_ADD", "MAP_ADD", "", "COPY_FREE_VARS", "YIELD_VALUE", "RESUME",
+        "MATCH_CLASS", "", "", "FORMAT_VALUE", "BUILD_CONST_KEY_MAP", "BUILD_STRING", "", "",
+        "", "", "LIST_EXTEND", "SET_UPDATE", "DICT_MERGE", "DICT_UPDATE", "", "",
+        "", "", "", "CALL", "KW_NAMES", "CALL_INTRINSIC_1", "CALL_INTRINSIC_2", "LOAD_FROM_DICT_OR_GLOBALS",
+        "LOAD_FROM_DICT_OR_DEREF",
+    ],
+    have_argument: 90,
+    hasconst: &[100, 121, 172],
+    hasname: &[90, 91, 95, 96, 97, 98, 101, 106, 108, 109, 116, 141, 175],
+    hasjrel: &[93, 110, 114, 115, 123, 128, 129, 134, 140],
+    hasjabs: &[],
+    haslocal: &[124, 125, 126, 127, 143],
+    hasfree: &[135, 136, 137, 138, 139, 176],
+    hascompare: &[107],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">="],
+};
+
+#[rustfmt::skip]
+static PY313: OpTable = OpTable {
+    names: &[
+        "CACHE", "BEFORE_ASYNC_WITH", "BEFORE_WITH", "", "BINARY_SLICE", "BINARY_SUBSCR", "CHECK_EG_MATCH", "CHECK_EXC_MATCH",
+        "CLEANUP_THROW", "DELETE_SUBSCR", "END_ASYNC_FOR", "END_FOR", "END_SEND", "EXIT_INIT_CHECK", "FORMAT_SIMPLE", "FORMAT_WITH_SPEC",
+        "GET_AITER", "RESERVED", "GET_ANEXT", "GET_ITER", "GET_LEN", "GET_YIELD_FROM_ITER", "INTERPRETER_EXIT", "LOAD_ASSERTION_ERROR",
+        "LOAD_BUILD_CLASS", "LOAD_LOCALS", "MAKE_FUNCTION", "MATCH_KEYS", "MATCH_MAPPING", "MATCH_SEQUENCE", "NOP", "POP_EXCEPT",
+        "POP_TOP", "PUSH_EXC_INFO", "PUSH_NULL", "RETURN_GENERATOR", "RETURN_VALUE", "SETUP_ANNOTATIONS", "STORE_SLICE", "STORE_SUBSCR",
+        "TO_BOOL", "UNARY_INVERT", "UNARY_NEGATIVE", "UNARY_NOT", "WITH_EXCEPT_START", "BINARY_OP", "BUILD_CONST_KEY_MAP", "BUILD_LIST",
+        "BUILD_MAP", "BUILD_SET", "BUILD_SLICE", "BUILD_STRING", "BUILD_TUPLE", "CALL", "CALL_FUNCTION_EX", "CALL_INTRINSIC_1",
+        "CALL_INTRINSIC_2", "CALL_KW", "COMPARE_OP", "CONTAINS_OP", "CONVERT_VALUE", "COPY", "COPY_FREE_VARS", "DELETE_ATTR",
+        "DELETE_DEREF", "DELETE_FAST", "DELETE_GLOBAL", "DELETE_NAME", "DICT_MERGE", "DICT_UPDATE", "ENTER_EXECUTOR", "EXTENDED_ARG",
+        "FOR_ITER", "GET_AWAITABLE", "IMPORT_FROM", "IMPORT_NAME", "IS_OP", "JUMP_BACKWARD", "JUMP_BACKWARD_NO_INTERRUPT", "JUMP_FORWARD",
+        "LIST_APPEND", "LIST_EXTEND", "LOAD_ATTR", "LOAD_CONST", "LOAD_DEREF", "LOAD_FAST", "LOAD_FAST_AND_CLEAR", "LOAD_FAST_CHECK",
+        "LOAD_FAST_LOAD_FAST", "LOAD_FROM_DICT_OR_DEREF", "LOAD_FROM_DICT_OR_GLOBALS", "LOAD_GLOBAL", "LOAD_NAME", "LOAD_SUPER_ATTR", "MAKE_CELL", "MAP_ADD",
+        "MATCH_CLASS", "POP_JUMP_IF_FALSE", "POP_JUMP_IF_NONE", "POP_JUMP_IF_NOT_NONE", "POP_JUMP_IF_TRUE", "RAISE_VARARGS", "RERAISE", "RETURN_CONST",
+        "SEND", "SET_ADD", "SET_FUNCTION_ATTRIBUTE", "SET_UPDATE", "STORE_ATTR", "STORE_DEREF", "STORE_FAST", "STORE_FAST_LOAD_FAST",
+        "STORE_FAST_STORE_FAST", "STORE_GLOBAL", "STORE_NAME", "SWAP", "UNPACK_EX", "UNPACK_SEQUENCE", "YIELD_VALUE", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "", "", "",
+        "", "", "", "", "", "RESUME",
+    ],
+    have_argument: 45,
+    hasconst: &[83, 103],
+    hasname: &[63, 66, 67, 74, 75, 82, 90, 91, 92, 93, 108, 113, 114],
+    hasjrel: &[72, 77, 78, 79, 97, 98, 99, 100, 104],
+    hasjabs: &[],
+    haslocal: &[65, 85, 86, 87, 88, 110, 111, 112],
+    hasfree: &[64, 84, 89, 94, 109],
+    hascompare: &[58],
+    cmp_op: &["<", "<=", "==", "!=", ">", ">="],
+};
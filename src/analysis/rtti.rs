@@ -0,0 +1,1319 @@
+//! C++ class hierarchy recovery from RTTI (Itanium and MSVC ABIs).
+//!
+//! Polymorphic C++ classes carry run-time type information that survives
+//! stripping: a type descriptor naming the class, a description of its
+//! bases, and vtables that point back at the descriptor. Both ABIs in use
+//! are parsed:
+//!
+//! - **Itanium** (GCC, Clang; ELF, Mach-O, MinGW): `std::type_info` objects
+//!   whose vptr is one of the `__cxxabiv1::{__class,__si_class,__vmi_class}_type_info`
+//!   vtables, and vtables laid out as `[offset_to_top][typeinfo][slots...]`.
+//!   The typeinfo vptrs are found through dynamic relocations or symbols;
+//!   static, stripped images fall back to a structural scan for
+//!   `{vptr, name}` pairs whose name demangles as a class type.
+//! - **MSVC** (PE): complete object locators, type descriptors
+//!   (`.?AVName@ns@@`), class hierarchy descriptors, and base class arrays,
+//!   with every vftable preceded by a pointer to its locator.
+//!
+//! The result is a `ClassHierarchy`: class names, direct bases with their
+//! offsets and virtual/public flags, vtables with their slots, and the
+//! virtual methods each class introduces or overrides. Itanium construction
+//! vtables (`_ZTC`) reference the base's typeinfo just like its own vtable
+//! does; they are dropped by symbol, and without symbols only the
+//! lowest-addressed vtable group of each class is kept.
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fmt;
+
+use serde::{Deserialize, Serialize};
+
+use crate::analysis::mapped::{MappedImage, Word};
+use crate::demangle::demangle_one;
+
+/// Most slots read from a single vtable.
+const MAX_SLOTS: usize = 1024;
+/// Most bases accepted from a single type descriptor.
+const MAX_BASES: u32 = 256;
+
+/// C++ ABI the RTTI was emitted for.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum RttiAbi {
+    Itanium,
+    Msvc,
+}
+
+impl fmt::Display for RttiAbi {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(match self {
+            RttiAbi::Itanium => "itanium",
+            RttiAbi::Msvc => "msvc",
+        })
+    }
+}
+
+/// A direct base class.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BaseClass {
+    /// Demangled class name
+    pub name: String,
+    /// VA of the base's type descriptor, when it is defined in this image
+    pub typeinfo: Option<u64>,
+    /// Offset of the base subobject (for Itanium virtual bases, the offset
+    /// of the vbase offset in the vtable)
+    pub offset: i64,
+    pub is_virtual: bool,
+    pub is_public: bool,
+}
+
+/// One vtable entry.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct VtableSlot {
+    /// Code address the slot points to (`None` for imported targets)
+    pub target: Option<u64>,
+    /// Demangled symbol of the target, if any (e.g. `__cxa_pure_virtual`)
+    pub symbol: Option<String>,
+}
+
+/// A vtable (MSVC: vftable) referencing a class's type descriptor.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Vtable {
+    /// Address point: the VA object vptrs hold, i.e. the first slot
+    pub va: u64,
+    /// Negated offset of the subobject using this vtable (0 for the primary)
+    pub offset_to_top: i64,
+    pub slots: Vec<VtableSlot>,
+}
+
+/// A virtual method a class introduces or overrides.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct VirtualMethod {
+    /// Entry point of the method
+    pub va: u64,
+    /// Demangled symbol when known, otherwise `Class::vfunc<slot>` for the
+    /// primary vtable and `Class::vfunc<slot>_<offset>` for the vtable of
+    /// the base subobject at `offset`
+    pub name: String,
+    /// Index into `CppClass::vtables`
+    pub vtable: usize,
+    /// Slot within that vtable
+    pub slot: usize,
+}
+
+/// A polymorphic class recovered from its RTTI.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct CppClass {
+    /// Demangled class name (e.g. `zoo::Dog`)
+    pub name: String,
+    /// Name as stored in the type descriptor (`N3zoo3DogE`, `.?AVDog@zoo@@`)
+    pub raw_name: String,
+    /// VA of the typeinfo object or type descriptor
+    pub typeinfo: u64,
+    pub bases: Vec<BaseClass>,
+    /// Primary vtable first, then secondary vtables
+    pub vtables: Vec<Vtable>,
+    pub methods: Vec<VirtualMethod>,
+}
+
+impl CppClass {
+    /// Declaration-style summary, e.g. `zoo::RoboDog : public zoo::Dog,
+    /// public zoo::Named`.
+    pub fn declaration(&self) -> String {
+        if self.bases.is_empty() {
+            return self.name.clone();
+        }
+        let bases: Vec<String> = self
+            .bases
+            .iter()
+            .map(|b| {
+                format!(
+                    "{}{}{}",
+                    if b.is_public { "public " } else { "private " },
+                    if b.is_virtual { "virtual " } else { "" },
+                    b.name
+                )
+            })
+            .collect();
+        format!("{} : {}", self.name, bases.join(", "))
+    }
+}
+
+/// Classes recovered from one image.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ClassHierarchy {
+    pub abi: RttiAbi,
+    /// Classes sorted by name
+    pub classes: Vec<CppClass>,
+}
+
+impl ClassHierarchy {
+    /// Class with the given demangled name.
+    pub fn class(&self, name: &str) -> Option<&CppClass> {
+        self.classes.iter().find(|c| c.name == name)
+    }
+
+    /// Virtual method names keyed by entry VA, for naming functions.
+    pub fn method_names(&self) -> BTreeMap<u64, &str> {
+        let mut out = BTreeMap::new();
+        for m in self.classes.iter().flat_map(|c| &c.methods) {
+            out.entry(m.va).or_insert(m.name.as_str());
+        }
+        out
+    }
+
+    /// Length of the longest base-class chain above `name` (0 for a root
+    /// class or one not in the hierarchy).
+    pub fn depth(&self, name: &str) -> usize {
+        self.depths().get(name).copied().unwrap_or(0)
+    }
+
+    /// `depth` of every class, keyed by name. Shared bases are walked once,
+    /// so deep diamond lattices stay linear.
+    pub fn depths(&self) -> HashMap<&str, usize> {
+        fn walk<'h>(
+            by_name: &HashMap<&str, &'h CppClass>,
+            name: &str,
+            memo: &mut HashMap<&'h str, usize>,
+        ) -> usize {
+            let Some(class) = by_name.get(name) else {
+                return 0;
+            };
+            if let Some(&d) = memo.get(class.name.as_str()) {
+                return d;
+            }
+            // A provisional 0 ends the walk on a cyclic (corrupt) hierarchy.
+            memo.insert(&class.name, 0);
+            let d = class
+                .bases
+                .iter()
+                .map(|b| 1 + walk(by_name, &b.name, memo))
+                .max()
+                .unwrap_or(0);
+            memo.insert(&class.name, d);
+            d
+        }
+        let by_name: HashMap<&str, &CppClass> =
+            self.classes.iter().map(|c| (c.name.as_str(), c)).collect();
+        let mut memo = HashMap::new();
+        for c in &self.classes {
+            walk(&by_name, &c.name, &mut memo);
+        }
+        memo
+    }
+
+    pub fn vtable_count(&self) -> usize {
+        self.classes.iter().map(|c| c.vtables.len()).sum()
+    }
+
+    pub fn method_count(&self) -> usize {
+        self.classes.iter().map(|c| c.methods.len()).sum()
+    }
+}
+
+/// Recover the C++ class hierarchy of an ELF, PE, or Mach-O image. Returns
+/// `None` when the image does not parse or carries no RTTI.
+pub fn recover_class_hierarchy(data: &[u8]) -> Option<ClassHierarchy> {
+    recover(&MappedImage::parse(data)?)
+}
+
+fn recover(mem: &MappedImage<'_>) -> Option<ClassHierarchy> {
+    let mut abi = RttiAbi::Itanium;
+    let mut classes = Vec::new();
+    if mem.is_pe {
+        classes = msvc_classes(mem);
+        abi = RttiAbi::Msvc;
+    }
+    if classes.is_empty() {
+        classes = itanium_classes(mem);
+        abi = RttiAbi::Itanium;
+    }
+    if classes.is_empty() {
+        return None;
+    }
+    attach_methods(&mut classes);
+    classes.sort_by(|a, b| a.name.cmp(&b.name).then(a.typeinfo.cmp(&b.typeinfo)));
+    Some(ClassHierarchy { abi, classes })
+}
+
+/// Demangled form of a symbol, or the symbol itself.
+fn demangled(raw: &str) -> String {
+    demangle_one(raw).map_or_else(|| raw.to_string(), |d| d.demangled)
+}
+
+/// Vtable slots from `va` up to the first entry that is not a code
+/// pointer or an imported function.
+fn vtable_slots(mem: &MappedImage<'_>, mut va: u64) -> Vec<VtableSlot> {
+    let mut out = Vec::new();
+    while out.len() < MAX_SLOTS {
+        let slot = match mem.word(va) {
+            Some(Word::Addr(a)) if a != 0 && mem.is_code(a) => VtableSlot {
+                target: Some(a),
+                symbol: mem.names.get(&a).map(|n| demangled(n)),
+            },
+            Some(Word::Import(name, 0)) if !name.starts_with("_ZT") => VtableSlot {
+                target: None,
+                symbol: Some(demangled(name)),
+            },
+            _ => break,
+        };
+        out.push(slot);
+        va += mem.ptr_size as u64;
+    }
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Itanium
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum TypeInfoKind {
+    /// `__class_type_info`: no bases
+    Class,
+    /// `__si_class_type_info`: one public, non-virtual base at offset 0
+    Single,
+    /// `__vmi_class_type_info`: anything else
+    Multiple,
+}
+
+const TYPE_INFO_VTABLES: [(&str, TypeInfoKind); 3] = [
+    ("_ZTVN10__cxxabiv117__class_type_infoE", TypeInfoKind::Class),
+    (
+        "_ZTVN10__cxxabiv120__si_class_type_infoE",
+        TypeInfoKind::Single,
+    ),
+    (
+        "_ZTVN10__cxxabiv121__vmi_class_type_infoE",
+        TypeInfoKind::Multiple,
+    ),
+];
+
+/// `__base_class_type_info::__offset_flags` bits.
+const BASE_IS_VIRTUAL: i64 = 0x1;
+const BASE_IS_PUBLIC: i64 = 0x2;
+const BASE_OFFSET_SHIFT: u32 = 8;
+
+struct TypeInfo {
+    va: u64,
+    kind: TypeInfoKind,
+    raw_name: String,
+}
+
+impl TypeInfo {
+    /// End of the object; `None` when it would wrap the address space.
+    fn end(&self, mem: &MappedImage<'_>) -> Option<u64> {
+        let ps = mem.ptr_size as u64;
+        let fields = self.va.checked_add(2 * ps)?;
+        match self.kind {
+            TypeInfoKind::Class => Some(fields),
+            TypeInfoKind::Single => fields.checked_add(ps),
+            TypeInfoKind::Multiple => {
+                let count = fields
+                    .checked_add(4)
+                    .and_then(|a| mem.u32(a))
+                    .unwrap_or(0)
+                    .min(MAX_BASES);
+                fields.checked_add(8 + count as u64 * 2 * ps)
+            }
+        }
+    }
+}
+
+/// `N3zoo3DogE` -> `zoo::Dog`; `None` for names that are not class types.
+fn itanium_type_name(raw: &str) -> Option<String> {
+    // Classes start with a source name, a nested name, a substitution
+    // (`St9exception`), or a local name; fundamental and pointer types
+    // (`i`, `PKc`) never have class typeinfo.
+    let first = raw.bytes().next()?;
+    if !(first.is_ascii_digit() || matches!(first, b'N' | b'S' | b'Z')) {
+        return None;
+    }
+    let d = demangle_one(&format!("_ZTS{}", raw))?;
+    d.demangled
+        .strip_prefix("typeinfo name for ")
+        .map(str::to_string)
+}
+
+/// Raw class name behind the name pointer at `va`.
+fn type_name_at(mem: &MappedImage<'_>, va: u64) -> Option<String> {
+    let s = mem.cstr(mem.pointer(va)?)?;
+    // GCC prefixes `*` to names of types with internal linkage.
+    let s = s.strip_prefix('*').unwrap_or(s);
+    itanium_type_name(s).map(|_| s.to_string())
+}
+
+fn typeinfo_kind(mem: &MappedImage<'_>, va: u64) -> Option<TypeInfoKind> {
+    let point = 2 * mem.ptr_size as i64;
+    match mem.word(va)? {
+        Word::Import(name, addend) if addend == point => TYPE_INFO_VTABLES
+            .iter()
+            .find(|(n, _)| *n == name)
+            .map(|(_, k)| *k),
+        Word::Addr(a) => TYPE_INFO_VTABLES
+            .iter()
+            .find(|(n, _)| {
+                mem.symbols
+                    .get(*n)
+                    .is_some_and(|(s, _)| s.wrapping_add(point as u64) == a)
+            })
+            .map(|(_, k)| *k),
+        _ => None,
+    }
+}
+
+fn find_typeinfos(mem: &MappedImage<'_>) -> Vec<TypeInfo> {
+    let ps = mem.ptr_size as u64;
+    let mut out = Vec::new();
+    for va in mem.scan(ps) {
+        let Some(kind) = typeinfo_kind(mem, va) else {
+            continue;
+        };
+        if let Some(raw_name) = type_name_at(mem, va + ps) {
+            out.push(TypeInfo { va, kind, raw_name });
+        }
+    }
+    if out.is_empty() {
+        out = guess_typeinfos(mem);
+    }
+    out
+}
+
+/// Typeinfo objects of a static, stripped image: `{vptr, name}` pairs whose
+/// name is a class type. Each distinct vptr is one `__cxxabiv1` class, so
+/// its kind is decided by majority over the shapes of the objects using it.
+fn guess_typeinfos(mem: &MappedImage<'_>) -> Vec<TypeInfo> {
+    let ps = mem.ptr_size as u64;
+    let mut candidates: Vec<(u64, u64, String)> = Vec::new();
+    for va in mem.scan(ps) {
+        let Some(vptr) = mem.pointer(va) else {
+            continue;
+        };
+        if vptr == 0 || !mem.is_data(vptr) {
+            continue;
+        }
+        if let Some(raw) = type_name_at(mem, va + ps) {
+            candidates.push((va, vptr, raw));
+        }
+    }
+    let at: HashSet<u64> = candidates.iter().map(|c| c.0).collect();
+    let mut votes: HashMap<u64, [usize; 3]> = HashMap::new();
+    for (va, vptr, _) in &candidates {
+        let shape = if vmi_bases(mem, *va).is_some_and(|b| {
+            b.iter()
+                .all(|(w, _)| matches!(w, Word::Addr(a) if at.contains(a)))
+        }) {
+            2
+        } else if va
+            .checked_add(2 * ps)
+            .and_then(|a| mem.pointer(a))
+            .is_some_and(|a| at.contains(&a))
+        {
+            1
+        } else {
+            0
+        };
+        votes.entry(*vptr).or_default()[shape] += 1;
+    }
+    candidates
+        .into_iter()
+        .map(|(va, vptr, raw_name)| {
+            let v = votes[&vptr];
+            let kind = if v[2] >= v[1] && v[2] > v[0] {
+                TypeInfoKind::Multiple
+            } else if v[1] > v[0] {
+                TypeInfoKind::Single
+            } else {
+                TypeInfoKind::Class
+            };
+            TypeInfo { va, kind, raw_name }
+        })
+        .collect()
+}
+
+/// `__vmi_class_type_info` base entries: `(base typeinfo, offset_flags)`.
+fn vmi_bases<'m>(mem: &'m MappedImage<'_>, va: u64) -> Option<Vec<(Word<'m>, i64)>> {
+    let ps = mem.ptr_size as u64;
+    let fields = va.checked_add(2 * ps)?;
+    let count = mem.u32(fields.checked_add(4)?)?;
+    if count == 0 || count > MAX_BASES {
+        return None;
+    }
+    (0..count as u64)
+        .map(|i| {
+            let entry = fields.checked_add(8 + i * 2 * ps)?;
+            let flags = mem.int(entry.checked_add(ps)?, mem.ptr_size)?;
+            Some((mem.word(entry)?, flags))
+        })
+        .collect()
+}
+
+fn itanium_base(mem: &MappedImage<'_>, names: &HashMap<u64, String>, w: Word<'_>) -> BaseClass {
+    let (name, typeinfo) = match w {
+        Word::Addr(a) => {
+            let name = names.get(&a).cloned().or_else(|| {
+                let sym = mem.names.get(&a)?;
+                Some(
+                    demangled(sym)
+                        .trim_start_matches("typeinfo for ")
+                        .to_string(),
+                )
+            });
+            (name.unwrap_or_else(|| format!("typeinfo_{:x}", a)), Some(a))
+        }
+        Word::Import(sym, _) => (
+            demangled(sym)
+                .trim_start_matches("typeinfo for ")
+                .to_string(),
+            None,
+        ),
+    };
+    BaseClass {
+        name,
+        typeinfo,
+        offset: 0,
+        is_virtual: false,
+        is_public: true,
+    }
+}
+
+fn itanium_classes(mem: &MappedImage<'_>) -> Vec<CppClass> {
+    let ps = mem.ptr_size as u64;
+    let typeinfos = find_typeinfos(mem);
+    let names: HashMap<u64, String> = typeinfos
+        .iter()
+        .map(|t| {
+            let name = itanium_type_name(&t.raw_name).unwrap_or_else(|| t.raw_name.clone());
+            (t.va, name)
+        })
+        .collect();
+
+    let mut classes: Vec<CppClass> = typeinfos
+        .iter()
+        .map(|t| {
+            let bases = match t.kind {
+                TypeInfoKind::Class => Vec::new(),
+                TypeInfoKind::Single => {
+                    t.va.checked_add(2 * ps)
+                        .and_then(|a| mem.word(a))
+                        .map(|w| vec![itanium_base(mem, &names, w)])
+                        .unwrap_or_default()
+                }
+                TypeInfoKind::Multiple => vmi_bases(mem, t.va)
+                    .unwrap_or_default()
+                    .into_iter()
+                    .map(|(w, flags)| BaseClass {
+                        offset: flags >> BASE_OFFSET_SHIFT,
+                        is_virtual: flags & BASE_IS_VIRTUAL != 0,
+                        is_public: flags & BASE_IS_PUBLIC != 0,
+                        ..itanium_base(mem, &names, w)
+                    })
+                    .collect(),
+            };
+            CppClass {
+                name: names[&t.va].clone(),
+                raw_name: t.raw_name.clone(),
+                typeinfo: t.va,
+                bases,
+                vtables: Vec::new(),
+                methods: Vec::new(),
+            }
+        })
+        .collect();
+
+    // Vtables: a typeinfo pointer preceded by a pointer-aligned,
+    // non-positive offset_to_top and followed by at least one slot. The
+    // typeinfo objects themselves and construction vtables are skipped.
+    let index: HashMap<u64, usize> = typeinfos
+        .iter()
+        .enumerate()
+        .map(|(i, t)| (t.va, i))
+        .collect();
+    let mut skip: Vec<(u64, u64)> = typeinfos
+        .iter()
+        .filter_map(|t| Some((t.va, t.end(mem)?)))
+        .collect();
+    skip.extend(
+        mem.symbols
+            .iter()
+            .filter(|(name, _)| name.starts_with("_ZTC"))
+            .map(|(_, (va, size))| (*va, va + size)),
+    );
+    for va in mem.scan(ps) {
+        let Some(Word::Addr(t)) = mem.word(va) else {
+            continue;
+        };
+        let Some(&i) = index.get(&t) else {
+            continue;
+        };
+        if skip.iter().any(|(lo, hi)| va >= *lo && va < *hi) {
+            continue;
+        }
+        let Some(ott_va) = va.checked_sub(ps) else {
+            continue;
+        };
+        if mem.relocs.contains_key(&ott_va) {
+            continue;
+        }
+        let Some(offset_to_top) = mem.int(ott_va, mem.ptr_size) else {
+            continue;
+        };
+        if offset_to_top > 0 || offset_to_top % ps as i64 != 0 {
+            continue;
+        }
+        let slots = vtable_slots(mem, va + ps);
+        if !slots.is_empty() {
+            classes[i].vtables.push(Vtable {
+                va: va + ps,
+                offset_to_top,
+                slots,
+            });
+        }
+    }
+
+    // One group per class: a primary vtable and the secondary vtables that
+    // follow it.
+    for c in &mut classes {
+        c.vtables.sort_by_key(|v| v.va);
+        let start = c.vtables.iter().position(|v| v.offset_to_top == 0);
+        let Some(start) = start else {
+            continue;
+        };
+        let len = c.vtables[start + 1..]
+            .iter()
+            .take_while(|v| v.offset_to_top != 0)
+            .count();
+        c.vtables = c.vtables.drain(start..=start + len).collect();
+    }
+    classes
+}
+
+// ---------------------------------------------------------------------------
+// MSVC
+
+/// `RTTIBaseClassDescriptor::attributes` bits that make a base non-public.
+const BCD_NOTVISIBLE: u32 = 0x01;
+const BCD_PRIVORPROTBASE: u32 = 0x08;
+
+/// Locator signature on x64, where RTTI pointers are image-relative.
+const COL_SIG_REV1: u32 = 1;
+
+struct Locator {
+    va: u64,
+    offset: u32,
+    type_descriptor: u64,
+    hierarchy: u64,
+}
+
+struct BaseDescriptor {
+    type_descriptor: u64,
+    contained: u32,
+    mdisp: i32,
+    pdisp: i32,
+    attributes: u32,
+}
+
+/// An RTTI pointer: image-relative on x64, absolute on x86.
+fn msvc_ptr(mem: &MappedImage<'_>, va: u64) -> Option<u64> {
+    let v = mem.u32(va)? as u64;
+    Some(if mem.ptr_size == 8 {
+        mem.image_base + v
+    } else {
+        v
+    })
+}
+
+/// Raw name of the type descriptor at `va` (`.?AVDog@zoo@@`).
+fn msvc_td_name<'a>(mem: &MappedImage<'a>, va: u64) -> Option<&'a str> {
+    let name = mem.cstr(va + 2 * mem.ptr_size as u64)?;
+    (name.starts_with(".?AV") || name.starts_with(".?AU")).then_some(name)
+}
+
+fn msvc_locators(mem: &MappedImage<'_>) -> Vec<Locator> {
+    let mut out = Vec::new();
+    for va in mem.scan(4) {
+        let Some(sig) = mem.u32(va) else {
+            continue;
+        };
+        let located = if mem.ptr_size == 8 {
+            // pSelf: the locator's own RVA.
+            sig == COL_SIG_REV1
+                && va
+                    .checked_sub(mem.image_base)
+                    .is_some_and(|rva| mem.u32(va + 20) == Some(rva as u32))
+        } else {
+            sig == 0
+        };
+        if !located {
+            continue;
+        }
+        let (Some(td), Some(chd)) = (msvc_ptr(mem, va + 12), msvc_ptr(mem, va + 16)) else {
+            continue;
+        };
+        if msvc_td_name(mem, td).is_none() || mem.u32(chd) != Some(0) {
+            continue;
+        }
+        out.push(Locator {
+            va,
+            offset: mem.u32(va + 4).unwrap_or(0),
+            type_descriptor: td,
+            hierarchy: chd,
+        });
+    }
+    out
+}
+
+fn msvc_base_descriptor(mem: &MappedImage<'_>, va: u64) -> Option<BaseDescriptor> {
+    Some(BaseDescriptor {
+        type_descriptor: msvc_ptr(mem, va)?,
+        contained: mem.u32(va + 4)?,
+        mdisp: mem.u32(va + 8)? as i32,
+        pdisp: mem.u32(va + 12)? as i32,
+        attributes: mem.u32(va + 20)?,
+    })
+}
+
+/// Base class array of a class hierarchy descriptor: the class itself,
+/// then each base followed by the bases it contains.
+fn msvc_base_array(mem: &MappedImage<'_>, chd: u64) -> Vec<BaseDescriptor> {
+    let (Some(count), Some(array)) = (mem.u32(chd + 8), msvc_ptr(mem, chd + 12)) else {
+        return Vec::new();
+    };
+    (0..count.min(MAX_BASES) as u64)
+        .map_while(|i| msvc_base_descriptor(mem, msvc_ptr(mem, array + 4 * i)?))
+        .collect()
+}
+
+/// Entries `i..end` of a base class array: base `i` and the bases it
+/// contains.
+fn msvc_subtree(entries: &[BaseDescriptor], i: usize) -> &[BaseDescriptor] {
+    let end = (i + 1 + entries[i].contained as usize).min(entries.len());
+    &entries[i..end]
+}
+
+/// Direct bases of `entries[0]`. Virtual bases reachable through an
+/// earlier base are indirect.
+fn msvc_direct_bases(mem: &MappedImage<'_>, entries: &[BaseDescriptor]) -> Vec<BaseClass> {
+    let mut out = Vec::new();
+    let mut covered: HashSet<u64> = HashSet::new();
+    let mut i = 1;
+    while i < entries.len() {
+        let e = &entries[i];
+        let is_virtual = e.pdisp != -1;
+        if !(is_virtual && covered.contains(&e.type_descriptor)) {
+            out.push(BaseClass {
+                name: msvc_class_name(mem, e.type_descriptor).0,
+                typeinfo: Some(e.type_descriptor),
+                offset: e.mdisp as i64,
+                is_virtual,
+                is_public: e.attributes & (BCD_NOTVISIBLE | BCD_PRIVORPROTBASE) == 0,
+            });
+        }
+        let sub = msvc_subtree(entries, i);
+        covered.extend(sub.iter().map(|b| b.type_descriptor));
+        i += sub.len();
+    }
+    out
+}
+
+/// `(demangled, raw)` name of the type descriptor at `td`.
+fn msvc_class_name(mem: &MappedImage<'_>, td: u64) -> (String, String) {
+    let raw = msvc_td_name(mem, td).unwrap_or_default();
+    let name = msvc_type_name(raw).unwrap_or_else(|| raw.to_string());
+    (name, raw.to_string())
+}
+
+fn msvc_class(mem: &MappedImage<'_>, td: u64, bases: Vec<BaseClass>) -> CppClass {
+    let (name, raw_name) = msvc_class_name(mem, td);
+    CppClass {
+        name,
+        raw_name,
+        typeinfo: td,
+        bases,
+        vtables: Vec::new(),
+        methods: Vec::new(),
+    }
+}
+
+fn msvc_classes(mem: &MappedImage<'_>) -> Vec<CppClass> {
+    let ps = mem.ptr_size as u64;
+    let locators = msvc_locators(mem);
+    let by_va: HashMap<u64, usize> = locators
+        .iter()
+        .enumerate()
+        .map(|(i, l)| (l.va, i))
+        .collect();
+
+    let mut classes: BTreeMap<u64, CppClass> = BTreeMap::new();
+    let mut arrays = Vec::new();
+    for l in &locators {
+        let entries = msvc_base_array(mem, l.hierarchy);
+        classes.entry(l.type_descriptor).or_insert_with(|| {
+            msvc_class(mem, l.type_descriptor, msvc_direct_bases(mem, &entries))
+        });
+        arrays.push(entries);
+    }
+    // Bases without a locator of their own (abstract or non-polymorphic
+    // classes, or ones whose vftables were optimized away) get their bases
+    // from the arrays that list them.
+    for entries in &arrays {
+        for i in 1..entries.len() {
+            let td = entries[i].type_descriptor;
+            if !classes.contains_key(&td) && msvc_td_name(mem, td).is_some() {
+                let bases = msvc_direct_bases(mem, msvc_subtree(entries, i));
+                classes.insert(td, msvc_class(mem, td, bases));
+            }
+        }
+    }
+
+    // Each vftable is preceded by a pointer to its locator.
+    for va in mem.scan(ps) {
+        let Some(&i) = mem.pointer(va).and_then(|a| by_va.get(&a)) else {
+            continue;
+        };
+        let slots = vtable_slots(mem, va + ps);
+        if slots.is_empty() {
+            continue;
+        }
+        let l = &locators[i];
+        if let Some(c) = classes.get_mut(&l.type_descriptor) {
+            c.vtables.push(Vtable {
+                va: va + ps,
+                offset_to_top: -(l.offset as i64),
+                slots,
+            });
+        }
+    }
+    let mut out: Vec<CppClass> = classes.into_values().collect();
+    for c in &mut out {
+        c.vtables
+            .sort_by_key(|v| (std::cmp::Reverse(v.offset_to_top), v.va));
+    }
+    out
+}
+
+/// Demangles an MSVC type descriptor name (`.?AVDog@zoo@@` -> `zoo::Dog`).
+/// Returns `None` for encodings outside the supported subset (class
+/// templates over class, enum, pointer, primitive, and integer arguments).
+pub(crate) fn msvc_type_name(raw: &str) -> Option<String> {
+    let mut p = MsvcName {
+        s: raw.strip_prefix(".?A")?.as_bytes(),
+        pos: 0,
+        names: Vec::new(),
+    };
+    let name = p.class_type()?;
+    (p.pos == p.s.len()).then_some(name)
+}
+
+struct MsvcName<'s> {
+    s: &'s [u8],
+    pos: usize,
+    /// Name back-references (`0`-`9`)
+    names: Vec<String>,
+}
+
+impl MsvcName<'_> {
+    fn next(&mut self) -> Option<u8> {
+        let b = *self.s.get(self.pos)?;
+        self.pos += 1;
+        Some(b)
+    }
+
+    fn eat(&mut self, b: u8) -> bool {
+        let hit = self.s.get(self.pos) == Some(&b);
+        if hit {
+            self.pos += 1;
+        }
+        hit
+    }
+
+    /// `V` (class), `U` (struct), or `T` (union) and a qualified name.
+    fn class_type(&mut self) -> Option<String> {
+        match self.next()? {
+            b'V' | b'U' | b'T' => self.qualified(),
+            _ => None,
+        }
+    }
+
+    /// Name fragments, innermost first, up to the closing `@`.
+    fn qualified(&mut self) -> Option<String> {
+        let mut parts = vec![self.fragment()?];
+        while !self.eat(b'@') {
+            parts.push(self.fragment()?);
+        }
+        parts.reverse();
+        Some(parts.join("::"))
+    }
+
+    fn fragment(&mut self) -> Option<String> {
+        let b = *self.s.get(self.pos)?;
+        if b.is_ascii_digit() {
+            self.pos += 1;
+            return self.names.get((b - b'0') as usize).cloned();
+        }
+        let name = if self.s[self.pos..].starts_with(b"?$") {
+            self.pos += 2;
+            // Template arguments have their own back-reference table.
+            let outer = std::mem::take(&mut self.names);
+            let name = self.template();
+            self.names = outer;
+            name?
+        } else {
+            self.ident()?
+        };
+        if self.names.len() < 10 {
+            self.names.push(name.clone());
+        }
+        Some(name)
+    }
+
+    fn ident(&mut self) -> Option<String> {
+        let len = self.s[self.pos..].iter().position(|&b| b == b'@')?;
+        if len == 0 {
+            return None;
+        }
+        let id = std::str::from_utf8(&self.s[self.pos..self.pos + len]).ok()?;
+        self.pos += len + 1;
+        Some(id.to_string())
+    }
+
+    fn template(&mut self) -> Option<String> {
+        let name = self.ident()?;
+        self.names.push(name.clone());
+        let mut args = Vec::new();
+        while !self.eat(b'@') {
+            args.push(self.arg()?);
+        }
+        Some(format!("{}<{}>", name, args.join(",")))
+    }
+
+    fn arg(&mut self) -> Option<String> {
+        let prim = match self.next()? {
+            b'V' | b'U' | b'T' => return self.qualified(),
+            b'W' => {
+                self.next()?; // enum underlying type
+                return self.qualified();
+            }
+            b'P' => {
+                self.eat(b'E'); // __ptr64
+                let constness = match self.next()? {
+                    b'A' => "",
+                    b'B' => "const ",
+                    _ => return None,
+                };
+                return Some(format!("{}{} *", constness, self.arg()?));
+            }
+            b'$' => {
+                return match self.next()? {
+                    b'0' => self.number().map(|n| n.to_string()),
+                    _ => None,
+                }
+            }
+            b'_' => match self.next()? {
+                b'N' => "bool",
+                b'J' => "__int64",
+                b'K' => "unsigned __int64",
+                b'W' => "wchar_t",
+                _ => return None,
+            },
+            b'C' => "signed char",
+            b'D' => "char",
+            b'E' => "unsigned char",
+            b'F' => "short",
+            b'G' => "unsigned short",
+            b'H' => "int",
+            b'I' => "unsigned int",
+            b'J' => "long",
+            b'K' => "unsigned long",
+            b'M' => "float",
+            b'N' => "double",
+            b'O' => "long double",
+            _ => return None,
+        };
+        Some(prim.to_string())
+    }
+
+    /// Encoded integer: `0`-`9` for 1-10, else hex digits `A`-`P` ended by
+    /// `@`; a leading `?` negates.
+    fn number(&mut self) -> Option<i64> {
+        let negative = self.eat(b'?');
+        let first = self.next()?;
+        let value = if first.is_ascii_digit() {
+            (first - b'0') as i64 + 1
+        } else {
+            let mut v = 0i64;
+            let mut c = first;
+            while c != b'@' {
+                if !(b'A'..=b'P').contains(&c) {
+                    return None;
+                }
+                v = v.checked_mul(16)? + (c - b'A') as i64;
+                c = self.next()?;
+            }
+            v
+        };
+        Some(if negative { -value } else { value })
+    }
+}
+
+// ---------------------------------------------------------------------------
+// Methods
+
+/// Attach to each class the vtable targets none of its ancestors' vtables
+/// hold: the virtual methods (and thunks) it introduces or overrides.
+fn attach_methods(classes: &mut [CppClass]) {
+    let index: HashMap<u64, usize> = classes
+        .iter()
+        .enumerate()
+        .map(|(i, c)| (c.typeinfo, i))
+        .collect();
+    let mut all = Vec::with_capacity(classes.len());
+    for i in 0..classes.len() {
+        let mut ancestors = HashSet::new();
+        let mut stack = vec![i];
+        while let Some(j) = stack.pop() {
+            for b in &classes[j].bases {
+                if let Some(&k) = b.typeinfo.and_then(|t| index.get(&t)) {
+                    if k != i && ancestors.insert(k) {
+                        stack.push(k);
+                    }
+                }
+            }
+        }
+        let inherited: HashSet<u64> = ancestors
+            .iter()
+            .flat_map(|&k| &classes[k].vtables)
+            .flat_map(|v| &v.slots)
+            .filter_map(|s| s.target)
+            .collect();
+
+        let c = &classes[i];
+        let mut seen = HashSet::new();
+        let mut methods = Vec::new();
+        for (vi, vt) in c.vtables.iter().enumerate() {
+            for (si, slot) in vt.slots.iter().enumerate() {
+                let Some(va) = slot.target else {
+                    continue;
+                };
+                if inherited.contains(&va) || !seen.insert(va) {
+                    continue;
+                }
+                let name = slot.symbol.clone().unwrap_or_else(|| {
+                    if vi == 0 {
+                        format!("{}::vfunc{}", c.name, si)
+                    } else {
+                        format!("{}::vfunc{}_{}", c.name, si, -vt.offset_to_top)
+                    }
+                });
+                methods.push(VirtualMethod {
+                    va,
+                    name,
+                    vtable: vi,
+                    slot: si,
+                });
+            }
+        }
+        all.push(methods);
+    }
+    for (c, methods) in classes.iter_mut().zip(all) {
+        c.methods = methods;
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::analysis::mapped::{Region, Reloc};
+
+    const CODE: u64 = 0x1000;
+    const DATA: u64 = 0x4000;
+
+    /// Synthetic image: 0x100 bytes of code at `CODE` and a data region at
+    /// `DATA` assembled by the test.
+    struct Image {
+        code: Vec<u8>,
+        data: Vec<u8>,
+        ptr_size: usize,
+    }
+
+    impl Image {
+        fn new(ptr_size: usize) -> Self {
+            Self {
+                code: vec![0xc3; 0x100],
+                data: vec![0; 0x800],
+                ptr_size,
+            }
+        }
+
+        fn put(&mut self, va: u64, value: u64, size: usize) {
+            let off = (va - DATA) as usize;
+            self.data[off..off + size].copy_from_slice(&value.to_le_bytes()[..size]);
+        }
+
+        fn ptr(&mut self, va: u64, value: u64) {
+            self.put(va, value, self.ptr_size);
+        }
+
+        fn str(&mut self, va: u64, s: &str) {
+            let off = (va - DATA) as usize;
+            self.data[off..off + s.len()].copy_from_slice(s.as_bytes());
+        }
+
+        fn memory(&self) -> MappedImage<'_> {
+            let mut mem = MappedImage::new(self.ptr_size, false);
+            mem.regions.push(Region {
+                va: CODE,
+                bytes: &self.code,
+                exec: true,
+            });
+            mem.regions.push(Region {
+                va: DATA,
+                bytes: &self.data,
+                exec: false,
+            });
+            mem
+        }
+    }
+
+    fn import(mem: &mut MappedImage<'_>, va: u64, kind: usize) {
+        let addend = 2 * mem.ptr_size as i64;
+        let name = TYPE_INFO_VTABLES[kind].0.to_string();
+        mem.relocs.insert(va, Reloc::Import(name, addend));
+    }
+
+    /// A : (none), B : A, C : A, virtual B. Vtables follow the typeinfo.
+    fn itanium_image() -> (Image, [u64; 3]) {
+        let mut img = Image::new(8);
+        let (a, b, c) = (DATA + 0x100, DATA + 0x120, DATA + 0x140);
+        img.str(DATA + 0x10, "1A");
+        img.str(DATA + 0x20, "1B");
+        img.str(DATA + 0x30, "1C");
+        img.ptr(a + 8, DATA + 0x10);
+        img.ptr(b + 8, DATA + 0x20);
+        img.ptr(b + 16, a);
+        img.ptr(c + 8, DATA + 0x30);
+        img.put(c + 16, 0, 4); // flags
+        img.put(c + 20, 2, 4); // base_count
+        img.ptr(c + 24, a);
+        img.ptr(c + 32, 2); // public, offset 0
+        img.ptr(c + 40, b);
+        img.ptr(c + 48, ((-24i64 << 8) | 3) as u64); // public virtual
+
+        // vtable for A: [0][&A][0x1000][0x1010]
+        img.ptr(DATA + 0x200, 0);
+        img.ptr(DATA + 0x208, a);
+        img.ptr(DATA + 0x210, CODE);
+        img.ptr(DATA + 0x218, CODE + 0x10);
+        // vtable for B: overrides slot 1, adds slot 2
+        img.ptr(DATA + 0x228, 0);
+        img.ptr(DATA + 0x230, b);
+        img.ptr(DATA + 0x238, CODE);
+        img.ptr(DATA + 0x240, CODE + 0x20);
+        img.ptr(DATA + 0x248, CODE + 0x30);
+        // vtable for C: primary, then the B-in-C secondary at -8
+        img.ptr(DATA + 0x258, 0);
+        img.ptr(DATA + 0x260, c);
+        img.ptr(DATA + 0x268, CODE + 0x40);
+        img.ptr(DATA + 0x270, (-8i64) as u64);
+        img.ptr(DATA + 0x278, c);
+        img.ptr(DATA + 0x280, CODE + 0x50);
+        (img, [a, b, c])
+    }
+
+    #[test]
+    fn itanium_hierarchy_from_imported_typeinfo_vtables() {
+        let (img, [a, b, c]) = itanium_image();
+        let mut mem = img.memory();
+        import(&mut mem, a, 0);
+        import(&mut mem, b, 1);
+        import(&mut mem, c, 2);
+        let h = recover(&mem).unwrap();
+        assert_eq!(h.abi, RttiAbi::Itanium);
+        let names: Vec<_> = h.classes.iter().map(|c| c.name.as_str()).collect();
+        assert_eq!(names, ["A", "B", "C"]);
+
+        let class_b = h.class("B").unwrap();
+        assert_eq!(class_b.bases.len(), 1);
+        assert_eq!(class_b.bases[0].name, "A");
+        assert_eq!(class_b.bases[0].typeinfo, Some(a));
+        assert_eq!(class_b.vtables.len(), 1);
+        assert_eq!(class_b.vtables[0].va, DATA + 0x238);
+        // Slot 0 is inherited from A.
+        let vas: Vec<u64> = class_b.methods.iter().map(|m| m.va).collect();
+        assert_eq!(vas, [CODE + 0x20, CODE + 0x30]);
+        assert_eq!(class_b.methods[0].name, "B::vfunc1");
+
+        let class_c = h.class("C").unwrap();
+        assert_eq!(class_c.declaration(), "C : public A, public virtual B");
+        assert_eq!(class_c.bases[1].offset, -24);
+        assert_eq!(class_c.vtables.len(), 2);
+        assert_eq!(class_c.vtables[1].offset_to_top, -8);
+        assert_eq!(class_c.methods[1].name, "C::vfunc0_8");
+        assert_eq!(h.vtable_count(), 4);
+        assert_eq!(h.method_names().get(&(CODE + 0x40)), Some(&"C::vfunc0"));
+        assert_eq!((h.depth("A"), h.depth("B"), h.depth("C")), (0, 1, 2));
+    }
+
+    #[test]
+    fn itanium_symbols_name_methods_and_drop_construction_vtables() {
+        let (img, [a, b, c]) = itanium_image();
+        let mut mem = img.memory();
+        import(&mut mem, a, 0);
+        import(&mut mem, b, 1);
+        import(&mut mem, c, 2);
+        mem.add_symbol("_ZN1B3barEv", CODE + 0x30, 0x10);
+        // Pretend C's vtable group is B-in-C's construction vtable.
+        mem.add_symbol("_ZTC1C0_1B", DATA + 0x250, 0x38);
+        let h = recover(&mem).unwrap();
+        let class_b = h.class("B").unwrap();
+        assert_eq!(class_b.methods[1].name, "B::bar()");
+        assert!(h.class("C").unwrap().vtables.is_empty());
+    }
+
+    #[test]
+    fn itanium_static_image_falls_back_to_structure() {
+        // No relocations or symbols: the typeinfo vptrs are plain pointers
+        // to three distinct (unnamed) vtables.
+        let (mut img, [a, b, c]) = itanium_image();
+        img.ptr(a, DATA + 0x700);
+        img.ptr(b, DATA + 0x710);
+        img.ptr(c, DATA + 0x720);
+        let h = recover(&img.memory()).unwrap();
+        assert_eq!(h.class("B").unwrap().bases[0].name, "A");
+        let class_c = h.class("C").unwrap();
+        assert_eq!(class_c.bases.len(), 2);
+        assert!(class_c.bases[1].is_virtual);
+    }
+
+    #[test]
+    fn non_class_typeinfo_names_are_rejected() {
+        assert_eq!(itanium_type_name("i"), None);
+        assert_eq!(itanium_type_name("PKc"), None);
+    }
+
+    #[test]
+    fn msvc_x64_locators_and_base_arrays() {
+        const BASE: u64 = 0x1_4000_0000;
+        let mut img = Image::new(8);
+        let rva = |va: u64| va - BASE;
+        let data = BASE + DATA;
+        // Type descriptors: {pVFTable, spare, name}.
+        let (td_a, td_b) = (data + 0x100, data + 0x140);
+        img.str(td_a + 16 - BASE, ".?AVAnimal@zoo@@");
+        img.str(td_b + 16 - BASE, ".?AVDog@zoo@@");
+        // Base class descriptors: {pTD, contained, mdisp, pdisp, vdisp, attr}.
+        let (bcd_b, bcd_a) = (data + 0x200, data + 0x220);
+        img.put(bcd_b - BASE, rva(td_b), 4);
+        img.put(bcd_b + 4 - BASE, 1, 4);
+        img.put(bcd_b + 12 - BASE, u32::MAX as u64, 4);
+        img.put(bcd_a - BASE, rva(td_a), 4);
+        img.put(bcd_a + 12 - BASE, u32::MAX as u64, 4);
+        // Base class arrays and hierarchy descriptors.
+        let (bca_b, bca_a) = (data + 0x240, data + 0x250);
+        img.put(bca_b - BASE, rva(bcd_b), 4);
+        img.put(bca_b + 4 - BASE, rva(bcd_a), 4);
+        img.put(bca_a - BASE, rva(bcd_a), 4);
+        let (chd_b, chd_a) = (data + 0x260, data + 0x270);
+        img.put(chd_b + 8 - BASE, 2, 4);
+        img.put(chd_b + 12 - BASE, rva(bca_b), 4);
+        img.put(chd_a + 8 - BASE, 1, 4);
+        img.put(chd_a + 12 - BASE, rva(bca_a), 4);
+        // Complete object locators.
+        for (col, td, chd) in [(data + 0x300, td_a, chd_a), (data + 0x320, td_b, chd_b)] {
+            img.put(col - BASE, COL_SIG_REV1 as u64, 4);
+            img.put(col + 12 - BASE, rva(td), 4);
+            img.put(col + 16 - BASE, rva(chd), 4);
+            img.put(col + 20 - BASE, rva(col), 4);
+        }
+        // vftables: [&COL][slots...]
+        img.ptr(data + 0x400 - BASE, data + 0x300);
+        img.ptr(data + 0x408 - BASE, BASE + CODE);
+        img.ptr(data + 0x410 - BASE, BASE + CODE + 0x10);
+        img.ptr(data + 0x420 - BASE, data + 0x320);
+        img.ptr(data + 0x428 - BASE, BASE + CODE);
+        img.ptr(data + 0x430 - BASE, BASE + CODE + 0x20);
+
+        let mut mem = img.memory();
+        mem.is_pe = true;
+        mem.image_base = BASE;
+        for r in &mut mem.regions {
+            r.va += BASE;
+        }
+        let h = recover(&mem).unwrap();
+        assert_eq!(h.abi, RttiAbi::Msvc);
+        let dog = h.class("zoo::Dog").unwrap();
+        assert_eq!(dog.raw_name, ".?AVDog@zoo@@");
+        assert_eq!(dog.declaration(), "zoo::Dog : public zoo::Animal");
+        assert_eq!(dog.vtables[0].va, data + 0x428);
+        assert_eq!(dog.methods.len(), 1);
+        assert_eq!(dog.methods[0].name, "zoo::Dog::vfunc1");
+        assert_eq!(h.class("zoo::Animal").unwrap().methods.len(), 2);
+    }
+
+    #[test]
+    fn msvc_type_names() {
+        for (raw, want) in [
+            (".?AVDog@zoo@@", "zoo::Dog"),
+            (".?AUPoint@@", "Point"),
+            (".?AVInner@Outer@ns@@", "ns::Outer::Inner"),
+            (".?AV?$Cage@VDog@zoo@@@zoo@@", "zoo::Cage<zoo::Dog>"),
+            (".?AV?$Box@H$02@@", "Box<int,3>"),
+            (".?AV?$Ptr@PEBD@@", "Ptr<const char *>"),
+            (".?AVImpl@?$Outer@H@ns@@", "ns::Outer<int>::Impl"),
+        ] {
+            assert_eq!(msvc_type_name(raw).as_deref(), Some(want), "{}", raw);
+        }
+        assert_eq!(msvc_type_name(".?AW4Color@@"), None);
+        assert_eq!(msvc_type_name("Dog"), None);
+    }
+
+    fn class(name: &str, bases: &[String]) -> CppClass {
+        CppClass {
+            name: name.to_string(),
+            raw_name: name.to_string(),
+            typeinfo: 0,
+            bases: bases
+                .iter()
+                .map(|b| BaseClass {
+                    name: b.clone(),
+                    typeinfo: None,
+                    offset: 0,
+                    is_virtual: false,
+                    is_public: true,
+                })
+                .collect(),
+            vtables: Vec::new(),
+            methods: Vec::new(),
+        }
+    }
+
+    #[test]
+    fn depth_walks_shared_bases_once() {
+        // Each level inherits from both classes of the level above: 2^64
+        // paths to the root, which an unmemoised walk never finishes.
+        let mut classes = vec![class("L0a", &[]), class("L0b", &[])];
+        for level in 1..64 {
+            let above = [format!("L{}a", level - 1), format!("L{}b", level - 1)];
+            classes.push(class(&format!("L{}a", level), &above));
+            classes.push(class(&format!("L{}b", level), &above));
+        }
+        classes.push(class("Cycle", &["Cycle".to_string()]));
+        let h = ClassHierarchy {
+            abi: RttiAbi::Itanium,
+            classes,
+        };
+        assert_eq!(h.depth("L63a"), 63);
+        assert_eq!(h.depths()["L10b"], 10);
+        assert_eq!(h.depth("Cycle"), 1);
+        assert_eq!(h.depth("Missing"), 0);
+    }
+
+    #[test]
+    fn typeinfo_fields_near_the_top_of_memory_are_skipped() {
+        let mem = MappedImage::new(8, false);
+        assert!(vmi_bases(&mem, u64::MAX - 8).is_none());
+        let t = TypeInfo {
+            va: u64::MAX - 8,
+            kind: TypeInfoKind::Single,
+            raw_name: "1A".to_string(),
+        };
+        assert_eq!(t.end(&mem), None);
+    }
+}
@@ -5,6 +5,7 @@
 //! lightweight and does not perform any I/O itself.
 
 use crate::core::address::{Address, AddressKind};
+use crate::core::address_map::{AddressMap, AddressMapError};
 use crate::core::address_range::AddressRange;
 use crate::core::binary::{Arch, Binary, Endianness, Format};
 use crate::core::{Section, Segment};
@@ -55,21 +56,26 @@ impl BinaryView {
         self.binary.format
     }
 
+    /// Address translation table built from the view's segments.
+    pub fn address_map(&self) -> AddressMap {
+        AddressMap::from_segments(self.image_base, self.binary.bits, &self.segments)
+    }
+
+    /// Convert `addr` to `target` kind, reporting why translation failed.
+    pub fn translate(
+        &self,
+        addr: &Address,
+        target: AddressKind,
+    ) -> Result<Address, AddressMapError> {
+        self.address_map().translate(addr, target)
+    }
+
     /// Convert a VA to a FileOffset using known segments.
     pub fn va_to_file_offset(&self, va: &Address) -> Option<Address> {
         if va.kind != AddressKind::VA {
             return None;
         }
-        for seg in &self.segments {
-            let start = seg.range.start.value;
-            let size = seg.range.size;
-            if va.value >= start && va.value < start.saturating_add(size) {
-                let off_in_seg = va.value - start;
-                let fo = seg.file_offset.value.saturating_add(off_in_seg);
-                return Address::new(AddressKind::FileOffset, fo, va.bits, None, None).ok();
-            }
-        }
-        None
+        self.translate(va, AddressKind::FileOffset).ok()
     }
 
     /// Convert a FileOffset to VA using known segments.
@@ -77,16 +83,7 @@ impl BinaryView {
         if fo.kind != AddressKind::FileOffset {
             return None;
         }
-        for seg in &self.segments {
-            let file_start = seg.file_offset.value;
-            let size = seg.range.size;
-            if fo.value >= file_start && fo.value < file_start.saturating_add(size) {
-                let off_in_seg = fo.value - file_start;
-                let va = seg.range.start.value.saturating_add(off_in_seg);
-                return Address::new(AddressKind::VA, va, fo.bits, None, None).ok();
-            }
-        }
-        None
+        self.translate(fo, AddressKind::VA).ok()
     }
 
     /// Convert RVA to VA using image_base (if available).
@@ -94,15 +91,7 @@ impl BinaryView {
         if rva.kind != AddressKind::RVA {
             return None;
         }
-        let base = self.image_base?;
-        Address::new(
-            AddressKind::VA,
-            base.saturating_add(rva.value),
-            rva.bits,
-            None,
-            None,
-        )
-        .ok()
+        self.translate(rva, AddressKind::VA).ok()
     }
 
     /// Convert VA to RVA using image_base (if available).
@@ -110,11 +99,7 @@ impl BinaryView {
         if va.kind != AddressKind::VA {
             return None;
         }
-        let base = self.image_base?;
-        if va.value < base {
-            return None;
-        }
-        Address::new(AddressKind::RVA, va.value - base, va.bits, None, None).ok()
+        self.translate(va, AddressKind::RVA).ok()
     }
 
     /// Provide a translator closure suitable for `SliceMemoryView`.
@@ -205,6 +190,12 @@ mod tests {
         let back_va = bv.file_offset_to_va(&fo).unwrap();
         assert_eq!(back_va.kind, AddressKind::VA);
         assert_eq!(back_va.value, va.value);
+
+        let unmapped = Address::new(AddressKind::VA, 0x900000, 64, None, None).unwrap();
+        assert!(matches!(
+            bv.translate(&unmapped, AddressKind::FileOffset),
+            Err(AddressMapError::Unmapped { .. })
+        ));
     }
 
     #[test]
@@ -0,0 +1,175 @@
+//! Cooperative cancellation and deadlines.
+//!
+//! A `CancellationToken` is the Rust counterpart of a request context: the
+//! caller (server mode, batch workers, a Python thread) holds a clone and
+//! can cancel it or give it a deadline, and long-running parsers and passes
+//! poll it at loop boundaries so a pathological input cannot wedge a worker.
+//! Tokens form a tree: a child is cancelled when its parent is, and its
+//! deadline is never later than its parent's.
+
+use crate::error::{GlaurungError, Result};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::Arc;
+use std::time::{Duration, Instant};
+
+#[derive(Debug)]
+struct Inner {
+    cancelled: AtomicBool,
+    created: Instant,
+    deadline: Option<Instant>,
+    parent: Option<CancellationToken>,
+}
+
+/// Why a token stopped work.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum CancelReason {
+    /// `cancel()` was called on this token or an ancestor
+    Cancelled,
+    /// The deadline of this token or an ancestor passed
+    DeadlineExceeded,
+}
+
+/// Shared, cheaply clonable cancellation handle.
+#[derive(Debug, Clone)]
+pub struct CancellationToken {
+    inner: Arc<Inner>,
+}
+
+impl Default for CancellationToken {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+impl CancellationToken {
+    /// A token with no deadline that is only cancelled explicitly.
+    pub fn new() -> Self {
+        Self::build(None, None)
+    }
+
+    /// A token that expires at `deadline`.
+    pub fn with_deadline(deadline: Instant) -> Self {
+        Self::build(Some(deadline), None)
+    }
+
+    /// A token that expires `timeout` from now.
+    pub fn with_timeout(timeout: Duration) -> Self {
+        Self::with_deadline(Instant::now() + timeout)
+    }
+
+    fn build(deadline: Option<Instant>, parent: Option<CancellationToken>) -> Self {
+        Self {
+            inner: Arc::new(Inner {
+                cancelled: AtomicBool::new(false),
+                created: Instant::now(),
+                deadline,
+                parent,
+            }),
+        }
+    }
+
+    /// A child token, cancelled with this one, optionally with a tighter
+    /// timeout of its own (e.g. a per-pass budget).
+    pub fn child(&self, timeout: Option<Duration>) -> Self {
+        let own = timeout.map(|t| Instant::now() + t);
+        let deadline = match (own, self.deadline()) {
+            (Some(a), Some(b)) => Some(a.min(b)),
+            (a, b) => a.or(b),
+        };
+        Self::build(deadline, Some(self.clone()))
+    }
+
+    /// Request cancellation of this token and all of its children.
+    pub fn cancel(&self) {
+        self.inner.cancelled.store(true, Ordering::Relaxed);
+    }
+
+    /// Effective deadline (the earliest among this token and its ancestors).
+    pub fn deadline(&self) -> Option<Instant> {
+        let parent = self.inner.parent.as_ref().and_then(|p| p.deadline());
+        match (self.inner.deadline, parent) {
+            (Some(a), Some(b)) => Some(a.min(b)),
+            (a, b) => a.or(b),
+        }
+    }
+
+    /// Time left before the deadline, if one is set.
+    pub fn remaining(&self) -> Option<Duration> {
+        self.deadline()
+            .map(|d| d.saturating_duration_since(Instant::now()))
+    }
+
+    /// Why work should stop, or `None` to carry on.
+    pub fn reason(&self) -> Option<CancelReason> {
+        let mut cur = Some(self);
+        while let Some(tok) = cur {
+            if tok.inner.cancelled.load(Ordering::Relaxed) {
+                return Some(CancelReason::Cancelled);
+            }
+            cur = tok.inner.parent.as_ref();
+        }
+        match self.deadline() {
+            Some(d) if Instant::now() >= d => Some(CancelReason::DeadlineExceeded),
+            _ => None,
+        }
+    }
+
+    /// True if work should stop.
+    pub fn is_cancelled(&self) -> bool {
+        self.reason().is_some()
+    }
+
+    /// `Err` if work should stop: `Cancelled` or `Timeout`.
+    pub fn check(&self) -> Result<()> {
+        match self.reason() {
+            None => Ok(()),
+            Some(CancelReason::Cancelled) => Err(GlaurungError::Cancelled),
+            Some(CancelReason::DeadlineExceeded) => Err(GlaurungError::Timeout {
+                seconds: self
+                    .deadline()
+                    .map(|d| d.saturating_duration_since(self.inner.created).as_secs())
+                    .unwrap_or_default(),
+            }),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn explicit_cancel_propagates_to_children() {
+        let root = CancellationToken::new();
+        let child = root.child(None);
+        assert!(child.check().is_ok());
+        root.cancel();
+        assert_eq!(child.reason(), Some(CancelReason::Cancelled));
+        assert!(matches!(child.check(), Err(GlaurungError::Cancelled)));
+    }
+
+    #[test]
+    fn cancelling_a_child_leaves_parent_running() {
+        let root = CancellationToken::new();
+        let child = root.child(None);
+        child.cancel();
+        assert!(child.is_cancelled());
+        assert!(!root.is_cancelled());
+    }
+
+    #[test]
+    fn deadlines_expire_and_children_inherit_the_tighter_one() {
+        let expired = CancellationToken::with_timeout(Duration::ZERO);
+        assert_eq!(expired.reason(), Some(CancelReason::DeadlineExceeded));
+        assert!(matches!(
+            expired.check(),
+            Err(GlaurungError::Timeout { .. })
+        ));
+
+        let root = CancellationToken::with_timeout(Duration::from_secs(60));
+        let child = root.child(Some(Duration::from_secs(3600)));
+        assert_eq!(child.deadline(), root.deadline());
+        assert!(child.remaining().unwrap() <= Duration::from_secs(60));
+        assert!(CancellationToken::new().remaining().is_none());
+    }
+}
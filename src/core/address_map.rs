@@ -0,0 +1,402 @@
+//! AddressMap: checked VA/RVA/FileOffset translation for a loaded image.
+//!
+//! Every format backend (ELF program headers, PE section table, Mach-O
+//! segments) describes the same thing: a set of virtual ranges, each
+//! optionally backed by a range of file bytes. `AddressMap` captures that
+//! layout once so passes can convert between address kinds without
+//! re-deriving ad-hoc integer offsets, and so unmapped or non-file-backed
+//! addresses surface as explicit errors rather than silently wrapping.
+//! Regions carry the R/W/X permissions of the mapping when the format
+//! records them, so the same map answers "may this be executed?" queries.
+
+use crate::core::address::{Address, AddressKind};
+use crate::core::segment::{Perms, Segment};
+use serde::{Deserialize, Serialize};
+
+/// Errors produced by address translation.
+#[derive(Debug, Clone, thiserror::Error, PartialEq, Eq)]
+pub enum AddressMapError {
+    /// The address does not fall inside any mapped region.
+    #[error("unmapped {kind} address {value:#x}")]
+    Unmapped { kind: AddressKind, value: u64 },
+    /// The VA is mapped but has no bytes on disk (e.g. `.bss`, zero-fill tail).
+    #[error("VA {0:#x} is mapped but not backed by file data")]
+    NotFileBacked(u64),
+    /// RVA conversion requested but the map has no image base.
+    #[error("no image base available for RVA conversion")]
+    NoImageBase,
+    /// The address kind cannot be translated (Symbolic, Relative, ...).
+    #[error("unsupported address kind for translation: {0}")]
+    UnsupportedKind(AddressKind),
+    /// Arithmetic overflow while translating.
+    #[error("address arithmetic overflow translating {0:#x}")]
+    Overflow(u64),
+}
+
+/// One contiguous virtual range and its (possibly shorter) file backing.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct MappedRegion {
+    /// Optional region name (segment or section name)
+    pub name: Option<String>,
+    /// First virtual address of the region
+    pub va: u64,
+    /// Size of the region in memory
+    pub mem_size: u64,
+    /// File offset of the first backed byte
+    pub file_offset: u64,
+    /// Number of bytes backed by the file (may be less than `mem_size`)
+    pub file_size: u64,
+    /// Mapping permissions, if the format records them
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub perms: Option<Perms>,
+}
+
+impl MappedRegion {
+    /// Create a new region. `file_size` is clamped to `mem_size`.
+    pub fn new(
+        name: Option<String>,
+        va: u64,
+        mem_size: u64,
+        file_offset: u64,
+        file_size: u64,
+    ) -> Self {
+        Self {
+            name,
+            va,
+            mem_size,
+            file_offset,
+            file_size: file_size.min(mem_size),
+            perms: None,
+        }
+    }
+
+    /// Attach the mapping's permissions.
+    pub fn with_perms(mut self, perms: Perms) -> Self {
+        self.perms = Some(perms);
+        self
+    }
+
+    /// True if the region is known to be executable.
+    pub fn is_executable(&self) -> bool {
+        self.perms.is_some_and(|p| p.has_execute())
+    }
+
+    /// True if the region is known to be both writable and executable.
+    pub fn is_writable_executable(&self) -> bool {
+        self.perms.is_some_and(|p| p.has_write() && p.has_execute())
+    }
+
+    /// Whether `va` lies inside the in-memory extent of this region.
+    pub fn contains_va(&self, va: u64) -> bool {
+        va >= self.va && va - self.va < self.mem_size
+    }
+
+    /// Whether `offset` lies inside the file-backed extent of this region.
+    pub fn contains_file_offset(&self, offset: u64) -> bool {
+        offset >= self.file_offset && offset - self.file_offset < self.file_size
+    }
+}
+
+/// Translation table between VA, RVA, and FileOffset for one image.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct AddressMap {
+    /// Image base used for RVA <-> VA conversions when applicable
+    pub image_base: Option<u64>,
+    /// Address width used for produced `Address` values (16, 32, or 64)
+    pub bits: u8,
+    /// Mapped regions in insertion order (first match wins on overlap)
+    pub regions: Vec<MappedRegion>,
+}
+
+impl AddressMap {
+    /// Create an empty map.
+    pub fn new(image_base: Option<u64>, bits: u8) -> Self {
+        Self {
+            image_base,
+            bits,
+            regions: Vec::new(),
+        }
+    }
+
+    /// Build a map from `core::Segment` values (VA range + file offset).
+    ///
+    /// Segments do not record a separate file size, so the whole range is
+    /// treated as file-backed, matching `BinaryView`'s historical behavior.
+    pub fn from_segments(image_base: Option<u64>, bits: u8, segments: &[Segment]) -> Self {
+        let mut map = Self::new(image_base, bits);
+        for seg in segments {
+            map.add_region(
+                MappedRegion::new(
+                    seg.name.clone(),
+                    seg.range.start.value,
+                    seg.range.size,
+                    seg.file_offset.value,
+                    seg.range.size,
+                )
+                .with_perms(seg.perms),
+            );
+        }
+        map
+    }
+
+    /// Append a region. Empty regions are ignored.
+    pub fn add_region(&mut self, region: MappedRegion) {
+        if region.mem_size > 0 {
+            self.regions.push(region);
+        }
+    }
+
+    /// Return true if no regions are mapped.
+    pub fn is_empty(&self) -> bool {
+        self.regions.is_empty()
+    }
+
+    /// Region containing `va`, if any.
+    pub fn region_for_va(&self, va: u64) -> Option<&MappedRegion> {
+        self.regions.iter().find(|r| r.contains_va(va))
+    }
+
+    /// Region whose file backing contains `offset`, if any.
+    pub fn region_for_file_offset(&self, offset: u64) -> Option<&MappedRegion> {
+        self.regions.iter().find(|r| r.contains_file_offset(offset))
+    }
+
+    /// Permissions of the region containing `va`; `None` if unmapped or
+    /// the format does not record them.
+    pub fn perms_at(&self, va: u64) -> Option<Perms> {
+        self.region_for_va(va).and_then(|r| r.perms)
+    }
+
+    /// True if `va` lies in a region known to be executable.
+    pub fn is_executable(&self, va: u64) -> bool {
+        self.region_for_va(va)
+            .is_some_and(MappedRegion::is_executable)
+    }
+
+    /// True if `va` lies in a region known to be writable.
+    pub fn is_writable(&self, va: u64) -> bool {
+        self.perms_at(va).is_some_and(|p| p.has_write())
+    }
+
+    /// Regions known to be executable, in map order.
+    pub fn executable_regions(&self) -> impl Iterator<Item = &MappedRegion> + '_ {
+        self.regions.iter().filter(|r| r.is_executable())
+    }
+
+    /// Regions mapped writable and executable at once (W^X violations).
+    pub fn writable_executable_regions(&self) -> impl Iterator<Item = &MappedRegion> + '_ {
+        self.regions.iter().filter(|r| r.is_writable_executable())
+    }
+
+    /// Translate a VA to a file offset.
+    pub fn va_to_file_offset(&self, va: u64) -> Result<u64, AddressMapError> {
+        let region = self.region_for_va(va).ok_or(AddressMapError::Unmapped {
+            kind: AddressKind::VA,
+            value: va,
+        })?;
+        let delta = va - region.va;
+        if delta >= region.file_size {
+            return Err(AddressMapError::NotFileBacked(va));
+        }
+        region
+            .file_offset
+            .checked_add(delta)
+            .ok_or(AddressMapError::Overflow(va))
+    }
+
+    /// Translate a file offset to the VA it is loaded at.
+    pub fn file_offset_to_va(&self, offset: u64) -> Result<u64, AddressMapError> {
+        let region = self
+            .region_for_file_offset(offset)
+            .ok_or(AddressMapError::Unmapped {
+                kind: AddressKind::FileOffset,
+                value: offset,
+            })?;
+        region
+            .va
+            .checked_add(offset - region.file_offset)
+            .ok_or(AddressMapError::Overflow(offset))
+    }
+
+    /// Translate an RVA to a VA using the image base.
+    pub fn rva_to_va(&self, rva: u64) -> Result<u64, AddressMapError> {
+        let base = self.image_base.ok_or(AddressMapError::NoImageBase)?;
+        base.checked_add(rva).ok_or(AddressMapError::Overflow(rva))
+    }
+
+    /// Translate a VA to an RVA using the image base.
+    pub fn va_to_rva(&self, va: u64) -> Result<u64, AddressMapError> {
+        let base = self.image_base.ok_or(AddressMapError::NoImageBase)?;
+        va.checked_sub(base).ok_or(AddressMapError::Unmapped {
+            kind: AddressKind::VA,
+            value: va,
+        })
+    }
+
+    /// Translate an RVA to a file offset.
+    pub fn rva_to_file_offset(&self, rva: u64) -> Result<u64, AddressMapError> {
+        self.va_to_file_offset(self.rva_to_va(rva)?)
+    }
+
+    /// Translate a file offset to an RVA.
+    pub fn file_offset_to_rva(&self, offset: u64) -> Result<u64, AddressMapError> {
+        self.va_to_rva(self.file_offset_to_va(offset)?)
+    }
+
+    /// Convert `addr` to the requested address kind.
+    ///
+    /// Supports any combination of VA, RVA, and FileOffset. The address
+    /// space and bit width of the input are preserved.
+    pub fn translate(
+        &self,
+        addr: &Address,
+        target: AddressKind,
+    ) -> Result<Address, AddressMapError> {
+        if addr.kind == target {
+            return Ok(addr.clone());
+        }
+        let va = match addr.kind {
+            AddressKind::VA => addr.value,
+            AddressKind::RVA => self.rva_to_va(addr.value)?,
+            AddressKind::FileOffset => self.file_offset_to_va(addr.value)?,
+            other => return Err(AddressMapError::UnsupportedKind(other)),
+        };
+        let value = match target {
+            AddressKind::VA => va,
+            AddressKind::RVA => self.va_to_rva(va)?,
+            AddressKind::FileOffset => self.va_to_file_offset(va)?,
+            other => return Err(AddressMapError::UnsupportedKind(other)),
+        };
+        Address::new(target, value, addr.bits, addr.space.clone(), None)
+            .map_err(|_| AddressMapError::Overflow(value))
+    }
+
+    /// Build a VA `Address` using this map's bit width.
+    pub fn va(&self, value: u64) -> Option<Address> {
+        Address::new(AddressKind::VA, value, self.bits, None, None).ok()
+    }
+}
+
+impl From<AddressMapError> for crate::error::GlaurungError {
+    fn from(err: AddressMapError) -> Self {
+        crate::error::GlaurungError::AddressError(err.to_string())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn elf_like() -> AddressMap {
+        let mut m = AddressMap::new(Some(0x400000), 64);
+        // .text: fully backed
+        m.add_region(MappedRegion::new(
+            Some("text".into()),
+            0x401000,
+            0x1000,
+            0x1000,
+            0x1000,
+        ));
+        // .data + .bss: 0x200 bytes on disk, 0x800 in memory
+        m.add_region(MappedRegion::new(
+            Some("data".into()),
+            0x403000,
+            0x800,
+            0x2000,
+            0x200,
+        ));
+        m
+    }
+
+    #[test]
+    fn permission_queries() {
+        let mut m = AddressMap::new(None, 64);
+        m.add_region(
+            MappedRegion::new(Some("text".into()), 0x1000, 0x100, 0, 0x100)
+                .with_perms(Perms::new(true, false, true)),
+        );
+        m.add_region(
+            MappedRegion::new(Some("rwx".into()), 0x2000, 0x100, 0x100, 0x100)
+                .with_perms(Perms::new(true, true, true)),
+        );
+        m.add_region(MappedRegion::new(None, 0x3000, 0x100, 0x200, 0x100));
+        assert!(m.is_executable(0x1010));
+        assert!(!m.is_writable(0x1010));
+        assert!(m.is_writable(0x2010));
+        // Unknown permissions are never reported as executable.
+        assert_eq!(m.perms_at(0x3010), None);
+        assert!(!m.is_executable(0x3010));
+        assert!(!m.is_executable(0x9000));
+        assert_eq!(m.executable_regions().count(), 2);
+        let wx: Vec<_> = m.writable_executable_regions().collect();
+        assert_eq!(wx.len(), 1);
+        assert_eq!(wx[0].va, 0x2000);
+    }
+
+    #[test]
+    fn va_file_offset_roundtrip() {
+        let m = elf_like();
+        assert_eq!(m.va_to_file_offset(0x401123).unwrap(), 0x1123);
+        assert_eq!(m.file_offset_to_va(0x1123).unwrap(), 0x401123);
+        assert_eq!(m.va_to_file_offset(0x403010).unwrap(), 0x2010);
+    }
+
+    #[test]
+    fn unmapped_and_bss_are_explicit_errors() {
+        let m = elf_like();
+        assert_eq!(
+            m.va_to_file_offset(0x500000),
+            Err(AddressMapError::Unmapped {
+                kind: AddressKind::VA,
+                value: 0x500000
+            })
+        );
+        assert_eq!(
+            m.va_to_file_offset(0x403400),
+            Err(AddressMapError::NotFileBacked(0x403400))
+        );
+        assert!(matches!(
+            m.file_offset_to_va(0x2400),
+            Err(AddressMapError::Unmapped { .. })
+        ));
+    }
+
+    #[test]
+    fn rva_conversions_require_image_base() {
+        let m = elf_like();
+        assert_eq!(m.rva_to_va(0x1000).unwrap(), 0x401000);
+        assert_eq!(m.va_to_rva(0x401000).unwrap(), 0x1000);
+        assert_eq!(m.rva_to_file_offset(0x1010).unwrap(), 0x1010);
+        assert_eq!(m.file_offset_to_rva(0x2000).unwrap(), 0x3000);
+
+        let no_base = AddressMap::new(None, 64);
+        assert_eq!(no_base.rva_to_va(0x10), Err(AddressMapError::NoImageBase));
+    }
+
+    #[test]
+    fn translate_between_kinds() {
+        let m = elf_like();
+        let va = Address::new(AddressKind::VA, 0x401020, 64, None, None).unwrap();
+        let fo = m.translate(&va, AddressKind::FileOffset).unwrap();
+        assert_eq!(fo.kind, AddressKind::FileOffset);
+        assert_eq!(fo.value, 0x1020);
+        let rva = m.translate(&fo, AddressKind::RVA).unwrap();
+        assert_eq!(rva.kind, AddressKind::RVA);
+        assert_eq!(rva.value, 0x1020);
+        let back = m.translate(&rva, AddressKind::VA).unwrap();
+        assert_eq!(back, va);
+
+        let sym = Address::new(AddressKind::Symbolic, 0, 64, None, Some("x".into())).unwrap();
+        assert_eq!(
+            m.translate(&sym, AddressKind::VA),
+            Err(AddressMapError::UnsupportedKind(AddressKind::Symbolic))
+        );
+    }
+
+    #[test]
+    fn empty_regions_are_ignored() {
+        let mut m = AddressMap::new(None, 32);
+        m.add_region(MappedRegion::new(None, 0x1000, 0, 0, 0));
+        assert!(m.is_empty());
+    }
+}
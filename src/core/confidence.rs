@@ -0,0 +1,205 @@
+//! Shared confidence model.
+//!
+//! Every detector (format sniffing and header validation, packer
+//! identification, function discovery, capability and signature matches)
+//! reports confidence as an `f32` in `[0.0, 1.0]` with the same meaning, so
+//! consumers can apply one threshold across all of them:
+//!
+//! | band          | range        | meaning                                         |
+//! |---------------|--------------|-------------------------------------------------|
+//! | `Certain`     | `>= 0.95`    | structural proof: a parsed header, an exact     |
+//! |               |              | signature, a symbol table entry                 |
+//! | `High`        | `[0.8, 0.95)`| several independent signals agree               |
+//! | `Medium`      | `[0.5, 0.8)` | one strong heuristic, or corroborated weak ones |
+//! | `Low`         | `[0.2, 0.5)` | a single weak heuristic                         |
+//! | `Speculative` | `< 0.2`      | kept for completeness; not actionable alone     |
+//!
+//! A value is an estimate that the claim is true, not a severity. Scores are
+//! combined with the rules below rather than ad-hoc sums, so adding a signal
+//! can never push a score past 1.0 or make it negative:
+//!
+//! * independent evidence for the same claim: [`any_of`] (noisy-OR);
+//! * a claim that needs every premise to hold: [`all_of`] (product);
+//! * a fixed-weight blend of signals: [`weighted_mean`];
+//! * a known contradiction or error: [`penalize`] (subtract, floor at 0).
+//!
+//! NaN and out-of-range inputs are clamped by [`clamp`] first.
+
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::str::FromStr;
+
+/// Lower bound of the `Certain` band.
+pub const CERTAIN: f32 = 0.95;
+/// Lower bound of the `High` band.
+pub const HIGH: f32 = 0.8;
+/// Lower bound of the `Medium` band.
+pub const MEDIUM: f32 = 0.5;
+/// Lower bound of the `Low` band.
+pub const LOW: f32 = 0.2;
+
+/// Clamp `c` into `[0.0, 1.0]`; NaN becomes 0.0.
+pub fn clamp(c: f32) -> f32 {
+    if c.is_nan() {
+        0.0
+    } else {
+        c.clamp(0.0, 1.0)
+    }
+}
+
+/// Combine independent pieces of evidence for one claim (noisy-OR):
+/// `1 - Π(1 - cᵢ)`. Empty input gives 0.0.
+pub fn any_of<I: IntoIterator<Item = f32>>(scores: I) -> f32 {
+    1.0 - scores
+        .into_iter()
+        .fold(1.0f32, |miss, c| miss * (1.0 - clamp(c)))
+}
+
+/// Confidence that every premise holds (product). Empty input gives 1.0.
+pub fn all_of<I: IntoIterator<Item = f32>>(scores: I) -> f32 {
+    scores.into_iter().fold(1.0f32, |acc, c| acc * clamp(c))
+}
+
+/// Weighted mean of `(score, weight)` pairs; non-positive weights are
+/// ignored. No usable weight gives 0.0.
+pub fn weighted_mean<I: IntoIterator<Item = (f32, f32)>>(signals: I) -> f32 {
+    let (sum, total) = signals
+        .into_iter()
+        .filter(|(_, w)| *w > 0.0)
+        .fold((0.0f32, 0.0f32), |(s, t), (c, w)| (s + clamp(c) * w, t + w));
+    if total > 0.0 {
+        clamp(sum / total)
+    } else {
+        0.0
+    }
+}
+
+/// Lower `c` by `penalty`, flooring at 0.0.
+pub fn penalize(c: f32, penalty: f32) -> f32 {
+    clamp(clamp(c) - penalty.max(0.0))
+}
+
+/// Qualitative band of a confidence value.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum ConfidenceBand {
+    Speculative,
+    Low,
+    Medium,
+    High,
+    Certain,
+}
+
+impl ConfidenceBand {
+    /// Band containing `c`.
+    pub fn of(c: f32) -> Self {
+        let c = clamp(c);
+        if c >= CERTAIN {
+            Self::Certain
+        } else if c >= HIGH {
+            Self::High
+        } else if c >= MEDIUM {
+            Self::Medium
+        } else if c >= LOW {
+            Self::Low
+        } else {
+            Self::Speculative
+        }
+    }
+
+    /// Smallest confidence in this band.
+    pub fn min_score(self) -> f32 {
+        match self {
+            Self::Speculative => 0.0,
+            Self::Low => LOW,
+            Self::Medium => MEDIUM,
+            Self::High => HIGH,
+            Self::Certain => CERTAIN,
+        }
+    }
+
+    pub fn label(self) -> &'static str {
+        match self {
+            Self::Speculative => "speculative",
+            Self::Low => "low",
+            Self::Medium => "medium",
+            Self::High => "high",
+            Self::Certain => "certain",
+        }
+    }
+}
+
+impl fmt::Display for ConfidenceBand {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.label())
+    }
+}
+
+impl FromStr for ConfidenceBand {
+    type Err = String;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        match s.to_ascii_lowercase().as_str() {
+            "speculative" => Ok(Self::Speculative),
+            "low" => Ok(Self::Low),
+            "medium" => Ok(Self::Medium),
+            "high" => Ok(Self::High),
+            "certain" => Ok(Self::Certain),
+            other => Err(format!("unknown confidence band '{}'", other)),
+        }
+    }
+}
+
+/// Parse a threshold given either as a number in `[0, 1]` or a band name.
+pub fn parse_threshold(s: &str) -> Result<f32, String> {
+    if let Ok(v) = s.trim().parse::<f32>() {
+        return if (0.0..=1.0).contains(&v) {
+            Ok(v)
+        } else {
+            Err(format!("confidence {} is outside [0, 1]", v))
+        };
+    }
+    s.trim()
+        .parse::<ConfidenceBand>()
+        .map(ConfidenceBand::min_score)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn close(a: f32, b: f32) -> bool {
+        (a - b).abs() < 1e-6
+    }
+
+    #[test]
+    fn combination_rules_stay_in_range() {
+        assert!(close(any_of([0.5, 0.5]), 0.75));
+        assert_eq!(any_of([]), 0.0);
+        assert_eq!(any_of([1.0, 0.1]), 1.0);
+        assert!(close(all_of([0.5, 0.5]), 0.25));
+        assert_eq!(all_of([]), 1.0);
+        assert!(close(weighted_mean([(1.0, 3.0), (0.0, 1.0)]), 0.75));
+        assert_eq!(weighted_mean([(1.0, 0.0)]), 0.0);
+        assert_eq!(penalize(0.1, 0.3), 0.0);
+        assert_eq!(clamp(f32::NAN), 0.0);
+        assert_eq!(clamp(7.0), 1.0);
+        // More independent evidence never lowers confidence.
+        assert!(any_of([0.4, 0.3, 0.3, 0.2]) > any_of([0.4, 0.3]));
+        assert!(any_of([2.0, -1.0]) <= 1.0);
+    }
+
+    #[test]
+    fn bands_and_thresholds() {
+        assert_eq!(ConfidenceBand::of(1.0), ConfidenceBand::Certain);
+        assert_eq!(ConfidenceBand::of(0.85), ConfidenceBand::High);
+        assert_eq!(ConfidenceBand::of(0.5), ConfidenceBand::Medium);
+        assert_eq!(ConfidenceBand::of(0.3), ConfidenceBand::Low);
+        assert_eq!(ConfidenceBand::of(0.0), ConfidenceBand::Speculative);
+        assert!(ConfidenceBand::High > ConfidenceBand::Medium);
+        assert_eq!(parse_threshold("high"), Ok(HIGH));
+        assert_eq!(parse_threshold("0.6"), Ok(0.6));
+        assert!(parse_threshold("1.5").is_err());
+        assert!(parse_threshold("sure").is_err());
+    }
+}
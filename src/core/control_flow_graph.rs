@@ -21,6 +21,8 @@ pub enum ControlFlowEdgeKind {
     Call,
     /// Function return (may not return)
     Return,
+    /// Unwinding from a protected range to its landing pad
+    Exception,
 }
 
 impl ControlFlowEdgeKind {
@@ -30,6 +32,7 @@ impl ControlFlowEdgeKind {
             ControlFlowEdgeKind::Branch => "branch",
             ControlFlowEdgeKind::Call => "call",
             ControlFlowEdgeKind::Return => "return",
+            ControlFlowEdgeKind::Exception => "exception",
         }
     }
 }
@@ -647,6 +650,7 @@ impl ControlFlowEdgeKind {
             ControlFlowEdgeKind::Branch => "branch",
             ControlFlowEdgeKind::Call => "call",
             ControlFlowEdgeKind::Return => "return",
+            ControlFlowEdgeKind::Exception => "exception",
         }
         .to_string()
     }
@@ -115,6 +115,12 @@ pub struct Function {
     /// Edges between basic blocks (from_addr, to_addr)
     pub edges: Vec<(Address, Address)>,
 
+    /// Edges from blocks inside a try region to its landing pad
+    /// (from_addr, to_addr). Kept apart from `edges` because they are only
+    /// taken while unwinding: dominance and loop analyses ignore them.
+    #[serde(default)]
+    pub exception_edges: Vec<(Address, Address)>,
+
     /// Addresses of functions that call this function
     pub callers: HashSet<Address>,
 
@@ -170,6 +176,7 @@ impl Function {
             signature: None,
             basic_blocks: Vec::new(),
             edges: Vec::new(),
+            exception_edges: Vec::new(),
             callers: HashSet::new(),
             callees: HashSet::new(),
             stack_frame_size: None,
@@ -222,6 +229,7 @@ impl Function {
             signature,
             basic_blocks: Vec::new(),
             edges: Vec::new(),
+            exception_edges: Vec::new(),
             callers: HashSet::new(),
             callees: HashSet::new(),
             stack_frame_size,
@@ -242,6 +250,11 @@ impl Function {
         self.edges.push((from, to));
     }
 
+    /// Add an exception edge from a block to a landing pad
+    pub fn add_exception_edge(&mut self, from: Address, to: Address) {
+        self.exception_edges.push((from, to));
+    }
+
     /// Add a caller address
     pub fn add_caller(&mut self, caller: Address) {
         self.callers.insert(caller);
@@ -447,6 +460,13 @@ impl Function {
         self.name = value;
     }
 
+    /// Human-readable form of `name` when it is a mangled C++, Rust or
+    /// Swift symbol; `None` otherwise.
+    #[getter]
+    fn demangled_name(&self) -> Option<String> {
+        crate::demangle::demangle_one(&self.name).map(|r| r.demangled)
+    }
+
     #[getter]
     fn entry_point(&self) -> Address {
         self.entry_point.clone()
@@ -0,0 +1,261 @@
+//! BinaryImage: the format-agnostic view analysis passes are written against.
+//!
+//! Each format backend (ELF/PE/Mach-O via `formats::object_image`, WebAssembly
+//! via `formats::wasm`) implements `BinaryImage`, exposing the same layout
+//! primitives — sections, segments, symbols, imports, entry point — and
+//! bounded reads by virtual address. Passes that only need these primitives
+//! take `&dyn BinaryImage` instead of re-parsing the file per format.
+
+use std::borrow::Cow;
+
+use crate::core::address_map::{AddressMap, AddressMapError, MappedRegion};
+use crate::core::binary::{Arch, Endianness, Format};
+use crate::core::segment::Perms;
+use crate::core::symbol::SymbolKind;
+use serde::{Deserialize, Serialize};
+
+/// A named section as described by the format's section table.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ImageSection {
+    /// Section name (e.g. `.text`, `__TEXT,__text`, `code`)
+    pub name: String,
+    /// Address of the section in memory (file offset for unmapped formats)
+    pub va: u64,
+    /// Size of the section in memory
+    pub size: u64,
+    /// `(offset, size)` of the section's bytes in the file, if any
+    pub file_range: Option<(u64, u64)>,
+    /// Effective permissions of the section
+    pub perms: Perms,
+}
+
+/// A load-time mapping unit (program header, PE section, Mach-O segment).
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ImageSegment {
+    /// Optional segment name
+    pub name: Option<String>,
+    /// First virtual address of the mapping
+    pub va: u64,
+    /// Size of the mapping in memory
+    pub mem_size: u64,
+    /// File offset of the backing bytes
+    pub file_offset: u64,
+    /// Size of the backing bytes (may be less than `mem_size`)
+    pub file_size: u64,
+    /// Mapping permissions
+    pub perms: Perms,
+}
+
+/// A defined symbol from the static or dynamic symbol table.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ImageSymbol {
+    /// Raw (possibly mangled) symbol name
+    pub name: String,
+    /// Symbol address
+    pub address: u64,
+    /// Symbol size in bytes (0 if unknown)
+    pub size: u64,
+    /// Kind of entity the symbol names
+    pub kind: SymbolKind,
+    /// Whether the symbol came from the dynamic table / export list
+    pub dynamic: bool,
+}
+
+/// An imported function or data item.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ImageImport {
+    /// Providing library or module, when the format records it
+    pub library: Option<String>,
+    /// Imported name
+    pub name: String,
+}
+
+/// Build the address map for `image` from its segments.
+pub fn build_address_map<I: BinaryImage + ?Sized>(image: &I) -> AddressMap {
+    let mut map = AddressMap::new(image.image_base(), image.bits());
+    for seg in image.segments() {
+        map.add_region(
+            MappedRegion::new(
+                seg.name.clone(),
+                seg.va,
+                seg.mem_size,
+                seg.file_offset,
+                seg.file_size,
+            )
+            .with_perms(seg.perms),
+        );
+    }
+    map
+}
+
+/// Common interface implemented by every format backend.
+pub trait BinaryImage {
+    /// Raw bytes of the image.
+    fn data(&self) -> &[u8];
+
+    /// Container format.
+    fn format(&self) -> Format;
+
+    /// CPU architecture (`Arch::Unknown` for bytecode formats).
+    fn arch(&self) -> Arch;
+
+    /// Address width in bits.
+    fn bits(&self) -> u8;
+
+    /// Byte order of the image.
+    fn endianness(&self) -> Endianness;
+
+    /// Entry point address, if the image has one.
+    fn entry(&self) -> Option<u64>;
+
+    /// Image base used for RVA conversions, if the format defines one.
+    fn image_base(&self) -> Option<u64> {
+        None
+    }
+
+    /// Sections from the section table.
+    fn sections(&self) -> Vec<ImageSection>;
+
+    /// Load-time segments.
+    fn segments(&self) -> Vec<ImageSegment>;
+
+    /// Defined symbols (static and dynamic).
+    fn symbols(&self) -> Vec<ImageSymbol>;
+
+    /// Imported names.
+    fn imports(&self) -> Vec<ImageImport>;
+
+    /// Address translation table derived from `segments()`.
+    ///
+    /// The default rebuilds the map on every call; backends that serve
+    /// many reads cache it and lend it out instead.
+    fn address_map(&self) -> Cow<'_, AddressMap> {
+        Cow::Owned(build_address_map(self))
+    }
+
+    /// Permissions of the segment mapping `va`, if any.
+    fn perms_at(&self, va: u64) -> Option<Perms> {
+        self.segments()
+            .into_iter()
+            .find(|s| va >= s.va && va - s.va < s.mem_size)
+            .map(|s| s.perms)
+    }
+
+    /// Segments mapped both writable and executable.
+    fn writable_executable_segments(&self) -> Vec<ImageSegment> {
+        self.segments()
+            .into_iter()
+            .filter(|s| s.perms.has_write() && s.perms.has_execute())
+            .collect()
+    }
+
+    /// Read `len` file-backed bytes starting at `va`.
+    ///
+    /// The whole range must be backed by a single region; reads that run
+    /// past the file data or the end of the image report an error.
+    fn read_va(&self, va: u64, len: usize) -> Result<&[u8], AddressMapError> {
+        let map = self.address_map();
+        let start = map.va_to_file_offset(va)?;
+        if len > 0 {
+            let last = va
+                .checked_add(len as u64 - 1)
+                .ok_or(AddressMapError::Overflow(va))?;
+            let last_off = map.va_to_file_offset(last)?;
+            if last_off != start + len as u64 - 1 {
+                return Err(AddressMapError::NotFileBacked(last));
+            }
+        }
+        let data = self.data();
+        let start = start as usize;
+        let end = start
+            .checked_add(len)
+            .ok_or(AddressMapError::Overflow(va))?;
+        data.get(start..end)
+            .ok_or(AddressMapError::NotFileBacked(va))
+    }
+
+    /// Sections that carry executable code.
+    fn code_sections(&self) -> Vec<ImageSection> {
+        self.sections()
+            .into_iter()
+            .filter(|s| s.perms.has_execute())
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    struct FlatImage {
+        bytes: Vec<u8>,
+    }
+
+    impl BinaryImage for FlatImage {
+        fn data(&self) -> &[u8] {
+            &self.bytes
+        }
+        fn format(&self) -> Format {
+            Format::Raw
+        }
+        fn arch(&self) -> Arch {
+            Arch::X86_64
+        }
+        fn bits(&self) -> u8 {
+            64
+        }
+        fn endianness(&self) -> Endianness {
+            Endianness::Little
+        }
+        fn entry(&self) -> Option<u64> {
+            Some(0x1000)
+        }
+        fn sections(&self) -> Vec<ImageSection> {
+            vec![ImageSection {
+                name: ".text".into(),
+                va: 0x1000,
+                size: 0x10,
+                file_range: Some((0, 0x10)),
+                perms: Perms::new(true, false, true),
+            }]
+        }
+        fn segments(&self) -> Vec<ImageSegment> {
+            vec![ImageSegment {
+                name: None,
+                va: 0x1000,
+                mem_size: 0x20,
+                file_offset: 0,
+                file_size: 0x10,
+                perms: Perms::new(true, false, true),
+            }]
+        }
+        fn symbols(&self) -> Vec<ImageSymbol> {
+            Vec::new()
+        }
+        fn imports(&self) -> Vec<ImageImport> {
+            Vec::new()
+        }
+    }
+
+    #[test]
+    fn read_va_is_bounded_by_file_backing() {
+        let img = FlatImage {
+            bytes: (0u8..0x10).collect(),
+        };
+        assert_eq!(img.read_va(0x1004, 4).unwrap(), &[4, 5, 6, 7]);
+        assert_eq!(img.read_va(0x1000, 0).unwrap(), &[] as &[u8]);
+        assert_eq!(
+            img.read_va(0x100e, 4),
+            Err(AddressMapError::NotFileBacked(0x1011))
+        );
+        assert!(matches!(
+            img.read_va(0x2000, 1),
+            Err(AddressMapError::Unmapped { .. })
+        ));
+        assert_eq!(img.code_sections().len(), 1);
+        assert!(img.address_map().is_executable(0x1000));
+        assert_eq!(img.perms_at(0x101f), Some(Perms::new(true, false, true)));
+        assert_eq!(img.perms_at(0x1020), None);
+        assert!(img.writable_executable_segments().is_empty());
+    }
+}
@@ -5,17 +5,20 @@
 //! references in binary analysis.
 
 pub mod address;
+pub mod address_map;
 pub mod address_range;
 pub mod address_space;
 pub mod artifact;
 pub mod basic_block;
 pub mod binary;
 pub mod call_graph;
+pub mod confidence;
 pub mod control_flow_graph;
 pub mod data_type;
 pub mod disassembler;
 pub mod function;
 pub mod id;
+pub mod image;
 pub mod instruction;
 pub mod pattern;
 pub mod reference;
@@ -31,16 +34,19 @@ pub mod variable;
 
 // Re-export key types for convenience
 pub use address::{Address, AddressKind};
+pub use address_map::{AddressMap, AddressMapError, MappedRegion};
 pub use address_range::AddressRange;
 pub use address_space::{AddressSpace, AddressSpaceKind};
 pub use artifact::Artifact;
 pub use basic_block::BasicBlock;
 pub use binary::{Arch, Binary, Endianness, Format, Hashes};
 pub use call_graph::CallGraph;
+pub use confidence::ConfidenceBand;
 pub use control_flow_graph::ControlFlowGraph;
 pub use data_type::{DataType, DataTypeKind, EnumMember, Field, TypeData};
 pub use function::{Function, FunctionFlags, FunctionKind};
 pub use id::{Id, IdGenerator, IdKind};
+pub use image::{BinaryImage, ImageImport, ImageSection, ImageSegment, ImageSymbol};
 pub use instruction::{Access, Instruction, Operand, OperandKind, SideEffect};
 pub use pattern::{Pattern, PatternType};
 pub use reference::{Reference, ReferenceKind};
@@ -0,0 +1,392 @@
+//! Analyst annotations attached to analyzed artifacts.
+//!
+//! Annotations are keyed by the artifact's SHA-256 rather than its path, so
+//! tags, comments, a verdict, and function renames recorded once follow the
+//! same bytes into every later re-analysis. They are persisted by
+//! `triage::annotations::AnnotationStore` and merged into a
+//! `TriagedArtifact` on request.
+
+use chrono::{DateTime, Utc};
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+/// Free-form analyst note, optionally anchored at an address.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct AnalystComment {
+    pub text: String,
+    /// Virtual address the note refers to, if any
+    pub address: Option<u64>,
+    pub author: Option<String>,
+    pub created_at: DateTime<Utc>,
+}
+
+/// The analyst's overall call on an artifact (e.g. "malicious", "benign").
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct AnalystVerdict {
+    pub label: String,
+    pub note: Option<String>,
+    pub author: Option<String>,
+    pub created_at: DateTime<Utc>,
+}
+
+/// Analyst-chosen name for the function at `address`.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct FunctionRename {
+    pub address: u64,
+    pub name: String,
+    pub author: Option<String>,
+    pub created_at: DateTime<Utc>,
+}
+
+/// Everything analysts have recorded about one artifact.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct Annotations {
+    /// Lower-case hex SHA-256 of the artifact bytes
+    pub sha256: String,
+    /// Sorted, de-duplicated tags
+    #[serde(default)]
+    pub tags: Vec<String>,
+    #[serde(default)]
+    pub comments: Vec<AnalystComment>,
+    #[serde(default)]
+    pub verdict: Option<AnalystVerdict>,
+    /// Renames sorted by address, at most one per address
+    #[serde(default)]
+    pub function_names: Vec<FunctionRename>,
+    pub updated_at: DateTime<Utc>,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl AnalystComment {
+    #[getter]
+    fn text(&self) -> String {
+        self.text.clone()
+    }
+    #[getter]
+    fn address(&self) -> Option<u64> {
+        self.address
+    }
+    #[getter]
+    fn author(&self) -> Option<String> {
+        self.author.clone()
+    }
+    #[getter]
+    fn created_at(&self) -> String {
+        self.created_at.to_rfc3339()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "AnalystComment(address={:?}, text={:?})",
+            self.address, self.text
+        )
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl AnalystVerdict {
+    #[getter]
+    fn label(&self) -> String {
+        self.label.clone()
+    }
+    #[getter]
+    fn note(&self) -> Option<String> {
+        self.note.clone()
+    }
+    #[getter]
+    fn author(&self) -> Option<String> {
+        self.author.clone()
+    }
+    #[getter]
+    fn created_at(&self) -> String {
+        self.created_at.to_rfc3339()
+    }
+
+    fn __repr__(&self) -> String {
+        format!("AnalystVerdict(label={:?})", self.label)
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl FunctionRename {
+    #[getter]
+    fn address(&self) -> u64 {
+        self.address
+    }
+    #[getter]
+    fn name(&self) -> String {
+        self.name.clone()
+    }
+    #[getter]
+    fn author(&self) -> Option<String> {
+        self.author.clone()
+    }
+    #[getter]
+    fn created_at(&self) -> String {
+        self.created_at.to_rfc3339()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "FunctionRename(address={:#x}, name={:?})",
+            self.address, self.name
+        )
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl Annotations {
+    #[getter]
+    fn sha256(&self) -> String {
+        self.sha256.clone()
+    }
+    #[getter]
+    fn tags(&self) -> Vec<String> {
+        self.tags.clone()
+    }
+    #[getter]
+    fn comments(&self) -> Vec<AnalystComment> {
+        self.comments.clone()
+    }
+    #[getter]
+    fn verdict(&self) -> Option<AnalystVerdict> {
+        self.verdict.clone()
+    }
+    #[getter]
+    fn function_names(&self) -> Vec<FunctionRename> {
+        self.function_names.clone()
+    }
+    #[getter]
+    fn updated_at(&self) -> String {
+        self.updated_at.to_rfc3339()
+    }
+
+    #[pyo3(name = "function_name")]
+    fn function_name_py(&self, address: u64) -> Option<String> {
+        self.function_name(address).map(str::to_string)
+    }
+
+    fn to_json(&self) -> PyResult<String> {
+        serde_json::to_string(self)
+            .map_err(|e| pyo3::exceptions::PyValueError::new_err(e.to_string()))
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "Annotations(sha256={:?}, tags={:?}, comments={}, verdict={:?}, function_names={})",
+            self.sha256,
+            self.tags,
+            self.comments.len(),
+            self.verdict.as_ref().map(|v| v.label.as_str()),
+            self.function_names.len()
+        )
+    }
+}
+
+// Pure Rust constructors and helpers
+impl Annotations {
+    /// Empty annotations for the artifact with digest `sha256`.
+    pub fn new<S: Into<String>>(sha256: S) -> Self {
+        Self {
+            sha256: sha256.into().to_ascii_lowercase(),
+            tags: Vec::new(),
+            comments: Vec::new(),
+            verdict: None,
+            function_names: Vec::new(),
+            updated_at: Utc::now(),
+        }
+    }
+
+    /// True if nothing has been recorded.
+    pub fn is_empty(&self) -> bool {
+        self.tags.is_empty()
+            && self.comments.is_empty()
+            && self.verdict.is_none()
+            && self.function_names.is_empty()
+    }
+
+    fn touch(&mut self) {
+        self.updated_at = Utc::now();
+    }
+
+    /// Add `tag`; returns false if it was already present.
+    pub fn add_tag<S: Into<String>>(&mut self, tag: S) -> bool {
+        let tag = tag.into();
+        match self.tags.binary_search(&tag) {
+            Ok(_) => false,
+            Err(i) => {
+                self.tags.insert(i, tag);
+                self.touch();
+                true
+            }
+        }
+    }
+
+    /// Remove `tag`; returns false if it was not present.
+    pub fn remove_tag(&mut self, tag: &str) -> bool {
+        match self.tags.binary_search_by(|t| t.as_str().cmp(tag)) {
+            Ok(i) => {
+                self.tags.remove(i);
+                self.touch();
+                true
+            }
+            Err(_) => false,
+        }
+    }
+
+    pub fn add_comment<S: Into<String>>(
+        &mut self,
+        text: S,
+        address: Option<u64>,
+        author: Option<String>,
+    ) {
+        self.comments.push(AnalystComment {
+            text: text.into(),
+            address,
+            author,
+            created_at: Utc::now(),
+        });
+        self.touch();
+    }
+
+    /// Set (or with `None`, clear) the verdict.
+    pub fn set_verdict(
+        &mut self,
+        label: Option<String>,
+        note: Option<String>,
+        author: Option<String>,
+    ) {
+        self.verdict = label.map(|label| AnalystVerdict {
+            label,
+            note,
+            author,
+            created_at: Utc::now(),
+        });
+        self.touch();
+    }
+
+    /// Name the function at `address`, replacing any earlier rename.
+    pub fn rename_function<S: Into<String>>(
+        &mut self,
+        address: u64,
+        name: S,
+        author: Option<String>,
+    ) {
+        let rename = FunctionRename {
+            address,
+            name: name.into(),
+            author,
+            created_at: Utc::now(),
+        };
+        match self
+            .function_names
+            .binary_search_by_key(&address, |r| r.address)
+        {
+            Ok(i) => self.function_names[i] = rename,
+            Err(i) => self.function_names.insert(i, rename),
+        }
+        self.touch();
+    }
+
+    /// Analyst name for the function at `address`, if renamed.
+    pub fn function_name(&self, address: u64) -> Option<&str> {
+        self.function_names
+            .binary_search_by_key(&address, |r| r.address)
+            .ok()
+            .map(|i| self.function_names[i].name.as_str())
+    }
+
+    /// Fold `other` (for the same artifact) into `self`: tags and comments
+    /// are unioned; for the verdict and each rename the newer entry wins
+    /// (`other` on ties).
+    pub fn merge(&mut self, other: &Annotations) {
+        for tag in &other.tags {
+            self.add_tag(tag.clone());
+        }
+        for c in &other.comments {
+            if !self.comments.contains(c) {
+                self.comments.push(c.clone());
+            }
+        }
+        self.comments.sort_by_key(|c| c.created_at);
+        if let Some(theirs) = &other.verdict {
+            if self
+                .verdict
+                .as_ref()
+                .is_none_or(|ours| theirs.created_at >= ours.created_at)
+            {
+                self.verdict = Some(theirs.clone());
+            }
+        }
+        for r in &other.function_names {
+            match self
+                .function_names
+                .binary_search_by_key(&r.address, |x| x.address)
+            {
+                Ok(i) if self.function_names[i].created_at > r.created_at => {}
+                Ok(i) => self.function_names[i] = r.clone(),
+                Err(i) => self.function_names.insert(i, r.clone()),
+            }
+        }
+        self.updated_at = self.updated_at.max(other.updated_at);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn tags_are_sorted_and_unique() {
+        let mut a = Annotations::new("AB");
+        assert_eq!(a.sha256, "ab");
+        assert!(a.add_tag("packed"));
+        assert!(a.add_tag("apt"));
+        assert!(!a.add_tag("packed"));
+        assert_eq!(a.tags, ["apt", "packed"]);
+        assert!(a.remove_tag("apt"));
+        assert!(!a.remove_tag("apt"));
+    }
+
+    #[test]
+    fn renames_replace_per_address() {
+        let mut a = Annotations::new("00");
+        a.rename_function(0x2000, "decrypt", None);
+        a.rename_function(0x1000, "main", None);
+        a.rename_function(0x2000, "decrypt_config", Some("ana".into()));
+        assert_eq!(a.function_names.len(), 2);
+        assert_eq!(a.function_name(0x2000), Some("decrypt_config"));
+        assert_eq!(a.function_names[0].address, 0x1000);
+    }
+
+    #[test]
+    fn merge_unions_and_prefers_newer() {
+        let mut ours = Annotations::new("00");
+        ours.add_tag("a");
+        ours.set_verdict(Some("suspicious".into()), None, None);
+        ours.rename_function(0x10, "old", None);
+        let mut theirs = Annotations::new("00");
+        theirs.add_tag("b");
+        theirs.add_comment("looks like a loader", Some(0x10), None);
+        theirs.set_verdict(Some("malicious".into()), None, None);
+        theirs.rename_function(0x10, "new", None);
+        ours.merge(&theirs);
+        assert_eq!(ours.tags, ["a", "b"]);
+        assert_eq!(ours.comments.len(), 1);
+        assert_eq!(ours.verdict.as_ref().unwrap().label, "malicious");
+        assert_eq!(ours.function_name(0x10), Some("new"));
+
+        let json = serde_json::to_string(&ours).unwrap();
+        let back: Annotations = serde_json::from_str(&json).unwrap();
+        assert_eq!(back, ours);
+    }
+}
@@ -96,6 +96,25 @@ impl fmt::Display for TriageErrorKind {
     }
 }
 
+impl From<crate::error::ErrorKind> for TriageErrorKind {
+    fn from(kind: crate::error::ErrorKind) -> Self {
+        use crate::error::ErrorKind;
+        match kind {
+            ErrorKind::UnsupportedFormat => TriageErrorKind::UnsupportedVariant,
+            ErrorKind::TruncatedStructure => TriageErrorKind::Truncated,
+            ErrorKind::BudgetExceeded | ErrorKind::Cancelled => TriageErrorKind::BudgetExceeded,
+            ErrorKind::InvalidInput => TriageErrorKind::IncoherentFields,
+            ErrorKind::ParserBug | ErrorKind::Io | ErrorKind::Other => TriageErrorKind::Other,
+        }
+    }
+}
+
+impl From<&crate::error::GlaurungError> for TriageError {
+    fn from(err: &crate::error::GlaurungError) -> Self {
+        Self::new(err.kind().into(), Some(err.to_string()))
+    }
+}
+
 // Pure Rust constructors and helpers
 impl TriageError {
     pub fn new(kind: TriageErrorKind, message: Option<String>) -> Self {
@@ -1,5 +1,6 @@
 //! Format-specific triage information.
 
+use crate::symbols::analysis::pdb_path::PdbPathLeak;
 use crate::triage::rich_header::RichHeader;
 #[cfg(feature = "python-ext")]
 use pyo3::prelude::*;
@@ -11,6 +12,33 @@ use serde::{Deserialize, Serialize};
 pub struct PeTriageInfo {
     /// Rich Header information, if present.
     pub rich_header: Option<RichHeader>,
+    /// Debug directory summary, if the image has one.
+    #[serde(default)]
+    pub debug: Option<PeDebugInfo>,
+}
+
+/// Summary of a PE debug directory.
+#[derive(Debug, Clone, PartialEq, Default, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct PeDebugInfo {
+    /// Debug entry types in directory order (`codeview`, `pogo`, `repro`, ...).
+    pub entries: Vec<String>,
+    /// PDB path from the CodeView RSDS record.
+    pub pdb_path: Option<String>,
+    /// File offset of `pdb_path`.
+    pub pdb_path_offset: Option<u64>,
+    /// Symbol-server `<GUID><AGE>` key from the CodeView RSDS record.
+    pub pdb_guid_age: Option<String>,
+    /// POGO signature (`LTCG`, `PGO`, ...), for LTCG/PGO builds.
+    pub pogo_signature: Option<String>,
+    /// Section and COFF group names from the POGO record.
+    pub pogo_sections: Vec<String>,
+    /// True if the image was linked deterministically (`/Brepro`).
+    pub repro: bool,
+    /// Repro build hash in hex, when recorded.
+    pub repro_hash: Option<String>,
+    /// What the PDB path discloses about the build environment.
+    pub leaks: Vec<PdbPathLeak>,
 }
 
 /// ELF-specific triage information.
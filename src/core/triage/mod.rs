@@ -1,5 +1,6 @@
 //! Core triage data types organized by submodule.
 
+pub mod annotations;
 pub mod containers;
 pub mod entropy;
 pub mod errors;
@@ -7,10 +8,13 @@ pub mod formats;
 pub mod hints;
 pub mod packers;
 pub mod parsers;
+pub mod provenance;
+pub mod severity;
 pub mod strings;
 pub mod verdict;
 
 // Re-exports for convenient access under crate::core::triage::*
+pub use annotations::{AnalystComment, AnalystVerdict, Annotations, FunctionRename};
 pub use containers::{ContainerChild, ContainerMetadata};
 pub use entropy::{
     EntropyAnalysis, EntropyAnomaly, EntropyClass, EntropySummary, PackedIndicators,
@@ -19,7 +23,10 @@ pub use errors::{TriageError, TriageErrorKind};
 pub use hints::{ConfidenceSignal, SnifferSource, TriageHint};
 pub use packers::PackerMatch;
 pub use parsers::{ParserKind, ParserResult};
+pub use provenance::{EvidenceSpan, Finding, Provenance};
+pub use severity::{Severity, SeverityOverrides};
 pub use strings::{DetectedString, IocSample, StringsSummary};
 pub use verdict::{
     Budgets, SimilaritySummary, TriageVerdict, TriagedArtifact, TriagedArtifactBuilder,
+    TRIAGE_SCHEMA_VERSION,
 };
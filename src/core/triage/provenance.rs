@@ -0,0 +1,260 @@
+//! Provenance and evidence types for reported findings.
+//!
+//! Every claim in a triage report can carry a `Provenance` saying which
+//! pass produced it, which rule (and rule-set version) fired, how confident
+//! the pass was, and which byte ranges of the input support it. Analysts can
+//! use this to justify, reproduce, or discount individual claims.
+
+use crate::core::confidence::ConfidenceBand;
+use crate::core::triage::severity::{Severity, SeverityOverrides};
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+/// A byte range of the input that supports a finding.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct EvidenceSpan {
+    /// File offset of the first supporting byte
+    pub offset: u64,
+    /// Number of supporting bytes
+    pub length: u64,
+    /// Optional description of what the bytes show (e.g. "magic", "UPX!")
+    pub note: Option<String>,
+}
+
+/// Where a finding came from and how much to trust it.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct Provenance {
+    /// Name of the pass or phase that produced the finding (e.g. "packers")
+    pub pass: String,
+    /// Rule or signature identifier that fired, if any
+    pub rule: Option<String>,
+    /// Version of the rule set the rule belongs to, if versioned
+    pub rule_version: Option<String>,
+    /// Version of the tool that produced the finding
+    pub tool_version: String,
+    /// Confidence in [0.0, 1.0], with the semantics of `core::confidence`
+    pub confidence: f32,
+    /// Supporting byte ranges
+    pub evidence: Vec<EvidenceSpan>,
+}
+
+/// A single reported fact together with its provenance.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct Finding {
+    /// Coarse category (e.g. "format", "packer", "container", "overlay")
+    pub category: String,
+    /// The claim itself, e.g. "format=ELF" or "packer=UPX"
+    pub claim: String,
+    /// How the claim was derived
+    pub provenance: Provenance,
+    /// How much the claim matters if true (see `core::triage::severity`)
+    pub severity: Severity,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl EvidenceSpan {
+    #[new]
+    #[pyo3(signature = (offset, length, note=None))]
+    pub fn new_py(offset: u64, length: u64, note: Option<String>) -> Self {
+        Self::new(offset, length, note)
+    }
+
+    #[getter]
+    fn offset(&self) -> u64 {
+        self.offset
+    }
+    #[getter]
+    fn length(&self) -> u64 {
+        self.length
+    }
+    #[getter]
+    fn note(&self) -> Option<String> {
+        self.note.clone()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "EvidenceSpan(offset={:#x}, length={}, note={:?})",
+            self.offset, self.length, self.note
+        )
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl Provenance {
+    #[getter]
+    fn pass_name(&self) -> String {
+        self.pass.clone()
+    }
+    #[getter]
+    fn rule(&self) -> Option<String> {
+        self.rule.clone()
+    }
+    #[getter]
+    fn rule_version(&self) -> Option<String> {
+        self.rule_version.clone()
+    }
+    #[getter]
+    fn tool_version(&self) -> String {
+        self.tool_version.clone()
+    }
+    #[getter]
+    fn confidence(&self) -> f32 {
+        self.confidence
+    }
+    #[getter]
+    fn evidence(&self) -> Vec<EvidenceSpan> {
+        self.evidence.clone()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "Provenance(pass={:?}, rule={:?}, confidence={:.2}, evidence={})",
+            self.pass,
+            self.rule,
+            self.confidence,
+            self.evidence.len()
+        )
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl Finding {
+    #[getter]
+    fn category(&self) -> String {
+        self.category.clone()
+    }
+    #[getter]
+    fn claim(&self) -> String {
+        self.claim.clone()
+    }
+    #[getter]
+    fn provenance(&self) -> Provenance {
+        self.provenance.clone()
+    }
+    /// Confidence band: "speculative", "low", "medium", "high", or "certain".
+    #[getter]
+    #[pyo3(name = "band")]
+    fn band_py(&self) -> &'static str {
+        self.band().label()
+    }
+    /// Severity: "info", "low", "medium", "high", or "critical".
+    #[getter]
+    #[pyo3(name = "severity")]
+    fn severity_py(&self) -> &'static str {
+        self.severity.label()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "Finding(category={:?}, claim={:?}, severity={}, pass={:?})",
+            self.category, self.claim, self.severity, self.provenance.pass
+        )
+    }
+}
+
+// Pure Rust constructors and helpers
+impl EvidenceSpan {
+    pub fn new(offset: u64, length: u64, note: Option<String>) -> Self {
+        Self {
+            offset,
+            length,
+            note,
+        }
+    }
+}
+
+impl Provenance {
+    /// Provenance for `pass` with the given confidence (clamped to [0, 1]).
+    pub fn new<S: Into<String>>(pass: S, confidence: f32) -> Self {
+        Self {
+            pass: pass.into(),
+            rule: None,
+            rule_version: None,
+            tool_version: env!("CARGO_PKG_VERSION").to_string(),
+            confidence: crate::core::confidence::clamp(confidence),
+            evidence: Vec::new(),
+        }
+    }
+
+    /// Record the rule that fired and the version of its rule set.
+    pub fn with_rule<S: Into<String>>(mut self, rule: S, version: Option<&str>) -> Self {
+        self.rule = Some(rule.into());
+        self.rule_version = version.map(str::to_string);
+        self
+    }
+
+    /// Append a supporting byte range.
+    pub fn with_evidence(mut self, offset: u64, length: u64, note: Option<&str>) -> Self {
+        self.evidence
+            .push(EvidenceSpan::new(offset, length, note.map(str::to_string)));
+        self
+    }
+}
+
+impl Finding {
+    pub fn new<C: Into<String>, S: Into<String>>(
+        category: C,
+        claim: S,
+        provenance: Provenance,
+    ) -> Self {
+        let category = category.into();
+        Self {
+            severity: Severity::for_category(&category),
+            category,
+            claim: claim.into(),
+            provenance,
+        }
+    }
+
+    /// Replace the category-default severity.
+    pub fn with_severity(mut self, severity: Severity) -> Self {
+        self.severity = severity;
+        self
+    }
+
+    /// Apply the override for this finding's rule or category, if any.
+    pub fn apply_severity_override(&mut self, overrides: &SeverityOverrides) {
+        if let Some(s) = overrides.lookup(self.provenance.rule.as_deref(), &self.category) {
+            self.severity = s;
+        }
+    }
+
+    /// Qualitative band of this finding's confidence.
+    pub fn band(&self) -> ConfidenceBand {
+        ConfidenceBand::of(self.provenance.confidence)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn provenance_builder_records_rule_and_evidence() {
+        let p = Provenance::new("packers", 1.4)
+            .with_rule("UPX", Some("1"))
+            .with_evidence(0x200, 4, Some("UPX!"));
+        assert_eq!(p.confidence, 1.0);
+        assert_eq!(p.rule.as_deref(), Some("UPX"));
+        assert_eq!(p.rule_version.as_deref(), Some("1"));
+        assert_eq!(p.tool_version, env!("CARGO_PKG_VERSION"));
+        assert_eq!(
+            p.evidence,
+            vec![EvidenceSpan::new(0x200, 4, Some("UPX!".into()))]
+        );
+
+        let f = Finding::new("packer", "packer=UPX", p);
+        assert_eq!(f.severity, Severity::Medium);
+        let json = serde_json::to_string(&f).unwrap();
+        let back: Finding = serde_json::from_str(&json).unwrap();
+        assert_eq!(back, f);
+    }
+}
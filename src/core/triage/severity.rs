@@ -0,0 +1,195 @@
+//! Severity of reported findings.
+//!
+//! Confidence says how likely a claim is to be true; severity says how much
+//! it matters if it is. Detectors assign a severity to every `Finding`
+//! (category defaults from `Severity::for_category`, raised or lowered per
+//! rule where the detector knows better), and deployments can remap either
+//! through `SeverityOverrides`, so CI gates and alerting key off one ordered
+//! level instead of matching finding names.
+
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fmt;
+use std::str::FromStr;
+
+/// Ordered severity level; `Info < Low < Medium < High < Critical`.
+#[derive(
+    Debug, Clone, Copy, Default, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize,
+)]
+#[serde(rename_all = "snake_case")]
+pub enum Severity {
+    /// Descriptive facts (format, entry point, type hints)
+    #[default]
+    Info,
+    /// Worth noting, rarely actionable alone (containers, overlays)
+    Low,
+    /// Hinders analysis or commonly abused (packers)
+    Medium,
+    /// Strongly associated with malicious or unsafe binaries (W^X mappings)
+    High,
+    /// Reserved for overrides and future detectors with direct impact
+    Critical,
+}
+
+impl Severity {
+    /// All levels, lowest first.
+    pub const ALL: [Severity; 5] = [
+        Severity::Info,
+        Severity::Low,
+        Severity::Medium,
+        Severity::High,
+        Severity::Critical,
+    ];
+
+    pub fn label(self) -> &'static str {
+        match self {
+            Severity::Info => "info",
+            Severity::Low => "low",
+            Severity::Medium => "medium",
+            Severity::High => "high",
+            Severity::Critical => "critical",
+        }
+    }
+
+    /// Default severity for findings of `category`, used by `Finding::new`
+    /// and when migrating reports written before severities existed.
+    pub fn for_category(category: &str) -> Self {
+        match category {
+            "wx_mapping" => Severity::High,
+            "packer" | "embedded_config" => Severity::Medium,
+            "container" | "overlay" | "pdb_path_leak" => Severity::Low,
+            _ => Severity::Info,
+        }
+    }
+}
+
+impl fmt::Display for Severity {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.label())
+    }
+}
+
+impl FromStr for Severity {
+    type Err = String;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        let s = s.trim().to_ascii_lowercase();
+        Severity::ALL
+            .into_iter()
+            .find(|l| l.label() == s)
+            .ok_or_else(|| {
+                format!(
+                    "unknown severity '{}' (expected info, low, medium, high, or critical)",
+                    s
+                )
+            })
+    }
+}
+
+/// Severity remapping keyed by rule id (e.g. "memory:wx", "UPX") or finding
+/// category (e.g. "packer"). A rule key wins over a category key.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(transparent)]
+pub struct SeverityOverrides(pub BTreeMap<String, Severity>);
+
+impl SeverityOverrides {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.0.is_empty()
+    }
+
+    /// Map `key` (a rule id or category) to `severity`.
+    pub fn set<S: Into<String>>(&mut self, key: S, severity: Severity) -> &mut Self {
+        self.0.insert(key.into(), severity);
+        self
+    }
+
+    /// Override for a finding with `rule` and `category`, if any.
+    pub fn lookup(&self, rule: Option<&str>, category: &str) -> Option<Severity> {
+        rule.and_then(|r| self.0.get(r))
+            .or_else(|| self.0.get(category))
+            .copied()
+    }
+
+    /// Parse a `KEY=LEVEL` item, as given on the command line.
+    pub fn parse_item(spec: &str) -> Result<(String, Severity), String> {
+        let (key, level) = spec
+            .rsplit_once('=')
+            .ok_or_else(|| format!("expected KEY=LEVEL, got '{}'", spec))?;
+        let key = key.trim();
+        if key.is_empty() {
+            return Err(format!("empty key in '{}'", spec));
+        }
+        Ok((key.to_string(), level.parse()?))
+    }
+}
+
+/// Parse a severity name; the error lists the accepted levels.
+#[cfg(feature = "python-ext")]
+#[pyfunction]
+#[pyo3(name = "parse_severity")]
+pub fn parse_severity_py(level: &str) -> PyResult<&'static str> {
+    level
+        .parse::<Severity>()
+        .map(Severity::label)
+        .map_err(pyo3::exceptions::PyValueError::new_err)
+}
+
+#[cfg(feature = "python-ext")]
+pub(crate) fn overrides_from_py(map: BTreeMap<String, String>) -> PyResult<SeverityOverrides> {
+    let mut out = SeverityOverrides::new();
+    for (key, level) in map {
+        let level = level
+            .parse()
+            .map_err(pyo3::exceptions::PyValueError::new_err)?;
+        out.set(key, level);
+    }
+    Ok(out)
+}
+
+#[cfg(feature = "python-ext")]
+pub(crate) fn overrides_to_py(o: &SeverityOverrides) -> BTreeMap<String, String> {
+    o.0.iter()
+        .map(|(k, v)| (k.clone(), v.label().to_string()))
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn levels_are_ordered_and_round_trip() {
+        assert!(Severity::Info < Severity::Low);
+        assert!(Severity::High < Severity::Critical);
+        for l in Severity::ALL {
+            assert_eq!(l.label().parse::<Severity>(), Ok(l));
+        }
+        assert_eq!(" HIGH ".parse::<Severity>(), Ok(Severity::High));
+        assert!("severe".parse::<Severity>().is_err());
+        assert_eq!(
+            serde_json::to_string(&Severity::Critical).unwrap(),
+            "\"critical\""
+        );
+    }
+
+    #[test]
+    fn rule_overrides_beat_category_overrides() {
+        let mut o = SeverityOverrides::new();
+        o.set("packer", Severity::Low).set("UPX", Severity::Info);
+        assert_eq!(o.lookup(Some("UPX"), "packer"), Some(Severity::Info));
+        assert_eq!(o.lookup(Some("ASPack"), "packer"), Some(Severity::Low));
+        assert_eq!(o.lookup(None, "format"), None);
+        assert_eq!(
+            SeverityOverrides::parse_item("memory:wx=critical"),
+            Ok(("memory:wx".to_string(), Severity::Critical))
+        );
+        assert!(SeverityOverrides::parse_item("memory:wx").is_err());
+        assert!(SeverityOverrides::parse_item("=high").is_err());
+    }
+}
@@ -1,11 +1,14 @@
 //! Verdict and artifact types for triage results.
 
+use super::annotations::Annotations;
 use super::containers::ContainerChild;
 use super::entropy::{EntropyAnalysis, EntropySummary};
 use super::errors::TriageError;
 use super::hints::{ConfidenceSignal, TriageHint};
 use super::packers::PackerMatch;
 use super::parsers::ParserResult;
+use super::provenance::Finding;
+use super::severity::{Severity, SeverityOverrides};
 use super::strings::StringsSummary;
 use crate::core::binary::{Arch, Endianness, Format};
 use crate::core::triage::formats::FormatSpecificTriage;
@@ -168,6 +171,10 @@ impl TriageVerdict {
     }
 }
 
+/// Schema version written into new `TriagedArtifact` reports. Bump it (and
+/// add a step to `triage::migrate`) whenever the serialized shape changes.
+pub const TRIAGE_SCHEMA_VERSION: &str = "1.11";
+
 /// Overall triage report for an input artifact.
 #[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
 #[cfg_attr(feature = "python-ext", pyclass)]
@@ -213,6 +220,27 @@ pub struct TriagedArtifact {
     pub heuristic_arch: Option<Vec<(Arch, f32)>>,
     /// Optional bounded disassembly preview (rendered lines)
     pub disasm_preview: Option<Vec<String>>,
+    /// Findings with pass/rule/evidence provenance
+    #[serde(default)]
+    pub findings: Option<Vec<Finding>>,
+    /// Analyst annotations recorded for these bytes, if merged from a store
+    #[serde(default)]
+    pub annotations: Option<Annotations>,
+    /// Build identifiers (GNU build-id, Mach-O UUID, CodeView, Go build ID)
+    #[serde(default)]
+    pub build_ids: Option<Vec<crate::triage::build_ids::BuildId>>,
+    /// Embedded open-source licenses (SPDX) and copyright notices
+    #[serde(default)]
+    pub licenses: Option<crate::triage::licenses::LicenseReport>,
+    /// Classifier verdict on the primary source language
+    #[serde(default)]
+    pub source_language: Option<crate::triage::source_language::SourceLanguageGuess>,
+    /// Go toolchain, module graph and runtime metadata counts
+    #[serde(default)]
+    pub go: Option<crate::triage::go_info::GoBinaryInfo>,
+    /// Children extracted from nested containers, each triaged in turn
+    #[serde(default)]
+    pub unpacked: Option<crate::triage::unpack::UnpackReport>,
 }
 
 #[cfg(feature = "python-ext")]
@@ -244,7 +272,14 @@ impl TriagedArtifact {
         errors=None,
         heuristic_endianness=None,
         heuristic_arch=None,
-        disasm_preview=None
+        disasm_preview=None,
+        findings=None,
+        annotations=None,
+        build_ids=None,
+        licenses=None,
+        source_language=None,
+        go=None,
+        unpacked=None
     ))]
     pub fn new_py(
         schema_version: String,
@@ -271,6 +306,13 @@ impl TriagedArtifact {
         heuristic_endianness: Option<(Endianness, f32)>,
         heuristic_arch: Option<Vec<(Arch, f32)>>,
         disasm_preview: Option<Vec<String>>,
+        findings: Option<Vec<Finding>>,
+        annotations: Option<Annotations>,
+        build_ids: Option<Vec<crate::triage::build_ids::BuildId>>,
+        licenses: Option<crate::triage::licenses::LicenseReport>,
+        source_language: Option<crate::triage::source_language::SourceLanguageGuess>,
+        go: Option<crate::triage::go_info::GoBinaryInfo>,
+        unpacked: Option<crate::triage::unpack::UnpackReport>,
     ) -> Self {
         Self {
             schema_version,
@@ -297,6 +339,13 @@ impl TriagedArtifact {
             heuristic_endianness,
             heuristic_arch,
             disasm_preview,
+            findings,
+            annotations,
+            build_ids,
+            licenses,
+            source_language,
+            go,
+            unpacked,
         }
     }
 
@@ -308,6 +357,40 @@ impl TriagedArtifact {
         Some(crate::similarity::ctph_similarity(a, b))
     }
 
+    /// Copy keeping only claims with confidence >= `min_confidence` and,
+    /// if `min_severity` is given, findings at or above that severity.
+    #[pyo3(name = "filtered", signature = (min_confidence=0.0, min_severity=None))]
+    pub fn filtered_py(&self, min_confidence: f32, min_severity: Option<&str>) -> PyResult<Self> {
+        let mut out = self.clone();
+        out.retain_min_confidence(min_confidence);
+        if let Some(level) = min_severity {
+            let level: Severity = level
+                .parse()
+                .map_err(pyo3::exceptions::PyValueError::new_err)?;
+            out.retain_min_severity(level);
+        }
+        Ok(out)
+    }
+
+    /// Copy with finding severities remapped by `{rule or category: level}`.
+    #[pyo3(name = "with_severity_overrides")]
+    pub fn with_severity_overrides_py(
+        &self,
+        overrides: std::collections::BTreeMap<String, String>,
+    ) -> PyResult<Self> {
+        let overrides = super::severity::overrides_from_py(overrides)?;
+        let mut out = self.clone();
+        out.apply_severity_overrides(&overrides);
+        Ok(out)
+    }
+
+    /// Highest finding severity, or None without findings.
+    #[getter]
+    #[pyo3(name = "max_severity")]
+    fn max_severity_py(&self) -> Option<&'static str> {
+        self.max_severity().map(Severity::label)
+    }
+
     /// Serialize to JSON string.
     pub fn to_json(&self) -> PyResult<String> {
         serde_json::to_string(self).map_err(|e| {
@@ -416,6 +499,34 @@ impl TriagedArtifact {
     fn heuristic_arch(&self) -> Option<Vec<(Arch, f32)>> {
         self.heuristic_arch.clone()
     }
+    #[getter]
+    fn findings(&self) -> Option<Vec<Finding>> {
+        self.findings.clone()
+    }
+    #[getter]
+    fn annotations(&self) -> Option<Annotations> {
+        self.annotations.clone()
+    }
+    #[getter]
+    fn build_ids(&self) -> Option<Vec<crate::triage::build_ids::BuildId>> {
+        self.build_ids.clone()
+    }
+    #[getter]
+    fn licenses(&self) -> Option<crate::triage::licenses::LicenseReport> {
+        self.licenses.clone()
+    }
+    #[getter]
+    fn source_language(&self) -> Option<crate::triage::source_language::SourceLanguageGuess> {
+        self.source_language.clone()
+    }
+    #[getter]
+    fn go(&self) -> Option<crate::triage::go_info::GoBinaryInfo> {
+        self.go.clone()
+    }
+    #[getter]
+    fn unpacked(&self) -> Option<crate::triage::unpack::UnpackReport> {
+        self.unpacked.clone()
+    }
 }
 
 // Pure Rust constructors and helpers
@@ -513,6 +624,64 @@ mod tests {
         assert!(artifact.verdicts.is_empty());
     }
 
+    #[test]
+    fn retain_min_confidence_filters_scored_claims() {
+        use crate::core::triage::{Finding, Provenance};
+        let verdict = |c| {
+            TriageVerdict::try_new(Format::ELF, Arch::X86_64, 64, Endianness::Little, c, None)
+                .unwrap()
+        };
+        let mut artifact = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(1)
+            .with_verdicts(vec![verdict(0.9), verdict(0.3)])
+            .with_packers(Some(vec![
+                PackerMatch::new("UPX".into(), 0.55),
+                PackerMatch::new("Packed".into(), 0.45),
+            ]))
+            .with_findings(Some(vec![Finding::new(
+                "x",
+                "weak",
+                Provenance::new("p", 0.1),
+            )]))
+            .build()
+            .unwrap();
+        artifact.retain_min_confidence(crate::core::confidence::MEDIUM);
+        assert_eq!(artifact.verdicts.len(), 1);
+        assert_eq!(artifact.packers.as_ref().unwrap()[0].name, "UPX");
+        assert_eq!(artifact.packers.as_ref().unwrap().len(), 1);
+        assert!(artifact.findings.as_ref().unwrap().is_empty());
+    }
+
+    #[test]
+    fn severity_overrides_and_filtering() {
+        use crate::core::triage::{Finding, Provenance};
+        let mut artifact = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(1)
+            .with_findings(Some(vec![
+                Finding::new("format", "format=ELF", Provenance::new("headers", 1.0)),
+                Finding::new(
+                    "packer",
+                    "packer=UPX",
+                    Provenance::new("packers", 0.9).with_rule("UPX", None),
+                ),
+            ]))
+            .build()
+            .unwrap();
+        assert_eq!(artifact.max_severity(), Some(Severity::Medium));
+        let mut o = SeverityOverrides::new();
+        o.set("UPX", Severity::Critical);
+        artifact.apply_severity_overrides(&o);
+        assert_eq!(artifact.max_severity(), Some(Severity::Critical));
+        artifact.retain_min_severity(Severity::Low);
+        let kept = artifact.findings.as_ref().unwrap();
+        assert_eq!(kept.len(), 1);
+        assert_eq!(kept[0].category, "packer");
+    }
+
     #[test]
     fn test_builder_pattern_missing_required_fields() {
         let result = TriagedArtifact::builder()
@@ -565,6 +734,7 @@ pub struct TriagedArtifactBuilder {
     heuristic_endianness: Option<(Endianness, f32)>,
     heuristic_arch: Option<Vec<(Arch, f32)>>,
     disasm_preview: Option<Vec<String>>,
+    findings: Option<Vec<Finding>>,
 }
 
 impl TriagedArtifactBuilder {
@@ -732,12 +902,20 @@ impl TriagedArtifactBuilder {
         self
     }
 
+    /// Sets the findings with provenance.
+    pub fn with_findings(mut self, findings: Option<Vec<Finding>>) -> Self {
+        self.findings = findings;
+        self
+    }
+
     /// Builds the TriagedArtifact. Returns an error if required fields are missing.
     pub fn build(self) -> Result<TriagedArtifact, String> {
         let id = self.id.ok_or("id is required")?;
         let path = self.path.ok_or("path is required")?;
         let size_bytes = self.size_bytes.ok_or("size_bytes is required")?;
-        let schema_version = self.schema_version.unwrap_or_else(|| "1.2".into());
+        let schema_version = self
+            .schema_version
+            .unwrap_or_else(|| TRIAGE_SCHEMA_VERSION.into());
 
         Ok(TriagedArtifact {
             schema_version,
@@ -764,6 +942,13 @@ impl TriagedArtifactBuilder {
             heuristic_endianness: self.heuristic_endianness,
             heuristic_arch: self.heuristic_arch,
             disasm_preview: self.disasm_preview,
+            findings: self.findings,
+            annotations: None,
+            build_ids: None,
+            licenses: None,
+            source_language: None,
+            go: None,
+            unpacked: None,
         })
     }
 }
@@ -774,6 +959,44 @@ impl TriagedArtifact {
         TriagedArtifactBuilder::new()
     }
 
+    /// Drop verdicts, packer matches, architecture guesses, and findings
+    /// whose confidence is below `min` (see `core::confidence`). Summaries
+    /// without a confidence are kept.
+    pub fn retain_min_confidence(&mut self, min: f32) {
+        self.verdicts.retain(|v| v.confidence >= min);
+        if let Some(p) = &mut self.packers {
+            p.retain(|m| m.confidence >= min);
+        }
+        if let Some(a) = &mut self.heuristic_arch {
+            a.retain(|(_, c)| *c >= min);
+        }
+        if let Some(f) = &mut self.findings {
+            f.retain(|f| f.provenance.confidence >= min);
+        }
+    }
+
+    /// Drop findings below severity `min`.
+    pub fn retain_min_severity(&mut self, min: Severity) {
+        if let Some(f) = &mut self.findings {
+            f.retain(|f| f.severity >= min);
+        }
+    }
+
+    /// Remap finding severities (see `SeverityOverrides`).
+    pub fn apply_severity_overrides(&mut self, overrides: &SeverityOverrides) {
+        if overrides.is_empty() {
+            return;
+        }
+        for f in self.findings.iter_mut().flatten() {
+            f.apply_severity_override(overrides);
+        }
+    }
+
+    /// Highest finding severity, or `None` without findings.
+    pub fn max_severity(&self) -> Option<Severity> {
+        self.findings.iter().flatten().map(|f| f.severity).max()
+    }
+
     #[allow(clippy::too_many_arguments)]
     pub fn new(
         id: String,
@@ -799,7 +1022,7 @@ impl TriagedArtifact {
     ) -> Self {
         // Use the builder internally for consistency
         TriagedArtifact::builder()
-            .with_schema_version("1.2")
+            .with_schema_version(TRIAGE_SCHEMA_VERSION)
             .with_id(id)
             .with_path(path)
             .with_size_bytes(size_bytes)
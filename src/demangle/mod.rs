@@ -1,18 +1,37 @@
-//! Demangler helpers for Rust and C++ (Itanium) symbols.
+//! Demangler helpers for Rust (v0 and legacy), C++ (Itanium and MSVC) and
+//! Swift symbols.
 //!
-//! MSVC demangling is left as a future enhancement; we detect MSVC-style
-//! patterns but only return the original name for now.
+//! [`demangle_one`] is the single entry point: it recognises the scheme,
+//! returns the readable name and the [`SymbolFlavor`] it was mangled with,
+//! and keeps the original so callers can report both. Mach-O's extra
+//! leading underscore (`__Z…`, `_$s…`) is accepted.
 
 use crate::strings::patterns;
 
+pub mod swift;
+
 #[derive(Debug, Clone, Copy, PartialEq, Eq)]
 pub enum SymbolFlavor {
     Rust,
     Itanium,
     Msvc,
+    Swift,
     Unknown,
 }
 
+impl SymbolFlavor {
+    /// Lowercase tag used in JSON output and the Python bindings.
+    pub fn as_str(self) -> &'static str {
+        match self {
+            SymbolFlavor::Rust => "rust",
+            SymbolFlavor::Itanium => "itanium",
+            SymbolFlavor::Msvc => "msvc",
+            SymbolFlavor::Swift => "swift",
+            SymbolFlavor::Unknown => "unknown",
+        }
+    }
+}
+
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct DemangleResult {
     pub original: String,
@@ -24,7 +43,10 @@ pub fn detect_flavor(s: &str) -> SymbolFlavor {
     if rustc_demangle::try_demangle(s).is_ok() {
         return SymbolFlavor::Rust;
     }
-    if patterns::RE_ITA_MANGLED.is_match(s) {
+    if swift::is_mangled(s) {
+        return SymbolFlavor::Swift;
+    }
+    if patterns::RE_ITA_MANGLED.is_match(itanium_name(s)) {
         return SymbolFlavor::Itanium;
     }
     if patterns::RE_MSVC_MANGLED.is_match(s) {
@@ -44,9 +66,18 @@ pub fn demangle_one(s: &str) -> Option<DemangleResult> {
             flavor: SymbolFlavor::Rust,
         });
     }
+    // Swift demangler
+    if let Some(out) = swift::demangle(s) {
+        return Some(DemangleResult {
+            original: s.to_string(),
+            demangled: out,
+            flavor: SymbolFlavor::Swift,
+        });
+    }
     // C++ (Itanium) demangler
-    if patterns::RE_ITA_MANGLED.is_match(s) {
-        if let Ok(sym) = cpp_demangle::Symbol::new(s) {
+    let ita = itanium_name(s);
+    if patterns::RE_ITA_MANGLED.is_match(ita) {
+        if let Ok(sym) = cpp_demangle::Symbol::new(ita) {
             let out = sym.to_string();
             return Some(DemangleResult {
                 original: s.to_string(),
@@ -68,6 +99,19 @@ pub fn demangle_one(s: &str) -> Option<DemangleResult> {
     None
 }
 
+/// Demangled form of `s`, or `s` itself when it is not mangled.
+pub fn display_name(s: &str) -> String {
+    demangle_one(s).map_or_else(|| s.to_string(), |r| r.demangled)
+}
+
+/// Mach-O prefixes every C-level symbol with `_`, turning `_Z…` into `__Z…`.
+fn itanium_name(s: &str) -> &str {
+    match s.strip_prefix('_') {
+        Some(rest) if rest.starts_with("_Z") => rest,
+        _ => s,
+    }
+}
+
 /// Demangle a stream of candidate names with a cap on results.
 pub fn demangle_many<'a, I: IntoIterator<Item = &'a str>>(
     iter: I,
@@ -94,5 +138,32 @@ mod tests {
         assert_ne!(detect_flavor("_Z3foov"), SymbolFlavor::Unknown);
         assert_ne!(detect_flavor("_ZN3foo3barE"), SymbolFlavor::Unknown);
         // MSVC patterns vary; basic detection is best-effort and optional.
+        assert_eq!(detect_flavor("$s4main3fooyyF"), SymbolFlavor::Swift);
+    }
+
+    #[test]
+    fn demangles_every_scheme_through_one_entry_point() {
+        let cases = [
+            ("_ZN3foo3barEv", "foo::bar()", SymbolFlavor::Itanium),
+            ("__ZN3foo3barEv", "foo::bar()", SymbolFlavor::Itanium),
+            (
+                "_RNvCs1234_7mycrate3foo",
+                "mycrate[3c1c0]::foo",
+                SymbolFlavor::Rust,
+            ),
+            (
+                "_$s4main3FooV3baryyF",
+                "main.Foo.bar() -> ()",
+                SymbolFlavor::Swift,
+            ),
+        ];
+        for (raw, pretty, flavor) in cases {
+            let r = demangle_one(raw).unwrap_or_else(|| panic!("{raw} not demangled"));
+            assert_eq!(r.original, raw);
+            assert_eq!(r.demangled, pretty, "{raw}");
+            assert_eq!(r.flavor, flavor, "{raw}");
+        }
+        assert!(demangle_one("printf").is_none());
+        assert_eq!(display_name("printf"), "printf");
     }
 }
@@ -0,0 +1,1081 @@
+//! Swift symbol demangler (Swift 5 `$s` mangling, common subset).
+//!
+//! Swift manglings are postfix: operands are pushed on a stack and each
+//! operator pops what it needs, with identifiers and nominal types recorded
+//! for back-references (`A…` substitutions, and word substitutions inside
+//! identifiers). This covers what dominates real binaries — functions,
+//! methods, initializers, properties and their accessors, closures,
+//! extensions, simple generics, and the metadata / descriptor / witness
+//! table symbols the runtime references — and renders them the way
+//! `swift-demangle` does. Anything outside that subset (private and local
+//! declaration names, punycode identifiers, thunks, specializations)
+//! returns `None` so callers keep the raw name rather than a wrong one.
+
+const PREFIXES: &[&str] = &["_$s", "$s", "_$S", "$S", "_$e", "$e"];
+const MAX_WORDS: usize = 26;
+const MAX_REPEAT: u64 = 2048;
+/// Longer inputs are specializations or thunks, outside this subset; the
+/// cap also bounds recursion when printing nested types.
+const MAX_LEN: usize = 4096;
+
+#[derive(Debug, Clone)]
+enum Node {
+    Ident(String),
+    Module(String),
+    /// A nominal declaration: kind (`C` class, `V` struct, `O` enum,
+    /// `P` protocol, `a` type alias), parent context and name.
+    Nominal(char, Box<Node>, String),
+    /// `(extension in <module>):<nominal>`
+    Extension(String, Box<Node>),
+    /// Wrapper marking a node usable wherever a type is expected.
+    Type(Box<Node>),
+    Tuple(Vec<Element>),
+    Function(FnType),
+    BoundGeneric(Box<Node>, Vec<Node>),
+    GenericParam(u64, u64),
+    /// `inout`, `__owned` or `__shared` parameter.
+    Modified(&'static str, Box<Node>),
+    EmptyList,
+    FirstElement,
+    Variadic,
+    Throws,
+    Async,
+    Sendable,
+    /// Generic parameter conforming to a protocol.
+    Requirement(Box<Node>, Box<Node>),
+    Signature(Vec<u64>, Vec<(Node, Node)>),
+    Entity(Entity),
+    Static(Box<Node>),
+    /// A fully rendered top-level symbol.
+    Global(String),
+}
+
+#[derive(Debug, Clone)]
+struct Element {
+    label: Option<String>,
+    ty: Node,
+    variadic: bool,
+}
+
+#[derive(Debug, Clone)]
+struct FnType {
+    params: Box<Node>,
+    result: Box<Node>,
+    is_async: bool,
+    throws: bool,
+    sendable: bool,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq)]
+enum EntityKind {
+    Function,
+    Variable(&'static str),
+    Allocator,
+    Constructor,
+    Deallocator,
+    Destructor,
+    Closure(u64),
+}
+
+#[derive(Debug, Clone)]
+struct Entity {
+    kind: EntityKind,
+    ctx: Box<Node>,
+    name: String,
+    labels: Option<Vec<Option<String>>>,
+    ty: Option<Box<Node>>,
+    sig: Option<Box<Node>>,
+}
+
+/// Whether `s` carries a Swift 5 mangling prefix.
+pub fn is_mangled(s: &str) -> bool {
+    PREFIXES
+        .iter()
+        .any(|p| s.len() > p.len() && s.starts_with(p))
+}
+
+/// Demangle a Swift symbol, or `None` if it is not one this demangler
+/// fully understands.
+pub fn demangle(s: &str) -> Option<String> {
+    if s.len() > MAX_LEN {
+        return None;
+    }
+    let body = PREFIXES.iter().find_map(|p| s.strip_prefix(p))?;
+    let mut d = Demangler {
+        text: body.as_bytes(),
+        pos: 0,
+        stack: Vec::new(),
+        subs: Vec::new(),
+        words: Vec::new(),
+    };
+    while d.pos < d.text.len() {
+        let node = d.operator()?;
+        d.stack.push(node);
+    }
+    if d.stack.len() != 1 {
+        return None;
+    }
+    match d.stack.pop()? {
+        Node::Global(s) => Some(s),
+        n @ (Node::Entity(_) | Node::Static(_)) => Some(print_entity(&n)),
+        _ => None,
+    }
+}
+
+struct Demangler<'a> {
+    text: &'a [u8],
+    pos: usize,
+    stack: Vec<Node>,
+    subs: Vec<Node>,
+    words: Vec<String>,
+}
+
+impl Demangler<'_> {
+    fn peek(&self) -> Option<u8> {
+        self.text.get(self.pos).copied()
+    }
+
+    fn next(&mut self) -> Option<u8> {
+        let c = self.peek()?;
+        self.pos += 1;
+        Some(c)
+    }
+
+    fn next_if(&mut self, c: u8) -> bool {
+        if self.peek() == Some(c) {
+            self.pos += 1;
+            true
+        } else {
+            false
+        }
+    }
+
+    fn natural(&mut self) -> Option<u64> {
+        let start = self.pos;
+        while self.peek().is_some_and(|c| c.is_ascii_digit()) {
+            self.pos += 1;
+        }
+        std::str::from_utf8(&self.text[start..self.pos])
+            .ok()?
+            .parse()
+            .ok()
+    }
+
+    /// `_` is 0, `<n>_` is n + 1.
+    fn index(&mut self) -> Option<u64> {
+        if self.next_if(b'_') {
+            return Some(0);
+        }
+        let n = self.natural()?;
+        if !self.next_if(b'_') {
+            return None;
+        }
+        n.checked_add(1)
+    }
+
+    fn pop(&mut self) -> Option<Node> {
+        self.stack.pop()
+    }
+
+    fn pop_if(&mut self, pred: impl Fn(&Node) -> bool) -> Option<Node> {
+        if self.stack.last().is_some_and(&pred) {
+            self.stack.pop()
+        } else {
+            None
+        }
+    }
+
+    fn pop_type(&mut self) -> Option<Node> {
+        self.pop_if(|n| matches!(n, Node::Type(_)))
+    }
+
+    fn pop_module(&mut self) -> Option<String> {
+        match self.pop_if(|n| matches!(n, Node::Ident(_) | Node::Module(_)))? {
+            Node::Ident(m) | Node::Module(m) => Some(m),
+            _ => None,
+        }
+    }
+
+    fn pop_context(&mut self) -> Option<Node> {
+        if let Some(m) = self.pop_module() {
+            return Some(Node::Module(m));
+        }
+        match self.pop()? {
+            Node::Type(inner) if matches!(*inner, Node::Nominal(..)) => Some(*inner),
+            n @ (Node::Extension(..) | Node::Entity(_) | Node::Static(_)) => Some(n),
+            _ => None,
+        }
+    }
+
+    fn pop_name(&mut self) -> Option<String> {
+        match self.pop_if(|n| matches!(n, Node::Ident(_)))? {
+            Node::Ident(s) => Some(s),
+            _ => None,
+        }
+    }
+
+    fn operator(&mut self) -> Option<Node> {
+        let c = self.next()?;
+        match c {
+            b'0'..=b'9' => {
+                self.pos -= 1;
+                self.identifier()
+            }
+            b'A' => self.multi_substitution(),
+            b'S' => self.standard_substitution(),
+            b's' => Some(Node::Module("Swift".into())),
+            b'C' | b'V' | b'O' | b'P' | b'a' => {
+                let name = self.pop_name()?;
+                let ctx = self.pop_context()?;
+                let ty = Node::Type(Box::new(Node::Nominal(c as char, Box::new(ctx), name)));
+                self.subs.push(ty.clone());
+                Some(ty)
+            }
+            b'E' => {
+                let module = self.pop_module()?;
+                let ext = match self.pop_type()? {
+                    Node::Type(inner) if matches!(*inner, Node::Nominal(..)) => inner,
+                    _ => return None,
+                };
+                Some(Node::Extension(module, ext))
+            }
+            b'y' => Some(Node::EmptyList),
+            b'_' => Some(Node::FirstElement),
+            b'd' => Some(Node::Variadic),
+            b'K' => Some(Node::Throws),
+            b'Y' => match self.next()? {
+                b'a' => Some(Node::Async),
+                b'b' => Some(Node::Sendable),
+                _ => None,
+            },
+            b't' => self.tuple(),
+            b'c' => Some(Node::Type(Box::new(Node::Function(self.function_type()?)))),
+            b'z' | b'n' | b'h' => {
+                let ty = self.pop_type()?;
+                let m = match c {
+                    b'z' => "inout",
+                    b'n' => "__owned",
+                    _ => "__shared",
+                };
+                Some(Node::Type(Box::new(Node::Modified(m, Box::new(ty)))))
+            }
+            b'G' => self.bound_generic(),
+            b'x' => Some(Node::Type(Box::new(Node::GenericParam(0, 0)))),
+            b'q' => {
+                let (depth, index) = self.generic_param_index()?;
+                Some(Node::Type(Box::new(Node::GenericParam(depth, index))))
+            }
+            b'R' => {
+                let (depth, index) = self.generic_param_index()?;
+                let proto = self.pop_protocol()?;
+                Some(Node::Requirement(
+                    Box::new(Node::GenericParam(depth, index)),
+                    Box::new(proto),
+                ))
+            }
+            b'l' => self.signature(vec![1]),
+            b'r' => {
+                let mut counts = Vec::new();
+                while !self.next_if(b'l') {
+                    let count = if self.next_if(b'z') {
+                        0
+                    } else {
+                        self.index()?.checked_add(1)?
+                    };
+                    // Each parameter is printed, so the count bounds work.
+                    if count > MAX_REPEAT {
+                        return None;
+                    }
+                    counts.push(count);
+                }
+                self.signature(counts)
+            }
+            b'F' => self.plain_function(),
+            b'f' => self.function_entity(),
+            b'v' => self.variable(),
+            b'Z' => {
+                let e = self.pop_if(|n| matches!(n, Node::Entity(_)))?;
+                Some(Node::Static(Box::new(e)))
+            }
+            b'N' => self.global_for_type("type metadata for "),
+            b'M' => {
+                let prefix = match self.next()? {
+                    b'a' => "type metadata accessor for ",
+                    b'n' => "nominal type descriptor for ",
+                    b'f' => "full type metadata for ",
+                    b'm' => "metaclass for ",
+                    _ => return None,
+                };
+                self.global_for_type(prefix)
+            }
+            b'W' => {
+                if self.next()? != b'P' {
+                    return None;
+                }
+                let module = self.pop_module()?;
+                let proto = self.pop_protocol()?;
+                let ty = self.pop_type()?;
+                Some(Node::Global(format!(
+                    "protocol witness table for {} : {} in {module}",
+                    print_type(&ty),
+                    print_type(&proto)
+                )))
+            }
+            b'T' => {
+                if self.next()? != b'q' {
+                    return None;
+                }
+                let e = self.pop_if(|n| matches!(n, Node::Entity(_) | Node::Static(_)))?;
+                Some(Node::Global(format!(
+                    "method descriptor for {}",
+                    print_entity(&e)
+                )))
+            }
+            b'D' => {
+                let ty = self.pop_type()?;
+                Some(Node::Global(print_type(&ty)))
+            }
+            _ => None,
+        }
+    }
+
+    fn global_for_type(&mut self, prefix: &str) -> Option<Node> {
+        let ty = self.pop_type()?;
+        Some(Node::Global(format!("{prefix}{}", print_type(&ty))))
+    }
+
+    fn pop_protocol(&mut self) -> Option<Node> {
+        let ty = self.pop_type()?;
+        match &ty {
+            Node::Type(inner) if matches!(**inner, Node::Nominal('P', ..)) => Some(ty),
+            _ => None,
+        }
+    }
+
+    /// `z` is (0, 0), `d<i><j>` is (i + 1, j), otherwise (0, index + 1).
+    fn generic_param_index(&mut self) -> Option<(u64, u64)> {
+        if self.next_if(b'z') {
+            return Some((0, 0));
+        }
+        if self.next_if(b'd') {
+            let depth = self.index()?.checked_add(1)?;
+            return Some((depth, self.index()?));
+        }
+        Some((0, self.index()?.checked_add(1)?))
+    }
+
+    fn identifier(&mut self) -> Option<Node> {
+        let mut word_subst = false;
+        if self.next_if(b'0') {
+            if self.peek() == Some(b'0') {
+                return None; // punycode
+            }
+            word_subst = true;
+        }
+        let mut ident = String::new();
+        loop {
+            while word_subst && self.peek().is_some_and(|c| c.is_ascii_alphabetic()) {
+                let c = self.next()?;
+                let idx = if c.is_ascii_lowercase() {
+                    c - b'a'
+                } else {
+                    word_subst = false;
+                    c - b'A'
+                };
+                ident.push_str(self.words.get(idx as usize)?);
+            }
+            if self.next_if(b'0') {
+                break;
+            }
+            let len = usize::try_from(self.natural()?).ok()?;
+            if len == 0 {
+                return None;
+            }
+            let slice = self.text.get(self.pos..self.pos.checked_add(len)?)?;
+            let slice = std::str::from_utf8(slice).ok()?;
+            self.pos += len;
+            ident.push_str(slice);
+            self.record_words(slice);
+            if !word_subst {
+                break;
+            }
+        }
+        let node = Node::Ident(ident);
+        self.subs.push(node.clone());
+        Some(node)
+    }
+
+    /// Record the words of an identifier for later `0…` references: runs
+    /// that start at a non-digit, non-`_` character and end before `_` or
+    /// a lowercase-to-uppercase transition, at least two characters long.
+    fn record_words(&mut self, slice: &str) {
+        let b = slice.as_bytes();
+        let mut start: Option<usize> = None;
+        for i in 0..=b.len() {
+            let c = b.get(i).copied().unwrap_or(0);
+            if let Some(s) = start {
+                let prev = b[i - 1];
+                let end =
+                    c == b'_' || c == 0 || (!prev.is_ascii_uppercase() && c.is_ascii_uppercase());
+                if end {
+                    if i - s >= 2 && self.words.len() < MAX_WORDS {
+                        self.words.push(slice[s..i].to_string());
+                    }
+                    start = None;
+                }
+            }
+            if start.is_none() && !c.is_ascii_digit() && c != b'_' && c != 0 {
+                start = Some(i);
+            }
+        }
+    }
+
+    fn multi_substitution(&mut self) -> Option<Node> {
+        let mut repeat: Option<u64> = None;
+        loop {
+            let c = self.next()?;
+            if c.is_ascii_lowercase() || c.is_ascii_uppercase() {
+                let idx = (c.to_ascii_lowercase() - b'a') as usize;
+                let node = self.subs.get(idx)?.clone();
+                let n = repeat.take().unwrap_or(1);
+                if n > MAX_REPEAT {
+                    return None;
+                }
+                for _ in 1..n {
+                    self.stack.push(node.clone());
+                }
+                if c.is_ascii_uppercase() {
+                    return Some(node);
+                }
+                self.stack.push(node);
+                continue;
+            }
+            if c == b'_' {
+                let idx = match repeat {
+                    Some(n) => usize::try_from(n).ok()?.checked_add(27)?,
+                    None => 26,
+                };
+                return self.subs.get(idx).cloned();
+            }
+            self.pos -= 1;
+            repeat = Some(self.natural()?);
+        }
+    }
+
+    fn standard_substitution(&mut self) -> Option<Node> {
+        match self.peek()? {
+            b'o' => {
+                self.pos += 1;
+                return Some(Node::Module("ObjectiveC".into()));
+            }
+            b'C' => {
+                self.pos += 1;
+                return Some(Node::Module("__C".into()));
+            }
+            b'g' => {
+                self.pos += 1;
+                let arg = self.pop_type()?;
+                let ty = Node::Type(Box::new(Node::BoundGeneric(
+                    Box::new(swift_type('O', "Optional")),
+                    vec![arg],
+                )));
+                self.subs.push(ty.clone());
+                return Some(ty);
+            }
+            _ => {}
+        }
+        let repeat = if self.peek()?.is_ascii_digit() {
+            self.natural()?
+        } else {
+            1
+        };
+        if repeat > MAX_REPEAT {
+            return None;
+        }
+        let (kind, name) = match self.next()? {
+            b'a' => ('V', "Array"),
+            b'b' => ('V', "Bool"),
+            b'D' => ('V', "Dictionary"),
+            b'd' => ('V', "Double"),
+            b'f' => ('V', "Float"),
+            b'h' => ('V', "Set"),
+            b'i' => ('V', "Int"),
+            b'J' => ('V', "Character"),
+            b'N' => ('V', "ClosedRange"),
+            b'n' => ('V', "Range"),
+            b'O' => ('V', "ObjectIdentifier"),
+            b'P' => ('V', "UnsafePointer"),
+            b'p' => ('V', "UnsafeMutablePointer"),
+            b'R' => ('V', "UnsafeBufferPointer"),
+            b'r' => ('V', "UnsafeMutableBufferPointer"),
+            b'S' => ('V', "String"),
+            b's' => ('V', "Substring"),
+            b'u' => ('V', "UInt"),
+            b'V' => ('V', "UnsafeRawPointer"),
+            b'v' => ('V', "UnsafeMutableRawPointer"),
+            b'W' => ('V', "UnsafeRawBufferPointer"),
+            b'w' => ('V', "UnsafeMutableRawBufferPointer"),
+            b'q' => ('O', "Optional"),
+            b'E' => ('P', "Encodable"),
+            b'e' => ('P', "Decodable"),
+            b'H' => ('P', "Hashable"),
+            b'j' => ('P', "Numeric"),
+            b'L' => ('P', "Comparable"),
+            b'l' => ('P', "Collection"),
+            b'Q' => ('P', "Equatable"),
+            b'T' => ('P', "Sequence"),
+            b't' => ('P', "IteratorProtocol"),
+            b'Y' => ('P', "RawRepresentable"),
+            b'y' => ('P', "StringProtocol"),
+            b'Z' => ('P', "SignedInteger"),
+            b'z' => ('P', "BinaryInteger"),
+            _ => return None,
+        };
+        let node = swift_type(kind, name);
+        for _ in 1..repeat {
+            self.stack.push(node.clone());
+        }
+        Some(node)
+    }
+
+    fn tuple(&mut self) -> Option<Node> {
+        let mut elems = Vec::new();
+        if self.pop_if(|n| matches!(n, Node::EmptyList)).is_none() {
+            loop {
+                let first = self.pop_if(|n| matches!(n, Node::FirstElement)).is_some();
+                let variadic = self.pop_if(|n| matches!(n, Node::Variadic)).is_some();
+                let label = self.pop_name();
+                let ty = self.pop_type()?;
+                elems.push(Element {
+                    label,
+                    ty,
+                    variadic,
+                });
+                if first {
+                    break;
+                }
+            }
+            elems.reverse();
+        }
+        let ty = Node::Type(Box::new(Node::Tuple(elems)));
+        Some(ty)
+    }
+
+    fn function_params(&mut self) -> Option<Node> {
+        if self.pop_if(|n| matches!(n, Node::EmptyList)).is_some() {
+            return Some(Node::Type(Box::new(Node::Tuple(Vec::new()))));
+        }
+        self.pop_type()
+    }
+
+    fn function_type(&mut self) -> Option<FnType> {
+        let throws = self.pop_if(|n| matches!(n, Node::Throws)).is_some();
+        let sendable = self.pop_if(|n| matches!(n, Node::Sendable)).is_some();
+        let is_async = self.pop_if(|n| matches!(n, Node::Async)).is_some();
+        let params = self.function_params()?;
+        let result = self.function_params()?;
+        Some(FnType {
+            params: Box::new(params),
+            result: Box::new(result),
+            is_async,
+            throws,
+            sendable,
+        })
+    }
+
+    /// Argument labels pushed ahead of a function's type: `y` for none,
+    /// otherwise one identifier or `_` per parameter.
+    fn labels(&mut self, ty: Option<&Node>) -> Option<Option<Vec<Option<String>>>> {
+        if self.pop_if(|n| matches!(n, Node::EmptyList)).is_some() {
+            return Some(Some(Vec::new()));
+        }
+        let Some(Node::Type(inner)) = ty else {
+            return Some(None);
+        };
+        let Node::Function(f) = &**inner else {
+            return Some(None);
+        };
+        let count = match &*f.params {
+            Node::Type(p) => match &**p {
+                Node::Tuple(elems) => elems.len(),
+                _ => 1,
+            },
+            _ => return None,
+        };
+        if count == 0 {
+            return Some(None);
+        }
+        let mut labels = Vec::with_capacity(count);
+        for _ in 0..count {
+            match self.pop()? {
+                Node::Ident(s) => labels.push(Some(s)),
+                Node::FirstElement => labels.push(None),
+                _ => return None,
+            }
+        }
+        labels.reverse();
+        Some(Some(labels))
+    }
+
+    fn bound_generic(&mut self) -> Option<Node> {
+        let mut levels: Vec<Vec<Node>> = Vec::new();
+        loop {
+            let mut args = Vec::new();
+            while let Some(t) = self.pop_type() {
+                args.push(t);
+            }
+            args.reverse();
+            levels.push(args);
+            if self.pop_if(|n| matches!(n, Node::EmptyList)).is_some() {
+                break;
+            }
+            self.pop_if(|n| matches!(n, Node::FirstElement))?;
+        }
+        // Only the innermost level (the nominal's own arguments) is kept;
+        // nested generic parents are outside this subset.
+        if levels.len() != 1 {
+            return None;
+        }
+        let base = match self.pop_type()? {
+            Node::Type(inner) if matches!(*inner, Node::Nominal(..)) => inner,
+            _ => return None,
+        };
+        let ty = Node::Type(Box::new(Node::BoundGeneric(base, levels.pop()?)));
+        self.subs.push(ty.clone());
+        Some(ty)
+    }
+
+    fn signature(&mut self, counts: Vec<u64>) -> Option<Node> {
+        let mut reqs = Vec::new();
+        while let Some(Node::Requirement(param, proto)) =
+            self.pop_if(|n| matches!(n, Node::Requirement(..)))
+        {
+            reqs.push((*param, *proto));
+        }
+        reqs.reverse();
+        Some(Node::Signature(counts, reqs))
+    }
+
+    fn plain_function(&mut self) -> Option<Node> {
+        let sig = self.pop_if(|n| matches!(n, Node::Signature(..)));
+        let ty = Node::Type(Box::new(Node::Function(self.function_type()?)));
+        let labels = self.labels(Some(&ty))?;
+        let name = self.pop_name()?;
+        let ctx = self.pop_context()?;
+        Some(Node::Entity(Entity {
+            kind: EntityKind::Function,
+            ctx: Box::new(ctx),
+            name,
+            labels,
+            ty: Some(Box::new(ty)),
+            sig: sig.map(Box::new),
+        }))
+    }
+
+    fn function_entity(&mut self) -> Option<Node> {
+        let kind = match self.next()? {
+            b'C' => EntityKind::Allocator,
+            b'c' => EntityKind::Constructor,
+            b'D' => EntityKind::Deallocator,
+            b'd' => EntityKind::Destructor,
+            b'U' => EntityKind::Closure(self.index()?.checked_add(1)?),
+            _ => return None,
+        };
+        let (ty, labels) = match kind {
+            EntityKind::Allocator | EntityKind::Constructor => {
+                let ty = self.pop_type()?;
+                let labels = self.labels(Some(&ty))?;
+                (Some(Box::new(ty)), labels)
+            }
+            EntityKind::Closure(_) => (Some(Box::new(self.pop_type()?)), None),
+            _ => (None, None),
+        };
+        let ctx = self.pop_context()?;
+        Some(Node::Entity(Entity {
+            kind,
+            ctx: Box::new(ctx),
+            name: String::new(),
+            labels,
+            ty,
+            sig: None,
+        }))
+    }
+
+    fn variable(&mut self) -> Option<Node> {
+        let ty = self.pop_type()?;
+        let name = self.pop_name()?;
+        let ctx = self.pop_context()?;
+        let accessor = match self.next()? {
+            b'p' => "",
+            b'g' => "getter",
+            b's' => "setter",
+            b'M' => "modify",
+            b'r' => "read",
+            b'w' => "willset",
+            b'W' => "didset",
+            b'a' if self.next_if(b'u') => "unsafeMutableAddressor",
+            b'l' if self.next_if(b'u') => "unsafeAddressor",
+            _ => return None,
+        };
+        Some(Node::Entity(Entity {
+            kind: EntityKind::Variable(accessor),
+            ctx: Box::new(ctx),
+            name,
+            labels: None,
+            ty: Some(Box::new(ty)),
+            sig: None,
+        }))
+    }
+}
+
+fn swift_type(kind: char, name: &str) -> Node {
+    Node::Type(Box::new(Node::Nominal(
+        kind,
+        Box::new(Node::Module("Swift".into())),
+        name.into(),
+    )))
+}
+
+fn generic_param_name(depth: u64, mut index: u64) -> String {
+    let mut name = String::new();
+    loop {
+        name.push((b'A' + (index % 26) as u8) as char);
+        index /= 26;
+        if index == 0 {
+            break;
+        }
+    }
+    if depth != 0 {
+        name.push_str(&depth.to_string());
+    }
+    name
+}
+
+fn print_context(n: &Node) -> String {
+    match n {
+        Node::Module(m) | Node::Ident(m) => m.clone(),
+        Node::Nominal(_, ctx, name) => format!("{}.{name}", print_context(ctx)),
+        Node::Extension(module, nominal) => {
+            format!("(extension in {module}):{}", print_context(nominal))
+        }
+        Node::Entity(_) | Node::Static(_) => print_entity(n),
+        other => print_type(other),
+    }
+}
+
+fn print_type(n: &Node) -> String {
+    match n {
+        Node::Type(inner) => print_type(inner),
+        Node::Nominal(..) => print_context(n),
+        Node::Tuple(elems) => {
+            let parts: Vec<String> = elems
+                .iter()
+                .map(|e| {
+                    let mut s = e
+                        .label
+                        .as_ref()
+                        .map(|l| format!("{l}: "))
+                        .unwrap_or_default();
+                    s.push_str(&print_type(&e.ty));
+                    if e.variadic {
+                        s.push_str("...");
+                    }
+                    s
+                })
+                .collect();
+            format!("({})", parts.join(", "))
+        }
+        Node::Function(f) => print_fn_type(f, None),
+        Node::BoundGeneric(base, args) => {
+            let base_name = print_context(base);
+            let args: Vec<String> = args.iter().map(print_type).collect();
+            match (base_name.as_str(), args.as_slice()) {
+                ("Swift.Optional", [t]) => format!("{t}?"),
+                ("Swift.Array", [t]) => format!("[{t}]"),
+                ("Swift.Dictionary", [k, v]) => format!("[{k} : {v}]"),
+                _ => format!("{base_name}<{}>", args.join(", ")),
+            }
+        }
+        Node::GenericParam(depth, index) => generic_param_name(*depth, *index),
+        Node::Modified(m, ty) => format!("{m} {}", print_type(ty)),
+        Node::Module(m) | Node::Ident(m) => m.clone(),
+        Node::Extension(..) | Node::Entity(_) | Node::Static(_) => print_context(n),
+        _ => String::new(),
+    }
+}
+
+/// `(params) async throws -> result`, with argument labels when given.
+fn print_fn_type(f: &FnType, labels: Option<&[Option<String>]>) -> String {
+    let elems: Vec<(Option<String>, String)> = match &*f.params {
+        Node::Type(inner) => match &**inner {
+            Node::Tuple(elems) => elems
+                .iter()
+                .map(|e| {
+                    let mut t = print_type(&e.ty);
+                    if e.variadic {
+                        t.push_str("...");
+                    }
+                    (e.label.clone(), t)
+                })
+                .collect(),
+            other => vec![(None, print_type(other))],
+        },
+        other => vec![(None, print_type(other))],
+    };
+    let params: Vec<String> = elems
+        .into_iter()
+        .enumerate()
+        .map(|(i, (own, ty))| match labels.filter(|l| !l.is_empty()) {
+            Some(l) => format!(
+                "{}: {ty}",
+                l.get(i).cloned().flatten().unwrap_or_else(|| "_".into())
+            ),
+            None => match own {
+                Some(label) => format!("{label}: {ty}"),
+                None => ty,
+            },
+        })
+        .collect();
+    let mut s = String::new();
+    if f.sendable {
+        s.push_str("@Sendable ");
+    }
+    s.push('(');
+    s.push_str(&params.join(", "));
+    s.push(')');
+    if f.is_async {
+        s.push_str(" async");
+    }
+    if f.throws {
+        s.push_str(" throws");
+    }
+    s.push_str(" -> ");
+    s.push_str(&print_type(&f.result));
+    s
+}
+
+fn print_signature(sig: &Node) -> String {
+    let Node::Signature(counts, reqs) = sig else {
+        return String::new();
+    };
+    let mut params = Vec::new();
+    for (depth, &count) in counts.iter().enumerate() {
+        for index in 0..count {
+            params.push(generic_param_name(depth as u64, index));
+        }
+    }
+    let mut s = format!("<{}", params.join(", "));
+    if !reqs.is_empty() {
+        let reqs: Vec<String> = reqs
+            .iter()
+            .map(|(p, proto)| format!("{}: {}", print_type(p), print_type(proto)))
+            .collect();
+        s.push_str(" where ");
+        s.push_str(&reqs.join(", "));
+    }
+    s.push('>');
+    s
+}
+
+fn print_entity(n: &Node) -> String {
+    let e = match n {
+        Node::Static(inner) => return format!("static {}", print_entity(inner)),
+        Node::Entity(e) => e,
+        other => return print_type(other),
+    };
+    let ctx = print_context(&e.ctx);
+    let fn_ty = e.ty.as_deref().and_then(|t| match t {
+        Node::Type(inner) => match &**inner {
+            Node::Function(f) => Some(f),
+            _ => None,
+        },
+        _ => None,
+    });
+    let sig = e.sig.as_deref().map(print_signature).unwrap_or_default();
+    let labels = e.labels.as_deref();
+    match e.kind {
+        EntityKind::Function => match fn_ty {
+            Some(f) => format!("{ctx}.{}{sig}{}", e.name, print_fn_type(f, labels)),
+            None => format!("{ctx}.{}", e.name),
+        },
+        EntityKind::Variable(accessor) => {
+            let ty = e.ty.as_deref().map(print_type).unwrap_or_default();
+            if accessor.is_empty() {
+                format!("{ctx}.{} : {ty}", e.name)
+            } else {
+                format!("{ctx}.{}.{accessor} : {ty}", e.name)
+            }
+        }
+        EntityKind::Allocator | EntityKind::Constructor => {
+            let name = if e.kind == EntityKind::Allocator {
+                "__allocating_init"
+            } else {
+                "init"
+            };
+            match fn_ty {
+                Some(f) => format!("{ctx}.{name}{}", print_fn_type(f, labels)),
+                None => format!("{ctx}.{name}"),
+            }
+        }
+        EntityKind::Deallocator => format!("{ctx}.__deallocating_deinit"),
+        EntityKind::Destructor => format!("{ctx}.deinit"),
+        EntityKind::Closure(n) => {
+            let ty = e.ty.as_deref().map(print_type).unwrap_or_default();
+            format!("closure #{n} {ty} in {ctx}")
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn dm(s: &str) -> String {
+        demangle(s).unwrap_or_else(|| panic!("failed to demangle {s}"))
+    }
+
+    #[test]
+    fn functions_methods_and_labels() {
+        assert_eq!(dm("$s4main3fooyyF"), "main.foo() -> ()");
+        assert_eq!(dm("_$s4main3FooV3baryyF"), "main.Foo.bar() -> ()");
+        assert_eq!(dm("$s4main3fooyySiF"), "main.foo(Swift.Int) -> ()");
+        assert_eq!(
+            dm("$s4main3foo1xSSSiF"),
+            "main.foo(x: Swift.Int) -> Swift.String"
+        );
+        assert_eq!(
+            dm("$s4main3add_1bS2i_SitF"),
+            "main.add(_: Swift.Int, b: Swift.Int) -> Swift.Int"
+        );
+        assert_eq!(
+            dm("$s4main3FooC3baryyYaKFZ"),
+            "static main.Foo.bar() async throws -> ()"
+        );
+    }
+
+    #[test]
+    fn initializers_properties_and_closures() {
+        assert_eq!(
+            dm("$s4main3FooCACycfC"),
+            "main.Foo.__allocating_init() -> main.Foo"
+        );
+        assert_eq!(dm("$s4main3FooCfD"), "main.Foo.__deallocating_deinit");
+        assert_eq!(
+            dm("$s4main3FooV3barSivg"),
+            "main.Foo.bar.getter : Swift.Int"
+        );
+        assert_eq!(dm("$s4main3FooV3barSSSgvp"), "main.Foo.bar : Swift.String?");
+        assert_eq!(
+            dm("$s4main3fooyyFyycfU_"),
+            "closure #1 () -> () in main.foo() -> ()"
+        );
+    }
+
+    #[test]
+    fn generics_extensions_and_collections() {
+        assert_eq!(dm("$s4main3fooyyxlF"), "main.foo<A>(A) -> ()");
+        assert_eq!(
+            dm("$s4main3fooyyxAA1PPRzlF"),
+            "main.foo<A where A: main.P>(A) -> ()"
+        );
+        assert_eq!(
+            dm("$sSi4mainE3fooyyF"),
+            "(extension in main):Swift.Int.foo() -> ()"
+        );
+        assert_eq!(
+            dm("$s4main3fooSDySSSaySiGGyF"),
+            "main.foo() -> [Swift.String : [Swift.Int]]"
+        );
+    }
+
+    #[test]
+    fn metadata_and_witness_symbols() {
+        assert_eq!(dm("$s4main3FooVMa"), "type metadata accessor for main.Foo");
+        assert_eq!(dm("$s4main3FooVMn"), "nominal type descriptor for main.Foo");
+        assert_eq!(dm("$s4main3FooCN"), "type metadata for main.Foo");
+        assert_eq!(
+            dm("$s4main3FooVAA1PPAAWP"),
+            "protocol witness table for main.Foo : main.P in main"
+        );
+        assert_eq!(
+            dm("$s4main3FooC3baryyFTq"),
+            "method descriptor for main.Foo.bar() -> ()"
+        );
+        assert_eq!(dm("$sSiD"), "Swift.Int");
+    }
+
+    #[test]
+    fn word_substitutions_reuse_earlier_identifier_words() {
+        // Words so far: main, Some, Long, Name — `0C` is "Long".
+        assert_eq!(
+            dm("$s4main12SomeLongNameV0C3FooVN"),
+            "type metadata for main.SomeLongName.LongFoo"
+        );
+    }
+
+    #[test]
+    fn unsupported_or_malformed_input_is_rejected() {
+        assert!(demangle("_ZN3foo3barEv").is_none());
+        assert!(demangle("$s4main3fooyy").is_none()); // no entity
+        assert!(demangle("$s4main3foo33_0123456789ABCDEF0123456789ABCDEFLLyyF").is_none());
+        assert!(demangle("$s99main").is_none());
+        assert!(demangle("$sAZ").is_none());
+    }
+
+    #[test]
+    fn index_overflow_is_rejected() {
+        for s in [
+            "$sq18446744073709551615_",
+            "$s4main3fooyyxqd18446744073709551614__lF",
+            "$s4main3fooyyxr18446744073709551614_lF",
+            "$s4main3fooyyFyycfU18446744073709551614_",
+            "$s4main3FooVA18446744073709551615_",
+            "$s4main3fooyyxr999999_lF",
+        ] {
+            assert!(demangle(s).is_none(), "{s}");
+        }
+    }
+
+    #[test]
+    fn mutated_inputs_do_not_panic() {
+        const CASES: &[&str] = &[
+            "$s4main3fooyyF",
+            "_$s4main3FooV3baryyF",
+            "$s4main3fooyySiF",
+            "$s4main3foo1xSSSiF",
+            "$s4main3add_1bS2i_SitF",
+            "$s4main3FooC3baryyYaKFZ",
+            "$s4main3FooCACycfC",
+            "$s4main3FooCfD",
+            "$s4main3FooV3barSivg",
+            "$s4main3FooV3barSSSgvp",
+            "$s4main3fooyyFyycfU_",
+            "$s4main3fooyyxlF",
+            "$s4main3fooyyxAA1PPRzlF",
+            "$sSi4mainE3fooyyF",
+            "$s4main3fooSDySSSaySiGGyF",
+            "$s4main3FooVMa",
+            "$s4main3FooCN",
+            "$s4main3FooVAA1PPAAWP",
+            "$s4main3FooC3baryyFTq",
+            "$sSiD",
+            "$s4main12SomeLongNameV0C3FooVN",
+        ];
+        const BYTES: &[u8] = b"0129_AaSsyxqdzrlUFVC";
+        const HUGE: &str = "18446744073709551615";
+        for case in CASES {
+            let bytes = case.as_bytes();
+            for i in 0..bytes.len() {
+                for &b in BYTES {
+                    let mut m = bytes.to_vec();
+                    m[i] = b;
+                    demangle(std::str::from_utf8(&m).unwrap());
+                    m.insert(i, b);
+                    demangle(std::str::from_utf8(&m).unwrap());
+                }
+                demangle(&format!("{}{HUGE}{}", &case[..i], &case[i..]));
+                demangle(&format!("{}{HUGE}_{}", &case[..i], &case[i..]));
+            }
+        }
+    }
+}
@@ -3,9 +3,53 @@
 //! This module provides comprehensive error handling using thiserror for
 //! structured error types that can be properly converted for Python bindings.
 
+use serde::{Deserialize, Serialize};
 use std::fmt;
 use thiserror::Error;
 
+/// Stable classification of errors and warnings.
+///
+/// Callers branch on the kind rather than the message: an
+/// `UnsupportedFormat` input is skipped, a `TruncatedStructure` still
+/// yields whatever was recovered before the cut, a `BudgetExceeded` result
+/// is incomplete by design, and a `ParserBug` should be reported upstream.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum ErrorKind {
+    /// The input is not a format (or variant) this component handles
+    UnsupportedFormat,
+    /// A structure extends past the end of the available data
+    TruncatedStructure,
+    /// A time, size, or count budget stopped the work early
+    BudgetExceeded,
+    /// The caller cancelled the work
+    Cancelled,
+    /// An internal invariant was violated; the input may be fine
+    ParserBug,
+    /// The input is malformed in a way other than truncation
+    InvalidInput,
+    /// I/O failure
+    Io,
+    /// Anything else
+    Other,
+}
+
+impl fmt::Display for ErrorKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let s = match self {
+            ErrorKind::UnsupportedFormat => "UnsupportedFormat",
+            ErrorKind::TruncatedStructure => "TruncatedStructure",
+            ErrorKind::BudgetExceeded => "BudgetExceeded",
+            ErrorKind::Cancelled => "Cancelled",
+            ErrorKind::ParserBug => "ParserBug",
+            ErrorKind::InvalidInput => "InvalidInput",
+            ErrorKind::Io => "Io",
+            ErrorKind::Other => "Other",
+        };
+        f.write_str(s)
+    }
+}
+
 /// Main error type for Glaurung operations.
 #[derive(Debug, Error)]
 pub enum GlaurungError {
@@ -13,6 +57,22 @@ pub enum GlaurungError {
     #[error("Invalid binary format: {0}")]
     InvalidFormat(String),
 
+    /// The input format or variant is not handled
+    #[error("Unsupported format: {0}")]
+    UnsupportedFormat(String),
+
+    /// A structure runs past the end of the data
+    #[error("Truncated {structure} at offset {offset:#x}")]
+    TruncatedStructure { structure: String, offset: u64 },
+
+    /// A named budget was exhausted before work completed
+    #[error("Budget exceeded: {budget} (limit {limit})")]
+    BudgetExceeded { budget: String, limit: u64 },
+
+    /// Internal invariant violation in a parser or pass
+    #[error("Parser bug in {component}: {message}")]
+    ParserBug { component: String, message: String },
+
     /// Parse error with location information
     #[error("Parse error at offset {offset:#x}: {message}")]
     ParseError { offset: u64, message: String },
@@ -21,6 +81,10 @@ pub enum GlaurungError {
     #[error("Analysis timeout after {seconds}s")]
     Timeout { seconds: u64 },
 
+    /// The caller cancelled the operation
+    #[error("Analysis cancelled")]
+    Cancelled,
+
     /// Resource limit exceeded
     #[error("Resource limit exceeded: {resource} ({used}/{limit})")]
     ResourceExhausted {
@@ -66,9 +130,117 @@ pub enum GlaurungError {
     Internal(String),
 }
 
+impl GlaurungError {
+    /// Classify this error.
+    pub fn kind(&self) -> ErrorKind {
+        match self {
+            GlaurungError::UnsupportedFormat(_)
+            | GlaurungError::InvalidFormat(_)
+            | GlaurungError::UnsupportedArchitecture(_) => ErrorKind::UnsupportedFormat,
+            GlaurungError::TruncatedStructure { .. } => ErrorKind::TruncatedStructure,
+            GlaurungError::BudgetExceeded { .. }
+            | GlaurungError::Timeout { .. }
+            | GlaurungError::ResourceExhausted { .. } => ErrorKind::BudgetExceeded,
+            GlaurungError::Cancelled => ErrorKind::Cancelled,
+            GlaurungError::ParserBug { .. } | GlaurungError::Internal(_) => ErrorKind::ParserBug,
+            GlaurungError::ParseError { .. } | GlaurungError::InvalidInput(_) => {
+                ErrorKind::InvalidInput
+            }
+            GlaurungError::Io(_) => ErrorKind::Io,
+            GlaurungError::Serialization(_)
+            | GlaurungError::AddressError(_)
+            | GlaurungError::SymbolNotFound(_)
+            | GlaurungError::PatternError(_)
+            | GlaurungError::TriageError(_) => ErrorKind::Other,
+        }
+    }
+}
+
 /// Result type alias for Glaurung operations
 pub type Result<T> = std::result::Result<T, GlaurungError>;
 
+/// A non-fatal problem encountered while producing a result.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Warning {
+    pub kind: ErrorKind,
+    pub message: String,
+    /// File offset the problem relates to, if any
+    pub offset: Option<u64>,
+}
+
+impl Warning {
+    pub fn new<S: Into<String>>(kind: ErrorKind, message: S) -> Self {
+        Self {
+            kind,
+            message: message.into(),
+            offset: None,
+        }
+    }
+
+    pub fn at(mut self, offset: u64) -> Self {
+        self.offset = Some(offset);
+        self
+    }
+}
+
+impl From<&GlaurungError> for Warning {
+    fn from(err: &GlaurungError) -> Self {
+        let offset = match err {
+            GlaurungError::ParseError { offset, .. }
+            | GlaurungError::TruncatedStructure { offset, .. } => Some(*offset),
+            _ => None,
+        };
+        Self {
+            kind: err.kind(),
+            message: err.to_string(),
+            offset,
+        }
+    }
+}
+
+/// A result that may be incomplete: the value recovered so far plus the
+/// warnings describing what was skipped or cut short.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Partial<T> {
+    pub value: T,
+    pub warnings: Vec<Warning>,
+}
+
+impl<T> Partial<T> {
+    /// A complete result with no warnings.
+    pub fn complete(value: T) -> Self {
+        Self {
+            value,
+            warnings: Vec::new(),
+        }
+    }
+
+    /// A result with accompanying warnings.
+    pub fn with_warnings(value: T, warnings: Vec<Warning>) -> Self {
+        Self { value, warnings }
+    }
+
+    /// True when no warnings were recorded.
+    pub fn is_complete(&self) -> bool {
+        self.warnings.is_empty()
+    }
+
+    pub fn warn(&mut self, warning: Warning) {
+        self.warnings.push(warning);
+    }
+
+    pub fn map<U, F: FnOnce(T) -> U>(self, f: F) -> Partial<U> {
+        Partial {
+            value: f(self.value),
+            warnings: self.warnings,
+        }
+    }
+
+    pub fn into_parts(self) -> (T, Vec<Warning>) {
+        (self.value, self.warnings)
+    }
+}
+
 /// Convert Glaurung errors to PyO3 exceptions
 #[cfg(feature = "python-ext")]
 impl From<GlaurungError> for pyo3::PyErr {
@@ -80,9 +252,11 @@ impl From<GlaurungError> for pyo3::PyErr {
             GlaurungError::Timeout { seconds } => {
                 PyTimeoutError::new_err(format!("Operation timed out after {}s", seconds))
             }
-            GlaurungError::InvalidInput(msg) | GlaurungError::InvalidFormat(msg) => {
-                PyValueError::new_err(msg)
-            }
+            GlaurungError::InvalidInput(msg)
+            | GlaurungError::InvalidFormat(msg)
+            | GlaurungError::UnsupportedFormat(msg) => PyValueError::new_err(msg),
+            GlaurungError::TruncatedStructure { .. } => PyValueError::new_err(err.to_string()),
+            GlaurungError::BudgetExceeded { .. } => PyTimeoutError::new_err(err.to_string()),
             _ => PyException::new_err(err.to_string()),
         }
     }
@@ -147,6 +321,35 @@ mod tests {
         );
     }
 
+    #[test]
+    fn test_error_kinds() {
+        let err = GlaurungError::TruncatedStructure {
+            structure: "section header".into(),
+            offset: 0x40,
+        };
+        assert_eq!(err.kind(), ErrorKind::TruncatedStructure);
+        let w = Warning::from(&err);
+        assert_eq!(w.offset, Some(0x40));
+        assert_eq!(
+            GlaurungError::Timeout { seconds: 1 }.kind(),
+            ErrorKind::BudgetExceeded
+        );
+        assert_eq!(
+            GlaurungError::UnsupportedArchitecture("z80".into()).kind(),
+            ErrorKind::UnsupportedFormat
+        );
+    }
+
+    #[test]
+    fn test_partial_result() {
+        let mut p = Partial::complete(vec![1, 2]);
+        assert!(p.is_complete());
+        p.warn(Warning::new(ErrorKind::BudgetExceeded, "stopped after 2").at(8));
+        let (v, w) = p.map(|v| v.len()).into_parts();
+        assert_eq!(v, 2);
+        assert_eq!(w[0].kind, ErrorKind::BudgetExceeded);
+    }
+
     #[test]
     fn test_default_budget() {
         let budget = AnalysisBudget::default();
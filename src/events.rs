@@ -0,0 +1,399 @@
+//! Lifecycle hooks and metrics.
+//!
+//! Long-running deployments (server mode, batch workers) register an
+//! `EventSink` to observe analyses as they happen: triage start/finish,
+//! bytes read, per-pass durations and outcomes, and findings emitted. The
+//! built-in `Metrics` sink aggregates those events into counters and renders
+//! them in the Prometheus text exposition format, so no exporter dependency
+//! is needed; OpenTelemetry users can bridge the `tracing` spans emitted by
+//! the same code paths instead.
+//!
+//! Emitting is a no-op (one atomic load) while no sink is registered.
+
+use std::collections::BTreeMap;
+use std::fmt::Write as _;
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::{Arc, Mutex, OnceLock, RwLock};
+
+/// Something observable that happened during analysis.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub enum AnalysisEvent<'a> {
+    /// Triage of one input began
+    AnalysisStarted { path: &'a str, size_bytes: u64 },
+    /// Triage of one input finished; `errors` counts recorded triage errors
+    AnalysisFinished {
+        path: &'a str,
+        elapsed_ms: u64,
+        errors: usize,
+    },
+    /// Bytes read from an input for analysis
+    BytesProcessed { bytes: u64 },
+    /// A pipeline pass is about to run
+    PassStarted { pass: &'a str },
+    /// A pipeline pass ended with `status` ("completed", "partial", ...)
+    PassFinished {
+        pass: &'a str,
+        status: &'a str,
+        elapsed_ms: u64,
+        warnings: usize,
+    },
+    /// A finding was recorded; `source` is the pass or triage stage
+    FindingEmitted { source: &'a str, category: &'a str },
+}
+
+/// Receiver for analysis events. Sinks are called synchronously on the
+/// analysing thread and must be cheap and non-blocking.
+pub trait EventSink: Send + Sync {
+    fn on_event(&self, event: &AnalysisEvent<'_>);
+}
+
+static ACTIVE: AtomicBool = AtomicBool::new(false);
+
+fn sinks() -> &'static RwLock<Vec<Arc<dyn EventSink>>> {
+    static SINKS: OnceLock<RwLock<Vec<Arc<dyn EventSink>>>> = OnceLock::new();
+    SINKS.get_or_init(|| RwLock::new(Vec::new()))
+}
+
+/// Register a process-wide sink.
+pub fn register_sink(sink: Arc<dyn EventSink>) {
+    let mut all = sinks().write().unwrap_or_else(|e| e.into_inner());
+    all.push(sink);
+    ACTIVE.store(true, Ordering::Release);
+}
+
+/// Remove a previously registered sink (matched by identity).
+pub fn unregister_sink(sink: &Arc<dyn EventSink>) {
+    let mut all = sinks().write().unwrap_or_else(|e| e.into_inner());
+    all.retain(|s| !Arc::ptr_eq(s, sink));
+    ACTIVE.store(!all.is_empty(), Ordering::Release);
+}
+
+/// Deliver `event` to every registered sink.
+pub fn emit(event: AnalysisEvent<'_>) {
+    if !ACTIVE.load(Ordering::Acquire) {
+        return;
+    }
+    let all = sinks().read().unwrap_or_else(|e| e.into_inner());
+    for sink in all.iter() {
+        sink.on_event(&event);
+    }
+}
+
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+struct Timing {
+    count: u64,
+    sum_ms: u64,
+}
+
+impl Timing {
+    fn observe(&mut self, ms: u64) {
+        self.count += 1;
+        self.sum_ms = self.sum_ms.saturating_add(ms);
+    }
+}
+
+/// Point-in-time copy of the aggregated counters.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct MetricsSnapshot {
+    pub analyses_started: u64,
+    pub analyses_finished: u64,
+    /// Finished analyses that recorded at least one triage error
+    pub analyses_with_errors: u64,
+    pub analysis_ms_sum: u64,
+    pub bytes_processed: u64,
+    /// pass -> (runs, total ms)
+    pub pass_durations: BTreeMap<String, (u64, u64)>,
+    /// (pass, status) -> runs
+    pub pass_status: BTreeMap<(String, String), u64>,
+    pub pass_warnings: BTreeMap<String, u64>,
+    /// (source, category) -> findings
+    pub findings: BTreeMap<(String, String), u64>,
+}
+
+#[derive(Debug, Default)]
+struct State {
+    analyses_started: u64,
+    analyses_finished: u64,
+    analyses_with_errors: u64,
+    analysis: Timing,
+    bytes_processed: u64,
+    pass_durations: BTreeMap<String, Timing>,
+    pass_status: BTreeMap<(String, String), u64>,
+    pass_warnings: BTreeMap<String, u64>,
+    findings: BTreeMap<(String, String), u64>,
+}
+
+/// Counter-aggregating sink with a Prometheus renderer.
+#[derive(Debug, Default)]
+pub struct Metrics {
+    state: Mutex<State>,
+}
+
+impl EventSink for Metrics {
+    fn on_event(&self, event: &AnalysisEvent<'_>) {
+        let mut st = self.state.lock().unwrap_or_else(|e| e.into_inner());
+        match *event {
+            AnalysisEvent::AnalysisStarted { .. } => st.analyses_started += 1,
+            AnalysisEvent::AnalysisFinished {
+                elapsed_ms, errors, ..
+            } => {
+                st.analyses_finished += 1;
+                if errors > 0 {
+                    st.analyses_with_errors += 1;
+                }
+                st.analysis.observe(elapsed_ms);
+            }
+            AnalysisEvent::BytesProcessed { bytes } => {
+                st.bytes_processed = st.bytes_processed.saturating_add(bytes)
+            }
+            AnalysisEvent::PassStarted { .. } => {}
+            AnalysisEvent::PassFinished {
+                pass,
+                status,
+                elapsed_ms,
+                warnings,
+            } => {
+                st.pass_durations
+                    .entry(pass.to_string())
+                    .or_default()
+                    .observe(elapsed_ms);
+                *st.pass_status
+                    .entry((pass.to_string(), status.to_string()))
+                    .or_default() += 1;
+                if warnings > 0 {
+                    *st.pass_warnings.entry(pass.to_string()).or_default() += warnings as u64;
+                }
+            }
+            AnalysisEvent::FindingEmitted { source, category } => {
+                *st.findings
+                    .entry((source.to_string(), category.to_string()))
+                    .or_default() += 1;
+            }
+        }
+    }
+}
+
+impl Metrics {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Copy of the current counters.
+    pub fn snapshot(&self) -> MetricsSnapshot {
+        let st = self.state.lock().unwrap_or_else(|e| e.into_inner());
+        MetricsSnapshot {
+            analyses_started: st.analyses_started,
+            analyses_finished: st.analyses_finished,
+            analyses_with_errors: st.analyses_with_errors,
+            analysis_ms_sum: st.analysis.sum_ms,
+            bytes_processed: st.bytes_processed,
+            pass_durations: st
+                .pass_durations
+                .iter()
+                .map(|(k, t)| (k.clone(), (t.count, t.sum_ms)))
+                .collect(),
+            pass_status: st.pass_status.clone(),
+            pass_warnings: st.pass_warnings.clone(),
+            findings: st.findings.clone(),
+        }
+    }
+
+    /// Zero every counter.
+    pub fn reset(&self) {
+        *self.state.lock().unwrap_or_else(|e| e.into_inner()) = State::default();
+    }
+
+    /// Render the counters in the Prometheus text exposition format.
+    pub fn render_prometheus(&self) -> String {
+        let snap = self.snapshot();
+        let mut out = Exposition::default();
+
+        out.family("analyses_started_total", "counter", "Analyses started.");
+        out.sample("analyses_started_total", &[], snap.analyses_started);
+        out.family("analyses_finished_total", "counter", "Analyses finished.");
+        out.sample("analyses_finished_total", &[], snap.analyses_finished);
+        out.family(
+            "analyses_with_errors_total",
+            "counter",
+            "Finished analyses that recorded triage errors.",
+        );
+        out.sample("analyses_with_errors_total", &[], snap.analyses_with_errors);
+        out.family(
+            "analysis_duration_ms",
+            "summary",
+            "Wall time per analysis in milliseconds.",
+        );
+        out.sample("analysis_duration_ms_sum", &[], snap.analysis_ms_sum);
+        out.sample("analysis_duration_ms_count", &[], snap.analyses_finished);
+        out.family("bytes_processed_total", "counter", "Input bytes read.");
+        out.sample("bytes_processed_total", &[], snap.bytes_processed);
+
+        out.family(
+            "pass_duration_ms",
+            "summary",
+            "Wall time per pipeline pass in milliseconds.",
+        );
+        for (pass, (count, sum)) in &snap.pass_durations {
+            out.sample("pass_duration_ms_sum", &[("pass", pass)], *sum);
+            out.sample("pass_duration_ms_count", &[("pass", pass)], *count);
+        }
+        out.family(
+            "pass_runs_total",
+            "counter",
+            "Pipeline pass runs by status.",
+        );
+        for ((pass, status), n) in &snap.pass_status {
+            out.sample("pass_runs_total", &[("pass", pass), ("status", status)], *n);
+        }
+        out.family(
+            "pass_warnings_total",
+            "counter",
+            "Warnings raised by passes.",
+        );
+        for (pass, n) in &snap.pass_warnings {
+            out.sample("pass_warnings_total", &[("pass", pass)], *n);
+        }
+        out.family("findings_total", "counter", "Findings emitted.");
+        for ((source, category), n) in &snap.findings {
+            out.sample(
+                "findings_total",
+                &[("source", source), ("category", category)],
+                *n,
+            );
+        }
+        out.text
+    }
+}
+
+#[derive(Default)]
+struct Exposition {
+    text: String,
+}
+
+impl Exposition {
+    fn family(&mut self, name: &str, kind: &str, help: &str) {
+        let _ = writeln!(self.text, "# HELP glaurung_{} {}", name, help);
+        let _ = writeln!(self.text, "# TYPE glaurung_{} {}", name, kind);
+    }
+
+    fn sample(&mut self, name: &str, labels: &[(&str, &str)], value: u64) {
+        let _ = write!(self.text, "glaurung_{}", name);
+        if !labels.is_empty() {
+            let rendered: Vec<String> = labels
+                .iter()
+                .map(|(k, v)| format!("{}=\"{}\"", k, escape_label(v)))
+                .collect();
+            let _ = write!(self.text, "{{{}}}", rendered.join(","));
+        }
+        let _ = writeln!(self.text, " {}", value);
+    }
+}
+
+fn escape_label(v: &str) -> String {
+    v.replace('\\', "\\\\")
+        .replace('"', "\\\"")
+        .replace('\n', "\\n")
+}
+
+/// The process-wide `Metrics` sink, registered on first use.
+pub fn global_metrics() -> &'static Arc<Metrics> {
+    static GLOBAL: OnceLock<Arc<Metrics>> = OnceLock::new();
+    GLOBAL.get_or_init(|| {
+        let m = Arc::new(Metrics::new());
+        register_sink(m.clone());
+        m
+    })
+}
+
+/// Start collecting process-wide metrics.
+#[cfg(feature = "python-ext")]
+#[pyo3::prelude::pyfunction]
+pub fn enable_metrics() {
+    global_metrics();
+}
+
+/// Process-wide metrics in the Prometheus text format.
+#[cfg(feature = "python-ext")]
+#[pyo3::prelude::pyfunction]
+pub fn metrics_prometheus() -> String {
+    global_metrics().render_prometheus()
+}
+
+/// Zero the process-wide metrics.
+#[cfg(feature = "python-ext")]
+#[pyo3::prelude::pyfunction]
+pub fn reset_metrics() {
+    global_metrics().reset();
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn metrics_aggregate_events() {
+        let m = Metrics::new();
+        m.on_event(&AnalysisEvent::AnalysisStarted {
+            path: "a.bin",
+            size_bytes: 10,
+        });
+        m.on_event(&AnalysisEvent::BytesProcessed { bytes: 10 });
+        for ms in [3, 5] {
+            m.on_event(&AnalysisEvent::PassFinished {
+                pass: "entry",
+                status: "completed",
+                elapsed_ms: ms,
+                warnings: 0,
+            });
+        }
+        m.on_event(&AnalysisEvent::FindingEmitted {
+            source: "entry",
+            category: "entry",
+        });
+        m.on_event(&AnalysisEvent::AnalysisFinished {
+            path: "a.bin",
+            elapsed_ms: 9,
+            errors: 1,
+        });
+        let snap = m.snapshot();
+        assert_eq!(snap.analyses_started, 1);
+        assert_eq!(snap.analyses_with_errors, 1);
+        assert_eq!(snap.bytes_processed, 10);
+        assert_eq!(snap.pass_durations["entry"], (2, 8));
+        m.reset();
+        assert_eq!(m.snapshot(), MetricsSnapshot::default());
+    }
+
+    #[test]
+    fn prometheus_text_is_grouped_and_escaped() {
+        let m = Metrics::new();
+        m.on_event(&AnalysisEvent::FindingEmitted {
+            source: "triage",
+            category: "odd\"name",
+        });
+        let text = m.render_prometheus();
+        assert!(text.contains("# TYPE glaurung_findings_total counter\n"));
+        assert!(text
+            .contains("glaurung_findings_total{source=\"triage\",category=\"odd\\\"name\"} 1\n"));
+        let type_at = text.find("# TYPE glaurung_findings_total").unwrap();
+        let sample_at = text.find("glaurung_findings_total{").unwrap();
+        assert!(type_at < sample_at);
+    }
+
+    #[test]
+    fn registered_sinks_receive_emitted_events() {
+        // Other tests emit concurrently; count only this test's events.
+        let m = Arc::new(Metrics::new());
+        let sink: Arc<dyn EventSink> = m.clone();
+        let probe = AnalysisEvent::FindingEmitted {
+            source: "events-test",
+            category: "probe",
+        };
+        register_sink(sink.clone());
+        emit(probe);
+        unregister_sink(&sink);
+        emit(probe);
+        let key = ("events-test".to_string(), "probe".to_string());
+        assert_eq!(m.snapshot().findings.get(&key), Some(&1));
+    }
+}
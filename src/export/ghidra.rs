@@ -0,0 +1,334 @@
+//! Ghidra XML program export.
+//!
+//! Writes the `PROGRAM` document Ghidra's "XML Program Loader" imports
+//! (`File > Import File`, format "XML Input Format"). Section bytes are not
+//! embedded: each initialized `MEMORY_SECTION` points at its offset in the
+//! original file, which Ghidra expects next to the XML under the name in
+//! `PROGRAM/@NAME`. Functions carry their basic-block ranges, basic blocks
+//! become `CODE_BLOCK`s, strings `DEFINED_DATA`, cross-references
+//! `MEMORY_REFERENCE`s and findings with a mapped address `BOOKMARK`s.
+
+use std::fmt::Write as _;
+
+use super::model::{Program, Section};
+
+/// Ghidra language ID for the program's architecture, if it has one.
+fn language_id(program: &Program) -> Option<String> {
+    let big = program.endianness == "big";
+    let e = if big { "BE" } else { "LE" };
+    let (processor, variant) = match program.arch.as_str() {
+        "x86" => ("x86", "32:default"),
+        "x86_64" => ("x86", "64:default"),
+        "arm" => ("ARM", "32:v8"),
+        "aarch64" => ("AARCH64", "64:v8A"),
+        "mips" => ("MIPS", "32:default"),
+        "mips64" => ("MIPS", "64:default"),
+        "ppc" => ("PowerPC", "32:default"),
+        "ppc64" => ("PowerPC", "64:default"),
+        "riscv" => ("RISCV", "32:RV32GC"),
+        "riscv64" => ("RISCV", "64:RV64GC"),
+        _ => return None,
+    };
+    let compiler = match (processor, program.format.as_str()) {
+        ("x86", "PE" | "COFF") => "windows",
+        ("x86" | "RISCV", _) => "gcc",
+        _ => "default",
+    };
+    Some(format!("{processor}:{e}:{variant}:{compiler}"))
+}
+
+/// Ghidra's display name for the container format.
+fn exe_format(format: &str) -> &str {
+    match format {
+        "ELF" => "Executable and Linking Format (ELF)",
+        "PE" => "Portable Executable (PE)",
+        "MachO" => "Mac OS X Mach-O",
+        "COFF" => "Common Object File Format (COFF)",
+        other => other,
+    }
+}
+
+/// Sections that occupy address space, without overlaps: Ghidra rejects
+/// overlapping memory blocks, and unallocated sections (symbol tables,
+/// debug info) all sit at address zero.
+fn mapped_sections(program: &Program) -> Vec<&Section> {
+    let mut out: Vec<&Section> = Vec::new();
+    let mut next = 0u64;
+    for s in &program.sections {
+        if s.size == 0 || !s.read || (!out.is_empty() && s.va < next) {
+            continue;
+        }
+        next = s.va.saturating_add(s.size);
+        out.push(s);
+    }
+    out
+}
+
+fn addr(va: u64) -> String {
+    format!("{:08x}", va)
+}
+
+fn perms(s: &Section) -> String {
+    let mut p = String::new();
+    p.push(if s.read { 'r' } else { '-' });
+    if s.write {
+        p.push('w');
+    }
+    if s.execute {
+        p.push('x');
+    }
+    p
+}
+
+/// Escape `s` for an attribute value, dropping characters XML 1.0 cannot
+/// represent at all.
+fn escape(s: &str) -> String {
+    let mut out = String::with_capacity(s.len());
+    for c in s.chars() {
+        match c {
+            '&' => out.push_str("&amp;"),
+            '<' => out.push_str("&lt;"),
+            '>' => out.push_str("&gt;"),
+            '"' => out.push_str("&quot;"),
+            '\'' => out.push_str("&apos;"),
+            '\t' => out.push_str("&#x9;"),
+            '\n' => out.push_str("&#xA;"),
+            '\r' => out.push_str("&#xD;"),
+            c if (c as u32) < 0x20 || c == '\u{fffe}' || c == '\u{ffff}' => {}
+            c => out.push(c),
+        }
+    }
+    out
+}
+
+/// Render `program` as a Ghidra XML program document.
+pub fn to_xml(program: &Program) -> String {
+    let sections = mapped_sections(program);
+    let mapped = |va: u64| sections.iter().any(|s| va >= s.va && va - s.va < s.size);
+    let mut x = String::new();
+    x.push_str("<?xml version=\"1.0\" standalone=\"yes\"?>\n");
+    x.push_str("<?program_dtd version=\"1\"?>\n");
+    let image_base = program
+        .image_base
+        .or_else(|| sections.first().map(|s| s.va))
+        .unwrap_or(0);
+    let _ = writeln!(
+        x,
+        "<PROGRAM NAME=\"{}\" EXE_PATH=\"{}\" EXE_FORMAT=\"{}\" IMAGE_BASE=\"{}\">",
+        escape(&program.name),
+        escape(&program.name),
+        escape(exe_format(&program.format)),
+        addr(image_base)
+    );
+    let _ = writeln!(
+        x,
+        "    <INFO_SOURCE USER=\"glaurung\" TOOL=\"glaurung {}\" FILE=\"{}\" />",
+        escape(&program.tool_version),
+        escape(&program.name)
+    );
+    if let Some(lang) = language_id(program) {
+        let _ = writeln!(
+            x,
+            "    <PROCESSOR NAME=\"{}\" LANGUAGE_PROVIDER=\"{}\" ENDIAN=\"{}\" ADDRESS_MODEL=\"{}-bit\" />",
+            lang.split(':').next().unwrap_or_default(),
+            lang,
+            program.endianness,
+            program.bits
+        );
+    }
+
+    x.push_str("    <MEMORY_MAP>\n");
+    for s in &sections {
+        let _ = write!(
+            x,
+            "        <MEMORY_SECTION NAME=\"{}\" START_ADDR=\"{}\" LENGTH=\"0x{:x}\" PERMISSIONS=\"{}\"",
+            escape(&s.name),
+            addr(s.va),
+            s.size,
+            perms(s)
+        );
+        match (s.file_offset, s.file_size) {
+            (Some(offset), Some(len)) if len > 0 => {
+                x.push_str(">\n");
+                let _ = writeln!(
+                    x,
+                    "            <MEMORY_CONTENTS FILE_NAME=\"{}\" FILE_OFFSET=\"0x{:x}\" LENGTH=\"0x{:x}\" />",
+                    escape(&program.name),
+                    offset,
+                    len.min(s.size)
+                );
+                x.push_str("        </MEMORY_SECTION>\n");
+            }
+            _ => x.push_str(" />\n"),
+        }
+    }
+    x.push_str("    </MEMORY_MAP>\n");
+
+    x.push_str("    <CODE>\n");
+    for f in &program.functions {
+        for b in f.blocks.iter().filter(|b| b.end > b.start) {
+            let _ = writeln!(
+                x,
+                "        <CODE_BLOCK START=\"{}\" END=\"{}\" />",
+                addr(b.start),
+                addr(b.end - 1)
+            );
+        }
+    }
+    x.push_str("    </CODE>\n");
+
+    x.push_str("    <DATA>\n");
+    for s in &program.strings {
+        let Some(va) = s.va.filter(|&va| mapped(va)) else {
+            continue;
+        };
+        // Ghidra's string types include the terminator.
+        let (datatype, terminator) = match s.encoding.as_str() {
+            "ascii" => ("string", 1),
+            "utf8" => ("string-utf8", 1),
+            "utf16le" if program.endianness == "little" => ("unicode", 2),
+            "utf16be" if program.endianness == "big" => ("unicode", 2),
+            _ => continue,
+        };
+        let _ = writeln!(
+            x,
+            "        <DEFINED_DATA ADDRESS=\"{}\" DATATYPE=\"{}\" DATATYPE_NAMESPACE=\"/\" SIZE=\"0x{:x}\" />",
+            addr(va),
+            datatype,
+            s.length + terminator
+        );
+    }
+    x.push_str("    </DATA>\n");
+
+    x.push_str("    <BOOKMARKS>\n");
+    for ind in &program.indicators {
+        let Some(va) = ind
+            .evidence
+            .iter()
+            .filter_map(|e| e.va)
+            .find(|&va| mapped(va))
+        else {
+            continue;
+        };
+        let kind = match ind.severity.as_str() {
+            "high" | "critical" => "Error",
+            "medium" => "Warning",
+            _ => "Info",
+        };
+        let _ = writeln!(
+            x,
+            "        <BOOKMARK ADDRESS=\"{}\" TYPE=\"{}\" CATEGORY=\"glaurung/{}\" DESCRIPTION=\"{} ({})\" />",
+            addr(va),
+            kind,
+            escape(&ind.category),
+            escape(&ind.claim),
+            escape(&ind.severity)
+        );
+    }
+    x.push_str("    </BOOKMARKS>\n");
+
+    if let Some(entry) = program.entry.filter(|&va| mapped(va)) {
+        x.push_str("    <PROGRAM_ENTRY_POINTS>\n");
+        let _ = writeln!(
+            x,
+            "        <PROGRAM_ENTRY_POINT ADDRESS=\"{}\" />",
+            addr(entry)
+        );
+        x.push_str("    </PROGRAM_ENTRY_POINTS>\n");
+    }
+
+    x.push_str("    <MARKUP>\n");
+    for r in &program.xrefs {
+        let _ = writeln!(
+            x,
+            "        <MEMORY_REFERENCE ADDRESS=\"{}\" TO_ADDRESS=\"{}\" PRIMARY=\"y\" USER_DEFINED=\"n\" />",
+            addr(r.from),
+            addr(r.to)
+        );
+    }
+    x.push_str("    </MARKUP>\n");
+
+    x.push_str("    <FUNCTIONS>\n");
+    for f in &program.functions {
+        let _ = writeln!(
+            x,
+            "        <FUNCTION ENTRY_POINT=\"{}\" NAME=\"{}\" LIBRARY_FUNCTION=\"n\">",
+            addr(f.entry),
+            escape(&f.name)
+        );
+        // Adjacent blocks collapse into one body range.
+        let mut ranges: Vec<(u64, u64)> = Vec::new();
+        for b in f.blocks.iter().filter(|b| b.end > b.start) {
+            match ranges.last_mut() {
+                Some(last) if b.start <= last.1 => last.1 = last.1.max(b.end),
+                _ => ranges.push((b.start, b.end)),
+            }
+        }
+        for (start, end) in ranges {
+            let _ = writeln!(
+                x,
+                "            <ADDRESS_RANGE START=\"{}\" END=\"{}\" />",
+                addr(start),
+                addr(end - 1)
+            );
+        }
+        x.push_str("        </FUNCTION>\n");
+    }
+    x.push_str("    </FUNCTIONS>\n");
+    x.push_str("</PROGRAM>\n");
+    x
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::export::model::sample;
+
+    #[test]
+    fn ghidra_xml_describes_memory_functions_and_markup() {
+        let xml = to_xml(&sample());
+        assert!(xml.starts_with(
+            "<?xml version=\"1.0\" standalone=\"yes\"?>\n<?program_dtd version=\"1\"?>\n"
+        ));
+        assert!(xml.contains("<PROGRAM NAME=\"a&amp;b.elf\""));
+        assert!(xml.contains("LANGUAGE_PROVIDER=\"x86:LE:64:default:gcc\""));
+        assert!(xml.contains("IMAGE_BASE=\"00401000\""));
+        // Unallocated sections stay out of the memory map.
+        assert!(!xml.contains(".comment"));
+        assert!(xml.contains(
+            "<MEMORY_SECTION NAME=\".text\" START_ADDR=\"00401000\" LENGTH=\"0x100\" PERMISSIONS=\"rx\">"
+        ));
+        assert!(xml.contains("FILE_NAME=\"a&amp;b.elf\" FILE_OFFSET=\"0x1000\" LENGTH=\"0x100\""));
+        assert!(xml.contains("<MEMORY_SECTION NAME=\".bss\" START_ADDR=\"00403000\" LENGTH=\"0x10\" PERMISSIONS=\"rw\" />"));
+        assert!(xml.contains("<CODE_BLOCK START=\"00401010\" END=\"0040101f\" />"));
+        assert!(xml.contains("<DEFINED_DATA ADDRESS=\"00402000\" DATATYPE=\"string\" DATATYPE_NAMESPACE=\"/\" SIZE=\"0x6\" />"));
+        assert_eq!(xml.matches("<DEFINED_DATA").count(), 1);
+        assert!(xml.contains("TYPE=\"Warning\" CATEGORY=\"glaurung/strings\" DESCRIPTION=\"url=&lt;http://x&gt; (medium)\""));
+        assert!(xml.contains("<MEMORY_REFERENCE ADDRESS=\"00401004\" TO_ADDRESS=\"00402000\""));
+        assert!(xml.contains(
+            "<FUNCTION ENTRY_POINT=\"00401000\" NAME=\"_ZN3foo3barEv\" LIBRARY_FUNCTION=\"n\">"
+        ));
+        // The two adjacent blocks form one body range.
+        assert_eq!(xml.matches("<ADDRESS_RANGE").count(), 1);
+        assert!(xml.contains("<ADDRESS_RANGE START=\"00401000\" END=\"0040101f\" />"));
+        // Elements follow the order of the program DTD.
+        let order = [
+            "<INFO_SOURCE",
+            "<PROCESSOR",
+            "<MEMORY_MAP>",
+            "<CODE>",
+            "<DATA>",
+            "<BOOKMARKS>",
+            "<PROGRAM_ENTRY_POINTS>",
+            "<MARKUP>",
+            "<FUNCTIONS>",
+        ];
+        let at: Vec<usize> = order.iter().map(|t| xml.find(t).unwrap()).collect();
+        assert!(at.windows(2).all(|w| w[0] < w[1]));
+    }
+
+    #[test]
+    fn escape_drops_characters_xml_cannot_carry() {
+        assert_eq!(escape("a\u{1}b\"c\n"), "ab&quot;c&#xA;");
+    }
+}
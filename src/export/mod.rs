@@ -0,0 +1,330 @@
+//! Interoperable exports of the analysis results.
+//!
+//! [`build_program`] collects what glaurung knows about a binary —
+//! sections, functions and their basic blocks, strings, code and data
+//! cross-references, and triage findings — into one [`Program`], and
+//! [`render`] serializes it for other tools:
+//!
+//! - `json`: the model as-is, for scripts.
+//! - `ghidra-xml`: Ghidra's XML program format ("XML Program Loader"),
+//!   which reads the section bytes from the original file next to it.
+//! - `sarif`: SARIF 2.1.0, carrying the findings for code-scanning UIs.
+//! - `protobuf`: the `glaurung.program.v1.Program` message defined in
+//!   `proto/glaurung/program/v1/program.proto`.
+
+pub mod ghidra;
+pub mod model;
+pub mod proto;
+pub mod sarif;
+
+use std::collections::{BTreeSet, HashMap};
+use std::str::FromStr;
+
+use sha2::{Digest, Sha256};
+
+use crate::analysis::cfg::{analyze_functions_bytes, Budgets};
+use crate::core::call_graph::CallType;
+use crate::formats::open_image;
+use crate::strings::StringsConfig;
+use crate::triage::io::IOLimits;
+
+pub use model::{
+    BasicBlock, EvidenceSpan, Function, Indicator, Program, Section, StringLiteral, Xref,
+    PROGRAM_SCHEMA_VERSION,
+};
+
+/// Most strings kept in a program model.
+const MAX_STRINGS: usize = 20_000;
+/// Most data cross-references kept in a program model.
+const MAX_DATA_XREFS: usize = 200_000;
+
+/// Serialization produced by [`render`].
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ExportFormat {
+    Json,
+    GhidraXml,
+    Sarif,
+    Protobuf,
+}
+
+impl ExportFormat {
+    pub const ALL: [ExportFormat; 4] = [
+        ExportFormat::Json,
+        ExportFormat::GhidraXml,
+        ExportFormat::Sarif,
+        ExportFormat::Protobuf,
+    ];
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            ExportFormat::Json => "json",
+            ExportFormat::GhidraXml => "ghidra-xml",
+            ExportFormat::Sarif => "sarif",
+            ExportFormat::Protobuf => "protobuf",
+        }
+    }
+
+    /// MIME type to serve the rendering with.
+    pub fn media_type(self) -> &'static str {
+        match self {
+            ExportFormat::Json => "application/json",
+            ExportFormat::GhidraXml => "application/xml",
+            ExportFormat::Sarif => "application/sarif+json",
+            ExportFormat::Protobuf => "application/x-protobuf",
+        }
+    }
+
+    /// Conventional file extension, without the dot.
+    pub fn extension(self) -> &'static str {
+        match self {
+            ExportFormat::Json => "json",
+            ExportFormat::GhidraXml => "xml",
+            ExportFormat::Sarif => "sarif",
+            ExportFormat::Protobuf => "pb",
+        }
+    }
+}
+
+impl FromStr for ExportFormat {
+    type Err = String;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        match s.to_ascii_lowercase().as_str() {
+            "json" => Ok(Self::Json),
+            "ghidra-xml" | "ghidra" | "xml" => Ok(Self::GhidraXml),
+            "sarif" => Ok(Self::Sarif),
+            "protobuf" | "proto" | "pb" => Ok(Self::Protobuf),
+            other => Err(format!("unknown export format '{}'", other)),
+        }
+    }
+}
+
+/// Serialize `program` as `format`.
+pub fn render(program: &Program, format: ExportFormat) -> Vec<u8> {
+    match format {
+        ExportFormat::Json => serde_json::to_vec_pretty(program).unwrap_or_default(),
+        ExportFormat::GhidraXml => ghidra::to_xml(program).into_bytes(),
+        ExportFormat::Sarif => {
+            serde_json::to_vec_pretty(&sarif::to_sarif(program)).unwrap_or_default()
+        }
+        ExportFormat::Protobuf => proto::encode_program(program),
+    }
+}
+
+/// Analyze `data` and collect the program model. `name` is the file name
+/// recorded in the exports; `budgets` bound function discovery.
+pub fn build_program(data: &[u8], name: &str, budgets: &Budgets) -> Program {
+    let mut program = Program {
+        schema_version: PROGRAM_SCHEMA_VERSION,
+        tool_version: env!("CARGO_PKG_VERSION").to_string(),
+        name: name.to_string(),
+        sha256: hex::encode(Sha256::digest(data)),
+        size: data.len() as u64,
+        format: "Unknown".to_string(),
+        arch: "unknown".to_string(),
+        endianness: "little".to_string(),
+        ..Default::default()
+    };
+    if let Some(img) = open_image(data) {
+        program.format = img.format().to_string();
+        program.arch = img.arch().to_string();
+        program.bits = u32::from(img.bits());
+        program.endianness = img.endianness().to_string().to_ascii_lowercase();
+        program.entry = img.entry();
+        program.image_base = img.image_base();
+        program.sections = img
+            .sections()
+            .into_iter()
+            .map(|s| Section {
+                name: s.name,
+                va: s.va,
+                size: s.size,
+                file_offset: s.file_range.map(|r| r.0),
+                file_size: s.file_range.map(|r| r.1),
+                read: s.perms.has_read(),
+                write: s.perms.has_write(),
+                execute: s.perms.has_execute(),
+            })
+            .collect();
+        program
+            .sections
+            .sort_by(|a, b| (a.va, &a.name).cmp(&(b.va, &b.name)));
+    }
+
+    let (funcs, callgraph) = analyze_functions_bytes(data, budgets);
+    program.functions = funcs
+        .iter()
+        .map(|f| {
+            let starts: HashMap<&str, u64> = f
+                .basic_blocks
+                .iter()
+                .map(|b| (b.id.as_str(), b.start_address.value))
+                .collect();
+            let mut blocks: Vec<BasicBlock> = f
+                .basic_blocks
+                .iter()
+                .map(|b| {
+                    let mut successors: Vec<u64> = b
+                        .successor_ids
+                        .iter()
+                        .filter_map(|id| starts.get(id.as_str()).copied())
+                        .collect();
+                    successors.sort_unstable();
+                    successors.dedup();
+                    BasicBlock {
+                        start: b.start_address.value,
+                        end: b.end_address.value,
+                        instructions: b.instruction_count,
+                        successors,
+                    }
+                })
+                .collect();
+            blocks.sort_by_key(|b| b.start);
+            Function {
+                name: f.name.clone(),
+                demangled: crate::demangle::demangle_one(&f.name).map(|r| r.demangled),
+                entry: f.entry_point.value,
+                size: f.size,
+                blocks,
+            }
+        })
+        .collect();
+    program.functions.sort_by_key(|f| f.entry);
+
+    // Call-graph nodes may still carry the `sub_<va>` name a function had
+    // before symbols renamed it, so resolve callees by either name.
+    let mut entries: HashMap<String, u64> = HashMap::new();
+    for f in &funcs {
+        entries.insert(f.name.clone(), f.entry_point.value);
+        entries.insert(
+            format!("sub_{:x}", f.entry_point.value),
+            f.entry_point.value,
+        );
+    }
+    let mut xrefs: BTreeSet<Xref> = BTreeSet::new();
+    for edge in &callgraph.edges {
+        let Some(&to) = entries.get(&edge.callee) else {
+            continue;
+        };
+        let kind = if edge.call_type == CallType::Tail {
+            "jump"
+        } else {
+            "call"
+        };
+        for site in &edge.call_sites {
+            xrefs.insert(Xref {
+                from: site.value,
+                to,
+                kind: kind.to_string(),
+                function: entries.get(&edge.caller).copied(),
+            });
+        }
+    }
+    for x in crate::analysis::xrefs::function_data_xrefs(data, &funcs, MAX_DATA_XREFS) {
+        xrefs.insert(Xref {
+            from: x.from.value,
+            to: x.to.value,
+            kind: "data".to_string(),
+            function: Some(x.function_va.value),
+        });
+    }
+    program.xrefs = xrefs.into_iter().collect();
+
+    program.strings = collect_strings(&program, data, budgets.timeout_ms);
+    program.indicators = collect_indicators(&program, data);
+    program
+}
+
+fn collect_strings(program: &Program, data: &[u8], time_guard_ms: u64) -> Vec<StringLiteral> {
+    let cfg = StringsConfig {
+        max_samples: MAX_STRINGS,
+        max_scan_bytes: data.len(),
+        time_guard_ms: time_guard_ms.max(1_000),
+        ..Default::default()
+    };
+    let scanned = crate::strings::scan::scan_strings(data, &cfg, std::time::Instant::now());
+    let groups = [
+        ("ascii", &scanned.ascii_strings, 1),
+        ("utf8", &scanned.utf8_strings, 1),
+        // UTF-16 scanners only keep ASCII code units: two bytes per char.
+        ("utf16le", &scanned.utf16le_strings, 2),
+        ("utf16be", &scanned.utf16be_strings, 2),
+    ];
+    let mut out: Vec<StringLiteral> = groups
+        .into_iter()
+        .flat_map(|(encoding, strings, unit)| {
+            strings
+                .iter()
+                .map(move |(value, offset)| (encoding, value, *offset, unit))
+        })
+        .map(|(encoding, value, offset, unit)| {
+            let offset = offset as u64;
+            let length = if unit == 1 {
+                value.len()
+            } else {
+                value.chars().count() * unit
+            };
+            StringLiteral {
+                offset,
+                va: program.va_for_offset(offset),
+                encoding: encoding.to_string(),
+                length: length as u64,
+                value: value.clone(),
+            }
+        })
+        .collect();
+    out.sort_by(|a, b| (a.offset, &a.encoding).cmp(&(b.offset, &b.encoding)));
+    out.truncate(MAX_STRINGS);
+    out
+}
+
+fn collect_indicators(program: &Program, data: &[u8]) -> Vec<Indicator> {
+    let limits = IOLimits {
+        max_read_bytes: data.len() as u64,
+        max_file_size: data.len() as u64,
+    };
+    let Ok(artifact) = crate::triage::api::analyze_bytes(data, &limits) else {
+        return Vec::new();
+    };
+    artifact
+        .findings
+        .unwrap_or_default()
+        .into_iter()
+        .map(|f| Indicator {
+            category: f.category,
+            claim: f.claim,
+            severity: f.severity.label().to_string(),
+            confidence: f.provenance.confidence,
+            pass: f.provenance.pass,
+            rule: f.provenance.rule,
+            evidence: f
+                .provenance
+                .evidence
+                .into_iter()
+                .map(|e| EvidenceSpan {
+                    offset: e.offset,
+                    length: e.length,
+                    va: program.va_for_offset(e.offset),
+                    note: e.note,
+                })
+                .collect(),
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn export_formats_round_trip_through_their_names() {
+        for fmt in ExportFormat::ALL {
+            assert_eq!(fmt.as_str().parse::<ExportFormat>(), Ok(fmt));
+        }
+        assert_eq!(
+            "ghidra".parse::<ExportFormat>(),
+            Ok(ExportFormat::GhidraXml)
+        );
+        assert!("yaml".parse::<ExportFormat>().is_err());
+    }
+}
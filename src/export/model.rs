@@ -0,0 +1,249 @@
+//! Tool-neutral program model shared by every exporter.
+//!
+//! Addresses are virtual addresses unless a field says otherwise; string
+//! and evidence locations keep their file offset and add the VA when a
+//! section maps it. Every list is sorted so the same input always
+//! produces byte-identical exports.
+
+use serde::{Deserialize, Serialize};
+
+/// Version of the program model. Bumped on incompatible changes and
+/// mirrored by the protobuf package (`glaurung.program.v1`).
+pub const PROGRAM_SCHEMA_VERSION: u32 = 1;
+
+/// Everything the exporters know about one binary.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct Program {
+    pub schema_version: u32,
+    pub tool_version: String,
+    /// File name the program was loaded from (no directories)
+    pub name: String,
+    pub sha256: String,
+    pub size: u64,
+    /// Container format (`ELF`, `PE`, `MachO`, ...)
+    pub format: String,
+    /// Architecture (`x86_64`, `aarch64`, ...)
+    pub arch: String,
+    pub bits: u32,
+    /// `little` or `big`
+    pub endianness: String,
+    pub entry: Option<u64>,
+    pub image_base: Option<u64>,
+    pub sections: Vec<Section>,
+    pub functions: Vec<Function>,
+    pub strings: Vec<StringLiteral>,
+    pub xrefs: Vec<Xref>,
+    pub indicators: Vec<Indicator>,
+}
+
+/// A section as the format describes it.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct Section {
+    pub name: String,
+    pub va: u64,
+    pub size: u64,
+    /// Start of the section's bytes in the file; `None` for zero-fill
+    pub file_offset: Option<u64>,
+    pub file_size: Option<u64>,
+    pub read: bool,
+    pub write: bool,
+    pub execute: bool,
+}
+
+/// A discovered function and its basic blocks.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct Function {
+    pub name: String,
+    /// Readable form of `name` when it is a mangled symbol
+    pub demangled: Option<String>,
+    pub entry: u64,
+    pub size: Option<u64>,
+    pub blocks: Vec<BasicBlock>,
+}
+
+/// A basic block; `end` is exclusive.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct BasicBlock {
+    pub start: u64,
+    pub end: u64,
+    pub instructions: u32,
+    /// Start addresses of the successor blocks
+    pub successors: Vec<u64>,
+}
+
+/// A string found in the file.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct StringLiteral {
+    pub offset: u64,
+    pub va: Option<u64>,
+    /// `ascii`, `utf8`, `utf16le` or `utf16be`
+    pub encoding: String,
+    /// Encoded length in bytes, without a terminator
+    pub length: u64,
+    pub value: String,
+}
+
+/// A reference from code to code or data.
+#[derive(Debug, Clone, Default, PartialEq, Eq, PartialOrd, Ord, Serialize, Deserialize)]
+pub struct Xref {
+    pub from: u64,
+    pub to: u64,
+    /// `call`, `jump` (tail call) or `data`
+    pub kind: String,
+    /// Entry of the function containing `from`
+    pub function: Option<u64>,
+}
+
+/// A triage finding: the claim, how much it matters and where the
+/// supporting bytes are.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct Indicator {
+    pub category: String,
+    pub claim: String,
+    /// `info`, `low`, `medium`, `high` or `critical`
+    pub severity: String,
+    pub confidence: f32,
+    /// Triage pass that produced the finding
+    pub pass: String,
+    pub rule: Option<String>,
+    pub evidence: Vec<EvidenceSpan>,
+}
+
+/// Supporting bytes of an indicator.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct EvidenceSpan {
+    pub offset: u64,
+    pub length: u64,
+    pub va: Option<u64>,
+    pub note: Option<String>,
+}
+
+impl Program {
+    /// VA of file offset `offset`, when a section with file bytes maps it.
+    pub fn va_for_offset(&self, offset: u64) -> Option<u64> {
+        self.sections.iter().find_map(|s| {
+            let start = s.file_offset?;
+            let len = s.file_size?.min(s.size);
+            (offset >= start && offset - start < len).then(|| s.va + (offset - start))
+        })
+    }
+
+    /// The function with a basic block covering `va`.
+    pub fn function_containing(&self, va: u64) -> Option<&Function> {
+        self.functions
+            .iter()
+            .find(|f| f.blocks.iter().any(|b| va >= b.start && va < b.end))
+    }
+}
+
+/// Small hand-built program shared by the exporter tests.
+#[cfg(test)]
+pub(crate) fn sample() -> Program {
+    Program {
+        schema_version: 1,
+        tool_version: "0.0.0".into(),
+        name: "a&b.elf".into(),
+        sha256: "00".repeat(32),
+        size: 0x3000,
+        format: "ELF".into(),
+        arch: "x86_64".into(),
+        bits: 64,
+        endianness: "little".into(),
+        entry: Some(0x401000),
+        image_base: None,
+        sections: vec![
+            Section {
+                name: ".comment".into(),
+                va: 0,
+                size: 0x20,
+                file_offset: Some(0x2800),
+                file_size: Some(0x20),
+                read: false,
+                ..Default::default()
+            },
+            Section {
+                name: ".text".into(),
+                va: 0x401000,
+                size: 0x100,
+                file_offset: Some(0x1000),
+                file_size: Some(0x100),
+                read: true,
+                execute: true,
+                ..Default::default()
+            },
+            Section {
+                name: ".rodata".into(),
+                va: 0x402000,
+                size: 0x40,
+                file_offset: Some(0x2000),
+                file_size: Some(0x40),
+                read: true,
+                ..Default::default()
+            },
+            Section {
+                name: ".bss".into(),
+                va: 0x403000,
+                size: 0x10,
+                read: true,
+                write: true,
+                ..Default::default()
+            },
+        ],
+        functions: vec![Function {
+            name: "_ZN3foo3barEv".into(),
+            demangled: Some("foo::bar()".into()),
+            entry: 0x401000,
+            size: Some(0x20),
+            blocks: vec![
+                BasicBlock {
+                    start: 0x401000,
+                    end: 0x401010,
+                    instructions: 4,
+                    successors: vec![0x401010],
+                },
+                BasicBlock {
+                    start: 0x401010,
+                    end: 0x401020,
+                    instructions: 3,
+                    successors: vec![],
+                },
+            ],
+        }],
+        strings: vec![
+            StringLiteral {
+                offset: 0x2000,
+                va: Some(0x402000),
+                encoding: "ascii".into(),
+                length: 5,
+                value: "hello".into(),
+            },
+            StringLiteral {
+                offset: 0x10,
+                va: None,
+                encoding: "ascii".into(),
+                length: 4,
+                value: "ELF>".into(),
+            },
+        ],
+        xrefs: vec![Xref {
+            from: 0x401004,
+            to: 0x402000,
+            kind: "data".into(),
+            function: Some(0x401000),
+        }],
+        indicators: vec![Indicator {
+            category: "strings".into(),
+            claim: "url=<http://x>".into(),
+            severity: "medium".into(),
+            confidence: 0.9,
+            pass: "strings".into(),
+            rule: Some("ioc.url".into()),
+            evidence: vec![EvidenceSpan {
+                offset: 0x2000,
+                length: 5,
+                va: Some(0x402000),
+                note: None,
+            }],
+        }],
+    }
+}
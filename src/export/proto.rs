@@ -0,0 +1,269 @@
+//! Protobuf encoding of the program model.
+//!
+//! Hand-written against `proto/glaurung/program/v1/program.proto` so the
+//! crate needs no code generator; the field numbers below must match that
+//! file. Encoding follows proto3: scalars equal to their default are
+//! omitted, `optional` fields are written whenever they are set, and
+//! repeated integers are packed.
+
+use super::model::{
+    BasicBlock, EvidenceSpan, Function, Indicator, Program, Section, StringLiteral, Xref,
+};
+
+const VARINT: u8 = 0;
+const LEN: u8 = 2;
+const FIXED32: u8 = 5;
+
+#[derive(Default)]
+struct Writer {
+    buf: Vec<u8>,
+}
+
+impl Writer {
+    fn varint(&mut self, mut v: u64) {
+        while v >= 0x80 {
+            self.buf.push((v as u8) | 0x80);
+            v >>= 7;
+        }
+        self.buf.push(v as u8);
+    }
+
+    fn key(&mut self, field: u32, wire: u8) {
+        self.varint((u64::from(field) << 3) | u64::from(wire));
+    }
+
+    fn uint(&mut self, field: u32, v: u64) {
+        if v != 0 {
+            self.opt_uint(field, Some(v));
+        }
+    }
+
+    fn opt_uint(&mut self, field: u32, v: Option<u64>) {
+        if let Some(v) = v {
+            self.key(field, VARINT);
+            self.varint(v);
+        }
+    }
+
+    fn boolean(&mut self, field: u32, v: bool) {
+        self.uint(field, u64::from(v));
+    }
+
+    fn float(&mut self, field: u32, v: f32) {
+        if v != 0.0 {
+            self.key(field, FIXED32);
+            self.buf.extend_from_slice(&v.to_le_bytes());
+        }
+    }
+
+    fn bytes(&mut self, field: u32, v: &[u8]) {
+        self.key(field, LEN);
+        self.varint(v.len() as u64);
+        self.buf.extend_from_slice(v);
+    }
+
+    fn string(&mut self, field: u32, v: &str) {
+        if !v.is_empty() {
+            self.bytes(field, v.as_bytes());
+        }
+    }
+
+    fn opt_string(&mut self, field: u32, v: Option<&str>) {
+        if let Some(v) = v {
+            self.bytes(field, v.as_bytes());
+        }
+    }
+
+    fn packed(&mut self, field: u32, vs: &[u64]) {
+        if !vs.is_empty() {
+            let mut inner = Writer::default();
+            for &v in vs {
+                inner.varint(v);
+            }
+            self.bytes(field, &inner.buf);
+        }
+    }
+
+    fn messages<T>(&mut self, field: u32, items: &[T], encode: fn(&mut Writer, &T)) {
+        for item in items {
+            let mut inner = Writer::default();
+            encode(&mut inner, item);
+            self.bytes(field, &inner.buf);
+        }
+    }
+}
+
+fn section(w: &mut Writer, s: &Section) {
+    w.string(1, &s.name);
+    w.uint(2, s.va);
+    w.uint(3, s.size);
+    w.opt_uint(4, s.file_offset);
+    w.opt_uint(5, s.file_size);
+    w.boolean(6, s.read);
+    w.boolean(7, s.write);
+    w.boolean(8, s.execute);
+}
+
+fn block(w: &mut Writer, b: &BasicBlock) {
+    w.uint(1, b.start);
+    w.uint(2, b.end);
+    w.uint(3, u64::from(b.instructions));
+    w.packed(4, &b.successors);
+}
+
+fn function(w: &mut Writer, f: &Function) {
+    w.string(1, &f.name);
+    w.opt_string(2, f.demangled.as_deref());
+    w.uint(3, f.entry);
+    w.opt_uint(4, f.size);
+    w.messages(5, &f.blocks, block);
+}
+
+fn string_literal(w: &mut Writer, s: &StringLiteral) {
+    w.uint(1, s.offset);
+    w.opt_uint(2, s.va);
+    w.string(3, &s.encoding);
+    w.uint(4, s.length);
+    w.string(5, &s.value);
+}
+
+fn xref(w: &mut Writer, x: &Xref) {
+    w.uint(1, x.from);
+    w.uint(2, x.to);
+    w.string(3, &x.kind);
+    w.opt_uint(4, x.function);
+}
+
+fn evidence(w: &mut Writer, e: &EvidenceSpan) {
+    w.uint(1, e.offset);
+    w.uint(2, e.length);
+    w.opt_uint(3, e.va);
+    w.opt_string(4, e.note.as_deref());
+}
+
+fn indicator(w: &mut Writer, i: &Indicator) {
+    w.string(1, &i.category);
+    w.string(2, &i.claim);
+    w.string(3, &i.severity);
+    w.float(4, i.confidence);
+    w.string(5, &i.pass);
+    w.opt_string(6, i.rule.as_deref());
+    w.messages(7, &i.evidence, evidence);
+}
+
+/// Encode `program` as a `glaurung.program.v1.Program` message.
+pub fn encode_program(program: &Program) -> Vec<u8> {
+    let mut w = Writer::default();
+    w.uint(1, u64::from(program.schema_version));
+    w.string(2, &program.tool_version);
+    w.string(3, &program.name);
+    w.string(4, &program.sha256);
+    w.uint(5, program.size);
+    w.string(6, &program.format);
+    w.string(7, &program.arch);
+    w.uint(8, u64::from(program.bits));
+    w.string(9, &program.endianness);
+    w.opt_uint(10, program.entry);
+    w.opt_uint(11, program.image_base);
+    w.messages(12, &program.sections, section);
+    w.messages(13, &program.functions, function);
+    w.messages(14, &program.strings, string_literal);
+    w.messages(15, &program.xrefs, xref);
+    w.messages(16, &program.indicators, indicator);
+    w.buf
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::export::model::sample;
+
+    /// `(field, wire type, value, bytes)` for each field of one message
+    /// level: varints fill `value`, length-delimited and fixed32 fields
+    /// fill `bytes` (with their length in `value`).
+    fn fields(mut buf: &[u8]) -> Vec<(u32, u8, u64, Vec<u8>)> {
+        fn varint(buf: &mut &[u8]) -> u64 {
+            let mut v = 0u64;
+            for shift in (0..64).step_by(7) {
+                let b = buf[0];
+                *buf = &buf[1..];
+                v |= u64::from(b & 0x7f) << shift;
+                if b < 0x80 {
+                    break;
+                }
+            }
+            v
+        }
+        let mut out = Vec::new();
+        while !buf.is_empty() {
+            let key = varint(&mut buf);
+            let (field, wire) = ((key >> 3) as u32, (key & 7) as u8);
+            match wire {
+                VARINT => out.push((field, wire, varint(&mut buf), Vec::new())),
+                LEN => {
+                    let n = varint(&mut buf) as usize;
+                    out.push((field, wire, n as u64, buf[..n].to_vec()));
+                    buf = &buf[n..];
+                }
+                FIXED32 => {
+                    out.push((field, wire, 4, buf[..4].to_vec()));
+                    buf = &buf[4..];
+                }
+                other => panic!("unexpected wire type {other}"),
+            }
+        }
+        out
+    }
+
+    #[test]
+    fn xref_encodes_to_known_bytes() {
+        let mut w = Writer::default();
+        let x = Xref {
+            from: 0x401004,
+            to: 2,
+            kind: "call".into(),
+            function: Some(0),
+        };
+        xref(&mut w, &x);
+        // `function` is optional, so an explicit zero is still written.
+        assert_eq!(
+            w.buf,
+            [
+                0x08, 0x84, 0xa0, 0x80, 0x02, 0x10, 0x02, 0x1a, 0x04, b'c', b'a', b'l', b'l', 0x20,
+                0x00
+            ]
+        );
+    }
+
+    #[test]
+    fn program_fields_use_the_schema_numbers() {
+        let program = sample();
+        let top = fields(&encode_program(&program));
+        let count = |n: u32| top.iter().filter(|f| f.0 == n).count();
+        assert_eq!(top[0].0, 1);
+        assert_eq!(top[0].2, 1);
+        assert_eq!(
+            (count(12), count(13), count(14), count(15), count(16)),
+            (4, 1, 2, 1, 1)
+        );
+        // image_base is unset and must be absent rather than zero.
+        assert_eq!(count(11), 0);
+        let name = top.iter().find(|f| f.0 == 3).unwrap();
+        assert_eq!(name.3, b"a&b.elf");
+
+        let func = fields(&top.iter().find(|f| f.0 == 13).unwrap().3);
+        assert_eq!(func.iter().find(|f| f.0 == 2).unwrap().3, b"foo::bar()");
+        let first_block = fields(&func.iter().find(|f| f.0 == 5).unwrap().3);
+        // Successors are packed: one length-delimited run of varints.
+        let succ = first_block.iter().find(|f| f.0 == 4).unwrap();
+        assert_eq!(
+            (succ.1, succ.3.clone()),
+            (LEN, vec![0x90, 0xa0, 0x80, 0x02])
+        );
+
+        let ind = fields(&top.iter().find(|f| f.0 == 16).unwrap().3);
+        let conf = ind.iter().find(|f| f.0 == 4).unwrap();
+        assert_eq!(conf.1, FIXED32);
+        assert_eq!(f32::from_le_bytes(conf.3[..4].try_into().unwrap()), 0.9);
+    }
+}
@@ -0,0 +1,168 @@
+//! SARIF 2.1.0 export of triage findings.
+//!
+//! One run per program: each distinct rule becomes a `reportingDescriptor`,
+//! each finding a `result` whose locations are its evidence byte ranges in
+//! the analyzed file, plus the containing function as a logical location
+//! when the evidence lands in code. Descriptive `info` findings are kept
+//! as `informational` results so consumers can filter on `kind`.
+
+use serde_json::{json, Value};
+
+use super::model::{Indicator, Program};
+
+pub const SARIF_VERSION: &str = "2.1.0";
+pub const SARIF_SCHEMA: &str = "https://json.schemastore.org/sarif-2.1.0.json";
+
+/// Rule identifier of a finding: the signature that fired, or the pass
+/// and category that produced it.
+fn rule_id(ind: &Indicator) -> String {
+    match &ind.rule {
+        Some(rule) => rule.clone(),
+        None => format!("{}/{}", ind.pass, ind.category),
+    }
+}
+
+/// SARIF `(kind, level)` for a severity label.
+fn kind_and_level(severity: &str) -> (&'static str, &'static str) {
+    match severity {
+        "critical" | "high" => ("fail", "error"),
+        "medium" => ("fail", "warning"),
+        "low" => ("fail", "note"),
+        _ => ("informational", "none"),
+    }
+}
+
+/// Build the SARIF log for `program`.
+pub fn to_sarif(program: &Program) -> Value {
+    let mut rules: Vec<String> = Vec::new();
+    let mut results = Vec::new();
+    for ind in &program.indicators {
+        let id = rule_id(ind);
+        let index = match rules.iter().position(|r| *r == id) {
+            Some(i) => i,
+            None => {
+                rules.push(id.clone());
+                rules.len() - 1
+            }
+        };
+        let artifact = json!({ "uri": program.name, "index": 0 });
+        let mut locations: Vec<Value> = ind
+            .evidence
+            .iter()
+            .map(|e| {
+                let mut loc = json!({
+                    "physicalLocation": {
+                        "artifactLocation": artifact,
+                        "region": { "byteOffset": e.offset, "byteLength": e.length },
+                    }
+                });
+                if let Some(note) = &e.note {
+                    loc["message"] = json!({ "text": note });
+                }
+                if let Some(f) = e.va.and_then(|va| program.function_containing(va)) {
+                    loc["logicalLocations"] = json!([{
+                        "name": f.name,
+                        "fullyQualifiedName": f.demangled.as_deref().unwrap_or(&f.name),
+                        "kind": "function",
+                    }]);
+                }
+                loc
+            })
+            .collect();
+        if locations.is_empty() {
+            locations.push(json!({ "physicalLocation": { "artifactLocation": artifact } }));
+        }
+        let (kind, level) = kind_and_level(&ind.severity);
+        results.push(json!({
+            "ruleId": id,
+            "ruleIndex": index,
+            "kind": kind,
+            "level": level,
+            "message": { "text": ind.claim },
+            "locations": locations,
+            "properties": {
+                "category": ind.category,
+                "severity": ind.severity,
+                "confidence": ind.confidence,
+                "pass": ind.pass,
+            },
+        }));
+    }
+
+    let rules: Vec<Value> = rules
+        .iter()
+        .map(|id| json!({ "id": id, "shortDescription": { "text": id } }))
+        .collect();
+    json!({
+        "$schema": SARIF_SCHEMA,
+        "version": SARIF_VERSION,
+        "runs": [{
+            "tool": {
+                "driver": {
+                    "name": "glaurung",
+                    "version": program.tool_version,
+                    "semanticVersion": program.tool_version,
+                    "rules": rules,
+                }
+            },
+            "artifacts": [{
+                "location": { "uri": program.name },
+                "length": program.size,
+                "hashes": { "sha-256": program.sha256 },
+                "properties": { "format": program.format, "arch": program.arch },
+            }],
+            "results": results,
+        }]
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::export::model::{sample, EvidenceSpan, Indicator};
+
+    #[test]
+    fn sarif_reports_findings_against_byte_ranges_and_functions() {
+        let mut program = sample();
+        program.indicators.push(Indicator {
+            category: "format".into(),
+            claim: "format=ELF".into(),
+            severity: "info".into(),
+            confidence: 1.0,
+            pass: "sniffers".into(),
+            rule: None,
+            evidence: vec![EvidenceSpan {
+                offset: 0x1004,
+                length: 4,
+                va: Some(0x401004),
+                note: Some("magic".into()),
+            }],
+        });
+        let log = to_sarif(&program);
+        assert_eq!(log["version"], "2.1.0");
+        let run = &log["runs"][0];
+        assert_eq!(run["tool"]["driver"]["name"], "glaurung");
+        assert_eq!(run["artifacts"][0]["hashes"]["sha-256"], program.sha256);
+        let rules = run["tool"]["driver"]["rules"].as_array().unwrap();
+        assert_eq!(rules.len(), 2);
+        assert_eq!(rules[1]["id"], "sniffers/format");
+
+        let results = run["results"].as_array().unwrap();
+        assert_eq!(results[0]["ruleId"], "ioc.url");
+        assert_eq!(results[0]["level"], "warning");
+        let region = &results[0]["locations"][0]["physicalLocation"]["region"];
+        assert_eq!(
+            (region["byteOffset"].as_u64(), region["byteLength"].as_u64()),
+            (Some(0x2000), Some(5))
+        );
+
+        assert_eq!(results[1]["kind"], "informational");
+        assert_eq!(results[1]["ruleIndex"], 1);
+        let loc = &results[1]["locations"][0];
+        assert_eq!(loc["message"]["text"], "magic");
+        assert_eq!(
+            loc["logicalLocations"][0]["fullyQualifiedName"],
+            "foo::bar()"
+        );
+    }
+}
@@ -0,0 +1,424 @@
+//! Signature generation from static libraries.
+//!
+//! Every function symbol defined in a relocatable object (`.o` / `.obj`,
+//! alone or inside an `.a` / `.lib` archive) becomes one signature: its
+//! first `pattern_len` bytes with every relocated byte wildcarded, a CRC16
+//! over the fixed bytes that follow (up to the next relocation), and its
+//! length. Signatures that collide under different names are dropped —
+//! an ambiguous rename is worse than none.
+
+use std::collections::{BTreeMap, BTreeSet};
+
+use object::read::archive::ArchiveFile;
+use object::{Object, ObjectSection, ObjectSymbol, SectionKind, SymbolKind};
+use serde::Serialize;
+
+use super::{crc16, format_pattern, FlirtLibraryFile, FlirtSignatureEntry, FLIRT_SCHEMA_VERSION};
+
+/// Knobs for [`build_library`].
+#[derive(Debug, Clone)]
+pub struct BuildOptions {
+    /// Leading bytes kept as the pattern.
+    pub pattern_len: usize,
+    /// Functions shorter than this are too generic to sign.
+    pub min_length: usize,
+    /// Upper bound on the CRC'd tail (FLIRT stores it in one byte).
+    pub max_crc_len: usize,
+}
+
+impl Default for BuildOptions {
+    fn default() -> Self {
+        Self {
+            pattern_len: 32,
+            min_length: 16,
+            max_crc_len: 255,
+        }
+    }
+}
+
+/// Counters written into the library's `stats`.
+#[derive(Debug, Clone, Default, Serialize)]
+pub struct BuildStats {
+    pub inputs: usize,
+    pub objects: usize,
+    /// Objects built for another architecture.
+    pub skipped_arch: usize,
+    pub functions: usize,
+    pub too_short: usize,
+    pub unique_signatures: usize,
+    pub dropped_ambiguous: usize,
+}
+
+/// Architecture tag of an object, spelled like [`crate::core::binary::Arch`]'s
+/// `Display` so libraries line up with the binaries they are matched against.
+fn arch_tag(arch: object::Architecture) -> Option<&'static str> {
+    use object::Architecture as A;
+    Some(match arch {
+        A::X86_64 | A::X86_64_X32 => "x86_64",
+        A::I386 => "x86",
+        A::Aarch64 => "aarch64",
+        A::Arm => "arm",
+        A::Mips => "mips",
+        A::Mips64 => "mips64",
+        A::PowerPc => "ppc",
+        A::PowerPc64 => "ppc64",
+        A::Riscv32 => "riscv",
+        A::Riscv64 => "riscv64",
+        _ => return None,
+    })
+}
+
+/// The relocatable objects in `data`: the members of an archive, or
+/// `data` itself. Members that are not objects (symbol tables, COFF
+/// import stubs) are skipped.
+pub fn objects(data: &[u8]) -> Vec<(String, &[u8])> {
+    let Ok(archive) = ArchiveFile::parse(data) else {
+        return vec![(String::new(), data)];
+    };
+    archive
+        .members()
+        .filter_map(Result::ok)
+        .filter_map(|m| {
+            let name = String::from_utf8_lossy(m.name()).into_owned();
+            Some((name, m.data(data).ok()?))
+        })
+        .collect()
+}
+
+/// Signatures for every function defined in one relocatable object,
+/// with the object's architecture tag. `None` if `data` is not an object.
+pub fn object_signatures(
+    data: &[u8],
+    source: &str,
+    opts: &BuildOptions,
+    stats: &mut BuildStats,
+) -> Option<(Option<&'static str>, Vec<FlirtSignatureEntry>)> {
+    let obj = object::File::parse(data).ok()?;
+    if obj.kind() != object::ObjectKind::Relocatable {
+        return None;
+    }
+    let mut out = Vec::new();
+    for section in obj.sections().filter(|s| s.kind() == SectionKind::Text) {
+        let Ok(code) = section.data() else {
+            continue;
+        };
+        let mut fixed = vec![true; code.len()];
+        for (offset, reloc) in section.relocations() {
+            // Size is in bits; 0 means "implied by the type" — assume a
+            // 32-bit field, the common case for instruction immediates.
+            let width = match reloc.size() {
+                0 => 4,
+                bits => (bits as usize).div_ceil(8),
+            };
+            let start = offset as usize;
+            let end = (start + width).min(fixed.len());
+            if start < end {
+                fixed[start..end].iter_mut().for_each(|f| *f = false);
+            }
+        }
+
+        let mut symbols: Vec<(u64, u64, String)> = obj
+            .symbols()
+            .filter(|s| s.section_index() == Some(section.index()))
+            .filter(|s| s.kind() == SymbolKind::Text && s.is_definition())
+            .filter_map(|s| {
+                let name = s.name().ok()?;
+                // Local labels and ARM/AArch64 mapping symbols.
+                if name.is_empty() || name.starts_with('.') || name.starts_with('$') {
+                    return None;
+                }
+                Some((s.address(), s.size(), name.to_string()))
+            })
+            .collect();
+        symbols.sort();
+        symbols.dedup_by_key(|(addr, _, _)| *addr);
+
+        for (i, (addr, size, name)) in symbols.iter().enumerate() {
+            // COFF symbols carry no size: run to the next symbol.
+            let end = if *size > 0 {
+                addr + size
+            } else {
+                symbols.get(i + 1).map_or(code.len() as u64, |next| next.0)
+            };
+            let (Ok(start), Ok(end)) = (usize::try_from(*addr), usize::try_from(end)) else {
+                continue;
+            };
+            let (Some(body), Some(keep)) = (code.get(start..end), fixed.get(start..end)) else {
+                continue;
+            };
+            stats.functions += 1;
+            match signature(name, body, keep, source, opts) {
+                Some(entry) => out.push(entry),
+                None => stats.too_short += 1,
+            }
+        }
+    }
+    Some((arch_tag(obj.architecture()), out))
+}
+
+fn signature(
+    name: &str,
+    body: &[u8],
+    keep: &[bool],
+    source: &str,
+    opts: &BuildOptions,
+) -> Option<FlirtSignatureEntry> {
+    if body.len() < opts.min_length {
+        return None;
+    }
+    let pat = body.len().min(opts.pattern_len);
+    if !keep[..pat].iter().any(|&k| k) {
+        return None;
+    }
+    let tail_end = (pat + opts.max_crc_len.min(255)).min(body.len());
+    let crc_len = keep[pat..tail_end]
+        .iter()
+        .position(|&k| !k)
+        .unwrap_or(tail_end - pat);
+    Some(FlirtSignatureEntry {
+        name: name.to_string(),
+        prologue_hex: String::new(),
+        pattern: format_pattern(&body[..pat], &keep[..pat]),
+        crc_len: crc_len as u8,
+        crc16: crc16(&body[pat..pat + crc_len]),
+        length: body.len() as u32,
+        source_binary: source.to_string(),
+    })
+}
+
+/// Build a library for `arch` from `(source name, file bytes)` inputs,
+/// each an archive or a single relocatable object.
+pub fn build_library<'a>(
+    inputs: impl IntoIterator<Item = (String, &'a [u8])>,
+    arch: &str,
+    opts: &BuildOptions,
+) -> FlirtLibraryFile {
+    let mut stats = BuildStats::default();
+    // (pattern, crc_len, crc16, length) → names seen with that key.
+    let mut by_key: BTreeMap<(String, u8, u16, u32), (FlirtSignatureEntry, BTreeSet<String>)> =
+        BTreeMap::new();
+    for (source, data) in inputs {
+        stats.inputs += 1;
+        for (member, bytes) in objects(data) {
+            let label = if member.is_empty() {
+                source.clone()
+            } else {
+                format!("{source}({member})")
+            };
+            let Some((tag, entries)) = object_signatures(bytes, &label, opts, &mut stats) else {
+                continue;
+            };
+            stats.objects += 1;
+            if tag.is_some_and(|t| !t.eq_ignore_ascii_case(arch)) {
+                stats.skipped_arch += 1;
+                continue;
+            }
+            for e in entries {
+                let key = (e.pattern.clone(), e.crc_len, e.crc16, e.length);
+                let slot = by_key
+                    .entry(key)
+                    .or_insert_with(|| (e.clone(), BTreeSet::new()));
+                slot.1.insert(e.name);
+            }
+        }
+    }
+
+    let mut entries = Vec::new();
+    for (entry, names) in by_key.into_values() {
+        if names.len() > 1 {
+            stats.dropped_ambiguous += 1;
+        } else {
+            entries.push(entry);
+        }
+    }
+    entries.sort_by(|a, b| a.name.cmp(&b.name).then_with(|| a.pattern.cmp(&b.pattern)));
+    stats.unique_signatures = entries.len();
+
+    let mut index: std::collections::HashMap<String, Vec<usize>> = Default::default();
+    for (i, e) in entries.iter().enumerate() {
+        let prefix: String = e.pattern.chars().take(8).collect();
+        index.entry(prefix).or_default().push(i);
+    }
+    FlirtLibraryFile {
+        schema_version: FLIRT_SCHEMA_VERSION.to_string(),
+        arch: arch.to_string(),
+        prologue_len: opts.pattern_len,
+        entries,
+        index,
+        stats: serde_json::to_value(&stats).unwrap_or_default(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::flirt::FlirtLibrary;
+
+    /// A minimal ELF64 x86-64 relocatable: one `.text` with the given
+    /// functions, `.rela.text` patching 4 bytes at each offset in `relocs`.
+    fn elf_object(funcs: &[(&str, &[u8])], relocs: &[usize]) -> Vec<u8> {
+        let mut text = Vec::new();
+        let mut syms = vec![[0u8; 24]];
+        let mut strtab = vec![0u8];
+        for (name, body) in funcs {
+            let mut sym = [0u8; 24];
+            sym[..4].copy_from_slice(&(strtab.len() as u32).to_le_bytes());
+            sym[4] = 0x12; // STB_GLOBAL, STT_FUNC
+            sym[6..8].copy_from_slice(&1u16.to_le_bytes()); // .text
+            sym[8..16].copy_from_slice(&(text.len() as u64).to_le_bytes());
+            sym[16..24].copy_from_slice(&(body.len() as u64).to_le_bytes());
+            syms.push(sym);
+            strtab.extend_from_slice(name.as_bytes());
+            strtab.push(0);
+            text.extend_from_slice(body);
+        }
+        let mut rela = Vec::new();
+        for &off in relocs {
+            rela.extend_from_slice(&(off as u64).to_le_bytes());
+            rela.extend_from_slice(&((1u64 << 32) | 4).to_le_bytes()); // sym 1, R_X86_64_PLT32
+            rela.extend_from_slice(&(-4i64).to_le_bytes());
+        }
+        let shstrtab = b"\0.text\0.rela.text\0.symtab\0.strtab\0.shstrtab\0".to_vec();
+        let symtab: Vec<u8> = syms.concat();
+
+        let mut out = vec![0u8; 64];
+        let mut place = |blob: &[u8], out: &mut Vec<u8>| {
+            while out.len() % 8 != 0 {
+                out.push(0);
+            }
+            let off = out.len();
+            out.extend_from_slice(blob);
+            off
+        };
+        let text_off = place(&text, &mut out);
+        let rela_off = place(&rela, &mut out);
+        let sym_off = place(&symtab, &mut out);
+        let str_off = place(&strtab, &mut out);
+        let shstr_off = place(&shstrtab, &mut out);
+        while out.len() % 8 != 0 {
+            out.push(0);
+        }
+        let shoff = out.len();
+        // (name, type, flags, offset, size, link, info, align, entsize)
+        let headers: [(u32, u32, u64, usize, usize, u32, u32, u64, u64); 6] = [
+            (0, 0, 0, 0, 0, 0, 0, 0, 0),
+            (1, 1, 0x6, text_off, text.len(), 0, 0, 16, 0),
+            (7, 4, 0x40, rela_off, rela.len(), 3, 1, 8, 24),
+            (18, 2, 0, sym_off, symtab.len(), 4, 1, 8, 24),
+            (26, 3, 0, str_off, strtab.len(), 0, 0, 1, 0),
+            (34, 3, 0, shstr_off, shstrtab.len(), 0, 0, 1, 0),
+        ];
+        for (name, ty, flags, off, size, link, info, align, entsize) in headers {
+            out.extend_from_slice(&name.to_le_bytes());
+            out.extend_from_slice(&ty.to_le_bytes());
+            out.extend_from_slice(&flags.to_le_bytes());
+            out.extend_from_slice(&0u64.to_le_bytes());
+            out.extend_from_slice(&(off as u64).to_le_bytes());
+            out.extend_from_slice(&(size as u64).to_le_bytes());
+            out.extend_from_slice(&link.to_le_bytes());
+            out.extend_from_slice(&info.to_le_bytes());
+            out.extend_from_slice(&align.to_le_bytes());
+            out.extend_from_slice(&entsize.to_le_bytes());
+        }
+        out[..4].copy_from_slice(b"\x7fELF");
+        out[4..8].copy_from_slice(&[2, 1, 1, 0]);
+        out[16..18].copy_from_slice(&1u16.to_le_bytes()); // ET_REL
+        out[18..20].copy_from_slice(&62u16.to_le_bytes()); // EM_X86_64
+        out[20..24].copy_from_slice(&1u32.to_le_bytes());
+        out[40..48].copy_from_slice(&(shoff as u64).to_le_bytes());
+        out[52..54].copy_from_slice(&64u16.to_le_bytes());
+        out[58..60].copy_from_slice(&64u16.to_le_bytes());
+        out[60..62].copy_from_slice(&6u16.to_le_bytes());
+        out[62..64].copy_from_slice(&5u16.to_le_bytes());
+        out
+    }
+
+    fn ar(members: &[(&str, &[u8])]) -> Vec<u8> {
+        let mut out = b"!<arch>\n".to_vec();
+        for (name, body) in members {
+            let header = format!("{:<16}{:<32}{:<10}`\n", format!("{name}/"), 0, body.len());
+            out.extend_from_slice(header.as_bytes());
+            out.extend_from_slice(body);
+            if body.len() % 2 == 1 {
+                out.push(b'\n');
+            }
+        }
+        out
+    }
+
+    /// `push rbp; mov rbp, rsp; call <reloc>; ...; pop rbp; ret`, padded
+    /// with `salt` so distinct functions differ.
+    fn body(salt: u8) -> Vec<u8> {
+        let mut b = vec![0x55, 0x48, 0x89, 0xe5, 0xe8, 0, 0, 0, 0];
+        b.extend(std::iter::repeat_n(salt, 40));
+        b.extend_from_slice(&[0x5d, 0xc3]);
+        b
+    }
+
+    #[test]
+    fn relocations_become_wildcards_and_tail_is_crcd() {
+        let f = body(0x90);
+        let obj = elf_object(&[("my_memcpy", &f)], &[5]);
+        let mut stats = BuildStats::default();
+        let (tag, sigs) =
+            object_signatures(&obj, "t.o", &BuildOptions::default(), &mut stats).unwrap();
+        assert_eq!(tag, Some("x86_64"));
+        let sig = &sigs[0];
+        assert_eq!(sig.name, "my_memcpy");
+        assert!(sig.pattern.starts_with("554889E5E8........9090"));
+        assert_eq!(sig.crc_len as usize, f.len() - 32);
+        assert_eq!(sig.crc16, crc16(&f[32..]));
+        assert_eq!(sig.length as usize, f.len());
+    }
+
+    #[test]
+    fn library_from_archive_matches_relinked_code() {
+        let a = body(0x90);
+        let b = body(0xcc);
+        let lib_a = elf_object(&[("inflate_fast", &a), ("tiny", &[0xc3])], &[5]);
+        let lib_b = elf_object(&[("adler32", &b)], &[5]);
+        let archive = ar(&[("inflate.o", &lib_a), ("adler32.o", &lib_b)]);
+        let file = build_library(
+            [("libz.a".to_string(), &archive[..])],
+            "x86_64",
+            &BuildOptions::default(),
+        );
+        assert_eq!(file.stats["objects"], 2);
+        assert_eq!(file.stats["too_short"], 1);
+        let lib = FlirtLibrary::from_file(file);
+        assert_eq!(lib.signature_count(), 2);
+
+        // The linked copy has a real call displacement where the object
+        // had a relocation.
+        let mut linked = a.clone();
+        linked[5..9].copy_from_slice(&0x1234u32.to_le_bytes());
+        linked.extend_from_slice(&[0xcc; 8]);
+        assert_eq!(lib.match_prologue(&linked), Some("inflate_fast"));
+
+        // A change in the CRC'd tail is a different function.
+        linked[40] = 0x91;
+        assert_eq!(lib.match_prologue(&linked), None);
+    }
+
+    #[test]
+    fn colliding_names_are_dropped_and_other_arches_skipped() {
+        let f = body(0x90);
+        let one = elf_object(&[("strlen", &f)], &[5]);
+        let two = elf_object(&[("__strlen_sse2", &f)], &[5]);
+        let file = build_library(
+            [("a.o".to_string(), &one[..]), ("b.o".to_string(), &two[..])],
+            "x86_64",
+            &BuildOptions::default(),
+        );
+        assert!(file.entries.is_empty());
+        assert_eq!(file.stats["dropped_ambiguous"], 1);
+
+        let file = build_library(
+            [("a.o".to_string(), &one[..])],
+            "aarch64",
+            &BuildOptions::default(),
+        );
+        assert!(file.entries.is_empty());
+        assert_eq!(file.stats["skipped_arch"], 1);
+    }
+}
@@ -1,25 +1,49 @@
 //! FLIRT-style signature matching (#158).
 //!
-//! Loads a JSON signature library produced by
-//! `python -m glaurung.tools.build_flirt_library` and uses it to rename
-//! `sub_*` functions in stripped binaries during the analysis pass.
+//! Loads JSON signature libraries and uses them to rename `sub_*`
+//! functions in stripped binaries during the analysis pass.
 //!
-//! v1 design: exact-byte-equality match on a fixed-length prologue
-//! starting at the function's entry VA. Library signatures with
-//! identical prologues but different names were already pruned at
-//! build time, so a hit here is unambiguous.
+//! A signature is a leading byte pattern with wildcards where the linker
+//! patched the code (relocations), a CRC16 over the bytes that follow it,
+//! and the function's length — the same shape as IDA FLIRT's `.pat`
+//! lines. [`build`] generates them from the relocatable objects in `.a` /
+//! `.lib` archives. Schema 1 libraries (exact 32-byte prologues harvested
+//! by `python -m glaurung.tools.build_flirt_library`) still load; their
+//! entries become patterns without wildcards, CRC or length.
+//!
+//! A hit is only reported when every matching signature agrees on the
+//! name, so identical library stubs with different names never rename.
+
+pub mod build;
 
 use std::collections::HashMap;
-use std::path::PathBuf;
+use std::path::{Path, PathBuf};
 
 use serde::{Deserialize, Serialize};
 
+use crate::core::binary::Arch;
 use crate::core::function::Function;
 
+/// Library schema written by [`build::build_library`].
+pub const FLIRT_SCHEMA_VERSION: &str = "2";
+
 #[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct FlirtSignatureEntry {
     pub name: String,
+    /// Schema 1: exact prologue bytes, `prologue_len` long.
+    #[serde(default, skip_serializing_if = "String::is_empty")]
     pub prologue_hex: String,
+    /// Schema 2: leading bytes as hex pairs, `..` for a wildcard byte.
+    #[serde(default, skip_serializing_if = "String::is_empty")]
+    pub pattern: String,
+    /// Bytes after the pattern covered by `crc16`.
+    #[serde(default)]
+    pub crc_len: u8,
+    #[serde(default)]
+    pub crc16: u16,
+    /// Function length in bytes; 0 when unknown.
+    #[serde(default)]
+    pub length: u32,
     #[serde(default)]
     pub source_binary: String,
 }
@@ -28,6 +52,8 @@ pub struct FlirtSignatureEntry {
 pub struct FlirtLibraryFile {
     pub schema_version: String,
     pub arch: String,
+    /// Schema 1 prologue length; the longest pattern in schema 2.
+    #[serde(default)]
     pub prologue_len: usize,
     pub entries: Vec<FlirtSignatureEntry>,
     /// Hex-prefix → indices into `entries`. Built by the Python tool;
@@ -39,28 +65,110 @@ pub struct FlirtLibraryFile {
     pub stats: serde_json::Value,
 }
 
-/// In-memory matcher: prologue bytes → (name, length).
+/// One compiled signature.
+#[derive(Debug, Clone, PartialEq)]
+pub struct FlirtSignature {
+    pub name: String,
+    pub bytes: Vec<u8>,
+    /// `false` where `bytes` holds a wildcard.
+    pub mask: Vec<bool>,
+    pub crc_len: usize,
+    pub crc16: u16,
+    /// Function length; 0 when unknown.
+    pub length: usize,
+}
+
+impl FlirtSignature {
+    fn from_entry(e: &FlirtSignatureEntry, prologue_len: usize) -> Option<Self> {
+        let (bytes, mask) = if e.pattern.is_empty() {
+            let bytes = hex_to_bytes(&e.prologue_hex).ok()?;
+            if bytes.len() != prologue_len {
+                return None;
+            }
+            let mask = vec![true; bytes.len()];
+            (bytes, mask)
+        } else {
+            parse_pattern(&e.pattern)?
+        };
+        if bytes.is_empty() {
+            return None;
+        }
+        Some(Self {
+            name: e.name.clone(),
+            bytes,
+            mask,
+            crc_len: e.crc_len as usize,
+            crc16: e.crc16,
+            length: e.length as usize,
+        })
+    }
+
+    /// True if `code`, starting at a candidate entry, is this function.
+    pub fn matches(&self, code: &[u8]) -> bool {
+        let pat = self.bytes.len();
+        if code.len() < (pat + self.crc_len).max(self.length) {
+            return false;
+        }
+        let fixed = self
+            .bytes
+            .iter()
+            .zip(&self.mask)
+            .zip(code)
+            .all(|((b, keep), c)| !keep || b == c);
+        fixed && (self.crc_len == 0 || crc16(&code[pat..pat + self.crc_len]) == self.crc16)
+    }
+}
+
+/// In-memory matcher, indexed by the first pattern byte.
 pub struct FlirtLibrary {
     pub arch: String,
+    /// Shortest pattern: no signature matches fewer bytes.
     pub prologue_len: usize,
-    by_prologue: HashMap<Vec<u8>, String>,
+    signatures: Vec<FlirtSignature>,
+    by_first: Vec<Vec<usize>>,
+    /// Signatures whose first byte is a wildcard.
+    any_first: Vec<usize>,
 }
 
 impl FlirtLibrary {
     pub fn from_file(file: FlirtLibraryFile) -> Self {
-        let mut by_prologue: HashMap<Vec<u8>, String> = HashMap::new();
-        for e in &file.entries {
-            if let Ok(bytes) = hex_to_bytes(&e.prologue_hex) {
-                if bytes.len() == file.prologue_len {
-                    by_prologue.insert(bytes, e.name.clone());
-                }
+        let signatures = file
+            .entries
+            .iter()
+            .filter_map(|e| FlirtSignature::from_entry(e, file.prologue_len))
+            .collect();
+        Self::from_signatures(file.arch, signatures)
+    }
+
+    pub fn from_signatures(arch: String, mut signatures: Vec<FlirtSignature>) -> Self {
+        signatures.retain(|s| !s.bytes.is_empty() && s.mask.len() == s.bytes.len());
+        let mut lib = Self {
+            arch,
+            prologue_len: 0,
+            signatures,
+            by_first: vec![Vec::new(); 256],
+            any_first: Vec::new(),
+        };
+        lib.reindex();
+        lib
+    }
+
+    fn reindex(&mut self) {
+        self.by_first.iter_mut().for_each(Vec::clear);
+        self.any_first.clear();
+        for (i, sig) in self.signatures.iter().enumerate() {
+            if sig.mask[0] {
+                self.by_first[sig.bytes[0] as usize].push(i);
+            } else {
+                self.any_first.push(i);
             }
         }
-        Self {
-            arch: file.arch,
-            prologue_len: file.prologue_len,
-            by_prologue,
-        }
+        self.prologue_len = self
+            .signatures
+            .iter()
+            .map(|s| s.bytes.len())
+            .min()
+            .unwrap_or(0);
     }
 
     pub fn from_json(s: &str) -> Result<Self, serde_json::Error> {
@@ -68,15 +176,39 @@ impl FlirtLibrary {
         Ok(Self::from_file(f))
     }
 
-    pub fn match_prologue(&self, prologue: &[u8]) -> Option<&str> {
-        if prologue.len() != self.prologue_len {
-            return None;
+    /// Read one library file.
+    pub fn load(path: &Path) -> std::io::Result<Self> {
+        let text = std::fs::read_to_string(path)?;
+        Self::from_json(&text).map_err(|e| std::io::Error::new(std::io::ErrorKind::InvalidData, e))
+    }
+
+    /// Add `other`'s signatures to this library.
+    pub fn merge(&mut self, other: FlirtLibrary) {
+        self.signatures.extend(other.signatures);
+        self.reindex();
+    }
+
+    /// Name of the function whose code starts at `code[0]`, if every
+    /// signature that matches agrees on it. `code` may run past the end
+    /// of the function.
+    pub fn match_prologue(&self, code: &[u8]) -> Option<&str> {
+        let first = *code.first()?;
+        let mut found: Option<&str> = None;
+        for &i in self.by_first[first as usize].iter().chain(&self.any_first) {
+            let sig = &self.signatures[i];
+            if !sig.matches(code) {
+                continue;
+            }
+            match found {
+                Some(name) if name != sig.name => return None,
+                _ => found = Some(&sig.name),
+            }
         }
-        self.by_prologue.get(prologue).map(|s| s.as_str())
+        found
     }
 
     pub fn signature_count(&self) -> usize {
-        self.by_prologue.len()
+        self.signatures.len()
     }
 }
 
@@ -92,35 +224,107 @@ fn hex_to_bytes(s: &str) -> Result<Vec<u8>, &'static str> {
     Ok(out)
 }
 
-/// Look up the default library file. Search order:
-///   1. `GLAURUNG_FLIRT_LIB` env var (single file).
-///   2. `data/sigs/glaurung-base.<arch>.flirt.json` relative to the cwd.
-///   3. `data/sigs/glaurung-base.x86_64.flirt.json` as a final fallback.
-///
-/// Returns `None` if no library is reachable — that's fine, the matcher
-/// pass becomes a no-op when no library is available.
-pub fn default_library_path() -> Option<PathBuf> {
-    if let Ok(p) = std::env::var("GLAURUNG_FLIRT_LIB") {
-        let pb = PathBuf::from(p);
-        if pb.exists() {
-            return Some(pb);
+/// Parse `.pat` notation: hex pairs, `..` for a wildcard byte.
+pub fn parse_pattern(s: &str) -> Option<(Vec<u8>, Vec<bool>)> {
+    if s.len() % 2 != 0 || !s.is_ascii() {
+        return None;
+    }
+    let mut bytes = Vec::with_capacity(s.len() / 2);
+    let mut mask = Vec::with_capacity(s.len() / 2);
+    for i in (0..s.len()).step_by(2) {
+        let pair = &s[i..i + 2];
+        if pair == ".." {
+            bytes.push(0);
+            mask.push(false);
+        } else {
+            bytes.push(u8::from_str_radix(pair, 16).ok()?);
+            mask.push(true);
         }
     }
-    let cwd = std::env::current_dir().ok()?;
-    let candidate = cwd.join("data/sigs/glaurung-base.x86_64.flirt.json");
-    if candidate.exists() {
-        return Some(candidate);
+    Some((bytes, mask))
+}
+
+/// Render `bytes` in `.pat` notation, `..` where `mask` is `false`.
+pub fn format_pattern(bytes: &[u8], mask: &[bool]) -> String {
+    bytes
+        .iter()
+        .zip(mask)
+        .map(|(b, keep)| {
+            if *keep {
+                format!("{b:02X}")
+            } else {
+                "..".to_string()
+            }
+        })
+        .collect()
+}
+
+/// CRC16 as FLIRT computes it over the bytes after the pattern
+/// (CRC-16/X.25: reflected 0x1021, initial and final 0xFFFF, then
+/// byte-swapped).
+pub fn crc16(data: &[u8]) -> u16 {
+    let mut crc: u16 = 0xffff;
+    for &b in data {
+        let mut d = b;
+        for _ in 0..8 {
+            crc = if (crc ^ d as u16) & 1 != 0 {
+                (crc >> 1) ^ 0x8408
+            } else {
+                crc >> 1
+            };
+            d >>= 1;
+        }
     }
-    None
+    (!crc).swap_bytes()
 }
 
-/// Try to load the default FLIRT library. Returns `None` silently if no
-/// library is available; analysis falls back to whatever DWARF and
+/// Library files to load. `GLAURUNG_FLIRT_LIB` lists files or directories
+/// (separated like `PATH`); without it, every `*.flirt.json` under
+/// `data/sigs/` relative to the cwd. Directories contribute their
+/// `*.flirt.json` files in name order.
+pub fn default_library_paths() -> Vec<PathBuf> {
+    let roots: Vec<PathBuf> = match std::env::var_os("GLAURUNG_FLIRT_LIB") {
+        Some(v) => std::env::split_paths(&v).collect(),
+        None => match std::env::current_dir() {
+            Ok(cwd) => vec![cwd.join("data/sigs")],
+            Err(_) => Vec::new(),
+        },
+    };
+    let mut out = Vec::new();
+    for root in roots {
+        if root.is_file() {
+            out.push(root);
+        } else if let Ok(dir) = std::fs::read_dir(&root) {
+            let mut files: Vec<PathBuf> = dir
+                .filter_map(|e| e.ok().map(|e| e.path()))
+                .filter(|p| p.to_string_lossy().ends_with(".flirt.json"))
+                .collect();
+            files.sort();
+            out.extend(files);
+        }
+    }
+    out
+}
+
+/// Load and merge every default library built for `arch`. Returns `None`
+/// silently if there is none; analysis falls back to whatever DWARF and
 /// symbol-table renaming already accomplished.
-pub fn load_default_library() -> Option<FlirtLibrary> {
-    let path = default_library_path()?;
-    let text = std::fs::read_to_string(&path).ok()?;
-    FlirtLibrary::from_json(&text).ok()
+pub fn load_default_library(arch: Arch) -> Option<FlirtLibrary> {
+    let arch = arch.to_string();
+    let mut merged: Option<FlirtLibrary> = None;
+    for path in default_library_paths() {
+        let Ok(lib) = FlirtLibrary::load(&path) else {
+            continue;
+        };
+        if !lib.arch.eq_ignore_ascii_case(&arch) {
+            continue;
+        }
+        match merged.as_mut() {
+            Some(m) => m.merge(lib),
+            None => merged = Some(lib),
+        }
+    }
+    merged.filter(|lib| lib.signature_count() > 0)
 }
 
 /// Build a (vm_start, vm_size, file_offset) → (vm_start, vm_size, file_offset)
@@ -220,13 +424,11 @@ pub fn discover_flirt_seeds(
         }
         let vbase = sec.address();
 
-        // Slide a window byte by byte. Could be sped up with a 4-byte
-        // prefix index, but on small text sections this is already
-        // sub-millisecond; keep v1 simple.
+        // Slide a window byte by byte; the first-byte index keeps each
+        // probe to the handful of signatures that could start there.
         let mut off = start;
         while off + lib.prologue_len <= end {
-            let proto = &data[off..off + lib.prologue_len];
-            if let Some(name) = lib.match_prologue(proto) {
+            if let Some(name) = lib.match_prologue(&data[off..end]) {
                 let va = vbase + (off as u64 - faddr);
                 if !known_starts.contains(&va) && !seen_vas.contains(&va) {
                     // Don't seed the same name twice — typically means
@@ -245,9 +447,9 @@ pub fn discover_flirt_seeds(
     seeds
 }
 
-/// Rename every `sub_*` function whose entry-VA prologue exactly matches
-/// a signature in `lib`. Reads prologues from `data` via the binary's
-/// section table (`object` crate). Returns the number of renames applied.
+/// Rename every `sub_*` function whose entry-VA code matches a signature
+/// in `lib`. Reads code from `data` via the binary's section table
+/// (`object` crate). Returns the number of renames applied.
 pub fn apply_flirt_overrides(data: &[u8], functions: &mut [Function], lib: &FlirtLibrary) -> usize {
     let maps = build_va_map(data);
     let mut renamed = 0usize;
@@ -261,12 +463,10 @@ pub fn apply_flirt_overrides(data: &[u8], functions: &mut [Function], lib: &Flir
             Some(o) => o,
             None => continue,
         };
-        let end = foff.saturating_add(lib.prologue_len);
-        if end > data.len() {
+        let Some(code) = data.get(foff..) else {
             continue;
-        }
-        let proto = &data[foff..end];
-        if let Some(name) = lib.match_prologue(proto) {
+        };
+        if let Some(name) = lib.match_prologue(code) {
             f.name = name.to_string();
             renamed += 1;
         }
@@ -25,6 +25,8 @@ pub enum ApkError {
     NotFound(String),
     /// DEFLATE decompression failed.
     Inflate(String),
+    /// The member inflates past the caller's size limit.
+    TooLarge(u64),
 }
 
 impl std::fmt::Display for ApkError {
@@ -35,6 +37,7 @@ impl std::fmt::Display for ApkError {
             Self::UnsupportedCompression(m) => write!(f, "unsupported compression method {}", m),
             Self::NotFound(n) => write!(f, "member not found: {}", n),
             Self::Inflate(e) => write!(f, "inflate failed: {}", e),
+            Self::TooLarge(max) => write!(f, "member exceeds {} bytes", max),
         }
     }
 }
@@ -122,6 +125,13 @@ impl<'a> ApkReader<'a> {
 
     /// Extract and decompress a member by exact path.
     pub fn read(&self, name: &str) -> Result<Vec<u8>> {
+        self.read_limited(name, u64::MAX)
+    }
+
+    /// Like [`read`](Self::read), but fails with [`ApkError::TooLarge`]
+    /// instead of inflating more than `max` bytes, whatever the central
+    /// directory claims (DEFLATE bombs lie about their size).
+    pub fn read_limited(&self, name: &str, max: u64) -> Result<Vec<u8>> {
         let e = self
             .entries
             .get(name)
@@ -139,12 +149,17 @@ impl<'a> ApkReader<'a> {
             .ok_or(ApkError::Truncated)?;
 
         match e.method {
+            METHOD_STORED if comp.len() as u64 > max => Err(ApkError::TooLarge(max)),
             METHOD_STORED => Ok(comp.to_vec()),
             METHOD_DEFLATE => {
-                let mut out = Vec::with_capacity(e.uncomp_size as usize);
+                let mut out = Vec::with_capacity(e.uncomp_size.min(max) as usize);
                 flate2::read::DeflateDecoder::new(comp)
+                    .take(max.saturating_add(1))
                     .read_to_end(&mut out)
                     .map_err(|err| ApkError::Inflate(err.to_string()))?;
+                if out.len() as u64 > max {
+                    return Err(ApkError::TooLarge(max));
+                }
                 Ok(out)
             }
             other => Err(ApkError::UnsupportedCompression(other)),
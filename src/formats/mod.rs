@@ -4,5 +4,22 @@ pub mod apk;
 pub mod axml;
 pub mod dex;
 pub mod elf;
+pub mod object_image;
 pub mod pe;
 pub mod sepolicy;
+pub mod wasm;
+
+use crate::core::image::BinaryImage;
+
+/// Open `data` with whichever backend recognises it, returning the common
+/// `BinaryImage` view. Returns `None` for formats without a backend.
+pub fn open_image(data: &[u8]) -> Option<Box<dyn BinaryImage + '_>> {
+    if wasm::WasmModule::is_wasm(data) {
+        return wasm::WasmModule::parse(data)
+            .ok()
+            .map(|m| Box::new(m) as Box<dyn BinaryImage + '_>);
+    }
+    object_image::ObjectImage::parse(data)
+        .ok()
+        .map(|img| Box::new(img) as Box<dyn BinaryImage + '_>)
+}
@@ -0,0 +1,431 @@
+//! `BinaryImage` backend for ELF, PE, and Mach-O via the `object` crate.
+
+use std::borrow::Cow;
+use std::sync::OnceLock;
+
+use crate::core::address_map::AddressMap;
+use crate::core::binary::{Arch, Endianness, Format};
+use crate::core::image::{
+    build_address_map, BinaryImage, ImageImport, ImageSection, ImageSegment, ImageSymbol,
+};
+use crate::core::segment::Perms;
+use crate::core::symbol::SymbolKind;
+use crate::error::{ErrorKind, Partial, Warning};
+use object::read::{Object, ObjectSection, ObjectSegment, ObjectSymbol};
+use object::{SectionFlags, SectionKind, SegmentFlags};
+
+/// Map an `object` architecture onto the core `Arch` enum.
+pub fn arch_from_object(arch: object::Architecture) -> Arch {
+    match arch {
+        object::Architecture::I386 => Arch::X86,
+        object::Architecture::X86_64 => Arch::X86_64,
+        object::Architecture::Arm => Arch::ARM,
+        object::Architecture::Aarch64 => Arch::AArch64,
+        object::Architecture::Mips => Arch::MIPS,
+        object::Architecture::Mips64 => Arch::MIPS64,
+        object::Architecture::PowerPc => Arch::PPC,
+        object::Architecture::PowerPc64 => Arch::PPC64,
+        object::Architecture::Riscv32 => Arch::RISCV,
+        object::Architecture::Riscv64 => Arch::RISCV64,
+        _ => Arch::Unknown,
+    }
+}
+
+/// Map an `object` container format onto the core `Format` enum.
+pub fn format_from_object(format: object::BinaryFormat) -> Format {
+    match format {
+        object::BinaryFormat::Elf => Format::ELF,
+        object::BinaryFormat::Coff => Format::COFF,
+        object::BinaryFormat::Pe => Format::PE,
+        object::BinaryFormat::MachO => Format::MachO,
+        _ => Format::Unknown,
+    }
+}
+
+/// R/W/X permissions of a segment from its format-specific flags.
+pub(crate) fn segment_perms(flags: SegmentFlags) -> Perms {
+    match flags {
+        SegmentFlags::Elf { p_flags } => Perms::new(
+            p_flags & object::elf::PF_R != 0,
+            p_flags & object::elf::PF_W != 0,
+            p_flags & object::elf::PF_X != 0,
+        ),
+        SegmentFlags::MachO { initprot, .. } => Perms::new(
+            initprot & object::macho::VM_PROT_READ != 0,
+            initprot & object::macho::VM_PROT_WRITE != 0,
+            initprot & object::macho::VM_PROT_EXECUTE != 0,
+        ),
+        SegmentFlags::Coff { characteristics } => coff_perms(characteristics),
+        _ => Perms::new(true, false, false),
+    }
+}
+
+fn coff_perms(characteristics: u32) -> Perms {
+    Perms::new(
+        characteristics & object::pe::IMAGE_SCN_MEM_READ != 0,
+        characteristics & object::pe::IMAGE_SCN_MEM_WRITE != 0,
+        characteristics & object::pe::IMAGE_SCN_MEM_EXECUTE != 0,
+    )
+}
+
+/// Effective permissions of a section from its flags (or kind, for Mach-O).
+pub(crate) fn section_perms(kind: SectionKind, flags: SectionFlags) -> Perms {
+    match flags {
+        SectionFlags::Elf { sh_flags } => Perms::new(
+            sh_flags & u64::from(object::elf::SHF_ALLOC) != 0,
+            sh_flags & u64::from(object::elf::SHF_WRITE) != 0,
+            sh_flags & u64::from(object::elf::SHF_EXECINSTR) != 0,
+        ),
+        SectionFlags::Coff { characteristics } => coff_perms(characteristics),
+        // Mach-O section flags do not carry protections; derive from kind.
+        _ => Perms::new(
+            true,
+            matches!(
+                kind,
+                SectionKind::Data | SectionKind::UninitializedData | SectionKind::Tls
+            ),
+            kind == SectionKind::Text,
+        ),
+    }
+}
+
+fn symbol_kind(kind: object::SymbolKind) -> SymbolKind {
+    match kind {
+        object::SymbolKind::Text => SymbolKind::Function,
+        object::SymbolKind::Data | object::SymbolKind::Tls => SymbolKind::Object,
+        object::SymbolKind::Section => SymbolKind::Section,
+        _ => SymbolKind::Other,
+    }
+}
+
+/// ELF/PE/Mach-O image parsed with `object`.
+pub struct ObjectImage<'data> {
+    data: &'data [u8],
+    file: object::read::File<'data>,
+    /// Built on first use; passes translate addresses in tight loops.
+    map: OnceLock<AddressMap>,
+}
+
+impl<'data> ObjectImage<'data> {
+    /// Parse `data` as an ELF, PE, COFF, or Mach-O image.
+    pub fn parse(data: &'data [u8]) -> Result<Self, object::read::Error> {
+        let file = object::read::File::parse(data)?;
+        Ok(Self {
+            data,
+            file,
+            map: OnceLock::new(),
+        })
+    }
+
+    /// Parse `data`, reporting tables that could not be read in full as
+    /// warnings instead of silently dropping them. Only a bad header is
+    /// fatal; the image still exposes everything that did parse.
+    pub fn parse_partial(data: &'data [u8]) -> Result<Partial<Self>, object::read::Error> {
+        let image = Self::parse(data)?;
+        let warnings = image.audit();
+        Ok(Partial::with_warnings(image, warnings))
+    }
+
+    /// Problems the `BinaryImage` accessors paper over: sections and
+    /// segments whose file data runs past the end of the input, and symbol,
+    /// import, and export tables that fail to parse.
+    fn audit(&self) -> Vec<Warning> {
+        let len = self.data.len() as u64;
+        let mut out = Vec::new();
+        let past_end = |offset: u64, size: u64| offset.checked_add(size).map_or(true, |e| e > len);
+        for s in self.file.sections() {
+            if let Some((offset, size)) = s.file_range() {
+                if past_end(offset, size) {
+                    let name = s.name().unwrap_or("<unnamed>");
+                    out.push(
+                        Warning::new(
+                            ErrorKind::TruncatedStructure,
+                            format!("section {} extends past end of file", name),
+                        )
+                        .at(offset),
+                    );
+                }
+            }
+        }
+        for s in self.file.segments() {
+            let (offset, size) = s.file_range();
+            if size != 0 && past_end(offset, size) {
+                out.push(
+                    Warning::new(
+                        ErrorKind::TruncatedStructure,
+                        format!("segment at {:#x} extends past end of file", s.address()),
+                    )
+                    .at(offset),
+                );
+            }
+        }
+        let bad_names = self
+            .file
+            .symbols()
+            .chain(self.file.dynamic_symbols())
+            .filter(|sym| sym.name().is_err())
+            .count();
+        if bad_names > 0 {
+            out.push(Warning::new(
+                ErrorKind::InvalidInput,
+                format!("{} symbols with unreadable names skipped", bad_names),
+            ));
+        }
+        if let Err(e) = self.file.imports() {
+            out.push(Warning::new(
+                ErrorKind::InvalidInput,
+                format!("import table: {}", e),
+            ));
+        }
+        if let Err(e) = self.file.exports() {
+            out.push(Warning::new(
+                ErrorKind::InvalidInput,
+                format!("export table: {}", e),
+            ));
+        }
+        out
+    }
+
+    /// Access the underlying `object` file for format-specific queries.
+    pub fn object(&self) -> &object::read::File<'data> {
+        &self.file
+    }
+}
+
+impl BinaryImage for ObjectImage<'_> {
+    fn data(&self) -> &[u8] {
+        self.data
+    }
+
+    fn address_map(&self) -> Cow<'_, AddressMap> {
+        Cow::Borrowed(self.map.get_or_init(|| build_address_map(self)))
+    }
+
+    fn format(&self) -> Format {
+        format_from_object(self.file.format())
+    }
+
+    fn arch(&self) -> Arch {
+        arch_from_object(self.file.architecture())
+    }
+
+    fn bits(&self) -> u8 {
+        if self.file.is_64() {
+            64
+        } else {
+            32
+        }
+    }
+
+    fn endianness(&self) -> Endianness {
+        if self.file.is_little_endian() {
+            Endianness::Little
+        } else {
+            Endianness::Big
+        }
+    }
+
+    fn entry(&self) -> Option<u64> {
+        match self.file.entry() {
+            0 => None,
+            va => Some(va),
+        }
+    }
+
+    fn image_base(&self) -> Option<u64> {
+        match self.file.format() {
+            object::BinaryFormat::Pe => Some(self.file.relative_address_base()),
+            object::BinaryFormat::MachO => self
+                .file
+                .segments()
+                .find(|s| s.name().ok().flatten() == Some("__TEXT"))
+                .map(|s| s.address()),
+            _ => None,
+        }
+    }
+
+    fn sections(&self) -> Vec<ImageSection> {
+        self.file
+            .sections()
+            .map(|s| ImageSection {
+                name: s.name().unwrap_or_default().to_string(),
+                va: s.address(),
+                size: s.size(),
+                file_range: s.file_range(),
+                perms: section_perms(s.kind(), s.flags()),
+            })
+            .collect()
+    }
+
+    fn segments(&self) -> Vec<ImageSegment> {
+        let segs: Vec<ImageSegment> = self
+            .file
+            .segments()
+            .map(|s| {
+                let (file_offset, file_size) = s.file_range();
+                ImageSegment {
+                    name: s.name().ok().flatten().map(str::to_string),
+                    va: s.address(),
+                    mem_size: s.size(),
+                    file_offset,
+                    file_size,
+                    perms: segment_perms(s.flags()),
+                }
+            })
+            .collect();
+        if !segs.is_empty() {
+            return segs;
+        }
+        // Relocatable objects have no segments; treat allocated sections
+        // with file data as their own mappings.
+        self.sections()
+            .into_iter()
+            .filter_map(|s| {
+                let (file_offset, file_size) = s.file_range?;
+                Some(ImageSegment {
+                    name: Some(s.name),
+                    va: s.va,
+                    mem_size: s.size,
+                    file_offset,
+                    file_size,
+                    perms: s.perms,
+                })
+            })
+            .collect()
+    }
+
+    fn symbols(&self) -> Vec<ImageSymbol> {
+        let mut out = Vec::new();
+        let tables = [
+            (self.file.symbols().collect::<Vec<_>>(), false),
+            (self.file.dynamic_symbols().collect::<Vec<_>>(), true),
+        ];
+        for (syms, dynamic) in tables {
+            for sym in syms {
+                if !sym.is_definition() {
+                    continue;
+                }
+                let Ok(name) = sym.name() else { continue };
+                if name.is_empty() {
+                    continue;
+                }
+                out.push(ImageSymbol {
+                    name: name.to_string(),
+                    address: sym.address(),
+                    size: sym.size(),
+                    kind: symbol_kind(sym.kind()),
+                    dynamic,
+                });
+            }
+        }
+        if let Ok(exports) = self.file.exports() {
+            for exp in exports {
+                let name = String::from_utf8_lossy(exp.name()).into_owned();
+                if name.is_empty() || out.iter().any(|s| s.name == name) {
+                    continue;
+                }
+                out.push(ImageSymbol {
+                    name,
+                    address: exp.address(),
+                    size: 0,
+                    kind: SymbolKind::Export,
+                    dynamic: true,
+                });
+            }
+        }
+        out
+    }
+
+    fn imports(&self) -> Vec<ImageImport> {
+        let Ok(imports) = self.file.imports() else {
+            return Vec::new();
+        };
+        imports
+            .iter()
+            .map(|imp| {
+                let lib = String::from_utf8_lossy(imp.library()).into_owned();
+                ImageImport {
+                    library: if lib.is_empty() { None } else { Some(lib) },
+                    name: String::from_utf8_lossy(imp.name()).into_owned(),
+                }
+            })
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// Fixture bytes, or `None` when the sample is missing or is a
+    /// git-lfs pointer that was never fetched.
+    fn read_fixture(path: &str) -> Option<Vec<u8>> {
+        let data = std::fs::read(path).ok()?;
+        (!data.starts_with(b"version https://git-lfs.github.com/spec/")).then_some(data)
+    }
+
+    #[test]
+    fn elf_image_exposes_layout() {
+        let path = "samples/binaries/platforms/linux/amd64/export/rust/hello-rust-release";
+        let Some(data) = read_fixture(path) else {
+            return;
+        };
+        let img = ObjectImage::parse(&data).expect("parse ELF");
+        assert_eq!(img.format(), Format::ELF);
+        assert_eq!(img.arch(), Arch::X86_64);
+        assert_eq!(img.bits(), 64);
+        assert_eq!(img.endianness(), Endianness::Little);
+        assert!(img.sections().iter().any(|s| s.name == ".text"));
+        assert!(img.segments().iter().any(|s| s.perms.has_execute()));
+        assert!(!img.imports().is_empty());
+        let entry = img.entry().expect("entry");
+        assert_eq!(img.read_va(entry, 4).unwrap().len(), 4);
+    }
+
+    #[test]
+    fn address_map_is_built_once() {
+        let path = "samples/binaries/platforms/linux/amd64/export/native/asm/gas/O0/hello-asm-gas-O0-stripped";
+        let Some(data) = read_fixture(path) else {
+            return;
+        };
+        let img = ObjectImage::parse(&data).expect("parse ELF");
+        let first: *const AddressMap = &*img.address_map();
+        assert!(matches!(img.address_map(), Cow::Borrowed(m) if std::ptr::eq(m, first)));
+    }
+
+    #[test]
+    fn partial_parse_flags_section_past_eof() {
+        let path = "samples/binaries/platforms/linux/amd64/export/native/asm/gas/O0/hello-asm-gas-O0-stripped";
+        let Some(mut data) = read_fixture(path) else {
+            return;
+        };
+        assert!(ObjectImage::parse_partial(&data).unwrap().is_complete());
+        // Point the first real section's sh_offset past the end of the file.
+        let shoff = u64::from_le_bytes(data[0x28..0x30].try_into().unwrap()) as usize;
+        let entsize = u16::from_le_bytes(data[0x3a..0x3c].try_into().unwrap()) as usize;
+        let field = shoff + entsize + 0x18;
+        let eof = data.len() as u64;
+        data[field..field + 8].copy_from_slice(&eof.to_le_bytes());
+        let partial = ObjectImage::parse_partial(&data).expect("header still parses");
+        assert!(partial
+            .warnings
+            .iter()
+            .any(|w| w.kind == ErrorKind::TruncatedStructure && w.offset == Some(eof)));
+        assert!(!partial.value.sections().is_empty());
+    }
+
+    #[test]
+    fn pe_image_has_image_base_and_named_imports() {
+        let path =
+            "samples/binaries/platforms/windows/i386/export/windows/i686/O3/hello-c-mingw32-O3.exe";
+        let Some(data) = read_fixture(path) else {
+            return;
+        };
+        let img = ObjectImage::parse(&data).expect("parse PE");
+        assert_eq!(img.format(), Format::PE);
+        assert_eq!(img.arch(), Arch::X86);
+        assert!(img.image_base().is_some());
+        assert!(img.imports().iter().any(|i| i.library.is_some()));
+        assert!(img.code_sections().iter().any(|s| s.name == ".text"));
+    }
+}
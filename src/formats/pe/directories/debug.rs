@@ -7,9 +7,41 @@ use crate::formats::pe::types::{DataDirectory, DebugEntry, PeError, Result};
 use crate::formats::pe::utils::ReadExt;
 
 const IMAGE_DEBUG_TYPE_CODEVIEW: u32 = 2;
+const IMAGE_DEBUG_TYPE_POGO: u32 = 13;
+const IMAGE_DEBUG_TYPE_REPRO: u32 = 16;
 const IMAGE_DEBUG_DIRECTORY_ENTRY_SIZE: usize = 28;
 const CODEVIEW_RSDS_SIGNATURE: &[u8; 4] = b"RSDS";
 
+/// Upper bound on POGO section records read from one entry.
+const MAX_POGO_ENTRIES: usize = 4096;
+
+/// Name of an `IMAGE_DEBUG_TYPE_*` value, or `"unknown"`.
+pub fn debug_type_name(debug_type: u32) -> &'static str {
+    match debug_type {
+        0 => "unknown",
+        1 => "coff",
+        2 => "codeview",
+        3 => "fpo",
+        4 => "misc",
+        5 => "exception",
+        6 => "fixup",
+        7 => "omap_to_src",
+        8 => "omap_from_src",
+        9 => "borland",
+        10 => "reserved10",
+        11 => "clsid",
+        12 => "vc_feature",
+        13 => "pogo",
+        14 => "iltcg",
+        15 => "mpx",
+        16 => "repro",
+        17 => "embedded_portable_pdb",
+        19 => "pdb_checksum",
+        20 => "ex_dllcharacteristics",
+        _ => "unknown",
+    }
+}
+
 /// CodeView RSDS record from a PE debug directory.
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct CodeViewRsds {
@@ -52,6 +84,31 @@ impl CodeViewRsds {
     }
 }
 
+/// One section contribution recorded by profile-guided/LTCG builds.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct PogoEntry {
+    pub rva: u32,
+    pub size: u32,
+    /// Section or COFF group name, e.g. `.text$mn`.
+    pub name: String,
+}
+
+/// POGO debug record: the optimisation mode and the linker's section map.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct PogoInfo {
+    /// Record signature: `LTCG`, `PGI`, `PGO`, `PGU`, ...
+    pub signature: String,
+    pub entries: Vec<PogoEntry>,
+}
+
+/// Repro (deterministic build) debug record.
+#[derive(Debug, Clone, PartialEq, Eq, Default)]
+pub struct ReproInfo {
+    /// Build hash that replaces timestamps; empty for the legacy form that
+    /// only marks the image as deterministic.
+    pub hash: Vec<u8>,
+}
+
 /// Parsed PE debug-directory summary.
 #[derive(Debug, Clone, Default)]
 pub struct DebugDirectory {
@@ -59,6 +116,12 @@ pub struct DebugDirectory {
     pub entries: Vec<DebugEntry>,
     /// First CodeView RSDS record, when present.
     pub codeview: Option<CodeViewRsds>,
+    /// File offset of the CodeView PDB path string.
+    pub pdb_path_offset: Option<usize>,
+    /// First POGO record, when present.
+    pub pogo: Option<PogoInfo>,
+    /// Repro record, when the image was built deterministically.
+    pub repro: Option<ReproInfo>,
     /// Non-fatal parse warnings.
     pub warnings: Vec<&'static str>,
 }
@@ -90,11 +153,23 @@ pub fn parse_debug_directory(
 
         if directory.codeview.is_none() && entry.debug_type == IMAGE_DEBUG_TYPE_CODEVIEW {
             match parse_codeview_rsds(data, sections, &entry) {
-                Ok(Some(rsds)) => directory.codeview = Some(rsds),
+                Ok(Some(rsds)) => {
+                    directory.pdb_path_offset = entry_data_offset(sections, &entry).map(|o| o + 24);
+                    directory.codeview = Some(rsds);
+                }
                 Ok(None) => directory.warnings.push("missing_codeview_rsds"),
                 Err(_) => directory.warnings.push("malformed_codeview_rsds"),
             }
         }
+        if directory.pogo.is_none() && entry.debug_type == IMAGE_DEBUG_TYPE_POGO {
+            match entry_data(data, sections, &entry).and_then(parse_pogo) {
+                Some(pogo) => directory.pogo = Some(pogo),
+                None => directory.warnings.push("malformed_pogo"),
+            }
+        }
+        if entry.debug_type == IMAGE_DEBUG_TYPE_REPRO {
+            directory.repro = Some(parse_repro(entry_data(data, sections, &entry)));
+        }
 
         directory.entries.push(entry);
     }
@@ -115,6 +190,60 @@ fn parse_debug_entry(data: &[u8], offset: usize) -> Option<DebugEntry> {
     })
 }
 
+/// File offset of an entry's data: `PointerToRawData`, else its RVA.
+fn entry_data_offset(sections: &SectionTable, entry: &DebugEntry) -> Option<usize> {
+    if entry.pointer_to_raw_data != 0 {
+        Some(entry.pointer_to_raw_data as usize)
+    } else {
+        sections.rva_to_offset(entry.address_of_raw_data)
+    }
+}
+
+fn entry_data<'a>(data: &'a [u8], sections: &SectionTable, entry: &DebugEntry) -> Option<&'a [u8]> {
+    let offset = entry_data_offset(sections, entry)?;
+    data.read_slice_at(offset, entry.size_of_data as usize)
+}
+
+fn parse_pogo(record: &[u8]) -> Option<PogoInfo> {
+    let sig = record.get(0..4)?;
+    // Stored as a little-endian FOURCC ("LTCG" reads back as "GCTL").
+    let signature: String = sig
+        .iter()
+        .rev()
+        .filter(|&&b| b != 0)
+        .map(|&b| b as char)
+        .collect();
+    if signature.is_empty() || !signature.bytes().all(|b| b.is_ascii_alphanumeric()) {
+        return None;
+    }
+    let mut entries = Vec::new();
+    let mut off = 4;
+    while off + 8 < record.len() && entries.len() < MAX_POGO_ENTRIES {
+        let rva = record.read_u32_le_at(off)?;
+        let size = record.read_u32_le_at(off + 4)?;
+        let name_start = off + 8;
+        let Some(len) = record[name_start..].iter().position(|&b| b == 0) else {
+            break;
+        };
+        let name = String::from_utf8_lossy(&record[name_start..name_start + len]).into_owned();
+        entries.push(PogoEntry { rva, size, name });
+        // Names are NUL-terminated and padded to a 4-byte boundary.
+        off = (name_start + len + 1 + 3) & !3;
+    }
+    Some(PogoInfo { signature, entries })
+}
+
+fn parse_repro(record: Option<&[u8]>) -> ReproInfo {
+    let hash = record
+        .and_then(|r| {
+            let len = r.read_u32_le_at(0)? as usize;
+            r.read_slice_at(4, len)
+        })
+        .map(<[u8]>::to_vec)
+        .unwrap_or_default();
+    ReproInfo { hash }
+}
+
 fn parse_codeview_rsds(
     data: &[u8],
     sections: &SectionTable,
@@ -197,6 +326,7 @@ mod tests {
     use std::fs;
     use std::path::{Path, PathBuf};
 
+    use super::{parse_pogo, parse_repro};
     use crate::formats::pe::PeParser;
 
     fn fixture(name: &str) -> Option<PathBuf> {
@@ -228,4 +358,29 @@ mod tests {
         assert_eq!(rsds.age, 1);
         assert_eq!(rsds.guid_age_key(), "CF32DE2E4A334C7C06FB63FCB6FAFB5C1");
     }
+
+    #[test]
+    fn parses_pogo_section_map_and_repro_hash() {
+        let mut pogo = b"GCTL".to_vec();
+        for (rva, size, name) in [(0x1000u32, 0x20u32, ".text$mn"), (0x2000, 8, ".rdata")] {
+            pogo.extend_from_slice(&rva.to_le_bytes());
+            pogo.extend_from_slice(&size.to_le_bytes());
+            pogo.extend_from_slice(name.as_bytes());
+            pogo.push(0);
+            while pogo.len() % 4 != 0 {
+                pogo.push(0);
+            }
+        }
+        let info = parse_pogo(&pogo).expect("POGO record");
+        assert_eq!(info.signature, "LTCG");
+        assert_eq!(info.entries.len(), 2);
+        assert_eq!(info.entries[0].name, ".text$mn");
+        assert_eq!(info.entries[1].rva, 0x2000);
+        assert!(parse_pogo(b"\0\0\0\0").is_none());
+
+        let mut repro = 4u32.to_le_bytes().to_vec();
+        repro.extend_from_slice(&[0xde, 0xad, 0xbe, 0xef]);
+        assert_eq!(parse_repro(Some(&repro)).hash, vec![0xde, 0xad, 0xbe, 0xef]);
+        assert!(parse_repro(None).hash.is_empty());
+    }
 }
@@ -6,7 +6,10 @@ pub mod import;
 pub mod resource;
 pub mod tls;
 
-pub use debug::{parse_debug_directory, CodeViewRsds, DebugDirectory};
+pub use debug::{
+    debug_type_name, parse_debug_directory, CodeViewRsds, DebugDirectory, PogoEntry, PogoInfo,
+    ReproInfo,
+};
 pub use export::{parse_exports, ExportTable};
 pub use import::{parse_imports, ImportTable};
 pub use resource::parse_resources;
@@ -0,0 +1,607 @@
+//! WebAssembly (Wasm) module parser.
+//!
+//! Parses the binary module layout (section headers, import/export tables,
+//! start function, code bodies, and the `name` custom section) so Wasm
+//! modules can be analysed through the common `BinaryImage` interface.
+//! Wasm has no virtual address space, so addresses reported here are file
+//! offsets: every section is identity-mapped and function addresses are the
+//! offsets of their code bodies.
+//!
+//! It is a read-only, bounds-checked parser; instruction streams are not
+//! decoded.
+//!
+//! Layout reference: <https://webassembly.github.io/spec/core/binary/modules.html>.
+
+use crate::core::address_map::AddressMap;
+use crate::core::binary::{Arch, Endianness, Format};
+use crate::core::image::{
+    build_address_map, BinaryImage, ImageImport, ImageSection, ImageSegment, ImageSymbol,
+};
+use crate::core::segment::Perms;
+use crate::core::symbol::SymbolKind;
+use crate::error::{GlaurungError, Partial, Warning};
+use std::borrow::Cow;
+use std::collections::HashMap;
+use std::fmt;
+use std::sync::OnceLock;
+
+/// Module magic: `\0asm`.
+pub const WASM_MAGIC: &[u8; 4] = b"\0asm";
+/// The only binary format version defined by the spec.
+pub const WASM_VERSION: u32 = 1;
+
+/// Wasm parsing errors.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum WasmError {
+    /// Magic bytes were not `\0asm`.
+    InvalidMagic,
+    /// Version field was not 1.
+    UnsupportedVersion(u32),
+    /// A structure ran past the end of the file.
+    Truncated { offset: usize },
+    /// A LEB128 value was malformed or overlong.
+    InvalidLeb { offset: usize },
+    /// A name was not valid UTF-8.
+    InvalidName { offset: usize },
+    /// An unknown import/export descriptor kind.
+    UnknownKind { offset: usize, kind: u8 },
+}
+
+impl fmt::Display for WasmError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Self::InvalidMagic => write!(f, "invalid Wasm magic"),
+            Self::UnsupportedVersion(v) => write!(f, "unsupported Wasm version {}", v),
+            Self::Truncated { offset } => write!(f, "truncated at {:#x}", offset),
+            Self::InvalidLeb { offset } => write!(f, "malformed LEB128 at {:#x}", offset),
+            Self::InvalidName { offset } => write!(f, "invalid UTF-8 name at {:#x}", offset),
+            Self::UnknownKind { offset, kind } => {
+                write!(f, "unknown descriptor kind {:#x} at {:#x}", kind, offset)
+            }
+        }
+    }
+}
+
+impl std::error::Error for WasmError {}
+
+impl WasmError {
+    fn offset(&self) -> Option<u64> {
+        match self {
+            Self::Truncated { offset }
+            | Self::InvalidLeb { offset }
+            | Self::InvalidName { offset }
+            | Self::UnknownKind { offset, .. } => Some(*offset as u64),
+            _ => None,
+        }
+    }
+}
+
+impl From<WasmError> for GlaurungError {
+    fn from(err: WasmError) -> Self {
+        match err {
+            WasmError::InvalidMagic | WasmError::UnsupportedVersion(_) => {
+                GlaurungError::UnsupportedFormat(err.to_string())
+            }
+            WasmError::Truncated { offset } => GlaurungError::TruncatedStructure {
+                structure: "Wasm module".to_string(),
+                offset: offset as u64,
+            },
+            _ => GlaurungError::ParseError {
+                offset: err.offset().unwrap_or(0),
+                message: err.to_string(),
+            },
+        }
+    }
+}
+
+impl From<&WasmError> for Warning {
+    fn from(err: &WasmError) -> Self {
+        Warning::from(&GlaurungError::from(err.clone()))
+    }
+}
+
+pub type Result<T> = std::result::Result<T, WasmError>;
+
+/// Kind of an imported or exported entity.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ExternalKind {
+    Function,
+    Table,
+    Memory,
+    Global,
+    Tag,
+}
+
+impl ExternalKind {
+    fn from_byte(b: u8, offset: usize) -> Result<Self> {
+        Ok(match b {
+            0 => Self::Function,
+            1 => Self::Table,
+            2 => Self::Memory,
+            3 => Self::Global,
+            4 => Self::Tag,
+            kind => return Err(WasmError::UnknownKind { offset, kind }),
+        })
+    }
+}
+
+/// One section header.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WasmSection {
+    /// Section id (0 = custom)
+    pub id: u8,
+    /// Standard section name, or the custom section's own name
+    pub name: String,
+    /// File offset of the section payload
+    pub offset: usize,
+    /// Payload size in bytes
+    pub size: usize,
+}
+
+/// An import entry.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WasmImport {
+    pub module: String,
+    pub field: String,
+    pub kind: ExternalKind,
+}
+
+/// An export entry.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WasmExport {
+    pub name: String,
+    pub kind: ExternalKind,
+    pub index: u32,
+}
+
+/// A defined function's code body.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WasmFunction {
+    /// Index in the function index space (imports first)
+    pub index: u32,
+    /// File offset of the body (after its size prefix)
+    pub offset: usize,
+    /// Body size in bytes
+    pub size: usize,
+}
+
+/// A parsed Wasm module.
+pub struct WasmModule<'a> {
+    data: &'a [u8],
+    sections: Vec<WasmSection>,
+    imports: Vec<WasmImport>,
+    exports: Vec<WasmExport>,
+    functions: Vec<WasmFunction>,
+    start: Option<u32>,
+    names: HashMap<u32, String>,
+    map: OnceLock<AddressMap>,
+}
+
+/// Bounds-checked cursor over module bytes.
+struct Reader<'a> {
+    data: &'a [u8],
+    pos: usize,
+    end: usize,
+}
+
+impl<'a> Reader<'a> {
+    fn new(data: &'a [u8], pos: usize, end: usize) -> Self {
+        Self {
+            data,
+            pos,
+            end: end.min(data.len()),
+        }
+    }
+
+    fn done(&self) -> bool {
+        self.pos >= self.end
+    }
+
+    fn u8(&mut self) -> Result<u8> {
+        if self.pos >= self.end {
+            return Err(WasmError::Truncated { offset: self.pos });
+        }
+        let b = self.data[self.pos];
+        self.pos += 1;
+        Ok(b)
+    }
+
+    fn uleb(&mut self) -> Result<u64> {
+        let start = self.pos;
+        let mut result = 0u64;
+        let mut shift = 0u32;
+        loop {
+            let b = self.u8()?;
+            if shift >= 64 {
+                return Err(WasmError::InvalidLeb { offset: start });
+            }
+            result |= u64::from(b & 0x7f) << shift;
+            if b & 0x80 == 0 {
+                return Ok(result);
+            }
+            shift += 7;
+        }
+    }
+
+    fn u32(&mut self) -> Result<u32> {
+        let start = self.pos;
+        u32::try_from(self.uleb()?).map_err(|_| WasmError::InvalidLeb { offset: start })
+    }
+
+    fn skip(&mut self, n: usize) -> Result<()> {
+        let end = self
+            .pos
+            .checked_add(n)
+            .filter(|e| *e <= self.end)
+            .ok_or(WasmError::Truncated { offset: self.pos })?;
+        self.pos = end;
+        Ok(())
+    }
+
+    fn name(&mut self) -> Result<String> {
+        let len = self.u32()? as usize;
+        let start = self.pos;
+        self.skip(len)?;
+        std::str::from_utf8(&self.data[start..start + len])
+            .map(str::to_string)
+            .map_err(|_| WasmError::InvalidName { offset: start })
+    }
+
+    fn limits(&mut self) -> Result<()> {
+        let flags = self.u8()?;
+        self.uleb()?;
+        if flags & 1 != 0 {
+            self.uleb()?;
+        }
+        Ok(())
+    }
+}
+
+fn standard_section_name(id: u8) -> &'static str {
+    match id {
+        1 => "type",
+        2 => "import",
+        3 => "function",
+        4 => "table",
+        5 => "memory",
+        6 => "global",
+        7 => "export",
+        8 => "start",
+        9 => "element",
+        10 => "code",
+        11 => "data",
+        12 => "datacount",
+        13 => "tag",
+        _ => "unknown",
+    }
+}
+
+impl<'a> WasmModule<'a> {
+    /// True if `data` begins with the Wasm magic and version 1.
+    pub fn is_wasm(data: &[u8]) -> bool {
+        data.len() >= 8
+            && &data[0..4] == WASM_MAGIC
+            && u32::from_le_bytes([data[4], data[5], data[6], data[7]]) == WASM_VERSION
+    }
+
+    /// Parse a module, walking every section header and the tables used by
+    /// `BinaryImage`. Any malformed section fails the whole parse.
+    pub fn parse(data: &'a [u8]) -> Result<Self> {
+        Self::parse_impl(data, true).map(|(m, _)| m)
+    }
+
+    /// Parse a module, keeping everything recovered before a malformed or
+    /// truncated section. Only a bad header is fatal; later problems are
+    /// returned as warnings.
+    pub fn parse_partial(data: &'a [u8]) -> Result<Partial<Self>> {
+        let (module, errors) = Self::parse_impl(data, false)?;
+        let warnings = errors.iter().map(Warning::from).collect();
+        Ok(Partial::with_warnings(module, warnings))
+    }
+
+    fn parse_impl(data: &'a [u8], strict: bool) -> Result<(Self, Vec<WasmError>)> {
+        if data.len() < 8 {
+            return Err(WasmError::Truncated { offset: data.len() });
+        }
+        if &data[0..4] != WASM_MAGIC {
+            return Err(WasmError::InvalidMagic);
+        }
+        let version = u32::from_le_bytes([data[4], data[5], data[6], data[7]]);
+        if version != WASM_VERSION {
+            return Err(WasmError::UnsupportedVersion(version));
+        }
+
+        let mut module = Self {
+            data,
+            sections: Vec::new(),
+            imports: Vec::new(),
+            exports: Vec::new(),
+            functions: Vec::new(),
+            start: None,
+            names: HashMap::new(),
+            map: OnceLock::new(),
+        };
+
+        let mut errors = Vec::new();
+        let mut r = Reader::new(data, 8, data.len());
+        while !r.done() {
+            // A bad section header leaves no way to find the next section.
+            let header = (|| {
+                let id = r.u8()?;
+                let size = r.u32()? as usize;
+                let offset = r.pos;
+                r.skip(size)?;
+                Ok((id, offset, size))
+            })();
+            let (id, offset, size) = match header {
+                Ok(h) => h,
+                Err(e) if !strict => {
+                    errors.push(e);
+                    break;
+                }
+                Err(e) => return Err(e),
+            };
+            let end = offset + size;
+            // A bad table inside a well-delimited section only loses the
+            // entries after the fault.
+            let parsed = (|| {
+                let name = if id == 0 {
+                    Reader::new(data, offset, end).name()?
+                } else {
+                    standard_section_name(id).to_string()
+                };
+                match id {
+                    0 if name == "name" => module.parse_names(offset, end),
+                    2 => module.parse_imports(offset, end)?,
+                    7 => module.parse_exports(offset, end)?,
+                    8 => module.start = Some(Reader::new(data, offset, end).u32()?),
+                    10 => module.parse_code(offset, end)?,
+                    _ => {}
+                }
+                Ok(name)
+            })();
+            let name = match parsed {
+                Ok(name) => name,
+                Err(e) if !strict => {
+                    errors.push(e);
+                    standard_section_name(id).to_string()
+                }
+                Err(e) => return Err(e),
+            };
+            module.sections.push(WasmSection {
+                id,
+                name,
+                offset,
+                size,
+            });
+        }
+        Ok((module, errors))
+    }
+
+    fn parse_imports(&mut self, offset: usize, end: usize) -> Result<()> {
+        let mut r = Reader::new(self.data, offset, end);
+        for _ in 0..r.u32()? {
+            let module = r.name()?;
+            let field = r.name()?;
+            let kind_off = r.pos;
+            let kind = ExternalKind::from_byte(r.u8()?, kind_off)?;
+            match kind {
+                ExternalKind::Function => {
+                    r.u32()?;
+                }
+                ExternalKind::Table => {
+                    r.u8()?;
+                    r.limits()?;
+                }
+                ExternalKind::Memory => r.limits()?,
+                ExternalKind::Global => {
+                    r.u8()?;
+                    r.u8()?;
+                }
+                ExternalKind::Tag => {
+                    r.u8()?;
+                    r.u32()?;
+                }
+            }
+            self.imports.push(WasmImport {
+                module,
+                field,
+                kind,
+            });
+        }
+        Ok(())
+    }
+
+    fn parse_exports(&mut self, offset: usize, end: usize) -> Result<()> {
+        let mut r = Reader::new(self.data, offset, end);
+        for _ in 0..r.u32()? {
+            let name = r.name()?;
+            let kind_off = r.pos;
+            let kind = ExternalKind::from_byte(r.u8()?, kind_off)?;
+            let index = r.u32()?;
+            self.exports.push(WasmExport { name, kind, index });
+        }
+        Ok(())
+    }
+
+    fn parse_code(&mut self, offset: usize, end: usize) -> Result<()> {
+        let base = self.imported_function_count();
+        let mut r = Reader::new(self.data, offset, end);
+        for i in 0..r.u32()? {
+            let size = r.u32()? as usize;
+            let body = r.pos;
+            r.skip(size)?;
+            self.functions.push(WasmFunction {
+                index: base + i,
+                offset: body,
+                size,
+            });
+        }
+        Ok(())
+    }
+
+    /// The `name` section is advisory; a malformed one is ignored rather
+    /// than failing the whole module.
+    fn parse_names(&mut self, offset: usize, end: usize) {
+        let mut r = Reader::new(self.data, offset, end);
+        let parsed: Result<()> = (|| {
+            r.name()?;
+            while !r.done() {
+                let sub = r.u8()?;
+                let size = r.u32()? as usize;
+                let sub_start = r.pos;
+                r.skip(size)?;
+                if sub != 1 {
+                    continue;
+                }
+                let mut s = Reader::new(self.data, sub_start, sub_start + size);
+                for _ in 0..s.u32()? {
+                    let idx = s.u32()?;
+                    let name = s.name()?;
+                    self.names.insert(idx, name);
+                }
+            }
+            Ok(())
+        })();
+        let _ = parsed;
+    }
+
+    /// Number of imported functions (they occupy the low function indices).
+    pub fn imported_function_count(&self) -> u32 {
+        self.imports
+            .iter()
+            .filter(|i| i.kind == ExternalKind::Function)
+            .count() as u32
+    }
+
+    pub fn sections(&self) -> &[WasmSection] {
+        &self.sections
+    }
+
+    pub fn imports(&self) -> &[WasmImport] {
+        &self.imports
+    }
+
+    pub fn exports(&self) -> &[WasmExport] {
+        &self.exports
+    }
+
+    pub fn functions(&self) -> &[WasmFunction] {
+        &self.functions
+    }
+
+    /// Index of the start function, if declared.
+    pub fn start_function(&self) -> Option<u32> {
+        self.start
+    }
+
+    /// Best available name for a function index (name section, then export).
+    pub fn function_name(&self, index: u32) -> Option<&str> {
+        self.names.get(&index).map(String::as_str).or_else(|| {
+            self.exports
+                .iter()
+                .find(|e| e.kind == ExternalKind::Function && e.index == index)
+                .map(|e| e.name.as_str())
+        })
+    }
+
+    fn function(&self, index: u32) -> Option<&WasmFunction> {
+        self.functions.iter().find(|f| f.index == index)
+    }
+}
+
+impl BinaryImage for WasmModule<'_> {
+    fn data(&self) -> &[u8] {
+        self.data
+    }
+
+    fn address_map(&self) -> Cow<'_, AddressMap> {
+        Cow::Borrowed(self.map.get_or_init(|| build_address_map(self)))
+    }
+
+    fn format(&self) -> Format {
+        Format::Wasm
+    }
+
+    fn arch(&self) -> Arch {
+        Arch::Unknown
+    }
+
+    fn bits(&self) -> u8 {
+        32
+    }
+
+    fn endianness(&self) -> Endianness {
+        Endianness::Little
+    }
+
+    fn entry(&self) -> Option<u64> {
+        let index = self.start.or_else(|| {
+            self.exports
+                .iter()
+                .find(|e| e.kind == ExternalKind::Function && e.name == "_start")
+                .map(|e| e.index)
+        })?;
+        self.function(index).map(|f| f.offset as u64)
+    }
+
+    fn sections(&self) -> Vec<ImageSection> {
+        self.sections
+            .iter()
+            .map(|s| ImageSection {
+                name: s.name.clone(),
+                va: s.offset as u64,
+                size: s.size as u64,
+                file_range: Some((s.offset as u64, s.size as u64)),
+                perms: Perms::new(true, false, s.id == 10),
+            })
+            .collect()
+    }
+
+    fn segments(&self) -> Vec<ImageSegment> {
+        BinaryImage::sections(self)
+            .into_iter()
+            .map(|s| ImageSegment {
+                name: Some(s.name),
+                va: s.va,
+                mem_size: s.size,
+                file_offset: s.va,
+                file_size: s.size,
+                perms: s.perms,
+            })
+            .collect()
+    }
+
+    fn symbols(&self) -> Vec<ImageSymbol> {
+        self.functions
+            .iter()
+            .filter_map(|f| {
+                let name = self.function_name(f.index)?;
+                let dynamic = self
+                    .exports
+                    .iter()
+                    .any(|e| e.kind == ExternalKind::Function && e.index == f.index);
+                Some(ImageSymbol {
+                    name: name.to_string(),
+                    address: f.offset as u64,
+                    size: f.size as u64,
+                    kind: SymbolKind::Function,
+                    dynamic,
+                })
+            })
+            .collect()
+    }
+
+    fn imports(&self) -> Vec<ImageImport> {
+        self.imports
+            .iter()
+            .map(|i| ImageImport {
+                library: Some(i.module.clone()),
+                name: i.field.clone(),
+            })
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests;
@@ -0,0 +1,129 @@
+use super::*;
+
+fn section(id: u8, payload: &[u8]) -> Vec<u8> {
+    let mut out = vec![id, payload.len() as u8];
+    out.extend_from_slice(payload);
+    out
+}
+
+fn name(s: &str) -> Vec<u8> {
+    let mut out = vec![s.len() as u8];
+    out.extend_from_slice(s.as_bytes());
+    out
+}
+
+/// (import "env" "log" (func)) plus two defined functions: index 1 exported
+/// as `_start` and named `main` in the name section, index 2 exported as
+/// `helper`.
+fn sample_module() -> Vec<u8> {
+    let mut m = Vec::new();
+    m.extend_from_slice(WASM_MAGIC);
+    m.extend_from_slice(&WASM_VERSION.to_le_bytes());
+    m.extend(section(1, &[0x01, 0x60, 0x00, 0x00]));
+    let mut imports = vec![0x01];
+    imports.extend(name("env"));
+    imports.extend(name("log"));
+    imports.extend([0x00, 0x00]);
+    m.extend(section(2, &imports));
+    m.extend(section(3, &[0x02, 0x00, 0x00]));
+    let mut exports = vec![0x02];
+    exports.extend(name("_start"));
+    exports.extend([0x00, 0x01]);
+    exports.extend(name("helper"));
+    exports.extend([0x00, 0x02]);
+    m.extend(section(7, &exports));
+    // Two bodies: no locals, `call 0; end` and `end`.
+    m.extend(section(
+        10,
+        &[0x02, 0x04, 0x00, 0x10, 0x00, 0x0b, 0x02, 0x00, 0x0b],
+    ));
+    let mut names = name("name");
+    let mut func_names = vec![0x01, 0x01];
+    func_names.extend(name("main"));
+    names.push(0x01);
+    names.push(func_names.len() as u8);
+    names.extend(func_names);
+    m.extend(section(0, &names));
+    m
+}
+
+#[test]
+fn detects_magic_and_version() {
+    let m = sample_module();
+    assert!(WasmModule::is_wasm(&m));
+    assert!(!WasmModule::is_wasm(b"\x7fELF\x02\x01\x01\x00"));
+    let mut bad = m.clone();
+    bad[4] = 2;
+    assert_eq!(
+        WasmModule::parse(&bad).err(),
+        Some(WasmError::UnsupportedVersion(2))
+    );
+}
+
+#[test]
+fn parses_tables() {
+    let data = sample_module();
+    let m = WasmModule::parse(&data).expect("parse");
+    let names: Vec<&str> = m.sections().iter().map(|s| s.name.as_str()).collect();
+    assert_eq!(
+        names,
+        ["type", "import", "function", "export", "code", "name"]
+    );
+    assert_eq!(m.imported_function_count(), 1);
+    assert_eq!(m.exports().len(), 2);
+    assert_eq!(m.functions().len(), 2);
+    assert_eq!(m.functions()[0].index, 1);
+    assert_eq!(m.function_name(1), Some("main"));
+    assert_eq!(m.function_name(2), Some("helper"));
+}
+
+#[test]
+fn implements_binary_image() {
+    let data = sample_module();
+    let m = WasmModule::parse(&data).expect("parse");
+    assert_eq!(m.format(), Format::Wasm);
+    assert_eq!(m.arch(), Arch::Unknown);
+
+    let imports = BinaryImage::imports(&m);
+    assert_eq!(imports.len(), 1);
+    assert_eq!(imports[0].library.as_deref(), Some("env"));
+    assert_eq!(imports[0].name, "log");
+
+    let syms = m.symbols();
+    assert_eq!(syms.len(), 2);
+    assert!(syms
+        .iter()
+        .all(|s| s.dynamic && s.kind == SymbolKind::Function));
+
+    // `_start` resolves to the first body: `call 0; end`.
+    let entry = m.entry().expect("entry");
+    assert_eq!(m.read_va(entry, 4).unwrap(), &[0x00, 0x10, 0x00, 0x0b]);
+    let code = m.code_sections();
+    assert_eq!(code.len(), 1);
+    assert_eq!(code[0].name, "code");
+}
+
+#[test]
+fn truncated_section_is_an_error() {
+    let mut data = sample_module();
+    data.truncate(data.len() - 3);
+    assert!(matches!(
+        WasmModule::parse(&data),
+        Err(WasmError::Truncated { .. })
+    ));
+}
+
+#[test]
+fn partial_parse_keeps_earlier_sections() {
+    let mut data = sample_module();
+    data.truncate(data.len() - 3);
+    let partial = WasmModule::parse_partial(&data).expect("header is valid");
+    assert!(!partial.is_complete());
+    assert_eq!(
+        partial.warnings[0].kind,
+        crate::error::ErrorKind::TruncatedStructure
+    );
+    let m = partial.value;
+    assert_eq!(m.functions().len(), 2);
+    assert_eq!(m.function_name(1), Some("_start"));
+}
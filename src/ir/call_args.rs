@@ -86,11 +86,16 @@ fn slot_of(arch: CallConv, name: &str) -> Option<usize> {
         .position(|names| names.contains(&name))
 }
 
-fn incoming_arg_expr(arch: CallConv, slot: usize) -> Option<Expr> {
+/// Full-width register carrying argument `slot`, or `None` past the last
+/// register-passed slot.
+pub fn arg_register(arch: CallConv, slot: usize) -> Option<&'static str> {
     arg_slots(arch)
         .get(slot)
-        .and_then(|names| names.first())
-        .map(|name| Expr::Reg(VReg::Phys((*name).to_string())))
+        .and_then(|names| names.first().copied())
+}
+
+fn incoming_arg_expr(arch: CallConv, slot: usize) -> Option<Expr> {
+    arg_register(arch, slot).map(|name| Expr::Reg(VReg::Phys(name.to_string())))
 }
 
 /// Run argument reconstruction on `f` using the given calling convention.
@@ -8,7 +8,8 @@
 //! other architectures return `None` so callers can handle the unsupported
 //! case explicitly rather than receive a silently-incomplete result.
 
-use crate::analysis::entry::va_to_file_offset;
+use crate::analysis::entry::build_address_map;
+use crate::core::address_map::AddressMap;
 use crate::core::binary::Arch;
 use crate::core::function::Function;
 use crate::ir::types::*;
@@ -43,6 +44,20 @@ pub fn lift_function_from_bytes(data: &[u8], func: &Function, arch: Arch) -> Opt
     if !supports_arch(arch) {
         return None;
     }
+    lift_function_with_map(data, func, arch, &build_address_map(data)?)
+}
+
+/// [`lift_function_from_bytes`] against a prebuilt address map, for callers
+/// lifting many functions from one image.
+pub fn lift_function_with_map(
+    data: &[u8],
+    func: &Function,
+    arch: Arch,
+    map: &AddressMap,
+) -> Option<LlirFunction> {
+    if !supports_arch(arch) {
+        return None;
+    }
 
     let mut blocks: Vec<LlirBlock> = Vec::with_capacity(func.basic_blocks.len());
 
@@ -52,9 +67,10 @@ pub fn lift_function_from_bytes(data: &[u8], func: &Function, arch: Arch) -> Opt
         if end <= start {
             continue;
         }
-        let Some(foff) = va_to_file_offset(data, start) else {
+        let Ok(foff) = map.va_to_file_offset(start) else {
             continue;
         };
+        let foff = foff as usize;
         let size = (end - start) as usize;
         let end_off = foff.saturating_add(size).min(data.len());
         if foff >= end_off {
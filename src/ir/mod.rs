@@ -43,6 +43,7 @@ pub mod ssa;
 pub mod stack_idiom;
 pub mod stack_locals;
 pub mod strings_fold;
+pub mod struct_recover;
 pub mod structure;
 pub mod types;
 pub mod types_recover;
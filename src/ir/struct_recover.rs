@@ -0,0 +1,1168 @@
+//! Composite type recovery from field-offset access patterns.
+//!
+//! Pointers into the same object are followed through every lifted
+//! function. Incoming argument registers and allocator return values each
+//! start a *root*; register copies, constant adds, and frame spills move a
+//! root (with a running byte delta) between locations; every `[root + k]`
+//! load or store records a field at offset `k` with the access width. A
+//! pointer-sized load from a field starts a child root for the pointee, so
+//! `a->next->len` describes two structs linked by a pointer field.
+//!
+//! Roots then merge across functions: an object passed to a direct call is
+//! unified with the callee's matching parameter, and two pointees reached
+//! through the same field of one struct are the same type. A constructor
+//! and the methods that use an object therefore contribute to one layout.
+//!
+//! Hints refine the raw shape:
+//!
+//! * allocation sizes (`malloc(0x28)`, `operator new(0x28)`,
+//!   `HeapAlloc(h, f, 0x28)`) fix the struct size and pad its tail;
+//! * a store of a known vtable address point names the struct after the
+//!   most-derived RTTI class installed and types the slot as a vtable;
+//! * a DWARF structure whose members start at every observed offset (and
+//!   whose size matches the allocation, when known) supplies the real name,
+//!   member names, and C types.
+//!
+//! The result is a list of [`RecoveredStruct`]s that render themselves as
+//! C declarations.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+
+use serde::{Deserialize, Serialize};
+
+use crate::core::binary::Arch;
+use crate::debug::dwarf::{DwarfType, DwarfTypeKind};
+use crate::ir::call_args::{arg_register, CallConv};
+use crate::ir::types::{BinOp, CallTarget, LlirFunction, Op, VReg, Value};
+
+/// Offsets at or past this are absolute addresses, not members.
+const MAX_FIELD_OFFSET: i64 = 0x10000;
+
+/// How many Field-origin parents to walk when naming a pointee struct.
+const MAX_NAME_DEPTH: usize = 8;
+
+/// Where an allocator takes the requested size from.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum AllocSize {
+    /// Argument `n` is the size
+    Arg(usize),
+    /// The product of two arguments is the size (`calloc`)
+    Product(usize, usize),
+}
+
+const ALLOCATORS: &[(&str, AllocSize)] = &[
+    ("malloc", AllocSize::Arg(0)),
+    ("xmalloc", AllocSize::Arg(0)),
+    ("g_malloc", AllocSize::Arg(0)),
+    ("g_malloc0", AllocSize::Arg(0)),
+    ("kmalloc", AllocSize::Arg(0)),
+    ("kzalloc", AllocSize::Arg(0)),
+    ("vmalloc", AllocSize::Arg(0)),
+    ("vzalloc", AllocSize::Arg(0)),
+    ("_Znwm", AllocSize::Arg(0)),
+    ("_Znam", AllocSize::Arg(0)),
+    ("_Znwj", AllocSize::Arg(0)),
+    ("_Znaj", AllocSize::Arg(0)),
+    ("??2@YAPEAX_K@Z", AllocSize::Arg(0)),
+    ("??_U@YAPEAX_K@Z", AllocSize::Arg(0)),
+    ("??2@YAPAXI@Z", AllocSize::Arg(0)),
+    ("??_U@YAPAXI@Z", AllocSize::Arg(0)),
+    ("calloc", AllocSize::Product(0, 1)),
+    ("xcalloc", AllocSize::Product(0, 1)),
+    ("kcalloc", AllocSize::Product(0, 1)),
+    ("realloc", AllocSize::Arg(1)),
+    ("LocalAlloc", AllocSize::Arg(1)),
+    ("GlobalAlloc", AllocSize::Arg(1)),
+    ("ExAllocatePool2", AllocSize::Arg(1)),
+    ("ExAllocatePoolWithTag", AllocSize::Arg(1)),
+    ("HeapAlloc", AllocSize::Arg(2)),
+    ("RtlAllocateHeap", AllocSize::Arg(2)),
+];
+
+/// Size convention of an allocator, looked up by symbol or import name.
+/// Decorations added by the stub maps (`malloc@plt`, `__imp_HeapAlloc`,
+/// `kernel32.dll!HeapAlloc`, Mach-O `_malloc`) are ignored.
+pub fn allocator_for(name: &str) -> Option<AllocSize> {
+    let mut name = name.strip_prefix("__imp_").unwrap_or(name);
+    if let Some((_, tail)) = name.rsplit_once('!') {
+        name = tail;
+    }
+    // MSVC-mangled names use '@' themselves; only strip ELF/Mach-O suffixes.
+    if !name.starts_with('?') {
+        name = name.split('@').next().unwrap_or(name);
+    }
+    let lookup = |n: &str| ALLOCATORS.iter().find(|(a, _)| *a == n).map(|(_, s)| *s);
+    lookup(name).or_else(|| name.strip_prefix('_').and_then(lookup))
+}
+
+/// Side information that refines recovered layouts.
+#[derive(Debug, Clone, Default)]
+pub struct StructHints {
+    /// Function names by entry VA, used to name parameter structs
+    pub names: HashMap<u64, String>,
+    /// Allocators by call target (function entry, PLT stub, or IAT slot)
+    pub allocators: HashMap<u64, AllocSize>,
+    /// `(class, inheritance depth)` by vtable address point
+    pub vtables: HashMap<u64, (String, usize)>,
+    /// Debug-info layouts to match recovered structs against
+    pub dwarf: Vec<DwarfType>,
+}
+
+/// One member of a recovered struct.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct RecoveredField {
+    pub offset: u64,
+    pub size: u64,
+    pub name: String,
+    /// C type; function pointers use `ret (*)(args)`, arrays `T[n]`
+    pub c_type: String,
+    /// Loads observed at this offset (zero for DWARF-only members)
+    pub reads: u32,
+    /// Stores observed at this offset (zero for DWARF-only members)
+    pub writes: u32,
+}
+
+/// A struct layout recovered from one group of unified pointers.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct RecoveredStruct {
+    pub name: String,
+    /// Size in bytes: DWARF size, else the larger of the allocation size
+    /// and the furthest access
+    pub size: u64,
+    /// Members in offset order; gaps are padding
+    pub fields: Vec<RecoveredField>,
+    /// Constant size passed to the allocator that produced the object
+    pub alloc_size: Option<u64>,
+    /// Most-derived C++ class whose vtable is installed in the object
+    pub class: Option<String>,
+    /// True when the layout was taken from a matching DWARF structure
+    pub dwarf: bool,
+    /// Entry VAs of the functions that access the struct
+    pub functions: Vec<u64>,
+    pub confidence: f32,
+}
+
+impl RecoveredStruct {
+    /// C declaration with explicit padding and an offset comment per member.
+    pub fn declaration(&self) -> String {
+        let mut out = format!("struct {} {{\n", self.name);
+        let mut at = 0u64;
+        for f in &self.fields {
+            if f.offset > at {
+                out.push_str(&format!("    uint8_t _pad_{:x}[{}];\n", at, f.offset - at));
+            }
+            out.push_str(&format!(
+                "    {}; /* {:#x} */\n",
+                declarator(&f.c_type, &f.name),
+                f.offset
+            ));
+            at = at.max(f.offset + f.size);
+        }
+        if self.size > at {
+            out.push_str(&format!("    uint8_t _pad_{:x}[{}];\n", at, self.size - at));
+        }
+        out.push_str("};");
+        out
+    }
+}
+
+/// `c_type name`, with the name placed inside function-pointer and array
+/// declarators.
+fn declarator(c_type: &str, name: &str) -> String {
+    if let Some((head, tail)) = c_type.split_once("(*)") {
+        return format!("{}(*{}){}", head, name, tail);
+    }
+    if let Some(i) = c_type.find('[') {
+        return format!("{} {}{}", c_type[..i].trim_end(), name, &c_type[i..]);
+    }
+    if c_type.ends_with('*') {
+        format!("{}{}", c_type, name)
+    } else {
+        format!("{} {}", c_type, name)
+    }
+}
+
+/// Recover struct layouts from `funcs`, lifted for `arch` with calling
+/// convention `conv`.
+pub fn recover_structs(
+    funcs: &[LlirFunction],
+    arch: Arch,
+    conv: CallConv,
+    hints: &StructHints,
+) -> Vec<RecoveredStruct> {
+    let mut r = Recoverer::new(arch, conv, hints);
+    for f in funcs {
+        r.function(f);
+    }
+    r.finish()
+}
+
+/// A pointer `delta` bytes into the object tracked by `root`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+struct Ptr {
+    root: usize,
+    delta: i64,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord)]
+enum Origin {
+    Alloc { site: u64 },
+    Arg { func: u64, slot: usize },
+    Field { parent: usize, offset: i64 },
+}
+
+#[derive(Debug, Clone, Default)]
+struct Access {
+    reads: u32,
+    writes: u32,
+    /// Access count per width
+    widths: BTreeMap<u8, u32>,
+    /// Classes whose vtable was stored here
+    vtables: BTreeSet<(usize, String)>,
+}
+
+impl Access {
+    fn merge(&mut self, other: &Access) {
+        self.reads += other.reads;
+        self.writes += other.writes;
+        for (w, n) in &other.widths {
+            *self.widths.entry(*w).or_default() += n;
+        }
+        self.vtables.extend(other.vtables.iter().cloned());
+    }
+
+    /// Most frequent width, the wider one on ties.
+    fn width(&self) -> u8 {
+        self.widths
+            .iter()
+            .max_by_key(|(w, n)| (**n, **w))
+            .map_or(1, |(w, _)| *w)
+    }
+}
+
+#[derive(Debug, Clone)]
+struct Root {
+    origin: Origin,
+    fields: BTreeMap<i64, Access>,
+    alloc_size: Option<u64>,
+    funcs: BTreeSet<u64>,
+    /// Used as an indirect call target
+    called: bool,
+}
+
+/// Register and frame-slot facts at one program point.
+#[derive(Debug, Clone, Default, PartialEq)]
+struct State {
+    ptrs: HashMap<VReg, Ptr>,
+    consts: HashMap<VReg, i64>,
+    /// Pointers spilled to `[frame_reg + disp]`
+    slots: HashMap<(VReg, i64), Ptr>,
+}
+
+impl State {
+    fn set(&mut self, dst: VReg, ptr: Option<Ptr>, k: Option<i64>) {
+        if is_frame(&dst) {
+            self.slots.retain(|(base, _), _| *base != dst);
+        }
+        match ptr {
+            Some(p) => self.ptrs.insert(dst.clone(), p),
+            None => self.ptrs.remove(&dst),
+        };
+        match k {
+            Some(k) => self.consts.insert(dst, k),
+            None => self.consts.remove(&dst),
+        };
+    }
+
+    /// Facts that hold on every incoming edge.
+    fn meet(mut self, other: &State) -> State {
+        self.ptrs.retain(|r, p| other.ptrs.get(r) == Some(p));
+        self.consts.retain(|r, k| other.consts.get(r) == Some(k));
+        self.slots.retain(|s, p| other.slots.get(s) == Some(p));
+        self
+    }
+
+    /// Drop everything a call may overwrite.
+    fn clobber(&mut self, conv: CallConv) {
+        let keep = |r: &VReg| matches!(r, VReg::Phys(n) if survives_call(conv, n));
+        self.ptrs.retain(|r, _| keep(r));
+        self.consts.retain(|r, _| keep(r));
+    }
+}
+
+const X86_FAMILIES: &[&[&str]] = &[
+    &["rax", "eax", "ax", "al", "ah"],
+    &["rbx", "ebx", "bx", "bl", "bh"],
+    &["rcx", "ecx", "cx", "cl", "ch"],
+    &["rdx", "edx", "dx", "dl", "dh"],
+    &["rsi", "esi", "si", "sil"],
+    &["rdi", "edi", "di", "dil"],
+    &["rbp", "ebp", "bp", "bpl"],
+    &["rsp", "esp", "sp", "spl"],
+];
+
+/// Full-width register a (sub-)register name belongs to: `edi` → `rdi`,
+/// `r8d` → `r8`, `w3` → `x3`.
+fn family(name: &str) -> String {
+    if let Some(f) = X86_FAMILIES.iter().find(|f| f.contains(&name)) {
+        return f[0].to_string();
+    }
+    if let Some(rest) = name.strip_prefix('r') {
+        let digits = rest.trim_end_matches(['d', 'w', 'b']);
+        if !digits.is_empty()
+            && digits.bytes().all(|b| b.is_ascii_digit())
+            && rest.len() - digits.len() <= 1
+        {
+            return format!("r{}", digits);
+        }
+    }
+    if let Some(n) = name.strip_prefix('w') {
+        if !n.is_empty() && n.bytes().all(|b| b.is_ascii_digit()) {
+            return format!("x{}", n);
+        }
+    }
+    name.to_string()
+}
+
+fn canon(reg: &VReg) -> VReg {
+    match reg {
+        VReg::Phys(name) => VReg::Phys(family(name)),
+        other => other.clone(),
+    }
+}
+
+fn is_frame(reg: &VReg) -> bool {
+    matches!(reg, VReg::Phys(n) if matches!(n.as_str(), "rsp" | "rbp" | "x29"))
+}
+
+/// Callee-saved registers keep their facts across a call.
+fn survives_call(conv: CallConv, name: &str) -> bool {
+    match conv {
+        CallConv::SysVAmd64 => {
+            matches!(name, "rbx" | "rbp" | "rsp" | "r12" | "r13" | "r14" | "r15")
+        }
+        CallConv::Win64 => matches!(
+            name,
+            "rbx" | "rbp" | "rsp" | "rsi" | "rdi" | "r12" | "r13" | "r14" | "r15"
+        ),
+        CallConv::Aarch64 => {
+            name == "sp"
+                || name
+                    .strip_prefix('x')
+                    .and_then(|n| n.parse::<u8>().ok())
+                    .is_some_and(|n| (19..=29).contains(&n))
+        }
+        CallConv::Arm => matches!(
+            name,
+            "r4" | "r5" | "r6" | "r7" | "r8" | "r9" | "r10" | "r11" | "sp"
+        ),
+    }
+}
+
+fn scalar_type(width: u64) -> String {
+    match width {
+        1 => "uint8_t".to_string(),
+        2 => "uint16_t".to_string(),
+        4 => "uint32_t".to_string(),
+        8 => "uint64_t".to_string(),
+        n => format!("uint8_t[{}]", n),
+    }
+}
+
+/// Struct or function names reduced to a C identifier.
+fn c_identifier(name: &str) -> String {
+    let mut out: String = name
+        .chars()
+        .map(|c| if c.is_ascii_alphanumeric() { c } else { '_' })
+        .collect();
+    if out.starts_with(|c: char| c.is_ascii_digit()) {
+        out.insert(0, '_');
+    }
+    out
+}
+
+struct UnionFind(Vec<usize>);
+
+impl UnionFind {
+    fn find(&mut self, mut x: usize) -> usize {
+        while self.0[x] != x {
+            self.0[x] = self.0[self.0[x]];
+            x = self.0[x];
+        }
+        x
+    }
+
+    /// Merge the sets of `a` and `b`; false if they were already one.
+    fn union(&mut self, a: usize, b: usize) -> bool {
+        let (a, b) = (self.find(a), self.find(b));
+        if a == b {
+            return false;
+        }
+        // Keep the older root as representative for stable naming.
+        let (lo, hi) = if a < b { (a, b) } else { (b, a) };
+        self.0[hi] = lo;
+        true
+    }
+}
+
+/// One group of unified roots.
+#[derive(Debug, Default)]
+struct Group {
+    origins: BTreeSet<Origin>,
+    fields: BTreeMap<i64, Access>,
+    alloc_size: Option<u64>,
+    funcs: BTreeSet<u64>,
+    called: bool,
+}
+
+impl Group {
+    /// Most-derived class whose vtable lands at offset 0.
+    fn class(&self) -> Option<&str> {
+        self.fields
+            .get(&0)?
+            .vtables
+            .iter()
+            .max_by(|a, b| a.0.cmp(&b.0).then(b.1.cmp(&a.1)))
+            .map(|(_, c)| c.as_str())
+    }
+
+    /// Worth reporting as a struct: two members, a sized allocation, or a
+    /// vtable. A lone offset-0 access is a plain dereference.
+    fn is_struct(&self) -> bool {
+        self.fields.len() >= 2
+            || (self.alloc_size.is_some() && !self.fields.is_empty())
+            || self.fields.values().any(|a| !a.vtables.is_empty())
+    }
+}
+
+struct Recoverer<'a> {
+    arch: Arch,
+    conv: CallConv,
+    ptr_size: u8,
+    hints: &'a StructHints,
+    roots: Vec<Root>,
+    /// Pointee root reached through `(root, offset)`
+    children: HashMap<(usize, i64), usize>,
+    /// Parameter roots by `(function entry, slot)`
+    params: HashMap<(u64, usize), usize>,
+    /// Roots known to be the same object
+    aliases: Vec<(usize, usize)>,
+    /// `(root, callee, slot)` for objects passed to direct calls
+    passed: Vec<(usize, u64, usize)>,
+}
+
+impl<'a> Recoverer<'a> {
+    fn new(arch: Arch, conv: CallConv, hints: &'a StructHints) -> Self {
+        Self {
+            arch,
+            conv,
+            ptr_size: if matches!(arch, Arch::X86 | Arch::ARM) {
+                4
+            } else {
+                8
+            },
+            hints,
+            roots: Vec::new(),
+            children: HashMap::new(),
+            params: HashMap::new(),
+            aliases: Vec::new(),
+            passed: Vec::new(),
+        }
+    }
+
+    fn new_root(&mut self, origin: Origin) -> usize {
+        self.roots.push(Root {
+            origin,
+            fields: BTreeMap::new(),
+            alloc_size: None,
+            funcs: BTreeSet::new(),
+            called: false,
+        });
+        self.roots.len() - 1
+    }
+
+    /// Register-passed argument slots; 32-bit x86 passes them on the stack.
+    fn arg_regs(&self) -> Vec<&'static str> {
+        if self.arch == Arch::X86 {
+            return Vec::new();
+        }
+        (0..)
+            .map_while(|slot| arg_register(self.conv, slot))
+            .collect()
+    }
+
+    fn ret_reg(&self) -> &'static str {
+        match self.arch {
+            Arch::AArch64 => "x0",
+            Arch::ARM => "r0",
+            _ => "rax",
+        }
+    }
+
+    fn ptr_of(st: &State, v: &Value) -> Option<Ptr> {
+        match v {
+            Value::Reg(r) => st.ptrs.get(&canon(r)).copied(),
+            _ => None,
+        }
+    }
+
+    fn const_of(st: &State, v: &Value) -> Option<i64> {
+        match v {
+            Value::Const(k) => Some(*k),
+            Value::Addr(a) => Some(*a as i64),
+            Value::Reg(r) => st.consts.get(&canon(r)).copied(),
+        }
+    }
+
+    /// Note an access; false when `offset` cannot be a member.
+    fn record(&mut self, root: usize, offset: i64, size: u8, write: bool, func: u64) -> bool {
+        if !(0..MAX_FIELD_OFFSET).contains(&offset) || size == 0 {
+            return false;
+        }
+        let r = &mut self.roots[root];
+        r.funcs.insert(func);
+        let acc = r.fields.entry(offset).or_default();
+        if write {
+            acc.writes += 1;
+        } else {
+            acc.reads += 1;
+        }
+        *acc.widths.entry(size).or_default() += 1;
+        true
+    }
+
+    /// Root for the object `(root, offset)` points to.
+    fn child(&mut self, parent: usize, offset: i64) -> usize {
+        if let Some(&c) = self.children.get(&(parent, offset)) {
+            return c;
+        }
+        let c = self.new_root(Origin::Field { parent, offset });
+        self.children.insert((parent, offset), c);
+        c
+    }
+
+    fn function(&mut self, f: &LlirFunction) {
+        let entry = f.entry_va;
+        let mut init = State::default();
+        for (slot, reg) in self.arg_regs().into_iter().enumerate() {
+            let root = self.new_root(Origin::Arg { func: entry, slot });
+            self.params.insert((entry, slot), root);
+            init.ptrs.insert(VReg::phys(reg), Ptr { root, delta: 0 });
+        }
+
+        let mut preds: HashMap<u64, Vec<u64>> = HashMap::new();
+        for b in &f.blocks {
+            for s in &b.succs {
+                preds.entry(*s).or_default().push(b.start_va);
+            }
+        }
+        // One forward sweep in address order; back edges contribute nothing.
+        let mut out: HashMap<u64, State> = HashMap::new();
+        for b in &f.blocks {
+            let mut st = if b.start_va == entry {
+                init.clone()
+            } else {
+                let mut incoming = preds
+                    .get(&b.start_va)
+                    .into_iter()
+                    .flatten()
+                    .filter_map(|p| out.get(p));
+                match incoming.next() {
+                    Some(first) => incoming.fold(first.clone(), |acc, s| acc.meet(s)),
+                    None => State::default(),
+                }
+            };
+            for ins in &b.instrs {
+                self.step(&mut st, entry, ins.va, &ins.op);
+            }
+            out.insert(b.start_va, st);
+        }
+    }
+
+    fn step(&mut self, st: &mut State, func: u64, va: u64, op: &Op) {
+        match op {
+            Op::Assign { dst, src } => {
+                let (p, k) = (Self::ptr_of(st, src), Self::const_of(st, src));
+                st.set(canon(dst), p, k);
+            }
+            Op::Bin { dst, op, lhs, rhs } => {
+                let (pl, pr) = (Self::ptr_of(st, lhs), Self::ptr_of(st, rhs));
+                let (kl, kr) = (Self::const_of(st, lhs), Self::const_of(st, rhs));
+                let moved = |p: Option<Ptr>, k: Option<i64>, sign: i64| {
+                    p.zip(k).map(|(p, k)| Ptr {
+                        root: p.root,
+                        delta: p.delta.wrapping_add(sign.wrapping_mul(k)),
+                    })
+                };
+                let (p, k) = match op {
+                    BinOp::Add => (
+                        moved(pl, kr, 1).or_else(|| moved(pr, kl, 1)),
+                        kl.zip(kr).map(|(a, b)| a.wrapping_add(b)),
+                    ),
+                    BinOp::Sub => (
+                        moved(pl, kr, -1),
+                        kl.zip(kr).map(|(a, b)| a.wrapping_sub(b)),
+                    ),
+                    BinOp::Mul => (None, kl.zip(kr).map(|(a, b)| a.wrapping_mul(b))),
+                    BinOp::Shl => (
+                        None,
+                        kl.zip(kr).map(|(a, b)| a.wrapping_shl((b & 63) as u32)),
+                    ),
+                    BinOp::Xor if lhs == rhs => (None, Some(0)),
+                    _ => (None, None),
+                };
+                st.set(canon(dst), p, k);
+            }
+            Op::Load { dst, addr } => {
+                let mut p = None;
+                if let (Some(base), None) = (addr.base.as_ref().map(canon), &addr.index) {
+                    if let Some(b) = st.ptrs.get(&base).copied() {
+                        let off = b.delta.wrapping_add(addr.disp);
+                        if self.record(b.root, off, addr.size, false, func)
+                            && addr.size == self.ptr_size
+                        {
+                            p = Some(Ptr {
+                                root: self.child(b.root, off),
+                                delta: 0,
+                            });
+                        }
+                    } else if is_frame(&base) {
+                        p = st.slots.get(&(base, addr.disp)).copied();
+                    }
+                }
+                st.set(canon(dst), p, None);
+            }
+            Op::Store { addr, src } => {
+                let (Some(base), None) = (addr.base.as_ref().map(canon), &addr.index) else {
+                    return;
+                };
+                let stored = Self::ptr_of(st, src).filter(|_| addr.size == self.ptr_size);
+                if let Some(b) = st.ptrs.get(&base).copied() {
+                    let off = b.delta.wrapping_add(addr.disp);
+                    if !self.record(b.root, off, addr.size, true, func) {
+                        return;
+                    }
+                    if let Some(q) = stored.filter(|q| q.delta == 0) {
+                        match self.children.get(&(b.root, off)) {
+                            Some(&c) => self.aliases.push((c, q.root)),
+                            None => {
+                                self.children.insert((b.root, off), q.root);
+                            }
+                        }
+                    } else if let Some(vt) =
+                        Self::const_of(st, src).and_then(|k| self.hints.vtables.get(&(k as u64)))
+                    {
+                        let (class, depth) = vt.clone();
+                        if let Some(acc) = self.roots[b.root].fields.get_mut(&off) {
+                            acc.vtables.insert((depth, class));
+                        }
+                    }
+                } else if is_frame(&base) {
+                    match stored {
+                        Some(q) => st.slots.insert((base, addr.disp), q),
+                        None => st.slots.remove(&(base, addr.disp)),
+                    };
+                }
+            }
+            Op::Call { target } => {
+                let (callee, alloc) = match target {
+                    CallTarget::Direct(t) => (Some(*t), self.hints.allocators.get(t).copied()),
+                    CallTarget::Indirect(Value::Addr(slot)) => {
+                        (None, self.hints.allocators.get(slot).copied())
+                    }
+                    CallTarget::Indirect(v) => {
+                        if let Some(p) = Self::ptr_of(st, v).filter(|p| p.delta == 0) {
+                            self.roots[p.root].called = true;
+                        }
+                        (None, None)
+                    }
+                };
+                let args: Vec<(Option<Ptr>, Option<i64>)> = self
+                    .arg_regs()
+                    .into_iter()
+                    .map(|r| {
+                        let r = VReg::phys(r);
+                        (st.ptrs.get(&r).copied(), st.consts.get(&r).copied())
+                    })
+                    .collect();
+                if let (Some(t), None) = (callee, alloc) {
+                    for (slot, (p, _)) in args.iter().enumerate() {
+                        if let Some(p) = p.filter(|p| p.delta == 0) {
+                            self.passed.push((p.root, t, slot));
+                        }
+                    }
+                }
+                st.clobber(self.conv);
+                if let Some(size) = alloc {
+                    let arg = |i: usize| args.get(i).and_then(|a| a.1);
+                    let n = match size {
+                        AllocSize::Arg(i) => arg(i),
+                        AllocSize::Product(a, b) => {
+                            arg(a).zip(arg(b)).map(|(a, b)| a.wrapping_mul(b))
+                        }
+                    };
+                    let root = self.new_root(Origin::Alloc { site: va });
+                    self.roots[root].alloc_size = n
+                        .filter(|n| (1..=MAX_FIELD_OFFSET).contains(n))
+                        .map(|n| n as u64);
+                    st.set(
+                        VReg::phys(self.ret_reg()),
+                        Some(Ptr { root, delta: 0 }),
+                        None,
+                    );
+                }
+            }
+            Op::CondAssign { dst, .. }
+            | Op::Un { dst, .. }
+            | Op::Cmp { dst, .. }
+            | Op::ZExt { dst, .. }
+            | Op::SExt { dst, .. }
+            | Op::Trunc { dst, .. }
+            | Op::Extract { dst, .. }
+            | Op::Concat { dst, .. }
+            | Op::Ite { dst, .. } => st.set(canon(dst), None, None),
+            Op::Intrinsic { outs, .. } => {
+                for (dst, _) in outs {
+                    st.set(canon(dst), None, None);
+                }
+            }
+            _ => {}
+        }
+    }
+
+    fn finish(self) -> Vec<RecoveredStruct> {
+        let mut uf = UnionFind((0..self.roots.len()).collect());
+        for &(a, b) in &self.aliases {
+            uf.union(a, b);
+        }
+        for &(root, callee, slot) in &self.passed {
+            if let Some(&p) = self.params.get(&(callee, slot)) {
+                uf.union(root, p);
+            }
+        }
+        // Pointees of the same field of one struct are one type; merging
+        // them can merge their own pointees in turn.
+        let mut edges: Vec<(usize, i64, usize)> = self
+            .children
+            .iter()
+            .map(|(&(parent, off), &child)| (parent, off, child))
+            .collect();
+        edges.sort_unstable();
+        loop {
+            let mut changed = false;
+            let mut seen: HashMap<(usize, i64), usize> = HashMap::new();
+            for &(parent, off, child) in &edges {
+                let key = (uf.find(parent), off);
+                match seen.get(&key) {
+                    Some(&c) => changed |= uf.union(c, child),
+                    None => {
+                        seen.insert(key, child);
+                    }
+                }
+            }
+            if !changed {
+                break;
+            }
+        }
+
+        let mut groups: BTreeMap<usize, Group> = BTreeMap::new();
+        for (i, root) in self.roots.iter().enumerate() {
+            let g = groups.entry(uf.find(i)).or_default();
+            g.origins.insert(root.origin);
+            for (off, acc) in &root.fields {
+                g.fields.entry(*off).or_default().merge(acc);
+            }
+            g.alloc_size = g.alloc_size.max(root.alloc_size);
+            g.funcs.extend(root.funcs.iter().copied());
+            g.called |= root.called;
+        }
+        let pointees: HashMap<(usize, i64), usize> = edges
+            .iter()
+            .map(|&(parent, off, child)| ((uf.find(parent), off), uf.find(child)))
+            .collect();
+        let parent_of = |origin: &Origin, uf: &mut UnionFind| match *origin {
+            Origin::Field { parent, offset } => Some((uf.find(parent), offset)),
+            _ => None,
+        };
+
+        let emitted: BTreeSet<usize> = groups
+            .iter()
+            .filter(|(_, g)| g.is_struct())
+            .map(|(id, _)| *id)
+            .collect();
+        let mut names: HashMap<usize, String> = HashMap::new();
+        for &id in &emitted {
+            let mut id_at = id;
+            let mut path: Vec<i64> = Vec::new();
+            let base = loop {
+                let g = &groups[&id_at];
+                if let Some(class) = g.class() {
+                    break c_identifier(class);
+                }
+                let named = g.origins.iter().find_map(|o| match *o {
+                    Origin::Alloc { site } => Some(format!("alloc_{:x}", site)),
+                    Origin::Arg { func, slot } => Some(format!(
+                        "{}_arg{}",
+                        c_identifier(
+                            &self
+                                .hints
+                                .names
+                                .get(&func)
+                                .cloned()
+                                .unwrap_or_else(|| format!("sub_{:x}", func))
+                        ),
+                        slot
+                    )),
+                    Origin::Field { .. } => None,
+                });
+                if let Some(n) = named {
+                    break n;
+                }
+                let up = g.origins.iter().find_map(|o| parent_of(o, &mut uf));
+                match up {
+                    Some((parent, off)) if path.len() < MAX_NAME_DEPTH => {
+                        path.push(off);
+                        id_at = parent;
+                    }
+                    _ => break format!("struct_{}", id),
+                }
+            };
+            let mut name = base;
+            for off in path.iter().rev() {
+                name.push_str(&format!("_{:x}", off));
+            }
+            names.insert(id, format!("{}_t", name));
+        }
+        // Distinct groups can land on the same class name.
+        let mut taken: HashMap<String, usize> = HashMap::new();
+        for &id in &emitted {
+            let n = names.get_mut(&id).unwrap();
+            let seen = taken.entry(n.clone()).or_default();
+            *seen += 1;
+            if *seen > 1 {
+                n.push_str(&format!("_{}", seen));
+            }
+        }
+
+        let mut out: Vec<RecoveredStruct> = Vec::new();
+        for &id in &emitted {
+            let g = &groups[&id];
+            let mut fields: Vec<RecoveredField> = Vec::new();
+            let mut end = 0u64;
+            for (&off, acc) in &g.fields {
+                let off = off as u64;
+                if off < end {
+                    continue;
+                }
+                let width = u64::from(acc.width());
+                let pointee = pointees.get(&(id, off as i64));
+                let (name, c_type) = if !acc.vtables.is_empty() {
+                    let name = if off == 0 {
+                        "vtable".to_string()
+                    } else {
+                        format!("vtable_{:x}", off)
+                    };
+                    (name, "void **".to_string())
+                } else if let Some(n) = pointee.and_then(|p| names.get(p)) {
+                    (format!("fld_{:x}", off), format!("struct {} *", n))
+                } else if pointee.is_some_and(|p| groups[p].called && groups[p].fields.is_empty()) {
+                    (format!("fld_{:x}", off), "void (*)(void)".to_string())
+                } else {
+                    (format!("fld_{:x}", off), scalar_type(width))
+                };
+                fields.push(RecoveredField {
+                    offset: off,
+                    size: width,
+                    name,
+                    c_type,
+                    reads: acc.reads,
+                    writes: acc.writes,
+                });
+                end = off + width;
+            }
+            let mut s = RecoveredStruct {
+                name: names[&id].clone(),
+                size: end.max(g.alloc_size.unwrap_or(0)),
+                fields,
+                alloc_size: g.alloc_size,
+                class: g.class().map(str::to_string),
+                dwarf: false,
+                functions: g.funcs.iter().copied().collect(),
+                confidence: 0.0,
+            };
+            s.confidence = 0.5
+                + if s.alloc_size.is_some() { 0.2 } else { 0.0 }
+                + if s.class.is_some() { 0.2 } else { 0.0 }
+                + if s.functions.len() > 1 { 0.1 } else { 0.0 };
+            if let Some(dt) = match_dwarf(&self.hints.dwarf, &s) {
+                apply_dwarf(&mut s, dt);
+            }
+            out.push(s);
+        }
+        out.sort_by(|a, b| {
+            a.functions
+                .first()
+                .cmp(&b.functions.first())
+                .then_with(|| a.name.cmp(&b.name))
+        });
+        out
+    }
+}
+
+/// The one DWARF structure (by name) every observed member lines up with.
+fn match_dwarf<'d>(dwarf: &'d [DwarfType], s: &RecoveredStruct) -> Option<&'d DwarfType> {
+    if s.fields.len() < 2 && s.alloc_size.is_none() {
+        return None;
+    }
+    let fits = |dt: &DwarfType| {
+        dt.kind == DwarfTypeKind::Struct
+            && dt.byte_size > 0
+            && s.alloc_size
+                .map_or(s.size <= dt.byte_size, |n| n == dt.byte_size)
+            && s.fields.iter().all(|f| {
+                dt.fields
+                    .iter()
+                    .any(|m| m.offset == f.offset && (m.size == 0 || f.size <= m.size))
+            })
+    };
+    let mut hits = dwarf.iter().filter(|dt| fits(dt));
+    let first = hits.next()?;
+    if hits.any(|dt| dt.name != first.name) {
+        return None;
+    }
+    Some(first)
+}
+
+/// Replace the access-derived layout with the DWARF one, keeping observed
+/// access counts on the members that were seen.
+fn apply_dwarf(s: &mut RecoveredStruct, dt: &DwarfType) {
+    let seen: HashMap<u64, (u32, u32)> = s
+        .fields
+        .iter()
+        .map(|f| (f.offset, (f.reads, f.writes)))
+        .collect();
+    s.fields = dt
+        .fields
+        .iter()
+        .map(|m| {
+            let (reads, writes) = seen.get(&m.offset).copied().unwrap_or_default();
+            RecoveredField {
+                offset: m.offset,
+                size: m.size,
+                name: m.name.clone(),
+                c_type: m.c_type.clone(),
+                reads,
+                writes,
+            }
+        })
+        .collect();
+    s.name = c_identifier(&dt.name);
+    s.size = dt.byte_size;
+    s.dwarf = true;
+    s.confidence = 0.9;
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::debug::dwarf::DwarfField;
+    use crate::ir::types::{LlirBlock, LlirInstr, MemOp};
+
+    fn func(entry: u64, ops: Vec<Op>) -> LlirFunction {
+        LlirFunction {
+            entry_va: entry,
+            blocks: vec![LlirBlock {
+                start_va: entry,
+                end_va: entry + 0x100,
+                instrs: ops
+                    .into_iter()
+                    .enumerate()
+                    .map(|(j, op)| LlirInstr {
+                        va: entry + (j as u64) * 4,
+                        op,
+                    })
+                    .collect(),
+                succs: vec![],
+            }],
+        }
+    }
+
+    fn reg(n: &str) -> VReg {
+        VReg::phys(n)
+    }
+
+    fn mem(base: &str, disp: i64, size: u8) -> MemOp {
+        MemOp::plain(Some(reg(base)), None, 1, disp, size)
+    }
+
+    fn load(dst: &str, base: &str, disp: i64, size: u8) -> Op {
+        Op::Load {
+            dst: reg(dst),
+            addr: mem(base, disp, size),
+        }
+    }
+
+    fn store(base: &str, disp: i64, size: u8, src: Value) -> Op {
+        Op::Store {
+            addr: mem(base, disp, size),
+            src,
+        }
+    }
+
+    fn assign(dst: &str, src: Value) -> Op {
+        Op::Assign { dst: reg(dst), src }
+    }
+
+    #[test]
+    fn allocation_size_and_callee_accesses_form_one_struct() {
+        // p = malloc(0x20); p->fld_0 = 0; init(p);
+        // init(q) { q->fld_8 = q->fld_0; q->fld_10 = (u8)0; }
+        let creator = func(
+            0x1000,
+            vec![
+                assign("edi", Value::Const(0x20)),
+                Op::Call {
+                    target: CallTarget::Direct(0x9000),
+                },
+                store("rax", 0, 8, Value::Const(0)),
+                assign("rdi", Value::Reg(reg("rax"))),
+                Op::Call {
+                    target: CallTarget::Direct(0x2000),
+                },
+            ],
+        );
+        let init = func(
+            0x2000,
+            vec![
+                load("rax", "rdi", 0, 8),
+                store("rdi", 8, 8, Value::Reg(reg("rax"))),
+                store("rdi", 0x10, 1, Value::Const(0)),
+            ],
+        );
+        let mut hints = StructHints::default();
+        hints
+            .allocators
+            .insert(0x9000, allocator_for("malloc@plt").unwrap());
+        hints.names.insert(0x2000, "init".into());
+        let out = recover_structs(&[creator, init], Arch::X86_64, CallConv::SysVAmd64, &hints);
+        assert_eq!(out.len(), 1);
+        let s = &out[0];
+        assert_eq!(s.name, "alloc_1004_t");
+        assert_eq!((s.size, s.alloc_size), (0x20, Some(0x20)));
+        assert_eq!(s.functions, [0x1000, 0x2000]);
+        let shape: Vec<(u64, u64)> = s.fields.iter().map(|f| (f.offset, f.size)).collect();
+        assert_eq!(shape, [(0, 8), (8, 8), (0x10, 1)]);
+        assert_eq!(
+            s.declaration(),
+            "struct alloc_1004_t {\n    uint64_t fld_0; /* 0x0 */\n    uint64_t fld_8; /* 0x8 */\n    \
+             uint8_t fld_10; /* 0x10 */\n    uint8_t _pad_11[15];\n};"
+        );
+    }
+
+    #[test]
+    fn spilled_argument_pointee_and_vtable_hints() {
+        // -O0 style: the argument is spilled and reloaded before each use.
+        // this->vptr = &Dog::vtable; this->next->len (u32); this->cb();
+        let method = func(
+            0x3000,
+            vec![
+                store("rbp", -0x18, 8, Value::Reg(reg("rdi"))),
+                load("rax", "rbp", -0x18, 8),
+                assign("rdx", Value::Addr(0x5010)),
+                store("rax", 0, 8, Value::Reg(reg("rdx"))),
+                load("rax", "rbp", -0x18, 8),
+                load("rax", "rax", 8, 8),
+                load("ecx", "rax", 4, 4),
+                load("edx", "rax", 0, 4),
+                load("rax", "rbp", -0x18, 8),
+                load("rax", "rax", 0x10, 8),
+                Op::Call {
+                    target: CallTarget::Indirect(Value::Reg(reg("rax"))),
+                },
+            ],
+        );
+        let mut hints = StructHints::default();
+        hints.vtables.insert(0x5010, ("zoo::Dog".into(), 1));
+        hints.names.insert(0x3000, "Dog::speak".into());
+        let out = recover_structs(&[method], Arch::X86_64, CallConv::SysVAmd64, &hints);
+        let dog = out
+            .iter()
+            .find(|s| s.name == "zoo__Dog_t")
+            .expect("class struct");
+        assert_eq!(dog.class.as_deref(), Some("zoo::Dog"));
+        let decl = dog.declaration();
+        assert!(decl.contains("void **vtable; /* 0x0 */"), "{decl}");
+        assert!(
+            decl.contains("struct zoo__Dog_8_t *fld_8; /* 0x8 */"),
+            "{decl}"
+        );
+        assert!(decl.contains("void (*fld_10)(void); /* 0x10 */"), "{decl}");
+        let next = out.iter().find(|s| s.name == "zoo__Dog_8_t").unwrap();
+        assert_eq!(next.fields.len(), 2);
+        assert_eq!(next.size, 8);
+    }
+
+    #[test]
+    fn dwarf_layout_replaces_matching_shape() {
+        let f = func(
+            0x4000,
+            vec![load("rax", "rdi", 0, 8), load("ecx", "rdi", 0xc, 4)],
+        );
+        let member = |offset: u64, name: &str, c_type: &str, size: u64| DwarfField {
+            offset,
+            name: name.into(),
+            c_type: c_type.into(),
+            size,
+        };
+        let dt = DwarfType {
+            kind: DwarfTypeKind::Struct,
+            name: "request".into(),
+            byte_size: 0x10,
+            fields: vec![
+                member(0, "buf", "char *", 8),
+                member(8, "cap", "unsigned int", 4),
+                member(0xc, "len", "unsigned int", 4),
+            ],
+            variants: vec![],
+            typedef_target: None,
+            source_file: None,
+        };
+        let hints = StructHints {
+            dwarf: vec![dt],
+            ..Default::default()
+        };
+        let out = recover_structs(&[f], Arch::X86_64, CallConv::SysVAmd64, &hints);
+        let s = &out[0];
+        assert!(s.dwarf);
+        assert_eq!(s.name, "request");
+        assert_eq!(
+            s.declaration(),
+            "struct request {\n    char *buf; /* 0x0 */\n    unsigned int cap; /* 0x8 */\n    \
+             unsigned int len; /* 0xc */\n};"
+        );
+        assert_eq!((s.fields[2].reads, s.fields[1].reads), (1, 0));
+    }
+
+    #[test]
+    fn lone_dereferences_and_stack_frames_are_not_structs() {
+        let f = func(
+            0x5000,
+            vec![
+                load("rax", "rdi", 0, 8),
+                store("rbp", -8, 8, Value::Const(1)),
+                load("rcx", "rbp", -0x10, 8),
+            ],
+        );
+        let out = recover_structs(
+            &[f],
+            Arch::X86_64,
+            CallConv::SysVAmd64,
+            &StructHints::default(),
+        );
+        assert!(out.is_empty());
+        assert_eq!(allocator_for("__imp_HeapAlloc"), Some(AllocSize::Arg(2)));
+        assert_eq!(allocator_for("_Znwm@plt"), Some(AllocSize::Arg(0)));
+        assert_eq!(allocator_for("??2@YAPEAX_K@Z"), Some(AllocSize::Arg(0)));
+        assert_eq!(allocator_for("mallocx"), None);
+    }
+}
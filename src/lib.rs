@@ -10,6 +10,12 @@ pub mod logging;
 /// Timeout utilities for analysis operations
 pub mod timeout;
 
+/// Cooperative cancellation tokens and deadlines
+pub mod cancel;
+
+/// Analysis lifecycle hooks and metrics
+pub mod events;
+
 /// Triage runtime implementation
 pub mod triage;
 
@@ -28,6 +34,9 @@ pub mod strings;
 /// High-performance entropy calculation and analysis
 pub mod entropy;
 
+/// Byte-structure images (Hilbert/scan byte maps, digraphs)
+pub mod visual;
+
 /// Analysis-time program and memory views
 pub mod analysis;
 
@@ -37,6 +46,9 @@ pub mod debug;
 /// FLIRT-style signature matching for stripped binaries
 pub mod flirt;
 
+/// Program-model exports (Ghidra XML, SARIF, protobuf)
+pub mod export;
+
 /// Disassembly engines and adapters
 pub mod disasm;
 
@@ -78,6 +90,11 @@ fn _native(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyResult<()> {
     m.add_function(wrap_pyfunction!(crate::logging::log_message, m)?)?;
     m.add_class::<crate::logging::LogLevel>()?;
 
+    // Register metrics functions
+    m.add_function(wrap_pyfunction!(crate::events::enable_metrics, m)?)?;
+    m.add_function(wrap_pyfunction!(crate::events::metrics_prometheus, m)?)?;
+    m.add_function(wrap_pyfunction!(crate::events::reset_metrics, m)?)?;
+
     // Top-level helper: symbol address map for a file
     m.add_function(wrap_pyfunction!(symbol_address_map_py, m)?)?;
 
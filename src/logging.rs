@@ -15,54 +15,116 @@ use tracing_subscriber::{
 
 static INIT: Once = Once::new();
 
+/// Environment variable consulted for the filter when none is configured
+/// explicitly; falls back to `RUST_LOG`.
+pub const LOG_ENV: &str = "GLAURUNG_LOG";
+
+/// Output encoding for log records.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum LogFormat {
+    /// Human-readable lines
+    #[default]
+    Text,
+    /// One JSON object per record, including the enclosing span list
+    Json,
+}
+
+/// Subscriber configuration.
+///
+/// `filter` accepts anything `EnvFilter` does: a bare level ("debug") or
+/// per-target directives ("info,glaurung::analysis::pipeline=trace").
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct LogConfig {
+    pub filter: Option<String>,
+    pub format: LogFormat,
+}
+
+impl Default for LogConfig {
+    fn default() -> Self {
+        Self {
+            filter: None,
+            format: LogFormat::Text,
+        }
+    }
+}
+
+impl LogConfig {
+    fn env_filter(&self) -> EnvFilter {
+        if let Some(f) = self.filter.as_deref() {
+            if let Ok(filter) = EnvFilter::try_new(f) {
+                return filter;
+            }
+        }
+        EnvFilter::try_from_env(LOG_ENV)
+            .or_else(|_| EnvFilter::try_from_default_env())
+            .unwrap_or_else(|_| EnvFilter::new("info"))
+    }
+}
+
+/// Span for work on one input file. Records logged inside it carry `file`.
+pub fn file_span(path: &str) -> tracing::Span {
+    tracing::info_span!("file", file = %path)
+}
+
+/// Span for one analysis pass. Records logged inside it carry `pass`.
+pub fn pass_span(pass: &str) -> tracing::Span {
+    tracing::info_span!("pass", pass = %pass)
+}
+
 /// Initialize the global tracing subscriber.
 ///
 /// This should be called once at program startup.
 /// Subsequent calls are ignored.
 pub fn init_tracing() {
-    INIT.call_once(|| {
-        let env_filter =
-            EnvFilter::try_from_default_env().unwrap_or_else(|_| EnvFilter::new("info"));
-
-        let fmt_layer = fmt::layer()
-            .with_span_events(FmtSpan::CLOSE)
-            .with_target(true)
-            .with_thread_ids(true)
-            .with_thread_names(true)
-            .with_file(true)
-            .with_line_number(true);
-
-        tracing_subscriber::registry()
-            .with(env_filter)
-            .with(fmt_layer)
-            .init();
-
-        info!("Glaurung tracing initialized");
-    });
+    init_tracing_with(&LogConfig::default());
 }
 
 /// Initialize tracing with JSON output for structured logging.
 pub fn init_tracing_json() {
+    init_tracing_with(&LogConfig {
+        format: LogFormat::Json,
+        ..LogConfig::default()
+    });
+}
+
+/// Initialize tracing from an explicit configuration.
+///
+/// Records go to stderr so they never interleave with report output on
+/// stdout. Only the first initialization in a process takes effect.
+pub fn init_tracing_with(config: &LogConfig) {
     INIT.call_once(|| {
-        let env_filter =
-            EnvFilter::try_from_default_env().unwrap_or_else(|_| EnvFilter::new("info"));
-
-        let fmt_layer = fmt::layer()
-            .json()
-            .with_span_events(FmtSpan::CLOSE)
-            .with_target(true)
-            .with_thread_ids(true)
-            .with_thread_names(true)
-            .with_file(true)
-            .with_line_number(true)
-            .with_current_span(true);
-
-        tracing_subscriber::registry()
-            .with(env_filter)
-            .with(fmt_layer)
-            .init();
-
-        info!("Glaurung tracing initialized (JSON mode)");
+        let env_filter = config.env_filter();
+        let registry = tracing_subscriber::registry().with(env_filter);
+
+        match config.format {
+            LogFormat::Text => {
+                let fmt_layer = fmt::layer()
+                    .with_span_events(FmtSpan::CLOSE)
+                    .with_target(true)
+                    .with_thread_ids(true)
+                    .with_thread_names(true)
+                    .with_file(true)
+                    .with_line_number(true)
+                    .with_writer(std::io::stderr);
+                registry.with(fmt_layer).init();
+                info!("Glaurung tracing initialized");
+            }
+            LogFormat::Json => {
+                let fmt_layer = fmt::layer()
+                    .json()
+                    .with_span_events(FmtSpan::CLOSE)
+                    .with_target(true)
+                    .with_thread_ids(true)
+                    .with_thread_names(true)
+                    .with_file(true)
+                    .with_line_number(true)
+                    .with_current_span(true)
+                    .with_span_list(true)
+                    .with_writer(std::io::stderr);
+                registry.with(fmt_layer).init();
+                info!("Glaurung tracing initialized (JSON mode)");
+            }
+        }
     });
 }
 
@@ -96,12 +158,21 @@ impl LogLevel {
 /// Initialize logging from Python
 #[cfg(feature = "python-ext")]
 #[pyo3::prelude::pyfunction]
-pub fn init_logging(json: bool) -> pyo3::PyResult<()> {
-    if json {
-        init_tracing_json();
-    } else {
-        init_tracing();
+#[pyo3(signature = (json=false, level=None))]
+pub fn init_logging(json: bool, level: Option<String>) -> pyo3::PyResult<()> {
+    if let Some(l) = level.as_deref() {
+        EnvFilter::try_new(l).map_err(|e| {
+            pyo3::exceptions::PyValueError::new_err(format!("invalid log filter '{}': {}", l, e))
+        })?;
     }
+    init_tracing_with(&LogConfig {
+        filter: level,
+        format: if json {
+            LogFormat::Json
+        } else {
+            LogFormat::Text
+        },
+    });
     Ok(())
 }
 
@@ -178,6 +249,27 @@ mod tests {
         );
     }
 
+    #[test]
+    fn test_config_filter_overrides_env() {
+        let cfg = LogConfig {
+            filter: Some("warn,glaurung=debug".to_string()),
+            format: LogFormat::Json,
+        };
+        let rendered = cfg.env_filter().to_string();
+        assert!(rendered.contains("glaurung=debug"));
+        assert!(rendered.contains("warn"));
+    }
+
+    #[test]
+    fn test_file_and_pass_spans() {
+        init_tracing();
+        let file = file_span("test.exe");
+        let _f = file.enter();
+        let pass = pass_span("entry");
+        let _p = pass.enter();
+        warn!("warning attributed to test.exe/entry");
+    }
+
     #[test]
     fn test_span_creation() {
         init_tracing();
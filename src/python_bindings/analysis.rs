@@ -65,8 +65,28 @@ pub fn register_analysis_bindings(_py: Python<'_>, m: &Bound<'_, PyModule>) -> P
         index_java_archive_bytes_py,
         &analysis_mod
     )?)?;
-    // Lua bytecode recognizer / source-name extractor.
+    // JVM call graph across a .class or every class in a JAR.
+    analysis_mod.add_function(wrap_pyfunction!(java_call_graph_path_py, &analysis_mod)?)?;
+    // Lua / LuaJIT bytecode parser: prototypes, constants, strings.
     analysis_mod.add_function(wrap_pyfunction!(parse_lua_bytecode_path_py, &analysis_mod)?)?;
+    // CPython .pyc parser / disassembler.
+    analysis_mod.add_function(wrap_pyfunction!(parse_pyc_path_py, &analysis_mod)?)?;
+    // Embedded configuration carver (length-prefixed, XOR/RC4 near markers).
+    analysis_mod.add_function(wrap_pyfunction!(carve_configs_path_py, &analysis_mod)?)?;
+    // Struct layouts inferred from field accesses, allocations, RTTI, DWARF.
+    analysis_mod.add_function(wrap_pyfunction!(recover_structs_path_py, &analysis_mod)?)?;
+    // Functions no root, call, or address reference reaches.
+    analysis_mod.add_function(wrap_pyfunction!(dead_functions_path_py, &analysis_mod)?)?;
+    // Byte-map (Hilbert/scan) and digraph images as PNG or SVG.
+    analysis_mod.add_function(wrap_pyfunction!(render_image_path_py, &analysis_mod)?)?;
+    // Go pclntab functions/lines, build info, packages and type names.
+    analysis_mod.add_function(wrap_pyfunction!(go_metadata_path_py, &analysis_mod)?)?;
+    // FLIRT-style signature libraries from .a/.lib archives, and matching.
+    analysis_mod.add_function(wrap_pyfunction!(flirt_build_library_py, &analysis_mod)?)?;
+    analysis_mod.add_function(wrap_pyfunction!(flirt_match_path_py, &analysis_mod)?)?;
+    // Program model as JSON, Ghidra XML, SARIF or protobuf.
+    analysis_mod.add_function(wrap_pyfunction!(export_program_path_py, &analysis_mod)?)?;
+    analysis_mod.add_function(wrap_pyfunction!(export_program_bytes_py, &analysis_mod)?)?;
 
     // Add analysis submodule to main module
     m.add_submodule(&analysis_mod)?;
@@ -361,6 +381,17 @@ fn function_discovery_stats_to_py(
         stats.pdata_chained_parent_starts,
     )?;
     dict.set_item("pdata_nonexec_rejected", stats.pdata_nonexec_rejected)?;
+    dict.set_item("eh_functions", stats.eh_functions)?;
+    dict.set_item("eh_try_regions", stats.eh_try_regions)?;
+    dict.set_item("eh_handler_seeds_inserted", stats.eh_handler_seeds_inserted)?;
+    dict.set_item("exception_edges", stats.exception_edges)?;
+    dict.set_item("go_itabs", stats.go_itabs)?;
+    dict.set_item("go_itab_seeds_inserted", stats.go_itab_seeds_inserted)?;
+    dict.set_item("go_interface_call_sites", stats.go_interface_call_sites)?;
+    dict.set_item("go_interface_call_edges", stats.go_interface_call_edges)?;
+    dict.set_item("register_call_edges", stats.register_call_edges)?;
+    dict.set_item("flirt_signatures", stats.flirt_signatures)?;
+    dict.set_item("flirt_functions_named", stats.flirt_functions_named)?;
     dict.set_item("prologue_scan_candidates", stats.prologue_scan_candidates)?;
     dict.set_item(
         "prologue_scan_seeds_inserted",
@@ -434,6 +465,7 @@ fn function_discovery_stats_to_py(
         item.set_item("source_va", provenance.source_va)?;
         item.set_item("kind", &provenance.kind)?;
         item.set_item("detail", &provenance.detail)?;
+        item.set_item("confidence", provenance.confidence)?;
         seed_provenance.append(item)?;
     }
     dict.set_item("seed_provenance", seed_provenance)?;
@@ -1021,7 +1053,8 @@ fn macho_stubs_map_path_py(
 
 /// Parse a Lua bytecode file (.luac or LuaJIT) and return a
 /// structured dict with version, format, source filename (if
-/// present), and engine kind. Returns None for non-Lua files.
+/// present), engine kind, string constants, and one entry per
+/// function prototype. Returns None for non-Lua files.
 #[pyfunction]
 #[pyo3(name = "parse_lua_bytecode_path")]
 #[pyo3(signature = (path, max_read_bytes=10_485_760u64, max_file_size=104_857_600u64))]
@@ -1050,6 +1083,27 @@ fn parse_lua_bytecode_path_py(
             dict.set_item("format", info.format)?;
             dict.set_item("source", info.source)?;
             dict.set_item("little_endian", info.little_endian)?;
+            dict.set_item("strings", info.strings())?;
+            let functions = pyo3::types::PyList::empty(py);
+            for f in &info.functions {
+                let fdict = pyo3::types::PyDict::new(py);
+                fdict.set_item("parent", f.parent)?;
+                fdict.set_item("source", &f.source)?;
+                fdict.set_item("line_defined", f.line_defined)?;
+                fdict.set_item("last_line_defined", f.last_line_defined)?;
+                fdict.set_item("num_params", f.num_params)?;
+                fdict.set_item("is_vararg", f.is_vararg)?;
+                fdict.set_item("max_stack", f.max_stack)?;
+                fdict.set_item("num_upvalues", f.num_upvalues)?;
+                fdict.set_item("num_instructions", f.num_instructions)?;
+                fdict.set_item("num_children", f.num_children)?;
+                let constants: Vec<String> = f.constants.iter().map(|k| k.to_string()).collect();
+                fdict.set_item("constants", constants)?;
+                fdict.set_item("locals", &f.locals)?;
+                fdict.set_item("upvalue_names", &f.upvalue_names)?;
+                functions.append(fdict)?;
+            }
+            dict.set_item("functions", functions)?;
             Ok(Some(dict.into()))
         }
         Err(LuaError::BadMagic) => Ok(None),
@@ -1060,6 +1114,75 @@ fn parse_lua_bytecode_path_py(
     }
 }
 
+/// Parse a CPython `.pyc` file (3.6-3.13) and return a dict with the
+/// version, header fields, string constants, imported modules, and one
+/// entry per code object. With `disassemble`, each code object also
+/// carries its instructions as `dis`-style lines. Returns None for files
+/// without a pyc magic.
+#[pyfunction]
+#[pyo3(name = "parse_pyc_path")]
+#[pyo3(signature = (path, disassemble=false, max_read_bytes=10_485_760u64, max_file_size=104_857_600u64))]
+fn parse_pyc_path_py(
+    py: Python<'_>,
+    path: String,
+    disassemble: bool,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<Option<Py<PyAny>>> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    use crate::analysis::pyc::{parse_pyc, PycError};
+    let pyc = match parse_pyc(&data) {
+        Ok(pyc) => pyc,
+        Err(PycError::BadMagic) => return Ok(None),
+        Err(e) => {
+            return Err(pyo3::exceptions::PyRuntimeError::new_err(format!(
+                "pyc parse failed: {:?}",
+                e,
+            )))
+        }
+    };
+    let dict = pyo3::types::PyDict::new(py);
+    dict.set_item("version", format!("3.{}", pyc.minor))?;
+    dict.set_item("magic", pyc.magic)?;
+    dict.set_item("flags", pyc.flags)?;
+    dict.set_item("source_mtime", pyc.source_mtime)?;
+    dict.set_item("source_size", pyc.source_size)?;
+    dict.set_item("source_hash", pyc.source_hash.map(hex::encode))?;
+    dict.set_item("filename", &pyc.code.filename)?;
+    dict.set_item("strings", pyc.strings())?;
+    dict.set_item("imports", pyc.imports())?;
+    let code_objects = pyo3::types::PyList::empty(py);
+    for c in pyc.code.code_objects() {
+        let cdict = pyo3::types::PyDict::new(py);
+        cdict.set_item("name", &c.name)?;
+        cdict.set_item("qualname", &c.qualname)?;
+        cdict.set_item("first_line", c.first_line)?;
+        cdict.set_item("arg_count", c.arg_count)?;
+        cdict.set_item("flags", c.flags)?;
+        cdict.set_item("names", &c.names)?;
+        cdict.set_item("varnames", &c.varnames)?;
+        cdict.set_item("bytecode_size", c.bytecode.len())?;
+        if disassemble {
+            let lines: Vec<String> = crate::analysis::pyc::disassemble(c, pyc.minor)
+                .map_err(|e| {
+                    pyo3::exceptions::PyRuntimeError::new_err(format!(
+                        "pyc disassembly failed in {}: {:?}",
+                        c.qualname, e,
+                    ))
+                })?
+                .iter()
+                .map(|i| i.to_string())
+                .collect();
+            cdict.set_item("instructions", lines)?;
+        }
+        code_objects.append(cdict)?;
+    }
+    dict.set_item("code_objects", code_objects)?;
+    Ok(Some(dict.into()))
+}
+
 /// Parse a Java `.class` file and return a structured dict with the
 /// class name, super class, interfaces, methods, and fields.
 /// Returns None for files that don't have the 0xCAFEBABE magic.
@@ -1098,6 +1221,59 @@ fn parse_java_class_bytes_inner(py: Python<'_>, data: &[u8]) -> PyResult<Option<
     }
 }
 
+/// Build the JVM call graph for a `.class` file or every class in a JAR.
+/// Returns a dict with the class count, method nodes, edges (caller,
+/// callee, kind, and call-site bytecode indices), and the suspicious
+/// calls among the callees. Returns None for anything else.
+#[pyfunction]
+#[pyo3(name = "java_call_graph_path")]
+#[pyo3(signature = (path, max_read_bytes=268_435_456u64, max_file_size=1_073_741_824u64))]
+fn java_call_graph_path_py(
+    py: Python<'_>,
+    path: String,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<Option<Py<PyAny>>> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    use crate::analysis::java_bytecode::{call_graph, jar_classes};
+    let classes = match crate::analysis::java_class::parse_class(&data) {
+        Ok(class) => vec![class],
+        Err(crate::analysis::java_class::ClassError::BadMagic(_)) => match jar_classes(&data) {
+            Ok(classes) => classes,
+            Err(_) => return Ok(None),
+        },
+        Err(e) => {
+            return Err(pyo3::exceptions::PyRuntimeError::new_err(format!(
+                "java class parse failed: {:?}",
+                e,
+            )))
+        }
+    };
+    let cg = call_graph(&classes);
+    let dict = pyo3::types::PyDict::new(py);
+    dict.set_item("class_count", classes.len())?;
+    dict.set_item("nodes", &cg.nodes)?;
+    let edges = pyo3::types::PyList::empty(py);
+    for e in &cg.edges {
+        let edict = pyo3::types::PyDict::new(py);
+        edict.set_item("caller", &e.caller)?;
+        edict.set_item("callee", &e.callee)?;
+        edict.set_item("kind", e.call_type.value())?;
+        let sites: Vec<u64> = e.call_sites.iter().map(|a| a.value).collect();
+        edict.set_item("call_sites", sites)?;
+        edges.append(edict)?;
+    }
+    dict.set_item("edges", edges)?;
+    let callees: Vec<String> = cg.edges.iter().map(|e| e.callee.clone()).collect();
+    dict.set_item(
+        "suspicious",
+        crate::symbols::analysis::suspicious::detect_suspicious_java_calls(&callees, 64),
+    )?;
+    Ok(Some(dict.into()))
+}
+
 fn java_class_info_to_py(
     py: Python<'_>,
     info: crate::analysis::java_class::ClassInfo,
@@ -1525,6 +1701,10 @@ fn java_code_to_py(
         return Ok(py.None());
     };
     let dict = pyo3::types::PyDict::new(py);
+    dict.set_item(
+        "disassembly",
+        crate::analysis::java_bytecode::disassemble(&code),
+    )?;
     dict.set_item("max_stack", code.max_stack)?;
     dict.set_item("max_locals", code.max_locals)?;
     dict.set_item("code_length", code.code_length)?;
@@ -1609,6 +1789,7 @@ fn java_code_to_py(
         xdict.set_item("descriptor", xref.descriptor)?;
         xdict.set_item("target", xref.target)?;
         xdict.set_item("string_value", xref.string_value)?;
+        xdict.set_item("bootstrap_index", xref.bootstrap_index)?;
         xrefs.append(xdict)?;
     }
     dict.set_item("xrefs", xrefs)?;
@@ -1760,3 +1941,356 @@ fn gopclntab_names_path_py(
         ))),
     }
 }
+
+/// Carve embedded configuration blobs from a file with the built-in
+/// extractors. Returns a JSON array of configs (offset, length, encoding,
+/// key, entries); empty when nothing config-like is found.
+#[pyfunction]
+#[pyo3(name = "carve_configs_path")]
+#[pyo3(signature = (path, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn carve_configs_path_py(
+    path: String,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let configs = crate::analysis::config_carver::carve_configs(&data);
+    serde_json::to_string(&configs).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize configs: {e}"))
+    })
+}
+
+/// Recover struct layouts from a file by running the pipeline's `structs`
+/// pass (and its dependencies). Returns a JSON array of structs, each with
+/// its fields and a C `declaration`; empty when nothing struct-like is
+/// accessed.
+#[pyfunction]
+#[pyo3(name = "recover_structs_path")]
+#[pyo3(signature = (path, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn recover_structs_path_py(
+    path: String,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    use crate::analysis::pipeline::blackboard::RecoveredStructs;
+    use crate::analysis::pipeline::{run_pipeline, PassRegistry, PassStatus, Profile};
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let img = crate::formats::object_image::ObjectImage::parse(&data)
+        .map_err(|e| pyo3::exceptions::PyValueError::new_err(format!("not an object file: {e}")))?;
+    let registry = PassRegistry::with_builtin();
+    let (report, mut ctx) =
+        run_pipeline(&registry, &Profile::only("structs", ["structs"]), &img)
+            .map_err(|e| pyo3::exceptions::PyRuntimeError::new_err(e.to_string()))?;
+    if let Some(PassStatus::Failed(e)) = report.outcome("structs").map(|o| &o.status) {
+        return Err(pyo3::exceptions::PyRuntimeError::new_err(e.to_string()));
+    }
+    let structs = ctx
+        .artifacts
+        .take::<RecoveredStructs>()
+        .map(|s| s.0)
+        .unwrap_or_default();
+    let out: Vec<serde_json::Value> = structs
+        .iter()
+        .map(|s| {
+            let mut v = serde_json::to_value(s).unwrap_or_default();
+            v["declaration"] = serde_json::Value::String(s.declaration());
+            v
+        })
+        .collect();
+    serde_json::to_string(&out).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize structs: {e}"))
+    })
+}
+
+/// Find unreferenced and unreachable functions in a file by running the
+/// pipeline's `dead_functions` pass (and its dependencies). Returns a JSON
+/// array of functions (va, name, size, status, referrers); empty when
+/// every discovered function is reachable.
+#[pyfunction]
+#[pyo3(name = "dead_functions_path")]
+#[pyo3(signature = (path, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn dead_functions_path_py(
+    path: String,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    use crate::analysis::pipeline::blackboard::DeadFunctions;
+    use crate::analysis::pipeline::{run_pipeline, PassRegistry, PassStatus, Profile};
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let img = crate::formats::object_image::ObjectImage::parse(&data)
+        .map_err(|e| pyo3::exceptions::PyValueError::new_err(format!("not an object file: {e}")))?;
+    let registry = PassRegistry::with_builtin();
+    let profile = Profile::only("dead_functions", ["dead_functions"]);
+    let (report, mut ctx) = run_pipeline(&registry, &profile, &img)
+        .map_err(|e| pyo3::exceptions::PyRuntimeError::new_err(e.to_string()))?;
+    if let Some(PassStatus::Failed(e)) = report.outcome("dead_functions").map(|o| &o.status) {
+        return Err(pyo3::exceptions::PyRuntimeError::new_err(e.to_string()));
+    }
+    let dead = ctx
+        .artifacts
+        .take::<DeadFunctions>()
+        .map(|d| d.0)
+        .unwrap_or_default();
+    serde_json::to_string(&dead).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize dead functions: {e}"))
+    })
+}
+
+/// Render a file as an image and return the encoded bytes.
+///
+/// `kind` is `hilbert` or `scan` for a `side`x`side` byte map (coloured by
+/// `coloring`: `class` or `entropy`, with section boundaries outlined when
+/// `sections` is true), or `digraph` for the 256x256 byte-pair map.
+/// `fmt` is `png` or `svg`; SVG is drawn at `scale` and carries the section
+/// legend.
+#[pyfunction]
+#[pyo3(name = "render_image_path")]
+#[pyo3(signature = (
+    path,
+    kind="hilbert",
+    coloring="class",
+    side=256u32,
+    fmt="png",
+    sections=true,
+    scale=2u32,
+    max_read_bytes=104_857_600u64,
+    max_file_size=104_857_600u64
+))]
+#[allow(clippy::too_many_arguments)]
+fn render_image_path_py(
+    py: Python<'_>,
+    path: String,
+    kind: &str,
+    coloring: &str,
+    side: u32,
+    fmt: &str,
+    sections: bool,
+    scale: u32,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<Py<PyAny>> {
+    use crate::visual::{render_byte_map, render_digraph, section_overlays, RenderOptions};
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let raster = if kind.eq_ignore_ascii_case("digraph") {
+        render_digraph(&data)
+    } else {
+        let opts = RenderOptions {
+            layout: kind
+                .parse()
+                .map_err(pyo3::exceptions::PyValueError::new_err)?,
+            coloring: coloring
+                .parse()
+                .map_err(pyo3::exceptions::PyValueError::new_err)?,
+            side,
+            ..Default::default()
+        };
+        let overlays = if sections {
+            section_overlays(&data)
+        } else {
+            Vec::new()
+        };
+        render_byte_map(&data, &opts, &overlays)
+    };
+    let out = match fmt.to_ascii_lowercase().as_str() {
+        "png" => raster.to_png(),
+        "svg" => raster.to_svg(scale).into_bytes(),
+        other => {
+            return Err(pyo3::exceptions::PyValueError::new_err(format!(
+                "unknown image format '{}'",
+                other
+            )))
+        }
+    };
+    Ok(pyo3::types::PyBytes::new(py, &out).into())
+}
+
+/// Recover a Go binary's runtime metadata: pclntab functions with source
+/// positions (and per-instruction line rows when `lines` is true), build
+/// information, packages and type names. Returns a JSON object; raises
+/// ValueError when the file is not a Go binary and RuntimeError when its
+/// pclntab is malformed.
+#[pyfunction]
+#[pyo3(name = "go_metadata_path")]
+#[pyo3(signature = (path, lines=false, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn go_metadata_path_py(
+    path: String,
+    lines: bool,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    use crate::analysis::gopclntab::GoPclnError;
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let meta = match crate::analysis::gometa::recover_go_metadata(&data, lines) {
+        Ok(meta) => meta,
+        Err(GoPclnError::NoSection) | Err(GoPclnError::UnknownMagic(_)) => {
+            return Err(pyo3::exceptions::PyValueError::new_err(format!(
+                "not a Go binary: {}",
+                path
+            )))
+        }
+        Err(e) => {
+            return Err(pyo3::exceptions::PyRuntimeError::new_err(format!(
+                "gopclntab parse failed: {:?}",
+                e
+            )))
+        }
+    };
+    serde_json::to_string(&meta).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize Go metadata: {e}"))
+    })
+}
+
+/// Build a FLIRT-style signature library from static libraries (`.a` /
+/// `.lib`) and relocatable objects. Objects built for another `arch` are
+/// skipped. Returns the library as JSON, ready to drop into `data/sigs/`
+/// as `<name>.flirt.json`.
+#[pyfunction]
+#[pyo3(name = "flirt_build_library")]
+#[pyo3(signature = (paths, arch="x86_64", pattern_len=32, min_length=16, max_read_bytes=268_435_456u64, max_file_size=268_435_456u64))]
+fn flirt_build_library_py(
+    paths: Vec<String>,
+    arch: &str,
+    pattern_len: usize,
+    min_length: usize,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let mut inputs = Vec::with_capacity(paths.len());
+    for path in paths {
+        let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+            .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+        inputs.push((path, data));
+    }
+    let opts = crate::flirt::build::BuildOptions {
+        pattern_len,
+        min_length,
+        ..Default::default()
+    };
+    let file = crate::flirt::build::build_library(
+        inputs.iter().map(|(p, d)| (p.clone(), d.as_slice())),
+        arch,
+        &opts,
+    );
+    serde_json::to_string(&file).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize FLIRT library: {e}"))
+    })
+}
+
+/// Scan a binary's code sections for functions in the given FLIRT
+/// libraries (default: the libraries analysis loads). Returns a JSON list
+/// of `{va, name}` sorted by address; raises ValueError when a library
+/// cannot be read.
+#[pyfunction]
+#[pyo3(name = "flirt_match_path")]
+#[pyo3(signature = (path, libraries=None, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn flirt_match_path_py(
+    path: String,
+    libraries: Option<Vec<String>>,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<String> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let paths: Vec<std::path::PathBuf> = match libraries {
+        Some(l) => l.into_iter().map(Into::into).collect(),
+        None => crate::flirt::default_library_paths(),
+    };
+    let mut merged: Option<crate::flirt::FlirtLibrary> = None;
+    for p in paths {
+        let lib = crate::flirt::FlirtLibrary::load(&p).map_err(|e| {
+            pyo3::exceptions::PyValueError::new_err(format!("{}: {e}", p.display()))
+        })?;
+        match merged.as_mut() {
+            Some(m) => m.merge(lib),
+            None => merged = Some(lib),
+        }
+    }
+    let mut hits = match merged {
+        Some(lib) => crate::flirt::discover_flirt_seeds(&data, &[], &lib),
+        None => Vec::new(),
+    };
+    hits.sort();
+    let out: Vec<serde_json::Value> = hits
+        .into_iter()
+        .map(|(va, name)| serde_json::json!({ "va": va, "name": name }))
+        .collect();
+    serde_json::to_string(&out).map_err(|e| {
+        pyo3::exceptions::PyValueError::new_err(format!("failed to serialize FLIRT matches: {e}"))
+    })
+}
+
+fn export_program_py(
+    py: Python<'_>,
+    data: &[u8],
+    name: &str,
+    format: &str,
+    max_functions: usize,
+    timeout_ms: u64,
+) -> PyResult<Py<PyAny>> {
+    let format: crate::export::ExportFormat = format
+        .parse()
+        .map_err(pyo3::exceptions::PyValueError::new_err)?;
+    let budgets = crate::analysis::cfg::Budgets {
+        max_functions,
+        timeout_ms,
+        ..Default::default()
+    };
+    let program = crate::export::build_program(data, name, &budgets);
+    let out = crate::export::render(&program, format);
+    Ok(pyo3::types::PyBytes::new(py, &out).into())
+}
+
+/// Export a file's program model — sections, functions and basic blocks,
+/// strings, code/data xrefs and triage findings — as `format`: `json`,
+/// `ghidra-xml` (Ghidra's XML program format, which loads the section
+/// bytes from the file itself), `sarif` (findings as SARIF 2.1.0) or
+/// `protobuf` (`glaurung.program.v1.Program`). Returns the encoded bytes.
+#[pyfunction]
+#[pyo3(name = "export_program_path")]
+#[pyo3(signature = (path, format="json", max_functions=0usize, timeout_ms=1000u64, max_read_bytes=104_857_600u64, max_file_size=104_857_600u64))]
+fn export_program_path_py(
+    py: Python<'_>,
+    path: String,
+    format: &str,
+    max_functions: usize,
+    timeout_ms: u64,
+    max_read_bytes: u64,
+    max_file_size: u64,
+) -> PyResult<Py<PyAny>> {
+    let limit = std::cmp::min(max_read_bytes, max_file_size);
+    let data = crate::triage::io::IOUtils::read_file_with_limit(&path, limit)
+        .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
+    let name = std::path::Path::new(&path)
+        .file_name()
+        .map(|n| n.to_string_lossy().into_owned())
+        .unwrap_or_else(|| path.clone());
+    export_program_py(py, &data, &name, format, max_functions, timeout_ms)
+}
+
+/// Like `export_program_path`, for bytes already in memory; `name` is the
+/// file name recorded in the export.
+#[pyfunction]
+#[pyo3(name = "export_program_bytes")]
+#[pyo3(signature = (data, format="json", name="input", max_functions=0usize, timeout_ms=1000u64))]
+fn export_program_bytes_py(
+    py: Python<'_>,
+    data: &[u8],
+    format: &str,
+    name: &str,
+    max_functions: usize,
+    timeout_ms: u64,
+) -> PyResult<Py<PyAny>> {
+    export_program_py(py, data, name, format, max_functions, timeout_ms)
+}
@@ -263,15 +263,7 @@ fn similarity_top_k_py(
 #[pyfunction]
 #[pyo3(name = "demangle_text")]
 fn demangle_text_py(text: &str) -> Option<(String, String)> {
-    crate::demangle::demangle_one(text).map(|r| {
-        let flavor = match r.flavor {
-            crate::demangle::SymbolFlavor::Rust => "rust",
-            crate::demangle::SymbolFlavor::Itanium => "itanium",
-            crate::demangle::SymbolFlavor::Msvc => "msvc",
-            crate::demangle::SymbolFlavor::Unknown => "unknown",
-        };
-        (r.demangled, flavor.to_string())
-    })
+    crate::demangle::demangle_one(text).map(|r| (r.demangled, r.flavor.as_str().to_string()))
 }
 
 /// Demangle a list of symbols.
@@ -286,13 +278,7 @@ fn demangle_list_py(names: Vec<String>, max: usize) -> Vec<(String, String, Stri
             break;
         }
         if let Some(r) = crate::demangle::demangle_one(&n) {
-            let flavor = match r.flavor {
-                crate::demangle::SymbolFlavor::Rust => "rust",
-                crate::demangle::SymbolFlavor::Itanium => "itanium",
-                crate::demangle::SymbolFlavor::Msvc => "msvc",
-                crate::demangle::SymbolFlavor::Unknown => "unknown",
-            };
-            out.push((n, r.demangled, flavor.to_string()));
+            out.push((n, r.demangled, r.flavor.as_str().to_string()));
             count += 1;
         }
     }
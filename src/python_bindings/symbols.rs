@@ -109,6 +109,7 @@ fn list_symbols_py(
         export_names: Some(exports),
         demangled_import_names: None,
         demangled_export_names: None,
+        demangled: None,
         stripped: false, // TODO: detect this
         tls_used: false, // TODO: detect this
         tls_callback_count: None,
@@ -142,8 +143,21 @@ fn list_symbols_demangled_py(
         .map_err(|e| pyo3::exceptions::PyIOError::new_err(format!("{:?}", e)))?;
 
     // Call the actual function and convert tuple to SymbolSummary
-    let (all_syms, dyn_syms, imports, exports, libs) =
-        crate::symbols::list_symbols_demangled_py(path, max_read_bytes, max_file_size)?;
+    let (all_raw, dyn_raw, raw_imports, raw_exports, libs) =
+        crate::symbols::list_symbols_py(path, max_read_bytes, max_file_size)?;
+    let demangled = crate::symbols::demangled_names(raw_imports.iter().chain(&raw_exports));
+    let pretty = |names: &[String]| -> Vec<String> {
+        names
+            .iter()
+            .map(|s| crate::demangle::display_name(s))
+            .collect()
+    };
+    let (all_syms, dyn_syms, imports, exports) = (
+        pretty(&all_raw),
+        pretty(&dyn_raw),
+        pretty(&raw_imports),
+        pretty(&raw_exports),
+    );
 
     Ok(crate::symbols::SymbolSummary {
         imports_count: imports.len() as u32,
@@ -153,8 +167,9 @@ fn list_symbols_demangled_py(
         export_names: Some(exports),
         demangled_import_names: Some(all_syms), // Use demangled versions
         demangled_export_names: Some(dyn_syms), // Use demangled versions
-        stripped: false,                        // TODO: detect this
-        tls_used: false,                        // TODO: detect this
+        demangled,
+        stripped: false, // TODO: detect this
+        tls_used: false, // TODO: detect this
         tls_callback_count: None,
         tls_callback_vas: None,
         debug_info_present: false, // TODO: detect this
@@ -18,6 +18,9 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
     triage.add_class::<crate::core::triage::ConfidenceSignal>()?;
     triage.add_class::<crate::core::triage::ParserKind>()?;
     triage.add_class::<crate::core::triage::ParserResult>()?;
+    triage.add_class::<crate::core::triage::EvidenceSpan>()?;
+    triage.add_class::<crate::core::triage::Provenance>()?;
+    triage.add_class::<crate::core::triage::Finding>()?;
     triage.add_class::<crate::core::triage::EntropySummary>()?;
     triage.add_class::<crate::core::triage::EntropyAnalysis>()?;
     triage.add_class::<crate::core::triage::EntropyClass>()?;
@@ -27,8 +30,19 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
     triage.add_class::<crate::core::triage::StringsSummary>()?;
     triage.add_class::<crate::core::triage::IocSample>()?;
     triage.add_class::<crate::symbols::SymbolSummary>()?;
+    triage.add_class::<crate::symbols::DemangledName>()?;
     triage.add_class::<crate::core::triage::SimilaritySummary>()?;
     triage.add_class::<crate::triage::signing::SigningSummary>()?;
+    triage.add_class::<crate::triage::build_ids::BuildId>()?;
+    triage.add_class::<crate::triage::licenses::LicenseReport>()?;
+    triage.add_class::<crate::triage::licenses::LicenseMatch>()?;
+    triage.add_class::<crate::triage::licenses::CopyrightNotice>()?;
+    triage.add_class::<crate::triage::source_language::SourceLanguageGuess>()?;
+    triage.add_class::<crate::triage::go_info::GoBinaryInfo>()?;
+    triage.add_class::<crate::triage::unpack::UnpackReport>()?;
+    triage.add_class::<crate::triage::unpack::UnpackedChild>()?;
+    triage.add_class::<crate::core::triage::formats::PeDebugInfo>()?;
+    triage.add_class::<crate::symbols::analysis::pdb_path::PdbPathLeak>()?;
     triage.add_class::<crate::core::triage::PackerMatch>()?;
     triage.add_class::<crate::core::triage::ContainerChild>()?;
     triage.add_class::<crate::core::triage::ContainerMetadata>()?;
@@ -41,6 +55,17 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
     triage.add_class::<crate::core::triage::TriageVerdict>()?;
     triage.add_class::<crate::core::triage::TriagedArtifact>()?;
 
+    // Analyst annotations
+    triage.add_class::<crate::core::triage::Annotations>()?;
+    triage.add_class::<crate::core::triage::AnalystComment>()?;
+    triage.add_class::<crate::core::triage::AnalystVerdict>()?;
+    triage.add_class::<crate::core::triage::FunctionRename>()?;
+    triage.add_class::<crate::triage::annotations::AnnotationStore>()?;
+    triage.add_function(wrap_pyfunction!(
+        crate::triage::annotations::sha256_file_py,
+        &triage
+    )?)?;
+
     // Triage configuration classes
     triage.add_class::<crate::triage::config::TriageConfig>()?;
     triage.add_class::<crate::triage::config::IOConfig>()?;
@@ -53,6 +78,7 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
     triage.add_class::<crate::triage::config::SimilarityConfig>()?;
     triage.add_class::<crate::triage::config::HeaderConfig>()?;
     triage.add_class::<crate::triage::config::ParserConfig>()?;
+    triage.add_class::<crate::triage::config::FindingsConfig>()?;
 
     // Triage API functions
     triage.add_function(wrap_pyfunction!(
@@ -63,6 +89,10 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
         crate::triage::api::analyze_bytes_py,
         &triage
     )?)?;
+    triage.add_function(wrap_pyfunction!(
+        crate::triage::migrate::migrate_report_py,
+        &triage
+    )?)?;
 
     // Back-compat: symbols helpers under triage
     triage.add_function(wrap_pyfunction!(crate::symbols::list_symbols_py, &triage)?)?;
@@ -85,6 +115,27 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
         &triage
     )?)?;
 
+    // Architecture and load-base identification for headerless blobs
+    triage.add_function(wrap_pyfunction!(
+        crate::triage::arch_id::identify_arch_py,
+        &triage
+    )?)?;
+    triage.add_function(wrap_pyfunction!(
+        crate::triage::base_addr::infer_base_py,
+        &triage
+    )?)?;
+    triage.add_function(wrap_pyfunction!(detect_layout_py, &triage)?)?;
+
+    // Shared confidence model
+    triage.add_function(wrap_pyfunction!(confidence_threshold_py, &triage)?)?;
+    triage.add_function(wrap_pyfunction!(confidence_band_py, &triage)?)?;
+
+    // Finding severity
+    triage.add_function(wrap_pyfunction!(
+        crate::core::triage::severity::parse_severity_py,
+        &triage
+    )?)?;
+
     // Language detection helper for debugging
     triage.add_function(wrap_pyfunction!(language_detection_py, &triage)?)?;
 
@@ -101,6 +152,37 @@ pub fn register_triage_bindings(py: Python<'_>, m: &Bound<'_, PyModule>) -> PyRe
     Ok(())
 }
 
+/// Parse a confidence threshold: a number in [0, 1] or a band name
+/// ("speculative", "low", "medium", "high", "certain").
+#[pyfunction]
+#[pyo3(name = "confidence_threshold")]
+fn confidence_threshold_py(spec: &str) -> PyResult<f32> {
+    crate::core::confidence::parse_threshold(spec).map_err(pyo3::exceptions::PyValueError::new_err)
+}
+
+/// Band name for a confidence value.
+#[pyfunction]
+#[pyo3(name = "confidence_band")]
+fn confidence_band_py(confidence: f32) -> &'static str {
+    crate::core::confidence::ConfidenceBand::of(confidence).label()
+}
+
+/// Probable byte order and word size of raw bytes as a dict, or None when
+/// the data holds too few non-zero words.
+#[pyfunction]
+#[pyo3(name = "detect_layout")]
+fn detect_layout_py(py: Python<'_>, data: Vec<u8>) -> PyResult<Option<PyObject>> {
+    let Some(l) = crate::triage::heuristics::layout::detect(&data) else {
+        return Ok(None);
+    };
+    let d = pyo3::types::PyDict::new(py);
+    d.set_item("endianness", l.endianness.to_string().to_lowercase())?;
+    d.set_item("endianness_confidence", l.endianness_confidence)?;
+    d.set_item("bits", l.bits)?;
+    d.set_item("bits_confidence", l.bits_confidence)?;
+    Ok(Some(d.into()))
+}
+
 /// Language detection helper for debugging.
 #[pyfunction]
 #[pyo3(name = "detect_language")]
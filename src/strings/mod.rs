@@ -11,12 +11,13 @@ pub mod detect_fast;
 pub mod metrics;
 pub mod normalize;
 pub mod patterns;
-mod scan;
+pub(crate) mod scan;
 pub mod search;
 pub mod similarity;
 
 pub use config::StringsConfig;
 
+use crate::cancel::CancellationToken;
 use crate::core::triage::{DetectedString, IocSample, StringsSummary};
 use crate::strings::detect::LanguageRouter;
 use crate::strings::search::{MatchKind, SearchBudget};
@@ -252,10 +253,18 @@ fn build_strings_summary(
 /// Produces counts, sampled strings (encoding + offsets), and aggregated
 /// language/script histograms when enabled.
 pub fn extract_summary(data: &[u8], cfg: &StringsConfig) -> StringsSummary {
-    let start = std::time::Instant::now();
+    extract_summary_with_cancel(data, cfg, &CancellationToken::new())
+}
 
+/// Like `extract_summary`, but the scan stops early once `cancel` fires and
+/// the summary covers only the bytes scanned so far.
+pub fn extract_summary_with_cancel(
+    data: &[u8],
+    cfg: &StringsConfig,
+    cancel: &CancellationToken,
+) -> StringsSummary {
     // Phase 1: scan encodings under byte/time limits
-    let scanned = scan::scan_strings(data, cfg, start);
+    let scanned = scan::scan_strings_with_cancel(data, cfg, cancel);
 
     // Phase 2: assemble sampled DetectedString set (bounded by max_samples)
     let mut detected_strings: Vec<DetectedString> = Vec::new();
@@ -1,6 +1,7 @@
 //! Bounded string scanners for ASCII and UTF-16 encodings.
 
 use super::StringsConfig;
+use crate::cancel::CancellationToken;
 
 /// Scanned strings and counts per encoding.
 pub struct ScannedStrings {
@@ -34,9 +35,22 @@ pub fn scan_strings(
     data: &[u8],
     cfg: &StringsConfig,
     _start: std::time::Instant,
+) -> ScannedStrings {
+    scan_strings_with_cancel(data, cfg, &CancellationToken::new())
+}
+
+/// Like `scan_strings`, but each scanner also stops once `cancel` fires,
+/// keeping the strings found so far.
+pub fn scan_strings_with_cancel(
+    data: &[u8],
+    cfg: &StringsConfig,
+    cancel: &CancellationToken,
 ) -> ScannedStrings {
     let mut out = ScannedStrings::new();
     let scan = &data[..data.len().min(cfg.max_scan_bytes)];
+    let expired = |start: std::time::Instant| {
+        start.elapsed().as_millis() as u64 > cfg.time_guard_ms || cancel.is_cancelled()
+    };
 
     // ASCII scanner with offsets
     {
@@ -44,8 +58,8 @@ pub fn scan_strings(
         let mut cur: Vec<u8> = Vec::new();
         let mut cur_offset: usize = 0;
         for (i, &b) in scan.iter().enumerate() {
-            if (i & 0x0FFF) == 0 && start.elapsed().as_millis() as u64 > cfg.time_guard_ms {
-                tracing::debug!("strings/ascii time budget exhausted at {} bytes", i);
+            if (i & 0x0FFF) == 0 && expired(start) {
+                tracing::debug!("strings/ascii stopped at {} bytes", i);
                 break;
             }
             if (b.is_ascii_graphic() || b == b'\t' || b == b' ') && b != 0x7f {
@@ -83,8 +97,8 @@ pub fn scan_strings(
         let mut run_has_non_ascii = false;
         let mut char_count = 0usize;
         while i < scan.len() {
-            if (i & 0x0FFF) == 0 && start.elapsed().as_millis() as u64 > cfg.time_guard_ms {
-                tracing::debug!("strings/utf8 time budget exhausted at {} bytes", i);
+            if (i & 0x0FFF) == 0 && expired(start) {
+                tracing::debug!("strings/utf8 stopped at {} bytes", i);
                 break;
             }
             let b0 = scan[i];
@@ -226,8 +240,8 @@ pub fn scan_strings(
         let mut run: Vec<u16> = Vec::new();
         let mut run_offset: usize = 0;
         for (i, chunk) in scan.chunks_exact(2).enumerate() {
-            if (i & 0x07FF) == 0 && start.elapsed().as_millis() as u64 > cfg.time_guard_ms {
-                tracing::debug!("strings/utf16le time budget exhausted at chunk {}", i);
+            if (i & 0x07FF) == 0 && expired(start) {
+                tracing::debug!("strings/utf16le stopped at chunk {}", i);
                 break;
             }
             let ch = u16::from_le_bytes([chunk[0], chunk[1]]);
@@ -272,8 +286,8 @@ pub fn scan_strings(
         let mut run: Vec<u16> = Vec::new();
         let mut run_offset: usize = 0;
         for (i, chunk) in scan.chunks_exact(2).enumerate() {
-            if (i & 0x07FF) == 0 && start.elapsed().as_millis() as u64 > cfg.time_guard_ms {
-                tracing::debug!("strings/utf16be time budget exhausted at chunk {}", i);
+            if (i & 0x07FF) == 0 && expired(start) {
+                tracing::debug!("strings/utf16be stopped at chunk {}", i);
                 break;
             }
             let ch = u16::from_be_bytes([chunk[0], chunk[1]]);
@@ -390,6 +404,16 @@ mod tests {
         );
     }
 
+    #[test]
+    fn cancelled_scan_stops_at_first_check() {
+        let data = b"Hello world!\x00Goodbye world!";
+        let cancel = CancellationToken::new();
+        cancel.cancel();
+        let out = scan_strings_with_cancel(data, &cfg_default(), &cancel);
+        assert_eq!(out.ascii_count, 0);
+        assert_eq!(out.utf16le_count, 0);
+    }
+
     #[test]
     fn respects_max_scan_bytes() {
         // Create 2MiB of 'A' so that limiting to 1MiB still produces exactly one long ASCII run
@@ -4,6 +4,8 @@ pub struct MachoEnv {
     pub rpaths: Vec<String>,
     pub minos: Option<String>,
     pub code_signature: bool,
+    /// LC_UUID payload, when present
+    pub uuid: Option<[u8; 16]>,
 }
 
 fn read_u32(data: &[u8], off: usize, le: bool) -> Option<u32> {
@@ -37,6 +39,7 @@ pub fn analyze_macho_env(data: &[u8]) -> Option<MachoEnv> {
     let mut rpaths: Vec<String> = Vec::new();
     let mut code_signature = false;
     let mut minos: Option<String> = None;
+    let mut uuid: Option<[u8; 16]> = None;
     for _ in 0..ncmds {
         if off + 8 > lc_end {
             break;
@@ -63,6 +66,9 @@ pub fn analyze_macho_env(data: &[u8]) -> Option<MachoEnv> {
                     }
                 }
             }
+            0x1b /* LC_UUID */ => {
+                uuid = data.get(off + 8..off + 24).and_then(|b| b.try_into().ok());
+            }
             0x1d /* LC_CODE_SIGNATURE */ => {
                 code_signature = true;
             }
@@ -82,5 +88,6 @@ pub fn analyze_macho_env(data: &[u8]) -> Option<MachoEnv> {
         rpaths,
         minos,
         code_signature,
+        uuid,
     })
 }
@@ -4,5 +4,6 @@ pub mod env;
 pub mod export;
 pub mod imphash;
 pub mod macho_env;
+pub mod pdb_path;
 pub mod pe_env;
 pub mod suspicious;
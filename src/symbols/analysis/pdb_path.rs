@@ -0,0 +1,152 @@
+//! Information leaked through the PDB path embedded in a PE.
+//!
+//! The linker records the absolute path the PDB was written to, so release
+//! binaries routinely carry the developer's account name, the build host's
+//! share names, CI agent layouts and the internal project tree.
+
+use serde::{Deserialize, Serialize};
+
+/// Profile directories under which the next component is an account name.
+const PROFILE_ROOTS: &[&str] = &["users", "documents and settings", "home"];
+
+/// Account names that identify nobody.
+const GENERIC_ACCOUNTS: &[&str] = &["public", "default", "all users", "default user"];
+
+/// Path fragments characteristic of CI build agents, and the system named.
+const BUILD_AGENT_MARKERS: &[(&str, &str)] = &[
+    ("/agent/_work/", "Azure Pipelines"),
+    ("/a/_work/", "Azure Pipelines"),
+    ("/runner/work/", "GitHub Actions"),
+    ("/jenkins/", "Jenkins"),
+    ("/workspace/", "Jenkins"),
+    ("/buildagent/work/", "TeamCity"),
+    ("/teamcity/", "TeamCity"),
+    ("/bamboo-agent-home/", "Bamboo"),
+    ("/buildbot/", "Buildbot"),
+    ("/gitlab-runner/", "GitLab CI"),
+    ("/builds/", "GitLab CI"),
+];
+
+/// One piece of information disclosed by a PDB path.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyo3::pyclass(get_all))]
+pub struct PdbPathLeak {
+    /// `username`, `host`, `build_agent` or `source_tree`.
+    pub kind: String,
+    /// The disclosed value (account, host, CI system, or directory).
+    pub value: String,
+}
+
+impl PdbPathLeak {
+    fn new(kind: &str, value: impl Into<String>) -> Self {
+        Self {
+            kind: kind.to_string(),
+            value: value.into(),
+        }
+    }
+}
+
+/// Split a Windows or POSIX path into (is_absolute, unc_host, components).
+fn split(path: &str) -> (bool, Option<&str>, Vec<&str>) {
+    let unc = path.starts_with("\\\\") || path.starts_with("//");
+    let body = if unc { &path[2..] } else { path };
+    let drive =
+        body.len() >= 2 && body.as_bytes()[1] == b':' && body.as_bytes()[0].is_ascii_alphabetic();
+    let absolute = unc || drive || body.starts_with(['\\', '/']);
+    let mut parts: Vec<&str> = body.split(['\\', '/']).filter(|p| !p.is_empty()).collect();
+    let host = if unc && !parts.is_empty() {
+        Some(parts.remove(0))
+    } else {
+        None
+    };
+    if drive && !parts.is_empty() {
+        parts.remove(0);
+    }
+    (absolute, host, parts)
+}
+
+/// Identify what the PDB path `path` discloses about where it was built.
+///
+/// Relative paths and bare file names disclose nothing and yield an empty
+/// result.
+pub fn analyze_pdb_path(path: &str) -> Vec<PdbPathLeak> {
+    let (absolute, host, parts) = split(path.trim());
+    let mut out = Vec::new();
+    if !absolute {
+        return out;
+    }
+    if let Some(h) = host {
+        out.push(PdbPathLeak::new("host", h));
+    }
+    let lower: Vec<String> = parts.iter().map(|p| p.to_ascii_lowercase()).collect();
+    if let Some(i) = lower
+        .iter()
+        .position(|p| PROFILE_ROOTS.contains(&p.as_str()))
+    {
+        if let Some(user) = parts.get(i + 1).filter(|_| i + 2 < parts.len()) {
+            if !GENERIC_ACCOUNTS.contains(&lower[i + 1].as_str()) {
+                out.push(PdbPathLeak::new("username", *user));
+            }
+        }
+    }
+    let normalized = format!("/{}/", lower.join("/"));
+    if let Some((_, system)) = BUILD_AGENT_MARKERS
+        .iter()
+        .find(|(marker, _)| normalized.contains(marker))
+    {
+        out.push(PdbPathLeak::new("build_agent", *system));
+    }
+    // Directory the PDB was written to, minus the file name.
+    if parts.len() >= 2 {
+        let sep = if path.contains('\\') { "\\" } else { "/" };
+        out.push(PdbPathLeak::new(
+            "source_tree",
+            parts[..parts.len() - 1].join(sep),
+        ));
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn kinds(path: &str) -> Vec<(String, String)> {
+        analyze_pdb_path(path)
+            .into_iter()
+            .map(|l| (l.kind, l.value))
+            .collect()
+    }
+
+    #[test]
+    fn windows_profile_path_leaks_user_and_tree() {
+        let k = kinds(r"C:\Users\jdoe\source\repos\Acme\Launcher\x64\Release\launcher.pdb");
+        assert_eq!(k[0], ("username".into(), "jdoe".into()));
+        assert_eq!(
+            k[1],
+            (
+                "source_tree".into(),
+                r"Users\jdoe\source\repos\Acme\Launcher\x64\Release".into()
+            )
+        );
+    }
+
+    #[test]
+    fn unc_and_ci_paths() {
+        let k = kinds(r"\\buildsrv01\drops\proj\bin\app.pdb");
+        assert_eq!(k[0], ("host".into(), "buildsrv01".into()));
+        let k = kinds(r"D:\a\_work\1\s\out\Release\tool.pdb");
+        assert!(k.contains(&("build_agent".into(), "Azure Pipelines".into())));
+        let k = kinds("/home/builder/project/obj/app.pdb");
+        assert_eq!(k[0], ("username".into(), "builder".into()));
+    }
+
+    #[test]
+    fn bare_names_and_generic_accounts_leak_nothing_personal() {
+        assert!(analyze_pdb_path("ntkrnlmp.pdb").is_empty());
+        assert!(analyze_pdb_path(r"obj\Release\app.pdb").is_empty());
+        assert!(kinds(r"C:\Users\Public\app.pdb")
+            .iter()
+            .all(|(k, _)| k != "username"));
+    }
+}
@@ -132,6 +132,104 @@ pub fn detect_suspicious_imports(names: &[String], max_out: usize) -> Vec<String
     out
 }
 
+/// Managed members (lowercase `Namespace.Type::Member`) that load or invoke
+/// code at run time. A trailing `.` matches every member of a namespace.
+const SUSPICIOUS_MANAGED_APIS: &[&str] = &[
+    // Loading assemblies from bytes or paths
+    "system.reflection.assembly::load",
+    "system.reflection.assembly::loadfrom",
+    "system.reflection.assembly::loadfile",
+    "system.reflection.assembly::unsafeloadfrom",
+    "system.appdomain::load",
+    // Late-bound construction and invocation
+    "system.activator::createinstance",
+    "system.reflection.methodbase::invoke",
+    "system.type::invokemember",
+    // Calling native code through raw pointers
+    "system.runtime.interopservices.marshal::getdelegateforfunctionpointer",
+    // Emitting code
+    "system.reflection.emit.",
+];
+
+/// Detect reflection-loading and code-emitting calls from managed member
+/// names as `CilMetadata::token_name` renders them. Generic arguments are
+/// ignored. Returns a deduplicated, sorted, lowercase list limited to
+/// `max_out`.
+pub fn detect_suspicious_managed_calls(names: &[String], max_out: usize) -> Vec<String> {
+    let mut out = Vec::new();
+    let mut seen = HashSet::new();
+    for n in names {
+        let base = n.split('<').next().unwrap_or(n).to_ascii_lowercase();
+        let hit = SUSPICIOUS_MANAGED_APIS
+            .iter()
+            .any(|api| match api.strip_suffix('.') {
+                Some(ns) => base.starts_with(ns) && base[ns.len()..].starts_with('.'),
+                None => base == *api,
+            });
+        if hit && seen.insert(base.clone()) {
+            out.push(base);
+            if out.len() >= max_out {
+                break;
+            }
+        }
+    }
+    out.sort();
+    out
+}
+
+/// JVM methods (`internal/Name.method`) that launch processes, load code,
+/// or reach native code. A trailing `.` matches every method of a class.
+const SUSPICIOUS_JAVA_APIS: &[&str] = &[
+    // Process execution
+    "java/lang/Runtime.exec",
+    "java/lang/ProcessBuilder.start",
+    // Loading classes from URLs, bytes, or by name
+    "java/net/URLClassLoader.",
+    "java/lang/ClassLoader.defineClass",
+    "java/security/SecureClassLoader.defineClass",
+    "java/lang/invoke/MethodHandles$Lookup.defineClass",
+    "java/lang/invoke/MethodHandles$Lookup.defineHiddenClass",
+    "sun/misc/Unsafe.defineClass",
+    "sun/misc/Unsafe.defineAnonymousClass",
+    "java/lang/Class.forName",
+    // Reflective invocation
+    "java/lang/reflect/Method.invoke",
+    "java/lang/reflect/Constructor.newInstance",
+    // Native libraries
+    "java/lang/System.load",
+    "java/lang/System.loadLibrary",
+    "java/lang/Runtime.load",
+    "java/lang/Runtime.loadLibrary",
+    // Script evaluation
+    "javax/script/ScriptEngine.eval",
+];
+
+/// Detect process-launching, class-loading, and reflective calls from JVM
+/// method references as `java_bytecode::call_graph` names them
+/// (`owner.name:descriptor`). Descriptors are ignored. Returns a
+/// deduplicated, sorted `owner.name` list limited to `max_out`.
+pub fn detect_suspicious_java_calls(names: &[String], max_out: usize) -> Vec<String> {
+    let mut out = Vec::new();
+    let mut seen = HashSet::new();
+    for n in names {
+        let base = n.split(':').next().unwrap_or(n);
+        let hit = SUSPICIOUS_JAVA_APIS
+            .iter()
+            .any(|api| match api.strip_suffix('.') {
+                Some(class) => base.strip_prefix(class).is_some_and(|m| m.starts_with('.')),
+                None => base == *api,
+            });
+        if hit && seen.insert(base.to_string()) {
+            out.push(base.to_string());
+            if out.len() >= max_out {
+                break;
+            }
+        }
+    }
+    out.sort();
+    out
+}
+
 /// Replace or extend the extra suspicious API set.
 pub fn set_extra_apis<I: IntoIterator<Item = String>>(iter: I, clear: bool) -> usize {
     let mut guard = EXTRA_APIS.write().expect("lock EXTRA_APIS");
@@ -230,6 +328,45 @@ mod tests {
         assert_eq!(v.len(), 2);
     }
 
+    #[test]
+    fn detect_suspicious_managed() {
+        let names = vec![
+            "System.Reflection.Assembly::Load".to_string(),
+            "System.Activator::CreateInstance<Plugin>".to_string(),
+            "System.Reflection.Emit.ILGenerator::Emit".to_string(),
+            "System.Reflection.Assembly::GetName".to_string(),
+            "System.Console::WriteLine".to_string(),
+        ];
+        let v = detect_suspicious_managed_calls(&names, 10);
+        assert_eq!(
+            v,
+            vec![
+                "system.activator::createinstance".to_string(),
+                "system.reflection.assembly::load".to_string(),
+                "system.reflection.emit.ilgenerator::emit".to_string(),
+            ]
+        );
+    }
+
+    #[test]
+    fn detect_suspicious_java() {
+        let names = vec![
+            "java/lang/Runtime.exec:([Ljava/lang/String;)Ljava/lang/Process;".to_string(),
+            "java/net/URLClassLoader.<init>:([Ljava/net/URL;)V".to_string(),
+            "java/lang/Runtime.getRuntime:()Ljava/lang/Runtime;".to_string(),
+            "java/lang/System.loadLibraryX:()V".to_string(),
+            "java/net/URLClassLoaderX.<init>:()V".to_string(),
+        ];
+        let v = detect_suspicious_java_calls(&names, 10);
+        assert_eq!(
+            v,
+            vec![
+                "java/lang/Runtime.exec".to_string(),
+                "java/net/URLClassLoader.<init>".to_string(),
+            ]
+        );
+    }
+
     #[test]
     fn extra_apis_are_detected() {
         set_extra_apis(vec!["very_suspicious".to_string()], true);
@@ -423,6 +423,7 @@ pub fn summarize_elf(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
             Some(v)
         }
     };
+    let demangled = super::demangled_names(import_names.iter().chain(&export_names));
 
     SymbolSummary {
         imports_count: (import_names.len() as u32).min(caps.max_imports),
@@ -440,6 +441,7 @@ pub fn summarize_elf(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
         },
         demangled_import_names,
         demangled_export_names,
+        demangled,
         stripped,
         tls_used,
         tls_callback_count: None,
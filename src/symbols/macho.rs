@@ -251,6 +251,7 @@ pub fn summarize_macho(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
             Some(v)
         }
     };
+    let demangled = super::demangled_names(import_names.iter().chain(&export_names));
 
     SymbolSummary {
         imports_count,
@@ -268,6 +269,7 @@ pub fn summarize_macho(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
         },
         demangled_import_names,
         demangled_export_names,
+        demangled,
         stripped,
         tls_used: false,
         tls_callback_count: None,
@@ -17,7 +17,31 @@ pub mod pe;
 pub mod types;
 
 // Re-export core types
-pub use types::{BudgetCaps, SymbolBinding, SymbolInfo, SymbolSummary, SymbolType};
+pub use types::{BudgetCaps, DemangledName, SymbolBinding, SymbolInfo, SymbolSummary, SymbolType};
+
+/// Pair every name that demangles with its readable form, deduplicated
+/// in first-seen order; `None` if nothing demangles.
+pub fn demangled_names<'a>(
+    names: impl IntoIterator<Item = &'a String>,
+) -> Option<Vec<DemangledName>> {
+    let mut seen = std::collections::HashSet::new();
+    let out: Vec<DemangledName> = names
+        .into_iter()
+        .filter(|n| seen.insert(n.as_str()))
+        .filter_map(|n| crate::demangle::demangle_one(n))
+        .filter(|r| r.demangled != r.original)
+        .map(|r| DemangledName {
+            name: r.original,
+            demangled: r.demangled,
+            flavor: r.flavor.as_str().to_string(),
+        })
+        .collect();
+    if out.is_empty() {
+        None
+    } else {
+        Some(out)
+    }
+}
 
 /// Main entry point for symbol extraction with format detection
 pub fn extract_symbols(data: &[u8], format: Format, caps: &BudgetCaps) -> Option<SymbolSummary> {
@@ -193,43 +217,17 @@ pub fn list_symbols_demangled_py(
 )> {
     let (all_syms, dyn_syms, imports, exports, libs) =
         list_symbols_py(path, max_read_bytes, max_file_size)?;
-    let mut demangled_all = Vec::with_capacity(all_syms.len());
-    for s in &all_syms {
-        if let Some(r) = crate::demangle::demangle_one(s) {
-            demangled_all.push(r.demangled);
-        } else {
-            demangled_all.push(s.clone());
-        }
-    }
-    let mut demangled_dyn = Vec::with_capacity(dyn_syms.len());
-    for s in &dyn_syms {
-        if let Some(r) = crate::demangle::demangle_one(s) {
-            demangled_dyn.push(r.demangled);
-        } else {
-            demangled_dyn.push(s.clone());
-        }
-    }
-    let mut demangled_imports = Vec::with_capacity(imports.len());
-    for s in &imports {
-        if let Some(r) = crate::demangle::demangle_one(s) {
-            demangled_imports.push(r.demangled);
-        } else {
-            demangled_imports.push(s.clone());
-        }
-    }
-    let mut demangled_exports = Vec::with_capacity(exports.len());
-    for s in &exports {
-        if let Some(r) = crate::demangle::demangle_one(s) {
-            demangled_exports.push(r.demangled);
-        } else {
-            demangled_exports.push(s.clone());
-        }
-    }
+    let pretty = |names: Vec<String>| -> Vec<String> {
+        names
+            .iter()
+            .map(|s| crate::demangle::display_name(s))
+            .collect()
+    };
     Ok((
-        demangled_all,
-        demangled_dyn,
-        demangled_imports,
-        demangled_exports,
+        pretty(all_syms),
+        pretty(dyn_syms),
+        pretty(imports),
+        pretty(exports),
         libs,
     ))
 }
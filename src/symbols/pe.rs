@@ -1,6 +1,7 @@
 //! PE (Portable Executable) symbol extraction
 
 use super::types::{BudgetCaps, SymbolSummary};
+use crate::analysis::cil_metadata::CilMetadata;
 use crate::symbols::analysis::suspicious;
 
 // Minimal PE header parsing for counts under strict bounds
@@ -404,10 +405,20 @@ pub fn summarize_pe(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
         }
     }
 
-    let suspicious_list = if import_names.is_empty() {
-        None
-    } else {
-        let v = suspicious::detect_suspicious_imports(&import_names, 64);
+    let suspicious_list = {
+        let mut v = suspicious::detect_suspicious_imports(&import_names, 64);
+        // .NET assemblies import only mscoree; native APIs arrive through
+        // P/Invoke and loaded code through reflection.
+        if let Ok(md) = CilMetadata::parse(data) {
+            let entries: Vec<String> = md.pinvokes().into_iter().map(|p| p.entry).collect();
+            v.extend(suspicious::detect_suspicious_imports(&entries, 64));
+            v.extend(suspicious::detect_suspicious_managed_calls(
+                &md.member_refs(),
+                64,
+            ));
+            v.sort();
+            v.dedup();
+        }
         if v.is_empty() {
             None
         } else {
@@ -444,6 +455,7 @@ pub fn summarize_pe(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
             Some(v)
         }
     };
+    let demangled = super::demangled_names(import_names.iter().chain(&export_names));
 
     // ImageBase for VA->RVA conversion
     let image_base: u64 = if is_pe32_plus {
@@ -470,6 +482,7 @@ pub fn summarize_pe(data: &[u8], caps: &BudgetCaps) -> SymbolSummary {
         },
         demangled_import_names,
         demangled_export_names,
+        demangled,
         stripped,
         tls_used,
         tls_callback_count: {
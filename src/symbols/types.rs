@@ -25,6 +25,16 @@ impl Default for BudgetCaps {
     }
 }
 
+/// A mangled symbol name with its readable form.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct DemangledName {
+    pub name: String,
+    pub demangled: String,
+    /// Mangling scheme: `rust`, `itanium`, `msvc` or `swift`.
+    pub flavor: String,
+}
+
 /// Summary of symbols extracted from a binary
 #[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
 #[cfg_attr(feature = "python-ext", pyclass)]
@@ -38,6 +48,10 @@ pub struct SymbolSummary {
     pub demangled_import_names: Option<Vec<String>>,
     /// Demangled variants of export names, when applicable
     pub demangled_export_names: Option<Vec<String>>,
+    /// Import and export names that demangle, paired with their readable
+    /// form; the raw names stay in `import_names` / `export_names`.
+    #[serde(default)]
+    pub demangled: Option<Vec<DemangledName>>,
     pub stripped: bool,
     pub tls_used: bool,
     /// Number of TLS callbacks if enumerated (PE-specific)
@@ -110,6 +124,7 @@ impl SymbolSummary {
             export_names,
             demangled_import_names: None,
             demangled_export_names: None,
+            demangled: None,
             stripped,
             tls_used,
             tls_callback_count: None,
@@ -162,6 +177,10 @@ impl SymbolSummary {
     fn demangled_export_names(&self) -> Option<Vec<String>> {
         self.demangled_export_names.clone()
     }
+    #[getter]
+    fn demangled(&self) -> Option<Vec<DemangledName>> {
+        self.demangled.clone()
+    }
 
     #[getter]
     fn stripped(&self) -> bool {
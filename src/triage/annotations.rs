@@ -0,0 +1,337 @@
+//! On-disk store for analyst annotations.
+//!
+//! One JSON document per artifact, sharded by digest prefix:
+//! `<root>/<sha[0..2]>/<sha>.json`. The layout lets annotations sit next to
+//! stored reports (or in a shared directory used by several analysts) and
+//! be merged by content hash into any later triage of the same bytes.
+//! Writes go through a temporary file and a rename, so readers never see a
+//! partial document; concurrent writers to the same digest are
+//! last-writer-wins.
+
+use crate::core::triage::{Annotations, TriagedArtifact};
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use sha2::{Digest, Sha256};
+use std::fs;
+use std::io::{self, Read, Write};
+use std::path::{Path, PathBuf};
+
+/// Lower-case hex SHA-256 of `data`.
+pub fn sha256_hex(data: &[u8]) -> String {
+    format!("{:x}", Sha256::digest(data))
+}
+
+/// Lower-case hex SHA-256 of the file at `path`, streamed.
+pub fn sha256_file<P: AsRef<Path>>(path: P) -> io::Result<String> {
+    let mut f = fs::File::open(path)?;
+    let mut hasher = Sha256::new();
+    let mut buf = vec![0u8; 64 * 1024];
+    loop {
+        let n = f.read(&mut buf)?;
+        if n == 0 {
+            break;
+        }
+        hasher.update(&buf[..n]);
+    }
+    Ok(format!("{:x}", hasher.finalize()))
+}
+
+fn normalize_digest(sha256: &str) -> io::Result<String> {
+    let d = sha256.trim().to_ascii_lowercase();
+    if d.len() == 64 && d.bytes().all(|b| b.is_ascii_hexdigit()) {
+        Ok(d)
+    } else {
+        Err(io::Error::new(
+            io::ErrorKind::InvalidInput,
+            format!("not a SHA-256 hex digest: {:?}", sha256),
+        ))
+    }
+}
+
+/// Directory-backed annotation store.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct AnnotationStore {
+    root: PathBuf,
+}
+
+impl AnnotationStore {
+    /// Open (creating if needed) the store rooted at `root`.
+    pub fn open<P: AsRef<Path>>(root: P) -> io::Result<Self> {
+        let root = root.as_ref().to_path_buf();
+        fs::create_dir_all(&root)?;
+        Ok(Self { root })
+    }
+
+    pub fn root(&self) -> &Path {
+        &self.root
+    }
+
+    fn path_for(&self, digest: &str) -> PathBuf {
+        self.root
+            .join(&digest[..2])
+            .join(format!("{}.json", digest))
+    }
+
+    /// Annotations for `sha256`; empty if none were recorded.
+    pub fn load(&self, sha256: &str) -> io::Result<Annotations> {
+        let digest = normalize_digest(sha256)?;
+        match fs::read(self.path_for(&digest)) {
+            Ok(bytes) => serde_json::from_slice(&bytes)
+                .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e)),
+            Err(e) if e.kind() == io::ErrorKind::NotFound => Ok(Annotations::new(digest)),
+            Err(e) => Err(e),
+        }
+    }
+
+    /// Persist `annotations`; an empty set removes the stored document.
+    pub fn save(&self, annotations: &Annotations) -> io::Result<()> {
+        let digest = normalize_digest(&annotations.sha256)?;
+        let path = self.path_for(&digest);
+        if annotations.is_empty() {
+            return match fs::remove_file(&path) {
+                Err(e) if e.kind() != io::ErrorKind::NotFound => Err(e),
+                _ => Ok(()),
+            };
+        }
+        let dir = path.parent().expect("sharded path has a parent");
+        fs::create_dir_all(dir)?;
+        let json = serde_json::to_vec_pretty(annotations)
+            .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))?;
+        let tmp = dir.join(format!(".{}.{}.tmp", digest, std::process::id()));
+        {
+            let mut f = fs::File::create(&tmp)?;
+            f.write_all(&json)?;
+            f.sync_all()?;
+        }
+        fs::rename(&tmp, &path).inspect_err(|_| {
+            let _ = fs::remove_file(&tmp);
+        })
+    }
+
+    /// Load, modify with `edit`, and save the annotations for `sha256`.
+    pub fn update<F: FnOnce(&mut Annotations)>(
+        &self,
+        sha256: &str,
+        edit: F,
+    ) -> io::Result<Annotations> {
+        let mut ann = self.load(sha256)?;
+        edit(&mut ann);
+        self.save(&ann)?;
+        Ok(ann)
+    }
+
+    /// Digests with stored annotations, sorted.
+    pub fn list(&self) -> io::Result<Vec<String>> {
+        let mut out = Vec::new();
+        for shard in fs::read_dir(&self.root)? {
+            let shard = shard?;
+            if !shard.file_type()?.is_dir() {
+                continue;
+            }
+            for entry in fs::read_dir(shard.path())? {
+                let name = entry?.file_name();
+                let name = name.to_string_lossy();
+                if let Some(d) = name.strip_suffix(".json") {
+                    if let Ok(d) = normalize_digest(d) {
+                        out.push(d);
+                    }
+                }
+            }
+        }
+        out.sort();
+        Ok(out)
+    }
+
+    /// Attach stored annotations to `artifact`, keyed by `sha256` (or the
+    /// artifact's own digest). Returns true if any were found.
+    pub fn apply(&self, artifact: &mut TriagedArtifact, sha256: Option<&str>) -> io::Result<bool> {
+        let digest = match sha256.or(artifact.sha256.as_deref()) {
+            Some(d) => normalize_digest(d)?,
+            None => {
+                return Err(io::Error::new(
+                    io::ErrorKind::InvalidInput,
+                    "artifact has no sha256; pass the digest explicitly",
+                ))
+            }
+        };
+        let ann = self.load(&digest)?;
+        artifact.sha256 = Some(digest);
+        if ann.is_empty() {
+            artifact.annotations = None;
+            return Ok(false);
+        }
+        artifact.annotations = Some(ann);
+        Ok(true)
+    }
+}
+
+#[cfg(feature = "python-ext")]
+fn py_io_err(e: io::Error) -> PyErr {
+    match e.kind() {
+        io::ErrorKind::InvalidInput | io::ErrorKind::InvalidData => {
+            pyo3::exceptions::PyValueError::new_err(e.to_string())
+        }
+        _ => pyo3::exceptions::PyIOError::new_err(e.to_string()),
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl AnnotationStore {
+    #[new]
+    fn new_py(root: std::path::PathBuf) -> PyResult<Self> {
+        Self::open(root).map_err(py_io_err)
+    }
+
+    #[getter]
+    #[pyo3(name = "root")]
+    fn root_py(&self) -> String {
+        self.root.to_string_lossy().into_owned()
+    }
+
+    /// Annotations for `sha256` (empty if none).
+    #[pyo3(name = "load")]
+    fn load_py(&self, sha256: &str) -> PyResult<Annotations> {
+        self.load(sha256).map_err(py_io_err)
+    }
+
+    #[pyo3(name = "add_tag")]
+    fn add_tag_py(&self, sha256: &str, tag: String) -> PyResult<Annotations> {
+        self.update(sha256, |a| {
+            a.add_tag(tag);
+        })
+        .map_err(py_io_err)
+    }
+
+    #[pyo3(name = "remove_tag")]
+    fn remove_tag_py(&self, sha256: &str, tag: &str) -> PyResult<Annotations> {
+        self.update(sha256, |a| {
+            a.remove_tag(tag);
+        })
+        .map_err(py_io_err)
+    }
+
+    #[pyo3(name = "add_comment", signature = (sha256, text, address=None, author=None))]
+    fn add_comment_py(
+        &self,
+        sha256: &str,
+        text: String,
+        address: Option<u64>,
+        author: Option<String>,
+    ) -> PyResult<Annotations> {
+        self.update(sha256, |a| a.add_comment(text, address, author))
+            .map_err(py_io_err)
+    }
+
+    /// Set the verdict; `label=None` clears it.
+    #[pyo3(name = "set_verdict", signature = (sha256, label, note=None, author=None))]
+    fn set_verdict_py(
+        &self,
+        sha256: &str,
+        label: Option<String>,
+        note: Option<String>,
+        author: Option<String>,
+    ) -> PyResult<Annotations> {
+        self.update(sha256, |a| a.set_verdict(label, note, author))
+            .map_err(py_io_err)
+    }
+
+    #[pyo3(name = "rename_function", signature = (sha256, address, name, author=None))]
+    fn rename_function_py(
+        &self,
+        sha256: &str,
+        address: u64,
+        name: String,
+        author: Option<String>,
+    ) -> PyResult<Annotations> {
+        self.update(sha256, |a| a.rename_function(address, name, author))
+            .map_err(py_io_err)
+    }
+
+    /// Digests with stored annotations.
+    #[pyo3(name = "list")]
+    fn list_py(&self) -> PyResult<Vec<String>> {
+        self.list().map_err(py_io_err)
+    }
+
+    /// Copy of `artifact` with stored annotations attached. The digest is
+    /// taken from `sha256`, the artifact, or by hashing `artifact.path`.
+    #[pyo3(name = "apply", signature = (artifact, sha256=None))]
+    fn apply_py(
+        &self,
+        artifact: &TriagedArtifact,
+        sha256: Option<String>,
+    ) -> PyResult<TriagedArtifact> {
+        let mut art = artifact.clone();
+        let digest = match sha256.or_else(|| art.sha256.clone()) {
+            Some(d) => d,
+            None => sha256_file(&art.path).map_err(py_io_err)?,
+        };
+        self.apply(&mut art, Some(&digest)).map_err(py_io_err)?;
+        Ok(art)
+    }
+
+    fn __repr__(&self) -> String {
+        format!("AnnotationStore(root={:?})", self.root)
+    }
+}
+
+/// SHA-256 of a file, as used to key annotations.
+#[cfg(feature = "python-ext")]
+#[pyfunction]
+#[pyo3(name = "sha256_file")]
+pub fn sha256_file_py(path: std::path::PathBuf) -> PyResult<String> {
+    sha256_file(path).map_err(py_io_err)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::triage::api::analyze_bytes;
+    use crate::triage::io::IOLimits;
+
+    #[test]
+    fn annotations_round_trip_and_merge_into_reanalysis() {
+        let dir = tempfile::tempdir().unwrap();
+        let store = AnnotationStore::open(dir.path()).unwrap();
+        let data = b"\x7fELF\x02\x01\x01\0\0\0\0\0\0\0\0\0".to_vec();
+        let digest = sha256_hex(&data);
+
+        store
+            .update(&digest, |a| {
+                a.add_tag("loader");
+                a.set_verdict(Some("malicious".into()), None, Some("ana".into()));
+                a.rename_function(0x401000, "unpack_stage2", None);
+            })
+            .unwrap();
+        assert_eq!(store.list().unwrap(), vec![digest.clone()]);
+
+        let mut art = analyze_bytes(&data, &IOLimits::default()).unwrap();
+        assert!(store.apply(&mut art, Some(&digest.to_uppercase())).unwrap());
+        let ann = art.annotations.as_ref().unwrap();
+        assert_eq!(ann.tags, ["loader"]);
+        assert_eq!(ann.function_name(0x401000), Some("unpack_stage2"));
+        assert_eq!(art.sha256.as_deref(), Some(digest.as_str()));
+
+        // Clearing everything removes the stored document.
+        store
+            .update(&digest, |a| {
+                a.remove_tag("loader");
+                a.set_verdict(None, None, None);
+                a.function_names.clear();
+            })
+            .unwrap();
+        assert!(store.list().unwrap().is_empty());
+        assert!(!store.apply(&mut art, None).unwrap());
+        assert!(art.annotations.is_none());
+    }
+
+    #[test]
+    fn rejects_malformed_digests() {
+        let dir = tempfile::tempdir().unwrap();
+        let store = AnnotationStore::open(dir.path()).unwrap();
+        let err = store.load("../../etc/passwd").unwrap_err();
+        assert_eq!(err.kind(), io::ErrorKind::InvalidInput);
+    }
+}
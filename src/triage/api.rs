@@ -1,32 +1,40 @@
+use crate::cancel::CancellationToken;
 use crate::core::binary::{Arch, Endianness, Format};
 use crate::core::disassembler::Disassembler;
-use crate::core::triage::formats::{FormatSpecificTriage, PeTriageInfo};
+use crate::core::triage::formats::{FormatSpecificTriage, PeDebugInfo, PeTriageInfo};
 use crate::core::triage::{
     Budgets, ContainerChild, EntropyAnalysis, EntropySummary, PackerMatch, SimilaritySummary,
     StringsSummary, TriageVerdict, TriagedArtifact,
 };
 use crate::core::triage::{TriageError, TriageErrorKind, TriageHint};
+use crate::events::{self, AnalysisEvent};
 
 use crate::symbols::SymbolSummary;
 
 use crate::strings::StringsConfig;
 use crate::symbols::{self, BudgetCaps};
+use crate::triage::arch_id;
+use crate::triage::build_ids;
 #[cfg(feature = "python-ext")]
 use crate::triage::config::TriageConfig;
 use crate::triage::config::{EntropyConfig, PackerConfig, SimilarityConfig};
 use crate::triage::entropy::analyze_entropy;
+use crate::triage::findings;
 use crate::triage::format_detection::{derive_format_from_hint, is_container_hint};
+use crate::triage::go_info;
 use crate::triage::headers;
-use crate::triage::heuristics::{architecture, endianness};
+use crate::triage::heuristics::{architecture, endianness, layout};
 use crate::triage::io::{
     IOLimits, SafeFileReader, MAX_ENTROPY_SIZE, MAX_HEADER_SIZE, MAX_SNIFF_SIZE,
 };
+use crate::triage::licenses;
 use crate::triage::packers::detect_packers;
 use crate::triage::parsers;
 use crate::triage::recurse::RecursionEngine;
 use crate::triage::score;
 use crate::triage::signing::SigningSummary;
 use crate::triage::sniffers::CombinedSniffer;
+use crate::triage::source_language;
 use chrono::Utc;
 #[cfg(feature = "python-ext")]
 use pyo3::prelude::*;
@@ -35,13 +43,37 @@ use std::path::Path;
 use std::time::Instant;
 use tracing::{debug, info};
 
+/// Where the disassembly preview should start: `(file offset, VA)`.
+///
+/// For ELF/PE/Mach-O images only executable mappings are decoded: the entry
+/// point if it is mapped executable, else the first executable region.
+/// Images with no executable mapping get no preview. Unparsed blobs are
+/// decoded from offset 0.
+fn preview_window(data: &[u8]) -> Option<(usize, u64)> {
+    use crate::core::image::BinaryImage;
+    let Ok(img) = crate::formats::object_image::ObjectImage::parse(data) else {
+        return Some((0, 0));
+    };
+    let map = img.address_map();
+    if let Some(entry) = img.entry().filter(|&va| map.is_executable(va)) {
+        if let Ok(off) = map.va_to_file_offset(entry) {
+            return Some((off as usize, entry));
+        }
+    }
+    map.executable_regions()
+        .find(|r| r.file_size > 0)
+        .map(|r| (r.file_offset as usize, r.va))
+}
+
 fn compute_disasm_preview(
     data: &[u8],
+    base_va: u64,
     arch_guesses: &[(Arch, f32)],
     e_guess: Endianness,
     max_instructions: usize,
     max_bytes: usize,
     max_time_ms: u64,
+    cancel: &CancellationToken,
 ) -> Option<Vec<String>> {
     use crate::core::disassembler::Architecture as DArch;
     let (barch, _conf) = arch_guesses.first().cloned()?;
@@ -50,7 +82,7 @@ fn compute_disasm_preview(
     let bits = darch.address_bits();
     let addr = crate::core::address::Address::new(
         crate::core::address::AddressKind::VA,
-        0,
+        base_va,
         bits,
         None,
         None,
@@ -64,7 +96,7 @@ fn compute_disasm_preview(
         if off >= limit {
             break;
         }
-        if t0.elapsed().as_millis() as u64 > max_time_ms {
+        if t0.elapsed().as_millis() as u64 > max_time_ms || cancel.is_cancelled() {
             break;
         }
         let cur = crate::core::address::Address::new(
@@ -151,6 +183,7 @@ fn extract_strings(
     strings_cfg: &StringsConfig,
     hints: &[TriageHint],
     entropy: Option<f64>,
+    cancel: &CancellationToken,
 ) -> Option<StringsSummary> {
     debug!(phase = "strings", "extract with language detection");
 
@@ -161,7 +194,7 @@ fn extract_strings(
         adj.min_length = adj.min_length.max(8);
     }
 
-    let s = crate::strings::extract_summary(heur_buf, &adj);
+    let s = crate::strings::extract_summary_with_cancel(heur_buf, &adj, cancel);
     if s.ascii_count == 0 && s.utf16le_count == 0 && s.utf16be_count == 0 {
         None
     } else {
@@ -305,6 +338,7 @@ fn perform_content_analysis(
     heur_buf: &[u8],
     path: &str,
     strings_cfg: &StringsConfig,
+    cancel: &CancellationToken,
 ) -> (
     Vec<TriageHint>,
     Vec<TriageError>,
@@ -332,7 +366,7 @@ fn perform_content_analysis(
     let entropy = Some(ea.summary.clone());
 
     // Phase 4: String extraction
-    let strings = extract_strings(heur_buf, strings_cfg, &hints, Some(entropy_overall));
+    let strings = extract_strings(heur_buf, strings_cfg, &hints, Some(entropy_overall), cancel);
 
     (
         hints,
@@ -356,6 +390,7 @@ fn perform_parser_discovery(
     hints: &[TriageHint],
     max_recursion_depth: usize,
     packer_cfg: &PackerConfig,
+    cancel: &CancellationToken,
 ) -> (
     Vec<crate::core::triage::ParserResult>,
     Option<Vec<ContainerChild>>,
@@ -364,11 +399,16 @@ fn perform_parser_discovery(
 ) {
     debug!(phase = "parsers", "structured parse probes");
     let parser_results = parsers::parse(heur_buf);
+    if cancel.is_cancelled() {
+        return (parser_results, None, 0, None);
+    }
     let (mut containers, rec_depth, _packers_placeholder) =
         discover_containers_and_packers(heur_buf, hints, max_recursion_depth);
 
     // Compute packers here with provided config
-    let packers = {
+    let packers = if cancel.is_cancelled() {
+        None
+    } else {
         let v = detect_packers(heur_buf, packer_cfg);
         if v.is_empty() {
             None
@@ -385,11 +425,48 @@ fn perform_parser_discovery(
     (parser_results, containers, rec_depth as usize, packers)
 }
 
+/// Summarize the PE debug directory: entry types, CodeView PDB path and what
+/// it leaks, POGO and Repro records.
+fn pe_debug_info(data: &[u8]) -> Option<PeDebugInfo> {
+    let pe = crate::formats::pe::PeParser::new(data).ok()?;
+    let dir = pe.debug_directory().ok()?;
+    if dir.entries.is_empty() {
+        return None;
+    }
+    let codeview = dir.codeview.as_ref();
+    Some(PeDebugInfo {
+        entries: dir
+            .entries
+            .iter()
+            .map(|e| crate::formats::pe::directories::debug_type_name(e.debug_type).to_string())
+            .collect(),
+        pdb_path: codeview.map(|cv| cv.pdb_path.clone()),
+        pdb_path_offset: codeview.and(dir.pdb_path_offset).map(|o| o as u64),
+        pdb_guid_age: codeview.map(|cv| cv.guid_age_key()),
+        pogo_signature: dir.pogo.as_ref().map(|p| p.signature.clone()),
+        pogo_sections: dir
+            .pogo
+            .iter()
+            .flat_map(|p| p.entries.iter().map(|e| e.name.clone()))
+            .collect(),
+        repro: dir.repro.is_some(),
+        repro_hash: dir
+            .repro
+            .as_ref()
+            .filter(|r| !r.hash.is_empty())
+            .map(|r| r.hash.iter().map(|b| format!("{b:02x}")).collect()),
+        leaks: codeview
+            .map(|cv| crate::symbols::analysis::pdb_path::analyze_pdb_path(&cv.pdb_path))
+            .unwrap_or_default(),
+    })
+}
+
 /// Perform format-specific analysis including symbols, overlay, similarity, and signing
 fn perform_format_analysis(
     heur_buf: &[u8],
     header_formats: &[Format],
     sim_cfg: &SimilarityConfig,
+    cancel: &CancellationToken,
 ) -> (
     Option<FormatSpecificTriage>,
     Option<SymbolSummary>,
@@ -400,22 +477,30 @@ fn perform_format_analysis(
     // Format-specific analysis
     let format_specific = if header_formats.first().copied() == Some(Format::PE) {
         let rich_header = crate::triage::rich_header::parse_rich_header(heur_buf);
+        let debug = pe_debug_info(heur_buf);
         Some(FormatSpecificTriage {
-            pe: Some(PeTriageInfo { rich_header }),
+            pe: Some(PeTriageInfo { rich_header, debug }),
             ..Default::default()
         })
     } else {
         None
     };
 
-    // Compute symbol summary, using heuristics buffer (bounded to MAX_ENTROPY_SIZE)
+    // Compute symbol summary, using heuristics buffer (bounded to MAX_ENTROPY_SIZE);
+    // the symbol walk's own time guard never outlives the token.
+    let mut caps = BudgetCaps::default();
+    if let Some(left) = cancel.remaining() {
+        caps.time_guard_ms = caps.time_guard_ms.min(left.as_millis() as u64);
+    }
     let symbols_sum = header_formats
         .first()
-        .map(|fmt| symbols::summarize_symbols(heur_buf, *fmt, &BudgetCaps::default()));
+        .filter(|_| !cancel.is_cancelled())
+        .map(|fmt| symbols::summarize_symbols(heur_buf, *fmt, &caps));
 
     // Detect overlay data if we have a recognized binary format
     let overlay = header_formats
         .first()
+        .filter(|_| !cancel.is_cancelled())
         .and_then(|fmt| crate::triage::overlay::detect_overlay(heur_buf, *fmt));
 
     // Compute similarity summary (CTPH for all; imphash for PE if available)
@@ -427,7 +512,7 @@ fn perform_format_analysis(
             None
         };
         // CTPH over bounded heuristics buffer, if enabled
-        let ctph = if sim_cfg.enable_ctph {
+        let ctph = if sim_cfg.enable_ctph && !cancel.is_cancelled() {
             let (w, d, p) = if sim_cfg.window_size == 0 || sim_cfg.digest_size == 0 {
                 if heur_buf.len() < 16 * 1024 {
                     (8usize, 4usize, 8u8)
@@ -540,7 +625,7 @@ fn build_and_finalize_artifact(
     };
 
     let prelim = TriagedArtifact::builder()
-        .with_schema_version("1.2")
+        .with_schema_version(crate::core::triage::TRIAGE_SCHEMA_VERSION)
         .with_id(id.clone())
         .with_path(path.clone())
         .with_size_bytes(size_bytes as u64)
@@ -636,6 +721,29 @@ fn build_and_finalize_artifact(
         .expect("All required fields are provided")
 }
 
+/// Whether the triage phase `phase` may run. The first refusal is recorded in
+/// `interrupted`; every later phase is refused as well.
+fn phase_allowed(
+    cancel: &CancellationToken,
+    phase: &str,
+    interrupted: &mut Option<String>,
+) -> bool {
+    if interrupted.is_some() {
+        return false;
+    }
+    match cancel.check() {
+        Ok(()) => true,
+        Err(e) => {
+            info!(phase, "triage interrupted: {}", e);
+            *interrupted = Some(format!(
+                "{} before {} phase; later phases skipped",
+                e, phase
+            ));
+            false
+        }
+    }
+}
+
 #[allow(clippy::too_many_arguments)]
 fn build_artifact_from_buffers(
     path: String,
@@ -651,13 +759,22 @@ fn build_artifact_from_buffers(
     strings_cfg: &StringsConfig,
     packer_cfg: &PackerConfig,
     sim_cfg: &SimilarityConfig,
+    cancel: &CancellationToken,
 ) -> TriagedArtifact {
     let t0 = Instant::now();
     let id = generate_id(None, size_bytes);
-    let span =
-        tracing::info_span!("triage", triage_id = %id, path = %path, size_bytes = size_bytes);
+    let file = crate::logging::file_span(&path);
+    let _f = file.enter();
+    let span = tracing::info_span!("triage", triage_id = %id, size_bytes = size_bytes);
     let _g = span.enter();
     info!("start");
+    events::emit(AnalysisEvent::AnalysisStarted {
+        path: &path,
+        size_bytes: size_bytes as u64,
+    });
+    events::emit(AnalysisEvent::BytesProcessed {
+        bytes: initial_bytes_read,
+    });
 
     // Perform initial content analysis
     let (
@@ -673,18 +790,57 @@ fn build_artifact_from_buffers(
         arch_guesses,
         entropy,
         strings,
-    ) = perform_content_analysis(sniff_buf, header_buf, heur_buf, &path, strings_cfg);
+    ) = perform_content_analysis(sniff_buf, header_buf, heur_buf, &path, strings_cfg, cancel);
+
+    // Later phases are skipped once the token fires; the first phase that
+    // did not run is recorded so the artifact is visibly incomplete.
+    let mut interrupted: Option<String> = None;
+
+    // Headerless blobs: the opcode classifier names both the ISA and the
+    // byte order, and outranks the byte-frequency guesses when it has a
+    // verdict; without one, the data-layout heuristic names the byte order.
+    // Code the classifier recognises is treated as executable below.
+    let raw_guesses = if verdicts.is_empty() && phase_allowed(cancel, "arch-id", &mut interrupted) {
+        arch_id::classify(heur_buf)
+    } else {
+        Vec::new()
+    };
+    let raw_layout = if verdicts.is_empty()
+        && raw_guesses.is_empty()
+        && phase_allowed(cancel, "layout", &mut interrupted)
+    {
+        layout::detect(heur_buf)
+    } else {
+        None
+    };
+    let (e_guess, e_conf, arch_guesses) = match (raw_guesses.first(), raw_layout) {
+        (Some(best), _) => (
+            best.endianness,
+            best.confidence as f64,
+            raw_guesses
+                .iter()
+                .take(3)
+                .map(|g| (g.arch, g.confidence))
+                .collect(),
+        ),
+        (None, Some(l)) => (l.endianness, l.endianness_confidence as f64, arch_guesses),
+        (None, None) => (e_guess, e_conf, arch_guesses),
+    };
 
     // Perform parser probes and container/packer discovery
     let (parser_results, containers, rec_depth, packers) =
-        perform_parser_discovery(heur_buf, &hints, max_recursion_depth, packer_cfg);
+        if phase_allowed(cancel, "parsers", &mut interrupted) {
+            perform_parser_discovery(heur_buf, &hints, max_recursion_depth, packer_cfg, cancel)
+        } else {
+            (Vec::new(), None, 0, None)
+        };
 
     // Phase 6: Error merging
     let container_labels: Vec<String> = containers
         .as_ref()
         .map(|v| v.iter().map(|c| c.type_name.clone()).collect())
         .unwrap_or_default();
-    let merged_errors_vec = merge_errors(
+    let mut merged_errors_vec = merge_errors(
         sniff_errors,
         header_errors,
         &hints,
@@ -697,24 +853,54 @@ fn build_artifact_from_buffers(
     .unwrap_or_default();
 
     // Phase 7: Artifact construction and scoring
-    let looks_exec =
-        !header_formats.is_empty() || hints.iter().any(|h| derive_format_from_hint(h).is_some());
+    let looks_exec = !header_formats.is_empty()
+        || hints.iter().any(|h| derive_format_from_hint(h).is_some())
+        || !raw_guesses.is_empty();
 
     // Optional disassembly preview (bounded, budgeted): only if likely executable
-    let disasm_preview = if looks_exec {
-        compute_disasm_preview(heur_buf, &arch_guesses, e_guess, 32, 512, 5)
+    let disasm_preview = if looks_exec && phase_allowed(cancel, "disasm", &mut interrupted) {
+        preview_window(heur_buf).and_then(|(off, va)| {
+            compute_disasm_preview(
+                heur_buf.get(off..).unwrap_or_default(),
+                va,
+                &arch_guesses,
+                e_guess,
+                32,
+                512,
+                5,
+                cancel,
+            )
+        })
     } else {
         None
     };
 
     // Perform format-specific analysis
     let (format_specific, symbols_sum, overlay, similarity, signing) =
-        perform_format_analysis(heur_buf, &header_formats, sim_cfg);
+        if phase_allowed(cancel, "format", &mut interrupted) {
+            perform_format_analysis(heur_buf, &header_formats, sim_cfg, cancel)
+        } else {
+            (None, None, None, None, None)
+        };
+    // Note a cancel that landed inside the format phase, the last one the
+    // artifact is built from.
+    if interrupted.is_none() {
+        if let Err(e) = cancel.check() {
+            interrupted = Some(format!("{} during format phase; results incomplete", e));
+        }
+    }
+    if let Some(msg) = &interrupted {
+        merged_errors_vec.push(TriageError::new(
+            TriageErrorKind::BudgetExceeded,
+            Some(msg.clone()),
+        ));
+    }
+    let recorded = interrupted.is_some();
 
     // Build and finalize the artifact
-    let art = build_and_finalize_artifact(
+    let mut art = build_and_finalize_artifact(
         id,
-        path,
+        path.clone(),
         size_bytes,
         t0,
         &hints,
@@ -743,6 +929,44 @@ fn build_artifact_from_buffers(
         disasm_preview,
     );
 
+    // Licenses before findings, which cite them
+    if phase_allowed(cancel, "licenses", &mut interrupted) {
+        art.licenses = Some(licenses::detect_licenses(heur_buf));
+    }
+    if phase_allowed(cancel, "source-language", &mut interrupted) {
+        art.source_language = source_language::classify(heur_buf);
+    }
+    if phase_allowed(cancel, "go", &mut interrupted) {
+        art.go = go_info::summarize(heur_buf);
+    }
+
+    // Attach provenance for each reported claim
+    let found = findings::collect_findings(heur_buf, &art);
+    for f in &found {
+        events::emit(AnalysisEvent::FindingEmitted {
+            source: "triage",
+            category: &f.category,
+        });
+    }
+    art.findings = Some(found);
+    if phase_allowed(cancel, "build-ids", &mut interrupted) {
+        art.build_ids = header_formats
+            .first()
+            .map(|fmt| build_ids::extract(heur_buf, *fmt));
+    }
+    // The artifact's errors were assembled before the steps above; a
+    // refusal among them is added to it here.
+    if let Some(msg) = interrupted.filter(|_| !recorded) {
+        art.errors
+            .get_or_insert_with(Vec::new)
+            .push(TriageError::new(TriageErrorKind::BudgetExceeded, Some(msg)));
+    }
+
+    events::emit(AnalysisEvent::AnalysisFinished {
+        path: &path,
+        elapsed_ms: t0.elapsed().as_millis() as u64,
+        errors: art.errors.as_ref().map_or(0, Vec::len),
+    });
     info!("complete");
     art
 }
@@ -825,6 +1049,28 @@ mod tests_inner {
         assert!(errs.is_empty());
     }
 
+    #[test]
+    fn cancelled_triage_skips_later_phases() {
+        let data = vec![0x90u8; 4096];
+        let cancel = CancellationToken::new();
+        cancel.cancel();
+        let art = analyze_bytes_with_cancel(&data, &IOLimits::default(), &cancel)
+            .expect("partial artifact");
+        assert!(art.disasm_preview.is_none());
+        assert!(art.errors.as_ref().is_some_and(|errs| errs
+            .iter()
+            .any(|e| e.kind == TriageErrorKind::BudgetExceeded)));
+        // A headerless blob reaches the opcode classifier first.
+        assert!(art.errors.as_ref().is_some_and(|errs| errs.iter().any(|e| e
+            .message
+            .as_deref()
+            .is_some_and(|m| m.contains("before arch-id phase")))));
+        assert!(art.licenses.is_none());
+        assert!(art.source_language.is_none());
+        assert!(art.go.is_none());
+        assert!(art.build_ids.is_none());
+    }
+
     #[test]
     fn budgets_are_tracked_in_analyze_bytes() {
         let data = vec![0u8; 32 * 1024];
@@ -858,6 +1104,33 @@ mod tests_inner {
     }
 }
 
+/// Token expiring after `timeout_ms`, or one that never fires.
+#[cfg(feature = "python-ext")]
+fn cancel_for_timeout(timeout_ms: Option<u64>) -> CancellationToken {
+    timeout_ms
+        .map(|ms| CancellationToken::with_timeout(std::time::Duration::from_millis(ms)))
+        .unwrap_or_default()
+}
+
+/// Children of `data`'s nested containers, when more than one level of
+/// recursion was requested. Children are triaged at depth 1, so this never
+/// re-enters itself.
+#[cfg(feature = "python-ext")]
+fn unpack_nested(
+    data: &[u8],
+    max_recursion_depth: usize,
+    cancel: &CancellationToken,
+) -> Option<crate::triage::unpack::UnpackReport> {
+    if max_recursion_depth <= 1 {
+        return None;
+    }
+    let limits = crate::triage::unpack::UnpackLimits {
+        max_depth: max_recursion_depth - 1,
+        ..Default::default()
+    };
+    Some(crate::triage::unpack::unpack(data, &limits, cancel))
+}
+
 #[cfg(feature = "python-ext")]
 #[pyfunction]
 #[pyo3(name = "analyze_path")]
@@ -873,7 +1146,8 @@ mod tests_inner {
     _enable_classification=true,
     _max_classify=200,
     _max_ioc_per_string=16,
-    _config=None
+    _config=None,
+    timeout_ms=None
 ))]
 pub fn analyze_path_py(
     path: String,
@@ -888,7 +1162,9 @@ pub fn analyze_path_py(
     _max_classify: usize,
     _max_ioc_per_string: usize,
     _config: Option<TriageConfig>,
+    timeout_ms: Option<u64>,
 ) -> PyResult<TriagedArtifact> {
+    let cancel = cancel_for_timeout(timeout_ms);
     let p = Path::new(&path);
     let limits = IOLimits {
         max_read_bytes: _max_read_bytes,
@@ -945,7 +1221,7 @@ pub fn analyze_path_py(
         .as_ref()
         .map(|c| c.similarity.clone())
         .unwrap_or_else(SimilarityConfig::default);
-    Ok(build_artifact_from_buffers(
+    let mut art = build_artifact_from_buffers(
         path,
         reader.size() as usize,
         &sniff,
@@ -959,7 +1235,18 @@ pub fn analyze_path_py(
         &strings_cfg,
         &packer_cfg,
         &sim_cfg,
-    ))
+        &cancel,
+    );
+    if let Some(c) = &_config {
+        art.apply_severity_overrides(&c.findings.severity_overrides);
+    }
+    if _max_recursion_depth > 1 {
+        let full = reader
+            .read_prefix(limits.max_read_bytes)
+            .map_err(|e| pyo3::exceptions::PyValueError::new_err(format!("{}", e)))?;
+        art.unpacked = unpack_nested(&full, _max_recursion_depth, &cancel);
+    }
+    Ok(art)
 }
 
 #[cfg(feature = "python-ext")]
@@ -976,7 +1263,8 @@ pub fn analyze_path_py(
     enable_classification=true,
     max_classify=200,
     max_ioc_per_string=16,
-    config=None
+    config=None,
+    timeout_ms=None
 ))]
 pub fn analyze_bytes_py(
     data: Vec<u8>,
@@ -990,7 +1278,9 @@ pub fn analyze_bytes_py(
     max_classify: usize,
     max_ioc_per_string: usize,
     config: Option<TriageConfig>,
+    timeout_ms: Option<u64>,
 ) -> PyResult<TriagedArtifact> {
+    let cancel = cancel_for_timeout(timeout_ms);
     if data.is_empty() {
         return Err(pyo3::exceptions::PyValueError::new_err("Empty data"));
     }
@@ -1033,7 +1323,7 @@ pub fn analyze_bytes_py(
         .as_ref()
         .map(|c| c.similarity.clone())
         .unwrap_or_else(SimilarityConfig::default);
-    Ok(build_artifact_from_buffers(
+    let mut art = build_artifact_from_buffers(
         "<memory>".to_string(),
         data.len(),
         &data[..sniff_len],
@@ -1047,13 +1337,30 @@ pub fn analyze_bytes_py(
         &strings_cfg,
         &packer_cfg,
         &sim_cfg,
-    ))
+        &cancel,
+    );
+    if let Some(c) = &config {
+        art.apply_severity_overrides(&c.findings.severity_overrides);
+    }
+    let capped = &data[..data.len().min(max_read_bytes as usize)];
+    art.unpacked = unpack_nested(capped, max_recursion_depth, &cancel);
+    Ok(art)
 }
 
 /// Pure Rust API: analyze a file path with I/O limits.
 pub fn analyze_path<P: AsRef<Path>>(
     path: P,
     limits: &IOLimits,
+) -> std::io::Result<TriagedArtifact> {
+    analyze_path_with_cancel(path, limits, &CancellationToken::new())
+}
+
+/// Like `analyze_path`, but stops early once `cancel` fires. Phases that did
+/// not run are reported through a `BudgetExceeded` triage error.
+pub fn analyze_path_with_cancel<P: AsRef<Path>>(
+    path: P,
+    limits: &IOLimits,
+    cancel: &CancellationToken,
 ) -> std::io::Result<TriagedArtifact> {
     let p = path.as_ref();
     let mut reader = SafeFileReader::open(p, limits.clone())?;
@@ -1091,11 +1398,21 @@ pub fn analyze_path<P: AsRef<Path>>(
         &strings_cfg,
         &PackerConfig::default(),
         &SimilarityConfig::default(),
+        cancel,
     ))
 }
 
 /// Pure Rust API: analyze raw bytes with I/O limits (only used for budgets; limits.max_read_bytes bounds processing).
 pub fn analyze_bytes(data: &[u8], limits: &IOLimits) -> std::io::Result<TriagedArtifact> {
+    analyze_bytes_with_cancel(data, limits, &CancellationToken::new())
+}
+
+/// Like `analyze_bytes`, but stops early once `cancel` fires.
+pub fn analyze_bytes_with_cancel(
+    data: &[u8],
+    limits: &IOLimits,
+    cancel: &CancellationToken,
+) -> std::io::Result<TriagedArtifact> {
     if data.is_empty() {
         return Err(std::io::Error::new(
             std::io::ErrorKind::InvalidData,
@@ -1131,5 +1448,6 @@ pub fn analyze_bytes(data: &[u8], limits: &IOLimits) -> std::io::Result<TriagedA
         &strings_cfg,
         &PackerConfig::default(),
         &SimilarityConfig::default(),
+        cancel,
     ))
 }
@@ -0,0 +1,680 @@
+//! Statistical architecture identification for headerless code blobs.
+//!
+//! Firmware dumps, shellcode and carved regions arrive without a container
+//! header to name their CPU. In the spirit of cpu_rec, this module scores a
+//! blob against one opcode model per ISA and byte order and reports the
+//! candidates with confidence scores, so callers can configure a
+//! disassembler without the user naming an architecture.
+//!
+//! Each model is a table of opcode n-grams: masked instruction words for
+//! the fixed-width ISAs (plus 16/32-bit units for Thumb and RISC-V
+//! compressed code), and byte n-grams for x86. A pattern's contribution is
+//! `f * ln(f / p0)`, where `f` is its observed frequency per instruction
+//! unit and `p0` the frequency expected if the blob's own bytes were
+//! shuffled; the score is the information (in nats per unit) a model
+//! explains beyond that, so data that is not code (text, tables, noise)
+//! scores near zero under every model.
+//!
+//! Models covered:
+//! - x86 and x86-64
+//! - A32 (little- and big-endian) and Thumb-2 (little-endian)
+//! - AArch64 (little-endian)
+//! - MIPS32 and MIPS64, both byte orders
+//! - PowerPC and PowerPC64, both byte orders
+//! - RV32 and RV64 with the C extension
+
+use crate::core::binary::{Arch, Endianness};
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use Endianness::{Big, Little};
+
+/// Bytes of input considered; the statistics settle well before this.
+const MAX_SCAN: usize = 256 * 1024;
+
+/// Fewer non-padding units than this gives no verdict.
+const MIN_UNITS: usize = 64;
+
+/// Scores below this (nats per unit) are indistinguishable from noise.
+const MIN_SCORE: f64 = 0.25;
+
+/// A model scoring this much is fully trusted before sharing with rivals.
+const STRONG_SCORE: f64 = 1.0;
+
+/// Mean x86 instruction length used to express byte n-gram counts per
+/// instruction, comparable with the fixed-width models.
+const X86_UNIT: f64 = 4.0;
+
+/// One candidate architecture for a blob.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct ArchGuess {
+    pub arch: Arch,
+    pub endianness: Endianness,
+    /// A32 guesses only: the code is Thumb rather than ARM state.
+    pub thumb: bool,
+    /// Information explained by the model, in nats per instruction unit.
+    pub score: f32,
+    /// Share of the total score, damped for weak evidence, in [0, 1].
+    pub confidence: f32,
+}
+
+/// A masked instruction pattern over a 16- or 32-bit unit.
+struct Op {
+    wide: bool,
+    mask: u32,
+    value: u32,
+}
+
+const fn w(mask: u32, value: u32) -> Op {
+    Op {
+        wide: true,
+        mask,
+        value,
+    }
+}
+
+const fn h(mask: u32, value: u32) -> Op {
+    Op {
+        wide: false,
+        mask,
+        value,
+    }
+}
+
+/// How a model splits the blob into instruction units.
+#[derive(Clone, Copy)]
+enum Stream {
+    /// Aligned 32-bit words.
+    Fixed32(Endianness),
+    /// Little-endian halfwords; Thumb-2 prefixes join the next halfword.
+    Thumb,
+    /// Little-endian halfwords; low bits `11` join the next halfword.
+    RiscV,
+    /// Byte n-grams.
+    X86,
+}
+
+struct Model {
+    arch: Arch,
+    endianness: Endianness,
+    thumb: bool,
+    stream: Stream,
+    ops: &'static [&'static [Op]],
+    grams: &'static [&'static [&'static [u8]]],
+}
+
+const AARCH64: &[Op] = &[
+    w(0xffff_ffff, 0xd65f_03c0), // ret
+    w(0xffff_ffff, 0xd503_201f), // nop
+    w(0xfc00_0000, 0x9400_0000), // bl
+    w(0xfc00_0000, 0x1400_0000), // b
+    w(0xffc0_7fff, 0xa980_7bfd), // stp x29, x30, [sp, #-n]!
+    w(0xffc0_7fff, 0xa8c0_7bfd), // ldp x29, x30, [sp], #n
+    w(0xffff_ffff, 0x9100_03fd), // mov x29, sp
+    w(0x9f00_0000, 0x9000_0000), // adrp
+    w(0xffc0_0000, 0xf940_0000), // ldr xt, [xn, #imm]
+    w(0xffc0_0000, 0xf900_0000), // str xt, [xn, #imm]
+    w(0xff80_0000, 0x9100_0000), // add xd, xn, #imm
+    w(0xff80_0000, 0xd100_0000), // sub xd, xn, #imm
+    w(0x7e00_0000, 0x3400_0000), // cbz / cbnz
+    w(0xff00_0010, 0x5400_0000), // b.cond
+    w(0xffe0_ffe0, 0xaa00_03e0), // mov xd, xm
+    w(0xffe0_ffe0, 0x2a00_03e0), // mov wd, wm
+    w(0x7f80_0000, 0x5280_0000), // movz
+];
+
+const A32: &[Op] = &[
+    w(0xf000_0000, 0xe000_0000), // condition AL
+    w(0xffff_ffff, 0xe12f_ff1e), // bx lr
+    w(0xffff_4000, 0xe92d_4000), // push {..., lr}
+    w(0xffff_8000, 0xe8bd_8000), // pop {..., pc}
+    w(0xff00_0000, 0xeb00_0000), // bl
+    w(0xff00_0000, 0xea00_0000), // b
+    w(0xffff_0000, 0xe59f_0000), // ldr rd, [pc, #imm]
+    w(0xfff0_0000, 0xe590_0000), // ldr rd, [rn, #imm]
+    w(0xfff0_0000, 0xe580_0000), // str rd, [rn, #imm]
+    w(0xffff_0ff0, 0xe1a0_0000), // mov rd, rm
+    w(0xffff_0000, 0xe3a0_0000), // mov rd, #imm
+    w(0xfff0_0000, 0xe350_0000), // cmp rn, #imm
+    w(0xfff0_0000, 0xe280_0000), // add rd, rn, #imm
+    w(0xfff0_0000, 0xe240_0000), // sub rd, rn, #imm
+];
+
+const THUMB: &[Op] = &[
+    h(0xffff, 0x4770),           // bx lr
+    h(0xff00, 0xb500),           // push {..., lr}
+    h(0xff00, 0xbd00),           // pop {..., pc}
+    h(0xffff, 0xbf00),           // nop
+    h(0xf800, 0x4800),           // ldr rd, [pc, #imm]
+    h(0xf800, 0x6800),           // ldr rd, [rn, #imm]
+    h(0xf800, 0x6000),           // str rd, [rn, #imm]
+    h(0xf800, 0x2000),           // movs rd, #imm
+    h(0xf800, 0x2800),           // cmp rn, #imm
+    h(0xff00, 0x4600),           // mov rd, rm
+    h(0xff80, 0xb080),           // sub sp, #imm
+    h(0xff80, 0xb000),           // add sp, #imm
+    h(0xf000, 0xd000),           // b<cond>
+    w(0xf800_d000, 0xf000_d000), // bl
+    w(0xffff_4000, 0xe92d_4000), // push.w {..., lr}
+    w(0xffff_8000, 0xe8bd_8000), // pop.w {..., pc}
+];
+
+const MIPS_COMMON: &[Op] = &[
+    w(0xffff_ffff, 0x03e0_0008), // jr ra
+    w(0xffff_ffff, 0x0320_f809), // jalr t9
+    w(0xfc00_0000, 0x0c00_0000), // jal
+    w(0xffe0_0000, 0x3c00_0000), // lui
+    w(0xfc00_0000, 0x1000_0000), // beq
+    w(0xfc00_0000, 0x1400_0000), // bne
+    w(0xfc00_07ff, 0x0000_0021), // addu / move
+];
+
+const MIPS32: &[Op] = &[
+    w(0xffff_0000, 0x27bd_0000), // addiu sp, sp, imm
+    w(0xffff_0000, 0xafbf_0000), // sw ra, imm(sp)
+    w(0xffff_0000, 0x8fbf_0000), // lw ra, imm(sp)
+    w(0xffff_0000, 0x8f99_0000), // lw t9, imm(gp)
+    w(0xfc00_0000, 0x2400_0000), // addiu
+    w(0xfc00_0000, 0x8c00_0000), // lw
+    w(0xfc00_0000, 0xac00_0000), // sw
+];
+
+const MIPS64: &[Op] = &[
+    w(0xffff_0000, 0x67bd_0000), // daddiu sp, sp, imm
+    w(0xffff_0000, 0xffbf_0000), // sd ra, imm(sp)
+    w(0xffff_0000, 0xdfbf_0000), // ld ra, imm(sp)
+    w(0xffff_0000, 0xdf99_0000), // ld t9, imm(gp)
+    w(0xfc00_0000, 0x6400_0000), // daddiu
+    w(0xfc00_0000, 0xdc00_0000), // ld
+    w(0xfc00_0000, 0xfc00_0000), // sd
+    w(0xfc00_07ff, 0x0000_002d), // daddu / move
+];
+
+const PPC_COMMON: &[Op] = &[
+    w(0xffff_ffff, 0x4e80_0020), // blr
+    w(0xffff_ffff, 0x4e80_0420), // bctr
+    w(0xffff_ffff, 0x7c08_02a6), // mflr r0
+    w(0xffff_ffff, 0x7c08_03a6), // mtlr r0
+    w(0xffff_ffff, 0x6000_0000), // nop
+    w(0xfc00_0003, 0x4800_0001), // bl
+    w(0xfc00_0003, 0x4800_0000), // b
+    w(0xfc00_0000, 0x4000_0000), // bc
+    w(0xfc00_0000, 0x3800_0000), // addi / li
+    w(0xfc1f_0000, 0x3c00_0000), // lis
+    w(0xfc00_07fe, 0x7c00_0378), // or / mr
+];
+
+const PPC32: &[Op] = &[
+    w(0xffff_0000, 0x9421_0000), // stwu r1, -n(r1)
+    w(0xffff_0000, 0x9001_0000), // stw r0, n(r1)
+    w(0xffff_0000, 0x8001_0000), // lwz r0, n(r1)
+    w(0xfc00_0000, 0x8000_0000), // lwz
+    w(0xfc00_0000, 0x9000_0000), // stw
+];
+
+const PPC64: &[Op] = &[
+    w(0xffff_0003, 0xf821_0001), // stdu r1, -n(r1)
+    w(0xffff_ffff, 0xf801_0010), // std r0, 16(r1)
+    w(0xffff_ffff, 0xe801_0010), // ld r0, 16(r1)
+    w(0xffff_ffff, 0xf841_0018), // std r2, 24(r1)
+    w(0xffff_ffff, 0xe841_0018), // ld r2, 24(r1)
+    w(0xfc00_0003, 0xe800_0000), // ld
+    w(0xfc00_0003, 0xf800_0000), // std
+];
+
+const RISCV_COMMON: &[Op] = &[
+    w(0xffff_ffff, 0x0000_8067), // ret
+    w(0xffff_ffff, 0x0000_0013), // nop
+    w(0x000f_ffff, 0x0001_0113), // addi sp, sp, imm
+    w(0x0000_707f, 0x0000_0013), // addi
+    w(0x0000_707f, 0x0000_0067), // jalr
+    w(0x0000_007f, 0x0000_006f), // jal
+    w(0x0000_007f, 0x0000_0017), // auipc
+    w(0x0000_007f, 0x0000_0037), // lui
+    w(0x0000_007f, 0x0000_0063), // branch
+    h(0xffff, 0x8082),           // c.ret
+    h(0xffff, 0x0001),           // c.nop
+    h(0xef83, 0x6101),           // c.addi16sp
+    h(0xe003, 0x0001),           // c.addi
+    h(0xe003, 0x4001),           // c.li
+    h(0xf003, 0x8002),           // c.mv
+    h(0xe003, 0xa001),           // c.j
+    h(0xe003, 0xc001),           // c.beqz
+    h(0xe003, 0xe001),           // c.bnez
+];
+
+const RV32: &[Op] = &[
+    w(0x01ff_f07f, 0x0011_2023), // sw ra, imm(sp)
+    w(0x000f_ffff, 0x0001_2083), // lw ra, imm(sp)
+    w(0x0000_707f, 0x0000_2023), // sw
+    w(0x0000_707f, 0x0000_2003), // lw
+    h(0xe07f, 0xc006),           // c.swsp ra
+    h(0xef83, 0x4082),           // c.lwsp ra
+];
+
+const RV64: &[Op] = &[
+    w(0x01ff_f07f, 0x0011_3023), // sd ra, imm(sp)
+    w(0x000f_ffff, 0x0001_3083), // ld ra, imm(sp)
+    w(0x0000_707f, 0x0000_3023), // sd
+    w(0x0000_707f, 0x0000_3003), // ld
+    w(0x0000_707f, 0x0000_001b), // addiw
+    h(0xe07f, 0xe006),           // c.sdsp ra
+    h(0xef83, 0x6082),           // c.ldsp ra
+];
+
+const X86_COMMON: &[&[u8]] = &[
+    &[0xc3, 0xcc],                   // ret; int3 padding
+    &[0xc3, 0x90],                   // ret; nop padding
+    &[0xc3, 0x0f, 0x1f],             // ret; nopl
+    &[0x5d, 0xc3],                   // pop rbp; ret
+    &[0xc9, 0xc3],                   // leave; ret
+    &[0x0f, 0x1f, 0x44, 0x00, 0x00], // nopl 0(rax,rax,1)
+    &[0x0f, 0x1f, 0x40, 0x00],       // nopl 0(rax)
+    &[0x0f, 0x84],                   // je rel32
+    &[0x0f, 0x85],                   // jne rel32
+    &[0x85, 0xc0],                   // test eax, eax
+    &[0x31, 0xc0],                   // xor eax, eax
+];
+
+const X86_32: &[&[u8]] = &[
+    &[0x55, 0x89, 0xe5],       // push ebp; mov ebp, esp
+    &[0xf3, 0x0f, 0x1e, 0xfb], // endbr32
+    &[0x83, 0xec],             // sub esp, imm8
+    &[0x83, 0xc4],             // add esp, imm8
+    &[0x8b, 0x45],             // mov eax, [ebp+d8]
+    &[0x89, 0x45],             // mov [ebp+d8], eax
+    &[0xff, 0x75],             // push [ebp+d8]
+    &[0x8b, 0x44, 0x24],       // mov eax, [esp+d8]
+    &[0x8b, 0x4c, 0x24],       // mov ecx, [esp+d8]
+    &[0x6a, 0x00],             // push 0
+];
+
+const X86_64: &[&[u8]] = &[
+    &[0x55, 0x48, 0x89, 0xe5], // push rbp; mov rbp, rsp
+    &[0xf3, 0x0f, 0x1e, 0xfa], // endbr64
+    &[0x48, 0x83, 0xec],       // sub rsp, imm8
+    &[0x48, 0x83, 0xc4],       // add rsp, imm8
+    &[0x48, 0x89],             // mov r/m64, r64
+    &[0x48, 0x8b],             // mov r64, r/m64
+    &[0x48, 0x8d],             // lea
+    &[0x48, 0x85],             // test r/m64, r64
+    &[0x48, 0x63],             // movsxd
+    &[0x48, 0xc7],             // mov r/m64, imm32
+    &[0x48, 0x39],             // cmp r/m64, r64
+    &[0x4c, 0x89],             // mov r/m64, r8-r15
+    &[0x4c, 0x8b],             // mov r8-r15, r/m64
+    &[0x49, 0x89],             // mov r8-r15 (r/m), r64
+    &[0x49, 0x8b],             // mov r64, r8-r15 (r/m)
+    &[0x41, 0x54],             // push r12
+    &[0x41, 0x5c],             // pop r12
+    &[0x41, 0x57],             // push r15
+    &[0x41, 0x5f],             // pop r15
+];
+
+const MODELS: &[Model] = &[
+    Model::grams(Arch::X86, &[X86_COMMON, X86_32]),
+    Model::grams(Arch::X86_64, &[X86_COMMON, X86_64]),
+    Model::fixed(Arch::AArch64, Little, &[AARCH64]),
+    Model::fixed(Arch::ARM, Little, &[A32]),
+    Model::fixed(Arch::ARM, Big, &[A32]),
+    Model {
+        arch: Arch::ARM,
+        endianness: Little,
+        thumb: true,
+        stream: Stream::Thumb,
+        ops: &[THUMB],
+        grams: &[],
+    },
+    Model::fixed(Arch::MIPS, Big, &[MIPS_COMMON, MIPS32]),
+    Model::fixed(Arch::MIPS, Little, &[MIPS_COMMON, MIPS32]),
+    Model::fixed(Arch::MIPS64, Big, &[MIPS_COMMON, MIPS64]),
+    Model::fixed(Arch::MIPS64, Little, &[MIPS_COMMON, MIPS64]),
+    Model::fixed(Arch::PPC, Big, &[PPC_COMMON, PPC32]),
+    Model::fixed(Arch::PPC, Little, &[PPC_COMMON, PPC32]),
+    Model::fixed(Arch::PPC64, Big, &[PPC_COMMON, PPC64]),
+    Model::fixed(Arch::PPC64, Little, &[PPC_COMMON, PPC64]),
+    Model::riscv(Arch::RISCV, &[RISCV_COMMON, RV32]),
+    Model::riscv(Arch::RISCV64, &[RISCV_COMMON, RV64]),
+];
+
+impl Model {
+    const fn fixed(arch: Arch, endianness: Endianness, ops: &'static [&'static [Op]]) -> Self {
+        Model {
+            arch,
+            endianness,
+            thumb: false,
+            stream: Stream::Fixed32(endianness),
+            ops,
+            grams: &[],
+        }
+    }
+
+    const fn riscv(arch: Arch, ops: &'static [&'static [Op]]) -> Self {
+        Model {
+            arch,
+            endianness: Little,
+            thumb: false,
+            stream: Stream::RiscV,
+            ops,
+            grams: &[],
+        }
+    }
+
+    const fn grams(arch: Arch, grams: &'static [&'static [&'static [u8]]]) -> Self {
+        Model {
+            arch,
+            endianness: Little,
+            thumb: false,
+            stream: Stream::X86,
+            ops: &[],
+            grams,
+        }
+    }
+
+    /// Information explained beyond chance, in nats per unit, or `None`
+    /// when the blob has too few units to judge.
+    fn score(&self, data: &[u8], freq: &[f64; 256]) -> Option<f64> {
+        match self.stream {
+            Stream::X86 => self.score_grams(data, freq),
+            stream => self.score_units(&units(data, stream), freq),
+        }
+    }
+
+    fn score_units(&self, units: &[(bool, u32)], freq: &[f64; 256]) -> Option<f64> {
+        if units.len() < MIN_UNITS {
+            return None;
+        }
+        let n = units.len() as f64;
+        let mut score = 0.0;
+        for op in self.ops.iter().flat_map(|g| g.iter()) {
+            let hits = units
+                .iter()
+                .filter(|&&(wide, v)| wide == op.wide && v & op.mask == op.value)
+                .count();
+            let lanes = if op.wide { 4 } else { 2 };
+            let p0: f64 = (0..lanes)
+                .map(|i| {
+                    let (m, v) = ((op.mask >> (8 * i)) as u8, (op.value >> (8 * i)) as u8);
+                    (0..=255u8)
+                        .filter(|b| b & m == v)
+                        .map(|b| freq[b as usize])
+                        .sum::<f64>()
+                })
+                .product();
+            score += gain(hits as f64 / n, p0);
+        }
+        Some(score)
+    }
+
+    fn score_grams(&self, data: &[u8], freq: &[f64; 256]) -> Option<f64> {
+        let live = data.iter().filter(|&&b| b != 0x00 && b != 0xff).count();
+        let n = live as f64 / X86_UNIT;
+        if n < MIN_UNITS as f64 {
+            return None;
+        }
+        let mut score = 0.0;
+        for gram in self.grams.iter().flat_map(|g| g.iter()) {
+            let hits = data.windows(gram.len()).filter(|w| w == gram).count();
+            let p0 = X86_UNIT * gram.iter().map(|&b| freq[b as usize]).product::<f64>();
+            score += gain((hits as f64 / n).min(1.0), p0);
+        }
+        Some(score)
+    }
+}
+
+/// Contribution of a pattern seen with frequency `f` where `p0` is
+/// expected by chance.
+fn gain(f: f64, p0: f64) -> f64 {
+    if f > p0 {
+        f * (f / p0).ln()
+    } else {
+        0.0
+    }
+}
+
+/// Byte frequencies of `data`, the chance baseline: a pattern is only
+/// evidence when it is more common than the blob's own bytes, drawn
+/// independently, would make it. Text is full of bytes that look like
+/// Thumb loads; it is the pairing of bytes that gives code away.
+fn byte_freq(data: &[u8]) -> [f64; 256] {
+    let mut freq = [0.0; 256];
+    for &b in data {
+        freq[b as usize] += 1.0;
+    }
+    let n = data.len().max(1) as f64;
+    freq.iter_mut().for_each(|f| *f /= n);
+    freq
+}
+
+/// Split `data` into `(is_32_bit, value)` instruction units, dropping
+/// all-zero and all-ones padding.
+fn units(data: &[u8], stream: Stream) -> Vec<(bool, u32)> {
+    let mut out = Vec::with_capacity(data.len() / 2);
+    match stream {
+        Stream::Fixed32(e) => {
+            for c in data.chunks_exact(4) {
+                let b = [c[0], c[1], c[2], c[3]];
+                let v = match e {
+                    Little => u32::from_le_bytes(b),
+                    Big => u32::from_be_bytes(b),
+                };
+                if v != 0 && v != u32::MAX {
+                    out.push((true, v));
+                }
+            }
+        }
+        Stream::Thumb | Stream::RiscV => {
+            let halves: Vec<u16> = data
+                .chunks_exact(2)
+                .map(|c| u16::from_le_bytes([c[0], c[1]]))
+                .collect();
+            let mut i = 0;
+            while i < halves.len() {
+                let hw = halves[i];
+                let wide = match stream {
+                    Stream::Thumb => matches!(hw >> 11, 0x1d..=0x1f),
+                    _ => hw & 3 == 3,
+                };
+                if wide && i + 1 < halves.len() {
+                    let v = match stream {
+                        // Thumb-2 stores the leading halfword first but
+                        // documents it as the high half.
+                        Stream::Thumb => (hw as u32) << 16 | halves[i + 1] as u32,
+                        _ => hw as u32 | (halves[i + 1] as u32) << 16,
+                    };
+                    if v != 0 && v != u32::MAX {
+                        out.push((true, v));
+                    }
+                    i += 2;
+                } else {
+                    if hw != 0 && hw != u16::MAX {
+                        out.push((false, hw as u32));
+                    }
+                    i += 1;
+                }
+            }
+        }
+        Stream::X86 => {}
+    }
+    out
+}
+
+/// Score `data` under every model. Candidates that explain it beyond noise
+/// are returned best first; an empty result means no verdict (too little
+/// data, or data that is not recognisable code).
+pub fn classify(data: &[u8]) -> Vec<ArchGuess> {
+    let scan = &data[..data.len().min(MAX_SCAN)];
+    let freq = byte_freq(scan);
+    let scored: Vec<(&Model, f64)> = MODELS
+        .iter()
+        .filter_map(|m| m.score(scan, &freq).map(|s| (m, s)))
+        .filter(|&(_, s)| s >= MIN_SCORE)
+        .collect();
+    let total: f64 = scored.iter().map(|&(_, s)| s).sum();
+    let mut out: Vec<ArchGuess> = scored
+        .into_iter()
+        .map(|(m, s)| ArchGuess {
+            arch: m.arch,
+            endianness: m.endianness,
+            thumb: m.thumb,
+            score: s as f32,
+            confidence: (s / total * (s / STRONG_SCORE).min(1.0)) as f32,
+        })
+        .collect();
+    out.sort_by(|a, b| b.score.total_cmp(&a.score));
+    out
+}
+
+/// The best guess when it is clear enough to drive a disassembler
+/// unattended: confidence of at least `min_confidence`.
+pub fn identify(data: &[u8], min_confidence: f32) -> Option<ArchGuess> {
+    classify(data)
+        .into_iter()
+        .next()
+        .filter(|g| g.confidence >= min_confidence)
+}
+
+/// Architecture candidates for raw bytes, best first, as dicts with
+/// `arch`, `endianness`, `thumb`, `score` and `confidence`.
+#[cfg(feature = "python-ext")]
+#[pyfunction]
+#[pyo3(name = "identify_arch")]
+#[pyo3(signature = (data, max_results=5))]
+pub fn identify_arch_py(py: Python<'_>, data: Vec<u8>, max_results: usize) -> PyResult<PyObject> {
+    let list = pyo3::types::PyList::empty(py);
+    for g in classify(&data).into_iter().take(max_results) {
+        let d = pyo3::types::PyDict::new(py);
+        d.set_item("arch", g.arch.to_string())?;
+        d.set_item("endianness", g.endianness.to_string().to_lowercase())?;
+        d.set_item("thumb", g.thumb)?;
+        d.set_item("score", g.score)?;
+        d.set_item("confidence", g.confidence)?;
+        list.append(d)?;
+    }
+    Ok(list.into())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn repeat_words(words: &[u32], e: Endianness) -> Vec<u8> {
+        let mut out = Vec::new();
+        for _ in 0..64 {
+            for &v in words {
+                out.extend_from_slice(&match e {
+                    Little => v.to_le_bytes(),
+                    Big => v.to_be_bytes(),
+                });
+            }
+        }
+        out
+    }
+
+    fn repeat_bytes(bytes: &[u8]) -> Vec<u8> {
+        bytes.repeat(4096 / bytes.len() + 1)
+    }
+
+    fn top(data: &[u8]) -> ArchGuess {
+        *classify(data).first().expect("a verdict")
+    }
+
+    #[test]
+    fn identifies_aarch64_function() {
+        let f = [
+            0xa9be7bfd, // stp x29, x30, [sp, #-32]!
+            0x910003fd, // mov x29, sp
+            0xf9000be0, // str x0, [sp, #16]
+            0x94000010, // bl
+            0xb4000060, // cbz x0
+            0xaa0003e1, // mov x1, x0
+            0xf9400be0, // ldr x0, [sp, #16]
+            0xa8c27bfd, // ldp x29, x30, [sp], #32
+            0xd65f03c0, // ret
+        ];
+        let g = top(&repeat_words(&f, Little));
+        assert_eq!((g.arch, g.endianness), (Arch::AArch64, Little));
+        assert!(g.confidence > 0.7, "{g:?}");
+    }
+
+    #[test]
+    fn separates_byte_orders() {
+        let mips = [
+            0x27bdffe0, // addiu sp, sp, -32
+            0xafbf001c, // sw ra, 28(sp)
+            0x8f998010, // lw t9, -32752(gp)
+            0x0320f809, // jalr t9
+            0x00000000, // nop
+            0x8fbf001c, // lw ra, 28(sp)
+            0x03e00008, // jr ra
+            0x27bd0020, // addiu sp, sp, 32
+        ];
+        for e in [Big, Little] {
+            let g = top(&repeat_words(&mips, e));
+            assert_eq!((g.arch, g.endianness), (Arch::MIPS, e));
+        }
+        let ppc = [
+            0x9421fff0, // stwu r1, -16(r1)
+            0x7c0802a6, // mflr r0
+            0x90010014, // stw r0, 20(r1)
+            0x48000011, // bl
+            0x80010014, // lwz r0, 20(r1)
+            0x7c0803a6, // mtlr r0
+            0x38210010, // addi r1, r1, 16
+            0x4e800020, // blr
+        ];
+        let g = top(&repeat_words(&ppc, Big));
+        assert_eq!((g.arch, g.endianness), (Arch::PPC, Big));
+    }
+
+    #[test]
+    fn identifies_thumb_and_riscv_streams() {
+        // push {r4, lr}; ldr r0, [pc, #8]; bl; movs r0, #0; pop {r4, pc}
+        let thumb = [
+            0x10, 0xb5, 0x02, 0x48, 0x00, 0xf0, 0x08, 0xf8, 0x00, 0x20, 0x10, 0xbd,
+        ];
+        let g = top(&repeat_bytes(&thumb));
+        assert_eq!(g.arch, Arch::ARM);
+        assert!(g.thumb);
+
+        // c.addi16sp; c.sdsp ra; jal; c.ldsp ra; c.addi16sp; c.ret
+        let rv64 = [
+            0x41, 0x11, 0x06, 0xe4, 0xef, 0x00, 0x00, 0x01, 0xa2, 0x60, 0x41, 0x01, 0x82, 0x80,
+        ];
+        assert_eq!(top(&repeat_bytes(&rv64)).arch, Arch::RISCV64);
+    }
+
+    #[test]
+    fn tells_x86_from_x86_64() {
+        // push rbp; mov rbp, rsp; sub rsp, 16; mov [rbp-8], rdi;
+        // mov rax, [rbp-8]; test rax, rax; leave; ret; int3
+        let x64 = [
+            0x55, 0x48, 0x89, 0xe5, 0x48, 0x83, 0xec, 0x10, 0x48, 0x89, 0x7d, 0xf8, 0x48, 0x8b,
+            0x45, 0xf8, 0x48, 0x85, 0xc0, 0xc9, 0xc3, 0xcc,
+        ];
+        assert_eq!(top(&repeat_bytes(&x64)).arch, Arch::X86_64);
+        // push ebp; mov ebp, esp; sub esp, 8; mov eax, [ebp+8];
+        // push [ebp+12]; add esp, 4; leave; ret; int3
+        let x86 = [
+            0x55, 0x89, 0xe5, 0x83, 0xec, 0x08, 0x8b, 0x45, 0x08, 0xff, 0x75, 0x0c, 0x83, 0xc4,
+            0x04, 0xc9, 0xc3, 0xcc,
+        ];
+        assert_eq!(top(&repeat_bytes(&x86)).arch, Arch::X86);
+    }
+
+    #[test]
+    fn no_verdict_for_noise_or_padding() {
+        // xorshift noise: no model explains it beyond chance.
+        let mut x = 0x2545_f491_4f6c_dd1du64;
+        let noise: Vec<u8> = (0..65_536)
+            .map(|_| {
+                x ^= x << 13;
+                x ^= x >> 7;
+                x ^= x << 17;
+                x as u8
+            })
+            .collect();
+        assert!(classify(&noise).is_empty(), "{:?}", classify(&noise));
+        assert!(classify(&[0u8; 4096]).is_empty());
+        assert!(identify(&[0xffu8; 4096], 0.5).is_none());
+    }
+}
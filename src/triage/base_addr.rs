@@ -0,0 +1,212 @@
+//! Load-address inference for headerless firmware.
+//!
+//! A raw dump carries no record of where it was mapped, but its absolute
+//! pointers still aim at its own strings. For a candidate base `B`, every
+//! pointer-sized word `v` with `v - B` landing on the first byte of a
+//! C string is a vote for `B`; the true base collects far more votes than
+//! any coincidence. Votes are only cast between words and string offsets
+//! that agree modulo the base alignment, which keeps the pairing sparse
+//! (the approach of basefind/rbasefind, done as a single voting pass).
+
+use crate::core::binary::Endianness;
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use std::collections::{HashMap, HashSet};
+
+/// Bytes of input considered.
+const MAX_SCAN: usize = 16 * 1024 * 1024;
+
+/// Shortest NUL-terminated ASCII run treated as a string.
+const MIN_STRING_LEN: usize = 6;
+
+/// Candidates explaining fewer pointers than this are noise.
+const MIN_HITS: u32 = 3;
+
+/// Upper bound on word/string pairings examined.
+const MAX_PAIRS: usize = 1 << 24;
+
+/// One candidate load address.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct BaseCandidate {
+    pub base: u64,
+    /// Distinct pointer values that land on a string start at this base.
+    pub hits: u32,
+    /// Share of the blob's strings those pointers account for, in [0, 1].
+    pub score: f32,
+}
+
+/// Offsets of NUL-terminated printable ASCII strings.
+fn string_starts(data: &[u8]) -> Vec<u64> {
+    let mut out = Vec::new();
+    let mut start = 0usize;
+    for (i, &b) in data.iter().enumerate() {
+        if b.is_ascii_graphic() || matches!(b, b' ' | b'\t' | b'\n' | b'\r') {
+            continue;
+        }
+        if b == 0 && i - start >= MIN_STRING_LEN {
+            out.push(start as u64);
+        }
+        start = i + 1;
+    }
+    out
+}
+
+/// Distinct non-trivial values of the aligned words of `data`.
+fn pointer_values(data: &[u8], bits: u8, endianness: Endianness) -> Vec<u64> {
+    let size = if bits == 64 { 8 } else { 4 };
+    let mut seen = HashSet::new();
+    for c in data.chunks_exact(size) {
+        let v = match (size, endianness) {
+            (8, Endianness::Little) => u64::from_le_bytes(c.try_into().unwrap()),
+            (8, Endianness::Big) => u64::from_be_bytes(c.try_into().unwrap()),
+            (_, Endianness::Little) => u32::from_le_bytes(c.try_into().unwrap()) as u64,
+            (_, Endianness::Big) => u32::from_be_bytes(c.try_into().unwrap()) as u64,
+        };
+        let ones = if size == 8 { u64::MAX } else { u32::MAX as u64 };
+        if v != 0 && v != ones {
+            seen.insert(v);
+        }
+    }
+    let mut out: Vec<u64> = seen.into_iter().collect();
+    out.sort_unstable();
+    out
+}
+
+/// Rank candidate load addresses for `data`, best first.
+///
+/// `bits` selects 32- or 64-bit pointers and `alignment` (a power of two,
+/// e.g. 0x1000) the granularity bases are searched at. An empty result
+/// means no base is supported by enough pointers.
+pub fn infer_base(
+    data: &[u8],
+    bits: u8,
+    endianness: Endianness,
+    alignment: u64,
+) -> Vec<BaseCandidate> {
+    let scan = &data[..data.len().min(MAX_SCAN)];
+    let align = alignment.max(1).next_power_of_two();
+    let strings = string_starts(scan);
+    if strings.is_empty() {
+        return Vec::new();
+    }
+    let mut buckets: HashMap<u64, Vec<u64>> = HashMap::new();
+    for &s in &strings {
+        buckets.entry(s & (align - 1)).or_default().push(s);
+    }
+
+    let mut votes: HashMap<u64, u32> = HashMap::new();
+    let mut pairs = 0usize;
+    'words: for v in pointer_values(scan, bits, endianness) {
+        let Some(bucket) = buckets.get(&(v & (align - 1))) else {
+            continue;
+        };
+        for &s in bucket {
+            if s > v {
+                break;
+            }
+            *votes.entry(v - s).or_default() += 1;
+            pairs += 1;
+            if pairs >= MAX_PAIRS {
+                break 'words;
+            }
+        }
+    }
+
+    let total = strings.len() as f32;
+    let mut out: Vec<BaseCandidate> = votes
+        .into_iter()
+        .filter(|&(_, hits)| hits >= MIN_HITS)
+        .map(|(base, hits)| BaseCandidate {
+            base,
+            hits,
+            score: (hits as f32 / total).min(1.0),
+        })
+        .collect();
+    out.sort_by(|a, b| b.hits.cmp(&a.hits).then(a.base.cmp(&b.base)));
+    out
+}
+
+/// Candidate load addresses for raw bytes as `(base, hits, score)` tuples,
+/// best first. `endianness` is `"little"` or `"big"`.
+#[cfg(feature = "python-ext")]
+#[pyfunction]
+#[pyo3(name = "infer_base")]
+#[pyo3(signature = (data, bits=32, endianness="little", alignment=0x1000, max_results=5))]
+pub fn infer_base_py(
+    data: Vec<u8>,
+    bits: u8,
+    endianness: &str,
+    alignment: u64,
+    max_results: usize,
+) -> PyResult<Vec<(u64, u32, f32)>> {
+    let e = match endianness.to_ascii_lowercase().as_str() {
+        "little" | "le" => Endianness::Little,
+        "big" | "be" => Endianness::Big,
+        other => {
+            return Err(pyo3::exceptions::PyValueError::new_err(format!(
+                "unknown endianness {other:?}"
+            )))
+        }
+    };
+    Ok(infer_base(&data, bits, e, alignment)
+        .into_iter()
+        .take(max_results)
+        .map(|c| (c.base, c.hits, c.score))
+        .collect())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// A blob of NUL-terminated strings followed by a table of pointers to
+    /// them as mapped at `base`, plus filler words.
+    fn firmware(base: u64, bits: u8, e: Endianness) -> Vec<u8> {
+        let mut data = vec![0u8; 0x40];
+        let mut offs = Vec::new();
+        for i in 0..24 {
+            offs.push(data.len() as u64);
+            data.extend_from_slice(format!("message number {i}\0").as_bytes());
+        }
+        while data.len() % 8 != 0 {
+            data.push(0);
+        }
+        let put = |data: &mut Vec<u8>, v: u64| match (bits, e) {
+            (64, Endianness::Little) => data.extend_from_slice(&v.to_le_bytes()),
+            (64, Endianness::Big) => data.extend_from_slice(&v.to_be_bytes()),
+            (_, Endianness::Little) => data.extend_from_slice(&(v as u32).to_le_bytes()),
+            (_, Endianness::Big) => data.extend_from_slice(&(v as u32).to_be_bytes()),
+        };
+        for (i, &o) in offs.iter().enumerate() {
+            put(&mut data, base + o);
+            put(&mut data, 0x1234_0000 + i as u64 * 0x10);
+        }
+        data
+    }
+
+    #[test]
+    fn recovers_base_from_string_pointers() {
+        for e in [Endianness::Little, Endianness::Big] {
+            let c = infer_base(&firmware(0x0800_0000, 32, e), 32, e, 0x1000);
+            assert_eq!(c[0].base, 0x0800_0000, "{e:?}");
+            assert_eq!(c[0].hits, 24);
+            assert!(c[0].score > 0.9);
+        }
+        let c = infer_base(
+            &firmware(0xffff_0000_4000_0000, 64, Endianness::Little),
+            64,
+            Endianness::Little,
+            0x1000,
+        );
+        assert_eq!(c[0].base, 0xffff_0000_4000_0000);
+    }
+
+    #[test]
+    fn wrong_byte_order_or_no_pointers_gives_no_base() {
+        let data = firmware(0x2000_0000, 32, Endianness::Big);
+        assert!(infer_base(&data, 32, Endianness::Little, 0x1000)
+            .iter()
+            .all(|c| c.base != 0x2000_0000));
+        assert!(infer_base(b"no strings here", 32, Endianness::Little, 0x1000).is_empty());
+    }
+}
@@ -0,0 +1,246 @@
+//! Build identifiers embedded by toolchains.
+//!
+//! Linkers stamp each build with an identifier that debuggers and symbol
+//! servers use to pair a binary with its debug information: the ELF
+//! `NT_GNU_BUILD_ID` note, the Mach-O `LC_UUID` command, the PE CodeView
+//! (RSDS) GUID and age, and the Go toolchain's build ID. The same identifier
+//! shows up in every copy of one build, stripped or not, so collecting them
+//! into one report section lets stored reports be correlated by build.
+
+use crate::core::binary::Format;
+use crate::formats::elf::notes::NoteSection;
+use crate::formats::elf::{ElfParser, PT_NOTE};
+use crate::formats::pe::PeParser;
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+/// GNU build-id note (ELF), value in lowercase hex.
+pub const GNU_BUILD_ID: &str = "gnu_build_id";
+/// `LC_UUID` load command (Mach-O), value as an uppercase hyphenated UUID.
+pub const MACHO_UUID: &str = "macho_uuid";
+/// CodeView RSDS record (PE), value as the symbol-server `<GUID><AGE>` key.
+pub const PE_CODEVIEW: &str = "pe_codeview";
+/// Go toolchain build ID, value as embedded (`action/content` hash pairs).
+pub const GO_BUILD_ID: &str = "go_build_id";
+
+/// `NT_GO_BUILD_ID` note type, in notes named "Go".
+const NT_GO_BUILD_ID: u32 = 4;
+
+/// Marker the Go linker places at the start of the text segment in every
+/// output format: `\xff Go build ID: "<id>"\n \xff`.
+const GO_BUILD_ID_MARKER: &[u8] = b"\xff Go build ID: \"";
+
+/// Longest Go build ID accepted (four base64 hashes and separators).
+const GO_BUILD_ID_MAX: usize = 256;
+
+/// One build identifier found in an artifact.
+#[derive(Debug, Clone, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct BuildId {
+    /// Identifier scheme, one of the `*_BUILD_ID`/`*_UUID`/`*_CODEVIEW` constants.
+    pub kind: String,
+    /// Normalized identifier, comparable across reports.
+    pub value: String,
+    /// Supporting detail (the PDB path for CodeView records).
+    pub detail: Option<String>,
+}
+
+impl BuildId {
+    fn new(kind: &str, value: String, detail: Option<String>) -> Self {
+        Self {
+            kind: kind.to_string(),
+            value,
+            detail,
+        }
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl BuildId {
+    fn __repr__(&self) -> String {
+        format!("BuildId({}={})", self.kind, self.value)
+    }
+}
+
+fn hex(bytes: &[u8]) -> String {
+    bytes.iter().map(|b| format!("{b:02x}")).collect()
+}
+
+fn format_uuid(u: &[u8; 16]) -> String {
+    let h = hex(u).to_ascii_uppercase();
+    format!(
+        "{}-{}-{}-{}-{}",
+        &h[0..8],
+        &h[8..12],
+        &h[12..16],
+        &h[16..20],
+        &h[20..32]
+    )
+}
+
+/// GNU and Go note identifiers, read from `PT_NOTE` segments (which sit
+/// near the start of the file) with the section table as a fallback.
+fn elf_ids(data: &[u8], out: &mut Vec<BuildId>) {
+    let Ok(elf) = ElfParser::parse(data) else {
+        return;
+    };
+    let endian = elf.header().ident.data;
+    let (mut gnu, mut go) = (None, None);
+    if let Ok(segments) = elf.segments() {
+        for seg in segments.segments().filter(|s| s.header.p_type == PT_NOTE) {
+            let Ok(notes) = NoteSection::parse(seg.data, endian) else {
+                continue;
+            };
+            gnu = gnu.or_else(|| notes.build_id().map(<[u8]>::to_vec));
+            go = go.or_else(|| {
+                notes
+                    .notes()
+                    .iter()
+                    .find(|n| n.name == "Go" && n.n_type == NT_GO_BUILD_ID)
+                    .and_then(|n| std::str::from_utf8(n.desc).ok())
+                    .map(|s| s.trim_end_matches('\0').to_string())
+                    .filter(|s| !s.is_empty())
+            });
+        }
+    }
+    if let Some(id) = gnu.or_else(|| elf.build_id()).filter(|b| !b.is_empty()) {
+        out.push(BuildId::new(GNU_BUILD_ID, hex(&id), None));
+    }
+    if let Some(id) = go {
+        out.push(BuildId::new(GO_BUILD_ID, id, None));
+    }
+}
+
+fn macho_ids(data: &[u8], out: &mut Vec<BuildId>) {
+    let uuid = crate::symbols::analysis::macho_env::analyze_macho_env(data).and_then(|e| e.uuid);
+    if let Some(u) = uuid.filter(|u| u.iter().any(|&b| b != 0)) {
+        out.push(BuildId::new(MACHO_UUID, format_uuid(&u), None));
+    }
+}
+
+fn pe_ids(data: &[u8], out: &mut Vec<BuildId>) {
+    let Ok(pe) = PeParser::new(data) else {
+        return;
+    };
+    if let Ok(Some(rsds)) = pe.codeview_rsds() {
+        out.push(BuildId::new(
+            PE_CODEVIEW,
+            rsds.guid_age_key(),
+            Some(rsds.pdb_path.clone()),
+        ));
+    }
+}
+
+/// Go build ID from the text-segment marker, for any container format.
+fn go_marker_id(data: &[u8]) -> Option<String> {
+    let start = memchr::memmem::find(data, GO_BUILD_ID_MARKER)? + GO_BUILD_ID_MARKER.len();
+    let tail = &data[start..data.len().min(start + GO_BUILD_ID_MAX)];
+    let end = tail.iter().position(|&b| b == b'"')?;
+    let id = std::str::from_utf8(&tail[..end]).ok()?;
+    (!id.is_empty() && id.bytes().all(|b| b.is_ascii_graphic())).then(|| id.to_string())
+}
+
+/// Collect the build identifiers of `data`, parsed as `format`.
+///
+/// Identifiers are returned in a stable order (format-native first, then
+/// the Go build ID) without duplicates; an empty result means none were
+/// found or the format carries none.
+pub fn extract(data: &[u8], format: Format) -> Vec<BuildId> {
+    let mut out = Vec::new();
+    match format {
+        Format::ELF => elf_ids(data, &mut out),
+        Format::MachO => macho_ids(data, &mut out),
+        Format::PE => pe_ids(data, &mut out),
+        _ => return out,
+    }
+    if !out.iter().any(|b| b.kind == GO_BUILD_ID) {
+        if let Some(id) = go_marker_id(data) {
+            out.push(BuildId::new(GO_BUILD_ID, id, None));
+        }
+    }
+    out.dedup();
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn note(name: &[u8], n_type: u32, desc: &[u8]) -> Vec<u8> {
+        let mut v = Vec::new();
+        v.extend_from_slice(&(name.len() as u32).to_le_bytes());
+        v.extend_from_slice(&(desc.len() as u32).to_le_bytes());
+        v.extend_from_slice(&n_type.to_le_bytes());
+        v.extend_from_slice(name);
+        while v.len() % 4 != 0 {
+            v.push(0);
+        }
+        v.extend_from_slice(desc);
+        while v.len() % 4 != 0 {
+            v.push(0);
+        }
+        v
+    }
+
+    /// ELF64 LE header with a single PT_NOTE program header.
+    fn elf_with_notes(notes: &[u8]) -> Vec<u8> {
+        let mut d = vec![0u8; 64 + 56];
+        d[..4].copy_from_slice(b"\x7fELF");
+        d[4] = 2; // ELFCLASS64
+        d[5] = 1; // little endian
+        d[6] = 1;
+        d[16..18].copy_from_slice(&2u16.to_le_bytes()); // ET_EXEC
+        d[18..20].copy_from_slice(&62u16.to_le_bytes()); // x86-64
+        d[20..24].copy_from_slice(&1u32.to_le_bytes());
+        d[32..40].copy_from_slice(&64u64.to_le_bytes()); // e_phoff
+        d[52..54].copy_from_slice(&64u16.to_le_bytes());
+        d[54..56].copy_from_slice(&56u16.to_le_bytes());
+        d[56..58].copy_from_slice(&1u16.to_le_bytes());
+        let ph = &mut d[64..120];
+        ph[0..4].copy_from_slice(&PT_NOTE.to_le_bytes());
+        ph[8..16].copy_from_slice(&120u64.to_le_bytes()); // p_offset
+        ph[32..40].copy_from_slice(&(notes.len() as u64).to_le_bytes());
+        ph[40..48].copy_from_slice(&(notes.len() as u64).to_le_bytes());
+        d.extend_from_slice(notes);
+        d
+    }
+
+    #[test]
+    fn elf_gnu_and_go_notes() {
+        let gnu: Vec<u8> = (0u8..20).collect();
+        let mut notes = note(b"GNU\0", 3, &gnu);
+        notes.extend(note(b"Go\0\0", NT_GO_BUILD_ID, b"abc/def/ghi/jkl"));
+        let ids = extract(&elf_with_notes(&notes), Format::ELF);
+        assert_eq!(ids.len(), 2);
+        assert_eq!(ids[0].kind, GNU_BUILD_ID);
+        assert_eq!(ids[0].value, "000102030405060708090a0b0c0d0e0f10111213");
+        assert_eq!(ids[1].kind, GO_BUILD_ID);
+        assert_eq!(ids[1].value, "abc/def/ghi/jkl");
+    }
+
+    #[test]
+    fn macho_uuid_and_go_marker() {
+        let mut d = vec![0u8; 32];
+        d[..4].copy_from_slice(&0xfeedfacfu32.to_le_bytes());
+        d[16..20].copy_from_slice(&1u32.to_le_bytes()); // ncmds
+        d[20..24].copy_from_slice(&24u32.to_le_bytes()); // sizeofcmds
+        d.extend_from_slice(&0x1bu32.to_le_bytes()); // LC_UUID
+        d.extend_from_slice(&24u32.to_le_bytes());
+        d.extend((0u8..16).map(|i| i * 0x11));
+        d.extend_from_slice(b"\xff Go build ID: \"aaa/bbb\"\n \xff");
+        let ids = extract(&d, Format::MachO);
+        assert_eq!(ids[0].kind, MACHO_UUID);
+        assert_eq!(ids[0].value, "00112233-4455-6677-8899-AABBCCDDEEFF");
+        assert_eq!(ids[1].kind, GO_BUILD_ID);
+        assert_eq!(ids[1].value, "aaa/bbb");
+    }
+
+    #[test]
+    fn nothing_for_raw_or_malformed_input() {
+        assert!(extract(b"\xff Go build ID: \"x\"", Format::Raw).is_empty());
+        assert!(extract(b"\x7fELF", Format::ELF).is_empty());
+        assert!(extract(b"MZ", Format::PE).is_empty());
+    }
+}
@@ -3,6 +3,7 @@
 //! Provides centralized configuration for all triage components with
 //! sensible defaults and Python-accessible configuration.
 
+use crate::core::triage::SeverityOverrides;
 use serde::{Deserialize, Serialize};
 
 #[cfg(feature = "python-ext")]
@@ -28,6 +29,9 @@ pub struct TriageConfig {
     pub parsers: ParserConfig,
     /// Similarity (CTPH) configuration.
     pub similarity: SimilarityConfig,
+    /// Finding severity configuration.
+    #[serde(default)]
+    pub findings: FindingsConfig,
 }
 
 #[cfg(feature = "python-ext")]
@@ -117,6 +121,49 @@ impl TriageConfig {
     pub fn set_similarity(&mut self, config: SimilarityConfig) {
         self.similarity = config;
     }
+
+    #[getter]
+    pub fn get_findings(&self) -> FindingsConfig {
+        self.findings.clone()
+    }
+
+    #[setter]
+    pub fn set_findings(&mut self, config: FindingsConfig) {
+        self.findings = config;
+    }
+}
+
+/// Finding severity configuration.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass)]
+pub struct FindingsConfig {
+    /// Severity remapping by rule id or finding category.
+    #[serde(default)]
+    pub severity_overrides: SeverityOverrides,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl FindingsConfig {
+    #[new]
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Overrides as `{rule or category: level}`.
+    #[getter]
+    pub fn get_severity_overrides(&self) -> std::collections::BTreeMap<String, String> {
+        crate::core::triage::severity::overrides_to_py(&self.severity_overrides)
+    }
+
+    #[setter]
+    pub fn set_severity_overrides(
+        &mut self,
+        overrides: std::collections::BTreeMap<String, String>,
+    ) -> PyResult<()> {
+        self.severity_overrides = crate::core::triage::severity::overrides_from_py(overrides)?;
+        Ok(())
+    }
 }
 
 /// Similarity (CTPH) configuration.
@@ -0,0 +1,417 @@
+//! Derive provenance-carrying findings from a triage artifact.
+//!
+//! The summaries in `TriagedArtifact` say *what* triage concluded; the
+//! findings produced here say *why*: which phase produced each claim, which
+//! rule fired, and which input bytes support it. Evidence offsets are file
+//! offsets into the buffer that triage analysed.
+
+use crate::core::binary::Format;
+use crate::core::triage::{Finding, Provenance, Severity, SnifferSource, TriagedArtifact};
+use crate::triage::licenses::LICENSE_RULES_VERSION;
+use crate::triage::packers::PACKER_RULES_VERSION;
+
+/// Version of the header-validation rule set (see `triage::headers`).
+pub const HEADER_RULES_VERSION: &str = "1";
+
+/// Byte markers checked by `detect_packers`, used to locate evidence.
+const PACKER_MARKERS: &[(&str, &[&[u8]])] = &[
+    ("UPX", &[b"UPX!", b"UPX0", b"UPX1"]),
+    ("ASPack", &[b"ASPack", b".adata"]),
+    ("PECompact", &[b"PECompact", b"PEC2"]),
+    ("Petite", &[b"Petite"]),
+    ("FSG", &[b"FSG!"]),
+    ("MPRESS", &[b"MPRESS"]),
+    ("Themida/WinLicense", &[b"Themida", b"WinLicense"]),
+    ("VMProtect", &[b".vmp0", b".vmp1"]),
+];
+
+/// Packers that are virtualizing or anti-debug protectors.
+const PROTECTORS: &[&str] = &["Themida/WinLicense", "VMProtect"];
+
+/// Version of the memory-permission rule set (`memory_findings`).
+pub const MEMORY_RULES_VERSION: &str = "1";
+
+/// Version of the PDB path leak rules (`symbols::analysis::pdb_path`).
+pub const PDB_PATH_RULES_VERSION: &str = "1";
+
+/// Maximum evidence spans recorded per marker.
+const MAX_SPANS_PER_MARKER: usize = 4;
+
+/// Build the findings list for `art`, using `data` to locate evidence.
+pub fn collect_findings(data: &[u8], art: &TriagedArtifact) -> Vec<Finding> {
+    let mut out = Vec::new();
+    format_findings(data, art, &mut out);
+    hint_findings(data, art, &mut out);
+    packer_findings(data, art, &mut out);
+    container_findings(art, &mut out);
+    overlay_findings(art, &mut out);
+    pdb_path_findings(art, &mut out);
+    license_findings(art, &mut out);
+    memory_findings(data, &mut out);
+    out
+}
+
+/// Flag segments the loader would map writable and executable.
+fn memory_findings(data: &[u8], out: &mut Vec<Finding>) {
+    use crate::core::image::BinaryImage;
+    let Ok(img) = crate::formats::object_image::ObjectImage::parse(data) else {
+        return;
+    };
+    for seg in img.writable_executable_segments() {
+        let mut prov =
+            Provenance::new("memory", 1.0).with_rule("memory:wx", Some(MEMORY_RULES_VERSION));
+        let end = seg.file_offset.saturating_add(seg.file_size);
+        if seg.file_size > 0 && end <= data.len() as u64 {
+            prov = prov.with_evidence(seg.file_offset, seg.file_size, Some("segment"));
+        }
+        out.push(Finding::new(
+            "wx_mapping",
+            format!(
+                "writable+executable segment {} at {:#x} ({} bytes, {})",
+                seg.name.as_deref().unwrap_or("<unnamed>"),
+                seg.va,
+                seg.mem_size,
+                seg.perms
+            ),
+            prov,
+        ));
+    }
+}
+
+fn format_findings(data: &[u8], art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    for v in &art.verdicts {
+        let mut prov = Provenance::new("headers", v.confidence)
+            .with_rule(format!("header:{}", v.format), Some(HEADER_RULES_VERSION));
+        for (offset, length, note) in header_spans(data, v.format) {
+            prov = prov.with_evidence(offset, length, Some(note));
+        }
+        out.push(Finding::new(
+            "format",
+            format!(
+                "format={} arch={} bits={} endianness={}",
+                v.format, v.arch, v.bits, v.endianness
+            ),
+            prov,
+        ));
+    }
+}
+
+/// Header byte ranges that identify `format`.
+fn header_spans(data: &[u8], format: Format) -> Vec<(u64, u64, &'static str)> {
+    let mut spans = Vec::new();
+    match format {
+        Format::ELF => spans.push((0, 16, "e_ident")),
+        Format::PE => {
+            spans.push((0, 2, "MZ"));
+            if let Some(b) = data.get(0x3c..0x40) {
+                let e_lfanew = u32::from_le_bytes([b[0], b[1], b[2], b[3]]) as u64;
+                if data.len() as u64 >= e_lfanew + 4 {
+                    spans.push((e_lfanew, 4, "PE signature"));
+                }
+            }
+        }
+        Format::MachO => spans.push((0, 4, "Mach-O magic")),
+        Format::Wasm => spans.push((0, 8, "Wasm magic/version")),
+        _ => spans.push((0, 4.min(data.len() as u64), "magic")),
+    }
+    spans.retain(|(o, l, _)| *l > 0 && o + l <= data.len() as u64);
+    spans
+}
+
+fn hint_findings(data: &[u8], art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    for h in &art.hints {
+        let Some(label) = h.label.as_ref().or(h.mime.as_ref()) else {
+            continue;
+        };
+        // Content sniffers see the leading bytes; extension guesses do not
+        // depend on content at all.
+        let mut prov = Provenance::new("sniffers", 0.5).with_rule(h.source.to_string(), None);
+        if h.source == SnifferSource::Infer && !data.is_empty() {
+            prov = prov.with_evidence(0, 16.min(data.len() as u64), Some("content sniff"));
+        }
+        out.push(Finding::new("hint", format!("type={}", label), prov));
+    }
+}
+
+fn packer_findings(data: &[u8], art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    let Some(packers) = &art.packers else {
+        return;
+    };
+    for m in packers {
+        let mut prov =
+            Provenance::new("packers", m.confidence).with_rule(&m.name, Some(PACKER_RULES_VERSION));
+        let markers = PACKER_MARKERS
+            .iter()
+            .find(|(name, _)| *name == m.name)
+            .map(|(_, markers)| *markers)
+            .unwrap_or(&[]);
+        for marker in markers {
+            let note = String::from_utf8_lossy(marker).into_owned();
+            for pos in memchr::memmem::find_iter(data, marker).take(MAX_SPANS_PER_MARKER) {
+                prov = prov.with_evidence(pos as u64, marker.len() as u64, Some(&note));
+            }
+        }
+        // Commercial protectors add anti-analysis on top of compression.
+        let severity = if PROTECTORS.contains(&m.name.as_str()) {
+            Severity::High
+        } else {
+            Severity::Medium
+        };
+        out.push(
+            Finding::new("packer", format!("packer={}", m.name), prov).with_severity(severity),
+        );
+    }
+}
+
+fn container_findings(art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    let Some(children) = &art.containers else {
+        return;
+    };
+    for c in children {
+        let prov = Provenance::new("containers", 0.9)
+            .with_rule(format!("container:{}", c.type_name), None)
+            .with_evidence(c.offset, c.size, Some(&c.type_name));
+        out.push(Finding::new(
+            "container",
+            format!("container={} at {:#x}", c.type_name, c.offset),
+            prov,
+        ));
+    }
+}
+
+fn overlay_findings(art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    let Some(ov) = &art.overlay else {
+        return;
+    };
+    // A recognizable payload appended to an image is more interesting than
+    // padding or an unidentified blob.
+    let (claim, severity) = match &ov.detected_format {
+        Some(f) => (
+            format!("overlay={:?} size={}", f, ov.size),
+            Severity::Medium,
+        ),
+        None => (format!("overlay size={}", ov.size), Severity::Low),
+    };
+    let prov = Provenance::new("overlay", 1.0)
+        .with_rule("end-of-image", None)
+        .with_evidence(ov.offset, ov.size, Some("overlay"));
+    out.push(Finding::new("overlay", claim, prov).with_severity(severity));
+}
+
+/// Report what the embedded PDB path discloses. Account and host names
+/// identify people and machines; the rest maps the build environment.
+fn pdb_path_findings(art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    let Some(debug) = art
+        .format_specific
+        .as_ref()
+        .and_then(|f| f.pe.as_ref())
+        .and_then(|pe| pe.debug.as_ref())
+    else {
+        return;
+    };
+    let path = debug.pdb_path.as_deref().unwrap_or("");
+    for leak in &debug.leaks {
+        let mut prov = Provenance::new("pe_debug", 1.0).with_rule(
+            format!("pdb_path:{}", leak.kind),
+            Some(PDB_PATH_RULES_VERSION),
+        );
+        if let Some(off) = debug.pdb_path_offset {
+            prov = prov.with_evidence(off, path.len() as u64, Some("pdb path"));
+        }
+        let severity = match leak.kind.as_str() {
+            "username" | "host" => Severity::Medium,
+            _ => Severity::Low,
+        };
+        out.push(
+            Finding::new(
+                "pdb_path_leak",
+                format!("{}={}", leak.kind, leak.value),
+                prov,
+            )
+            .with_severity(severity),
+        );
+    }
+}
+
+/// One finding per embedded license; copyleft licenses are raised to Low
+/// since shipping them carries source-disclosure obligations.
+fn license_findings(art: &TriagedArtifact, out: &mut Vec<Finding>) {
+    let Some(report) = &art.licenses else {
+        return;
+    };
+    for m in &report.licenses {
+        let prov = Provenance::new("licenses", 1.0)
+            .with_rule(format!("license:{}", m.source), Some(LICENSE_RULES_VERSION))
+            .with_evidence(m.offset, m.length, Some(m.source.as_str()));
+        let mut finding = Finding::new("license", format!("license={}", m.spdx), prov);
+        if m.spdx.contains("GPL") {
+            finding = finding.with_severity(Severity::Low);
+        }
+        out.push(finding);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::core::binary::{Arch, Endianness};
+    use crate::core::triage::{PackerMatch, TriageVerdict};
+
+    #[test]
+    fn packer_findings_point_at_markers() {
+        let mut data = vec![0u8; 0x100];
+        data[0x40..0x44].copy_from_slice(b"UPX!");
+        let art = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(data.len() as u64)
+            .with_packers(Some(vec![PackerMatch::new("UPX".into(), 0.7)]))
+            .build()
+            .unwrap();
+        let findings = collect_findings(&data, &art);
+        let f = findings.iter().find(|f| f.category == "packer").unwrap();
+        assert_eq!(f.provenance.pass, "packers");
+        assert_eq!(f.provenance.rule.as_deref(), Some("UPX"));
+        assert_eq!(
+            f.provenance.rule_version.as_deref(),
+            Some(PACKER_RULES_VERSION)
+        );
+        assert_eq!(f.provenance.evidence.len(), 1);
+        assert_eq!(f.provenance.evidence[0].offset, 0x40);
+        assert_eq!(f.severity, Severity::Medium);
+    }
+
+    /// 64-bit ELF with a single RWX PT_LOAD covering the whole file.
+    fn rwx_elf() -> Vec<u8> {
+        let mut d = vec![0u8; 0x80];
+        d[..8].copy_from_slice(b"\x7fELF\x02\x01\x01\0");
+        d[16..18].copy_from_slice(&2u16.to_le_bytes()); // ET_EXEC
+        d[18..20].copy_from_slice(&0x3eu16.to_le_bytes()); // x86-64
+        d[20..24].copy_from_slice(&1u32.to_le_bytes());
+        d[24..32].copy_from_slice(&0x400078u64.to_le_bytes());
+        d[32..40].copy_from_slice(&64u64.to_le_bytes()); // e_phoff
+        d[52..54].copy_from_slice(&64u16.to_le_bytes());
+        d[54..56].copy_from_slice(&56u16.to_le_bytes());
+        d[56..58].copy_from_slice(&1u16.to_le_bytes());
+        d[58..60].copy_from_slice(&64u16.to_le_bytes());
+        let ph = &mut d[64..120];
+        ph[0..4].copy_from_slice(&1u32.to_le_bytes()); // PT_LOAD
+        ph[4..8].copy_from_slice(&7u32.to_le_bytes()); // RWX
+        ph[16..24].copy_from_slice(&0x400000u64.to_le_bytes());
+        ph[24..32].copy_from_slice(&0x400000u64.to_le_bytes());
+        ph[32..40].copy_from_slice(&0x80u64.to_le_bytes());
+        ph[40..48].copy_from_slice(&0x80u64.to_le_bytes());
+        ph[48..56].copy_from_slice(&0x1000u64.to_le_bytes());
+        d
+    }
+
+    #[test]
+    fn rwx_segments_are_flagged() {
+        let data = rwx_elf();
+        let art = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(data.len() as u64)
+            .build()
+            .unwrap();
+        let findings = collect_findings(&data, &art);
+        let f = findings
+            .iter()
+            .find(|f| f.category == "wx_mapping")
+            .expect("W^X finding");
+        assert!(f.claim.contains("0x400000"));
+        assert_eq!(f.provenance.rule.as_deref(), Some("memory:wx"));
+        assert_eq!(f.severity, Severity::High);
+        assert_eq!(f.provenance.evidence[0].length, 0x80);
+    }
+
+    #[test]
+    fn format_findings_cite_header_bytes() {
+        let path =
+            "samples/binaries/platforms/windows/i386/export/windows/i686/O3/hello-c-mingw32-O3.exe";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        let v = TriageVerdict::try_new(Format::PE, Arch::X86, 32, Endianness::Little, 0.9, None)
+            .unwrap();
+        let art = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path(path)
+            .with_size_bytes(data.len() as u64)
+            .with_verdicts(vec![v])
+            .build()
+            .unwrap();
+        let findings = collect_findings(&data, &art);
+        let f = findings.iter().find(|f| f.category == "format").unwrap();
+        assert_eq!(f.provenance.evidence.len(), 2);
+        let sig = &f.provenance.evidence[1];
+        let at = sig.offset as usize;
+        assert_eq!(&data[at..at + 4], b"PE\0\0");
+    }
+
+    #[test]
+    fn pdb_path_leaks_cite_the_path() {
+        use crate::core::triage::formats::{FormatSpecificTriage, PeDebugInfo, PeTriageInfo};
+        use crate::symbols::analysis::pdb_path::analyze_pdb_path;
+
+        let path = r"C:\Users\jdoe\src\tool\Release\tool.pdb";
+        let debug = PeDebugInfo {
+            entries: vec!["codeview".into()],
+            pdb_path: Some(path.into()),
+            pdb_path_offset: Some(0x1234),
+            leaks: analyze_pdb_path(path),
+            ..Default::default()
+        };
+        let art = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(0x2000)
+            .with_format_specific(Some(FormatSpecificTriage {
+                pe: Some(PeTriageInfo {
+                    rich_header: None,
+                    debug: Some(debug),
+                }),
+                ..Default::default()
+            }))
+            .build()
+            .unwrap();
+        let leaks: Vec<_> = collect_findings(&[], &art)
+            .into_iter()
+            .filter(|f| f.category == "pdb_path_leak")
+            .collect();
+        assert_eq!(leaks.len(), 2);
+        assert_eq!(leaks[0].claim, "username=jdoe");
+        assert_eq!(leaks[0].severity, Severity::Medium);
+        assert_eq!(leaks[1].severity, Severity::Low);
+        assert_eq!(leaks[0].provenance.evidence[0].offset, 0x1234);
+        assert_eq!(leaks[0].provenance.evidence[0].length, path.len() as u64);
+    }
+
+    #[test]
+    fn licenses_cite_their_evidence() {
+        let mut art = TriagedArtifact::builder()
+            .with_id("t")
+            .with_path("t")
+            .with_size_bytes(0x100)
+            .build()
+            .unwrap();
+        art.licenses = Some(crate::triage::licenses::detect_licenses(
+            b"\0\0License GPLv3+: GNU GPL version 3 or later\0\0\0\0\0\0\0\0\0\0\
+              SPDX-License-Identifier: MIT\0",
+        ));
+        let found: Vec<_> = collect_findings(&[], &art)
+            .into_iter()
+            .filter(|f| f.category == "license")
+            .collect();
+        assert_eq!(found.len(), 2);
+        assert_eq!(found[0].claim, "license=GPL-3.0-or-later");
+        assert_eq!(found[0].severity, Severity::Low);
+        assert_eq!(found[0].provenance.evidence[0].offset, 18);
+        assert_eq!(found[1].claim, "license=MIT");
+        assert_eq!(found[1].severity, Severity::Info);
+        assert_eq!(
+            found[1].provenance.rule.as_deref(),
+            Some("license:spdx_tag")
+        );
+    }
+}
@@ -0,0 +1,124 @@
+//! Go runtime metadata summary for triage reports.
+//!
+//! `analysis::gometa` recovers the full function, file, package and type
+//! lists of a Go binary; a triage report carries the part an analyst reads
+//! first: toolchain, main package, module graph and build settings, plus
+//! the counts and first-party packages that show how much was recovered.
+
+use crate::analysis::gometa::recover_go_metadata;
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+/// Section names that mark a Go binary besides the build information.
+const PCLNTAB_SECTIONS: &[&[u8]] = &[b".gopclntab", b"__gopclntab"];
+
+/// First-party packages listed in a report.
+const MAX_PACKAGES: usize = 64;
+
+/// Summary of a Go binary's runtime metadata.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct GoBinaryInfo {
+    /// Toolchain version, e.g. `go1.22.1`.
+    pub go_version: Option<String>,
+    /// Import path of the main package.
+    pub main_package: Option<String>,
+    /// Main module as `path@version`.
+    pub main_module: Option<String>,
+    /// Dependency modules as `path@version`, with `=> replacement` appended
+    /// for replaced modules.
+    pub dependencies: Vec<String>,
+    /// Build settings (`GOOS`, `CGO_ENABLED`, `-ldflags`, `vcs.revision`, ...).
+    pub settings: Vec<(String, String)>,
+    /// Functions named by pclntab.
+    pub functions: u32,
+    /// Source files named by pclntab.
+    pub source_files: u32,
+    /// Type descriptors recovered.
+    pub types: u32,
+    /// Non-standard-library packages (including `main`), sorted.
+    pub packages: Vec<String>,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl GoBinaryInfo {
+    fn __repr__(&self) -> String {
+        format!(
+            "GoBinaryInfo({}, functions={}, dependencies={})",
+            self.go_version.as_deref().unwrap_or("unknown"),
+            self.functions,
+            self.dependencies.len()
+        )
+    }
+}
+
+fn module_ref(m: &crate::analysis::gobuildinfo::GoModule) -> String {
+    let mut s = format!("{}@{}", m.path, m.version);
+    if let Some(r) = &m.replace {
+        s.push_str(&format!(" => {}@{}", r.path, r.version));
+    }
+    s
+}
+
+/// Summarize the Go metadata of `data`; `None` for non-Go input.
+pub fn summarize(data: &[u8]) -> Option<GoBinaryInfo> {
+    // Cheap gate: the full recovery scans the data of PE images.
+    let marked = memchr::memmem::find(data, b"\xff Go buildinf:").is_some()
+        || PCLNTAB_SECTIONS
+            .iter()
+            .any(|n| memchr::memmem::find(data, n).is_some());
+    if !marked {
+        return None;
+    }
+    let meta = recover_go_metadata(data, false).ok()?;
+    let build = meta.build.as_ref();
+    Some(GoBinaryInfo {
+        go_version: build.map(|b| b.go_version.clone()),
+        main_package: build.and_then(|b| b.path.clone()),
+        main_module: build.and_then(|b| b.main.as_ref()).map(module_ref),
+        dependencies: build
+            .map(|b| b.deps.iter().map(module_ref).collect())
+            .unwrap_or_default(),
+        settings: build.map(|b| b.settings.clone()).unwrap_or_default(),
+        functions: meta.functions.len() as u32,
+        source_files: meta.source_files.len() as u32,
+        types: meta.types.len() as u32,
+        packages: meta
+            .first_party_packages()
+            .take(MAX_PACKAGES)
+            .map(str::to_string)
+            .collect(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn non_go_input_has_no_summary() {
+        assert!(summarize(b"\x7fELF not really").is_none());
+        // A marker without any parsable metadata is not enough either.
+        assert!(summarize(b"junk .gopclntab junk").is_none());
+    }
+
+    #[test]
+    fn stripped_go_sample_is_summarized() {
+        let path = "samples/binaries/platforms/linux/amd64/export/go/hello-go";
+        let Ok(data) = std::fs::read(path) else {
+            return;
+        };
+        if !data.starts_with(b"\x7fELF") {
+            return; // Git LFS pointer
+        }
+        let info = summarize(&data).expect("go summary");
+        assert!(info
+            .go_version
+            .as_deref()
+            .is_some_and(|v| v.starts_with("go1.")));
+        assert!(info.functions > 100);
+        assert!(info.packages.iter().any(|p| p == "main"));
+    }
+}
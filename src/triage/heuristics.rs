@@ -107,6 +107,117 @@ pub mod architecture {
     }
 }
 
+/// Byte order and word size from the shape of data words, for blobs whose
+/// code (if any) no opcode model recognises.
+///
+/// Two signals decide the byte order: small integers (counts, sizes,
+/// enum fields) read as values below 0x10000 only in the right order, and
+/// pointers into one image share their upper half with a neighbouring
+/// field only in the right order. Word size then comes from 8-byte slots:
+/// 64-bit fields holding small values or user-space pointers leave their
+/// high half zero far more often than their low half, where 32-bit fields
+/// show no such bias.
+pub mod layout {
+    use super::*;
+
+    const MAX_SCAN: usize = 1_048_576;
+    const MIN_WORDS: usize = 64;
+    /// High-half-zero bias above which slots are taken as 64-bit.
+    const WIDE_BIAS: f32 = 1.0 / 3.0;
+    /// Half-empty 8-byte slots needed before the bias means anything.
+    const MIN_SPLIT_SLOTS: u32 = 16;
+
+    /// Probable byte order and word size of a blob.
+    #[derive(Debug, Clone, Copy, PartialEq)]
+    pub struct DataLayout {
+        pub endianness: Endianness,
+        /// Share of the byte-order evidence that favours `endianness`.
+        pub endianness_confidence: f32,
+        /// 32 or 64.
+        pub bits: u8,
+        pub bits_confidence: f32,
+    }
+
+    fn words(data: &[u8], e: Endianness) -> Vec<u32> {
+        data.chunks_exact(4)
+            .map(|c| {
+                let b = [c[0], c[1], c[2], c[3]];
+                match e {
+                    Endianness::Little => u32::from_le_bytes(b),
+                    Endianness::Big => u32::from_be_bytes(b),
+                }
+            })
+            .collect()
+    }
+
+    /// Small-integer and shared-upper-half counts for one byte order.
+    fn order_evidence(w: &[u32]) -> u32 {
+        let small = w.iter().filter(|&&v| (1..=0xffff).contains(&v)).count();
+        let ptr = |a: u32, b: u32| {
+            let hi = a >> 16;
+            hi != 0 && hi != 0xffff && hi == b >> 16 && a != b
+        };
+        let pairs = (0..w.len())
+            .filter(|&i| {
+                w.get(i + 1).is_some_and(|&b| ptr(w[i], b))
+                    || w.get(i + 2).is_some_and(|&b| ptr(w[i], b))
+            })
+            .count();
+        (small + pairs) as u32
+    }
+
+    /// Byte order and word size of `data`, or `None` when it holds too few
+    /// non-zero words to say.
+    pub fn detect(data: &[u8]) -> Option<DataLayout> {
+        let scan = &data[..data.len().min(MAX_SCAN)];
+        let le = words(scan, Endianness::Little);
+        if le.iter().filter(|&&v| v != 0).count() < MIN_WORDS {
+            return None;
+        }
+        let be = words(scan, Endianness::Big);
+        let (le_score, be_score) = (order_evidence(&le), order_evidence(&be));
+        if le_score + be_score == 0 {
+            return None;
+        }
+        let (endianness, w, win) = if le_score >= be_score {
+            (Endianness::Little, &le, le_score)
+        } else {
+            (Endianness::Big, &be, be_score)
+        };
+
+        // In each 8-byte slot, count "low half set, high half clear"
+        // against the mirror case.
+        let (mut low_only, mut high_only) = (0u32, 0u32);
+        for pair in w.chunks_exact(2) {
+            let (lo, hi) = match endianness {
+                Endianness::Little => (pair[0], pair[1]),
+                Endianness::Big => (pair[1], pair[0]),
+            };
+            match (lo != 0, hi != 0) {
+                (true, false) => low_only += 1,
+                (false, true) => high_only += 1,
+                _ => {}
+            }
+        }
+        let split = low_only + high_only;
+        let (bits, bits_confidence) = if split < MIN_SPLIT_SLOTS {
+            // Too few half-empty slots to tell; 32-bit is the safer default.
+            (32, 0.5)
+        } else {
+            let bias = (low_only as f32 - high_only as f32) / split as f32;
+            let bits = if bias > WIDE_BIAS { 64 } else { 32 };
+            (bits, (0.5 + (bias - WIDE_BIAS).abs()).min(1.0))
+        };
+
+        Some(DataLayout {
+            endianness,
+            endianness_confidence: win as f32 / (le_score + be_score) as f32,
+            bits,
+            bits_confidence,
+        })
+    }
+}
+
 /// String extraction and summarization.
 pub mod strings {
     use super::*;
@@ -265,4 +376,24 @@ mod tests {
         assert!(!results2.is_empty());
         assert_eq!(results2[0].0, Arch::AArch64);
     }
+
+    #[test]
+    fn test_layout_detects_order_and_word_size() {
+        // A table of { u32 id; u32 len; void *name; } records as laid out
+        // on a 64-bit target, then the same fields on a 32-bit target.
+        let mut wide = Vec::new();
+        let mut narrow = Vec::new();
+        for i in 0..128u64 {
+            wide.extend_from_slice(&(i + 1).to_be_bytes());
+            wide.extend_from_slice(&(0x0000_7fff_0040_1000 + i * 0x20).to_be_bytes());
+            narrow.extend_from_slice(&(i as u32 + 1).to_le_bytes());
+            narrow.extend_from_slice(&(0x0804_8000 + i as u32 * 0x20).to_le_bytes());
+        }
+        let l = layout::detect(&wide).expect("layout");
+        assert_eq!((l.endianness, l.bits), (Endianness::Big, 64));
+        assert!(l.endianness_confidence > 0.8, "{l:?}");
+        let l = layout::detect(&narrow).expect("layout");
+        assert_eq!((l.endianness, l.bits), (Endianness::Little, 32));
+        assert!(layout::detect(&[0u8; 4096]).is_none());
+    }
 }
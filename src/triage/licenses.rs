@@ -0,0 +1,570 @@
+//! Open-source licenses shipped inside binaries.
+//!
+//! Statically linked libraries carry their license obligations with them,
+//! and the evidence usually survives into the artifact: license headers and
+//! full texts printed by `--version`/`--license`, `SPDX-License-Identifier`
+//! tags, the `license=` field of Linux kernel modules, and copyright notices
+//! (in PE version resources these are UTF-16). This module matches that
+//! evidence against a small corpus of distinctive license phrases and
+//! reports the SPDX identifiers found, for compliance review.
+
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+/// Shortest string considered; license phrases are far longer.
+const MIN_RUN: usize = 8;
+
+/// Non-text bytes (NUL padding between adjacent strings) bridged when
+/// grouping strings into one block, so a license split across a table of
+/// lines still matches as a whole.
+const MAX_GAP: usize = 8;
+
+/// Longest copyright notice kept, in bytes.
+const MAX_NOTICE: usize = 160;
+
+/// Most copyright notices reported.
+const MAX_NOTICES: usize = 64;
+
+/// Version of the license corpus and matching rules.
+pub const LICENSE_RULES_VERSION: &str = "1";
+
+/// `SPDX-License-Identifier` tag.
+const SPDX_TAG: &[u8] = b"SPDX-License-Identifier:";
+
+/// Where a license was identified from.
+pub const SOURCE_TEXT: &str = "text";
+pub const SOURCE_SPDX_TAG: &str = "spdx_tag";
+pub const SOURCE_MODINFO: &str = "modinfo";
+
+/// A license recognised by distinctive phrases of its text or standard
+/// header. Phrases are in normalized form: lowercase ASCII words separated
+/// by single spaces, punctuation dropped.
+struct LicenseRule {
+    spdx: &'static str,
+    all: &'static [&'static str],
+    none: &'static [&'static str],
+}
+
+const BSD_BINARY_CLAUSE: &str =
+    "redistributions in binary form must reproduce the above copyright notice";
+
+const RULES: &[LicenseRule] = &[
+    LicenseRule {
+        spdx: "GPL-2.0-or-later",
+        all: &["gnu general public license as published by the free software foundation either version 2 of the license or at your option any later version"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-2.0-or-later",
+        all: &["gnu gpl version 2 or later"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-2.0-only",
+        all: &["gnu general public license version 2 as published by the free software foundation"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-2.0-only",
+        all: &["gnu general public license version 2 june 1991"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-3.0-or-later",
+        all: &["gnu general public license as published by the free software foundation either version 3 of the license or at your option any later version"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-3.0-or-later",
+        all: &["gnu gpl version 3 or later"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-3.0-only",
+        all: &["gnu general public license version 3 as published by the free software foundation"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "GPL-3.0-only",
+        all: &["gnu general public license version 3 29 june 2007"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "LGPL-2.0-or-later",
+        all: &["gnu library general public license as published by the free software foundation either version 2 of the license"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "LGPL-2.1-or-later",
+        all: &["gnu lesser general public license as published by the free software foundation either version 2 1 of the license"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "LGPL-3.0-or-later",
+        all: &["gnu lesser general public license as published by the free software foundation either version 3 of the license"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "AGPL-3.0-or-later",
+        all: &["gnu affero general public license as published by the free software foundation either version 3 of the license"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "Apache-2.0",
+        all: &["apache license version 2 0"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "MIT",
+        all: &["permission is hereby granted free of charge to any person obtaining a copy of this software"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "BSD-4-Clause",
+        all: &[
+            BSD_BINARY_CLAUSE,
+            "all advertising materials mentioning features or use of this software must display the following acknowledgement",
+        ],
+        none: &["openssl project"],
+    },
+    LicenseRule {
+        spdx: "BSD-3-Clause",
+        all: &[BSD_BINARY_CLAUSE, "neither the name of"],
+        none: &["all advertising materials"],
+    },
+    LicenseRule {
+        spdx: "BSD-2-Clause",
+        all: &[BSD_BINARY_CLAUSE],
+        none: &["neither the name of", "all advertising materials"],
+    },
+    LicenseRule {
+        spdx: "OpenSSL",
+        all: &["this product includes software developed by the openssl project"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "ISC",
+        all: &["permission to use copy modify and or distribute this software for any purpose with or without fee is hereby granted"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "curl",
+        all: &["permission to use copy modify and distribute this software for any purpose with or without fee is hereby granted provided that the above copyright notice and this permission notice appear in all copies"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "Zlib",
+        all: &[
+            "provided as is without any express or implied warranty",
+            "the origin of this software must not be misrepresented",
+        ],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "MPL-2.0",
+        all: &["subject to the terms of the mozilla public license v 2 0"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "EPL-1.0",
+        all: &["eclipse org legal epl v10"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "EPL-2.0",
+        all: &["eclipse org legal epl 2 0"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "CDDL-1.0",
+        all: &["common development and distribution license version 1 0"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "BSL-1.0",
+        all: &["boost software license version 1 0"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "Python-2.0",
+        all: &["python software foundation license version 2"],
+        none: &[],
+    },
+    LicenseRule {
+        spdx: "Unlicense",
+        all: &["this is free and unencumbered software released into the public domain"],
+        none: &[],
+    },
+];
+
+/// Linux `MODULE_LICENSE` strings and the licenses they declare (see
+/// `license_is_gpl_compatible` in the kernel).
+const MODINFO_LICENSES: &[(&str, &str)] = &[
+    ("GPL", "GPL-2.0-only"),
+    ("GPL v2", "GPL-2.0-only"),
+    ("GPL and additional rights", "GPL-2.0-only"),
+    ("Dual BSD/GPL", "BSD-3-Clause OR GPL-2.0-only"),
+    ("Dual MIT/GPL", "MIT OR GPL-2.0-only"),
+    ("Dual MPL/GPL", "MPL-1.1 OR GPL-2.0-only"),
+];
+
+/// One license identified in an artifact.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct LicenseMatch {
+    /// SPDX identifier, or an SPDX expression for tags and dual licenses.
+    pub spdx: String,
+    /// `text`, `spdx_tag` or `modinfo`.
+    pub source: String,
+    /// File offset of the first piece of evidence.
+    pub offset: u64,
+    /// Length in bytes of that evidence.
+    pub length: u64,
+}
+
+/// A copyright notice found in an artifact.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct CopyrightNotice {
+    /// The notice, from `Copyright` to the end of its line.
+    pub text: String,
+    pub offset: u64,
+}
+
+/// Licenses and copyright notices embedded in an artifact.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct LicenseReport {
+    /// One entry per (SPDX identifier, source), at its first occurrence.
+    pub licenses: Vec<LicenseMatch>,
+    /// Distinct copyright notices, in file order.
+    pub copyrights: Vec<CopyrightNotice>,
+}
+
+impl LicenseReport {
+    /// Distinct SPDX identifiers, in order of first appearance.
+    pub fn spdx_ids(&self) -> Vec<&str> {
+        let mut ids: Vec<&str> = Vec::new();
+        for m in &self.licenses {
+            if !ids.contains(&m.spdx.as_str()) {
+                ids.push(&m.spdx);
+            }
+        }
+        ids
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.licenses.is_empty() && self.copyrights.is_empty()
+    }
+
+    fn add_license(&mut self, spdx: &str, source: &str, offset: u64, length: u64) {
+        if !self
+            .licenses
+            .iter()
+            .any(|m| m.spdx == spdx && m.source == source)
+        {
+            self.licenses.push(LicenseMatch {
+                spdx: spdx.to_string(),
+                source: source.to_string(),
+                offset,
+                length,
+            });
+        }
+    }
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl LicenseReport {
+    #[pyo3(name = "spdx_ids")]
+    fn spdx_ids_py(&self) -> Vec<String> {
+        self.spdx_ids().into_iter().map(str::to_string).collect()
+    }
+
+    fn __repr__(&self) -> String {
+        format!(
+            "LicenseReport(licenses=[{}], copyrights={})",
+            self.spdx_ids().join(", "),
+            self.copyrights.len()
+        )
+    }
+}
+
+/// Text recovered from the file: characters with the file offset of each.
+struct Block {
+    text: Vec<u8>,
+    offsets: Vec<u64>,
+}
+
+impl Block {
+    fn new() -> Self {
+        Self {
+            text: Vec::new(),
+            offsets: Vec::new(),
+        }
+    }
+
+    fn push(&mut self, b: u8, off: u64) {
+        self.text.push(b);
+        self.offsets.push(off);
+    }
+
+    /// File span of `text[start..end]`.
+    fn span(&self, start: usize, end: usize) -> (u64, u64) {
+        let from = self.offsets[start];
+        (from, self.offsets[end - 1] + 1 - from)
+    }
+}
+
+fn is_text(b: u8) -> bool {
+    b.is_ascii_graphic() || matches!(b, b' ' | b'\t' | b'\n' | b'\r')
+}
+
+/// ASCII/UTF-8 strings, with strings separated by short runs of padding
+/// grouped into one block (joined by a NUL, which normalizes to a space).
+fn ascii_blocks(data: &[u8], mut f: impl FnMut(&Block)) {
+    let mut block = Block::new();
+    let mut last_end = 0;
+    let mut i = 0;
+    while i < data.len() {
+        if !is_text(data[i]) {
+            i += 1;
+            continue;
+        }
+        let start = i;
+        while i < data.len() && is_text(data[i]) {
+            i += 1;
+        }
+        // Runs too short to be strings count as padding.
+        if i - start < MIN_RUN {
+            continue;
+        }
+        if !block.text.is_empty() {
+            if start - last_end > MAX_GAP {
+                f(&block);
+                block = Block::new();
+            } else {
+                block.push(0, last_end as u64);
+            }
+        }
+        for (k, &b) in data[start..i].iter().enumerate() {
+            block.push(b, (start + k) as u64);
+        }
+        last_end = i;
+    }
+    if !block.text.is_empty() {
+        f(&block);
+    }
+}
+
+/// UTF-16LE strings of ASCII characters, one block each.
+fn utf16_blocks(data: &[u8], mut f: impl FnMut(&Block)) {
+    let mut i = 0;
+    while i + 1 < data.len() {
+        let mut block = Block::new();
+        let mut j = i;
+        while j + 1 < data.len() && data[j + 1] == 0 && is_text(data[j]) {
+            block.push(data[j], j as u64);
+            j += 2;
+        }
+        if block.text.len() >= MIN_RUN {
+            f(&block);
+            i = j;
+        } else {
+            i += 1;
+        }
+    }
+}
+
+/// Lowercase words separated by single spaces, with the index into
+/// `text` of every normalized byte.
+fn normalize(text: &[u8]) -> (Vec<u8>, Vec<usize>) {
+    let mut norm = Vec::with_capacity(text.len());
+    let mut map = Vec::with_capacity(text.len());
+    for (i, &b) in text.iter().enumerate() {
+        if b.is_ascii_alphanumeric() {
+            norm.push(b.to_ascii_lowercase());
+            map.push(i);
+        } else if norm.last().is_some_and(|&l| l != b' ') {
+            norm.push(b' ');
+            map.push(i);
+        }
+    }
+    (norm, map)
+}
+
+fn match_rules(block: &Block, report: &mut LicenseReport) {
+    let (norm, map) = normalize(&block.text);
+    let find = |p: &str| memchr::memmem::find(&norm, p.as_bytes());
+    for rule in RULES {
+        let Some(hits) = rule.all.iter().map(|p| find(p)).collect::<Option<Vec<_>>>() else {
+            continue;
+        };
+        if rule.none.iter().any(|p| find(p).is_some()) {
+            continue;
+        }
+        // Evidence is the rule's first phrase.
+        let (first, end) = (hits[0], hits[0] + rule.all[0].len());
+        let (offset, length) = block.span(map[first], map[end - 1] + 1);
+        report.add_license(rule.spdx, SOURCE_TEXT, offset, length);
+    }
+}
+
+fn match_spdx_tags(block: &Block, report: &mut LicenseReport) {
+    for pos in memchr::memmem::find_iter(&block.text, SPDX_TAG) {
+        let start = pos + SPDX_TAG.len();
+        let expr: &[u8] = &block.text[start..];
+        let len = expr
+            .iter()
+            .position(|&b| !(b.is_ascii_alphanumeric() || b" .+-()".contains(&b)))
+            .unwrap_or(expr.len());
+        let value = String::from_utf8_lossy(&expr[..len]);
+        let value = value.trim().trim_end_matches(['*', '/']).trim();
+        if !value.is_empty() {
+            let (offset, length) = block.span(pos, start + len);
+            report.add_license(value, SOURCE_SPDX_TAG, offset, length);
+        }
+    }
+}
+
+fn match_copyrights(block: &Block, report: &mut LicenseReport) {
+    let lower = block.text.to_ascii_lowercase();
+    for pos in memchr::memmem::find_iter(&lower, b"copyright") {
+        if report.copyrights.len() >= MAX_NOTICES {
+            return;
+        }
+        // A notice names a year: "Copyright (C) 1995-2024 ...".
+        let mut rest = &lower[pos + b"copyright".len()..];
+        loop {
+            let trimmed = rest.trim_ascii_start();
+            if let Some(r) = trimmed.strip_prefix(b"(c)") {
+                rest = r;
+            } else if trimmed.len() < rest.len() {
+                rest = trimmed;
+            } else {
+                break;
+            }
+        }
+        let year = rest.len() >= 4
+            && (rest.starts_with(b"19") || rest.starts_with(b"20"))
+            && rest[..4].iter().all(u8::is_ascii_digit);
+        if !year {
+            continue;
+        }
+        let line = &block.text[pos..];
+        let len = line
+            .iter()
+            .take(MAX_NOTICE)
+            .position(|&b| matches!(b, b'\n' | b'\r' | 0))
+            .unwrap_or(line.len().min(MAX_NOTICE));
+        let text = String::from_utf8_lossy(&line[..len]).trim().to_string();
+        if !report.copyrights.iter().any(|c| c.text == text) {
+            let (offset, _) = block.span(pos, pos + len);
+            report.copyrights.push(CopyrightNotice { text, offset });
+        }
+    }
+}
+
+/// `license=` fields of a kernel module's `.modinfo` (NUL-separated).
+fn match_modinfo(data: &[u8], report: &mut LicenseReport) {
+    const FIELD: &[u8] = b"\0license=";
+    for pos in memchr::memmem::find_iter(data, FIELD) {
+        let start = pos + FIELD.len();
+        let Some(len) = data[start..].iter().take(64).position(|&b| b == 0) else {
+            continue;
+        };
+        let value = &data[start..start + len];
+        if let Some((_, spdx)) = MODINFO_LICENSES
+            .iter()
+            .find(|(name, _)| name.as_bytes() == value)
+        {
+            report.add_license(spdx, SOURCE_MODINFO, (pos + 1) as u64, (len + 8) as u64);
+        }
+    }
+}
+
+/// Identify the licenses and copyright notices embedded in `data`.
+pub fn detect_licenses(data: &[u8]) -> LicenseReport {
+    let mut report = LicenseReport::default();
+    let mut scan = |block: &Block| {
+        match_rules(block, &mut report);
+        match_spdx_tags(block, &mut report);
+        match_copyrights(block, &mut report);
+    };
+    ascii_blocks(data, &mut scan);
+    utf16_blocks(data, &mut scan);
+    match_modinfo(data, &mut report);
+    report.licenses.sort_by_key(|m| m.offset);
+    report.copyrights.sort_by_key(|c| c.offset);
+    report
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn gpl_header_split_across_strings_and_mit_text() {
+        let mut d = vec![0xffu8; 40];
+        for line in [
+            "This program is free software; you can redistribute it and/or modify",
+            "it under the terms of the GNU General Public License as published by",
+            "the Free Software Foundation; either version 2 of the License, or",
+            "(at your option) any later version.",
+        ] {
+            d.extend_from_slice(line.as_bytes());
+            d.extend_from_slice(&[0, 0, 0]);
+        }
+        d.extend_from_slice(&[0xff; 64]);
+        let mit_at = d.len();
+        d.extend_from_slice(
+            b"Permission is hereby granted, free of charge, to any person obtaining a\n\
+              copy of this software and associated documentation files\0",
+        );
+        let r = detect_licenses(&d);
+        assert_eq!(r.spdx_ids(), ["GPL-2.0-or-later", "MIT"]);
+        assert_eq!(r.licenses[0].source, SOURCE_TEXT);
+        assert_eq!(r.licenses[1].offset, mit_at as u64);
+    }
+
+    #[test]
+    fn bsd_variants_are_told_apart() {
+        let two = "Redistributions in binary form must reproduce the above copyright notice, \
+                   this list of conditions and the following disclaimer.";
+        let three =
+            format!("{two} Neither the name of the project nor the names of its contributors");
+        assert_eq!(detect_licenses(two.as_bytes()).spdx_ids(), ["BSD-2-Clause"]);
+        assert_eq!(
+            detect_licenses(three.as_bytes()).spdx_ids(),
+            ["BSD-3-Clause"]
+        );
+    }
+
+    #[test]
+    fn spdx_tags_modinfo_and_utf16_copyright() {
+        let mut d = b"/* SPDX-License-Identifier: Apache-2.0 OR MIT */\0\0".to_vec();
+        d.extend_from_slice(b"\0license=Dual BSD/GPL\0author=someone\0");
+        for c in "LegalCopyright\0Copyright (C) 2021 Example Corp.".encode_utf16() {
+            d.extend_from_slice(&c.to_le_bytes());
+        }
+        d.extend_from_slice(&[0, 0]);
+        let r = detect_licenses(&d);
+        assert_eq!(
+            r.spdx_ids(),
+            ["Apache-2.0 OR MIT", "BSD-3-Clause OR GPL-2.0-only"]
+        );
+        assert_eq!(r.licenses[0].source, SOURCE_SPDX_TAG);
+        assert_eq!(r.licenses[1].source, SOURCE_MODINFO);
+        assert_eq!(r.copyrights.len(), 1);
+        assert_eq!(r.copyrights[0].text, "Copyright (C) 2021 Example Corp.");
+    }
+
+    #[test]
+    fn license_clauses_alone_are_not_notices() {
+        let r = detect_licenses(
+            b"The above copyright notice and this permission notice shall be included\0",
+        );
+        assert!(r.is_empty());
+    }
+}
@@ -0,0 +1,387 @@
+//! Upgrade stored triage reports to the current schema.
+//!
+//! Reports are migrated as JSON values, one version step at a time, and the
+//! result is validated by deserializing it into `TriagedArtifact`. Each step
+//! is a small pure function so the chain stays auditable as the schema
+//! grows; when `TRIAGE_SCHEMA_VERSION` is bumped a step from the previous
+//! version must be added to `STEPS`.
+
+use crate::core::triage::{Severity, TriagedArtifact, TRIAGE_SCHEMA_VERSION};
+use serde_json::{Map, Value};
+
+/// Version assumed for reports written before `schema_version` existed.
+pub const UNVERSIONED: &str = "1.0";
+
+/// Errors raised while migrating a report.
+#[derive(Debug, thiserror::Error)]
+pub enum MigrationError {
+    #[error("report is not a JSON object")]
+    NotAnObject,
+    #[error("unknown schema version '{0}'")]
+    UnknownVersion(String),
+    #[error("schema version '{0}' is newer than this build ({TRIAGE_SCHEMA_VERSION})")]
+    NewerThanCurrent(String),
+    #[error("invalid JSON: {0}")]
+    Json(#[from] serde_json::Error),
+}
+
+/// What `migrate_value` did to one report.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct MigrationReport {
+    /// Version found in the input
+    pub from: String,
+    /// Version written to the output
+    pub to: String,
+    /// Descriptions of the steps applied, oldest first
+    pub applied: Vec<String>,
+}
+
+impl MigrationReport {
+    /// True if the input was already current.
+    pub fn is_noop(&self) -> bool {
+        self.applied.is_empty()
+    }
+}
+
+struct Step {
+    from: &'static str,
+    to: &'static str,
+    describe: &'static str,
+    apply: fn(&mut Map<String, Value>),
+}
+
+const STEPS: &[Step] = &[
+    Step {
+        from: "1.0",
+        to: "1.2",
+        describe: "add required hint/verdict lists missing from legacy reports",
+        apply: fill_required_lists,
+    },
+    Step {
+        from: "1.1",
+        to: "1.2",
+        describe: "add required hint/verdict lists missing from legacy reports",
+        apply: fill_required_lists,
+    },
+    Step {
+        from: "1.2",
+        to: "1.3",
+        describe: "add provenance-bearing findings list",
+        apply: add_findings,
+    },
+    Step {
+        from: "1.3",
+        to: "1.4",
+        describe: "add analyst annotations slot",
+        apply: add_annotations,
+    },
+    Step {
+        from: "1.4",
+        to: "1.5",
+        describe: "assign category-default severities to findings",
+        apply: add_finding_severity,
+    },
+    Step {
+        from: "1.5",
+        to: "1.6",
+        describe: "add build identifiers slot",
+        apply: add_build_ids,
+    },
+    Step {
+        from: "1.6",
+        to: "1.7",
+        describe: "add PE debug directory slot",
+        apply: add_pe_debug,
+    },
+    Step {
+        from: "1.7",
+        to: "1.8",
+        describe: "add embedded license slot",
+        apply: add_licenses,
+    },
+    Step {
+        from: "1.8",
+        to: "1.9",
+        describe: "add source language slot",
+        apply: add_source_language,
+    },
+    Step {
+        from: "1.9",
+        to: "1.10",
+        describe: "add Go metadata slot",
+        apply: add_go,
+    },
+    Step {
+        from: "1.10",
+        to: "1.11",
+        describe: "add unpacked children slot",
+        apply: add_unpacked,
+    },
+];
+
+fn fill_required_lists(obj: &mut Map<String, Value>) {
+    for key in ["hints", "verdicts"] {
+        match obj.get(key) {
+            Some(Value::Array(_)) => {}
+            _ => {
+                obj.insert(key.to_string(), Value::Array(Vec::new()));
+            }
+        }
+    }
+}
+
+fn add_findings(obj: &mut Map<String, Value>) {
+    // Findings cannot be reconstructed without the input bytes; record that
+    // they were not computed rather than claiming there were none.
+    obj.entry("findings").or_insert(Value::Null);
+}
+
+fn add_annotations(obj: &mut Map<String, Value>) {
+    obj.entry("annotations").or_insert(Value::Null);
+}
+
+fn add_build_ids(obj: &mut Map<String, Value>) {
+    // Like findings, identifiers need the input bytes to recover.
+    obj.entry("build_ids").or_insert(Value::Null);
+}
+
+fn add_licenses(obj: &mut Map<String, Value>) {
+    obj.entry("licenses").or_insert(Value::Null);
+}
+
+fn add_source_language(obj: &mut Map<String, Value>) {
+    obj.entry("source_language").or_insert(Value::Null);
+}
+
+fn add_go(obj: &mut Map<String, Value>) {
+    obj.entry("go").or_insert(Value::Null);
+}
+
+fn add_unpacked(obj: &mut Map<String, Value>) {
+    obj.entry("unpacked").or_insert(Value::Null);
+}
+
+fn add_pe_debug(obj: &mut Map<String, Value>) {
+    let pe = obj
+        .get_mut("format_specific")
+        .and_then(|f| f.get_mut("pe"))
+        .and_then(Value::as_object_mut);
+    if let Some(pe) = pe {
+        pe.entry("debug").or_insert(Value::Null);
+    }
+}
+
+fn add_finding_severity(obj: &mut Map<String, Value>) {
+    let Some(Value::Array(findings)) = obj.get_mut("findings") else {
+        return;
+    };
+    for f in findings.iter_mut().filter_map(Value::as_object_mut) {
+        if f.contains_key("severity") {
+            continue;
+        }
+        let category = f.get("category").and_then(Value::as_str).unwrap_or("");
+        let severity = Severity::for_category(category).label();
+        f.insert("severity".to_string(), Value::String(severity.to_string()));
+    }
+}
+
+fn version_of(obj: &Map<String, Value>) -> String {
+    obj.get("schema_version")
+        .and_then(Value::as_str)
+        .unwrap_or(UNVERSIONED)
+        .to_string()
+}
+
+fn parse_version(v: &str) -> Option<(u32, u32)> {
+    let (major, minor) = v.split_once('.').unwrap_or((v, "0"));
+    Some((major.parse().ok()?, minor.parse().ok()?))
+}
+
+/// Migrate one report in place to `TRIAGE_SCHEMA_VERSION`.
+pub fn migrate_value(report: &mut Value) -> Result<MigrationReport, MigrationError> {
+    let obj = report.as_object_mut().ok_or(MigrationError::NotAnObject)?;
+    let from = version_of(obj);
+    let mut current = from.clone();
+    let mut applied = Vec::new();
+    while current != TRIAGE_SCHEMA_VERSION {
+        let Some(step) = STEPS.iter().find(|s| s.from == current) else {
+            return Err(
+                match (
+                    parse_version(&current),
+                    parse_version(TRIAGE_SCHEMA_VERSION),
+                ) {
+                    (Some(have), Some(latest)) if have > latest => {
+                        MigrationError::NewerThanCurrent(current)
+                    }
+                    _ => MigrationError::UnknownVersion(current),
+                },
+            );
+        };
+        (step.apply)(obj);
+        obj.insert(
+            "schema_version".to_string(),
+            Value::String(step.to.to_string()),
+        );
+        applied.push(format!("{} -> {}: {}", step.from, step.to, step.describe));
+        current = step.to.to_string();
+    }
+    Ok(MigrationReport {
+        from,
+        to: current,
+        applied,
+    })
+}
+
+/// Migrate a serialized report and check that it loads as a current
+/// `TriagedArtifact`.
+pub fn migrate_json(json: &str) -> Result<(TriagedArtifact, MigrationReport), MigrationError> {
+    let mut value: Value = serde_json::from_str(json)?;
+    let report = migrate_value(&mut value)?;
+    let artifact = serde_json::from_value(value)?;
+    Ok((artifact, report))
+}
+
+/// Migrate a serialized report; returns the upgraded JSON and the steps
+/// applied.
+#[cfg(feature = "python-ext")]
+#[pyo3::prelude::pyfunction]
+#[pyo3(name = "migrate_report")]
+pub fn migrate_report_py(json: &str) -> pyo3::PyResult<(String, Vec<String>)> {
+    let (artifact, report) =
+        migrate_json(json).map_err(|e| pyo3::exceptions::PyValueError::new_err(e.to_string()))?;
+    let out = artifact
+        .to_json_string()
+        .map_err(|e| pyo3::exceptions::PyValueError::new_err(e.to_string()))?;
+    Ok((out, report.applied))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::triage::api::analyze_bytes;
+    use crate::triage::io::IOLimits;
+
+    fn current_report() -> Value {
+        let art = analyze_bytes(
+            b"\x7fELF\x02\x01\x01\0\0\0\0\0\0\0\0\0",
+            &IOLimits::default(),
+        )
+        .expect("triage");
+        serde_json::to_value(art).unwrap()
+    }
+
+    #[test]
+    fn current_reports_are_untouched() {
+        let mut v = current_report();
+        let before = v.clone();
+        let r = migrate_value(&mut v).unwrap();
+        assert!(r.is_noop());
+        assert_eq!(v, before);
+    }
+
+    #[test]
+    fn v1_2_gains_findings() {
+        let mut v = current_report();
+        let obj = v.as_object_mut().unwrap();
+        obj.remove("findings");
+        obj.insert("schema_version".into(), "1.2".into());
+        let r = migrate_value(&mut v).unwrap();
+        assert_eq!(r.from, "1.2");
+        assert_eq!(r.to, TRIAGE_SCHEMA_VERSION);
+        assert_eq!(v["findings"], Value::Null);
+        let (art, _) = migrate_json(&v.to_string()).unwrap();
+        assert_eq!(art.schema_version, TRIAGE_SCHEMA_VERSION);
+    }
+
+    #[test]
+    fn v1_4_findings_gain_default_severity() {
+        let mut v = serde_json::json!({
+            "schema_version": "1.4",
+            "findings": [
+                { "category": "packer", "claim": "packer=UPX" },
+                { "category": "format", "claim": "format=ELF", "severity": "high" },
+            ],
+        });
+        migrate_value(&mut v).unwrap();
+        assert_eq!(v["findings"][0]["severity"], "medium");
+        assert_eq!(v["findings"][1]["severity"], "high");
+    }
+
+    #[test]
+    fn v1_6_pe_info_gains_debug_slot() {
+        let mut v = serde_json::json!({
+            "schema_version": "1.6",
+            "format_specific": { "pe": { "rich_header": null }, "elf": null, "macho": null },
+        });
+        migrate_value(&mut v).unwrap();
+        assert_eq!(v["format_specific"]["pe"]["debug"], Value::Null);
+        assert!(v["format_specific"]["pe"]
+            .as_object()
+            .unwrap()
+            .contains_key("debug"));
+    }
+
+    #[test]
+    fn v1_7_gains_license_slot() {
+        let mut v = current_report();
+        let obj = v.as_object_mut().unwrap();
+        obj.remove("licenses");
+        obj.insert("schema_version".into(), "1.7".into());
+        let r = migrate_value(&mut v).unwrap();
+        assert_eq!(r.applied[0], "1.7 -> 1.8: add embedded license slot");
+        assert_eq!(v["licenses"], Value::Null);
+    }
+
+    #[test]
+    fn v1_9_gains_go_slot() {
+        let mut v = current_report();
+        let obj = v.as_object_mut().unwrap();
+        obj.remove("go");
+        obj.insert("schema_version".into(), "1.9".into());
+        let r = migrate_value(&mut v).unwrap();
+        assert_eq!(r.applied[0], "1.9 -> 1.10: add Go metadata slot");
+        assert_eq!(v["go"], Value::Null);
+    }
+
+    #[test]
+    fn v1_10_gains_unpacked_slot() {
+        let mut v = current_report();
+        let obj = v.as_object_mut().unwrap();
+        obj.remove("unpacked");
+        obj.insert("schema_version".into(), "1.10".into());
+        let r = migrate_value(&mut v).unwrap();
+        assert_eq!(r.applied[0], "1.10 -> 1.11: add unpacked children slot");
+        assert_eq!(v["unpacked"], Value::Null);
+    }
+
+    #[test]
+    fn unversioned_legacy_report_loads() {
+        let mut v = current_report();
+        let obj = v.as_object_mut().unwrap();
+        for key in ["schema_version", "hints", "verdicts", "findings"] {
+            obj.remove(key);
+        }
+        let (art, r) = migrate_json(&v.to_string()).unwrap();
+        assert_eq!(r.from, UNVERSIONED);
+        assert_eq!(r.applied.len(), STEPS.len() - 1);
+        assert!(art.hints.is_empty());
+    }
+
+    #[test]
+    fn newer_and_unknown_versions_are_rejected() {
+        let mut v = serde_json::json!({ "schema_version": "9.0" });
+        assert!(matches!(
+            migrate_value(&mut v),
+            Err(MigrationError::NewerThanCurrent(_))
+        ));
+        let mut v = serde_json::json!({ "schema_version": "0.7-beta" });
+        assert!(matches!(
+            migrate_value(&mut v),
+            Err(MigrationError::UnknownVersion(_))
+        ));
+        assert!(matches!(
+            migrate_value(&mut Value::Null),
+            Err(MigrationError::NotAnObject)
+        ));
+    }
+}
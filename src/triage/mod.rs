@@ -3,17 +3,25 @@
 //! This module provides the core triage functionality for classifying
 //! and analyzing binary artifacts safely and deterministically.
 
+pub mod annotations;
 pub mod api;
+pub mod arch_id;
+pub mod base_addr;
+pub mod build_ids;
 pub mod compiler_detection;
 pub mod config;
 pub mod containers;
 pub mod disasm_mini;
 pub mod entropy;
+pub mod findings;
 pub mod format_detection;
+pub mod go_info;
 pub mod headers;
 pub mod heuristics;
 pub mod io;
 pub mod languages;
+pub mod licenses;
+pub mod migrate;
 pub mod overlay;
 pub mod packers;
 pub mod parsers;
@@ -23,6 +31,8 @@ pub mod score;
 pub mod signatures;
 pub mod signing;
 pub mod sniffers;
+pub mod source_language;
+pub mod unpack;
 
 // Re-export key types from core for convenience
 pub use crate::core::triage::{
@@ -1,15 +1,21 @@
+use crate::core::confidence;
 use crate::core::triage::PackerMatch;
 use crate::entropy::shannon_entropy;
 use crate::triage::config::{EntropyConfig, PackerConfig};
 use crate::triage::entropy::analyze_entropy;
 
+/// Version of the packer signature set below; bump when markers or weights change.
+pub const PACKER_RULES_VERSION: &str = "1";
+
+/// Corroborate an existing match with independent evidence of strength
+/// `delta`, or add the match at `base_if_absent`.
 fn bump_match(out: &mut Vec<PackerMatch>, name: &str, base_if_absent: f32, delta: f32) {
     if let Some(m) = out.iter_mut().find(|m| m.name.eq_ignore_ascii_case(name)) {
-        m.confidence = (m.confidence + delta).clamp(0.0, 1.0);
+        m.confidence = confidence::any_of([m.confidence, delta]);
     } else if base_if_absent > 0.0 {
         out.push(PackerMatch::new(
             name.to_string(),
-            base_if_absent.clamp(0.0, 1.0),
+            confidence::clamp(base_if_absent),
         ));
     }
 }
@@ -24,26 +30,23 @@ pub fn detect_packers(data: &[u8], cfg: &PackerConfig) -> Vec<PackerMatch> {
         data
     };
 
-    // UPX
-    let mut upx = 0.0f32;
-    if memchr::memmem::find(hay, b"UPX!").is_some() {
-        upx += 0.4;
-    }
-    if memchr::memmem::find(hay, b"UPX0").is_some() {
-        upx += 0.3;
-    }
-    if memchr::memmem::find(hay, b"UPX1").is_some() {
-        upx += 0.3;
-    }
-    // Version/signature hints increase confidence
-    if memchr::memmem::find(hay, b"$Id: UPX ").is_some()
-        || memchr::memmem::find(hay, b"UPX ").is_some()
-    {
-        upx += 0.2;
-    }
+    // UPX: each marker is independent evidence of the same claim.
+    let upx_markers: [(&[u8], f32); 4] = [
+        (b"UPX!", 0.6),
+        (b"UPX0", 0.4),
+        (b"UPX1", 0.4),
+        // Version/signature strings
+        (b"UPX ", 0.2),
+    ];
+    let upx = confidence::any_of(
+        upx_markers
+            .iter()
+            .filter(|(m, _)| memchr::memmem::find(hay, m).is_some())
+            .map(|(_, c)| *c),
+    );
     if upx > 0.0 {
         // Calibrate lightly using config weights without hard-coding
-        let conf = (upx * cfg.upx_detection_weight.max(0.5)).min(1.0);
+        let conf = confidence::clamp(upx * cfg.upx_detection_weight.max(0.5));
         out.push(PackerMatch::new("UPX".to_string(), conf));
     }
 
@@ -93,27 +96,22 @@ pub fn detect_packers(data: &[u8], cfg: &PackerConfig) -> Vec<PackerMatch> {
     let ecfg = EntropyConfig::default();
     let ea = analyze_entropy(hay, &ecfg);
     let pi = &ea.packed_indicators;
-    let mut packed_score = 0.0f32;
+    let mut packed_signals: Vec<f32> = Vec::new();
     if pi.has_low_entropy_header {
-        packed_score += 0.25;
+        packed_signals.push(0.25);
     }
     if pi.has_high_entropy_body {
-        packed_score += 0.35;
+        packed_signals.push(0.35);
     }
     if pi.entropy_cliff.is_some() {
-        packed_score += 0.25;
+        packed_signals.push(0.25);
     }
     // Overall high entropy nudges up a bit
-    if let Some(overall) = ea.summary.overall {
-        if overall > 7.3 {
-            packed_score += 0.15;
-        }
-    } else {
-        let overall = shannon_entropy(hay);
-        if overall > 7.3 {
-            packed_score += 0.15;
-        }
+    let overall = ea.summary.overall.unwrap_or_else(|| shannon_entropy(hay));
+    if overall > 7.3 {
+        packed_signals.push(0.15);
     }
+    let packed_score = confidence::any_of(packed_signals.iter().copied());
 
     if packed_score > 0.4 {
         bump_match(&mut out, "Packed", packed_score.min(0.85), 0.0);
@@ -147,11 +145,12 @@ pub fn detect_packers(data: &[u8], cfg: &PackerConfig) -> Vec<PackerMatch> {
                 if bytes.len() >= 4096 {
                     let h = shannon_entropy(bytes) as f32;
                     if h > 7.3 {
-                        packed_score += 0.05; // small nudge per high-entropy section
+                        packed_signals.push(0.05); // small nudge per high-entropy section
                     }
                 }
             }
         }
+        let packed_score = confidence::any_of(packed_signals.iter().copied());
         if packed_score > 0.5 {
             bump_match(
                 &mut out,
@@ -1,5 +1,10 @@
 //! Confidence scoring and verdict ranking.
+//!
+//! Signals are blended with `confidence::weighted_mean` and errors applied
+//! with `confidence::penalize`, so verdict confidences follow the shared
+//! model in `core::confidence`.
 
+use crate::core::confidence;
 use crate::core::triage::{
     ConfidenceSignal, TriageError, TriageErrorKind, TriageVerdict, TriagedArtifact,
 };
@@ -38,31 +43,17 @@ impl Default for ScoreEngine {
 impl ScoreEngine {
     /// Calculate confidence score from signals with weights.
     pub fn calculate_confidence(&self, signals: &[ConfidenceSignal]) -> f32 {
-        let mut total_weight = 0.0f32;
-        let mut weighted_sum = 0.0f32;
-
-        for s in signals {
+        confidence::weighted_mean(signals.iter().map(|s| {
             let w = self.signal_weights.get(&s.name).copied().unwrap_or(0.05);
-            weighted_sum += s.score * w;
-            total_weight += w;
-        }
-
-        if total_weight > 0.0 {
-            (weighted_sum / total_weight).clamp(0.0, 1.0)
-        } else {
-            0.0
-        }
+            (s.score, w)
+        }))
     }
 
     /// Apply error penalties to a base confidence value.
     pub fn apply_penalties(&self, base: f32, errors: &[TriageError]) -> f32 {
-        let mut c = base;
-        for e in errors {
-            if let Some(p) = self.error_penalties.get(&e.kind) {
-                c -= *p;
-            }
-        }
-        c.clamp(0.0, 1.0)
+        errors.iter().fold(confidence::clamp(base), |c, e| {
+            confidence::penalize(c, self.error_penalties.get(&e.kind).copied().unwrap_or(0.0))
+        })
     }
 
     /// Rank verdicts by confidence (descending).
@@ -127,14 +118,14 @@ impl ScoreEngine {
             if let Some((top_arch, conf)) = arch_guesses.first() {
                 let mut score = 0.0f32;
                 if verdict.arch == *top_arch {
-                    score = (*conf).clamp(0.0, 1.0);
+                    score = confidence::clamp(*conf);
                 } else {
                     // family equivalence: x86 <-> x86_64
                     use crate::core::binary::Arch;
                     let fam_match = (verdict.arch == Arch::X86 && *top_arch == Arch::X86_64)
                         || (verdict.arch == Arch::X86_64 && *top_arch == Arch::X86);
                     if fam_match {
-                        score = (*conf).min(0.7).clamp(0.0, 1.0);
+                        score = confidence::clamp(conf.min(0.7));
                     }
                 }
                 if score > 0.0 {
@@ -152,7 +143,7 @@ impl ScoreEngine {
             if verdict.endianness == e_guess {
                 signals.push(ConfidenceSignal::new(
                     "endianness_match".into(),
-                    e_conf.clamp(0.0, 1.0),
+                    confidence::clamp(e_conf),
                     None,
                 ));
             } else {
@@ -219,7 +210,7 @@ impl ScoreEngine {
             let (abn_pen, abn_sigs) = self.abnormal_penalties(artifact, v);
             let mut all_sigs = signals;
             all_sigs.extend(abn_sigs);
-            v.confidence = (with_errors - abn_pen).clamp(0.0, 1.0);
+            v.confidence = confidence::penalize(with_errors, abn_pen);
             // Store per-verdict signal breakdown for reporting
             v.signals = Some(all_sigs);
         }
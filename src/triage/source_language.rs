@@ -0,0 +1,456 @@
+//! Primary source language of a compiled binary.
+//!
+//! A log-linear classifier over features every toolchain leaves behind:
+//! runtime strings (panic messages, RTTI names, metadata stream headers),
+//! section names, imported runtime libraries, and symbol mangling schemes.
+//! Each feature contributes `weight * log2(1 + count)` to the score of the
+//! languages it supports; a softmax over the scores gives the confidence.
+//! Runtime strings survive stripping, so stripped Go, Rust, C++, .NET and
+//! Delphi binaries are still classified; a stripped C binary shows none of
+//! the other languages' features and falls back to the C prior with low
+//! confidence.
+//!
+//! `triage::compiler_detection` answers the related question of which
+//! compiler built the binary from symbol names and metadata.
+
+use crate::core::image::BinaryImage;
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+use serde::{Deserialize, Serialize};
+
+pub const C: &str = "c";
+pub const CPP: &str = "cpp";
+pub const OBJC: &str = "objc";
+pub const SWIFT: &str = "swift";
+pub const GO: &str = "go";
+pub const RUST: &str = "rust";
+pub const DOTNET: &str = "dotnet";
+pub const DELPHI: &str = "delphi";
+
+/// Every label the classifier can produce, with its prior log-odds. C is
+/// the default for native code that shows nothing more specific.
+const LANGUAGES: &[(&str, f32)] = &[
+    (C, 1.0),
+    (CPP, 0.0),
+    (OBJC, 0.0),
+    (SWIFT, 0.0),
+    (GO, 0.0),
+    (RUST, 0.0),
+    (DOTNET, 0.0),
+    (DELPHI, 0.0),
+];
+
+/// Occurrences counted per feature; further hits add no information.
+const MAX_COUNT: u32 = 15;
+
+/// Alternatives reported besides the winner.
+const MAX_ALTERNATIVES: usize = 3;
+
+/// Where a feature is observed.
+enum Source {
+    /// Byte strings anywhere in the file (survive stripping).
+    Bytes(&'static [&'static [u8]]),
+    /// Section names containing any of these.
+    Sections(&'static [&'static str]),
+    /// Imported library names (lowercased) containing any of these.
+    Libraries(&'static [&'static str]),
+    /// Symbol names accepted by the predicate.
+    Symbols(fn(&str) -> bool),
+}
+
+struct Feature {
+    name: &'static str,
+    source: Source,
+    weights: &'static [(&'static str, f32)],
+}
+
+fn is_rust_symbol(s: &str) -> bool {
+    // Mach-O adds a leading underscore.
+    let s = if s.starts_with("__") { &s[1..] } else { s };
+    if let Some(rest) = s.strip_prefix("_R") {
+        // v0 mangling: `_R` then an uppercase production tag.
+        return rest.starts_with(|c: char| c.is_ascii_uppercase());
+    }
+    // Legacy mangling: Itanium-style with a `17h<hash>E` suffix.
+    let b = s.as_bytes();
+    s.starts_with("_ZN")
+        && b.len() > 23
+        && s.ends_with('E')
+        && &b[b.len() - 20..b.len() - 17] == b"17h"
+        && b[b.len() - 17..b.len() - 1]
+            .iter()
+            .all(u8::is_ascii_hexdigit)
+}
+
+fn is_itanium_cpp_symbol(s: &str) -> bool {
+    (s.starts_with("_Z") || s.starts_with("__Z")) && !is_rust_symbol(s)
+}
+
+fn is_msvc_cpp_symbol(s: &str) -> bool {
+    s.starts_with('?') && s.contains('@')
+}
+
+fn is_go_symbol(s: &str) -> bool {
+    s.starts_with("runtime.")
+        || s.starts_with("main.")
+        || s.starts_with("go:")
+        || s.starts_with("type:")
+}
+
+fn is_swift_symbol(s: &str) -> bool {
+    let s = s.strip_prefix('_').unwrap_or(s);
+    s.starts_with("$s") || s.starts_with("$S") || s.starts_with("_T0")
+}
+
+fn is_objc_symbol(s: &str) -> bool {
+    s.starts_with("_OBJC_CLASS_$_")
+        || s.starts_with("OBJC_CLASS_$_")
+        || s.starts_with("-[")
+        || s.starts_with("+[")
+}
+
+fn is_delphi_symbol(s: &str) -> bool {
+    s.starts_with("@System@") || s.starts_with("@Sysutils@") || s.starts_with("System.")
+}
+
+/// The model. Weights are hand-tuned log-odds: distinctive runtime
+/// artifacts (Go build ID, CLR metadata, Rust panic paths) dominate,
+/// while features shared between languages (the ObjC runtime in Swift
+/// apps, `std::` in Rust panic messages) are split or kept small.
+const FEATURES: &[Feature] = &[
+    Feature {
+        name: "go_build_id",
+        source: Source::Bytes(&[b"\xff Go build ID: \"", b"\xff Go buildinf:"]),
+        weights: &[(GO, 6.0)],
+    },
+    Feature {
+        name: "go_runtime_strings",
+        source: Source::Bytes(&[b"runtime.gopanic", b"runtime.morestack", b"runtime.goexit"]),
+        weights: &[(GO, 2.0)],
+    },
+    Feature {
+        name: "go_sections",
+        source: Source::Sections(&["gopclntab", "go.buildinfo", "gosymtab"]),
+        weights: &[(GO, 4.0)],
+    },
+    Feature {
+        name: "go_symbols",
+        source: Source::Symbols(is_go_symbol),
+        weights: &[(GO, 1.5)],
+    },
+    Feature {
+        name: "rust_panic_strings",
+        source: Source::Bytes(&[
+            b"panicked at",
+            b"called `Option::unwrap()` on a `None` value",
+            b"called `Result::unwrap()` on an `Err` value",
+        ]),
+        weights: &[(RUST, 3.0)],
+    },
+    Feature {
+        name: "rust_source_paths",
+        source: Source::Bytes(&[
+            b"/rustc/",
+            b"library/core/src/",
+            b"library/std/src/",
+            b".cargo/registry/src/",
+        ]),
+        weights: &[(RUST, 3.0)],
+    },
+    Feature {
+        name: "rust_symbols",
+        source: Source::Symbols(is_rust_symbol),
+        weights: &[(RUST, 2.0)],
+    },
+    Feature {
+        name: "cpp_runtime_strings",
+        source: Source::Bytes(&[
+            b"St9exception",
+            b"N10__cxxabiv1",
+            b"basic_string",
+            b"std::bad_alloc",
+            b"__gxx_personality_v0",
+            b"__cxa_throw",
+        ]),
+        weights: &[(CPP, 2.0)],
+    },
+    Feature {
+        name: "msvc_rtti",
+        source: Source::Bytes(&[b".?AV", b".?AU"]),
+        weights: &[(CPP, 2.5)],
+    },
+    Feature {
+        name: "cpp_libraries",
+        source: Source::Libraries(&["libstdc++", "libc++", "msvcp"]),
+        weights: &[(CPP, 3.0)],
+    },
+    Feature {
+        name: "cpp_library_names",
+        source: Source::Bytes(&[b"libstdc++.so", b"libc++.1.dylib", b"libc++.so"]),
+        weights: &[(CPP, 2.0)],
+    },
+    Feature {
+        name: "cpp_symbols",
+        source: Source::Symbols(is_itanium_cpp_symbol),
+        weights: &[(CPP, 1.5)],
+    },
+    Feature {
+        name: "msvc_cpp_symbols",
+        source: Source::Symbols(is_msvc_cpp_symbol),
+        weights: &[(CPP, 1.5)],
+    },
+    Feature {
+        name: "swift_sections",
+        source: Source::Sections(&["__swift5_", "swift5_"]),
+        weights: &[(SWIFT, 5.0)],
+    },
+    Feature {
+        name: "swift_runtime",
+        source: Source::Bytes(&[b"libswiftCore", b"swift_retain", b"swift_allocObject"]),
+        weights: &[(SWIFT, 3.0)],
+    },
+    Feature {
+        name: "swift_symbols",
+        source: Source::Symbols(is_swift_symbol),
+        weights: &[(SWIFT, 2.0)],
+    },
+    Feature {
+        name: "objc_sections",
+        source: Source::Sections(&["__objc_classlist", "__objc_methname", "__objc_selrefs"]),
+        weights: &[(OBJC, 3.0), (SWIFT, 0.5)],
+    },
+    Feature {
+        name: "objc_runtime",
+        source: Source::Bytes(&[b"libobjc.A.dylib", b"objc_msgSend"]),
+        weights: &[(OBJC, 1.5), (SWIFT, 0.5)],
+    },
+    Feature {
+        name: "objc_symbols",
+        source: Source::Symbols(is_objc_symbol),
+        weights: &[(OBJC, 2.0)],
+    },
+    Feature {
+        name: "clr_metadata",
+        // Metadata stream header and runtime version strings; the bare
+        // `BSJB` signature is too short to count on its own.
+        source: Source::Bytes(&[b"#Strings\0\0\0\0", b"v4.0.30319\0", b"v2.0.50727\0"]),
+        weights: &[(DOTNET, 2.5)],
+    },
+    Feature {
+        name: "clr_entry",
+        source: Source::Bytes(&[b"_CorExeMain", b"_CorDllMain"]),
+        weights: &[(DOTNET, 4.0)],
+    },
+    Feature {
+        name: "clr_libraries",
+        source: Source::Libraries(&["mscoree"]),
+        weights: &[(DOTNET, 4.0)],
+    },
+    Feature {
+        name: "delphi_strings",
+        // `\x07TObject` is the root class's ShortString RTTI name.
+        source: Source::Bytes(&[
+            b"SOFTWARE\\Borland\\Delphi",
+            b"Embarcadero",
+            b"FastMM",
+            b"\x07TObject",
+        ]),
+        weights: &[(DELPHI, 2.5)],
+    },
+    Feature {
+        name: "delphi_sections",
+        source: Source::Sections(&[".itext"]),
+        weights: &[(DELPHI, 2.0)],
+    },
+    Feature {
+        name: "delphi_libraries",
+        source: Source::Libraries(&["borlndmm", "rtl1", "rtl2", "vcl1", "vcl2"]),
+        weights: &[(DELPHI, 2.5)],
+    },
+    Feature {
+        name: "delphi_symbols",
+        source: Source::Symbols(is_delphi_symbol),
+        weights: &[(DELPHI, 2.0)],
+    },
+];
+
+/// The classifier's verdict on a binary's source language.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct SourceLanguageGuess {
+    /// `c`, `cpp`, `objc`, `swift`, `go`, `rust`, `dotnet` or `delphi`.
+    pub language: String,
+    /// Softmax probability of `language`, 0.0-1.0.
+    pub confidence: f32,
+    /// Runner-up languages and their probabilities, best first.
+    pub alternatives: Vec<(String, f32)>,
+    /// Features that fired, as `name=count`.
+    pub evidence: Vec<String>,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl SourceLanguageGuess {
+    fn __repr__(&self) -> String {
+        format!(
+            "SourceLanguageGuess({}, confidence={:.2})",
+            self.language, self.confidence
+        )
+    }
+}
+
+fn count_bytes(data: &[u8], needles: &[&[u8]]) -> u32 {
+    needles
+        .iter()
+        .map(|n| {
+            memchr::memmem::find_iter(data, n)
+                .take(MAX_COUNT as usize)
+                .count() as u32
+        })
+        .sum::<u32>()
+        .min(MAX_COUNT)
+}
+
+fn count_matching<'a>(names: impl Iterator<Item = &'a str>, pred: impl Fn(&str) -> bool) -> u32 {
+    names.filter(|&n| pred(n)).take(MAX_COUNT as usize).count() as u32
+}
+
+/// Classify from already-extracted parts of a binary: its bytes, section
+/// names, symbol names, and imported library names.
+pub fn classify_parts(
+    data: &[u8],
+    sections: &[String],
+    symbols: &[String],
+    libraries: &[String],
+) -> SourceLanguageGuess {
+    // Mach-O records install paths; match on the file name.
+    let libraries: Vec<String> = libraries
+        .iter()
+        .map(|l| {
+            l.rsplit(['/', '\\'])
+                .next()
+                .unwrap_or(l)
+                .to_ascii_lowercase()
+        })
+        .collect();
+    let mut scores: Vec<(&str, f32)> = LANGUAGES.to_vec();
+    let mut evidence = Vec::new();
+    for feature in FEATURES {
+        let count = match &feature.source {
+            Source::Bytes(needles) => count_bytes(data, needles),
+            Source::Sections(parts) => count_matching(sections.iter().map(String::as_str), |s| {
+                parts.iter().any(|p| s.contains(p))
+            }),
+            Source::Libraries(parts) => count_matching(libraries.iter().map(String::as_str), |l| {
+                parts.iter().any(|p| l.starts_with(p))
+            }),
+            Source::Symbols(pred) => count_matching(symbols.iter().map(String::as_str), pred),
+        };
+        if count == 0 {
+            continue;
+        }
+        evidence.push(format!("{}={}", feature.name, count));
+        let x = (1.0 + count as f32).log2();
+        for (lang, w) in feature.weights {
+            if let Some(s) = scores.iter_mut().find(|(l, _)| l == lang) {
+                s.1 += w * x;
+            }
+        }
+    }
+    let max = scores.iter().map(|s| s.1).fold(f32::MIN, f32::max);
+    let total: f32 = scores.iter().map(|s| (s.1 - max).exp()).sum();
+    let mut probs: Vec<(String, f32)> = scores
+        .iter()
+        .map(|(l, s)| (l.to_string(), (s - max).exp() / total))
+        .collect();
+    probs.sort_by(|a, b| b.1.total_cmp(&a.1));
+    let (language, confidence) = probs.remove(0);
+    probs.truncate(MAX_ALTERNATIVES);
+    SourceLanguageGuess {
+        language,
+        confidence,
+        alternatives: probs,
+        evidence,
+    }
+}
+
+/// Classify the primary source language of an ELF, PE or Mach-O binary.
+/// Returns None for anything the object parser does not accept.
+pub fn classify(data: &[u8]) -> Option<SourceLanguageGuess> {
+    let img = crate::formats::object_image::ObjectImage::parse(data).ok()?;
+    let sections: Vec<String> = img.sections().into_iter().map(|s| s.name).collect();
+    let symbols: Vec<String> = img.symbols().into_iter().map(|s| s.name).collect();
+    let mut libraries: Vec<String> = img
+        .imports()
+        .into_iter()
+        .filter_map(|i| i.library)
+        .collect();
+    libraries.sort_unstable();
+    libraries.dedup();
+    Some(classify_parts(data, &sections, &symbols, &libraries))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn names(v: &[&str]) -> Vec<String> {
+        v.iter().map(|s| s.to_string()).collect()
+    }
+
+    #[test]
+    fn stripped_binaries_classified_from_runtime_strings() {
+        let rust = b"\0panicked at /rustc/90b35a6239c3d8bdabc530a6a0816f7ff89a0aaf/library/core/src/option.rs\0";
+        let g = classify_parts(rust, &[], &[], &[]);
+        assert_eq!(g.language, RUST);
+        assert!(g.confidence > 0.9, "{g:?}");
+
+        let go = b"\xff Go build ID: \"abc/def\"\n \xff\0runtime.gopanic\0runtime.goexit\0";
+        assert_eq!(classify_parts(go, &[], &[], &[]).language, GO);
+
+        let dotnet =
+            b"BSJB\x01\0\x01\0\0\0\0\0\x0c\0\0\0v4.0.30319\0\0#Strings\0\0\0\0_CorExeMain\0";
+        let g = classify_parts(
+            dotnet,
+            &names(&[".text", ".rsrc"]),
+            &[],
+            &names(&["mscoree.dll"]),
+        );
+        assert_eq!(g.language, DOTNET);
+        assert!(g.evidence.contains(&"clr_libraries=1".to_string()));
+    }
+
+    #[test]
+    fn symbols_sections_and_libraries() {
+        let syms = names(&[
+            "_ZNSt6vectorIiSaIiEE9push_backERKi",
+            "_ZN3foo3barEv",
+            "main",
+        ]);
+        let g = classify_parts(b"", &[], &syms, &names(&["libstdc++.so.6", "libc.so.6"]));
+        assert_eq!(g.language, CPP);
+
+        let rust_syms = names(&[
+            "_ZN4core3fmt5write17h0123456789abcdefE",
+            "_RNvCs1234_5alloc",
+        ]);
+        assert_eq!(classify_parts(b"", &[], &rust_syms, &[]).language, RUST);
+
+        let swift_secs = names(&["__swift5_typeref", "__swift5_proto", "__objc_classlist"]);
+        let g = classify_parts(b"", &swift_secs, &[], &names(&["/usr/lib/libobjc.A.dylib"]));
+        assert_eq!(g.language, SWIFT);
+        assert_eq!(g.alternatives[0].0, OBJC);
+
+        let delphi = b"SOFTWARE\\Borland\\Delphi\\RTL\0\x07TObject\0";
+        let g = classify_parts(delphi, &names(&[".text", ".itext"]), &[], &[]);
+        assert_eq!(g.language, DELPHI);
+    }
+
+    #[test]
+    fn plain_c_falls_back_to_the_prior() {
+        let g = classify_parts(b"usage: tool [-v]\0", &[], &names(&["main", "usage"]), &[]);
+        assert_eq!(g.language, C);
+        assert!(g.confidence < 0.5);
+        assert!(g.evidence.is_empty());
+    }
+}
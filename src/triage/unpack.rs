@@ -0,0 +1,1031 @@
+//! Recursive extraction of nested containers.
+//!
+//! [`recurse`](crate::triage::recurse) only locates children by magic and
+//! offset; this stage takes them apart. ZIP/JAR/APK members, gzip streams,
+//! tar and ar members, CAB files (stored or MSZIP), fat Mach-O slices,
+//! overlays and PE resources that carry an executable or archive are
+//! extracted, hashed, triaged like a top-level file, and extracted in turn.
+//! Depth, child count, per-child size and total extracted bytes are bounded,
+//! and every bound that stopped extraction is named in the report. Formats
+//! that are recognised but not decoded (xz, bzip2, zstd, 7z, RAR) and packed
+//! executables (UPX and friends) are reported with a note instead.
+
+use crate::cancel::CancellationToken;
+use crate::core::binary::Format;
+use crate::formats::apk::{ApkError, ApkReader};
+use crate::triage::io::IOLimits;
+use object::FileKind;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::borrow::Cow;
+use std::collections::BTreeSet;
+use std::io::Read;
+
+#[cfg(feature = "python-ext")]
+use pyo3::prelude::*;
+
+/// Overlays and resources smaller than this are never payloads worth a child.
+const MIN_EMBEDDED_SIZE: usize = 64;
+
+/// Bounds on one extraction walk.
+#[derive(Debug, Clone, PartialEq)]
+pub struct UnpackLimits {
+    /// Deepest child level extracted; the input itself is level 0.
+    pub max_depth: usize,
+    /// Children extracted across the whole tree.
+    pub max_children: usize,
+    /// Largest single child, decompressed.
+    pub max_child_size: u64,
+    /// Bytes extracted across the whole tree.
+    pub max_total_size: u64,
+}
+
+impl Default for UnpackLimits {
+    fn default() -> Self {
+        Self {
+            max_depth: 3,
+            max_children: 256,
+            max_child_size: 64 * 1024 * 1024,
+            max_total_size: 256 * 1024 * 1024,
+        }
+    }
+}
+
+/// A child extracted from its parent, with its own triage verdict.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct UnpackedChild {
+    /// Member path, `overlay`, `resource/<type>/<name>` or `slice/<arch>`.
+    pub name: String,
+    /// How it was extracted: `zip`, `gzip`, `tar`, `ar`, `cab`,
+    /// `fat-macho`, `overlay` or `pe-resource`.
+    pub source: String,
+    /// Nesting level; children of the input are at 1.
+    pub depth: u32,
+    pub size: u64,
+    pub sha256: String,
+    /// Format from the child's triage (`ELF`, `PE`, ...), its container
+    /// type (`zip`, `tar`, ...) when it is an archive, otherwise `data`.
+    pub format: String,
+    pub arch: Option<String>,
+    pub bits: Option<u8>,
+    /// Packers the child's triage matched.
+    pub packers: Vec<String>,
+    /// Highest finding severity in the child's triage.
+    pub max_severity: Option<String>,
+    /// Why the child was not taken further apart, when it looks like it
+    /// could be (`xz not unpacked`, `packed (UPX); not unpacked`).
+    pub note: Option<String>,
+    pub children: Vec<UnpackedChild>,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl UnpackedChild {
+    fn __repr__(&self) -> String {
+        format!(
+            "UnpackedChild({} via {}, {}, {} bytes, children={})",
+            self.name,
+            self.source,
+            self.format,
+            self.size,
+            self.children.len()
+        )
+    }
+}
+
+/// The child tree of one input.
+#[derive(Debug, Clone, PartialEq, Default, Serialize, Deserialize)]
+#[cfg_attr(feature = "python-ext", pyclass(get_all))]
+pub struct UnpackReport {
+    pub children: Vec<UnpackedChild>,
+    /// Children extracted at every level.
+    pub total_children: u32,
+    /// Bytes extracted at every level.
+    pub total_bytes: u64,
+    /// Deepest level reached.
+    pub max_depth: u32,
+    /// Bounds that stopped extraction (`max_depth`, `max_children`,
+    /// `max_child_size`, `max_total_size`, `cancelled`).
+    pub stopped: Vec<String>,
+}
+
+#[cfg(feature = "python-ext")]
+#[pymethods]
+impl UnpackReport {
+    fn __repr__(&self) -> String {
+        format!(
+            "UnpackReport(children={}, bytes={}, depth={})",
+            self.total_children, self.total_bytes, self.max_depth
+        )
+    }
+}
+
+/// Extract the nested containers of `data` and triage every child.
+pub fn unpack(data: &[u8], limits: &UnpackLimits, cancel: &CancellationToken) -> UnpackReport {
+    let mut walk = Walk {
+        limits,
+        cancel,
+        children: 0,
+        bytes: 0,
+        deepest: 0,
+        stopped: BTreeSet::new(),
+    };
+    let children = walk.expand(data, 1);
+    UnpackReport {
+        children,
+        total_children: walk.children as u32,
+        total_bytes: walk.bytes,
+        max_depth: walk.deepest as u32,
+        stopped: walk.stopped.into_iter().map(str::to_string).collect(),
+    }
+}
+
+/// One extracted member, borrowed from its parent when stored uncompressed.
+struct Member<'a> {
+    name: String,
+    source: &'static str,
+    data: Cow<'a, [u8]>,
+}
+
+impl<'a> Member<'a> {
+    fn new(name: impl Into<String>, source: &'static str, data: impl Into<Cow<'a, [u8]>>) -> Self {
+        Self {
+            name: name.into(),
+            source,
+            data: data.into(),
+        }
+    }
+}
+
+/// What a buffer is, as far as extraction is concerned.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Kind {
+    Zip,
+    Gzip,
+    Tar,
+    Ar,
+    Cab,
+    FatMachO,
+    Executable(Format),
+    /// Recognised, but nothing here decodes it.
+    Opaque(&'static str),
+}
+
+fn kind(data: &[u8]) -> Option<Kind> {
+    let starts = |magic: &[u8]| data.starts_with(magic);
+    if starts(b"PK\x03\x04") || starts(b"PK\x05\x06") {
+        return Some(Kind::Zip);
+    }
+    if starts(&[0x1f, 0x8b]) {
+        return Some(Kind::Gzip);
+    }
+    if data.get(257..262) == Some(b"ustar") {
+        return Some(Kind::Tar);
+    }
+    if starts(b"!<arch>\n") {
+        return Some(Kind::Ar);
+    }
+    if starts(b"MSCF") {
+        return Some(Kind::Cab);
+    }
+    let opaque: [(&[u8], &'static str); 6] = [
+        (&[0xfd, b'7', b'z', b'X', b'Z', 0x00], "xz"),
+        (b"BZh", "bzip2"),
+        (&[0x28, 0xb5, 0x2f, 0xfd], "zstd"),
+        (&[b'7', b'z', 0xbc, 0xaf, 0x27, 0x1c], "7z"),
+        (b"Rar!\x1a\x07", "rar"),
+        (&[0x04, 0x22, 0x4d, 0x18], "lz4"),
+    ];
+    if let Some((_, name)) = opaque.iter().find(|(magic, _)| starts(magic)) {
+        return Some(Kind::Opaque(name));
+    }
+    match FileKind::parse(data).ok()? {
+        FileKind::MachOFat32 | FileKind::MachOFat64 => Some(Kind::FatMachO),
+        FileKind::Pe32 | FileKind::Pe64 => Some(Kind::Executable(Format::PE)),
+        FileKind::Elf32 | FileKind::Elf64 => Some(Kind::Executable(Format::ELF)),
+        FileKind::MachO32 | FileKind::MachO64 => Some(Kind::Executable(Format::MachO)),
+        _ => None,
+    }
+}
+
+struct Walk<'l> {
+    limits: &'l UnpackLimits,
+    cancel: &'l CancellationToken,
+    children: usize,
+    bytes: u64,
+    deepest: usize,
+    stopped: BTreeSet<&'static str>,
+}
+
+impl Walk<'_> {
+    fn stop(&mut self, why: &'static str) {
+        self.stopped.insert(why);
+    }
+
+    /// Bytes the next member may inflate to, given `pending` bytes already
+    /// extracted from the same parent but not yet accounted.
+    fn budget(&self, pending: u64) -> u64 {
+        let left = self
+            .limits
+            .max_total_size
+            .saturating_sub(self.bytes.saturating_add(pending));
+        left.min(self.limits.max_child_size)
+    }
+
+    /// A member did not fit in `budget`: name the bound that was hit.
+    fn over_budget(&mut self, budget: u64) {
+        if budget < self.limits.max_child_size {
+            self.stop("max_total_size");
+        } else {
+            self.stop("max_child_size");
+        }
+    }
+
+    fn expand(&mut self, data: &[u8], depth: usize) -> Vec<UnpackedChild> {
+        let mut out = Vec::new();
+        for member in self.members(data) {
+            if self.cancel.is_cancelled() {
+                self.stop("cancelled");
+                break;
+            }
+            if self.children >= self.limits.max_children {
+                self.stop("max_children");
+                break;
+            }
+            let size = member.data.len() as u64;
+            if self.bytes.saturating_add(size) > self.limits.max_total_size {
+                self.stop("max_total_size");
+                break;
+            }
+            self.children += 1;
+            self.bytes += size;
+            self.deepest = self.deepest.max(depth);
+            let mut child = self.triage(&member, depth);
+            let archive = kind(&member.data)
+                .is_some_and(|k| !matches!(k, Kind::Executable(_) | Kind::Opaque(_)));
+            if depth < self.limits.max_depth {
+                child.children = self.expand(&member.data, depth + 1);
+            } else if archive {
+                self.stop("max_depth");
+            }
+            out.push(child);
+        }
+        out
+    }
+
+    fn members<'a>(&mut self, data: &'a [u8]) -> Vec<Member<'a>> {
+        match kind(data) {
+            Some(Kind::Zip) => self.zip_members(data),
+            Some(Kind::Gzip) => self.gzip_member(data).into_iter().collect(),
+            Some(Kind::Tar) => self.tar_members(data),
+            Some(Kind::Ar) => self.ar_members(data),
+            Some(Kind::Cab) => self.cab_members(data),
+            Some(Kind::FatMachO) => fat_slices(data),
+            Some(Kind::Executable(format)) => embedded_payloads(data, format),
+            Some(Kind::Opaque(_)) | None => Vec::new(),
+        }
+    }
+
+    /// Hash and triage one member.
+    fn triage(&self, member: &Member<'_>, depth: usize) -> UnpackedChild {
+        let data = &member.data[..];
+        let mut child = UnpackedChild {
+            name: member.name.clone(),
+            source: member.source.to_string(),
+            depth: depth as u32,
+            size: data.len() as u64,
+            sha256: hex::encode(Sha256::digest(data)),
+            format: "data".to_string(),
+            arch: None,
+            bits: None,
+            packers: Vec::new(),
+            max_severity: None,
+            note: None,
+            children: Vec::new(),
+        };
+        let io = IOLimits {
+            max_read_bytes: self.limits.max_child_size,
+            max_file_size: self.limits.max_child_size,
+        };
+        if let Ok(art) = crate::triage::api::analyze_bytes_with_cancel(data, &io, self.cancel) {
+            if let Some(v) = art.verdicts.first() {
+                child.format = v.format.to_string();
+                child.arch = Some(v.arch.to_string());
+                child.bits = Some(v.bits);
+            } else if let Some(c) = art.containers.as_ref().and_then(|c| c.first()) {
+                child.format = c.type_name.clone();
+            }
+            child.packers = art
+                .packers
+                .iter()
+                .flatten()
+                .map(|p| p.name.clone())
+                .collect();
+            child.max_severity = art.max_severity().map(|s| s.label().to_string());
+        }
+        child.note = match kind(data) {
+            Some(Kind::Opaque(name)) => Some(format!("{name} not unpacked")),
+            Some(Kind::Executable(_)) if !child.packers.is_empty() => Some(format!(
+                "packed ({}); not unpacked",
+                child.packers.join(", ")
+            )),
+            _ => None,
+        };
+        child
+    }
+
+    fn zip_members<'a>(&mut self, data: &'a [u8]) -> Vec<Member<'a>> {
+        let Ok(zip) = ApkReader::open(data) else {
+            return Vec::new();
+        };
+        let mut out = Vec::new();
+        let mut pending = 0u64;
+        for name in zip.names().filter(|n| !n.ends_with('/')) {
+            if out.len() >= self.limits.max_children {
+                break;
+            }
+            let budget = self.budget(pending);
+            match zip.read_limited(name, budget) {
+                Ok(bytes) => {
+                    pending += bytes.len() as u64;
+                    out.push(Member::new(name, "zip", bytes));
+                }
+                Err(ApkError::TooLarge(_)) => self.over_budget(budget),
+                // Unsupported methods and corrupt members are skipped.
+                Err(_) => {}
+            }
+        }
+        out
+    }
+
+    fn gzip_member<'a>(&mut self, data: &'a [u8]) -> Option<Member<'a>> {
+        let budget = self.budget(0);
+        let mut gz = flate2::read::MultiGzDecoder::new(data);
+        let mut out = Vec::new();
+        (&mut gz)
+            .take(budget.saturating_add(1))
+            .read_to_end(&mut out)
+            .ok()?;
+        if out.len() as u64 > budget {
+            self.over_budget(budget);
+            return None;
+        }
+        let name = gz
+            .header()
+            .and_then(|h| h.filename())
+            .map(|n| String::from_utf8_lossy(n).into_owned())
+            .unwrap_or_else(|| "gunzip".to_string());
+        Some(Member::new(name, "gzip", out))
+    }
+
+    fn tar_members<'a>(&mut self, data: &'a [u8]) -> Vec<Member<'a>> {
+        let mut out = Vec::new();
+        let mut pending = 0u64;
+        let mut long_name: Option<String> = None;
+        let mut off = 0usize;
+        while let Some(h) = data.get(off..off + 512) {
+            if h.iter().all(|&b| b == 0) {
+                break;
+            }
+            let Some(size) = tar_size(&h[124..136]) else {
+                break;
+            };
+            let body = off + 512;
+            let Some(content) = usize::try_from(size)
+                .ok()
+                .and_then(|n| data.get(body..body.checked_add(n)?))
+            else {
+                break;
+            };
+            let name = long_name.take().unwrap_or_else(|| tar_name(h));
+            match h[156] {
+                // GNU long name: the next header's name is this entry's data.
+                b'L' => long_name = Some(c_str(content)),
+                b'0' | b'7' | 0 if !content.is_empty() => {
+                    if out.len() >= self.limits.max_children {
+                        break;
+                    }
+                    let budget = self.budget(pending);
+                    if size > budget {
+                        self.over_budget(budget);
+                    } else {
+                        pending += size;
+                        out.push(Member::new(name, "tar", content));
+                    }
+                }
+                _ => {}
+            }
+            off = body + content.len().div_ceil(512) * 512;
+        }
+        out
+    }
+
+    fn ar_members<'a>(&mut self, data: &'a [u8]) -> Vec<Member<'a>> {
+        let mut out = Vec::new();
+        let mut pending = 0u64;
+        let mut long_names: &[u8] = &[];
+        let mut off = 8usize;
+        while let Some(h) = data.get(off..off + 60) {
+            if &h[58..60] != b"`\n" {
+                break;
+            }
+            let Some(size) = decimal(&h[48..58]) else {
+                break;
+            };
+            let body = off + 60;
+            let Some(content) = data.get(body..body.saturating_add(size)) else {
+                break;
+            };
+            off = body + size + (size & 1);
+            let raw = h[..16].trim_ascii_end();
+            let (name, content) = if raw == b"//" {
+                // GNU long-name table.
+                long_names = content;
+                continue;
+            } else if raw == b"/" || raw == b"/SYM64/" || raw.starts_with(b"__.SYMDEF") {
+                continue;
+            } else if let Some(len) = raw.strip_prefix(b"#1/").and_then(decimal) {
+                // BSD: the name is the first `len` bytes of the data.
+                let Some((name, rest)) = (len <= content.len()).then(|| content.split_at(len))
+                else {
+                    continue;
+                };
+                (c_str(name), rest)
+            } else if let Some(idx) = raw.strip_prefix(b"/").and_then(decimal) {
+                let entry = long_names.get(idx..).unwrap_or_default();
+                let end = memchr::memchr(b'\n', entry).unwrap_or(entry.len());
+                let name = &entry[..end];
+                (
+                    String::from_utf8_lossy(name.strip_suffix(b"/").unwrap_or(name)).into_owned(),
+                    content,
+                )
+            } else {
+                let name = raw.strip_suffix(b"/").unwrap_or(raw);
+                (String::from_utf8_lossy(name).into_owned(), content)
+            };
+            if content.is_empty() {
+                continue;
+            }
+            if out.len() >= self.limits.max_children {
+                break;
+            }
+            let budget = self.budget(pending);
+            if content.len() as u64 > budget {
+                self.over_budget(budget);
+                continue;
+            }
+            pending += content.len() as u64;
+            out.push(Member::new(name, "ar", content));
+        }
+        out
+    }
+
+    fn cab_members<'a>(&mut self, data: &'a [u8]) -> Vec<Member<'a>> {
+        let mut out = Vec::new();
+        let Some(cab) = Cabinet::parse(data) else {
+            return out;
+        };
+        // Folders decode once, on first use; `None` marks a folder that
+        // failed or did not fit.
+        let mut decoded: Vec<Option<Option<Vec<u8>>>> = vec![None; cab.folders.len()];
+        let mut pending = 0u64;
+        for file in &cab.files {
+            if out.len() >= self.limits.max_children {
+                break;
+            }
+            let Some(folder) = cab.folders.get(file.folder as usize) else {
+                continue;
+            };
+            let budget = self.budget(pending);
+            let slot = &mut decoded[file.folder as usize];
+            if slot.is_none() {
+                let bytes = cab.decode(folder, budget);
+                if bytes.is_none() && cab.uncompressed_size(folder) > budget {
+                    self.over_budget(budget);
+                }
+                if let Some(b) = &bytes {
+                    pending += b.len() as u64;
+                }
+                *slot = Some(bytes);
+            }
+            let Some(Some(folder_data)) = slot.as_ref() else {
+                continue;
+            };
+            let start = file.offset as usize;
+            if let Some(content) = folder_data.get(start..start.saturating_add(file.size as usize))
+            {
+                out.push(Member::new(file.name.clone(), "cab", content.to_vec()));
+            }
+        }
+        out
+    }
+}
+
+/// Each architecture slice of a fat Mach-O.
+fn fat_slices(data: &[u8]) -> Vec<Member<'_>> {
+    use object::read::macho::{FatArch, MachOFatFile32, MachOFatFile64};
+    fn slices<'a, A: FatArch>(data: &'a [u8], arches: &[A]) -> Vec<Member<'a>> {
+        arches
+            .iter()
+            .filter_map(|arch| {
+                let (off, size) = arch.file_range();
+                let slice = data.get(off as usize..off.checked_add(size)? as usize)?;
+                let name = format!("slice/{:?}", arch.architecture()).to_ascii_lowercase();
+                Some(Member::new(name, "fat-macho", slice))
+            })
+            .collect()
+    }
+    if let Ok(fat) = MachOFatFile32::parse(data) {
+        return slices(data, fat.arches());
+    }
+    if let Ok(fat) = MachOFatFile64::parse(data) {
+        return slices(data, fat.arches());
+    }
+    Vec::new()
+}
+
+/// The overlay of an executable (unless it is only a signature) and any PE
+/// resource that is itself an executable or archive.
+fn embedded_payloads(data: &[u8], format: Format) -> Vec<Member<'_>> {
+    let mut out = Vec::new();
+    if let Some(ov) = crate::triage::overlay::detect_overlay(data, format) {
+        let start = ov.offset as usize;
+        let overlay = data.get(start..start.saturating_add(ov.size as usize));
+        if let Some(overlay) = overlay.filter(|o| !ov.has_signature && o.len() >= MIN_EMBEDDED_SIZE)
+        {
+            out.push(Member::new("overlay", "overlay", overlay));
+        }
+    }
+    if format == Format::PE {
+        let Ok(pe) = crate::formats::pe::PeParser::new(data) else {
+            return out;
+        };
+        let Ok(resources) = pe.resources() else {
+            return out;
+        };
+        for res in &resources.resources {
+            if res.data.len() < MIN_EMBEDDED_SIZE || kind(res.data).is_none() {
+                continue;
+            }
+            let type_label = res
+                .type_name
+                .clone()
+                .unwrap_or_else(|| resource_label(&res.type_id));
+            let name = format!("resource/{}/{}", type_label, resource_label(&res.name));
+            out.push(Member::new(name, "pe-resource", res.data));
+        }
+    }
+    out
+}
+
+fn resource_label(id: &crate::formats::pe::ResourceIdentifier) -> String {
+    match id {
+        crate::formats::pe::ResourceIdentifier::Name(n) => n.clone(),
+        crate::formats::pe::ResourceIdentifier::Id(i) => i.to_string(),
+    }
+}
+
+/// Tar header size: octal digits, or base-256 when the high bit is set.
+fn tar_size(field: &[u8]) -> Option<u64> {
+    if field[0] & 0x80 != 0 {
+        return field[1..]
+            .iter()
+            .try_fold(0u64, |acc, &b| acc.checked_mul(256)?.checked_add(b as u64));
+    }
+    let digits = field[..memchr::memchr(0, field).unwrap_or(field.len())].trim_ascii();
+    if digits.is_empty() {
+        return Some(0);
+    }
+    u64::from_str_radix(std::str::from_utf8(digits).ok()?, 8).ok()
+}
+
+/// Member name of a tar header, joined to its ustar prefix.
+fn tar_name(h: &[u8]) -> String {
+    let name = c_str(&h[..100]);
+    if &h[257..262] == b"ustar" {
+        let prefix = c_str(&h[345..500]);
+        if !prefix.is_empty() {
+            return format!("{prefix}/{name}");
+        }
+    }
+    name
+}
+
+fn c_str(b: &[u8]) -> String {
+    let end = memchr::memchr(0, b).unwrap_or(b.len());
+    String::from_utf8_lossy(&b[..end]).into_owned()
+}
+
+fn decimal(b: &[u8]) -> Option<usize> {
+    std::str::from_utf8(b.trim_ascii()).ok()?.parse().ok()
+}
+
+fn u16_at(d: &[u8], o: usize) -> Option<u16> {
+    Some(u16::from_le_bytes(d.get(o..o + 2)?.try_into().ok()?))
+}
+
+fn u32_at(d: &[u8], o: usize) -> Option<u32> {
+    Some(u32::from_le_bytes(d.get(o..o + 4)?.try_into().ok()?))
+}
+
+/// CAB folder: where its CFDATA blocks start, how many, and the method.
+struct CabFolder {
+    data_offset: usize,
+    blocks: u16,
+    compression: u16,
+}
+
+struct CabFile {
+    name: String,
+    size: u32,
+    /// Offset of the file in its folder's uncompressed stream.
+    offset: u32,
+    folder: u16,
+}
+
+/// A Microsoft Cabinet's directory; data blocks decode on demand.
+struct Cabinet<'a> {
+    data: &'a [u8],
+    data_reserve: usize,
+    folders: Vec<CabFolder>,
+    files: Vec<CabFile>,
+}
+
+const CAB_PREV_CABINET: u16 = 0x0001;
+const CAB_NEXT_CABINET: u16 = 0x0002;
+const CAB_RESERVE_PRESENT: u16 = 0x0004;
+const CAB_COMPRESS_NONE: u16 = 0;
+const CAB_COMPRESS_MSZIP: u16 = 1;
+
+impl<'a> Cabinet<'a> {
+    fn parse(data: &'a [u8]) -> Option<Self> {
+        if !data.starts_with(b"MSCF") {
+            return None;
+        }
+        let files_offset = u32_at(data, 16)? as usize;
+        let nfolders = u16_at(data, 26)?;
+        let nfiles = u16_at(data, 28)?;
+        let flags = u16_at(data, 30)?;
+        let (mut pos, folder_reserve, data_reserve) = if flags & CAB_RESERVE_PRESENT != 0 {
+            let header_reserve = u16_at(data, 36)? as usize;
+            (
+                40 + header_reserve,
+                *data.get(38)? as usize,
+                *data.get(39)? as usize,
+            )
+        } else {
+            (36, 0, 0)
+        };
+        // Spanning cabinets name their neighbours (cabinet, disk) here.
+        let strings = 2
+            * (usize::from(flags & CAB_PREV_CABINET != 0)
+                + usize::from(flags & CAB_NEXT_CABINET != 0));
+        for _ in 0..strings {
+            pos += memchr::memchr(0, data.get(pos..)?)? + 1;
+        }
+        let mut folders = Vec::with_capacity(nfolders as usize);
+        for _ in 0..nfolders {
+            folders.push(CabFolder {
+                data_offset: u32_at(data, pos)? as usize,
+                blocks: u16_at(data, pos + 4)?,
+                compression: u16_at(data, pos + 6)?,
+            });
+            pos += 8 + folder_reserve;
+        }
+        let mut files = Vec::with_capacity(nfiles as usize);
+        let mut pos = files_offset;
+        for _ in 0..nfiles {
+            let name_start = pos + 16;
+            let name_len = memchr::memchr(0, data.get(name_start..)?)?;
+            files.push(CabFile {
+                size: u32_at(data, pos)?,
+                offset: u32_at(data, pos + 4)?,
+                folder: u16_at(data, pos + 8)?,
+                name: String::from_utf8_lossy(&data[name_start..name_start + name_len])
+                    .replace('\\', "/"),
+            });
+            pos = name_start + name_len + 1;
+        }
+        Some(Self {
+            data,
+            data_reserve,
+            folders,
+            files,
+        })
+    }
+
+    /// CFDATA blocks of `folder` as `(payload, uncompressed size)`.
+    fn blocks(&self, folder: &CabFolder) -> Vec<(&'a [u8], usize)> {
+        let mut out = Vec::new();
+        let mut pos = folder.data_offset;
+        for _ in 0..folder.blocks {
+            let (Some(packed), Some(unpacked)) =
+                (u16_at(self.data, pos + 4), u16_at(self.data, pos + 6))
+            else {
+                break;
+            };
+            let start = pos + 8 + self.data_reserve;
+            let Some(payload) = self.data.get(start..start + packed as usize) else {
+                break;
+            };
+            out.push((payload, unpacked as usize));
+            pos = start + packed as usize;
+        }
+        out
+    }
+
+    fn uncompressed_size(&self, folder: &CabFolder) -> u64 {
+        self.blocks(folder).iter().map(|&(_, n)| n as u64).sum()
+    }
+
+    /// The folder's uncompressed stream, or `None` if it is larger than
+    /// `max`, truncated, or compressed with LZX/Quantum.
+    fn decode(&self, folder: &CabFolder, max: u64) -> Option<Vec<u8>> {
+        use miniz_oxide::inflate::core::{decompress, inflate_flags, DecompressorOxide};
+        use miniz_oxide::inflate::TINFLStatus;
+        if self.uncompressed_size(folder) > max {
+            return None;
+        }
+        let mut out = Vec::new();
+        for (payload, size) in self.blocks(folder) {
+            match folder.compression & 0x000f {
+                CAB_COMPRESS_NONE => out.extend_from_slice(payload),
+                CAB_COMPRESS_MSZIP => {
+                    // Each block is a complete DEFLATE stream whose matches
+                    // may reach back into the previous blocks' output, so
+                    // inflate in place after it.
+                    let packed = payload.strip_prefix(b"CK")?;
+                    let start = out.len();
+                    out.resize(start + size, 0);
+                    let mut state = DecompressorOxide::new();
+                    let (status, _, written) = decompress(
+                        &mut state,
+                        packed,
+                        &mut out,
+                        start,
+                        inflate_flags::TINFL_FLAG_USING_NON_WRAPPING_OUTPUT_BUF,
+                    );
+                    if status != TINFLStatus::Done || written != size {
+                        return None;
+                    }
+                }
+                _ => return None,
+            }
+        }
+        Some(out)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn tar_entry(name: &str, body: &[u8]) -> Vec<u8> {
+        let mut h = vec![0u8; 512];
+        h[..name.len()].copy_from_slice(name.as_bytes());
+        h[124..135].copy_from_slice(format!("{:011o}", body.len()).as_bytes());
+        h[156] = b'0';
+        h[257..263].copy_from_slice(b"ustar\0");
+        let mut out = h;
+        out.extend_from_slice(body);
+        out.resize(out.len().div_ceil(512) * 512, 0);
+        out
+    }
+
+    fn tar(entries: &[(&str, &[u8])]) -> Vec<u8> {
+        let mut out: Vec<u8> = entries.iter().flat_map(|(n, b)| tar_entry(n, b)).collect();
+        out.extend_from_slice(&[0u8; 1024]);
+        out
+    }
+
+    fn gzip(data: &[u8], name: &str) -> Vec<u8> {
+        let mut gz = flate2::GzBuilder::new()
+            .filename(name)
+            .write(Vec::new(), flate2::Compression::default());
+        std::io::Write::write_all(&mut gz, data).unwrap();
+        gz.finish().unwrap()
+    }
+
+    /// A ZIP with one local header and central directory entry per member;
+    /// `deflate` members are compressed, the rest stored.
+    fn zip(members: &[(&str, &[u8], bool)]) -> Vec<u8> {
+        let mut out = Vec::new();
+        let mut central = Vec::new();
+        for &(name, body, deflate) in members {
+            let packed = if deflate {
+                let mut e =
+                    flate2::write::DeflateEncoder::new(Vec::new(), flate2::Compression::default());
+                std::io::Write::write_all(&mut e, body).unwrap();
+                e.finish().unwrap()
+            } else {
+                body.to_vec()
+            };
+            let method: u16 = if deflate { 8 } else { 0 };
+            let mut fields = Vec::new();
+            fields.extend_from_slice(&method.to_le_bytes());
+            fields.extend_from_slice(&[0; 8]); // time, date, crc
+            fields.extend_from_slice(&(packed.len() as u32).to_le_bytes());
+            fields.extend_from_slice(&(body.len() as u32).to_le_bytes());
+            fields.extend_from_slice(&(name.len() as u16).to_le_bytes());
+            fields.extend_from_slice(&0u16.to_le_bytes()); // extra
+
+            central.extend_from_slice(b"PK\x01\x02\x14\x00\x14\x00\x00\x00");
+            central.extend_from_slice(&fields);
+            central.extend_from_slice(&[0; 10]); // comment, disk, attributes
+            central.extend_from_slice(&(out.len() as u32).to_le_bytes());
+            central.extend_from_slice(name.as_bytes());
+
+            out.extend_from_slice(b"PK\x03\x04\x14\x00\x00\x00");
+            out.extend_from_slice(&fields);
+            out.extend_from_slice(name.as_bytes());
+            out.extend_from_slice(&packed);
+        }
+        let cd_off = out.len() as u32;
+        let n = members.len() as u16;
+        out.extend_from_slice(&central);
+        out.extend_from_slice(b"PK\x05\x06\x00\x00\x00\x00");
+        out.extend_from_slice(&n.to_le_bytes());
+        out.extend_from_slice(&n.to_le_bytes());
+        out.extend_from_slice(&(central.len() as u32).to_le_bytes());
+        out.extend_from_slice(&cd_off.to_le_bytes());
+        out.extend_from_slice(&0u16.to_le_bytes());
+        out
+    }
+
+    fn unpack_default(data: &[u8]) -> UnpackReport {
+        unpack(data, &UnpackLimits::default(), &CancellationToken::new())
+    }
+
+    #[test]
+    fn nested_gzip_tar_is_walked_with_hashes() {
+        let inner = tar(&[
+            ("docs/readme.txt", b"hello nested world"),
+            ("b.bin", &[7u8; 700]),
+        ]);
+        let report = unpack_default(&gzip(&inner, "bundle.tar"));
+        assert_eq!(report.children.len(), 1);
+        let tarball = &report.children[0];
+        assert_eq!(
+            (tarball.name.as_str(), tarball.source.as_str()),
+            ("bundle.tar", "gzip")
+        );
+        assert_eq!(tarball.format, "tar");
+        let names: Vec<&str> = tarball.children.iter().map(|c| c.name.as_str()).collect();
+        assert_eq!(names, ["docs/readme.txt", "b.bin"]);
+        let readme = &tarball.children[0];
+        assert_eq!(readme.depth, 2);
+        assert_eq!(
+            readme.sha256,
+            hex::encode(Sha256::digest(b"hello nested world"))
+        );
+        assert_eq!(report.total_children, 3);
+        assert_eq!(report.max_depth, 2);
+        assert!(report.stopped.is_empty());
+    }
+
+    #[test]
+    fn budgets_are_enforced_and_reported() {
+        let inner = tar(&[("a", &[1u8; 600]), ("b", &[2u8; 600])]);
+        let outer = tar(&[("inner.tar", &inner)]);
+        let shallow = UnpackLimits {
+            max_depth: 1,
+            ..Default::default()
+        };
+        let report = unpack(&outer, &shallow, &CancellationToken::new());
+        assert_eq!(report.total_children, 1);
+        assert_eq!(report.stopped, ["max_depth"]);
+
+        let few = UnpackLimits {
+            max_children: 2,
+            ..Default::default()
+        };
+        let report = unpack(&outer, &few, &CancellationToken::new());
+        assert_eq!(report.total_children, 2);
+        assert_eq!(report.stopped, ["max_children"]);
+
+        let small = UnpackLimits {
+            max_child_size: 1000,
+            ..Default::default()
+        };
+        let report = unpack(&outer, &small, &CancellationToken::new());
+        assert_eq!(report.total_children, 0);
+        assert_eq!(report.stopped, ["max_child_size"]);
+    }
+
+    #[test]
+    fn gzip_bombs_stop_at_the_child_limit() {
+        let bomb = gzip(&vec![0u8; 1 << 20], "zeros");
+        let limits = UnpackLimits {
+            max_child_size: 4096,
+            ..Default::default()
+        };
+        let report = unpack(&bomb, &limits, &CancellationToken::new());
+        assert!(report.children.is_empty());
+        assert_eq!(report.stopped, ["max_child_size"]);
+    }
+
+    #[test]
+    fn zip_members_nest_and_deflate_bombs_are_refused() {
+        let inner = tar(&[("lib/payload.bin", &[0x90u8; 300])]);
+        let archive = zip(&[
+            ("META-INF/", b"", false),
+            ("inner.tar", &inner, false),
+            ("zeros.bin", &vec![0u8; 1 << 20], true),
+        ]);
+        let limits = UnpackLimits {
+            max_child_size: 64 * 1024,
+            ..Default::default()
+        };
+        let report = unpack(&archive, &limits, &CancellationToken::new());
+        assert_eq!(report.children.len(), 1);
+        let tarball = &report.children[0];
+        assert_eq!(
+            (tarball.name.as_str(), tarball.source.as_str()),
+            ("inner.tar", "zip")
+        );
+        assert_eq!(tarball.children[0].name, "lib/payload.bin");
+        assert_eq!(report.stopped, ["max_child_size"]);
+    }
+
+    #[test]
+    fn ar_members_resolve_gnu_long_names() {
+        let mut ar = b"!<arch>\n".to_vec();
+        let mut member = |name: &str, body: &[u8]| {
+            ar.extend_from_slice(format!("{name:<16}{:<32}{:<10}`\n", "0", body.len()).as_bytes());
+            ar.extend_from_slice(body);
+            if body.len() % 2 == 1 {
+                ar.push(b'\n');
+            }
+        };
+        member("//", b"a_rather_long_member_name.o/\n");
+        member("short.o/", b"abc");
+        member("/0", b"long body");
+        let report = unpack_default(&ar);
+        let names: Vec<&str> = report.children.iter().map(|c| c.name.as_str()).collect();
+        assert_eq!(names, ["short.o", "a_rather_long_member_name.o"]);
+        assert!(report.children.iter().all(|c| c.source == "ar"));
+    }
+
+    /// A cabinet with one MSZIP folder of two blocks. The second block was
+    /// deflated with the first as its dictionary (`zlib.compressobj(wbits=-15,
+    /// zdict=block1)`), so it only inflates after its predecessor.
+    #[test]
+    fn cab_mszip_blocks_share_history() {
+        let block1 = b"The quick brown fox jumps over the lazy dog. ".repeat(4);
+        let block2 = b"The lazy dog sleeps; the quick brown fox jumps over it again.";
+        let deflated1 = hex::decode(
+            "0bc94855282ccd4cce56482aca2fcf5348cbaf50c82acd2d2856c82f4b2d5228\
+             014ae72456552aa4e4a7eb29840c0ec500",
+        )
+        .unwrap();
+        let deflated2 =
+            hex::decode("0b41e22914e7a4a616145b8395e03121b34421313d31334f0f00").unwrap();
+        let blocks = [(deflated1, block1.len()), (deflated2, block2.len())];
+
+        let name = b"dir\\fox.txt\0";
+        let files_offset = 36 + 8;
+        let data_offset = files_offset + 16 + name.len();
+        let mut cab = b"MSCF".to_vec();
+        cab.extend_from_slice(&[0; 12]); // reserved, cabinet size, reserved
+        cab.extend_from_slice(&(files_offset as u32).to_le_bytes());
+        cab.extend_from_slice(&[0; 4]);
+        cab.extend_from_slice(&[3, 1]); // version 1.3
+        cab.extend_from_slice(&1u16.to_le_bytes()); // folders
+        cab.extend_from_slice(&1u16.to_le_bytes()); // files
+        cab.extend_from_slice(&[0; 6]); // flags, set id, cabinet index
+        cab.extend_from_slice(&(data_offset as u32).to_le_bytes());
+        cab.extend_from_slice(&(blocks.len() as u16).to_le_bytes());
+        cab.extend_from_slice(&CAB_COMPRESS_MSZIP.to_le_bytes());
+        cab.extend_from_slice(&((block1.len() + block2.len()) as u32).to_le_bytes());
+        cab.extend_from_slice(&[0; 4]); // offset in folder
+        cab.extend_from_slice(&0u16.to_le_bytes()); // folder index
+        cab.extend_from_slice(&[0; 6]); // date, time, attributes
+        cab.extend_from_slice(name);
+        for (packed, size) in &blocks {
+            cab.extend_from_slice(&[0; 4]); // checksum
+            cab.extend_from_slice(&((packed.len() + 2) as u16).to_le_bytes());
+            cab.extend_from_slice(&(*size as u16).to_le_bytes());
+            cab.extend_from_slice(b"CK");
+            cab.extend_from_slice(packed);
+        }
+
+        let report = unpack_default(&cab);
+        assert_eq!(report.children.len(), 1);
+        let fox = &report.children[0];
+        assert_eq!(
+            (fox.name.as_str(), fox.source.as_str()),
+            ("dir/fox.txt", "cab")
+        );
+        let whole = [&block1[..], &block2[..]].concat();
+        assert_eq!(fox.sha256, hex::encode(Sha256::digest(&whole)));
+    }
+
+    #[test]
+    fn opaque_children_carry_a_note() {
+        let xz = [&[0xfd, b'7', b'z', b'X', b'Z', 0x00][..], &[0u8; 64]].concat();
+        let report = unpack_default(&tar(&[("payload.xz", &xz)]));
+        assert_eq!(report.children[0].note.as_deref(), Some("xz not unpacked"));
+        assert!(report.children[0].children.is_empty());
+    }
+}
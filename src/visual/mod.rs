@@ -0,0 +1,449 @@
+//! Byte-structure images.
+//!
+//! Renders a file as a square byte map, laid out along a Hilbert curve or
+//! in row-major scan order and coloured by byte class or local entropy,
+//! with section boundaries outlined; and as a byte digraph, where pixel
+//! `(x, y)` shows how often byte `y` follows byte `x`. Images encode as PNG
+//! or as SVG, which adds a legend naming each outlined section.
+//!
+//! This is the at-a-glance view binvis popularised: zero padding, code,
+//! strings, pointer tables and compressed or encrypted payloads each have a
+//! recognisable texture, and section outlines show where the format says
+//! they should be.
+
+pub mod hilbert;
+pub mod png;
+
+use std::fmt::Write as _;
+use std::str::FromStr;
+
+use crate::core::image::BinaryImage;
+use crate::entropy::shannon_entropy;
+use crate::formats::object_image::ObjectImage;
+
+/// Pixel colour for the part of the grid past the end of the data.
+const BACKGROUND: [u8; 3] = [128, 128, 128];
+
+/// Section outline colours, cycled in section order.
+const PALETTE: [[u8; 3]; 8] = [
+    [255, 217, 47],
+    [102, 194, 165],
+    [252, 141, 98],
+    [141, 160, 203],
+    [231, 138, 195],
+    [166, 216, 84],
+    [229, 196, 148],
+    [255, 255, 255],
+];
+
+/// Entropy/density ramp stops: black, blue, magenta, pale pink.
+const RAMP: [(f64, [u8; 3]); 4] = [
+    (0.0, [0, 0, 0]),
+    (0.5, [0, 0, 180]),
+    (0.75, [200, 0, 200]),
+    (1.0, [255, 220, 220]),
+];
+
+/// How byte offsets map onto the square grid.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Layout {
+    /// Hilbert curve: nearby offsets stay nearby in both dimensions
+    Hilbert,
+    /// Row-major scan, left to right and top to bottom
+    Scan,
+}
+
+impl FromStr for Layout {
+    type Err = String;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        match s.to_ascii_lowercase().as_str() {
+            "hilbert" => Ok(Self::Hilbert),
+            "scan" | "linear" => Ok(Self::Scan),
+            other => Err(format!("unknown layout '{}'", other)),
+        }
+    }
+}
+
+/// What a byte-map pixel's colour encodes.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Coloring {
+    /// 0x00 black, 0xff white, printable ASCII blue, other low bytes
+    /// green, high bytes red (averaged when a pixel covers several bytes)
+    ByteClass,
+    /// Shannon entropy of the bytes around the pixel, on a black (0 bits)
+    /// to pale pink (8 bits) ramp
+    Entropy,
+}
+
+impl FromStr for Coloring {
+    type Err = String;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        match s.to_ascii_lowercase().as_str() {
+            "class" | "byteclass" | "byte_class" => Ok(Self::ByteClass),
+            "entropy" => Ok(Self::Entropy),
+            other => Err(format!("unknown coloring '{}'", other)),
+        }
+    }
+}
+
+/// Byte-map rendering options.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct RenderOptions {
+    pub layout: Layout,
+    pub coloring: Coloring,
+    /// Grid side in pixels; rounded up to a power of two within 16..=4096
+    pub side: u32,
+    /// Bytes of context for `Coloring::Entropy` when a pixel covers fewer
+    pub entropy_window: usize,
+}
+
+impl Default for RenderOptions {
+    fn default() -> Self {
+        Self {
+            layout: Layout::Hilbert,
+            coloring: Coloring::ByteClass,
+            side: 256,
+            entropy_window: 64,
+        }
+    }
+}
+
+/// A named file range to outline (typically a section).
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Overlay {
+    pub name: String,
+    pub offset: u64,
+    pub size: u64,
+}
+
+/// Rendered RGB image plus the legend for its outlines.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Raster {
+    pub width: u32,
+    pub height: u32,
+    /// Row-major pixels
+    pub pixels: Vec<[u8; 3]>,
+    /// `(label, outline colour)` per outlined region
+    pub legend: Vec<(String, [u8; 3])>,
+}
+
+impl Raster {
+    pub fn to_png(&self) -> Vec<u8> {
+        png::encode_rgb(self.width, self.height, &self.pixels)
+    }
+
+    /// SVG with one `rect` per horizontal run of equal pixels, drawn at
+    /// `scale` screen pixels per image pixel, and the legend underneath.
+    pub fn to_svg(&self, scale: u32) -> String {
+        let scale = scale.max(1);
+        let legend_h = self.legend.len() as u32 * 10;
+        let (w, h) = (self.width, self.height + legend_h);
+        let mut s = String::new();
+        let _ = writeln!(
+            s,
+            r#"<svg xmlns="http://www.w3.org/2000/svg" width="{}" height="{}" viewBox="0 0 {} {}">"#,
+            w * scale,
+            h * scale,
+            w,
+            h
+        );
+        s.push_str("<g shape-rendering=\"crispEdges\">\n");
+        for (y, row) in self.pixels.chunks(self.width.max(1) as usize).enumerate() {
+            let mut x = 0;
+            while x < row.len() {
+                let run = row[x..].iter().take_while(|p| **p == row[x]).count();
+                let _ = writeln!(
+                    s,
+                    r#"<rect x="{}" y="{}" width="{}" height="1" fill="{}"/>"#,
+                    x,
+                    y,
+                    run,
+                    hex(row[x])
+                );
+                x += run;
+            }
+        }
+        s.push_str("</g>\n");
+        if !self.legend.is_empty() {
+            s.push_str("<g font-family=\"monospace\" font-size=\"8\">\n");
+            for (i, (label, color)) in self.legend.iter().enumerate() {
+                let y = self.height + i as u32 * 10;
+                let _ = writeln!(
+                    s,
+                    r#"<rect x="1" y="{}" width="8" height="8" fill="{}"/><text x="12" y="{}">{}</text>"#,
+                    y + 1,
+                    hex(*color),
+                    y + 8,
+                    xml_escape(label)
+                );
+            }
+            s.push_str("</g>\n");
+        }
+        s.push_str("</svg>\n");
+        s
+    }
+}
+
+fn hex(c: [u8; 3]) -> String {
+    format!("#{:02x}{:02x}{:02x}", c[0], c[1], c[2])
+}
+
+fn xml_escape(s: &str) -> String {
+    s.replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+}
+
+/// binvis byte-class colour of `b`.
+pub fn byte_class_color(b: u8) -> [u8; 3] {
+    match b {
+        0x00 => [0, 0, 0],
+        0xff => [255, 255, 255],
+        0x20..=0x7e => [55, 126, 184],
+        0x01..=0x1f | 0x7f => [77, 175, 74],
+        _ => [228, 26, 28],
+    }
+}
+
+/// Colour at `t` (0..=1) on the entropy/density ramp.
+fn ramp(t: f64) -> [u8; 3] {
+    let t = t.clamp(0.0, 1.0);
+    for pair in RAMP.windows(2) {
+        let ((t0, c0), (t1, c1)) = (pair[0], pair[1]);
+        if t <= t1 {
+            let f = (t - t0) / (t1 - t0);
+            let mix =
+                |a: u8, b: u8| (f64::from(a) + (f64::from(b) - f64::from(a)) * f).round() as u8;
+            return [mix(c0[0], c1[0]), mix(c0[1], c1[1]), mix(c0[2], c1[2])];
+        }
+    }
+    RAMP[RAMP.len() - 1].1
+}
+
+/// Sections of `data` with file bytes, when it parses as an object file.
+pub fn section_overlays(data: &[u8]) -> Vec<Overlay> {
+    let Ok(img) = ObjectImage::parse(data) else {
+        return Vec::new();
+    };
+    let mut out: Vec<Overlay> = img
+        .sections()
+        .into_iter()
+        .filter_map(|s| {
+            let (offset, size) = s.file_range?;
+            (size > 0).then_some(Overlay {
+                name: s.name,
+                offset,
+                size,
+            })
+        })
+        .collect();
+    out.sort_by_key(|o| o.offset);
+    out
+}
+
+fn grid_position(layout: Layout, side: u32, index: u64) -> (u32, u32) {
+    match layout {
+        Layout::Hilbert => hilbert::d2xy(side, index),
+        Layout::Scan => (
+            (index % u64::from(side)) as u32,
+            (index / u64::from(side)) as u32,
+        ),
+    }
+}
+
+/// Render `data` as a `side`×`side` byte map with `overlays` outlined.
+/// Each pixel covers the same number of bytes, enough to fit the whole
+/// file; pixels past the end are grey.
+pub fn render_byte_map(data: &[u8], opts: &RenderOptions, overlays: &[Overlay]) -> Raster {
+    let side = opts.side.clamp(16, 4096).next_power_of_two();
+    let cells = (side as usize) * (side as usize);
+    let per_pixel = data.len().div_ceil(cells).max(1);
+    let mut pixels = vec![BACKGROUND; cells];
+    // Overlay index owning each grid cell, for outlining.
+    let mut owner: Vec<Option<usize>> = vec![None; cells];
+    let mut used = vec![false; overlays.len()];
+
+    for (i, chunk) in data.chunks(per_pixel).enumerate() {
+        let (x, y) = grid_position(opts.layout, side, i as u64);
+        let cell = (y * side + x) as usize;
+        pixels[cell] = match opts.coloring {
+            Coloring::ByteClass => {
+                let mut sum = [0usize; 3];
+                for &b in chunk {
+                    for (acc, c) in sum.iter_mut().zip(byte_class_color(b)) {
+                        *acc += c as usize;
+                    }
+                }
+                sum.map(|v| (v / chunk.len()) as u8)
+            }
+            Coloring::Entropy => {
+                let start = i * per_pixel;
+                let bytes = if chunk.len() >= opts.entropy_window {
+                    chunk
+                } else {
+                    let lo = (start + chunk.len() / 2).saturating_sub(opts.entropy_window / 2);
+                    let hi = (lo + opts.entropy_window).min(data.len());
+                    &data[hi.saturating_sub(opts.entropy_window)..hi]
+                };
+                ramp(shannon_entropy(bytes) / 8.0)
+            }
+        };
+        let off = (i * per_pixel) as u64;
+        owner[cell] = overlays
+            .iter()
+            .position(|o| off >= o.offset && off - o.offset < o.size);
+    }
+
+    let at = |x: i64, y: i64| -> Option<usize> {
+        if x < 0 || y < 0 || x >= i64::from(side) || y >= i64::from(side) {
+            return None;
+        }
+        owner[(y as usize) * (side as usize) + x as usize]
+    };
+    let mut outline = Vec::new();
+    for y in 0..i64::from(side) {
+        for x in 0..i64::from(side) {
+            let Some(k) = at(x, y) else { continue };
+            used[k] = true;
+            let edge = [(x - 1, y), (x + 1, y), (x, y - 1), (x, y + 1)]
+                .iter()
+                .any(|&(nx, ny)| at(nx, ny) != Some(k));
+            if edge {
+                outline.push(((y as usize) * (side as usize) + x as usize, k));
+            }
+        }
+    }
+    for (cell, k) in outline {
+        pixels[cell] = PALETTE[k % PALETTE.len()];
+    }
+
+    let legend = overlays
+        .iter()
+        .enumerate()
+        .filter(|(k, _)| used[*k])
+        .map(|(k, o)| {
+            (
+                format!("{} {:#x}+{:#x}", o.name, o.offset, o.size),
+                PALETTE[k % PALETTE.len()],
+            )
+        })
+        .collect();
+    Raster {
+        width: side,
+        height: side,
+        pixels,
+        legend,
+    }
+}
+
+/// Render the 256×256 byte digraph of `data`: pixel `(x, y)` is brighter
+/// the more often byte `y` directly follows byte `x` (log scale).
+pub fn render_digraph(data: &[u8]) -> Raster {
+    let mut counts = vec![0u64; 256 * 256];
+    for pair in data.windows(2) {
+        counts[(pair[1] as usize) * 256 + pair[0] as usize] += 1;
+    }
+    let max = counts.iter().copied().max().unwrap_or(0);
+    let scale = ((max + 1) as f64).ln();
+    let pixels = counts
+        .iter()
+        .map(|&c| match c {
+            0 => [0, 0, 0],
+            // Start above black so single occurrences stay visible.
+            _ => ramp(0.15 + 0.85 * ((c + 1) as f64).ln() / scale),
+        })
+        .collect();
+    Raster {
+        width: 256,
+        height: 256,
+        pixels,
+        legend: Vec::new(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn byte_classes_match_binvis() {
+        assert_eq!(byte_class_color(0), [0, 0, 0]);
+        assert_eq!(byte_class_color(0xff), [255, 255, 255]);
+        assert_eq!(byte_class_color(b'A'), [55, 126, 184]);
+        assert_eq!(byte_class_color(b'\n'), [77, 175, 74]);
+        assert_eq!(byte_class_color(0x90), [228, 26, 28]);
+        assert_eq!(ramp(0.0), [0, 0, 0]);
+        assert_eq!(ramp(1.0), [255, 220, 220]);
+        assert_eq!("Linear".parse::<Layout>(), Ok(Layout::Scan));
+        assert!("spiral".parse::<Layout>().is_err());
+    }
+
+    #[test]
+    fn byte_map_outlines_sections_and_greys_the_tail() {
+        // 16x16 grid, one byte per pixel: zeros then text then 0xff.
+        let mut data = vec![0u8; 64];
+        data.extend(std::iter::repeat(b'A').take(64));
+        data.extend(std::iter::repeat(0xffu8).take(64));
+        let opts = RenderOptions {
+            layout: Layout::Scan,
+            side: 16,
+            ..Default::default()
+        };
+        let overlays = [Overlay {
+            name: ".rodata".into(),
+            offset: 64,
+            size: 64,
+        }];
+        let r = render_byte_map(&data, &opts, &overlays);
+        assert_eq!((r.width, r.height, r.pixels.len()), (16, 16, 256));
+        // Rows 4..8 are the overlay: edges outlined, interior blue.
+        assert_eq!(r.pixels[4 * 16], PALETTE[0]);
+        assert_eq!(r.pixels[5 * 16 + 5], byte_class_color(b'A'));
+        assert_eq!(r.pixels[2 * 16 + 5], [0, 0, 0]);
+        assert_eq!(r.pixels[9 * 16 + 5], [255, 255, 255]);
+        assert_eq!(r.pixels[255], BACKGROUND);
+        assert_eq!(r.legend, [(".rodata 0x40+0x40".to_string(), PALETTE[0])]);
+
+        let svg = r.to_svg(2);
+        assert!(svg.starts_with("<svg") && svg.trim_end().ends_with("</svg>"));
+        assert!(svg.contains(r#"width="32" height="52""#));
+        assert!(svg.contains(".rodata 0x40+0x40"));
+    }
+
+    #[test]
+    fn entropy_coloring_separates_padding_from_random() {
+        let mut data = vec![0u8; 2048];
+        let mut x = 0x1234_5678u32;
+        for _ in 0..2048 {
+            x ^= x << 13;
+            x ^= x >> 17;
+            x ^= x << 5;
+            data.push(x as u8);
+        }
+        let opts = RenderOptions {
+            layout: Layout::Hilbert,
+            coloring: Coloring::Entropy,
+            side: 16,
+            entropy_window: 64,
+        };
+        let r = render_byte_map(&data, &opts, &[]);
+        let (x0, y0) = hilbert::d2xy(16, 0);
+        let (x1, y1) = hilbert::d2xy(16, 255);
+        assert_eq!(r.pixels[(y0 * 16 + x0) as usize], [0, 0, 0]);
+        let hot = r.pixels[(y1 * 16 + x1) as usize];
+        assert!(hot[0] > 150 && hot[2] > 150, "{:?}", hot);
+    }
+
+    #[test]
+    fn digraph_counts_successive_byte_pairs() {
+        let r = render_digraph(b"ababab\x00");
+        let px = |x: usize, y: usize| r.pixels[y * 256 + x];
+        assert_ne!(px(b'a' as usize, b'b' as usize), [0, 0, 0]);
+        assert_ne!(px(b'b' as usize, b'a' as usize), [0, 0, 0]);
+        assert_eq!(px(b'a' as usize, b'a' as usize), [0, 0, 0]);
+        assert_eq!(px(b'a' as usize, b'b' as usize), ramp(1.0));
+    }
+}
@@ -0,0 +1,86 @@
+//! Minimal PNG encoder (8-bit RGB, no interlacing).
+//!
+//! Rasters here are small and written once, so every scanline uses filter
+//! type 0 and the whole image is one zlib stream in a single `IDAT`.
+
+use std::io::Write;
+
+use flate2::write::ZlibEncoder;
+use flate2::{Compression, Crc};
+
+const SIGNATURE: &[u8; 8] = b"\x89PNG\r\n\x1a\n";
+
+fn chunk(out: &mut Vec<u8>, kind: &[u8; 4], body: &[u8]) {
+    out.extend_from_slice(&(body.len() as u32).to_be_bytes());
+    out.extend_from_slice(kind);
+    out.extend_from_slice(body);
+    let mut crc = Crc::new();
+    crc.update(kind);
+    crc.update(body);
+    out.extend_from_slice(&crc.sum().to_be_bytes());
+}
+
+/// Encode `pixels` (row-major, `width * height` RGB triples) as a PNG file.
+pub fn encode_rgb(width: u32, height: u32, pixels: &[[u8; 3]]) -> Vec<u8> {
+    debug_assert_eq!(pixels.len(), (width as usize) * (height as usize));
+    let mut raw = Vec::with_capacity(pixels.len() * 3 + height as usize);
+    for row in pixels.chunks(width.max(1) as usize) {
+        raw.push(0); // filter: none
+        raw.extend(row.iter().flatten());
+    }
+    let mut z = ZlibEncoder::new(Vec::new(), Compression::default());
+    // Writing into a Vec cannot fail.
+    let _ = z.write_all(&raw);
+    let idat = z.finish().unwrap_or_default();
+
+    let mut ihdr = Vec::with_capacity(13);
+    ihdr.extend_from_slice(&width.to_be_bytes());
+    ihdr.extend_from_slice(&height.to_be_bytes());
+    // bit depth 8, colour type 2 (RGB), deflate, adaptive filtering, no interlace
+    ihdr.extend_from_slice(&[8, 2, 0, 0, 0]);
+
+    let mut out = Vec::with_capacity(idat.len() + 64);
+    out.extend_from_slice(SIGNATURE);
+    chunk(&mut out, b"IHDR", &ihdr);
+    chunk(&mut out, b"IDAT", &idat);
+    chunk(&mut out, b"IEND", &[]);
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use flate2::read::ZlibDecoder;
+    use std::io::Read;
+
+    #[test]
+    fn chunks_are_well_formed_and_round_trip() {
+        let pixels = [[255, 0, 0], [0, 255, 0], [0, 0, 255], [1, 2, 3]];
+        let png = encode_rgb(2, 2, &pixels);
+        assert_eq!(&png[..8], SIGNATURE);
+
+        let mut pos = 8;
+        let mut kinds = Vec::new();
+        let mut idat = Vec::new();
+        while pos < png.len() {
+            let len = u32::from_be_bytes(png[pos..pos + 4].try_into().unwrap()) as usize;
+            let kind = &png[pos + 4..pos + 8];
+            let body = &png[pos + 8..pos + 8 + len];
+            let mut crc = Crc::new();
+            crc.update(kind);
+            crc.update(body);
+            let stored = u32::from_be_bytes(png[pos + 8 + len..pos + 12 + len].try_into().unwrap());
+            assert_eq!(crc.sum(), stored);
+            if kind == b"IDAT" {
+                idat.extend_from_slice(body);
+            }
+            kinds.push(String::from_utf8(kind.to_vec()).unwrap());
+            pos += 12 + len;
+        }
+        assert_eq!(kinds, ["IHDR", "IDAT", "IEND"]);
+
+        let mut raw = Vec::new();
+        ZlibDecoder::new(&idat[..]).read_to_end(&mut raw).unwrap();
+        assert_eq!(raw, [0, 255, 0, 0, 0, 255, 0, 0, 0, 0, 255, 1, 2, 3]);
+    }
+}
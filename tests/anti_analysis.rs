@@ -0,0 +1,200 @@
+//! Evidence left by the benign anti-analysis samples.
+//!
+//! Binaries come from `samples/build-anti-analysis.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/native/anti-analysis/` as
+//! `<sample>-<driver>-<opt>[.exe]`. Each source documents what a detector
+//! should recover; these tests pin down that the binaries keep that
+//! evidence (encoded blobs, timing imports, TLS callbacks) and never carry
+//! the plaintext, so they stay valid positives as compilers change. The
+//! binaries are git-lfs fixtures, so the tests are ignored by default: build
+//! or fetch them, then run `cargo test --test anti_analysis -- --ignored`.
+
+use glaurung::core::binary::Format;
+use glaurung::symbols::summarize_symbols;
+use glaurung::symbols::types::BudgetCaps;
+use object::{Object, ObjectSection, SectionKind};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-anti-analysis.sh";
+
+/// Strings the samples only ever build at run time.
+const HIDDEN: &[(&str, &[&str])] = &[
+    (
+        "stack_strings",
+        &[
+            "glaurung-stack-bytes",
+            "glaurung-stack-dwords",
+            "glaurung-stack-wide",
+        ],
+    ),
+    (
+        "xor_strings",
+        &["glaurung-xor-single-byte", "glaurung-xor-rolling-key"],
+    ),
+    ("tls_callbacks", &["glaurung-tls-callback"]),
+];
+
+/// Encoded forms from `xor_strings.c` and `tls_callbacks.c`.
+const XOR_SINGLE_BYTE: (&str, &[u8]) = ("glaurung-xor-single-byte", &[0x5a]);
+const XOR_ROLLING_KEY: (&str, &[u8]) = ("glaurung-xor-rolling-key", b"K3y!");
+const XOR_TLS: (&str, &[u8]) = ("glaurung-tls-callback", &[0x37]);
+
+struct AntiSample {
+    path: PathBuf,
+    name: String,
+    windows: bool,
+}
+
+fn anti_samples() -> Vec<AntiSample> {
+    let out = samples("export/native/anti-analysis")
+        .into_iter()
+        .filter_map(|s| {
+            let (name, _) = s.name.split_once('-')?;
+            Some(AntiSample {
+                name: name.to_string(),
+                windows: s.name.ends_with(".exe"),
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "anti-analysis", SCRIPT)
+}
+
+fn contains(haystack: &[u8], needle: &[u8]) -> bool {
+    haystack.windows(needle.len()).any(|w| w == needle)
+}
+
+fn utf16le(s: &str) -> Vec<u8> {
+    s.encode_utf16().flat_map(|u| u.to_le_bytes()).collect()
+}
+
+fn xor(plain: &str, key: &[u8]) -> Vec<u8> {
+    plain
+        .bytes()
+        .enumerate()
+        .map(|(i, b)| b ^ key[i % key.len()])
+        .collect()
+}
+
+/// Imported function names, without ELF symbol versions.
+fn imports(data: &[u8], windows: bool) -> Vec<String> {
+    let format = if windows { Format::PE } else { Format::ELF };
+    summarize_symbols(data, format, &BudgetCaps::default())
+        .import_names
+        .unwrap_or_default()
+        .into_iter()
+        .map(|n| n.split('@').next().unwrap_or_default().to_string())
+        .collect()
+}
+
+#[test]
+#[ignore = "needs the anti-analysis samples from samples/build-anti-analysis.sh"]
+fn plaintext_never_appears() {
+    for s in anti_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let hidden = HIDDEN
+            .iter()
+            .find(|(name, _)| *name == s.name)
+            .map_or(&[][..], |(_, strings)| *strings);
+        for plain in hidden {
+            assert!(
+                !contains(&data, plain.as_bytes()) && !contains(&data, &utf16le(plain)),
+                "{}: {:?} is stored in plain text",
+                rel(&s.path),
+                plain
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the anti-analysis samples from samples/build-anti-analysis.sh"]
+fn encoded_strings_are_present() {
+    for s in anti_samples() {
+        let encoded: &[(&str, &[u8])] = match s.name.as_str() {
+            "xor_strings" => &[XOR_SINGLE_BYTE, XOR_ROLLING_KEY],
+            "tls_callbacks" => &[XOR_TLS],
+            _ => continue,
+        };
+        let data = require_fixture(&s.path, SCRIPT);
+        for (plain, key) in encoded {
+            assert!(
+                contains(&data, &xor(plain, key)),
+                "{}: encoded {:?} (key {:02x?}) not found",
+                rel(&s.path),
+                plain,
+                key
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the anti-analysis samples from samples/build-anti-analysis.sh"]
+fn timing_sources_are_visible() {
+    for s in anti_samples()
+        .into_iter()
+        .filter(|s| s.name == "timing_checks")
+    {
+        let data = require_fixture(&s.path, SCRIPT);
+        let imported = imports(&data, s.windows);
+        let wanted: &[&str] = if s.windows {
+            &["QueryPerformanceCounter", "GetTickCount"]
+        } else {
+            &["clock_gettime"]
+        };
+        for name in wanted {
+            assert!(
+                imported.iter().any(|i| i == name),
+                "{}: {} not imported (imports: {:?})",
+                rel(&s.path),
+                name,
+                imported
+            );
+        }
+
+        let obj = object::File::parse(&*data).unwrap();
+        if matches!(
+            obj.architecture(),
+            object::Architecture::X86_64 | object::Architecture::I386
+        ) {
+            // rdtsc is 0f 31.
+            let has_rdtsc = obj
+                .sections()
+                .filter(|sec| sec.kind() == SectionKind::Text)
+                .filter_map(|sec| sec.data().ok())
+                .any(|code| contains(code, &[0x0f, 0x31]));
+            assert!(has_rdtsc, "{}: no rdtsc in code", rel(&s.path));
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the anti-analysis samples from samples/build-anti-analysis.sh"]
+fn tls_callbacks_are_registered() {
+    for s in anti_samples()
+        .into_iter()
+        .filter(|s| s.name == "tls_callbacks")
+    {
+        let data = require_fixture(&s.path, SCRIPT);
+        let sum = summarize_symbols(&data, Format::PE, &BudgetCaps::default());
+        assert!(sum.tls_used, "{}: no TLS directory", rel(&s.path));
+        assert_eq!(
+            sum.tls_callback_count,
+            Some(2),
+            "{}: callback count",
+            rel(&s.path)
+        );
+        let imported = imports(&data, true);
+        assert!(
+            imported.iter().any(|i| i == "IsDebuggerPresent"),
+            "{}: IsDebuggerPresent not imported",
+            rel(&s.path)
+        );
+    }
+}
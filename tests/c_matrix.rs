@@ -0,0 +1,173 @@
+//! Function discovery, demangling, and compiler identification across the
+//! C/C++ compiler and optimization matrix.
+//!
+//! Binaries come from `samples/build-c-matrix.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/native/matrix/<family>/`
+//! as `<sample>-<driver>-<opt>[.exe]`. They are git-lfs fixtures, so the tests
+//! are ignored by default: build or fetch the matrix, then run
+//! `cargo test --test c_matrix -- --ignored`.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use glaurung::triage::compiler_detection::{
+    detect_from_elf_comment, detect_from_rich_header, CompilerVendor,
+};
+use glaurung::triage::rich_header::parse_rich_header;
+use object::{Object, ObjectSection, ObjectSymbol};
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-c-matrix.sh";
+
+/// Functions in `source/c/algos.c` that are NOINLINE and so must exist as
+/// separate functions at every optimization level.
+const ALGOS_FUNCTIONS: &[&str] = &[
+    "op_add",
+    "op_sub",
+    "op_mul",
+    "op_xor",
+    "classify",
+    "fib",
+    "is_even",
+    "is_odd",
+    "compare_ints",
+    "sum_varargs",
+    "die",
+    "apply",
+    "checksum",
+    "main",
+];
+
+struct MatrixBinary {
+    path: PathBuf,
+    family: String,
+    sample: String,
+}
+
+fn matrix_binaries() -> Vec<MatrixBinary> {
+    let out = samples("export/native/matrix")
+        .into_iter()
+        .filter(|s| !s.name.ends_with(".pdb"))
+        // Stripped copies are scored by tests/ground_truth.rs.
+        .filter(|s| !s.name.contains("-stripped"))
+        .filter_map(|s| {
+            let (sample, _) = s.name.split_once('-')?;
+            let family = s.path.parent()?.file_name()?.to_string_lossy();
+            Some(MatrixBinary {
+                family: family.into_owned(),
+                sample: sample.to_string(),
+                path: s.path.clone(),
+            })
+        })
+        .collect();
+    require_any(out, "C/C++ matrix", SCRIPT)
+}
+
+/// Symbol name → address, with compiler clone suffixes (`.constprop.0`,
+/// `.isra.0`, `.lto_priv.0`) removed.
+fn defined_symbols(obj: &object::File) -> HashMap<String, u64> {
+    obj.symbols()
+        .filter(|s| s.is_definition() && s.kind() == object::SymbolKind::Text)
+        .filter_map(|s| {
+            let name = s.name().ok()?;
+            let base = name.split('.').next().unwrap_or(name);
+            Some((base.to_string(), s.address()))
+        })
+        .collect()
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn compiler_family_is_identified() {
+    for bin in matrix_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let info = match bin.family.as_str() {
+            "msvc" => parse_rich_header(&data).and_then(|rh| detect_from_rich_header(&rh)),
+            _ => {
+                let obj = object::File::parse(&*data).unwrap();
+                let comment = obj
+                    .section_by_name(".comment")
+                    .and_then(|s| s.data().ok())
+                    .map(|d| String::from_utf8_lossy(d).into_owned())
+                    .unwrap_or_default();
+                detect_from_elf_comment(&comment)
+            }
+        };
+        let expected = match bin.family.as_str() {
+            "gcc" => CompilerVendor::Gnu,
+            "clang" => CompilerVendor::Llvm,
+            "msvc" => CompilerVendor::Microsoft,
+            other => panic!("unexpected compiler family directory: {}", other),
+        };
+        assert_eq!(info.map(|i| i.vendor), Some(expected), "{}", rel(&bin.path));
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn discovery_finds_noinline_functions_at_every_level() {
+    let budgets = Budgets {
+        max_functions: 0,
+        max_blocks: 100_000,
+        max_instructions: 1_000_000,
+        timeout_ms: 10_000,
+    };
+    for bin in matrix_binaries() {
+        // MSVC keeps symbols in the PDB, not the image; there is no ground
+        // truth to compare against here.
+        if bin.sample != "algos" || bin.family == "msvc" {
+            continue;
+        }
+        let data = require_fixture(&bin.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let symbols = defined_symbols(&obj);
+        let (funcs, _cg) = analyze_functions_bytes(&data, &budgets);
+        for name in ALGOS_FUNCTIONS {
+            let addr = symbols
+                .get(*name)
+                .unwrap_or_else(|| panic!("{}: no symbol for {}", rel(&bin.path), name));
+            assert!(
+                funcs.iter().any(|f| f.entry_point.value == *addr),
+                "{}: {} at {:#x} not discovered",
+                rel(&bin.path),
+                name,
+                addr
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn itanium_symbols_demangle_at_every_level() {
+    for bin in matrix_binaries() {
+        if bin.sample != "shapes" || bin.family == "msvc" {
+            continue;
+        }
+        let data = require_fixture(&bin.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let demangled: Vec<String> = obj
+            .symbols()
+            .filter_map(|s| s.name().ok())
+            .filter(|n| n.starts_with("_Z"))
+            .filter_map(glaurung::demangle::demangle_one)
+            .map(|r| r.demangled)
+            .collect();
+        for expected in [
+            "geometry::Circle::area() const",
+            "geometry::Rect::area() const",
+            "util::Accumulator<double>::mean() const",
+        ] {
+            assert!(
+                demangled.iter().any(|d| d.starts_with(expected)),
+                "{}: no symbol demangles to {}",
+                rel(&bin.path),
+                expected
+            );
+        }
+    }
+}
@@ -66,3 +66,93 @@ pub mod file_utils {
         }
     }
 }
+
+/// Sample binaries built by the `samples/build-*.sh` scripts and stored in
+/// git-lfs. Tests that need them are `#[ignore]`d and run with
+/// `cargo test -- --ignored` once the fixtures are present.
+pub mod fixtures {
+    use std::path::{Path, PathBuf};
+
+    /// Root of the per-platform sample binaries.
+    pub const PLATFORMS: &str = "samples/binaries/platforms";
+
+    /// A file found by `samples`.
+    pub struct Sample {
+        /// `<os>` and `<arch>` directory names under `PLATFORMS`
+        pub os: String,
+        pub arch: String,
+        /// File name, e.g. `hello-go1.23.5-stripped.exe`
+        pub name: String,
+        pub path: PathBuf,
+    }
+
+    /// Every file under `<PLATFORMS>/<os>/<arch>/<dir>/`, subdirectories
+    /// included, for every platform, sorted by path. Sample-matrix tests
+    /// enumerate their fixtures with this and parse `name` themselves.
+    pub fn samples(dir: &str) -> Vec<Sample> {
+        fn walk(dir: &Path, os: &str, arch: &str, out: &mut Vec<Sample>) {
+            for entry in std::fs::read_dir(dir).into_iter().flatten().flatten() {
+                let path = entry.path();
+                if path.is_dir() {
+                    walk(&path, os, arch, out);
+                } else {
+                    out.push(Sample {
+                        os: os.to_string(),
+                        arch: arch.to_string(),
+                        name: entry.file_name().to_string_lossy().into_owned(),
+                        path,
+                    });
+                }
+            }
+        }
+        let mut out = Vec::new();
+        for os in std::fs::read_dir(PLATFORMS).into_iter().flatten().flatten() {
+            for arch in std::fs::read_dir(os.path()).into_iter().flatten().flatten() {
+                let os_name = os.file_name().to_string_lossy().into_owned();
+                let arch_name = arch.file_name().to_string_lossy().into_owned();
+                walk(&arch.path().join(dir), &os_name, &arch_name, &mut out);
+            }
+        }
+        out.sort_by(|a, b| a.path.cmp(&b.path));
+        out
+    }
+
+    /// Fixture bytes, or `None` for a missing file or a git-lfs pointer that
+    /// was never fetched.
+    pub fn read_fixture(path: &Path) -> Option<Vec<u8>> {
+        let data = std::fs::read(path).ok()?;
+        (!data.starts_with(b"version https://git-lfs.github.com/spec/")).then_some(data)
+    }
+
+    /// Fixture bytes, panicking with how to produce them when absent.
+    pub fn require_fixture(path: &Path, script: &str) -> Vec<u8> {
+        read_fixture(path).unwrap_or_else(|| {
+            panic!(
+                "{}: missing or an unfetched git-lfs pointer; run samples/{} or `git lfs pull`",
+                path.display(),
+                script
+            )
+        })
+    }
+
+    /// `found`, panicking when a fixture set is empty so a test cannot pass
+    /// without checking anything.
+    pub fn require_any<T>(found: Vec<T>, what: &str, script: &str) -> Vec<T> {
+        assert!(
+            !found.is_empty(),
+            "no {} fixtures found; run samples/{} or `git lfs pull`",
+            what,
+            script
+        );
+        found
+    }
+
+    /// `path` relative to the sample tree, for assertion messages.
+    pub fn rel(path: &Path) -> String {
+        path.strip_prefix(PLATFORMS)
+            .or_else(|_| path.strip_prefix("samples"))
+            .unwrap_or(path)
+            .display()
+            .to_string()
+    }
+}
@@ -0,0 +1,192 @@
+//! Exception-handling metadata recovery on the matrix C++ samples.
+//!
+//! Binaries come from `samples/build-c-matrix.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/native/matrix/<family>/`
+//! as `exceptions-<driver>-<opt>[.exe]`, built from
+//! `samples/source/cpp/exceptions.cpp`: typed catches, `catch (...)`, a
+//! cleanup-only frame, nested tries, and (msvc only) `__try`/`__except` and
+//! `__try`/`__finally`. gcc and clang builds carry LSDAs, msvc builds
+//! FuncInfo and scope tables; both survive stripping, so `-stripped` copies
+//! are checked too. The binaries are git-lfs fixtures, so the tests are
+//! ignored by default: build or fetch the matrix, then run
+//! `cargo test --test cpp_exceptions -- --ignored`.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use glaurung::analysis::eh::{recover_eh_info, EhInfo, HandlerKind};
+use glaurung::analysis::pipeline::{run_pipeline, PassRegistry, Profile};
+use glaurung::formats::object_image::ObjectImage;
+use std::collections::{BTreeSet, HashSet};
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-c-matrix.sh";
+
+/// Catch clauses that must share one function, one set per function in
+/// `exceptions.cpp` that catches.
+const CATCHES: &[&[&str]] = &[
+    &["catch (eh::ParseError)", "catch (int)"],
+    &["catch (...)"],
+    &["catch (std::out_of_range)", "catch (std::exception)"],
+];
+
+fn exception_binaries() -> Vec<PathBuf> {
+    let out = samples("export/native/matrix")
+        .into_iter()
+        .filter(|s| s.name.starts_with("exceptions-") && !s.name.ends_with(".pdb"))
+        .map(|s| s.path)
+        .collect();
+    require_any(out, "C++ exceptions", SCRIPT)
+}
+
+fn recover(path: &Path, data: &[u8]) -> EhInfo {
+    recover_eh_info(data)
+        .unwrap_or_else(|| panic!("{}: no exception metadata recovered", rel(path)))
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn catch_clauses_match_the_source() {
+    for path in exception_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let info = recover(&path, &data);
+        let per_function: Vec<BTreeSet<String>> = info
+            .functions
+            .iter()
+            .map(|f| {
+                f.regions
+                    .iter()
+                    .flat_map(|r| &r.handlers)
+                    .map(|h| h.describe())
+                    .collect()
+            })
+            .collect();
+        for want in CATCHES {
+            assert!(
+                per_function
+                    .iter()
+                    .any(|got| want.iter().all(|w| got.contains(*w))),
+                "{}: no function catches {:?}; got {:?}",
+                rel(&path),
+                want,
+                per_function
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn cleanups_and_seh_handlers_are_recovered() {
+    for path in exception_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let info = recover(&path, &data);
+        let kinds: HashSet<HandlerKind> = info
+            .functions
+            .iter()
+            .flat_map(|f| &f.regions)
+            .flat_map(|r| &r.handlers)
+            .map(|h| h.kind)
+            .collect();
+        if data.starts_with(b"MZ") {
+            // seh_except filters through seh_filter; seh_finally has a
+            // termination handler.
+            let filtered = info
+                .functions
+                .iter()
+                .flat_map(|f| &f.regions)
+                .flat_map(|r| &r.handlers)
+                .any(|h| h.kind == HandlerKind::Except && h.filter.is_some());
+            assert!(filtered, "{}: no filtered __except", rel(&path));
+            assert!(
+                kinds.contains(&HandlerKind::Finally),
+                "{}: no __finally",
+                rel(&path)
+            );
+        } else {
+            // MSVC runs destructors from the unwind map, outside any try
+            // block; the LSDA lists them as cleanup landing pads.
+            assert!(
+                kinds.contains(&HandlerKind::Cleanup),
+                "{}: no cleanup landing pad",
+                rel(&path)
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn discovery_follows_handlers_and_landing_pads() {
+    for path in exception_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let info = recover(&path, &data);
+        let (funcs, _) = analyze_functions_bytes(&data, &Budgets::default());
+        let entries: BTreeSet<u64> = funcs.iter().map(|f| f.entry_point.value).collect();
+        let blocks: BTreeSet<u64> = funcs
+            .iter()
+            .flat_map(|f| &f.basic_blocks)
+            .map(|b| b.start_address.value)
+            .collect();
+        for va in info.handler_functions() {
+            assert!(
+                entries.contains(&va),
+                "{}: handler {:#x} not discovered as a function",
+                rel(&path),
+                va
+            );
+        }
+        for f in &info.functions {
+            if !entries.contains(&f.start) {
+                continue;
+            }
+            for pad in f.regions.iter().filter_map(|r| r.landing_pad) {
+                assert!(
+                    blocks.contains(&pad),
+                    "{}: landing pad {:#x} of {:#x} is not a block",
+                    rel(&path),
+                    pad,
+                    f.start
+                );
+            }
+        }
+        if !info.landing_pads().is_empty() {
+            assert!(
+                funcs.iter().any(|f| !f.exception_edges.is_empty()),
+                "{}: no exception edges",
+                rel(&path)
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn exceptions_pass_reports_catching_functions() {
+    for path in exception_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let img = ObjectImage::parse(&data).unwrap();
+        let reg = PassRegistry::with_builtin();
+        let (report, ctx) = run_pipeline(&reg, &Profile::default(), &img).unwrap();
+        assert!(
+            ctx.artifact::<EhInfo>().is_some(),
+            "{}: no eh_info artifact",
+            rel(&path)
+        );
+        let claims: Vec<&str> = report
+            .findings
+            .iter()
+            .filter(|f| f.category == "exception_handler")
+            .map(|f| f.claim.as_str())
+            .collect();
+        assert!(
+            claims.iter().any(|c| c.contains("catch (eh::ParseError)")),
+            "{}: ParseError handler missing from {:?}",
+            rel(&path),
+            claims
+        );
+    }
+}
@@ -0,0 +1,191 @@
+//! C++ class hierarchy recovery from RTTI on the matrix C++ samples.
+//!
+//! Binaries come from `samples/build-c-matrix.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/native/matrix/<family>/`
+//! as `hierarchy-<driver>-<opt>[.exe]`, built from
+//! `samples/source/cpp/hierarchy.cpp`: single, multiple, and virtual
+//! inheritance, an abstract class, and a class template. gcc and clang
+//! builds exercise the Itanium ABI, msvc builds the MSVC one. RTTI survives
+//! stripping, so `-stripped` copies (`scripts/make_ground_truth.py`) are
+//! checked too. The binaries are git-lfs fixtures, so the tests are ignored
+//! by default: build or fetch the matrix, then run
+//! `cargo test --test cpp_rtti -- --ignored`.
+
+use glaurung::analysis::pipeline::{run_pipeline, PassRegistry, Profile};
+use glaurung::analysis::rtti::{recover_class_hierarchy, ClassHierarchy, RttiAbi};
+use glaurung::formats::object_image::ObjectImage;
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-c-matrix.sh";
+
+/// Every class in `hierarchy.cpp` with its direct bases, as
+/// `(name, is_virtual)`, in declaration order.
+const CLASSES: &[(&str, &[(&str, bool)])] = &[
+    ("zoo::Animal", &[]),
+    ("zoo::Named", &[]),
+    ("zoo::Dog", &[("zoo::Animal", false)]),
+    (
+        "zoo::RoboDog",
+        &[("zoo::Dog", false), ("zoo::Named", false)],
+    ),
+    ("zoo::Base", &[]),
+    ("zoo::Left", &[("zoo::Base", true)]),
+    ("zoo::Right", &[("zoo::Base", true)]),
+    (
+        "zoo::Diamond",
+        &[("zoo::Left", false), ("zoo::Right", false)],
+    ),
+    ("zoo::Cage<zoo::Dog>", &[("zoo::Named", false)]),
+];
+
+/// The one abstract class in `CLASSES`.
+const ABSTRACT: &str = "zoo::Animal";
+
+fn hierarchy_binaries() -> Vec<PathBuf> {
+    let out = samples("export/native/matrix")
+        .into_iter()
+        .filter(|s| s.name.starts_with("hierarchy-") && !s.name.ends_with(".pdb"))
+        .map(|s| s.path)
+        .collect();
+    require_any(out, "C++ hierarchy", SCRIPT)
+}
+
+fn recover(path: &Path, data: &[u8]) -> ClassHierarchy {
+    recover_class_hierarchy(data).unwrap_or_else(|| panic!("{}: no RTTI recovered", rel(path)))
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn classes_and_bases_match_the_source() {
+    for path in hierarchy_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let h = recover(&path, &data);
+        let want_abi = if data.starts_with(b"MZ") {
+            RttiAbi::Msvc
+        } else {
+            RttiAbi::Itanium
+        };
+        assert_eq!(h.abi, want_abi, "{}", rel(&path));
+        for (name, bases) in CLASSES {
+            let class = h
+                .class(name)
+                .unwrap_or_else(|| panic!("{}: class {} not recovered", rel(&path), name));
+            let got: Vec<(&str, bool)> = class
+                .bases
+                .iter()
+                .map(|b| (b.name.as_str(), b.is_virtual))
+                .collect();
+            assert_eq!(got, bases.to_vec(), "{}: bases of {}", rel(&path), name);
+            assert!(
+                class.bases.iter().all(|b| b.is_public),
+                "{}: {}",
+                rel(&path),
+                class.declaration()
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn every_class_has_its_vtables() {
+    for path in hierarchy_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let h = recover(&path, &data);
+        // Animal is abstract: optimizers may drop its vtable along with the
+        // inlined constructor that was its only user.
+        for (name, _) in CLASSES.iter().filter(|(n, _)| *n != ABSTRACT) {
+            let class = h.class(name).unwrap();
+            assert!(
+                !class.vtables.is_empty(),
+                "{}: {} has no vtable",
+                rel(&path),
+                name
+            );
+            assert_eq!(
+                class.vtables[0].offset_to_top,
+                0,
+                "{}: {} primary vtable",
+                rel(&path),
+                name
+            );
+        }
+        // Named lives at a non-zero offset inside RoboDog, so it needs a
+        // vtable of its own there.
+        let robo = h.class("zoo::RoboDog").unwrap();
+        assert!(
+            robo.vtables.iter().any(|v| v.offset_to_top < 0),
+            "{}: RoboDog has no secondary vtable ({} vtables)",
+            rel(&path),
+            robo.vtables.len()
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn methods_attach_to_the_class_that_defines_them() {
+    for path in hierarchy_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let h = recover(&path, &data);
+        let robo = h.class("zoo::RoboDog").unwrap();
+        assert!(!robo.methods.is_empty(), "{}: RoboDog", rel(&path));
+        // Dog inherits legs() from Animal; it must stay Animal's.
+        let animal = h.class(ABSTRACT).unwrap();
+        let dog = h.class("zoo::Dog").unwrap();
+        for m in &dog.methods {
+            assert!(
+                !animal.methods.iter().any(|a| a.va == m.va),
+                "{}: {} listed under both Animal and Dog",
+                rel(&path),
+                m.name
+            );
+        }
+        // ELF matrix builds keep their symbols (msvc's are in the PDB).
+        let stripped = path.to_string_lossy().contains("-stripped");
+        if !data.starts_with(b"MZ") && !stripped {
+            assert!(
+                robo.methods
+                    .iter()
+                    .any(|m| m.name.starts_with("zoo::RoboDog::battery")),
+                "{}: RoboDog::battery not attached",
+                rel(&path)
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the C/C++ matrix from samples/build-c-matrix.sh"]
+fn rtti_pass_reports_each_class() {
+    for path in hierarchy_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let img = ObjectImage::parse(&data).unwrap();
+        let reg = PassRegistry::with_builtin();
+        let (report, ctx) = run_pipeline(&reg, &Profile::default(), &img).unwrap();
+        assert!(
+            ctx.artifact::<ClassHierarchy>().is_some(),
+            "{}: no class_hierarchy artifact",
+            rel(&path)
+        );
+        let claims: Vec<&str> = report
+            .findings
+            .iter()
+            .filter(|f| f.category == "cpp_class")
+            .map(|f| f.claim.as_str())
+            .collect();
+        assert!(
+            claims
+                .iter()
+                .any(|c| c.contains("zoo::RoboDog : public zoo::Dog, public zoo::Named")),
+            "{}: RoboDog finding missing from {:?}",
+            rel(&path),
+            claims
+        );
+    }
+}
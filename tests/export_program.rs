@@ -0,0 +1,37 @@
+//! The exported program model on a real ELF.
+//!
+//! Uses `samples/binaries/platforms/linux/amd64/export/native/gcc/O2/
+//! hello-gcc-O2` from `samples/build-multiplatform.sh`. It is a git-lfs
+//! fixture, so the test is ignored by default: fetch it with `git lfs pull`,
+//! then run `cargo test --test export_program -- --ignored`.
+
+use glaurung::analysis::cfg::Budgets;
+use glaurung::export::{build_program, render, ExportFormat, Program};
+use std::path::Path;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{require_fixture, PLATFORMS};
+
+const SCRIPT: &str = "build-multiplatform.sh";
+
+#[test]
+#[ignore = "needs the hello-gcc-O2 sample from git-lfs"]
+fn program_model_covers_a_real_elf() {
+    let path = Path::new(PLATFORMS).join("linux/amd64/export/native/gcc/O2/hello-gcc-O2");
+    let data = require_fixture(&path, SCRIPT);
+    let program = build_program(&data, "hello-gcc-O2", &Budgets::default());
+    assert_eq!(program.format, "ELF");
+    assert_eq!(program.arch, "x86_64");
+    assert!(program
+        .sections
+        .iter()
+        .any(|s| s.name == ".text" && s.execute));
+    assert!(program.functions.iter().any(|f| f.name == "main"));
+    assert!(program.strings.iter().any(|s| s.va.is_some()));
+    assert!(program.xrefs.iter().any(|x| x.kind == "call"));
+    assert!(!program.indicators.is_empty());
+    let json: Program = serde_json::from_slice(&render(&program, ExportFormat::Json)).unwrap();
+    assert_eq!(json, program);
+}
@@ -0,0 +1,76 @@
+//! Replays inputs that once crashed a fuzz target.
+//!
+//! `fuzz/triage-crashes.sh --promote` copies minimized crash inputs to
+//! `fuzz/regressions/<target>/`. Each is fed to the same entry points the
+//! fuzz targets drive; the test fails if any of them panics again.
+
+use glaurung::core::triage::Budgets;
+use glaurung::formats::elf::ElfParser;
+use glaurung::formats::open_image;
+use glaurung::formats::pe::PeParser;
+use glaurung::triage::api::analyze_bytes;
+use glaurung::triage::io::IOLimits;
+use glaurung::triage::recurse::RecursionEngine;
+use std::path::{Path, PathBuf};
+
+const REGRESSIONS: &str = "fuzz/regressions";
+
+fn inputs() -> Vec<PathBuf> {
+    let mut out = Vec::new();
+    let Ok(targets) = std::fs::read_dir(REGRESSIONS) else {
+        return out;
+    };
+    for target in targets.flatten() {
+        let Ok(files) = std::fs::read_dir(target.path()) else {
+            continue;
+        };
+        out.extend(files.flatten().map(|f| f.path()));
+    }
+    out.sort();
+    out
+}
+
+fn rel(p: &Path) -> String {
+    p.strip_prefix(REGRESSIONS)
+        .unwrap_or(p)
+        .display()
+        .to_string()
+}
+
+/// Every parser entry point, whichever target found the input: a crash in
+/// one parser is often reachable from the others.
+fn exercise(data: &[u8]) {
+    if let Ok(elf) = ElfParser::parse(data) {
+        if let Ok(Some(symbols)) = elf.symbols() {
+            let _ = symbols.functions();
+        }
+        let _ = elf.dynamic();
+        let _ = elf.validate();
+    }
+    if let Ok(pe) = PeParser::new(data) {
+        let _ = pe.imports();
+        let _ = pe.exports();
+        let _ = pe.resources();
+        let _ = pe.anomalies();
+    }
+    if let Some(image) = open_image(data) {
+        let _ = image.sections();
+        let _ = image.symbols();
+        let _ = image.imports();
+    }
+    let mut budgets = Budgets::new(0, 0, 0);
+    let _ = RecursionEngine::new(3).discover_children(data, &mut budgets, 0);
+    let _ = analyze_bytes(data, &IOLimits::default());
+}
+
+#[test]
+fn fixed_crashes_stay_fixed() {
+    let mut failures = Vec::new();
+    for path in inputs() {
+        let data = std::fs::read(&path).unwrap();
+        if std::panic::catch_unwind(|| exercise(&data)).is_err() {
+            failures.push(rel(&path));
+        }
+    }
+    assert!(failures.is_empty(), "still crashing: {:?}", failures);
+}
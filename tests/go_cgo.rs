@@ -0,0 +1,182 @@
+//! pclntab and symbol recovery on externally linked cgo binaries.
+//!
+//! Binaries come from `samples/build-go-cgo.sh` and are named
+//! `cgo-go<version>[-stripped|-static]` under
+//! `samples/binaries/platforms/linux/<arch>/export/go/cgo/`. The binaries
+//! are git-lfs fixtures, so the tests are ignored by default: build or fetch
+//! them, then run `cargo test --test go_cgo -- --ignored`.
+
+use glaurung::analysis::gopclntab::extract_go_functions;
+use glaurung::triage::compiler_detection::{
+    detect_language_and_compiler, extract_go_version, SourceLanguage,
+};
+use object::{Object, ObjectSymbol};
+use std::collections::{HashMap, HashSet};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-go-cgo.sh";
+
+/// C functions defined in `source/go/cgo/fold.c`.
+const C_FUNCTIONS: &[&str] = &["c_fold", "c_checksum"];
+
+struct CgoBinary {
+    path: PathBuf,
+    version: String,
+    stripped: bool,
+}
+
+fn cgo_binaries() -> Vec<CgoBinary> {
+    let out = samples("export/go/cgo")
+        .into_iter()
+        .filter(|s| s.os == "linux")
+        .filter_map(|s| {
+            let stem = s
+                .name
+                .trim_end_matches("-stripped")
+                .trim_end_matches("-static");
+            let version = stem.strip_prefix("cgo-go")?;
+            Some(CgoBinary {
+                version: version.to_string(),
+                stripped: s.name.ends_with("-stripped"),
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "Go cgo", SCRIPT)
+}
+
+/// Defined symbol name → address, with Go's `.abi0` linker suffix removed
+/// so names compare equal to pclntab's.
+fn symbol_addresses(obj: &object::File) -> HashMap<String, u64> {
+    obj.symbols()
+        .filter(|s| s.is_definition())
+        .filter_map(|s| {
+            let name = s.name().ok()?;
+            Some((name.trim_end_matches(".abi0").to_string(), s.address()))
+        })
+        .collect()
+}
+
+#[test]
+#[ignore = "needs the cgo samples from samples/build-go-cgo.sh"]
+fn pclntab_covers_go_side_of_language_crossings() {
+    for bin in cgo_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let funcs =
+            extract_go_functions(&data).unwrap_or_else(|e| panic!("{}: {:?}", rel(&bin.path), e));
+        let has_prefix = |p: &str| funcs.iter().any(|f| f.name.starts_with(p));
+        assert!(has_prefix("main.main"), "{}: no main.main", rel(&bin.path));
+        // Go -> C stubs and the C -> Go export wrapper are Go code.
+        assert!(
+            has_prefix("main._Cfunc_c_fold") && has_prefix("main._Cfunc_c_checksum"),
+            "{}: no _Cfunc_ stubs",
+            rel(&bin.path)
+        );
+        assert!(
+            funcs
+                .iter()
+                .any(|f| f.name.starts_with("_cgoexp_") && f.name.ends_with("_goAccumulate")),
+            "{}: no _cgoexp_ wrapper for goAccumulate",
+            rel(&bin.path)
+        );
+        assert!(
+            funcs.iter().any(|f| f.name == "crosscall2"),
+            "{}: no crosscall2",
+            rel(&bin.path)
+        );
+        // The C side is linked by the system linker and is not in pclntab.
+        for name in C_FUNCTIONS {
+            assert!(
+                !funcs.iter().any(|f| f.name == *name),
+                "{}: C function {} in pclntab",
+                rel(&bin.path),
+                name
+            );
+        }
+    }
+}
+
+/// With external linking, Go text does not start at the beginning of
+/// .text; pclntab entries must still land on the linker's addresses.
+#[test]
+#[ignore = "needs the cgo samples from samples/build-go-cgo.sh"]
+fn pclntab_entries_match_symbol_table() {
+    for bin in cgo_binaries().into_iter().filter(|b| !b.stripped) {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let symbols = symbol_addresses(&obj);
+        let funcs = extract_go_functions(&data).unwrap();
+        let mut checked = 0;
+        for f in &funcs {
+            if let Some(&addr) = symbols.get(&f.name) {
+                assert_eq!(
+                    f.entry_va,
+                    addr,
+                    "{}: {} at {:#x} in pclntab but {:#x} in symtab",
+                    rel(&bin.path),
+                    f.name,
+                    f.entry_va,
+                    addr
+                );
+                checked += 1;
+            }
+        }
+        assert!(
+            checked * 2 >= funcs.len(),
+            "{}: only {} of {} pclntab functions have symbols",
+            rel(&bin.path),
+            checked,
+            funcs.len()
+        );
+        let go_entries: HashSet<u64> = funcs.iter().map(|f| f.entry_va).collect();
+        let (Some(&text), Some(&etext)) =
+            (symbols.get("runtime.text"), symbols.get("runtime.etext"))
+        else {
+            panic!("{}: no runtime.text/runtime.etext", rel(&bin.path));
+        };
+        for name in C_FUNCTIONS {
+            let addr = symbols[*name];
+            assert!(
+                !go_entries.contains(&addr) && !(text..etext).contains(&addr),
+                "{}: C function {} at {:#x} inside Go text",
+                rel(&bin.path),
+                name,
+                addr
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the cgo samples from samples/build-go-cgo.sh"]
+fn cgo_binaries_are_go() {
+    for bin in cgo_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        assert_eq!(
+            extract_go_version(&data).as_deref(),
+            Some(format!("go{}", bin.version).as_str()),
+            "{}",
+            rel(&bin.path)
+        );
+        // Stripped binaries keep only libc's dynamic symbols (malloc, free,
+        // abort), which score as plain C against the lone Go buildid; only the
+        // version is checked for them.
+        if bin.stripped {
+            continue;
+        }
+        let obj = object::File::parse(&*data).unwrap();
+        let names: Vec<String> = obj
+            .symbols()
+            .chain(obj.dynamic_symbols())
+            .filter_map(|s| s.name().ok().map(str::to_string))
+            .filter(|n| !n.is_empty())
+            .collect();
+        let result = detect_language_and_compiler(&names, &[], &[], None, None, &data);
+        assert_eq!(result.language, SourceLanguage::Go, "{}", rel(&bin.path));
+    }
+}
@@ -0,0 +1,151 @@
+//! How Go metadata recovery holds up against garble obfuscation.
+//!
+//! Binaries come from `samples/build-go-garble.sh` and are named
+//! `<sample>-go<version>-garble[-<mode>][.exe]` under
+//! `samples/binaries/platforms/<os>/<arch>/export/go/garble/`, with mode
+//! `literals`, `tiny`, or `full` (both). What garble cannot remove without
+//! breaking the runtime is asserted; the rest is reported so regressions and
+//! improvements in obfuscation-resilient recovery show up in the test log.
+//! The binaries are git-lfs fixtures, so the tests are ignored by default:
+//! build or fetch them, then run `cargo test --test go_garble -- --ignored`.
+
+use glaurung::analysis::gopclntab::{extract_go_functions, GoPclnError};
+use glaurung::triage::compiler_detection::extract_go_version;
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-go-garble.sh";
+
+/// Names the unobfuscated samples define; garble hashes all of them.
+const SOURCE_NAMES: &[(&str, &[&str])] = &[
+    (
+        "hello",
+        &[
+            "main.worker",
+            "main.riskyOperation",
+            "main.(*Application).Speak",
+        ],
+    ),
+    (
+        "generics",
+        &["main.Sum[", "main.Filter[", "main.SortedPairs["],
+    ),
+    (
+        "iface",
+        &["main.Rect.Area", "main.(*Circle).Area", "main.describe"],
+    ),
+];
+
+const HELLO_LITERAL: &[u8] = b"Hello, World from Go!";
+
+struct GarbleBinary {
+    path: PathBuf,
+    os: String,
+    sample: String,
+    version: String,
+    literals: bool,
+}
+
+fn garble_binaries() -> Vec<GarbleBinary> {
+    let out = samples("export/go/garble")
+        .into_iter()
+        .filter_map(|s| {
+            let stem = s.name.trim_end_matches(".exe");
+            let (head, mode) = stem.split_once("-garble")?;
+            let (sample, version) = head.rsplit_once("-go")?;
+            Some(GarbleBinary {
+                sample: sample.to_string(),
+                version: version.to_string(),
+                literals: matches!(mode, "-literals" | "-full"),
+                os: s.os,
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "Go garble", SCRIPT)
+}
+
+#[test]
+#[ignore = "needs the garble samples from samples/build-go-garble.sh"]
+fn pclntab_survives_obfuscation() {
+    for bin in garble_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let funcs = match extract_go_functions(&data) {
+            Ok(funcs) => funcs,
+            // Same limitation as the plain Go matrix: no PE pclntab lookup.
+            Err(GoPclnError::NoSection) if bin.os == "windows" => continue,
+            Err(e) => panic!("{}: {:?}", rel(&bin.path), e),
+        };
+        // The runtime finds the entry point by this name, so it is kept.
+        assert!(
+            funcs.iter().any(|f| f.name == "main.main"),
+            "{}: no main.main",
+            rel(&bin.path)
+        );
+        let runtime = funcs
+            .iter()
+            .filter(|f| f.name.starts_with("runtime."))
+            .count();
+        let user = funcs.iter().filter(|f| f.name.starts_with("main.")).count();
+        eprintln!(
+            "{}: {} functions, {} runtime.*, {} main.*",
+            rel(&bin.path),
+            funcs.len(),
+            runtime,
+            user
+        );
+        // Confirms the fixture really is obfuscated.
+        let names = SOURCE_NAMES
+            .iter()
+            .find(|(s, _)| *s == bin.sample)
+            .map_or(&[][..], |(_, n)| *n);
+        for name in names {
+            assert!(
+                !funcs.iter().any(|f| f.name.starts_with(name)),
+                "{}: {} survived obfuscation",
+                rel(&bin.path),
+                name
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the garble samples from samples/build-go-garble.sh"]
+fn buildinfo_version_is_never_wrong() {
+    for bin in garble_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let version = extract_go_version(&data);
+        eprintln!("{}: go version {:?}", rel(&bin.path), version);
+        if let Some(v) = version {
+            assert_eq!(v, format!("go{}", bin.version), "{}", rel(&bin.path));
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the garble samples from samples/build-go-garble.sh"]
+fn literals_mode_hides_string_constants() {
+    for bin in garble_binaries()
+        .into_iter()
+        .filter(|b| b.sample == "hello")
+    {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let present = memchr::memmem::find(&data, HELLO_LITERAL).is_some();
+        assert_eq!(
+            present,
+            !bin.literals,
+            "{}: greeting literal {}",
+            rel(&bin.path),
+            if present {
+                "still in plain text"
+            } else {
+                "missing without -literals"
+            }
+        );
+    }
+}
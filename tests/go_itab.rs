@@ -0,0 +1,177 @@
+//! Go itab recovery and interface call resolution on the matrix samples.
+//!
+//! Binaries come from `samples/build-go-matrix.sh` as
+//! `iface-go<version>[-stripped][.exe]` under
+//! `samples/binaries/platforms/<os>/<arch>/export/go/matrix/`, built from
+//! `samples/source/go/iface.go`. Itabs survive stripping, so stripped
+//! copies are checked too. The binaries are git-lfs fixtures, so the tests
+//! are ignored by default: build or fetch the matrix, then run
+//! `cargo test --test go_itab -- --ignored`.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use glaurung::analysis::goitab::{recover_go_itabs, GoItabs};
+use glaurung::analysis::pipeline::{run_pipeline, PassRegistry, Profile};
+use glaurung::core::call_graph::CallType;
+use glaurung::formats::object_image::ObjectImage;
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-go-matrix.sh";
+
+/// `main.Named` methods; the interface embeds `Shape` and `fmt.Stringer`.
+const NAMED: &[&str] = &["Area", "Perimeter", "String"];
+
+/// (interface, concrete type, methods in interface order) itabs `iface.go`
+/// converts to at compile time.
+const ITABS: &[(&str, &str, &[&str])] = &[
+    ("main.Named", "main.Rect", NAMED),
+    ("main.Named", "*main.Circle", NAMED),
+    ("main.Named", "main.Square", NAMED),
+    ("sort.Interface", "main.byArea", &["Len", "Less", "Swap"]),
+    ("io.Writer", "main.upperWriter", &["Write"]),
+    ("error", "*main.shapeError", &["Error"]),
+];
+
+/// (binary, arch directory)
+fn iface_binaries() -> Vec<(PathBuf, String)> {
+    let out = samples("export/go/matrix")
+        .into_iter()
+        .filter(|s| s.name.starts_with("iface-go"))
+        .map(|s| (s.path, s.arch))
+        .collect();
+    require_any(out, "Go itab", SCRIPT)
+}
+
+fn recover(path: &Path, data: &[u8]) -> GoItabs {
+    recover_go_itabs(data).unwrap_or_else(|| panic!("{}: no itabs recovered", rel(path)))
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn itabs_match_the_source() {
+    for (path, _) in iface_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let itabs = recover(&path, &data);
+        for (interface, concrete, methods) in ITABS {
+            let itab = itabs.find(interface, concrete).unwrap_or_else(|| {
+                panic!(
+                    "{}: no itab for {} implementing {}",
+                    rel(&path),
+                    concrete,
+                    interface
+                )
+            });
+            let names: Vec<&str> = itab.methods.iter().map(|m| m.name.as_str()).collect();
+            assert_eq!(&names, methods, "{}: {}", rel(&path), concrete);
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn itab_methods_are_discovered() {
+    for (path, _) in iface_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let itabs = recover(&path, &data);
+        let (funcs, _) = analyze_functions_bytes(&data, &Budgets::default());
+        for (interface, concrete, _) in ITABS {
+            for m in &itabs.find(interface, concrete).unwrap().methods {
+                assert!(
+                    funcs.iter().any(|f| f.entry_point.value == m.target),
+                    "{}: {}.{} at {:#x} not discovered",
+                    rel(&path),
+                    concrete,
+                    m.name,
+                    m.target
+                );
+            }
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn interface_calls_reach_every_implementation() {
+    for (path, arch) in iface_binaries() {
+        // Dispatch sites are matched on x86 operands only.
+        if !matches!(arch.as_str(), "amd64" | "i386") {
+            continue;
+        }
+        let data = require_fixture(&path, SCRIPT);
+        let itabs = recover(&path, &data);
+        let (funcs, cg) = analyze_functions_bytes(&data, &Budgets::default());
+        let name_by_va: HashMap<u64, &str> = funcs
+            .iter()
+            .map(|f| (f.entry_point.value, f.name.as_str()))
+            .collect();
+        // byArea.Less calls Area through main.Named; every Named
+        // implementation's Area is a target of one virtual call site.
+        let areas: Vec<&str> = ["main.Rect", "*main.Circle", "main.Square"]
+            .iter()
+            .map(|c| {
+                let target = itabs.find("main.Named", c).unwrap().methods[0].target;
+                name_by_va[&target]
+            })
+            .collect();
+        let reaches_all = cg.edges.iter().any(|e| {
+            e.call_type == CallType::Virtual
+                && areas.iter().all(|area| {
+                    cg.edges.iter().any(|o| {
+                        o.call_type == CallType::Virtual
+                            && o.caller == e.caller
+                            && o.call_sites == e.call_sites
+                            && o.callee == *area
+                    })
+                })
+        });
+        assert!(
+            reaches_all,
+            "{}: no interface call reaches {:?}",
+            rel(&path),
+            areas
+        );
+        assert!(
+            cg.edges
+                .iter()
+                .filter(|e| e.call_type == CallType::Virtual)
+                .all(|e| e.confidence.is_some_and(|c| c > 0.0 && c <= 1.0)),
+            "{}: virtual edge without a confidence",
+            rel(&path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn go_itabs_pass_reports_implementations() {
+    for (path, _) in iface_binaries() {
+        let data = require_fixture(&path, SCRIPT);
+        let img = ObjectImage::parse(&data).unwrap();
+        let reg = PassRegistry::with_builtin();
+        let (report, ctx) = run_pipeline(&reg, &Profile::default(), &img).unwrap();
+        assert!(
+            ctx.artifact::<GoItabs>().is_some(),
+            "{}: no go_itabs artifact",
+            rel(&path)
+        );
+        let claims: Vec<&str> = report
+            .findings
+            .iter()
+            .filter(|f| f.category == "go_itab")
+            .map(|f| f.claim.as_str())
+            .collect();
+        assert!(
+            claims
+                .iter()
+                .any(|c| c.starts_with("main.byArea implements sort.Interface: Len=")),
+            "{}: byArea itab missing from {:?}",
+            rel(&path),
+            claims
+        );
+    }
+}
@@ -0,0 +1,100 @@
+//! Go pclntab and buildinfo parsing across the cross-compiled sample matrix.
+//!
+//! Binaries come from `samples/build-go-matrix.sh` and are named
+//! `<sample>-go<version>[-stripped][.exe]` under
+//! `samples/binaries/platforms/<os>/<arch>/export/go/matrix/`. They are git-lfs
+//! fixtures, so the tests are ignored by default: build or fetch the matrix,
+//! then run `cargo test --test go_matrix -- --ignored`.
+
+use glaurung::analysis::gopclntab::{extract_go_functions, GoPclnError};
+use glaurung::triage::compiler_detection::extract_go_version;
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-go-matrix.sh";
+
+struct MatrixBinary {
+    path: PathBuf,
+    os: String,
+    sample: String,
+    version: String,
+}
+
+fn matrix_binaries() -> Vec<MatrixBinary> {
+    let out = samples("export/go/matrix")
+        .into_iter()
+        .filter_map(|s| {
+            let stem = s
+                .name
+                .trim_end_matches(".exe")
+                .trim_end_matches("-stripped");
+            let (sample, version) = stem.rsplit_once("-go")?;
+            Some(MatrixBinary {
+                sample: sample.to_string(),
+                version: version.to_string(),
+                os: s.os,
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "Go matrix", SCRIPT)
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn buildinfo_version_matches_toolchain() {
+    for bin in matrix_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        assert_eq!(
+            extract_go_version(&data).as_deref(),
+            Some(format!("go{}", bin.version).as_str()),
+            "{}",
+            rel(&bin.path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the Go matrix from samples/build-go-matrix.sh"]
+fn pclntab_recovers_main_across_targets() {
+    for bin in matrix_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let funcs = match extract_go_functions(&data) {
+            Ok(funcs) => funcs,
+            // PE images keep pclntab inside .rdata with no section of its
+            // own; locating it there is not supported yet.
+            Err(GoPclnError::NoSection) if bin.os == "windows" => continue,
+            Err(e) => panic!("{}: {:?}", rel(&bin.path), e),
+        };
+        let has = |name: &str| funcs.iter().any(|f| f.name == name);
+        assert!(has("main.main"), "{}: no main.main", rel(&bin.path));
+        assert!(
+            funcs
+                .iter()
+                .filter(|f| f.name.starts_with("runtime."))
+                .count()
+                >= 50,
+            "{}: too few runtime functions",
+            rel(&bin.path)
+        );
+        match bin.sample.as_str() {
+            "generics" => assert!(
+                funcs
+                    .iter()
+                    .any(|f| f.name.starts_with("main.SortedPairs[go.shape.")),
+                "{}: no stenciled generic instantiation",
+                rel(&bin.path)
+            ),
+            "iface" => assert!(
+                has("main.Rect.Area") && has("main.(*Circle).Area"),
+                "{}: missing interface methods",
+                rel(&bin.path)
+            ),
+            _ => {}
+        }
+    }
+}
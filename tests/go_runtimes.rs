@@ -0,0 +1,159 @@
+//! Go detection on runtimes other than the gc toolchain's native binaries.
+//!
+//! Binaries come from `samples/build-go-runtimes.sh`: standard Go
+//! `go/wasm/hello-go<version>.wasm` under `platforms/{js,wasip1}/wasm/`, and
+//! TinyGo `go/tinygo/hello-tinygo<version>[-<board>].{wasm,elf}` under the
+//! same wasm platforms and `platforms/baremetal/<arch>/`. None of them has a
+//! pclntab section or a "Go buildinf:" blob where the native parsers look,
+//! so Go must be recognised from names alone. The binaries are git-lfs
+//! fixtures, so the tests are ignored by default: build or fetch them, then
+//! run `cargo test --test go_runtimes -- --ignored`.
+
+use glaurung::analysis::gopclntab::{extract_go_functions, GoPclnError};
+use glaurung::formats::wasm::WasmModule;
+use glaurung::triage::compiler_detection::{
+    detect_language_and_compiler, extract_go_version, SourceLanguage,
+};
+use object::{Object, ObjectSymbol};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-go-runtimes.sh";
+
+struct RuntimeBinary {
+    path: PathBuf,
+    /// `go` for the standard toolchain, `tinygo` otherwise.
+    compiler: &'static str,
+    version: String,
+    wasm: bool,
+}
+
+fn runtime_binaries() -> Vec<RuntimeBinary> {
+    let out = [
+        ("export/go/wasm", "go", "hello-go"),
+        ("export/go/tinygo", "tinygo", "hello-tinygo"),
+    ]
+    .into_iter()
+    .flat_map(|(dir, compiler, prefix)| {
+        samples(dir).into_iter().filter_map(move |s| {
+            let wasm = s.name.ends_with(".wasm");
+            let stem = s.name.trim_end_matches(".wasm").trim_end_matches(".elf");
+            let rest = stem.strip_prefix(prefix)?;
+            // Board names follow the version after a dash.
+            let version = rest.split_once('-').map_or(rest, |(v, _)| v);
+            Some(RuntimeBinary {
+                compiler,
+                version: version.to_string(),
+                wasm,
+                path: s.path,
+            })
+        })
+    })
+    .collect();
+    require_any(out, "Go runtime", SCRIPT)
+}
+
+/// Function names from the wasm name section, or the ELF symbol table.
+fn function_names(bin: &RuntimeBinary, data: &[u8]) -> Vec<String> {
+    if bin.wasm {
+        let module =
+            WasmModule::parse(data).unwrap_or_else(|e| panic!("{}: {:?}", rel(&bin.path), e));
+        let count = module.imported_function_count() + module.functions().len() as u32;
+        (0..count)
+            .filter_map(|i| module.function_name(i).map(str::to_string))
+            .collect()
+    } else {
+        let obj = object::File::parse(data).unwrap();
+        obj.symbols()
+            .filter(|s| s.is_definition())
+            .filter_map(|s| s.name().ok().map(str::to_string))
+            .collect()
+    }
+}
+
+#[test]
+#[ignore = "needs the wasm and TinyGo samples from samples/build-go-runtimes.sh"]
+fn standard_wasm_keeps_go_names() {
+    for bin in runtime_binaries()
+        .into_iter()
+        .filter(|b| b.compiler == "go")
+    {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let module =
+            WasmModule::parse(&data).unwrap_or_else(|e| panic!("{}: {:?}", rel(&bin.path), e));
+        assert!(
+            module.sections().iter().any(|s| s.name == "go:buildid"),
+            "{}: no go:buildid custom section",
+            rel(&bin.path)
+        );
+        let names = function_names(&bin, &data);
+        assert!(
+            names.iter().any(|n| n == "main.main"),
+            "{}: no main.main in the name section",
+            rel(&bin.path)
+        );
+        assert!(
+            names.iter().any(|n| n.starts_with("runtime.")),
+            "{}: no runtime.* in the name section",
+            rel(&bin.path)
+        );
+    }
+}
+
+/// Standard Go wasm keeps pclntab inside a data segment and TinyGo emits
+/// none; the lookup must fail cleanly rather than parse something else.
+#[test]
+#[ignore = "needs the wasm and TinyGo samples from samples/build-go-runtimes.sh"]
+fn pclntab_lookup_fails_cleanly() {
+    for bin in runtime_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        match extract_go_functions(&data) {
+            Err(GoPclnError::NoSection) => {}
+            other => panic!(
+                "{}: expected no pclntab, got {:?}",
+                rel(&bin.path),
+                other.map(|f| f.len())
+            ),
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the wasm and TinyGo samples from samples/build-go-runtimes.sh"]
+fn go_version_is_never_wrong() {
+    for bin in runtime_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let version = extract_go_version(&data);
+        eprintln!("{}: go version {:?}", rel(&bin.path), version);
+        // TinyGo's file name carries its own release, not Go's.
+        if let (Some(v), "go") = (version, bin.compiler) {
+            assert_eq!(v, format!("go{}", bin.version), "{}", rel(&bin.path));
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the wasm and TinyGo samples from samples/build-go-runtimes.sh"]
+fn names_identify_go_without_runtime_metadata() {
+    for bin in runtime_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let names = function_names(&bin, &data);
+        if names.is_empty() {
+            // Built without debug info (TinyGo -no-debug); nothing to go on.
+            eprintln!("{}: no function names", rel(&bin.path));
+            continue;
+        }
+        let result = detect_language_and_compiler(&names, &[], &[], None, None, &data);
+        assert_eq!(
+            result.language,
+            SourceLanguage::Go,
+            "{}: {}",
+            rel(&bin.path),
+            result.evidence_summary
+        );
+    }
+}
@@ -0,0 +1,345 @@
+//! Golden-report regression over the sample corpus.
+//!
+//! Every binary under `samples/binaries/platforms/<os>/<arch>/export/` is run
+//! through triage and function discovery, and the combined report is compared
+//! with `samples/golden/<os>/<arch>/<path under export>.json`. Fields are
+//! compared exactly unless `TOLERANCES` says otherwise, and every mismatch is
+//! listed by JSON path so a regression points at the pass that caused it.
+//!
+//! This target has its own `main` (`harness = false`) so it can take flags:
+//!
+//! ```text
+//! cargo test --test golden_reports                   # compare
+//! cargo test --test golden_reports -- --update       # rewrite mismatched or missing goldens
+//! cargo test --test golden_reports -- linux/amd64    # only samples whose path contains this
+//! ```
+//!
+//! Samples that are missing or unfetched LFS pointers are skipped. A sample
+//! without a golden report is listed but does not fail the run until one is
+//! written with `--update`.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use glaurung::triage::api::analyze_path;
+use glaurung::triage::io::IOLimits;
+use serde_json::{json, Value};
+use std::collections::BTreeSet;
+use std::path::{Path, PathBuf};
+use std::process::ExitCode;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, PLATFORMS};
+
+const GOLDEN_DIR: &str = "samples/golden";
+
+/// Mismatches listed per sample before the rest are summarised.
+const MAX_DIFFS: usize = 20;
+
+/// How a field is compared.
+#[derive(Clone, Copy)]
+enum Tolerance {
+    /// Not compared (volatile or bulky).
+    Ignore,
+    /// Numbers may differ by this much.
+    Abs(f64),
+    /// Numbers may differ by this fraction of the golden value.
+    Rel(f64),
+    /// Arrays compared as sets; this fraction of the golden entries may be
+    /// missing or extra.
+    Unordered(f64),
+}
+
+/// Field rules by JSON pointer; `*` matches one path segment. The first
+/// match wins; unmatched floats use `FLOAT_EPSILON` and everything else is
+/// exact.
+const TOLERANCES: &[(&str, Tolerance)] = &[
+    ("/triage/path", Tolerance::Ignore),
+    ("/triage/budgets/time_ms", Tolerance::Ignore),
+    // Per-window entropy is summarised by mean/min/max/std_dev.
+    ("/triage/entropy/windows", Tolerance::Ignore),
+    (
+        "/triage/entropy_analysis/summary/windows",
+        Tolerance::Ignore,
+    ),
+    // String listings are capped samples; their counts are still compared.
+    ("/triage/strings/strings", Tolerance::Ignore),
+    ("/triage/strings/ioc_samples", Tolerance::Ignore),
+    ("/triage/findings", Tolerance::Unordered(0.0)),
+    ("/triage/hints", Tolerance::Unordered(0.0)),
+    // f32 scores, rounded differently across platforms.
+    ("/triage/heuristic_arch/*/1", Tolerance::Abs(1e-4)),
+    ("/functions/entries", Tolerance::Unordered(0.0)),
+    ("/functions/blocks", Tolerance::Rel(0.01)),
+    ("/call_graph/edges", Tolerance::Rel(0.01)),
+];
+
+const FLOAT_EPSILON: f64 = 1e-6;
+
+struct Options {
+    update: bool,
+    filters: Vec<String>,
+}
+
+fn parse_args() -> Options {
+    let mut opts = Options {
+        update: false,
+        filters: Vec::new(),
+    };
+    for arg in std::env::args().skip(1) {
+        match arg.as_str() {
+            "--update" | "-update" => opts.update = true,
+            // libtest flags cargo may forward (--nocapture, --quiet, ...).
+            a if a.starts_with('-') => {}
+            a => opts.filters.push(a.to_string()),
+        }
+    }
+    opts
+}
+
+/// `(sample, golden)` paths for every file under an `export/` directory,
+/// skipping build metadata.
+fn corpus() -> Vec<(PathBuf, PathBuf)> {
+    let mut out = Vec::new();
+    let Ok(oses) = std::fs::read_dir(PLATFORMS) else {
+        return out;
+    };
+    for os in oses.flatten() {
+        let Ok(arches) = std::fs::read_dir(os.path()) else {
+            continue;
+        };
+        for arch in arches.flatten() {
+            let export = arch.path().join("export");
+            let golden_root = Path::new(GOLDEN_DIR)
+                .join(os.file_name())
+                .join(arch.file_name());
+            let mut files = Vec::new();
+            walk(&export, &mut files);
+            for f in files {
+                let sub = f.strip_prefix(&export).unwrap();
+                if sub.starts_with("metadata") || sub.extension().is_some_and(|e| e == "json") {
+                    continue;
+                }
+                let mut golden = golden_root.join(sub).into_os_string();
+                golden.push(".json");
+                out.push((f, PathBuf::from(golden)));
+            }
+        }
+    }
+    out.sort();
+    out
+}
+
+fn walk(dir: &Path, out: &mut Vec<PathBuf>) {
+    let Ok(entries) = std::fs::read_dir(dir) else {
+        return;
+    };
+    for e in entries.flatten() {
+        let path = e.path();
+        if path.is_dir() {
+            walk(&path, out);
+        } else {
+            out.push(path);
+        }
+    }
+}
+
+fn is_lfs_pointer(path: &Path) -> bool {
+    let mut head = [0u8; 40];
+    std::fs::File::open(path)
+        .and_then(|mut f| std::io::Read::read_exact(&mut f, &mut head))
+        .is_ok_and(|_| head.starts_with(b"version https://git-lfs.github.com/spec/"))
+}
+
+/// Triage plus function discovery, as one JSON document.
+fn report(path: &Path) -> Result<Value, String> {
+    let artifact = analyze_path(path, &IOLimits::default()).map_err(|e| e.to_string())?;
+    let triage = serde_json::to_value(&artifact).map_err(|e| e.to_string())?;
+    let data = std::fs::read(path).map_err(|e| e.to_string())?;
+    // No wall-clock cutoff, so the result does not depend on machine speed.
+    let budgets = Budgets {
+        timeout_ms: u64::MAX,
+        ..Budgets::default()
+    };
+    let (functions, call_graph) = analyze_functions_bytes(&data, &budgets);
+    let mut entries: Vec<String> = functions
+        .iter()
+        .map(|f| format!("{:#x} {}", f.entry_point.value, f.name))
+        .collect();
+    entries.sort();
+    Ok(json!({
+        "sample": rel(path),
+        "triage": triage,
+        "functions": {
+            "count": functions.len(),
+            "blocks": functions.iter().map(|f| f.basic_blocks.len()).sum::<usize>(),
+            "entries": entries,
+        },
+        "call_graph": {
+            "nodes": call_graph.nodes.len(),
+            "edges": call_graph.edges.len(),
+        },
+    }))
+}
+
+fn tolerance_for(path: &str) -> Option<Tolerance> {
+    TOLERANCES
+        .iter()
+        .find(|(pattern, _)| pointer_matches(pattern, path))
+        .map(|(_, t)| *t)
+}
+
+fn pointer_matches(pattern: &str, path: &str) -> bool {
+    let (mut p, mut q) = (pattern.split('/'), path.split('/'));
+    loop {
+        match (p.next(), q.next()) {
+            (None, None) => return true,
+            (Some(a), Some(b)) if a == "*" || a == b => {}
+            _ => return false,
+        }
+    }
+}
+
+fn compare(golden: &Value, actual: &Value, path: &str, diffs: &mut Vec<String>) {
+    let tolerance = tolerance_for(path);
+    match (golden, actual, tolerance) {
+        (_, _, Some(Tolerance::Ignore)) => {}
+        (Value::Number(g), Value::Number(a), t) => {
+            let (g, a) = (g.as_f64().unwrap(), a.as_f64().unwrap());
+            let limit = match t {
+                Some(Tolerance::Abs(d)) => d,
+                Some(Tolerance::Rel(r)) => r * g.abs(),
+                _ if golden.is_f64() || actual.is_f64() => FLOAT_EPSILON,
+                _ => 0.0,
+            };
+            if (g - a).abs() > limit {
+                diffs.push(format!("{path}: {g} -> {a}"));
+            }
+        }
+        (Value::Array(g), Value::Array(a), Some(Tolerance::Unordered(frac))) => {
+            let key = |v: &Value| v.to_string();
+            let before: BTreeSet<String> = g.iter().map(key).collect();
+            let after: BTreeSet<String> = a.iter().map(key).collect();
+            let missing: Vec<&String> = before.difference(&after).collect();
+            let extra: Vec<&String> = after.difference(&before).collect();
+            if (missing.len() + extra.len()) as f64 > frac * before.len() as f64 {
+                for m in missing.iter().take(MAX_DIFFS / 2) {
+                    diffs.push(format!("{path}: lost {m}"));
+                }
+                for x in extra.iter().take(MAX_DIFFS / 2) {
+                    diffs.push(format!("{path}: gained {x}"));
+                }
+                diffs.push(format!(
+                    "{path}: {} lost, {} gained of {}",
+                    missing.len(),
+                    extra.len(),
+                    before.len()
+                ));
+            }
+        }
+        (Value::Array(g), Value::Array(a), _) => {
+            if g.len() != a.len() {
+                diffs.push(format!("{path}: length {} -> {}", g.len(), a.len()));
+            }
+            for (i, (gv, av)) in g.iter().zip(a).enumerate() {
+                compare(gv, av, &format!("{path}/{i}"), diffs);
+            }
+        }
+        (Value::Object(g), Value::Object(a), _) => {
+            for (k, gv) in g {
+                let child = format!("{path}/{k}");
+                match a.get(k) {
+                    Some(av) => compare(gv, av, &child, diffs),
+                    None if matches!(tolerance_for(&child), Some(Tolerance::Ignore)) => {}
+                    None => diffs.push(format!("{child}: removed")),
+                }
+            }
+            for k in a.keys().filter(|k| !g.contains_key(*k)) {
+                let child = format!("{path}/{k}");
+                if !matches!(tolerance_for(&child), Some(Tolerance::Ignore)) {
+                    diffs.push(format!("{child}: added"));
+                }
+            }
+        }
+        (g, a, _) if g == a => {}
+        (g, a, _) => diffs.push(format!("{path}: {g} -> {a}")),
+    }
+}
+
+fn write_golden(path: &Path, report: &Value) -> std::io::Result<()> {
+    std::fs::create_dir_all(path.parent().unwrap())?;
+    let mut text = serde_json::to_string_pretty(report)?;
+    text.push('\n');
+    std::fs::write(path, text)
+}
+
+fn main() -> ExitCode {
+    let opts = parse_args();
+    let (mut matched, mut updated, mut unblessed, mut skipped) = (0, 0, 0, 0);
+    let mut failed = Vec::new();
+
+    for (sample, golden_path) in corpus() {
+        let name = rel(&sample);
+        if !opts.filters.is_empty() && !opts.filters.iter().any(|f| name.contains(f.as_str())) {
+            continue;
+        }
+        if is_lfs_pointer(&sample) {
+            skipped += 1;
+            continue;
+        }
+        let actual = match report(&sample) {
+            Ok(r) => r,
+            Err(e) => {
+                eprintln!("FAIL {name}: analysis failed: {e}");
+                failed.push(name);
+                continue;
+            }
+        };
+        let golden: Option<Value> = std::fs::read_to_string(&golden_path)
+            .ok()
+            .and_then(|s| serde_json::from_str(&s).ok());
+        let mut diffs = Vec::new();
+        if let Some(g) = &golden {
+            compare(g, &actual, "", &mut diffs);
+            if diffs.is_empty() {
+                matched += 1;
+                continue;
+            }
+        }
+        if opts.update {
+            if let Err(e) = write_golden(&golden_path, &actual) {
+                eprintln!("FAIL {name}: cannot write {}: {e}", golden_path.display());
+                failed.push(name);
+                continue;
+            }
+            eprintln!("updated {}", golden_path.display());
+            updated += 1;
+        } else if golden.is_none() {
+            eprintln!("no golden report for {name}");
+            unblessed += 1;
+        } else {
+            eprintln!("FAIL {name}: {} field(s) differ", diffs.len());
+            for d in diffs.iter().take(MAX_DIFFS) {
+                eprintln!("    {d}");
+            }
+            if diffs.len() > MAX_DIFFS {
+                eprintln!("    ... {} more", diffs.len() - MAX_DIFFS);
+            }
+            failed.push(name);
+        }
+    }
+
+    println!(
+        "golden reports: {matched} matched, {updated} updated, {unblessed} without golden, \
+         {skipped} skipped (LFS pointers), {} failed",
+        failed.len()
+    );
+    if unblessed > 0 {
+        println!("write missing goldens with: cargo test --test golden_reports -- --update");
+    }
+    if failed.is_empty() {
+        ExitCode::SUCCESS
+    } else {
+        ExitCode::FAILURE
+    }
+}
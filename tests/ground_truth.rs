@@ -0,0 +1,214 @@
+//! Function discovery scored against symbol-table ground truth.
+//!
+//! `scripts/make_ground_truth.py` writes `samples/ground-truth/**/*.json`
+//! from each unstripped sample, alongside a `-stripped` copy of the binary.
+//! Discovery on the unstripped binary should recover nearly every true
+//! function; on the stripped copy the score is reported and held to a floor.
+//! Entries whose binaries are missing or unfetched LFS pointers are skipped.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use object::Object;
+use serde::Deserialize;
+use sha2::{Digest, Sha256};
+use std::collections::HashSet;
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::read_fixture;
+
+const SAMPLES: &str = "samples";
+const TRUTH_DIR: &str = "samples/ground-truth";
+
+#[derive(Deserialize)]
+struct GroundTruth {
+    binary: String,
+    sha256: String,
+    stripped: Option<StrippedVariant>,
+    functions: Vec<TrueFunction>,
+}
+
+#[derive(Deserialize)]
+struct StrippedVariant {
+    path: String,
+    sha256: String,
+}
+
+#[derive(Deserialize)]
+struct TrueFunction {
+    name: String,
+    start: u64,
+    end: u64,
+}
+
+struct Score {
+    found: usize,
+    truth: usize,
+    in_span: usize,
+    false_positives: usize,
+}
+
+impl Score {
+    fn recall(&self) -> f64 {
+        self.found as f64 / self.truth.max(1) as f64
+    }
+
+    fn precision(&self) -> f64 {
+        if self.in_span == 0 {
+            return 1.0;
+        }
+        (self.in_span - self.false_positives) as f64 / self.in_span as f64
+    }
+}
+
+fn truth_files(dir: &Path, out: &mut Vec<PathBuf>) {
+    let Ok(entries) = std::fs::read_dir(dir) else {
+        return;
+    };
+    for e in entries.flatten() {
+        let p = e.path();
+        if p.is_dir() {
+            truth_files(&p, out);
+        } else if p.extension().is_some_and(|x| x == "json") {
+            out.push(p);
+        }
+    }
+}
+
+fn load_truth() -> Vec<(PathBuf, GroundTruth)> {
+    let mut paths = Vec::new();
+    truth_files(Path::new(TRUTH_DIR), &mut paths);
+    paths.sort();
+    paths
+        .into_iter()
+        .map(|p| {
+            let text = std::fs::read_to_string(&p).unwrap();
+            let gt =
+                serde_json::from_str(&text).unwrap_or_else(|e| panic!("{}: {}", p.display(), e));
+            (p, gt)
+        })
+        .collect()
+}
+
+/// Bytes of a sample, checked against the hash recorded with its truth.
+fn read_checked(rel: &str, sha256: &str) -> Option<Vec<u8>> {
+    let data = read_fixture(&Path::new(SAMPLES).join(rel))?;
+    assert_eq!(
+        hex::encode(Sha256::digest(&data)),
+        sha256,
+        "{rel}: ground truth is stale; rerun scripts/make_ground_truth.py --force"
+    );
+    Some(data)
+}
+
+/// Entry points found, and discovered functions inside the truth's address
+/// span that do not start a true function.
+fn score(truth: &[TrueFunction], discovered: &HashSet<u64>) -> Score {
+    let starts: HashSet<u64> = truth.iter().map(|f| f.start).collect();
+    let lo = truth.iter().map(|f| f.start).min().unwrap_or(0);
+    let hi = truth.iter().map(|f| f.end).max().unwrap_or(0);
+    let in_span: Vec<u64> = discovered
+        .iter()
+        .copied()
+        .filter(|va| (lo..hi).contains(va))
+        .collect();
+    Score {
+        found: starts.intersection(discovered).count(),
+        truth: starts.len(),
+        in_span: in_span.len(),
+        false_positives: in_span.iter().filter(|va| !starts.contains(va)).count(),
+    }
+}
+
+fn discover(data: &[u8]) -> HashSet<u64> {
+    let budgets = Budgets {
+        max_functions: 0,
+        max_blocks: 100_000,
+        max_instructions: 1_000_000,
+        timeout_ms: 10_000,
+    };
+    let (funcs, _cg) = analyze_functions_bytes(data, &budgets);
+    funcs.iter().map(|f| f.entry_point.value).collect()
+}
+
+#[test]
+fn ground_truth_is_well_formed() {
+    for (path, gt) in load_truth() {
+        assert!(!gt.functions.is_empty(), "{}: no functions", path.display());
+        let mut prev_start = None;
+        for f in &gt.functions {
+            assert!(
+                f.start < f.end,
+                "{}: {} has an empty range",
+                path.display(),
+                f.name
+            );
+            assert!(
+                prev_start.is_none_or(|p| p < f.start),
+                "{}: functions not sorted by start at {}",
+                path.display(),
+                f.name
+            );
+            prev_start = Some(f.start);
+        }
+    }
+}
+
+#[test]
+fn discovery_recovers_symbolized_functions() {
+    for (_, gt) in load_truth() {
+        let Some(data) = read_checked(&gt.binary, &gt.sha256) else {
+            continue;
+        };
+        let s = score(&gt.functions, &discover(&data));
+        assert!(
+            s.recall() >= 0.9,
+            "{}: recall {:.2} ({} of {})",
+            gt.binary,
+            s.recall(),
+            s.found,
+            s.truth
+        );
+    }
+}
+
+#[test]
+fn discovery_on_stripped_variants_is_scored() {
+    for (_, gt) in load_truth() {
+        let Some(variant) = &gt.stripped else {
+            continue;
+        };
+        let Some(data) = read_checked(&variant.path, &variant.sha256) else {
+            continue;
+        };
+        let discovered = discover(&data);
+        let s = score(&gt.functions, &discovered);
+        eprintln!(
+            "{}: recall {:.2} ({}/{}), precision {:.2} ({} false positives)",
+            variant.path,
+            s.recall(),
+            s.found,
+            s.truth,
+            s.precision(),
+            s.false_positives
+        );
+        let entry = object::File::parse(&*data).unwrap().entry();
+        if gt.functions.iter().any(|f| f.start == entry) {
+            assert!(
+                discovered.contains(&entry),
+                "{}: entry point {:#x} not discovered",
+                variant.path,
+                entry
+            );
+        }
+        assert!(
+            s.precision() >= 0.5,
+            "{}: precision {:.2} ({} of {} in-span functions are not true starts)",
+            variant.path,
+            s.precision(),
+            s.false_positives,
+            s.in_span
+        );
+    }
+}
@@ -0,0 +1,134 @@
+//! Compiler identification on Zig, Nim, D, and Haskell binaries.
+//!
+//! Binaries come from `samples/build-lang-samples.sh` and live under
+//! `samples/binaries/platforms/linux/<arch>/export/<lang>/` as
+//! `hello-<compiler>-<mode>`. Their runtimes look like other languages to
+//! symbol heuristics (Zig's dotted names resemble Go's, D's druntime sits
+//! on a C main, Nim and GHC link through gcc), so detection must at least
+//! not name a different runtime. The binaries are git-lfs fixtures, so the
+//! tests are ignored by default: build or fetch them, then run
+//! `cargo test --test lang_samples -- --ignored`.
+
+use glaurung::formats::elf::ElfParser;
+use glaurung::triage::compiler_detection::{detect_language_and_compiler, SourceLanguage};
+use object::{Object, ObjectSection, ObjectSymbol};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-lang-samples.sh";
+
+struct LangBinary {
+    path: PathBuf,
+    lang: &'static str,
+}
+
+/// Language directory, and symbol prefixes only that language's runtime
+/// defines. Zig's are std namespaces, since release builds inline most of
+/// any single one away.
+const LANGS: &[(&str, &[&str])] = &[
+    ("zig", &["start.", "debug.", "heap.", "fmt."]),
+    ("nim", &["NimMain"]),
+    ("d", &["_Dmain"]),
+    ("haskell", &["hs_main", "stg_"]),
+];
+
+fn lang_binaries() -> Vec<LangBinary> {
+    let out = LANGS
+        .iter()
+        .flat_map(|&(lang, _)| {
+            samples(&format!("export/{lang}"))
+                .into_iter()
+                .filter(|s| s.os == "linux" && s.name.starts_with("hello-"))
+                .map(move |s| LangBinary { path: s.path, lang })
+        })
+        .collect();
+    require_any(out, "Zig, Nim, D, or Haskell", SCRIPT)
+}
+
+fn symbol_names(obj: &object::File) -> Vec<String> {
+    obj.symbols()
+        .chain(obj.dynamic_symbols())
+        .filter_map(|s| s.name().ok().map(str::to_string))
+        .filter(|n| !n.is_empty())
+        .collect()
+}
+
+/// Languages detection may settle on for a binary of `lang`: its own, or
+/// C/C++ for the code these toolchains emit through a C compiler or link
+/// from libc. Anything else means another runtime's heuristics fired.
+fn acceptable(lang: &str) -> &'static [SourceLanguage] {
+    use SourceLanguage::*;
+    match lang {
+        "zig" => &[Zig, C, Unknown],
+        "nim" => &[Nim, C, Unknown],
+        "d" => &[D, C, Cpp, Unknown],
+        // No SourceLanguage for Haskell yet.
+        _ => &[C, Unknown],
+    }
+}
+
+#[test]
+#[ignore = "needs the language samples from samples/build-lang-samples.sh"]
+fn runtime_markers_are_present() {
+    for bin in lang_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let names = symbol_names(&obj);
+        let markers = LANGS.iter().find(|(l, _)| *l == bin.lang).unwrap().1;
+        assert!(
+            names
+                .iter()
+                .any(|n| markers.iter().any(|m| n.starts_with(m))),
+            "{}: no symbol starting with any of {:?}; not a {} runtime binary?",
+            rel(&bin.path),
+            markers,
+            bin.lang
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the language samples from samples/build-lang-samples.sh"]
+fn language_is_not_misattributed() {
+    for bin in lang_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let names = symbol_names(&obj);
+        let elf = ElfParser::parse(&data).unwrap();
+        let libs: Vec<String> = elf
+            .dynamic()
+            .ok()
+            .flatten()
+            .map(|d| {
+                d.needed_libraries()
+                    .into_iter()
+                    .map(str::to_string)
+                    .collect()
+            })
+            .unwrap_or_default();
+        let comment = obj
+            .section_by_name(".comment")
+            .and_then(|s| s.data().ok())
+            .map(|d| String::from_utf8_lossy(d).into_owned());
+        let result =
+            detect_language_and_compiler(&names, &libs, &[], None, comment.as_deref(), &data);
+        eprintln!(
+            "{}: {:?} ({:.2}), compiler {:?}",
+            rel(&bin.path),
+            result.language,
+            result.confidence,
+            result.compiler.as_ref().map(|c| c.product_name.as_str())
+        );
+        assert!(
+            acceptable(bin.lang).contains(&result.language),
+            "{}: detected {:?}: {}",
+            rel(&bin.path),
+            result.language,
+            result.evidence_summary
+        );
+    }
+}
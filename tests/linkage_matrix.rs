@@ -0,0 +1,244 @@
+//! Dependency reporting, hardening, and libc signature matching across
+//! static and dynamic linkage.
+//!
+//! Binaries come from `samples/build-linkage-matrix.sh` and are named
+//! `<sample>-<lang>-<mode>` under
+//! `samples/binaries/platforms/linux/<arch>/export/linkage/`. The binaries
+//! are git-lfs fixtures, so the tests are ignored by default: build or fetch
+//! them, then run `cargo test --test linkage_matrix -- --ignored`.
+
+use glaurung::core::address::{Address, AddressKind};
+use glaurung::core::binary::Format;
+use glaurung::core::function::{Function, FunctionKind};
+use glaurung::flirt::{apply_flirt_overrides, FlirtLibrary, FlirtLibraryFile, FlirtSignatureEntry};
+use glaurung::formats::elf::{ElfParser, RelroLevel};
+use glaurung::symbols::{summarize_symbols, BudgetCaps};
+use object::{Object, ObjectSection, ObjectSymbol};
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-linkage-matrix.sh";
+const PROLOGUE_LEN: usize = 32;
+
+struct LinkageBinary {
+    path: PathBuf,
+    sample: String,
+    lang: String,
+    mode: String,
+}
+
+fn linkage_binaries() -> Vec<LinkageBinary> {
+    let out = samples("export/linkage")
+        .into_iter()
+        .filter(|s| s.os == "linux")
+        .filter_map(|s| {
+            let (sample, rest) = s.name.split_once('-')?;
+            let (lang, mode) = rest.split_once('-')?;
+            Some(LinkageBinary {
+                sample: sample.to_string(),
+                lang: lang.to_string(),
+                mode: mode.to_string(),
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "linkage matrix", SCRIPT)
+}
+
+/// First `PROLOGUE_LEN` bytes of every defined function symbol, by name.
+fn prologues(data: &[u8], obj: &object::File) -> HashMap<String, (u64, Vec<u8>)> {
+    let mut out = HashMap::new();
+    for sym in obj.symbols() {
+        if !sym.is_definition() || sym.kind() != object::SymbolKind::Text {
+            continue;
+        }
+        let (Ok(name), Some(idx)) = (sym.name(), sym.section_index()) else {
+            continue;
+        };
+        let Ok(sec) = obj.section_by_index(idx) else {
+            continue;
+        };
+        let Some((foff, _)) = sec.file_range() else {
+            continue;
+        };
+        let start = (foff + (sym.address() - sec.address())) as usize;
+        if let Some(bytes) = data.get(start..start + PROLOGUE_LEN) {
+            out.insert(name.to_string(), (sym.address(), bytes.to_vec()));
+        }
+    }
+    out
+}
+
+#[test]
+#[ignore = "needs the linkage matrix from samples/build-linkage-matrix.sh"]
+fn dependencies_match_linkage_mode() {
+    for bin in linkage_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let elf = ElfParser::parse(&data).unwrap();
+        let needed: Vec<String> = elf
+            .dynamic()
+            .ok()
+            .flatten()
+            .map(|d| {
+                d.needed_libraries()
+                    .into_iter()
+                    .map(str::to_string)
+                    .collect()
+            })
+            .unwrap_or_default();
+        let dynamic = matches!(bin.mode.as_str(), "dynamic" | "musl-dynamic");
+        assert_eq!(
+            elf.interpreter().is_some(),
+            dynamic,
+            "{}: interpreter {:?}",
+            rel(&bin.path),
+            elf.interpreter()
+        );
+        let libc = match bin.mode.as_str() {
+            "dynamic" => Some("libc.so.6"),
+            "musl-dynamic" => Some("libc.so"),
+            _ => None,
+        };
+        match libc {
+            Some(lib) => assert!(
+                needed.iter().any(|n| n == lib),
+                "{}: {} not in DT_NEEDED {:?}",
+                rel(&bin.path),
+                lib,
+                needed
+            ),
+            None => assert!(
+                needed.is_empty(),
+                "{}: static binary needs {:?}",
+                rel(&bin.path),
+                needed
+            ),
+        }
+        let summary = summarize_symbols(&data, Format::ELF, &BudgetCaps::default());
+        assert_eq!(
+            summary.libs_count as usize,
+            needed.len(),
+            "{}: symbol summary disagrees with DT_NEEDED",
+            rel(&bin.path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the linkage matrix from samples/build-linkage-matrix.sh"]
+fn hardening_reflects_linkage_mode() {
+    for bin in linkage_binaries() {
+        let data = require_fixture(&bin.path, SCRIPT);
+        let sec = ElfParser::parse(&data).unwrap().security_features();
+        assert!(sec.nx, "{}: executable stack", rel(&bin.path));
+        match bin.mode.as_str() {
+            "static-pie" => assert!(sec.pie, "{}: static-pie is not ET_DYN", rel(&bin.path)),
+            // gcc's static and Go's external link default to ET_EXEC; the
+            // internal Go linker does too.
+            "static" | "musl-static" | "static-nocgo" => {
+                assert!(!sec.pie, "{}: unexpected PIE", rel(&bin.path))
+            }
+            _ => {}
+        }
+        if bin.mode == "static-nocgo" {
+            // The Go internal linker emits no PT_GNU_RELRO for ET_EXEC.
+            assert_eq!(sec.relro, RelroLevel::None, "{}", rel(&bin.path));
+        } else {
+            assert_ne!(
+                sec.relro,
+                RelroLevel::None,
+                "{}: linked by the system linker without RELRO",
+                rel(&bin.path)
+            );
+        }
+    }
+}
+
+/// Signatures taken from the libc linked into one static C sample should
+/// name the same libc functions in another sample linked against the same
+/// libc.a, whether or not that one is position independent.
+#[test]
+#[ignore = "needs the linkage matrix from samples/build-linkage-matrix.sh"]
+fn libc_signatures_transfer_between_static_samples() {
+    let bins = linkage_binaries();
+    let find = |sample: &str, mode: &str| {
+        bins.iter()
+            .find(|b| b.lang == "c" && b.sample == sample && b.mode == mode)
+    };
+    // Static modes are skipped by the build script when libc.a is missing.
+    let Some(lib_src) = find("hello", "static") else {
+        return;
+    };
+    let lib_data = require_fixture(&lib_src.path, SCRIPT);
+    let lib_obj = object::File::parse(&*lib_data).unwrap();
+    let mut by_prologue: HashMap<Vec<u8>, Vec<String>> = HashMap::new();
+    for (name, (_, bytes)) in prologues(&lib_data, &lib_obj) {
+        if name != "main" {
+            by_prologue.entry(bytes).or_default().push(name);
+        }
+    }
+    // Drop ambiguous prologues, as the library builder does.
+    let entries: Vec<FlirtSignatureEntry> = by_prologue
+        .into_iter()
+        .filter(|(_, names)| names.len() == 1)
+        .map(|(bytes, mut names)| FlirtSignatureEntry {
+            name: names.pop().unwrap(),
+            prologue_hex: bytes.iter().map(|b| format!("{:02x}", b)).collect(),
+            source_binary: rel(&lib_src.path),
+        })
+        .collect();
+    let lib = FlirtLibrary::from_file(FlirtLibraryFile {
+        schema_version: "1".to_string(),
+        arch: "x86_64".to_string(),
+        prologue_len: PROLOGUE_LEN,
+        entries,
+        index: HashMap::new(),
+        stats: serde_json::Value::Null,
+    });
+
+    for mode in ["static", "static-pie"] {
+        let Some(target) = find("algos", mode) else {
+            continue;
+        };
+        let data = require_fixture(&target.path, SCRIPT);
+        // Pretend the target is stripped: placeholder names at its symbol VAs.
+        let obj = object::File::parse(&*data).unwrap();
+        let truth: HashMap<u64, String> = prologues(&data, &obj)
+            .into_iter()
+            .map(|(name, (va, _))| (va, name))
+            .collect();
+        let mut funcs: Vec<Function> = truth
+            .keys()
+            .map(|va| {
+                let entry = Address::new(AddressKind::VA, *va, 64, None, None).unwrap();
+                Function::new(format!("sub_{va:x}"), entry, FunctionKind::Normal).unwrap()
+            })
+            .collect();
+        let renamed = apply_flirt_overrides(&data, &mut funcs, &lib);
+        let correct = funcs
+            .iter()
+            .filter(|f| !f.name.starts_with("sub_"))
+            .filter(|f| truth.get(&f.entry_point.value) == Some(&f.name))
+            .count();
+        // 535-546 of ~1100 libc functions match with glibc 2.36 on x86-64.
+        assert!(
+            correct >= 300,
+            "{}: only {} of {} libc matches were correct",
+            rel(&target.path),
+            correct,
+            renamed
+        );
+        assert!(
+            correct * 100 >= renamed * 95,
+            "{}: {} of {} libc matches were wrong",
+            rel(&target.path),
+            renamed - correct,
+            renamed
+        );
+    }
+}
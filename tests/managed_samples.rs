@@ -0,0 +1,499 @@
+//! CIL and JVM parsers on the managed-code inventory samples.
+//!
+//! Artifacts come from `samples/build-managed-samples.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/`:
+//! `dotnet/{framework,core,self-contained,r2r}/Inventory-*` and
+//! `java/inventory/jdk<N>/{classes/,inventory-fat.jar,image/}`. All of them
+//! are built from `samples/source/{csharp,java}/inventory/`, so the same
+//! types and methods must come back out of every packaging. They are
+//! git-lfs fixtures, so the tests are ignored by default: build or fetch
+//! them, then run `cargo test --test managed_samples -- --ignored`.
+
+use glaurung::analysis::cil_il::disassemble_assembly;
+use glaurung::analysis::cil_metadata::{extract_cil_methods, CilMetadata};
+use glaurung::analysis::java_bytecode::{call_graph, jar_classes};
+use glaurung::analysis::java_class::parse_class;
+use glaurung::core::call_graph::CallType;
+use glaurung::formats::apk::ApkReader;
+use glaurung::formats::pe::{PeParser, IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR};
+use glaurung::symbols::analysis::suspicious::detect_suspicious_java_calls;
+use glaurung::symbols::{pe::summarize_pe, BudgetCaps};
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{read_fixture, rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-managed-samples.sh";
+
+/// Methods every .NET variant defines (`Namespace.Type::Method`).
+const CIL_METHODS: &[&str] = &[
+    "Glaurung.Samples.Inventory::Add",
+    "Glaurung.Samples.Inventory::Total",
+    "Glaurung.Samples.Inventory::Main",
+    "Glaurung.Samples.Ledger`1::Record",
+    "Glaurung.Samples.Item::ToString",
+];
+
+/// `Inventory.cs` declares `Plugins::Protect` as kernel32's VirtualProtect.
+const PINVOKE: (&str, &str, &str) = (
+    "Glaurung.Samples.Plugins::Protect",
+    "kernel32.dll",
+    "VirtualProtect",
+);
+
+const JAVA_MAIN: &str = "com/glaurung/samples/app/Inventory";
+const JAVA_LEDGER: &str = "com/glaurung/samples/ledger/Ledger";
+const JAVA_METHODS: &[&str] = &["add", "total", "main"];
+/// `Inventory.java`'s package-private loader class.
+const JAVA_PLUGINS: &str = "com/glaurung/samples/app/Plugins";
+
+/// .NET variants that are plain IL assemblies rather than native bundles.
+const IL_VARIANTS: &[&str] = &["framework", "core", "r2r"];
+
+/// A literal from both sources; C# keeps it UTF-16 in the #US heap.
+const LITERAL: &str = "glaurung-widget";
+
+/// `Inventory-*` files under `export/dotnet/<variant>/` for each of
+/// `variants`, with the variant each came from.
+fn dotnet_samples(variants: &[&'static str]) -> Vec<(&'static str, PathBuf)> {
+    let out = variants
+        .iter()
+        .flat_map(|&variant| {
+            samples(&format!("export/dotnet/{variant}"))
+                .into_iter()
+                .filter(|s| s.name.starts_with("Inventory-"))
+                .map(move |s| (variant, s.path))
+        })
+        .collect();
+    require_any(out, &format!(".NET {}", variants.join("/")), SCRIPT)
+}
+
+/// `export/java/inventory/jdk<N>/` directories.
+fn jdk_dirs() -> Vec<PathBuf> {
+    let mut out: Vec<PathBuf> = samples("export/java/inventory")
+        .into_iter()
+        .filter_map(|s| {
+            let jdk = s
+                .path
+                .ancestors()
+                .find(|p| p.parent().and_then(Path::file_name) == Some("inventory".as_ref()))?;
+            Some(jdk.to_path_buf())
+        })
+        .collect();
+    out.dedup();
+    require_any(out, "Java inventory", SCRIPT)
+}
+
+/// Class-file major version for a `jdk<N>` directory (JDK N emits 44 + N).
+fn jdk_major(dir: &Path) -> Option<u16> {
+    let n: u16 = dir
+        .file_name()?
+        .to_str()?
+        .strip_prefix("jdk")?
+        .parse()
+        .ok()?;
+    Some(44 + n)
+}
+
+fn contains(haystack: &[u8], needle: &[u8]) -> bool {
+    haystack.windows(needle.len()).any(|w| w == needle)
+}
+
+fn utf16le(s: &str) -> Vec<u8> {
+    s.encode_utf16().flat_map(|u| u.to_le_bytes()).collect()
+}
+
+fn assert_cil_methods(path: &Path, data: &[u8]) {
+    let methods = extract_cil_methods(data)
+        .unwrap_or_else(|e| panic!("{}: CIL metadata: {:?}", rel(path), e));
+    for want in CIL_METHODS {
+        assert!(
+            methods.iter().any(|m| m.name == *want),
+            "{}: {} not recovered ({} methods)",
+            rel(path),
+            want,
+            methods.len()
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn assemblies_carry_cil_metadata() {
+    for (_, path) in dotnet_samples(IL_VARIANTS) {
+        let data = require_fixture(&path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        assert!(pe.is_dotnet(), "{}: no CLR header", rel(&path));
+        assert_cil_methods(&path, &data);
+        assert!(
+            contains(&data, &utf16le(LITERAL)),
+            "{}: {:?} not in the user-string heap",
+            rel(&path),
+            LITERAL
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn il_disassembly_resolves_members() {
+    for (_, path) in dotnet_samples(IL_VARIANTS) {
+        let data = require_fixture(&path, SCRIPT);
+        let md = CilMetadata::parse(&data).unwrap();
+        let program =
+            disassemble_assembly(&data).unwrap_or_else(|e| panic!("{}: IL: {:?}", rel(&path), e));
+        let main = program
+            .methods
+            .iter()
+            .find(|m| m.name == "Glaurung.Samples.Inventory::Main")
+            .unwrap_or_else(|| panic!("{}: Main has no body", rel(&path)));
+        let text: Vec<String> = main
+            .body
+            .instructions
+            .iter()
+            .map(|i| i.render(&md))
+            .collect();
+        for want in [
+            format!("ldstr {:?}", LITERAL),
+            "Glaurung.Samples.Inventory::Add".to_string(),
+            "call System.Console::WriteLine".to_string(),
+        ] {
+            assert!(
+                text.iter().any(|l| l.ends_with(&want)),
+                "{}: Main lacks {:?}: {:#?}",
+                rel(&path),
+                want,
+                text
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn managed_call_graph_links_methods() {
+    for (_, path) in dotnet_samples(IL_VARIANTS) {
+        let data = require_fixture(&path, SCRIPT);
+        let cg = disassemble_assembly(&data).unwrap().call_graph;
+        let edge = |caller: &str, callee: &str| {
+            cg.edges
+                .iter()
+                .find(|e| e.caller == caller && e.callee == callee)
+                .unwrap_or_else(|| panic!("{}: no edge {} -> {}", rel(&path), caller, callee))
+        };
+        let add = edge(
+            "Glaurung.Samples.Inventory::Main",
+            "Glaurung.Samples.Inventory::Add",
+        );
+        // Two literal adds plus one per argument.
+        assert_eq!(add.call_sites.len(), 3, "{}", rel(&path));
+        assert_eq!(add.call_type, CallType::Virtual, "{}", rel(&path));
+        let run = edge(
+            "Glaurung.Samples.Inventory::Main",
+            "Glaurung.Samples.Plugins::Run",
+        );
+        assert_eq!(run.call_type, CallType::Direct, "{}", rel(&path));
+        edge(
+            "Glaurung.Samples.Plugins::Run",
+            "System.Reflection.Assembly::Load",
+        );
+        assert!(
+            cg.nodes.iter().any(|n| n == "System.Console::WriteLine"),
+            "{}: external callees are not nodes",
+            rel(&path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn pinvoke_and_reflection_are_capabilities() {
+    for (_, path) in dotnet_samples(IL_VARIANTS) {
+        let data = require_fixture(&path, SCRIPT);
+        let pinvokes = CilMetadata::parse(&data).unwrap().pinvokes();
+        let (method, module, entry) = PINVOKE;
+        assert!(
+            pinvokes
+                .iter()
+                .any(|p| p.method == method && p.module == module && p.entry == entry),
+            "{}: {} not imported from {}: {:?}",
+            rel(&path),
+            entry,
+            module,
+            pinvokes
+        );
+        let suspicious = summarize_pe(&data, &BudgetCaps::default())
+            .suspicious_imports
+            .unwrap_or_default();
+        for want in [
+            "virtualprotect",
+            "system.reflection.assembly::load",
+            "system.activator::createinstance",
+        ] {
+            assert!(
+                suspicious.iter().any(|s| s == want),
+                "{}: {} not flagged: {:?}",
+                rel(&path),
+                want,
+                suspicious
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn assemblies_reference_their_core_library() {
+    for (variant, path) in dotnet_samples(IL_VARIANTS) {
+        let corlib = if variant == "framework" {
+            "mscorlib"
+        } else {
+            "System.Runtime"
+        };
+        let data = require_fixture(&path, SCRIPT);
+        assert!(
+            contains(&data, format!("{}\0", corlib).as_bytes()),
+            "{}: no AssemblyRef to {}",
+            rel(&path),
+            corlib
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn ready_to_run_images_have_a_native_header() {
+    for (_, path) in dotnet_samples(&["r2r"]) {
+        let data = require_fixture(&path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        let com = pe
+            .data_directory(IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR)
+            .unwrap();
+        let cor20 = pe.rva_to_offset(com.virtual_address).unwrap();
+        // IMAGE_COR20_HEADER.ManagedNativeHeader is the last directory,
+        // at offset 64; ReadyToRun puts its READYTORUN_HEADER there.
+        let word = |o: usize| {
+            let b = &data[cor20 + o..cor20 + o + 4];
+            u32::from_le_bytes([b[0], b[1], b[2], b[3]])
+        };
+        let (rva, size) = (word(64), word(68));
+        assert!(
+            rva != 0 && size != 0,
+            "{}: no ManagedNativeHeader",
+            rel(&path)
+        );
+        let off = pe.rva_to_offset(rva).unwrap();
+        assert_eq!(
+            &data[off..off + 4],
+            b"RTR\0",
+            "{}: ManagedNativeHeader is not a READYTORUN_HEADER",
+            rel(&path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn single_file_bundles_embed_the_assembly() {
+    for (_, path) in dotnet_samples(&["self-contained"]) {
+        let data = require_fixture(&path, SCRIPT);
+        let windows = path.extension().is_some_and(|e| e == "exe");
+        let magic: &[u8] = if windows { b"MZ" } else { b"\x7fELF" };
+        assert!(
+            data.starts_with(magic),
+            "{}: bundle is not a native apphost",
+            rel(&path)
+        );
+        // The app assembly is appended uncompressed: its metadata root
+        // signature and user strings are in the bundle.
+        assert!(
+            contains(&data, b"BSJB"),
+            "{}: no CLI metadata root in the bundle",
+            rel(&path)
+        );
+        assert!(
+            contains(&data, &utf16le(LITERAL)),
+            "{}: app assembly not bundled",
+            rel(&path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn class_files_parse_with_their_jdk_version() {
+    for dir in jdk_dirs() {
+        let path = dir.join("classes").join(format!("{}.class", JAVA_MAIN));
+        let data = require_fixture(&path, SCRIPT);
+        let class = parse_class(&data).unwrap_or_else(|e| panic!("{}: {:?}", rel(&path), e));
+        assert_eq!(class.class_name, JAVA_MAIN, "{}", rel(&path));
+        if let Some(major) = jdk_major(&dir) {
+            assert_eq!(class.major_version, major, "{}: major version", rel(&path));
+        }
+        for want in JAVA_METHODS {
+            assert!(
+                class.methods.iter().any(|m| m.name == *want),
+                "{}: method {} missing",
+                rel(&path),
+                want
+            );
+        }
+        // The merge lambda and the stream lambda bootstrap through the
+        // LambdaMetafactory.
+        assert!(
+            class.bootstrap_method_count >= 2,
+            "{}: {} bootstrap methods",
+            rel(&path),
+            class.bootstrap_method_count
+        );
+
+        let module = dir.join("classes/module-info.class");
+        if let Some(data) = read_fixture(&module) {
+            let info = parse_class(&data).unwrap();
+            let m = info.module.expect("module-info without Module attribute");
+            assert_eq!(m.name, "com.glaurung.samples.app", "{}", rel(&module));
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn fat_jars_bundle_the_library() {
+    for dir in jdk_dirs() {
+        let path = dir.join("inventory-fat.jar");
+        let data = require_fixture(&path, SCRIPT);
+        let jar = ApkReader::open(&data).unwrap();
+        for class in [JAVA_MAIN, JAVA_LEDGER] {
+            let name = format!("{}.class", class);
+            let bytes = jar
+                .read(&name)
+                .unwrap_or_else(|e| panic!("{}: {}: {}", rel(&path), name, e));
+            assert_eq!(parse_class(&bytes).unwrap().class_name, class);
+        }
+        assert!(
+            !jar.contains("module-info.class"),
+            "{}: shaded JAR kept a module descriptor",
+            rel(&path)
+        );
+        let manifest = jar.read("META-INF/MANIFEST.MF").unwrap();
+        let main_class = format!("Main-Class: {}", JAVA_MAIN.replace('/', "."));
+        assert!(
+            String::from_utf8_lossy(&manifest)
+                .lines()
+                .any(|l| l.trim_end() == main_class),
+            "{}: manifest lacks {:?}",
+            rel(&path),
+            main_class
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn fat_jar_call_graph_crosses_into_the_library() {
+    for dir in jdk_dirs() {
+        let path = dir.join("inventory-fat.jar");
+        let data = require_fixture(&path, SCRIPT);
+        let classes = jar_classes(&data).unwrap();
+        let cg = call_graph(&classes);
+        let edge = |caller: &str, callee: &str| {
+            cg.edges
+                .iter()
+                .find(|e| e.caller.starts_with(caller) && e.callee.starts_with(callee))
+                .map(|e| e.call_type)
+        };
+        let add = format!("{}.add:", JAVA_MAIN);
+        assert_eq!(
+            edge(&add, &format!("{}.record:", JAVA_LEDGER)),
+            Some(CallType::Virtual),
+            "{}: add -> Ledger.record",
+            rel(&path)
+        );
+        // The merge lambda is reached through LambdaMetafactory.
+        assert_eq!(
+            edge(&add, &format!("{}.lambda$add$", JAVA_MAIN)),
+            Some(CallType::Indirect),
+            "{}: add -> merge lambda",
+            rel(&path)
+        );
+        // price() calls through the Priced interface; Item overrides it.
+        let price = format!("{}.price:", JAVA_MAIN);
+        assert_eq!(
+            edge(&price, &format!("{}$Item.priceCents:", JAVA_MAIN)),
+            Some(CallType::Virtual),
+            "{}: price -> Item.priceCents",
+            rel(&path)
+        );
+        let run = format!("{}.run:", JAVA_PLUGINS);
+        let callees: Vec<String> = cg
+            .edges
+            .iter()
+            .filter(|e| e.caller.starts_with(&run))
+            .map(|e| e.callee.clone())
+            .collect();
+        let suspicious = detect_suspicious_java_calls(&callees, 16);
+        for want in [
+            "java/lang/Class.forName",
+            "java/lang/Runtime.exec",
+            "java/lang/reflect/Method.invoke",
+            "java/net/URLClassLoader.<init>",
+        ] {
+            assert!(
+                suspicious.iter().any(|s| s == want),
+                "{}: {} not flagged in {:?}",
+                rel(&path),
+                want,
+                suspicious
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the managed samples from samples/build-managed-samples.sh"]
+fn jlink_images_hold_the_modules() {
+    for dir in jdk_dirs() {
+        let image = dir.join("image");
+        // JDK 8 has no jlink.
+        if !image.is_dir() {
+            continue;
+        }
+        let modules = require_fixture(&image.join("lib/modules"), SCRIPT);
+        // jimage files start with 0xCAFEDADA in host byte order.
+        assert!(
+            modules.starts_with(&0xCAFE_DADAu32.to_le_bytes())
+                || modules.starts_with(&0xCAFE_DADAu32.to_be_bytes()),
+            "{}: lib/modules is not a jimage",
+            rel(&image)
+        );
+        assert!(
+            contains(&modules, JAVA_MAIN.as_bytes()),
+            "{}: app classes not in the jimage",
+            rel(&image)
+        );
+        let release = std::fs::read_to_string(image.join("release")).unwrap_or_default();
+        let modules_line = release
+            .lines()
+            .find(|l| l.starts_with("MODULES="))
+            .unwrap_or_default();
+        for m in [
+            "java.base",
+            "com.glaurung.samples.ledger",
+            "com.glaurung.samples.app",
+        ] {
+            assert!(
+                modules_line.contains(m),
+                "{}: release MODULES lacks {}",
+                rel(&image),
+                m
+            );
+        }
+        assert!(
+            image.join("bin/inventory").is_file(),
+            "{}: no inventory launcher",
+            rel(&image)
+        );
+    }
+}
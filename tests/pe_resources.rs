@@ -0,0 +1,269 @@
+//! Resource parsing and Authenticode on the Windows resource sample.
+//!
+//! Binaries come from `samples/build-pe-resources.sh` and live under
+//! `samples/binaries/platforms/windows/<arch>/export/native/resources/` as
+//! `versioned-<driver>.exe`, plus a `-signed.exe` twin signed with the
+//! self-signed `test-signing.cer` beside it. Expected values are the ones in
+//! `samples/source/c/resources/versioned.rc`. The binaries are git-lfs
+//! fixtures, so the tests are ignored by default: build or fetch them, then
+//! run `cargo test --test pe_resources -- --ignored`.
+
+use glaurung::formats::pe::{PeParser, ResourceDataEntry, IMAGE_DIRECTORY_ENTRY_SECURITY};
+use glaurung::triage::api::analyze_path;
+use glaurung::triage::io::IOLimits;
+use sha2::{Digest, Sha256};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-pe-resources.sh";
+
+const FILE_VERSION: (u16, u16, u16, u16) = (1, 2, 3, 4);
+const VERSION_STRINGS: &[(&str, &str)] = &[
+    ("CompanyName", "Glaurung Project"),
+    ("FileDescription", "Glaurung resource sample"),
+    ("ProductName", "Glaurung Samples"),
+    ("OriginalFilename", "versioned.exe"),
+];
+const GREETING: &str = "glaurung-resource-greeting";
+const ICON_SIZES: &[u8] = &[16, 32];
+
+const VS_FIXEDFILEINFO_SIGNATURE: u32 = 0xFEEF_04BD;
+const WIN_CERT_REVISION_2_0: u16 = 0x0200;
+const WIN_CERT_TYPE_PKCS_SIGNED_DATA: u16 = 0x0002;
+
+struct ResourceSample {
+    path: PathBuf,
+    signed: bool,
+}
+
+fn resource_samples() -> Vec<ResourceSample> {
+    let out = samples("export/native/resources")
+        .into_iter()
+        .filter(|s| s.os == "windows")
+        .filter(|s| s.name.starts_with("versioned-") && s.name.ends_with(".exe"))
+        .map(|s| ResourceSample {
+            signed: s.name.ends_with("-signed.exe"),
+            path: s.path,
+        })
+        .collect();
+    require_any(out, "PE resource", SCRIPT)
+}
+
+fn contains(haystack: &[u8], needle: &[u8]) -> bool {
+    haystack.windows(needle.len()).any(|w| w == needle)
+}
+
+fn utf16le(s: &str) -> Vec<u8> {
+    s.encode_utf16().flat_map(|u| u.to_le_bytes()).collect()
+}
+
+fn u16_at(d: &[u8], o: usize) -> u16 {
+    u16::from_le_bytes([d[o], d[o + 1]])
+}
+
+fn u32_at(d: &[u8], o: usize) -> u32 {
+    u32::from_le_bytes([d[o], d[o + 1], d[o + 2], d[o + 3]])
+}
+
+fn of_type<'a, 'd>(
+    resources: &'a [ResourceDataEntry<'d>],
+    type_name: &'a str,
+) -> impl Iterator<Item = &'a ResourceDataEntry<'d>> + 'a {
+    resources
+        .iter()
+        .filter(move |r| r.type_name.as_deref() == Some(type_name))
+}
+
+/// Authenticode PE image hash (SHA-256): the whole file except the
+/// checksum, the certificate-table directory entry, and the certificate
+/// table itself.
+fn authenticode_sha256(data: &[u8]) -> Vec<u8> {
+    let opt = u32_at(data, 0x3C) as usize + 24;
+    let checksum = opt + 64;
+    let dirs = opt + if u16_at(data, opt) == 0x20B { 112 } else { 96 };
+    let security = dirs + 8 * IMAGE_DIRECTORY_ENTRY_SECURITY;
+    let cert_off = u32_at(data, security) as usize;
+    let cert_end = cert_off + u32_at(data, security + 4) as usize;
+    let mut h = Sha256::new();
+    h.update(&data[..checksum]);
+    h.update(&data[checksum + 4..security]);
+    h.update(&data[security + 8..cert_off]);
+    h.update(&data[cert_end.min(data.len())..]);
+    h.finalize().to_vec()
+}
+
+#[test]
+#[ignore = "needs the resource samples from samples/build-pe-resources.sh"]
+fn version_resource_matches_the_rc() {
+    for s in resource_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        let resources = &pe.resources().unwrap().resources;
+        let version: Vec<_> = of_type(resources, "VERSIONINFO").collect();
+        assert_eq!(version.len(), 1, "{}: VERSIONINFO count", rel(&s.path));
+        let v = version[0].data;
+        assert!(
+            contains(v, &utf16le("VS_VERSION_INFO")),
+            "{}: no VS_VERSION_INFO key",
+            rel(&s.path)
+        );
+        let fixed = v
+            .windows(4)
+            .position(|w| w == VS_FIXEDFILEINFO_SIGNATURE.to_le_bytes())
+            .unwrap_or_else(|| panic!("{}: no VS_FIXEDFILEINFO", rel(&s.path)));
+        let (ms, ls) = (u32_at(v, fixed + 8), u32_at(v, fixed + 12));
+        let (a, b, c, d) = FILE_VERSION;
+        assert_eq!(
+            (ms, ls),
+            ((a as u32) << 16 | b as u32, (c as u32) << 16 | d as u32),
+            "{}: dwFileVersion",
+            rel(&s.path)
+        );
+        for (key, value) in VERSION_STRINGS {
+            assert!(
+                contains(v, &utf16le(key)) && contains(v, &utf16le(value)),
+                "{}: StringFileInfo lacks {} = {:?}",
+                rel(&s.path),
+                key,
+                value
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the resource samples from samples/build-pe-resources.sh"]
+fn manifest_is_embedded() {
+    for s in resource_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        let resources = &pe.resources().unwrap().resources;
+        let manifest = of_type(resources, "MANIFEST")
+            .find(|r| r.name.as_id() == Some(1))
+            .unwrap_or_else(|| panic!("{}: no RT_MANIFEST #1", rel(&s.path)));
+        let xml = String::from_utf8_lossy(manifest.data);
+        for needle in [
+            r#"name="Glaurung.Samples.Versioned""#,
+            r#"requestedExecutionLevel level="asInvoker""#,
+            "<dpiAware",
+        ] {
+            assert!(
+                xml.contains(needle),
+                "{}: manifest lacks {}",
+                rel(&s.path),
+                needle
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the resource samples from samples/build-pe-resources.sh"]
+fn icon_group_references_its_icons() {
+    for s in resource_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        let resources = &pe.resources().unwrap().resources;
+        let groups: Vec<_> = of_type(resources, "GROUP_ICON").collect();
+        assert_eq!(groups.len(), 1, "{}: GROUP_ICON count", rel(&s.path));
+        let g = groups[0].data;
+        // GRPICONDIR, then 14-byte GRPICONDIRENTRYs.
+        assert_eq!(u16_at(g, 2), 1, "{}: not an icon group", rel(&s.path));
+        let count = u16_at(g, 4) as usize;
+        assert_eq!(count, ICON_SIZES.len(), "{}: icon count", rel(&s.path));
+        for i in 0..count {
+            let e = &g[6 + 14 * i..6 + 14 * (i + 1)];
+            let (width, bytes, id) = (e[0], u32_at(e, 8), u16_at(e, 12));
+            assert!(ICON_SIZES.contains(&width), "{}: icon width", rel(&s.path));
+            let icon = of_type(resources, "ICON")
+                .find(|r| r.name.as_id() == Some(id as u32))
+                .unwrap_or_else(|| panic!("{}: ICON #{} missing", rel(&s.path), id));
+            assert_eq!(icon.size, bytes, "{}: ICON #{} size", rel(&s.path), id);
+            // BITMAPINFOHEADER, as written by glaurung.ico.
+            assert_eq!(u32_at(icon.data, 0), 40, "{}: ICON #{}", rel(&s.path), id);
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the resource samples from samples/build-pe-resources.sh"]
+fn string_table_holds_the_greeting() {
+    for s in resource_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        let resources = &pe.resources().unwrap().resources;
+        // IDS_GREETING is 201: block 201 / 16 + 1.
+        let block = of_type(resources, "STRINGTABLE")
+            .find(|r| r.name.as_id() == Some(201 / 16 + 1))
+            .unwrap_or_else(|| panic!("{}: string block 13 missing", rel(&s.path)));
+        assert!(
+            contains(block.data, &utf16le(GREETING)),
+            "{}: greeting not in string block",
+            rel(&s.path)
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the resource samples from samples/build-pe-resources.sh"]
+fn signed_builds_carry_authenticode() {
+    for s in resource_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let pe = PeParser::new(&data).unwrap();
+        if !s.signed {
+            assert!(
+                !pe.is_signed(),
+                "{}: unsigned build is signed",
+                rel(&s.path)
+            );
+            continue;
+        }
+        assert!(pe.is_signed(), "{}: no certificate table", rel(&s.path));
+        // The security directory holds a file offset, not an RVA.
+        let dir = pe.data_directory(IMAGE_DIRECTORY_ENTRY_SECURITY).unwrap();
+        let (off, len) = (dir.virtual_address as usize, dir.size as usize);
+        assert!(
+            off + len <= data.len(),
+            "{}: certificate table",
+            rel(&s.path)
+        );
+        let cert = &data[off..off + len];
+        assert_eq!(u16_at(cert, 4), WIN_CERT_REVISION_2_0, "{}", rel(&s.path));
+        assert_eq!(
+            u16_at(cert, 6),
+            WIN_CERT_TYPE_PKCS_SIGNED_DATA,
+            "{}",
+            rel(&s.path)
+        );
+        let pkcs7 = &cert[8..u32_at(cert, 0) as usize];
+
+        // SpcIndirectDataContent carries the image digest verbatim.
+        assert!(
+            contains(pkcs7, &authenticode_sha256(&data)),
+            "{}: Authenticode digest does not match the image",
+            rel(&s.path)
+        );
+        let signer = s.path.with_file_name("test-signing.cer");
+        if let Ok(der) = std::fs::read(&signer) {
+            assert!(
+                contains(pkcs7, &der),
+                "{}: not signed by {}",
+                rel(&s.path),
+                rel(&signer)
+            );
+        }
+
+        let artifact = analyze_path(&s.path, &IOLimits::default()).unwrap();
+        assert!(
+            artifact
+                .signing
+                .is_some_and(|sig| sig.pe_authenticode_present),
+            "{}: triage does not report the signature",
+            rel(&s.path)
+        );
+    }
+}
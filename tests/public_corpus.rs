@@ -0,0 +1,100 @@
+//! Triage over the fetched public corpus.
+//!
+//! `scripts/fetch_public_corpus.py` extracts the binaries pinned in
+//! `samples/public-corpus.json` under `samples/public/<os>/<arch>/<name>/`
+//! and records them in `samples/public/index.json`. The corpus is not
+//! committed, so the tests are ignored by default: fetch it, then run
+//! `cargo test --test public_corpus -- --ignored`.
+
+use glaurung::core::binary::{Arch, Format};
+use glaurung::triage::api::analyze_path;
+use glaurung::triage::io::IOLimits;
+use sha2::{Digest, Sha256};
+use std::path::{Path, PathBuf};
+
+const PUBLIC: &str = "samples/public";
+
+struct CorpusFile {
+    path: PathBuf,
+    platform: String,
+    sha256: String,
+}
+
+fn corpus_files() -> Vec<CorpusFile> {
+    let index = Path::new(PUBLIC).join("index.json");
+    let text = std::fs::read_to_string(&index).unwrap_or_else(|e| {
+        panic!(
+            "{}: {}; run scripts/fetch_public_corpus.py",
+            index.display(),
+            e
+        )
+    });
+    let index: serde_json::Value = serde_json::from_str(&text).expect("index.json is valid JSON");
+    let mut out = Vec::new();
+    for (name, entry) in index.as_object().into_iter().flatten() {
+        let platform = entry["platform"].as_str().unwrap_or_default().to_string();
+        for (file, info) in entry["files"].as_object().into_iter().flatten() {
+            out.push(CorpusFile {
+                path: Path::new(PUBLIC).join(&platform).join(name).join(file),
+                platform: platform.clone(),
+                sha256: info["sha256"].as_str().unwrap_or_default().to_string(),
+            });
+        }
+    }
+    out.sort_by(|a, b| a.path.cmp(&b.path));
+    out
+}
+
+fn rel(p: &Path) -> String {
+    p.strip_prefix(PUBLIC).unwrap_or(p).display().to_string()
+}
+
+/// Expected container format and architecture for an OS platform; `None`
+/// for firmware images and for architectures `Arch` has no variant for.
+fn expected(platform: &str) -> Option<(Format, Arch)> {
+    let (os, arch) = platform.split_once('/')?;
+    let format = match os {
+        "linux" => Format::ELF,
+        "windows" => Format::PE,
+        _ => return None,
+    };
+    let arch = match arch {
+        "amd64" => Arch::X86_64,
+        "i386" => Arch::X86,
+        "arm64" => Arch::AArch64,
+        "armhf" => Arch::ARM,
+        "mips64el" => Arch::MIPS64,
+        "ppc64el" => Arch::PPC64,
+        _ => return None,
+    };
+    Some((format, arch))
+}
+
+#[test]
+#[ignore = "needs the corpus from scripts/fetch_public_corpus.py"]
+fn fetched_files_match_index() {
+    for f in corpus_files() {
+        let data = std::fs::read(&f.path)
+            .unwrap_or_else(|e| panic!("{}: listed in index.json but {}", rel(&f.path), e));
+        let got = hex::encode(Sha256::digest(&data));
+        assert_eq!(got, f.sha256, "{}: modified since extraction", rel(&f.path));
+    }
+}
+
+#[test]
+#[ignore = "needs the corpus from scripts/fetch_public_corpus.py"]
+fn triage_identifies_platform() {
+    for f in corpus_files() {
+        let art = analyze_path(&f.path, &IOLimits::default())
+            .unwrap_or_else(|e| panic!("{}: {}", rel(&f.path), e));
+        let Some((format, arch)) = expected(&f.platform) else {
+            continue;
+        };
+        let top = art
+            .verdicts
+            .first()
+            .unwrap_or_else(|| panic!("{}: no verdict", rel(&f.path)));
+        assert_eq!(top.format, format, "{}", rel(&f.path));
+        assert_eq!(top.arch, arch, "{}", rel(&f.path));
+    }
+}
@@ -0,0 +1,121 @@
+//! CPython bytecode parsing across interpreter versions.
+//!
+//! `samples/source/python/hello.py` is compiled by every interpreter the
+//! sample build finds into `samples/binaries/platforms/linux/<arch>/export/
+//! python/hello-py3.<minor>[.opt].pyc`. Each release changes the code-object
+//! layout, the opcode numbering, or both, so the same imports, literals,
+//! and methods must come back out of every one. The bytecode files are
+//! git-lfs fixtures, so the tests are ignored by default: build or fetch
+//! them, then run `cargo test --test pyc_samples -- --ignored`.
+
+use glaurung::analysis::pyc::{disassemble, parse_pyc};
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{require_any, require_fixture, samples};
+
+const SCRIPT: &str = "test_python_multi_version.sh";
+
+/// `(path, minor)` of every `hello-py3.<minor>*.pyc`.
+fn pyc_samples() -> Vec<(PathBuf, u8)> {
+    let out = samples("export/python")
+        .into_iter()
+        .filter(|s| s.os == "linux")
+        .filter_map(|s| {
+            let rest = s.name.strip_prefix("hello-py3.")?;
+            let minor = rest.split('.').next()?.parse().ok()?;
+            Some((s.path, minor))
+        })
+        .collect();
+    require_any(out, "CPython bytecode", SCRIPT)
+}
+
+#[test]
+#[ignore = "needs the .pyc samples from samples/test_python_multi_version.sh"]
+fn pyc_versions_match_file_names() {
+    for (path, minor) in pyc_samples() {
+        let data = require_fixture(&path, SCRIPT);
+        let pyc = parse_pyc(&data).unwrap_or_else(|e| panic!("{}: {:?}", path.display(), e));
+        assert_eq!(pyc.minor, minor, "{}", path.display());
+        assert!(
+            pyc.code.filename.ends_with("hello.py"),
+            "{}: {}",
+            path.display(),
+            pyc.code.filename
+        );
+    }
+}
+
+#[test]
+#[ignore = "needs the .pyc samples from samples/test_python_multi_version.sh"]
+fn pyc_imports_and_strings() {
+    for (path, _) in pyc_samples() {
+        let data = require_fixture(&path, SCRIPT);
+        let pyc = parse_pyc(&data).unwrap();
+        assert_eq!(pyc.imports(), vec!["os", "sys"], "{}", path.display());
+        let strings = pyc.strings();
+        for want in [
+            "Hello, World from Python!",
+            "Second instance from Python",
+            "__main__",
+        ] {
+            assert!(
+                strings.iter().any(|s| s == want),
+                "{}: {:?} missing from {:?}",
+                path.display(),
+                want,
+                strings
+            );
+        }
+    }
+}
+
+#[test]
+#[ignore = "needs the .pyc samples from samples/test_python_multi_version.sh"]
+fn pyc_disassembles_every_code_object() {
+    for (path, _) in pyc_samples() {
+        let data = require_fixture(&path, SCRIPT);
+        let pyc = parse_pyc(&data).unwrap();
+        let codes = pyc.code.code_objects();
+        for want in ["HelloWorld", "print_message", "calculate_arg_sum", "main"] {
+            assert!(
+                codes.iter().any(|c| c.name == want),
+                "{}: no code object {}",
+                path.display(),
+                want
+            );
+        }
+        for code in codes {
+            let ins = disassemble(code, pyc.minor)
+                .unwrap_or_else(|e| panic!("{}: {}: {:?}", path.display(), code.name, e));
+            assert!(!ins.is_empty(), "{}: {}", path.display(), code.name);
+            // Every jump lands on an instruction boundary.
+            for i in &ins {
+                if let Some(t) = i.target {
+                    assert!(
+                        ins.iter().any(|j| j.offset == t) || t as usize == code.bytecode.len(),
+                        "{}: {} jumps into the middle of an instruction: {}",
+                        path.display(),
+                        code.name,
+                        i
+                    );
+                }
+            }
+        }
+        let main = pyc
+            .code
+            .code_objects()
+            .into_iter()
+            .find(|c| c.name == "main")
+            .unwrap();
+        let ins = disassemble(main, pyc.minor).unwrap();
+        assert!(
+            ins.iter()
+                .any(|i| i.argrepr == "calculate_arg_sum" && i.opname.starts_with("LOAD_GLOBAL")),
+            "{}: main does not load calculate_arg_sum",
+            path.display()
+        );
+    }
+}
@@ -0,0 +1,236 @@
+//! Rust detection, v0 demangling, and std signature matching against the
+//! Rust samples.
+//!
+//! Binaries come from `samples/build-rust-samples.sh` and live under
+//! `samples/binaries/platforms/<os>/<arch>/export/rust/` as
+//! `<sample>-rust-<profile>[-v0]` (`lib<sample>-...so` for the cdylib). They
+//! are git-lfs fixtures, so the tests are ignored by default: build or fetch
+//! the samples, then run `cargo test --test rust_samples -- --ignored`.
+
+use glaurung::core::address::{Address, AddressKind};
+use glaurung::core::function::{Function, FunctionKind};
+use glaurung::demangle::{demangle_one, SymbolFlavor};
+use glaurung::flirt::{apply_flirt_overrides, FlirtLibrary, FlirtLibraryFile, FlirtSignatureEntry};
+use glaurung::triage::compiler_detection::{detect_language_and_compiler, SourceLanguage};
+use object::{Object, ObjectSection, ObjectSymbol};
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::{rel, require_any, require_fixture, samples};
+
+const SCRIPT: &str = "build-rust-samples.sh";
+const SAMPLES: &[&str] = &["async_exec", "panics", "generics", "cdylib"];
+const PROLOGUE_LEN: usize = 32;
+
+struct RustSample {
+    path: PathBuf,
+    sample: String,
+    release: bool,
+    v0: bool,
+}
+
+fn rust_samples() -> Vec<RustSample> {
+    let out = samples("export/rust")
+        .into_iter()
+        .filter(|s| !s.name.contains("-stripped"))
+        .filter_map(|s| {
+            let stem = s.name.split('.').next().unwrap_or(&s.name);
+            let stem = stem.strip_prefix("lib").unwrap_or(stem);
+            let (sample, variant) = stem.split_once("-rust-")?;
+            if !SAMPLES.contains(&sample) {
+                return None;
+            }
+            Some(RustSample {
+                sample: sample.to_string(),
+                release: variant.starts_with("release"),
+                v0: variant.ends_with("-v0"),
+                path: s.path,
+            })
+        })
+        .collect();
+    require_any(out, "Rust", SCRIPT)
+}
+
+fn symbol_names(obj: &object::File) -> Vec<String> {
+    obj.symbols()
+        .chain(obj.dynamic_symbols())
+        .filter_map(|s| s.name().ok().map(str::to_string))
+        .filter(|n| !n.is_empty())
+        .collect()
+}
+
+/// First `PROLOGUE_LEN` bytes of every defined function symbol, by name.
+fn prologues(data: &[u8], obj: &object::File) -> HashMap<String, (u64, Vec<u8>)> {
+    let mut out = HashMap::new();
+    for sym in obj.symbols() {
+        if !sym.is_definition() || sym.kind() != object::SymbolKind::Text {
+            continue;
+        }
+        let (Ok(name), Some(idx)) = (sym.name(), sym.section_index()) else {
+            continue;
+        };
+        let Ok(sec) = obj.section_by_index(idx) else {
+            continue;
+        };
+        let Some((foff, _)) = sec.file_range() else {
+            continue;
+        };
+        let start = (foff + (sym.address() - sec.address())) as usize;
+        if let Some(bytes) = data.get(start..start + PROLOGUE_LEN) {
+            out.insert(name.to_string(), (sym.address(), bytes.to_vec()));
+        }
+    }
+    out
+}
+
+fn is_std_symbol(name: &str) -> bool {
+    demangle_one(name).is_some_and(|r| {
+        r.flavor == SymbolFlavor::Rust
+            && [
+                "std::", "core::", "alloc::", "<std::", "<core::", "<alloc::",
+            ]
+            .iter()
+            .any(|p| r.demangled.starts_with(p))
+    })
+}
+
+#[test]
+#[ignore = "needs the Rust samples from samples/build-rust-samples.sh"]
+fn rust_samples_are_detected_as_rust() {
+    for s in rust_samples() {
+        let data = require_fixture(&s.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let comment = obj
+            .section_by_name(".comment")
+            .and_then(|sec| sec.data().ok())
+            .map(|d| String::from_utf8_lossy(d).into_owned());
+        let result = detect_language_and_compiler(
+            &symbol_names(&obj),
+            &[],
+            &[],
+            None,
+            comment.as_deref(),
+            &data,
+        );
+        assert_eq!(result.language, SourceLanguage::Rust, "{}", rel(&s.path));
+    }
+}
+
+#[test]
+#[ignore = "needs the Rust samples from samples/build-rust-samples.sh"]
+fn v0_symbols_demangle_with_generic_arguments() {
+    let v0 = rust_samples().into_iter().filter(|s| s.v0).collect();
+    for s in require_any(v0, "Rust v0-mangled", SCRIPT) {
+        let data = require_fixture(&s.path, SCRIPT);
+        let obj = object::File::parse(&*data).unwrap();
+        let mut demangled = Vec::new();
+        for name in symbol_names(&obj).iter().filter(|n| n.starts_with("_R")) {
+            let r = demangle_one(name)
+                .unwrap_or_else(|| panic!("{}: {} did not demangle", rel(&s.path), name));
+            assert_eq!(r.flavor, SymbolFlavor::Rust, "{}", name);
+            demangled.push(r.demangled);
+        }
+        assert!(!demangled.is_empty(), "{}: no v0 symbols", rel(&s.path));
+        if s.sample == "generics" {
+            for inst in ["::largest::<f64>", "::largest::<i64>", "::largest::<char>"] {
+                assert!(
+                    demangled.iter().any(|d| d.contains(inst)),
+                    "{}: no {} instantiation",
+                    rel(&s.path),
+                    inst
+                );
+            }
+            assert!(
+                demangled
+                    .iter()
+                    .any(|d| d.contains("::Triangle as ") && d.ends_with("::Shape>::area")),
+                "{}: no trait impl method",
+                rel(&s.path)
+            );
+        }
+    }
+}
+
+/// Signatures built from the std functions of one release sample should
+/// name the same std functions in another sample built by the same
+/// toolchain, since std is linked in precompiled.
+#[test]
+#[ignore = "needs the Rust samples from samples/build-rust-samples.sh"]
+fn std_signatures_transfer_between_samples() {
+    let release: Vec<RustSample> = rust_samples()
+        .into_iter()
+        .filter(|s| s.release && !s.v0 && s.sample != "cdylib")
+        .collect();
+    let find = |sample: &str| {
+        release
+            .iter()
+            .find(|s| s.sample == sample)
+            .unwrap_or_else(|| panic!("no release {} sample; run samples/{}", sample, SCRIPT))
+    };
+    let (lib_src, target) = (find("panics"), find("async_exec"));
+    let lib_data = require_fixture(&lib_src.path, SCRIPT);
+    let data = require_fixture(&target.path, SCRIPT);
+
+    let lib_obj = object::File::parse(&*lib_data).unwrap();
+    let mut by_prologue: HashMap<Vec<u8>, Vec<String>> = HashMap::new();
+    for (name, (_, bytes)) in prologues(&lib_data, &lib_obj) {
+        if is_std_symbol(&name) {
+            by_prologue.entry(bytes).or_default().push(name);
+        }
+    }
+    // Drop ambiguous prologues, as the library builder does.
+    let entries: Vec<FlirtSignatureEntry> = by_prologue
+        .into_iter()
+        .filter(|(_, names)| names.len() == 1)
+        .map(|(bytes, mut names)| FlirtSignatureEntry {
+            name: names.pop().unwrap(),
+            prologue_hex: bytes.iter().map(|b| format!("{:02x}", b)).collect(),
+            source_binary: rel(&lib_src.path),
+        })
+        .collect();
+    let lib = FlirtLibrary::from_file(FlirtLibraryFile {
+        schema_version: "1".to_string(),
+        arch: "x86_64".to_string(),
+        prologue_len: PROLOGUE_LEN,
+        entries,
+        index: HashMap::new(),
+        stats: serde_json::Value::Null,
+    });
+
+    // Pretend the target is stripped: placeholder names at its symbol VAs.
+    let obj = object::File::parse(&*data).unwrap();
+    let truth: HashMap<u64, String> = prologues(&data, &obj)
+        .into_iter()
+        .map(|(name, (va, _))| (va, name))
+        .collect();
+    let mut funcs: Vec<Function> = truth
+        .keys()
+        .map(|va| {
+            let entry = Address::new(AddressKind::VA, *va, 64, None, None).unwrap();
+            Function::new(format!("sub_{va:x}"), entry, FunctionKind::Normal).unwrap()
+        })
+        .collect();
+    let renamed = apply_flirt_overrides(&data, &mut funcs, &lib);
+    let correct = funcs
+        .iter()
+        .filter(|f| !f.name.starts_with("sub_"))
+        .filter(|f| truth.get(&f.entry_point.value) == Some(&f.name))
+        .count();
+    assert!(
+        correct >= 100,
+        "{}: only {} of {} std matches were correct",
+        rel(&target.path),
+        correct,
+        renamed
+    );
+    assert!(
+        correct * 10 >= renamed * 9,
+        "{}: {} of {} std matches were wrong",
+        rel(&target.path),
+        renamed - correct,
+        renamed
+    );
+}
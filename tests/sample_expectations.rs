@@ -0,0 +1,234 @@
+//! Triage, compiler identification, strings, and function discovery checked
+//! against per-sample expectations.
+//!
+//! `scripts/make_expectations.py` seeds `samples/expected/**/*.json` with
+//! what is known to be true of each sample (from its header, `.comment` or
+//! Rich header, source, and symbol table); the files may be tightened by
+//! hand. Keys an expectation leaves out are not checked. Entries whose
+//! binaries are unfetched LFS pointers are skipped.
+
+use glaurung::analysis::cfg::{analyze_functions_bytes, Budgets};
+use glaurung::core::binary::{Arch, Endianness, Format};
+use glaurung::strings::{extract_summary, StringsConfig};
+use glaurung::triage::api::analyze_path;
+use glaurung::triage::compiler_detection::{
+    detect_from_elf_comment, detect_from_rich_header, CompilerVendor,
+};
+use glaurung::triage::io::IOLimits;
+use glaurung::triage::rich_header::parse_rich_header;
+use object::{Object, ObjectSection};
+use serde::Deserialize;
+use sha2::{Digest, Sha256};
+use std::path::{Path, PathBuf};
+
+#[allow(dead_code)]
+mod common;
+
+use common::fixtures::read_fixture;
+
+const SAMPLES: &str = "samples";
+const EXPECTED_DIR: &str = "samples/expected";
+
+#[derive(Deserialize)]
+#[serde(deny_unknown_fields)]
+struct Expected {
+    #[allow(dead_code)]
+    schema_version: String,
+    binary: String,
+    sha256: String,
+    format: Format,
+    arch: Option<Arch>,
+    bits: Option<u8>,
+    endianness: Option<Endianness>,
+    compiler: Option<CompilerVendor>,
+    #[serde(default)]
+    strings: Vec<String>,
+    functions: Option<CountRange>,
+}
+
+#[derive(Deserialize)]
+#[serde(deny_unknown_fields)]
+struct CountRange {
+    min: usize,
+    max: usize,
+}
+
+fn expected_files(dir: &Path, out: &mut Vec<PathBuf>) {
+    let Ok(entries) = std::fs::read_dir(dir) else {
+        return;
+    };
+    for e in entries.flatten() {
+        let p = e.path();
+        if p.is_dir() {
+            expected_files(&p, out);
+        } else if p.extension().is_some_and(|x| x == "json") {
+            out.push(p);
+        }
+    }
+}
+
+fn load_expected() -> Vec<(PathBuf, Expected)> {
+    let mut paths = Vec::new();
+    expected_files(Path::new(EXPECTED_DIR), &mut paths);
+    paths.sort();
+    paths
+        .into_iter()
+        .map(|p| {
+            let text = std::fs::read_to_string(&p).unwrap();
+            let exp =
+                serde_json::from_str(&text).unwrap_or_else(|e| panic!("{}: {}", p.display(), e));
+            (p, exp)
+        })
+        .collect()
+}
+
+/// Bytes of a sample, checked against the hash recorded with its
+/// expectations.
+fn read_checked(exp: &Expected) -> Option<Vec<u8>> {
+    let data = read_fixture(&Path::new(SAMPLES).join(&exp.binary))?;
+    assert_eq!(
+        hex::encode(Sha256::digest(&data)),
+        exp.sha256,
+        "{}: expectations are stale; rerun scripts/make_expectations.py --force",
+        exp.binary
+    );
+    Some(data)
+}
+
+#[test]
+fn expectations_are_well_formed() {
+    for (path, exp) in load_expected() {
+        assert!(
+            Path::new(SAMPLES).join(&exp.binary).exists(),
+            "{}: {} does not exist",
+            path.display(),
+            exp.binary
+        );
+        if let Some(range) = &exp.functions {
+            assert!(
+                0 < range.min && range.min <= range.max,
+                "{}: empty function count range",
+                path.display()
+            );
+        }
+        assert!(
+            exp.strings.iter().all(|s| !s.is_empty()),
+            "{}: empty expected string",
+            path.display()
+        );
+    }
+}
+
+#[test]
+fn triage_verdict_matches() {
+    for (_, exp) in load_expected() {
+        if read_checked(&exp).is_none() {
+            continue;
+        }
+        let path = Path::new(SAMPLES).join(&exp.binary);
+        let art = analyze_path(&path, &IOLimits::default())
+            .unwrap_or_else(|e| panic!("{}: {}", exp.binary, e));
+        let top = art
+            .verdicts
+            .first()
+            .unwrap_or_else(|| panic!("{}: no verdict", exp.binary));
+        assert_eq!(top.format, exp.format, "{}: format", exp.binary);
+        if let Some(arch) = exp.arch {
+            assert_eq!(top.arch, arch, "{}: arch", exp.binary);
+        }
+        if let Some(bits) = exp.bits {
+            assert_eq!(top.bits, bits, "{}: bits", exp.binary);
+        }
+        if let Some(endianness) = exp.endianness {
+            assert_eq!(top.endianness, endianness, "{}: endianness", exp.binary);
+        }
+    }
+}
+
+#[test]
+fn compiler_vendor_matches() {
+    for (_, exp) in load_expected() {
+        let Some(vendor) = exp.compiler else {
+            continue;
+        };
+        let Some(data) = read_checked(&exp) else {
+            continue;
+        };
+        let info = match exp.format {
+            Format::PE => parse_rich_header(&data).and_then(|rh| detect_from_rich_header(&rh)),
+            _ => {
+                let obj = object::File::parse(&*data).unwrap();
+                let comment = obj
+                    .section_by_name(".comment")
+                    .and_then(|s| s.data().ok())
+                    .map(|d| String::from_utf8_lossy(d).into_owned())
+                    .unwrap_or_default();
+                detect_from_elf_comment(&comment)
+            }
+        };
+        assert_eq!(info.map(|i| i.vendor), Some(vendor), "{}", exp.binary);
+    }
+}
+
+#[test]
+fn notable_strings_are_extracted() {
+    for (_, exp) in load_expected() {
+        if exp.strings.is_empty() {
+            continue;
+        }
+        let Some(data) = read_checked(&exp) else {
+            continue;
+        };
+        // Every string, not a sample, and no language or IOC passes.
+        let cfg = StringsConfig {
+            max_samples: usize::MAX,
+            max_scan_bytes: data.len(),
+            time_guard_ms: 10_000,
+            enable_language: false,
+            enable_classification: false,
+            ..StringsConfig::default()
+        };
+        let found: Vec<String> = extract_summary(&data, &cfg)
+            .strings
+            .unwrap_or_default()
+            .into_iter()
+            .map(|s| s.text)
+            .collect();
+        for want in &exp.strings {
+            assert!(
+                found.iter().any(|s| s.contains(want.as_str())),
+                "{}: {:?} not among {} extracted strings",
+                exp.binary,
+                want,
+                found.len()
+            );
+        }
+    }
+}
+
+#[test]
+fn function_count_is_in_range() {
+    let budgets = Budgets {
+        max_functions: 0,
+        max_blocks: 100_000,
+        max_instructions: 1_000_000,
+        timeout_ms: 10_000,
+    };
+    for (_, exp) in load_expected() {
+        let Some(range) = &exp.functions else {
+            continue;
+        };
+        let Some(data) = read_checked(&exp) else {
+            continue;
+        };
+        let (funcs, _cg) = analyze_functions_bytes(&data, &budgets);
+        assert!(
+            (range.min..=range.max).contains(&funcs.len()),
+            "{}: {} functions, expected {}..={}",
+            exp.binary,
+            funcs.len(),
+            range.min,
+            range.max
+        );
+    }
+}